@@ -142,3 +142,20 @@ type BCCSP interface {
 	// The opts argument should be appropriate for the algorithm used.
 	Decrypt(k Key, ciphertext []byte, opts DecrypterOpts) (plaintext []byte, err error)
 }
+
+// VerifyItem is a signature to verify, as passed to Verify.
+type VerifyItem struct {
+	Key       Key
+	Signature []byte
+	Digest    []byte
+	Opts      SignerOpts
+}
+
+// BatchVerifier is implemented by the CSPs which can verify
+// many signatures at once faster than one at a time.
+type BatchVerifier interface {
+
+	// VerifyBatch verifies the signatures of items.
+	// valid[i] and errs[i] are what Verify returns for items[i].
+	VerifyBatch(items []VerifyItem) (valid []bool, errs []error)
+}
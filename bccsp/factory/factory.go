@@ -88,6 +88,34 @@ func GetBCCSP(opts Opts) (bccsp.BCCSP, error) {
 	return getBCCSPInternal(opts)
 }
 
+// InitFactories selects the factory creating the default BCCSP, which only SW can.
+// The GM factory is refused, as its SM2 signing is not constant time: its BCCSP
+// is obtained explicitly, by GetBCCSP, where only verification is needed.
+// It must be called before the default BCCSP is first used.
+func InitFactories(providerName string) error {
+	if providerName == GMBasedFactoryName {
+		return fmt.Errorf("Factory [%s] cannot create the default BCCSP, its SM2 signing is not constant time.", providerName)
+	}
+	if providerName != SoftwareBasedFactoryName {
+		return fmt.Errorf("Factory [%s] does not exist.", providerName)
+	}
+
+	initialized := true
+	factoriesInitOnce.Do(func() {
+		initialized = false
+	})
+	if initialized {
+		return errors.New("BCCSP factories are already initialized.")
+	}
+
+	// The once has been consumed above, so run the initialization here
+	if factoriesInitError = initFactoriesMap(); factoriesInitError != nil {
+		return factoriesInitError
+	}
+	defaultBCCSP, factoriesInitError = createDefaultBCCSP()
+	return factoriesInitError
+}
+
 func initFactories() error {
 	factoriesInitOnce.Do(func() {
 		// Initialize factories map
@@ -111,6 +139,10 @@ func initFactoriesMap() error {
 	f := &SWFactory{}
 	factories[f.Name()] = f
 
+	// BCCSP of the Chinese national cryptography standards
+	gmf := &GMFactory{}
+	factories[gmf.Name()] = gmf
+
 	return nil
 }
 
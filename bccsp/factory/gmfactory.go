@@ -0,0 +1,98 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/gm"
+	"github.com/hyperledger/fabric/bccsp/sw"
+)
+
+const (
+	// GMBasedFactoryName is the name of the factory of the BCCSP implementation
+	// of the Chinese national cryptography standards
+	GMBasedFactoryName = "GM"
+)
+
+// GMFactory is the factory of the GM BCCSP.
+type GMFactory struct {
+	initOnce sync.Once
+	bccsp    bccsp.BCCSP
+	err      error
+}
+
+// Name returns the name of this factory
+func (f *GMFactory) Name() string {
+	return GMBasedFactoryName
+}
+
+// Get returns an instance of BCCSP using Opts.
+func (f *GMFactory) Get(opts Opts) (bccsp.BCCSP, error) {
+	// Validate arguments
+	if opts == nil {
+		return nil, errors.New("Invalid opts. It must not be nil.")
+	}
+
+	if opts.FactoryName() != f.Name() {
+		return nil, fmt.Errorf("Invalid Provider Name [%s]. Opts must refer to [%s].", opts.FactoryName(), f.Name())
+	}
+
+	gmOpts, ok := opts.(*GmOpts)
+	if !ok {
+		return nil, errors.New("Invalid opts. They must be of type GmOpts.")
+	}
+
+	if !opts.Ephemeral() {
+		f.initOnce.Do(func() {
+			f.bccsp, f.err = newGM(gmOpts)
+			return
+		})
+		return f.bccsp, f.err
+	}
+
+	return newGM(gmOpts)
+}
+
+func newGM(opts *GmOpts) (bccsp.BCCSP, error) {
+	fallback, err := sw.New(opts.SecLevel, opts.HashFamily, opts.KeyStore)
+	if err != nil {
+		return nil, err
+	}
+	return gm.New(fallback)
+}
+
+// GmOpts contains options for the GMFactory. SecLevel, HashFamily and KeyStore
+// configure the software-based BCCSP serving the algorithms other than SM2, SM3 and SM4
+type GmOpts struct {
+	Ephemeral_ bool
+	SecLevel   int
+	HashFamily string
+	KeyStore   bccsp.KeyStore
+}
+
+// FactoryName returns the name of the provider
+func (o *GmOpts) FactoryName() string {
+	return GMBasedFactoryName
+}
+
+// Ephemeral returns true if the CSP has to be ephemeral, false otherwise
+func (o *GmOpts) Ephemeral() bool {
+	return o.Ephemeral_
+}
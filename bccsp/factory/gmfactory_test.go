@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package factory
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+)
+
+func TestGetGM(t *testing.T) {
+	ks := &sw.FileBasedKeyStore{}
+	if err := ks.Init(nil, os.TempDir(), false); err != nil {
+		t.Fatalf("Failed initializing key store [%s]", err)
+	}
+
+	csp, err := GetBCCSP(&GmOpts{Ephemeral_: true, SecLevel: 256, HashFamily: "SHA2", KeyStore: ks})
+	if err != nil {
+		t.Fatalf("Failed getting GM BCCSP [%s]", err)
+	}
+	if _, err := csp.KeyGen(&bccsp.SM2KeyGenOpts{Temporary: true}); err != nil {
+		t.Fatalf("GM BCCSP should generate SM2 keys [%s]", err)
+	}
+
+	if _, err := (&GMFactory{}).Get(&SwOpts{}); err == nil {
+		t.Fatal("GM factory should reject opts of another factory")
+	}
+}
+
+func TestInitFactories(t *testing.T) {
+	if err := InitFactories("UNKNOWN"); err == nil {
+		t.Fatal("Unknown factory should be rejected")
+	}
+	if err := InitFactories(GMBasedFactoryName); err == nil {
+		t.Fatal("GM factory should not create the default BCCSP")
+	}
+
+	if _, err := GetDefault(); err != nil {
+		t.Fatalf("Failed getting default BCCSP [%s]", err)
+	}
+	if err := InitFactories(GMBasedFactoryName); err == nil {
+		t.Fatal("Factories should not be initialized twice")
+	}
+}
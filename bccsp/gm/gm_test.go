@@ -0,0 +1,300 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gm
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+)
+
+func newTestBCCSP(t *testing.T) bccsp.BCCSP {
+	fallback, err := sw.NewDefaultSecurityLevel(os.TempDir())
+	if err != nil {
+		t.Fatalf("Failed creating fallback BCCSP [%s]", err)
+	}
+	csp, err := New(fallback)
+	if err != nil {
+		t.Fatalf("Failed creating GM BCCSP [%s]", err)
+	}
+	return csp
+}
+
+// TestSM3 checks the examples of GB/T 32905-2016 appendix A
+func TestSM3(t *testing.T) {
+	vectors := []struct {
+		msg    string
+		digest string
+	}{
+		{"abc", "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+		{"abcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcd", "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"},
+	}
+	for _, v := range vectors {
+		if digest := hex.EncodeToString(SumSM3([]byte(v.msg))); digest != v.digest {
+			t.Fatalf("SM3(%q) = %s, expected %s", v.msg, digest, v.digest)
+		}
+
+		// Writing byte by byte must compute the same digest
+		h := NewSM3()
+		for i := 0; i < len(v.msg); i++ {
+			h.Write([]byte{v.msg[i]})
+		}
+		if digest := hex.EncodeToString(h.Sum(nil)); digest != v.digest {
+			t.Fatalf("Incremental SM3(%q) = %s, expected %s", v.msg, digest, v.digest)
+		}
+	}
+}
+
+func TestSM4(t *testing.T) {
+	seen := make(map[byte]bool)
+	for _, b := range sm4Sbox {
+		seen[b] = true
+	}
+	if len(seen) != 256 {
+		t.Fatalf("S-box is not a permutation")
+	}
+
+	key, _ := hex.DecodeString("0123456789abcdeffedcba9876543210")
+	expected, _ := hex.DecodeString("681edf34d206965e86b3e94f536e4246")
+	block, err := NewSM4(key)
+	if err != nil {
+		t.Fatalf("Failed creating SM4 cipher [%s]", err)
+	}
+	ciphertext := make([]byte, SM4BlockSize)
+	block.Encrypt(ciphertext, key)
+	if !bytes.Equal(ciphertext, expected) {
+		t.Fatalf("SM4 ciphertext is %x, expected %x", ciphertext, expected)
+	}
+	plaintext := make([]byte, SM4BlockSize)
+	block.Decrypt(plaintext, ciphertext)
+	if !bytes.Equal(plaintext, key) {
+		t.Fatalf("SM4 decrypted %x, expected %x", plaintext, key)
+	}
+
+	if _, err := NewSM4(key[:8]); err == nil {
+		t.Fatalf("Short SM4 key should be rejected")
+	}
+}
+
+func TestSM2Curve(t *testing.T) {
+	c := SM2P256V1()
+	params := c.Params()
+	if !c.IsOnCurve(params.Gx, params.Gy) {
+		t.Fatalf("Base point is not on the curve")
+	}
+	x, y := c.ScalarBaseMult(params.N.Bytes())
+	if x.Sign() != 0 || y.Sign() != 0 {
+		t.Fatalf("Base point does not have order n")
+	}
+}
+
+// fixedScalar returns a reader from which randScalar reads the scalar k
+func fixedScalar(k *big.Int) io.Reader {
+	b := make([]byte, 40)
+	kMinusOne := new(big.Int).Sub(k, one).Bytes()
+	copy(b[len(b)-len(kMinusOne):], kMinusOne)
+	return bytes.NewReader(b)
+}
+
+// TestSM2KnownAnswer checks the example of GB/T 32918.2-2016 appendix A.2
+func TestSM2KnownAnswer(t *testing.T) {
+	hexInt := func(s string) *big.Int {
+		i, _ := new(big.Int).SetString(s, 16)
+		return i
+	}
+	priv, err := NewPrivateKey(hexInt("3945208F7B2144B13F36E38AC6D39F95889393692860B51A42FB81EF4DF7C5B8"))
+	if err != nil {
+		t.Fatalf("Failed creating SM2 key [%s]", err)
+	}
+	if priv.X.Cmp(hexInt("09F9DF311E5421A150DD7D161E4BC5C672179FAD1833FC076BB08FF356F35020")) != 0 ||
+		priv.Y.Cmp(hexInt("CCEA490CE26775A52DC6EA718CC1AA600AED05FBF35E084A6632F6072DA9AD13")) != 0 {
+		t.Fatalf("Unexpected public key (%X, %X)", priv.X, priv.Y)
+	}
+
+	digest, err := Digest(&priv.PublicKey, []byte("1234567812345678"), []byte("message digest"))
+	if err != nil {
+		t.Fatalf("Failed computing digest [%s]", err)
+	}
+	if e := hex.EncodeToString(digest); e != "f0b43e94ba45accaace692ed534382eb17e6ab5a19ce7b31f4486fdfc0d28640" {
+		t.Fatalf("Digest is %s, expected f0b43e94ba45accaace692ed534382eb17e6ab5a19ce7b31f4486fdfc0d28640", e)
+	}
+
+	signature, err := Sign(fixedScalar(hexInt("59276E27D506861A16680F3AD9C02DCCEF3CC1FA3CDBE4CE6D54B80DEAC1BC21")), priv, digest)
+	if err != nil {
+		t.Fatalf("Failed signing [%s]", err)
+	}
+	sig := &sm2Signature{}
+	if _, err = asn1.Unmarshal(signature, sig); err != nil {
+		t.Fatalf("Failed unmarshalling signature [%s]", err)
+	}
+	if sig.R.Cmp(hexInt("F5A03B0648D2C4630EEAC513E1BB81A15944DA3827D5B74143AC7EACEEE720B3")) != 0 ||
+		sig.S.Cmp(hexInt("B1B6AA29DF212FD8763182BC0D421CA1BB9038FD1F7F42D4840B69C485BBC1AA")) != 0 {
+		t.Fatalf("Unexpected signature (%X, %X)", sig.R, sig.S)
+	}
+	if valid, err := Verify(&priv.PublicKey, signature, digest); err != nil || !valid {
+		t.Fatalf("Signature should be valid: %v %s", valid, err)
+	}
+}
+
+func TestSM2SignVerify(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating SM2 key [%s]", err)
+	}
+	digest, err := Digest(&priv.PublicKey, DefaultSM2ID, []byte("message"))
+	if err != nil {
+		t.Fatalf("Failed computing digest [%s]", err)
+	}
+	signature, err := Sign(rand.Reader, priv, digest)
+	if err != nil {
+		t.Fatalf("Failed signing [%s]", err)
+	}
+	if valid, err := Verify(&priv.PublicKey, signature, digest); err != nil || !valid {
+		t.Fatalf("Signature should be valid: %v %s", valid, err)
+	}
+
+	other, err := Digest(&priv.PublicKey, DefaultSM2ID, []byte("other message"))
+	if err != nil {
+		t.Fatalf("Failed computing digest [%s]", err)
+	}
+	if valid, _ := Verify(&priv.PublicKey, signature, other); valid {
+		t.Fatalf("Signature of another message should not be valid")
+	}
+}
+
+func TestBCCSPSM2(t *testing.T) {
+	csp := newTestBCCSP(t)
+
+	k, err := csp.KeyGen(&bccsp.SM2KeyGenOpts{Temporary: false})
+	if err != nil {
+		t.Fatalf("Failed generating SM2 key [%s]", err)
+	}
+	if k.Symmetric() || !k.Private() {
+		t.Fatalf("SM2 key should be a private asymmetric key")
+	}
+	if _, err := k.Bytes(); err == nil {
+		t.Fatalf("SM2 private key should not be exportable")
+	}
+	stored, err := csp.GetKey(k.SKI())
+	if err != nil || stored != k {
+		t.Fatalf("Non ephemeral key should be retrieved by its SKI [%s]", err)
+	}
+
+	pk, err := k.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed getting public key [%s]", err)
+	}
+	raw, err := pk.Bytes()
+	if err != nil {
+		t.Fatalf("Failed marshalling public key [%s]", err)
+	}
+	imported, err := csp.KeyImport(raw, &bccsp.SM2PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing public key [%s]", err)
+	}
+	if !bytes.Equal(imported.SKI(), k.SKI()) {
+		t.Fatalf("Imported public key should have the SKI of its private key")
+	}
+
+	digest, err := Digest(imported.(*sm2PublicKey).pubKey, DefaultSM2ID, []byte("message"))
+	if err != nil {
+		t.Fatalf("Failed computing digest [%s]", err)
+	}
+	signature, err := csp.Sign(k, digest, nil)
+	if err != nil {
+		t.Fatalf("Failed signing [%s]", err)
+	}
+	if valid, err := csp.Verify(imported, signature, digest, nil); err != nil || !valid {
+		t.Fatalf("Signature should be valid: %v %s", valid, err)
+	}
+}
+
+func TestBCCSPSM4(t *testing.T) {
+	csp := newTestBCCSP(t)
+
+	k, err := csp.KeyGen(&bccsp.SM4KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating SM4 key [%s]", err)
+	}
+	if !k.Symmetric() {
+		t.Fatalf("SM4 key should be symmetric")
+	}
+	if _, err := csp.GetKey(k.SKI()); err == nil {
+		t.Fatalf("Ephemeral key should not be stored")
+	}
+
+	for _, msg := range [][]byte{nil, []byte("0123456789abcdef"), []byte("a message longer than one block")} {
+		ciphertext, err := csp.Encrypt(k, msg, &bccsp.SM4CBCPKCS7ModeOpts{})
+		if err != nil {
+			t.Fatalf("Failed encrypting [%s]", err)
+		}
+		plaintext, err := csp.Decrypt(k, ciphertext, &bccsp.SM4CBCPKCS7ModeOpts{})
+		if err != nil {
+			t.Fatalf("Failed decrypting [%s]", err)
+		}
+		if !bytes.Equal(plaintext, msg) {
+			t.Fatalf("Decrypted %q, expected %q", plaintext, msg)
+		}
+	}
+
+	if _, err := csp.KeyImport([]byte("short"), &bccsp.SM4ImportKeyOpts{}); err == nil {
+		t.Fatalf("Short SM4 key should be rejected")
+	}
+}
+
+func TestBCCSPFallback(t *testing.T) {
+	csp := newTestBCCSP(t)
+
+	msg := []byte("message")
+	digest, err := csp.Hash(msg, &bccsp.SHAOpts{})
+	if err != nil {
+		t.Fatalf("Failed hashing [%s]", err)
+	}
+	expected := sha256.Sum256(msg)
+	if !bytes.Equal(digest, expected[:]) {
+		t.Fatalf("Default hash should be served by the fallback BCCSP")
+	}
+
+	digest, err = csp.Hash(msg, &bccsp.SM3Opts{})
+	if err != nil {
+		t.Fatalf("Failed hashing [%s]", err)
+	}
+	if !bytes.Equal(digest, SumSM3(msg)) {
+		t.Fatalf("SM3 hash should be served by the GM BCCSP")
+	}
+
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key through the fallback [%s]", err)
+	}
+	signature, err := csp.Sign(k, expected[:], nil)
+	if err != nil {
+		t.Fatalf("Failed signing through the fallback [%s]", err)
+	}
+	if valid, err := csp.Verify(k, signature, expected[:], nil); err != nil || !valid {
+		t.Fatalf("Fallback signature should be valid: %v %s", valid, err)
+	}
+}
@@ -0,0 +1,402 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gm implements a BCCSP offering the Chinese national cryptography
+// standards: SM2 signatures, SM3 hashing and SM4 encryption.
+//
+// Every other algorithm, including the SHA family used by the default hash
+// options, is served by a fallback BCCSP such that nodes running this provider
+// compute the same block, transaction and config hashes as the rest of the
+// network. The channels keep hashing their blocks with SHAKE256 as well, so
+// SM3 is not a hashing algorithm of the channel config.
+//
+// The certificates with SM2 public keys or SM2 with SM3 signatures, which the
+// Go standard library does not parse, are parsed and verified by ParseCertificate
+// and VerifyChain, such that the X.509 MSP accepts SM2 identities. TLS has no
+// SM2 cipher suites in the Go standard library, so the TLS connections of the
+// nodes remain ECDSA.
+//
+// The arithmetic of sm2p256v1 is the generic one of elliptic.CurveParams, which
+// is not constant time, so the timing of SM2 signing may leak the private key.
+// This provider therefore cannot be the default BCCSP of a node, with which it
+// would sign: it only verifies SM2 signatures, whose inputs are public, for the
+// MSP.
+package gm
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+// New returns a new instance of the GM BCCSP. Algorithms other than SM2, SM3
+// and SM4 are delegated to fallback, which must not be nil.
+func New(fallback bccsp.BCCSP) (bccsp.BCCSP, error) {
+	if fallback == nil {
+		return nil, errors.New("Invalid fallback BCCSP. It must be different from nil.")
+	}
+
+	return &impl{fallback: fallback, keys: make(map[string]bccsp.Key)}, nil
+}
+
+// impl is the GM implementation of the BCCSP.
+type impl struct {
+	fallback bccsp.BCCSP
+
+	// keys holds the non-ephemeral GM keys, which the fallback key store cannot serialize
+	lock sync.RWMutex
+	keys map[string]bccsp.Key
+}
+
+func (csp *impl) storeKey(k bccsp.Key) {
+	csp.lock.Lock()
+	defer csp.lock.Unlock()
+	csp.keys[string(k.SKI())] = k
+}
+
+func isGMKey(k bccsp.Key) bool {
+	switch k.(type) {
+	case *sm2PrivateKey, *sm2PublicKey, *sm4Key:
+		return true
+	}
+	return false
+}
+
+// KeyGen generates a key using opts.
+func (csp *impl) KeyGen(opts bccsp.KeyGenOpts) (k bccsp.Key, err error) {
+	// Validate arguments
+	if opts == nil {
+		return nil, errors.New("Invalid Opts parameter. It must not be nil.")
+	}
+
+	switch opts.(type) {
+	case *bccsp.SM2KeyGenOpts:
+		lowLevelKey, err := GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("Failed generating SM2 key [%s]", err)
+		}
+
+		k = &sm2PrivateKey{lowLevelKey}
+
+	case *bccsp.SM4KeyGenOpts:
+		lowLevelKey := make([]byte, SM4BlockSize)
+		if _, err := io.ReadFull(rand.Reader, lowLevelKey); err != nil {
+			return nil, fmt.Errorf("Failed generating SM4 key [%s]", err)
+		}
+
+		k = &sm4Key{lowLevelKey, false}
+
+	default:
+		return csp.fallback.KeyGen(opts)
+	}
+
+	if !opts.Ephemeral() {
+		csp.storeKey(k)
+	}
+
+	return k, nil
+}
+
+// KeyDeriv derives a key from k using opts.
+// No derivation is defined for GM keys.
+func (csp *impl) KeyDeriv(k bccsp.Key, opts bccsp.KeyDerivOpts) (dk bccsp.Key, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+
+	if isGMKey(k) {
+		return nil, fmt.Errorf("Key type not supported for derivation [%T]", k)
+	}
+
+	return csp.fallback.KeyDeriv(k, opts)
+}
+
+// KeyImport imports a key from its raw representation using opts.
+// SM2 public keys are imported from an uncompressed curve point, SM2 private keys
+// from their big-endian scalar and SM4 keys from their 16 bytes.
+func (csp *impl) KeyImport(raw interface{}, opts bccsp.KeyImportOpts) (k bccsp.Key, err error) {
+	// Validate arguments
+	if raw == nil {
+		return nil, errors.New("Invalid raw. Cannot be nil")
+	}
+
+	if opts == nil {
+		return nil, errors.New("Invalid Opts parameter. It must not be nil.")
+	}
+
+	switch opts.(type) {
+	case *bccsp.SM2PublicKeyImportOpts, *bccsp.SM2PrivateKeyImportOpts, *bccsp.SM4ImportKeyOpts:
+	case *bccsp.X509PublicKeyImportOpts:
+		// the certificates parsed by ParseCertificate carry SM2 public keys
+		if cert, ok := raw.(*x509.Certificate); ok {
+			if pub, ok := cert.PublicKey.(*PublicKey); ok {
+				k = &sm2PublicKey{pub}
+				if !opts.Ephemeral() {
+					csp.storeKey(k)
+				}
+				return k, nil
+			}
+		}
+		return csp.fallback.KeyImport(raw, opts)
+	default:
+		return csp.fallback.KeyImport(raw, opts)
+	}
+
+	der, ok := raw.([]byte)
+	if !ok {
+		return nil, errors.New("[GM] Invalid raw material. Expected byte array.")
+	}
+	if len(der) == 0 {
+		return nil, errors.New("[GM] Invalid raw. It must not be nil.")
+	}
+
+	switch opts.(type) {
+	case *bccsp.SM2PublicKeyImportOpts:
+		pub, err := NewPublicKey(der)
+		if err != nil {
+			return nil, err
+		}
+
+		k = &sm2PublicKey{pub}
+
+	case *bccsp.SM2PrivateKeyImportOpts:
+		priv, err := NewPrivateKey(new(big.Int).SetBytes(der))
+		if err != nil {
+			return nil, err
+		}
+
+		k = &sm2PrivateKey{priv}
+
+	case *bccsp.SM4ImportKeyOpts:
+		if len(der) != SM4BlockSize {
+			return nil, fmt.Errorf("Invalid SM4 key length [%d], must be %d", len(der), SM4BlockSize)
+		}
+
+		k = &sm4Key{append([]byte(nil), der...), false}
+	}
+
+	if !opts.Ephemeral() {
+		csp.storeKey(k)
+	}
+
+	return k, nil
+}
+
+// GetKey returns the key this CSP associates to
+// the Subject Key Identifier ski.
+func (csp *impl) GetKey(ski []byte) (k bccsp.Key, err error) {
+	csp.lock.RLock()
+	k, ok := csp.keys[string(ski)]
+	csp.lock.RUnlock()
+	if ok {
+		return k, nil
+	}
+
+	return csp.fallback.GetKey(ski)
+}
+
+// Hash hashes messages msg using options opts.
+func (csp *impl) Hash(msg []byte, opts bccsp.HashOpts) (digest []byte, err error) {
+	if _, ok := opts.(*bccsp.SM3Opts); ok {
+		return SumSM3(msg), nil
+	}
+
+	return csp.fallback.Hash(msg, opts)
+}
+
+// GetHash returns and instance of hash.Hash using options opts.
+func (csp *impl) GetHash(opts bccsp.HashOpts) (h hash.Hash, err error) {
+	if _, ok := opts.(*bccsp.SM3Opts); ok {
+		return NewSM3(), nil
+	}
+
+	return csp.fallback.GetHash(opts)
+}
+
+// Sign signs digest using key k.
+//
+// For SM2 keys digest must be the value computed by Digest, which binds the
+// public key of the signer to SM3 hash of the message.
+func (csp *impl) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+	if len(digest) == 0 {
+		return nil, errors.New("Invalid digest. Cannot be empty.")
+	}
+
+	switch k.(type) {
+	case *sm2PrivateKey:
+		return Sign(rand.Reader, k.(*sm2PrivateKey).privKey, digest)
+	case *sm2PublicKey, *sm4Key:
+		return nil, fmt.Errorf("Key type not recognized [%T]", k)
+	default:
+		return csp.fallback.Sign(k, digest, opts)
+	}
+}
+
+// Verify verifies signature against key k and digest
+func (csp *impl) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	// Validate arguments
+	if k == nil {
+		return false, errors.New("Invalid Key. It must not be nil.")
+	}
+	if len(signature) == 0 {
+		return false, errors.New("Invalid signature. Cannot be empty.")
+	}
+	if len(digest) == 0 {
+		return false, errors.New("Invalid digest. Cannot be empty.")
+	}
+
+	switch k.(type) {
+	case *sm2PrivateKey:
+		return Verify(&k.(*sm2PrivateKey).privKey.PublicKey, signature, digest)
+	case *sm2PublicKey:
+		return Verify(k.(*sm2PublicKey).pubKey, signature, digest)
+	case *sm4Key:
+		return false, fmt.Errorf("Key type not recognized [%T]", k)
+	default:
+		return csp.fallback.Verify(k, signature, digest, opts)
+	}
+}
+
+// VerifyBatch verifies the signatures of items. The SM2 signatures are verified
+// one at a time, the others in a batch if the fallback BCCSP is a bccsp.BatchVerifier.
+func (csp *impl) VerifyBatch(items []bccsp.VerifyItem) (valid []bool, errs []error) {
+	valid = make([]bool, len(items))
+	errs = make([]error, len(items))
+	batchVerifier, batch := csp.fallback.(bccsp.BatchVerifier)
+	var indexes []int
+	var fallbackItems []bccsp.VerifyItem
+	for i, item := range items {
+		if batch && item.Key != nil && !isGMKey(item.Key) {
+			indexes = append(indexes, i)
+			fallbackItems = append(fallbackItems, item)
+			continue
+		}
+		valid[i], errs[i] = csp.Verify(item.Key, item.Signature, item.Digest, item.Opts)
+	}
+	if len(fallbackItems) > 0 {
+		fallbackValid, fallbackErrs := batchVerifier.VerifyBatch(fallbackItems)
+		for j, i := range indexes {
+			valid[i], errs[i] = fallbackValid[j], fallbackErrs[j]
+		}
+	}
+	return valid, errs
+}
+
+// Encrypt encrypts plaintext using key k.
+// SM4 keys encrypt in CBC mode with PKCS7 padding, the random IV prefixing the ciphertext.
+func (csp *impl) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts) (ciphertext []byte, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+
+	switch k.(type) {
+	case *sm4Key:
+		switch opts.(type) {
+		case *bccsp.SM4CBCPKCS7ModeOpts, bccsp.SM4CBCPKCS7ModeOpts:
+			return SM4CBCPKCS7Encrypt(k.(*sm4Key).key, plaintext)
+		default:
+			return nil, fmt.Errorf("Mode not recognized [%s]", opts)
+		}
+	case *sm2PrivateKey, *sm2PublicKey:
+		return nil, fmt.Errorf("Key type not recognized [%T]", k)
+	default:
+		return csp.fallback.Encrypt(k, plaintext, opts)
+	}
+}
+
+// Decrypt decrypts ciphertext using key k.
+func (csp *impl) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpts) (plaintext []byte, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+
+	switch k.(type) {
+	case *sm4Key:
+		switch opts.(type) {
+		case *bccsp.SM4CBCPKCS7ModeOpts, bccsp.SM4CBCPKCS7ModeOpts:
+			return SM4CBCPKCS7Decrypt(k.(*sm4Key).key, ciphertext)
+		default:
+			return nil, fmt.Errorf("Mode not recognized [%s]", opts)
+		}
+	case *sm2PrivateKey, *sm2PublicKey:
+		return nil, fmt.Errorf("Key type not recognized [%T]", k)
+	default:
+		return csp.fallback.Decrypt(k, ciphertext, opts)
+	}
+}
+
+// SM4CBCPKCS7Encrypt pads src with PKCS7 and encrypts it with SM4 in CBC mode,
+// prefixing the ciphertext with the random IV
+func SM4CBCPKCS7Encrypt(key, src []byte) ([]byte, error) {
+	block, err := NewSM4(key)
+	if err != nil {
+		return nil, err
+	}
+
+	padding := SM4BlockSize - len(src)%SM4BlockSize
+	padded := append(append([]byte(nil), src...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	ciphertext := make([]byte, SM4BlockSize+len(padded))
+	iv := ciphertext[:SM4BlockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext[SM4BlockSize:], padded)
+	return ciphertext, nil
+}
+
+// SM4CBCPKCS7Decrypt decrypts src, as returned by SM4CBCPKCS7Encrypt, and removes its padding
+func SM4CBCPKCS7Decrypt(key, src []byte) ([]byte, error) {
+	block, err := NewSM4(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(src) < 2*SM4BlockSize || len(src)%SM4BlockSize != 0 {
+		return nil, errors.New("Invalid ciphertext. It must be a multiple of the block size")
+	}
+
+	plaintext := make([]byte, len(src)-SM4BlockSize)
+	cipher.NewCBCDecrypter(block, src[:SM4BlockSize]).CryptBlocks(plaintext, src[SM4BlockSize:])
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > SM4BlockSize {
+		return nil, errors.New("Invalid pkcs7 padding")
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("Invalid pkcs7 padding")
+		}
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
@@ -0,0 +1,208 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gm
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// DefaultSM2ID is the signer identifier GB/T 35276 recommends when none is agreed upon
+var DefaultSM2ID = []byte("1234567812345678")
+
+var (
+	sm2p256v1     *elliptic.CurveParams
+	sm2p256v1Once sync.Once
+	one           = big.NewInt(1)
+)
+
+// SM2P256V1 returns the curve sm2p256v1 of GB/T 32918.5. Its operations are
+// not constant time
+func SM2P256V1() elliptic.Curve {
+	sm2p256v1Once.Do(func() {
+		sm2p256v1 = &elliptic.CurveParams{Name: "SM2-P-256"}
+		sm2p256v1.P, _ = new(big.Int).SetString("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF", 16)
+		sm2p256v1.N, _ = new(big.Int).SetString("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFF7203DF6B21C6052B53BBF40939D54123", 16)
+		sm2p256v1.B, _ = new(big.Int).SetString("28E9FA9E9D9F5E344D5A9E4BCF6509A7F39789F515AB8F92DDBCBD414D940E93", 16)
+		sm2p256v1.Gx, _ = new(big.Int).SetString("32C4AE2C1F1981195F9904466A39C9948FE30BBFF2660BE1715A4589334C74C7", 16)
+		sm2p256v1.Gy, _ = new(big.Int).SetString("BC3736A2F4F6779C59BDCEE36B692153D0A9877CC62A474002DF32E52139F0A0", 16)
+		sm2p256v1.BitSize = 256
+	})
+	return sm2p256v1
+}
+
+// PublicKey is an SM2 public key
+type PublicKey struct {
+	elliptic.Curve
+	X, Y *big.Int
+}
+
+// PrivateKey is an SM2 private key
+type PrivateKey struct {
+	PublicKey
+	D *big.Int
+}
+
+type sm2Signature struct {
+	R, S *big.Int
+}
+
+// randScalar returns a random scalar in [1, n-2], as the signature needs 1+d to be invertible
+func randScalar(c elliptic.Curve, rand io.Reader) (*big.Int, error) {
+	params := c.Params()
+	b := make([]byte, params.BitSize/8+8)
+	if _, err := io.ReadFull(rand, b); err != nil {
+		return nil, err
+	}
+	k := new(big.Int).SetBytes(b)
+	nMinusTwo := new(big.Int).Sub(params.N, big.NewInt(2))
+	k.Mod(k, nMinusTwo)
+	k.Add(k, one)
+	return k, nil
+}
+
+// GenerateKey generates an SM2 key pair on sm2p256v1
+func GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	c := SM2P256V1()
+	d, err := randScalar(c, rand)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKey(d)
+}
+
+// NewPrivateKey returns the SM2 private key with scalar d
+func NewPrivateKey(d *big.Int) (*PrivateKey, error) {
+	c := SM2P256V1()
+	nMinusOne := new(big.Int).Sub(c.Params().N, one)
+	if d.Sign() <= 0 || d.Cmp(nMinusOne) >= 0 {
+		return nil, errors.New("Invalid SM2 private key, it must be in [1, n-2]")
+	}
+	priv := &PrivateKey{D: new(big.Int).Set(d)}
+	priv.Curve = c
+	priv.X, priv.Y = c.ScalarBaseMult(d.Bytes())
+	return priv, nil
+}
+
+// NewPublicKey returns the SM2 public key at the uncompressed point raw
+func NewPublicKey(raw []byte) (*PublicKey, error) {
+	c := SM2P256V1()
+	x, y := elliptic.Unmarshal(c, raw)
+	if x == nil {
+		return nil, errors.New("Invalid SM2 public key, it must be an uncompressed point of sm2p256v1")
+	}
+	return &PublicKey{Curve: c, X: x, Y: y}, nil
+}
+
+// Digest returns the value e = SM3(Z || msg) which SM2 signs, where Z binds the signer
+// identifier id and the public key
+func Digest(pub *PublicKey, id []byte, msg []byte) ([]byte, error) {
+	if len(id) >= 8192 {
+		return nil, fmt.Errorf("SM2 signer identifier is too long [%d]", len(id))
+	}
+	params := pub.Params()
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+	entl := len(id) * 8
+
+	h := NewSM3()
+	h.Write([]byte{byte(entl >> 8), byte(entl)})
+	h.Write(id)
+	for _, v := range []*big.Int{a, params.B, params.Gx, params.Gy, pub.X, pub.Y} {
+		h.Write(padTo32(v.Bytes()))
+	}
+	z := h.Sum(nil)
+
+	h.Reset()
+	h.Write(z)
+	h.Write(msg)
+	return h.Sum(nil), nil
+}
+
+func padTo32(b []byte) []byte {
+	if len(b) >= 32 {
+		return b
+	}
+	padded := make([]byte, 32)
+	copy(padded[32-len(b):], b)
+	return padded
+}
+
+// Sign signs the digest e, as computed by Digest, and returns the ASN.1 encoded signature
+func Sign(rand io.Reader, priv *PrivateKey, digest []byte) ([]byte, error) {
+	n := priv.Params().N
+	e := new(big.Int).SetBytes(digest)
+	dInv := new(big.Int).Add(priv.D, one)
+	if dInv.ModInverse(dInv, n) == nil {
+		return nil, errors.New("Invalid SM2 private key")
+	}
+
+	for {
+		k, err := randScalar(priv.Curve, rand)
+		if err != nil {
+			return nil, err
+		}
+		x1, _ := priv.ScalarBaseMult(k.Bytes())
+		r := new(big.Int).Add(e, x1)
+		r.Mod(r, n)
+		if r.Sign() == 0 || new(big.Int).Add(r, k).Cmp(n) == 0 {
+			continue
+		}
+		s := new(big.Int).Mul(r, priv.D)
+		s.Sub(k, s)
+		s.Mul(s, dInv)
+		s.Mod(s, n)
+		if s.Sign() == 0 {
+			continue
+		}
+		return asn1.Marshal(sm2Signature{r, s})
+	}
+}
+
+// Verify returns whether signature, ASN.1 encoded, is a valid signature of digest by pub
+func Verify(pub *PublicKey, signature []byte, digest []byte) (bool, error) {
+	sig := &sm2Signature{}
+	rest, err := asn1.Unmarshal(signature, sig)
+	if err != nil {
+		return false, fmt.Errorf("Failed unmarshalling signature [%s]", err)
+	}
+	if len(rest) != 0 {
+		return false, errors.New("Invalid signature, trailing data")
+	}
+
+	n := pub.Params().N
+	if sig.R == nil || sig.S == nil || sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Cmp(n) >= 0 {
+		return false, nil
+	}
+	t := new(big.Int).Add(sig.R, sig.S)
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		return false, nil
+	}
+	x1, y1 := pub.ScalarBaseMult(sig.S.Bytes())
+	x2, y2 := pub.ScalarMult(pub.X, pub.Y, t.Bytes())
+	x, _ := pub.Add(x1, y1, x2, y2)
+
+	r := new(big.Int).SetBytes(digest)
+	r.Add(r, x)
+	r.Mod(r, n)
+	return r.Cmp(sig.R) == 0, nil
+}
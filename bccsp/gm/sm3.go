@@ -0,0 +1,160 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gm
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// SM3Size is the size of an SM3 checksum in bytes
+const SM3Size = 32
+
+const sm3BlockSize = 64
+
+var sm3IV = [8]uint32{0x7380166f, 0x4914b2b9, 0x172442d7, 0xda8a0600, 0xa96f30bc, 0x163138aa, 0xe38dee4d, 0xb0fb0e4e}
+
+type sm3Digest struct {
+	h   [8]uint32
+	x   [sm3BlockSize]byte
+	nx  int
+	len uint64
+}
+
+// NewSM3 returns a hash.Hash computing the SM3 checksum
+func NewSM3() hash.Hash {
+	d := &sm3Digest{}
+	d.Reset()
+	return d
+}
+
+// SumSM3 returns the SM3 checksum of data
+func SumSM3(data []byte) []byte {
+	d := NewSM3()
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+func (d *sm3Digest) Reset() {
+	d.h = sm3IV
+	d.nx = 0
+	d.len = 0
+}
+
+func (d *sm3Digest) Size() int { return SM3Size }
+
+func (d *sm3Digest) BlockSize() int { return sm3BlockSize }
+
+func (d *sm3Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		p = p[c:]
+		if d.nx == sm3BlockSize {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+	}
+	for len(p) >= sm3BlockSize {
+		d.block(p[:sm3BlockSize])
+		p = p[sm3BlockSize:]
+	}
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+	return n, nil
+}
+
+func (d *sm3Digest) Sum(in []byte) []byte {
+	// work on a copy so that the caller can keep writing
+	c := *d
+	length := c.len << 3
+	var pad [sm3BlockSize + 8]byte
+	pad[0] = 0x80
+	padLen := 56 - int(c.len%sm3BlockSize)
+	if padLen <= 0 {
+		padLen += sm3BlockSize
+	}
+	binary.BigEndian.PutUint64(pad[padLen:], length)
+	c.Write(pad[:padLen+8])
+
+	var sum [SM3Size]byte
+	for i, v := range c.h {
+		binary.BigEndian.PutUint32(sum[i*4:], v)
+	}
+	return append(in, sum[:]...)
+}
+
+func rotl(x uint32, n uint) uint32 {
+	n %= 32
+	return x<<n | x>>(32-n)
+}
+
+func sm3P0(x uint32) uint32 { return x ^ rotl(x, 9) ^ rotl(x, 17) }
+
+func sm3P1(x uint32) uint32 { return x ^ rotl(x, 15) ^ rotl(x, 23) }
+
+// block compresses one 64 byte block into the state of d
+func (d *sm3Digest) block(p []byte) {
+	var w [68]uint32
+	var w1 [64]uint32
+	for j := 0; j < 16; j++ {
+		w[j] = binary.BigEndian.Uint32(p[j*4:])
+	}
+	for j := 16; j < 68; j++ {
+		w[j] = sm3P1(w[j-16]^w[j-9]^rotl(w[j-3], 15)) ^ rotl(w[j-13], 7) ^ w[j-6]
+	}
+	for j := 0; j < 64; j++ {
+		w1[j] = w[j] ^ w[j+4]
+	}
+
+	a, b, c, dd, e, f, g, h := d.h[0], d.h[1], d.h[2], d.h[3], d.h[4], d.h[5], d.h[6], d.h[7]
+	for j := 0; j < 64; j++ {
+		var t, ff, gg uint32
+		if j < 16 {
+			t = 0x79cc4519
+			ff = a ^ b ^ c
+			gg = e ^ f ^ g
+		} else {
+			t = 0x7a879d8a
+			ff = (a & b) | (a & c) | (b & c)
+			gg = (e & f) | (^e & g)
+		}
+		ss1 := rotl(rotl(a, 12)+e+rotl(t, uint(j)), 7)
+		ss2 := ss1 ^ rotl(a, 12)
+		tt1 := ff + dd + ss2 + w1[j]
+		tt2 := gg + h + ss1 + w[j]
+		dd = c
+		c = rotl(b, 9)
+		b = a
+		a = tt1
+		h = g
+		g = rotl(f, 19)
+		f = e
+		e = sm3P0(tt2)
+	}
+	d.h[0] ^= a
+	d.h[1] ^= b
+	d.h[2] ^= c
+	d.h[3] ^= dd
+	d.h[4] ^= e
+	d.h[5] ^= f
+	d.h[6] ^= g
+	d.h[7] ^= h
+}
@@ -0,0 +1,296 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gm
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	oidPublicKeyEC         = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveSM2       = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+	oidSignatureSM2WithSM3 = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}
+
+	// oidPublicKeyPlaceholder has the length of oidPublicKeyEC once encoded
+	// and no meaning to crypto/x509, which parses the certificates whose
+	// public key algorithm it replaces without their public key
+	oidPublicKeyPlaceholder = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 99}
+)
+
+type certificate struct {
+	TBSCertificate     asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+type tbsCertificate struct {
+	Raw                asn1.RawContent
+	Version            int `asn1:"optional,explicit,default:0,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Issuer             asn1.RawValue
+	Validity           asn1.RawValue
+	Subject            asn1.RawValue
+	PublicKey          publicKeyInfo
+	UniqueID           asn1.BitString   `asn1:"optional,tag:1"`
+	SubjectUniqueID    asn1.BitString   `asn1:"optional,tag:2"`
+	Extensions         []pkix.Extension `asn1:"optional,explicit,tag:3"`
+}
+
+type publicKeyInfo struct {
+	Raw       asn1.RawContent
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+func parseRawCertificate(der []byte) (*certificate, *tbsCertificate, error) {
+	cert := &certificate{}
+	if rest, err := asn1.Unmarshal(der, cert); err != nil {
+		return nil, nil, err
+	} else if len(rest) != 0 {
+		return nil, nil, errors.New("x509: trailing data")
+	}
+	tbs := &tbsCertificate{}
+	if _, err := asn1.Unmarshal(cert.TBSCertificate.FullBytes, tbs); err != nil {
+		return nil, nil, err
+	}
+	return cert, tbs, nil
+}
+
+func isSM2PublicKeyInfo(spki *publicKeyInfo) bool {
+	if !spki.Algorithm.Algorithm.Equal(oidPublicKeyEC) {
+		return false
+	}
+	curve := asn1.ObjectIdentifier{}
+	_, err := asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &curve)
+	return err == nil && curve.Equal(oidNamedCurveSM2)
+}
+
+// ParseCertificate parses a certificate from its DER encoding like x509.ParseCertificate,
+// which it calls, and parses the certificates whose public key is an SM2 key as well.
+// Their PublicKey is then a *PublicKey, and their PublicKeyAlgorithm is unknown
+func ParseCertificate(der []byte) (*x509.Certificate, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err == nil {
+		return cert, nil
+	}
+	_, tbs, rawErr := parseRawCertificate(der)
+	if rawErr != nil || !isSM2PublicKeyInfo(&tbs.PublicKey) {
+		return nil, err
+	}
+	pub, err := NewPublicKey(tbs.PublicKey.PublicKey.RightAlign())
+	if err != nil {
+		return nil, err
+	}
+
+	// crypto/x509 does not know sm2p256v1, so it parses the certificate with
+	// the public key algorithm replaced, every offset kept
+	oldOID, err := asn1.Marshal(oidPublicKeyEC)
+	if err != nil {
+		return nil, err
+	}
+	newOID, err := asn1.Marshal(oidPublicKeyPlaceholder)
+	if err != nil {
+		return nil, err
+	}
+	spkiStart := bytes.Index(der, tbs.PublicKey.Raw)
+	replaced := append([]byte(nil), der...)
+	spki := replaced[spkiStart : spkiStart+len(tbs.PublicKey.Raw)]
+	copy(spki[bytes.Index(spki, oldOID):], newOID)
+
+	cert, err = x509.ParseCertificate(replaced)
+	if err != nil {
+		return nil, err
+	}
+	cert.Raw = der
+	cert.RawTBSCertificate = tbs.Raw
+	cert.RawSubjectPublicKeyInfo = tbs.PublicKey.Raw
+	cert.PublicKey = pub
+	return cert, nil
+}
+
+// IsSM2Certificate returns whether the public key of cert, as parsed by ParseCertificate,
+// or the signature of its issuer is an SM2 one
+func IsSM2Certificate(cert *x509.Certificate) bool {
+	if _, ok := cert.PublicKey.(*PublicKey); ok {
+		return true
+	}
+	raw, _, err := parseRawCertificate(cert.Raw)
+	return err == nil && raw.SignatureAlgorithm.Algorithm.Equal(oidSignatureSM2WithSM3)
+}
+
+// CheckSignatureFrom verifies that the signature on cert is a valid signature from parent,
+// which must be a CA. The SM2 with SM3 signatures are verified with DefaultSM2ID as the
+// identifier of parent, the others by crypto/x509
+func CheckSignatureFrom(cert, parent *x509.Certificate) error {
+	raw, _, err := parseRawCertificate(cert.Raw)
+	if err != nil {
+		return err
+	}
+	if !raw.SignatureAlgorithm.Algorithm.Equal(oidSignatureSM2WithSM3) {
+		return cert.CheckSignatureFrom(parent)
+	}
+	if !parent.BasicConstraintsValid || !parent.IsCA {
+		return errors.New("x509: parent certificate cannot sign this kind of certificate")
+	}
+	if parent.KeyUsage != 0 && parent.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return errors.New("x509: parent certificate cannot sign this kind of certificate")
+	}
+	pub, ok := parent.PublicKey.(*PublicKey)
+	if !ok {
+		return errors.New("x509: SM2 signature from a parent certificate without an SM2 public key")
+	}
+	digest, err := Digest(pub, DefaultSM2ID, cert.RawTBSCertificate)
+	if err != nil {
+		return err
+	}
+	valid, err := Verify(pub, cert.Signature, digest)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("x509: SM2 verification failure")
+	}
+	return nil
+}
+
+// VerifyChain returns a chain from cert to one of roots through intermediates, in which
+// every certificate is signed by the next, as checked by CheckSignatureFrom, and valid
+// at now. Unlike x509.Certificate.Verify, it verifies the SM2 signatures, but not the
+// extended key usages nor the name constraints of the certificates
+func VerifyChain(cert *x509.Certificate, roots, intermediates []*x509.Certificate, now time.Time) ([]*x509.Certificate, error) {
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, x509.CertificateInvalidError{Cert: cert, Reason: x509.Expired}
+	}
+	for _, root := range roots {
+		if bytes.Equal(cert.Raw, root.Raw) {
+			return []*x509.Certificate{cert}, nil
+		}
+	}
+	return verifyChain([]*x509.Certificate{cert}, roots, intermediates, now)
+}
+
+func verifyChain(chain []*x509.Certificate, roots, intermediates []*x509.Certificate, now time.Time) ([]*x509.Certificate, error) {
+	cert := chain[len(chain)-1]
+	for _, root := range roots {
+		if isParent(cert, root, now) {
+			return append(chain, root), nil
+		}
+	}
+	for _, intermediate := range intermediates {
+		if inChain(chain, intermediate) || !isParent(cert, intermediate, now) {
+			continue
+		}
+		if verified, err := verifyChain(append(chain, intermediate), roots, intermediates, now); err == nil {
+			return verified, nil
+		}
+	}
+	return nil, x509.UnknownAuthorityError{Cert: cert}
+}
+
+func isParent(cert, parent *x509.Certificate, now time.Time) bool {
+	if !bytes.Equal(cert.RawIssuer, parent.RawSubject) {
+		return false
+	}
+	if len(cert.AuthorityKeyId) > 0 && len(parent.SubjectKeyId) > 0 && !bytes.Equal(cert.AuthorityKeyId, parent.SubjectKeyId) {
+		return false
+	}
+	if now.Before(parent.NotBefore) || now.After(parent.NotAfter) {
+		return false
+	}
+	return CheckSignatureFrom(cert, parent) == nil
+}
+
+func inChain(chain []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range chain {
+		if bytes.Equal(c.Raw, cert.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateCertificate creates a certificate from template like x509.CreateCertificate, for
+// the SM2 public key pub, signed with SM2 and SM3 by priv, the key of parent. The subject
+// key identifier of the CA certificates defaults to the SHA-1 hash of pub
+func CreateCertificate(template, parent *x509.Certificate, pub *PublicKey, priv *PrivateKey) ([]byte, error) {
+	// crypto/x509 creates the certificate for a placeholder key, whose public
+	// key and signature are then replaced
+	placeholder, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	point := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+	tmpl := *template
+	if tmpl.IsCA && len(tmpl.SubjectKeyId) == 0 {
+		ski := sha1.Sum(point)
+		tmpl.SubjectKeyId = ski[:]
+	}
+	issuer := *parent
+	if parent == template {
+		issuer = tmpl
+	}
+	issuer.PublicKey = nil
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &issuer, &placeholder.PublicKey, placeholder)
+	if err != nil {
+		return nil, err
+	}
+	_, tbs, err := parseRawCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	curve, err := asn1.Marshal(oidNamedCurveSM2)
+	if err != nil {
+		return nil, err
+	}
+	tbs.Raw = nil
+	tbs.SignatureAlgorithm = pkix.AlgorithmIdentifier{Algorithm: oidSignatureSM2WithSM3}
+	tbs.PublicKey = publicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyEC, Parameters: asn1.RawValue{FullBytes: curve}},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	}
+	tbsBytes, err := asn1.Marshal(*tbs)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := Digest(&priv.PublicKey, DefaultSM2ID, tbsBytes)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := Sign(rand.Reader, priv, digest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed signing certificate [%s]", err)
+	}
+	return asn1.Marshal(certificate{
+		TBSCertificate:     asn1.RawValue{FullBytes: tbsBytes},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSignatureSM2WithSM3},
+		SignatureValue:     asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)},
+	})
+}
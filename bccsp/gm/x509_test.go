@@ -0,0 +1,143 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gm
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+func newTestCertificate(t *testing.T, name string, isCA bool, parent *x509.Certificate, parentKey *PrivateKey) (*x509.Certificate, *PrivateKey) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating SM2 key [%s]", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name, OrganizationalUnit: []string{"COP"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, priv
+	}
+	der, err := CreateCertificate(template, parent, &priv.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Failed creating certificate [%s]", err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed parsing certificate [%s]", err)
+	}
+	return cert, priv
+}
+
+func TestSM2Certificate(t *testing.T) {
+	root, rootKey := newTestCertificate(t, "root", true, nil, nil)
+	intermediate, intermediateKey := newTestCertificate(t, "intermediate", true, root, rootKey)
+	leaf, leafKey := newTestCertificate(t, "leaf", false, intermediate, intermediateKey)
+
+	if pub, ok := leaf.PublicKey.(*PublicKey); !ok || pub.X.Cmp(leafKey.X) != 0 || pub.Y.Cmp(leafKey.Y) != 0 {
+		t.Fatalf("Certificate should carry its SM2 public key")
+	}
+	if leaf.Subject.CommonName != "leaf" || leaf.Issuer.CommonName != "intermediate" || leaf.IsCA {
+		t.Fatalf("Certificate fields should have been parsed: %v", leaf.Subject)
+	}
+	if !IsSM2Certificate(leaf) || !IsSM2Certificate(root) {
+		t.Fatalf("Certificates should be SM2 ones")
+	}
+
+	chain, err := VerifyChain(leaf, []*x509.Certificate{root}, []*x509.Certificate{intermediate}, time.Now())
+	if err != nil {
+		t.Fatalf("Chain should have been verified [%s]", err)
+	}
+	if len(chain) != 3 || chain[1] != intermediate || chain[2] != root {
+		t.Fatalf("Unexpected chain %v", chain)
+	}
+	if _, err := VerifyChain(leaf, []*x509.Certificate{root}, nil, time.Now()); err == nil {
+		t.Fatalf("Chain without the intermediate should not be verified")
+	}
+	if _, err := VerifyChain(leaf, []*x509.Certificate{root}, []*x509.Certificate{intermediate}, time.Now().Add(2*time.Hour)); err == nil {
+		t.Fatalf("Expired certificate should not be verified")
+	}
+
+	// a certificate signed by another key of the same name
+	other, otherKey := newTestCertificate(t, "intermediate", true, root, rootKey)
+	forged, _ := newTestCertificate(t, "leaf", false, other, otherKey)
+	if _, err := VerifyChain(forged, []*x509.Certificate{root}, []*x509.Certificate{intermediate}, time.Now()); err == nil {
+		t.Fatalf("Certificate of another intermediate should not be verified")
+	}
+	if err := CheckSignatureFrom(leaf, root); err == nil {
+		t.Fatalf("Certificate should not be signed by the root")
+	}
+	if err := CheckSignatureFrom(leaf, leaf); err == nil {
+		t.Fatalf("Certificate of a non CA should not sign")
+	}
+
+	tampered := append([]byte(nil), leaf.Raw...)
+	i := bytes.Index(tampered, []byte("leaf"))
+	copy(tampered[i:], "lead")
+	tamperedCert, err := ParseCertificate(tampered)
+	if err != nil {
+		t.Fatalf("Failed parsing certificate [%s]", err)
+	}
+	if err := CheckSignatureFrom(tamperedCert, intermediate); err == nil {
+		t.Fatalf("Tampered certificate should not be verified")
+	}
+}
+
+func TestBCCSPX509PublicKeyImport(t *testing.T) {
+	csp := newTestBCCSP(t)
+	cert, priv := newTestCertificate(t, "leaf", false, nil, nil)
+
+	k, err := csp.KeyImport(cert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing the key of the certificate [%s]", err)
+	}
+	if !bytes.Equal(k.SKI(), (&sm2PrivateKey{priv}).SKI()) {
+		t.Fatalf("Imported key should be the SM2 key of the certificate")
+	}
+
+	digest, err := Digest(&priv.PublicKey, DefaultSM2ID, []byte("message"))
+	if err != nil {
+		t.Fatalf("Failed computing digest [%s]", err)
+	}
+	signature, err := Sign(rand.Reader, priv, digest)
+	if err != nil {
+		t.Fatalf("Failed signing [%s]", err)
+	}
+	valid, errs := csp.(bccsp.BatchVerifier).VerifyBatch([]bccsp.VerifyItem{
+		{Key: k, Signature: signature, Digest: digest},
+		{Key: k, Signature: signature, Digest: []byte("another digest")},
+	})
+	if !valid[0] || valid[1] || errs[0] != nil || errs[1] != nil {
+		t.Fatalf("Unexpected batch verification %v %v", valid, errs)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bccsp
+
+const (
+	// SM2 is the Chinese national standard elliptic curve signature algorithm
+	// GB/T 32918 over the sm2p256v1 curve (key gen, import, sign, verify)
+	SM2 = "SM2"
+
+	// SM3 is the Chinese national standard hash function GB/T 32905
+	SM3 = "SM3"
+
+	// SM4 is the Chinese national standard block cipher GB/T 32907 (key gen, import, encrypt, decrypt)
+	SM4 = "SM4"
+)
+
+// SM2KeyGenOpts contains options for SM2 key generation.
+type SM2KeyGenOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key generation algorithm identifier (to be used).
+func (opts *SM2KeyGenOpts) Algorithm() string {
+	return SM2
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *SM2KeyGenOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// SM2PublicKeyImportOpts contains options for SM2 public key importation
+// from an uncompressed curve point.
+type SM2PublicKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *SM2PublicKeyImportOpts) Algorithm() string {
+	return SM2
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *SM2PublicKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// SM2PrivateKeyImportOpts contains options for SM2 private key importation
+// from its big-endian scalar.
+type SM2PrivateKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *SM2PrivateKeyImportOpts) Algorithm() string {
+	return SM2
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *SM2PrivateKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// SM3Opts contains options relating to SM3.
+type SM3Opts struct {
+}
+
+// Algorithm returns the hash algorithm identifier (to be used).
+func (opts *SM3Opts) Algorithm() string {
+	return SM3
+}
+
+// SM4KeyGenOpts contains options for SM4 key generation.
+type SM4KeyGenOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key generation algorithm identifier (to be used).
+func (opts *SM4KeyGenOpts) Algorithm() string {
+	return SM4
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *SM4KeyGenOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// SM4ImportKeyOpts contains options for importing SM4 keys.
+type SM4ImportKeyOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *SM4ImportKeyOpts) Algorithm() string {
+	return SM4
+}
+
+// Ephemeral returns true if the key generated has to be ephemeral,
+// false otherwise.
+func (opts *SM4ImportKeyOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// SM4CBCPKCS7ModeOpts contains options for SM4 encryption in CBC mode
+// with PKCS7 padding.
+type SM4CBCPKCS7ModeOpts struct{}
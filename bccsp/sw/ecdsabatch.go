@@ -0,0 +1,161 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"runtime"
+	"sync"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+// minVerificationSet is the number of signatures of a key under which its
+// verification set is not split to be verified by several goroutines.
+const minVerificationSet = 4
+
+// ecdsaVerificationSet is the set of the signatures of a batch made by the same
+// ECDSA key. The key is unwrapped once for the set, the verification of the
+// signatures shares an ecdsaPrecomputation per goroutine, and a signature which
+// appears more than once in the set is only verified once.
+type ecdsaVerificationSet struct {
+	pubKey *ecdsa.PublicKey
+
+	// items are the indexes of the distinct signatures of the set in the batch
+	items []int
+
+	// duplicates maps the indexes of the repeated signatures to the index
+	// of their first occurrence in the batch
+	duplicates map[int]int
+
+	seen map[string]int
+}
+
+// VerifyBatch verifies the signatures of items. The ECDSA signatures are
+// grouped by key into verification sets, the sets being verified in parallel
+// and the large ones split across goroutines, each part of a set amortizing
+// the inversions of the s of its signatures. The other signatures are
+// verified one at a time by Verify.
+func (csp *impl) VerifyBatch(items []bccsp.VerifyItem) (valid []bool, errs []error) {
+	valid = make([]bool, len(items))
+	errs = make([]error, len(items))
+
+	var jobs []func()
+	var sets []*ecdsaVerificationSet
+	setsByKey := map[string]*ecdsaVerificationSet{}
+	for i, item := range items {
+		pubKey := ecdsaPublicKeyOf(item.Key)
+		if pubKey == nil || len(item.Signature) == 0 || len(item.Digest) == 0 {
+			i := i
+			jobs = append(jobs, func() {
+				valid[i], errs[i] = csp.Verify(items[i].Key, items[i].Signature, items[i].Digest, items[i].Opts)
+			})
+			continue
+		}
+
+		keyID := string(elliptic.Marshal(pubKey.Curve, pubKey.X, pubKey.Y))
+		set, ok := setsByKey[keyID]
+		if !ok {
+			set = &ecdsaVerificationSet{pubKey: pubKey, duplicates: map[int]int{}, seen: map[string]int{}}
+			setsByKey[keyID] = set
+			sets = append(sets, set)
+		}
+		sigID := string(item.Digest) + string(item.Signature)
+		if first, ok := set.seen[sigID]; ok {
+			set.duplicates[i] = first
+			continue
+		}
+		set.seen[sigID] = i
+		set.items = append(set.items, i)
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	for _, set := range sets {
+		chunk := (len(set.items) + workers - 1) / workers
+		if chunk < minVerificationSet {
+			chunk = minVerificationSet
+		}
+		for start := 0; start < len(set.items); start += chunk {
+			end := start + chunk
+			if end > len(set.items) {
+				end = len(set.items)
+			}
+			set, indexes := set, set.items[start:end]
+			jobs = append(jobs, func() {
+				setItems := make([]bccsp.VerifyItem, len(indexes))
+				for j, i := range indexes {
+					setItems[j] = items[i]
+				}
+				precomputation := precomputeECDSA(set.pubKey, setItems)
+				for j, i := range indexes {
+					valid[i], errs[i] = precomputation.verify(j, items[i].Digest)
+				}
+			})
+		}
+	}
+
+	runJobs(workers, jobs)
+
+	for _, set := range sets {
+		for i, first := range set.duplicates {
+			valid[i], errs[i] = valid[first], errs[first]
+		}
+	}
+	return valid, errs
+}
+
+// ecdsaPublicKeyOf returns the ECDSA public key of k, or nil if k is not an ECDSA key
+func ecdsaPublicKeyOf(k bccsp.Key) *ecdsa.PublicKey {
+	switch key := k.(type) {
+	case *ecdsaPrivateKey:
+		return &key.privKey.PublicKey
+	case *ecdsaPublicKey:
+		return key.pubKey
+	}
+	return nil
+}
+
+// runJobs runs the jobs from a pool of at most workers goroutines
+func runJobs(workers int, jobs []func()) {
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+	if workers <= 1 {
+		for _, job := range jobs {
+			job()
+		}
+		return
+	}
+
+	work := make(chan func(), len(jobs))
+	for _, job := range jobs {
+		work <- job
+	}
+	close(work)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range work {
+				job()
+			}
+		}()
+	}
+	wg.Wait()
+}
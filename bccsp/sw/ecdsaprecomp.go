@@ -0,0 +1,144 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+// combinedMult is implemented by the curves of crypto/elliptic which compute
+// baseScalar*G + scalar*P faster than with two multiplications, as P-256 does
+type combinedMult interface {
+	CombinedMult(bigX, bigY *big.Int, baseScalar, scalar []byte) (x, y *big.Int)
+}
+
+// ecdsaPrecomputation is what the verification of the signatures of a key
+// shares: the key and its curve parameters, looked up once, and the inverses
+// of the s of all the signatures, computed with a single modular inversion
+// (Montgomery's trick) instead of one per signature
+type ecdsaPrecomputation struct {
+	pubKey *ecdsa.PublicKey
+	n      *big.Int
+
+	r, sInv []*big.Int
+	errs    []error
+}
+
+// precomputeECDSA unmarshals and checks the signatures of items made by
+// pubKey, and inverts their s at once. A signature which cannot be verified
+// gets its error, as verifyECDSA would return it
+func precomputeECDSA(pubKey *ecdsa.PublicKey, items []bccsp.VerifyItem) *ecdsaPrecomputation {
+	p := &ecdsaPrecomputation{
+		pubKey: pubKey,
+		n:      pubKey.Params().N,
+		r:      make([]*big.Int, len(items)),
+		sInv:   make([]*big.Int, len(items)),
+		errs:   make([]error, len(items)),
+	}
+	halfOrder, ok := curveHalfOrders[pubKey.Curve]
+
+	// products[i] is the product of the s of the valid signatures before i
+	products := make([]*big.Int, len(items))
+	acc := big.NewInt(1)
+	for i, item := range items {
+		if !ok {
+			p.errs[i] = fmt.Errorf("Curve not recognized [%s]", pubKey.Curve)
+			continue
+		}
+		r, s, err := unmarshalECDSASignature(item.Signature)
+		if err != nil {
+			p.errs[i] = fmt.Errorf("Failed unmashalling signature [%s]", err)
+			continue
+		}
+		if s.Cmp(halfOrder) == 1 {
+			p.errs[i] = fmt.Errorf("Invalid S. Must be smaller than half the order [%s][%s].", s, halfOrder)
+			continue
+		}
+		if r.Cmp(p.n) >= 0 {
+			// ecdsa.Verify rejects it without error
+			continue
+		}
+		p.r[i] = r
+		p.sInv[i] = s
+		products[i] = new(big.Int).Set(acc)
+		acc.Mul(acc, s)
+		acc.Mod(acc, p.n)
+	}
+
+	// n is prime and every s is in [1, n/2], so acc is invertible
+	inv := new(big.Int).ModInverse(acc, p.n)
+	for i := len(items) - 1; i >= 0; i-- {
+		s := p.sInv[i]
+		if s == nil {
+			continue
+		}
+		p.sInv[i] = new(big.Int).Mul(inv, products[i])
+		p.sInv[i].Mod(p.sInv[i], p.n)
+		inv.Mul(inv, s)
+		inv.Mod(inv, p.n)
+	}
+	return p
+}
+
+// verify verifies the signature of the item i of the precomputation
+func (p *ecdsaPrecomputation) verify(i int, digest []byte) (bool, error) {
+	if p.errs[i] != nil {
+		return false, p.errs[i]
+	}
+	if p.r[i] == nil {
+		return false, nil
+	}
+	u1 := hashToInt(digest, p.n)
+	u1.Mul(u1, p.sInv[i])
+	u1.Mod(u1, p.n)
+	u2 := new(big.Int).Mul(p.r[i], p.sInv[i])
+	u2.Mod(u2, p.n)
+
+	curve := p.pubKey.Curve
+	var x, y *big.Int
+	if cm, ok := curve.(combinedMult); ok {
+		x, y = cm.CombinedMult(p.pubKey.X, p.pubKey.Y, u1.Bytes(), u2.Bytes())
+	} else {
+		x1, y1 := curve.ScalarBaseMult(u1.Bytes())
+		x2, y2 := curve.ScalarMult(p.pubKey.X, p.pubKey.Y, u2.Bytes())
+		x, y = curve.Add(x1, y1, x2, y2)
+	}
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return false, nil
+	}
+	x.Mod(x, p.n)
+	return x.Cmp(p.r[i]) == 0, nil
+}
+
+// hashToInt converts a digest to an integer as crypto/ecdsa does, keeping
+// its leftmost bits up to the size of n
+func hashToInt(digest []byte, n *big.Int) *big.Int {
+	orderBits := n.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(digest) > orderBytes {
+		digest = digest[:orderBytes]
+	}
+	e := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - orderBits; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
@@ -1920,3 +1920,56 @@ func getCryptoHashIndex(t *testing.T) crypto.Hash {
 
 	return crypto.SHA3_256
 }
+
+func TestECDSAVerifyBatch(t *testing.T) {
+	batchVerifier, ok := currentBCCSP.(bccsp.BatchVerifier)
+	if !ok {
+		t.Fatal("The software-based BCCSP should verify batches")
+	}
+
+	var items []bccsp.VerifyItem
+	for i := 0; i < 2; i++ {
+		k, err := currentBCCSP.KeyGen(&bccsp.ECDSAKeyGenOpts{Temporary: true})
+		if err != nil {
+			t.Fatalf("Failed generating ECDSA key [%s]", err)
+		}
+		pk, err := k.PublicKey()
+		if err != nil {
+			t.Fatalf("Failed getting corresponding public key [%s]", err)
+		}
+		for j := 0; j < 2*minVerificationSet; j++ {
+			digest, err := currentBCCSP.Hash([]byte(fmt.Sprintf("Hello World %d", j)), &bccsp.SHAOpts{})
+			if err != nil {
+				t.Fatalf("Failed computing HASH [%s]", err)
+			}
+			signature, err := currentBCCSP.Sign(k, digest, nil)
+			if err != nil {
+				t.Fatalf("Failed generating ECDSA signature [%s]", err)
+			}
+			items = append(items, bccsp.VerifyItem{Key: pk, Signature: signature, Digest: digest})
+		}
+	}
+	// a repeated signature, a signature of another digest, a malformed one
+	// and an empty one, left to Verify
+	items = append(items, items[0])
+	items = append(items, bccsp.VerifyItem{Key: items[1].Key, Signature: items[1].Signature, Digest: items[2].Digest})
+	items = append(items, bccsp.VerifyItem{Key: items[1].Key, Signature: []byte{0, 1, 2}, Digest: items[1].Digest})
+	items = append(items, bccsp.VerifyItem{Key: items[1].Key, Digest: items[1].Digest})
+
+	valid, errs := batchVerifier.VerifyBatch(items)
+	n := len(items)
+	for i := 0; i < n-3; i++ {
+		if errs[i] != nil || !valid[i] {
+			t.Fatalf("Signature %d should be valid, got [%t][%v]", i, valid[i], errs[i])
+		}
+	}
+	if errs[n-3] != nil || valid[n-3] {
+		t.Fatalf("Signature of another digest should be invalid, got [%t][%v]", valid[n-3], errs[n-3])
+	}
+	if errs[n-2] == nil || valid[n-2] {
+		t.Fatal("Malformed signature should fail")
+	}
+	if errs[n-1] == nil || valid[n-1] {
+		t.Fatal("Empty signature should fail")
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capabilities
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// A capability gates a behavior which not every peer and orderer version has,
+// and which the peers and orderers of a channel must all have or all lack not
+// to diverge, such as a new validation rule. A capability is enabled on a
+// channel by listing it in the Capabilities value of its channel group, once
+// every organization of the channel has upgraded to a version supporting it.
+// A version which does not support a capability enabled on a channel refuses
+// the config of the channel rather than process it with a different behavior
+const (
+	// ReadSetValidation rejects the config updates whose read set is stale
+	ReadSetValidation = "ReadSetValidation"
+
+	// NamespaceValidation validates every namespace a transaction writes to
+	// with the VSCC and policy of its chaincode, invalidating the writes to
+	// namespaces of no chaincode, rather than the invoked chaincode only
+	NamespaceValidation = "NamespaceValidation"
+
+	// TxsFilterRunLength run-length encodes the transactions filter of the
+	// blocks when it is shorter than their bit array, which the clients reading
+	// the TRANSACTIONS_FILTER metadata as a bit array cannot read
+	TxsFilterRunLength = "TxsFilterRunLength"
+)
+
+// supported lists the capabilities this version supports
+var supported = map[string]struct{}{
+	ReadSetValidation:   struct{}{},
+	NamespaceValidation: struct{}{},
+	TxsFilterRunLength:  struct{}{},
+}
+
+// Provider reports the capabilities enabled on a channel
+type Provider struct {
+	enabled map[string]struct{}
+}
+
+// NewProvider creates a Provider of the capabilities of capabilities, which
+// may be nil if the channel has none enabled
+func NewProvider(capabilities *cb.Capabilities) *Provider {
+	p := &Provider{enabled: make(map[string]struct{})}
+	if capabilities != nil {
+		for _, capability := range capabilities.Capabilities {
+			p.enabled[capability] = struct{}{}
+		}
+	}
+	return p
+}
+
+// Supported returns an error naming the capabilities enabled which this
+// version does not support, if any
+func (p *Provider) Supported() error {
+	var unsupported []string
+	for capability := range p.enabled {
+		if _, ok := supported[capability]; !ok {
+			unsupported = append(unsupported, capability)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return fmt.Errorf("Channel requires capabilities [%s] which this version does not support, it must be upgraded", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// Enabled returns whether capability is enabled
+func (p *Provider) Enabled(capability string) bool {
+	_, ok := p.enabled[capability]
+	return ok
+}
+
+// ReadSetValidation returns whether the config updates whose read set is stale
+// are rejected
+func (p *Provider) ReadSetValidation() bool {
+	return p.Enabled(ReadSetValidation)
+}
+
+// NamespaceValidation returns whether every namespace a transaction writes to
+// is validated, rather than the invoked chaincode only
+func (p *Provider) NamespaceValidation() bool {
+	return p.Enabled(NamespaceValidation)
+}
+
+// TxsFilterRunLength returns whether the transactions filter of the blocks is
+// run-length encoded when it is shorter than their bit array
+func (p *Provider) TxsFilterRunLength() bool {
+	return p.Enabled(TxsFilterRunLength)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capabilities
+
+import (
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoCapabilities(t *testing.T) {
+	p := NewProvider(nil)
+	assert.NoError(t, p.Supported())
+	assert.False(t, p.ReadSetValidation())
+	assert.False(t, p.NamespaceValidation())
+	assert.False(t, p.TxsFilterRunLength())
+}
+
+func TestCapabilities(t *testing.T) {
+	p := NewProvider(&cb.Capabilities{Capabilities: []string{ReadSetValidation, NamespaceValidation, TxsFilterRunLength}})
+	assert.NoError(t, p.Supported())
+	assert.True(t, p.ReadSetValidation())
+	assert.True(t, p.NamespaceValidation())
+	assert.True(t, p.TxsFilterRunLength())
+	assert.True(t, p.Enabled(ReadSetValidation))
+	assert.False(t, p.Enabled("Other"))
+}
+
+func TestUnsupportedCapabilities(t *testing.T) {
+	p := NewProvider(&cb.Capabilities{Capabilities: []string{"Foo", ReadSetValidation, "Bar"}})
+	err := p.Supported()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "[Bar, Foo]", "The error should name the unsupported capabilities")
+	assert.True(t, p.ReadSetValidation(), "The supported capabilities should still be reported")
+}
@@ -92,6 +92,20 @@ func SignedByMspMember(mspId string) *cb.SignaturePolicyEnvelope {
 	return p
 }
 
+// SignedByMspRole creates a SignaturePolicyEnvelope requiring 1 signature
+// from any identity of the specified MSP with the given role, such as a peer
+func SignedByMspRole(mspId string, role cb.MSPRole_MSPRoleType) *cb.SignaturePolicyEnvelope {
+	principal := &cb.MSPPrincipal{
+		PrincipalClassification: cb.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&cb.MSPRole{Role: role, MspIdentifier: mspId})}
+
+	return &cb.SignaturePolicyEnvelope{
+		Version:    0,
+		Policy:     NOutOf(1, []*cb.SignaturePolicy{SignedBy(0)}),
+		Identities: []*cb.MSPPrincipal{principal},
+	}
+}
+
 // And is a convenience method which utilizes NOutOf to produce And equivalent behavior
 func And(lhs, rhs *cb.SignaturePolicy) *cb.SignaturePolicy {
 	return NOutOf(2, []*cb.SignaturePolicy{lhs, rhs})
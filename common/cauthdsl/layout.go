@@ -0,0 +1,178 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cauthdsl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// maxLayouts bounds the number of layouts kept for each gate of a policy, the
+// smallest ones being kept
+const maxLayouts = 64
+
+// SatisfyingLayout returns how many signers of each MSP, among the signers
+// available by MSP ID, a smallest set of signatures satisfying the policy needs.
+// The principals of the policy must be MSP roles, the signers being assumed to
+// satisfy the roles of their MSP
+func SatisfyingLayout(policy *cb.SignaturePolicyEnvelope, available map[string]int) (map[string]int, error) {
+	mspIDs := make([]string, len(policy.Identities))
+	for i, principal := range policy.Identities {
+		if principal.PrincipalClassification != cb.MSPPrincipal_ROLE {
+			return nil, fmt.Errorf("Principal %d is not an MSP role", i)
+		}
+		role := &cb.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err != nil {
+			return nil, fmt.Errorf("Error unmarshaling principal %d: %s", i, err)
+		}
+		mspIDs[i] = role.MspIdentifier
+	}
+
+	candidates, err := layouts(policy.Policy, mspIDs, available)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, errors.New("No layout of the available signers satisfies the policy")
+	}
+	return candidates[0], nil
+}
+
+// layouts returns the smallest layouts of the available signers satisfying the policy
+func layouts(policy *cb.SignaturePolicy, mspIDs []string, available map[string]int) ([]map[string]int, error) {
+	switch t := policy.Type.(type) {
+	case *cb.SignaturePolicy_From:
+		children := make([][]map[string]int, len(t.From.Policies))
+		for i, policy := range t.From.Policies {
+			var err error
+			if children[i], err = layouts(policy, mspIDs, available); err != nil {
+				return nil, err
+			}
+		}
+
+		// merge the layouts of each choice of N of the policies
+		var result []map[string]int
+		var choose func(next int, n int32, layout map[string]int)
+		choose = func(next int, n int32, layout map[string]int) {
+			if n <= 0 {
+				result = append(result, layout)
+				return
+			}
+			for i := next; i+int(n) <= len(children); i++ {
+				for _, child := range children[i] {
+					if merged := mergeLayouts(layout, child); isAvailable(merged, available) {
+						choose(i+1, n-1, merged)
+					}
+				}
+			}
+		}
+		choose(0, t.From.N, map[string]int{})
+		return pruneLayouts(result), nil
+	case *cb.SignaturePolicy_SignedBy:
+		if t.SignedBy < 0 || t.SignedBy >= int32(len(mspIDs)) {
+			return nil, fmt.Errorf("Identity index out of range, requested %d, but identies length is %d", t.SignedBy, len(mspIDs))
+		}
+		layout := map[string]int{mspIDs[t.SignedBy]: 1}
+		if !isAvailable(layout, available) {
+			return nil, nil
+		}
+		return []map[string]int{layout}, nil
+	default:
+		return nil, fmt.Errorf("Unknown type: %T:%v", t, t)
+	}
+}
+
+func mergeLayouts(a, b map[string]int) map[string]int {
+	merged := make(map[string]int, len(a)+len(b))
+	for mspID, n := range a {
+		merged[mspID] += n
+	}
+	for mspID, n := range b {
+		merged[mspID] += n
+	}
+	return merged
+}
+
+func isAvailable(layout map[string]int, available map[string]int) bool {
+	for mspID, n := range layout {
+		if n > available[mspID] {
+			return false
+		}
+	}
+	return true
+}
+
+// contains returns whether layout a needs at least the signers layout b needs
+func contains(a, b map[string]int) bool {
+	for mspID, n := range b {
+		if a[mspID] < n {
+			return false
+		}
+	}
+	return true
+}
+
+func layoutSize(layout map[string]int) int {
+	size := 0
+	for _, n := range layout {
+		size += n
+	}
+	return size
+}
+
+func layoutKey(layout map[string]int) string {
+	keys := make([]string, 0, len(layout))
+	for mspID, n := range layout {
+		keys = append(keys, fmt.Sprintf("%s:%d", mspID, n))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// pruneLayouts sorts the layouts by size, dropping those containing another
+// one, and keeps the maxLayouts first ones
+func pruneLayouts(candidates []map[string]int) []map[string]int {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if si, sj := layoutSize(candidates[i]), layoutSize(candidates[j]); si != sj {
+			return si < sj
+		}
+		return layoutKey(candidates[i]) < layoutKey(candidates[j])
+	})
+
+	var pruned []map[string]int
+	for _, candidate := range candidates {
+		redundant := false
+		for _, layout := range pruned {
+			if contains(candidate, layout) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			pruned = append(pruned, candidate)
+			if len(pruned) == maxLayouts {
+				break
+			}
+		}
+	}
+	return pruned
+}
@@ -0,0 +1,66 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cauthdsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSatisfyingLayout(t *testing.T) {
+	available := map[string]int{"A": 2, "B": 1, "C": 0}
+
+	for _, test := range []struct {
+		policy string
+		layout map[string]int
+	}{
+		{"OR('A.member', 'B.member')", map[string]int{"A": 1}},
+		{"OR('C.member', 'B.member')", map[string]int{"B": 1}},
+		{"AND('A.member', 'B.member')", map[string]int{"A": 1, "B": 1}},
+		{"AND('A.member', 'A.member')", map[string]int{"A": 2}},
+		{"OR(AND('A.member', 'B.member'), 'A.member')", map[string]int{"A": 1}},
+		{"OR(AND('A.member', 'C.member'), AND('B.member', 'A.member'))", map[string]int{"A": 1, "B": 1}},
+	} {
+		policy, err := FromString(test.policy)
+		assert.NoError(t, err)
+		layout, err := SatisfyingLayout(policy, available)
+		assert.NoError(t, err, test.policy)
+		assert.Equal(t, test.layout, layout, test.policy)
+	}
+
+	layout, err := SatisfyingLayout(AcceptAllPolicy, available)
+	assert.NoError(t, err)
+	assert.Empty(t, layout)
+}
+
+func TestSatisfyingLayoutUnsatisfiable(t *testing.T) {
+	available := map[string]int{"A": 1, "B": 1}
+
+	for _, policy := range []string{
+		"AND('A.member', 'C.member')",
+		"AND('A.member', 'A.member')",
+	} {
+		envelope, err := FromString(policy)
+		assert.NoError(t, err)
+		_, err = SatisfyingLayout(envelope, available)
+		assert.Error(t, err, policy)
+	}
+
+	_, err := SatisfyingLayout(Envelope(SignedBy(0), signers), available)
+	assert.Error(t, err, "Identity principals can't be satisfied by the signers of an MSP")
+}
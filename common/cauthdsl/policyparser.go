@@ -21,13 +21,14 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/Knetic/govaluate"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 )
 
-var regex *regexp.Regexp = regexp.MustCompile("^([[:alnum:]]+)([.])(member|admin)$")
+var regex *regexp.Regexp = regexp.MustCompile("^([[:alnum:]]+)([.])(member|admin|peer|client|orderer)$")
 
 func and(args ...interface{}) (interface{}, error) {
 	toret := "outof(" + strconv.Itoa(len(args))
@@ -129,7 +130,7 @@ func secondPass(args ...interface{}) (interface{}, error) {
 		switch t := principal.(type) {
 		/* if it's a string, we expect it to be formed as
 		   <MSP_ID> . <ROLE>, where MSP_ID is the MSP identifier
-		   and ROLE is either a member of an admin*/
+		   and ROLE is a member, an admin or an identity type*/
 		case string:
 			/* split the string */
 			subm := regex.FindAllStringSubmatch(t, -1)
@@ -138,12 +139,7 @@ func secondPass(args ...interface{}) (interface{}, error) {
 			}
 
 			/* get the right role */
-			var r common.MSPRole_MSPRoleType
-			if subm[0][3] == "member" {
-				r = common.MSPRole_MEMBER
-			} else {
-				r = common.MSPRole_ADMIN
-			}
+			r := common.MSPRole_MSPRoleType(common.MSPRole_MSPRoleType_value[strings.ToUpper(subm[0][3])])
 
 			/* build the principal we've been told */
 			p := &common.MSPPrincipal{
@@ -200,7 +196,9 @@ func newContext() *context {
 //
 // where
 //	- ORG is a string (representing the MSP identifier)
-//	- ROLE is either the string "member" or the string "admin" representing the required role
+//	- ROLE is either the string "member" or the string "admin" representing the required role,
+//	  or one of the strings "peer", "client" and "orderer" representing the identity type the
+//	  MSP derives from the organizational units of the signer
 func FromString(policy string) (*common.SignaturePolicyEnvelope, error) {
 	// first we translate the and/or business into outof gates
 	intermediate, err := govaluate.NewEvaluableExpressionWithFunctions(policy, map[string]govaluate.ExpressionFunction{"AND": and, "and": and, "OR": or, "or": or})
@@ -128,3 +128,31 @@ func TestComplex2(t *testing.T) {
 
 	assert.True(t, reflect.DeepEqual(p1, p2))
 }
+
+func TestIdentityTypes(t *testing.T) {
+	p1, err := FromString("AND('A.peer', OR('B.client', 'B.orderer'))")
+	assert.NoError(t, err)
+
+	// The principals of the nested gate come first
+	principals := make([]*common.MSPPrincipal, 0)
+	for _, role := range []*common.MSPRole{
+		{Role: common.MSPRole_CLIENT, MspIdentifier: "B"},
+		{Role: common.MSPRole_ORDERER, MspIdentifier: "B"},
+		{Role: common.MSPRole_PEER, MspIdentifier: "A"},
+	} {
+		principals = append(principals, &common.MSPPrincipal{
+			PrincipalClassification: common.MSPPrincipal_ROLE,
+			Principal:               utils.MarshalOrPanic(role)})
+	}
+
+	p2 := &common.SignaturePolicyEnvelope{
+		Version:    0,
+		Policy:     And(SignedBy(2), Or(SignedBy(0), SignedBy(1))),
+		Identities: principals,
+	}
+
+	assert.True(t, reflect.DeepEqual(p1, p2))
+
+	_, err = FromString("AND('A.auditor', 'B.member')")
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cauthdsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// Simulate evaluates the policy of sigPolicy as if each of the serialized
+// identities had signed, which the signatures of the identities would satisfy.
+// The identities are matched to the principals as they are when the policy is
+// evaluated, each identity satisfying a single SignedBy rule of a satisfied
+// branch. The evaluation of every branch is reported, the signatures themselves
+// being left out
+func Simulate(sigPolicy *cb.SignaturePolicyEnvelope, identities [][]byte, deserializer msp.IdentityDeserializer) (*cb.PolicySimulation, error) {
+	if sigPolicy == nil || sigPolicy.Policy == nil {
+		return nil, fmt.Errorf("Cannot simulate nil policy")
+	}
+	if sigPolicy.Version != 0 {
+		return nil, fmt.Errorf("This evaluator only understands messages of version 0, but version was %d", sigPolicy.Version)
+	}
+
+	ids := make([]msp.Identity, len(identities))
+	for i, idBytes := range identities {
+		id, err := deserializer.DeserializeIdentity(idBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Error deserializing identity %d: %s", i, err)
+		}
+		ids[i] = id
+	}
+
+	simulation := &cb.PolicySimulation{}
+	satisfied, err := simulate(sigPolicy.Policy, sigPolicy.Identities, ids, make([]bool, len(ids)), "", simulation)
+	if err != nil {
+		return nil, err
+	}
+	simulation.Satisfied = satisfied
+	return simulation, nil
+}
+
+// simulate evaluates policy as the function compile returns does, appending the
+// evaluation of the branch at path and of its descendants to simulation
+func simulate(policy *cb.SignaturePolicy, principals []*cb.MSPPrincipal, ids []msp.Identity, used []bool, path string, simulation *cb.PolicySimulation) (bool, error) {
+	branch := &cb.PolicyBranch{Path: path}
+	simulation.Branches = append(simulation.Branches, branch)
+
+	switch t := policy.Type.(type) {
+	case *cb.SignaturePolicy_From:
+		branch.Rule = fmt.Sprintf("OutOf(%d)", t.From.N)
+		verified := int32(0)
+		_used := make([]bool, len(used))
+		for i, child := range t.From.Policies {
+			copy(_used, used)
+			childPath := strconv.Itoa(i)
+			if path != "" {
+				childPath = path + "/" + childPath
+			}
+			ok, err := simulate(child, principals, ids, _used, childPath, simulation)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				verified++
+				copy(used, _used)
+			}
+		}
+		branch.Satisfied = verified >= t.From.N
+	case *cb.SignaturePolicy_SignedBy:
+		if t.SignedBy < 0 || t.SignedBy >= int32(len(principals)) {
+			return false, fmt.Errorf("Identity index out of range, requested %d, but identies length is %d", t.SignedBy, len(principals))
+		}
+		principal := principals[t.SignedBy]
+		branch.Rule = fmt.Sprintf("SignedBy(%s)", principalString(principal))
+		for i, id := range ids {
+			if used[i] {
+				continue
+			}
+			if id.SatisfiesPrincipal(principal) == nil {
+				used[i] = true
+				branch.Satisfied = true
+				branch.Identity = int32(i)
+				break
+			}
+		}
+	default:
+		return false, fmt.Errorf("Unknown type: %T:%v", t, t)
+	}
+
+	return branch.Satisfied, nil
+}
+
+// principalString describes principal as the policy parser reads roles
+func principalString(principal *cb.MSPPrincipal) string {
+	switch principal.PrincipalClassification {
+	case cb.MSPPrincipal_ROLE:
+		role := &cb.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err == nil {
+			return role.MspIdentifier + "." + strings.ToLower(role.Role.String())
+		}
+	case cb.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &cb.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err == nil {
+			return ou.MspIdentifier + ".OU=" + ou.OrganizationalUnitIdentifier
+		}
+	}
+	return principal.PrincipalClassification.String()
+}
@@ -0,0 +1,61 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cauthdsl
+
+import (
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulate(t *testing.T) {
+	policy := Envelope(Or(And(SignedBy(0), SignedBy(1)), SignedBy(1)), signers)
+
+	simulation, err := Simulate(policy, [][]byte{signers[1]}, &mockDeserializer{})
+	assert.NoError(t, err)
+	assert.True(t, simulation.Satisfied)
+	assert.Equal(t, []*cb.PolicyBranch{
+		{Path: "", Rule: "OutOf(1)", Satisfied: true},
+		{Path: "0", Rule: "OutOf(2)"},
+		{Path: "0/0", Rule: "SignedBy(IDENTITY)"},
+		{Path: "0/1", Rule: "SignedBy(IDENTITY)", Satisfied: true},
+		{Path: "1", Rule: "SignedBy(IDENTITY)", Satisfied: true},
+	}, simulation.Branches)
+
+	simulation, err = Simulate(Envelope(And(SignedBy(0), SignedBy(0)), signers), [][]byte{signers[1], signers[0]}, &mockDeserializer{})
+	assert.NoError(t, err)
+	assert.False(t, simulation.Satisfied, "An identity should only satisfy a single principal")
+	assert.Equal(t, int32(1), simulation.Branches[1].Identity)
+	assert.False(t, simulation.Branches[2].Satisfied)
+}
+
+func TestSimulateRoles(t *testing.T) {
+	policy, err := FromString("AND('Org1.peer', 'Org2.admin')")
+	assert.NoError(t, err)
+
+	simulation, err := Simulate(policy, nil, &mockDeserializer{})
+	assert.NoError(t, err)
+	assert.False(t, simulation.Satisfied)
+	assert.Equal(t, "SignedBy(Org1.peer)", simulation.Branches[1].Rule)
+	assert.Equal(t, "SignedBy(Org2.admin)", simulation.Branches[2].Rule)
+
+	_, err = Simulate(Envelope(SignedBy(2), signers), nil, &mockDeserializer{})
+	assert.Error(t, err)
+	_, err = Simulate(nil, nil, &mockDeserializer{})
+	assert.Error(t, err)
+}
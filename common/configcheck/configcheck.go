@@ -0,0 +1,185 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configcheck provides the checks shared by the validation of the
+// configurations of the peer and of the orderer, which report every error
+// found rather than stopping at the first one
+package configcheck
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/common/viperutil"
+	"github.com/hyperledger/fabric/msp"
+)
+
+// Checker collects the errors found checking the settings of a configuration,
+// each reported with the key of the setting
+type Checker struct {
+	Errors []string
+}
+
+// Errorf records an error of the setting key
+func (c *Checker) Errorf(key string, format string, args ...interface{}) {
+	c.Errors = append(c.Errors, fmt.Sprintf("%s: %s", key, fmt.Sprintf(format, args...)))
+}
+
+// NotEmpty checks that the setting is set
+func (c *Checker) NotEmpty(key string, value string) bool {
+	if value == "" {
+		c.Errorf(key, "must be set")
+		return false
+	}
+	return true
+}
+
+// Address checks that address is a host and a port
+func (c *Checker) Address(key string, address string) {
+	if !c.NotEmpty(key, address) {
+		return
+	}
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		c.Errorf(key, "invalid address %s: %s", address, err)
+		return
+	}
+	if _, err = strconv.ParseUint(port, 10, 16); err != nil {
+		c.Errorf(key, "invalid port %s", port)
+	}
+}
+
+// OneOf checks that value is one of values
+func (c *Checker) OneOf(key string, value string, values ...string) {
+	for _, v := range values {
+		if value == v {
+			return
+		}
+	}
+	c.Errorf(key, "invalid value %s, must be one of %s", value, strings.Join(values, ", "))
+}
+
+// File reads the file of the setting, returning nil if it cannot be read
+func (c *Checker) File(key string, path string) []byte {
+	if !c.NotEmpty(key, path) {
+		return nil
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		c.Errorf(key, "%s", err)
+		return nil
+	}
+	return contents
+}
+
+// Directory checks that path is a directory, if it exists when mayNotExist
+func (c *Checker) Directory(key string, path string, mayNotExist bool) {
+	if !c.NotEmpty(key, path) {
+		return
+	}
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err) && mayNotExist:
+	case err != nil:
+		c.Errorf(key, "%s", err)
+	case !info.IsDir():
+		c.Errorf(key, "%s is not a directory", path)
+	}
+}
+
+// Certificates checks that certificates is a non empty list of PEM encoded
+// x509 certificates
+func (c *Checker) Certificates(key string, certificates []byte) {
+	if certificates == nil {
+		return
+	}
+	count := 0
+	for rest := certificates; ; count++ {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			c.Errorf(key, "invalid certificate: %s", err)
+			return
+		}
+	}
+	if count == 0 {
+		c.Errorf(key, "no PEM encoded certificate")
+	}
+}
+
+// KeyPair checks that the PEM encoded certificate and private key match
+func (c *Checker) KeyPair(key string, certificate, privateKey []byte) {
+	if certificate == nil || privateKey == nil {
+		return
+	}
+	if _, err := tls.X509KeyPair(certificate, privateKey); err != nil {
+		c.Errorf(key, "invalid key pair: %s", err)
+	}
+}
+
+// MSPDir checks that the MSP of the directory dir can be set up
+func (c *Checker) MSPDir(key string, dir string, mspID string) {
+	if !c.NotEmpty(key, dir) {
+		return
+	}
+	conf, err := msp.GetLocalMspConfig(dir, mspID)
+	if err != nil {
+		c.Errorf(key, "%s", err)
+		return
+	}
+	localMSP, err := msp.NewBccspMsp()
+	if err != nil {
+		c.Errorf(key, "%s", err)
+		return
+	}
+	if err = localMSP.Setup(conf); err != nil {
+		c.Errorf(key, "invalid MSP: %s", err)
+	}
+}
+
+// EnvOverrides returns the environment variables with prefix, e.g. CORE_PEER_ADDRESS
+// for the prefix CORE, split between the ones overriding a setting, i.e. for
+// which known returns true given the name without the prefix, and the others.
+// A variable with the suffix _FILE, setting a setting to the contents of a file,
+// overrides the setting of its name without the suffix
+func EnvOverrides(prefix string, known func(name string) bool) (overrides []string, unknown []string) {
+	prefix = strings.ToUpper(prefix) + "_"
+	for _, env := range os.Environ() {
+		name := strings.SplitN(env, "=", 2)[0]
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		setting := strings.TrimPrefix(name, prefix)
+		if known(setting) || (strings.HasSuffix(setting, viperutil.FileSuffix) && known(strings.TrimSuffix(setting, viperutil.FileSuffix))) {
+			overrides = append(overrides, name)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(overrides)
+	sort.Strings(unknown)
+	return overrides, unknown
+}
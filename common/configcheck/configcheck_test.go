@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mspfixtures "github.com/hyperledger/fabric/msp/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettings(t *testing.T) {
+	c := &Checker{}
+	c.Address("good", "127.0.0.1:7050")
+	c.OneOf("good", "file", "file", "ram")
+	assert.Empty(t, c.Errors)
+
+	c.Address("noport", "127.0.0.1")
+	c.Address("badport", "127.0.0.1:70500")
+	c.Address("empty", "")
+	c.OneOf("ledger", "disk", "file", "ram")
+	assert.Len(t, c.Errors, 4)
+	assert.Equal(t, "ledger: invalid value disk, must be one of file, ram", c.Errors[3])
+}
+
+func TestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcheck")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("contents"), 0600))
+
+	c := &Checker{}
+	assert.Equal(t, []byte("contents"), c.File("file", file))
+	c.Directory("dir", dir, false)
+	c.Directory("missing", filepath.Join(dir, "missing"), true)
+	assert.Empty(t, c.Errors)
+
+	assert.Nil(t, c.File("missing", filepath.Join(dir, "missing")))
+	c.Directory("missing", filepath.Join(dir, "missing"), false)
+	c.Directory("notdir", file, false)
+	assert.Len(t, c.Errors, 3)
+}
+
+func TestCertificates(t *testing.T) {
+	org, err := mspfixtures.NewOrg("Org1MSP")
+	assert.NoError(t, err)
+	other, err := org.NewIdentity("other")
+	assert.NoError(t, err)
+
+	c := &Checker{}
+	c.Certificates("ca", org.Member.CA.CertPEM)
+	c.KeyPair("tls", org.Member.CertPEM, org.Member.KeyPEM)
+	assert.Empty(t, c.Errors)
+
+	c.Certificates("ca", []byte("not a certificate"))
+	c.KeyPair("tls", org.Member.CertPEM, other.KeyPEM)
+	assert.Len(t, c.Errors, 2)
+}
+
+func TestMSPDir(t *testing.T) {
+	org, err := mspfixtures.NewOrg("Org1MSP")
+	assert.NoError(t, err)
+	dir, err := ioutil.TempDir("", "configcheck")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, org.WriteDir(dir, org.Member))
+
+	c := &Checker{}
+	c.MSPDir("msp", dir, "Org1MSP")
+	assert.Empty(t, c.Errors)
+
+	c.MSPDir("msp", filepath.Join(dir, "missing"), "Org1MSP")
+	assert.Len(t, c.Errors, 1)
+}
+
+func TestEnvOverrides(t *testing.T) {
+	os.Setenv("CONFIGCHECKTEST_KNOWN", "1")
+	os.Setenv("CONFIGCHECKTEST_UNKNOWN", "1")
+	defer os.Unsetenv("CONFIGCHECKTEST_KNOWN")
+	defer os.Unsetenv("CONFIGCHECKTEST_UNKNOWN")
+
+	overrides, unknown := EnvOverrides("configchecktest", func(name string) bool {
+		return name == "KNOWN"
+	})
+	assert.Equal(t, []string{"CONFIGCHECKTEST_KNOWN"}, overrides)
+	assert.Equal(t, []string{"CONFIGCHECKTEST_UNKNOWN"}, unknown)
+}
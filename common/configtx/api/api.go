@@ -19,6 +19,7 @@ package api
 import (
 	"time"
 
+	"github.com/hyperledger/fabric/common/capabilities"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/msp"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -38,6 +39,9 @@ type ChannelConfig interface {
 
 	// OrdererAddresses returns the list of valid orderer addresses to connect to to invoke Broadcast/Deliver
 	OrdererAddresses() []string
+
+	// Capabilities returns the capabilities enabled on the channel
+	Capabilities() *capabilities.Provider
 }
 
 // ApplicationOrgConfig stores the per org application config
@@ -50,6 +54,14 @@ type ApplicationOrgConfig interface {
 type ApplicationConfig interface {
 	// Organizations returns a map of org ID to ApplicationOrgConfig
 	Organizations() map[string]ApplicationOrgConfig
+
+	// ShimCapabilityDisabled returns true if chaincodes may not send messages
+	// of the given type (e.g. GET_HISTORY_FOR_KEY) on the channel
+	ShimCapabilityDisabled(msgType string) bool
+
+	// ChaincodeNamespacePrefix returns the prefix of the ledger namespaces and
+	// the containers of the chaincodes of the channel, or "" if it has none
+	ChaincodeNamespacePrefix() string
 }
 
 // OrdererConfig stores the common shared orderer config
@@ -86,7 +98,8 @@ type Handler interface {
 	ProposeConfig(key string, configValue *cb.ConfigValue) error
 }
 
-// Manager provides a mechanism to query and update config
+// Manager provides a mechanism to query and update config. The updates are serialized, whereas the
+// config may be queried concurrently with them, the queries returning the last config committed
 type Manager interface {
 	Resources
 
@@ -96,7 +109,17 @@ type Manager interface {
 	// Validate attempts to validate a new configtx against the current config state
 	Validate(configtx *cb.Envelope) error
 
-	// ConfigEnvelope returns the *cb.ConfigEnvelope from the last successful Apply
+	// Simulate validates a new configtx against the current config state and
+	// returns the config applying it would result in, without applying it
+	Simulate(configtx *cb.Envelope) (*cb.ConfigEnvelope, error)
+
+	// Rollback reverts to the previously committed config with the given sequence
+	// number, for instance after a failure committing the current one. The config
+	// is committed again with the next sequence number
+	Rollback(sequence uint64) error
+
+	// ConfigEnvelope returns the *cb.ConfigEnvelope from the last successful Apply,
+	// or the initial *cb.ConfigEnvelope if no Apply succeeded yet
 	ConfigEnvelope() *cb.ConfigEnvelope
 
 	// ChainID retrieves the chain ID associated with this manager
@@ -104,6 +127,36 @@ type Manager interface {
 
 	// Sequence returns the current sequence number of the config
 	Sequence() uint64
+
+	// ConfigDigest returns the canonical SHA-256 digest of the current config,
+	// the same on all the nodes which have the same config for the chain
+	ConfigDigest() []byte
+}
+
+// ConfigListener is notified of the phases of the config updates of a Manager, in the order the
+// listeners were given to it. The updates being serialized, so are the calls to a listener
+type ConfigListener interface {
+	// OnValidate is called with the config resulting from an update each time the update is
+	// validated by Validate or Simulate, it rejects the update by returning an error. The
+	// orderer validates the updates before they are ordered, and the configs of the ordered
+	// blocks are applied regardless, so the result may only depend on configEnv
+	OnValidate(configEnv *cb.ConfigEnvelope) error
+
+	// OnPostApply is called once the config is committed, with the Manager reading it
+	OnPostApply(manager Manager)
+}
+
+// PostApplyFunc is a ConfigListener which only reacts to the configs committed
+type PostApplyFunc func(manager Manager)
+
+// OnValidate accepts any config
+func (f PostApplyFunc) OnValidate(configEnv *cb.ConfigEnvelope) error {
+	return nil
+}
+
+// OnPostApply calls f
+func (f PostApplyFunc) OnPostApply(manager Manager) {
+	f(manager)
 }
 
 // Resources is the common set of config resources for all channels
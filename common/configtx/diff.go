@@ -0,0 +1,184 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"bytes"
+	"fmt"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// Diff computes the config update turning the config of old into the config of
+// new, whatever the versions the elements of new carry. The elements added or
+// modified by new are written at the next sequence number, the others keep their
+// version, and the elements removed by new are listed in the delete set. As
+// omitting an element from the write set is otherwise treated as an attempt to
+// delete it implicitly, the write set carries the whole config, whereas the read
+// set only carries the versions of the elements modified or deleted and of the
+// groups leading to them. The returned ConfigUpdate must be wrapped in a
+// ConfigUpdateEnvelope and signed by enough members to satisfy the mod_policy
+// of the modified and deleted elements
+func Diff(old, new *cb.ConfigEnvelope) (*cb.ConfigUpdate, error) {
+	if old.GetConfig().GetHeader() == nil || old.Config.Channel == nil {
+		return nil, fmt.Errorf("Old config must have a header and a channel group")
+	}
+	if new.GetConfig().GetHeader() == nil || new.Config.Channel == nil {
+		return nil, fmt.Errorf("New config must have a header and a channel group")
+	}
+	if old.Config.Header.ChannelId != new.Config.Header.ChannelId {
+		return nil, fmt.Errorf("Configs are for different chains, %s and %s", old.Config.Header.ChannelId, new.Config.Header.ChannelId)
+	}
+
+	sequence := utils.ComputeConfigSequence(old.Config.Channel) + 1
+	writeSet, readSet, deleteSet := diffGroup(old.Config.Channel, new.Config.Channel, sequence)
+	if readSet == nil {
+		return nil, fmt.Errorf("Configs are identical")
+	}
+
+	return &cb.ConfigUpdate{
+		Header: &cb.ChannelHeader{
+			ChannelId: old.Config.Header.ChannelId,
+			Type:      int32(cb.HeaderType_CONFIG),
+		},
+		ReadSet:   readSet,
+		WriteSet:  writeSet,
+		DeleteSet: deleteSet,
+	}, nil
+}
+
+// diffGroup returns the group to write in place of old, the group to read
+// from old, which is nil if neither old nor its members are modified or
+// deleted, and the group to delete from old, which is nil if none of its
+// members are deleted
+func diffGroup(old, new *cb.ConfigGroup, sequence uint64) (*cb.ConfigGroup, *cb.ConfigGroup, *cb.ConfigGroup) {
+	writeSet := cb.NewConfigGroup()
+	writeSet.ModPolicy = new.ModPolicy
+	readSet := cb.NewConfigGroup()
+	readSet.Version = old.Version
+	deleteSet := cb.NewConfigGroup()
+	readMembers := false
+
+	for key, oldGroup := range old.Groups {
+		if _, ok := new.Groups[key]; !ok {
+			deleteSet.Groups[key] = &cb.ConfigGroup{Version: oldGroup.Version}
+			readSet.Groups[key] = &cb.ConfigGroup{Version: oldGroup.Version}
+		}
+	}
+	for key, oldValue := range old.Values {
+		if _, ok := new.Values[key]; !ok {
+			deleteSet.Values[key] = &cb.ConfigValue{Version: oldValue.Version}
+			readSet.Values[key] = &cb.ConfigValue{Version: oldValue.Version}
+		}
+	}
+	for key, oldPolicy := range old.Policies {
+		if _, ok := new.Policies[key]; !ok {
+			deleteSet.Policies[key] = &cb.ConfigPolicy{Version: oldPolicy.Version}
+			readSet.Policies[key] = &cb.ConfigPolicy{Version: oldPolicy.Version}
+		}
+	}
+	deletesMembers := len(deleteSet.Groups) > 0 || len(deleteSet.Values) > 0 || len(deleteSet.Policies) > 0
+
+	// A group is modified by a change of its mod_policy or of the set of its members
+	modified := old.ModPolicy != new.ModPolicy ||
+		deletesMembers ||
+		len(old.Groups) != len(new.Groups) ||
+		len(old.Values) != len(new.Values) ||
+		len(old.Policies) != len(new.Policies)
+
+	for key, newGroup := range new.Groups {
+		oldGroup, ok := old.Groups[key]
+		if !ok {
+			writeSet.Groups[key] = newGroupAt(newGroup, sequence)
+			continue
+		}
+		groupWrite, groupRead, groupDelete := diffGroup(oldGroup, newGroup, sequence)
+		writeSet.Groups[key] = groupWrite
+		if groupRead != nil {
+			readSet.Groups[key] = groupRead
+			readMembers = true
+		}
+		if groupDelete != nil {
+			deleteSet.Groups[key] = groupDelete
+			deletesMembers = true
+		}
+	}
+
+	for key, newValue := range new.Values {
+		value := &cb.ConfigValue{ModPolicy: newValue.ModPolicy, Value: newValue.Value, Version: sequence}
+		if oldValue, ok := old.Values[key]; ok {
+			if oldValue.ModPolicy == newValue.ModPolicy && bytes.Equal(oldValue.Value, newValue.Value) {
+				value.Version = oldValue.Version
+			} else {
+				readSet.Values[key] = &cb.ConfigValue{Version: oldValue.Version}
+				readMembers = true
+			}
+		}
+		writeSet.Values[key] = value
+	}
+
+	for key, newPolicy := range new.Policies {
+		policy := &cb.ConfigPolicy{ModPolicy: newPolicy.ModPolicy, Policy: newPolicy.Policy, Version: sequence}
+		if oldPolicy, ok := old.Policies[key]; ok {
+			if oldPolicy.ModPolicy == newPolicy.ModPolicy && equalPolicies(oldPolicy.Policy, newPolicy.Policy) {
+				policy.Version = oldPolicy.Version
+			} else {
+				readSet.Policies[key] = &cb.ConfigPolicy{Version: oldPolicy.Version}
+				readMembers = true
+			}
+		}
+		writeSet.Policies[key] = policy
+	}
+
+	writeSet.Version = old.Version
+	if modified {
+		writeSet.Version = sequence
+	}
+	if !modified && !readMembers {
+		readSet = nil
+	}
+	if !deletesMembers {
+		deleteSet = nil
+	}
+	return writeSet, readSet, deleteSet
+}
+
+// newGroupAt returns a copy of a group added by the update, with every element
+// at the given version
+func newGroupAt(group *cb.ConfigGroup, version uint64) *cb.ConfigGroup {
+	result := cb.NewConfigGroup()
+	result.Version = version
+	result.ModPolicy = group.ModPolicy
+	for key, subGroup := range group.Groups {
+		result.Groups[key] = newGroupAt(subGroup, version)
+	}
+	for key, value := range group.Values {
+		result.Values[key] = &cb.ConfigValue{ModPolicy: value.ModPolicy, Value: value.Value, Version: version}
+	}
+	for key, policy := range group.Policies {
+		result.Policies[key] = &cb.ConfigPolicy{ModPolicy: policy.ModPolicy, Policy: policy.Policy, Version: version}
+	}
+	return result
+}
+
+func equalPolicies(lhs, rhs *cb.Policy) bool {
+	if lhs == nil || rhs == nil {
+		return lhs == rhs
+	}
+	return lhs.Type == rhs.Type && bytes.Equal(lhs.Policy, rhs.Policy)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	configtxapplication "github.com/hyperledger/fabric/common/configtx/handlers/application"
+	configtxorderer "github.com/hyperledger/fabric/common/configtx/handlers/orderer"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+func TestDiff(t *testing.T) {
+	old := &cb.ConfigEnvelope{Config: makeChannelConfig()}
+	new := proto.Clone(old).(*cb.ConfigEnvelope)
+	new.Config.Channel.Values["foo"].Value = []byte("bar")
+	newOrg := cb.NewConfigGroup()
+	newOrg.Values[MSPKey] = &cb.ConfigValue{Value: []byte("newmsp")}
+	new.Config.Channel.Groups[configtxapplication.GroupKey].Groups["NewOrg"] = newOrg
+
+	configUpdate, err := Diff(old, new)
+	if err != nil {
+		t.Fatalf("Error computing config diff: %s", err)
+	}
+
+	writeSet := configUpdate.WriteSet
+	if writeSet.Values["foo"].Version != 3 || string(writeSet.Values["foo"].Value) != "bar" {
+		t.Errorf("Modified value should have been written at version 3, got %v", writeSet.Values["foo"])
+	}
+	application := writeSet.Groups[configtxapplication.GroupKey]
+	if application.Version != 3 {
+		t.Errorf("Application group gained a member, its version should have been bumped to 3, got %d", application.Version)
+	}
+	if application.Groups["NewOrg"].Values[MSPKey].Version != 3 {
+		t.Errorf("New org should have been written at version 3, got %v", application.Groups["NewOrg"])
+	}
+	if application.Groups["ExistingOrg"].Values[MSPKey].Version != 1 {
+		t.Errorf("Unmodified org should have kept its version, got %v", application.Groups["ExistingOrg"])
+	}
+	if _, ok := writeSet.Groups[configtxorderer.GroupKey]; !ok {
+		t.Errorf("Unmodified Orderer group should have been written")
+	}
+
+	readSet := configUpdate.ReadSet
+	if readSet.Values["foo"].Version != 2 {
+		t.Errorf("Modified value should have been read at version 2, got %v", readSet.Values["foo"])
+	}
+	if _, ok := readSet.Groups[configtxapplication.GroupKey]; !ok {
+		t.Errorf("Modified Application group should have been read")
+	}
+	if _, ok := readSet.Groups[configtxorderer.GroupKey]; ok {
+		t.Errorf("Unmodified Orderer group should not have been read")
+	}
+
+	if configUpdate.DeleteSet != nil {
+		t.Errorf("Nothing was removed, the delete set should have been nil")
+	}
+
+	cm, err := NewManagerImpl(old, defaultInitializer(), nil)
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+	if err = cm.Validate(wrapConfigUpdate(configUpdate)); err != nil {
+		t.Errorf("Config update computed by Diff should have been valid: %s", err)
+	}
+}
+
+func TestDiffDeletes(t *testing.T) {
+	old := &cb.ConfigEnvelope{Config: makeChannelConfig()}
+	new := proto.Clone(old).(*cb.ConfigEnvelope)
+	delete(new.Config.Channel.Groups[configtxapplication.GroupKey].Groups, "ExistingOrg")
+	delete(new.Config.Channel.Values, "foo")
+
+	configUpdate, err := Diff(old, new)
+	if err != nil {
+		t.Fatalf("Error computing config diff: %s", err)
+	}
+
+	deleteSet := configUpdate.DeleteSet
+	if deleteSet == nil || deleteSet.Values["foo"] == nil || deleteSet.Values["foo"].Version != 2 {
+		t.Fatalf("Removed value should have been deleted at version 2, got %v", deleteSet)
+	}
+	existingOrg := deleteSet.Groups[configtxapplication.GroupKey].Groups["ExistingOrg"]
+	if existingOrg == nil || len(existingOrg.Values) != 0 {
+		t.Errorf("Removed org should have been deleted as a whole, got %v", existingOrg)
+	}
+	if _, ok := deleteSet.Groups[configtxorderer.GroupKey]; ok {
+		t.Errorf("Unmodified Orderer group should not have been in the delete set")
+	}
+	if configUpdate.WriteSet.Groups[configtxapplication.GroupKey].Version != 3 {
+		t.Errorf("Application group lost a member, its version should have been bumped to 3")
+	}
+
+	cm, err := NewManagerImpl(old, defaultInitializer(), nil)
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+	if err = cm.Apply(wrapConfigUpdate(configUpdate)); err != nil {
+		t.Fatalf("Config update computed by Diff should have been applied: %s", err)
+	}
+	channel := cm.ConfigEnvelope().Config.Channel
+	if _, ok := channel.Groups[configtxapplication.GroupKey].Groups["ExistingOrg"]; ok {
+		t.Errorf("Removed org should not be in the config anymore")
+	}
+	if _, ok := channel.Values["foo"]; ok {
+		t.Errorf("Removed value should not be in the config anymore")
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	old := &cb.ConfigEnvelope{Config: makeChannelConfig()}
+
+	if _, err := Diff(old, proto.Clone(old).(*cb.ConfigEnvelope)); err == nil {
+		t.Errorf("Diff of identical configs should have failed")
+	}
+
+	otherChain := proto.Clone(old).(*cb.ConfigEnvelope)
+	otherChain.Config.Header.ChannelId = "OtherChain"
+	if _, err := Diff(old, otherChain); err == nil {
+		t.Errorf("Diff of configs of different chains should have failed")
+	}
+
+	if _, err := Diff(old, &cb.ConfigEnvelope{}); err == nil {
+		t.Errorf("Diff with an empty config should have failed")
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"sort"
+)
+
+// computeConfigDigest returns the SHA-256 digest of the config of a chain, as a
+// config map. The config items are hashed in the order of their fully
+// qualified paths, each with its version, modification policy and content, so
+// that the digest depends on the config alone, not on the order of its maps
+// or on how it was marshaled. The content of a group is made of its items
+func computeConfigDigest(chainID string, config map[string]comparable) []byte {
+	fqPaths := make([]string, 0, len(config))
+	for fqPath := range config {
+		fqPaths = append(fqPaths, fqPath)
+	}
+	sort.Strings(fqPaths)
+
+	h := sha256.New()
+	writeDigestBytes(h, []byte(chainID))
+	for _, fqPath := range fqPaths {
+		item := config[fqPath]
+		writeDigestBytes(h, []byte(fqPath))
+		writeDigestUint64(h, item.version())
+		writeDigestBytes(h, []byte(item.modPolicy()))
+		switch {
+		case item.ConfigValue != nil:
+			writeDigestBytes(h, item.ConfigValue.Value)
+		case item.ConfigPolicy != nil && item.ConfigPolicy.Policy != nil:
+			writeDigestUint64(h, uint64(item.ConfigPolicy.Policy.Type))
+			writeDigestBytes(h, item.ConfigPolicy.Policy.Policy)
+		}
+	}
+	return h.Sum(nil)
+}
+
+// writeDigestBytes writes b behind its length, so that the boundaries of the
+// fields are part of the digest
+func writeDigestBytes(h hash.Hash, b []byte) {
+	writeDigestUint64(h, uint64(len(b)))
+	h.Write(b)
+}
+
+func writeDigestUint64(h hash.Hash, n uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], n)
+	h.Write(buf[:])
+}
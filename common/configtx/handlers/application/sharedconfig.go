@@ -18,20 +18,41 @@ package application
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/hyperledger/fabric/common/configtx/api"
 	"github.com/hyperledger/fabric/common/configtx/handlers"
 	"github.com/hyperledger/fabric/common/configtx/handlers/msp"
 	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/op/go-logging"
 )
 
 const (
 	// GroupKey is the group name for the Application config
 	GroupKey = "Application"
+
+	// ShimCapabilitiesKey is the key name for the ShimCapabilities ConfigValue
+	ShimCapabilitiesKey = "ShimCapabilities"
+
+	// ChaincodeNamespacePrefixKey is the key name for the ChaincodeNamespacePrefix ConfigValue
+	ChaincodeNamespacePrefixKey = "ChaincodeNamespacePrefix"
 )
 
+// NamespacePrefixRegexp is the format of the chaincode namespace prefixes
+var NamespacePrefixRegexp = regexp.MustCompile("^[a-z0-9]+$")
+
+// RestrictableShimCapabilities lists the chaincode message types which may be
+// disabled for the chaincodes of a channel through the ShimCapabilities value
+var RestrictableShimCapabilities = map[string]bool{
+	pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(): true,
+	pb.ChaincodeMessage_GET_QUERY_RESULT.String():    true,
+	pb.ChaincodeMessage_GET_STATE_BY_RANGE.String():  true,
+	pb.ChaincodeMessage_INVOKE_CHAINCODE.String():    true,
+}
+
 var orgSchema = &cb.ConfigGroupSchema{
 	Groups: map[string]*cb.ConfigGroupSchema{},
 	Values: map[string]*cb.ConfigValueSchema{
@@ -47,7 +68,10 @@ var Schema = &cb.ConfigGroupSchema{
 	Groups: map[string]*cb.ConfigGroupSchema{
 		"": orgSchema,
 	},
-	Values:   map[string]*cb.ConfigValueSchema{},
+	Values: map[string]*cb.ConfigValueSchema{
+		ShimCapabilitiesKey:         nil,
+		ChaincodeNamespacePrefixKey: nil,
+	},
 	Policies: map[string]*cb.ConfigPolicySchema{
 	// TODO, set appropriately once hierarchical policies are implemented
 	},
@@ -56,7 +80,9 @@ var Schema = &cb.ConfigGroupSchema{
 var logger = logging.MustGetLogger("common/configtx/handlers/application")
 
 type sharedConfig struct {
-	orgs map[string]api.ApplicationOrgConfig
+	orgs                     map[string]api.ApplicationOrgConfig
+	disabledShimCapabilities map[string]bool
+	namespacePrefix          string
 }
 
 // SharedConfigImpl is an implementation of Manager and configtx.ConfigHandler
@@ -83,7 +109,8 @@ func (di *SharedConfigImpl) BeginConfig() {
 		logger.Panicf("Programming error, cannot call begin in the middle of a proposal")
 	}
 	di.pendingConfig = &sharedConfig{
-		orgs: make(map[string]api.ApplicationOrgConfig),
+		orgs:                     make(map[string]api.ApplicationOrgConfig),
+		disabledShimCapabilities: make(map[string]bool),
 	}
 }
 
@@ -99,13 +126,41 @@ func (di *SharedConfigImpl) CommitConfig() {
 	if di.pendingConfig == nil {
 		logger.Panicf("Programming error, cannot call commit without an existing proposal")
 	}
+	for _, org := range di.pendingConfig.orgs {
+		org.(*ApplicationOrgConfig).CommitConfig()
+	}
 	di.config = di.pendingConfig
 	di.pendingConfig = nil
 }
 
 // ProposeConfig is used to add new config to the config proposal
 func (di *SharedConfigImpl) ProposeConfig(key string, configValue *cb.ConfigValue) error {
-	logger.Warningf("Uknown Peer config item with key %s", key)
+	switch key {
+	case ShimCapabilitiesKey:
+		shimCapabilities := &pb.ShimCapabilities{}
+		if err := proto.Unmarshal(configValue.Value, shimCapabilities); err != nil {
+			return fmt.Errorf("Unmarshaling error for %s: %s", key, err)
+		}
+		for _, capability := range shimCapabilities.Disabled {
+			if !RestrictableShimCapabilities[capability] {
+				return fmt.Errorf("Shim capability %s cannot be disabled", capability)
+			}
+			di.pendingConfig.disabledShimCapabilities[capability] = true
+		}
+		logger.Debugf("Setting %s to %v", key, shimCapabilities.Disabled)
+	case ChaincodeNamespacePrefixKey:
+		namespacePrefix := &pb.ChaincodeNamespacePrefix{}
+		if err := proto.Unmarshal(configValue.Value, namespacePrefix); err != nil {
+			return fmt.Errorf("Unmarshaling error for %s: %s", key, err)
+		}
+		if !NamespacePrefixRegexp.MatchString(namespacePrefix.Prefix) {
+			return fmt.Errorf("Invalid chaincode namespace prefix [%s], it must match %s", namespacePrefix.Prefix, NamespacePrefixRegexp)
+		}
+		di.pendingConfig.namespacePrefix = namespacePrefix.Prefix
+		logger.Debugf("Setting %s to %s", key, namespacePrefix.Prefix)
+	default:
+		logger.Warningf("Uknown Peer config item with key %s", key)
+	}
 	return nil
 }
 
@@ -114,6 +169,18 @@ func (di *SharedConfigImpl) Organizations() map[string]api.ApplicationOrgConfig
 	return di.config.orgs
 }
 
+// ShimCapabilityDisabled returns true if chaincodes on the channel may not
+// send messages of the given type
+func (di *SharedConfigImpl) ShimCapabilityDisabled(msgType string) bool {
+	return di.config.disabledShimCapabilities[msgType]
+}
+
+// ChaincodeNamespacePrefix returns the prefix of the ledger namespaces and the
+// containers of the chaincodes of the channel, or "" if it has none
+func (di *SharedConfigImpl) ChaincodeNamespacePrefix() string {
+	return di.config.namespacePrefix
+}
+
 // Handler returns the associated api.Handler for the given path
 func (pm *SharedConfigImpl) Handler(path []string) (api.Handler, error) {
 	if len(path) == 0 {
@@ -127,6 +194,7 @@ func (pm *SharedConfigImpl) Handler(path []string) (api.Handler, error) {
 	org, ok := pm.pendingConfig.orgs[path[0]]
 	if !ok {
 		org = NewApplicationOrgConfig(path[0], pm.mspConfig)
+		org.(*ApplicationOrgConfig).BeginConfig()
 		pm.pendingConfig.orgs[path[0]] = org
 	}
 	return org.(*ApplicationOrgConfig), nil
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	pb "github.com/hyperledger/fabric/protos/peer"
 
 	logging "github.com/op/go-logging"
 )
@@ -63,3 +64,81 @@ func TestApplicationRollback(t *testing.T) {
 		t.Fatalf("Should have cleared pending config on rollback")
 	}
 }
+
+func TestApplicationShimCapabilities(t *testing.T) {
+	disabled := []string{pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), pb.ChaincodeMessage_INVOKE_CHAINCODE.String()}
+	validMessage := TemplateShimCapabilities(disabled).Groups[GroupKey].Values[ShimCapabilitiesKey]
+	invalidMessage := TemplateShimCapabilities([]string{pb.ChaincodeMessage_PUT_STATE.String()}).Groups[GroupKey].Values[ShimCapabilitiesKey]
+
+	m := NewSharedConfigImpl(nil)
+	m.BeginConfig()
+	if err := m.ProposeConfig(ShimCapabilitiesKey, invalidMessage); err == nil {
+		t.Fatalf("Should have failed to disable a shim capability which is not restrictable")
+	}
+	m.RollbackConfig()
+
+	m.BeginConfig()
+	if err := m.ProposeConfig(ShimCapabilitiesKey, validMessage); err != nil {
+		t.Fatalf("Error applying valid config: %s", err)
+	}
+	m.CommitConfig()
+
+	for _, capability := range disabled {
+		if !m.ShimCapabilityDisabled(capability) {
+			t.Fatalf("Expected %s to be disabled", capability)
+		}
+	}
+	if m.ShimCapabilityDisabled(pb.ChaincodeMessage_GET_STATE_BY_RANGE.String()) {
+		t.Fatalf("Expected %s to remain enabled", pb.ChaincodeMessage_GET_STATE_BY_RANGE)
+	}
+
+	// A config without the value re-enables every capability
+	m.BeginConfig()
+	m.CommitConfig()
+	if m.ShimCapabilityDisabled(disabled[0]) {
+		t.Fatalf("Expected %s to be enabled again", disabled[0])
+	}
+}
+
+func TestApplicationChaincodeNamespacePrefix(t *testing.T) {
+	m := NewSharedConfigImpl(nil)
+	m.BeginConfig()
+	invalidMessage := TemplateChaincodeNamespacePrefix("tenant/1").Groups[GroupKey].Values[ChaincodeNamespacePrefixKey]
+	if err := m.ProposeConfig(ChaincodeNamespacePrefixKey, invalidMessage); err == nil {
+		t.Fatalf("Should have failed to set a namespace prefix with a slash")
+	}
+	m.RollbackConfig()
+
+	m.BeginConfig()
+	validMessage := TemplateChaincodeNamespacePrefix("tenant1").Groups[GroupKey].Values[ChaincodeNamespacePrefixKey]
+	if err := m.ProposeConfig(ChaincodeNamespacePrefixKey, validMessage); err != nil {
+		t.Fatalf("Error applying valid config: %s", err)
+	}
+	m.CommitConfig()
+
+	if prefix := m.ChaincodeNamespacePrefix(); prefix != "tenant1" {
+		t.Fatalf("Expected namespace prefix tenant1, got %s", prefix)
+	}
+}
+
+func TestApplicationOrgs(t *testing.T) {
+	anchorPeers := []*pb.AnchorPeer{&pb.AnchorPeer{Host: "foo", Port: 234, Cert: []byte("foocert")}}
+	m := NewSharedConfigImpl(nil)
+	m.BeginConfig()
+	org, err := m.Handler([]string{"org1"})
+	if err != nil {
+		t.Fatalf("Error getting the handler of org1: %s", err)
+	}
+	if err = org.ProposeConfig(AnchorPeersKey, TemplateAnchorPeers("org1", anchorPeers).Groups[GroupKey].Groups["org1"].Values[AnchorPeersKey]); err != nil {
+		t.Fatalf("Error applying valid config: %s", err)
+	}
+	m.CommitConfig()
+
+	orgs := m.Organizations()
+	if len(orgs) != 1 || orgs["org1"] == nil {
+		t.Fatalf("Expected org1 only, got %v", orgs)
+	}
+	if peers := orgs["org1"].AnchorPeers(); len(peers) != 1 || peers[0].Host != "foo" {
+		t.Fatalf("Expected the anchor peers of org1 to be committed, got %v", peers)
+	}
+}
@@ -36,3 +36,25 @@ func configGroup(orgID string, key string, value []byte) *cb.ConfigGroup {
 func TemplateAnchorPeers(orgID string, anchorPeers []*pb.AnchorPeer) *cb.ConfigGroup {
 	return configGroup(orgID, AnchorPeersKey, utils.MarshalOrPanic(&pb.AnchorPeers{AnchorPeers: anchorPeers}))
 }
+
+// TemplateShimCapabilities creates a headerless config item disabling the given
+// shim capabilities for the chaincodes of the channel
+func TemplateShimCapabilities(disabled []string) *cb.ConfigGroup {
+	result := cb.NewConfigGroup()
+	result.Groups[GroupKey] = cb.NewConfigGroup()
+	result.Groups[GroupKey].Values[ShimCapabilitiesKey] = &cb.ConfigValue{
+		Value: utils.MarshalOrPanic(&pb.ShimCapabilities{Disabled: disabled}),
+	}
+	return result
+}
+
+// TemplateChaincodeNamespacePrefix creates a headerless config item setting the
+// prefix of the ledger namespaces and the containers of the chaincodes of the channel
+func TemplateChaincodeNamespacePrefix(prefix string) *cb.ConfigGroup {
+	result := cb.NewConfigGroup()
+	result.Groups[GroupKey] = cb.NewConfigGroup()
+	result.Groups[GroupKey].Values[ChaincodeNamespacePrefixKey] = &cb.ConfigValue{
+		Value: utils.MarshalOrPanic(&pb.ChaincodeNamespacePrefix{Prefix: prefix}),
+	}
+	return result
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/hyperledger/fabric/common/capabilities"
 	"github.com/hyperledger/fabric/common/configtx/api"
 	"github.com/hyperledger/fabric/common/configtx/handlers/application"
 	"github.com/hyperledger/fabric/common/configtx/handlers/orderer"
@@ -39,6 +40,7 @@ var Schema = &cb.ConfigGroupSchema{
 		HashingAlgorithmKey:          nil,
 		BlockDataHashingStructureKey: nil,
 		OrdererAddressesKey:          nil,
+		CapabilitiesKey:              nil,
 	},
 	Policies: map[string]*cb.ConfigPolicySchema{
 	// TODO, set appropriately once hierarchical policies are implemented
@@ -55,12 +57,20 @@ const (
 
 	// OrdererAddressesKey is the cb.ConfigItem type key name for the OrdererAddresses message
 	OrdererAddressesKey = "OrdererAddresses"
+
+	// CapabilitiesKey is the cb.ConfigItem type key name for the Capabilities message
+	CapabilitiesKey = "Capabilities"
 )
 
 // Hashing algorithm types
 const (
 	// SHAKE256 is the algorithm type for the sha3 shake256 hashing algorithm with 512 bits of output
 	SHA3Shake256 = "SHAKE256"
+
+	// SM3 is the algorithm type for the Chinese national standard SM3 hashing algorithm.
+	// It is rejected, as block headers and data are hashed with SHAKE256 whatever the
+	// hashing algorithm of the channel
+	SM3 = "SM3"
 )
 
 var logger = logging.MustGetLogger("configtx/handlers/chainconfig")
@@ -69,6 +79,11 @@ type chainConfig struct {
 	hashingAlgorithm               func(input []byte) []byte
 	blockDataHashingStructureWidth uint32
 	ordererAddresses               []string
+	capabilities                   *capabilities.Provider
+}
+
+func newChainConfig() *chainConfig {
+	return &chainConfig{capabilities: capabilities.NewProvider(nil)}
 }
 
 // SharedConfigImpl is an implementation of Manager and configtx.ConfigHandler
@@ -84,7 +99,7 @@ type SharedConfigImpl struct {
 // NewSharedConfigImpl creates a new SharedConfigImpl with the given CryptoHelper
 func NewSharedConfigImpl(ordererConfig *orderer.ManagerImpl, applicationConfig *application.SharedConfigImpl) *SharedConfigImpl {
 	return &SharedConfigImpl{
-		config:            &chainConfig{},
+		config:            newChainConfig(),
 		ordererConfig:     ordererConfig,
 		applicationConfig: applicationConfig,
 	}
@@ -105,12 +120,17 @@ func (pm *SharedConfigImpl) OrdererAddresses() []string {
 	return pm.config.ordererAddresses
 }
 
+// Capabilities returns the capabilities enabled on the channel
+func (pm *SharedConfigImpl) Capabilities() *capabilities.Provider {
+	return pm.config.capabilities
+}
+
 // BeginConfig is used to start a new config proposal
 func (pm *SharedConfigImpl) BeginConfig() {
 	if pm.pendingConfig != nil {
 		logger.Panicf("Programming error, cannot call begin in the middle of a proposal")
 	}
-	pm.pendingConfig = &chainConfig{}
+	pm.pendingConfig = newChainConfig()
 }
 
 // RollbackConfig is used to abandon a new config proposal
@@ -138,6 +158,8 @@ func (pm *SharedConfigImpl) ProposeConfig(key string, configValue *cb.ConfigValu
 		switch hashingAlgorithm.Name {
 		case SHA3Shake256:
 			pm.pendingConfig.hashingAlgorithm = util.ComputeCryptoHash
+		case SM3:
+			return fmt.Errorf("Hashing algorithm %s is not supported, blocks are hashed with %s", SM3, SHA3Shake256)
 		default:
 			return fmt.Errorf("Unknown hashing algorithm type: %s", hashingAlgorithm.Name)
 		}
@@ -158,6 +180,16 @@ func (pm *SharedConfigImpl) ProposeConfig(key string, configValue *cb.ConfigValu
 			return fmt.Errorf("Unmarshaling error for HashingAlgorithm: %s", err)
 		}
 		pm.pendingConfig.ordererAddresses = ordererAddresses.Addresses
+	case CapabilitiesKey:
+		capabilitiesConfig := &cb.Capabilities{}
+		if err := proto.Unmarshal(configValue.Value, capabilitiesConfig); err != nil {
+			return fmt.Errorf("Unmarshaling error for Capabilities: %s", err)
+		}
+		provider := capabilities.NewProvider(capabilitiesConfig)
+		if err := provider.Supported(); err != nil {
+			return err
+		}
+		pm.pendingConfig.capabilities = provider
 	default:
 		logger.Warningf("Uknown Chain config item with key %s", key)
 	}
@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/hyperledger/fabric/common/capabilities"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
 	cb "github.com/hyperledger/fabric/protos/common"
 
@@ -109,6 +110,18 @@ func TestHashingAlgorithm(t *testing.T) {
 	}
 }
 
+func TestSM3HashingAlgorithm(t *testing.T) {
+	m := NewSharedConfigImpl(nil, nil)
+	m.BeginConfig()
+	defer m.RollbackConfig()
+
+	// SM3 is rejected until blocks are hashed with the hashing algorithm of the channel
+	err := m.ProposeConfig(groupToKeyValue(TemplateHashingAlgorithm(SM3)))
+	if err == nil {
+		t.Fatalf("SM3 hashing algorithm should have been rejected")
+	}
+}
+
 func TestBlockDataHashingStructure(t *testing.T) {
 	invalidMessage := makeInvalidConfigValue()
 	invalidWidth := TemplateBlockDataHashingStructure(0)
@@ -161,3 +174,29 @@ func TestOrdererAddresses(t *testing.T) {
 		t.Fatalf("Unexpected width, got %s expected %s", newAddrs, defaultOrdererAddresses)
 	}
 }
+
+func TestCapabilities(t *testing.T) {
+	m := NewSharedConfigImpl(nil, nil)
+	if m.Capabilities().ReadSetValidation() {
+		t.Fatalf("Should have no capabilities enabled by default")
+	}
+	m.BeginConfig()
+
+	if err := m.ProposeConfig(CapabilitiesKey, makeInvalidConfigValue()); err == nil {
+		t.Fatalf("Should have failed on invalid message")
+	}
+
+	if err := m.ProposeConfig(groupToKeyValue(TemplateCapabilities("Unsupported"))); err == nil {
+		t.Fatalf("Should have failed on unsupported capability")
+	}
+
+	if err := m.ProposeConfig(groupToKeyValue(TemplateCapabilities(capabilities.ReadSetValidation))); err != nil {
+		t.Fatalf("Error applying valid config: %s", err)
+	}
+
+	m.CommitConfig()
+
+	if !m.Capabilities().ReadSetValidation() {
+		t.Fatalf("Should have enabled the ReadSetValidation capability")
+	}
+}
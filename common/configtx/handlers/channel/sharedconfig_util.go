@@ -67,3 +67,8 @@ func TemplateOrdererAddresses(addresses []string) *cb.ConfigGroup {
 func DefaultOrdererAddresses() *cb.ConfigGroup {
 	return TemplateOrdererAddresses(defaultOrdererAddresses)
 }
+
+// TemplateCapabilities creates a headerless config item representing the capabilities enabled on the channel
+func TemplateCapabilities(capabilities ...string) *cb.ConfigGroup {
+	return configGroup(CapabilitiesKey, utils.MarshalOrPanic(&cb.Capabilities{Capabilities: capabilities}))
+}
@@ -0,0 +1,409 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// EncodeConfigGroupJSON encodes a config group tree in a canonical JSON form,
+// suited to keeping channel configurations under version control. The fields
+// are named as in their proto definitions and sorted, and the enums are named.
+// The config values, the policies and the other bytes fields holding the
+// messages the Printer decodes are encoded as JSON objects, as long as their
+// bytes are exactly those the message marshals to, and in base64 otherwise, so
+// that DecodeConfigGroupJSON rebuilds the same config group
+func EncodeConfigGroupJSON(group *cb.ConfigGroup) ([]byte, error) {
+	if group == nil {
+		return nil, fmt.Errorf("Nothing to encode")
+	}
+	tree, err := encodeMessage(group)
+	if err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("Error encoding config group: %s", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// DecodeConfigGroupJSON parses a config group tree encoded by
+// EncodeConfigGroupJSON, marshaling back the messages nested in its bytes fields.
+// Unknown field names are rejected rather than ignored
+func DecodeConfigGroupJSON(data []byte) (*cb.ConfigGroup, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	tree := make(map[string]interface{})
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("Error parsing config group: %s", err)
+	}
+	group := &cb.ConfigGroup{}
+	if err := decodeMessage(tree, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func encodeMessage(msg proto.Message) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	v := reflect.ValueOf(msg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		if isZero(value) {
+			continue
+		}
+		if field.Tag.Get("protobuf_oneof") != "" {
+			// the field set in a oneof is encoded even if zero
+			field = value.Elem().Elem().Type().Field(0)
+			value = value.Elem().Elem().Field(0)
+		}
+		name := fieldName(field)
+		if name == "" {
+			continue
+		}
+		encoded, err := encodeField(msg, name, field, value)
+		if err != nil {
+			return nil, err
+		}
+		tree[name] = encoded
+	}
+	return tree, nil
+}
+
+// encodeField encodes the field name of msg holding value, or one of its elements
+func encodeField(msg proto.Message, name string, field reflect.StructField, value reflect.Value) (interface{}, error) {
+	if raw, ok := value.Interface().([]byte); ok {
+		if nested := nestedMessage(msg, name); nested != nil && unmarshalExactly(raw, nested) {
+			return encodeMessage(nested)
+		}
+		return base64.StdEncoding.EncodeToString(raw), nil
+	}
+	if enumType(field) != "" {
+		return value.Interface().(fmt.Stringer).String(), nil
+	}
+	if enum := enumName(msg, name, value); enum != "" {
+		return enum, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		return encodeMessage(value.Interface().(proto.Message))
+	case reflect.Slice:
+		list := make([]interface{}, value.Len())
+		for i := range list {
+			encoded, err := encodeField(msg, name, field, value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = encoded
+		}
+		return list, nil
+	case reflect.Map:
+		entries := make(map[string]interface{}, value.Len())
+		for _, key := range value.MapKeys() {
+			var encoded interface{}
+			var err error
+			if configValue, ok := value.MapIndex(key).Interface().(*cb.ConfigValue); ok {
+				encoded, err = encodeConfigValue(key.String(), configValue)
+			} else {
+				encoded, err = encodeMessage(value.MapIndex(key).Interface().(proto.Message))
+			}
+			if err != nil {
+				return nil, fmt.Errorf("Error encoding %s %s: %s", name, key.String(), err)
+			}
+			entries[key.String()] = encoded
+		}
+		return entries, nil
+	}
+	return value.Interface(), nil
+}
+
+// encodeConfigValue encodes a config value, decoding its value according to its key
+func encodeConfigValue(key string, configValue *cb.ConfigValue) (map[string]interface{}, error) {
+	tree, err := encodeMessage(configValue)
+	if err != nil {
+		return nil, err
+	}
+	if newMessage, ok := configValueMessages[key]; ok && len(configValue.Value) > 0 {
+		if msg := newMessage(); unmarshalExactly(configValue.Value, msg) {
+			if tree["value"], err = encodeMessage(msg); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tree, nil
+}
+
+// unmarshalExactly unmarshals raw into msg and reports whether msg marshals back to raw
+func unmarshalExactly(raw []byte, msg proto.Message) bool {
+	if proto.Unmarshal(raw, msg) != nil {
+		return false
+	}
+	remarshaled, err := proto.Marshal(msg)
+	return err == nil && bytes.Equal(remarshaled, raw)
+}
+
+// enumType returns the name of the enum type of a field declared as an enum, or ""
+func enumType(field reflect.StructField) string {
+	for _, part := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "enum=") {
+			return strings.TrimPrefix(part, "enum=")
+		}
+	}
+	return ""
+}
+
+func decodeMessage(tree map[string]interface{}, msg proto.Message) error {
+	v := reflect.ValueOf(msg).Elem()
+	decoded := make(map[string]bool, len(tree))
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Tag.Get("protobuf_oneof") != "" {
+			if err := decodeOneof(tree, msg, v.Field(i), decoded); err != nil {
+				return err
+			}
+			continue
+		}
+		name := fieldName(field)
+		encoded, ok := tree[name]
+		if name == "" || !ok {
+			continue
+		}
+		if err := decodeField(msg, name, field, encoded, v.Field(i)); err != nil {
+			return fmt.Errorf("Error decoding %s of %T: %s", name, msg, err)
+		}
+		decoded[name] = true
+	}
+	for name := range tree {
+		if !decoded[name] {
+			return fmt.Errorf("Unknown field %s of %T", name, msg)
+		}
+	}
+	return nil
+}
+
+// decodeOneof sets the oneof field of msg to the wrapper of the first of its fields found in tree
+func decodeOneof(tree map[string]interface{}, msg proto.Message, oneof reflect.Value, decoded map[string]bool) error {
+	oneofMessage, ok := msg.(interface {
+		XXX_OneofFuncs() (func(proto.Message, *proto.Buffer) error, func(proto.Message, int, int, *proto.Buffer) (bool, error), func(proto.Message) int, []interface{})
+	})
+	if !ok {
+		return fmt.Errorf("Unsupported oneof in %T", msg)
+	}
+	_, _, _, wrappers := oneofMessage.XXX_OneofFuncs()
+	for _, wrapper := range wrappers {
+		wrapperType := reflect.TypeOf(wrapper)
+		if !wrapperType.AssignableTo(oneof.Type()) {
+			continue
+		}
+		field := wrapperType.Elem().Field(0)
+		name := fieldName(field)
+		encoded, ok := tree[name]
+		if !ok {
+			continue
+		}
+		value := reflect.New(wrapperType.Elem())
+		if err := decodeField(msg, name, field, encoded, value.Elem().Field(0)); err != nil {
+			return fmt.Errorf("Error decoding %s of %T: %s", name, msg, err)
+		}
+		oneof.Set(value)
+		decoded[name] = true
+		return nil
+	}
+	return nil
+}
+
+// decodeField sets target, the field name of msg or one of its elements, to its encoded value
+func decodeField(msg proto.Message, name string, field reflect.StructField, encoded interface{}, target reflect.Value) error {
+	if target.Type() == reflect.TypeOf([]byte{}) {
+		raw, err := decodeBytes(msg, name, encoded)
+		if err != nil {
+			return err
+		}
+		target.SetBytes(raw)
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.Int32:
+		if enum, ok := encoded.(string); ok {
+			value, err := decodeEnum(msg, name, field, enum)
+			if err != nil {
+				return err
+			}
+			target.SetInt(int64(value))
+			return nil
+		}
+		fallthrough
+	case reflect.Int64:
+		number, ok := encoded.(json.Number)
+		if !ok {
+			return fmt.Errorf("Expected a number, got %v", encoded)
+		}
+		value, err := number.Int64()
+		if err != nil {
+			return err
+		}
+		target.SetInt(value)
+	case reflect.Uint32, reflect.Uint64:
+		number, ok := encoded.(json.Number)
+		if !ok {
+			return fmt.Errorf("Expected a number, got %v", encoded)
+		}
+		value, err := strconv.ParseUint(number.String(), 10, 64)
+		if err != nil {
+			return err
+		}
+		target.SetUint(value)
+	case reflect.Bool:
+		value, ok := encoded.(bool)
+		if !ok {
+			return fmt.Errorf("Expected a boolean, got %v", encoded)
+		}
+		target.SetBool(value)
+	case reflect.String:
+		value, ok := encoded.(string)
+		if !ok {
+			return fmt.Errorf("Expected a string, got %v", encoded)
+		}
+		target.SetString(value)
+	case reflect.Ptr:
+		tree, ok := encoded.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("Expected an object, got %v", encoded)
+		}
+		value := reflect.New(target.Type().Elem())
+		if err := decodeMessage(tree, value.Interface().(proto.Message)); err != nil {
+			return err
+		}
+		target.Set(value)
+	case reflect.Slice:
+		list, ok := encoded.([]interface{})
+		if !ok {
+			return fmt.Errorf("Expected a list, got %v", encoded)
+		}
+		value := reflect.MakeSlice(target.Type(), len(list), len(list))
+		for i, element := range list {
+			if err := decodeField(msg, name, field, element, value.Index(i)); err != nil {
+				return err
+			}
+		}
+		target.Set(value)
+	case reflect.Map:
+		entries, ok := encoded.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("Expected an object, got %v", encoded)
+		}
+		value := reflect.MakeMap(target.Type())
+		for key, entry := range entries {
+			tree, ok := entry.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("Expected an object for %s, got %v", key, entry)
+			}
+			element := reflect.New(target.Type().Elem().Elem())
+			var err error
+			if configValue, ok := element.Interface().(*cb.ConfigValue); ok {
+				err = decodeConfigValue(key, tree, configValue)
+			} else {
+				err = decodeMessage(tree, element.Interface().(proto.Message))
+			}
+			if err != nil {
+				return fmt.Errorf("Error decoding %s: %s", key, err)
+			}
+			value.SetMapIndex(reflect.ValueOf(key), element)
+		}
+		target.Set(value)
+	default:
+		return fmt.Errorf("Unsupported field type %s", target.Type())
+	}
+	return nil
+}
+
+// decodeBytes returns the bytes encoded in base64 or the marshaled message encoded as an object
+func decodeBytes(msg proto.Message, name string, encoded interface{}) ([]byte, error) {
+	switch value := encoded.(type) {
+	case string:
+		return base64.StdEncoding.DecodeString(value)
+	case map[string]interface{}:
+		nested := nestedMessage(msg, name)
+		if nested == nil {
+			return nil, fmt.Errorf("Expected base64 bytes, got an object")
+		}
+		if err := decodeMessage(value, nested); err != nil {
+			return nil, err
+		}
+		return proto.Marshal(nested)
+	}
+	return nil, fmt.Errorf("Expected base64 bytes or an object, got %v", encoded)
+}
+
+// decodeEnum returns the value of an enum encoded by name
+func decodeEnum(msg proto.Message, name string, field reflect.StructField, enum string) (int32, error) {
+	var values map[string]int32
+	if typeName := enumType(field); typeName != "" {
+		values = proto.EnumValueMap(typeName)
+	} else {
+		values = enumValues(msg, name)
+	}
+	value, ok := values[enum]
+	if !ok {
+		return 0, fmt.Errorf("Unknown enum value %s", enum)
+	}
+	return value, nil
+}
+
+// decodeConfigValue decodes a config value, marshaling its value according to its key
+func decodeConfigValue(key string, tree map[string]interface{}, configValue *cb.ConfigValue) error {
+	nested, ok := tree["value"].(map[string]interface{})
+	if !ok {
+		return decodeMessage(tree, configValue)
+	}
+	newMessage, ok := configValueMessages[key]
+	if !ok {
+		return fmt.Errorf("Unknown message for config value %s, expected base64 bytes", key)
+	}
+	withoutValue := make(map[string]interface{}, len(tree))
+	for name, encoded := range tree {
+		if name != "value" {
+			withoutValue[name] = encoded
+		}
+	}
+	if err := decodeMessage(withoutValue, configValue); err != nil {
+		return err
+	}
+	msg := newMessage()
+	if err := decodeMessage(nested, msg); err != nil {
+		return err
+	}
+	value, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	configValue.Value = value
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	configtxorderer "github.com/hyperledger/fabric/common/configtx/handlers/orderer"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGroupJSONRoundTrip(t *testing.T) {
+	group := printerTestConfigUpdate().WriteSet
+	group.ModPolicy = "Admins"
+	// a value which does not marshal back to the same bytes is kept in base64
+	group.Groups[configtxorderer.GroupKey].Values[configtxorderer.BatchTimeoutKey] = &cb.ConfigValue{Value: []byte{0x0a, 0x01}}
+
+	data, err := EncodeConfigGroupJSON(group)
+	assert.NoError(t, err)
+	decoded, err := DecodeConfigGroupJSON(data)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(group, decoded), "Expected %s, got %s", group, decoded)
+
+	reencoded, err := EncodeConfigGroupJSON(decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), string(reencoded), "The encoding should be canonical")
+
+	tree := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(data, &tree))
+	orderer := tree["groups"].(map[string]interface{})["Orderer"].(map[string]interface{})
+	values := orderer["values"].(map[string]interface{})
+	assert.Equal(t, float64(10), values["BatchSize"].(map[string]interface{})["value"].(map[string]interface{})["maxMessageCount"])
+	assert.Equal(t, "3q0=", values["Opaque"].(map[string]interface{})["value"])
+	assert.Equal(t, "CgE=", values["BatchTimeout"].(map[string]interface{})["value"])
+	policy := orderer["groups"].(map[string]interface{})["org1"].(map[string]interface{})["policies"].(map[string]interface{})["Admins"].(map[string]interface{})["policy"].(map[string]interface{})
+	assert.Equal(t, "SIGNATURE", policy["type"])
+	principal := policy["policy"].(map[string]interface{})["identities"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "ADMIN", principal["principal"].(map[string]interface{})["Role"])
+}
+
+func TestConfigGroupJSONDecodeErrors(t *testing.T) {
+	_, err := EncodeConfigGroupJSON(nil)
+	assert.Error(t, err)
+
+	for _, data := range []string{
+		`not json`,
+		`{"version": "1"}`,
+		`{"version": -1}`,
+		`{"unknown": 1}`,
+		`{"values": {"MSP": {"value": {"unknown": 1}}}}`,
+		`{"values": {"Unknown": {"value": {"name": "foo"}}}}`,
+		`{"values": {"Opaque": {"value": "not base64"}}}`,
+		`{"policies": {"Admins": {"policy": {"type": "BOGUS"}}}}`,
+	} {
+		_, err := DecodeConfigGroupJSON([]byte(data))
+		assert.Error(t, err, data)
+	}
+
+	group, err := DecodeConfigGroupJSON([]byte(`{"version": 2, "values": {"Opaque": {"value": "3q0="}}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), group.Version)
+	assert.Equal(t, []byte{0xde, 0xad}, group.Values["Opaque"].Value)
+}
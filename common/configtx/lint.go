@@ -0,0 +1,321 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// LintSeverity tells how a problem found by LintConfig affects a channel
+type LintSeverity int
+
+const (
+	// LintWarning is a problem which is likely a mistake but leaves the channel usable
+	LintWarning LintSeverity = iota
+
+	// LintError is a policy which can never be satisfied, or a config item which can never be modified
+	LintError
+)
+
+func (s LintSeverity) String() string {
+	if s == LintError {
+		return "ERROR"
+	}
+	return "WARNING"
+}
+
+// LintIssue is a problem found by LintConfig
+type LintIssue struct {
+	Severity LintSeverity
+
+	// Path is the fully qualified path of the config item, as in "[Policy] /Channel/Admins"
+	Path string
+
+	Message string
+}
+
+func (li *LintIssue) String() string {
+	return fmt.Sprintf("%s %s: %s", li.Severity, li.Path, li.Message)
+}
+
+// byPath sorts issues by the path of their config item
+type byPath []*LintIssue
+
+func (bp byPath) Len() int           { return len(bp) }
+func (bp byPath) Swap(i, j int)      { bp[i], bp[j] = bp[j], bp[i] }
+func (bp byPath) Less(i, j int) bool { return bp[i].Path < bp[j].Path }
+
+// unbounded is the number of signers of a principal which any number of identities can satisfy
+const unbounded = -1
+
+// linter holds what LintConfig learns of a config while walking it
+type linter struct {
+	issues []*LintIssue
+
+	// msps maps the MSP identifiers defined in the config to their number of admins
+	msps map[string]int
+
+	// opaqueMSPs is set when the config defines MSPs whose identifiers cannot be read,
+	// in which case principals of unknown MSPs are not reported
+	opaqueMSPs bool
+}
+
+func (l *linter) report(severity LintSeverity, path string, format string, args ...interface{}) {
+	l.issues = append(l.issues, &LintIssue{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// LintConfig walks a channel config and reports the policies which no set of signatures
+// can satisfy, the policies which are satisfied without any signature, and the config
+// items whose mod_policy leaves them unmodifiable
+func LintConfig(config *cb.Config) ([]*LintIssue, error) {
+	if config == nil || config.Channel == nil {
+		return nil, fmt.Errorf("Config must have a channel group")
+	}
+	configMap, err := mapConfig(config.Channel)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(configMap))
+	for path := range configMap {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	l := &linter{msps: make(map[string]int)}
+	for _, path := range paths {
+		item := configMap[path]
+		if item.ConfigValue == nil || item.key != MSPKey {
+			continue
+		}
+		_, fabricConfig, err := unmarshalFabricMSPConfig(item.ConfigValue)
+		switch {
+		case err != nil:
+			l.report(LintError, path, "%s", err)
+		case fabricConfig == nil:
+			l.opaqueMSPs = true
+		default:
+			l.msps[fabricConfig.Name] = len(fabricConfig.Admins)
+		}
+	}
+
+	// Policies are looked up by their key alone, wherever they are defined
+	policyPaths := make(map[string][]string)
+	satisfiable := make(map[string]bool)
+	for _, path := range paths {
+		item := configMap[path]
+		if item.ConfigPolicy == nil {
+			continue
+		}
+		policyPaths[item.key] = append(policyPaths[item.key], path)
+		satisfiable[item.key] = l.lintPolicy(path, item.ConfigPolicy.Policy) || satisfiable[item.key]
+	}
+	for key, defined := range policyPaths {
+		if len(defined) > 1 {
+			l.report(LintWarning, defined[0], "Policy %s is also defined at %v, only one of them is in effect", key, defined[1:])
+		}
+	}
+
+	for _, path := range paths {
+		modPolicy := configMap[path].modPolicy()
+		switch {
+		case modPolicy == "":
+			l.report(LintWarning, path, "Has no mod_policy and can never be modified")
+		case policyPaths[modPolicy] == nil:
+			l.report(LintError, path, "Mod_policy %s is not defined, the item can never be modified", modPolicy)
+		case !satisfiable[modPolicy]:
+			l.report(LintError, path, "Mod_policy %s can never be satisfied, the item can never be modified", modPolicy)
+		}
+	}
+
+	sort.Stable(byPath(l.issues))
+	return l.issues, nil
+}
+
+// lintPolicy reports the problems of the policy at path and returns whether it can be satisfied
+func (l *linter) lintPolicy(path string, policy *cb.Policy) bool {
+	if policy == nil {
+		l.report(LintError, path, "Has no policy")
+		return false
+	}
+	if policy.Type != int32(cb.Policy_SIGNATURE) {
+		l.report(LintError, path, "Policy type %d is not supported", policy.Type)
+		return false
+	}
+	envelope := &cb.SignaturePolicyEnvelope{}
+	if err := proto.Unmarshal(policy.Policy, envelope); err != nil {
+		l.report(LintError, path, "Error unmarshaling signature policy: %s", err)
+		return false
+	}
+	if envelope.Policy == nil {
+		l.report(LintError, path, "Signature policy is empty")
+		return false
+	}
+
+	signers := make([]int, len(envelope.Identities))
+	for i, principal := range envelope.Identities {
+		signers[i] = l.signers(path, principal)
+	}
+	var reasons []string
+	satisfiable := l.satisfiable(envelope.Policy, envelope.Identities, signers, &reasons)
+	severity := LintWarning
+	if !satisfiable {
+		severity = LintError
+		reasons = append(reasons, "no set of signatures can satisfy the policy")
+	}
+	seen := make(map[string]bool)
+	for _, reason := range reasons {
+		if !seen[reason] {
+			seen[reason] = true
+			l.report(severity, path, "%s", reason)
+		}
+	}
+	if satisfiable && unsigned(envelope.Policy) {
+		l.report(LintWarning, path, "Policy is satisfied without any signature")
+	}
+	return satisfiable
+}
+
+// signers returns how many distinct identities can satisfy principal, or unbounded
+func (l *linter) signers(path string, principal *cb.MSPPrincipal) int {
+	var mspID string
+	admin := false
+	switch principal.PrincipalClassification {
+	case cb.MSPPrincipal_ROLE:
+		role := &cb.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err != nil {
+			l.report(LintError, path, "Error unmarshaling MSP role principal: %s", err)
+			return 0
+		}
+		mspID = role.MspIdentifier
+		admin = role.Role == cb.MSPRole_ADMIN
+	case cb.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &cb.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err != nil {
+			l.report(LintError, path, "Error unmarshaling organization unit principal: %s", err)
+			return 0
+		}
+		mspID = ou.MspIdentifier
+	case cb.MSPPrincipal_IDENTITY:
+		id := &msp.SerializedIdentity{}
+		if err := proto.Unmarshal(principal.Principal, id); err != nil {
+			l.report(LintError, path, "Error unmarshaling identity principal: %s", err)
+			return 0
+		}
+		mspID = id.Mspid
+	default:
+		l.report(LintError, path, "Unknown principal classification %d", principal.PrincipalClassification)
+		return 0
+	}
+
+	admins, ok := l.msps[mspID]
+	switch {
+	case !ok && l.opaqueMSPs:
+		return unbounded
+	case !ok:
+		l.report(LintError, path, "Requires a signature of MSP %s, which is not defined in the config", mspID)
+		return 0
+	case principal.PrincipalClassification == cb.MSPPrincipal_IDENTITY:
+		return 1
+	case admin && admins == 0:
+		l.report(LintError, path, "Requires a signature of an admin of MSP %s, which has no admin certificates", mspID)
+		return 0
+	case admin:
+		return admins
+	}
+	return unbounded
+}
+
+// satisfiable returns whether some set of signatures satisfies policy, appending to reasons
+// why its unsatisfiable sub-policies are so. As a signature is used at most once by a policy,
+// an NOutOf of principals needs N distinct signers amongst them
+func (l *linter) satisfiable(policy *cb.SignaturePolicy, identities []*cb.MSPPrincipal, signers []int, reasons *[]string) bool {
+	switch t := policy.Type.(type) {
+	case *cb.SignaturePolicy_SignedBy:
+		if t.SignedBy < 0 || t.SignedBy >= int32(len(identities)) {
+			*reasons = append(*reasons, fmt.Sprintf("Identity index %d is out of range of the %d identities", t.SignedBy, len(identities)))
+			return false
+		}
+		return signers[t.SignedBy] != 0
+	case *cb.SignaturePolicy_From:
+		if t.From == nil {
+			*reasons = append(*reasons, "NOutOf has no sub-policies")
+			return false
+		}
+		satisfied := int32(0)
+		for _, sub := range t.From.Policies {
+			if l.satisfiable(sub, identities, signers, reasons) {
+				satisfied++
+			}
+		}
+		if satisfied < t.From.N {
+			*reasons = append(*reasons, fmt.Sprintf("Requires %d of %d sub-policies, only %d of which can be satisfied", t.From.N, len(t.From.Policies), satisfied))
+			return false
+		}
+		if distinct, ok := distinctSigners(t.From.Policies, identities, signers); ok && distinct < t.From.N {
+			*reasons = append(*reasons, fmt.Sprintf("Requires %d distinct signers, but only %d identities can satisfy its principals", t.From.N, distinct))
+			return false
+		}
+		return true
+	default:
+		*reasons = append(*reasons, fmt.Sprintf("Unknown signature policy type %T", t))
+		return false
+	}
+}
+
+// distinctSigners returns how many of policies distinct identities can satisfy when they
+// are all satisfied by a single principal, ok is false otherwise
+func distinctSigners(policies []*cb.SignaturePolicy, identities []*cb.MSPPrincipal, signers []int) (distinct int32, ok bool) {
+	count := make(map[string]int)
+	capacity := make(map[string]int)
+	for _, policy := range policies {
+		signedBy, isSignedBy := policy.Type.(*cb.SignaturePolicy_SignedBy)
+		if !isSignedBy || signedBy.SignedBy < 0 || signedBy.SignedBy >= int32(len(identities)) {
+			return 0, false
+		}
+		key := identities[signedBy.SignedBy].String()
+		count[key]++
+		capacity[key] = signers[signedBy.SignedBy]
+	}
+	for key, n := range count {
+		if capacity[key] != unbounded && capacity[key] < n {
+			n = capacity[key]
+		}
+		distinct += int32(n)
+	}
+	return distinct, true
+}
+
+// unsigned returns whether policy is satisfied by an empty set of signatures
+func unsigned(policy *cb.SignaturePolicy) bool {
+	from, ok := policy.Type.(*cb.SignaturePolicy_From)
+	if !ok || from.From == nil {
+		return false
+	}
+	satisfied := int32(0)
+	for _, sub := range from.From.Policies {
+		if unsigned(sub) {
+			satisfied++
+		}
+	}
+	return satisfied >= from.From.N
+}
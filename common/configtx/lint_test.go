@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func mspValue(name string, admins int) *cb.ConfigValue {
+	fabricConfig := &mspprotos.FabricMSPConfig{Name: name}
+	for i := 0; i < admins; i++ {
+		fabricConfig.Admins = append(fabricConfig.Admins, []byte("admin"))
+	}
+	return &cb.ConfigValue{
+		ModPolicy: "Admins",
+		Value:     utils.MarshalOrPanic(&mspprotos.MSPConfig{Type: int32(msp.FABRIC), Config: utils.MarshalOrPanic(fabricConfig)}),
+	}
+}
+
+func rolePrincipal(mspID string, role cb.MSPRole_MSPRoleType) *cb.MSPPrincipal {
+	return &cb.MSPPrincipal{
+		PrincipalClassification: cb.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&cb.MSPRole{MspIdentifier: mspID, Role: role}),
+	}
+}
+
+func signaturePolicy(policy *cb.SignaturePolicy, identities ...*cb.MSPPrincipal) *cb.ConfigPolicy {
+	return &cb.ConfigPolicy{
+		ModPolicy: "Admins",
+		Policy: &cb.Policy{
+			Type:   int32(cb.Policy_SIGNATURE),
+			Policy: utils.MarshalOrPanic(&cb.SignaturePolicyEnvelope{Policy: policy, Identities: identities}),
+		},
+	}
+}
+
+func lintIssues(t *testing.T, channel *cb.ConfigGroup) map[string][]string {
+	issues, err := LintConfig(&cb.Config{Channel: channel})
+	assert.NoError(t, err)
+	result := make(map[string][]string)
+	for _, issue := range issues {
+		result[issue.Path] = append(result[issue.Path], issue.Severity.String()+" "+issue.Message)
+	}
+	return result
+}
+
+func TestLintConfigClean(t *testing.T) {
+	channel := cb.NewConfigGroup()
+	channel.ModPolicy = "Admins"
+	org := cb.NewConfigGroup()
+	org.ModPolicy = "Admins"
+	org.Values[MSPKey] = mspValue("Org1MSP", 2)
+	channel.Groups["Org1"] = org
+	channel.Policies["Admins"] = signaturePolicy(cauthdsl.And(cauthdsl.SignedBy(0), cauthdsl.SignedBy(1)),
+		rolePrincipal("Org1MSP", cb.MSPRole_ADMIN), rolePrincipal("Org1MSP", cb.MSPRole_ADMIN))
+
+	assert.Empty(t, lintIssues(t, channel))
+}
+
+func TestLintConfig(t *testing.T) {
+	channel := cb.NewConfigGroup()
+	channel.ModPolicy = "Admins"
+	org := cb.NewConfigGroup()
+	org.ModPolicy = "Missing"
+	org.Values[MSPKey] = mspValue("Org1MSP", 0)
+	org.Policies["Readers"] = signaturePolicy(cauthdsl.SignedBy(0), rolePrincipal("Org1MSP", cb.MSPRole_MEMBER))
+	channel.Groups["Org1"] = org
+	channel.Values["Unmodifiable"] = &cb.ConfigValue{}
+	channel.Policies["Admins"] = signaturePolicy(cauthdsl.SignedBy(0), rolePrincipal("Org1MSP", cb.MSPRole_ADMIN))
+	channel.Policies["Writers"] = signaturePolicy(cauthdsl.NOutOf(0, nil))
+	channel.Policies["Readers"] = signaturePolicy(cauthdsl.NOutOf(2, []*cb.SignaturePolicy{cauthdsl.SignedBy(0), cauthdsl.SignedBy(0)}),
+		rolePrincipal("Org1MSP", cb.MSPRole_MEMBER))
+	channel.Policies["Mystery"] = signaturePolicy(cauthdsl.Or(cauthdsl.SignedBy(0), cauthdsl.SignedBy(3)), rolePrincipal("Org2MSP", cb.MSPRole_MEMBER))
+
+	issues := lintIssues(t, channel)
+	assert.Equal(t, []string{
+		"ERROR Requires a signature of an admin of MSP Org1MSP, which has no admin certificates",
+		"ERROR no set of signatures can satisfy the policy",
+		"ERROR Mod_policy Admins can never be satisfied, the item can never be modified",
+	}, issues["[Policy] /Channel/Admins"])
+	assert.Equal(t, []string{
+		"WARNING Policy is satisfied without any signature",
+		"ERROR Mod_policy Admins can never be satisfied, the item can never be modified",
+	}, issues["[Policy] /Channel/Writers"])
+	assert.Equal(t, []string{
+		"ERROR Requires a signature of MSP Org2MSP, which is not defined in the config",
+		"ERROR Identity index 3 is out of range of the 1 identities",
+		"ERROR Requires 1 of 2 sub-policies, only 0 of which can be satisfied",
+		"ERROR no set of signatures can satisfy the policy",
+		"ERROR Mod_policy Admins can never be satisfied, the item can never be modified",
+	}, issues["[Policy] /Channel/Mystery"])
+	assert.Equal(t, []string{
+		"WARNING Policy Readers is also defined at [[Policy] /Channel/Readers], only one of them is in effect",
+		"ERROR Mod_policy Admins can never be satisfied, the item can never be modified",
+	}, issues["[Policy] /Channel/Org1/Readers"])
+	assert.Equal(t, []string{"ERROR Mod_policy Missing is not defined, the item can never be modified"}, issues["[Groups] /Channel/Org1"])
+	assert.Equal(t, []string{"WARNING Has no mod_policy and can never be modified"}, issues["[Values] /Channel/Unmodifiable"])
+}
+
+func TestLintConfigDistinctSigners(t *testing.T) {
+	channel := cb.NewConfigGroup()
+	channel.ModPolicy = "Admins"
+	org := cb.NewConfigGroup()
+	org.ModPolicy = "Admins"
+	org.Values[MSPKey] = mspValue("Org1MSP", 1)
+	channel.Groups["Org1"] = org
+	channel.Policies["Admins"] = signaturePolicy(cauthdsl.And(cauthdsl.SignedBy(0), cauthdsl.SignedBy(0)), rolePrincipal("Org1MSP", cb.MSPRole_ADMIN))
+
+	assert.Contains(t, lintIssues(t, channel)["[Policy] /Channel/Admins"],
+		"ERROR Requires 2 distinct signers, but only 1 identities can satisfy its principals")
+}
@@ -17,14 +17,23 @@ limitations under the License.
 package configtx
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 
+	"github.com/hyperledger/fabric/common/capabilities"
 	"github.com/hyperledger/fabric/common/configtx/api"
+	configtxapplication "github.com/hyperledger/fabric/common/configtx/handlers/application"
+	configtxchannel "github.com/hyperledger/fabric/common/configtx/handlers/channel"
 	"github.com/hyperledger/fabric/common/policies"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 
+	"github.com/golang/protobuf/proto"
 	logging "github.com/op/go-logging"
 )
 
@@ -43,37 +52,36 @@ var (
 // NewConfigItemPolicyKey is the ID of the policy used when no other policy can be resolved, for instance when attempting to create a new config item
 const NewConfigItemPolicyKey = "NewConfigItemPolicy"
 
+// configHistorySize is the number of committed configs, the current one included, which can be rolled back to
+const configHistorySize = 10
+
 type acceptAllPolicy struct{}
 
 func (ap *acceptAllPolicy) Evaluate(signedData []*cb.SignedData) error {
 	return nil
 }
 
+// configManager serializes the config updates, Validate, Simulate, Apply and Rollback, which drive the handlers
+// through their proposals. The readers of the current config don't wait for the updates: they read the snapshot
+// of the last config committed, which is replaced as a whole, and never modified, by the commit of the next one
 type configManager struct {
 	api.Resources
+	chainID     string
+	listeners   []api.ConfigListener
+	initializer api.Initializer
+
+	// lock serializes the updates, it protects history
+	lock    sync.Mutex
+	current atomic.Value // *committedConfig
+	history []*committedConfig
+}
+
+// committedConfig is a config committed by the manager, which it can be rolled back to. It is immutable
+type committedConfig struct {
 	sequence     uint64
-	chainID      string
 	config       map[string]comparable
-	callOnUpdate []func(api.Manager)
-	initializer  api.Initializer
 	configEnv    *cb.ConfigEnvelope
-}
-
-func computeSequence(configGroup *cb.ConfigGroup) uint64 {
-	max := uint64(0)
-	for _, value := range configGroup.Values {
-		if value.Version > max {
-			max = value.Version
-		}
-	}
-
-	for _, group := range configGroup.Groups {
-		if groupMax := computeSequence(group); groupMax > max {
-			max = groupMax
-		}
-	}
-
-	return max
+	configDigest []byte
 }
 
 // validateChainID makes sure that proposed chain IDs (i.e. channel names)
@@ -106,7 +114,9 @@ func validateChainID(chainID string) error {
 	return nil
 }
 
-func NewManagerImpl(configEnv *cb.ConfigEnvelope, initializer api.Initializer, callOnUpdate []func(api.Manager)) (api.Manager, error) {
+// NewManagerImpl creates a Manager of the config of configEnv, which the listeners are notified of
+// the commit of as they are for the updates
+func NewManagerImpl(configEnv *cb.ConfigEnvelope, initializer api.Initializer, listeners []api.ConfigListener) (api.Manager, error) {
 	if configEnv == nil {
 		return nil, fmt.Errorf("Nil config envelope")
 	}
@@ -129,12 +139,10 @@ func NewManagerImpl(configEnv *cb.ConfigEnvelope, initializer api.Initializer, c
 	}
 
 	cm := &configManager{
-		Resources:    initializer,
-		initializer:  initializer,
-		sequence:     computeSequence(configEnv.Config.Channel),
-		chainID:      configEnv.Config.Header.ChannelId,
-		config:       configMap,
-		callOnUpdate: callOnUpdate,
+		Resources:   initializer,
+		initializer: initializer,
+		chainID:     configEnv.Config.Header.ChannelId,
+		listeners:   listeners,
 	}
 
 	cm.beginHandlers()
@@ -142,11 +150,32 @@ func NewManagerImpl(configEnv *cb.ConfigEnvelope, initializer api.Initializer, c
 		cm.rollbackHandlers()
 		return nil, err
 	}
-	cm.commitHandlers()
+	cm.commit(&committedConfig{
+		sequence:     utils.ComputeConfigSequence(configEnv.Config.Channel),
+		config:       configMap,
+		configEnv:    configEnv,
+		configDigest: computeConfigDigest(configEnv.Config.Header.ChannelId, configMap),
+	})
 
 	return cm, nil
 }
 
+// snapshot returns the last config committed
+func (cm *configManager) snapshot() *committedConfig {
+	return cm.current.Load().(*committedConfig)
+}
+
+// commit makes committed the current config, records it in the history, forgetting the oldest config if the
+// history is full, and commits the proposal of the handlers. The listeners are then notified, reading the new config
+func (cm *configManager) commit(committed *committedConfig) {
+	cm.current.Store(committed)
+	cm.history = append(cm.history, committed)
+	if len(cm.history) > configHistorySize {
+		cm.history = cm.history[len(cm.history)-configHistorySize:]
+	}
+	cm.commitHandlers()
+}
+
 func (cm *configManager) beginHandlers() {
 	logger.Debugf("Beginning new config for chain %s", cm.chainID)
 	cm.initializer.BeginConfig()
@@ -160,12 +189,43 @@ func (cm *configManager) rollbackHandlers() {
 func (cm *configManager) commitHandlers() {
 	logger.Debugf("Committing config for chain %s", cm.chainID)
 	cm.initializer.CommitConfig()
-	for _, callback := range cm.callOnUpdate {
-		callback(cm)
+	for _, listener := range cm.listeners {
+		listener.OnPostApply(cm)
+	}
+}
+
+// notifyValidate has the listeners validate configEnv, the first error rejects it
+func (cm *configManager) notifyValidate(configEnv *cb.ConfigEnvelope) error {
+	for i, listener := range cm.listeners {
+		if err := listener.OnValidate(configEnv); err != nil {
+			return fmt.Errorf("Config rejected by listener %d: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// validateListeners has the listeners validate the config configMap, resulting from the configtx update
+func (cm *configManager) validateListeners(configMap map[string]comparable, configtx *cb.Envelope) (*cb.ConfigEnvelope, error) {
+	configEnv, err := cm.configEnvelope(configMap, configtx)
+	if err != nil {
+		return nil, fmt.Errorf("Config was validated, but could not be transformed back into proto form: %s", err)
+	}
+	if err = cm.notifyValidate(configEnv); err != nil {
+		return nil, err
 	}
+	return configEnv, nil
 }
 
 func (cm *configManager) proposeConfig(config map[string]comparable) error {
+	if _, err := capabilitiesOf(config); err != nil {
+		return err
+	}
+	if current, ok := cm.current.Load().(*committedConfig); ok {
+		if err := checkNamespacePrefix(current.config, config); err != nil {
+			return err
+		}
+	}
+
 	for fqPath, c := range config {
 		logger.Debugf("Proposing: %s", fqPath)
 		switch {
@@ -188,47 +248,68 @@ func (cm *configManager) proposeConfig(config map[string]comparable) error {
 }
 
 // authorizeUpdate validates that all modified config has the corresponding modification policies satisfied by the signature set
-// it returns a map of the modified config
-func (cm *configManager) authorizeUpdate(configUpdateEnv *cb.ConfigUpdateEnvelope) (map[string]comparable, error) {
+// it returns a map of the modified config, and the set of the keys deleted
+func (cm *configManager) authorizeUpdate(configUpdateEnv *cb.ConfigUpdateEnvelope) (map[string]comparable, map[string]struct{}, error) {
 	if configUpdateEnv == nil {
-		return nil, fmt.Errorf("Cannot process nil ConfigUpdateEnvelope")
+		return nil, nil, fmt.Errorf("Cannot process nil ConfigUpdateEnvelope")
 	}
 
 	config, err := UnmarshalConfigUpdate(configUpdateEnv.ConfigUpdate)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	current := cm.snapshot()
 
 	if config.Header == nil {
-		return nil, fmt.Errorf("Must have header set")
+		return nil, nil, fmt.Errorf("Must have header set")
 	}
 
-	seq := computeSequence(config.WriteSet)
+	seq := utils.ComputeConfigSequence(config.WriteSet)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	signedData, err := configUpdateEnv.AsSignedData()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Verify config is a sequential update to prevent exhausting sequence numbers
-	if seq != cm.sequence+1 {
-		return nil, fmt.Errorf("Config sequence number jumped from %d to %d", cm.sequence, seq)
+	if seq != current.sequence+1 {
+		return nil, nil, fmt.Errorf("Config sequence number jumped from %d to %d", current.sequence, seq)
 	}
 
 	// Verify config is intended for this globally unique chain ID
 	if config.Header.ChannelId != cm.chainID {
-		return nil, fmt.Errorf("Config is for the wrong chain, expected %s, got %s", cm.chainID, config.Header.ChannelId)
+		return nil, nil, fmt.Errorf("Config is for the wrong chain, expected %s, got %s", cm.chainID, config.Header.ChannelId)
+	}
+
+	// Verify the config update was computed from the current config, once every member of the channel does
+	channelCapabilities, err := capabilitiesOf(current.config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if channelCapabilities.ReadSetValidation() {
+		if err := verifyReadSet(current.config, config.ReadSet); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	deleted, err := cm.authorizeDeletes(current.config, config.DeleteSet, signedData)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	configMap, err := mapConfig(config.WriteSet)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	for key, value := range configMap {
 		logger.Debugf("Processing key %s with value %v", key, value)
+		if _, ok := deleted[key]; ok {
+			return nil, nil, fmt.Errorf("Key %s was both written and deleted", key)
+		}
+
 		if key == "[Groups] /Channel" {
 			// XXX temporary hack to prevent group evaluation for modification
 			continue
@@ -237,12 +318,12 @@ func (cm *configManager) authorizeUpdate(configUpdateEnv *cb.ConfigUpdateEnvelop
 		// Ensure the config sequence numbers are correct to prevent replay attacks
 		var isModified bool
 
-		oldValue, ok := cm.config[key]
+		oldValue, ok := current.config[key]
 		if ok {
 			isModified = !value.equals(oldValue)
 		} else {
 			if value.version() != seq {
-				return nil, fmt.Errorf("Key %v was new, but had an older Sequence %d set", key, value.version())
+				return nil, nil, fmt.Errorf("Key %v was new, but had an older Sequence %d set", key, value.version())
 			}
 			isModified = true
 		}
@@ -252,7 +333,7 @@ func (cm *configManager) authorizeUpdate(configUpdateEnv *cb.ConfigUpdateEnvelop
 			logger.Debugf("Proposed config item %s on channel %s has been modified", key, cm.chainID)
 
 			if value.version() != seq {
-				return nil, fmt.Errorf("Key %s was modified, but its Version %d does not equal current configtx Sequence %d", key, value.version(), seq)
+				return nil, nil, fmt.Errorf("Key %s was modified, but its Version %d does not equal current configtx Sequence %d", key, value.version(), seq)
 			}
 
 			// Get the modification policy for this config item if one was previously specified
@@ -262,29 +343,177 @@ func (cm *configManager) authorizeUpdate(configUpdateEnv *cb.ConfigUpdateEnvelop
 				policy, _ = cm.PolicyManager().GetPolicy(oldValue.modPolicy())
 				// Ensure the policy is satisfied
 				if err = policy.Evaluate(signedData); err != nil {
-					return nil, err
+					return nil, nil, err
+				}
+
+				if value.ConfigValue != nil && oldValue.ConfigValue != nil && value.key == MSPKey {
+					if err = authorizeMSPRotation(key, oldValue.ConfigValue, value.ConfigValue, signedData); err != nil {
+						return nil, nil, err
+					}
 				}
 			}
 
 		}
 	}
 
-	// Ensure that any config items which used to exist still exist, to prevent implicit deletion
-	for key, _ := range cm.config {
+	// Ensure that any config items which used to exist still exist unless explicitly deleted, to prevent implicit deletion
+	for key, _ := range current.config {
 		_, ok := configMap[key]
+		_, isDeleted := deleted[key]
+		if !ok && !isDeleted {
+			return nil, nil, fmt.Errorf("Missing key %v in new config", key)
+		}
+
+	}
+
+	return configMap, deleted, nil
+}
+
+// verifyReadSet validates that the config items of the read set exist in the current config at the Version they
+// were read at, so that a config update computed from a config which has since been modified, such as a concurrent
+// reconfiguration proposal, is rejected instead of overwriting the modifications. The error names every stale key.
+// As versions ignoring the read set would accept such updates, it only applies once the ReadSetValidation
+// capability is enabled on the channel
+func verifyReadSet(current map[string]comparable, readSet *cb.ConfigGroup) error {
+	if readSet == nil {
+		return nil
+	}
+
+	readMap, err := mapConfig(readSet)
+	if err != nil {
+		return fmt.Errorf("Error converting read set to map: %s", err)
+	}
+	var stale []string
+	for key, value := range readMap {
+		oldValue, ok := current[key]
+		if !ok {
+			stale = append(stale, fmt.Sprintf("%s was read but does not exist", key))
+			continue
+		}
+		if value.version() != oldValue.version() {
+			stale = append(stale, fmt.Sprintf("%s was read at Version %d but is at Version %d", key, value.version(), oldValue.version()))
+		}
+	}
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		return fmt.Errorf("Config update read set is stale: %s", strings.Join(stale, "; "))
+	}
+	return nil
+}
+
+// capabilitiesOf returns the capabilities enabled by config, failing if this version does not support them all
+func capabilitiesOf(config map[string]comparable) (*capabilities.Provider, error) {
+	c, ok := config[ValuePrefix+PathSeparator+RootGroupKey+PathSeparator+configtxchannel.CapabilitiesKey]
+	if !ok {
+		return capabilities.NewProvider(nil), nil
+	}
+	capabilitiesConfig := &cb.Capabilities{}
+	if err := proto.Unmarshal(c.ConfigValue.Value, capabilitiesConfig); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling Capabilities: %s", err)
+	}
+	provider := capabilities.NewProvider(capabilitiesConfig)
+	if err := provider.Supported(); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// checkNamespacePrefix makes sure that the chaincode namespace prefix of the
+// proposed config is the one of the current config. The prefix names the ledger
+// namespaces and the containers of the chaincodes of the channel, so it is set
+// when the channel is created and cannot be added, changed or removed after
+func checkNamespacePrefix(current, proposed map[string]comparable) error {
+	key := ValuePrefix + PathSeparator + RootGroupKey + PathSeparator + configtxapplication.GroupKey + PathSeparator + configtxapplication.ChaincodeNamespacePrefixKey
+	currentPrefix, hadPrefix := current[key]
+	proposedPrefix, hasPrefix := proposed[key]
+	if hadPrefix != hasPrefix || (hasPrefix && !bytes.Equal(currentPrefix.ConfigValue.Value, proposedPrefix.ConfigValue.Value)) {
+		return fmt.Errorf("The chaincode namespace prefix of the channel cannot be changed once the channel is created")
+	}
+	return nil
+}
+
+// authorizeDeletes validates that the config items listed in the delete set exist at the Version given, and that
+// their modification policies, and those of the groups they are deleted from, are satisfied by the signature set.
+// A group of the delete set with members only leads to the items deleted, whereas a group without is deleted with
+// all of its members, from the current config. The MSP of an organization is only deleted with its group, so that
+// an MSP added again is either rotated, as authorizeMSPRotation checks, or added with a new group.
+// It returns the set of the keys deleted
+func (cm *configManager) authorizeDeletes(current map[string]comparable, deleteSet *cb.ConfigGroup, signedData []*cb.SignedData) (map[string]struct{}, error) {
+	deleted := make(map[string]struct{})
+	if deleteSet == nil {
+		return deleted, nil
+	}
+
+	deleteMap, err := mapConfig(deleteSet)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range deleteMap {
+		if key == GroupPrefix+PathSeparator+RootGroupKey {
+			// The root group cannot be deleted
+			continue
+		}
+		if value.ConfigGroup != nil && (len(value.Groups) > 0 || len(value.Values) > 0 || len(value.Policies) > 0) {
+			continue
+		}
+
+		oldValue, ok := current[key]
 		if !ok {
-			return nil, fmt.Errorf("Missing key %v in new config", key)
+			return nil, fmt.Errorf("Key %s was deleted, but does not exist", key)
+		}
+		if value.version() != oldValue.version() {
+			return nil, fmt.Errorf("Key %s was deleted at Version %d, but its current Version is %d", key, value.version(), oldValue.version())
 		}
 
+		if oldValue.ConfigValue != nil && oldValue.key == MSPKey {
+			return nil, fmt.Errorf("Key %s was deleted, but an MSP can only be deleted with its group", key)
+		}
+
+		logger.Debugf("Config item %s on channel %s is deleted", key, cm.chainID)
+		policy, _ := cm.PolicyManager().GetPolicy(oldValue.modPolicy())
+		if err = policy.Evaluate(signedData); err != nil {
+			return nil, err
+		}
+
+		// Deleting an item modifies the group it belongs to
+		if parent, ok := current[GroupPrefix+PathSeparator+strings.Join(oldValue.path, PathSeparator)]; ok {
+			policy, _ = cm.PolicyManager().GetPolicy(parent.modPolicy())
+			if err = policy.Evaluate(signedData); err != nil {
+				return nil, err
+			}
+		}
+
+		deleted[key] = struct{}{}
+		if value.ConfigGroup != nil {
+			groupPath := strings.TrimPrefix(key, GroupPrefix)
+			for existingKey := range current {
+				if isMemberOfGroup(existingKey, groupPath) {
+					deleted[existingKey] = struct{}{}
+				}
+			}
+		}
 	}
+	return deleted, nil
+}
 
-	return configMap, nil
+// isMemberOfGroup returns whether the config item with the given key is a member, at any depth, of the group at groupPath
+func isMemberOfGroup(key, groupPath string) bool {
+	for _, prefix := range []string{GroupPrefix, ValuePrefix, PolicyPrefix} {
+		if strings.HasPrefix(key, prefix) {
+			return strings.HasPrefix(key[len(prefix):], groupPath+PathSeparator)
+		}
+	}
+	return false
 }
 
-// computeUpdateResult takes a configMap generated by an update and produces a new configMap overlaying it onto the old config
-func (cm *configManager) computeUpdateResult(updatedConfig map[string]comparable) map[string]comparable {
+// computeUpdateResult takes a configMap generated by an update and produces a new configMap overlaying it onto the old config,
+// without the keys deleted
+func (cm *configManager) computeUpdateResult(updatedConfig map[string]comparable, deleted map[string]struct{}) map[string]comparable {
 	newConfigMap := make(map[string]comparable)
-	for key, value := range cm.config {
+	for key, value := range cm.snapshot().config {
+		if _, ok := deleted[key]; ok {
+			continue
+		}
 		newConfigMap[key] = value
 	}
 
@@ -296,11 +525,11 @@ func (cm *configManager) computeUpdateResult(updatedConfig map[string]comparable
 
 func (cm *configManager) processConfig(configtx *cb.ConfigUpdateEnvelope) (map[string]comparable, error) {
 	cm.beginHandlers()
-	configMap, err := cm.authorizeUpdate(configtx)
+	configMap, deleted, err := cm.authorizeUpdate(configtx)
 	if err != nil {
 		return nil, err
 	}
-	computedResult := cm.computeUpdateResult(configMap)
+	computedResult := cm.computeUpdateResult(configMap, deleted)
 	if err := cm.proposeConfig(computedResult); err != nil {
 		return nil, err
 	}
@@ -335,43 +564,129 @@ func (cm *configManager) Validate(configtx *cb.Envelope) error {
 	if err != nil {
 		return err
 	}
-	_, err = cm.processConfig(configUpdateEnv)
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	configMap, err := cm.processConfig(configUpdateEnv)
+	if err == nil {
+		_, err = cm.validateListeners(configMap, configtx)
+	}
 	cm.rollbackHandlers()
 	return err
 }
 
-// Apply attempts to apply a configtx to become the new config
-func (cm *configManager) Apply(configtx *cb.Envelope) error {
+// Simulate validates a new configtx against the current config state, as
+// Validate does, and returns the config which applying it would result in,
+// leaving the current config untouched
+func (cm *configManager) Simulate(configtx *cb.Envelope) (*cb.ConfigEnvelope, error) {
 	configUpdateEnv, err := envelopeToConfigUpdate(configtx)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
 	configMap, err := cm.processConfig(configUpdateEnv)
 	if err != nil {
 		cm.rollbackHandlers()
-		return err
+		return nil, err
 	}
-	cm.config = configMap
-	cm.sequence++
-	cm.commitHandlers()
+	configEnv, err := cm.validateListeners(configMap, configtx)
+	cm.rollbackHandlers()
+	return configEnv, err
+}
+
+// configEnvelope returns the ConfigEnvelope of configMap, resulting from the
+// configtx update
+func (cm *configManager) configEnvelope(configMap map[string]comparable, configtx *cb.Envelope) (*cb.ConfigEnvelope, error) {
 	channelGroup, err := configMapToConfig(configMap)
 	if err != nil {
-		logger.Panicf("Config was validated and applied, but could not be transformed back into proto form: %s", err)
+		return nil, err
 	}
 
-	cm.configEnv = &cb.ConfigEnvelope{
+	return &cb.ConfigEnvelope{
 		Config: &cb.Config{
-			// XXX add header
+			// XXX the header is that of the initial config
+			Header:  cm.snapshot().configEnv.Config.Header,
 			Channel: channelGroup,
 		},
 		LastUpdate: configtx,
+	}, nil
+}
+
+// Apply attempts to apply a configtx to become the new config, the readers see the new config once the
+// handlers commit it. The configtx was ordered, so the listeners don't validate it again
+func (cm *configManager) Apply(configtx *cb.Envelope) error {
+	configUpdateEnv, err := envelopeToConfigUpdate(configtx)
+	if err != nil {
+		return err
+	}
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	configMap, err := cm.processConfig(configUpdateEnv)
+	if err != nil {
+		cm.rollbackHandlers()
+		return err
 	}
+	return cm.commitUpdate(configMap, configtx)
+}
+
+// commitUpdate commits configMap, which the handlers have been proposed, as the config following the current one
+func (cm *configManager) commitUpdate(configMap map[string]comparable, configtx *cb.Envelope) error {
+	configEnv, err := cm.configEnvelope(configMap, configtx)
+	if err != nil {
+		cm.rollbackHandlers()
+		return fmt.Errorf("Config was validated, but could not be transformed back into proto form: %s", err)
+	}
+	cm.commit(&committedConfig{
+		sequence:     cm.snapshot().sequence + 1,
+		config:       configMap,
+		configEnv:    configEnv,
+		configDigest: computeConfigDigest(cm.chainID, configMap),
+	})
+	return nil
+}
+
+// Rollback reverts to the committed config with the given sequence number, which must be one of the last configs
+// committed. Any proposal left pending by the handlers, for instance by a panic while committing, is abandoned,
+// then the config is proposed to the handlers, and committed if they accept it. If they don't, the current config
+// is left in place. The config rolled back to is committed again as
+// the config following the current one, so that the sequence number only ever increases and the configs committed
+// in between can still be rolled back to
+func (cm *configManager) Rollback(sequence uint64) error {
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	var target *committedConfig
+	for _, committed := range cm.history {
+		if committed.sequence == sequence {
+			target = committed
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("Config with sequence %d is not in the history of chain %s", sequence, cm.chainID)
+	}
+
+	cm.rollbackHandlers()
+	cm.beginHandlers()
+	if err := cm.proposeConfig(target.config); err != nil {
+		cm.rollbackHandlers()
+		return fmt.Errorf("Error proposing config with sequence %d: %s", sequence, err)
+	}
+	current := cm.snapshot().sequence
+	logger.Warningf("Rolling back chain %s from config sequence %d to the config with sequence %d, committed as sequence %d",
+		cm.chainID, current, sequence, current+1)
+	cm.commit(&committedConfig{
+		sequence:     current + 1,
+		config:       target.config,
+		configEnv:    target.configEnv,
+		configDigest: target.configDigest,
+	})
 	return nil
 }
 
-// ConfigEnvelope retrieve the current ConfigEnvelope, generated after the last successfully applied configuration
+// ConfigEnvelope retrieve the current ConfigEnvelope, generated after the last successfully applied configuration,
+// or the initial one if no configuration has been applied yet
 func (cm *configManager) ConfigEnvelope() *cb.ConfigEnvelope {
-	return cm.configEnv
+	return cm.snapshot().configEnv
 }
 
 // ChainID retrieves the chain ID associated with this manager
@@ -381,5 +696,10 @@ func (cm *configManager) ChainID() string {
 
 // Sequence returns the current sequence number of the config
 func (cm *configManager) Sequence() uint64 {
-	return cm.sequence
+	return cm.snapshot().sequence
+}
+
+// ConfigDigest returns the canonical digest of the current config
+func (cm *configManager) ConfigDigest() []byte {
+	return cm.snapshot().configDigest
 }
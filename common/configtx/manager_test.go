@@ -17,10 +17,17 @@ limitations under the License.
 package configtx
 
 import (
+	"bytes"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/capabilities"
 	"github.com/hyperledger/fabric/common/configtx/api"
+	configtxapplication "github.com/hyperledger/fabric/common/configtx/handlers/application"
+	configtxchannel "github.com/hyperledger/fabric/common/configtx/handlers/channel"
 	mockconfigtx "github.com/hyperledger/fabric/common/mocks/configtx"
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -78,12 +85,15 @@ func makeConfigUpdateEnvelope(chainID string, configPairs ...*configPair) *cb.En
 		values[pair.key] = pair.value
 	}
 
-	config := &cb.ConfigUpdate{
+	return wrapConfigUpdate(&cb.ConfigUpdate{
 		Header: &cb.ChannelHeader{ChannelId: chainID},
 		WriteSet: &cb.ConfigGroup{
 			Values: values,
 		},
-	}
+	})
+}
+
+func wrapConfigUpdate(config *cb.ConfigUpdate) *cb.Envelope {
 	return &cb.Envelope{
 		Payload: utils.MarshalOrPanic(&cb.Payload{
 			Header: &cb.Header{
@@ -106,7 +116,7 @@ func TestCallback(t *testing.T) {
 
 	cm, err := NewManagerImpl(
 		makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo"))),
-		defaultInitializer(), []func(api.Manager){callback})
+		defaultInitializer(), []api.ConfigListener{api.PostApplyFunc(callback)})
 
 	if err != nil {
 		t.Fatalf("Error constructing config manager: %s", err)
@@ -117,6 +127,74 @@ func TestCallback(t *testing.T) {
 	}
 }
 
+// recordingListener records the phases it is notified of, rejecting the configs it is told to
+type recordingListener struct {
+	phases        []string
+	validateErr   error
+	postApplySeqs []uint64
+}
+
+func (rl *recordingListener) OnValidate(configEnv *cb.ConfigEnvelope) error {
+	rl.phases = append(rl.phases, "validate")
+	return rl.validateErr
+}
+
+func (rl *recordingListener) OnPostApply(m api.Manager) {
+	rl.phases = append(rl.phases, "postapply")
+	rl.postApplySeqs = append(rl.postApplySeqs, m.Sequence())
+}
+
+// TestConfigListener tests that the listeners are notified of the phases of the updates in order,
+// and that they can reject an update being validated but not one being applied
+func TestConfigListener(t *testing.T) {
+	first, second := &recordingListener{}, &recordingListener{}
+	cm, err := NewManagerImpl(
+		makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo"))),
+		defaultInitializer(), []api.ConfigListener{first, second})
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+	if !reflect.DeepEqual(first.phases, []string{"postapply"}) {
+		t.Errorf("Unexpected phases for the initial config: %v", first.phases)
+	}
+
+	first.phases = nil
+	newConfig := makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 1, []byte("bar")))
+	if err = cm.Validate(newConfig); err != nil {
+		t.Fatalf("Should not have errored validating config: %s", err)
+	}
+	if err = cm.Apply(newConfig); err != nil {
+		t.Fatalf("Should not have errored applying config: %s", err)
+	}
+	if !reflect.DeepEqual(first.phases, []string{"validate", "postapply"}) {
+		t.Errorf("Unexpected phases for the update: %v", first.phases)
+	}
+	if !reflect.DeepEqual(second.postApplySeqs, []uint64{0, 1}) {
+		t.Errorf("Listeners should read the config committed, got sequences %v", second.postApplySeqs)
+	}
+
+	second.validateErr = fmt.Errorf("veto")
+	newConfig = makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 2, []byte("baz")))
+	if err = cm.Validate(newConfig); err == nil {
+		t.Error("Should have errored validating config a listener rejects")
+	}
+	if _, err = cm.Simulate(newConfig); err == nil {
+		t.Error("Should have errored simulating config a listener rejects")
+	}
+	if err = cm.Apply(newConfig); err != nil {
+		t.Errorf("Should not have errored applying config a listener rejects, as it was ordered: %s", err)
+	}
+	if err = cm.Rollback(1); err != nil {
+		t.Errorf("Should not have errored rolling back to a config a listener rejects: %s", err)
+	}
+
+	if _, err = NewManagerImpl(
+		makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo"))),
+		defaultInitializer(), []api.ConfigListener{&recordingListener{validateErr: fmt.Errorf("veto")}}); err != nil {
+		t.Errorf("Should not have errored constructing a config manager with a config a listener rejects: %s", err)
+	}
+}
+
 // TestDifferentChainID tests that a config update for a different chain ID fails
 func TestDifferentChainID(t *testing.T) {
 	cm, err := NewManagerImpl(
@@ -186,6 +264,266 @@ func TestValidConfigChange(t *testing.T) {
 	}
 }
 
+// TestConcurrentReads tests that the config is read while updates are applied, the readers seeing the
+// committed configs only, and that the callbacks see the config just committed
+func TestConcurrentReads(t *testing.T) {
+	var sequences []uint64
+	callback := func(m api.Manager) {
+		sequences = append(sequences, m.Sequence())
+	}
+
+	cm, err := NewManagerImpl(
+		makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo"))),
+		defaultInitializer(), []api.ConfigListener{api.PostApplyFunc(callback)})
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+
+	const updates = 20
+	done := make(chan struct{})
+	readersDone := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			var last uint64
+			for {
+				select {
+				case <-done:
+					readersDone <- nil
+					return
+				default:
+				}
+				// the config read is at most as recent as the sequence read after it
+				value := cm.ConfigEnvelope().Config.Channel.Values["foo"]
+				seq := cm.Sequence()
+				if seq < last {
+					readersDone <- fmt.Errorf("Sequence regressed from %d to %d", last, seq)
+					return
+				}
+				last = seq
+				if value == nil || value.Version > seq {
+					readersDone <- fmt.Errorf("Read a config which was not committed: %v", value)
+					return
+				}
+			}
+		}()
+	}
+
+	for seq := uint64(1); seq <= updates; seq++ {
+		if err := cm.Apply(makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", seq, []byte(fmt.Sprintf("foo%d", seq))))); err != nil {
+			t.Fatalf("Should not have errored applying config %d: %s", seq, err)
+		}
+	}
+	close(done)
+	for i := 0; i < 4; i++ {
+		if err := <-readersDone; err != nil {
+			t.Error(err)
+		}
+	}
+
+	if cm.Sequence() != updates {
+		t.Fatalf("Expected sequence %d, got %d", updates, cm.Sequence())
+	}
+	for i, seq := range sequences {
+		if seq != uint64(i) {
+			t.Fatalf("Callback %d should have seen the config with sequence %d, got %d", i, i, seq)
+		}
+	}
+}
+
+// TestConfigDigest tests that the config digest only depends on the config, and follows its changes
+func TestConfigDigest(t *testing.T) {
+	newManager := func(configPairs ...*configPair) api.Manager {
+		cm, err := NewManagerImpl(makeConfigEnvelope(defaultChain, configPairs...), defaultInitializer(), nil)
+		if err != nil {
+			t.Fatalf("Error constructing config manager: %s", err)
+		}
+		return cm
+	}
+
+	cm := newManager(makeConfigPair("foo", "foo", 0, []byte("foo")), makeConfigPair("bar", "bar", 0, []byte("bar")))
+	digest := cm.ConfigDigest()
+	if len(digest) != 32 {
+		t.Fatalf("Expected a SHA-256 digest, got %x", digest)
+	}
+	for i := 0; i < 10; i++ {
+		other := newManager(makeConfigPair("bar", "bar", 0, []byte("bar")), makeConfigPair("foo", "foo", 0, []byte("foo")))
+		if !bytes.Equal(digest, other.ConfigDigest()) {
+			t.Fatalf("The digest of the same config should be the same")
+		}
+	}
+
+	for _, other := range []api.Manager{
+		newManager(makeConfigPair("foo", "foo", 0, []byte("foo")), makeConfigPair("bar", "bar", 0, []byte("baz"))),
+		newManager(makeConfigPair("foo", "foo", 0, []byte("foo")), makeConfigPair("bar", "foo", 0, []byte("bar"))),
+		newManager(makeConfigPair("foo", "foo", 0, []byte("foo")), makeConfigPair("baz", "bar", 0, []byte("bar"))),
+		newManager(makeConfigPair("foo", "foo", 0, []byte("foobar"))),
+	} {
+		if bytes.Equal(digest, other.ConfigDigest()) {
+			t.Fatalf("The digest of a different config should be different")
+		}
+	}
+
+	if err := cm.Apply(makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 1, []byte("baz")), makeConfigPair("bar", "bar", 0, []byte("bar")))); err != nil {
+		t.Fatalf("Should not have errored applying config: %s", err)
+	}
+	if bytes.Equal(digest, cm.ConfigDigest()) {
+		t.Fatalf("The digest should follow the applied config")
+	}
+}
+
+// TestConfigEnvelope tests that the config envelope is that of the initial
+// config until a config is applied, and then follows the applied config
+func TestConfigEnvelope(t *testing.T) {
+	configEnv := makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo")))
+	cm, err := NewManagerImpl(configEnv, defaultInitializer(), nil)
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+	if cm.ConfigEnvelope() != configEnv {
+		t.Fatalf("Should have returned the initial config envelope")
+	}
+
+	configtx := makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 1, []byte("bar")))
+	if err := cm.Apply(configtx); err != nil {
+		t.Fatalf("Should not have errored applying config: %s", err)
+	}
+	applied := cm.ConfigEnvelope()
+	if applied.LastUpdate != configtx {
+		t.Fatalf("Should have returned the applied config envelope")
+	}
+	if applied.Config.Header.ChannelId != defaultChain {
+		t.Fatalf("Expected the header of channel %s, got %v", defaultChain, applied.Config.Header)
+	}
+	if value := applied.Config.Channel.Values["foo"]; value == nil || !bytes.Equal(value.Value, []byte("bar")) {
+		t.Fatalf("Expected the applied value of foo, got %v", value)
+	}
+}
+
+// TestRollback tests that the manager reverts to a previously committed config, which it commits again at the next
+// sequence number, and that it keeps the current config if the handlers reject the one rolled back to
+func TestRollback(t *testing.T) {
+	initializer := defaultInitializer()
+	var callbacks int
+	cm, err := NewManagerImpl(
+		makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo"))),
+		initializer, []api.ConfigListener{api.PostApplyFunc(func(api.Manager) { callbacks++ })})
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+
+	if err = cm.Apply(makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 1, []byte("bar")))); err != nil {
+		t.Fatalf("Should not have errored applying config: %s", err)
+	}
+	configEnv, digest := cm.ConfigEnvelope(), cm.ConfigDigest()
+	if err = cm.Apply(makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 2, []byte("baz")))); err != nil {
+		t.Fatalf("Should not have errored applying config: %s", err)
+	}
+
+	if err = cm.Rollback(3); err == nil {
+		t.Error("Should have errored rolling back to a config which was never committed")
+	}
+
+	initializer.HandlerVal = &mockconfigtx.Handler{ErrorForProposeConfig: fmt.Errorf("err")}
+	if err = cm.Rollback(1); err == nil {
+		t.Error("Should have errored rolling back to a config the handlers reject")
+	}
+	if cm.Sequence() != 2 {
+		t.Errorf("Should have kept the current config, got sequence %d", cm.Sequence())
+	}
+	initializer.HandlerVal = &mockconfigtx.Handler{}
+
+	callbacks = 0
+	if err = cm.Rollback(1); err != nil {
+		t.Fatalf("Should not have errored rolling back: %s", err)
+	}
+	if cm.Sequence() != 3 || cm.ConfigEnvelope() != configEnv || !bytes.Equal(cm.ConfigDigest(), digest) {
+		t.Errorf("Should have committed the config with sequence 1 again with sequence 3, got sequence %d", cm.Sequence())
+	}
+	if callbacks != 1 {
+		t.Errorf("Should have called back once the config was rolled back, called %d times", callbacks)
+	}
+
+	if err = cm.Apply(makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 3, []byte("qux")))); err == nil {
+		t.Error("Should have errored applying config at the sequence of the config rolled back to")
+	}
+	if err = cm.Apply(makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 4, []byte("qux")))); err != nil {
+		t.Fatalf("Should not have errored applying config after rolling back: %s", err)
+	}
+	if err = cm.Rollback(2); err != nil {
+		t.Fatalf("Should not have errored rolling back to a config committed before the last rollback: %s", err)
+	}
+	if value := cm.ConfigEnvelope().Config.Channel.Values["foo"]; cm.Sequence() != 5 || !bytes.Equal(value.Value, []byte("baz")) {
+		t.Errorf("Expected the value of foo at sequence 2 with sequence 5, got %v with sequence %d", value, cm.Sequence())
+	}
+	if err = cm.Rollback(0); err != nil {
+		t.Fatalf("Should not have errored rolling back to the initial config: %s", err)
+	}
+	if value := cm.ConfigEnvelope().Config.Channel.Values["foo"]; !bytes.Equal(value.Value, []byte("foo")) {
+		t.Errorf("Expected the initial value of foo, got %v", value)
+	}
+}
+
+// TestRollbackHistorySize tests that only the last configs committed can be rolled back to
+func TestRollbackHistorySize(t *testing.T) {
+	cm, err := NewManagerImpl(
+		makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo"))),
+		defaultInitializer(), nil)
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+
+	for seq := uint64(1); seq <= configHistorySize; seq++ {
+		if err = cm.Apply(makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", seq, []byte(fmt.Sprintf("foo%d", seq))))); err != nil {
+			t.Fatalf("Should not have errored applying config: %s", err)
+		}
+	}
+
+	if err = cm.Rollback(0); err == nil {
+		t.Error("Should have errored rolling back to a config older than the history")
+	}
+	if err = cm.Rollback(1); err != nil {
+		t.Errorf("Should not have errored rolling back to the oldest config of the history: %s", err)
+	}
+}
+
+// TestSimulate tests that simulating a config update returns the config it
+// results in, without changing the current config
+func TestSimulate(t *testing.T) {
+	configEnv := makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo")))
+	cm, err := NewManagerImpl(configEnv, defaultInitializer(), nil)
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+	digest := cm.ConfigDigest()
+
+	configtx := makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 1, []byte("bar")))
+	simulated, err := cm.Simulate(configtx)
+	if err != nil {
+		t.Fatalf("Should not have errored simulating config: %s", err)
+	}
+	if simulated.LastUpdate != configtx {
+		t.Fatalf("Should have returned the config envelope of the update")
+	}
+	if value := simulated.Config.Channel.Values["foo"]; value == nil || !bytes.Equal(value.Value, []byte("bar")) {
+		t.Fatalf("Expected the simulated value of foo, got %v", value)
+	}
+
+	if cm.ConfigEnvelope() != configEnv || cm.Sequence() != 0 || !bytes.Equal(digest, cm.ConfigDigest()) {
+		t.Fatalf("Simulating config should not have changed the current config")
+	}
+
+	if _, err = cm.Simulate(makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 2, []byte("bar")))); err == nil {
+		t.Fatalf("Should have errored simulating config with a sequence jump")
+	}
+
+	if err = cm.Apply(configtx); err != nil {
+		t.Fatalf("Should not have errored applying the simulated config: %s", err)
+	}
+	if !proto.Equal(cm.ConfigEnvelope(), simulated) {
+		t.Fatalf("The applied config should be the simulated one")
+	}
+}
+
 // TestConfigChangeRegressedSequence tests to make sure that a new config cannot roll back one of the
 // config values while advancing another
 func TestConfigChangeRegressedSequence(t *testing.T) {
@@ -242,6 +580,148 @@ func TestConfigChangeOldSequence(t *testing.T) {
 	}
 }
 
+// TestStaleReadSet tests that an update is only accepted if the config items of its read set are at the
+// Version they were read at, and that the rejection names the stale keys
+func TestStaleReadSet(t *testing.T) {
+	cm, err := NewManagerImpl(
+		makeConfigEnvelope(
+			defaultChain,
+			makeConfigPair("foo", "foo", 0, []byte("foo")),
+			makeConfigPair("bar", "bar", 0, []byte("bar")),
+			makeConfigPair(configtxchannel.CapabilitiesKey, "", 0, utils.MarshalOrPanic(&cb.Capabilities{Capabilities: []string{capabilities.ReadSetValidation}})),
+		),
+		defaultInitializer(), nil)
+
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+
+	makeUpdate := func(readSet *cb.ConfigGroup) *cb.Envelope {
+		return wrapConfigUpdate(&cb.ConfigUpdate{
+			Header:  &cb.ChannelHeader{ChannelId: defaultChain},
+			ReadSet: readSet,
+			WriteSet: &cb.ConfigGroup{
+				Values: map[string]*cb.ConfigValue{
+					"foo": makeConfigPair("foo", "foo", 1, []byte("foo")).value,
+					"bar": makeConfigPair("bar", "bar", 0, []byte("bar")).value,
+					configtxchannel.CapabilitiesKey: makeConfigPair(configtxchannel.CapabilitiesKey, "", 0,
+						utils.MarshalOrPanic(&cb.Capabilities{Capabilities: []string{capabilities.ReadSetValidation}})).value,
+				},
+			},
+		})
+	}
+
+	err = cm.Validate(makeUpdate(&cb.ConfigGroup{Values: map[string]*cb.ConfigValue{"bar": {Version: 0}}}))
+	if err != nil {
+		t.Errorf("Should not have errored validating config with a current read set: %s", err)
+	}
+
+	err = cm.Validate(makeUpdate(&cb.ConfigGroup{Values: map[string]*cb.ConfigValue{"bar": {Version: 1}, "baz": {}}}))
+	if err == nil {
+		t.Fatalf("Should have errored validating config with a stale read set")
+	}
+	for _, key := range []string{"[Values] /Channel/bar", "[Values] /Channel/baz"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Error should have named stale key %s: %s", key, err)
+		}
+	}
+
+	err = cm.Apply(makeUpdate(&cb.ConfigGroup{Values: map[string]*cb.ConfigValue{"foo": {Version: 1}}}))
+	if err == nil {
+		t.Errorf("Should have errored applying config with a stale read set")
+	}
+}
+
+// TestReadSetValidationCapability tests that the read set is ignored unless the ReadSetValidation capability is enabled
+func TestReadSetValidationCapability(t *testing.T) {
+	cm, err := NewManagerImpl(
+		makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo"))),
+		defaultInitializer(), nil)
+
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+
+	err = cm.Validate(wrapConfigUpdate(&cb.ConfigUpdate{
+		Header:   &cb.ChannelHeader{ChannelId: defaultChain},
+		ReadSet:  &cb.ConfigGroup{Values: map[string]*cb.ConfigValue{"foo": {Version: 1}}},
+		WriteSet: &cb.ConfigGroup{Values: map[string]*cb.ConfigValue{"foo": makeConfigPair("foo", "foo", 1, []byte("foo")).value}},
+	}))
+	if err != nil {
+		t.Errorf("Should not have errored validating config with a stale read set without the capability: %s", err)
+	}
+}
+
+// TestUnsupportedCapabilities tests that a config enabling capabilities this version does not support is refused
+func TestUnsupportedCapabilities(t *testing.T) {
+	unsupported := makeConfigPair(configtxchannel.CapabilitiesKey, "", 0, utils.MarshalOrPanic(&cb.Capabilities{Capabilities: []string{"Unsupported"}}))
+	_, err := NewManagerImpl(makeConfigEnvelope(defaultChain, unsupported), defaultInitializer(), nil)
+	if err == nil {
+		t.Errorf("Should have errored constructing config manager with an unsupported capability")
+	}
+
+	cm, err := NewManagerImpl(makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo"))), defaultInitializer(), nil)
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+	unsupported.value.Version = 1
+	err = cm.Validate(makeConfigUpdateEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo")), unsupported))
+	if err == nil || !strings.Contains(err.Error(), "Unsupported") {
+		t.Errorf("Should have errored validating config enabling an unsupported capability, got %v", err)
+	}
+}
+
+// TestChaincodeNamespacePrefixImmutable tests that the chaincode namespace prefix of a channel cannot be changed
+func TestChaincodeNamespacePrefixImmutable(t *testing.T) {
+	prefixedConfig := func(prefix string, prefixVersion uint64, foo []byte, fooVersion uint64) *cb.ConfigGroup {
+		group := configtxapplication.TemplateChaincodeNamespacePrefix(prefix)
+		group.Groups[configtxapplication.GroupKey].Values[configtxapplication.ChaincodeNamespacePrefixKey].Version = prefixVersion
+		group.Values["foo"] = makeConfigPair("foo", "foo", fooVersion, foo).value
+		return group
+	}
+	cm, err := NewManagerImpl(&cb.ConfigEnvelope{
+		Config: &cb.Config{
+			Header:  &cb.ChannelHeader{ChannelId: defaultChain},
+			Channel: prefixedConfig("tenant1", 0, []byte("foo"), 0),
+		},
+	}, defaultInitializer(), nil)
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+
+	err = cm.Validate(wrapConfigUpdate(&cb.ConfigUpdate{
+		Header:   &cb.ChannelHeader{ChannelId: defaultChain},
+		WriteSet: prefixedConfig("tenant2", 1, []byte("foo"), 0),
+	}))
+	if err == nil || !strings.Contains(err.Error(), "namespace prefix") {
+		t.Errorf("Should have errored validating config changing the chaincode namespace prefix, got %v", err)
+	}
+
+	err = cm.Validate(wrapConfigUpdate(&cb.ConfigUpdate{
+		Header: &cb.ChannelHeader{ChannelId: defaultChain},
+		WriteSet: &cb.ConfigGroup{
+			Values: map[string]*cb.ConfigValue{"foo": makeConfigPair("foo", "foo", 1, []byte("bar")).value},
+			Groups: map[string]*cb.ConfigGroup{configtxapplication.GroupKey: {Version: 1}},
+		},
+		DeleteSet: &cb.ConfigGroup{
+			Groups: map[string]*cb.ConfigGroup{configtxapplication.GroupKey: {
+				Values: map[string]*cb.ConfigValue{configtxapplication.ChaincodeNamespacePrefixKey: {}},
+			}},
+		},
+	}))
+	if err == nil || !strings.Contains(err.Error(), "namespace prefix") {
+		t.Errorf("Should have errored validating config removing the chaincode namespace prefix, got %v", err)
+	}
+
+	err = cm.Validate(wrapConfigUpdate(&cb.ConfigUpdate{
+		Header:   &cb.ChannelHeader{ChannelId: defaultChain},
+		WriteSet: prefixedConfig("tenant1", 0, []byte("bar"), 1),
+	}))
+	if err != nil {
+		t.Errorf("Should not have errored validating config keeping the chaincode namespace prefix: %s", err)
+	}
+}
+
 // TestConfigImplicitDelete tests to make sure that a new config does not implicitly delete config items
 // by omitting them in the new config
 func TestConfigImplicitDelete(t *testing.T) {
@@ -273,6 +753,93 @@ func TestConfigImplicitDelete(t *testing.T) {
 	}
 }
 
+// TestConfigExplicitDelete tests that a config item listed in the delete set of an update is deleted
+// provided it is listed at its current version, is not written too, and its modification policy is satisfied
+func TestConfigExplicitDelete(t *testing.T) {
+	initializer := defaultInitializer()
+	cm, err := NewManagerImpl(
+		makeConfigEnvelope(
+			defaultChain,
+			makeConfigPair("foo", "foo", 0, []byte("foo")),
+			makeConfigPair("bar", "bar", 0, []byte("bar")),
+		),
+		initializer, nil)
+
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+
+	makeDeleteEnvelope := func(deletedVersion uint64, configPairs ...*configPair) *cb.Envelope {
+		values := make(map[string]*cb.ConfigValue)
+		for _, pair := range configPairs {
+			values[pair.key] = pair.value
+		}
+		return wrapConfigUpdate(&cb.ConfigUpdate{
+			Header:   &cb.ChannelHeader{ChannelId: defaultChain},
+			WriteSet: &cb.ConfigGroup{Values: values},
+			DeleteSet: &cb.ConfigGroup{
+				Values: map[string]*cb.ConfigValue{"foo": &cb.ConfigValue{Version: deletedVersion}},
+			},
+		})
+	}
+
+	if err = cm.Validate(makeDeleteEnvelope(1, makeConfigPair("bar", "bar", 1, []byte("bar")))); err == nil {
+		t.Error("Should have errored validating config because foo was deleted at a version other than its current one")
+	}
+
+	if err = cm.Validate(makeDeleteEnvelope(0, makeConfigPair("foo", "foo", 1, []byte("foo")), makeConfigPair("bar", "bar", 1, []byte("bar")))); err == nil {
+		t.Error("Should have errored validating config because foo was both written and deleted")
+	}
+
+	// Set the mock policy of foo to error
+	initializer.Resources.PolicyManagerVal.PolicyMap = map[string]*mockpolicies.Policy{"foo": &mockpolicies.Policy{Err: fmt.Errorf("err")}}
+	newConfig := makeDeleteEnvelope(0, makeConfigPair("bar", "bar", 1, []byte("bar")))
+	if err = cm.Validate(newConfig); err == nil {
+		t.Error("Should have errored validating config because the modification policy of foo was not satisfied")
+	}
+
+	// Set the mock policy of the root group, which foo is deleted from, to error
+	initializer.Resources.PolicyManagerVal.PolicyMap = map[string]*mockpolicies.Policy{"": &mockpolicies.Policy{Err: fmt.Errorf("err")}}
+	if err = cm.Validate(newConfig); err == nil {
+		t.Error("Should have errored validating config because the modification policy of the group of foo was not satisfied")
+	}
+	initializer.Resources.PolicyManagerVal.PolicyMap = nil
+
+	if err = cm.Validate(newConfig); err != nil {
+		t.Errorf("Should not have errored validating config, but got %s", err)
+	}
+
+	if err = cm.Apply(newConfig); err != nil {
+		t.Fatalf("Should not have errored applying config, but got %s", err)
+	}
+
+	values := cm.ConfigEnvelope().Config.Channel.Values
+	if _, ok := values["foo"]; ok {
+		t.Error("Deleted key foo should not be in the config anymore")
+	}
+	if _, ok := values["bar"]; !ok {
+		t.Error("Key bar should still be in the config")
+	}
+
+	if err = cm.Validate(makeDeleteEnvelope(0, makeConfigPair("bar", "bar", 2, []byte("bar")))); err == nil {
+		t.Error("Should have errored validating config because foo does not exist anymore")
+	}
+
+	cm, err = NewManagerImpl(
+		makeConfigEnvelope(defaultChain, makeConfigPair(MSPKey, "foo", 0, []byte("msp")), makeConfigPair("bar", "bar", 0, []byte("bar"))),
+		defaultInitializer(), nil)
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+	if err = cm.Validate(wrapConfigUpdate(&cb.ConfigUpdate{
+		Header:    &cb.ChannelHeader{ChannelId: defaultChain},
+		WriteSet:  &cb.ConfigGroup{Values: map[string]*cb.ConfigValue{"bar": &cb.ConfigValue{ModPolicy: "bar", Version: 1, Value: []byte("bar")}}},
+		DeleteSet: &cb.ConfigGroup{Values: map[string]*cb.ConfigValue{MSPKey: &cb.ConfigValue{}}},
+	})); err == nil {
+		t.Error("Should have errored validating config because an MSP was deleted without its group")
+	}
+}
+
 // TestEmptyConfigUpdate tests to make sure that an empty config is rejected as an update
 func TestEmptyConfigUpdate(t *testing.T) {
 	cm, err := NewManagerImpl(
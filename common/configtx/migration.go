@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/capabilities"
+	configtxchannel "github.com/hyperledger/fabric/common/configtx/handlers/channel"
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Migration moves the channel group of a config, in place, to the layout the
+// versions supporting Capability read once it is enabled. The peers and
+// orderers of a channel must all read the same layout, so the config is never
+// migrated when it is loaded: the migration is proposed as a config update
+// enabling its capability, which is applied once ordered like any other
+type Migration struct {
+	Capability  string
+	Description string
+	Migrate     func(channel *cb.ConfigGroup) error
+}
+
+// migrations lists the migrations in the order they are applied. A new
+// layout is introduced by adding its capability to the capabilities package
+// and appending its migration here
+var migrations = []*Migration{}
+
+// MigrationUpdate computes the config update migrating the config of configEnv
+// through the migrations whose capability it does not enable yet, and enabling
+// their capabilities. It returns nil if the config has no migration left. The
+// returned ConfigUpdate must be wrapped in a ConfigUpdateEnvelope and signed by
+// enough members to satisfy the mod_policy of the elements it modifies
+func MigrationUpdate(configEnv *cb.ConfigEnvelope) (*cb.ConfigUpdate, error) {
+	return migrationUpdate(configEnv, migrations)
+}
+
+func migrationUpdate(configEnv *cb.ConfigEnvelope, migrations []*Migration) (*cb.ConfigUpdate, error) {
+	if configEnv.GetConfig().GetChannel() == nil {
+		return nil, fmt.Errorf("Config has no channel group")
+	}
+	capabilitiesConfig, err := capabilitiesConfigOf(configEnv.Config.Channel)
+	if err != nil {
+		return nil, err
+	}
+	enabled := capabilities.NewProvider(capabilitiesConfig)
+
+	migrated := proto.Clone(configEnv.Config).(*cb.Config)
+	var applied []string
+	for _, migration := range migrations {
+		if enabled.Enabled(migration.Capability) {
+			continue
+		}
+		logger.Infof("Migrating config for capability %s: %s", migration.Capability, migration.Description)
+		if err := migration.Migrate(migrated.Channel); err != nil {
+			return nil, fmt.Errorf("Error migrating config for capability %s: %s", migration.Capability, err)
+		}
+		applied = append(applied, migration.Capability)
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+
+	if migrated.Channel.Values == nil {
+		migrated.Channel.Values = make(map[string]*cb.ConfigValue)
+	}
+	value := configtxchannel.TemplateCapabilities(append(capabilitiesConfig.Capabilities, applied...)...).Values[configtxchannel.CapabilitiesKey]
+	if current, ok := migrated.Channel.Values[configtxchannel.CapabilitiesKey]; ok {
+		value.ModPolicy = current.ModPolicy
+	}
+	migrated.Channel.Values[configtxchannel.CapabilitiesKey] = value
+
+	return Diff(configEnv, &cb.ConfigEnvelope{Config: migrated})
+}
+
+// capabilitiesConfigOf returns the Capabilities value of channel, which is
+// empty if channel has none
+func capabilitiesConfigOf(channel *cb.ConfigGroup) (*cb.Capabilities, error) {
+	capabilitiesConfig := &cb.Capabilities{}
+	value, ok := channel.Values[configtxchannel.CapabilitiesKey]
+	if !ok {
+		return capabilitiesConfig, nil
+	}
+	if err := proto.Unmarshal(value.Value, capabilitiesConfig); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling Capabilities: %s", err)
+	}
+	return capabilitiesConfig, nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/capabilities"
+	configtxchannel "github.com/hyperledger/fabric/common/configtx/handlers/channel"
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// renameValue returns a migration step renaming the value from of the channel
+// group to to
+func renameValue(from, to string) func(channel *cb.ConfigGroup) error {
+	return func(channel *cb.ConfigGroup) error {
+		value, ok := channel.Values[from]
+		if !ok {
+			return fmt.Errorf("No %s value", from)
+		}
+		delete(channel.Values, from)
+		channel.Values[to] = value
+		return nil
+	}
+}
+
+// testMigrations renames the foo value to bar, then bar to baz, gated by
+// capabilities this version supports
+func testMigrations() []*Migration {
+	return []*Migration{
+		{Capability: capabilities.ReadSetValidation, Description: "Rename foo to bar", Migrate: renameValue("foo", "bar")},
+		{Capability: capabilities.NamespaceValidation, Description: "Rename bar to baz", Migrate: renameValue("bar", "baz")},
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	for _, migration := range migrations {
+		provider := capabilities.NewProvider(&cb.Capabilities{Capabilities: []string{migration.Capability}})
+		assert.NoError(t, provider.Supported(), "The capability of a migration should be supported")
+	}
+}
+
+func TestMigrationUpdate(t *testing.T) {
+	cm, err := NewManagerImpl(
+		makeConfigEnvelope(defaultChain, makeConfigPair("foo", "foo", 0, []byte("foo"))),
+		defaultInitializer(), nil)
+	assert.NoError(t, err)
+	assert.Contains(t, cm.ConfigEnvelope().Config.Channel.Values, "foo", "The config should not be migrated when it is loaded")
+
+	update, err := migrationUpdate(cm.ConfigEnvelope(), testMigrations())
+	assert.NoError(t, err)
+	assert.NoError(t, cm.Apply(wrapConfigUpdate(update)), "The migration should be applied as a config update")
+	values := cm.ConfigEnvelope().Config.Channel.Values
+	assert.NotContains(t, values, "foo")
+	assert.Equal(t, []byte("foo"), values["baz"].Value, "The migrations should be applied in order")
+	capabilitiesConfig, err := capabilitiesConfigOf(cm.ConfigEnvelope().Config.Channel)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{capabilities.ReadSetValidation, capabilities.NamespaceValidation}, capabilitiesConfig.Capabilities)
+
+	update, err = migrationUpdate(cm.ConfigEnvelope(), testMigrations())
+	assert.NoError(t, err)
+	assert.Nil(t, update, "A config enabling the capabilities of the migrations should not be migrated again")
+}
+
+func TestMigrationUpdateEnabledCapabilities(t *testing.T) {
+	configEnv := makeConfigEnvelope(defaultChain, makeConfigPair("bar", "bar", 0, []byte("bar")))
+	capabilitiesValue := configtxchannel.TemplateCapabilities(capabilities.ReadSetValidation).Values[configtxchannel.CapabilitiesKey]
+	capabilitiesValue.ModPolicy = "admins"
+	configEnv.Config.Channel.Values[configtxchannel.CapabilitiesKey] = capabilitiesValue
+
+	update, err := migrationUpdate(configEnv, testMigrations())
+	assert.NoError(t, err)
+	assert.Contains(t, update.WriteSet.Values, "baz", "Only the migrations of the capabilities not enabled should be applied")
+	assert.Contains(t, update.DeleteSet.Values, "bar")
+	written := update.WriteSet.Values[configtxchannel.CapabilitiesKey]
+	assert.Equal(t, "admins", written.ModPolicy)
+	capabilitiesConfig, err := capabilitiesConfigOf(update.WriteSet)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{capabilities.ReadSetValidation, capabilities.NamespaceValidation}, capabilitiesConfig.Capabilities)
+
+	configEnv = makeConfigEnvelope(defaultChain, makeConfigPair("qux", "qux", 0, []byte("qux")))
+	_, err = migrationUpdate(configEnv, testMigrations())
+	assert.Error(t, err, "A failed migration should fail the update")
+
+	configEnv.Config.Channel.Values[configtxchannel.CapabilitiesKey] = &cb.ConfigValue{Value: []byte("garbage")}
+	_, err = migrationUpdate(configEnv, testMigrations())
+	assert.Error(t, err)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"fmt"
+
+	configtxapplication "github.com/hyperledger/fabric/common/configtx/handlers/application"
+	configtxorderer "github.com/hyperledger/fabric/common/configtx/handlers/orderer"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// OrganizationDefinition describes an organization to be added to a channel
+type OrganizationDefinition struct {
+	// ID is the key of the organization's group in the Application (and Orderer) group
+	ID string
+
+	// MSP is the config of the organization's MSP
+	MSP *mspprotos.MSPConfig
+
+	// AnchorPeers are the anchor peers of the organization, they may be empty
+	AnchorPeers []*pb.AnchorPeer
+
+	// Policies are the policies defined within the organization's group, they may be empty
+	Policies map[string]*cb.ConfigPolicy
+
+	// ModPolicy is the policy which must be satisfied to modify the organization's
+	// config later on. If it is empty, the mod_policy of the group the organization
+	// is added to is used
+	ModPolicy string
+
+	// Orderer requests the organization to be added to the Orderer group as well,
+	// so that its members are recognized by the ordering service
+	Orderer bool
+}
+
+// AddOrganization computes the config update adding org to the channel whose
+// current config is config. The returned ConfigUpdateEnvelope carries no signatures yet;
+// it must be signed by enough members to satisfy the mod_policy of the Application
+// group (and of the Orderer group if org.Orderer is set) before it is submitted
+func AddOrganization(config *cb.Config, org *OrganizationDefinition) (*cb.ConfigUpdateEnvelope, error) {
+	if config == nil || config.Header == nil || config.Channel == nil {
+		return nil, fmt.Errorf("Config must have a header and a channel group")
+	}
+	if org == nil || org.MSP == nil {
+		return nil, fmt.Errorf("Organization must have an MSP definition")
+	}
+	if err := validateChainID(org.ID); err != nil {
+		return nil, fmt.Errorf("Bad organization ID: %s", err)
+	}
+
+	// The write set must contain the whole config, as omitting a key is treated as an attempt to delete it
+	writeSet := proto.Clone(config.Channel).(*cb.ConfigGroup)
+	sequence := utils.ComputeConfigSequence(config.Channel) + 1
+
+	// The read set holds the groups modified, so that the update is rejected if they are modified meanwhile
+	readSet := &cb.ConfigGroup{Version: config.Channel.Version, Groups: make(map[string]*cb.ConfigGroup)}
+
+	groupKeys := []string{configtxapplication.GroupKey}
+	if org.Orderer {
+		groupKeys = append(groupKeys, configtxorderer.GroupKey)
+	}
+
+	for _, groupKey := range groupKeys {
+		group, ok := writeSet.Groups[groupKey]
+		if !ok {
+			return nil, fmt.Errorf("Channel %s has no %s group", config.Header.ChannelId, groupKey)
+		}
+		if _, ok := group.Groups[org.ID]; ok {
+			return nil, fmt.Errorf("Organization %s already exists in the %s group", org.ID, groupKey)
+		}
+		if group.Groups == nil {
+			group.Groups = make(map[string]*cb.ConfigGroup)
+		}
+
+		modPolicy := org.ModPolicy
+		if modPolicy == "" {
+			modPolicy = group.ModPolicy
+		}
+
+		orgGroup, err := makeOrganizationGroup(org, groupKey, modPolicy, sequence)
+		if err != nil {
+			return nil, err
+		}
+
+		// Adding a member modifies the group, so its version must move to the new sequence
+		readSet.Groups[groupKey] = &cb.ConfigGroup{Version: group.Version}
+		group.Groups[org.ID] = orgGroup
+		group.Version = sequence
+	}
+
+	configUpdate, err := proto.Marshal(&cb.ConfigUpdate{
+		Header: &cb.ChannelHeader{
+			ChannelId: config.Header.ChannelId,
+			Type:      int32(cb.HeaderType_CONFIG),
+		},
+		ReadSet:  readSet,
+		WriteSet: writeSet,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &cb.ConfigUpdateEnvelope{ConfigUpdate: configUpdate}, nil
+}
+
+// makeOrganizationGroup creates the group of org within the group groupKey, with
+// every element at the given version
+func makeOrganizationGroup(org *OrganizationDefinition, groupKey, modPolicy string, version uint64) (*cb.ConfigGroup, error) {
+	mspConfig, err := proto.Marshal(org.MSP)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling MSP config of organization %s: %s", org.ID, err)
+	}
+
+	orgGroup := cb.NewConfigGroup()
+	orgGroup.Version = version
+	orgGroup.ModPolicy = modPolicy
+	orgGroup.Values[MSPKey] = &cb.ConfigValue{
+		Version:   version,
+		ModPolicy: modPolicy,
+		Value:     mspConfig,
+	}
+
+	// Anchor peers are only meaningful to the peers of the channel
+	if groupKey == configtxapplication.GroupKey && len(org.AnchorPeers) > 0 {
+		orgGroup.Values[configtxapplication.AnchorPeersKey] = &cb.ConfigValue{
+			Version:   version,
+			ModPolicy: modPolicy,
+			Value:     utils.MarshalOrPanic(&pb.AnchorPeers{AnchorPeers: org.AnchorPeers}),
+		}
+	}
+
+	for key, policy := range org.Policies {
+		if err := validateChainID(key); err != nil {
+			return nil, fmt.Errorf("Bad policy name %s for organization %s: %s", key, org.ID, err)
+		}
+		orgPolicy := proto.Clone(policy).(*cb.ConfigPolicy)
+		orgPolicy.Version = version
+		if orgPolicy.ModPolicy == "" {
+			orgPolicy.ModPolicy = modPolicy
+		}
+		orgGroup.Policies[key] = orgPolicy
+	}
+
+	return orgGroup, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"testing"
+
+	configtxapplication "github.com/hyperledger/fabric/common/configtx/handlers/application"
+	configtxorderer "github.com/hyperledger/fabric/common/configtx/handlers/orderer"
+	mockconfigtx "github.com/hyperledger/fabric/common/mocks/configtx"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+func makeChannelConfig() *cb.Config {
+	channel := cb.NewConfigGroup()
+	channel.Values["foo"] = &cb.ConfigValue{Version: 2, ModPolicy: "foo", Value: []byte("foo")}
+
+	application := cb.NewConfigGroup()
+	application.ModPolicy = "ApplicationAdmins"
+	existingOrg := cb.NewConfigGroup()
+	existingOrg.Values[MSPKey] = &cb.ConfigValue{Version: 1, Value: []byte("msp")}
+	application.Groups["ExistingOrg"] = existingOrg
+	channel.Groups[configtxapplication.GroupKey] = application
+
+	orderer := cb.NewConfigGroup()
+	orderer.ModPolicy = "OrdererAdmins"
+	channel.Groups[configtxorderer.GroupKey] = orderer
+
+	return &cb.Config{
+		Header:  &cb.ChannelHeader{ChannelId: defaultChain},
+		Channel: channel,
+	}
+}
+
+func TestAddOrganization(t *testing.T) {
+	config := makeChannelConfig()
+	org := &OrganizationDefinition{
+		ID:          "NewOrg",
+		MSP:         &mspprotos.MSPConfig{Config: []byte("newmsp")},
+		AnchorPeers: []*pb.AnchorPeer{{Host: "peer0.neworg", Port: 7051}},
+		Policies:    map[string]*cb.ConfigPolicy{"Admins": {Policy: &cb.Policy{Type: int32(cb.Policy_SIGNATURE)}}},
+		Orderer:     true,
+	}
+
+	configUpdateEnv, err := AddOrganization(config, org)
+	if err != nil {
+		t.Fatalf("Error computing org addition: %s", err)
+	}
+
+	configUpdate, err := UnmarshalConfigUpdate(configUpdateEnv.ConfigUpdate)
+	if err != nil {
+		t.Fatalf("Error unmarshaling config update: %s", err)
+	}
+
+	application := configUpdate.WriteSet.Groups[configtxapplication.GroupKey]
+	if application.Version != 3 {
+		t.Errorf("Application group version should have been bumped to 3, got %d", application.Version)
+	}
+	newOrg := application.Groups["NewOrg"]
+	if newOrg == nil || newOrg.ModPolicy != "ApplicationAdmins" || newOrg.Values[MSPKey].Version != 3 {
+		t.Fatalf("New org should inherit the Application mod_policy and be at version 3, got %v", newOrg)
+	}
+	if _, ok := newOrg.Values[configtxapplication.AnchorPeersKey]; !ok {
+		t.Errorf("New org should have its anchor peers in the Application group")
+	}
+	if newOrg.Policies["Admins"].Version != 3 || newOrg.Policies["Admins"].ModPolicy != "ApplicationAdmins" {
+		t.Errorf("New org policy should be at version 3 with the org mod_policy")
+	}
+	if _, ok := application.Groups["ExistingOrg"]; !ok {
+		t.Errorf("Existing org should be retained in the write set")
+	}
+
+	ordererOrg := configUpdate.WriteSet.Groups[configtxorderer.GroupKey].Groups["NewOrg"]
+	if ordererOrg == nil || ordererOrg.ModPolicy != "OrdererAdmins" {
+		t.Fatalf("New org should have been added to the Orderer group with its mod_policy")
+	}
+	if _, ok := ordererOrg.Values[configtxapplication.AnchorPeersKey]; ok {
+		t.Errorf("Anchor peers should not be added to the Orderer group")
+	}
+
+	if config.Channel.Groups[configtxapplication.GroupKey].Version != 0 {
+		t.Errorf("The original config should not have been modified")
+	}
+
+	initializer := defaultInitializer()
+	initializer.PolicyHandlerVal = &mockconfigtx.PolicyHandler{}
+	cm, err := NewManagerImpl(&cb.ConfigEnvelope{Config: config}, initializer, nil)
+	if err != nil {
+		t.Fatalf("Error constructing config manager: %s", err)
+	}
+
+	configtx := &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: &cb.ChannelHeader{Type: int32(cb.HeaderType_CONFIG_UPDATE)}},
+			Data:   utils.MarshalOrPanic(configUpdateEnv),
+		}),
+	}
+	if err = cm.Apply(configtx); err != nil {
+		t.Fatalf("Config manager should have accepted the org addition: %s", err)
+	}
+	if cm.Sequence() != 3 {
+		t.Errorf("Sequence should have advanced to 3, got %d", cm.Sequence())
+	}
+}
+
+func TestAddOrganizationErrors(t *testing.T) {
+	msp := &mspprotos.MSPConfig{Config: []byte("msp")}
+
+	if _, err := AddOrganization(makeChannelConfig(), &OrganizationDefinition{ID: "ExistingOrg", MSP: msp}); err == nil {
+		t.Errorf("Should have failed to add an org which already exists")
+	}
+
+	if _, err := AddOrganization(makeChannelConfig(), &OrganizationDefinition{ID: "NewOrg"}); err == nil {
+		t.Errorf("Should have failed to add an org without an MSP")
+	}
+
+	if _, err := AddOrganization(makeChannelConfig(), &OrganizationDefinition{ID: "New/Org", MSP: msp}); err == nil {
+		t.Errorf("Should have failed to add an org with an illegal ID")
+	}
+
+	config := makeChannelConfig()
+	delete(config.Channel.Groups, configtxorderer.GroupKey)
+	if _, err := AddOrganization(config, &OrganizationDefinition{ID: "NewOrg", MSP: msp, Orderer: true}); err == nil {
+		t.Errorf("Should have failed to add an orderer org to a channel without an Orderer group")
+	}
+}
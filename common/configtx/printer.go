@@ -0,0 +1,342 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
+	configtxapplication "github.com/hyperledger/fabric/common/configtx/handlers/application"
+	configtxchannel "github.com/hyperledger/fabric/common/configtx/handlers/channel"
+	configtxorderer "github.com/hyperledger/fabric/common/configtx/handlers/orderer"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"gopkg.in/yaml.v2"
+)
+
+// PrintFormat is the output format of a Printer
+type PrintFormat int
+
+const (
+	// PrintJSON renders indented JSON
+	PrintJSON PrintFormat = iota
+
+	// PrintYAML renders YAML
+	PrintYAML
+)
+
+// ParsePrintFormat returns the PrintFormat named "json" or "yaml"
+func ParsePrintFormat(name string) (PrintFormat, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return PrintJSON, nil
+	case "yaml":
+		return PrintYAML, nil
+	}
+	return PrintJSON, fmt.Errorf("Unknown print format %s, expected json or yaml", name)
+}
+
+// configValueMessages maps the keys of the config values to the messages they hold
+var configValueMessages = map[string]func() proto.Message{
+	MSPKey:                              func() proto.Message { return &mspprotos.MSPConfig{} },
+	CreationPolicyKey:                   func() proto.Message { return &ab.CreationPolicy{} },
+	configtxchannel.HashingAlgorithmKey: func() proto.Message { return &cb.HashingAlgorithm{} },
+	configtxchannel.BlockDataHashingStructureKey:    func() proto.Message { return &cb.BlockDataHashingStructure{} },
+	configtxchannel.OrdererAddressesKey:             func() proto.Message { return &cb.OrdererAddresses{} },
+	configtxorderer.ConsensusTypeKey:                func() proto.Message { return &ab.ConsensusType{} },
+	configtxorderer.BatchSizeKey:                    func() proto.Message { return &ab.BatchSize{} },
+	configtxorderer.BatchTimeoutKey:                 func() proto.Message { return &ab.BatchTimeout{} },
+	configtxorderer.ChainCreationPolicyNamesKey:     func() proto.Message { return &ab.ChainCreationPolicyNames{} },
+	configtxorderer.KafkaBrokersKey:                 func() proto.Message { return &ab.KafkaBrokers{} },
+	configtxorderer.IngressPolicyNamesKey:           func() proto.Message { return &ab.IngressPolicyNames{} },
+	configtxorderer.EgressPolicyNamesKey:            func() proto.Message { return &ab.EgressPolicyNames{} },
+	configtxapplication.ShimCapabilitiesKey:         func() proto.Message { return &pb.ShimCapabilities{} },
+	configtxapplication.ChaincodeNamespacePrefixKey: func() proto.Message { return &pb.ChaincodeNamespacePrefix{} },
+	configtxapplication.AnchorPeersKey:              func() proto.Message { return &pb.AnchorPeers{} },
+}
+
+// Printer renders config envelopes and config updates as JSON or YAML trees
+// of the fields of their messages, named as in their proto definitions. The
+// marshaled messages nested in bytes fields, such as the config values, the
+// policies, the MSP configs and the signature headers, are decoded in place.
+// The other bytes fields are rendered as strings when they hold printable
+// text, such as PEM certificates, and in hex otherwise
+type Printer struct {
+	format PrintFormat
+}
+
+// NewPrinter creates a Printer rendering in the given format
+func NewPrinter(format PrintFormat) *Printer {
+	return &Printer{format: format}
+}
+
+// PrintConfigEnvelope writes the rendering of configEnvelope to w
+func (p *Printer) PrintConfigEnvelope(w io.Writer, configEnvelope *cb.ConfigEnvelope) error {
+	return p.print(w, configEnvelope)
+}
+
+// PrintConfigUpdate writes the rendering of configUpdate to w
+func (p *Printer) PrintConfigUpdate(w io.Writer, configUpdate *cb.ConfigUpdate) error {
+	return p.print(w, configUpdate)
+}
+
+func (p *Printer) print(w io.Writer, msg proto.Message) error {
+	if msg == nil || reflect.ValueOf(msg).IsNil() {
+		return fmt.Errorf("Nothing to print")
+	}
+	tree := renderMessage(reflect.ValueOf(msg))
+
+	var out []byte
+	var err error
+	switch p.format {
+	case PrintYAML:
+		out, err = yaml.Marshal(tree)
+	default:
+		out, err = json.MarshalIndent(tree, "", "  ")
+		out = append(out, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("Error rendering %T: %s", msg, err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// renderMessage renders the non zero fields of the message pointed to by msg
+func renderMessage(msg reflect.Value) map[string]interface{} {
+	tree := make(map[string]interface{})
+	renderFields(msg, msg.Elem(), false, tree)
+	return tree
+}
+
+// renderFields adds the fields of the struct v to tree. The struct is either
+// the message msg, whose zero fields are left out, or the wrapper of the field
+// set in one of its oneofs, which is rendered even if zero
+func renderFields(msg reflect.Value, v reflect.Value, oneof bool, tree map[string]interface{}) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		if !oneof && isZero(value) {
+			continue
+		}
+		if field.Tag.Get("protobuf_oneof") != "" {
+			renderFields(msg, value.Elem().Elem(), true, tree)
+			continue
+		}
+		name := fieldName(field)
+		if name == "" {
+			continue
+		}
+		tree[name] = renderField(msg.Interface(), name, value)
+	}
+}
+
+// fieldName returns the proto name of a field, or "" if it is not a proto field
+func fieldName(field reflect.StructField) string {
+	for _, part := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "name=") {
+			return strings.TrimPrefix(part, "name=")
+		}
+	}
+	return ""
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	}
+	return false
+}
+
+// renderField renders the field name of msg holding value
+func renderField(msg interface{}, name string, value reflect.Value) interface{} {
+	if bytes, ok := value.Interface().([]byte); ok {
+		if nested := nestedMessage(msg, name); nested != nil && proto.Unmarshal(bytes, nested) == nil {
+			return renderMessage(reflect.ValueOf(nested))
+		}
+		return renderBytes(bytes)
+	}
+	if enum := enumName(msg, name, value); enum != "" {
+		return enum
+	}
+	return renderValue(value)
+}
+
+func renderValue(value reflect.Value) interface{} {
+	if ts, ok := value.Interface().(*google_protobuf.Timestamp); ok {
+		if t, err := ptypes.Timestamp(ts); err == nil {
+			return t.String()
+		}
+	}
+	if stringer, ok := value.Interface().(fmt.Stringer); ok && value.Kind() == reflect.Int32 {
+		return stringer.String()
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		return renderMessage(value)
+	case reflect.Slice:
+		if bytes, ok := value.Interface().([]byte); ok {
+			return renderBytes(bytes)
+		}
+		list := make([]interface{}, value.Len())
+		for i := range list {
+			list[i] = renderValue(value.Index(i))
+		}
+		return list
+	case reflect.Map:
+		entries := make(map[string]interface{}, value.Len())
+		for _, key := range value.MapKeys() {
+			entry := value.MapIndex(key)
+			if configValue, ok := entry.Interface().(*cb.ConfigValue); ok {
+				entries[fmt.Sprint(key.Interface())] = renderConfigValue(key.String(), configValue)
+				continue
+			}
+			entries[fmt.Sprint(key.Interface())] = renderValue(entry)
+		}
+		return entries
+	}
+	return value.Interface()
+}
+
+// renderConfigValue renders a config value, decoding its value according to its key
+func renderConfigValue(key string, configValue *cb.ConfigValue) interface{} {
+	tree := renderMessage(reflect.ValueOf(configValue))
+	if newMessage, ok := configValueMessages[key]; ok && len(configValue.Value) > 0 {
+		msg := newMessage()
+		if proto.Unmarshal(configValue.Value, msg) == nil {
+			tree["value"] = renderMessage(reflect.ValueOf(msg))
+		}
+	}
+	return tree
+}
+
+// renderBytes renders printable ASCII text as a string and anything else in hex
+func renderBytes(bytes []byte) string {
+	if strings.IndexFunc(string(bytes), func(r rune) bool {
+		return r > unicode.MaxASCII || (!unicode.IsPrint(r) && !unicode.IsSpace(r))
+	}) < 0 {
+		return string(bytes)
+	}
+	return hex.EncodeToString(bytes)
+}
+
+// nestedMessage returns the message marshaled in the bytes field name of msg,
+// or nil if the field holds no message
+func nestedMessage(msg interface{}, name string) proto.Message {
+	switch m := msg.(type) {
+	case *cb.Envelope:
+		if name == "payload" {
+			return &cb.Payload{}
+		}
+	case *cb.Payload:
+		if name != "data" || m.Header == nil || m.Header.ChannelHeader == nil {
+			return nil
+		}
+		switch cb.HeaderType(m.Header.ChannelHeader.Type) {
+		case cb.HeaderType_CONFIG_UPDATE:
+			return &cb.ConfigUpdateEnvelope{}
+		case cb.HeaderType_CONFIG:
+			return &cb.ConfigEnvelope{}
+		}
+	case *cb.ConfigUpdateEnvelope:
+		if name == "config_update" {
+			return &cb.ConfigUpdate{}
+		}
+	case *cb.ConfigSignature:
+		if name == "signature_header" {
+			return &cb.SignatureHeader{}
+		}
+	case *cb.SignatureHeader:
+		if name == "creator" {
+			return &msp.SerializedIdentity{}
+		}
+	case *cb.Policy:
+		if name == "policy" && m.Type == int32(cb.Policy_SIGNATURE) {
+			return &cb.SignaturePolicyEnvelope{}
+		}
+	case *cb.MSPPrincipal:
+		if name != "principal" {
+			return nil
+		}
+		switch m.PrincipalClassification {
+		case cb.MSPPrincipal_ROLE:
+			return &cb.MSPRole{}
+		case cb.MSPPrincipal_ORGANIZATION_UNIT:
+			return &cb.OrganizationUnit{}
+		case cb.MSPPrincipal_IDENTITY:
+			return &msp.SerializedIdentity{}
+		}
+	case *mspprotos.MSPConfig:
+		if name == "config" && m.Type == int32(msp.FABRIC) {
+			return &mspprotos.FabricMSPConfig{}
+		}
+	}
+	return nil
+}
+
+// enumName returns the name of the value of the int32 fields of msg which hold
+// an enum without being declared as such, or "" for the other fields
+func enumName(msg interface{}, name string, value reflect.Value) string {
+	switch msg.(type) {
+	case *cb.ChannelHeader:
+		if name == "type" {
+			return cb.HeaderType(value.Int()).String()
+		}
+	case *cb.Policy:
+		if name == "type" {
+			return cb.Policy_PolicyType(value.Int()).String()
+		}
+	}
+	return ""
+}
+
+// enumValues returns the values by name of the enum held by the int32 field
+// name of msg, as rendered by enumName, or nil for the other fields
+func enumValues(msg interface{}, name string) map[string]int32 {
+	switch msg.(type) {
+	case *cb.ChannelHeader:
+		if name == "type" {
+			return cb.HeaderType_value
+		}
+	case *cb.Policy:
+		if name == "type" {
+			return cb.Policy_PolicyType_value
+		}
+	}
+	return nil
+}
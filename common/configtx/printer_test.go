@@ -0,0 +1,120 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	configtxorderer "github.com/hyperledger/fabric/common/configtx/handlers/orderer"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func printerTestConfigUpdate() *cb.ConfigUpdate {
+	org := cb.NewConfigGroup()
+	org.Values[MSPKey] = mspValue("org1", 1)
+	org.Policies["Admins"] = signaturePolicy(cauthdsl.SignedBy(0), rolePrincipal("org1", cb.MSPRole_ADMIN))
+
+	orderer := cb.NewConfigGroup()
+	orderer.Values[configtxorderer.BatchSizeKey] = &cb.ConfigValue{
+		Version: 1,
+		Value:   utils.MarshalOrPanic(&ab.BatchSize{MaxMessageCount: 10}),
+	}
+	orderer.Values["Opaque"] = &cb.ConfigValue{Value: []byte{0xde, 0xad}}
+	orderer.Groups["org1"] = org
+
+	channel := cb.NewConfigGroup()
+	channel.Groups[configtxorderer.GroupKey] = orderer
+	return &cb.ConfigUpdate{
+		Header:   &cb.ChannelHeader{ChannelId: "foo", Type: int32(cb.HeaderType_CONFIG_UPDATE)},
+		WriteSet: channel,
+	}
+}
+
+func TestPrintConfigUpdate(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, NewPrinter(PrintJSON).PrintConfigUpdate(buf, printerTestConfigUpdate()))
+
+	tree := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &tree), buf.String())
+	get := func(path ...string) interface{} {
+		var node interface{} = tree
+		for _, key := range path {
+			m, ok := node.(map[string]interface{})
+			if !ok {
+				t.Fatalf("No %v in %s", path, buf.String())
+			}
+			node = m[key]
+		}
+		return node
+	}
+
+	assert.Equal(t, "CONFIG_UPDATE", get("header", "type"))
+	assert.Equal(t, "foo", get("header", "channel_id"))
+	orderer := []string{"write_set", "groups", "Orderer"}
+	assert.Equal(t, float64(1), get(append(orderer, "values", "BatchSize", "version")...))
+	assert.Equal(t, float64(10), get(append(orderer, "values", "BatchSize", "value", "maxMessageCount")...))
+	assert.Equal(t, "dead", get(append(orderer, "values", "Opaque", "value")...))
+
+	org := append(orderer, "groups", "org1")
+	assert.Equal(t, "org1", get(append(org, "values", "MSP", "value", "config", "name")...))
+	assert.Equal(t, "admin", get(append(org, "values", "MSP", "value", "config", "admins")...).([]interface{})[0])
+	policy := append(org, "policies", "Admins", "policy")
+	assert.Equal(t, "SIGNATURE", get(append(policy, "type")...))
+	assert.Equal(t, float64(0), get(append(policy, "policy", "policy", "signed_by")...), "A zero oneof field should be printed")
+	principal := get(append(policy, "policy", "identities")...).([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"msp_identifier": "org1", "Role": "ADMIN"}, principal["principal"])
+}
+
+func TestPrintConfigEnvelope(t *testing.T) {
+	configUpdate := printerTestConfigUpdate()
+	configEnvelope := &cb.ConfigEnvelope{
+		Config: &cb.Config{Header: configUpdate.Header, Channel: configUpdate.WriteSet},
+		LastUpdate: &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: configUpdate.Header},
+			Data: utils.MarshalOrPanic(&cb.ConfigUpdateEnvelope{
+				ConfigUpdate: utils.MarshalOrPanic(configUpdate),
+				Signatures:   []*cb.ConfigSignature{{Signature: []byte("sig")}},
+			}),
+		})},
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, NewPrinter(PrintYAML).PrintConfigEnvelope(buf, configEnvelope))
+	tree := make(map[string]interface{})
+	assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &tree), buf.String())
+
+	lastUpdate := tree["last_update"].(map[interface{}]interface{})["payload"].(map[interface{}]interface{})["data"].(map[interface{}]interface{})
+	assert.Equal(t, "foo", lastUpdate["config_update"].(map[interface{}]interface{})["header"].(map[interface{}]interface{})["channel_id"])
+	assert.Equal(t, "sig", lastUpdate["signatures"].([]interface{})[0].(map[interface{}]interface{})["signature"])
+}
+
+func TestPrintErrors(t *testing.T) {
+	assert.Error(t, NewPrinter(PrintJSON).PrintConfigUpdate(&bytes.Buffer{}, nil))
+
+	format, err := ParsePrintFormat("YAML")
+	assert.NoError(t, err)
+	assert.Equal(t, PrintYAML, format)
+	_, err = ParsePrintFormat("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func unmarshalFabricMSPConfig(value *cb.ConfigValue) (*mspprotos.MSPConfig, *mspprotos.FabricMSPConfig, error) {
+	mspConfig := &mspprotos.MSPConfig{}
+	if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+		return nil, nil, fmt.Errorf("Error unmarshaling MSP config: %s", err)
+	}
+	if mspConfig.Type != int32(msp.FABRIC) {
+		return mspConfig, nil, nil
+	}
+	fabricConfig := &mspprotos.FabricMSPConfig{}
+	if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err != nil {
+		return nil, nil, fmt.Errorf("Error unmarshaling fabric MSP config: %s", err)
+	}
+	return mspConfig, fabricConfig, nil
+}
+
+func equalCerts(lhs, rhs [][]byte) bool {
+	if len(lhs) != len(rhs) {
+		return false
+	}
+	for i := range lhs {
+		if !bytes.Equal(lhs[i], rhs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// signedByAdmin returns nil if one of the signatures in signedData is a valid
+// signature of an admin of the MSP defined by mspConfig
+func signedByAdmin(mspConfig *mspprotos.MSPConfig, name string, signedData []*cb.SignedData) error {
+	mspInst, err := msp.NewBccspMsp()
+	if err != nil {
+		return fmt.Errorf("Error creating MSP: %s", err)
+	}
+	if err = mspInst.Setup(mspConfig); err != nil {
+		return fmt.Errorf("Error setting up MSP %s: %s", name, err)
+	}
+
+	principal := &cb.MSPPrincipal{
+		PrincipalClassification: cb.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&cb.MSPRole{MspIdentifier: name, Role: cb.MSPRole_ADMIN}),
+	}
+	for _, sd := range signedData {
+		id, err := mspInst.DeserializeIdentity(sd.Identity)
+		if err != nil {
+			continue
+		}
+		if err = mspInst.SatisfiesPrincipal(id, principal); err != nil {
+			continue
+		}
+		if err = id.Verify(sd.Data, sd.Signature); err != nil {
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("No valid signature from an admin of MSP %s", name)
+}
+
+// authorizeMSPRotation checks the modification of an MSP config value against
+// the rotation requirement of the MSP it replaces. If that MSP sets
+// RequirePairedAdminRotation, a change of its root or admin certs must be
+// signed by an admin of both the old and the new MSP, and lifting the
+// requirement must be signed by an admin of the old MSP. This prevents an
+// org from locking itself out with certs none of its admins holds, and an
+// outsider satisfying the mod_policy from taking over the org
+func authorizeMSPRotation(key string, oldValue, newValue *cb.ConfigValue, signedData []*cb.SignedData) error {
+	oldMSPConfig, oldConfig, err := unmarshalFabricMSPConfig(oldValue)
+	if err != nil {
+		return fmt.Errorf("Error reading current MSP config at %s: %s", key, err)
+	}
+	if oldConfig == nil || !oldConfig.RequirePairedAdminRotation {
+		return nil
+	}
+
+	newMSPConfig, newConfig, err := unmarshalFabricMSPConfig(newValue)
+	if err != nil {
+		return fmt.Errorf("Error reading proposed MSP config at %s: %s", key, err)
+	}
+	if newConfig == nil {
+		return fmt.Errorf("MSP at %s requires paired admin rotation and cannot change its type", key)
+	}
+
+	rotated := !equalCerts(oldConfig.RootCerts, newConfig.RootCerts) || !equalCerts(oldConfig.Admins, newConfig.Admins)
+	if !rotated && newConfig.RequirePairedAdminRotation {
+		return nil
+	}
+
+	if err = signedByAdmin(oldMSPConfig, oldConfig.Name, signedData); err != nil {
+		return fmt.Errorf("Update to MSP at %s must be signed by a current admin: %s", key, err)
+	}
+	if rotated {
+		if err = signedByAdmin(newMSPConfig, newConfig.Name, signedData); err != nil {
+			return fmt.Errorf("Update to MSP at %s must be signed by a new admin: %s", key, err)
+		}
+	}
+	return nil
+}
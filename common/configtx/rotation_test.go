@@ -0,0 +1,162 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+const rotationMSPID = "RotatingOrg"
+
+type testAdmin struct {
+	caCert    []byte
+	adminCert []byte
+	key       *ecdsa.PrivateKey
+}
+
+func makeCert(t *testing.T, template, parent *x509.Certificate, pub *ecdsa.PublicKey, signer *ecdsa.PrivateKey) ([]byte, *x509.Certificate) {
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, signer)
+	if err != nil {
+		t.Fatalf("Error creating certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Error parsing certificate: %s", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), cert
+}
+
+func newTestAdmin(t *testing.T) *testAdmin {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+	adminKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caPem, caCert := makeCert(t, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+
+	adminTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "admin"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	adminPem, _ := makeCert(t, adminTemplate, caCert, &adminKey.PublicKey, caKey)
+
+	return &testAdmin{caCert: caPem, adminCert: adminPem, key: adminKey}
+}
+
+func (ta *testAdmin) sign(t *testing.T, data []byte) *cb.SignedData {
+	digest := sha256.Sum256(data)
+	r, s, err := ecdsa.Sign(rand.Reader, ta.key, digest[:])
+	if err != nil {
+		t.Fatalf("Error signing: %s", err)
+	}
+	// The BCCSP only accepts signatures in their low-S form
+	if halfOrder := new(big.Int).Rsh(elliptic.P256().Params().N, 1); s.Cmp(halfOrder) > 0 {
+		s.Sub(elliptic.P256().Params().N, s)
+	}
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		t.Fatalf("Error marshaling signature: %s", err)
+	}
+	return &cb.SignedData{
+		Data:      data,
+		Identity:  utils.MarshalOrPanic(&msp.SerializedIdentity{Mspid: rotationMSPID, IdBytes: ta.adminCert}),
+		Signature: sig,
+	}
+}
+
+func (ta *testAdmin) mspValue(paired bool) *cb.ConfigValue {
+	return &cb.ConfigValue{Value: utils.MarshalOrPanic(&mspprotos.MSPConfig{
+		Type: int32(msp.FABRIC),
+		Config: utils.MarshalOrPanic(&mspprotos.FabricMSPConfig{
+			Name:                       rotationMSPID,
+			RootCerts:                  [][]byte{ta.caCert},
+			Admins:                     [][]byte{ta.adminCert},
+			RequirePairedAdminRotation: paired,
+		}),
+	})}
+}
+
+func TestAuthorizeMSPRotation(t *testing.T) {
+	oldAdmin := newTestAdmin(t)
+	newAdmin := newTestAdmin(t)
+	data := []byte("config update")
+
+	// Without the requirement, the mod_policy alone governs the rotation
+	if err := authorizeMSPRotation(MSPKey, oldAdmin.mspValue(false), newAdmin.mspValue(false), nil); err != nil {
+		t.Errorf("Rotation should not require admin signatures unless requested: %s", err)
+	}
+
+	oldValue := oldAdmin.mspValue(true)
+	newValue := newAdmin.mspValue(true)
+
+	if err := authorizeMSPRotation(MSPKey, oldValue, newValue, []*cb.SignedData{oldAdmin.sign(t, data)}); err == nil {
+		t.Errorf("Rotation signed only by the old admin should have been rejected")
+	}
+
+	if err := authorizeMSPRotation(MSPKey, oldValue, newValue, []*cb.SignedData{newAdmin.sign(t, data)}); err == nil {
+		t.Errorf("Rotation signed only by the new admin should have been rejected")
+	}
+
+	if err := authorizeMSPRotation(MSPKey, oldValue, newValue, []*cb.SignedData{oldAdmin.sign(t, data), newAdmin.sign(t, data)}); err != nil {
+		t.Errorf("Rotation signed by both the old and new admins should have been accepted: %s", err)
+	}
+
+	forged := oldAdmin.sign(t, data)
+	forged.Data = []byte("other config update")
+	if err := authorizeMSPRotation(MSPKey, oldValue, newValue, []*cb.SignedData{forged, newAdmin.sign(t, data)}); err == nil {
+		t.Errorf("Rotation with an invalid old admin signature should have been rejected")
+	}
+
+	if err := authorizeMSPRotation(MSPKey, oldValue, oldAdmin.mspValue(false), nil); err == nil {
+		t.Errorf("Lifting the requirement without an old admin signature should have been rejected")
+	}
+
+	if err := authorizeMSPRotation(MSPKey, oldValue, oldAdmin.mspValue(false), []*cb.SignedData{oldAdmin.sign(t, data)}); err != nil {
+		t.Errorf("Lifting the requirement signed by the old admin should have been accepted: %s", err)
+	}
+}
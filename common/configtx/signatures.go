@@ -0,0 +1,188 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// Validator validates config update transactions, as the config Manager of a channel does
+type Validator interface {
+	Validate(configtx *cb.Envelope) error
+}
+
+// SignatureCollector accumulates the signatures of a config update from the
+// admins whose approval it needs, as they come, keeping a single valid
+// signature per signer. It may be used concurrently
+type SignatureCollector struct {
+	lock            sync.Mutex
+	configUpdateEnv *cb.ConfigUpdateEnvelope
+	signers         map[string]struct{}
+	deserializer    msp.IdentityDeserializer
+}
+
+// NewSignatureCollector creates a SignatureCollector for the config update of
+// configUpdateEnv, starting with the signatures it already carries. The
+// signers are deserialized by deserializer, such as the MSP manager of the
+// channel, to verify their signatures
+func NewSignatureCollector(configUpdateEnv *cb.ConfigUpdateEnvelope, deserializer msp.IdentityDeserializer) (*SignatureCollector, error) {
+	if deserializer == nil {
+		return nil, fmt.Errorf("Cannot collect signatures without identity deserializer")
+	}
+	if configUpdateEnv == nil {
+		return nil, fmt.Errorf("Cannot collect signatures for nil ConfigUpdateEnvelope")
+	}
+	if _, err := UnmarshalConfigUpdate(configUpdateEnv.ConfigUpdate); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling ConfigUpdate: %s", err)
+	}
+
+	sc := &SignatureCollector{
+		configUpdateEnv: &cb.ConfigUpdateEnvelope{ConfigUpdate: configUpdateEnv.ConfigUpdate},
+		signers:         make(map[string]struct{}),
+		deserializer:    deserializer,
+	}
+	for _, configSig := range configUpdateEnv.Signatures {
+		if _, err := sc.AddSignature(configSig); err != nil {
+			return nil, err
+		}
+	}
+	return sc, nil
+}
+
+// Sign signs the config update with signer and adds the signature
+func (sc *SignatureCollector) Sign(signer crypto.LocalSigner) error {
+	sigHeader, err := signer.NewSignatureHeader()
+	if err != nil {
+		return fmt.Errorf("Error creating signature header: %s", err)
+	}
+	configSig := &cb.ConfigSignature{SignatureHeader: utils.MarshalOrPanic(sigHeader)}
+	configSig.Signature, err = signer.Sign(util.ConcatenateBytes(configSig.SignatureHeader, sc.configUpdateEnv.ConfigUpdate))
+	if err != nil {
+		return fmt.Errorf("Error signing config update: %s", err)
+	}
+	_, err = sc.AddSignature(configSig)
+	return err
+}
+
+// AddSignature adds a signature of the config update. It returns false if its
+// signer had already signed, in which case the signature is dropped, and an
+// error if the signature isn't a valid one of its signer, so that a signature
+// claiming to be from a signer cannot take the place of the signer's own
+func (sc *SignatureCollector) AddSignature(configSig *cb.ConfigSignature) (bool, error) {
+	if configSig == nil {
+		return false, fmt.Errorf("Cannot add nil ConfigSignature")
+	}
+	sigHeader, err := utils.GetSignatureHeader(configSig.SignatureHeader)
+	if err != nil {
+		return false, fmt.Errorf("Error unmarshaling signature header: %s", err)
+	}
+	if len(sigHeader.Creator) == 0 {
+		return false, fmt.Errorf("Signature header has no creator")
+	}
+	identity, err := sc.deserializer.DeserializeIdentity(sigHeader.Creator)
+	if err != nil {
+		return false, fmt.Errorf("Error deserializing the creator of the signature: %s", err)
+	}
+	if err = identity.Verify(util.ConcatenateBytes(configSig.SignatureHeader, sc.configUpdateEnv.ConfigUpdate), configSig.Signature); err != nil {
+		return false, fmt.Errorf("Invalid signature of config update: %s", err)
+	}
+
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	if _, ok := sc.signers[string(sigHeader.Creator)]; ok {
+		logger.Debugf("Dropping duplicate signature of config update")
+		return false, nil
+	}
+	sc.signers[string(sigHeader.Creator)] = struct{}{}
+	sc.configUpdateEnv.Signatures = append(sc.configUpdateEnv.Signatures, configSig)
+	return true, nil
+}
+
+// Merge adds the signatures of configUpdateEnv, which must carry the same
+// config update, such as a copy signed by other admins. It returns the number
+// of signatures added
+func (sc *SignatureCollector) Merge(configUpdateEnv *cb.ConfigUpdateEnvelope) (int, error) {
+	if configUpdateEnv == nil || !bytes.Equal(configUpdateEnv.ConfigUpdate, sc.configUpdateEnv.ConfigUpdate) {
+		return 0, fmt.Errorf("Cannot merge the signatures of a different config update")
+	}
+	added := 0
+	for _, configSig := range configUpdateEnv.Signatures {
+		ok, err := sc.AddSignature(configSig)
+		if err != nil {
+			return added, err
+		}
+		if ok {
+			added++
+		}
+	}
+	return added, nil
+}
+
+// Signers returns the number of distinct signers of the config update
+func (sc *SignatureCollector) Signers() int {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	return len(sc.signers)
+}
+
+// ConfigUpdateEnvelope returns the config update with the signatures collected so far
+func (sc *SignatureCollector) ConfigUpdateEnvelope() *cb.ConfigUpdateEnvelope {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	signatures := make([]*cb.ConfigSignature, len(sc.configUpdateEnv.Signatures))
+	copy(signatures, sc.configUpdateEnv.Signatures)
+	return &cb.ConfigUpdateEnvelope{ConfigUpdate: sc.configUpdateEnv.ConfigUpdate, Signatures: signatures}
+}
+
+// Envelope returns the CONFIG_UPDATE transaction carrying the config update
+// with the signatures collected so far. The transaction itself is not signed
+func (sc *SignatureCollector) Envelope() (*cb.Envelope, error) {
+	configUpdate, err := UnmarshalConfigUpdate(sc.configUpdateEnv.ConfigUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if configUpdate.Header == nil {
+		return nil, fmt.Errorf("Config update must have header set")
+	}
+	payloadChannelHeader := utils.MakeChannelHeader(cb.HeaderType_CONFIG_UPDATE, msgVersion, configUpdate.Header.ChannelId, epoch)
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: payloadChannelHeader},
+			Data:   utils.MarshalOrPanic(sc.ConfigUpdateEnvelope()),
+		}),
+	}, nil
+}
+
+// Satisfied validates the config update with the signatures collected so far
+// against validator, such as the config Manager of the channel. It returns nil
+// once the mod policies of every modified config item are satisfied, and the
+// reason for which the config update is rejected otherwise
+func (sc *SignatureCollector) Satisfied(validator Validator) error {
+	configtx, err := sc.Envelope()
+	if err != nil {
+		return err
+	}
+	return validator.Validate(configtx)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configtx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSigner struct {
+	creator string
+}
+
+func (ms *mockSigner) NewSignatureHeader() (*cb.SignatureHeader, error) {
+	return &cb.SignatureHeader{Creator: []byte(ms.creator), Nonce: utils.CreateNonceOrPanic()}, nil
+}
+
+func (ms *mockSigner) Sign(message []byte) ([]byte, error) {
+	return []byte(ms.creator), nil
+}
+
+// thresholdValidator accepts config updates signed by at least threshold signers
+type thresholdValidator struct {
+	threshold int
+}
+
+func (tv *thresholdValidator) Validate(configtx *cb.Envelope) error {
+	configUpdateEnv, err := envelopeToConfigUpdate(configtx)
+	if err != nil {
+		return err
+	}
+	signedData, err := configUpdateEnv.AsSignedData()
+	if err != nil {
+		return err
+	}
+	if len(signedData) < tv.threshold {
+		return fmt.Errorf("Signed by %d signers, %d required", len(signedData), tv.threshold)
+	}
+	return nil
+}
+
+func signatureCollectorTestUpdate() *cb.ConfigUpdateEnvelope {
+	return &cb.ConfigUpdateEnvelope{ConfigUpdate: utils.MarshalOrPanic(&cb.ConfigUpdate{
+		Header:   &cb.ChannelHeader{ChannelId: "foo"},
+		WriteSet: cb.NewConfigGroup(),
+	})}
+}
+
+func TestSignatureCollector(t *testing.T) {
+	sc, err := NewSignatureCollector(signatureCollectorTestUpdate(), cauthdsl.NewMockDeserializer())
+	assert.NoError(t, err)
+	validator := &thresholdValidator{threshold: 2}
+
+	assert.NoError(t, sc.Sign(&mockSigner{creator: "admin1"}))
+	assert.Error(t, sc.Satisfied(validator))
+
+	assert.NoError(t, sc.Sign(&mockSigner{creator: "admin1"}))
+	assert.Equal(t, 1, sc.Signers(), "A second signature of the same signer should be dropped")
+	assert.Error(t, sc.Satisfied(validator))
+
+	assert.NoError(t, sc.Sign(&mockSigner{creator: "admin2"}))
+	assert.NoError(t, sc.Satisfied(validator))
+
+	configtx, err := sc.Envelope()
+	assert.NoError(t, err)
+	payload, err := utils.UnmarshalPayload(configtx.Payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", payload.Header.ChannelHeader.ChannelId)
+	assert.Len(t, sc.ConfigUpdateEnvelope().Signatures, 2)
+}
+
+func TestSignatureCollectorMerge(t *testing.T) {
+	sc, err := NewSignatureCollector(signatureCollectorTestUpdate(), cauthdsl.NewMockDeserializer())
+	assert.NoError(t, err)
+	assert.NoError(t, sc.Sign(&mockSigner{creator: "admin1"}))
+
+	// Other admins sign their own copies of the config update
+	other, err := NewSignatureCollector(sc.ConfigUpdateEnvelope(), cauthdsl.NewMockDeserializer())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, other.Signers())
+	assert.NoError(t, other.Sign(&mockSigner{creator: "admin2"}))
+	assert.NoError(t, other.Sign(&mockSigner{creator: "admin3"}))
+
+	added, err := sc.Merge(other.ConfigUpdateEnvelope())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 3, sc.Signers())
+
+	different := &cb.ConfigUpdateEnvelope{ConfigUpdate: utils.MarshalOrPanic(&cb.ConfigUpdate{Header: &cb.ChannelHeader{ChannelId: "bar"}})}
+	_, err = sc.Merge(different)
+	assert.Error(t, err, "Signatures of another config update should not be merged")
+}
+
+func TestSignatureCollectorErrors(t *testing.T) {
+	_, err := NewSignatureCollector(nil, cauthdsl.NewMockDeserializer())
+	assert.Error(t, err)
+	_, err = NewSignatureCollector(&cb.ConfigUpdateEnvelope{ConfigUpdate: []byte("garbage")}, cauthdsl.NewMockDeserializer())
+	assert.Error(t, err)
+
+	sc, err := NewSignatureCollector(signatureCollectorTestUpdate(), cauthdsl.NewMockDeserializer())
+	assert.NoError(t, err)
+	_, err = sc.AddSignature(&cb.ConfigSignature{SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{})})
+	assert.Error(t, err, "A signature without creator should be rejected")
+	_, err = NewSignatureCollector(signatureCollectorTestUpdate(), nil)
+	assert.Error(t, err, "A collector without deserializer should be refused")
+}
+
+func TestSignatureCollectorForgedSignature(t *testing.T) {
+	sc, err := NewSignatureCollector(signatureCollectorTestUpdate(), cauthdsl.NewMockDeserializer())
+	assert.NoError(t, err)
+
+	// A signature claiming to be from admin1 which doesn't verify is rejected
+	forged := &cb.ConfigSignature{
+		SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: []byte("admin1"), Nonce: utils.CreateNonceOrPanic()}),
+		Signature:       []byte("badsigned"),
+	}
+	added, err := sc.AddSignature(forged)
+	assert.Error(t, err)
+	assert.False(t, added)
+	assert.Equal(t, 0, sc.Signers())
+
+	// and doesn't keep admin1 from signing
+	assert.NoError(t, sc.Sign(&mockSigner{creator: "admin1"}))
+	assert.Equal(t, 1, sc.Signers())
+	assert.Equal(t, []byte("admin1"), sc.ConfigUpdateEnvelope().Signatures[0].Signature)
+
+	// nor are the signatures of a config update carrying a forged one accepted
+	_, err = NewSignatureCollector(&cb.ConfigUpdateEnvelope{
+		ConfigUpdate: signatureCollectorTestUpdate().ConfigUpdate,
+		Signatures:   []*cb.ConfigSignature{forged},
+	}, cauthdsl.NewMockDeserializer())
+	assert.Error(t, err)
+}
@@ -40,7 +40,8 @@ const Prefix string = "CONFIGTX"
 
 // TopLevel contains the genesis structures for use by the provisional bootstrapper
 type TopLevel struct {
-	Orderer Orderer
+	Orderer      Orderer
+	Capabilities []string
 }
 
 // Orderer contains config which is used for orderer genesis by the provisional bootstrapper
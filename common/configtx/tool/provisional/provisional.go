@@ -94,6 +94,10 @@ func New(conf *genesisconfig.TopLevel) Generator {
 		},
 	}
 
+	if len(conf.Capabilities) > 0 {
+		bs.minimalGroups = append(bs.minimalGroups, configtxchannel.TemplateCapabilities(conf.Capabilities...))
+	}
+
 	switch conf.Orderer.OrdererType {
 	case ConsensusTypeSolo, ConsensusTypeSbft:
 	case ConsensusTypeKafka:
@@ -20,6 +20,9 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/hyperledger/fabric/common/capabilities"
+	"github.com/hyperledger/fabric/common/configtx"
+	configtxchannel "github.com/hyperledger/fabric/common/configtx/handlers/channel"
 	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
 )
@@ -60,3 +63,15 @@ func TestGenesisMetadata(t *testing.T) {
 		}
 	}
 }
+
+func TestGenesisCapabilities(t *testing.T) {
+	conf := genesisconfig.Load()
+	conf.Capabilities = []string{capabilities.ReadSetValidation}
+	configEnv, err := configtx.ConfigEnvelopeFromBlock(New(conf).GenesisBlock())
+	if err != nil {
+		t.Fatalf("Error extracting genesis config: %s", err)
+	}
+	if _, ok := configEnv.Config.Channel.Values[configtxchannel.CapabilitiesKey]; !ok {
+		t.Fatalf("Should have the capabilities set")
+	}
+}
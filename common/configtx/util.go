@@ -18,6 +18,8 @@ package configtx
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -83,3 +85,52 @@ func ConfigEnvelopeFromBlock(block *cb.Block) (*cb.ConfigEnvelope, error) {
 
 	return UnmarshalConfigEnvelope(payload.Data)
 }
+
+// FindPolicy returns the policy name of the channel group of config. A name
+// of the form Group/.../Key designates the policy Key of the group at that path
+// under the channel group, whereas a bare key designates the policy of that key
+// wherever it is in the config, provided there is a single one
+func FindPolicy(config *cb.Config, name string) (*cb.Policy, error) {
+	if config == nil || config.Channel == nil {
+		return nil, fmt.Errorf("Config has no channel group")
+	}
+
+	if strings.Contains(name, "/") {
+		path := strings.Split(strings.Trim(name, "/"), "/")
+		group := config.Channel
+		for _, groupKey := range path[:len(path)-1] {
+			if group = group.Groups[groupKey]; group == nil {
+				return nil, fmt.Errorf("No group %s on the path of policy %s", groupKey, name)
+			}
+		}
+		configPolicy, ok := group.Policies[path[len(path)-1]]
+		if !ok || configPolicy.Policy == nil {
+			return nil, fmt.Errorf("No policy %s", name)
+		}
+		return configPolicy.Policy, nil
+	}
+
+	var found []string
+	var policy *cb.Policy
+	var walk func(group *cb.ConfigGroup, path string)
+	walk = func(group *cb.ConfigGroup, path string) {
+		if configPolicy, ok := group.Policies[name]; ok && configPolicy.Policy != nil {
+			found = append(found, path+name)
+			policy = configPolicy.Policy
+		}
+		for groupKey, subGroup := range group.Groups {
+			walk(subGroup, path+groupKey+"/")
+		}
+	}
+	walk(config.Channel, "")
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("No policy %s", name)
+	case 1:
+		return policy, nil
+	default:
+		sort.Strings(found)
+		return nil, fmt.Errorf("Policy %s is ambiguous, it may be any of %s", name, strings.Join(found, ", "))
+	}
+}
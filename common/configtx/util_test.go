@@ -19,6 +19,9 @@ package configtx
 import (
 	"math/rand"
 	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
 )
 
 // TestValidchainID checks that the constraints on chain IDs are enforced properly
@@ -69,3 +72,37 @@ func randomAlphaString(size int) string {
 	}
 	return string(output)
 }
+
+func TestFindPolicy(t *testing.T) {
+	policy := func(name string) *cb.ConfigPolicy {
+		return &cb.ConfigPolicy{Policy: &cb.Policy{Type: int32(cb.Policy_SIGNATURE), Policy: []byte(name)}}
+	}
+	org1, org2 := cb.NewConfigGroup(), cb.NewConfigGroup()
+	org1.Policies["Admins"] = policy("org1")
+	org2.Policies["Admins"] = policy("org2")
+	org2.Policies["Writers"] = policy("writers")
+	application := cb.NewConfigGroup()
+	application.Groups["Org1"] = org1
+	application.Groups["Org2"] = org2
+	channel := cb.NewConfigGroup()
+	channel.Groups["Application"] = application
+	channel.Policies["Readers"] = policy("readers")
+	config := &cb.Config{Channel: channel}
+
+	found, err := FindPolicy(config, "Application/Org2/Admins")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("org2"), found.Policy)
+	found, err = FindPolicy(config, "Writers")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("writers"), found.Policy)
+	found, err = FindPolicy(config, "/Readers")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("readers"), found.Policy)
+
+	_, err = FindPolicy(config, "Admins")
+	assert.Error(t, err, "A key of several policies should be ambiguous")
+	_, err = FindPolicy(config, "Application/Org3/Admins")
+	assert.Error(t, err)
+	_, err = FindPolicy(config, "Missing")
+	assert.Error(t, err)
+}
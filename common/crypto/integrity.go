@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// NewIntegrityProof computes the hash of content and signs it with signer, so that
+// a receiver can verify the content did not change on its way from the signer
+func NewIntegrityProof(signer LocalSigner, content []byte) (*cb.IntegrityProof, error) {
+	sigHeader, err := signer.NewSignatureHeader()
+	if err != nil {
+		return nil, fmt.Errorf("Error creating signature header: %s", err)
+	}
+	sigHeaderBytes, err := proto.Marshal(sigHeader)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling signature header: %s", err)
+	}
+
+	hash := sha256.Sum256(content)
+	signature, err := signer.Sign(append(sigHeaderBytes, hash[:]...))
+	if err != nil {
+		return nil, fmt.Errorf("Error signing content hash: %s", err)
+	}
+
+	return &cb.IntegrityProof{
+		ContentHash:     hash[:],
+		SignatureHeader: sigHeaderBytes,
+		Signature:       signature,
+	}, nil
+}
+
+// IntegritySignedData checks that proof matches content, and returns the signed
+// data of the proof, whose signature must still be verified against the identity
+// of the signer (for instance by evaluating a policy over it)
+func IntegritySignedData(proof *cb.IntegrityProof, content []byte) (*cb.SignedData, error) {
+	if proof == nil {
+		return nil, fmt.Errorf("No integrity proof")
+	}
+
+	hash := sha256.Sum256(content)
+	if !bytes.Equal(hash[:], proof.ContentHash) {
+		return nil, fmt.Errorf("Content hash does not match the integrity proof")
+	}
+
+	sigHeader := &cb.SignatureHeader{}
+	if err := proto.Unmarshal(proof.SignatureHeader, sigHeader); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling signature header: %s", err)
+	}
+
+	data := make([]byte, 0, len(proof.SignatureHeader)+len(proof.ContentHash))
+	data = append(data, proof.SignatureHeader...)
+	data = append(data, proof.ContentHash...)
+
+	return &cb.SignedData{
+		Data:      data,
+		Identity:  sigHeader.Creator,
+		Signature: proof.Signature,
+	}, nil
+}
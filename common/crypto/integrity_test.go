@@ -0,0 +1,62 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+type echoSigner struct{}
+
+func (echoSigner) NewSignatureHeader() (*cb.SignatureHeader, error) {
+	return &cb.SignatureHeader{Creator: []byte("creator"), Nonce: []byte("nonce")}, nil
+}
+
+func (echoSigner) Sign(message []byte) ([]byte, error) {
+	return message, nil
+}
+
+func TestIntegrityProof(t *testing.T) {
+	content := []byte("block bytes")
+
+	proof, err := NewIntegrityProof(echoSigner{}, content)
+	if err != nil {
+		t.Fatalf("Error creating integrity proof: %s", err)
+	}
+
+	sd, err := IntegritySignedData(proof, content)
+	if err != nil {
+		t.Fatalf("Integrity proof should have matched its content: %s", err)
+	}
+	if !bytes.Equal(sd.Identity, []byte("creator")) {
+		t.Errorf("Signed data should carry the creator of the signature header")
+	}
+	if !bytes.Equal(sd.Data, sd.Signature) {
+		t.Errorf("Signed data should be what the signer signed")
+	}
+
+	if _, err = IntegritySignedData(proof, []byte("tampered bytes")); err == nil {
+		t.Errorf("Integrity proof should not have matched tampered content")
+	}
+
+	if _, err = IntegritySignedData(nil, content); err == nil {
+		t.Errorf("Missing integrity proof should have been rejected")
+	}
+}
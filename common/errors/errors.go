@@ -0,0 +1,217 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package errors provides the errors the peer and orderer APIs return to
+// their clients. An error carries a code, which is stable across releases,
+// and a category telling the clients whether to retry the request, so that
+// they base their retry and alerting policies on them rather than on the
+// error messages. The errors are conveyed as a common.ErrorInfo, in the
+// details of the proposal responses and in the broadcast responses
+package errors
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/ptypes"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"google.golang.org/grpc/codes"
+)
+
+// The codes of the errors returned by the peer and orderer APIs. They are
+// part of the APIs: a code is never renamed nor reused for another error
+const (
+	// AccessDenied is returned when the creator of a request doesn't satisfy
+	// the policy it is checked against
+	AccessDenied = "ACCESS_DENIED"
+	// ChannelNotFound is returned when a request is for a channel the peer
+	// or orderer doesn't serve
+	ChannelNotFound = "CHANNEL_NOT_FOUND"
+	// ChannelCreationRejected is returned when the orderer rejects a request
+	// to create a channel
+	ChannelCreationRejected = "CHANNEL_CREATION_REJECTED"
+	// EndorsementFailed is returned when the peer fails endorsing the
+	// simulation results of a proposal
+	EndorsementFailed = "ENDORSEMENT_FAILED"
+	// Internal is returned for the errors which are not otherwise coded
+	Internal = "INTERNAL"
+	// InvalidTxID is returned when a proposal has no transaction ID
+	InvalidTxID = "INVALID_TXID"
+	// MalformedProposal is returned when a proposal can't be unmarshaled or
+	// fails the checks of its header and signature
+	MalformedProposal = "MALFORMED_PROPOSAL"
+	// MalformedEnvelope is returned when an envelope, or a chunk of it, can't
+	// be unmarshaled or lacks its header
+	MalformedEnvelope = "MALFORMED_ENVELOPE"
+	// MessageRejected is returned when a broadcast message is rejected by the
+	// filters of its channel
+	MessageRejected = "MESSAGE_REJECTED"
+	// RateLimited is returned when a client or organization exceeds the rate
+	// of proposals it is allowed
+	RateLimited = "RATE_LIMITED"
+	// RequestTooLarge is returned when a request exceeds the size limits of
+	// the peer or orderer
+	RequestTooLarge = "REQUEST_TOO_LARGE"
+	// ServiceUnavailable is returned when the peer or orderer is shutting
+	// down or draining, or is too busy to handle a request
+	ServiceUnavailable = "SERVICE_UNAVAILABLE"
+	// SimulationFailed is returned when the peer fails simulating a proposal
+	SimulationFailed = "SIMULATION_FAILED"
+	// StorageExceeded is returned when the ledger of a channel exceeds its
+	// storage quota
+	StorageExceeded = "STORAGE_EXCEEDED"
+)
+
+// Error is an error of the peer or orderer APIs, with a stable code and a
+// category. Its message is the one of the error it replaces, so that the
+// clients matching messages keep working
+type Error struct {
+	Code     string
+	Category cb.ErrorCategory
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// New returns an Error of the given category and code
+func New(category cb.ErrorCategory, code string, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Category: category, Message: fmt.Sprintf(format, args...)}
+}
+
+// Authorization returns an Error of the AUTHORIZATION category
+func Authorization(code string, format string, args ...interface{}) *Error {
+	return New(cb.ErrorCategory_AUTHORIZATION, code, format, args...)
+}
+
+// Validation returns an Error of the VALIDATION category
+func Validation(code string, format string, args ...interface{}) *Error {
+	return New(cb.ErrorCategory_VALIDATION, code, format, args...)
+}
+
+// Conflict returns an Error of the CONFLICT category
+func Conflict(code string, format string, args ...interface{}) *Error {
+	return New(cb.ErrorCategory_CONFLICT, code, format, args...)
+}
+
+// Unavailable returns an Error of the UNAVAILABLE category
+func Unavailable(code string, format string, args ...interface{}) *Error {
+	return New(cb.ErrorCategory_UNAVAILABLE, code, format, args...)
+}
+
+// Wrap returns err if it is an Error, or an Error of the given category and
+// code with the message of err otherwise. It returns nil if err is nil
+func Wrap(err error, category cb.ErrorCategory, code string) *Error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{Code: code, Category: category, Message: err.Error()}
+}
+
+// Info returns the ErrorInfo conveying err to the clients. An error which is
+// not an Error is conveyed as an INTERNAL one. It returns nil if err is nil
+func Info(err error) *cb.ErrorInfo {
+	e := Wrap(err, cb.ErrorCategory_INTERNAL, Internal)
+	if e == nil {
+		return nil
+	}
+	return &cb.ErrorInfo{Code: e.Code, Category: e.Category, Message: e.Message}
+}
+
+// FromInfo returns the Error conveyed by info, or nil if info conveys none
+func FromInfo(info *cb.ErrorInfo) *Error {
+	if info == nil || info.Code == "" {
+		return nil
+	}
+	category := info.Category
+	if category == cb.ErrorCategory_UNCATEGORIZED {
+		category = cb.ErrorCategory_INTERNAL
+	}
+	return &Error{Code: info.Code, Category: category, Message: info.Message}
+}
+
+// Retryable returns whether a request which failed with an error of the given
+// category may succeed if submitted again as is, once the service recovered.
+// A request failing with a CONFLICT error may succeed once rebuilt
+func Retryable(category cb.ErrorCategory) bool {
+	return category == cb.ErrorCategory_UNAVAILABLE
+}
+
+// GRPCCode returns the gRPC style status code of the errors of the given
+// category, set in the code of the proposal responses
+func GRPCCode(category cb.ErrorCategory) codes.Code {
+	switch category {
+	case cb.ErrorCategory_AUTHORIZATION:
+		return codes.PermissionDenied
+	case cb.ErrorCategory_VALIDATION:
+		return codes.InvalidArgument
+	case cb.ErrorCategory_CONFLICT:
+		return codes.Aborted
+	case cb.ErrorCategory_UNAVAILABLE:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// CategoryOfStatus returns the category of the errors reported by the given
+// broadcast status, for the components which report a status alone
+func CategoryOfStatus(status cb.Status) cb.ErrorCategory {
+	switch status {
+	case cb.Status_FORBIDDEN:
+		return cb.ErrorCategory_AUTHORIZATION
+	case cb.Status_BAD_REQUEST, cb.Status_NOT_FOUND, cb.Status_REQUEST_ENTITY_TOO_LARGE:
+		return cb.ErrorCategory_VALIDATION
+	case cb.Status_SERVICE_UNAVAILABLE, cb.Status_INSUFFICIENT_STORAGE:
+		return cb.ErrorCategory_UNAVAILABLE
+	default:
+		return cb.ErrorCategory_INTERNAL
+	}
+}
+
+// Response returns the response to a proposal failing with err, with the
+// given status. The code of the response is the gRPC style code of the
+// category of err, and its details carry the ErrorInfo of err
+func Response(status int32, err error) *pb.Response {
+	info := Info(err)
+	res := &pb.Response{Status: status, Message: info.Message, Code: int32(GRPCCode(info.Category))}
+	if a, marshalErr := ptypes.MarshalAny(info); marshalErr == nil {
+		res.Details = append(res.Details, a)
+	}
+	return res
+}
+
+// FromResponse returns the Error conveyed in the details of a proposal
+// response, or nil if it conveys none
+func FromResponse(res *pb.Response) *Error {
+	if res == nil {
+		return nil
+	}
+	for _, detail := range res.Details {
+		info := &cb.ErrorInfo{}
+		if !ptypes.Is(detail, info) {
+			continue
+		}
+		if err := ptypes.UnmarshalAny(detail, info); err == nil {
+			return FromInfo(info)
+		}
+	}
+	return nil
+}
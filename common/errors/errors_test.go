@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestInfo(t *testing.T) {
+	assert.Nil(t, Info(nil))
+
+	info := Info(Authorization(AccessDenied, "Access denied to chaincode %s", "mycc"))
+	assert.Equal(t, &cb.ErrorInfo{Code: AccessDenied, Category: cb.ErrorCategory_AUTHORIZATION, Message: "Access denied to chaincode mycc"}, info)
+
+	info = Info(fmt.Errorf("failure"))
+	assert.Equal(t, &cb.ErrorInfo{Code: Internal, Category: cb.ErrorCategory_INTERNAL, Message: "failure"}, info)
+}
+
+func TestWrap(t *testing.T) {
+	assert.Nil(t, Wrap(nil, cb.ErrorCategory_INTERNAL, SimulationFailed))
+
+	err := Unavailable(RateLimited, "rate exceeded")
+	assert.Equal(t, err, Wrap(err, cb.ErrorCategory_INTERNAL, SimulationFailed), "An Error should be returned as is")
+
+	wrapped := Wrap(fmt.Errorf("failure"), cb.ErrorCategory_INTERNAL, SimulationFailed)
+	assert.Equal(t, SimulationFailed, wrapped.Code)
+	assert.Equal(t, "failure", wrapped.Error())
+}
+
+func TestFromInfo(t *testing.T) {
+	assert.Nil(t, FromInfo(nil))
+	assert.Nil(t, FromInfo(&cb.ErrorInfo{}), "An empty info conveys no error")
+
+	err := FromInfo(&cb.ErrorInfo{Code: "FUTURE_CODE", Message: "failure"})
+	assert.Equal(t, cb.ErrorCategory_INTERNAL, err.Category, "An uncategorized error should be internal")
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, Retryable(cb.ErrorCategory_UNAVAILABLE))
+	for _, category := range []cb.ErrorCategory{cb.ErrorCategory_AUTHORIZATION, cb.ErrorCategory_VALIDATION, cb.ErrorCategory_CONFLICT, cb.ErrorCategory_INTERNAL} {
+		assert.False(t, Retryable(category), "%s errors should not be retryable", category)
+	}
+}
+
+func TestCategoryOfStatus(t *testing.T) {
+	assert.Equal(t, cb.ErrorCategory_AUTHORIZATION, CategoryOfStatus(cb.Status_FORBIDDEN))
+	assert.Equal(t, cb.ErrorCategory_VALIDATION, CategoryOfStatus(cb.Status_BAD_REQUEST))
+	assert.Equal(t, cb.ErrorCategory_UNAVAILABLE, CategoryOfStatus(cb.Status_SERVICE_UNAVAILABLE))
+	assert.Equal(t, cb.ErrorCategory_INTERNAL, CategoryOfStatus(cb.Status_INTERNAL_SERVER_ERROR))
+}
+
+func TestResponse(t *testing.T) {
+	res := Response(403, Authorization(AccessDenied, "Access denied"))
+	assert.Equal(t, int32(403), res.Status)
+	assert.Equal(t, "Access denied", res.Message)
+	assert.Equal(t, int32(codes.PermissionDenied), res.Code)
+
+	err := FromResponse(res)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, AccessDenied, err.Code)
+		assert.Equal(t, cb.ErrorCategory_AUTHORIZATION, err.Category)
+	}
+
+	assert.Nil(t, FromResponse(nil))
+	assert.Nil(t, FromResponse(&pb.Response{Status: 500, Message: "chaincode error"}), "A chaincode error response conveys no Error")
+}
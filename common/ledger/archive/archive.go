@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package archive implements a portable archive format for a range of blocks
+// of a chain. An archive is a tar stream holding one file per block under
+// blocks/, the config blocks governing the range under config/ and a
+// manifest.json describing the content together with the SHA256 digest of
+// every file. The archive is meant for audit purposes: its content can be
+// verified without access to the network the blocks originate from.
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+const (
+	// ManifestPath is the path of the manifest within the archive
+	ManifestPath = "manifest.json"
+
+	// FormatVersion is the version of the archive format written by Writer
+	FormatVersion = 1
+
+	blocksDir = "blocks"
+	configDir = "config"
+)
+
+// Entry describes a block stored in the archive
+type Entry struct {
+	// Number is the number of the block
+	Number uint64 `json:"number"`
+
+	// Path is the location of the marshaled block within the archive
+	Path string `json:"path"`
+
+	// SHA256 is the hex encoded digest of the file at Path
+	SHA256 string `json:"sha256"`
+
+	// HeaderHash is the hex encoded hash of the block header
+	HeaderHash string `json:"header_hash"`
+
+	// Config is set if the block carries a config transaction
+	Config bool `json:"config,omitempty"`
+}
+
+// Manifest is the index of an archive
+type Manifest struct {
+	Version int    `json:"version"`
+	ChainID string `json:"chain_id"`
+	From    uint64 `json:"from"`
+	To      uint64 `json:"to"`
+
+	// Blocks lists the exported blocks in order, From through To
+	Blocks []*Entry `json:"blocks"`
+
+	// ConfigBlocks lists the config blocks preceding From which are needed
+	// to interpret the exported blocks
+	ConfigBlocks []*Entry `json:"config_blocks,omitempty"`
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func blockPath(dir string, number uint64) string {
+	return fmt.Sprintf("%s/%020d.block", dir, number)
+}
+
+// IsConfigBlock returns true if block contains a config transaction
+func IsConfigBlock(block *cb.Block) bool {
+	if block.Data == nil || len(block.Data.Data) != 1 {
+		return false
+	}
+	env, err := utils.UnmarshalEnvelope(block.Data.Data[0])
+	if err != nil {
+		return false
+	}
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil || payload.Header == nil || payload.Header.ChannelHeader == nil {
+		return false
+	}
+	return payload.Header.ChannelHeader.Type == int32(cb.HeaderType_CONFIG)
+}
+
+func newEntry(dir string, block *cb.Block) (*Entry, []byte, error) {
+	if block == nil || block.Header == nil {
+		return nil, nil, fmt.Errorf("Block must have a header")
+	}
+	data, err := proto.Marshal(block)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error marshaling block %d: %s", block.Header.Number, err)
+	}
+	return &Entry{
+		Number:     block.Header.Number,
+		Path:       blockPath(dir, block.Header.Number),
+		SHA256:     digest(data),
+		HeaderHash: hex.EncodeToString(block.Header.Hash()),
+		Config:     IsConfigBlock(block),
+	}, data, nil
+}
+
+// LastConfigIndex returns the number of the config block in effect for block
+// as recorded by the orderer in the block metadata. The second return value
+// is false if block carries no such record
+func LastConfigIndex(block *cb.Block) (uint64, bool) {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_LAST_CONFIG) {
+		return 0, false
+	}
+	raw := block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG]
+	if len(raw) == 0 {
+		return 0, false
+	}
+	md := &cb.Metadata{}
+	if err := proto.Unmarshal(raw, md); err != nil {
+		return 0, false
+	}
+	lc := &cb.LastConfig{}
+	if err := proto.Unmarshal(md.Value, lc); err != nil {
+		return 0, false
+	}
+	return lc.Index, true
+}
@@ -0,0 +1,177 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+func makeConfigBlock(number uint64) *cb.Block {
+	env := &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: &cb.ChannelHeader{Type: int32(cb.HeaderType_CONFIG), ChannelId: "testchain"}},
+		Data:   utils.MarshalOrPanic(&cb.ConfigEnvelope{}),
+	})}
+	block := cb.NewBlock(number, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(env)}
+	block.Header.DataHash = block.Data.Hash()
+	return block
+}
+
+// setLastConfig records index as the last config of block the way the
+// orderer does, including a (dummy) signature
+func setLastConfig(block *cb.Block, index uint64) {
+	block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&cb.Metadata{
+		Value:      utils.MarshalOrPanic(&cb.LastConfig{Index: index}),
+		Signatures: []*cb.MetadataSignature{{Signature: []byte("signature")}},
+	})
+}
+
+func writeArchive(t *testing.T, configBlock *cb.Block, blocks []*cb.Block) []byte {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, "testchain")
+	if configBlock != nil {
+		if err := w.AddConfigBlock(configBlock); err != nil {
+			t.Fatalf("Error adding config block: %s", err)
+		}
+	}
+	for _, block := range blocks {
+		if err := w.AddBlock(block); err != nil {
+			t.Fatalf("Error adding block %d: %s", block.Header.Number, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Error closing archive: %s", err)
+	}
+	return buf.Bytes()
+}
+
+// rewrite copies the archive in data, passing the content of every file
+// through modify
+func rewrite(t *testing.T, data []byte, modify func(name string, content []byte) []byte) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Error reading archive: %s", err)
+		}
+		content, _ := ioutil.ReadAll(tr)
+		content = modify(hdr.Name, content)
+		hdr.Size = int64(len(content))
+		tw.WriteHeader(hdr)
+		tw.Write(content)
+	}
+	tw.Close()
+	return buf.Bytes()
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	configBlock := makeConfigBlock(0)
+	blocks := testutil.ConstructTestBlocks(t, 3)
+	for _, block := range blocks {
+		setLastConfig(block, 0)
+	}
+
+	a, err := Open(bytes.NewReader(writeArchive(t, configBlock, blocks)))
+	if err != nil {
+		t.Fatalf("Error opening archive: %s", err)
+	}
+	if err = a.Verify(); err != nil {
+		t.Fatalf("Expected archive to verify, got %s", err)
+	}
+
+	m := a.Manifest()
+	if m.ChainID != "testchain" || m.From != 1 || m.To != 3 {
+		t.Fatalf("Unexpected manifest %+v", m)
+	}
+
+	read, err := a.Blocks()
+	if err != nil {
+		t.Fatalf("Error reading blocks: %s", err)
+	}
+	if len(read) != len(blocks) {
+		t.Fatalf("Expected %d blocks, got %d", len(blocks), len(read))
+	}
+	for i := range blocks {
+		if !bytes.Equal(read[i].Header.Hash(), blocks[i].Header.Hash()) {
+			t.Fatalf("Block %d differs from the exported block", i)
+		}
+	}
+
+	configBlocks, err := a.ConfigBlocks()
+	if err != nil || len(configBlocks) != 1 || configBlocks[0].Header.Number != 0 {
+		t.Fatalf("Expected config block 0, got %v (err %v)", configBlocks, err)
+	}
+}
+
+func TestArchiveWriterRejectsGap(t *testing.T) {
+	blocks := testutil.ConstructTestBlocks(t, 3)
+	w := NewWriter(&bytes.Buffer{}, "testchain")
+	if err := w.AddBlock(blocks[0]); err != nil {
+		t.Fatalf("Error adding block: %s", err)
+	}
+	if err := w.AddBlock(blocks[2]); err == nil {
+		t.Fatalf("Expected a gap in the exported range to be rejected")
+	}
+	if err := w.AddConfigBlock(blocks[1]); err == nil {
+		t.Fatalf("Expected a block without config transaction to be rejected as config block")
+	}
+}
+
+func TestArchiveVerifyTampered(t *testing.T) {
+	configBlock := makeConfigBlock(0)
+	blocks := testutil.ConstructTestBlocks(t, 3)
+	for _, block := range blocks {
+		setLastConfig(block, 0)
+	}
+	data := writeArchive(t, configBlock, blocks)
+
+	tampered := rewrite(t, data, func(name string, content []byte) []byte {
+		if name == blockPath(blocksDir, 2) {
+			content[len(content)-1] ^= 0xff
+		}
+		return content
+	})
+	a, err := Open(bytes.NewReader(tampered))
+	if err != nil {
+		t.Fatalf("Error opening archive: %s", err)
+	}
+	if err = a.Verify(); err == nil {
+		t.Fatalf("Expected verification of tampered archive to fail")
+	}
+
+	// Without the config block the references from the blocks dangle
+	a, err = Open(bytes.NewReader(writeArchive(t, nil, blocks)))
+	if err != nil {
+		t.Fatalf("Error opening archive: %s", err)
+	}
+	if err = a.Verify(); err == nil {
+		t.Fatalf("Expected verification to fail with missing config block")
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// Archive is an archive loaded in memory
+type Archive struct {
+	manifest *Manifest
+	files    map[string][]byte
+}
+
+// Open reads the whole archive from r. The content is not verified; call
+// Verify before trusting any of the blocks
+func Open(r io.Reader) (*Archive, error) {
+	a := &Archive{files: make(map[string][]byte)}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error reading archive: %s", err)
+		}
+		if _, exists := a.files[hdr.Name]; exists {
+			return nil, fmt.Errorf("Duplicate file %s in archive", hdr.Name)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading %s: %s", hdr.Name, err)
+		}
+		a.files[hdr.Name] = data
+	}
+
+	data, ok := a.files[ManifestPath]
+	if !ok {
+		return nil, fmt.Errorf("Archive has no manifest")
+	}
+	a.manifest = &Manifest{}
+	if err := json.Unmarshal(data, a.manifest); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling manifest: %s", err)
+	}
+	if a.manifest.Version != FormatVersion {
+		return nil, fmt.Errorf("Unsupported archive version %d", a.manifest.Version)
+	}
+
+	return a, nil
+}
+
+// Manifest returns the manifest of the archive
+func (a *Archive) Manifest() *Manifest {
+	return a.manifest
+}
+
+// block returns the block described by entry after checking it against the
+// digests recorded in the manifest
+func (a *Archive) block(entry *Entry) (*cb.Block, error) {
+	data, ok := a.files[entry.Path]
+	if !ok {
+		return nil, fmt.Errorf("Block %d missing from archive at %s", entry.Number, entry.Path)
+	}
+	if digest(data) != entry.SHA256 {
+		return nil, fmt.Errorf("Digest mismatch for block %d", entry.Number)
+	}
+
+	block, err := utils.GetBlockFromBlockBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling block %d: %s", entry.Number, err)
+	}
+	if block.Header == nil || block.Header.Number != entry.Number {
+		return nil, fmt.Errorf("File %s does not hold block %d", entry.Path, entry.Number)
+	}
+	if hex.EncodeToString(block.Header.Hash()) != entry.HeaderHash {
+		return nil, fmt.Errorf("Header hash mismatch for block %d", entry.Number)
+	}
+	if block.Data == nil || !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return nil, fmt.Errorf("Data hash mismatch for block %d", entry.Number)
+	}
+	if IsConfigBlock(block) != entry.Config {
+		return nil, fmt.Errorf("Config flag mismatch for block %d", entry.Number)
+	}
+	return block, nil
+}
+
+// Verify checks the integrity of the archive: every file listed in the
+// manifest must match its digest, the exported blocks must form an unbroken
+// hash chain from From to To and the config block referenced by each
+// exported block must be contained in the archive
+func (a *Archive) Verify() error {
+	_, _, err := a.load()
+	return err
+}
+
+// Blocks returns the exported blocks in order, after verifying the archive
+func (a *Archive) Blocks() ([]*cb.Block, error) {
+	blocks, _, err := a.load()
+	return blocks, err
+}
+
+// ConfigBlocks returns the config blocks preceding the exported range, after
+// verifying the archive
+func (a *Archive) ConfigBlocks() ([]*cb.Block, error) {
+	_, configBlocks, err := a.load()
+	return configBlocks, err
+}
+
+func (a *Archive) load() ([]*cb.Block, []*cb.Block, error) {
+	m := a.manifest
+	if len(m.Blocks) == 0 {
+		return nil, nil, fmt.Errorf("Archive contains no blocks")
+	}
+	if m.To < m.From || uint64(len(m.Blocks)) != m.To-m.From+1 {
+		return nil, nil, fmt.Errorf("Manifest lists %d blocks for range [%d, %d]", len(m.Blocks), m.From, m.To)
+	}
+
+	// Every file except the manifest must be accounted for
+	listed := map[string]bool{ManifestPath: true}
+	for _, entry := range append(append([]*Entry{}, m.Blocks...), m.ConfigBlocks...) {
+		listed[entry.Path] = true
+	}
+	for path := range a.files {
+		if !listed[path] {
+			return nil, nil, fmt.Errorf("File %s is not listed in the manifest", path)
+		}
+	}
+
+	configNumbers := make(map[uint64]bool)
+	var configBlocks []*cb.Block
+	for _, entry := range m.ConfigBlocks {
+		if entry.Number >= m.From {
+			return nil, nil, fmt.Errorf("Config block %d does not precede the exported range", entry.Number)
+		}
+		block, err := a.block(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !entry.Config {
+			return nil, nil, fmt.Errorf("Block %d is not a config block", entry.Number)
+		}
+		configNumbers[entry.Number] = true
+		configBlocks = append(configBlocks, block)
+	}
+
+	var blocks []*cb.Block
+	for i, entry := range m.Blocks {
+		if entry.Number != m.From+uint64(i) {
+			return nil, nil, fmt.Errorf("Expected block %d, manifest lists block %d", m.From+uint64(i), entry.Number)
+		}
+		block, err := a.block(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+		if i > 0 && !bytes.Equal(block.Header.PreviousHash, blocks[i-1].Header.Hash()) {
+			return nil, nil, fmt.Errorf("Block %d does not chain to block %d", entry.Number, entry.Number-1)
+		}
+		if entry.Config {
+			configNumbers[entry.Number] = true
+		}
+		// Blocks without last config metadata (e.g. genesis blocks) are
+		// accepted as is
+		if lastConfig, ok := LastConfigIndex(block); ok && !configNumbers[lastConfig] {
+			return nil, nil, fmt.Errorf("Config block %d referenced by block %d is missing from archive", lastConfig, entry.Number)
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, configBlocks, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// Writer writes an archive to an underlying io.Writer. Blocks must be added
+// in order with no gaps; the manifest is written by Close
+type Writer struct {
+	tw       *tar.Writer
+	manifest *Manifest
+	closed   bool
+}
+
+// NewWriter returns a Writer for the blocks of chainID
+func NewWriter(w io.Writer, chainID string) *Writer {
+	return &Writer{
+		tw:       tar.NewWriter(w),
+		manifest: &Manifest{Version: FormatVersion, ChainID: chainID},
+	}
+}
+
+func (w *Writer) writeFile(path string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    path,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("Error writing header for %s: %s", path, err)
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return fmt.Errorf("Error writing %s: %s", path, err)
+	}
+	return nil
+}
+
+// AddBlock adds the next block of the exported range. The first block added
+// determines the start of the range
+func (w *Writer) AddBlock(block *cb.Block) error {
+	if w.closed {
+		return fmt.Errorf("Archive already closed")
+	}
+	entry, data, err := newEntry(blocksDir, block)
+	if err != nil {
+		return err
+	}
+
+	if n := len(w.manifest.Blocks); n == 0 {
+		w.manifest.From = entry.Number
+	} else if expected := w.manifest.Blocks[n-1].Number + 1; entry.Number != expected {
+		return fmt.Errorf("Expected block %d, got block %d", expected, entry.Number)
+	}
+
+	if err = w.writeFile(entry.Path, data); err != nil {
+		return err
+	}
+	w.manifest.To = entry.Number
+	w.manifest.Blocks = append(w.manifest.Blocks, entry)
+	return nil
+}
+
+// AddConfigBlock adds a config block preceding the exported range, such as
+// the config block in effect for the first exported block
+func (w *Writer) AddConfigBlock(block *cb.Block) error {
+	if w.closed {
+		return fmt.Errorf("Archive already closed")
+	}
+	entry, data, err := newEntry(configDir, block)
+	if err != nil {
+		return err
+	}
+	if !entry.Config {
+		return fmt.Errorf("Block %d is not a config block", entry.Number)
+	}
+	for _, existing := range w.manifest.ConfigBlocks {
+		if existing.Number == entry.Number {
+			return nil
+		}
+	}
+
+	if err = w.writeFile(entry.Path, data); err != nil {
+		return err
+	}
+	w.manifest.ConfigBlocks = append(w.manifest.ConfigBlocks, entry)
+	return nil
+}
+
+// Manifest returns the manifest describing the blocks added so far
+func (w *Writer) Manifest() *Manifest {
+	return w.manifest
+}
+
+// Close writes the manifest and flushes the archive. It does not close the
+// underlying io.Writer
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if len(w.manifest.Blocks) == 0 {
+		return fmt.Errorf("Archive contains no blocks")
+	}
+	w.closed = true
+
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error marshaling manifest: %s", err)
+	}
+	if err = w.writeFile(ManifestPath, data); err != nil {
+		return err
+	}
+	return w.tw.Close()
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/protos/common"
+	"golang.org/x/net/context"
 )
 
 // IndexableAttr represents an indexable attribute
@@ -33,6 +34,7 @@ const (
 	IndexableAttrTxID            = IndexableAttr("TxID")
 	IndexableAttrBlockNumTranNum = IndexableAttr("BlockNumTranNum")
 	IndexableAttrBlockTxID       = IndexableAttr("BlockTxID")
+	IndexableAttrConfigSeq       = IndexableAttr("ConfigSeq")
 )
 
 // IndexConfig - a configuration that includes a list of attributes that should be indexed
@@ -62,11 +64,16 @@ type BlockStoreProvider interface {
 type BlockStore interface {
 	AddBlock(block *common.Block) error
 	GetBlockchainInfo() (*common.BlockchainInfo, error)
-	RetrieveBlocks(startNum uint64) (ledger.ResultsIterator, error)
+	// RetrieveBlocks returns a blocking iterator from startNum, its Next returns the error of ctx
+	// once ctx is done while it waits for the next block
+	RetrieveBlocks(ctx context.Context, startNum uint64) (ledger.ResultsIterator, error)
 	RetrieveBlockByHash(blockHash []byte) (*common.Block, error)
 	RetrieveBlockByNumber(blockNum uint64) (*common.Block, error) // blockNum of  math.MaxUint64 will return last block
 	RetrieveTxByID(txID string) (*common.Envelope, error)
 	RetrieveTxByBlockNumTranNum(blockNum uint64, tranNum uint64) (*common.Envelope, error)
 	RetrieveBlockByTxID(txID string) (*common.Block, error)
+	// RetrieveConfigBlocks returns the sequence and block numbers of the config
+	// blocks of the store, in the order of their sequence numbers
+	RetrieveConfigBlocks() ([]*common.ConfigBlockInfo, error)
 	Shutdown()
 }
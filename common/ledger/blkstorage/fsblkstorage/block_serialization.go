@@ -26,8 +26,10 @@ import (
 )
 
 type serializedBlockInfo struct {
-	blockHeader *common.BlockHeader
-	txOffsets   []*txindexInfo
+	blockHeader   *common.BlockHeader
+	txOffsets     []*txindexInfo
+	configSeq     uint64
+	isConfigBlock bool
 }
 
 //The order of the transactions must be maintained for history
@@ -47,6 +49,7 @@ func serializeBlock(block *common.Block) ([]byte, *serializedBlockInfo, error) {
 	if info.txOffsets, err = addDataBytes(block.Data, buf); err != nil {
 		return nil, nil, err
 	}
+	info.configSeq, info.isConfigBlock = utils.GetConfigSequenceFromBlock(block)
 	if err = addMetadataBytes(block.Metadata, buf); err != nil {
 		return nil, nil, err
 	}
@@ -80,10 +83,12 @@ func extractSerializedBlockInfo(serializedBlockBytes []byte) (*serializedBlockIn
 	if err != nil {
 		return nil, err
 	}
-	_, info.txOffsets, err = extractData(b)
+	data, txOffsets, err := extractData(b)
 	if err != nil {
 		return nil, err
 	}
+	info.txOffsets = txOffsets
+	info.configSeq, info.isConfigBlock = utils.GetConfigSequenceFromBlock(&common.Block{Header: info.blockHeader, Data: data})
 	return info, nil
 }
 
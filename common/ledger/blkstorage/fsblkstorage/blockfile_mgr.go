@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger/fabric/protos/common"
 	putil "github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
+	"golang.org/x/net/context"
 )
 
 var logger = logging.MustGetLogger("kvledger")
@@ -151,6 +152,9 @@ func newBlockfileMgr(id string, conf *Conf, indexConfig *blkstorage.IndexConfig,
 	// Verify that the index stored in db is accurate with what is actually stored in block file system
 	// If not the same, sync the index and the file system
 	mgr.syncIndex()
+	if err = mgr.syncConfigSeqIndex(); err != nil {
+		panic(fmt.Sprintf("Could not build the config sequence index: %s", err))
+	}
 
 	// init BlockchainInfo for external API's
 	bcInfo := &common.BlockchainInfo{
@@ -302,7 +306,8 @@ func (mgr *blockfileMgr) addBlock(block *common.Block) error {
 	//save the index in the database
 	mgr.index.indexBlock(&blockIdxInfo{
 		blockNum: block.Header.Number, blockHash: blockHash,
-		flp: blockFLP, txOffsets: txOffsets})
+		flp: blockFLP, txOffsets: txOffsets,
+		configSeq: info.configSeq, isConfigBlock: info.isConfigBlock})
 
 	//update the checkpoint info (for storage) and the blockchain info (for APIs) in the manager
 	mgr.updateCheckpoint(newCPInfo)
@@ -310,6 +315,41 @@ func (mgr *blockfileMgr) addBlock(block *common.Block) error {
 	return nil
 }
 
+// syncConfigSeqIndex indexes by config sequence the config blocks stored before
+// the config sequence index existed, reading all the blocks once. The blocks
+// added since are indexed along with the other attributes
+func (mgr *blockfileMgr) syncConfigSeqIndex() error {
+	built, err := mgr.index.isConfigSeqIndexBuilt()
+	if err != nil || built {
+		return err
+	}
+	logger.Infof("Building the config sequence index of the blocks stored in %s", mgr.rootDir)
+
+	stream, err := newBlockStream(mgr.rootDir, 0, 0, mgr.cpInfo.latestFileChunkSuffixNum)
+	if err != nil {
+		return err
+	}
+	defer stream.close()
+	var configBlocks []*blockIdxInfo
+	for {
+		blockBytes, _, err := stream.nextBlockBytesAndPlacementInfo()
+		if err != nil {
+			return err
+		}
+		if blockBytes == nil {
+			break
+		}
+		info, err := extractSerializedBlockInfo(blockBytes)
+		if err != nil {
+			return err
+		}
+		if info.isConfigBlock {
+			configBlocks = append(configBlocks, &blockIdxInfo{blockNum: info.blockHeader.Number, configSeq: info.configSeq, isConfigBlock: true})
+		}
+	}
+	return mgr.index.buildConfigSeqIndex(configBlocks)
+}
+
 func (mgr *blockfileMgr) syncIndex() error {
 	var lastBlockIndexed uint64
 	var err error
@@ -383,6 +423,8 @@ func (mgr *blockfileMgr) syncIndex() error {
 		blockIdxInfo.flp = &fileLocPointer{fileSuffixNum: blockPlacementInfo.fileNum,
 			locPointer: locPointer{offset: int(blockPlacementInfo.blockStartOffset)}}
 		blockIdxInfo.txOffsets = info.txOffsets
+		blockIdxInfo.configSeq = info.configSeq
+		blockIdxInfo.isConfigBlock = info.isConfigBlock
 		logger.Debugf("syncIndex() indexing block [%d]", blockIdxInfo.blockNum)
 		if err = mgr.index.indexBlock(blockIdxInfo); err != nil {
 			return err
@@ -428,7 +470,7 @@ func (mgr *blockfileMgr) retrieveBlockByNumber(blockNum uint64) (*common.Block,
 
 	// interpret math.MaxUint64 as a request for last block
 	if blockNum == math.MaxUint64 {
-		blockNum = mgr.getBlockchainInfo().Height
+		blockNum = mgr.cpInfo.lastBlockNumber
 	}
 
 	loc, err := mgr.index.getBlockLocByBlockNum(blockNum)
@@ -449,6 +491,11 @@ func (mgr *blockfileMgr) retrieveBlockByTxID(txID string) (*common.Block, error)
 	return mgr.fetchBlock(loc)
 }
 
+func (mgr *blockfileMgr) retrieveConfigBlocks() ([]*common.ConfigBlockInfo, error) {
+	logger.Debug("retrieveConfigBlocks()")
+	return mgr.index.getConfigBlocks()
+}
+
 func (mgr *blockfileMgr) retrieveBlockHeaderByNumber(blockNum uint64) (*common.BlockHeader, error) {
 	logger.Debugf("retrieveBlockHeaderByNumber() - blockNum = [%d]", blockNum)
 	loc, err := mgr.index.getBlockLocByBlockNum(blockNum)
@@ -466,8 +513,8 @@ func (mgr *blockfileMgr) retrieveBlockHeaderByNumber(blockNum uint64) (*common.B
 	return info.blockHeader, nil
 }
 
-func (mgr *blockfileMgr) retrieveBlocks(startNum uint64) (*blocksItr, error) {
-	return newBlockItr(mgr, startNum), nil
+func (mgr *blockfileMgr) retrieveBlocks(ctx context.Context, startNum uint64) (*blocksItr, error) {
+	return newBlockItr(ctx, mgr, startNum), nil
 }
 
 func (mgr *blockfileMgr) retrieveTransactionByID(txID string) (*common.Envelope, error) {
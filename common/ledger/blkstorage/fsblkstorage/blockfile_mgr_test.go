@@ -25,6 +25,7 @@ import (
 
 	"github.com/hyperledger/fabric/protos/common"
 	putil "github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
 )
 
 func TestBlockfileMgrBlockReadWrite(t *testing.T) {
@@ -103,7 +104,7 @@ func TestBlockfileMgrBlockIterator(t *testing.T) {
 
 func testBlockfileMgrBlockIterator(t *testing.T, blockfileMgr *blockfileMgr,
 	firstBlockNum int, lastBlockNum int, expectedBlocks []*common.Block) {
-	itr, err := blockfileMgr.retrieveBlocks(uint64(firstBlockNum))
+	itr, err := blockfileMgr.retrieveBlocks(context.Background(), uint64(firstBlockNum))
 	defer itr.Close()
 	testutil.AssertNoError(t, err, "Error while getting blocks iterator")
 	numBlocksItrated := 0
@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/protos/common"
 )
 
 const (
@@ -32,11 +33,16 @@ const (
 	txIDIdxKeyPrefix            = 't'
 	blockNumTranNumIdxKeyPrefix = 'a'
 	blockTxIDIdxKeyPrefix       = 'b'
+	configSeqIdxKeyPrefix       = 'c'
 	indexCheckpointKeyStr       = "indexCheckpointKey"
 )
 
 var indexCheckpointKey = []byte(indexCheckpointKeyStr)
 
+// configSeqIndexBuiltKey marks the config sequence index as holding the config
+// blocks stored before it existed, which the ledgers created earlier lack
+var configSeqIndexBuiltKey = []byte("indexConfigSeqBuiltKey")
+
 type index interface {
 	getLastBlockIndexed() (uint64, error)
 	indexBlock(blockIdxInfo *blockIdxInfo) error
@@ -45,13 +51,18 @@ type index interface {
 	getTxLoc(txID string) (*fileLocPointer, error)
 	getTXLocByBlockNumTranNum(blockNum uint64, tranNum uint64) (*fileLocPointer, error)
 	getBlockLocByTxID(txID string) (*fileLocPointer, error)
+	getConfigBlocks() ([]*common.ConfigBlockInfo, error)
+	isConfigSeqIndexBuilt() (bool, error)
+	buildConfigSeqIndex(configBlocks []*blockIdxInfo) error
 }
 
 type blockIdxInfo struct {
-	blockNum  uint64
-	blockHash []byte
-	flp       *fileLocPointer
-	txOffsets []*txindexInfo
+	blockNum      uint64
+	blockHash     []byte
+	flp           *fileLocPointer
+	txOffsets     []*txindexInfo
+	configSeq     uint64
+	isConfigBlock bool
 }
 
 type blockIndex struct {
@@ -136,6 +147,11 @@ func (index *blockIndex) indexBlock(blockIdxInfo *blockIdxInfo) error {
 		}
 	}
 
+	// Index6 - Store the number of the config blocks by config sequence number
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrConfigSeq]; ok && blockIdxInfo.isConfigBlock {
+		batch.Put(constructConfigSeqKey(blockIdxInfo.configSeq), encodeBlockNum(blockIdxInfo.blockNum))
+	}
+
 	batch.Put(indexCheckpointKey, encodeBlockNum(blockIdxInfo.blockNum))
 	if err := index.db.WriteBatch(batch, false); err != nil {
 		return err
@@ -223,6 +239,47 @@ func (index *blockIndex) getTXLocByBlockNumTranNum(blockNum uint64, tranNum uint
 	return txFLP, nil
 }
 
+func (index *blockIndex) getConfigBlocks() ([]*common.ConfigBlockInfo, error) {
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrConfigSeq]; !ok {
+		return nil, blkstorage.ErrAttrNotIndexed
+	}
+	itr := index.db.GetIterator([]byte{configSeqIdxKeyPrefix}, []byte{configSeqIdxKeyPrefix + 1})
+	defer itr.Release()
+	var configBlocks []*common.ConfigBlockInfo
+	for itr.Next() {
+		configSeq, _ := util.DecodeOrderPreservingVarUint64(itr.Key()[1:])
+		configBlocks = append(configBlocks, &common.ConfigBlockInfo{Sequence: configSeq, BlockNumber: decodeBlockNum(itr.Value())})
+	}
+	if err := itr.Error(); err != nil {
+		return nil, err
+	}
+	return configBlocks, nil
+}
+
+// isConfigSeqIndexBuilt returns whether the config sequence index holds the
+// config blocks stored before it existed, true if it is not maintained
+func (index *blockIndex) isConfigSeqIndexBuilt() (bool, error) {
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrConfigSeq]; !ok {
+		return true, nil
+	}
+	built, err := index.db.Get(configSeqIndexBuiltKey)
+	if err != nil {
+		return false, err
+	}
+	return built != nil, nil
+}
+
+// buildConfigSeqIndex indexes the config blocks stored, and marks the config
+// sequence index as built
+func (index *blockIndex) buildConfigSeqIndex(configBlocks []*blockIdxInfo) error {
+	batch := leveldbhelper.NewUpdateBatch()
+	for _, configBlock := range configBlocks {
+		batch.Put(constructConfigSeqKey(configBlock.configSeq), encodeBlockNum(configBlock.blockNum))
+	}
+	batch.Put(configSeqIndexBuiltKey, []byte{1})
+	return index.db.WriteBatch(batch, true)
+}
+
 func constructBlockNumKey(blockNum uint64) []byte {
 	blkNumBytes := util.EncodeOrderPreservingVarUint64(blockNum)
 	return append([]byte{blockNumIdxKeyPrefix}, blkNumBytes...)
@@ -240,6 +297,10 @@ func constructBlockTxIDKey(txID string) []byte {
 	return append([]byte{blockTxIDIdxKeyPrefix}, []byte(txID)...)
 }
 
+func constructConfigSeqKey(configSeq uint64) []byte {
+	return append([]byte{configSeqIdxKeyPrefix}, util.EncodeOrderPreservingVarUint64(configSeq)...)
+}
+
 func constructBlockNumTranNumKey(blockNum uint64, txNum uint64) []byte {
 	blkNumBytes := util.EncodeOrderPreservingVarUint64(blockNum)
 	tranNumBytes := util.EncodeOrderPreservingVarUint64(txNum)
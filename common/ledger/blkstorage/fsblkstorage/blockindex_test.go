@@ -22,6 +22,7 @@ import (
 
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/protos/common"
 	putil "github.com/hyperledger/fabric/protos/utils"
 )
 
@@ -51,6 +52,18 @@ func (i *noopIndex) getBlockLocByTxID(txID string) (*fileLocPointer, error) {
 	return nil, nil
 }
 
+func (i *noopIndex) getConfigBlocks() ([]*common.ConfigBlockInfo, error) {
+	return nil, nil
+}
+
+func (i *noopIndex) isConfigSeqIndexBuilt() (bool, error) {
+	return true, nil
+}
+
+func (i *noopIndex) buildConfigSeqIndex(configBlocks []*blockIdxInfo) error {
+	return nil
+}
+
 func TestBlockIndexSync(t *testing.T) {
 	testBlockIndexSync(t, 10, 5, false)
 	testBlockIndexSync(t, 10, 5, true)
@@ -184,4 +197,82 @@ func testBlockIndexSelectiveIndexing(t *testing.T, indexItems []blkstorage.Index
 	} else {
 		testutil.AssertSame(t, err, blkstorage.ErrAttrNotIndexed)
 	}
+
+	// test 'retrieveConfigBlocks'
+	_, err = blockfileMgr.retrieveConfigBlocks()
+	if testutil.Contains(indexItems, blkstorage.IndexableAttrConfigSeq) {
+		testutil.AssertNoError(t, err, "Error while retrieving config blocks")
+	} else {
+		testutil.AssertSame(t, err, blkstorage.ErrAttrNotIndexed)
+	}
+}
+
+func TestBlockIndexConfigBlocks(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath, 0))
+	defer env.Cleanup()
+	ledgerid := "testledger"
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, ledgerid)
+	defer blkfileMgrWrapper.close()
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+
+	bg := testutil.NewBlockGenerator(t)
+	blkfileMgrWrapper.addBlocks([]*common.Block{bg.NextConfigBlock(0)})
+	blkfileMgrWrapper.addBlocks(bg.NextTestBlocks(2))
+	blkfileMgrWrapper.addBlocks([]*common.Block{bg.NextConfigBlock(1)})
+	blkfileMgrWrapper.addBlocks(bg.NextTestBlocks(1))
+
+	// the last config block is only found by the index sync on restart
+	origIndex := blkfileMgr.index
+	blkfileMgr.index = &noopIndex{}
+	blkfileMgrWrapper.addBlocks([]*common.Block{bg.NextConfigBlock(2)})
+	blkfileMgr.index = origIndex
+
+	configBlocks, err := blkfileMgr.retrieveConfigBlocks()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, configBlocks, []*common.ConfigBlockInfo{
+		{Sequence: 0, BlockNumber: 1},
+		{Sequence: 1, BlockNumber: 4},
+	})
+
+	blkfileMgrWrapper.close()
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, ledgerid)
+	defer blkfileMgrWrapper.close()
+	configBlocks, err = blkfileMgrWrapper.blockfileMgr.retrieveConfigBlocks()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, configBlocks, []*common.ConfigBlockInfo{
+		{Sequence: 0, BlockNumber: 1},
+		{Sequence: 1, BlockNumber: 4},
+		{Sequence: 2, BlockNumber: 6},
+	})
+}
+
+func TestBlockIndexBuildConfigSeqIndex(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath, 0))
+	defer env.Cleanup()
+	ledgerid := "testledger"
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, ledgerid)
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+
+	bg := testutil.NewBlockGenerator(t)
+	blkfileMgrWrapper.addBlocks([]*common.Block{bg.NextConfigBlock(0)})
+	blkfileMgrWrapper.addBlocks(bg.NextTestBlocks(2))
+	blkfileMgrWrapper.addBlocks([]*common.Block{bg.NextConfigBlock(1)})
+
+	// a ledger created before the config sequence index existed lacks it
+	testutil.AssertNoError(t, blkfileMgr.db.Delete(constructConfigSeqKey(0), true), "")
+	testutil.AssertNoError(t, blkfileMgr.db.Delete(constructConfigSeqKey(1), true), "")
+	testutil.AssertNoError(t, blkfileMgr.db.Delete(configSeqIndexBuiltKey, true), "")
+	blkfileMgrWrapper.close()
+
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, ledgerid)
+	defer blkfileMgrWrapper.close()
+	configBlocks, err := blkfileMgrWrapper.blockfileMgr.retrieveConfigBlocks()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, configBlocks, []*common.ConfigBlockInfo{
+		{Sequence: 0, BlockNumber: 1},
+		{Sequence: 1, BlockNumber: 4},
+	})
+	built, err := blkfileMgrWrapper.blockfileMgr.index.isConfigSeqIndexBuilt()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, built, true)
 }
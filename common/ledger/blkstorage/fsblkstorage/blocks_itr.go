@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger"
 
 	"github.com/hyperledger/fabric/protos/common"
+	"golang.org/x/net/context"
 )
 
 // blockHolder holds block bytes
@@ -52,16 +53,45 @@ type blocksItr struct {
 	stream               *blockStream
 	closeMarker          bool
 	closeMarkerLock      *sync.Mutex
+	// ctx cancels the wait for the next block, closed is closed along with the iterator
+	ctx    context.Context
+	closed chan struct{}
 }
 
-func newBlockItr(mgr *blockfileMgr, startBlockNum uint64) *blocksItr {
-	return &blocksItr{mgr, mgr.cpInfo.lastBlockNumber, startBlockNum, nil, false, &sync.Mutex{}}
+func newBlockItr(ctx context.Context, mgr *blockfileMgr, startBlockNum uint64) *blocksItr {
+	itr := &blocksItr{
+		mgr:                  mgr,
+		maxBlockNumAvailable: mgr.cpInfo.lastBlockNumber,
+		blockNumToRetrieve:   startBlockNum,
+		closeMarkerLock:      &sync.Mutex{},
+		ctx:                  ctx,
+		closed:               make(chan struct{}),
+	}
+	if ctx.Done() != nil {
+		go itr.wakeUpOnCancel()
+	}
+	return itr
+}
+
+// wakeUpOnCancel wakes up the iterator waiting for the next block once its context is done
+func (itr *blocksItr) wakeUpOnCancel() {
+	select {
+	case <-itr.ctx.Done():
+		itr.wakeUp()
+	case <-itr.closed:
+	}
+}
+
+func (itr *blocksItr) wakeUp() {
+	itr.mgr.cpInfoCond.L.Lock()
+	defer itr.mgr.cpInfoCond.L.Unlock()
+	itr.mgr.cpInfoCond.Broadcast()
 }
 
 func (itr *blocksItr) waitForBlock(blockNum uint64) uint64 {
 	itr.mgr.cpInfoCond.L.Lock()
 	defer itr.mgr.cpInfoCond.L.Unlock()
-	for itr.mgr.cpInfo.lastBlockNumber < blockNum && !itr.shouldClose() {
+	for itr.mgr.cpInfo.lastBlockNumber < blockNum && !itr.shouldClose() && itr.ctx.Err() == nil {
 		logger.Debugf("Going to wait for newer blocks. maxAvailaBlockNumber=[%d], waitForBlockNum=[%d]",
 			itr.mgr.cpInfo.lastBlockNumber, blockNum)
 		itr.mgr.cpInfoCond.Wait()
@@ -88,7 +118,8 @@ func (itr *blocksItr) shouldClose() bool {
 	return itr.closeMarker
 }
 
-// Next moves the cursor to next block and returns true iff the iterator is not exhausted
+// Next moves the cursor to next block and returns true iff the iterator is not exhausted.
+// It returns the error of the context of the iterator if it is done while waiting for the block
 func (itr *blocksItr) Next() (ledger.QueryResult, error) {
 	if itr.maxBlockNumAvailable < itr.blockNumToRetrieve {
 		itr.maxBlockNumAvailable = itr.waitForBlock(itr.blockNumToRetrieve)
@@ -98,6 +129,9 @@ func (itr *blocksItr) Next() (ledger.QueryResult, error) {
 	if itr.closeMarker {
 		return nil, nil
 	}
+	if itr.maxBlockNumAvailable < itr.blockNumToRetrieve {
+		return nil, itr.ctx.Err()
+	}
 	if itr.stream == nil {
 		if err := itr.initStream(); err != nil {
 			return nil, err
@@ -114,10 +148,16 @@ func (itr *blocksItr) Next() (ledger.QueryResult, error) {
 // Close releases any resources held by the iterator
 func (itr *blocksItr) Close() {
 	itr.closeMarkerLock.Lock()
-	defer itr.closeMarkerLock.Unlock()
+	if itr.closeMarker {
+		itr.closeMarkerLock.Unlock()
+		return
+	}
 	itr.closeMarker = true
-	itr.mgr.cpInfoCond.L.Lock()
-	defer itr.mgr.cpInfoCond.L.Unlock()
-	itr.mgr.cpInfoCond.Broadcast()
-	itr.stream.close()
+	close(itr.closed)
+	if itr.stream != nil {
+		itr.stream.close()
+	}
+	itr.closeMarkerLock.Unlock()
+	// the marker is released first as the waiting iterator checks it while holding the condition lock
+	itr.wakeUp()
 }
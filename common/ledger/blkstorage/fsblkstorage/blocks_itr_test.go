@@ -22,6 +22,7 @@ import (
 
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/protos/common"
+	"golang.org/x/net/context"
 )
 
 func TestBlocksItrBlockingNext(t *testing.T) {
@@ -34,7 +35,7 @@ func TestBlocksItrBlockingNext(t *testing.T) {
 	blocks := testutil.ConstructTestBlocks(t, 10)
 	blkfileMgrWrapper.addBlocks(blocks[:5])
 
-	itr, err := blkfileMgr.retrieveBlocks(2)
+	itr, err := blkfileMgr.retrieveBlocks(context.Background(), 2)
 	defer itr.Close()
 	testutil.AssertNoError(t, err, "")
 	doneChan := make(chan bool)
@@ -52,6 +53,37 @@ func TestBlocksItrBlockingNext(t *testing.T) {
 	<-doneChan
 }
 
+func TestBlocksItrCancelledNext(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath, 0))
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+
+	blocks := testutil.ConstructTestBlocks(t, 2)
+	blkfileMgrWrapper.addBlocks(blocks)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	itr, err := blkfileMgr.retrieveBlocks(ctx, 3)
+	testutil.AssertNoError(t, err, "")
+	defer itr.Close()
+	errChan := make(chan error)
+	go func() {
+		_, err := itr.Next()
+		errChan <- err
+	}()
+
+	// Next waits for block 3, until the context is cancelled
+	time.Sleep(time.Millisecond * 10)
+	cancel()
+	select {
+	case err := <-errChan:
+		testutil.AssertEquals(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatalf("Next should have returned once the context was cancelled")
+	}
+}
+
 func testIterateAndVerify(t *testing.T, itr *blocksItr, blocks []*common.Block, doneChan chan bool) {
 	blocksIterated := 0
 	for {
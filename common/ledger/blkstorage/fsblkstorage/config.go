@@ -26,15 +26,25 @@ const (
 type Conf struct {
 	blockStorageDir  string
 	maxBlockfileSize int
+	ledgerPaths      map[string]string
 }
 
 // NewConf constructs new `Conf`.
 // blockStorageDir is the top level folder under which `FsBlockStore` manages its data
 func NewConf(blockStorageDir string, maxBlockfileSize int) *Conf {
+	return NewConfWithLedgerPaths(blockStorageDir, maxBlockfileSize, nil)
+}
+
+// NewConfWithLedgerPaths constructs new `Conf` storing the block files of
+// some ledgers under other paths than blockStorageDir, e.g. on other volumes.
+// ledgerPaths maps the ids of these ledgers to their paths, the block files
+// of a ledger being stored in a folder named after the ledger under its path.
+// The block index of all the ledgers remains under blockStorageDir
+func NewConfWithLedgerPaths(blockStorageDir string, maxBlockfileSize int, ledgerPaths map[string]string) *Conf {
 	if maxBlockfileSize <= 0 {
 		maxBlockfileSize = defaultMaxBlockfileSize
 	}
-	return &Conf{blockStorageDir, maxBlockfileSize}
+	return &Conf{blockStorageDir, maxBlockfileSize, ledgerPaths}
 }
 
 func (conf *Conf) getIndexDir() string {
@@ -46,5 +56,14 @@ func (conf *Conf) getBlocksDir() string {
 }
 
 func (conf *Conf) getLedgerBlockDir(ledgerid string) string {
+	if path, ok := conf.ledgerPaths[ledgerid]; ok {
+		return filepath.Join(path, ledgerid)
+	}
+	return conf.getDefaultLedgerBlockDir(ledgerid)
+}
+
+// getDefaultLedgerBlockDir returns the folder of the block files of a ledger
+// which is not mapped to a path
+func (conf *Conf) getDefaultLedgerBlockDir(ledgerid string) string {
 	return filepath.Join(conf.getBlocksDir(), ledgerid)
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 
 	"github.com/hyperledger/fabric/protos/common"
+	"golang.org/x/net/context"
 )
 
 // fsBlockStore - filesystem based implementation for `BlockStore`
@@ -47,11 +48,12 @@ func (store *fsBlockStore) GetBlockchainInfo() (*common.BlockchainInfo, error) {
 	return store.fileMgr.getBlockchainInfo(), nil
 }
 
-// RetrieveBlocks returns an iterator that can be used for iterating over a range of blocks
-func (store *fsBlockStore) RetrieveBlocks(startNum uint64) (ledger.ResultsIterator, error) {
+// RetrieveBlocks returns an iterator that can be used for iterating over a range of blocks,
+// until ctx is done
+func (store *fsBlockStore) RetrieveBlocks(ctx context.Context, startNum uint64) (ledger.ResultsIterator, error) {
 	var itr *blocksItr
 	var err error
-	if itr, err = store.fileMgr.retrieveBlocks(startNum); err != nil {
+	if itr, err = store.fileMgr.retrieveBlocks(ctx, startNum); err != nil {
 		return nil, err
 	}
 	return itr, nil
@@ -81,6 +83,11 @@ func (store *fsBlockStore) RetrieveBlockByTxID(txID string) (*common.Block, erro
 	return store.fileMgr.retrieveBlockByTxID(txID)
 }
 
+// RetrieveConfigBlocks returns the sequence and block numbers of the config blocks
+func (store *fsBlockStore) RetrieveConfigBlocks() ([]*common.ConfigBlockInfo, error) {
+	return store.fileMgr.retrieveConfigBlocks()
+}
+
 // Shutdown shuts down the block store
 func (store *fsBlockStore) Shutdown() {
 	logger.Debugf("closing fs blockStore:%s", store.id)
@@ -17,6 +17,8 @@ limitations under the License.
 package fsblkstorage
 
 import (
+	"fmt"
+
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
@@ -44,6 +46,9 @@ func (p *FsBlockstoreProvider) CreateBlockStore(ledgerid string) (blkstorage.Blo
 // If a blockstore is not existing, this method creates one
 // This method should be invoked only once for a particular ledgerid
 func (p *FsBlockstoreProvider) OpenBlockStore(ledgerid string) (blkstorage.BlockStore, error) {
+	if err := p.checkLedgerBlockDir(ledgerid); err != nil {
+		return nil, err
+	}
 	indexStoreHandle := p.leveldbProvider.GetDBHandle(ledgerid)
 	return newFsBlockStore(ledgerid, p.conf, p.indexConfig, indexStoreHandle), nil
 }
@@ -54,9 +59,52 @@ func (p *FsBlockstoreProvider) Exists(ledgerid string) (bool, error) {
 	return exists, err
 }
 
+// checkLedgerBlockDir returns an error if the block files of a ledger newly
+// mapped to a path are still in the default folder, as opening the ledger
+// would then start its block files over at the new path
+func (p *FsBlockstoreProvider) checkLedgerBlockDir(ledgerid string) error {
+	dir := p.conf.getLedgerBlockDir(ledgerid)
+	defaultDir := p.conf.getDefaultLedgerBlockDir(ledgerid)
+	if dir == defaultDir {
+		return nil
+	}
+	exists, _, err := util.FileExists(dir)
+	if err != nil || exists {
+		return err
+	}
+	exists, _, err = util.FileExists(defaultDir)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("The block files of ledger %s are in %s rather than in %s, they must be moved to its new path first", ledgerid, defaultDir, dir)
+	}
+	return nil
+}
+
 // List lists the ids of the existing ledgers
 func (p *FsBlockstoreProvider) List() ([]string, error) {
-	return util.ListSubdirs(p.conf.getBlocksDir())
+	ledgerids, err := util.ListSubdirs(p.conf.getBlocksDir())
+	if err != nil {
+		return nil, err
+	}
+	listed := map[string]bool{}
+	for _, ledgerid := range ledgerids {
+		listed[ledgerid] = true
+	}
+	for ledgerid := range p.conf.ledgerPaths {
+		if listed[ledgerid] {
+			continue
+		}
+		exists, err := p.Exists(ledgerid)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			ledgerids = append(ledgerids, ledgerid)
+		}
+	}
+	return ledgerids, nil
 }
 
 // Close closes the FsBlockstoreProvider
@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/protos/common"
+	"golang.org/x/net/context"
 )
 
 func TestMultipleBlockStores(t *testing.T) {
@@ -56,7 +57,7 @@ func checkBlocks(t *testing.T, expectedBlocks []*common.Block, store blkstorage.
 	testutil.AssertEquals(t, bcInfo.Height, uint64(len(expectedBlocks)))
 	testutil.AssertEquals(t, bcInfo.CurrentBlockHash, expectedBlocks[len(expectedBlocks)-1].GetHeader().Hash())
 
-	itr, _ := store.RetrieveBlocks(1)
+	itr, _ := store.RetrieveBlocks(context.Background(), 1)
 	for i := 0; i < len(expectedBlocks); i++ {
 		blockHolder, _ := itr.Next()
 		block := blockHolder.(ledger.BlockHolder).GetBlock()
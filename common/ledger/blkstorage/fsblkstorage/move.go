@@ -0,0 +1,125 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric/common/ledger/util"
+)
+
+// MoveLedgerBlocks moves the block files of a ledger from the folder conf
+// stores them in to the folder of the ledger under toPath, or to the default
+// folder if toPath is empty, so that the ledger can then be mapped to toPath.
+// The block index refers to the block files by number and offset only, and
+// remains valid. The ledger must not be open, i.e. the peer must be stopped.
+// MoveLedgerBlocks returns the folder the block files were moved to
+func MoveLedgerBlocks(conf *Conf, ledgerid string, toPath string) (string, error) {
+	from := conf.getLedgerBlockDir(ledgerid)
+	to := conf.getDefaultLedgerBlockDir(ledgerid)
+	if toPath != "" {
+		to = filepath.Join(toPath, ledgerid)
+	}
+	if filepath.Clean(from) == filepath.Clean(to) {
+		return "", fmt.Errorf("The block files of ledger %s are already in %s", ledgerid, to)
+	}
+
+	exists, _, err := util.FileExists(from)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("No block files of ledger %s in %s", ledgerid, from)
+	}
+	exists, _, err = util.FileExists(to)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		empty, err := util.DirEmpty(to)
+		if err != nil {
+			return "", err
+		}
+		if !empty {
+			return "", fmt.Errorf("Cannot move the block files of ledger %s to %s, which is not empty", ledgerid, to)
+		}
+	}
+
+	if _, err = util.CreateDirIfMissing(filepath.Dir(to)); err != nil {
+		return "", err
+	}
+	// a rename is enough within a volume
+	if err = os.Rename(from, to); err == nil {
+		logger.Infof("Moved the block files of ledger %s from %s to %s", ledgerid, from, to)
+		return to, nil
+	}
+
+	logger.Debugf("Could not rename %s to %s, copying the block files: %s", from, to, err)
+	if err = copyBlockfiles(from, to); err != nil {
+		os.RemoveAll(to)
+		return "", fmt.Errorf("Error copying the block files of ledger %s to %s: %s", ledgerid, to, err)
+	}
+	if err = os.RemoveAll(from); err != nil {
+		return "", fmt.Errorf("Copied the block files of ledger %s to %s but could not remove them from %s: %s", ledgerid, to, from, err)
+	}
+	logger.Infof("Moved the block files of ledger %s from %s to %s", ledgerid, from, to)
+	return to, nil
+}
+
+// copyBlockfiles copies the files of the folder from to the folder to,
+// syncing them to the disk
+func copyBlockfiles(from, to string) error {
+	if _, err := util.CreateDirIfMissing(to); err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(from)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		if err = copyBlockfile(filepath.Join(from, f.Name()), filepath.Join(to, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyBlockfile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0660)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, src); err == nil {
+		err = dst.Sync()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
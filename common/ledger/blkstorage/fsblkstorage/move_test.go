@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/util"
+)
+
+func TestLedgerPaths(t *testing.T) {
+	ledgerPath := filepath.Join(testPath, "archive")
+	defer os.RemoveAll(ledgerPath)
+	env := newTestEnv(t, NewConfWithLedgerPaths(testPath, 0, map[string]string{"ledger1": ledgerPath}))
+	defer env.Cleanup()
+
+	store1, err := env.provider.OpenBlockStore("ledger1")
+	testutil.AssertNoError(t, err, "")
+	defer store1.Shutdown()
+	store2, err := env.provider.OpenBlockStore("ledger2")
+	testutil.AssertNoError(t, err, "")
+	defer store2.Shutdown()
+
+	blocks := testutil.ConstructTestBlocks(t, 5)
+	for _, b := range blocks {
+		store1.AddBlock(b)
+	}
+	checkBlocks(t, blocks, store1)
+
+	exists, _, _ := util.FileExists(filepath.Join(ledgerPath, "ledger1", "blockfile_000000"))
+	testutil.AssertEquals(t, exists, true)
+	exists, _, _ = util.FileExists(filepath.Join(testPath, "blocks", "ledger1"))
+	testutil.AssertEquals(t, exists, false)
+
+	ledgerids, err := env.provider.List()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(ledgerids), 2)
+}
+
+func TestMoveLedgerBlocks(t *testing.T) {
+	ledgerPath := filepath.Join(testPath, "archive")
+	defer os.RemoveAll(ledgerPath)
+	env := newTestEnv(t, NewConf(testPath, 0))
+	defer env.Cleanup()
+
+	store, _ := env.provider.OpenBlockStore("ledger1")
+	blocks := testutil.ConstructTestBlocks(t, 5)
+	for _, b := range blocks {
+		store.AddBlock(b)
+	}
+	store.Shutdown()
+	env.provider.Close()
+
+	// the ledger mapped to a path cannot be opened before it is moved
+	mapped := NewConfWithLedgerPaths(testPath, 0, map[string]string{"ledger1": ledgerPath})
+	env = newTestEnv(t, mapped)
+	_, err := env.provider.OpenBlockStore("ledger1")
+	testutil.AssertError(t, err, "The ledger should not be opened at its new path before it is moved")
+	env.provider.Close()
+
+	dir, err := MoveLedgerBlocks(NewConf(testPath, 0), "ledger1", ledgerPath)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, dir, filepath.Join(ledgerPath, "ledger1"))
+	_, err = MoveLedgerBlocks(mapped, "ledger1", ledgerPath)
+	testutil.AssertError(t, err, "The block files should already be at the path")
+	_, err = MoveLedgerBlocks(mapped, "ledger2", "")
+	testutil.AssertError(t, err, "There should be no block files to move")
+
+	env = newTestEnv(t, mapped)
+	store, err = env.provider.OpenBlockStore("ledger1")
+	testutil.AssertNoError(t, err, "")
+	checkBlocks(t, blocks, store)
+	store.Shutdown()
+	env.provider.Close()
+
+	// and back to the default folder
+	_, err = MoveLedgerBlocks(mapped, "ledger1", "")
+	testutil.AssertNoError(t, err, "")
+	env = newTestEnv(t, NewConf(testPath, 0))
+	defer env.Cleanup()
+	store, err = env.provider.OpenBlockStore("ledger1")
+	testutil.AssertNoError(t, err, "")
+	defer store.Shutdown()
+	checkBlocks(t, blocks, store)
+}
+
+func TestCopyBlockfiles(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath, 0))
+	defer env.Cleanup()
+
+	store, _ := env.provider.OpenBlockStore("ledger1")
+	blocks := testutil.ConstructTestBlocks(t, 5)
+	for _, b := range blocks {
+		store.AddBlock(b)
+	}
+	store.Shutdown()
+	env.provider.Close()
+
+	// as when moving the block files to another volume
+	from := filepath.Join(testPath, "blocks", "ledger1")
+	to := filepath.Join(testPath, "copy", "ledger1")
+	testutil.AssertNoError(t, copyBlockfiles(from, to), "")
+	os.RemoveAll(from)
+
+	env = newTestEnv(t, NewConfWithLedgerPaths(testPath, 0, map[string]string{"ledger1": filepath.Join(testPath, "copy")}))
+	store, err := env.provider.OpenBlockStore("ledger1")
+	testutil.AssertNoError(t, err, "")
+	defer store.Shutdown()
+	checkBlocks(t, blocks, store)
+}
@@ -42,6 +42,7 @@ func newTestEnv(t testing.TB, conf *Conf) *testEnv {
 		blkstorage.IndexableAttrTxID,
 		blkstorage.IndexableAttrBlockNumTranNum,
 		blkstorage.IndexableAttrBlockTxID,
+		blkstorage.IndexableAttrConfigSeq,
 	}
 	return newTestEnvSelectiveIndexing(t, conf, attrsToIndex)
 }
@@ -0,0 +1,135 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leveldbblkstorage
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/protos/common"
+	"golang.org/x/net/context"
+)
+
+// blockHolder holds block bytes
+type blockHolder struct {
+	blockBytes []byte
+}
+
+// GetBlock deserializes Block from block bytes
+func (bh *blockHolder) GetBlock() *common.Block {
+	block, err := deserializeBlock(bh.blockBytes)
+	if err != nil {
+		panic(fmt.Errorf("Problem in deserialzing block: %s", err))
+	}
+	return block
+}
+
+// GetBlockBytes returns block bytes
+func (bh *blockHolder) GetBlockBytes() []byte {
+	return bh.blockBytes
+}
+
+// blocksItr - an iterator for iterating over a sequence of blocks
+type blocksItr struct {
+	store              *levelDBBlockStore
+	blockNumToRetrieve uint64
+	closeMarker        bool
+	closeMarkerLock    sync.Mutex
+	// ctx cancels the wait for the next block, closed is closed along with the iterator
+	ctx    context.Context
+	closed chan struct{}
+}
+
+func newBlocksItr(ctx context.Context, store *levelDBBlockStore, startBlockNum uint64) *blocksItr {
+	itr := &blocksItr{
+		store:              store,
+		blockNumToRetrieve: startBlockNum,
+		ctx:                ctx,
+		closed:             make(chan struct{}),
+	}
+	if ctx.Done() != nil {
+		go itr.wakeUpOnCancel()
+	}
+	return itr
+}
+
+// wakeUpOnCancel wakes up the iterator waiting for the next block once its context is done
+func (itr *blocksItr) wakeUpOnCancel() {
+	select {
+	case <-itr.ctx.Done():
+		itr.wakeUp()
+	case <-itr.closed:
+	}
+}
+
+func (itr *blocksItr) wakeUp() {
+	itr.store.cond.L.Lock()
+	defer itr.store.cond.L.Unlock()
+	itr.store.cond.Broadcast()
+}
+
+// waitForBlock waits for the block to retrieve to be added to the store, and
+// tells whether it was, rather than the iterator being closed or its context
+// being done in the meantime
+func (itr *blocksItr) waitForBlock() bool {
+	itr.store.cond.L.Lock()
+	defer itr.store.cond.L.Unlock()
+	for !itr.store.info.contains(itr.blockNumToRetrieve) && !itr.shouldClose() && itr.ctx.Err() == nil {
+		logger.Debugf("Going to wait for newer blocks. waitForBlockNum=[%d]", itr.blockNumToRetrieve)
+		itr.store.cond.Wait()
+	}
+	return itr.store.info.contains(itr.blockNumToRetrieve)
+}
+
+func (itr *blocksItr) shouldClose() bool {
+	itr.closeMarkerLock.Lock()
+	defer itr.closeMarkerLock.Unlock()
+	return itr.closeMarker
+}
+
+// Next moves the cursor to next block and returns true iff the iterator is not exhausted.
+// It returns the error of the context of the iterator if it is done while waiting for the block
+func (itr *blocksItr) Next() (ledger.QueryResult, error) {
+	available := itr.waitForBlock()
+	if itr.shouldClose() {
+		return nil, nil
+	}
+	if !available {
+		return nil, itr.ctx.Err()
+	}
+	blockBytes, err := itr.store.retrieveBlockBytes(itr.blockNumToRetrieve)
+	if err != nil {
+		return nil, err
+	}
+	itr.blockNumToRetrieve++
+	return &blockHolder{blockBytes}, nil
+}
+
+// Close releases any resources held by the iterator
+func (itr *blocksItr) Close() {
+	itr.closeMarkerLock.Lock()
+	if itr.closeMarker {
+		itr.closeMarkerLock.Unlock()
+		return
+	}
+	itr.closeMarker = true
+	close(itr.closed)
+	itr.closeMarkerLock.Unlock()
+	// the marker is released first as the waiting iterator checks it while holding the condition lock
+	itr.wakeUp()
+}
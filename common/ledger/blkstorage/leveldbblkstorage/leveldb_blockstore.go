@@ -0,0 +1,353 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leveldbblkstorage
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/protos/common"
+	putil "github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+var logger = logging.MustGetLogger("kvledger")
+
+const (
+	blockKeyPrefix     = 'n'
+	blockHashKeyPrefix = 'h'
+	txIDKeyPrefix      = 't'
+	configSeqKeyPrefix = 'c'
+)
+
+var storeInfoKey = []byte("storeInfo")
+
+// storeInfo is the state of a block store, saved along with every block added
+type storeInfo struct {
+	bcInfo       *common.BlockchainInfo
+	lastBlockNum uint64
+}
+
+// contains tells whether blockNum is not past the last block of the store
+func (i *storeInfo) contains(blockNum uint64) bool {
+	return i.bcInfo.Height > 0 && blockNum <= i.lastBlockNum
+}
+
+func (i *storeInfo) marshal() ([]byte, error) {
+	bcInfoBytes, err := proto.Marshal(i.bcInfo)
+	if err != nil {
+		return nil, err
+	}
+	buffer := proto.NewBuffer([]byte{})
+	if err = buffer.EncodeVarint(i.lastBlockNum); err != nil {
+		return nil, err
+	}
+	if err = buffer.EncodeRawBytes(bcInfoBytes); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (i *storeInfo) unmarshal(b []byte) error {
+	buffer := proto.NewBuffer(b)
+	var err error
+	if i.lastBlockNum, err = buffer.DecodeVarint(); err != nil {
+		return err
+	}
+	bcInfoBytes, err := buffer.DecodeRawBytes(false)
+	if err != nil {
+		return err
+	}
+	i.bcInfo = &common.BlockchainInfo{}
+	return proto.Unmarshal(bcInfoBytes, i.bcInfo)
+}
+
+// levelDBBlockStore - goleveldb based implementation for `BlockStore`. The blocks
+// are stored by number along with their index, a block and its index being
+// written in a single batch so that no index recovery is needed on restart
+type levelDBBlockStore struct {
+	id          string
+	db          *leveldbhelper.DBHandle
+	indexConfig map[blkstorage.IndexableAttr]bool
+	// cond is signaled whenever a block is added, info is guarded by cond.L
+	cond *sync.Cond
+	info *storeInfo
+}
+
+func newLevelDBBlockStore(id string, indexConfig *blkstorage.IndexConfig, db *leveldbhelper.DBHandle) (*levelDBBlockStore, error) {
+	indexItems := make(map[blkstorage.IndexableAttr]bool)
+	for _, attr := range indexConfig.AttrsToIndex {
+		indexItems[attr] = true
+	}
+	info := &storeInfo{bcInfo: &common.BlockchainInfo{}}
+	infoBytes, err := db.Get(storeInfoKey)
+	if err != nil {
+		return nil, err
+	}
+	if infoBytes != nil {
+		if err = info.unmarshal(infoBytes); err != nil {
+			return nil, fmt.Errorf("Error while unmarshalling the info of block store %s: %s", id, err)
+		}
+	}
+	logger.Debugf("Opened leveldb blockStore:%s at height %d", id, info.bcInfo.Height)
+	return &levelDBBlockStore{id, db, indexItems, sync.NewCond(&sync.Mutex{}), info}, nil
+}
+
+// AddBlock adds a new block
+func (store *levelDBBlockStore) AddBlock(block *common.Block) error {
+	store.cond.L.Lock()
+	defer store.cond.L.Unlock()
+	return store.addBlock(block, store.info.bcInfo.Height+1)
+}
+
+// addBlock writes block and its index, the store then reporting the given
+// height. It must be called with cond.L held
+func (store *levelDBBlockStore) addBlock(block *common.Block, height uint64) error {
+	blockBytes, err := proto.Marshal(block)
+	if err != nil {
+		return fmt.Errorf("Error while serializing block: %s", err)
+	}
+	blockNum := block.Header.Number
+	blockHash := block.Header.Hash()
+	batch := leveldbhelper.NewUpdateBatch()
+	batch.Put(constructBlockKey(blockNum), blockBytes)
+	if store.indexConfig[blkstorage.IndexableAttrBlockHash] {
+		batch.Put(constructBlockHashKey(blockHash), util.EncodeOrderPreservingVarUint64(blockNum))
+	}
+	if store.indexConfig[blkstorage.IndexableAttrTxID] || store.indexConfig[blkstorage.IndexableAttrBlockTxID] {
+		for tranNum, envBytes := range block.Data.Data {
+			txID, err := extractTxID(envBytes)
+			if err != nil {
+				return fmt.Errorf("Error while extracting the ID of transaction %d of block %d: %s", tranNum, blockNum, err)
+			}
+			if txID == "" {
+				continue
+			}
+			batch.Put(constructTxIDKey(txID), encodeTxLoc(blockNum, uint64(tranNum)))
+		}
+	}
+	if store.indexConfig[blkstorage.IndexableAttrConfigSeq] {
+		if configSeq, isConfigBlock := putil.GetConfigSequenceFromBlock(block); isConfigBlock {
+			batch.Put(constructConfigSeqKey(configSeq), util.EncodeOrderPreservingVarUint64(blockNum))
+		}
+	}
+	info := &storeInfo{
+		bcInfo: &common.BlockchainInfo{
+			Height:            height,
+			CurrentBlockHash:  blockHash,
+			PreviousBlockHash: block.Header.PreviousHash},
+		lastBlockNum: blockNum}
+	infoBytes, err := info.marshal()
+	if err != nil {
+		return err
+	}
+	batch.Put(storeInfoKey, infoBytes)
+	if err = store.db.WriteBatch(batch, true); err != nil {
+		return fmt.Errorf("Error while writing block %d to db: %s", blockNum, err)
+	}
+	store.info = info
+	store.cond.Broadcast()
+	return nil
+}
+
+// GetBlockchainInfo returns the current info about blockchain
+func (store *levelDBBlockStore) GetBlockchainInfo() (*common.BlockchainInfo, error) {
+	store.cond.L.Lock()
+	defer store.cond.L.Unlock()
+	return store.info.bcInfo, nil
+}
+
+// RetrieveBlocks returns an iterator that can be used for iterating over a range of blocks,
+// until ctx is done
+func (store *levelDBBlockStore) RetrieveBlocks(ctx context.Context, startNum uint64) (ledger.ResultsIterator, error) {
+	return newBlocksItr(ctx, store, startNum), nil
+}
+
+// RetrieveBlockByHash returns the block for given block-hash
+func (store *levelDBBlockStore) RetrieveBlockByHash(blockHash []byte) (*common.Block, error) {
+	if !store.indexConfig[blkstorage.IndexableAttrBlockHash] {
+		return nil, blkstorage.ErrAttrNotIndexed
+	}
+	b, err := store.db.Get(constructBlockHashKey(blockHash))
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, blkstorage.ErrNotFoundInIndex
+	}
+	blockNum, _ := util.DecodeOrderPreservingVarUint64(b)
+	return store.RetrieveBlockByNumber(blockNum)
+}
+
+// RetrieveBlockByNumber returns the block at a given blockchain height
+func (store *levelDBBlockStore) RetrieveBlockByNumber(blockNum uint64) (*common.Block, error) {
+	// interpret math.MaxUint64 as a request for last block
+	if blockNum == math.MaxUint64 {
+		store.cond.L.Lock()
+		blockNum = store.info.lastBlockNum
+		store.cond.L.Unlock()
+	}
+	blockBytes, err := store.retrieveBlockBytes(blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return deserializeBlock(blockBytes)
+}
+
+func (store *levelDBBlockStore) retrieveBlockBytes(blockNum uint64) ([]byte, error) {
+	blockBytes, err := store.db.Get(constructBlockKey(blockNum))
+	if err != nil {
+		return nil, err
+	}
+	if blockBytes == nil {
+		return nil, blkstorage.ErrNotFoundInIndex
+	}
+	return blockBytes, nil
+}
+
+// RetrieveTxByID returns a transaction for given transaction id
+func (store *levelDBBlockStore) RetrieveTxByID(txID string) (*common.Envelope, error) {
+	if !store.indexConfig[blkstorage.IndexableAttrTxID] {
+		return nil, blkstorage.ErrAttrNotIndexed
+	}
+	block, tranNum, err := store.retrieveTxLoc(txID)
+	if err != nil {
+		return nil, err
+	}
+	return putil.GetEnvelopeFromBlock(block.Data.Data[tranNum])
+}
+
+// RetrieveTxByBlockNumTranNum returns the transaction tranNum, starting at 1, of block blockNum
+func (store *levelDBBlockStore) RetrieveTxByBlockNumTranNum(blockNum uint64, tranNum uint64) (*common.Envelope, error) {
+	if !store.indexConfig[blkstorage.IndexableAttrBlockNumTranNum] {
+		return nil, blkstorage.ErrAttrNotIndexed
+	}
+	block, err := store.RetrieveBlockByNumber(blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if tranNum == 0 || tranNum > uint64(len(block.Data.Data)) {
+		return nil, blkstorage.ErrNotFoundInIndex
+	}
+	return putil.GetEnvelopeFromBlock(block.Data.Data[tranNum-1])
+}
+
+// RetrieveBlockByTxID returns the block of the transaction with the given id
+func (store *levelDBBlockStore) RetrieveBlockByTxID(txID string) (*common.Block, error) {
+	if !store.indexConfig[blkstorage.IndexableAttrBlockTxID] {
+		return nil, blkstorage.ErrAttrNotIndexed
+	}
+	block, _, err := store.retrieveTxLoc(txID)
+	return block, err
+}
+
+// retrieveTxLoc returns the block of a transaction and its position in the block
+func (store *levelDBBlockStore) retrieveTxLoc(txID string) (*common.Block, uint64, error) {
+	b, err := store.db.Get(constructTxIDKey(txID))
+	if err != nil {
+		return nil, 0, err
+	}
+	if b == nil {
+		return nil, 0, blkstorage.ErrNotFoundInIndex
+	}
+	blockNum, tranNum := decodeTxLoc(b)
+	block, err := store.RetrieveBlockByNumber(blockNum)
+	if err != nil {
+		return nil, 0, err
+	}
+	if tranNum >= uint64(len(block.Data.Data)) {
+		return nil, 0, fmt.Errorf("Transaction %s indexed at position %d of block %d which has %d transactions", txID, tranNum, blockNum, len(block.Data.Data))
+	}
+	return block, tranNum, nil
+}
+
+// RetrieveConfigBlocks returns the sequence and block numbers of the config blocks
+func (store *levelDBBlockStore) RetrieveConfigBlocks() ([]*common.ConfigBlockInfo, error) {
+	if !store.indexConfig[blkstorage.IndexableAttrConfigSeq] {
+		return nil, blkstorage.ErrAttrNotIndexed
+	}
+	itr := store.db.GetIterator([]byte{configSeqKeyPrefix}, []byte{configSeqKeyPrefix + 1})
+	defer itr.Release()
+	var configBlocks []*common.ConfigBlockInfo
+	for itr.Next() {
+		configSeq, _ := util.DecodeOrderPreservingVarUint64(itr.Key()[1:])
+		blockNum, _ := util.DecodeOrderPreservingVarUint64(itr.Value())
+		configBlocks = append(configBlocks, &common.ConfigBlockInfo{Sequence: configSeq, BlockNumber: blockNum})
+	}
+	if err := itr.Error(); err != nil {
+		return nil, err
+	}
+	return configBlocks, nil
+}
+
+// Shutdown shuts down the block store, the db being closed along with the provider
+func (store *levelDBBlockStore) Shutdown() {
+	logger.Debugf("closing leveldb blockStore:%s", store.id)
+}
+
+func constructBlockKey(blockNum uint64) []byte {
+	return append([]byte{blockKeyPrefix}, util.EncodeOrderPreservingVarUint64(blockNum)...)
+}
+
+func constructBlockHashKey(blockHash []byte) []byte {
+	return append([]byte{blockHashKeyPrefix}, blockHash...)
+}
+
+func constructTxIDKey(txID string) []byte {
+	return append([]byte{txIDKeyPrefix}, []byte(txID)...)
+}
+
+func constructConfigSeqKey(configSeq uint64) []byte {
+	return append([]byte{configSeqKeyPrefix}, util.EncodeOrderPreservingVarUint64(configSeq)...)
+}
+
+func encodeTxLoc(blockNum uint64, tranNum uint64) []byte {
+	return append(util.EncodeOrderPreservingVarUint64(blockNum), util.EncodeOrderPreservingVarUint64(tranNum)...)
+}
+
+func decodeTxLoc(b []byte) (uint64, uint64) {
+	blockNum, n := util.DecodeOrderPreservingVarUint64(b)
+	tranNum, _ := util.DecodeOrderPreservingVarUint64(b[n:])
+	return blockNum, tranNum
+}
+
+func deserializeBlock(blockBytes []byte) (*common.Block, error) {
+	block := &common.Block{}
+	if err := proto.Unmarshal(blockBytes, block); err != nil {
+		return nil, fmt.Errorf("Error while deserializing block: %s", err)
+	}
+	return block, nil
+}
+
+func extractTxID(txEnvelopBytes []byte) (string, error) {
+	txEnvelope, err := putil.GetEnvelopeFromBlock(txEnvelopBytes)
+	if err != nil {
+		return "", err
+	}
+	txPayload, err := putil.GetPayload(txEnvelope)
+	if err != nil || txPayload.Header == nil || txPayload.Header.ChannelHeader == nil {
+		return "", nil
+	}
+	return txPayload.Header.ChannelHeader.TxId, nil
+}
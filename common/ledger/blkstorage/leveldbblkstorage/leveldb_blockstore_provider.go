@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leveldbblkstorage
+
+import (
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+)
+
+// ledgersDBName is the name of the db listing the ledgers, keyed by their ids.
+// Being empty, its keys cannot overlap the keys of the dbs of the ledgers
+const ledgersDBName = ""
+
+var ledgerExistsValue = []byte{1}
+
+// LevelDBBlockstoreProvider provides handle to block storage kept in a single
+// goleveldb, every ledger having its own logical db - this is not thread-safe
+type LevelDBBlockstoreProvider struct {
+	dbPath          string
+	indexConfig     *blkstorage.IndexConfig
+	leveldbProvider *leveldbhelper.Provider
+}
+
+// NewProvider constructs a goleveldb based block store provider, storing the
+// blocks under dbPath
+func NewProvider(dbPath string, indexConfig *blkstorage.IndexConfig) blkstorage.BlockStoreProvider {
+	p := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: dbPath})
+	return &LevelDBBlockstoreProvider{dbPath, indexConfig, p}
+}
+
+// CreateBlockStore simply calls OpenBlockStore
+func (p *LevelDBBlockstoreProvider) CreateBlockStore(ledgerid string) (blkstorage.BlockStore, error) {
+	return p.OpenBlockStore(ledgerid)
+}
+
+// OpenBlockStore opens a block store for given ledgerid.
+// If a blockstore is not existing, this method creates one
+// This method should be invoked only once for a particular ledgerid
+func (p *LevelDBBlockstoreProvider) OpenBlockStore(ledgerid string) (blkstorage.BlockStore, error) {
+	if err := p.leveldbProvider.GetDBHandle(ledgersDBName).Put([]byte(ledgerid), ledgerExistsValue, true); err != nil {
+		return nil, err
+	}
+	return newLevelDBBlockStore(ledgerid, p.indexConfig, p.leveldbProvider.GetDBHandle(ledgerid))
+}
+
+// Exists tells whether the BlockStore with given id exits
+func (p *LevelDBBlockstoreProvider) Exists(ledgerid string) (bool, error) {
+	value, err := p.leveldbProvider.GetDBHandle(ledgersDBName).Get([]byte(ledgerid))
+	return value != nil, err
+}
+
+// List lists the ids of the existing ledgers
+func (p *LevelDBBlockstoreProvider) List() ([]string, error) {
+	itr := p.leveldbProvider.GetDBHandle(ledgersDBName).GetIterator(nil, nil)
+	defer itr.Release()
+	var ledgerids []string
+	for itr.Next() {
+		ledgerids = append(ledgerids, string(itr.Key()))
+	}
+	return ledgerids, itr.Error()
+}
+
+// Close closes the LevelDBBlockstoreProvider
+func (p *LevelDBBlockstoreProvider) Close() {
+	p.leveldbProvider.Close()
+}
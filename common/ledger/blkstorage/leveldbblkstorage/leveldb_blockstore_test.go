@@ -0,0 +1,285 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leveldbblkstorage
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/protos/common"
+	putil "github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+)
+
+func TestBlockStoreReadWrite(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	store := env.openBlockStore("testLedger")
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	addBlocks(t, store, blocks)
+
+	bcInfo, _ := store.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(10))
+	testutil.AssertEquals(t, bcInfo.CurrentBlockHash, blocks[9].Header.Hash())
+	testutil.AssertEquals(t, bcInfo.PreviousBlockHash, blocks[9].Header.PreviousHash)
+
+	for i, block := range blocks {
+		b, err := store.RetrieveBlockByNumber(uint64(i + 1))
+		testutil.AssertNoError(t, err, "")
+		assertBlockEquals(t, b, block)
+		b, err = store.RetrieveBlockByHash(block.Header.Hash())
+		testutil.AssertNoError(t, err, "")
+		assertBlockEquals(t, b, block)
+	}
+	b, err := store.RetrieveBlockByNumber(math.MaxUint64)
+	testutil.AssertNoError(t, err, "")
+	assertBlockEquals(t, b, blocks[9])
+	_, err = store.RetrieveBlockByNumber(11)
+	testutil.AssertEquals(t, err, blkstorage.ErrNotFoundInIndex)
+}
+
+func TestBlockStoreTransactions(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	store := env.openBlockStore("testLedger")
+	blocks := testutil.ConstructTestBlocks(t, 5)
+	addBlocks(t, store, blocks)
+
+	for blockIndex, blk := range blocks {
+		for tranIndex, txEnvelopeBytes := range blk.Data.Data {
+			txEnvelope, err := putil.GetEnvelopeFromBlock(txEnvelopeBytes)
+			testutil.AssertNoError(t, err, "Error while unmarshalling tx")
+			txID, err := extractTxID(txEnvelopeBytes)
+			testutil.AssertNoError(t, err, "")
+
+			tx, err := store.RetrieveTxByID(txID)
+			testutil.AssertNoError(t, err, "Error while retrieving tx by id")
+			testutil.AssertEquals(t, tx, txEnvelope)
+			// blockNum starts with 1, tranNum starts with 1
+			tx, err = store.RetrieveTxByBlockNumTranNum(uint64(blockIndex+1), uint64(tranIndex+1))
+			testutil.AssertNoError(t, err, "Error while retrieving tx by block and tran number")
+			testutil.AssertEquals(t, tx, txEnvelope)
+			b, err := store.RetrieveBlockByTxID(txID)
+			testutil.AssertNoError(t, err, "Error while retrieving block by tx id")
+			assertBlockEquals(t, b, blk)
+		}
+	}
+	_, err := store.RetrieveTxByID("nonexistent")
+	testutil.AssertEquals(t, err, blkstorage.ErrNotFoundInIndex)
+	_, err = store.RetrieveTxByBlockNumTranNum(1, 0)
+	testutil.AssertEquals(t, err, blkstorage.ErrNotFoundInIndex)
+}
+
+func TestBlockStoreSelectiveIndexing(t *testing.T) {
+	env := newTestEnvSelectiveIndexing(t, []blkstorage.IndexableAttr{blkstorage.IndexableAttrBlockNum})
+	defer env.Cleanup()
+	store := env.openBlockStore("testLedger")
+	blocks := testutil.ConstructTestBlocks(t, 2)
+	addBlocks(t, store, blocks)
+
+	_, err := store.RetrieveBlockByNumber(1)
+	testutil.AssertNoError(t, err, "")
+	_, err = store.RetrieveBlockByHash(blocks[0].Header.Hash())
+	testutil.AssertEquals(t, err, blkstorage.ErrAttrNotIndexed)
+	txID, _ := extractTxID(blocks[0].Data.Data[0])
+	_, err = store.RetrieveTxByID(txID)
+	testutil.AssertEquals(t, err, blkstorage.ErrAttrNotIndexed)
+	_, err = store.RetrieveBlockByTxID(txID)
+	testutil.AssertEquals(t, err, blkstorage.ErrAttrNotIndexed)
+	_, err = store.RetrieveTxByBlockNumTranNum(1, 1)
+	testutil.AssertEquals(t, err, blkstorage.ErrAttrNotIndexed)
+	_, err = store.RetrieveConfigBlocks()
+	testutil.AssertEquals(t, err, blkstorage.ErrAttrNotIndexed)
+}
+
+func TestBlockStoreConfigBlocks(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	store := env.openBlockStore("testLedger")
+
+	bg := testutil.NewBlockGenerator(t)
+	addBlocks(t, store, []*common.Block{bg.NextConfigBlock(0)})
+	addBlocks(t, store, bg.NextTestBlocks(2))
+	addBlocks(t, store, []*common.Block{bg.NextConfigBlock(1)})
+	configBlocks, err := store.RetrieveConfigBlocks()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, configBlocks, []*common.ConfigBlockInfo{
+		{Sequence: 0, BlockNumber: 1},
+		{Sequence: 1, BlockNumber: 4},
+	})
+}
+
+func TestBlockStoreRestart(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	store := env.openBlockStore("testLedger")
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	addBlocks(t, store, blocks[:5])
+	store.Shutdown()
+
+	env.reopen()
+	store = env.openBlockStore("testLedger")
+	bcInfo, _ := store.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(5))
+	testutil.AssertEquals(t, bcInfo.CurrentBlockHash, blocks[4].Header.Hash())
+	addBlocks(t, store, blocks[5:])
+	checkBlocks(t, blocks, store)
+}
+
+func TestBlocksItrBlockingNext(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	store := env.openBlockStore("testLedger")
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	addBlocks(t, store, blocks[:5])
+
+	itr, err := store.RetrieveBlocks(context.Background(), 2)
+	testutil.AssertNoError(t, err, "")
+	defer itr.Close()
+	doneChan := make(chan bool)
+	go func() {
+		for _, block := range blocks[1:] {
+			bh, err := itr.Next()
+			testutil.AssertNoError(t, err, "")
+			assertBlockEquals(t, bh.(ledger.BlockHolder).GetBlock(), block)
+		}
+		doneChan <- true
+	}()
+	time.Sleep(time.Millisecond * 10)
+	addBlocks(t, store, blocks[5:])
+	select {
+	case <-doneChan:
+	case <-time.After(time.Second):
+		t.Fatalf("Next should have returned the blocks added")
+	}
+}
+
+func TestBlocksItrCancelledNext(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	store := env.openBlockStore("testLedger")
+	addBlocks(t, store, testutil.ConstructTestBlocks(t, 2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	itr, err := store.RetrieveBlocks(ctx, 3)
+	testutil.AssertNoError(t, err, "")
+	defer itr.Close()
+	errChan := make(chan error)
+	go func() {
+		_, err := itr.Next()
+		errChan <- err
+	}()
+
+	// Next waits for block 3, until the context is cancelled
+	time.Sleep(time.Millisecond * 10)
+	cancel()
+	select {
+	case err := <-errChan:
+		testutil.AssertEquals(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatalf("Next should have returned once the context was cancelled")
+	}
+}
+
+func TestBlocksItrClosedNext(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	store := env.openBlockStore("testLedger")
+
+	itr, err := store.RetrieveBlocks(context.Background(), 1)
+	testutil.AssertNoError(t, err, "")
+	resultChan := make(chan ledger.QueryResult)
+	go func() {
+		result, _ := itr.Next()
+		resultChan <- result
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+	itr.Close()
+	select {
+	case result := <-resultChan:
+		testutil.AssertNil(t, result)
+	case <-time.After(time.Second):
+		t.Fatalf("Next should have returned once the iterator was closed")
+	}
+}
+
+func TestBlockStoreProvider(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+
+	numStores := 10
+	for i := 0; i < numStores; i++ {
+		store := env.openBlockStore(constructLedgerid(i))
+		addBlocks(t, store, testutil.ConstructTestBlocks(t, i+1))
+	}
+	env.reopen()
+	provider := env.provider
+
+	storeNames, err := provider.List()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(storeNames), numStores)
+	for i := 0; i < numStores; i++ {
+		exists, err := provider.Exists(constructLedgerid(i))
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, exists, true)
+		bcInfo, _ := env.openBlockStore(constructLedgerid(i)).GetBlockchainInfo()
+		testutil.AssertEquals(t, bcInfo.Height, uint64(i+1))
+	}
+
+	exists, err := provider.Exists(constructLedgerid(numStores + 1))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, exists, false)
+}
+
+func addBlocks(t *testing.T, store blkstorage.BlockStore, blocks []*common.Block) {
+	for _, block := range blocks {
+		testutil.AssertNoError(t, store.AddBlock(block), "Error while adding block")
+	}
+}
+
+func checkBlocks(t *testing.T, expectedBlocks []*common.Block, store blkstorage.BlockStore) {
+	bcInfo, _ := store.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(len(expectedBlocks)))
+	testutil.AssertEquals(t, bcInfo.CurrentBlockHash, expectedBlocks[len(expectedBlocks)-1].GetHeader().Hash())
+
+	itr, _ := store.RetrieveBlocks(context.Background(), 1)
+	defer itr.Close()
+	for i := 0; i < len(expectedBlocks); i++ {
+		blockHolder, _ := itr.Next()
+		block := blockHolder.(ledger.BlockHolder).GetBlock()
+		assertBlockEquals(t, block, expectedBlocks[i])
+	}
+}
+
+// assertBlockEquals compares the blocks as protos, the empty hashes of the test
+// blocks being unmarshalled as nil
+func assertBlockEquals(t *testing.T, actual *common.Block, expected *common.Block) {
+	if !proto.Equal(actual, expected) {
+		t.Errorf("Blocks are not equal.\n Actual=[%s]\n Expected=[%s]", actual, expected)
+	}
+}
+
+func constructLedgerid(id int) string {
+	return fmt.Sprintf("ledger_%d", id)
+}
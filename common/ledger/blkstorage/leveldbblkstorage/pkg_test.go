@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leveldbblkstorage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+)
+
+var testPath = "/tmp/fabric/ledgertests/blkstorage/leveldbblkstorage"
+
+type testEnv struct {
+	t        testing.TB
+	provider *LevelDBBlockstoreProvider
+}
+
+func newTestEnv(t testing.TB) *testEnv {
+	attrsToIndex := []blkstorage.IndexableAttr{
+		blkstorage.IndexableAttrBlockHash,
+		blkstorage.IndexableAttrBlockNum,
+		blkstorage.IndexableAttrTxID,
+		blkstorage.IndexableAttrBlockNumTranNum,
+		blkstorage.IndexableAttrBlockTxID,
+		blkstorage.IndexableAttrConfigSeq,
+	}
+	return newTestEnvSelectiveIndexing(t, attrsToIndex)
+}
+
+func newTestEnvSelectiveIndexing(t testing.TB, attrsToIndex []blkstorage.IndexableAttr) *testEnv {
+	os.RemoveAll(testPath)
+	indexConfig := &blkstorage.IndexConfig{AttrsToIndex: attrsToIndex}
+	return &testEnv{t, NewProvider(testPath, indexConfig).(*LevelDBBlockstoreProvider)}
+}
+
+// reopen closes the provider and opens it again, over the same db
+func (env *testEnv) reopen() {
+	env.provider.Close()
+	env.provider = NewProvider(testPath, env.provider.indexConfig).(*LevelDBBlockstoreProvider)
+}
+
+func (env *testEnv) Cleanup() {
+	env.provider.Close()
+	os.RemoveAll(env.provider.dbPath)
+}
+
+func (env *testEnv) openBlockStore(ledgerid string) *levelDBBlockStore {
+	store, err := env.provider.OpenBlockStore(ledgerid)
+	testutil.AssertNoError(env.t, err, "")
+	return store.(*levelDBBlockStore)
+}
@@ -18,6 +18,7 @@ package ledger
 
 import (
 	"github.com/hyperledger/fabric/protos/common"
+	"golang.org/x/net/context"
 )
 
 // Ledger captures the methods that are common across the 'PeerLedger', 'OrdererLedger', and 'ValidatedLedger'
@@ -28,9 +29,10 @@ type Ledger interface {
 	// blockNumber of  math.MaxUint64 will return last block
 	GetBlockByNumber(blockNumber uint64) (*common.Block, error)
 	// GetBlocksIterator returns an iterator that starts from `startBlockNumber`(inclusive).
-	// The iterator is a blocking iterator i.e., it blocks till the next block gets available in the ledger
+	// The iterator is a blocking iterator i.e., it blocks till the next block gets available in the ledger,
+	// or till ctx is done in which case Next returns the error of ctx
 	// ResultsIterator contains type BlockHolder
-	GetBlocksIterator(startBlockNumber uint64) (ResultsIterator, error)
+	GetBlocksIterator(ctx context.Context, startBlockNumber uint64) (ResultsIterator, error)
 	// Close closes the ledger
 	Close()
 	// Commit adds a new block
@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/protos/common"
 	ptestutils "github.com/hyperledger/fabric/protos/testutils"
+	"github.com/hyperledger/fabric/protos/utils"
 )
 
 //BlockGenerator generates a series of blocks for testing
@@ -53,6 +54,32 @@ func (bg *BlockGenerator) NextBlock(simulationResults [][]byte, sign bool) *comm
 	return block
 }
 
+// NextConfigBlock constructs next block in sequence carrying a config of the given sequence number
+func (bg *BlockGenerator) NextConfigBlock(sequence uint64) *common.Block {
+	env := &common.Envelope{
+		Payload: utils.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{
+				ChannelHeader: &common.ChannelHeader{
+					Type:      int32(common.HeaderType_CONFIG),
+					ChannelId: util.GetTestChainID(),
+				},
+				SignatureHeader: &common.SignatureHeader{},
+			},
+			Data: utils.MarshalOrPanic(&common.ConfigEnvelope{
+				Config: &common.Config{
+					Channel: &common.ConfigGroup{
+						Values: map[string]*common.ConfigValue{"TestValue": {Version: sequence}},
+					},
+				},
+			}),
+		}),
+	}
+	block := newBlock([]*common.Envelope{env}, bg.blockNum, bg.previousHash)
+	bg.blockNum++
+	bg.previousHash = block.Header.Hash()
+	return block
+}
+
 // NextTestBlock constructs next block in sequence block with 'numTx' number of transactions for testing
 func (bg *BlockGenerator) NextTestBlock(numTx int, txSize int) *common.Block {
 	simulationResults := [][]byte{}
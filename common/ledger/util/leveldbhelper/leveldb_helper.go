@@ -169,3 +169,44 @@ func (dbInst *DB) WriteBatch(batch *leveldb.Batch, sync bool) error {
 	}
 	return nil
 }
+
+// Snapshot is a read-only view of the store at the time it was taken. The
+// snapshot should be released after the use.
+type Snapshot struct {
+	snapshot *leveldb.Snapshot
+	readOpts *opt.ReadOptions
+}
+
+// GetSnapshot takes a snapshot of the store, which is not affected by the later writes
+func (dbInst *DB) GetSnapshot() (*Snapshot, error) {
+	snapshot, err := dbInst.db.GetSnapshot()
+	if err != nil {
+		logger.Errorf("Error while taking a snapshot: %s", err)
+		return nil, err
+	}
+	return &Snapshot{snapshot, dbInst.readOpts}, nil
+}
+
+// Get returns the value for the given key as of the snapshot
+func (s *Snapshot) Get(key []byte) ([]byte, error) {
+	value, err := s.snapshot.Get(key, s.readOpts)
+	if err == leveldb.ErrNotFound {
+		value = nil
+		err = nil
+	}
+	if err != nil {
+		logger.Errorf("Error while trying to retrieve key [%#v] from snapshot: %s", key, err)
+		return nil, err
+	}
+	return value, nil
+}
+
+// GetIterator returns an iterator over the snapshot, see DB.GetIterator
+func (s *Snapshot) GetIterator(startKey []byte, endKey []byte) iterator.Iterator {
+	return s.snapshot.NewIterator(&goleveldbutil.Range{Start: startKey, Limit: endKey}, s.readOpts)
+}
+
+// Release releases the snapshot
+func (s *Snapshot) Release() {
+	s.snapshot.Release()
+}
@@ -81,6 +81,37 @@ func TestIterator(t *testing.T) {
 	checkItrResults(t, itr3, createTestKeys(0, 19), createTestValues("db2", 0, 19))
 }
 
+func TestSnapshot(t *testing.T) {
+	p := createTestDBProvider(t)
+	defer p.Close()
+	db1 := p.GetDBHandle("db1")
+	db2 := p.GetDBHandle("db2")
+	for i := 0; i < 5; i++ {
+		db1.Put([]byte(createTestKey(i)), []byte(createTestValue("db1", i)), false)
+		db2.Put([]byte(createTestKey(i)), []byte(createTestValue("db2", i)), false)
+	}
+
+	snapshot, err := db1.GetSnapshot()
+	testutil.AssertNoError(t, err, "")
+	defer snapshot.Release()
+	// the writes following the snapshot are not visible through it
+	db1.Put([]byte(createTestKey(0)), []byte("updated"), false)
+	db1.Put([]byte(createTestKey(5)), []byte(createTestValue("db1", 5)), false)
+	db1.Delete([]byte(createTestKey(1)), false)
+
+	val, err := snapshot.Get([]byte(createTestKey(0)))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, val, []byte(createTestValue("db1", 0)))
+	val, err = snapshot.Get([]byte(createTestKey(5)))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, val)
+	checkItrResults(t, snapshot.GetIterator(nil, nil), createTestKeys(0, 4), createTestValues("db1", 0, 4))
+
+	val, err = db1.Get([]byte(createTestKey(0)))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, val, []byte("updated"))
+}
+
 func checkItrResults(t *testing.T, itr *Iterator, expectedKeys []string, expectedValues []string) {
 	defer itr.Release()
 	var actualKeys []string
@@ -100,16 +100,44 @@ func (h *DBHandle) WriteBatch(batch *UpdateBatch, sync bool) error {
 // The resultset contains all the keys that are present in the db between the startKey (inclusive) and the endKey (exclusive).
 // A nil startKey represents the first available key and a nil endKey represent a logical key after the last available key
 func (h *DBHandle) GetIterator(startKey []byte, endKey []byte) *Iterator {
-	sKey := constructLevelKey(h.dbName, startKey)
-	eKey := constructLevelKey(h.dbName, endKey)
-	if endKey == nil {
-		// replace the last byte 'dbNameKeySep' by 'lastKeyIndicator'
-		eKey[len(eKey)-1] = lastKeyIndicator
-	}
+	sKey, eKey := constructLevelKeyRange(h.dbName, startKey, endKey)
 	logger.Debugf("Getting iterator for range [%#v] - [%#v]", sKey, eKey)
 	return &Iterator{h.db.GetIterator(sKey, eKey)}
 }
 
+// GetSnapshot takes a snapshot of the named db, which is not affected by the later writes
+func (h *DBHandle) GetSnapshot() (*SnapshotHandle, error) {
+	snapshot, err := h.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotHandle{h.dbName, snapshot}, nil
+}
+
+// SnapshotHandle is a read-only view of a named db at the time it was taken.
+// The snapshot should be released after the use.
+type SnapshotHandle struct {
+	dbName   string
+	snapshot *Snapshot
+}
+
+// Get returns the value for the given key as of the snapshot
+func (h *SnapshotHandle) Get(key []byte) ([]byte, error) {
+	return h.snapshot.Get(constructLevelKey(h.dbName, key))
+}
+
+// GetIterator gets an handle to iterator over the snapshot, see DBHandle.GetIterator
+func (h *SnapshotHandle) GetIterator(startKey []byte, endKey []byte) *Iterator {
+	sKey, eKey := constructLevelKeyRange(h.dbName, startKey, endKey)
+	logger.Debugf("Getting snapshot iterator for range [%#v] - [%#v]", sKey, eKey)
+	return &Iterator{h.snapshot.GetIterator(sKey, eKey)}
+}
+
+// Release releases the snapshot
+func (h *SnapshotHandle) Release() {
+	h.snapshot.Release()
+}
+
 // UpdateBatch encloses the details of multiple `updates`
 type UpdateBatch struct {
 	KVs map[string][]byte
@@ -147,6 +175,18 @@ func constructLevelKey(dbName string, key []byte) []byte {
 	return append(append([]byte(dbName), dbNameKeySep...), key...)
 }
 
+// constructLevelKeyRange returns the range of the leveldb keys of the named
+// db between startKey and endKey, a nil endKey being after the last key
+func constructLevelKeyRange(dbName string, startKey []byte, endKey []byte) ([]byte, []byte) {
+	sKey := constructLevelKey(dbName, startKey)
+	eKey := constructLevelKey(dbName, endKey)
+	if endKey == nil {
+		// replace the last byte 'dbNameKeySep' by 'lastKeyIndicator'
+		eKey[len(eKey)-1] = lastKeyIndicator
+	}
+	return sKey, eKey
+}
+
 func retrieveAppKey(levelKey []byte) []byte {
 	return bytes.SplitN(levelKey, dbNameKeySep, 2)[1]
 }
@@ -140,6 +140,9 @@ type Manager struct {
 	// SequenceVal is returned as the result of Sequence()
 	SequenceVal uint64
 
+	// ConfigDigestVal is returned as the result of ConfigDigest()
+	ConfigDigestVal []byte
+
 	// ApplyVal is returned by Apply
 	ApplyVal error
 
@@ -148,6 +151,15 @@ type Manager struct {
 
 	// ValidateVal is returned by Validate
 	ValidateVal error
+
+	// SimulateVal is returned by Simulate, along with ValidateVal
+	SimulateVal *cb.ConfigEnvelope
+
+	// RollbackVal is returned by Rollback
+	RollbackVal error
+
+	// RolledBackSequence is set by Rollback
+	RolledBackSequence uint64
 }
 
 // ConfigEnvelope is currently unimplemented
@@ -165,6 +177,11 @@ func (cm *Manager) Sequence() uint64 {
 	return cm.SequenceVal
 }
 
+// ConfigDigest returns the ConfigDigestVal
+func (cm *Manager) ConfigDigest() []byte {
+	return cm.ConfigDigestVal
+}
+
 // Apply returns ApplyVal
 func (cm *Manager) Apply(configtx *cb.Envelope) error {
 	cm.AppliedConfigUpdateEnvelope = configtx
@@ -175,3 +192,14 @@ func (cm *Manager) Apply(configtx *cb.Envelope) error {
 func (cm *Manager) Validate(configtx *cb.Envelope) error {
 	return cm.ValidateVal
 }
+
+// Simulate returns SimulateVal and ValidateVal
+func (cm *Manager) Simulate(configtx *cb.Envelope) (*cb.ConfigEnvelope, error) {
+	return cm.SimulateVal, cm.ValidateVal
+}
+
+// Rollback returns RollbackVal
+func (cm *Manager) Rollback(sequence uint64) error {
+	cm.RolledBackSequence = sequence
+	return cm.RollbackVal
+}
@@ -16,7 +16,10 @@ limitations under the License.
 
 package channel
 
-import "github.com/hyperledger/fabric/common/util"
+import (
+	"github.com/hyperledger/fabric/common/capabilities"
+	"github.com/hyperledger/fabric/common/util"
+)
 
 func nearIdentityHash(input []byte) []byte {
 	return util.ConcatenateBytes([]byte("FakeHash("), input, []byte(""))
@@ -30,6 +33,8 @@ type SharedConfig struct {
 	BlockDataHashingStructureWidthVal uint32
 	// OrdererAddressesVal is returned as the result of OrdererAddresses()
 	OrdererAddressesVal []string
+	// CapabilitiesVal is returned as the result of Capabilities() if set
+	CapabilitiesVal *capabilities.Provider
 }
 
 // HashingAlgorithm returns the HashingAlgorithmVal if set, otherwise a fake simple hash function
@@ -49,3 +54,11 @@ func (scm *SharedConfig) BlockDataHashingStructureWidth() uint32 {
 func (scm *SharedConfig) OrdererAddresses() []string {
 	return scm.OrdererAddressesVal
 }
+
+// Capabilities returns the CapabilitiesVal if set, otherwise no capabilities
+func (scm *SharedConfig) Capabilities() *capabilities.Provider {
+	if scm.CapabilitiesVal == nil {
+		return capabilities.NewProvider(nil)
+	}
+	return scm.CapabilitiesVal
+}
@@ -27,6 +27,15 @@ import (
 
 var logger = logging.MustGetLogger("common/policies")
 
+// ChannelReaders is the name of the channel policy satisfied by the identities
+// allowed to read the blocks and the state of the channel from the peers
+const ChannelReaders = "Readers"
+
+// ChannelFullReaders is the name of the channel policy satisfied by the
+// readers allowed to read the transactions of the channel in full from the
+// ledger queries of the peers, the other readers getting redacted views
+const ChannelFullReaders = "FullReaders"
+
 // Policy is used to determine if a signature is valid
 type Policy interface {
 	// Evaluate takes a set of SignedData and evaluates whether this set of signatures satisfies the policy
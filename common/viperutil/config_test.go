@@ -310,3 +310,18 @@ func TestStringFromFileEnv(t *testing.T) {
 		t.Fatalf(`Expected: "%v",  Actual: "%v"`, expectedValue, uconf.Inner.Single)
 	}
 }
+
+func TestKeyNames(t *testing.T) {
+	keys := map[string]interface{}{
+		"general": map[string]interface{}{
+			"tls":        map[string]interface{}{"privatekey": "secret"},
+			"listenport": 7050,
+			"password":   "secret",
+		},
+		"kafka": map[string]interface{}{"brokers": []string{"127.0.0.1:9092"}},
+	}
+	expected := []string{"general.listenport", "general.password", "general.tls.privatekey", "kafka.brokers"}
+	if names := keyNames("", keys); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected key names %v, got %v", expected, names)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"math"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -56,21 +57,35 @@ func getKeysRecursively(base string, v *viper.Viper, nodeKeys map[string]interfa
 			logger.Debugf("Found map[string]interface{} value for %s", fqKey)
 			result[key] = getKeysRecursively(fqKey+".", v, m)
 		} else if m, ok := unmarshalJSON(val); ok {
-			logger.Debugf("Found real value for %s setting to map[string]string %v", fqKey, m)
+			logger.Debugf("Found real value for %s setting to map[string]string", fqKey)
 			result[key] = m
 		} else {
-			logger.Debugf("Found real value for %s setting to %T %v", fqKey, val, val)
+			logger.Debugf("Found real value for %s setting to %T", fqKey, val)
 			result[key] = val
 		}
 	}
 	return result
 }
 
+// keyNames returns the sorted fully qualified names of the leaf keys of keys
+func keyNames(base string, keys map[string]interface{}) []string {
+	var names []string
+	for key, val := range keys {
+		if m, ok := val.(map[string]interface{}); ok {
+			names = append(names, keyNames(base+key+".", m)...)
+		} else {
+			names = append(names, base+key)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func unmarshalJSON(val interface{}) (map[string]string, bool) {
 	mp := map[string]string{}
 	s, ok := val.(string)
 	if !ok {
-		logger.Debugf("Unmarshal JSON: value is not a string: %v", val)
+		logger.Debugf("Unmarshal JSON: value is not a string but %T", val)
 		return nil, false
 	}
 	err := json.Unmarshal([]byte(s), &mp)
@@ -236,9 +251,16 @@ func pemBlocksFromFileDecodeHook() mapstructure.DecodeHookFunc {
 // the time.Duration type
 func EnhancedExactUnmarshal(v *viper.Viper, output interface{}) error {
 	baseKeys := v.AllSettings() // AllKeys doesn't actually return all keys, it only returns the base ones
+	for key := range baseKeys {
+		if strings.Contains(key, ".") {
+			// an override of a nested key, e.g. a resolved secret, which Get returns
+			delete(baseKeys, key)
+		}
+	}
 	leafKeys := getKeysRecursively("", v, baseKeys)
 
-	logger.Infof("%+v", leafKeys)
+	// Only the names of the keys are logged, the values may be secrets
+	logger.Infof("%s", strings.Join(keyNames("", leafKeys), ", "))
 	config := &mapstructure.DecoderConfig{
 		ErrorUnused:      true,
 		Metadata:         nil,
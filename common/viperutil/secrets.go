@@ -0,0 +1,120 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viperutil
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// SecretPrefix is the prefix of the values referencing a secret file, e.g.
+// secret://couchdb_password or secret:///etc/secrets/couchdb_password
+const SecretPrefix = "secret://"
+
+// FileSuffix is the suffix of the environment variables setting a value to
+// the contents of a file, e.g. CORE_LEDGER_STATE_COUCHDBCONFIG_PASSWORD_FILE
+const FileSuffix = "_FILE"
+
+// SecretsDir is the directory of the secret references with a relative path,
+// where Docker and Kubernetes mount the secrets
+var SecretsDir = "/run/secrets"
+
+// Settings is the part of a viper resolving the secrets, implemented by
+// *viper.Viper, and by GlobalViper for the package level viper
+type Settings interface {
+	AllSettings() map[string]interface{}
+	Get(key string) interface{}
+	Set(key string, value interface{})
+}
+
+type globalViper struct{}
+
+func (globalViper) AllSettings() map[string]interface{} { return viper.AllSettings() }
+func (globalViper) Get(key string) interface{}          { return viper.Get(key) }
+func (globalViper) Set(key string, value interface{})   { viper.Set(key, value) }
+
+// GlobalViper is the package level viper, e.g. the config of the peer
+var GlobalViper Settings = globalViper{}
+
+// ResolveSecrets sets the settings of v whose value is a secret reference, or
+// overridden by an environment variable with the suffix FileSuffix, to the
+// contents of the file referenced, without its trailing newline. That way the
+// passwords and passphrases need not appear in the config file nor in plain
+// environment variables. It returns the lower case keys of the settings resolved,
+// and the errors of the others
+func ResolveSecrets(v Settings, envPrefix string) ([]string, error) {
+	var resolved []string
+	var errs []string
+	keys := leafKeys("", v.AllSettings())
+	sort.Strings(keys)
+	for _, key := range keys {
+		envName := strings.ToUpper(envPrefix + "_" + strings.Replace(key, ".", "_", -1))
+		path := os.Getenv(envName + FileSuffix)
+		if path == "" {
+			value, ok := v.Get(key).(string)
+			if !ok || !strings.HasPrefix(value, SecretPrefix) {
+				continue
+			}
+			path = strings.TrimPrefix(value, SecretPrefix)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(SecretsDir, path)
+			}
+		} else if os.Getenv(envName) != "" {
+			errs = append(errs, fmt.Sprintf("Both %s and %s are set", envName, envName+FileSuffix))
+			continue
+		}
+
+		secret, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Error reading the secret of %s: %s", key, err))
+			continue
+		}
+		v.Set(key, strings.TrimRight(string(secret), "\r\n"))
+		resolved = append(resolved, strings.ToLower(key))
+	}
+	if len(errs) > 0 {
+		return resolved, errors.New(strings.Join(errs, "; "))
+	}
+	return resolved, nil
+}
+
+// leafKeys returns the dotted keys of the leaves of settings
+func leafKeys(base string, settings map[string]interface{}) []string {
+	var keys []string
+	for key, value := range settings {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			keys = append(keys, leafKeys(base+key+".", value)...)
+		case map[interface{}]interface{}:
+			m := make(map[string]interface{}, len(value))
+			for k, v := range value {
+				m[fmt.Sprint(k)] = v
+			}
+			keys = append(keys, leafKeys(base+key+".", m)...)
+		default:
+			keys = append(keys, base+key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,96 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viperutil
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type secretsConfig struct {
+	Kafka struct {
+		Password string
+	}
+	CouchDB struct {
+		Username string
+		Password string
+	}
+	Keystore struct {
+		Passphrase string
+	}
+}
+
+func newSecretsTestViper(t *testing.T, yaml string) *viper.Viper {
+	config := viper.New()
+	config.SetEnvPrefix(Prefix)
+	config.AutomaticEnv()
+	config.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	config.SetConfigType("yaml")
+	assert.NoError(t, config.ReadConfig(bytes.NewReader([]byte(yaml))))
+	return config
+}
+
+func TestResolveSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(secretsDir string) { SecretsDir = secretsDir }(SecretsDir)
+	SecretsDir = dir
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "kafka"), []byte("kafkapw\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "couchdb"), []byte("couchpw"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "keystore"), []byte("phrase"), 0600))
+
+	os.Setenv("VIPERUTIL_COUCHDB_PASSWORD_FILE", filepath.Join(dir, "couchdb"))
+	defer os.Unsetenv("VIPERUTIL_COUCHDB_PASSWORD_FILE")
+	os.Setenv("VIPERUTIL_KEYSTORE_PASSPHRASE", "secret://"+filepath.Join(dir, "keystore"))
+	defer os.Unsetenv("VIPERUTIL_KEYSTORE_PASSPHRASE")
+
+	config := newSecretsTestViper(t, "---\nKafka:\n  Password: secret://kafka\nCouchDB:\n  Username: admin\n  Password:\nKeystore:\n  Passphrase:\n")
+	resolved, err := ResolveSecrets(config, Prefix)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"couchdb.password", "kafka.password", "keystore.passphrase"}, resolved)
+
+	var uconf secretsConfig
+	assert.NoError(t, EnhancedExactUnmarshal(config, &uconf))
+	assert.Equal(t, "kafkapw", uconf.Kafka.Password)
+	assert.Equal(t, "admin", uconf.CouchDB.Username)
+	assert.Equal(t, "couchpw", uconf.CouchDB.Password)
+	assert.Equal(t, "phrase", uconf.Keystore.Passphrase)
+}
+
+func TestResolveSecretsErrors(t *testing.T) {
+	config := newSecretsTestViper(t, "---\nKafka:\n  Password: secret:///nonexistent/kafka\n")
+	_, err := ResolveSecrets(config, Prefix)
+	assert.Error(t, err)
+
+	os.Setenv("VIPERUTIL_KAFKA_PASSWORD", "plain")
+	defer os.Unsetenv("VIPERUTIL_KAFKA_PASSWORD")
+	os.Setenv("VIPERUTIL_KAFKA_PASSWORD_FILE", "/nonexistent/kafka")
+	defer os.Unsetenv("VIPERUTIL_KAFKA_PASSWORD_FILE")
+	config = newSecretsTestViper(t, "---\nKafka:\n  Password:\n")
+	_, err = ResolveSecrets(config, Prefix)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Both")
+}
@@ -0,0 +1,215 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wsgateway exposes the Broadcast and Deliver services of an orderer
+// and the events of a peer over WebSocket, for the browsers and the clients
+// which cannot reach the gRPC services through their firewall.
+//
+// Every WebSocket text frame carries one protobuf message in its JSON mapping,
+// the bytes fields being base64 encoded. A client sends common.Envelope on
+// /broadcast and /deliver, orderer.BroadcastBatch on /broadcast/batch,
+// orderer.EnvelopeChunk on /broadcast/chunked and peer.Event on /events, and
+// receives the responses of the gRPC services: orderer.BroadcastResponse,
+// orderer.DeliverResponse, orderer.BroadcastBatchResponse,
+// orderer.BroadcastResponse and peer.Event respectively. The messages are
+// relayed as they are to a stream of the gRPC service, so that the signatures
+// of the clients are verified by the orderer and the peer exactly as if the
+// clients were connected to them directly: the gateway neither holds nor
+// needs any identity.
+package wsgateway
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+)
+
+var logger = logging.MustGetLogger("common/wsgateway")
+
+// The paths of the services
+const (
+	BroadcastPath        = "/broadcast"
+	BroadcastBatchPath   = "/broadcast/batch"
+	BroadcastChunkedPath = "/broadcast/chunked"
+	DeliverPath          = "/deliver"
+	EventsPath           = "/events"
+)
+
+// Config is the config of a Gateway
+type Config struct {
+	// AllowedOrigins are the origins of the web pages allowed to connect, any
+	// origin being allowed if empty. As every message is signed by the client,
+	// the origin only matters to keep pages of other sites from using the
+	// gateway on behalf of a browser
+	AllowedOrigins []string
+}
+
+// Gateway relays the WebSocket connections to the gRPC services
+type Gateway struct {
+	broadcast ab.AtomicBroadcastClient
+	events    pb.EventsClient
+	origins   map[string]struct{}
+	mux       *http.ServeMux
+}
+
+// New returns a Gateway relaying /broadcast, its variants and /deliver to
+// broadcast and /events to events. Either may be nil, its paths then not being served
+func New(conf Config, broadcast ab.AtomicBroadcastClient, events pb.EventsClient) *Gateway {
+	g := &Gateway{
+		broadcast: broadcast,
+		events:    events,
+		origins:   make(map[string]struct{}),
+		mux:       http.NewServeMux(),
+	}
+	for _, origin := range conf.AllowedOrigins {
+		g.origins[origin] = struct{}{}
+	}
+	if broadcast != nil {
+		g.mux.Handle(BroadcastPath, g.handler(g.relayBroadcast))
+		g.mux.Handle(BroadcastBatchPath, g.handler(g.relayBroadcastBatch))
+		g.mux.Handle(BroadcastChunkedPath, g.handler(g.relayBroadcastChunked))
+		g.mux.Handle(DeliverPath, g.handler(g.relayDeliver))
+	}
+	if events != nil {
+		g.mux.Handle(EventsPath, g.handler(g.relayEvents))
+	}
+	return g
+}
+
+// ServeHTTP serves the WebSocket connections
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	g.mux.ServeHTTP(w, req)
+}
+
+func (g *Gateway) handler(relay func(*websocket.Conn)) http.Handler {
+	return websocket.Server{Handshake: g.checkOrigin, Handler: relay}
+}
+
+// checkOrigin refuses the connections from the origins not allowed
+func (g *Gateway) checkOrigin(config *websocket.Config, req *http.Request) error {
+	if len(g.origins) == 0 {
+		return nil
+	}
+	origin, err := websocket.Origin(config, req)
+	if err != nil {
+		return err
+	}
+	if origin == nil {
+		return fmt.Errorf("Missing origin")
+	}
+	if _, ok := g.origins[(&url.URL{Scheme: origin.Scheme, Host: origin.Host}).String()]; !ok {
+		return fmt.Errorf("Origin %s not allowed", origin)
+	}
+	return nil
+}
+
+func (g *Gateway) relayBroadcast(ws *websocket.Conn) {
+	g.relay(ws, func(ctx context.Context) (grpc.ClientStream, error) {
+		return g.broadcast.Broadcast(ctx)
+	}, func() proto.Message { return &cb.Envelope{} }, func() proto.Message { return &ab.BroadcastResponse{} })
+}
+
+func (g *Gateway) relayBroadcastBatch(ws *websocket.Conn) {
+	g.relay(ws, func(ctx context.Context) (grpc.ClientStream, error) {
+		return g.broadcast.BroadcastBatch(ctx)
+	}, func() proto.Message { return &ab.BroadcastBatch{} }, func() proto.Message { return &ab.BroadcastBatchResponse{} })
+}
+
+func (g *Gateway) relayBroadcastChunked(ws *websocket.Conn) {
+	g.relay(ws, func(ctx context.Context) (grpc.ClientStream, error) {
+		return g.broadcast.BroadcastChunked(ctx)
+	}, func() proto.Message { return &ab.EnvelopeChunk{} }, func() proto.Message { return &ab.BroadcastResponse{} })
+}
+
+func (g *Gateway) relayDeliver(ws *websocket.Conn) {
+	g.relay(ws, func(ctx context.Context) (grpc.ClientStream, error) {
+		return g.broadcast.Deliver(ctx)
+	}, func() proto.Message { return &cb.Envelope{} }, func() proto.Message { return &ab.DeliverResponse{} })
+}
+
+func (g *Gateway) relayEvents(ws *websocket.Conn) {
+	g.relay(ws, func(ctx context.Context) (grpc.ClientStream, error) {
+		return g.events.Chat(ctx)
+	}, func() proto.Message { return &pb.Event{} }, func() proto.Message { return &pb.Event{} })
+}
+
+// relay opens a stream of the gRPC service for the WebSocket connection, and
+// relays the messages both ways until either side ends. The end of the
+// messages of the client half closes the stream, the end of the stream closes
+// the connection
+func (g *Gateway) relay(ws *websocket.Conn, open func(context.Context) (grpc.ClientStream, error), newRequest, newResponse func() proto.Message) {
+	defer ws.Close()
+	remote := ws.Request().RemoteAddr
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := open(ctx)
+	if err != nil {
+		logger.Warningf("Failed opening the stream of %s for %s: %s", ws.Request().URL.Path, remote, err)
+		return
+	}
+
+	go func() {
+		// cancelling the stream ends the relay of its responses
+		defer cancel()
+		for {
+			var frame string
+			if err := websocket.Message.Receive(ws, &frame); err != nil {
+				logger.Debugf("Connection of %s ended: %s", remote, err)
+				stream.CloseSend()
+				<-ctx.Done()
+				return
+			}
+			request := newRequest()
+			if err := jsonpb.UnmarshalString(frame, request); err != nil {
+				logger.Warningf("Closing the connection of %s, which sent a malformed message: %s", remote, err)
+				return
+			}
+			if err := stream.SendMsg(request); err != nil {
+				logger.Debugf("Failed relaying a message of %s: %s", remote, err)
+				return
+			}
+		}
+	}()
+
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	for {
+		response := newResponse()
+		if err := stream.RecvMsg(response); err != nil {
+			logger.Debugf("Stream of %s ended: %s", remote, err)
+			return
+		}
+		frame, err := marshaler.MarshalToString(response)
+		if err != nil {
+			logger.Errorf("Failed encoding a response to %s: %s", remote, err)
+			return
+		}
+		if err := websocket.Message.Send(ws, frame); err != nil {
+			logger.Debugf("Failed relaying a response to %s: %s", remote, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wsgateway
+
+import (
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+)
+
+type mockOrderer struct {
+	broadcast chan *cb.Envelope
+}
+
+func (o *mockOrderer) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	for {
+		env, err := srv.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		o.broadcast <- env
+		if err := srv.Send(&ab.BroadcastResponse{Status: cb.Status_SUCCESS}); err != nil {
+			return err
+		}
+	}
+}
+
+func (o *mockOrderer) BroadcastBatch(srv ab.AtomicBroadcast_BroadcastBatchServer) error {
+	batch, err := srv.Recv()
+	if err != nil {
+		return err
+	}
+	statuses := make([]cb.Status, len(batch.Envelopes))
+	for i := range statuses {
+		statuses[i] = cb.Status_SUCCESS
+	}
+	return srv.Send(&ab.BroadcastBatchResponse{Statuses: statuses})
+}
+
+func (o *mockOrderer) BroadcastChunked(srv ab.AtomicBroadcast_BroadcastChunkedServer) error {
+	return nil
+}
+
+func (o *mockOrderer) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	env, err := srv.Recv()
+	if err != nil {
+		return err
+	}
+	block := &cb.Block{Data: &cb.BlockData{Data: [][]byte{env.Payload}}}
+	if err := srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Block{Block: block}}); err != nil {
+		return err
+	}
+	return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: cb.Status_SUCCESS}})
+}
+
+type mockEvents struct{}
+
+func (mockEvents) Chat(srv pb.Events_ChatServer) error {
+	for {
+		evt, err := srv.Recv()
+		if err != nil {
+			return nil
+		}
+		if err := srv.Send(evt); err != nil {
+			return err
+		}
+	}
+}
+
+func newTestGateway(t *testing.T, conf Config) (*mockOrderer, *httptest.Server, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	orderer := &mockOrderer{broadcast: make(chan *cb.Envelope, 10)}
+	ab.RegisterAtomicBroadcastServer(grpcServer, orderer)
+	pb.RegisterEventsServer(grpcServer, mockEvents{})
+	go grpcServer.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.NoError(t, err)
+	server := httptest.NewServer(New(conf, ab.NewAtomicBroadcastClient(conn), pb.NewEventsClient(conn)))
+	return orderer, server, func() {
+		server.Close()
+		conn.Close()
+		grpcServer.Stop()
+	}
+}
+
+func dial(t *testing.T, server *httptest.Server, path, origin string) *websocket.Conn {
+	ws, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1)+path, "", origin)
+	assert.NoError(t, err)
+	return ws
+}
+
+func send(t *testing.T, ws *websocket.Conn, msg proto.Message) {
+	frame, err := (&jsonpb.Marshaler{}).MarshalToString(msg)
+	assert.NoError(t, err)
+	assert.NoError(t, websocket.Message.Send(ws, frame))
+}
+
+func receive(t *testing.T, ws *websocket.Conn, msg proto.Message) {
+	var frame string
+	assert.NoError(t, websocket.Message.Receive(ws, &frame))
+	assert.NoError(t, jsonpb.UnmarshalString(frame, msg))
+}
+
+func TestBroadcast(t *testing.T) {
+	orderer, server, stop := newTestGateway(t, Config{})
+	defer stop()
+
+	ws := dial(t, server, BroadcastPath, "http://localhost/")
+	defer ws.Close()
+	// the envelope is relayed byte for byte, its signature included
+	env := &cb.Envelope{Payload: []byte{0, 1, 2, 255}, Signature: []byte("signature")}
+	send(t, ws, env)
+	resp := &ab.BroadcastResponse{}
+	receive(t, ws, resp)
+	assert.Equal(t, cb.Status_SUCCESS, resp.Status)
+	assert.True(t, proto.Equal(env, <-orderer.broadcast))
+
+	// a malformed message closes the connection
+	assert.NoError(t, websocket.Message.Send(ws, "not a message"))
+	var frame string
+	assert.Error(t, websocket.Message.Receive(ws, &frame))
+}
+
+func TestBroadcastBatch(t *testing.T) {
+	_, server, stop := newTestGateway(t, Config{})
+	defer stop()
+
+	ws := dial(t, server, BroadcastBatchPath, "http://localhost/")
+	defer ws.Close()
+	send(t, ws, &ab.BroadcastBatch{Envelopes: []*cb.Envelope{{Payload: []byte("1")}, {Payload: []byte("2")}}})
+	resp := &ab.BroadcastBatchResponse{}
+	receive(t, ws, resp)
+	assert.Equal(t, []cb.Status{cb.Status_SUCCESS, cb.Status_SUCCESS}, resp.Statuses)
+}
+
+func TestDeliver(t *testing.T) {
+	_, server, stop := newTestGateway(t, Config{})
+	defer stop()
+
+	ws := dial(t, server, DeliverPath, "http://localhost/")
+	defer ws.Close()
+	send(t, ws, &cb.Envelope{Payload: []byte("seek"), Signature: []byte("signature")})
+	resp := &ab.DeliverResponse{}
+	receive(t, ws, resp)
+	assert.Equal(t, [][]byte{[]byte("seek")}, resp.GetBlock().Data.Data)
+	receive(t, ws, resp)
+	assert.Equal(t, cb.Status_SUCCESS, resp.GetStatus())
+
+	// the end of the stream closes the connection
+	var frame string
+	assert.Error(t, websocket.Message.Receive(ws, &frame))
+}
+
+func TestEvents(t *testing.T) {
+	_, server, stop := newTestGateway(t, Config{})
+	defer stop()
+
+	ws := dial(t, server, EventsPath, "http://localhost/")
+	defer ws.Close()
+	register := &pb.Event{Event: &pb.Event_Register{Register: &pb.Register{
+		Events: []*pb.Interest{{EventType: pb.EventType_BLOCK}},
+	}}, Creator: []byte("creator")}
+	send(t, ws, register)
+	evt := &pb.Event{}
+	receive(t, ws, evt)
+	assert.True(t, proto.Equal(register, evt))
+}
+
+func TestAllowedOrigins(t *testing.T) {
+	_, server, stop := newTestGateway(t, Config{AllowedOrigins: []string{"https://example.com"}})
+	defer stop()
+
+	url := strings.Replace(server.URL, "http", "ws", 1) + BroadcastPath
+	_, err := websocket.Dial(url, "", "https://attacker.com/")
+	assert.Error(t, err)
+	ws, err := websocket.Dial(url, "", "https://example.com/page")
+	assert.NoError(t, err)
+	ws.Close()
+}
+
+func TestServicesNotProvided(t *testing.T) {
+	server := httptest.NewServer(New(Config{}, nil, nil))
+	defer server.Close()
+
+	_, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1)+EventsPath, "", "http://localhost/")
+	assert.Error(t, err)
+}
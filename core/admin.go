@@ -17,6 +17,7 @@ limitations under the License.
 package core
 
 import (
+	"fmt"
 	"os"
 	"runtime"
 
@@ -26,6 +27,7 @@ import (
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/container/dockercontroller"
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -39,6 +41,13 @@ func NewAdminServer() *ServerAdmin {
 
 // ServerAdmin implementation of the Admin service for the Peer
 type ServerAdmin struct {
+	imageGC *dockercontroller.ImageGC
+}
+
+// SetImageGC sets the collector of the chaincode images used by
+// CollectChaincodeImages
+func (s *ServerAdmin) SetImageGC(gc *dockercontroller.ImageGC) {
+	s.imageGC = gc
 }
 
 func worker(id int, die chan struct{}) {
@@ -95,3 +104,17 @@ func (*ServerAdmin) SetModuleLogLevel(ctx context.Context, request *pb.LogLevelR
 
 	return logResponse, err
 }
+
+// CollectChaincodeImages removes the images and containers of the chaincode
+// versions no longer defined on any chain, or lists them on a dry run
+func (s *ServerAdmin) CollectChaincodeImages(ctx context.Context, request *pb.CollectImagesRequest) (*pb.CollectImagesResponse, error) {
+	if s.imageGC == nil {
+		return nil, fmt.Errorf("Chaincode image collection is not available on this peer")
+	}
+	res, err := s.imageGC.Collect(request.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Collected chaincode images (dry run %t): %v", request.DryRun, res)
+	return &pb.CollectImagesResponse{Images: res.Images, Containers: res.Containers, Retained: res.Retained}, nil
+}
@@ -64,6 +64,11 @@ func (c *ccProviderImpl) GetContext(ledger ledger.PeerLedger) (context.Context,
 	return ctxt, nil
 }
 
+// GetContextForSimulator returns a context with the supplied tx simulator, which the caller releases
+func (c *ccProviderImpl) GetContextForSimulator(txsim ledger.TxSimulator) context.Context {
+	return context.WithValue(context.Background(), TXSimulatorKey, txsim)
+}
+
 // GetCCContext returns an interface that encapsulates a
 // chaincode context; the interface is required to avoid
 // referencing the chaincode package from the interface definition
@@ -93,6 +98,11 @@ func (c *ccProviderImpl) GetCCValidationInfoFromLCCC(ctxt context.Context, txid
 	return data.Vscc, data.Policy, nil
 }
 
+// GetChaincodeDataFromLCCC returns the ChaincodeData listed in LCCC for the supplied chaincode
+func (c *ccProviderImpl) GetChaincodeDataFromLCCC(ctxt context.Context, txid string, prop *pb.Proposal, chainID string, chaincodeID string) (*ccprovider.ChaincodeData, error) {
+	return GetChaincodeDataFromLCCC(ctxt, txid, prop, chainID, chaincodeID)
+}
+
 // ExecuteChaincode executes the chaincode specified in the context with the specified arguments
 func (c *ccProviderImpl) ExecuteChaincode(ctxt context.Context, cccid interface{}, args [][]byte) (*pb.Response, *pb.ChaincodeEvent, error) {
 	return ExecuteChaincode(ctxt, cccid.(*ccProviderContextImpl).ctx, args)
@@ -54,6 +54,9 @@ const (
 
 	//HistoryQueryExecutorKey is used to attach ledger history query executor context
 	HistoryQueryExecutorKey string = "historyqueryexecutorkey"
+
+	//SignedProposalKey is used to attach the signed proposal being executed
+	SignedProposalKey string = "signedproposalkey"
 )
 
 //this is basically the singleton that supports the
@@ -81,6 +84,14 @@ func getHistoryQueryExecutor(context context.Context) ledger.HistoryQueryExecuto
 	return nil
 }
 
+//use this to pass the signature of the proposal on to the chaincode
+func getSignedProposal(context context.Context) *pb.SignedProposal {
+	if signedProp, ok := context.Value(SignedProposalKey).(*pb.SignedProposal); ok {
+		return signedProp
+	}
+	return nil
+}
+
 //
 //chaincode runtime environment encapsulates handler and container environment
 //This is where the VM that's running the chaincode would hook in
@@ -172,6 +183,14 @@ func NewChaincodeSupport(getPeerEndpoint func() (*pb.PeerEndpoint, error), userr
 		theChaincodeSupport.keepalive = time.Duration(t) * time.Second
 	}
 
+	if rt := viper.GetInt("chaincode.reconnecttimeout"); rt > 0 {
+		theChaincodeSupport.reconnectTimeout = time.Duration(rt) * time.Second
+	}
+
+	theChaincodeSupport.maxEventPayloadSize = viper.GetInt("chaincode.maxeventpayloadsize")
+
+	theChaincodeSupport.queryFetchSize = viper.GetInt("chaincode.queryfetchsize")
+
 	viper.SetEnvPrefix("CORE")
 	viper.AutomaticEnv()
 	replacer := strings.NewReplacer(".", "_")
@@ -210,7 +229,10 @@ type ChaincodeSupport struct {
 	peerTLSKeyFile       string
 	peerTLSSvrHostOrd    string
 	keepalive            time.Duration
+	reconnectTimeout     time.Duration
 	chaincodeLogLevel    string
+	maxEventPayloadSize  int
+	queryFetchSize       int
 }
 
 // DuplicateChaincodeHandlerError returned if attempt to register same chaincodeID while a stream already exists.
@@ -232,15 +254,30 @@ func (chaincodeSupport *ChaincodeSupport) registerHandler(chaincodehandler *Hand
 	chaincodeSupport.runningChaincodes.Lock()
 	defer chaincodeSupport.runningChaincodes.Unlock()
 
+	//now we are ready to receive messages and send back responses
+	chaincodehandler.txCtxs = make(map[string]*transactionContext)
+	chaincodehandler.txidMap = make(map[string]bool)
+
 	chrte2, ok := chaincodeSupport.chaincodeHasBeenLaunched(key)
 	if ok && chrte2.handler.registered == true {
-		chaincodeLogger.Debugf("duplicate registered handler(key:%s) return error", key)
-		// Duplicate, return error
-		return newDuplicateChaincodeHandlerError(chaincodehandler)
-	}
-	//a placeholder, unregistered handler will be setup by transaction processing that comes
-	//through via consensus. In this case we swap the handler and give it the notify channel
-	if chrte2 != nil {
+		waitingForResume := chrte2.handler.stopWaitingForResume()
+		if !chaincodeSupport.userRunsCC && !waitingForResume {
+			chaincodeLogger.Debugf("duplicate registered handler(key:%s) return error", key)
+			// Duplicate, return error
+			return newDuplicateChaincodeHandlerError(chaincodehandler)
+		}
+		//in dev mode a restarted chaincode process takes over the name from the
+		//previous process whose stream may not have been torn down yet, as does
+		//a chaincode registering again instead of resuming its broken stream.
+		//Transactions in flight on the old process are executed again by the
+		//new one, their callers waiting for its responses
+		chaincodeLogger.Infof("chaincode %s re-registered, replacing previous handler", key)
+		chaincodehandler.resync = chrte2.handler.handOverTxContexts(chaincodehandler, fmt.Sprintf("chaincode %s was restarted", key))
+		chrte2.handler.close()
+		chrte2.handler = chaincodehandler
+	} else if chrte2 != nil {
+		//a placeholder, unregistered handler will be setup by transaction processing that comes
+		//through via consensus. In this case we swap the handler and give it the notify channel
 		chaincodehandler.readyNotify = chrte2.handler.readyNotify
 		chrte2.handler = chaincodehandler
 	} else {
@@ -249,10 +286,6 @@ func (chaincodeSupport *ChaincodeSupport) registerHandler(chaincodehandler *Hand
 
 	chaincodehandler.registered = true
 
-	//now we are ready to receive messages and send back responses
-	chaincodehandler.txCtxs = make(map[string]*transactionContext)
-	chaincodehandler.txidMap = make(map[string]bool)
-
 	chaincodeLogger.Debugf("registered handler complete for chaincode %s", key)
 
 	return nil
@@ -260,21 +293,34 @@ func (chaincodeSupport *ChaincodeSupport) registerHandler(chaincodehandler *Hand
 
 func (chaincodeSupport *ChaincodeSupport) deregisterHandler(chaincodehandler *Handler) error {
 
-	// clean up queryIteratorMap
-	for _, context := range chaincodehandler.txCtxs {
-		for _, v := range context.queryIteratorMap {
-			v.Close()
+	key := chaincodehandler.ChaincodeID.Name
+
+	if chaincodeSupport.userRunsCC {
+		//the chaincode process went away, don't leave the transactions it was
+		//executing waiting for the timeout
+		chaincodehandler.abortTxContexts(fmt.Sprintf("chaincode %s disconnected", key))
+	} else {
+		// clean up queryIteratorMap
+		for _, context := range chaincodehandler.txCtxs {
+			for _, v := range context.queryIteratorMap {
+				v.Close()
+			}
 		}
 	}
 
-	key := chaincodehandler.ChaincodeID.Name
 	chaincodeLogger.Debugf("Deregister handler: %s", key)
 	chaincodeSupport.runningChaincodes.Lock()
 	defer chaincodeSupport.runningChaincodes.Unlock()
-	if _, ok := chaincodeSupport.chaincodeHasBeenLaunched(key); !ok {
+	chrte, ok := chaincodeSupport.chaincodeHasBeenLaunched(key)
+	if !ok {
 		// Handler NOT found
 		return fmt.Errorf("Error deregistering handler, could not find handler with key: %s", key)
 	}
+	if chrte.handler != chaincodehandler {
+		//the chaincode was re-registered by a new process (dev mode), leave it be
+		chaincodeLogger.Debugf("Handler with key %s has been replaced, not deregistering", key)
+		return nil
+	}
 	delete(chaincodeSupport.runningChaincodes.chaincodeMap, key)
 	chaincodeLogger.Debugf("Deregistered handler with key: %s", key)
 	return nil
@@ -353,6 +399,17 @@ func (chaincodeSupport *ChaincodeSupport) getArgsAndEnv(cccid *ccprovider.CCCont
 		envs = append(envs, "CORE_LOGGING_CHAINCODE="+chaincodeSupport.chaincodeLogLevel)
 	}
 
+	envs = append(envs, fmt.Sprintf("CORE_CHAINCODE_MAXEVENTPAYLOADSIZE=%d", chaincodeSupport.maxEventPayloadSize))
+
+	//the chaincode detects the peer stopped responding from the missing keepalives,
+	//and re-establishes its stream within the reconnect timeout
+	if chaincodeSupport.keepalive > 0 {
+		envs = append(envs, fmt.Sprintf("CORE_CHAINCODE_KEEPALIVE=%d", int(chaincodeSupport.keepalive/time.Second)))
+	}
+	if chaincodeSupport.reconnectTimeout > 0 {
+		envs = append(envs, fmt.Sprintf("CORE_CHAINCODE_RECONNECTTIMEOUT=%d", int(chaincodeSupport.reconnectTimeout/time.Second)))
+	}
+
 	switch cLang {
 	case pb.ChaincodeSpec_GOLANG, pb.ChaincodeSpec_CAR:
 		//chaincode executable will be same as the name of the chaincode
@@ -409,7 +466,7 @@ func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.
 
 	vmtype, _ := chaincodeSupport.getVMType(cds)
 
-	sir := container.StartImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID, Version: cccid.Version}, Builder: builder, Args: args, Env: env}
+	sir := container.StartImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID, Version: cccid.Version, NamespacePrefix: cccid.NamespacePrefix}, Builder: builder, Args: args, Env: env}
 
 	ipcCtxt := context.WithValue(ctxt, ccintf.GetCCHandlerKey(), chaincodeSupport)
 
@@ -452,7 +509,7 @@ func (chaincodeSupport *ChaincodeSupport) Stop(context context.Context, cccid *c
 	}
 
 	//stop the chaincode
-	sir := container.StopImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID, Version: cccid.Version}, Timeout: 0}
+	sir := container.StopImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID, Version: cccid.Version, NamespacePrefix: cccid.NamespacePrefix}, Timeout: 0}
 	// The line below is left for debugging. It replaces the line above to keep
 	// the chaincode container around to give you a chance to get data
 	//sir := container.StopImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID, ChainID: cccid.ChainID, Version: cccid.Version}, Timeout: 0, Dontremove: true}
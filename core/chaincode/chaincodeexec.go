@@ -22,9 +22,12 @@ import (
 	"fmt"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -73,6 +76,24 @@ func GetChaincodeDataFromLCCC(ctxt context.Context, txid string, prop *pb.Propos
 	return nil, err
 }
 
+// CheckInvocationPolicy returns an error unless the creator of the signed
+// proposal satisfies the invocation policy of the chaincode on chainID, if it
+// has one. The invocation policy is enforced by the endorsers alone, on the
+// invoked chaincode as on the chaincodes it calls
+func CheckInvocationPolicy(chainID string, signedData *common.SignedData, cd *ccprovider.ChaincodeData) error {
+	if cd == nil || cd.InvocationPolicy == nil {
+		return nil
+	}
+	if signedData == nil {
+		return fmt.Errorf("no signed proposal to evaluate the invocation policy against")
+	}
+	policy, err := cauthdsl.NewPolicyProvider(peer.GetMSPMgr(chainID)).NewPolicy(cd.InvocationPolicy)
+	if err != nil {
+		return fmt.Errorf("invalid invocation policy, %s", err)
+	}
+	return policy.Evaluate([]*common.SignedData{signedData})
+}
+
 // ExecuteChaincode executes a given chaincode given chaincode name and arguments
 func ExecuteChaincode(ctxt context.Context, cccid *ccprovider.CCContext, args [][]byte) (*pb.Response, *pb.ChaincodeEvent, error) {
 	var spec *pb.ChaincodeInvocationSpec
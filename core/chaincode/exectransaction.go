@@ -19,6 +19,7 @@ package chaincode
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -30,6 +31,16 @@ import (
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
+// droppedEventCount counts the chaincode events dropped because their payload
+// exceeded chaincode.maxeventpayloadsize
+var droppedEventCount uint64
+
+// DroppedEventCount returns the number of chaincode events dropped by the peer
+// because their payload exceeded the configured maximum size
+func DroppedEventCount() uint64 {
+	return atomic.LoadUint64(&droppedEventCount)
+}
+
 //Execute - execute proposal, return original response of chaincode
 func Execute(ctxt context.Context, cccid *ccprovider.CCContext, spec interface{}) (*pb.Response, *pb.ChaincodeEvent, error) {
 	var err error
@@ -80,8 +91,16 @@ func Execute(ctxt context.Context, cccid *ccprovider.CCContext, spec interface{}
 	}
 
 	if resp.ChaincodeEvent != nil {
-		resp.ChaincodeEvent.ChaincodeId = cccid.Name
-		resp.ChaincodeEvent.TxId = cccid.TxID
+		if max := theChaincodeSupport.maxEventPayloadSize; max > 0 && len(resp.ChaincodeEvent.Payload) > max {
+			// the shim refuses such events, so only a misbehaving chaincode gets here
+			chaincodeLogger.Warningf("Dropping event %s of chaincode %s (tx %s): payload of %d bytes exceeds the maximum of %d bytes",
+				resp.ChaincodeEvent.EventName, cccid.Name, cccid.TxID, len(resp.ChaincodeEvent.Payload), max)
+			atomic.AddUint64(&droppedEventCount, 1)
+			resp.ChaincodeEvent = nil
+		} else {
+			resp.ChaincodeEvent.ChaincodeId = cccid.Name
+			resp.ChaincodeEvent.TxId = cccid.TxID
+		}
 	}
 
 	if resp.Type == pb.ChaincodeMessage_COMPLETED {
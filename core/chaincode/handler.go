@@ -28,8 +28,12 @@ import (
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	ccintf "github.com/hyperledger/fabric/core/container/ccintf"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"github.com/hyperledger/fabric/core/detached"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/limits"
 	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/looplab/fsm"
@@ -59,10 +63,20 @@ type transactionContext struct {
 	responseNotifier chan *pb.ChaincodeMessage
 
 	// tracks open iterators used for range queries
-	queryIteratorMap map[string]commonledger.ResultsIterator
+	queryIteratorMap map[string]*queryCursor
 
 	txsimulator          ledger.TxSimulator
 	historyQueryExecutor ledger.HistoryQueryExecutor
+
+	// signedProposal is the proposal being executed with its signature, nil
+	// if the execution is not on behalf of a signed proposal
+	signedProposal *pb.SignedProposal
+
+	// request is the INIT or TRANSACTION message sent to the chaincode, and
+	// touched is set once the chaincode sent a message for the transaction,
+	// for the transaction to be sent again if the stream breaks before
+	request *pb.ChaincodeMessage
+	touched bool
 }
 
 type nextStateInfo struct {
@@ -80,7 +94,7 @@ type nextStateInfo struct {
 type ccParts struct {
 	name    string //the main name of the chaincode
 	version string //the version param if any (used for upgrade)
-	suffix  string //for now just the chain name
+	suffix  string //the chain name, or the namespace prefix of the chain for a registered chaincode
 }
 
 // Handler responsbile for management of Peer's side of chaincode stream
@@ -104,6 +118,23 @@ type Handler struct {
 
 	// used to do Send after making sure the state transition is complete
 	nextState chan *nextStateInfo
+
+	// resumeTimer is set while the stream of the handler is broken and the
+	// chaincode may resume its registration on a new stream
+	resumeTimer *time.Timer
+
+	// resumeNonce is sent to the chaincode in the REGISTERED message of its
+	// registration, which it resumes only with it
+	resumeNonce []byte
+
+	// resync holds the messages of the transactions handed over by the
+	// previous process of a restarted chaincode, to be sent again once the
+	// chaincode registered
+	resync []*pb.ChaincodeMessage
+
+	// done is closed once the handler no longer processes messages
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 func shorttxid(txid string) string {
@@ -155,6 +186,12 @@ func (handler *Handler) getCCRootName() string {
 	return handler.ccCompParts.name
 }
 
+//getNamespace returns the ledger namespace of the chaincode for the chain
+//of the transaction
+func (handler *Handler) getNamespace(txContext *transactionContext) string {
+	return ccprovider.Namespace(txContext.chainID, handler.getCCRootName())
+}
+
 //serialSend serializes msgs so gRPC will be happy
 func (handler *Handler) serialSend(msg *pb.ChaincodeMessage) error {
 	handler.serialLock.Lock()
@@ -192,10 +229,11 @@ func (handler *Handler) createTxContext(ctxt context.Context, chainID string, tx
 		return nil, fmt.Errorf("txid:%s exists", txid)
 	}
 	txctx := &transactionContext{chainID: chainID, proposal: prop, responseNotifier: make(chan *pb.ChaincodeMessage, 1),
-		queryIteratorMap: make(map[string]commonledger.ResultsIterator)}
+		queryIteratorMap: make(map[string]*queryCursor)}
 	handler.txCtxs[txid] = txctx
 	txctx.txsimulator = getTxSimulator(ctxt)
 	txctx.historyQueryExecutor = getHistoryQueryExecutor(ctxt)
+	txctx.signedProposal = getSignedProposal(ctxt)
 
 	return txctx, nil
 }
@@ -210,18 +248,89 @@ func (handler *Handler) deleteTxContext(txid string) {
 	handler.Lock()
 	defer handler.Unlock()
 	if handler.txCtxs != nil {
+		// release the iterators left open by a transaction which timed out
+		if txctx := handler.txCtxs[txid]; txctx != nil {
+			for _, v := range txctx.queryIteratorMap {
+				v.Close()
+			}
+		}
 		delete(handler.txCtxs, txid)
 	}
 }
 
+// abortTxContexts fails every transaction in flight on this handler with an
+// ERROR message carrying reason and closes their query iterators
+func (handler *Handler) abortTxContexts(reason string) {
+	handler.Lock()
+	defer handler.Unlock()
+	for txid, txctx := range handler.txCtxs {
+		abortTxContext(txid, txctx, reason)
+	}
+}
+
+// abortTxContext fails the transaction txid, the handler being locked
+func abortTxContext(txid string, txctx *transactionContext, reason string) {
+	for _, v := range txctx.queryIteratorMap {
+		v.Close()
+	}
+	chaincodeLogger.Debugf("[%s]aborting transaction: %s", shorttxid(txid), reason)
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(reason), Txid: txid}
+	select {
+	case txctx.responseNotifier <- msg:
+	default:
+		//a response has already been delivered
+	}
+}
+
+// handOverTxContexts moves the transactions in flight on handler to next,
+// the handler of the process the chaincode was restarted as, and returns
+// their INIT or TRANSACTION messages for next to send them again. The callers
+// keep waiting for the responses, which next delivers. The transactions
+// without such a message, as the launch ones, cannot be resent and are failed
+func (handler *Handler) handOverTxContexts(next *Handler, reason string) []*pb.ChaincodeMessage {
+	handler.Lock()
+	defer handler.Unlock()
+	var msgs []*pb.ChaincodeMessage
+	for txid, txctx := range handler.txCtxs {
+		if txctx.request == nil {
+			abortTxContext(txid, txctx, reason)
+			continue
+		}
+		txctx.touched = false
+		next.txCtxs[txid] = txctx
+		msgs = append(msgs, txctx.request)
+	}
+	handler.txCtxs = make(map[string]*transactionContext)
+	return msgs
+}
+
+// resyncTxContexts takes the restarted chaincode to the ready state and sends
+// it the messages of the transactions handed over by its previous process
+func (handler *Handler) resyncTxContexts(msgs []*pb.ChaincodeMessage) {
+	handler.triggerNextStateSync(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_READY})
+	for _, msg := range msgs {
+		chaincodeLogger.Debugf("[%s]sending %s again to the restarted chaincode", shorttxid(msg.Txid), msg.Type)
+		handler.triggerNextState(msg, true)
+	}
+}
+
+// touchTxContext records that the chaincode sent a message for txid
+func (handler *Handler) touchTxContext(txid string) {
+	handler.Lock()
+	defer handler.Unlock()
+	if txctx := handler.txCtxs[txid]; txctx != nil {
+		txctx.touched = true
+	}
+}
+
 func (handler *Handler) putQueryIterator(txContext *transactionContext, txid string,
-	queryIterator commonledger.ResultsIterator) {
+	queryIterator *queryCursor) {
 	handler.Lock()
 	defer handler.Unlock()
 	txContext.queryIteratorMap[txid] = queryIterator
 }
 
-func (handler *Handler) getQueryIterator(txContext *transactionContext, txid string) commonledger.ResultsIterator {
+func (handler *Handler) getQueryIterator(txContext *transactionContext, txid string) *queryCursor {
 	handler.Lock()
 	defer handler.Unlock()
 	return txContext.queryIteratorMap[txid]
@@ -233,6 +342,39 @@ func (handler *Handler) deleteQueryIterator(txContext *transactionContext, txid
 	delete(txContext.queryIteratorMap, txid)
 }
 
+// iterators counts the query iterators open on each channel
+var iterators = limits.NewCounter()
+
+// quotaIterator gives back the unit of the channel's iterator quota taken for
+// the wrapped iterator once it is closed. Closing it more than once is a no-op
+type quotaIterator struct {
+	commonledger.ResultsIterator
+	chainID string
+	once    sync.Once
+}
+
+func newQuotaIterator(iter commonledger.ResultsIterator, chainID string) commonledger.ResultsIterator {
+	return &quotaIterator{ResultsIterator: iter, chainID: chainID}
+}
+
+func (qi *quotaIterator) Close() {
+	qi.once.Do(func() {
+		qi.ResultsIterator.Close()
+		iterators.Release(qi.chainID)
+	})
+}
+
+// acquireIterator takes a unit of the iterator quota of the transaction's
+// channel, returning an ERROR message if the quota is exhausted
+func (handler *Handler) acquireIterator(txContext *transactionContext, msg *pb.ChaincodeMessage) *pb.ChaincodeMessage {
+	if iterators.TryAcquire(txContext.chainID, limits.ForChannel(txContext.chainID).Iterators) {
+		return nil
+	}
+	errMsg := fmt.Sprintf("Too many open query iterators on channel %s", txContext.chainID)
+	chaincodeLogger.Errorf("[%s]%s. Sending %s", shorttxid(msg.Txid), errMsg, pb.ChaincodeMessage_ERROR)
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(errMsg), Txid: msg.Txid}
+}
+
 // Check if the transactor is allow to call this chaincode on this channel
 func (handler *Handler) checkACL(proposal *pb.Proposal, calledCC *ccParts) *pb.ChaincodeMessage {
 	// TODO: Decide what to pass in to verify that this transactor can access this
@@ -243,6 +385,45 @@ func (handler *Handler) checkACL(proposal *pb.Proposal, calledCC *ccParts) *pb.C
 	return nil
 }
 
+// checkShimCapability returns an ERROR message if the channel of the
+// transaction has disabled the shim capability requested by msg
+func (handler *Handler) checkShimCapability(txContext *transactionContext, msg *pb.ChaincodeMessage) *pb.ChaincodeMessage {
+	appConfig := peer.GetApplicationConfig(txContext.chainID)
+	if appConfig == nil || !appConfig.ShimCapabilityDisabled(msg.Type.String()) {
+		return nil
+	}
+	errMsg := fmt.Sprintf("%s is disabled on channel %s", msg.Type, txContext.chainID)
+	chaincodeLogger.Errorf("[%s]%s. Sending %s", shorttxid(msg.Txid), errMsg, pb.ChaincodeMessage_ERROR)
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(errMsg), Txid: msg.Txid}
+}
+
+// checkInvocationPolicy returns an ERROR message unless the creator of the
+// proposal of the transaction satisfies the invocation policy of the called
+// chaincode cd on chainID
+func (handler *Handler) checkInvocationPolicy(txContext *transactionContext, msg *pb.ChaincodeMessage, chainID string, cd *ccprovider.ChaincodeData) *pb.ChaincodeMessage {
+	if cd == nil || cd.InvocationPolicy == nil {
+		return nil
+	}
+	var signedData *common.SignedData
+	if txContext.signedProposal != nil && txContext.proposal != nil {
+		hdr, err := utils.GetHeader(txContext.proposal.Header)
+		if err == nil && hdr.SignatureHeader != nil {
+			signedData = &common.SignedData{
+				Data:      txContext.signedProposal.ProposalBytes,
+				Identity:  hdr.SignatureHeader.Creator,
+				Signature: txContext.signedProposal.Signature,
+			}
+		}
+	}
+	err := CheckInvocationPolicy(chainID, signedData, cd)
+	if err == nil {
+		return nil
+	}
+	errMsg := fmt.Sprintf("Invocation of chaincode %s denied: %s", cd.Name, err)
+	chaincodeLogger.Errorf("[%s]%s. Sending %s", shorttxid(msg.Txid), errMsg, pb.ChaincodeMessage_ERROR)
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(errMsg), Txid: msg.Txid}
+}
+
 //THIS CAN BE REMOVED ONCE WE FULL SUPPORT (Invoke) CONFIDENTIALITY WITH CC-CALLING-CC
 //Only invocation are allowed
 func (handler *Handler) canCallChaincode(txid string, isQuery bool) *pb.ChaincodeMessage {
@@ -269,12 +450,18 @@ func (handler *Handler) deregister() error {
 
 func (handler *Handler) triggerNextState(msg *pb.ChaincodeMessage, send bool) {
 	//this will send Async
-	handler.nextState <- &nextStateInfo{msg: msg, sendToCC: send, sendSync: false}
+	select {
+	case handler.nextState <- &nextStateInfo{msg: msg, sendToCC: send, sendSync: false}:
+	case <-handler.done:
+	}
 }
 
 func (handler *Handler) triggerNextStateSync(msg *pb.ChaincodeMessage) {
 	//this will send sync
-	handler.nextState <- &nextStateInfo{msg: msg, sendToCC: true, sendSync: true}
+	select {
+	case handler.nextState <- &nextStateInfo{msg: msg, sendToCC: true, sendSync: true}:
+	case <-handler.done:
+	}
 }
 
 func (handler *Handler) waitForKeepaliveTimer() <-chan time.Time {
@@ -288,8 +475,15 @@ func (handler *Handler) waitForKeepaliveTimer() <-chan time.Time {
 }
 
 func (handler *Handler) processStream() error {
-	defer handler.deregister()
-	msgAvail := make(chan *pb.ChaincodeMessage)
+	err := handler.processMessages()
+	handler.streamEnded(err)
+	return err
+}
+
+func (handler *Handler) processMessages() error {
+	//buffered, for the receiving routine of a stream ended for want of keepalive
+	//to exit
+	msgAvail := make(chan *pb.ChaincodeMessage, 1)
 	var nsInfo *nextStateInfo
 	var in *pb.ChaincodeMessage
 	var err error
@@ -300,6 +494,7 @@ func (handler *Handler) processStream() error {
 
 	//catch send errors and bail now that sends aren't synchronous
 	errc := make(chan error, 1)
+	lastRecv := time.Now()
 	for {
 		in = nil
 		err = nil
@@ -339,6 +534,14 @@ func (handler *Handler) processStream() error {
 
 			// we can spin off another Recv again
 			recv = true
+			lastRecv = time.Now()
+
+			if in.Type == pb.ChaincodeMessage_RESUME && handler.FSM.Current() == createdstate {
+				// the chaincode resumes on this stream the registration of its
+				// broken stream, whose handler processes the messages from now on
+				return handler.chaincodeSupport.resumeHandler(handler.ChatStream, in)
+			}
+			handler.touchTxContext(in.Txid)
 
 			if in.Type == pb.ChaincodeMessage_KEEPALIVE {
 				chaincodeLogger.Debug("Received KEEPALIVE Response")
@@ -360,6 +563,14 @@ func (handler *Handler) processStream() error {
 				continue
 			}
 
+			//the chaincode answers every KEEPALIVE: without any message for a few of
+			//them, the stream is deemed broken
+			if time.Since(lastRecv) >= keepaliveMisses*handler.chaincodeSupport.keepalive {
+				err = fmt.Errorf("No message from chaincode in %s, ending chaincode support stream", time.Since(lastRecv))
+				chaincodeLogger.Errorf("%s", err)
+				return err
+			}
+
 			//if no error message from serialSend, KEEPALIVE happy, and don't care about error
 			//(maybe it'll work later)
 			handler.serialSendAsync(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_KEEPALIVE}, nil)
@@ -405,6 +616,7 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 	v.chaincodeSupport = chaincodeSupport
 	//we want this to block
 	v.nextState = make(chan *nextStateInfo)
+	v.done = make(chan struct{})
 
 	v.FSM = fsm.NewFSM(
 		createdstate,
@@ -420,6 +632,7 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			{Name: pb.ChaincodeMessage_GET_STATE_BY_RANGE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_QUERY_RESULT.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), Src: []string{readystate}, Dst: readystate},
+			{Name: pb.ChaincodeMessage_GET_STATE_AT_HEIGHT.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_QUERY_STATE_NEXT.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_QUERY_STATE_CLOSE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_ERROR.String(), Src: []string{readystate}, Dst: readystate},
@@ -434,6 +647,7 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			"after_" + pb.ChaincodeMessage_GET_STATE_BY_RANGE.String():  func(e *fsm.Event) { v.afterGetStateByRange(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_GET_QUERY_RESULT.String():    func(e *fsm.Event) { v.afterGetQueryResult(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(): func(e *fsm.Event) { v.afterGetHistoryForKey(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_GET_STATE_AT_HEIGHT.String(): func(e *fsm.Event) { v.afterGetStateAtHeight(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_QUERY_STATE_NEXT.String():    func(e *fsm.Event) { v.afterQueryStateNext(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_QUERY_STATE_CLOSE.String():   func(e *fsm.Event) { v.afterQueryStateClose(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_PUT_STATE.String():           func(e *fsm.Event) { v.enterBusyState(e, v.FSM.Current()) },
@@ -496,6 +710,16 @@ func (handler *Handler) beforeRegisterEvent(e *fsm.Event, state string) {
 		return
 	}
 
+	// the nonce proves a chaincode resuming the registration is the one which
+	// registered, rather than another process knowing its name. It is set
+	// before the handler is registered, and then read by resumeHandler
+	handler.resumeNonce, err = primitives.GetRandomNonce()
+	if err != nil {
+		e.Cancel(fmt.Errorf("Error generating the resume nonce: %s", err))
+		handler.notifyDuringStartup(false)
+		return
+	}
+
 	// Now register with the chaincodeSupport
 	handler.ChaincodeID = chaincodeID
 	err = handler.chaincodeSupport.registerHandler(handler)
@@ -510,11 +734,17 @@ func (handler *Handler) beforeRegisterEvent(e *fsm.Event, state string) {
 	handler.decomposeRegisteredName(handler.ChaincodeID)
 
 	chaincodeLogger.Debugf("Got %s for chaincodeID = %s, sending back %s", e.Event, chaincodeID, pb.ChaincodeMessage_REGISTERED)
-	if err := handler.serialSend(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTERED}); err != nil {
+	if err := handler.serialSend(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTERED, Payload: handler.resumeNonce}); err != nil {
 		e.Cancel(fmt.Errorf("Error sending %s: %s", pb.ChaincodeMessage_REGISTERED, err))
 		handler.notifyDuringStartup(false)
 		return
 	}
+
+	if len(handler.resync) > 0 {
+		//the state transitions are processed once this event completes
+		go handler.resyncTxContexts(handler.resync)
+		handler.resync = nil
+	}
 }
 
 func (handler *Handler) notify(msg *pb.ChaincodeMessage) {
@@ -604,7 +834,7 @@ func (handler *Handler) handleGetState(msg *pb.ChaincodeMessage) {
 		}
 
 		key := string(msg.Payload)
-		chaincodeID := handler.getCCRootName()
+		chaincodeID := handler.getNamespace(txContext)
 		if chaincodeLogger.IsEnabledFor(logging.DEBUG) {
 			chaincodeLogger.Debugf("[%s] getting state for chaincode %s, key %s, channel %s",
 				shorttxid(msg.Txid), chaincodeID, key, txContext.chainID)
@@ -636,8 +866,6 @@ func (handler *Handler) handleGetState(msg *pb.ChaincodeMessage) {
 	}()
 }
 
-const maxGetStateByRangeLimit = 100
-
 // afterGetStateByRange handles a GET_STATE_BY_RANGE request from the chaincode.
 func (handler *Handler) afterGetStateByRange(e *fsm.Event, state string) {
 	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
@@ -691,57 +919,26 @@ func (handler *Handler) handleGetStateByRange(msg *pb.ChaincodeMessage) {
 		if txContext == nil {
 			return
 		}
-		chaincodeID := handler.getCCRootName()
-
-		rangeIter, err := txContext.txsimulator.GetStateRangeScanIterator(chaincodeID, getStateByRange.StartKey, getStateByRange.EndKey)
-		if err != nil {
-			// Send error msg back to chaincode. GetState will not trigger event
-			payload := []byte(err.Error())
-			chaincodeLogger.Errorf("Failed to get ledger scan iterator. Sending %s", pb.ChaincodeMessage_ERROR)
-			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+		if serialSendMsg = handler.checkShimCapability(txContext, msg); serialSendMsg != nil {
 			return
 		}
+		chaincodeID := handler.getNamespace(txContext)
 
-		handler.putQueryIterator(txContext, iterID, rangeIter)
-
-		var keysAndValues []*pb.QueryStateKeyValue
-		var i = uint32(0)
-		var qresult commonledger.QueryResult
-		for ; i < maxGetStateByRangeLimit; i++ {
-			qresult, err = rangeIter.Next()
-			if err != nil {
-				chaincodeLogger.Errorf("Failed to get query result from iterator. Sending %s", pb.ChaincodeMessage_ERROR)
-				return
-			}
-			if qresult == nil {
-				break
-			}
-			kv := qresult.(*ledger.KV)
-			keyAndValue := pb.QueryStateKeyValue{Key: kv.Key, Value: kv.Value}
-			keysAndValues = append(keysAndValues, &keyAndValue)
-		}
-
-		if qresult != nil {
-			rangeIter.Close()
-			handler.deleteQueryIterator(txContext, iterID)
+		if serialSendMsg = handler.acquireIterator(txContext, msg); serialSendMsg != nil {
+			return
 		}
-
-		payload := &pb.QueryStateResponse{KeysAndValues: keysAndValues, HasMore: qresult != nil, Id: iterID}
-		payloadBytes, err := proto.Marshal(payload)
+		rangeIter, err := txContext.txsimulator.GetStateRangeScanIterator(chaincodeID, getStateByRange.StartKey, getStateByRange.EndKey)
 		if err != nil {
-			rangeIter.Close()
-			handler.deleteQueryIterator(txContext, iterID)
-
+			iterators.Release(txContext.chainID)
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(err.Error())
-			chaincodeLogger.Errorf("Failed marshall resopnse. Sending %s", pb.ChaincodeMessage_ERROR)
+			chaincodeLogger.Errorf("Failed to get ledger scan iterator. Sending %s", pb.ChaincodeMessage_ERROR)
 			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
 			return
 		}
-
-		chaincodeLogger.Debugf("Got keys and values. Sending %s", pb.ChaincodeMessage_RESPONSE)
-		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: msg.Txid}
-
+		cursor := newQueryCursor(newQuotaIterator(rangeIter, txContext.chainID))
+		handler.putQueryIterator(txContext, iterID, cursor)
+		serialSendMsg = handler.queryResponse(txContext, iterID, cursor, msg)
 	}()
 }
 
@@ -791,55 +988,19 @@ func (handler *Handler) handleQueryStateNext(msg *pb.ChaincodeMessage) {
 		}
 
 		txContext := handler.getTxContext(msg.Txid)
-		queryIter := handler.getQueryIterator(txContext, queryStateNext.Id)
+		var cursor *queryCursor
+		if txContext != nil {
+			cursor = handler.getQueryIterator(txContext, queryStateNext.Id)
+		}
 
-		if queryIter == nil {
+		if cursor == nil {
 			payload := []byte("query iterator not found")
 			chaincodeLogger.Errorf("query iterator not found. Sending %s", pb.ChaincodeMessage_ERROR)
 			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
 			return
 		}
 
-		var keysAndValues []*pb.QueryStateKeyValue
-		var i = uint32(0)
-
-		var qresult commonledger.QueryResult
-		var err error
-		for ; i < maxGetStateByRangeLimit; i++ {
-			qresult, err = queryIter.Next()
-			if err != nil {
-				chaincodeLogger.Errorf("Failed to get query result from iterator. Sending %s", pb.ChaincodeMessage_ERROR)
-				return
-			}
-			if qresult != nil {
-				break
-			}
-			kv := qresult.(*ledger.KV)
-			keyAndValue := pb.QueryStateKeyValue{Key: kv.Key, Value: kv.Value}
-			keysAndValues = append(keysAndValues, &keyAndValue)
-		}
-
-		if qresult != nil {
-			queryIter.Close()
-			handler.deleteQueryIterator(txContext, queryStateNext.Id)
-		}
-
-		payload := &pb.QueryStateResponse{KeysAndValues: keysAndValues, HasMore: qresult != nil, Id: queryStateNext.Id}
-		payloadBytes, err := proto.Marshal(payload)
-		if err != nil {
-			queryIter.Close()
-			handler.deleteQueryIterator(txContext, queryStateNext.Id)
-
-			// Send error msg back to chaincode. GetState will not trigger event
-			payload := []byte(err.Error())
-			chaincodeLogger.Errorf("Failed marshall resopnse. Sending %s", pb.ChaincodeMessage_ERROR)
-			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
-			return
-		}
-
-		chaincodeLogger.Debugf("Got keys and values. Sending %s", pb.ChaincodeMessage_RESPONSE)
-		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: msg.Txid}
-
+		serialSendMsg = handler.queryResponse(txContext, queryStateNext.Id, cursor, msg)
 	}()
 }
 
@@ -888,11 +1049,11 @@ func (handler *Handler) handleQueryStateClose(msg *pb.ChaincodeMessage) {
 			return
 		}
 
-		txContext := handler.getTxContext(msg.Txid)
-		iter := handler.getQueryIterator(txContext, queryStateClose.Id)
-		if iter != nil {
-			iter.Close()
-			handler.deleteQueryIterator(txContext, queryStateClose.Id)
+		if txContext := handler.getTxContext(msg.Txid); txContext != nil {
+			if cursor := handler.getQueryIterator(txContext, queryStateClose.Id); cursor != nil {
+				cursor.Close()
+				handler.deleteQueryIterator(txContext, queryStateClose.Id)
+			}
 		}
 
 		payload := &pb.QueryStateResponse{HasMore: false, Id: queryStateClose.Id}
@@ -912,8 +1073,6 @@ func (handler *Handler) handleQueryStateClose(msg *pb.ChaincodeMessage) {
 	}()
 }
 
-const maxGetQueryResultLimit = 100
-
 // afterGetQueryResult handles a GET_QUERY_RESULT request from the chaincode.
 func (handler *Handler) afterGetQueryResult(e *fsm.Event, state string) {
 	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
@@ -967,64 +1126,30 @@ func (handler *Handler) handleGetQueryResult(msg *pb.ChaincodeMessage) {
 		if txContext == nil {
 			return
 		}
-
-		chaincodeID := handler.getCCRootName()
-
-		executeIter, err := txContext.txsimulator.ExecuteQuery(chaincodeID, getQueryResult.Query)
-		if err != nil {
-			// Send error msg back to chaincode. GetState will not trigger event
-			payload := []byte(err.Error())
-			chaincodeLogger.Errorf("Failed to get ledger query iterator. Sending %s", pb.ChaincodeMessage_ERROR)
-			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+		if serialSendMsg = handler.checkShimCapability(txContext, msg); serialSendMsg != nil {
 			return
 		}
 
-		handler.putQueryIterator(txContext, iterID, executeIter)
+		chaincodeID := handler.getNamespace(txContext)
 
-		var keysAndValues []*pb.QueryStateKeyValue
-		var i = uint32(0)
-		var qresult commonledger.QueryResult
-		for ; i < maxGetQueryResultLimit; i++ {
-			qresult, err = executeIter.Next()
-			if err != nil {
-				chaincodeLogger.Errorf("Failed to get query result from iterator. Sending %s", pb.ChaincodeMessage_ERROR)
-				return
-			}
-			if qresult == nil {
-				break
-			}
-			queryRecord := qresult.(*ledger.QueryRecord)
-			keyAndValue := pb.QueryStateKeyValue{Key: queryRecord.Key, Value: queryRecord.Record}
-			keysAndValues = append(keysAndValues, &keyAndValue)
-		}
-
-		if qresult != nil {
-			executeIter.Close()
-			handler.deleteQueryIterator(txContext, iterID)
+		if serialSendMsg = handler.acquireIterator(txContext, msg); serialSendMsg != nil {
+			return
 		}
-
-		var payloadBytes []byte
-		payload := &pb.QueryStateResponse{KeysAndValues: keysAndValues, HasMore: qresult != nil, Id: iterID}
-		payloadBytes, err = proto.Marshal(payload)
+		executeIter, err := txContext.txsimulator.ExecuteQuery(chaincodeID, getQueryResult.Query)
 		if err != nil {
-			executeIter.Close()
-			handler.deleteQueryIterator(txContext, iterID)
-
+			iterators.Release(txContext.chainID)
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(err.Error())
-			chaincodeLogger.Errorf("Failed marshall resopnse. Sending %s", pb.ChaincodeMessage_ERROR)
+			chaincodeLogger.Errorf("Failed to get ledger query iterator. Sending %s", pb.ChaincodeMessage_ERROR)
 			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
 			return
 		}
-
-		chaincodeLogger.Debugf("Got keys and values. Sending %s", pb.ChaincodeMessage_RESPONSE)
-		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: msg.Txid}
-
+		cursor := newQueryCursor(newQuotaIterator(executeIter, txContext.chainID))
+		handler.putQueryIterator(txContext, iterID, cursor)
+		serialSendMsg = handler.queryResponse(txContext, iterID, cursor, msg)
 	}()
 }
 
-const maxGetHistoryForKeyLimit = 100
-
 // afterGetHistoryForKey handles a GET_HISTORY_FOR_KEY request from the chaincode.
 func (handler *Handler) afterGetHistoryForKey(e *fsm.Event, state string) {
 	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
@@ -1078,60 +1203,103 @@ func (handler *Handler) handleGetHistoryForKey(msg *pb.ChaincodeMessage) {
 		if txContext == nil {
 			return
 		}
-		chaincodeID := handler.getCCRootName()
+		if serialSendMsg = handler.checkShimCapability(txContext, msg); serialSendMsg != nil {
+			return
+		}
+		chaincodeID := handler.getNamespace(txContext)
 
+		if serialSendMsg = handler.acquireIterator(txContext, msg); serialSendMsg != nil {
+			return
+		}
 		historyIter, err := txContext.historyQueryExecutor.GetHistoryForKey(chaincodeID, getHistoryForKey.Key)
 		if err != nil {
+			iterators.Release(txContext.chainID)
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(err.Error())
 			chaincodeLogger.Errorf("Failed to get ledger history iterator. Sending %s", pb.ChaincodeMessage_ERROR)
 			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
 			return
 		}
+		cursor := newQueryCursor(newQuotaIterator(historyIter, txContext.chainID))
+		handler.putQueryIterator(txContext, iterID, cursor)
+		serialSendMsg = handler.queryResponse(txContext, iterID, cursor, msg)
+	}()
+}
 
-		handler.putQueryIterator(txContext, iterID, historyIter)
+// afterGetStateAtHeight handles a GET_STATE_AT_HEIGHT request from the chaincode.
+func (handler *Handler) afterGetStateAtHeight(e *fsm.Event, state string) {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(fmt.Errorf("Received unexpected message type"))
+		return
+	}
+	chaincodeLogger.Debugf("[%s]Received %s, invoking get state at height from ledger", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_STATE_AT_HEIGHT)
 
-		// TODO QueryStateKeyValue can be re-used for now since history records have a string (TxID)
-		// and value (value).  But we'll need to use another structure if we add other fields like timestamp.
-		var keysAndValues []*pb.QueryStateKeyValue
-		var i = uint32(0)
-		var qresult commonledger.QueryResult
-		for ; i < maxGetHistoryForKeyLimit; i++ {
-			qresult, err = historyIter.Next()
-			if err != nil {
-				chaincodeLogger.Errorf("Failed to get query result from iterator. Sending %s", pb.ChaincodeMessage_ERROR)
-				return
-			}
-			if qresult == nil {
-				break
-			}
-			queryRecord := qresult.(*ledger.KeyModification)
-			keyAndValue := pb.QueryStateKeyValue{Key: queryRecord.TxID, Value: queryRecord.Value}
-			keysAndValues = append(keysAndValues, &keyAndValue)
+	// Query ledger history db
+	handler.handleGetStateAtHeight(msg)
+}
+
+// Handles query to ledger history db to get the state of a key at a height
+func (handler *Handler) handleGetStateAtHeight(msg *pb.ChaincodeMessage) {
+	// The defer followed by triggering a go routine dance is needed to ensure that the previous state transition
+	// is completed before the next one is triggered. The previous state transition is deemed complete only when
+	// the afterGetStateAtHeight function is exited.
+	go func() {
+		// Check if this is the unique state request from this chaincode txid
+		uniqueReq := handler.createTXIDEntry(msg.Txid)
+		if !uniqueReq {
+			// Drop this request
+			chaincodeLogger.Error("Another state request pending for this Txid. Cannot process.")
+			return
 		}
 
-		if qresult != nil {
-			historyIter.Close()
-			handler.deleteQueryIterator(txContext, iterID)
+		var serialSendMsg *pb.ChaincodeMessage
+
+		defer func() {
+			handler.deleteTXIDEntry(msg.Txid)
+			chaincodeLogger.Debugf("[%s]handleGetStateAtHeight serial send %s", shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
+			handler.serialSendAsync(serialSendMsg, nil)
+		}()
+
+		getStateAtHeight := &pb.GetStateAtHeight{}
+		unmarshalErr := proto.Unmarshal(msg.Payload, getStateAtHeight)
+		if unmarshalErr != nil {
+			payload := []byte(unmarshalErr.Error())
+			chaincodeLogger.Errorf("Failed to unmarshall get state at height request. Sending %s", pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+			return
 		}
 
-		var payloadBytes []byte
-		payload := &pb.QueryStateResponse{KeysAndValues: keysAndValues, HasMore: qresult != nil, Id: iterID}
-		payloadBytes, err = proto.Marshal(payload)
-		if err != nil {
-			historyIter.Close()
-			handler.deleteQueryIterator(txContext, iterID)
+		var txContext *transactionContext
 
-			// Send error msg back to chaincode. GetState will not trigger event
-			payload := []byte(err.Error())
-			chaincodeLogger.Errorf("Failed marshall resopnse. Sending %s", pb.ChaincodeMessage_ERROR)
+		txContext, serialSendMsg = handler.isValidTxSim(msg.Txid, "[%s]No ledger context for GetStateAtHeight. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
+		if txContext == nil {
+			return
+		}
+		if serialSendMsg = handler.checkShimCapability(txContext, msg); serialSendMsg != nil {
+			return
+		}
+		if txContext.historyQueryExecutor == nil {
+			payload := []byte("History tracking not enabled - historyDatabase is false")
+			chaincodeLogger.Errorf("[%s]No history query executor for GetStateAtHeight. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
 			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
 			return
 		}
+		chaincodeID := handler.getNamespace(txContext)
 
-		chaincodeLogger.Debugf("Got keys and values. Sending %s", pb.ChaincodeMessage_RESPONSE)
-		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: msg.Txid}
+		res, err := txContext.historyQueryExecutor.GetStateAtHeight(chaincodeID, getStateAtHeight.Key, getStateAtHeight.Height)
+		if err != nil {
+			// Send error msg back to chaincode. GetStateAtHeight will not trigger event
+			payload := []byte(err.Error())
+			chaincodeLogger.Errorf("[%s]Failed to get chaincode state at height %d(%s). Sending %s",
+				shorttxid(msg.Txid), getStateAtHeight.Height, err, pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+			return
+		}
 
+		// a key which did not exist at the height is sent with an empty payload
+		chaincodeLogger.Debugf("[%s]Got state at height %d. Sending %s", shorttxid(msg.Txid), getStateAtHeight.Height, pb.ChaincodeMessage_RESPONSE)
+		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: res, Txid: msg.Txid}
 	}()
 }
 
@@ -1204,7 +1372,7 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			return
 		}
 
-		chaincodeID := handler.getCCRootName()
+		chaincodeID := handler.getNamespace(txContext)
 		var err error
 		var res []byte
 
@@ -1250,6 +1418,11 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 					shorttxid(msg.Txid), calledCcParts.name, calledCcParts.suffix)
 			}
 
+			triggerNextStateMsg = handler.checkShimCapability(txContext, msg)
+			if triggerNextStateMsg != nil {
+				return
+			}
+
 			triggerNextStateMsg = handler.checkACL(txContext.proposal, calledCcParts)
 			if triggerNextStateMsg != nil {
 				return
@@ -1279,6 +1452,9 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			}
 			ctxt = context.WithValue(ctxt, TXSimulatorKey, txsim)
 			ctxt = context.WithValue(ctxt, HistoryQueryExecutorKey, historyQueryExecutor)
+			if txContext.signedProposal != nil {
+				ctxt = context.WithValue(ctxt, SignedProposalKey, txContext.signedProposal)
+			}
 
 			if chaincodeLogger.IsEnabledFor(logging.DEBUG) {
 				chaincodeLogger.Debugf("[%s] calling lccc to get chaincode data for %s on channel %s",
@@ -1295,6 +1471,13 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 				triggerNextStateMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
 				return
 			}
+
+			// The creator of the proposal must be allowed to invoke the called
+			// chaincode as if it had invoked it directly
+			triggerNextStateMsg = handler.checkInvocationPolicy(txContext, msg, calledCcParts.suffix, cd)
+			if triggerNextStateMsg != nil {
+				return
+			}
 			cccid := ccprovider.NewCCContext(calledCcParts.suffix, calledCcParts.name, cd.Version, msg.Txid, false, txContext.proposal)
 
 			// Launch the new chaincode if not already running
@@ -1374,7 +1557,7 @@ func (handler *Handler) enterEndState(e *fsm.Event, state string) {
 	e.Cancel(fmt.Errorf("Entered end state"))
 }
 
-func (handler *Handler) setChaincodeProposal(prop *pb.Proposal, msg *pb.ChaincodeMessage) error {
+func (handler *Handler) setChaincodeProposal(ctxt context.Context, chainID string, prop *pb.Proposal, msg *pb.ChaincodeMessage) error {
 	chaincodeLogger.Debug("Setting chaincode proposal context...")
 	if prop != nil {
 		chaincodeLogger.Debug("Proposal different from nil. Creating chaincode proposal context...")
@@ -1385,11 +1568,56 @@ func (handler *Handler) setChaincodeProposal(prop *pb.Proposal, msg *pb.Chaincod
 			return fmt.Errorf("Failed getting proposal context from proposal [%s]", err)
 		}
 
+		if err = resolveDetachedPayloads(msg, proposalContext); err != nil {
+			return err
+		}
+		proposalContext.PreferredMaxBytes = preferredMaxBytes(chainID)
+		if signedProp := getSignedProposal(ctxt); signedProp != nil {
+			proposalContext.ProposalBytes = signedProp.ProposalBytes
+			proposalContext.Signature = signedProp.Signature
+		}
+
 		msg.ProposalContext = proposalContext
 	}
 	return nil
 }
 
+// resolveDetachedPayloads sets the contents of the detached payloads of the
+// input of an execute msg in proposalContext, when the peer resolves them
+func resolveDetachedPayloads(msg *pb.ChaincodeMessage, proposalContext *pb.ChaincodeProposalContext) error {
+	if msg.Type != pb.ChaincodeMessage_TRANSACTION && msg.Type != pb.ChaincodeMessage_INIT {
+		return nil
+	}
+	input := &pb.ChaincodeInput{}
+	if err := proto.Unmarshal(msg.Payload, input); err != nil || len(input.Detached) == 0 {
+		return nil
+	}
+	if err := detached.CheckReferences(input.Detached); err != nil {
+		return err
+	}
+	if !detached.Enabled() {
+		return nil
+	}
+	contents, err := detached.FetchAll(input.Detached)
+	if err != nil {
+		chaincodeLogger.Errorf("[%s]Failed resolving detached payloads: %s", shorttxid(msg.Txid), err)
+		return err
+	}
+	proposalContext.DetachedContents = contents
+	return nil
+}
+
+// preferredMaxBytes returns the preferred maximum size of the blocks of the
+// channel, for chaincodes batching writes to size their transactions to fit
+// a block, or 0 if the peer has no orderer config for the channel
+func preferredMaxBytes(chainID string) uint32 {
+	ordererConfig := peer.GetOrdererConfig(chainID)
+	if ordererConfig == nil || ordererConfig.BatchSize() == nil {
+		return 0
+	}
+	return ordererConfig.BatchSize().PreferredMaxBytes
+}
+
 //move to ready
 func (handler *Handler) ready(ctxt context.Context, chainID string, txid string, prop *pb.Proposal) (chan *pb.ChaincodeMessage, error) {
 	txctx, funcErr := handler.createTxContext(ctxt, chainID, txid, prop)
@@ -1401,7 +1629,7 @@ func (handler *Handler) ready(ctxt context.Context, chainID string, txid string,
 	ccMsg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_READY, Txid: txid}
 
 	//if security is disabled the context elements will just be nil
-	if err := handler.setChaincodeProposal(prop, ccMsg); err != nil {
+	if err := handler.setChaincodeProposal(ctxt, chainID, prop, ccMsg); err != nil {
 		return nil, err
 	}
 
@@ -1466,10 +1694,14 @@ func (handler *Handler) sendExecuteMessage(ctxt context.Context, chainID string,
 	}
 
 	//if security is disabled the context elements will just be nil
-	if err = handler.setChaincodeProposal(prop, msg); err != nil {
+	if err = handler.setChaincodeProposal(ctxt, chainID, prop, msg); err != nil {
 		return nil, err
 	}
 
+	handler.Lock()
+	txctx.request = msg
+	handler.Unlock()
+
 	chaincodeLogger.Debugf("[%s]sendExecuteMsg trigger event %s", shorttxid(msg.Txid), msg.Type)
 	handler.triggerNextState(msg, true)
 
@@ -0,0 +1,326 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/looplab/fsm"
+	"golang.org/x/net/context"
+)
+
+func newTestChaincodeSupport(userRunsCC bool) *ChaincodeSupport {
+	return &ChaincodeSupport{
+		runningChaincodes: &runningChaincodes{chaincodeMap: make(map[string]*chaincodeRTEnv)},
+		userRunsCC:        userRunsCC,
+	}
+}
+
+func newTestHandler(chaincodeSupport *ChaincodeSupport, name string) *Handler {
+	handler := newChaincodeSupportHandler(chaincodeSupport, nil)
+	handler.ChaincodeID = &pb.ChaincodeID{Name: name}
+	return handler
+}
+
+func TestRegisterDuplicateHandler(t *testing.T) {
+	chaincodeSupport := newTestChaincodeSupport(false)
+
+	if err := chaincodeSupport.registerHandler(newTestHandler(chaincodeSupport, "mycc:0")); err != nil {
+		t.Fatalf("Error registering handler: %s", err)
+	}
+
+	err := chaincodeSupport.registerHandler(newTestHandler(chaincodeSupport, "mycc:0"))
+	if _, ok := err.(*DuplicateChaincodeHandlerError); !ok {
+		t.Fatalf("Expected duplicate handler error, got %v", err)
+	}
+}
+
+func TestDevModeReregisterHandler(t *testing.T) {
+	chaincodeSupport := newTestChaincodeSupport(true)
+
+	oldHandler := newTestHandler(chaincodeSupport, "mycc:0")
+	if err := chaincodeSupport.registerHandler(oldHandler); err != nil {
+		t.Fatalf("Error registering handler: %s", err)
+	}
+
+	txctx, err := oldHandler.createTxContext(context.Background(), "mychain", "txid", nil)
+	if err != nil {
+		t.Fatalf("Error creating transaction context: %s", err)
+	}
+	request := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Txid: "txid"}
+	txctx.request = request
+	txctx.touched = true
+	launchctx, err := oldHandler.createTxContext(context.Background(), "mychain", "launch", nil)
+	if err != nil {
+		t.Fatalf("Error creating transaction context: %s", err)
+	}
+
+	newHandler := newTestHandler(chaincodeSupport, "mycc:0")
+	if err = chaincodeSupport.registerHandler(newHandler); err != nil {
+		t.Fatalf("Expected re-registration to succeed in dev mode, got %s", err)
+	}
+
+	// The transaction in flight is handed over to the new process, its
+	// caller still waiting for the response
+	if newHandler.getTxContext("txid") != txctx || oldHandler.getTxContext("txid") != nil {
+		t.Fatalf("Expected in-flight transaction to be handed over to the new handler")
+	}
+	if len(newHandler.resync) != 1 || newHandler.resync[0] != request || txctx.touched {
+		t.Fatalf("Expected in-flight transaction to be sent again, got %v", newHandler.resync)
+	}
+	select {
+	case msg := <-txctx.responseNotifier:
+		t.Fatalf("Expected in-flight transaction to keep waiting, got %s", msg.Type)
+	default:
+	}
+	// The transaction without a message to send again is failed
+	select {
+	case msg := <-launchctx.responseNotifier:
+		if msg.Type != pb.ChaincodeMessage_ERROR {
+			t.Fatalf("Expected launch transaction to be failed, got %s", msg.Type)
+		}
+	default:
+		t.Fatalf("Expected launch transaction to be notified")
+	}
+
+	// The old stream ending must not remove the new registration
+	if err = chaincodeSupport.deregisterHandler(oldHandler); err != nil {
+		t.Fatalf("Error deregistering old handler: %s", err)
+	}
+	chrte, ok := chaincodeSupport.chaincodeHasBeenLaunched("mycc:0")
+	if !ok || chrte.handler != newHandler {
+		t.Fatalf("Expected new handler to remain registered")
+	}
+
+	if err = chaincodeSupport.deregisterHandler(newHandler); err != nil {
+		t.Fatalf("Error deregistering new handler: %s", err)
+	}
+	if _, ok = chaincodeSupport.chaincodeHasBeenLaunched("mycc:0"); ok {
+		t.Fatalf("Expected handler to be deregistered")
+	}
+}
+
+// mockChaincodeStream records the messages sent and ends when read
+type mockChaincodeStream struct {
+	sync.Mutex
+	sent []*pb.ChaincodeMessage
+}
+
+func (s *mockChaincodeStream) Send(msg *pb.ChaincodeMessage) error {
+	s.Lock()
+	defer s.Unlock()
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func (s *mockChaincodeStream) Recv() (*pb.ChaincodeMessage, error) {
+	return nil, io.EOF
+}
+
+// newBrokenHandler returns the registered handler of a ready chaincode whose
+// stream just broke
+func newBrokenHandler(t *testing.T, chaincodeSupport *ChaincodeSupport) *Handler {
+	handler := newTestHandler(chaincodeSupport, "mycc:0")
+	if err := chaincodeSupport.registerHandler(handler); err != nil {
+		t.Fatalf("Error registering handler: %s", err)
+	}
+	handler.FSM = fsm.NewFSM(readystate, nil, nil)
+	handler.resumeNonce = []byte("nonce")
+	return handler
+}
+
+func resumeMessage(t *testing.T, txids ...string) *pb.ChaincodeMessage {
+	return resumeMessageWithNonce(t, []byte("nonce"), txids...)
+}
+
+func resumeMessageWithNonce(t *testing.T, nonce []byte, txids ...string) *pb.ChaincodeMessage {
+	payload, err := proto.Marshal(&pb.ChaincodeResume{ChaincodeId: &pb.ChaincodeID{Name: "mycc:0"}, Txids: txids, Nonce: nonce})
+	if err != nil {
+		t.Fatalf("Error marshaling resume: %s", err)
+	}
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESUME, Payload: payload}
+}
+
+func TestResumeChaincodeStream(t *testing.T) {
+	chaincodeSupport := newTestChaincodeSupport(false)
+	chaincodeSupport.reconnectTimeout = time.Minute
+	handler := newBrokenHandler(t, chaincodeSupport)
+
+	txctxs := make(map[string]*transactionContext)
+	for _, txid := range []string{"unsent", "touched", "executing"} {
+		txctx, err := handler.createTxContext(context.Background(), "mychain", txid, nil)
+		if err != nil {
+			t.Fatalf("Error creating transaction context: %s", err)
+		}
+		txctx.request = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Txid: txid}
+		txctxs[txid] = txctx
+	}
+	handler.touchTxContext("touched")
+	handler.touchTxContext("executing")
+
+	handler.streamEnded(errors.New("stream broken"))
+	if chrte, ok := chaincodeSupport.chaincodeHasBeenLaunched("mycc:0"); !ok || chrte.handler != handler {
+		t.Fatalf("Expected the handler to stay registered while waiting for the chaincode")
+	}
+
+	// the registration is only resumed with the nonce of the registration
+	for _, nonce := range [][]byte{nil, []byte("other")} {
+		stream := &mockChaincodeStream{}
+		if err := chaincodeSupport.resumeHandler(stream, resumeMessageWithNonce(t, nonce, "executing")); err == nil {
+			t.Fatalf("Expected the resume with nonce %q to be refused", nonce)
+		}
+		if len(stream.sent) != 1 || stream.sent[0].Type != pb.ChaincodeMessage_ERROR {
+			t.Fatalf("Expected an error to be sent, got %v", stream.sent)
+		}
+	}
+
+	stream := &mockChaincodeStream{}
+	if err := chaincodeSupport.resumeHandler(stream, resumeMessage(t, "executing")); err != io.EOF {
+		t.Fatalf("Expected the resumed stream to end with EOF, got %v", err)
+	}
+	if len(stream.sent) != 2 || stream.sent[0].Type != pb.ChaincodeMessage_RESUME || stream.sent[1].Txid != "unsent" {
+		t.Fatalf("Expected the resume to be acknowledged and the unsent transaction to be sent again, got %v", stream.sent)
+	}
+	select {
+	case msg := <-txctxs["touched"].responseNotifier:
+		if msg.Type != pb.ChaincodeMessage_ERROR {
+			t.Fatalf("Expected the touched transaction to be failed, got %s", msg.Type)
+		}
+	default:
+		t.Fatalf("Expected the touched transaction to be failed")
+	}
+	for _, txid := range []string{"unsent", "executing"} {
+		if len(txctxs[txid].responseNotifier) != 0 {
+			t.Fatalf("Expected transaction %s to be resumed", txid)
+		}
+	}
+
+	// the chaincode closing its resumed stream ends its registration
+	if _, ok := chaincodeSupport.chaincodeHasBeenLaunched("mycc:0"); ok {
+		t.Fatalf("Expected handler to be deregistered")
+	}
+}
+
+func TestResumeTimeout(t *testing.T) {
+	chaincodeSupport := newTestChaincodeSupport(false)
+	chaincodeSupport.reconnectTimeout = 50 * time.Millisecond
+	handler := newBrokenHandler(t, chaincodeSupport)
+	txctx, err := handler.createTxContext(context.Background(), "mychain", "txid", nil)
+	if err != nil {
+		t.Fatalf("Error creating transaction context: %s", err)
+	}
+
+	handler.streamEnded(errors.New("stream broken"))
+	select {
+	case msg := <-txctx.responseNotifier:
+		if msg.Type != pb.ChaincodeMessage_ERROR {
+			t.Fatalf("Expected the transaction to be failed, got %s", msg.Type)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the transaction to be failed after the reconnect timeout")
+	}
+	if _, ok := chaincodeSupport.chaincodeHasBeenLaunched("mycc:0"); ok {
+		t.Fatalf("Expected handler to be deregistered")
+	}
+
+	stream := &mockChaincodeStream{}
+	if err = chaincodeSupport.resumeHandler(stream, resumeMessage(t)); err == nil {
+		t.Fatalf("Expected the resume to be refused")
+	}
+	if len(stream.sent) != 1 || stream.sent[0].Type != pb.ChaincodeMessage_ERROR {
+		t.Fatalf("Expected an error to be sent, got %v", stream.sent)
+	}
+}
+
+func TestRegisterReplacesBrokenHandler(t *testing.T) {
+	chaincodeSupport := newTestChaincodeSupport(false)
+	chaincodeSupport.reconnectTimeout = time.Minute
+	handler := newBrokenHandler(t, chaincodeSupport)
+	handler.streamEnded(errors.New("stream broken"))
+
+	newHandler := newTestHandler(chaincodeSupport, "mycc:0")
+	if err := chaincodeSupport.registerHandler(newHandler); err != nil {
+		t.Fatalf("Expected the chaincode to register again instead of resuming, got %s", err)
+	}
+	if chrte, ok := chaincodeSupport.chaincodeHasBeenLaunched("mycc:0"); !ok || chrte.handler != newHandler {
+		t.Fatalf("Expected new handler to be registered")
+	}
+	if err := chaincodeSupport.resumeHandler(&mockChaincodeStream{}, resumeMessage(t)); err == nil {
+		t.Fatalf("Expected the resume of a replaced handler to be refused")
+	}
+}
+
+// mockNamespacePrefixes maps the chains to their chaincode namespace prefix
+type mockNamespacePrefixes map[string]string
+
+func (m mockNamespacePrefixes) NamespacePrefix(chainID string) string {
+	return m[chainID]
+}
+
+func TestNamespacePrefix(t *testing.T) {
+	ccprovider.RegisterNamespacePrefixSource(mockNamespacePrefixes{"tenantchain": "tenant1"})
+	defer ccprovider.RegisterNamespacePrefixSource(nil)
+
+	cccid := ccprovider.NewCCContext("tenantchain", "mycc", "0", "txid", false, nil)
+	if cccid.GetCanonicalName() != "mycc:0/tenant1" {
+		t.Fatalf("Expected the canonical name to carry the prefix, got %s", cccid.GetCanonicalName())
+	}
+	if cccid = ccprovider.NewCCContext("otherchain", "mycc", "0", "txid", false, nil); cccid.GetCanonicalName() != "mycc:0" {
+		t.Fatalf("Expected no prefix for a chain without one, got %s", cccid.GetCanonicalName())
+	}
+	if cccid = ccprovider.NewCCContext("tenantchain", "lccc", "0", "txid", true, nil); cccid.GetCanonicalName() != "lccc:0" {
+		t.Fatalf("Expected no prefix for a system chaincode, got %s", cccid.GetCanonicalName())
+	}
+
+	chaincodeSupport := newTestChaincodeSupport(false)
+	handler := newTestHandler(chaincodeSupport, "mycc:0/tenant1")
+	handler.decomposeRegisteredName(handler.ChaincodeID)
+	if handler.getCCRootName() != "mycc" {
+		t.Fatalf("Expected root name mycc, got %s", handler.getCCRootName())
+	}
+	for chainID, namespace := range map[string]string{"tenantchain": "tenant1/mycc", "otherchain": "mycc"} {
+		if ns := handler.getNamespace(&transactionContext{chainID: chainID}); ns != namespace {
+			t.Fatalf("Expected namespace %s on chain %s, got %s", namespace, chainID, ns)
+		}
+	}
+}
+
+func TestCheckInvocationPolicy(t *testing.T) {
+	handler := newTestHandler(newTestChaincodeSupport(false), "mycc:0")
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_INVOKE_CHAINCODE, Txid: "txid"}
+	txContext := &transactionContext{chainID: "testchain"}
+
+	if errMsg := handler.checkInvocationPolicy(txContext, msg, "testchain", &ccprovider.ChaincodeData{Name: "calledcc"}); errMsg != nil {
+		t.Fatalf("Expected a chaincode without invocation policy to be invocable, got %s", errMsg.Payload)
+	}
+
+	cd := &ccprovider.ChaincodeData{Name: "calledcc", InvocationPolicy: []byte("policy")}
+	errMsg := handler.checkInvocationPolicy(txContext, msg, "testchain", cd)
+	if errMsg == nil || errMsg.Type != pb.ChaincodeMessage_ERROR {
+		t.Fatalf("Expected the invocation to be denied without a signed proposal")
+	}
+	if errMsg.Txid != msg.Txid {
+		t.Fatalf("Expected the error to carry txid %s, got %s", msg.Txid, errMsg.Txid)
+	}
+}
@@ -211,5 +211,12 @@ func collectChaincodeFiles(spec *pb.ChaincodeSpec, tw *tar.Writer) (string, erro
 		return "", fmt.Errorf("Could not get hashcode for %s - %s\n", path, err)
 	}
 
+	if viper.GetBool("chaincode.golang.vendorcheck") {
+		autovendor := viper.GetBool("chaincode.golang.autovendor")
+		if err = validateDependencies(codegopath, actualcodepath, autovendor, tw); err != nil {
+			return "", fmt.Errorf("Error validating dependencies for %s - %s", path, err)
+		}
+	}
+
 	return hex.EncodeToString(hash[:]), nil
 }
@@ -0,0 +1,237 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"archive/tar"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cutil "github.com/hyperledger/fabric/core/container/util"
+)
+
+// fabricImportPath is provided by the chaincode build environment (ccenv) and
+// so never needs to be vendored by the chaincode
+const fabricImportPath = "github.com/hyperledger/fabric"
+
+// dependencyReport lists the packages imported (directly or transitively) by
+// a chaincode which are neither in the standard library, vendored with the
+// chaincode nor provided by the chaincode build environment
+type dependencyReport struct {
+	// Unvendored maps the import path of each package found only in the
+	// local GOPATH to its directory
+	Unvendored map[string]string
+
+	// Missing lists the import paths which could not be found at all
+	Missing []string
+}
+
+// empty returns true if all the dependencies of the chaincode are satisfied
+func (dr *dependencyReport) empty() bool {
+	return len(dr.Unvendored) == 0 && len(dr.Missing) == 0
+}
+
+// Error renders the report as a human readable error message
+func (dr *dependencyReport) Error() string {
+	var lines []string
+	if len(dr.Missing) > 0 {
+		lines = append(lines, fmt.Sprintf("packages not found: %s", strings.Join(dr.Missing, ", ")))
+	}
+	if len(dr.Unvendored) > 0 {
+		var unvendored []string
+		for importPath := range dr.Unvendored {
+			unvendored = append(unvendored, importPath)
+		}
+		sort.Strings(unvendored)
+		lines = append(lines, fmt.Sprintf("packages not vendored (found in GOPATH, set chaincode.golang.autovendor to include them): %s", strings.Join(unvendored, ", ")))
+	}
+	return "chaincode has unsatisfied dependencies; " + strings.Join(lines, "; ")
+}
+
+func isFabricPackage(importPath string) bool {
+	return importPath == fabricImportPath || strings.HasPrefix(importPath, fabricImportPath+"/")
+}
+
+// findVendored looks for importPath in the vendor directories of pkgDir and
+// its parents, stopping at srcRoot, the way the go tool resolves imports
+func findVendored(srcRoot, pkgDir, importPath string) (string, bool) {
+	for dir := pkgDir; strings.HasPrefix(dir, srcRoot); dir = filepath.Dir(dir) {
+		candidate := filepath.Join(dir, "vendor", filepath.FromSlash(importPath))
+		if exists, _ := pathExists(candidate); exists {
+			return candidate, true
+		}
+		if dir == srcRoot {
+			break
+		}
+	}
+	return "", false
+}
+
+// chaincodePackageDirs returns every package directory under the chaincode
+// path, skipping vendor and testdata directories
+func chaincodePackageDirs(ccDir string) ([]string, error) {
+	var dirs []string
+	err := filepath.Walk(ccDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		name := info.Name()
+		if path != ccDir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	return dirs, err
+}
+
+// checkDependencies walks the import graph of the chaincode at ccPath within
+// gopath and reports any packages the chaincode build environment will not be
+// able to resolve
+func checkDependencies(gopath, ccPath string) (*dependencyReport, error) {
+	ctxt := build.Default
+	ctxt.GOPATH = gopath
+	srcRoot := filepath.Join(gopath, "src")
+	ccDir := filepath.Join(srcRoot, filepath.FromSlash(ccPath))
+
+	dirs, err := chaincodePackageDirs(ccDir)
+	if err != nil {
+		return nil, fmt.Errorf("Error walking chaincode path %s: %s", ccPath, err)
+	}
+
+	report := &dependencyReport{Unvendored: make(map[string]string)}
+	missing := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	// queue holds package directories whose imports remain to be checked
+	queue := dirs
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		if visited[dir] {
+			continue
+		}
+		visited[dir] = true
+
+		pkg, err := ctxt.ImportDir(dir, 0)
+		if err != nil {
+			if _, ok := err.(*build.NoGoError); ok {
+				continue
+			}
+			return nil, fmt.Errorf("Error parsing package in %s: %s", dir, err)
+		}
+
+		for _, importPath := range pkg.Imports {
+			if importPath == "C" || isFabricPackage(importPath) {
+				continue
+			}
+			if importPath == ccPath || strings.HasPrefix(importPath, ccPath+"/") {
+				// packages of the chaincode itself are already queued
+				continue
+			}
+			if vendored, ok := findVendored(srcRoot, dir, importPath); ok {
+				queue = append(queue, vendored)
+				continue
+			}
+			found, err := ctxt.Import(importPath, "", build.FindOnly)
+			if err == nil && found.Goroot {
+				continue
+			}
+			if err != nil {
+				missing[importPath] = true
+				continue
+			}
+			report.Unvendored[importPath] = found.Dir
+			queue = append(queue, found.Dir)
+		}
+	}
+
+	for importPath := range missing {
+		report.Missing = append(report.Missing, importPath)
+	}
+	sort.Strings(report.Missing)
+
+	return report, nil
+}
+
+// writeVendoredPackages adds the packages found only in the local GOPATH to
+// the chaincode package under the chaincode's vendor directory
+func writeVendoredPackages(ccPath string, unvendored map[string]string, tw *tar.Writer) error {
+	var importPaths []string
+	for importPath := range unvendored {
+		importPaths = append(importPaths, importPath)
+	}
+	sort.Strings(importPaths)
+
+	for _, importPath := range importPaths {
+		dir := unvendored[importPath]
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("Error reading %s: %s", dir, err)
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			switch filepath.Ext(file.Name()) {
+			case ".go", ".c", ".h", ".s":
+			default:
+				continue
+			}
+			if strings.HasSuffix(file.Name(), "_test.go") {
+				continue
+			}
+			packagepath := filepath.ToSlash(filepath.Join("src", ccPath, "vendor", importPath, file.Name()))
+			logger.Debugf("auto-vendoring %s", packagepath)
+			if err = cutil.WriteFileToPackage(filepath.Join(dir, file.Name()), packagepath, tw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateDependencies checks that every package imported by the chaincode is
+// available to the chaincode build environment. When autovendor is set,
+// packages found only in the local GOPATH are written to the package rather
+// than being reported
+func validateDependencies(gopath, ccPath string, autovendor bool, tw *tar.Writer) error {
+	report, err := checkDependencies(gopath, ccPath)
+	if err != nil {
+		return err
+	}
+
+	if autovendor && len(report.Unvendored) > 0 {
+		if err = writeVendoredPackages(ccPath, report.Unvendored, tw); err != nil {
+			return fmt.Errorf("Error auto-vendoring dependencies: %s", err)
+		}
+		report.Unvendored = nil
+	}
+
+	if !report.empty() {
+		return report
+	}
+	return nil
+}
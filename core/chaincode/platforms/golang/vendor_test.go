@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeGoFile(t *testing.T, gopath, pkgPath, contents string) {
+	dir := filepath.Join(gopath, "src", filepath.FromSlash(pkgPath))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Error creating %s: %s", dir, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(contents), 0644); err != nil {
+		t.Fatalf("Error writing source in %s: %s", dir, err)
+	}
+}
+
+func setupGopath(t *testing.T) string {
+	gopath, err := ioutil.TempDir("", "vendorcheck")
+	if err != nil {
+		t.Fatalf("Error creating temporary GOPATH: %s", err)
+	}
+
+	writeGoFile(t, gopath, "example.com/cc", `package main
+
+import (
+	"fmt"
+
+	"example.com/cc/util"
+	"example.com/dep"
+	"example.com/vendoreddep"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() { fmt.Println(util.X, dep.X, vendoreddep.X, shim.OK) }
+`)
+	writeGoFile(t, gopath, "example.com/cc/util", "package util\n\nconst X = 1\n")
+	writeGoFile(t, gopath, "example.com/cc/vendor/example.com/vendoreddep", "package vendoreddep\n\nimport \"example.com/missing\"\n\nconst X = missing.X\n")
+	writeGoFile(t, gopath, "example.com/dep", "package dep\n\nconst X = 1\n")
+
+	return gopath
+}
+
+func TestCheckDependencies(t *testing.T) {
+	gopath := setupGopath(t)
+	defer os.RemoveAll(gopath)
+
+	report, err := checkDependencies(gopath, "example.com/cc")
+	if err != nil {
+		t.Fatalf("Error checking dependencies: %s", err)
+	}
+
+	if len(report.Missing) != 1 || report.Missing[0] != "example.com/missing" {
+		t.Fatalf("Expected example.com/missing to be reported missing, got %v", report.Missing)
+	}
+
+	if len(report.Unvendored) != 1 {
+		t.Fatalf("Expected exactly one unvendored package, got %v", report.Unvendored)
+	}
+	if _, ok := report.Unvendored["example.com/dep"]; !ok {
+		t.Fatalf("Expected example.com/dep to be reported unvendored, got %v", report.Unvendored)
+	}
+}
+
+func TestValidateDependenciesAutovendor(t *testing.T) {
+	gopath := setupGopath(t)
+	defer os.RemoveAll(gopath)
+
+	// Satisfy the transitive dependency so only the unvendored package remains
+	writeGoFile(t, gopath, "example.com/cc/vendor/example.com/missing", "package missing\n\nconst X = 1\n")
+
+	buf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buf)
+	if err := validateDependencies(gopath, "example.com/cc", false, tw); err == nil {
+		t.Fatalf("Expected validation to fail for unvendored package without autovendor")
+	}
+
+	if err := validateDependencies(gopath, "example.com/cc", true, tw); err != nil {
+		t.Fatalf("Expected validation to succeed with autovendor, got %s", err)
+	}
+	tw.Close()
+
+	tr := tar.NewReader(buf)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Error reading package: %s", err)
+		}
+		names = append(names, hdr.Name)
+	}
+
+	expected := "src/example.com/cc/vendor/example.com/dep/main.go"
+	if len(names) != 1 || names[0] != expected {
+		t.Fatalf("Expected package to contain only %s, got %v", expected, names)
+	}
+}
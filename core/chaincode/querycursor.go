@@ -0,0 +1,131 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/ledger"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// defaultQueryFetchSize is the number of query results per batch when
+// chaincode.queryfetchsize is not set
+const defaultQueryFetchSize = 100
+
+// queryCursor is the position of a chaincode in the results of a query. The
+// results are read from the ledger one batch at a time as the chaincode
+// asks for them with QUERY_STATE_NEXT, so that the peer never holds more
+// than a batch of the results of a query, whatever their number
+type queryCursor struct {
+	commonledger.ResultsIterator
+
+	// next is the result read ahead to tell whether there are more results
+	next   commonledger.QueryResult
+	peeked bool
+}
+
+func newQueryCursor(iter commonledger.ResultsIterator) *queryCursor {
+	return &queryCursor{ResultsIterator: iter}
+}
+
+// Next returns the next result, nil once there are no more
+func (c *queryCursor) Next() (commonledger.QueryResult, error) {
+	if c.peeked {
+		c.peeked = false
+		next := c.next
+		c.next = nil
+		return next, nil
+	}
+	return c.ResultsIterator.Next()
+}
+
+// hasMore returns whether Next has a result left to return
+func (c *queryCursor) hasMore() (bool, error) {
+	if !c.peeked {
+		next, err := c.ResultsIterator.Next()
+		if err != nil {
+			return false, err
+		}
+		c.next, c.peeked = next, true
+	}
+	return c.next != nil, nil
+}
+
+// getQueryFetchSize returns the number of query results per batch
+func (chaincodeSupport *ChaincodeSupport) getQueryFetchSize() int {
+	if chaincodeSupport.queryFetchSize <= 0 {
+		return defaultQueryFetchSize
+	}
+	return chaincodeSupport.queryFetchSize
+}
+
+// queryStateKeyValue returns the key and value the chaincode gets for a
+// result of a range, rich or history query
+func queryStateKeyValue(qresult commonledger.QueryResult) (*pb.QueryStateKeyValue, error) {
+	switch r := qresult.(type) {
+	case *ledger.KV:
+		return &pb.QueryStateKeyValue{Key: r.Key, Value: r.Value}, nil
+	case *ledger.QueryRecord:
+		return &pb.QueryStateKeyValue{Key: r.Key, Value: r.Record}, nil
+	case *ledger.KeyModification:
+		// TODO QueryStateKeyValue can be re-used for now since history records have a string (TxID)
+		// and value (value).  But we'll need to use another structure if we add other fields like timestamp.
+		return &pb.QueryStateKeyValue{Key: r.TxID, Value: r.Value}, nil
+	default:
+		return nil, fmt.Errorf("Unexpected query result type %T", qresult)
+	}
+}
+
+// queryResponse returns the RESPONSE to msg with the next batch of results of
+// the cursor iterID, or an ERROR message. The cursor is closed once it has no
+// more results, or on error
+func (handler *Handler) queryResponse(txContext *transactionContext, iterID string, cursor *queryCursor, msg *pb.ChaincodeMessage) *pb.ChaincodeMessage {
+	fetchSize := handler.chaincodeSupport.getQueryFetchSize()
+	keysAndValues := make([]*pb.QueryStateKeyValue, 0, fetchSize)
+	hasMore, err := cursor.hasMore()
+	for err == nil && hasMore && len(keysAndValues) < fetchSize {
+		var qresult commonledger.QueryResult
+		if qresult, err = cursor.Next(); err != nil {
+			break
+		}
+		var keyAndValue *pb.QueryStateKeyValue
+		if keyAndValue, err = queryStateKeyValue(qresult); err != nil {
+			break
+		}
+		keysAndValues = append(keysAndValues, keyAndValue)
+		hasMore, err = cursor.hasMore()
+	}
+
+	var payloadBytes []byte
+	if err == nil {
+		payloadBytes, err = proto.Marshal(&pb.QueryStateResponse{KeysAndValues: keysAndValues, HasMore: hasMore, Id: iterID})
+	}
+	if err != nil || !hasMore {
+		cursor.Close()
+		handler.deleteQueryIterator(txContext, iterID)
+	}
+	if err != nil {
+		chaincodeLogger.Errorf("[%s]Failed to get query results: %s. Sending %s", shorttxid(msg.Txid), err, pb.ChaincodeMessage_ERROR)
+		return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error()), Txid: msg.Txid}
+	}
+
+	chaincodeLogger.Debugf("[%s]Got %d keys and values, more: %t. Sending %s", shorttxid(msg.Txid), len(keysAndValues), hasMore, pb.ChaincodeMessage_RESPONSE)
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: msg.Txid}
+}
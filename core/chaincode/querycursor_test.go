@@ -0,0 +1,136 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/ledger"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"golang.org/x/net/context"
+)
+
+// mockResultsIterator returns count key/values, then err if any
+type mockResultsIterator struct {
+	count  int
+	read   int
+	err    error
+	closed bool
+}
+
+func (iter *mockResultsIterator) Next() (commonledger.QueryResult, error) {
+	if iter.read == iter.count {
+		return nil, iter.err
+	}
+	iter.read++
+	return &ledger.KV{Key: fmt.Sprintf("key%d", iter.read), Value: []byte("value")}, nil
+}
+
+func (iter *mockResultsIterator) Close() {
+	iter.closed = true
+}
+
+// queryBatches returns the number of results of every batch of the cursor
+// over iter, and the reads from the ledger ahead of each batch
+func queryBatches(t *testing.T, handler *Handler, txContext *transactionContext, iter *mockResultsIterator) (batches []int, readAhead []int) {
+	cursor := newQueryCursor(iter)
+	handler.putQueryIterator(txContext, "iterid", cursor)
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY_STATE_NEXT, Txid: "txid"}
+	for {
+		res := handler.queryResponse(txContext, "iterid", cursor, msg)
+		if res.Type != pb.ChaincodeMessage_RESPONSE {
+			t.Fatalf("Expected a response, got %s: %s", res.Type, string(res.Payload))
+		}
+		response := &pb.QueryStateResponse{}
+		if err := proto.Unmarshal(res.Payload, response); err != nil {
+			t.Fatalf("Error unmarshaling response: %s", err)
+		}
+		batches = append(batches, len(response.KeysAndValues))
+		readAhead = append(readAhead, iter.read-sum(batches))
+		if !response.HasMore {
+			return
+		}
+		if handler.getQueryIterator(txContext, "iterid") == nil {
+			t.Fatalf("Expected the cursor to stay open while there are more results")
+		}
+	}
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func TestQueryBatches(t *testing.T) {
+	chaincodeSupport := newTestChaincodeSupport(false)
+	chaincodeSupport.queryFetchSize = 2
+	handler := newTestHandler(chaincodeSupport, "mycc:0")
+	if err := chaincodeSupport.registerHandler(handler); err != nil {
+		t.Fatalf("Error registering handler: %s", err)
+	}
+	txContext, err := handler.createTxContext(context.Background(), "mychain", "txid", nil)
+	if err != nil {
+		t.Fatalf("Error creating transaction context: %s", err)
+	}
+
+	for count, expected := range map[int][]int{0: {0}, 3: {2, 1}, 4: {2, 2}, 5: {2, 2, 1}} {
+		iter := &mockResultsIterator{count: count}
+		batches, readAhead := queryBatches(t, handler, txContext, iter)
+		if fmt.Sprint(batches) != fmt.Sprint(expected) {
+			t.Fatalf("Expected batches %v of %d results, got %v", expected, count, batches)
+		}
+		// no more than a batch and the result telling whether there are more is read
+		for _, ahead := range readAhead {
+			if ahead > 1 {
+				t.Fatalf("Expected the results to be read one batch at a time, read %d ahead", ahead)
+			}
+		}
+		if !iter.closed || handler.getQueryIterator(txContext, "iterid") != nil {
+			t.Fatalf("Expected the cursor to be closed after the last batch of %d results", count)
+		}
+	}
+}
+
+func TestQueryBatchError(t *testing.T) {
+	chaincodeSupport := newTestChaincodeSupport(false)
+	handler := newTestHandler(chaincodeSupport, "mycc:0")
+	if err := chaincodeSupport.registerHandler(handler); err != nil {
+		t.Fatalf("Error registering handler: %s", err)
+	}
+	txContext, err := handler.createTxContext(context.Background(), "mychain", "txid", nil)
+	if err != nil {
+		t.Fatalf("Error creating transaction context: %s", err)
+	}
+
+	iter := &mockResultsIterator{count: 1, err: errors.New("ledger failure")}
+	cursor := newQueryCursor(iter)
+	handler.putQueryIterator(txContext, "iterid", cursor)
+	res := handler.queryResponse(txContext, "iterid", cursor, &pb.ChaincodeMessage{Txid: "txid"})
+	if res.Type != pb.ChaincodeMessage_ERROR {
+		t.Fatalf("Expected an error, got %s", res.Type)
+	}
+	if !iter.closed || handler.getQueryIterator(txContext, "iterid") != nil {
+		t.Fatalf("Expected the cursor to be closed on error")
+	}
+}
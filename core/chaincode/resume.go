@@ -0,0 +1,155 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// keepaliveMisses is the number of keepalive intervals without any message from
+// the other end after which a chaincode stream is deemed broken
+const keepaliveMisses = 3
+
+// streamEnded is called once the stream of the handler ended with err. Unless
+// the chaincode closed the stream or the reconnect timeout is 0, a handler
+// ready for invocations stays registered up to the reconnect timeout for the
+// chaincode to resume its registration on a new stream, the transactions in
+// flight waiting with it. Otherwise the handler is deregistered
+func (handler *Handler) streamEnded(err error) {
+	timeout := handler.chaincodeSupport.reconnectTimeout
+	if timeout <= 0 || err == io.EOF || !handler.registered || handler.FSM.Current() != readystate {
+		handler.deregister()
+		handler.close()
+		return
+	}
+
+	chaincodeLogger.Warningf("Stream of chaincode %s broken (%s), waiting %s for the chaincode to resume it", handler.ChaincodeID.Name, err, timeout)
+	handler.Lock()
+	defer handler.Unlock()
+	handler.resumeTimer = time.AfterFunc(timeout, func() {
+		if !handler.stopWaitingForResume() {
+			return
+		}
+		reason := fmt.Sprintf("chaincode %s did not resume its stream within %s", handler.ChaincodeID.Name, timeout)
+		chaincodeLogger.Errorf("%s", reason)
+		handler.deregister()
+		handler.close()
+		handler.abortTxContexts(reason)
+	})
+}
+
+// stopWaitingForResume returns whether the handler was waiting for its
+// chaincode to resume its stream, and stops waiting
+func (handler *Handler) stopWaitingForResume() bool {
+	handler.Lock()
+	defer handler.Unlock()
+	if handler.resumeTimer == nil {
+		return false
+	}
+	handler.resumeTimer.Stop()
+	handler.resumeTimer = nil
+	return true
+}
+
+// close releases the routines waiting for the handler to process their messages
+func (handler *Handler) close() {
+	handler.closeOnce.Do(func() {
+		if handler.done != nil {
+			close(handler.done)
+		}
+	})
+}
+
+// resumeHandler resumes on stream the registration of the chaincode of the
+// RESUME message msg, whose previous stream broke, and processes the messages
+// of the stream with the handler of the chaincode. The RESUME message must
+// carry the nonce sent to the chaincode when it registered
+func (chaincodeSupport *ChaincodeSupport) resumeHandler(stream ccintf.ChaincodeStream, msg *pb.ChaincodeMessage) error {
+	resume := &pb.ChaincodeResume{}
+	if err := proto.Unmarshal(msg.Payload, resume); err != nil || resume.ChaincodeId == nil {
+		return fmt.Errorf("Error in received %s, could NOT unmarshal resume info: %v", pb.ChaincodeMessage_RESUME, err)
+	}
+	key := resume.ChaincodeId.Name
+
+	chaincodeSupport.runningChaincodes.Lock()
+	chrte, ok := chaincodeSupport.chaincodeHasBeenLaunched(key)
+	chaincodeSupport.runningChaincodes.Unlock()
+	if ok && subtle.ConstantTimeCompare(resume.Nonce, chrte.handler.resumeNonce) != 1 {
+		err := fmt.Errorf("Chaincode %s did not resume its stream with the nonce of its registration", key)
+		chaincodeLogger.Warningf("%s", err)
+		stream.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error())})
+		return err
+	}
+	if !ok || !chrte.handler.stopWaitingForResume() {
+		err := fmt.Errorf("Chaincode %s has no broken stream to resume", key)
+		chaincodeLogger.Warningf("%s", err)
+		stream.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error())})
+		return err
+	}
+
+	chaincodeLogger.Infof("Chaincode %s resumed its stream", key)
+	return chrte.handler.resume(stream, resume.Txids)
+}
+
+// resume processes the messages of stream, the new stream of the chaincode
+// which is still executing the transactions executing. The transactions in
+// flight whose message the chaincode never got, as it sent nothing for them,
+// are sent again. The other transactions in flight the chaincode is not
+// executing anymore are failed, their result being lost with the stream
+func (handler *Handler) resume(stream ccintf.ChaincodeStream, executing []string) error {
+	handler.serialLock.Lock()
+	handler.ChatStream = stream
+	handler.serialLock.Unlock()
+
+	isExecuting := make(map[string]bool)
+	for _, txid := range executing {
+		isExecuting[txid] = true
+	}
+	var resend []*pb.ChaincodeMessage
+	handler.Lock()
+	for txid, txctx := range handler.txCtxs {
+		switch {
+		case isExecuting[txid]:
+		case !txctx.touched && txctx.request != nil:
+			resend = append(resend, txctx.request)
+		default:
+			abortTxContext(txid, txctx, fmt.Sprintf("stream of chaincode %s broke while executing the transaction", handler.ChaincodeID.Name))
+		}
+	}
+	handler.Unlock()
+
+	err := handler.serialSend(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESUME})
+	for _, msg := range resend {
+		if err != nil {
+			break
+		}
+		chaincodeLogger.Debugf("[%s]sending %s again on the resumed stream", shorttxid(msg.Txid), msg.Type)
+		err = handler.serialSend(msg)
+	}
+	if err != nil {
+		handler.streamEnded(err)
+		return err
+	}
+	return handler.processStream()
+}
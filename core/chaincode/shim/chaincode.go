@@ -19,12 +19,15 @@ limitations under the License.
 package shim
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/golang/protobuf/proto"
@@ -51,6 +54,7 @@ const (
 type ChaincodeStub struct {
 	TxID            string
 	proposalContext *pb.ChaincodeProposalContext
+	detached        []*pb.DetachedPayload
 	chaincodeEvent  *pb.ChaincodeEvent
 	args            [][]byte
 	handler         *Handler
@@ -88,8 +92,18 @@ func Start(cc Chaincode) error {
 
 	chaincodeSupportClient := pb.NewChaincodeSupportClient(clientConn)
 
+	// Every stream has a context of its own, canceled once the stream is
+	// abandoned for a new one
+	cancel := context.CancelFunc(func() {})
+	register := func() (PeerChaincodeStream, error) {
+		cancel()
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		return chaincodeSupportClient.Register(ctx)
+	}
+
 	// Establish stream with validating peer
-	stream, err := chaincodeSupportClient.Register(context.Background())
+	stream, err := register()
 	if err != nil {
 		return fmt.Errorf("Error chatting with leader at address=%s:  %s", getPeerAddress(), err)
 	}
@@ -98,7 +112,7 @@ func Start(cc Chaincode) error {
 	if chaincodename == "" {
 		return fmt.Errorf("Error chaincode id not provided")
 	}
-	err = chatWithPeer(chaincodename, stream, cc)
+	err = chatWithPeer(chaincodename, stream, cc, register)
 
 	return err
 }
@@ -151,7 +165,7 @@ func StartInProc(env []string, args []string, cc Chaincode, recv <-chan *pb.Chai
 	}
 	chaincodeLogger.Debugf("starting chat with peer using name=%s", chaincodename)
 	stream := newInProcStream(recv, send)
-	err := chatWithPeer(chaincodename, stream, cc)
+	err := chatWithPeer(chaincodename, stream, cc, nil)
 	return err
 }
 
@@ -175,12 +189,21 @@ func newPeerClientConnection() (*grpc.ClientConn, error) {
 	return comm.NewClientConnectionWithAddress(peerAddress, true, false, nil)
 }
 
-func chatWithPeer(chaincodename string, stream PeerChaincodeStream, cc Chaincode) error {
+// chatWithPeer registers the chaincode on stream and processes its messages.
+// If register is not nil and the peer set a reconnect timeout, a broken stream
+// is replaced by a new one from register, on which the registration resumes
+func chatWithPeer(chaincodename string, stream PeerChaincodeStream, cc Chaincode, register func() (PeerChaincodeStream, error)) error {
 
 	// Create the shim handler responsible for all control logic
 	handler := newChaincodeHandler(stream, cc)
+	if register != nil {
+		handler.keepalive = time.Duration(viper.GetInt("chaincode.keepalive")) * time.Second
+		handler.reconnectTimeout = time.Duration(viper.GetInt("chaincode.reconnecttimeout")) * time.Second
+	}
 
-	defer stream.CloseSend()
+	defer func() {
+		handler.ChatStream.CloseSend()
+	}()
 	// Send the ChaincodeID during register.
 	chaincodeID := &pb.ChaincodeID{Name: chaincodename}
 	payload, err := proto.Marshal(chaincodeID)
@@ -192,79 +215,16 @@ func chatWithPeer(chaincodename string, stream PeerChaincodeStream, cc Chaincode
 	if err = handler.serialSend(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTER, Payload: payload}); err != nil {
 		return fmt.Errorf("Error sending chaincode REGISTER: %s", err)
 	}
-	waitc := make(chan struct{})
-	errc := make(chan error)
-	go func() {
-		defer close(waitc)
-		msgAvail := make(chan *pb.ChaincodeMessage)
-		var nsInfo *nextStateInfo
-		var in *pb.ChaincodeMessage
-		recv := true
-		for {
-			in = nil
-			err = nil
-			nsInfo = nil
-			if recv {
-				recv = false
-				go func() {
-					var in2 *pb.ChaincodeMessage
-					in2, err = stream.Recv()
-					msgAvail <- in2
-				}()
-			}
-			select {
-			case sendErr := <-errc:
-				//serialSendAsync successful?
-				if sendErr == nil {
-					continue
-				}
-				//no, bail
-				err = fmt.Errorf("Error sending %s: %s", in.Type.String(), sendErr)
-				return
-			case in = <-msgAvail:
-				if err == io.EOF {
-					chaincodeLogger.Debugf("Received EOF, ending chaincode stream, %s", err)
-					return
-				} else if err != nil {
-					chaincodeLogger.Errorf("Received error from server: %s, ending chaincode stream", err)
-					return
-				} else if in == nil {
-					err = fmt.Errorf("Received nil message, ending chaincode stream")
-					chaincodeLogger.Debug("Received nil message, ending chaincode stream")
-					return
-				}
-				chaincodeLogger.Debugf("[%s]Received message %s from shim", shorttxid(in.Txid), in.Type.String())
-				recv = true
-			case nsInfo = <-handler.nextState:
-				in = nsInfo.msg
-				if in == nil {
-					panic("nil msg")
-				}
-				chaincodeLogger.Debugf("[%s]Move state message %s", shorttxid(in.Txid), in.Type.String())
-			}
-
-			// Call FSM.handleMessage()
-			err = handler.handleMessage(in)
-			if err != nil {
-				err = fmt.Errorf("Error handling message: %s", err)
-				return
-			}
-
-			//keepalive messages are PONGs to the fabric's PINGs
-			if (nsInfo != nil && nsInfo.sendToCC) || (in.Type == pb.ChaincodeMessage_KEEPALIVE) {
-				if in.Type == pb.ChaincodeMessage_KEEPALIVE {
-					chaincodeLogger.Debug("Sending KEEPALIVE response")
-					//ignore any errors, maybe next KEEPALIVE will work
-					handler.serialSendAsync(in, nil)
-				} else {
-					chaincodeLogger.Debugf("[%s]send state message %s", shorttxid(in.Txid), in.Type.String())
-					handler.serialSendAsync(in, errc)
-				}
-			}
+	for {
+		err = handler.chat()
+		if err == io.EOF || !handler.resumable() {
+			return err
 		}
-	}()
-	<-waitc
-	return err
+		chaincodeLogger.Warningf("Stream to peer broken (%s), resuming it", err)
+		if err = handler.resume(chaincodeID, register); err != nil {
+			return err
+		}
+	}
 }
 
 // -- init stub ---
@@ -273,6 +233,7 @@ func chatWithPeer(chaincodename string, stream PeerChaincodeStream, cc Chaincode
 func (stub *ChaincodeStub) init(handler *Handler, txid string, input *pb.ChaincodeInput, proposalContext *pb.ChaincodeProposalContext) {
 	stub.TxID = txid
 	stub.args = input.Args
+	stub.detached = input.Detached
 	stub.handler = handler
 	stub.proposalContext = proposalContext
 }
@@ -325,7 +286,9 @@ func (stub *ChaincodeStub) DelState(key string) error {
 }
 
 // StateQueryIterator allows a chaincode to iterate over a set of
-// key/value pairs in the state.
+// key/value pairs in the state. The key/value pairs are received from the
+// peer one batch at a time, the next batch being fetched once the chaincode
+// iterated past the current one.
 type StateQueryIterator struct {
 	handler    *Handler
 	uuid       string
@@ -369,6 +332,12 @@ func (stub *ChaincodeStub) GetHistoryForKey(key string) (StateQueryIteratorInter
 	return &StateQueryIterator{stub.handler, stub.TxID, response, 0}, nil
 }
 
+// GetStateAtHeight returns the value the key had once the blocks below
+// height were committed. GetStateAtHeight is intended to be used for read-only queries.
+func (stub *ChaincodeStub) GetStateAtHeight(key string, height uint64) ([]byte, error) {
+	return stub.handler.handleGetStateAtHeight(key, height, stub.TxID)
+}
+
 //CreateCompositeKey combines the given attributes to form a composite key.
 func (stub *ChaincodeStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
 	return createCompositeKey(objectType, attributes)
@@ -463,6 +432,9 @@ func (iter *StateQueryIterator) Next() (string, []byte, error) {
 
 		iter.currentLoc = 0
 		iter.response = response
+		if len(iter.response.KeysAndValues) == 0 {
+			return "", nil, errors.New("No such key")
+		}
 		keyValue := iter.response.KeysAndValues[iter.currentLoc]
 		iter.currentLoc++
 		return keyValue.Key, keyValue.Value, nil
@@ -471,8 +443,12 @@ func (iter *StateQueryIterator) Next() (string, []byte, error) {
 }
 
 // Close closes the range query iterator. This should be called when done
-// reading from the iterator to free up resources.
+// reading from the iterator to free up resources. The peer closes the
+// iterator itself once it sent the last batch of results.
 func (iter *StateQueryIterator) Close() error {
+	if !iter.response.HasMore {
+		return nil
+	}
 	_, err := iter.handler.handleQueryStateClose(iter.response.Id, iter.uuid)
 	return err
 }
@@ -505,6 +481,24 @@ func (stub *ChaincodeStub) GetFunctionAndParameters() (function string, params [
 	return
 }
 
+// GetCreator returns the serialized identity of the creator of the proposal
+func (stub *ChaincodeStub) GetCreator() ([]byte, error) {
+	if stub.proposalContext != nil {
+		return stub.proposalContext.Creator, nil
+	}
+
+	return nil, errors.New("Creator field not set.")
+}
+
+// GetSignedProposal returns the proposal being executed along with the
+// signature of its creator, as passed on by the endorsing peer
+func (stub *ChaincodeStub) GetSignedProposal() (*pb.SignedProposal, error) {
+	if stub.proposalContext == nil || stub.proposalContext.Signature == nil {
+		return nil, errors.New("Signed proposal not set.")
+	}
+	return &pb.SignedProposal{ProposalBytes: stub.proposalContext.ProposalBytes, Signature: stub.proposalContext.Signature}, nil
+}
+
 // GetCallerCertificate returns caller certificate
 func (stub *ChaincodeStub) GetCallerCertificate() ([]byte, error) {
 	if stub.proposalContext != nil {
@@ -541,17 +535,71 @@ func (stub *ChaincodeStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
 	return nil, nil
 }
 
+// GetDetachedPayloads returns the references to the detached payloads of the
+// invocation
+func (stub *ChaincodeStub) GetDetachedPayloads() []*pb.DetachedPayload {
+	return stub.detached
+}
+
+// GetDetachedPayload returns the content of the i-th detached payload of the
+// invocation, as resolved by the peer and verified against its hash
+func (stub *ChaincodeStub) GetDetachedPayload(i int) ([]byte, error) {
+	var contents [][]byte
+	if stub.proposalContext != nil {
+		contents = stub.proposalContext.DetachedContents
+	}
+	return detachedContent(stub.detached, contents, i)
+}
+
+// GetPreferredMaxBytes returns the preferred maximum size in bytes of the
+// blocks of the channel, as set by the endorsing peer
+func (stub *ChaincodeStub) GetPreferredMaxBytes() uint32 {
+	if stub.proposalContext == nil {
+		return 0
+	}
+	return stub.proposalContext.PreferredMaxBytes
+}
+
+// detachedContent returns the i-th of contents once verified against the hash
+// of the i-th of refs
+func detachedContent(refs []*pb.DetachedPayload, contents [][]byte, i int) ([]byte, error) {
+	if i < 0 || i >= len(refs) {
+		return nil, fmt.Errorf("No detached payload %d, the invocation has %d", i, len(refs))
+	}
+	if len(contents) != len(refs) {
+		return nil, fmt.Errorf("Detached payload %s not resolved, the peer does not resolve detached payloads", refs[i].Uri)
+	}
+	if hash := sha256.Sum256(contents[i]); !bytes.Equal(hash[:], refs[i].Hash) {
+		return nil, fmt.Errorf("Content of detached payload %s does not match its hash", refs[i].Uri)
+	}
+	return contents[i], nil
+}
+
 // ------------- ChaincodeEvent API ----------------------
 
-// SetEvent saves the event to be sent when a transaction is made part of a block
+// SetEvent saves the event to be sent when a transaction is made part of a block.
+// The payload may not exceed the size set by the peer in
+// chaincode.maxeventpayloadsize
 func (stub *ChaincodeStub) SetEvent(name string, payload []byte) error {
 	if name == "" {
 		return errors.New("Event name can not be nil string.")
 	}
+	if err := checkEventPayloadSize(name, payload); err != nil {
+		return err
+	}
 	stub.chaincodeEvent = &pb.ChaincodeEvent{EventName: name, Payload: payload}
 	return nil
 }
 
+// checkEventPayloadSize enforces the maximum event payload size configured by
+// the peer, which passes it to the chaincode in the environment
+func checkEventPayloadSize(name string, payload []byte) error {
+	if max := viper.GetInt("chaincode.maxeventpayloadsize"); max > 0 && len(payload) > max {
+		return fmt.Errorf("Event %s has a payload of %d bytes, exceeding the maximum of %d bytes", name, len(payload), max)
+	}
+	return nil
+}
+
 // ------------- Logging Control and Chaincode Loggers ---------------
 
 // As independent programs, Go language chaincodes can use any logging
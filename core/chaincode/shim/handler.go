@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	pb "github.com/hyperledger/fabric/protos/peer"
@@ -55,6 +56,23 @@ type Handler struct {
 	// responseChannel is the channel on which responses are communicated by the shim to the chaincodeStub.
 	responseChannel map[string]chan pb.ChaincodeMessage
 	nextState       chan *nextStateInfo
+
+	// pending are the requests awaiting their response, by txid, and
+	// executing are the transactions the chaincode executes, with the
+	// COMPLETED or ERROR message of their result until it is sent, for the
+	// registration of the chaincode to resume on a new stream
+	pending   map[string]*pb.ChaincodeMessage
+	executing map[string]*pb.ChaincodeMessage
+
+	// keepalive is the interval of the keepalives of the peer and
+	// reconnectTimeout is how long a broken stream is re-established for,
+	// 0 if it is not
+	keepalive        time.Duration
+	reconnectTimeout time.Duration
+
+	// resumeNonce is the nonce of the REGISTERED message of the peer, which
+	// the registration is resumed with
+	resumeNonce []byte
 }
 
 func shorttxid(txid string) string {
@@ -121,6 +139,9 @@ func (handler *Handler) sendChannel(msg *pb.ChaincodeMessage) error {
 
 //sends a message and selects
 func (handler *Handler) sendReceive(msg *pb.ChaincodeMessage, c chan pb.ChaincodeMessage) (pb.ChaincodeMessage, error) {
+	handler.setPending(msg)
+	defer handler.clearPending(msg.Txid)
+
 	errc := make(chan error, 1)
 	handler.serialSendAsync(msg, errc)
 
@@ -136,6 +157,11 @@ func (handler *Handler) sendReceive(msg *pb.ChaincodeMessage, c chan pb.Chaincod
 			if err == nil {
 				continue
 			}
+			if handler.resumable() {
+				//the request is sent again, or failed, once the stream is resumed
+				chaincodeLogger.Debugf("[%s]error sending %s, waiting for the stream to resume: %s", shorttxid(msg.Txid), msg.Type, err)
+				continue
+			}
 			//would have been logged, return false
 			return pb.ChaincodeMessage{}, err
 		case outmsg, val := <-c:
@@ -163,6 +189,8 @@ func newChaincodeHandler(peerChatStream PeerChaincodeStream, chaincode Chaincode
 	}
 	v.responseChannel = make(map[string]chan pb.ChaincodeMessage)
 	v.nextState = make(chan *nextStateInfo)
+	v.pending = make(map[string]*pb.ChaincodeMessage)
+	v.executing = make(map[string]*pb.ChaincodeMessage)
 
 	// Create the shim side FSM
 	v.FSM = fsm.NewFSM(
@@ -192,10 +220,14 @@ func newChaincodeHandler(peerChatStream PeerChaincodeStream, chaincode Chaincode
 
 // beforeRegistered is called to handle the REGISTERED message.
 func (handler *Handler) beforeRegistered(e *fsm.Event) {
-	if _, ok := e.Args[0].(*pb.ChaincodeMessage); !ok {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
 		e.Cancel(fmt.Errorf("Received unexpected message type"))
 		return
 	}
+	handler.Lock()
+	handler.resumeNonce = msg.Payload
+	handler.Unlock()
 	chaincodeLogger.Debugf("Received %s, ready for invocations", pb.ChaincodeMessage_REGISTERED)
 }
 
@@ -204,6 +236,7 @@ func (handler *Handler) handleInit(msg *pb.ChaincodeMessage) {
 	// The defer followed by triggering a go routine dance is needed to ensure that the previous state transition
 	// is completed before the next one is triggered. The previous state transition is deemed complete only when
 	// the beforeInit function is exited. Interesting bug fix!!
+	handler.startExecuting(msg.Txid)
 	go func() {
 		var nextStateMsg *pb.ChaincodeMessage
 
@@ -272,6 +305,7 @@ func (handler *Handler) handleTransaction(msg *pb.ChaincodeMessage) {
 	// The defer followed by triggering a go routine dance is needed to ensure that the previous state transition
 	// is completed before the next one is triggered. The previous state transition is deemed complete only when
 	// the beforeInit function is exited. Interesting bug fix!!
+	handler.startExecuting(msg.Txid)
 	go func() {
 		//better not be nil
 		var nextStateMsg *pb.ChaincodeMessage
@@ -734,6 +768,47 @@ func (handler *Handler) handleGetHistoryForKey(key string, txid string) (*pb.Que
 	return nil, errors.New("Incorrect chaincode message received")
 }
 
+// handleGetStateAtHeight communicates with the validator to fetch the state of a key at a height from the history database.
+func (handler *Handler) handleGetStateAtHeight(key string, height uint64, txid string) ([]byte, error) {
+	// Create the channel on which to communicate the response from validating peer
+	respChan, uniqueReqErr := handler.createChannel(txid)
+	if uniqueReqErr != nil {
+		chaincodeLogger.Debugf("[%s]Another state request pending for this Txid. Cannot process.", shorttxid(txid))
+		return nil, uniqueReqErr
+	}
+
+	defer handler.deleteChannel(txid)
+
+	// Send GET_STATE_AT_HEIGHT message to validator chaincode support
+	payload := &pb.GetStateAtHeight{Key: key, Height: height}
+	payloadBytes, err := proto.Marshal(payload)
+	if err != nil {
+		return nil, errors.New("Failed to process get state at height request")
+	}
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE_AT_HEIGHT, Payload: payloadBytes, Txid: txid}
+	chaincodeLogger.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_STATE_AT_HEIGHT)
+	responseMsg, err := handler.sendReceive(msg, respChan)
+	if err != nil {
+		chaincodeLogger.Errorf("[%s]error sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_STATE_AT_HEIGHT)
+		return nil, errors.New("could not send msg")
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		// Success response
+		chaincodeLogger.Debugf("[%s]GetStateAtHeight received payload %s", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_RESPONSE)
+		return responseMsg.Payload, nil
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		// Error response
+		chaincodeLogger.Errorf("[%s]GetStateAtHeight received error %s", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_ERROR)
+		return nil, errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	chaincodeLogger.Errorf("[%s]Incorrect chaincode message %s received. Expecting %s or %s", shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+	return nil, errors.New("Incorrect chaincode message received")
+}
+
 // handleInvokeChaincode communicates with the validator to invoke another chaincode.
 func (handler *Handler) handleInvokeChaincode(chaincodeName string, args [][]byte, txid string) pb.Response {
 	chaincodeID := &pb.ChaincodeID{Name: chaincodeName}
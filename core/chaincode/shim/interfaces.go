@@ -101,6 +101,20 @@ type ChaincodeStubInterface interface {
 	// key values across time. GetHistoryForKey is intended to be used for read-only queries.
 	GetHistoryForKey(key string) (StateQueryIteratorInterface, error)
 
+	// GetStateAtHeight returns the value the key had once the blocks below
+	// height were committed, nil if the key did not exist at that height.
+	// The read is not recorded in the read set of the transaction, so
+	// GetStateAtHeight is intended to be used for read-only queries.
+	GetStateAtHeight(key string, height uint64) ([]byte, error)
+
+	// GetCreator returns the serialized identity of the creator of the proposal
+	// being executed, whose signature was verified by the endorsing peer
+	GetCreator() ([]byte, error)
+
+	// GetSignedProposal returns the proposal being executed along with the
+	// signature of its creator, for the chaincode to evaluate policies
+	GetSignedProposal() (*pb.SignedProposal, error)
+
 	// GetCallerCertificate returns caller certificate
 	GetCallerCertificate() ([]byte, error)
 
@@ -119,6 +133,20 @@ type ChaincodeStubInterface interface {
 	// may not be the same with the other peers' time.
 	GetTxTimestamp() (*timestamp.Timestamp, error)
 
+	// GetDetachedPayloads returns the references to the detached payloads of
+	// the invocation, which the transaction carries in place of their content
+	GetDetachedPayloads() []*pb.DetachedPayload
+
+	// GetDetachedPayload returns the content of the i-th detached payload of
+	// the invocation, verified against its hash. It fails if the endorsing
+	// peer does not resolve detached payloads
+	GetDetachedPayload(i int) ([]byte, error)
+
+	// GetPreferredMaxBytes returns the preferred maximum size in bytes of the
+	// blocks of the channel, for chaincodes batching writes to size their
+	// transactions to fit a block, or 0 if the endorsing peer does not know it
+	GetPreferredMaxBytes() uint32
+
 	// SetEvent saves the event to be sent when a transaction is made part of a block
 	SetEvent(name string, payload []byte) error
 }
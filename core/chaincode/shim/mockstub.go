@@ -57,6 +57,21 @@ type MockStub struct {
 	// stores a transaction uuid while being Invoked / Deployed
 	// TODO if a chaincode uses recursion this may need to be a stack of TxIDs or possibly a reference counting map
 	TxID string
+
+	// Creator is returned as the serialized identity of the proposal creator
+	Creator []byte
+
+	// SignedProposal is returned as the proposal being executed
+	SignedProposal *pb.SignedProposal
+
+	// Detached are the references to the detached payloads of the invocation
+	// and DetachedContents their contents, in order, as resolved by the peer
+	Detached         []*pb.DetachedPayload
+	DetachedContents [][]byte
+
+	// PreferredMaxBytes is returned as the preferred maximum size of the
+	// blocks of the channel
+	PreferredMaxBytes uint32
 }
 
 func (stub *MockStub) GetTxID() string {
@@ -210,6 +225,12 @@ func (stub *MockStub) GetHistoryForKey(key string) (StateQueryIteratorInterface,
 	return nil, errors.New("Not Implemented")
 }
 
+// GetStateAtHeight returns the value the key had once the blocks below
+// height were committed. GetStateAtHeight is intended to be used for read-only queries.
+func (stub *MockStub) GetStateAtHeight(key string, height uint64) ([]byte, error) {
+	return nil, errors.New("Not Implemented")
+}
+
 //GetStateByPartialCompositeKey function can be invoked by a chaincode to query the
 //state based on a given partial composite key. This function returns an
 //iterator which can be used to iterate over all composite keys whose prefix
@@ -250,6 +271,16 @@ func (stub *MockStub) InvokeChaincode(chaincodeName string, args [][]byte, chann
 	return res
 }
 
+// GetCreator returns the Creator set on the stub
+func (stub *MockStub) GetCreator() ([]byte, error) {
+	return stub.Creator, nil
+}
+
+// GetSignedProposal returns the SignedProposal set on the stub
+func (stub *MockStub) GetSignedProposal() (*pb.SignedProposal, error) {
+	return stub.SignedProposal, nil
+}
+
 // Not implemented
 func (stub *MockStub) GetCallerCertificate() ([]byte, error) {
 	return nil, nil
@@ -275,9 +306,25 @@ func (stub *MockStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
 	return nil, nil
 }
 
+// GetDetachedPayloads returns the Detached references set on the stub
+func (stub *MockStub) GetDetachedPayloads() []*pb.DetachedPayload {
+	return stub.Detached
+}
+
+// GetDetachedPayload returns the i-th of the DetachedContents set on the stub,
+// verified against the hash of the i-th Detached reference
+func (stub *MockStub) GetDetachedPayload(i int) ([]byte, error) {
+	return detachedContent(stub.Detached, stub.DetachedContents, i)
+}
+
+// GetPreferredMaxBytes returns the PreferredMaxBytes set on the stub
+func (stub *MockStub) GetPreferredMaxBytes() uint32 {
+	return stub.PreferredMaxBytes
+}
+
 // Not implemented
 func (stub *MockStub) SetEvent(name string, payload []byte) error {
-	return nil
+	return checkEventPayloadSize(name, payload)
 }
 
 // Constructor to initialise the internal State map
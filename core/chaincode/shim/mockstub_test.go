@@ -17,11 +17,13 @@ limitations under the License.
 package shim
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
 
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/spf13/viper"
 )
 
@@ -69,6 +71,24 @@ func TestSetChaincodeLoggingLevel(t *testing.T) {
 	}
 }
 
+func TestSetEventPayloadSizeLimit(t *testing.T) {
+	viper.Set("chaincode.maxeventpayloadsize", 4)
+	defer viper.Set("chaincode.maxeventpayloadsize", 0)
+
+	stub := NewMockStub("eventTest", nil)
+	if err := stub.SetEvent("event", []byte("1234")); err != nil {
+		t.Fatalf("Expected event payload at the limit to be accepted, got %s", err)
+	}
+	if err := stub.SetEvent("event", []byte("12345")); err == nil {
+		t.Fatalf("Expected event payload over the limit to be rejected")
+	}
+
+	viper.Set("chaincode.maxeventpayloadsize", 0)
+	if err := stub.SetEvent("event", []byte("12345")); err != nil {
+		t.Fatalf("Expected event payload to be accepted without limit, got %s", err)
+	}
+}
+
 type Marble struct {
 	ObjectType string `json:"docType"` //docType is used to distinguish the various types of objects in state database
 	Name       string `json:"name"`    //the fieldtags are needed to keep case from bouncing around
@@ -143,3 +163,27 @@ func TestGetStateByPartialCompositeKey(t *testing.T) {
 		}
 	}
 }
+
+func TestGetDetachedPayload(t *testing.T) {
+	hash := sha256.Sum256([]byte("large document"))
+	stub := NewMockStub("detached", nil)
+	stub.Detached = []*pb.DetachedPayload{{Uri: "https://example.com/doc", Hash: hash[:]}}
+
+	if _, err := stub.GetDetachedPayload(0); err == nil {
+		t.Fatalf("Expected the payload not resolved by the peer to be unavailable")
+	}
+
+	stub.DetachedContents = [][]byte{[]byte("large document")}
+	content, err := stub.GetDetachedPayload(0)
+	if err != nil || string(content) != "large document" {
+		t.Fatalf("Expected the content of the payload, got %s, %v", content, err)
+	}
+	if _, err = stub.GetDetachedPayload(1); err == nil {
+		t.Fatalf("Expected an error getting a payload beyond those of the invocation")
+	}
+
+	stub.DetachedContents = [][]byte{[]byte("another document")}
+	if _, err = stub.GetDetachedPayload(0); err == nil {
+		t.Fatalf("Expected an error getting a content not matching its hash")
+	}
+}
@@ -16,7 +16,12 @@ limitations under the License.
 package shim
 
 import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	"google.golang.org/grpc/codes"
 )
 
 const (
@@ -41,3 +46,27 @@ func Error(msg string) pb.Response {
 		Message: msg,
 	}
 }
+
+// ErrorWithCode returns an error response with a gRPC style status code, so
+// that clients can tell business errors, such as codes.InvalidArgument or
+// codes.FailedPrecondition, from system errors, such as codes.Internal.
+// The details are returned to the client as typed protobuf Any messages
+func ErrorWithCode(code codes.Code, msg string, details ...proto.Message) pb.Response {
+	res := pb.Response{
+		Status:  ERROR,
+		Message: msg,
+		Code:    int32(code),
+	}
+	for _, detail := range details {
+		a, err := ptypes.MarshalAny(detail)
+		if err != nil {
+			return pb.Response{
+				Status:  ERROR,
+				Message: fmt.Sprintf("%s (error marshaling the error details: %s)", msg, err),
+				Code:    int32(codes.Internal),
+			}
+		}
+		res.Details = append(res.Details, a)
+	}
+	return res
+}
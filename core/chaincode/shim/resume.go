@@ -0,0 +1,250 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shim
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// keepaliveMisses is the number of keepalive intervals without any message from
+// the peer after which the stream to the peer is deemed broken
+const keepaliveMisses = 3
+
+// resumeRetryInterval is the interval at which the stream to the peer is
+// re-established until the reconnect timeout
+var resumeRetryInterval = time.Second
+
+// errResumeRefused is returned when the peer has no registration of the
+// chaincode to resume, for instance after the reconnect timeout
+var errResumeRefused = errors.New("Peer refused to resume the chaincode stream")
+
+// resumable returns whether a broken stream is re-established
+func (handler *Handler) resumable() bool {
+	return handler.reconnectTimeout > 0
+}
+
+// chat processes the messages of the stream to the peer and those of the
+// transactions executing until the stream ends, and returns why it ended
+func (handler *Handler) chat() error {
+	//buffered, for the routines of an abandoned stream to exit
+	msgAvail := make(chan *pb.ChaincodeMessage, 1)
+	errc := make(chan error, 1)
+	var nsInfo *nextStateInfo
+	var in *pb.ChaincodeMessage
+	var err error
+	recv := true
+	lastRecv := time.Now()
+	for {
+		in = nil
+		err = nil
+		nsInfo = nil
+		if recv {
+			recv = false
+			go func() {
+				var in2 *pb.ChaincodeMessage
+				in2, err = handler.ChatStream.Recv()
+				msgAvail <- in2
+			}()
+		}
+		select {
+		case sendErr := <-errc:
+			//serialSendAsync successful?
+			if sendErr == nil {
+				continue
+			}
+			//no, bail
+			return fmt.Errorf("Error sending message: %s", sendErr)
+		case in = <-msgAvail:
+			if err == io.EOF {
+				chaincodeLogger.Debugf("Received EOF, ending chaincode stream, %s", err)
+				return err
+			} else if err != nil {
+				chaincodeLogger.Errorf("Received error from server: %s, ending chaincode stream", err)
+				return err
+			} else if in == nil {
+				chaincodeLogger.Debug("Received nil message, ending chaincode stream")
+				return fmt.Errorf("Received nil message, ending chaincode stream")
+			}
+			chaincodeLogger.Debugf("[%s]Received message %s from shim", shorttxid(in.Txid), in.Type.String())
+			recv = true
+			lastRecv = time.Now()
+		case nsInfo = <-handler.nextState:
+			in = nsInfo.msg
+			if in == nil {
+				panic("nil msg")
+			}
+			chaincodeLogger.Debugf("[%s]Move state message %s", shorttxid(in.Txid), in.Type.String())
+		case <-handler.keepaliveTimeout(lastRecv):
+			return fmt.Errorf("No message from peer in %s, ending chaincode stream", time.Since(lastRecv))
+		}
+
+		// Call FSM.handleMessage()
+		err = handler.handleMessage(in)
+		if err != nil {
+			return fmt.Errorf("Error handling message: %s", err)
+		}
+
+		//keepalive messages are PONGs to the fabric's PINGs
+		if (nsInfo != nil && nsInfo.sendToCC) || (in.Type == pb.ChaincodeMessage_KEEPALIVE) {
+			if in.Type == pb.ChaincodeMessage_KEEPALIVE {
+				chaincodeLogger.Debug("Sending KEEPALIVE response")
+				//ignore any errors, maybe next KEEPALIVE will work
+				handler.serialSendAsync(in, nil)
+			} else {
+				chaincodeLogger.Debugf("[%s]send state message %s", shorttxid(in.Txid), in.Type.String())
+				handler.sendResult(in, errc)
+			}
+		}
+	}
+}
+
+// keepaliveTimeout returns a channel signaled once the stream is deemed
+// broken for want of a message from the peer since lastRecv, if the stream
+// is resumable and the peer sends keepalives
+func (handler *Handler) keepaliveTimeout(lastRecv time.Time) <-chan time.Time {
+	if !handler.resumable() || handler.keepalive <= 0 {
+		return nil
+	}
+	return time.After(keepaliveMisses*handler.keepalive - time.Since(lastRecv))
+}
+
+// sendResult sends asynchronously msg, the result of a transaction, which
+// is sent again if the stream breaks before
+func (handler *Handler) sendResult(msg *pb.ChaincodeMessage, errc chan error) {
+	handler.Lock()
+	if _, ok := handler.executing[msg.Txid]; ok {
+		handler.executing[msg.Txid] = msg
+	}
+	handler.Unlock()
+
+	go func() {
+		err := handler.serialSend(msg)
+		if err == nil {
+			handler.Lock()
+			if handler.executing[msg.Txid] == msg {
+				delete(handler.executing, msg.Txid)
+			}
+			handler.Unlock()
+		}
+		errc <- err
+	}()
+}
+
+func (handler *Handler) startExecuting(txid string) {
+	handler.Lock()
+	defer handler.Unlock()
+	handler.executing[txid] = nil
+}
+
+func (handler *Handler) setPending(msg *pb.ChaincodeMessage) {
+	handler.Lock()
+	defer handler.Unlock()
+	handler.pending[msg.Txid] = msg
+}
+
+func (handler *Handler) clearPending(txid string) {
+	handler.Lock()
+	defer handler.Unlock()
+	delete(handler.pending, txid)
+}
+
+// resume re-establishes the stream to the peer with register, until the
+// reconnect timeout, and resumes the registration of chaincodeID on it
+func (handler *Handler) resume(chaincodeID *pb.ChaincodeID, register func() (PeerChaincodeStream, error)) error {
+	deadline := time.Now().Add(handler.reconnectTimeout)
+	for {
+		err := handler.tryResume(chaincodeID, register)
+		if err == nil {
+			chaincodeLogger.Infof("Stream to peer resumed")
+			return nil
+		}
+		if err == errResumeRefused || time.Now().After(deadline) {
+			return fmt.Errorf("Could not resume the chaincode stream: %s", err)
+		}
+		chaincodeLogger.Warningf("Could not resume the chaincode stream, retrying: %s", err)
+		time.Sleep(resumeRetryInterval)
+	}
+}
+
+// tryResume opens a new stream and resumes the registration on it, with
+// the transactions executing. The results of the transactions not sent and
+// the requests awaiting their response are sent again, but for the requests
+// which cannot be repeated safely, which fail
+func (handler *Handler) tryResume(chaincodeID *pb.ChaincodeID, register func() (PeerChaincodeStream, error)) error {
+	stream, err := register()
+	if err != nil {
+		return err
+	}
+
+	handler.RLock()
+	resume := &pb.ChaincodeResume{ChaincodeId: chaincodeID, Nonce: handler.resumeNonce}
+	var resend []*pb.ChaincodeMessage
+	for txid, result := range handler.executing {
+		resume.Txids = append(resume.Txids, txid)
+		if result != nil {
+			resend = append(resend, result)
+		}
+	}
+	var failed []*pb.ChaincodeMessage
+	for _, msg := range handler.pending {
+		switch msg.Type {
+		case pb.ChaincodeMessage_QUERY_STATE_NEXT, pb.ChaincodeMessage_QUERY_STATE_CLOSE, pb.ChaincodeMessage_INVOKE_CHAINCODE:
+			failed = append(failed, msg)
+		default:
+			resend = append(resend, msg)
+		}
+	}
+	handler.RUnlock()
+
+	payload, err := proto.Marshal(resume)
+	if err != nil {
+		return err
+	}
+	if err = stream.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESUME, Payload: payload}); err != nil {
+		return err
+	}
+	ack, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if ack.Type != pb.ChaincodeMessage_RESUME {
+		chaincodeLogger.Errorf("Peer answered %s to %s: %s", ack.Type, pb.ChaincodeMessage_RESUME, string(ack.Payload))
+		return errResumeRefused
+	}
+
+	handler.serialLock.Lock()
+	handler.ChatStream.CloseSend()
+	handler.ChatStream = stream
+	handler.serialLock.Unlock()
+
+	for _, msg := range resend {
+		chaincodeLogger.Debugf("[%s]sending %s again on the resumed stream", shorttxid(msg.Txid), msg.Type)
+		handler.serialSendAsync(msg, nil)
+	}
+	for _, msg := range failed {
+		errMsg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Txid: msg.Txid,
+			Payload: []byte(fmt.Sprintf("%s could not be repeated on the resumed stream to the peer", msg.Type))}
+		go handler.sendChannel(errMsg)
+	}
+	return nil
+}
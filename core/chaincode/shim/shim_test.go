@@ -17,10 +17,18 @@ limitations under the License.
 package shim
 
 import (
+	"errors"
+	"io"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/op/go-logging"
+	"google.golang.org/grpc/codes"
 )
 
 // Test Go shim functionality that can be tested outside of a real chaincode
@@ -142,3 +150,188 @@ func TestNilEventName(t *testing.T) {
 	}
 
 }
+
+// TestErrorWithCode tests that the status code and the details of an error
+// response reach the client
+func TestErrorWithCode(t *testing.T) {
+	res := ErrorWithCode(codes.FailedPrecondition, "insufficient funds", &pb.ChaincodeID{Name: "account1"})
+	if res.Status != ERROR || res.Message != "insufficient funds" || codes.Code(res.Code) != codes.FailedPrecondition {
+		t.Fatalf("Unexpected error response %v", res)
+	}
+	if len(res.Details) != 1 {
+		t.Fatalf("Expected one error detail, got %d", len(res.Details))
+	}
+
+	// the response is sent to the peer marshaled
+	resBytes, err := proto.Marshal(&res)
+	if err != nil {
+		t.Fatalf("Error marshaling the response: %s", err)
+	}
+	received := &pb.Response{}
+	if err = proto.Unmarshal(resBytes, received); err != nil {
+		t.Fatalf("Error unmarshaling the response: %s", err)
+	}
+	detail := &pb.ChaincodeID{}
+	if err = ptypes.UnmarshalAny(received.Details[0], detail); err != nil {
+		t.Fatalf("Error unmarshaling the error detail: %s", err)
+	}
+	if detail.Name != "account1" {
+		t.Errorf("Unexpected error detail %v", detail)
+	}
+
+	if res = Error("failed"); res.Code != 0 || res.Details != nil {
+		t.Errorf("Error responses should not have a status code or details, got %v", res)
+	}
+}
+
+// mockPeerStream answers the messages sent with replies, then ends
+type mockPeerStream struct {
+	sync.Mutex
+	sent    []*pb.ChaincodeMessage
+	replies chan *pb.ChaincodeMessage
+}
+
+func newMockPeerStream(replies ...*pb.ChaincodeMessage) *mockPeerStream {
+	s := &mockPeerStream{replies: make(chan *pb.ChaincodeMessage, len(replies))}
+	for _, reply := range replies {
+		s.replies <- reply
+	}
+	close(s.replies)
+	return s
+}
+
+func (s *mockPeerStream) Send(msg *pb.ChaincodeMessage) error {
+	s.Lock()
+	defer s.Unlock()
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func (s *mockPeerStream) Recv() (*pb.ChaincodeMessage, error) {
+	if reply, ok := <-s.replies; ok {
+		return reply, nil
+	}
+	return nil, io.EOF
+}
+
+func (s *mockPeerStream) CloseSend() error {
+	return nil
+}
+
+func (s *mockPeerStream) sentTypes() map[string]pb.ChaincodeMessage_Type {
+	s.Lock()
+	defer s.Unlock()
+	types := make(map[string]pb.ChaincodeMessage_Type)
+	for _, msg := range s.sent {
+		types[msg.Txid] = msg.Type
+	}
+	return types
+}
+
+// TestResumeStream tests that a chaincode resuming its stream reports the
+// transactions it executes, sends their results again and repeats only the
+// requests which can safely be repeated
+func TestResumeStream(t *testing.T) {
+	handler := newChaincodeHandler(newMockPeerStream(), nil)
+	handler.reconnectTimeout = time.Second
+	handler.resumeNonce = []byte("nonce")
+
+	handler.startExecuting("done")
+	handler.startExecuting("running")
+	handler.setPending(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE, Txid: "running"})
+	handler.startExecuting("iterating")
+	handler.setPending(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY_STATE_NEXT, Txid: "iterating"})
+	respc, err := handler.createChannel("iterating")
+	if err != nil {
+		t.Fatalf("Error creating response channel: %s", err)
+	}
+	// the result of done was sent on the broken stream but may have been lost
+	handler.Lock()
+	handler.executing["done"] = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "done"}
+	handler.Unlock()
+
+	stream := newMockPeerStream(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESUME})
+	err = handler.resume(&pb.ChaincodeID{Name: "mycc:0"}, func() (PeerChaincodeStream, error) {
+		return stream, nil
+	})
+	if err != nil {
+		t.Fatalf("Error resuming stream: %s", err)
+	}
+
+	select {
+	case msg := <-respc:
+		if msg.Type != pb.ChaincodeMessage_ERROR {
+			t.Fatalf("Expected the iteration to fail, got %s", msg.Type)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the iteration to fail")
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	stream.Lock()
+	first := stream.sent[0]
+	stream.Unlock()
+	resume := &pb.ChaincodeResume{}
+	if err = proto.Unmarshal(first.Payload, resume); err != nil {
+		t.Fatalf("Error unmarshaling resume: %s", err)
+	}
+	if first.Type != pb.ChaincodeMessage_RESUME || resume.ChaincodeId.Name != "mycc:0" || len(resume.Txids) != 3 || string(resume.Nonce) != "nonce" {
+		t.Fatalf("Unexpected resume %v", resume)
+	}
+	types := stream.sentTypes()
+	if types["done"] != pb.ChaincodeMessage_COMPLETED || types["running"] != pb.ChaincodeMessage_GET_STATE {
+		t.Fatalf("Expected the result and the request to be sent again, got %v", types)
+	}
+	if _, ok := types["iterating"]; ok {
+		t.Fatalf("Expected the iteration not to be repeated")
+	}
+}
+
+// TestResumeRefused tests that a chaincode stops resuming its stream once
+// the peer refuses it
+func TestResumeRefused(t *testing.T) {
+	handler := newChaincodeHandler(newMockPeerStream(), nil)
+	handler.reconnectTimeout = time.Minute
+
+	tries := 0
+	err := handler.resume(&pb.ChaincodeID{Name: "mycc:0"}, func() (PeerChaincodeStream, error) {
+		tries++
+		return newMockPeerStream(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR}), nil
+	})
+	if err == nil || tries != 1 {
+		t.Fatalf("Expected the resume to be refused once, got %v after %d tries", err, tries)
+	}
+
+	// the peer being unreachable, the resume is given up at the reconnect timeout
+	defer func(interval time.Duration) { resumeRetryInterval = interval }(resumeRetryInterval)
+	resumeRetryInterval = 10 * time.Millisecond
+	handler.reconnectTimeout = 50 * time.Millisecond
+	tries = 0
+	err = handler.resume(&pb.ChaincodeID{Name: "mycc:0"}, func() (PeerChaincodeStream, error) {
+		tries++
+		return nil, errors.New("peer unreachable")
+	})
+	if err == nil || tries < 2 {
+		t.Fatalf("Expected the resume to be retried until the reconnect timeout, got %v after %d tries", err, tries)
+	}
+}
+
+// TestGetPreferredMaxBytes tests that the chaincode gets the block size
+// advisory the peer sets in the proposal context
+func TestGetPreferredMaxBytes(t *testing.T) {
+	stub := &ChaincodeStub{}
+	stub.init(&Handler{}, "txid", &pb.ChaincodeInput{}, nil)
+	if size := stub.GetPreferredMaxBytes(); size != 0 {
+		t.Fatalf("Expected no advisory without proposal context, got %d", size)
+	}
+
+	stub.init(&Handler{}, "txid", &pb.ChaincodeInput{}, &pb.ChaincodeProposalContext{PreferredMaxBytes: 512 * 1024})
+	if size := stub.GetPreferredMaxBytes(); size != 512*1024 {
+		t.Fatalf("Expected the advisory of the peer, got %d", size)
+	}
+
+	var mockStub ChaincodeStubInterface = &MockStub{PreferredMaxBytes: 1024}
+	if size := mockStub.GetPreferredMaxBytes(); size != 1024 {
+		t.Fatalf("Expected the advisory set on the mock stub, got %d", size)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+//Reasons of the failed TLS handshakes
+const (
+	//HandshakeFailureProtocol is a failure of the TLS protocol itself, e.g. no
+	//common cipher suite or a peer not speaking TLS
+	HandshakeFailureProtocol = "protocol"
+	//HandshakeFailureNoCertificate is a client which offered no certificate
+	HandshakeFailureNoCertificate = "no_certificate"
+	//HandshakeFailureMalformedCertificate is a client which offered a certificate
+	//which could not be parsed
+	HandshakeFailureMalformedCertificate = "malformed_certificate"
+	//HandshakeFailureExpired is a client certificate, or one of its issuers,
+	//outside of its validity period
+	HandshakeFailureExpired = "expired"
+	//HandshakeFailureUnknownAuthority is a client certificate which does not
+	//chain up to any of the trusted client root CAs
+	HandshakeFailureUnknownAuthority = "unknown_authority"
+	//HandshakeFailureBadCertificate is a client certificate rejected for any
+	//other reason, e.g. its key usage does not allow client authentication
+	HandshakeFailureBadCertificate = "bad_certificate"
+)
+
+//CertificateInfo describes a certificate offered during a TLS handshake
+type CertificateInfo struct {
+	Subject  string
+	Issuer   string
+	NotAfter time.Time
+}
+
+func (ci CertificateInfo) String() string {
+	return fmt.Sprintf("{subject: %s, issuer: %s, expiry: %s}", ci.Subject, ci.Issuer, ci.NotAfter.UTC().Format(time.RFC3339))
+}
+
+//HandshakeFailure describes a failed TLS handshake
+type HandshakeFailure struct {
+	//Address of the remote end of the connection
+	RemoteAddress string
+	//Server name indicated by the client, if any
+	ServerName string
+	//Reason of the failure, one of the HandshakeFailure constants
+	Reason string
+	//Certificate chain offered by the client, leaf first
+	OfferedChain []CertificateInfo
+	//Subjects of the client root CAs the chain was verified against
+	TrustedRoots []string
+	//Err is the error which failed the handshake
+	Err error
+}
+
+func (hf *HandshakeFailure) String() string {
+	return fmt.Sprintf("TLS handshake with %s failed [reason: %s, sni: %q, offered chain: %v, trusted roots: %v]: %s",
+		hf.RemoteAddress, hf.Reason, hf.ServerName, hf.OfferedChain, hf.TrustedRoots, hf.Err)
+}
+
+var handshakeFailures = struct {
+	sync.Mutex
+	counts map[string]uint64
+}{counts: make(map[string]uint64)}
+
+//HandshakeFailures returns the number of TLS handshakes which failed since the
+//process started, per failure reason
+func HandshakeFailures() map[string]uint64 {
+	handshakeFailures.Lock()
+	defer handshakeFailures.Unlock()
+	counts := make(map[string]uint64, len(handshakeFailures.counts))
+	for reason, count := range handshakeFailures.counts {
+		counts[reason] = count
+	}
+	return counts
+}
+
+func recordHandshakeFailure(failure *HandshakeFailure) {
+	handshakeFailures.Lock()
+	handshakeFailures.counts[failure.Reason]++
+	handshakeFailures.Unlock()
+	commLogger.Warning(failure.String())
+}
+
+//diagnosticCredentials are TLS transport credentials which verify the client
+//certificates themselves, so that a failed handshake can be reported along with
+//what the client offered and what it was verified against
+type diagnosticCredentials struct {
+	credentials.TransportCredentials
+	//handshakeConfig returns a copy of the current TLS config of the server and
+	//the client root CAs its ClientCAs pool is made of
+	handshakeConfig func() (*tls.Config, []*x509.Certificate)
+}
+
+func newDiagnosticCredentials(config *tls.Config, handshakeConfig func() (*tls.Config, []*x509.Certificate)) credentials.TransportCredentials {
+	return &diagnosticCredentials{
+		TransportCredentials: credentials.NewTLS(config),
+		handshakeConfig:      handshakeConfig,
+	}
+}
+
+func (dc *diagnosticCredentials) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	failure := &HandshakeFailure{RemoteAddress: rawConn.RemoteAddr().String(), Reason: HandshakeFailureProtocol}
+
+	config, rootCerts := dc.handshakeConfig()
+	if config.ClientAuth == tls.RequireAndVerifyClientCert {
+		//only request the certificate so that missing ones are diagnosed as well
+		roots := config.ClientCAs
+		config.ClientAuth = tls.RequestClientCert
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyClientChain(rawCerts, roots, rootCerts, failure)
+		}
+	}
+
+	conn := tls.Server(rawConn, config)
+	if err := conn.Handshake(); err != nil {
+		rawConn.Close()
+		failure.ServerName = conn.ConnectionState().ServerName
+		failure.Err = err
+		recordHandshakeFailure(failure)
+		return nil, nil, err
+	}
+	return conn, credentials.TLSInfo{State: conn.ConnectionState()}, nil
+}
+
+//verifyClientChain verifies the certificate chain offered by a client the same way
+//crypto/tls does, recording in failure why it was rejected
+func verifyClientChain(rawCerts [][]byte, roots *x509.CertPool, rootCerts []*x509.Certificate, failure *HandshakeFailure) error {
+	for _, root := range rootCerts {
+		failure.TrustedRoots = append(failure.TrustedRoots, describeName(root.Subject))
+	}
+
+	if len(rawCerts) == 0 {
+		failure.Reason = HandshakeFailureNoCertificate
+		return fmt.Errorf("tls: client didn't provide a certificate")
+	}
+
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			failure.Reason = HandshakeFailureMalformedCertificate
+			return fmt.Errorf("tls: failed to parse client certificate: %s", err)
+		}
+		certs[i] = cert
+		failure.OfferedChain = append(failure.OfferedChain, CertificateInfo{
+			Subject:  describeName(cert.Subject),
+			Issuer:   describeName(cert.Issuer),
+			NotAfter: cert.NotAfter,
+		})
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(opts)
+	if err == nil {
+		return nil
+	}
+	switch e := err.(type) {
+	case x509.CertificateInvalidError:
+		if e.Reason == x509.Expired {
+			failure.Reason = HandshakeFailureExpired
+		} else {
+			failure.Reason = HandshakeFailureBadCertificate
+		}
+	case x509.UnknownAuthorityError:
+		failure.Reason = HandshakeFailureUnknownAuthority
+	default:
+		failure.Reason = HandshakeFailureBadCertificate
+	}
+	return fmt.Errorf("tls: failed to verify client's certificate: %s", err)
+}
+
+//describeName renders the main attributes of a distinguished name
+func describeName(name pkix.Name) string {
+	var parts []string
+	add := func(attr string, values ...string) {
+		for _, value := range values {
+			parts = append(parts, attr+"="+value)
+		}
+	}
+	add("CN", name.CommonName)
+	add("OU", name.OrganizationalUnit...)
+	add("O", name.Organization...)
+	add("C", name.Country...)
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,212 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testIdentity struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestIdentity(t *testing.T, name string, parent *testIdentity, notAfter time.Time, usage x509.ExtKeyUsage) *testIdentity {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"Diagnostics"}},
+		NotBefore:    time.Now().Add(-2 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	parentCert, parentKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Error creating certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Error parsing certificate: %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error marshaling key: %s", err)
+	}
+	return &testIdentity{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (ti *testIdentity) tlsCertificate(t *testing.T) []tls.Certificate {
+	cert, err := tls.X509KeyPair(ti.certPEM, ti.keyPEM)
+	if err != nil {
+		t.Fatalf("Error loading key pair: %s", err)
+	}
+	return []tls.Certificate{cert}
+}
+
+func TestHandshakeFailureDiagnostics(t *testing.T) {
+	valid := time.Now().Add(time.Hour)
+	ca := newTestIdentity(t, "trustedca", nil, valid, x509.ExtKeyUsageAny)
+	otherCA := newTestIdentity(t, "otherca", nil, valid, x509.ExtKeyUsageAny)
+	server := newTestIdentity(t, "server", ca, valid, x509.ExtKeyUsageServerAuth)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error creating listener: %s", err)
+	}
+	srv, err := NewGRPCServerFromListener(lis, SecureServerConfig{
+		UseTLS:            true,
+		ServerCertificate: server.certPEM,
+		ServerKey:         server.keyPEM,
+		RequireClientCert: true,
+		ClientRootCAs:     [][]byte{ca.certPEM},
+	})
+	if err != nil {
+		t.Fatalf("Error creating server: %s", err)
+	}
+	go srv.Start()
+	defer srv.Stop()
+
+	var tests = []struct {
+		name   string
+		client []tls.Certificate
+		reason string
+	}{
+		{
+			name:   "Trusted",
+			client: newTestIdentity(t, "trusted", ca, valid, x509.ExtKeyUsageClientAuth).tlsCertificate(t),
+		},
+		{
+			name:   "NoCertificate",
+			reason: HandshakeFailureNoCertificate,
+		},
+		{
+			name:   "UnknownAuthority",
+			client: newTestIdentity(t, "untrusted", otherCA, valid, x509.ExtKeyUsageClientAuth).tlsCertificate(t),
+			reason: HandshakeFailureUnknownAuthority,
+		},
+		{
+			name:   "Expired",
+			client: newTestIdentity(t, "expired", ca, time.Now().Add(-time.Hour), x509.ExtKeyUsageClientAuth).tlsCertificate(t),
+			reason: HandshakeFailureExpired,
+		},
+		{
+			name:   "WrongKeyUsage",
+			client: newTestIdentity(t, "server-only", ca, valid, x509.ExtKeyUsageServerAuth).tlsCertificate(t),
+			reason: HandshakeFailureBadCertificate,
+		},
+	}
+
+	for _, test := range tests {
+		before := HandshakeFailures()
+		client := test.client
+		conn, err := tls.Dial("tcp", srv.Address(), &tls.Config{
+			// offer the certificate even if the server does not list its issuer
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if len(client) == 0 {
+					return &tls.Certificate{}, nil
+				}
+				return &client[0], nil
+			},
+			InsecureSkipVerify: true,
+			NextProtos:         []string{"h2"},
+		})
+		if err == nil {
+			// The client certificate is only verified once the client reads
+			_, err = conn.Read(make([]byte, 1))
+			conn.Close()
+		}
+
+		if test.reason == "" {
+			// a trusted client reads the HTTP/2 settings of the server
+			assert.NoError(t, err, test.name)
+			assert.Equal(t, before, HandshakeFailures(), test.name)
+			continue
+		}
+		assert.Error(t, err, test.name)
+		// The failure is recorded once the server is done with the handshake
+		for i := 0; i < 100 && HandshakeFailures()[test.reason] == before[test.reason]; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, before[test.reason]+1, HandshakeFailures()[test.reason], test.name)
+	}
+}
+
+func TestVerifyClientChain(t *testing.T) {
+	valid := time.Now().Add(time.Hour)
+	ca := newTestIdentity(t, "trustedca", nil, valid, x509.ExtKeyUsageAny)
+	client := newTestIdentity(t, "client", ca, valid, x509.ExtKeyUsageClientAuth)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	failure := &HandshakeFailure{}
+	err := verifyClientChain([][]byte{client.cert.Raw}, roots, []*x509.Certificate{ca.cert}, failure)
+	assert.NoError(t, err)
+
+	failure = &HandshakeFailure{}
+	err = verifyClientChain([][]byte{[]byte("garbage")}, roots, []*x509.Certificate{ca.cert}, failure)
+	assert.Error(t, err)
+	assert.Equal(t, HandshakeFailureMalformedCertificate, failure.Reason)
+	assert.Equal(t, []string{"CN=trustedca,O=Diagnostics"}, failure.TrustedRoots)
+
+	// The offered chain is reported, leaf first
+	other := newTestIdentity(t, "otherca", nil, valid, x509.ExtKeyUsageAny)
+	client = newTestIdentity(t, "client", other, valid, x509.ExtKeyUsageClientAuth)
+	failure = &HandshakeFailure{}
+	err = verifyClientChain([][]byte{client.cert.Raw, other.cert.Raw}, roots, []*x509.Certificate{ca.cert}, failure)
+	assert.Error(t, err)
+	assert.Equal(t, HandshakeFailureUnknownAuthority, failure.Reason)
+	if assert.Len(t, failure.OfferedChain, 2) {
+		assert.Equal(t, "CN=client,O=Diagnostics", failure.OfferedChain[0].Subject)
+		assert.Equal(t, "CN=otherca,O=Diagnostics", failure.OfferedChain[0].Issuer)
+		assert.Equal(t, client.cert.NotAfter, failure.OfferedChain[0].NotAfter)
+		assert.Equal(t, "CN=otherca,O=Diagnostics", failure.OfferedChain[1].Subject)
+	}
+}
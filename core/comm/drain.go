@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// drainer tracks the in-flight calls of a grpc.Server so that it can stop once they complete.
+// Streams are long lived (gossip, deliver, events, chaincode), so a new stream is refused once
+// draining but the open ones are not waited for: they are cut when the server stops
+type drainer struct {
+	lock     sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+}
+
+// enter registers a new call, it returns false if the server is draining
+func (d *drainer) enter() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inflight.Add(1)
+	return true
+}
+
+func (d *drainer) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !d.enter() {
+		return nil, grpc.Errorf(codes.Unavailable, "Server is shutting down")
+	}
+	defer d.inflight.Done()
+	return handler(ctx, req)
+}
+
+func (d *drainer) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	d.lock.Lock()
+	draining := d.draining
+	d.lock.Unlock()
+	if draining {
+		return grpc.Errorf(codes.Unavailable, "Server is shutting down")
+	}
+	return handler(srv, ss)
+}
+
+// drain refuses the new calls and waits up to timeout for the in-flight ones,
+// it returns whether they all completed
+func (d *drainer) drain(timeout time.Duration) bool {
+	d.lock.Lock()
+	d.draining = true
+	d.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,345 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	defaultPoolSize            = 512
+	defaultPoolIdleTimeout     = 5 * time.Minute
+	defaultPoolHealthCheckTime = 30 * time.Second
+)
+
+//DialFunc dials a new gRPC client connection
+type DialFunc func() (*grpc.ClientConn, error)
+
+//ConnectionPool is a size-bounded set of gRPC client connections shared by the
+//subsystems of a process. Connections are identified by a key, which callers
+//derive from the endpoint and from anything else that makes their dial options
+//unique, such as the TLS client certificate they authenticate with
+type ConnectionPool interface {
+	//Get returns the pooled connection of key, dialing it with dial if the pool
+	//has none. Every successful Get must be paired with a Release or Invalidate
+	Get(key string, dial DialFunc) (*grpc.ClientConn, error)
+
+	//Release signals the caller is done with the connection it got for key. The
+	//connection stays open and is closed once it has been idle for too long
+	Release(key string, conn *grpc.ClientConn)
+
+	//Invalidate releases the connection of key like Release, and takes it out of
+	//the pool, e.g. because a call over it failed, so that the next Get dials a
+	//new one. The connection is closed once all its other users released it
+	Invalidate(key string, conn *grpc.ClientConn)
+
+	//Close closes all the connections of the pool
+	Close()
+}
+
+type pooledConn struct {
+	conn     *grpc.ClientConn
+	err      error
+	ready    chan struct{}
+	refs     int
+	lastUsed time.Time
+}
+
+func (pc *pooledConn) isReady() bool {
+	select {
+	case <-pc.ready:
+		return pc.err == nil
+	default:
+		return false
+	}
+}
+
+type connectionPool struct {
+	lock                sync.Mutex
+	size                int
+	idleTimeout         time.Duration
+	healthCheckInterval time.Duration
+	conns               map[string]*pooledConn
+	//retired holds the connections taken out of the pool while still in use,
+	//until their last user releases them
+	retired map[*grpc.ClientConn]*pooledConn
+	//stopChan stops the maintenance of the connections, which only runs while
+	//the pool holds some
+	stopChan chan struct{}
+}
+
+//NewConnectionPool creates a ConnectionPool which holds up to size connections.
+//Connections unused for idleTimeout are closed, and every healthCheckInterval
+//the connections which do not answer a gRPC health check are closed. A zero
+//idleTimeout or healthCheckInterval disables the corresponding task
+func NewConnectionPool(size int, idleTimeout, healthCheckInterval time.Duration) ConnectionPool {
+	return &connectionPool{
+		size:                size,
+		idleTimeout:         idleTimeout,
+		healthCheckInterval: healthCheckInterval,
+		conns:               make(map[string]*pooledConn),
+		retired:             make(map[*grpc.ClientConn]*pooledConn),
+	}
+}
+
+var defaultPool struct {
+	sync.Once
+	pool ConnectionPool
+}
+
+//GetConnectionPool returns the connection pool of the process, configured
+//by the peer.connectionPool section of the configuration
+func GetConnectionPool() ConnectionPool {
+	defaultPool.Do(func() {
+		size := viper.GetInt("peer.connectionPool.size")
+		if size <= 0 {
+			size = defaultPoolSize
+		}
+		idleTimeout := defaultPoolIdleTimeout
+		if viper.IsSet("peer.connectionPool.idleTimeout") {
+			idleTimeout = viper.GetDuration("peer.connectionPool.idleTimeout")
+		}
+		healthCheckInterval := defaultPoolHealthCheckTime
+		if viper.IsSet("peer.connectionPool.healthCheckInterval") {
+			healthCheckInterval = viper.GetDuration("peer.connectionPool.healthCheckInterval")
+		}
+		defaultPool.pool = NewConnectionPool(size, idleTimeout, healthCheckInterval)
+	})
+	return defaultPool.pool
+}
+
+func (p *connectionPool) Get(key string, dial DialFunc) (*grpc.ClientConn, error) {
+	p.lock.Lock()
+	if pc, exists := p.conns[key]; exists {
+		pc.refs++
+		p.lock.Unlock()
+		//wait for the connection in case another caller is still dialing it
+		<-pc.ready
+		return pc.conn, pc.err
+	}
+	if err := p.makeRoom(); err != nil {
+		p.lock.Unlock()
+		return nil, err
+	}
+	pc := &pooledConn{ready: make(chan struct{}), refs: 1}
+	p.add(key, pc)
+	p.lock.Unlock()
+
+	pc.conn, pc.err = dial()
+	if pc.err != nil {
+		p.lock.Lock()
+		if p.conns[key] == pc {
+			p.remove(key)
+		}
+		p.lock.Unlock()
+	}
+	close(pc.ready)
+	return pc.conn, pc.err
+}
+
+//makeRoom evicts the least recently used idle connection if the pool is full.
+//It must be called with the lock held
+func (p *connectionPool) makeRoom() error {
+	if len(p.conns) < p.size {
+		return nil
+	}
+	var lruKey string
+	var lru *pooledConn
+	for key, pc := range p.conns {
+		if pc.refs == 0 && pc.isReady() && (lru == nil || pc.lastUsed.Before(lru.lastUsed)) {
+			lruKey, lru = key, pc
+		}
+	}
+	if lru == nil {
+		return fmt.Errorf("Connection pool is full, all its %d connections are in use", p.size)
+	}
+	commLogger.Debugf("Evicting idle connection %s from the connection pool", lruKey)
+	p.remove(lruKey)
+	lru.conn.Close()
+	return nil
+}
+
+//add adds a connection to the pool, starting the maintenance of the pool if it
+//was empty. It must be called with the lock held
+func (p *connectionPool) add(key string, pc *pooledConn) {
+	p.conns[key] = pc
+	if p.stopChan == nil {
+		p.stopChan = make(chan struct{})
+		go p.maintain(p.stopChan)
+	}
+}
+
+//remove removes a connection from the pool, stopping the maintenance of the
+//pool if it is now empty. It must be called with the lock held
+func (p *connectionPool) remove(key string) {
+	delete(p.conns, key)
+	if len(p.conns) == 0 && p.stopChan != nil {
+		close(p.stopChan)
+		p.stopChan = nil
+	}
+}
+
+func (p *connectionPool) Release(key string, conn *grpc.ClientConn) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	pc, exists := p.conns[key]
+	if !exists || pc.conn != conn {
+		//the connection was invalidated in the meantime
+		p.releaseRetired(conn)
+		return
+	}
+	pc.refs--
+	pc.lastUsed = time.Now()
+}
+
+func (p *connectionPool) Invalidate(key string, conn *grpc.ClientConn) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.retire(key, conn)
+	p.releaseRetired(conn)
+}
+
+//retire takes the connection of key out of the pool. It is closed right away
+//if nobody uses it, and once its last user releases it otherwise. It must be
+//called with the lock held
+func (p *connectionPool) retire(key string, conn *grpc.ClientConn) {
+	pc, exists := p.conns[key]
+	if !exists || pc.conn != conn {
+		return
+	}
+	p.remove(key)
+	if pc.refs == 0 {
+		conn.Close()
+		return
+	}
+	p.retired[conn] = pc
+}
+
+//releaseRetired drops a reference to a retired connection, closing it when it
+//was the last one. It must be called with the lock held
+func (p *connectionPool) releaseRetired(conn *grpc.ClientConn) {
+	pc, exists := p.retired[conn]
+	if !exists {
+		return
+	}
+	pc.refs--
+	if pc.refs <= 0 {
+		delete(p.retired, conn)
+		conn.Close()
+	}
+}
+
+func (p *connectionPool) Close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for key, pc := range p.conns {
+		if pc.isReady() {
+			pc.conn.Close()
+		}
+		p.remove(key)
+	}
+	for conn := range p.retired {
+		conn.Close()
+	}
+	p.retired = make(map[*grpc.ClientConn]*pooledConn)
+}
+
+//maintain periodically reaps the idle connections and health checks the others,
+//until stopChan is closed
+func (p *connectionPool) maintain(stopChan chan struct{}) {
+	var reap, check <-chan time.Time
+	if p.idleTimeout > 0 {
+		ticker := time.NewTicker(p.idleTimeout / 2)
+		defer ticker.Stop()
+		reap = ticker.C
+	}
+	if p.healthCheckInterval > 0 {
+		ticker := time.NewTicker(p.healthCheckInterval)
+		defer ticker.Stop()
+		check = ticker.C
+	}
+
+	for {
+		select {
+		case <-reap:
+			p.reapIdle()
+		case <-check:
+			p.checkHealth()
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+func (p *connectionPool) reapIdle() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for key, pc := range p.conns {
+		if pc.refs == 0 && pc.isReady() && time.Since(pc.lastUsed) >= p.idleTimeout {
+			commLogger.Debugf("Closing connection %s, idle since %s", key, pc.lastUsed)
+			p.remove(key)
+			pc.conn.Close()
+		}
+	}
+}
+
+func (p *connectionPool) checkHealth() {
+	p.lock.Lock()
+	conns := make(map[string]*grpc.ClientConn, len(p.conns))
+	for key, pc := range p.conns {
+		if pc.isReady() {
+			conns[key] = pc.conn
+		}
+	}
+	p.lock.Unlock()
+
+	for key, conn := range conns {
+		if err := checkConnection(conn); err != nil {
+			commLogger.Warningf("Connection %s failed its health check, closing it: %s", key, err)
+			//the health check holds no reference to the connection
+			p.lock.Lock()
+			p.retire(key, conn)
+			p.lock.Unlock()
+		}
+	}
+}
+
+//checkConnection calls the gRPC health service of the remote server. Servers
+//which do not implement it are considered healthy as long as they answer
+func checkConnection(conn *grpc.ClientConn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		if grpc.Code(err) == codes.Unimplemented {
+			return nil
+		}
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("server is %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func newPoolTestServer(t *testing.T) GRPCServer {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error creating listener: %s", err)
+	}
+	srv, err := NewGRPCServerFromListener(lis, SecureServerConfig{})
+	if err != nil {
+		t.Fatalf("Error creating server: %s", err)
+	}
+	go srv.Start()
+	return srv
+}
+
+type countingDialer struct {
+	address string
+	dials   int32
+}
+
+func (cd *countingDialer) dial() (*grpc.ClientConn, error) {
+	atomic.AddInt32(&cd.dials, 1)
+	return grpc.Dial(cd.address, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+}
+
+func TestConnectionPoolSharing(t *testing.T) {
+	srv := newPoolTestServer(t)
+	defer srv.Stop()
+	pool := NewConnectionPool(2, 0, 0)
+	defer pool.Close()
+	dialer := &countingDialer{address: srv.Address()}
+
+	// Concurrent callers share a single dial
+	var wg sync.WaitGroup
+	conns := make([]*grpc.ClientConn, 10)
+	for i := range conns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conn, err := pool.Get("a", dialer.dial)
+			assert.NoError(t, err)
+			conns[i] = conn
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&dialer.dials))
+	for _, conn := range conns {
+		assert.True(t, conn == conns[0], "All callers should have gotten the same connection")
+		pool.Release("a", conn)
+	}
+
+	other, err := pool.Get("b", dialer.dial)
+	assert.NoError(t, err)
+	assert.False(t, other == conns[0], "Another key should have gotten another connection")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&dialer.dials))
+
+	// The pool is full, the idle connection is evicted to make room
+	third, err := pool.Get("c", dialer.dial)
+	assert.NoError(t, err)
+	_, err = healthpb.NewHealthClient(conns[0]).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Error(t, err, "The evicted connection should have been closed")
+
+	// None of the connections is idle now
+	_, err = pool.Get("d", dialer.dial)
+	assert.Error(t, err, "The pool should have been full")
+
+	pool.Release("c", third)
+	pool.Invalidate("b", other)
+	conn, err := pool.Get("b", dialer.dial)
+	assert.NoError(t, err)
+	assert.False(t, conn == other, "An invalidated connection should have been dialed again")
+	assert.Equal(t, int32(4), atomic.LoadInt32(&dialer.dials))
+}
+
+func TestConnectionPoolInvalidateShared(t *testing.T) {
+	srv := newPoolTestServer(t)
+	defer srv.Stop()
+	pool := NewConnectionPool(1, 0, 0)
+	defer pool.Close()
+	dialer := &countingDialer{address: srv.Address()}
+
+	conn, err := pool.Get("a", dialer.dial)
+	assert.NoError(t, err)
+	shared, err := pool.Get("a", dialer.dial)
+	assert.NoError(t, err)
+
+	// Invalidating the connection does not close it under its other user
+	pool.Invalidate("a", conn)
+	_, err = healthpb.NewHealthClient(shared).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err, "The connection should have stayed open while in use")
+	fresh, err := pool.Get("a", dialer.dial)
+	assert.NoError(t, err)
+	assert.False(t, fresh == shared, "An invalidated connection should have been dialed again")
+	pool.Release("a", fresh)
+
+	// The last user closes it
+	pool.Release("a", shared)
+	_, err = healthpb.NewHealthClient(shared).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Error(t, err, "The connection should have been closed by its last user")
+}
+
+func TestConnectionPoolDialFailure(t *testing.T) {
+	pool := NewConnectionPool(1, 0, 0)
+	defer pool.Close()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error creating listener: %s", err)
+	}
+	dialer := &countingDialer{address: lis.Addr().String()}
+	lis.Close()
+
+	_, err = pool.Get("a", dialer.dial)
+	assert.Error(t, err)
+	// Failed dials do not take room in the pool, and are retried
+	srv := newPoolTestServer(t)
+	defer srv.Stop()
+	dialer.address = srv.Address()
+	_, err = pool.Get("a", dialer.dial)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&dialer.dials))
+}
+
+func TestConnectionPoolMaintenance(t *testing.T) {
+	srv := newPoolTestServer(t)
+	pool := NewConnectionPool(10, 100*time.Millisecond, 50*time.Millisecond).(*connectionPool)
+	defer pool.Close()
+	dialer := &countingDialer{address: srv.Address()}
+
+	idle, err := pool.Get("idle", dialer.dial)
+	assert.NoError(t, err)
+	pool.Release("idle", idle)
+	inUse, err := pool.Get("inUse", dialer.dial)
+	assert.NoError(t, err)
+
+	numConns := func() int {
+		pool.lock.Lock()
+		defer pool.lock.Unlock()
+		return len(pool.conns)
+	}
+
+	// The idle connection is reaped, the one in use is kept as long as it is healthy
+	time.Sleep(500 * time.Millisecond)
+	assert.Equal(t, 1, numConns())
+	conn, err := pool.Get("inUse", dialer.dial)
+	assert.NoError(t, err)
+	assert.True(t, conn == inUse)
+
+	// Once the server is gone, the connection fails its health check
+	srv.Stop()
+	for i := 0; i < 50 && numConns() > 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.Equal(t, 0, numConns())
+	pool.lock.Lock()
+	assert.Nil(t, pool.stopChan, "The maintenance of an empty pool should have stopped")
+	pool.lock.Unlock()
+}
@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+)
+
+//lookupHost resolves a host name, it is replaced by the tests
+var lookupHost = net.LookupHost
+
+//DNSDialOptions returns the dial options which make a gRPC connection resolve
+//the host of its endpoint again every peer.dns.refreshInterval, and reconnect
+//when the address it is connected to is no longer among the ones the host
+//resolves to. No option is returned when the refresh interval is not configured
+func DNSDialOptions() []grpc.DialOption {
+	interval := viper.GetDuration("peer.dns.refreshInterval")
+	if interval <= 0 {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithDialer(NewReResolvingDialer(interval))}
+}
+
+//NewReResolvingDialer creates a gRPC dialer which connects to the first reachable
+//address of the host of the endpoint, and closes the connection once the host no
+//longer resolves to that address, so that gRPC dials it again. The host is
+//resolved every interval
+func NewReResolvingDialer(interval time.Duration) func(endpoint string, timeout time.Duration) (net.Conn, error) {
+	return func(endpoint string, timeout time.Duration) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) != nil {
+			//there is nothing to resolve
+			return net.DialTimeout("tcp", endpoint, timeout)
+		}
+
+		ips, err := lookupHost(host)
+		if err != nil {
+			return nil, err
+		}
+		err = fmt.Errorf("%s resolves to no address", host)
+		for _, ip := range ips {
+			var conn net.Conn
+			conn, err = net.DialTimeout("tcp", net.JoinHostPort(ip, port), timeout)
+			if err == nil {
+				rc := &resolvedConn{Conn: conn, host: host, ip: ip, stopChan: make(chan struct{})}
+				go rc.watch(interval)
+				return rc, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+//resolvedConn is a connection to one of the addresses of host, which is closed
+//once host no longer resolves to it
+type resolvedConn struct {
+	net.Conn
+	host     string
+	ip       string
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+func (rc *resolvedConn) Close() error {
+	rc.stopOnce.Do(func() {
+		close(rc.stopChan)
+	})
+	return rc.Conn.Close()
+}
+
+func (rc *resolvedConn) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-rc.stopChan:
+			return
+		}
+		ips, err := lookupHost(rc.host)
+		if err == nil && len(ips) == 0 {
+			err = fmt.Errorf("%s resolves to no address", rc.host)
+		}
+		if err != nil {
+			commLogger.Warningf("Failed resolving %s again, keeping the connection to %s: %s", rc.host, rc.ip, err)
+			continue
+		}
+		if !containsAddress(ips, rc.ip) {
+			commLogger.Infof("%s no longer resolves to %s but to %v, reconnecting", rc.host, rc.ip, ips)
+			rc.Close()
+			return
+		}
+	}
+}
+
+func containsAddress(ips []string, ip string) bool {
+	for _, addr := range ips {
+		if addr == ip {
+			return true
+		}
+	}
+	return false
+}
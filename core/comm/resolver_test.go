@@ -0,0 +1,162 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+//fakeDNS serves the records of the host names of the tests
+type fakeDNS struct {
+	sync.Mutex
+	records map[string][]string
+}
+
+func (dns *fakeDNS) set(host string, ips ...string) {
+	dns.Lock()
+	defer dns.Unlock()
+	dns.records[host] = ips
+}
+
+func (dns *fakeDNS) lookupHost(host string) ([]string, error) {
+	dns.Lock()
+	defer dns.Unlock()
+	ips, exists := dns.records[host]
+	if !exists {
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+	return ips, nil
+}
+
+func installFakeDNS() (*fakeDNS, func()) {
+	dns := &fakeDNS{records: make(map[string][]string)}
+	lookupHost = dns.lookupHost
+	return dns, func() {
+		lookupHost = net.LookupHost
+	}
+}
+
+func TestReResolvingDialer(t *testing.T) {
+	dns, restore := installFakeDNS()
+	defer restore()
+
+	lis, err := net.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("Error creating listener: %s", err)
+	}
+	defer lis.Close()
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	endpoint := net.JoinHostPort("orderer.example.com", port)
+	dial := NewReResolvingDialer(10 * time.Millisecond)
+
+	_, err = dial(endpoint, time.Second)
+	assert.Error(t, err, "Dialing a host which does not resolve should have failed")
+
+	dns.set("orderer.example.com", "127.0.0.1")
+	conn, err := dial(endpoint, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", conn.RemoteAddr().(*net.TCPAddr).IP.String())
+
+	// The connection survives failed resolutions, and records still containing its address
+	dns.set("orderer.example.com")
+	time.Sleep(50 * time.Millisecond)
+	dns.set("orderer.example.com", "127.0.0.2", "127.0.0.1")
+	time.Sleep(50 * time.Millisecond)
+	conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 1))
+	if netErr, isNetErr := err.(net.Error); !isNetErr || !netErr.Timeout() {
+		t.Fatalf("The connection should still be open, got %v", err)
+	}
+
+	// Once the host moves, the connection is closed
+	dns.set("orderer.example.com", "127.0.0.2")
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+	if netErr, isNetErr := err.(net.Error); isNetErr {
+		assert.False(t, netErr.Timeout(), "The connection should have been closed")
+	}
+
+	conn, err = dial(endpoint, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.2", conn.RemoteAddr().(*net.TCPAddr).IP.String())
+	conn.Close()
+}
+
+func TestDNSDialOptions(t *testing.T) {
+	dns, restore := installFakeDNS()
+	defer restore()
+	defer viper.Set("peer.dns.refreshInterval", 0)
+
+	viper.Set("peer.dns.refreshInterval", 0)
+	assert.Empty(t, DNSDialOptions(), "No option should be returned when re-resolution is disabled")
+
+	lis, err := net.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("Error creating listener: %s", err)
+	}
+	srv, err := NewGRPCServerFromListener(lis, SecureServerConfig{})
+	if err != nil {
+		t.Fatalf("Error creating server: %s", err)
+	}
+	go srv.Start()
+	defer srv.Stop()
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+
+	viper.Set("peer.dns.refreshInterval", 10*time.Millisecond)
+	dns.set("peer0.example.com", "127.0.0.1")
+	opts := append(DNSDialOptions(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+	conn, err := grpc.Dial(net.JoinHostPort("peer0.example.com", port), opts...)
+	if err != nil {
+		t.Fatalf("Error dialing: %s", err)
+	}
+	defer conn.Close()
+
+	// The gRPC connection follows the host to its new address
+	dns.set("peer0.example.com", "127.0.0.2")
+	time.Sleep(100 * time.Millisecond)
+	var resp *healthpb.HealthCheckResponse
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		resp, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.FailFast(false))
+		cancel()
+		if err == nil {
+			break
+		}
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
@@ -24,9 +24,11 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 //A SecureServerConfig structure is used to configure security (e.g. TLS) for a
@@ -56,6 +58,10 @@ type GRPCServer interface {
 	Start() error
 	//Stop stops the underlying grpc.Server
 	Stop()
+	//GracefulStop refuses new calls, waits up to timeout for the in-flight unary
+	//calls to complete and stops the underlying grpc.Server. It returns whether
+	//the in-flight calls completed before the timeout
+	GracefulStop(timeout time.Duration) bool
 	//Server returns the grpc.Server instance for the GRPCServer
 	Server() *grpc.Server
 	//Listener returns the net.Listener instance for the GRPCServer
@@ -99,6 +105,8 @@ type grpcServerImpl struct {
 	tlsConfig *tls.Config
 	//Is TLS enabled?
 	tlsEnabled bool
+	//Tracks the in-flight calls for GracefulStop
+	drainer *drainer
 }
 
 //NewGRPCServer creates a new implementation of a GRPCServer given a
@@ -127,10 +135,14 @@ func NewGRPCServerFromListener(listener net.Listener, secureConfig SecureServerC
 		address:  listener.Addr().String(),
 		listener: listener,
 		lock:     &sync.Mutex{},
+		drainer:  &drainer{},
 	}
 
 	//set up our server options
-	var serverOpts []grpc.ServerOption
+	serverOpts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(grpcServer.drainer.unaryInterceptor),
+		grpc.StreamInterceptor(grpcServer.drainer.streamInterceptor),
+	}
 	//check secureConfig
 	if secureConfig.UseTLS {
 		//both key and cert are required
@@ -168,8 +180,8 @@ func NewGRPCServerFromListener(listener net.Listener, secureConfig SecureServerC
 				}
 			}
 
-			//create credentials
-			creds := credentials.NewTLS(grpcServer.tlsConfig)
+			//create credentials, diagnosing the failed handshakes
+			creds := newDiagnosticCredentials(grpcServer.tlsConfig, grpcServer.handshakeConfig)
 
 			//add to server options
 			serverOpts = append(serverOpts, grpc.Creds(creds))
@@ -180,6 +192,8 @@ func NewGRPCServerFromListener(listener net.Listener, secureConfig SecureServerC
 		}
 	}
 	grpcServer.server = grpc.NewServer(serverOpts...)
+	//answer the health checks of the connection pools of the clients
+	healthpb.RegisterHealthServer(grpcServer.server, health.NewHealthServer())
 
 	return grpcServer, nil
 }
@@ -220,6 +234,26 @@ func (gServer *grpcServerImpl) Stop() {
 	gServer.server.Stop()
 }
 
+//GracefulStop refuses new calls, waits up to timeout for the in-flight unary
+//calls and stops the underlying grpc.Server
+func (gServer *grpcServerImpl) GracefulStop(timeout time.Duration) bool {
+	drained := gServer.drainer.drain(timeout)
+	gServer.server.Stop()
+	return drained
+}
+
+//handshakeConfig returns a copy of the current TLS config and the client
+//root CAs it trusts
+func (gServer *grpcServerImpl) handshakeConfig() (*tls.Config, []*x509.Certificate) {
+	gServer.lock.Lock()
+	defer gServer.lock.Unlock()
+	roots := make([]*x509.Certificate, 0, len(gServer.clientRootCAs))
+	for _, root := range gServer.clientRootCAs {
+		roots = append(roots, root)
+	}
+	return gServer.tlsConfig.Clone(), roots
+}
+
 //AppendClientRootCAs appends PEM-encoded X509 certificate authorities to
 //the list of authorities used to verify client certificates
 func (gServer *grpcServerImpl) AppendClientRootCAs(clientRoots [][]byte) error {
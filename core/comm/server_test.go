@@ -577,6 +577,57 @@ func TestNewGRPCServerFromListener(t *testing.T) {
 	}
 }
 
+//test server whose calls block until released
+type blockingServiceServer struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (bss *blockingServiceServer) EmptyCall(context.Context, *testpb.Empty) (*testpb.Empty, error) {
+	bss.entered <- struct{}{}
+	<-bss.release
+	return new(testpb.Empty), nil
+}
+
+func TestGracefulStop(t *testing.T) {
+
+	t.Parallel()
+	testAddress := "localhost:9059"
+	srv, err := comm.NewGRPCServer(testAddress,
+		comm.SecureServerConfig{UseTLS: false})
+	if err != nil {
+		t.Fatalf("Failed to return new GRPC server: %v", err)
+	}
+
+	bss := &blockingServiceServer{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	testpb.RegisterTestServiceServer(srv.Server(), bss)
+	go srv.Start()
+	time.Sleep(10 * time.Millisecond)
+
+	dialOptions := []grpc.DialOption{grpc.WithInsecure()}
+	inflight := make(chan error, 1)
+	go func() {
+		_, err := invokeEmptyCall(testAddress, dialOptions)
+		inflight <- err
+	}()
+	<-bss.entered
+
+	stopped := make(chan bool, 1)
+	go func() {
+		stopped <- srv.GracefulStop(timeout)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	//new calls are refused while draining
+	_, err = invokeEmptyCall(testAddress, dialOptions)
+	assert.Error(t, err, "New calls should be refused while draining")
+
+	//the in-flight call completes before the server stops
+	close(bss.release)
+	assert.NoError(t, <-inflight, "In-flight call should complete")
+	assert.True(t, <-stopped, "In-flight calls should have drained")
+}
+
 func TestNewSecureGRPCServer(t *testing.T) {
 
 	t.Parallel()
@@ -0,0 +1,214 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package txvalidator
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric/msp"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/viper"
+)
+
+// The modes of peer.validator.signatureBatching
+const (
+	// batchingAuto batches the signatures while measurements show it is faster
+	batchingAuto = "auto"
+	batchingOn   = "on"
+	batchingOff  = "off"
+)
+
+const (
+	// minBatchTransactions is the number of transactions under which the
+	// signatures of a set of transactions are never batched
+	minBatchTransactions = 8
+
+	// gateSamples is the number of sets of transactions the auto mode
+	// measures each way before choosing one
+	gateSamples = 4
+
+	// gateInterval is the number of sets of transactions after which the
+	// auto mode measures both ways again, as the load of the peer changes
+	gateInterval = 1024
+)
+
+// batchingGate decides whether the creator and endorser signatures of a set
+// of transactions are verified in one batch before the transactions are
+// validated, the validation then finding them already verified. In the auto
+// mode, the gate alternates both ways on the first sets of transactions, and
+// again every gateInterval sets, and batches the signatures while the
+// validation time per transaction measured is lower with batching
+type batchingGate struct {
+	mode string
+
+	sync.Mutex
+	sets    int
+	samples [2]int
+	elapsed [2]time.Duration
+	txs     [2]int
+	enabled bool
+}
+
+func newBatchingGate(mode string) *batchingGate {
+	return &batchingGate{mode: mode}
+}
+
+// getBatchingGate returns the gate of the mode of peer.validator.signatureBatching
+func getBatchingGate() *batchingGate {
+	mode := viper.GetString("peer.validator.signatureBatching")
+	switch mode {
+	case batchingAuto, batchingOn, batchingOff:
+	case "":
+		mode = batchingAuto
+	default:
+		logger.Warningf("Invalid peer.validator.signatureBatching %s, batching signatures automatically", mode)
+		mode = batchingAuto
+	}
+	return newBatchingGate(mode)
+}
+
+func sampleIndex(batched bool) int {
+	if batched {
+		return 1
+	}
+	return 0
+}
+
+// measuring returns whether the gate still measures both ways
+func (g *batchingGate) measuring() bool {
+	return g.samples[0] < gateSamples || g.samples[1] < gateSamples
+}
+
+// batch returns whether the signatures of txCount transactions are batched
+func (g *batchingGate) batch(txCount int) bool {
+	if txCount < minBatchTransactions || g.mode == batchingOff {
+		return false
+	}
+	if g.mode == batchingOn {
+		return true
+	}
+
+	g.Lock()
+	defer g.Unlock()
+	if g.sets++; g.sets > gateInterval {
+		g.sets = 1
+		g.samples, g.elapsed, g.txs = [2]int{}, [2]time.Duration{}, [2]int{}
+	}
+	if g.measuring() {
+		// the way with the fewest samples, starting without batching
+		return g.samples[1] < g.samples[0]
+	}
+	return g.enabled
+}
+
+// record records the time taken to validate txCount transactions, batched or not
+func (g *batchingGate) record(batched bool, txCount int, elapsed time.Duration) {
+	if batched {
+		atomic.AddUint64(&validationMetrics.BatchedTransactions, uint64(txCount))
+	}
+	if txCount < minBatchTransactions || g.mode != batchingAuto {
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+	if !g.measuring() {
+		return
+	}
+	i := sampleIndex(batched)
+	g.samples[i]++
+	g.elapsed[i] += elapsed
+	g.txs[i] += txCount
+	if g.measuring() {
+		return
+	}
+
+	unbatchedPerTx := g.elapsed[0] / time.Duration(g.txs[0])
+	batchedPerTx := g.elapsed[1] / time.Duration(g.txs[1])
+	atomic.StoreUint64(&validationMetrics.UnbatchedNanosPerTransaction, uint64(unbatchedPerTx))
+	atomic.StoreUint64(&validationMetrics.BatchedNanosPerTransaction, uint64(batchedPerTx))
+	g.enabled = batchedPerTx < unbatchedPerTx
+	logger.Infof("Signature batching enabled: %t (%s per transaction batched, %s unbatched)", g.enabled, batchedPerTx, unbatchedPerTx)
+}
+
+// verifySignatures verifies the creator and endorser signatures of the
+// transactions of the indexes in one batch. Every identity is deserialized
+// once, and the signatures which are not well formed are left to the
+// validation of the transactions, as are the invalid ones. The valid ones
+// are returned, to be released once the transactions are validated
+func verifySignatures(data [][]byte, indexes []int) *msp.VerifiedSignatures {
+	identities := map[string]msp.Identity{}
+	var messages []msp.SignedMessage
+	add := func(chainID string, creator, msg, signature []byte) {
+		key := chainID + "\x00" + string(creator)
+		id, ok := identities[key]
+		if !ok {
+			if deserializer := mspmgmt.GetIdentityDeserializer(chainID); deserializer != nil {
+				id, _ = deserializer.DeserializeIdentity(creator)
+			}
+			identities[key] = id
+		}
+		if id != nil {
+			messages = append(messages, msp.SignedMessage{Identity: id, Msg: msg, Signature: signature})
+		}
+	}
+
+	for _, tIdx := range indexes {
+		env, err := utils.GetEnvelopeFromBlock(data[tIdx])
+		if err != nil || env == nil {
+			continue
+		}
+		payload, err := utils.GetPayload(env)
+		if err != nil || payload.Header == nil || payload.Header.ChannelHeader == nil || payload.Header.SignatureHeader == nil {
+			continue
+		}
+		chainID := payload.Header.ChannelHeader.ChannelId
+		add(chainID, payload.Header.SignatureHeader.Creator, env.Payload, env.Signature)
+
+		if common.HeaderType(payload.Header.ChannelHeader.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue
+		}
+		tx, err := utils.GetTransaction(payload.Data)
+		if err != nil {
+			continue
+		}
+		for _, act := range tx.Actions {
+			cap, err := utils.GetChaincodeActionPayload(act.Payload)
+			if err != nil || cap.Action == nil {
+				continue
+			}
+			prespBytes := cap.Action.ProposalResponsePayload
+			for _, endorsement := range cap.Action.Endorsements {
+				// the endorser signs the proposal response bytes followed by its identity
+				msg := make([]byte, 0, len(prespBytes)+len(endorsement.Endorser))
+				msg = append(append(msg, prespBytes...), endorsement.Endorser...)
+				add(chainID, endorsement.Endorser, msg, endorsement.Signature)
+			}
+		}
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+	logger.Debugf("Verifying %d signatures of %d transactions in a batch", len(messages), len(indexes))
+	_, verified := msp.VerifyBatch(messages)
+	return verified
+}
@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package txvalidator
+
+import (
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// namespaceValidation is a VSCC and a policy, as listed by LCCC for the
+// namespaces it validates
+type namespaceValidation struct {
+	vscc       string
+	policy     []byte
+	namespaces []string
+}
+
+// validatedNamespaces returns the chaincodes of a transaction on chain chainID
+// which are validated: the invoked chaincode, whether it writes or not,
+// followed by the chaincodes of the other namespaces the transaction writes
+// to, as the chaincodes invoked by the invoked chaincode do. Namespaces only
+// read from are not validated. The namespaces are stripped of the namespace
+// prefix of the chain, if any, to be looked up in LCCC
+func validatedNamespaces(envBytes []byte, chainID string, invoked string) ([]string, error) {
+	action, err := utils.GetActionFromEnvelope(envBytes)
+	if err != nil {
+		return nil, err
+	}
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err = txRWSet.Unmarshal(action.Results); err != nil {
+		return nil, err
+	}
+
+	namespaces := []string{invoked}
+	for _, nsRWSet := range txRWSet.NsRWs {
+		ns := ccprovider.ChaincodeName(chainID, nsRWSet.NameSpace)
+		// the writes to the LCCC namespace only come from LCCC invocations,
+		// which are not validated by the VSCC
+		if ns == invoked || ns == "lccc" || (len(nsRWSet.Writes) == 0 && len(nsRWSet.MetadataWrites) == 0) {
+			continue
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, nil
+}
+
+// groupNamespaces returns the VSCC invocations validating namespaces, looking
+// up the VSCC and the policy of every namespace. The namespaces with the same
+// VSCC and policy are validated by a single invocation, in the order of their
+// first namespace. The transaction is valid only if every invocation succeeds
+func groupNamespaces(namespaces []string, lookup func(ns string) (string, []byte, error)) ([]*namespaceValidation, error) {
+	var validations []*namespaceValidation
+	byKey := map[string]*namespaceValidation{}
+	for _, ns := range namespaces {
+		vscc, policy, err := lookup(ns)
+		if err != nil {
+			return nil, err
+		}
+		key := vscc + "\x00" + string(policy)
+		validation, ok := byKey[key]
+		if !ok {
+			validation = &namespaceValidation{vscc: vscc, policy: policy}
+			byKey[key] = validation
+			validations = append(validations, validation)
+		}
+		validation.namespaces = append(validation.namespaces, ns)
+	}
+	return validations, nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package txvalidator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatedNamespaces(t *testing.T) {
+	txRWSet := &rwset.TxReadWriteSet{NsRWs: []*rwset.NsReadWriteSet{
+		{NameSpace: "cc2", Writes: []*rwset.KVWrite{rwset.NewKVWrite("key", []byte("value"))}},
+		{NameSpace: "cc1", Writes: []*rwset.KVWrite{rwset.NewKVWrite("key", []byte("value"))}},
+		{NameSpace: "cc3", Reads: []*rwset.KVRead{rwset.NewKVRead("key", nil)}},
+		{NameSpace: "cc4", MetadataWrites: []*rwset.KVMetadataWrite{{Key: "key"}}},
+	}}
+	simRes, err := txRWSet.Marshal()
+	assert.NoError(t, err)
+	env, _, err := testutil.ConstructTransaction(t, simRes, false)
+	assert.NoError(t, err)
+	envBytes, err := utils.Marshal(env)
+	assert.NoError(t, err)
+
+	namespaces, err := validatedNamespaces(envBytes, "mychain", "cc1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cc1", "cc2", "cc4"}, namespaces)
+
+	// the invoked chaincode is validated even if it writes nothing
+	namespaces, err = validatedNamespaces(envBytes, "mychain", "cc3")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cc3", "cc2", "cc1", "cc4"}, namespaces)
+
+	_, err = validatedNamespaces([]byte("not a transaction"), "mychain", "cc1")
+	assert.Error(t, err)
+}
+
+// mockNamespacePrefixes maps the chains to their chaincode namespace prefix
+type mockNamespacePrefixes map[string]string
+
+func (m mockNamespacePrefixes) NamespacePrefix(chainID string) string {
+	return m[chainID]
+}
+
+func TestValidatedNamespacesWithPrefix(t *testing.T) {
+	ccprovider.RegisterNamespacePrefixSource(mockNamespacePrefixes{"tenantchain": "tenant1"})
+	defer ccprovider.RegisterNamespacePrefixSource(nil)
+
+	txRWSet := &rwset.TxReadWriteSet{NsRWs: []*rwset.NsReadWriteSet{
+		{NameSpace: "tenant1/cc1", Writes: []*rwset.KVWrite{rwset.NewKVWrite("key", []byte("value"))}},
+		{NameSpace: "tenant1/cc2", Writes: []*rwset.KVWrite{rwset.NewKVWrite("key", []byte("value"))}},
+		{NameSpace: "lccc", Reads: []*rwset.KVRead{rwset.NewKVRead("cc1", nil)}},
+	}}
+	simRes, err := txRWSet.Marshal()
+	assert.NoError(t, err)
+	env, _, err := testutil.ConstructTransaction(t, simRes, false)
+	assert.NoError(t, err)
+	envBytes, err := utils.Marshal(env)
+	assert.NoError(t, err)
+
+	// the namespaces are looked up in LCCC by the names of their chaincodes
+	namespaces, err := validatedNamespaces(envBytes, "tenantchain", "cc1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cc1", "cc2"}, namespaces)
+
+	validations, err := groupNamespaces(namespaces, func(ns string) (string, []byte, error) {
+		if ns != "cc1" && ns != "cc2" {
+			return "", nil, fmt.Errorf("chaincode %s not found", ns)
+		}
+		return "vscc", []byte("policy"), nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, validations, 1)
+}
+
+func TestGroupNamespaces(t *testing.T) {
+	definitions := map[string][2]string{
+		"cc1": {"vscc", "policy1"},
+		"cc2": {"custom", "policy1"},
+		"cc3": {"vscc", "policy1"},
+		"cc4": {"vscc", "policy2"},
+	}
+	lookup := func(ns string) (string, []byte, error) {
+		definition, ok := definitions[ns]
+		if !ok {
+			return "", nil, fmt.Errorf("chaincode %s not found", ns)
+		}
+		return definition[0], []byte(definition[1]), nil
+	}
+
+	validations, err := groupNamespaces([]string{"cc1", "cc2", "cc3", "cc4"}, lookup)
+	assert.NoError(t, err)
+	assert.Len(t, validations, 3)
+	assert.Equal(t, &namespaceValidation{vscc: "vscc", policy: []byte("policy1"), namespaces: []string{"cc1", "cc3"}}, validations[0])
+	assert.Equal(t, &namespaceValidation{vscc: "custom", policy: []byte("policy1"), namespaces: []string{"cc2"}}, validations[1])
+	assert.Equal(t, &namespaceValidation{vscc: "vscc", policy: []byte("policy2"), namespaces: []string{"cc4"}}, validations[2])
+
+	_, err = groupNamespaces([]string{"cc1", "unknown"}, lookup)
+	assert.Error(t, err)
+}
@@ -0,0 +1,268 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package txvalidator
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// autoPoolSize sizes the pool of every block from GOMAXPROCS, the kinds of
+// the transactions of the block which can be validated in parallel, and the
+// validation time of each kind observed on the previous blocks
+const autoPoolSize = 0
+
+const (
+	// minGoroutineWork is the validation time under which starting one more
+	// goroutine costs more than it saves
+	minGoroutineWork = 200 * time.Microsecond
+
+	// costWeight is the weight, in 1/costWeight, of a new observation in the
+	// moving average of the validation time of a kind of transaction
+	costWeight = 16
+)
+
+// The kinds of transactions, whose validation times differ widely
+const (
+	// endorserTxKind is an endorser transaction, validated by its VSCC
+	endorserTxKind = iota
+	// otherTxKind is any other transaction which is not a config
+	// transaction, such as an undecodable one, which is rejected early
+	otherTxKind
+	// configTxKind is a config transaction, which is applied on its own
+	configTxKind
+)
+
+// txMix is the number of transactions of each kind of a set of transactions
+// validated in parallel
+type txMix [configTxKind]int
+
+func (mix txMix) count() int {
+	count := 0
+	for _, n := range mix {
+		count += n
+	}
+	return count
+}
+
+// poolConfig sizes the pool of goroutines validating the transactions of a block
+type poolConfig struct {
+	// size is the number of goroutines, or autoPoolSize
+	size int
+
+	// maxSize bounds the auto sized pool, 0 bounds it by GOMAXPROCS
+	maxSize int
+
+	// costs are the validation times observed by the auto sized pool, a nil
+	// costs sizes it from GOMAXPROCS only
+	costs *txCosts
+}
+
+// txCosts is the moving average of the validation time of each kind of
+// transaction, observed on the committed blocks
+type txCosts struct {
+	lock  sync.Mutex
+	nanos txMix
+}
+
+// observe records that validating a transaction of kind took elapsed
+func (c *txCosts) observe(kind int, elapsed time.Duration) {
+	if c == nil || kind >= configTxKind {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.nanos[kind] == 0 {
+		c.nanos[kind] = int(elapsed)
+	} else {
+		c.nanos[kind] += (int(elapsed) - c.nanos[kind]) / costWeight
+	}
+	if c.nanos[kind] <= 0 {
+		c.nanos[kind] = 1
+	}
+	atomic.StoreUint64(&validationMetrics.NanosPerTransaction[kind], uint64(c.nanos[kind]))
+}
+
+// work returns the validation time expected for mix, and false if the
+// validation time of one of its kinds was not observed yet
+func (c *txCosts) work(mix txMix) (time.Duration, bool) {
+	if c == nil {
+		return 0, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var work time.Duration
+	for kind, n := range mix {
+		if n == 0 {
+			continue
+		}
+		if c.nanos[kind] == 0 {
+			return 0, false
+		}
+		work += time.Duration(n * c.nanos[kind])
+	}
+	return work, true
+}
+
+// getPoolConfig reads the sizing of the validation pool from the peer configuration
+func getPoolConfig() poolConfig {
+	conf := poolConfig{size: autoPoolSize, maxSize: viper.GetInt("peer.validator.maxPoolSize"), costs: &txCosts{}}
+	if size := viper.GetString("peer.validator.poolSize"); size != "" && size != "auto" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n <= 0 {
+			logger.Warningf("Invalid peer.validator.poolSize %s, sizing the validation pool automatically", size)
+		} else {
+			conf.size = n
+		}
+	}
+	if conf.maxSize < 0 {
+		logger.Warningf("Invalid peer.validator.maxPoolSize %d, bounding the validation pool by GOMAXPROCS", conf.maxSize)
+		conf.maxSize = 0
+	}
+	return conf
+}
+
+// workers returns the number of goroutines validating the transactions of mix.
+// The auto sized pool gets one goroutine per minGoroutineWork of expected
+// validation time, or as many as allowed until the validation time of each
+// kind of transaction of mix was observed
+func (conf poolConfig) workers(mix txMix) int {
+	txCount := mix.count()
+	workers := conf.size
+	if workers == autoPoolSize {
+		workers = conf.maxSize
+		if workers == 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+		if work, observed := conf.costs.work(mix); observed {
+			if needed := int((work + minGoroutineWork - 1) / minGoroutineWork); needed < workers {
+				workers = needed
+			}
+		}
+	}
+	if workers > txCount {
+		workers = txCount
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// ValidationMetrics shows the parallelism chosen to validate the committed blocks
+type ValidationMetrics struct {
+	// Blocks is the number of validated blocks
+	Blocks uint64
+
+	// Transactions is the number of validated transactions
+	Transactions uint64
+
+	// ParallelTransactions is the number of transactions validated by pools
+	// of more than one goroutine
+	ParallelTransactions uint64
+
+	// LastPoolSize is the number of goroutines of the last pool
+	LastPoolSize uint64
+
+	// MaxPoolSize is the number of goroutines of the largest pool
+	MaxPoolSize uint64
+
+	// BatchedTransactions is the number of transactions whose signatures
+	// were verified in a batch
+	BatchedTransactions uint64
+
+	// BatchedNanosPerTransaction and UnbatchedNanosPerTransaction are the
+	// validation times per transaction last measured by the auto signature
+	// batching, with and without batching
+	BatchedNanosPerTransaction   uint64
+	UnbatchedNanosPerTransaction uint64
+
+	// NanosPerTransaction is the moving average of the validation time of
+	// the endorser transactions and of the other transactions the auto sized
+	// pool is sized from
+	NanosPerTransaction [configTxKind]uint64
+}
+
+var validationMetrics ValidationMetrics
+
+// GetValidationMetrics returns the parallelism of the validation since the peer started
+func GetValidationMetrics() ValidationMetrics {
+	return ValidationMetrics{
+		Blocks:               atomic.LoadUint64(&validationMetrics.Blocks),
+		Transactions:         atomic.LoadUint64(&validationMetrics.Transactions),
+		ParallelTransactions: atomic.LoadUint64(&validationMetrics.ParallelTransactions),
+		LastPoolSize:         atomic.LoadUint64(&validationMetrics.LastPoolSize),
+		MaxPoolSize:          atomic.LoadUint64(&validationMetrics.MaxPoolSize),
+		BatchedTransactions:  atomic.LoadUint64(&validationMetrics.BatchedTransactions),
+
+		BatchedNanosPerTransaction:   atomic.LoadUint64(&validationMetrics.BatchedNanosPerTransaction),
+		UnbatchedNanosPerTransaction: atomic.LoadUint64(&validationMetrics.UnbatchedNanosPerTransaction),
+
+		NanosPerTransaction: [configTxKind]uint64{
+			atomic.LoadUint64(&validationMetrics.NanosPerTransaction[endorserTxKind]),
+			atomic.LoadUint64(&validationMetrics.NanosPerTransaction[otherTxKind]),
+		},
+	}
+}
+
+// recordPool records a pool of workers goroutines validating txCount transactions
+func recordPool(workers int, txCount int) {
+	atomic.AddUint64(&validationMetrics.Transactions, uint64(txCount))
+	atomic.StoreUint64(&validationMetrics.LastPoolSize, uint64(workers))
+	if workers > 1 {
+		atomic.AddUint64(&validationMetrics.ParallelTransactions, uint64(txCount))
+	}
+	for {
+		max := atomic.LoadUint64(&validationMetrics.MaxPoolSize)
+		if uint64(workers) <= max || atomic.CompareAndSwapUint64(&validationMetrics.MaxPoolSize, max, uint64(workers)) {
+			return
+		}
+	}
+}
+
+// runPool calls validate with every index of indexes from a pool of workers goroutines
+func runPool(workers int, indexes []int, validate func(tIdx int)) {
+	recordPool(workers, len(indexes))
+	if workers <= 1 {
+		for _, tIdx := range indexes {
+			validate(tIdx)
+		}
+		return
+	}
+	logger.Debugf("Validating %d transactions with %d goroutines", len(indexes), workers)
+	work := make(chan int, len(indexes))
+	for _, tIdx := range indexes {
+		work <- tIdx
+	}
+	close(work)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for tIdx := range work {
+				validate(tIdx)
+			}
+		}()
+	}
+	wg.Wait()
+}
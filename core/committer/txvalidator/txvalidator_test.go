@@ -17,7 +17,10 @@ limitations under the License.
 package txvalidator
 
 import (
+	"fmt"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
@@ -39,7 +42,7 @@ func TestKVLedgerBlockStorage(t *testing.T) {
 	ledger, _ := ledgermgmt.CreateLedger("TestLedger")
 	defer ledger.Close()
 
-	validator := &txValidator{&mocktxvalidator.Support{LedgerVal: ledger}, &validator.MockVsccValidator{}}
+	validator := &txValidator{&mocktxvalidator.Support{LedgerVal: ledger}, &validator.MockVsccValidator{}, poolConfig{}, newBatchingGate(batchingOff)}
 
 	bcInfo, _ := ledger.GetBlockchainInfo()
 	testutil.AssertEquals(t, bcInfo, &common.BlockchainInfo{
@@ -56,7 +59,8 @@ func TestKVLedgerBlockStorage(t *testing.T) {
 
 	validator.Validate(block)
 
-	txsfltr := util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	txsfltr, err := util.GetTxsFilter(block)
+	assert.NoError(t, err)
 
 	assert.True(t, !txsfltr.IsSet(uint(0)))
 	assert.True(t, !txsfltr.IsSet(uint(1)))
@@ -70,7 +74,7 @@ func TestNewTxValidator_DuplicateTransactions(t *testing.T) {
 	ledger, _ := ledgermgmt.CreateLedger("TestLedger")
 	defer ledger.Close()
 
-	validator := &txValidator{&mocktxvalidator.Support{LedgerVal: ledger}, &validator.MockVsccValidator{}}
+	validator := &txValidator{&mocktxvalidator.Support{LedgerVal: ledger}, &validator.MockVsccValidator{}, poolConfig{}, newBatchingGate(batchingOff)}
 
 	// Create simeple endorsement transaction
 	payload := &common.Payload{
@@ -120,7 +124,144 @@ func TestNewTxValidator_DuplicateTransactions(t *testing.T) {
 	// because it's already committed
 	validator.Validate(block)
 
-	txsfltr := util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	txsfltr, err := util.GetTxsFilter(block)
+	assert.NoError(t, err)
 
 	assert.True(t, txsfltr.IsSet(0))
 }
+
+func TestParallelValidation(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/txvalidatortest")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+	ledger, _ := ledgermgmt.CreateLedger("TestLedger")
+	defer ledger.Close()
+
+	validator := &txValidator{&mocktxvalidator.Support{LedgerVal: ledger}, &validator.MockVsccValidator{}, poolConfig{size: 2}, newBatchingGate(batchingOff)}
+
+	var simResults [][]byte
+	for i := 0; i < 5; i++ {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.SetState("ns1", fmt.Sprintf("key%d", i), []byte("value"))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		simResults = append(simResults, simRes)
+	}
+	block := testutil.ConstructBlock(t, simResults, true)
+	block.Data.Data = append(block.Data.Data, []byte("not a transaction"))
+	sequentialBlock := proto.Clone(block).(*common.Block)
+
+	before := GetValidationMetrics()
+	assert.NoError(t, validator.Validate(block))
+	after := GetValidationMetrics()
+	assert.Equal(t, uint64(1), after.Blocks-before.Blocks)
+	assert.Equal(t, uint64(6), after.Transactions-before.Transactions)
+	assert.Equal(t, uint64(6), after.ParallelTransactions-before.ParallelTransactions)
+	assert.Equal(t, uint64(2), after.LastPoolSize)
+	assert.True(t, after.MaxPoolSize >= 2)
+
+	// the parallel validation marks the same transactions as invalid as the sequential one
+	validator.pool = poolConfig{size: 1}
+	assert.NoError(t, validator.Validate(sequentialBlock))
+	assert.Equal(t, sequentialBlock.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER],
+		block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	txsfltr, err := util.GetTxsFilter(block)
+	assert.NoError(t, err)
+	assert.True(t, txsfltr.IsSet(5))
+	assert.Equal(t, uint64(1), GetValidationMetrics().LastPoolSize)
+	assert.Equal(t, uint64(6), GetValidationMetrics().ParallelTransactions-before.ParallelTransactions)
+}
+
+func TestPoolConfig(t *testing.T) {
+	defer viper.Set("peer.validator.poolSize", "auto")
+	defer viper.Set("peer.validator.maxPoolSize", 0)
+
+	viper.Set("peer.validator.poolSize", "auto")
+	viper.Set("peer.validator.maxPoolSize", 0)
+	conf := getPoolConfig()
+	assert.Equal(t, autoPoolSize, conf.size)
+	assert.Equal(t, 0, conf.maxSize)
+	assert.Equal(t, 1, conf.workers(txMix{endorserTxKind: 1}))
+	assert.Equal(t, runtime.GOMAXPROCS(0), conf.workers(txMix{endorserTxKind: runtime.GOMAXPROCS(0) + 10}))
+
+	viper.Set("peer.validator.maxPoolSize", 3)
+	assert.Equal(t, 3, getPoolConfig().workers(txMix{endorserTxKind: 10}))
+	assert.Equal(t, 2, getPoolConfig().workers(txMix{endorserTxKind: 1, otherTxKind: 1}))
+
+	viper.Set("peer.validator.poolSize", "8")
+	assert.Equal(t, 8, getPoolConfig().workers(txMix{endorserTxKind: 10}))
+
+	// invalid sizes fall back to the auto sized pool
+	viper.Set("peer.validator.poolSize", "-1")
+	assert.Equal(t, autoPoolSize, getPoolConfig().size)
+	viper.Set("peer.validator.poolSize", "many")
+	assert.Equal(t, autoPoolSize, getPoolConfig().size)
+}
+
+func TestPoolAutoTune(t *testing.T) {
+	conf := poolConfig{size: autoPoolSize, maxSize: 8, costs: &txCosts{}}
+
+	// the pool is as large as allowed until the costs of the kinds are observed
+	assert.Equal(t, 8, conf.workers(txMix{endorserTxKind: 20, otherTxKind: 20}))
+	conf.costs.observe(endorserTxKind, minGoroutineWork/2)
+	assert.Equal(t, 8, conf.workers(txMix{endorserTxKind: 20, otherTxKind: 20}))
+	assert.Equal(t, uint64(minGoroutineWork/2), GetValidationMetrics().NanosPerTransaction[endorserTxKind])
+
+	// one goroutine per minGoroutineWork of expected validation time
+	conf.costs.observe(otherTxKind, time.Microsecond)
+	assert.Equal(t, 5, conf.workers(txMix{endorserTxKind: 10}))
+	assert.Equal(t, 1, conf.workers(txMix{otherTxKind: 100}))
+	assert.Equal(t, 6, conf.workers(txMix{endorserTxKind: 10, otherTxKind: 200}))
+	assert.Equal(t, 8, conf.workers(txMix{endorserTxKind: 100}))
+
+	// the costs follow the observed validation times
+	for i := 0; i < 10*costWeight; i++ {
+		conf.costs.observe(endorserTxKind, 2*minGoroutineWork)
+	}
+	assert.Equal(t, 8, conf.workers(txMix{endorserTxKind: 10}))
+	assert.Equal(t, 3, conf.workers(txMix{endorserTxKind: 3}))
+
+	// config transactions are not sized from
+	conf.costs.observe(configTxKind, time.Hour)
+	assert.Equal(t, 3, conf.workers(txMix{endorserTxKind: 3}))
+}
+
+func TestBatchingGate(t *testing.T) {
+	defer viper.Set("peer.validator.signatureBatching", "auto")
+
+	viper.Set("peer.validator.signatureBatching", "on")
+	assert.True(t, getBatchingGate().batch(minBatchTransactions))
+	assert.False(t, getBatchingGate().batch(minBatchTransactions-1))
+	viper.Set("peer.validator.signatureBatching", "off")
+	assert.False(t, getBatchingGate().batch(minBatchTransactions))
+	viper.Set("peer.validator.signatureBatching", "sometimes")
+	assert.Equal(t, batchingAuto, getBatchingGate().mode)
+
+	// the auto mode alternates both ways, then keeps the fastest
+	measure := func(g *batchingGate, unbatched, batched time.Duration) {
+		for i := 0; i < 2*gateSamples; i++ {
+			b := g.batch(minBatchTransactions)
+			assert.Equal(t, i%2 == 1, b)
+			elapsed := unbatched
+			if b {
+				elapsed = batched
+			}
+			g.record(b, minBatchTransactions, elapsed)
+		}
+	}
+	g := newBatchingGate(batchingAuto)
+	measure(g, 2*time.Millisecond, time.Millisecond)
+	assert.True(t, g.batch(minBatchTransactions))
+	assert.Equal(t, uint64(time.Millisecond/minBatchTransactions), GetValidationMetrics().BatchedNanosPerTransaction)
+
+	g = newBatchingGate(batchingAuto)
+	measure(g, time.Millisecond, 2*time.Millisecond)
+	assert.False(t, g.batch(minBatchTransactions))
+
+	// and measures again after gateInterval sets of transactions
+	for g.sets < gateInterval {
+		g.batch(minBatchTransactions)
+	}
+	measure(g, 2*time.Millisecond, time.Millisecond)
+	assert.True(t, g.batch(minBatchTransactions))
+}
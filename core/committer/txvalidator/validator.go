@@ -18,9 +18,12 @@ package txvalidator
 
 import (
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/configtx"
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
 	coreUtil "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
@@ -44,6 +47,9 @@ type Support interface {
 
 	// Apply attempts to apply a configtx to become the new config
 	Apply(configtx *common.Envelope) error
+
+	// ChannelConfig returns the channel config of this chain
+	ChannelConfig() configtxapi.ChannelConfig
 }
 
 //Validator interface which defines API to validate block transactions
@@ -61,18 +67,22 @@ type vsccValidator interface {
 }
 
 // vsccValidator implementation which used to call
-// vscc chaincode and validate block transactions
+// vscc chaincode and validate block transactions.
+// The transactions are validated in parallel and a chaincode
+// provider holds the simulator of its context, so every
+// validation gets a provider of its own
 type vsccValidatorImpl struct {
-	support    Support
-	ccprovider ccprovider.ChaincodeProvider
+	support Support
 }
 
 // implementation of Validator interface, keeps
 // reference to the ledger to enable tx simulation
 // and execution of vscc
 type txValidator struct {
-	support Support
-	vscc    vsccValidator
+	support  Support
+	vscc     vsccValidator
+	pool     poolConfig
+	batching *batchingGate
 }
 
 var logger *logging.Logger // package-level logger
@@ -85,7 +95,7 @@ func init() {
 // NewTxValidator creates new transactions validator
 func NewTxValidator(support Support) Validator {
 	// Encapsulates interface implementation
-	return &txValidator{support, &vsccValidatorImpl{support: support, ccprovider: ccprovider.GetChaincodeProvider()}}
+	return &txValidator{support, &vsccValidatorImpl{support: support}, getPoolConfig(), getBatchingGate()}
 }
 
 func (v *txValidator) chainExists(chain string) bool {
@@ -97,86 +107,163 @@ func (v *txValidator) Validate(block *common.Block) error {
 	logger.Debug("START Block Validation")
 	defer logger.Debug("END Block Validation")
 	txsfltr := ledgerUtil.NewFilterBitArray(uint(len(block.Data.Data)))
+	valid := make([]bool, len(block.Data.Data))
+
+	// The config transactions change the config the later transactions are
+	// validated against, so they are applied one at a time and the transactions
+	// between them are validated in parallel
+	var indexes []int
+	kinds := make([]int, len(block.Data.Data))
 	for tIdx, d := range block.Data.Data {
-		// Start by marking transaction as invalid, before
-		// doing any validation checks.
-		txsfltr.Set(uint(tIdx))
-		if d != nil {
-			if env, err := utils.GetEnvelopeFromBlock(d); err != nil {
-				logger.Warningf("Error getting tx from block(%s)", err)
-			} else if env != nil {
-				// validate the transaction: here we check that the transaction
-				// is properly formed, properly signed and that the security
-				// chain binding proposal to endorsements to tx holds. We do
-				// NOT check the validity of endorsements, though. That's a
-				// job for VSCC below
-				logger.Debug("Validating transaction peer.ValidateTransaction()")
-				var payload *common.Payload
-				var err error
-				if payload, err = validation.ValidateTransaction(env); err != nil {
-					logger.Errorf("Invalid transaction with index %d, error %s", tIdx, err)
-					continue
-				}
-
-				chain := payload.Header.ChannelHeader.ChannelId
-				logger.Debug("Transaction is for chain %s", chain)
-
-				if !v.chainExists(chain) {
-					logger.Errorf("Dropping transaction for non-existent chain %s", chain)
-					continue
-				}
-
-				if common.HeaderType(payload.Header.ChannelHeader.Type) == common.HeaderType_ENDORSER_TRANSACTION {
-					// Check duplicate transactions
-					txID := payload.Header.ChannelHeader.TxId
-					if _, err := v.support.Ledger().GetTransactionByID(txID); err == nil {
-						logger.Warning("Duplicate transaction found, ", txID, ", skipping")
-						continue
-					}
-
-					//the payload is used to get headers
-					logger.Debug("Validating transaction vscc tx validate")
-					if err = v.vscc.VSCCValidateTx(payload, d); err != nil {
-						txID := txID
-						logger.Errorf("VSCCValidateTx for transaction txId = %s returned error %s", txID, err)
-						continue
-					}
-				} else if common.HeaderType(payload.Header.ChannelHeader.Type) == common.HeaderType_CONFIG {
-					configEnvelope, err := configtx.UnmarshalConfigEnvelope(payload.Data)
-					if err != nil {
-						err := fmt.Errorf("Error unmarshaling config which passed initial validity checks: %s", err)
-						logger.Critical(err)
-						return err
-					}
-
-					if err := v.support.Apply(configEnvelope.LastUpdate); err != nil {
-						err := fmt.Errorf("Error validating config which passed initial validity checks: %s", err)
-						logger.Critical(err)
-						return err
-					}
-					logger.Debugf("config transaction received for chain %s", chain)
-				}
-
-				if _, err := proto.Marshal(env); err != nil {
-					logger.Warningf("Cannot marshal transaction due to %s", err)
-					continue
-				}
-				// Succeeded to pass down here, transaction is valid,
-				// just unset the filter bit array flag.
-				txsfltr.Unset(uint(tIdx))
-			} else {
-				logger.Warning("Nil tx from block")
-			}
+		if kinds[tIdx] = getTxKind(d); kinds[tIdx] != configTxKind {
+			indexes = append(indexes, tIdx)
+			continue
+		}
+		v.validateInParallel(block.Data.Data, indexes, kinds, valid)
+		indexes = nil
+
+		var err error
+		if valid[tIdx], err = v.validateTx(tIdx, d); err != nil {
+			return err
+		}
+	}
+	v.validateInParallel(block.Data.Data, indexes, kinds, valid)
+	atomic.AddUint64(&validationMetrics.Blocks, 1)
+
+	for tIdx := range block.Data.Data {
+		// Transactions which did not pass validation are marked as invalid
+		if !valid[tIdx] {
+			txsfltr.Set(uint(tIdx))
 		}
 	}
 	// Initialize metadata structure
 	utils.InitBlockMetadata(block)
 	// Serialize invalid transaction bit array into block metadata field
-	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsfltr.ToBytes()
+	ledgerUtil.SetTxsFilter(block, txsfltr, v.support.ChannelConfig().Capabilities().TxsFilterRunLength())
 
 	return nil
 }
 
+// validateInParallel validates the transactions of the indexes, none of
+// which is a config transaction, with a pool of goroutines sized from their
+// kinds. Their signatures are verified in a batch beforehand when the
+// batching gate says so
+func (v *txValidator) validateInParallel(data [][]byte, indexes []int, kinds []int, valid []bool) {
+	if len(indexes) == 0 {
+		return
+	}
+	var mix txMix
+	for _, tIdx := range indexes {
+		mix[kinds[tIdx]]++
+	}
+	start := time.Now()
+	batched := v.batching.batch(len(indexes))
+	if batched {
+		defer verifySignatures(data, indexes).Release()
+	}
+	runPool(v.pool.workers(mix), indexes, func(tIdx int) {
+		txStart := time.Now()
+		// only config transactions fail the validation of the block
+		valid[tIdx], _ = v.validateTx(tIdx, data[tIdx])
+		v.pool.costs.observe(kinds[tIdx], time.Since(txStart))
+	})
+	v.batching.record(batched, len(indexes), time.Since(start))
+}
+
+// getTxKind returns the kind of the transaction, the transactions which
+// cannot be decoded are of otherTxKind
+func getTxKind(d []byte) int {
+	env, err := utils.GetEnvelopeFromBlock(d)
+	if err != nil || env == nil {
+		return otherTxKind
+	}
+	payload, err := utils.GetPayload(env)
+	if err != nil || payload.Header == nil || payload.Header.ChannelHeader == nil {
+		return otherTxKind
+	}
+	switch common.HeaderType(payload.Header.ChannelHeader.Type) {
+	case common.HeaderType_CONFIG:
+		return configTxKind
+	case common.HeaderType_ENDORSER_TRANSACTION:
+		return endorserTxKind
+	default:
+		return otherTxKind
+	}
+}
+
+// validateTx returns whether the transaction of index tIdx is valid, and an
+// error if the config of a config transaction cannot be applied
+func (v *txValidator) validateTx(tIdx int, d []byte) (bool, error) {
+	if d == nil {
+		return false, nil
+	}
+	env, err := utils.GetEnvelopeFromBlock(d)
+	if err != nil {
+		logger.Warningf("Error getting tx from block(%s)", err)
+		return false, nil
+	}
+	if env == nil {
+		logger.Warning("Nil tx from block")
+		return false, nil
+	}
+	// validate the transaction: here we check that the transaction
+	// is properly formed, properly signed and that the security
+	// chain binding proposal to endorsements to tx holds. We do
+	// NOT check the validity of endorsements, though. That's a
+	// job for VSCC below
+	logger.Debug("Validating transaction peer.ValidateTransaction()")
+	var payload *common.Payload
+	if payload, err = validation.ValidateTransaction(env); err != nil {
+		logger.Errorf("Invalid transaction with index %d, error %s", tIdx, err)
+		return false, nil
+	}
+
+	chain := payload.Header.ChannelHeader.ChannelId
+	logger.Debug("Transaction is for chain %s", chain)
+
+	if !v.chainExists(chain) {
+		logger.Errorf("Dropping transaction for non-existent chain %s", chain)
+		return false, nil
+	}
+
+	if common.HeaderType(payload.Header.ChannelHeader.Type) == common.HeaderType_ENDORSER_TRANSACTION {
+		// Check duplicate transactions
+		txID := payload.Header.ChannelHeader.TxId
+		if _, err := v.support.Ledger().GetTransactionByID(txID); err == nil {
+			logger.Warning("Duplicate transaction found, ", txID, ", skipping")
+			return false, nil
+		}
+
+		//the payload is used to get headers
+		logger.Debug("Validating transaction vscc tx validate")
+		if err = v.vscc.VSCCValidateTx(payload, d); err != nil {
+			logger.Errorf("VSCCValidateTx for transaction txId = %s returned error %s", txID, err)
+			return false, nil
+		}
+	} else if common.HeaderType(payload.Header.ChannelHeader.Type) == common.HeaderType_CONFIG {
+		configEnvelope, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+		if err != nil {
+			err := fmt.Errorf("Error unmarshaling config which passed initial validity checks: %s", err)
+			logger.Critical(err)
+			return false, err
+		}
+
+		if err := v.support.Apply(configEnvelope.LastUpdate); err != nil {
+			err := fmt.Errorf("Error validating config which passed initial validity checks: %s", err)
+			logger.Critical(err)
+			return false, err
+		}
+		logger.Debugf("config transaction received for chain %s", chain)
+	}
+
+	if _, err := proto.Marshal(env); err != nil {
+		logger.Warningf("Cannot marshal transaction due to %s", err)
+		return false, nil
+	}
+	// Succeeded to pass down here, transaction is valid
+	return true, nil
+}
+
 func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []byte) error {
 	// Chain ID
 	chainID := payload.Header.ChannelHeader.ChannelId
@@ -195,12 +282,13 @@ func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []b
 		return err
 	}
 
-	ctxt, err := v.ccprovider.GetContext(v.support.Ledger())
+	provider := ccprovider.GetChaincodeProvider()
+	ctxt, err := provider.GetContext(v.support.Ledger())
 	if err != nil {
 		logger.Errorf("Cannot obtain context for txid=%s, err %s", txid, err)
 		return err
 	}
-	defer v.ccprovider.ReleaseContext()
+	defer provider.ReleaseContext()
 
 	// get header extensions so we have the visibility field
 	hdrExt, err := utils.GetChaincodeHeaderExtension(payload.Header)
@@ -219,35 +307,54 @@ func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []b
 		return nil
 	}
 
-	// obtain name of the VSCC and the policy from LCCC
-	vscc, policy, err := v.ccprovider.GetCCValidationInfoFromLCCC(ctxt, txid, nil, chainID, hdrExt.ChaincodeId.Name)
+	// the invoked chaincode is validated by the VSCC and the policy LCCC lists
+	// for it. Once the NamespaceValidation capability is enabled on the channel,
+	// so is each of the other chaincodes the transaction writes to, the writes
+	// to a namespace LCCC does not know invalidating the transaction
+	namespaces := []string{hdrExt.ChaincodeId.Name}
+	if v.support.ChannelConfig().Capabilities().NamespaceValidation() {
+		namespaces, err = validatedNamespaces(envBytes, chainID, hdrExt.ChaincodeId.Name)
+		if err != nil {
+			logger.Errorf("Unable to get the namespaces written by txid %s, due to %s", txid, err)
+			return err
+		}
+	}
+	validations, err := groupNamespaces(namespaces, func(ns string) (string, []byte, error) {
+		return provider.GetCCValidationInfoFromLCCC(ctxt, txid, nil, chainID, ns)
+	})
 	if err != nil {
 		logger.Errorf("Unable to get chaincode data from LCCC for txid %s, due to %s", txid, err)
 		return err
 	}
 
-	// build arguments for VSCC invocation
-	// args[0] - function name (not used now)
-	// args[1] - serialized Envelope
-	// args[2] - serialized policy
-	args := [][]byte{[]byte(""), envBytes, policy}
+	for _, validation := range validations {
+		// build arguments for VSCC invocation
+		// args[0] - function name (not used now)
+		// args[1] - serialized Envelope
+		// args[2] - serialized policy
+		// args[3:] - the namespaces validated
+		args := [][]byte{[]byte(""), envBytes, validation.policy}
+		for _, ns := range validation.namespaces {
+			args = append(args, []byte(ns))
+		}
 
-	vscctxid := coreUtil.GenerateUUID()
+		vscctxid := coreUtil.GenerateUUID()
 
-	// Get chaincode version
-	version := coreUtil.GetSysCCVersion()
-	cccid := v.ccprovider.GetCCContext(chainID, vscc, version, vscctxid, true, nil)
+		// Get chaincode version
+		version := coreUtil.GetSysCCVersion()
+		cccid := provider.GetCCContext(chainID, validation.vscc, version, vscctxid, true, nil)
 
-	// invoke VSCC
-	logger.Info("Invoking VSCC txid", txid, "chaindID", chainID)
-	res, _, err := v.ccprovider.ExecuteChaincode(ctxt, cccid, args)
-	if err != nil {
-		logger.Errorf("Invoke VSCC failed for transaction txid=%s, error %s", txid, err)
-		return err
-	}
-	if res.Status != shim.OK {
-		logger.Errorf("VSCC check failed for transaction txid=%s, error %s", txid, res.Message)
-		return fmt.Errorf("%s", res.Message)
+		// invoke VSCC
+		logger.Info("Invoking VSCC", validation.vscc, "txid", txid, "chaindID", chainID, "namespaces", validation.namespaces)
+		res, _, err := provider.ExecuteChaincode(ctxt, cccid, args)
+		if err != nil {
+			logger.Errorf("Invoke VSCC failed for transaction txid=%s, error %s", txid, err)
+			return err
+		}
+		if res.Status != shim.OK {
+			logger.Errorf("VSCC check failed for transaction txid=%s, namespaces %v, error %s", txid, validation.namespaces, res.Message)
+			return fmt.Errorf("%s", res.Message)
+		}
 	}
 
 	return nil
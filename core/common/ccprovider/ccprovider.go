@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/golang/protobuf/proto"
 
@@ -107,6 +108,54 @@ func PutChaincodeIntoFS(depSpec *pb.ChaincodeDeploymentSpec) error {
 	return nil
 }
 
+//NamespacePrefixSource gives the chaincode namespace prefixes of the chains
+type NamespacePrefixSource interface {
+	//NamespacePrefix returns the chaincode namespace prefix of the chain, or
+	//"" if it has none
+	NamespacePrefix(chainID string) string
+}
+
+var namespacePrefixSource NamespacePrefixSource
+
+//RegisterNamespacePrefixSource sets the source of the chaincode namespace
+//prefixes, the ChaincodeNamespacePrefix value of the application config of
+//the chains. A prefix isolates the state and the containers of the chaincodes
+//of its chain from those of the chaincodes with the same name on the other
+//chains of the peer. It is set when the chain is created and cannot change
+func RegisterNamespacePrefixSource(source NamespacePrefixSource) {
+	namespacePrefixSource = source
+}
+
+//NamespacePrefix returns the chaincode namespace prefix of the chain, or ""
+//if it has none
+func NamespacePrefix(chainID string) string {
+	if namespacePrefixSource == nil {
+		return ""
+	}
+	return namespacePrefixSource.NamespacePrefix(chainID)
+}
+
+//Namespace returns the ledger namespace of the chaincode on the chain. It is
+//the name of the chaincode, behind the namespace prefix of the chain if any.
+//As LCCC does not allow "/" in chaincode names, a prefixed namespace cannot
+//be the one of another chaincode
+func Namespace(chainID, name string) string {
+	if prefix := NamespacePrefix(chainID); prefix != "" {
+		return prefix + "/" + name
+	}
+	return name
+}
+
+//ChaincodeName returns the name of the chaincode whose ledger namespace on
+//the chain is ns, the inverse of Namespace. The namespaces of the system
+//chaincodes are not prefixed
+func ChaincodeName(chainID, ns string) string {
+	if prefix := NamespacePrefix(chainID); prefix != "" {
+		return strings.TrimPrefix(ns, prefix+"/")
+	}
+	return ns
+}
+
 //CCContext pass this around instead of string of args
 type CCContext struct {
 	//ChainID chain id
@@ -129,6 +178,10 @@ type CCContext struct {
 	//from this to the chaincode
 	Proposal *pb.Proposal
 
+	//NamespacePrefix of the chain, if any, for a chaincode which is not a
+	//system chaincode. The chaincode gets its own instance for the prefix
+	NamespacePrefix string
+
 	//this is not set but computed (note that this is not exported. use GetCanonicalName)
 	canonicalName string
 }
@@ -144,7 +197,15 @@ func NewCCContext(cid, name, version, txid string, syscc bool, prop *pb.Proposal
 
 	canName := name + ":" + version
 
-	cccid := &CCContext{cid, name, version, txid, syscc, prop, canName}
+	var prefix string
+	if !syscc {
+		prefix = NamespacePrefix(cid)
+	}
+	if prefix != "" {
+		canName = canName + "/" + prefix
+	}
+
+	cccid := &CCContext{ChainID: cid, Name: name, Version: version, TxID: txid, Syscc: syscc, Proposal: prop, NamespacePrefix: prefix, canonicalName: canName}
 
 	ccproviderLogger.Infof("NewCCCC (chain=%s,chaincode=%s,version=%s,txid=%s,syscc=%t,proposal=%p,canname=%s", cid, name, version, txid, syscc, prop, cccid.canonicalName)
 
@@ -168,6 +229,10 @@ type ChaincodeData struct {
 	Escc    string `protobuf:"bytes,4,opt,name=escc"`
 	Vscc    string `protobuf:"bytes,5,opt,name=vscc"`
 	Policy  []byte `protobuf:"bytes,6,opt,name=policy"`
+	// InvocationPolicy is the marshalled SignaturePolicyEnvelope the creators
+	// of the proposals invoking the chaincode must satisfy, anyone may invoke
+	// the chaincode if it is nil
+	InvocationPolicy []byte `protobuf:"bytes,7,opt,name=invocationPolicy,proto3"`
 }
 
 //implement functions needed from proto.Message for proto's mar/unmarshal functions
@@ -188,10 +253,14 @@ func (*ChaincodeData) ProtoMessage() {}
 type ChaincodeProvider interface {
 	// GetContext returns a ledger context
 	GetContext(ledger ledger.PeerLedger) (context.Context, error)
+	// GetContextForSimulator returns a context in which chaincodes run with the supplied tx simulator
+	GetContextForSimulator(txsim ledger.TxSimulator) context.Context
 	// GetCCContext returns an opaque chaincode context
 	GetCCContext(cid, name, version, txid string, syscc bool, prop *pb.Proposal) interface{}
 	// GetCCValidationInfoFromLCCC returns the VSCC and the policy listed by LCCC for the supplied chaincode
 	GetCCValidationInfoFromLCCC(ctxt context.Context, txid string, prop *pb.Proposal, chainID string, chaincodeID string) (string, []byte, error)
+	// GetChaincodeDataFromLCCC returns the ChaincodeData listed by LCCC for the supplied chaincode
+	GetChaincodeDataFromLCCC(ctxt context.Context, txid string, prop *pb.Proposal, chainID string, chaincodeID string) (*ChaincodeData, error)
 	// ExecuteChaincode executes the chaincode given context and args
 	ExecuteChaincode(ctxt context.Context, cccid interface{}, args [][]byte) (*pb.Response, *pb.ChaincodeEvent, error)
 	// Execute executes the chaincode given context and spec (invocation or deploy)
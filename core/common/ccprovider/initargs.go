@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+//CheckInitArgsSchema checks that the optional arguments of the schema are the
+//last ones and that the patterns are valid regular expressions
+func CheckInitArgsSchema(schema *pb.ChaincodeInitArgsSchema) error {
+	_, err := compileInitArgsSchema(schema)
+	return err
+}
+
+//ValidateInitArgs validates the Init arguments of a chaincode against the schema
+//its package declares. The first argument is the name of the function, the
+//schema describing the arguments following it. Any arguments are valid without schema
+func ValidateInitArgs(schema *pb.ChaincodeInitArgsSchema, args [][]byte) error {
+	if schema == nil {
+		return nil
+	}
+	patterns, err := compileInitArgsSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	var params [][]byte
+	if len(args) > 0 {
+		params = args[1:]
+	}
+	if len(params) > len(schema.Args) {
+		return fmt.Errorf("expected at most %d arguments, got %d", len(schema.Args), len(params))
+	}
+
+	for i, argSchema := range schema.Args {
+		if i >= len(params) {
+			if !argSchema.Optional {
+				return fmt.Errorf("missing argument %d (%s)", i+1, argSchema.Name)
+			}
+			break
+		}
+		if err := validateInitArg(argSchema, patterns[i], params[i]); err != nil {
+			return fmt.Errorf("invalid argument %d (%s): %s", i+1, argSchema.Name, err)
+		}
+	}
+	return nil
+}
+
+func compileInitArgsSchema(schema *pb.ChaincodeInitArgsSchema) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, len(schema.GetArgs()))
+	optional := false
+	for i, argSchema := range schema.GetArgs() {
+		if _, ok := pb.ChaincodeArgSchema_Type_name[int32(argSchema.Type)]; !ok {
+			return nil, fmt.Errorf("unknown type %d of argument %d (%s)", argSchema.Type, i+1, argSchema.Name)
+		}
+		if optional && !argSchema.Optional {
+			return nil, fmt.Errorf("argument %d (%s) is required but follows an optional argument", i+1, argSchema.Name)
+		}
+		optional = argSchema.Optional
+		if argSchema.Pattern != "" {
+			pattern, err := regexp.Compile("^(?:" + argSchema.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of argument %d (%s): %s", i+1, argSchema.Name, err)
+			}
+			patterns[i] = pattern
+		}
+	}
+	return patterns, nil
+}
+
+func validateInitArg(argSchema *pb.ChaincodeArgSchema, pattern *regexp.Regexp, arg []byte) error {
+	switch argSchema.Type {
+	case pb.ChaincodeArgSchema_INTEGER:
+		if _, err := strconv.ParseInt(string(arg), 10, 64); err != nil {
+			return fmt.Errorf("%s is not an integer", arg)
+		}
+	case pb.ChaincodeArgSchema_BOOL:
+		if _, err := strconv.ParseBool(string(arg)); err != nil {
+			return fmt.Errorf("%s is not a boolean", arg)
+		}
+	case pb.ChaincodeArgSchema_JSON:
+		if !json.Valid(arg) {
+			return fmt.Errorf("%s is not valid JSON", arg)
+		}
+	}
+	if pattern != nil && !pattern.Match(arg) {
+		return fmt.Errorf("%s does not match %s", arg, argSchema.Pattern)
+	}
+	return nil
+}
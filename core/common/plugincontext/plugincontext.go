@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugincontext is the context given to the endorsement and
+// validation plugins (the ESCC and VSCC system chaincodes). It lets them read
+// the current config and state of a channel without importing the packages
+// of the peer, which registers the implementation when it is loaded
+package plugincontext
+
+import (
+	"github.com/hyperledger/fabric/common/policies"
+)
+
+// ChannelConfig is a read-only view of the current config of a channel. The
+// view follows the config updates of the channel, so plugins should not cache
+// what it returns across invocations
+type ChannelConfig interface {
+	// ChainID returns the ID of the channel
+	ChainID() string
+
+	// Sequence returns the sequence number of the current config
+	Sequence() uint64
+
+	// MSPIDs returns the sorted IDs of the MSPs of the organizations of the channel
+	MSPIDs() ([]string, error)
+
+	// GetPolicy returns the policy of the channel of the given name and true,
+	// or the default policy and false if there is no such policy
+	GetPolicy(name string) (policies.Policy, bool)
+
+	// ShimCapabilityDisabled returns true if chaincodes may not send messages
+	// of the given type (e.g. GET_HISTORY_FOR_KEY) on the channel
+	ShimCapabilityDisabled(msgType string) bool
+}
+
+// State is a read-only view of the state of a channel
+type State interface {
+	// GetState returns the value of a key of a namespace, nil if there is none
+	GetState(namespace string, key string) ([]byte, error)
+
+	// GetStateMultipleKeys returns the values of keys of a namespace
+	GetStateMultipleKeys(namespace string, keys []string) ([][]byte, error)
+
+	// Done releases the state, which must not be used anymore
+	Done()
+}
+
+// StateFetcher fetches the state of a channel
+type StateFetcher interface {
+	// FetchState returns a view of the current state of the channel, which
+	// holds back the commits of the channel until Done is called on it
+	FetchState() (State, error)
+}
+
+// Context gives plugins access to the channels joined by the peer
+type Context interface {
+	// ChannelConfig returns the config of a channel, an error if the peer
+	// has not joined it
+	ChannelConfig(chainID string) (ChannelConfig, error)
+
+	// StateFetcher returns the fetcher of the state of a channel, an error if
+	// the peer has not joined it
+	StateFetcher(chainID string) (StateFetcher, error)
+}
+
+var context Context
+
+// Register is called once by the peer to set the context of the plugins
+func Register(ctx Context) {
+	context = ctx
+}
+
+// Get returns the context of the plugins registered by the peer
+func Get() Context {
+	if context == nil {
+		panic("The plugin context must be set first via Register")
+	}
+	return context
+}
@@ -21,6 +21,8 @@ import (
 
 	"bytes"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/detached"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
@@ -50,9 +52,32 @@ func validateChaincodeProposalMessage(prop *pb.Proposal, hdr *common.Header) (*p
 
 	// TODO: should we check the payload as well?
 
+	// ensure that the detached payloads referenced, if any, are well formed
+	if err = checkDetachedPayloads(prop.Payload); err != nil {
+		return nil, err
+	}
+
 	return chaincodeHdrExt, nil
 }
 
+// checkDetachedPayloads returns an error if the references to the detached
+// payloads of the invocation in the ChaincodeProposalPayload cppBytes are not
+// well formed. Payloads which are not invocations are left to their handling
+func checkDetachedPayloads(cppBytes []byte) error {
+	cpp, err := utils.GetChaincodeProposalPayload(cppBytes)
+	if err != nil {
+		return nil
+	}
+	cis := &pb.ChaincodeInvocationSpec{}
+	if err = proto.Unmarshal(cpp.Input, cis); err != nil || cis.ChaincodeSpec == nil || cis.ChaincodeSpec.Input == nil {
+		return nil
+	}
+	if err = detached.CheckReferences(cis.ChaincodeSpec.Input.Detached); err != nil {
+		return fmt.Errorf("Invalid detached payload: %s", err)
+	}
+	return nil
+}
+
 // ValidateProposalMessage checks the validity of a SignedProposal message
 // this function returns Header and ChaincodeHeaderExtension messages since they
 // have been unmarshalled and validated
@@ -313,6 +338,11 @@ func validateEndorserTransaction(data []byte, hdr *common.Header) error {
 		if bytes.Compare(pHash, prp.ProposalHash) != 0 {
 			return fmt.Errorf("proposal hash does not match")
 		}
+
+		// ensure that the detached payloads referenced, if any, are well formed
+		if err = checkDetachedPayloads(cap.ChaincodeProposalPayload); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -52,6 +52,8 @@ type CCID struct {
 	PeerID        string
 	ChainID       string
 	Version       string
+	// NamespacePrefix of the chain for which the chaincode runs, if any
+	NamespacePrefix string
 }
 
 //GetName returns canonical chaincode name based on chain name
@@ -61,6 +63,9 @@ func (ccid *CCID) GetName() string {
 	}
 
 	name := ccid.ChaincodeSpec.ChaincodeId.Name
+	if ccid.NamespacePrefix != "" {
+		name = ccid.NamespacePrefix + "-" + name
+	}
 	if ccid.Version != "" {
 		name = name + "-" + ccid.Version
 	}
@@ -26,6 +26,8 @@ import (
 
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/config"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
 func TestHostConfig(t *testing.T) {
@@ -54,3 +56,16 @@ func TestGetDockerHostConfig(t *testing.T) {
 	testutil.AssertEquals(t, hostConfig.Memory, int64(1024*1024*1024*2))
 	testutil.AssertEquals(t, hostConfig.CPUShares, int64(1024*1024*1024*2))
 }
+
+func TestGetVMNameWithNamespacePrefix(t *testing.T) {
+	vm := &DockerVM{}
+	spec := &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: "mycc"}}
+
+	name, err := vm.GetVMName(ccintf.CCID{ChaincodeSpec: spec, PeerID: "peer0", Version: "0"})
+	testutil.AssertNoError(t, err, "Error getting VM name")
+	testutil.AssertEquals(t, name, "peer0-mycc-0")
+
+	name, err = vm.GetVMName(ccintf.CCID{ChaincodeSpec: spec, PeerID: "peer0", Version: "0", NamespacePrefix: "tenant1"})
+	testutil.AssertNoError(t, err, "Error getting VM name")
+	testutil.AssertEquals(t, name, "peer0-tenant1-mycc-0")
+}
@@ -0,0 +1,228 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockercontroller
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	cutil "github.com/hyperledger/fabric/core/container/util"
+)
+
+// gcClient is the part of the docker client used by the image GC
+type gcClient interface {
+	ListImages(opts docker.ListImagesOptions) ([]docker.APIImages, error)
+	ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error)
+	RemoveContainer(opts docker.RemoveContainerOptions) error
+	RemoveImageExtended(name string, opts docker.RemoveImageOptions) error
+}
+
+// GCResult lists what a collection removed, or would remove on a dry run
+type GCResult struct {
+	// Images are the images of chaincode versions no longer defined
+	Images []string
+
+	// Containers are the containers of these images
+	Containers []string
+
+	// Retained are the unused images still within the grace period
+	Retained []string
+}
+
+// ImageGC removes the images and containers this peer built for chaincode
+// versions which are no longer defined on any of its chains
+type ImageGC struct {
+	networkID   string
+	peerID      string
+	gracePeriod time.Duration
+	defined     func() ([]ccintf.CCID, error)
+	newClient   func() (gcClient, error)
+	now         func() time.Time
+
+	sync.Mutex
+	unusedSince map[string]time.Time
+	stop        chan struct{}
+}
+
+// NewImageGC creates an image GC for the images of the peer peerID of the
+// network networkID. defined returns the chaincode versions in use, and an
+// image is removed once it has been unused for longer than gracePeriod.
+func NewImageGC(networkID string, peerID string, gracePeriod time.Duration, defined func() ([]ccintf.CCID, error)) *ImageGC {
+	return &ImageGC{
+		networkID:   networkID,
+		peerID:      peerID,
+		gracePeriod: gracePeriod,
+		defined:     defined,
+		newClient: func() (gcClient, error) {
+			return cutil.NewDockerClient()
+		},
+		now:         time.Now,
+		unusedSince: make(map[string]time.Time),
+	}
+}
+
+// prefix returns the prefix GetVMName gives to the images of this peer
+func (gc *ImageGC) prefix() string {
+	if gc.networkID != "" {
+		return fmt.Sprintf("%s-%s-", gc.networkID, gc.peerID)
+	} else if gc.peerID != "" {
+		return gc.peerID + "-"
+	}
+	return ""
+}
+
+// imageName strips the tag of a docker image reference
+func imageName(ref string) string {
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i]
+	}
+	return ref
+}
+
+// Collect removes the images unused for longer than the grace period along
+// with their containers. A dry run only reports what would be removed and
+// does not start the grace period of newly unused images.
+func (gc *ImageGC) Collect(dryRun bool) (*GCResult, error) {
+	gc.Lock()
+	defer gc.Unlock()
+
+	prefix := gc.prefix()
+	if prefix == "" {
+		return nil, fmt.Errorf("Refusing to collect images without a peer ID to tell the images of this peer")
+	}
+	ccids, err := gc.defined()
+	if err != nil {
+		return nil, fmt.Errorf("Error listing the defined chaincodes: %s", err)
+	}
+	vm := &DockerVM{}
+	inUse := make(map[string]bool)
+	for _, ccid := range ccids {
+		ccid.NetworkID = gc.networkID
+		ccid.PeerID = gc.peerID
+		name, _ := vm.GetVMName(ccid)
+		inUse[strings.Replace(name, ":", "_", -1)] = true
+	}
+
+	client, err := gc.newClient()
+	if err != nil {
+		return nil, fmt.Errorf("Error creating docker client: %s", err)
+	}
+	images, err := client.ListImages(docker.ListImagesOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing images: %s", err)
+	}
+
+	now := gc.now()
+	result := &GCResult{}
+	unused := make(map[string]time.Time)
+	remove := make(map[string]bool)
+	for _, image := range images {
+		for _, ref := range image.RepoTags {
+			name := imageName(ref)
+			if !strings.HasPrefix(name, prefix) || inUse[name] {
+				continue
+			}
+			since, ok := gc.unusedSince[name]
+			if !ok {
+				since = now
+			}
+			unused[name] = since
+			if now.Sub(since) < gc.gracePeriod {
+				result.Retained = append(result.Retained, name)
+				continue
+			}
+			remove[name] = true
+			result.Images = append(result.Images, name)
+		}
+	}
+	if len(remove) > 0 {
+		containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
+		if err != nil {
+			return nil, fmt.Errorf("Error listing containers: %s", err)
+		}
+		for _, c := range containers {
+			if !remove[imageName(c.Image)] {
+				continue
+			}
+			id := c.ID
+			if len(c.Names) > 0 {
+				id = strings.TrimPrefix(c.Names[0], "/")
+			}
+			result.Containers = append(result.Containers, id)
+		}
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	for _, id := range result.Containers {
+		if err := client.RemoveContainer(docker.RemoveContainerOptions{ID: id, Force: true}); err != nil {
+			dockerLogger.Warningf("Error removing container %s: %s", id, err)
+		} else {
+			dockerLogger.Infof("Removed container %s of an undefined chaincode version", id)
+		}
+	}
+	for _, name := range result.Images {
+		if err := client.RemoveImageExtended(name, docker.RemoveImageOptions{}); err != nil {
+			dockerLogger.Warningf("Error removing image %s: %s", name, err)
+			continue
+		}
+		dockerLogger.Infof("Removed image %s of an undefined chaincode version", name)
+		delete(unused, name)
+	}
+	// images which were removed or are in use again start over when unused
+	gc.unusedSince = unused
+	return result, nil
+}
+
+// Start collects the images every interval until Stop is called
+func (gc *ImageGC) Start(interval time.Duration) {
+	gc.Lock()
+	defer gc.Unlock()
+	if gc.stop != nil {
+		return
+	}
+	gc.stop = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := gc.Collect(false); err != nil {
+					dockerLogger.Errorf("Chaincode image collection failed: %s", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(gc.stop)
+}
+
+// Stop stops the periodic collection
+func (gc *ImageGC) Stop() {
+	gc.Lock()
+	defer gc.Unlock()
+	if gc.stop != nil {
+		close(gc.stop)
+		gc.stop = nil
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockercontroller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockGCClient struct {
+	images            []docker.APIImages
+	containers        []docker.APIContainers
+	removedImages     []string
+	removedContainers []string
+}
+
+func (c *mockGCClient) ListImages(opts docker.ListImagesOptions) ([]docker.APIImages, error) {
+	return c.images, nil
+}
+
+func (c *mockGCClient) ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error) {
+	return c.containers, nil
+}
+
+func (c *mockGCClient) RemoveContainer(opts docker.RemoveContainerOptions) error {
+	c.removedContainers = append(c.removedContainers, opts.ID)
+	return nil
+}
+
+func (c *mockGCClient) RemoveImageExtended(name string, opts docker.RemoveImageOptions) error {
+	c.removedImages = append(c.removedImages, name)
+	return nil
+}
+
+func ccid(name string, version string) ccintf.CCID {
+	return ccintf.CCID{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: name}}, Version: version}
+}
+
+func TestImageGC(t *testing.T) {
+	client := &mockGCClient{
+		images: []docker.APIImages{
+			{ID: "1", RepoTags: []string{"dev-peer0-mycc-1.0:latest"}},
+			{ID: "2", RepoTags: []string{"dev-peer0-mycc-2.0:latest"}},
+			{ID: "3", RepoTags: []string{"dev-peer1-mycc-1.0:latest"}},
+			{ID: "4", RepoTags: []string{"hyperledger/fabric-ccenv:latest"}},
+			{ID: "5", RepoTags: []string{"dev-peer0-tenant1-mycc-1.0:latest"}},
+		},
+		containers: []docker.APIContainers{
+			{ID: "a", Image: "dev-peer0-mycc-1.0", Names: []string{"/dev-peer0-mycc-1.0"}},
+			{ID: "b", Image: "dev-peer0-mycc-2.0", Names: []string{"/dev-peer0-mycc-2.0"}},
+		},
+	}
+	now := time.Now()
+	gc := NewImageGC("dev", "peer0", time.Hour, func() ([]ccintf.CCID, error) {
+		prefixed := ccid("mycc", "1.0")
+		prefixed.NamespacePrefix = "tenant1"
+		return []ccintf.CCID{ccid("mycc", "2.0"), prefixed}, nil
+	})
+	gc.newClient = func() (gcClient, error) { return client, nil }
+	gc.now = func() time.Time { return now }
+
+	// a dry run does not start the grace period
+	res, err := gc.Collect(true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dev-peer0-mycc-1.0"}, res.Retained)
+	assert.Empty(t, res.Images)
+	now = now.Add(2 * time.Hour)
+	res, err = gc.Collect(true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dev-peer0-mycc-1.0"}, res.Retained)
+
+	// the images of other peers and the images in use are left alone
+	res, err = gc.Collect(false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dev-peer0-mycc-1.0"}, res.Retained)
+	assert.Empty(t, client.removedImages)
+
+	now = now.Add(30 * time.Minute)
+	res, err = gc.Collect(true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dev-peer0-mycc-1.0"}, res.Retained)
+
+	now = now.Add(time.Hour)
+	res, err = gc.Collect(true)
+	assert.NoError(t, err)
+	assert.Equal(t, &GCResult{Images: []string{"dev-peer0-mycc-1.0"}, Containers: []string{"dev-peer0-mycc-1.0"}}, res)
+	assert.Empty(t, client.removedImages, "A dry run should not remove anything")
+
+	res, err = gc.Collect(false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dev-peer0-mycc-1.0"}, res.Images)
+	assert.Equal(t, []string{"dev-peer0-mycc-1.0"}, client.removedContainers)
+	assert.Equal(t, []string{"dev-peer0-mycc-1.0"}, client.removedImages)
+	assert.Empty(t, gc.unusedSince)
+}
+
+func TestImageGCWithoutPeerID(t *testing.T) {
+	gc := NewImageGC("", "", 0, func() ([]ccintf.CCID, error) { return nil, nil })
+	gc.newClient = func() (gcClient, error) { return &mockGCClient{}, nil }
+	_, err := gc.Collect(false)
+	assert.Error(t, err, "Collecting without a peer ID could remove the images of other peers")
+}
@@ -85,6 +85,9 @@ type blocksProviderImpl struct {
 
 	gossip GossipServiceAdapter
 
+	// compression is the compression requested for the delivered blocks
+	compression orderer.Compression
+
 	done int32
 }
 
@@ -103,6 +106,17 @@ func NewBlocksProvider(chainID string, client BlocksDeliverer, gossip GossipServ
 	}
 }
 
+// NewBlocksProviderWithCompression constructor function to create blocks deliverer instance
+// which requests the ordering service to send the blocks compressed with given algorithm
+func NewBlocksProviderWithCompression(chainID string, client BlocksDeliverer, gossip GossipServiceAdapter, compression orderer.Compression) BlocksProvider {
+	return &blocksProviderImpl{
+		chainID:     chainID,
+		client:      client,
+		gossip:      gossip,
+		compression: compression,
+	}
+}
+
 // DeliverBlocks used to pull out blocks from the ordering service to
 // distributed them across peers
 func (b *blocksProviderImpl) DeliverBlocks() {
@@ -120,21 +134,14 @@ func (b *blocksProviderImpl) DeliverBlocks() {
 			}
 			logger.Warning("Got error ", t)
 		case *orderer.DeliverResponse_Block:
-			seqNum := t.Block.Header.Number
-
-			numberOfPeers := len(b.gossip.PeersOfChannel(gossipcommon.ChainID(b.chainID)))
-			// Create payload with a block received
-			payload := createPayload(seqNum, t.Block)
-			// Use payload to create gossip message
-			gossipMsg := createGossipMsg(b.chainID, payload)
-
-			logger.Debugf("Adding payload locally, buffer seqNum = [%d], peers number [%d]", seqNum, numberOfPeers)
-			// Add payload to local state payloads buffer
-			b.gossip.AddPayload(b.chainID, payload)
-
-			// Gossip messages with other nodes
-			logger.Debugf("Gossiping block [%d], peers number [%d]", seqNum, numberOfPeers)
-			b.gossip.Gossip(gossipMsg)
+			b.disseminate(t.Block)
+		case *orderer.DeliverResponse_CompressedBlock:
+			block, err := utils.DecompressBlock(t.CompressedBlock)
+			if err != nil {
+				logger.Warningf("Received malformed compressed block: %s", err)
+				return
+			}
+			b.disseminate(block)
 		default:
 			logger.Warning("Received unknown: ", t)
 			return
@@ -142,6 +149,28 @@ func (b *blocksProviderImpl) DeliverBlocks() {
 	}
 }
 
+// disseminate commits the block locally and gossips it to the other peers
+func (b *blocksProviderImpl) disseminate(block *common.Block) {
+	seqNum := block.Header.Number
+
+	numberOfPeers := len(b.gossip.PeersOfChannel(gossipcommon.ChainID(b.chainID)))
+	// Create payload with a block received
+	payload := createPayload(seqNum, block)
+	// Use payload to create gossip message
+	gossipMsg := createGossipMsg(b.chainID, payload)
+
+	logger.Debugf("Adding payload locally, buffer seqNum = [%d], peers number [%d]", seqNum, numberOfPeers)
+	// Add payload to local state payloads buffer, waiting for the ledger to
+	// catch up if it is too far behind
+	if err := b.gossip.AddPayload(b.chainID, payload); err != nil {
+		logger.Warningf("Failed adding payload of block [%d]: %s", seqNum, err)
+	}
+
+	// Gossip messages with other nodes
+	logger.Debugf("Gossiping block [%d], peers number [%d]", seqNum, numberOfPeers)
+	b.gossip.Gossip(gossipMsg)
+}
+
 // Stops blocks delivery provider
 func (b *blocksProviderImpl) Stop() {
 	atomic.StoreInt32(&b.done, 1)
@@ -184,9 +213,10 @@ func (b *blocksProviderImpl) seekOldest() error {
 				SignatureHeader: &common.SignatureHeader{},
 			},
 			Data: utils.MarshalOrPanic(&orderer.SeekInfo{
-				Start:    &orderer.SeekPosition{Type: &orderer.SeekPosition_Oldest{Oldest: &orderer.SeekOldest{}}},
-				Stop:     &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: math.MaxUint64}}},
-				Behavior: orderer.SeekInfo_BLOCK_UNTIL_READY,
+				Start:       &orderer.SeekPosition{Type: &orderer.SeekPosition_Oldest{Oldest: &orderer.SeekOldest{}}},
+				Stop:        &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: math.MaxUint64}}},
+				Behavior:    orderer.SeekInfo_BLOCK_UNTIL_READY,
+				Compression: b.compression,
 			}),
 		}),
 	})
@@ -202,9 +232,10 @@ func (b *blocksProviderImpl) seekLatestFromCommitter(height uint64) error {
 				SignatureHeader: &common.SignatureHeader{},
 			},
 			Data: utils.MarshalOrPanic(&orderer.SeekInfo{
-				Start:    &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: height}}},
-				Stop:     &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: math.MaxUint64}}},
-				Behavior: orderer.SeekInfo_BLOCK_UNTIL_READY,
+				Start:       &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: height}}},
+				Stop:        &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: math.MaxUint64}}},
+				Behavior:    orderer.SeekInfo_BLOCK_UNTIL_READY,
+				Compression: b.compression,
 			}),
 		}),
 	})
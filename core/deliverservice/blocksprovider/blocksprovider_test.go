@@ -17,12 +17,15 @@ package blocksprovider
 
 import (
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/deliverservice/mocks"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -142,3 +145,66 @@ func TestBlocksProvider_CheckTerminationDeliveryResponseStatus(t *testing.T) {
 		}
 	}
 }
+
+func TestBlocksProvider_CompressedBlocks(t *testing.T) {
+	deliverer := &mocks.MockBlocksDeliverer{}
+	sent := make(chan *orderer.SeekInfo, 1)
+	deliverer.MockRecv = func(mock *mocks.MockBlocksDeliverer) (*orderer.DeliverResponse, error) {
+		block := &common.Block{
+			Header: &common.BlockHeader{Number: mock.Pos},
+			Data:   &common.BlockData{Data: [][]byte{}},
+		}
+		mock.Pos++
+		compressed, err := utils.CompressBlock(block, orderer.Compression_GZIP)
+		if err != nil {
+			return nil, err
+		}
+		return &orderer.DeliverResponse{
+			Type: &orderer.DeliverResponse_CompressedBlock{CompressedBlock: compressed},
+		}, nil
+	}
+
+	gossipServiceAdapter := &mocks.MockGossipServiceAdapter{}
+	provider := NewBlocksProviderWithCompression("***TEST_CHAINID***", &seekRecorder{deliverer, sent}, gossipServiceAdapter, orderer.Compression_GZIP)
+
+	provider.RequestBlocks(&mocks.MockLedgerInfo{0})
+	assert.Equal(t, orderer.Compression_GZIP, (<-sent).Compression)
+
+	ready := make(chan struct{})
+	go func() {
+		provider.DeliverBlocks()
+		close(ready)
+	}()
+
+	time.Sleep(time.Duration(10) * time.Millisecond)
+	provider.Stop()
+
+	select {
+	case <-ready:
+		// All compressed blocks received should have been decompressed, gossiped and locally committed
+		assert.True(t, atomic.LoadInt32(&deliverer.RecvCnt) > 0)
+		assert.Equal(t, atomic.LoadInt32(&deliverer.RecvCnt), atomic.LoadInt32(&gossipServiceAdapter.AddPayloadsCnt))
+		assert.Equal(t, atomic.LoadInt32(&deliverer.RecvCnt), atomic.LoadInt32(&gossipServiceAdapter.GossipCallsCnt))
+	case <-time.After(time.Duration(1) * time.Second):
+		t.Fatal("Test hasn't finished in timely manner, failing.")
+	}
+}
+
+// seekRecorder records the seek info of the requests sent through the deliverer
+type seekRecorder struct {
+	*mocks.MockBlocksDeliverer
+	sent chan *orderer.SeekInfo
+}
+
+func (sr *seekRecorder) Send(env *common.Envelope) error {
+	payload, err := utils.GetPayload(env)
+	if err != nil {
+		return err
+	}
+	seekInfo := &orderer.SeekInfo{}
+	if err = proto.Unmarshal(payload.Data, seekInfo); err != nil {
+		return err
+	}
+	sr.sent <- seekInfo
+	return sr.MockBlocksDeliverer.Send(env)
+}
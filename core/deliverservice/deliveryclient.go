@@ -18,6 +18,7 @@ package deliverclient
 
 import (
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -44,6 +45,10 @@ type DeliverService interface {
 	// ordering service endpoint
 	JoinChain(chainID string, ledgerInfo blocksprovider.LedgerInfo) error
 
+	// LeaveChain stops pulling the blocks of the given chain from the
+	// ordering service
+	LeaveChain(chainID string) error
+
 	// Stop terminates delivery service and closes the connection
 	Stop()
 }
@@ -91,6 +96,8 @@ type deliverServiceImpl struct {
 	stopping bool
 
 	conn *grpc.ClientConn
+	// releaseConn is called instead of closing conn when it is pooled
+	releaseConn func()
 }
 
 // NewDeliverService construction function to create and initialize
@@ -109,14 +116,25 @@ func NewDeliverService(gossip blocksprovider.GossipServiceAdapter) (DeliverServi
 	} else {
 		dialOpts = append(dialOpts, grpc.WithInsecure())
 	}
-
-	conn, err := grpc.Dial(endpoint, dialOpts...)
+	// Follow the changes of the DNS records of the ordering service
+	dialOpts = append(dialOpts, comm.DNSDialOptions()...)
+
+	// The connection to the ordering service is shared through the connection pool
+	pool := comm.GetConnectionPool()
+	connKey := "deliver/" + endpoint
+	conn, err := pool.Get(connKey, func() (*grpc.ClientConn, error) {
+		return grpc.Dial(endpoint, dialOpts...)
+	})
 	if err != nil {
 		logger.Errorf("Cannot dial to %s, because of %s", endpoint, err)
 		return nil, err
 	}
 
-	return NewFactoryDeliverService(gossip, &blocksDelivererFactoryImpl{conn}, conn), nil
+	ds := NewFactoryDeliverService(gossip, &blocksDelivererFactoryImpl{conn}, conn).(*deliverServiceImpl)
+	ds.releaseConn = func() {
+		pool.Release(connKey, conn)
+	}
+	return ds, nil
 }
 
 // NewFactoryDeliverService construction function to create and initialize
@@ -152,7 +170,7 @@ func (d *deliverServiceImpl) JoinChain(chainID string, ledgerInfo blocksprovider
 			return errors.New("Delivery service is stopping cannot join a new channel")
 		}
 
-		d.clients[chainID] = blocksprovider.NewBlocksProvider(chainID, abc, d.gossip)
+		d.clients[chainID] = blocksprovider.NewBlocksProviderWithCompression(chainID, abc, d.gossip, blocksCompression())
 
 		if err := d.clients[chainID].RequestBlocks(ledgerInfo); err == nil {
 			// Start reading blocks from ordering service in case this peer is a leader for specified chain
@@ -162,6 +180,34 @@ func (d *deliverServiceImpl) JoinChain(chainID string, ledgerInfo blocksprovider
 	return nil
 }
 
+// LeaveChain stops the blocks provider of the given chainID, if the peer pulls
+// its blocks from the ordering service
+func (d *deliverServiceImpl) LeaveChain(chainID string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if client, exists := d.clients[chainID]; exists {
+		client.Stop()
+		delete(d.clients, chainID)
+	}
+	return nil
+}
+
+// blocksCompression returns the compression to request for the blocks delivered by the
+// ordering service, as configured by peer.committer.ledger.compression
+func blocksCompression() orderer.Compression {
+	name := viper.GetString("peer.committer.ledger.compression")
+	if name == "" {
+		return orderer.Compression_NONE
+	}
+	compression, ok := orderer.Compression_value[strings.ToUpper(name)]
+	if !ok {
+		logger.Warningf("Unknown blocks compression %s, blocks will be delivered uncompressed", name)
+		return orderer.Compression_NONE
+	}
+	return orderer.Compression(compression)
+}
+
 // Stop all service and release resources
 func (d *deliverServiceImpl) Stop() {
 	d.lock.Lock()
@@ -169,7 +215,10 @@ func (d *deliverServiceImpl) Stop() {
 	// Marking flag to indicate the shutdown of the delivery service
 	d.stopping = true
 	// Closing grpc connection
-	if d.conn != nil {
+	if d.releaseConn != nil {
+		d.releaseConn()
+		d.releaseConn = nil
+	} else if d.conn != nil {
 		d.conn.Close()
 	}
 
@@ -62,3 +62,32 @@ func TestNewDeliverService(t *testing.T) {
 	assert.Equal(t, atomic.LoadInt32(&blocksDeliverer.RecvCnt), atomic.LoadInt32(&gossipServiceAdapter.GossipCallsCnt))
 
 }
+
+func TestDeliverServiceLeaveChain(t *testing.T) {
+	viper.Set("peer.gossip.orgLeader", true)
+
+	gossipServiceAdapter := &mocks.MockGossipServiceAdapter{}
+	factory := &struct{ mockBlocksDelivererFactory }{}
+
+	blocksDeliverer := &mocks.MockBlocksDeliverer{}
+	blocksDeliverer.MockRecv = mocks.MockRecv
+
+	factory.mockCreate = func() (blocksprovider.BlocksDeliverer, error) {
+		return blocksDeliverer, nil
+	}
+
+	service := NewFactoryDeliverService(gossipServiceAdapter, factory, nil)
+	defer service.Stop()
+	service.JoinChain("TEST_CHAINID", &mocks.MockLedgerInfo{0})
+	time.Sleep(time.Duration(10) * time.Millisecond)
+
+	assert.NilError(t, service.LeaveChain("TEST_CHAINID"))
+	// Make sure the blocks provider stopped
+	time.Sleep(time.Duration(500) * time.Millisecond)
+	received := atomic.LoadInt32(&blocksDeliverer.RecvCnt)
+	time.Sleep(time.Duration(100) * time.Millisecond)
+	assert.Equal(t, atomic.LoadInt32(&blocksDeliverer.RecvCnt), received)
+
+	// Leaving a chain the service doesn't pull the blocks of does nothing
+	assert.NilError(t, service.LeaveChain("OTHER_CHAINID"))
+}
@@ -0,0 +1,239 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package detached resolves the detached payloads of chaincode invocations:
+// payloads kept out of the transactions, which only carry their URI and the
+// SHA256 hash of their content. The peer fetches the contents through the
+// resolver of the scheme of their URI, once the scheme is allowed by
+// peer.detachedPayloads.schemes, and verifies their hash before handing them
+// to the chaincode
+package detached
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/core/limits"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+)
+
+var logger = logging.MustGetLogger("detached")
+
+// defaultMaxSize bounds the size of a detached payload when
+// peer.detachedPayloads.maxSize is not set
+const defaultMaxSize = 64 * 1024 * 1024
+
+// defaultTimeout bounds the time taken to fetch a detached payload when
+// peer.detachedPayloads.timeout is not set
+const defaultTimeout = 30 * time.Second
+
+// Resolver fetches the content of the detached payloads whose URI has one of
+// the schemes it is registered for
+type Resolver interface {
+	// Resolve returns the content at uri, reading at most maxSize bytes
+	Resolve(uri *url.URL, maxSize int64) ([]byte, error)
+}
+
+// builtinResolvers are the resolvers of the schemes the peer knows, which
+// are only used once allowed by peer.detachedPayloads.schemes
+var builtinResolvers = map[string]Resolver{
+	"file":  fileResolver{},
+	"http":  httpResolver{},
+	"https": httpResolver{},
+}
+
+var resolvers = struct {
+	sync.RWMutex
+	byScheme map[string]Resolver
+}{byScheme: map[string]Resolver{}}
+
+// RegisterResolver registers r for the URIs of scheme, replacing the resolver
+// previously registered for it, if any. It is only used once the scheme is
+// allowed by peer.detachedPayloads.schemes
+func RegisterResolver(scheme string, r Resolver) {
+	resolvers.Lock()
+	defer resolvers.Unlock()
+	resolvers.byScheme[scheme] = r
+}
+
+// allowedSchemes returns the schemes of peer.detachedPayloads.schemes, the
+// only ones whose URIs are resolved. None are by default
+func allowedSchemes() map[string]bool {
+	allowed := map[string]bool{}
+	for _, scheme := range viper.GetStringSlice("peer.detachedPayloads.schemes") {
+		allowed[strings.ToLower(scheme)] = true
+	}
+	return allowed
+}
+
+func getResolver(scheme string) (Resolver, bool) {
+	scheme = strings.ToLower(scheme)
+	if !allowedSchemes()[scheme] {
+		return nil, false
+	}
+	resolvers.RLock()
+	r, ok := resolvers.byScheme[scheme]
+	resolvers.RUnlock()
+	if !ok {
+		r, ok = builtinResolvers[scheme]
+	}
+	return r, ok
+}
+
+// Enabled returns whether the peer resolves the detached payloads of the
+// invocations it endorses, as set by peer.detachedPayloads.resolve. Peers
+// which do not resolve them only check the references are well formed
+func Enabled() bool {
+	return viper.GetBool("peer.detachedPayloads.resolve")
+}
+
+func maxSize() int64 {
+	if max := int64(viper.GetInt("peer.detachedPayloads.maxSize")); max > 0 {
+		return max
+	}
+	return defaultMaxSize
+}
+
+func timeout() time.Duration {
+	if t := viper.GetDuration("peer.detachedPayloads.timeout"); t > 0 {
+		return t
+	}
+	return defaultTimeout
+}
+
+// CheckReferences returns an error if one of refs is not well formed, that is
+// if its URI has no scheme or its hash is not a SHA256 hash
+func CheckReferences(refs []*pb.DetachedPayload) error {
+	for i, ref := range refs {
+		if ref == nil {
+			return fmt.Errorf("Detached payload %d is nil", i)
+		}
+		u, err := url.Parse(ref.Uri)
+		if err != nil {
+			return fmt.Errorf("Invalid URI of detached payload %d: %s", i, err)
+		}
+		if u.Scheme == "" {
+			return fmt.Errorf("URI %s of detached payload %d has no scheme", ref.Uri, i)
+		}
+		if len(ref.Hash) != sha256.Size {
+			return fmt.Errorf("Hash of detached payload %s must be a %d byte SHA256 hash, not %d bytes", ref.Uri, sha256.Size, len(ref.Hash))
+		}
+	}
+	return nil
+}
+
+// Fetch returns the content of the detached payload ref once its hash is
+// verified. The errors of the resolvers are logged, not returned, as they
+// may tell what the peer can reach to the client which chose the URI
+func Fetch(ref *pb.DetachedPayload) ([]byte, error) {
+	return fetch(ref, maxSize())
+}
+
+func fetch(ref *pb.DetachedPayload, maxSize int64) ([]byte, error) {
+	if err := CheckReferences([]*pb.DetachedPayload{ref}); err != nil {
+		return nil, err
+	}
+	u, _ := url.Parse(ref.Uri)
+	r, ok := getResolver(u.Scheme)
+	if !ok {
+		return nil, fmt.Errorf("Scheme %s of detached payload %s is not allowed", u.Scheme, ref.Uri)
+	}
+	content, err := r.Resolve(u, maxSize)
+	if err == nil && int64(len(content)) > maxSize {
+		err = fmt.Errorf("content exceeds the maximum of %d bytes", maxSize)
+	}
+	if err != nil {
+		logger.Warningf("Error fetching detached payload %s: %s", ref.Uri, err)
+		return nil, fmt.Errorf("Detached payload %s could not be fetched", ref.Uri)
+	}
+	if hash := sha256.Sum256(content); !bytes.Equal(hash[:], ref.Hash) {
+		return nil, fmt.Errorf("Content of detached payload %s does not match its hash", ref.Uri)
+	}
+	logger.Debugf("Resolved %d bytes of detached payload %s", len(content), ref.Uri)
+	return content, nil
+}
+
+// FetchAll returns the contents of refs, in order, once their hashes are
+// verified. Their total size is bounded by peer.limits.proposal.maxDetachedBytes
+func FetchAll(refs []*pb.DetachedPayload) ([][]byte, error) {
+	remaining := int64(limits.ForProposals().MaxDetachedBytes)
+	bounded := remaining > 0
+	contents := make([][]byte, len(refs))
+	for i, ref := range refs {
+		max := maxSize()
+		if bounded && remaining < max {
+			max = remaining
+		}
+		content, err := fetch(ref, max)
+		if err != nil {
+			return nil, err
+		}
+		remaining -= int64(len(content))
+		contents[i] = content
+	}
+	return contents, nil
+}
+
+// readAll reads at most maxSize bytes of r, it fails if r has more
+func readAll(r io.Reader, maxSize int64) ([]byte, error) {
+	content, err := ioutil.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSize {
+		return nil, fmt.Errorf("content exceeds the maximum of %d bytes", maxSize)
+	}
+	return content, nil
+}
+
+// fileResolver reads the content of file URIs from the file system of the peer
+type fileResolver struct{}
+
+func (fileResolver) Resolve(uri *url.URL, maxSize int64) ([]byte, error) {
+	f, err := os.Open(uri.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readAll(f, maxSize)
+}
+
+// httpResolver gets the content of http and https URIs
+type httpResolver struct{}
+
+func (httpResolver) Resolve(uri *url.URL, maxSize int64) ([]byte, error) {
+	client := &http.Client{Timeout: timeout()}
+	resp, err := client.Get(uri.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return readAll(resp.Body, maxSize)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package detached
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func ref(uri string, content []byte) *pb.DetachedPayload {
+	hash := sha256.Sum256(content)
+	return &pb.DetachedPayload{Uri: uri, Hash: hash[:]}
+}
+
+func TestCheckReferences(t *testing.T) {
+	assert.NoError(t, CheckReferences(nil))
+	assert.NoError(t, CheckReferences([]*pb.DetachedPayload{ref("https://example.com/doc", []byte("doc"))}))
+	assert.Error(t, CheckReferences([]*pb.DetachedPayload{nil}))
+	assert.Error(t, CheckReferences([]*pb.DetachedPayload{ref("doc", []byte("doc"))}), "a URI without scheme")
+	assert.Error(t, CheckReferences([]*pb.DetachedPayload{{Uri: "https://example.com/doc", Hash: []byte("short")}}))
+}
+
+func TestSchemesNotAllowed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("a scheme which is not allowed should not be fetched")
+	}))
+	defer server.Close()
+
+	// no scheme is allowed by default
+	_, err := Fetch(ref(server.URL+"/doc", []byte("large document")))
+	assert.Error(t, err)
+
+	viper.Set("peer.detachedPayloads.schemes", []string{"https"})
+	defer viper.Reset()
+	_, err = Fetch(ref(server.URL+"/doc", []byte("large document")))
+	assert.Error(t, err)
+}
+
+func TestFetchFile(t *testing.T) {
+	viper.Set("peer.detachedPayloads.schemes", []string{"file"})
+	defer viper.Reset()
+	dir, err := ioutil.TempDir("", "detached")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "doc")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("large document"), 0600))
+
+	content, err := Fetch(ref("file://"+path, []byte("large document")))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("large document"), content)
+
+	_, err = Fetch(ref("file://"+path, []byte("another document")))
+	assert.Error(t, err, "the content does not match the hash")
+
+	// the error of the resolver, which tells what the peer can read, is not
+	// returned to the client
+	missing := filepath.Join(dir, "missing")
+	_, err = Fetch(ref("file://"+missing, []byte("large document")))
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "no such file")
+
+	viper.Set("peer.detachedPayloads.maxSize", 4)
+	_, err = Fetch(ref("file://"+path, []byte("large document")))
+	assert.Error(t, err, "the content exceeds the maximum size")
+}
+
+func TestFetchHTTP(t *testing.T) {
+	viper.Set("peer.detachedPayloads.schemes", []string{"http"})
+	defer viper.Reset()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/doc" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("large document"))
+	}))
+	defer server.Close()
+
+	contents, err := FetchAll([]*pb.DetachedPayload{
+		ref(server.URL+"/doc", []byte("large document")),
+		ref(server.URL+"/doc", []byte("large document")),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, contents, 2)
+	assert.Equal(t, []byte("large document"), contents[1])
+
+	_, err = FetchAll([]*pb.DetachedPayload{ref(server.URL+"/missing", []byte("large document"))})
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "404")
+
+	// the contents fetched for a proposal are bounded as a whole
+	viper.Set("peer.limits.proposal.maxDetachedBytes", 20)
+	_, err = FetchAll([]*pb.DetachedPayload{
+		ref(server.URL+"/doc", []byte("large document")),
+		ref(server.URL+"/doc", []byte("large document")),
+	})
+	assert.Error(t, err)
+}
+
+type mapResolver map[string][]byte
+
+func (m mapResolver) Resolve(uri *url.URL, maxSize int64) ([]byte, error) {
+	content, ok := m[uri.Opaque]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", uri)
+	}
+	return content, nil
+}
+
+func TestRegisterResolver(t *testing.T) {
+	viper.Set("peer.detachedPayloads.schemes", []string{"ipfs"})
+	defer viper.Reset()
+	_, err := Fetch(ref("ipfs:doc", []byte("large document")))
+	assert.Error(t, err, "no resolver registered for the scheme")
+
+	RegisterResolver("ipfs", mapResolver{"doc": []byte("large document")})
+	content, err := Fetch(ref("ipfs:doc", []byte("large document")))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("large document"), content)
+
+	viper.Set("peer.detachedPayloads.schemes", []string{})
+	_, err = Fetch(ref("ipfs:doc", []byte("large document")))
+	assert.Error(t, err, "a registered scheme is only resolved once allowed")
+}
@@ -17,18 +17,21 @@ limitations under the License.
 package endorser
 
 import (
+	"crypto/sha256"
 	"fmt"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/op/go-logging"
 	"golang.org/x/net/context"
 
+	cerrors "github.com/hyperledger/fabric/common/errors"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/common/validation"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/limits"
 	"github.com/hyperledger/fabric/core/peer"
 	syscc "github.com/hyperledger/fabric/core/scc"
 	"github.com/hyperledger/fabric/msp"
@@ -46,6 +49,90 @@ var endorserLogger = logging.MustGetLogger("endorser")
 type Endorser struct {
 }
 
+// endorsements counts the proposals being simulated on each channel
+var endorsements = limits.NewCounter()
+
+// proposalTooLarge is the status of the response to a proposal exceeding the
+// proposal limits, as the HTTP status of a request entity too large
+const proposalTooLarge = 413
+
+// proposalRates are the token buckets of the client identities and of the
+// organizations whose proposals are rate limited
+var proposalRates = limits.NewBuckets()
+
+// tooManyProposals is the status of the response to a proposal beyond the
+// rate of its identity or organization, as the HTTP status of too many
+// requests
+const tooManyProposals = 429
+
+// readOnlyRefused is the status of the response to an endorsement proposal
+// sent to a read-only peer, as the HTTP status of a service unavailable
+const readOnlyRefused = 503
+
+// queryChaincodes are the system chaincodes whose proposals only query the
+// ledger of a channel, which a read-only peer keeps serving
+var queryChaincodes = map[string]bool{"qscc": true}
+
+// refusesProposal returns whether the peer refuses a proposal to the chaincode
+// cid on chainID because it is read-only. A read-only peer serves the chainless
+// proposals and the queries of the ledger, but no endorsement proposal
+func refusesProposal(chainID string, cid *pb.ChaincodeID) bool {
+	return peer.IsReadOnly() && chainID != "" && !queryChaincodes[cid.Name]
+}
+
+// checkProposalRate takes a token of the rates of the creator of a proposal
+// and of its organization, returning an error past either rate
+func checkProposalRate(creator []byte) error {
+	rates := limits.ForProposalRates()
+	if rates.Unlimited() {
+		return nil
+	}
+	sID := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(creator, sID); err != nil {
+		return fmt.Errorf("Could not unmarshal the creator of the proposal: %s", err)
+	}
+	identity := sha256.Sum256(creator)
+	takes := []limits.Take{
+		{Key: "identity/" + string(identity[:]), Rate: rates.Identity},
+		{Key: "org/" + sID.Mspid, Rate: rates.ForOrg(sID.Mspid)},
+	}
+	i, ok := proposalRates.TakeAll(takes...)
+	if ok {
+		return nil
+	}
+	if i == 0 {
+		return &limits.RateExceededError{Limit: "identity", Rate: rates.Identity}
+	}
+	return &limits.RateExceededError{Limit: "org " + sID.Mspid, Rate: takes[i].Rate}
+}
+
+// invocationDenied is the status of the response to a proposal whose creator
+// does not satisfy the invocation policy of the chaincode, as the HTTP status
+// of a forbidden request
+const invocationDenied = 403
+
+// invocationDeniedError is the error of a proposal whose creator does not
+// satisfy the invocation policy of the chaincode
+type invocationDeniedError struct {
+	chaincode string
+	err       error
+}
+
+func (e *invocationDeniedError) Error() string {
+	return fmt.Sprintf("Access denied to chaincode %s: %s", e.chaincode, e.err)
+}
+
+// checkInvocationPolicy returns an error unless the creator of the signed
+// proposal satisfies the invocation policy of the chaincode, if it has one.
+// The invocation policy is enforced by the endorsers alone, the validation of
+// the transactions of the chaincode by all the peers being unchanged
+func checkInvocationPolicy(chainID string, signedData *common.SignedData, cd *ccprovider.ChaincodeData) error {
+	if err := chaincode.CheckInvocationPolicy(chainID, signedData, cd); err != nil {
+		return &invocationDeniedError{chaincode: cd.Name, err: err}
+	}
+	return nil
+}
+
 // NewEndorserServer creates and returns a new Endorser server instance.
 func NewEndorserServer() pb.EndorserServer {
 	e := new(Endorser)
@@ -62,6 +149,15 @@ func (*Endorser) checkEsccAndVscc(prop *pb.Proposal) error {
 	return nil
 }
 
+// chaincodeError is the error response of an invoked chaincode
+type chaincodeError struct {
+	response *pb.Response
+}
+
+func (ce *chaincodeError) Error() string {
+	return ce.response.Message
+}
+
 func (*Endorser) getTxSimulator(ledgername string) (ledger.TxSimulator, error) {
 	lgr := peer.GetLedger(ledgername)
 	if lgr == nil {
@@ -100,7 +196,7 @@ func (e *Endorser) callChaincode(ctxt context.Context, chainID string, version s
 	}
 
 	if res.Status != shim.OK {
-		return nil, nil, fmt.Errorf(string(res.Message))
+		return nil, nil, &chaincodeError{res}
 	}
 
 	//----- BEGIN -  SECTION THAT MAY NEED TO BE DONE IN LCCC ------
@@ -136,7 +232,7 @@ func (e *Endorser) callChaincode(ctxt context.Context, chainID string, version s
 }
 
 //simulate the proposal by calling the chaincode
-func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid string, prop *pb.Proposal, cid *pb.ChaincodeID, txsim ledger.TxSimulator) (*ccprovider.ChaincodeData, *pb.Response, []byte, *pb.ChaincodeEvent, error) {
+func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid string, signedData *common.SignedData, prop *pb.Proposal, cid *pb.ChaincodeID, txsim ledger.TxSimulator) (*ccprovider.ChaincodeData, *pb.Response, []byte, *pb.ChaincodeEvent, error) {
 	//we do expect the payload to be a ChaincodeInvocationSpec
 	//if we are supporting other payloads in future, this be glaringly point
 	//as something that should change
@@ -163,6 +259,9 @@ func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid st
 		if err != nil {
 			return nil, nil, nil, nil, fmt.Errorf("failed to obtain cds for %s - %s", cid.Name, err)
 		}
+		if err = checkInvocationPolicy(chainID, signedData, cd); err != nil {
+			return nil, nil, nil, nil, err
+		}
 		version = cd.Version
 	}
 
@@ -268,17 +367,44 @@ func (e *Endorser) endorseProposal(ctx context.Context, chainID string, txid str
 	return pResp, nil
 }
 
-// ProcessProposal process the Proposal
+// rejectProposal returns the response to a proposal rejected with err, whose
+// details carry the code and category of err. As for the error responses of
+// the chaincodes, no RPC error is returned, which would drop the response
+func rejectProposal(status int32, err error) (*pb.ProposalResponse, error) {
+	return &pb.ProposalResponse{Response: cerrors.Response(status, err)}, nil
+}
+
+// ProcessProposal process the Proposal. A rejected proposal gets an error
+// response, carrying the code and category of the error in its details, rather
+// than an error
 func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ProposalResponse, error) {
+	// the proposal is checked against the limits before it, and then its
+	// chaincode input, are unmarshaled
+	proposalLimits := limits.ForProposals()
+	if err := proposalLimits.CheckSize(len(signedProp.ProposalBytes)); err != nil {
+		return rejectProposal(proposalTooLarge, cerrors.Validation(cerrors.RequestTooLarge, "%s", err))
+	}
+
 	// at first, we check whether the message is valid
 	prop, _, hdrExt, err := validation.ValidateProposalMessage(signedProp)
 	if err != nil {
-		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
+		return rejectProposal(500, cerrors.Validation(cerrors.MalformedProposal, "%s", err))
+	}
+
+	if err = proposalLimits.CheckInput(prop.Payload); err != nil {
+		return rejectProposal(proposalTooLarge, cerrors.Validation(cerrors.RequestTooLarge, "%s", err))
 	}
 
 	hdr, err := putils.GetHeader(prop.Header)
 	if err != nil {
-		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
+		return rejectProposal(500, cerrors.Validation(cerrors.MalformedProposal, "%s", err))
+	}
+
+	// the creator was authenticated by the validation, so that no client can
+	// use up the rates of another
+	if err = checkProposalRate(hdr.SignatureHeader.Creator); err != nil {
+		endorserLogger.Warningf("Rejecting proposal: %s", err)
+		return rejectProposal(tooManyProposals, cerrors.Unavailable(cerrors.RateLimited, "%s", err))
 	}
 
 	chainID := hdr.ChannelHeader.ChannelId
@@ -294,8 +420,12 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 
 	txid := hdr.ChannelHeader.TxId
 	if txid == "" {
-		err = fmt.Errorf("Invalid txID")
-		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
+		return rejectProposal(500, cerrors.Validation(cerrors.InvalidTxID, "Invalid txID"))
+	}
+
+	if refusesProposal(chainID, hdrExt.ChaincodeId) {
+		endorserLogger.Debugf("Refusing proposal %s to %s on read-only peer", txid, hdrExt.ChaincodeId.Name)
+		return rejectProposal(readOnlyRefused, cerrors.Unavailable(cerrors.ServiceUnavailable, "Peer is read-only and does not endorse proposals"))
 	}
 
 	// obtaining once the tx simulator for this proposal. This will be nil
@@ -304,11 +434,16 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 	var txsim ledger.TxSimulator
 	var historyQueryExecutor ledger.HistoryQueryExecutor
 	if chainID != "" {
+		if !endorsements.TryAcquire(chainID, limits.ForChannel(chainID).Endorsements) {
+			return rejectProposal(500, cerrors.Unavailable(cerrors.ServiceUnavailable, "Too many concurrent endorsements on channel %s", chainID))
+		}
+		defer endorsements.Release(chainID)
+
 		if txsim, err = e.getTxSimulator(chainID); err != nil {
-			return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
+			return rejectProposal(500, cerrors.Validation(cerrors.ChannelNotFound, "%s", err))
 		}
 		if historyQueryExecutor, err = e.getHistoryQueryExecutor(chainID); err != nil {
-			return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
+			return rejectProposal(500, cerrors.Validation(cerrors.ChannelNotFound, "%s", err))
 		}
 		// Add the historyQueryExecutor to context
 		// TODO shouldn't we also add txsim to context here as well? Rather than passing txsim parameter
@@ -327,9 +462,19 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 	//1 -- simulate
 	//TODO what do we do with response ? We need it for Invoke responses for sure
 	//Which field in PayloadResponse will carry return value ?
-	cd, res, simulationResult, ccevent, err := e.simulateProposal(ctx, chainID, txid, prop, hdrExt.ChaincodeId, txsim)
-	if err != nil {
-		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
+	signedData := &common.SignedData{Data: signedProp.ProposalBytes, Identity: hdr.SignatureHeader.Creator, Signature: signedProp.Signature}
+	ctx = context.WithValue(ctx, chaincode.SignedProposalKey, signedProp)
+	cd, res, simulationResult, ccevent, err := e.simulateProposal(ctx, chainID, txid, signedData, prop, hdrExt.ChaincodeId, txsim)
+	if deniedErr, ok := err.(*invocationDeniedError); ok {
+		endorserLogger.Warningf("Rejecting proposal %s: %s", txid, deniedErr)
+		return rejectProposal(invocationDenied, cerrors.Authorization(cerrors.AccessDenied, "%s", deniedErr))
+	} else if ccErr, ok := err.(*chaincodeError); ok {
+		// the error response of the chaincode, with its status code and
+		// details, is returned verbatim so that the client can process it
+		endorserLogger.Debugf("Chaincode %s returned error response %d for proposal %s", hdrExt.ChaincodeId.Name, ccErr.response.Status, txid)
+		return &pb.ProposalResponse{Response: ccErr.response}, nil
+	} else if err != nil {
+		return rejectProposal(500, cerrors.Wrap(err, common.ErrorCategory_INTERNAL, cerrors.SimulationFailed))
 	}
 
 	//2 -- endorse and get a marshalled ProposalResponse message
@@ -339,10 +484,15 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 	//chainless proposals (such as CSCC) don't have to be endorsed
 	if ischainless {
 		pResp = &pb.ProposalResponse{Response: res}
+	} else if peer.IsReadOnly() {
+		// the queries a read-only peer serves are answered without an
+		// endorsement, their responses cannot be submitted as transactions
+		endorserLogger.Debugf("Answering query %s on read-only peer without endorsing it", txid)
+		pResp = &pb.ProposalResponse{Response: res}
 	} else {
 		pResp, err = e.endorseProposal(ctx, chainID, txid, prop, res, simulationResult, ccevent, hdrExt.PayloadVisibility, hdrExt.ChaincodeId, txsim, cd)
 		if err != nil {
-			return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
+			return rejectProposal(500, cerrors.Wrap(err, common.ErrorCategory_INTERNAL, cerrors.EndorsementFailed))
 		}
 	}
 
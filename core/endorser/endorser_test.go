@@ -279,6 +279,30 @@ func TestDeploy(t *testing.T) {
 	chaincode.GetChain().Stop(context.Background(), cccid, &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec})
 }
 
+// TestReadOnlyPeer checks that a read-only peer refuses endorsement proposals
+// but still answers the ledger queries and the chainless proposals
+func TestReadOnlyPeer(t *testing.T) {
+	chainID := util.GetTestChainID()
+	if refusesProposal(chainID, &pb.ChaincodeID{Name: "ex01"}) {
+		t.Fatalf("A peer which is not read-only should not refuse proposals")
+	}
+
+	viper.Set("peer.readOnly", true)
+	defer viper.Set("peer.readOnly", false)
+	if !refusesProposal(chainID, &pb.ChaincodeID{Name: "ex01"}) {
+		t.Fatalf("A read-only peer should refuse endorsement proposals")
+	}
+	if !refusesProposal(chainID, &pb.ChaincodeID{Name: "lccc"}) {
+		t.Fatalf("A read-only peer should refuse deployment proposals")
+	}
+	if refusesProposal(chainID, &pb.ChaincodeID{Name: "qscc"}) {
+		t.Fatalf("A read-only peer should answer ledger queries")
+	}
+	if refusesProposal("", &pb.ChaincodeID{Name: "cscc"}) {
+		t.Fatalf("A read-only peer should answer chainless proposals")
+	}
+}
+
 //TestRedeploy - deploy two times, second time should fail but example02 should remain deployed
 func TestRedeploy(t *testing.T) {
 	chainID := util.GetTestChainID()
@@ -0,0 +1,265 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gateway submits transactions on behalf of clients: it collects the
+// endorsements of their proposals, sends their transactions to the ordering
+// service and follows the commit of the transactions, endorsing and submitting
+// them again when they are invalidated by an MVCC conflict, so that clients do
+// not have to implement the retry loop themselves
+package gateway
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+var logger = logging.MustGetLogger("gateway")
+
+// Endorser endorses proposals, such as the endorser server of the peer
+type Endorser interface {
+	ProcessProposal(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ProposalResponse, error)
+}
+
+// EndorserClient adapts the client of the endorser of another peer to an Endorser
+func EndorserClient(client pb.EndorserClient) Endorser {
+	return &endorserClient{client: client}
+}
+
+type endorserClient struct {
+	client pb.EndorserClient
+}
+
+func (e *endorserClient) ProcessProposal(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ProposalResponse, error) {
+	return e.client.ProcessProposal(ctx, signedProp)
+}
+
+// Broadcaster sends a transaction to the ordering service
+type Broadcaster func(env *cb.Envelope) error
+
+// LedgerGetter returns the ledger of a chain, or nil if the peer has not joined it
+type LedgerGetter func(chainID string) ledger.PeerLedger
+
+// Config configures a Gateway server
+type Config struct {
+	// Endorsers endorse every proposal, all of them must succeed
+	Endorsers []Endorser
+
+	// Broadcast sends the transactions to the ordering service
+	Broadcast Broadcaster
+
+	// GetLedger returns the ledgers in which the commit of the transactions is followed
+	GetLedger LedgerGetter
+
+	// MaxRetries is the number of times a transaction invalidated by an MVCC
+	// conflict is endorsed and submitted again
+	MaxRetries int
+
+	// CommitTimeout bounds the wait for the commit of each attempt
+	CommitTimeout time.Duration
+}
+
+type server struct {
+	config Config
+}
+
+// NewGatewayServer creates a Gateway server
+func NewGatewayServer(config Config) pb.GatewayServer {
+	return &server{config: config}
+}
+
+// Submit runs the attempts of a transaction. Each attempt starts with a signed
+// proposal from the client, which the gateway endorses before sending back the
+// payload of the transaction to sign. Once the client has sent the signed
+// transaction, the gateway submits it and waits for its commit. An attempt
+// invalidated by an MVCC conflict is followed by a RETRY response, to which the
+// client answers with a new proposal, until MaxRetries is reached
+func (s *server) Submit(stream pb.Gateway_SubmitServer) error {
+	for attempt := uint32(1); ; attempt++ {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if req.Proposal == nil {
+			return fmt.Errorf("Expected a proposal for attempt %d", attempt)
+		}
+
+		result, err := s.attempt(stream, req.Proposal, attempt)
+		if err != nil {
+			return err
+		}
+		if result.Status == pb.SubmitResponse_INVALID && result.Conflict != nil && int(attempt) <= s.config.MaxRetries {
+			logger.Debugf("Transaction [%s] invalidated by an MVCC conflict on key [%s:%s], retrying",
+				result.TxId, result.Conflict.Namespace, result.Conflict.Key)
+			result.Status = pb.SubmitResponse_RETRY
+		}
+		if err = stream.Send(result); err != nil {
+			return err
+		}
+		if result.Status != pb.SubmitResponse_RETRY {
+			return nil
+		}
+	}
+}
+
+// attempt endorses, submits and waits for the commit of a proposal, returning
+// how the transaction was committed
+func (s *server) attempt(stream pb.Gateway_SubmitServer, signedProp *pb.SignedProposal, attempt uint32) (*pb.SubmitResponse, error) {
+	prop, err := putils.GetProposal(signedProp.ProposalBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling proposal: %s", err)
+	}
+	hdr, err := putils.GetHeader(prop.Header)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling proposal header: %s", err)
+	}
+	chainID, txID := hdr.ChannelHeader.ChannelId, hdr.ChannelHeader.TxId
+	lgr := s.config.GetLedger(chainID)
+	if lgr == nil {
+		return nil, fmt.Errorf("Chain %s does not exist on this peer", chainID)
+	}
+
+	payload, err := s.endorse(stream.Context(), prop, signedProp)
+	if err != nil {
+		return nil, fmt.Errorf("Error endorsing transaction %s: %s", txID, err)
+	}
+	if err = stream.Send(&pb.SubmitResponse{Status: pb.SubmitResponse_SIGN, TxId: txID, Attempt: attempt, Payload: payload}); err != nil {
+		return nil, err
+	}
+	req, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if req.Transaction == nil || !bytes.Equal(req.Transaction.Payload, payload) {
+		return nil, fmt.Errorf("Expected the signed transaction %s", txID)
+	}
+
+	// The commit is looked for from the current height, before the
+	// transaction can be ordered
+	info, err := lgr.GetBlockchainInfo()
+	if err != nil {
+		return nil, fmt.Errorf("Error getting the height of chain %s: %s", chainID, err)
+	}
+	if err = s.config.Broadcast(req.Transaction); err != nil {
+		return nil, fmt.Errorf("Error submitting transaction %s: %s", txID, err)
+	}
+	result, err := s.waitForCommit(stream.Context(), lgr, info.Height, txID)
+	if err != nil {
+		return nil, err
+	}
+	result.Attempt = attempt
+	return result, nil
+}
+
+// endorse collects the endorsements of a proposal and returns the payload of its transaction
+func (s *server) endorse(ctx context.Context, prop *pb.Proposal, signedProp *pb.SignedProposal) ([]byte, error) {
+	if len(s.config.Endorsers) == 0 {
+		return nil, fmt.Errorf("No endorser configured")
+	}
+	resps := make([]*pb.ProposalResponse, len(s.config.Endorsers))
+	for i, endorser := range s.config.Endorsers {
+		resp, err := endorser.ProcessProposal(ctx, signedProp)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Response == nil || resp.Response.Status != 200 {
+			return nil, fmt.Errorf("Endorsement failed: %v", resp.Response)
+		}
+		resps[i] = resp
+	}
+	return putils.CreateTxPayload(prop, resps...)
+}
+
+// waitForCommit follows the blocks committed from height until one contains the transaction txID
+func (s *server) waitForCommit(ctx context.Context, lgr ledger.PeerLedger, height uint64, txID string) (*pb.SubmitResponse, error) {
+	if s.config.CommitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.CommitTimeout)
+		defer cancel()
+	}
+	itr, err := lgr.GetBlocksIterator(ctx, height)
+	if err != nil {
+		return nil, fmt.Errorf("Error iterating the blocks from block %d: %s", height, err)
+	}
+	defer itr.Close()
+
+	for {
+		result, err := itr.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Error waiting for the commit of transaction %s: %s", txID, err)
+		}
+		if result == nil {
+			return nil, fmt.Errorf("Ledger closed before the commit of transaction %s", txID)
+		}
+		block := result.(commonledger.BlockHolder).GetBlock()
+		txIndex, err := findTx(block, txID)
+		if err != nil {
+			return nil, err
+		}
+		if txIndex < 0 {
+			continue
+		}
+
+		committed := &pb.SubmitResponse{Status: pb.SubmitResponse_VALID, TxId: txID, BlockNumber: block.Header.Number}
+		txsFilter, err := util.GetTxsFilter(block)
+		if err != nil {
+			return nil, err
+		}
+		if !txsFilter.IsSet(uint(txIndex)) {
+			return committed, nil
+		}
+		committed.Status = pb.SubmitResponse_INVALID
+		conflicts, err := putils.GetMVCCConflictsFromBlock(block)
+		if err != nil {
+			return nil, err
+		}
+		if conflicts != nil {
+			for _, conflict := range conflicts.Conflicts {
+				if conflict.TxIndex == uint64(txIndex) {
+					committed.Conflict = conflict
+				}
+			}
+		}
+		return committed, nil
+	}
+}
+
+// findTx returns the index of the transaction txID in block, or -1
+func findTx(block *cb.Block, txID string) (int, error) {
+	for txIndex, envBytes := range block.Data.Data {
+		env, err := putils.GetEnvelopeFromBlock(envBytes)
+		if err != nil {
+			return -1, fmt.Errorf("Error extracting transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		payload, err := putils.GetPayload(env)
+		if err != nil {
+			return -1, fmt.Errorf("Error extracting payload of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		if payload.Header.ChannelHeader.TxId == txID {
+			return txIndex, nil
+		}
+	}
+	return -1, nil
+}
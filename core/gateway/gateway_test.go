@@ -0,0 +1,221 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"fmt"
+	"testing"
+
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type blockHolder struct {
+	block *cb.Block
+}
+
+func (bh *blockHolder) GetBlock() *cb.Block {
+	return bh.block
+}
+
+func (bh *blockHolder) GetBlockBytes() []byte {
+	return nil
+}
+
+type blocksItr struct {
+	blocks []*cb.Block
+}
+
+func (itr *blocksItr) Next() (commonledger.QueryResult, error) {
+	if len(itr.blocks) == 0 {
+		return nil, nil
+	}
+	block := itr.blocks[0]
+	itr.blocks = itr.blocks[1:]
+	return &blockHolder{block}, nil
+}
+
+func (itr *blocksItr) Close() {}
+
+type mockLedger struct {
+	ledger.PeerLedger
+	blocks []*cb.Block
+}
+
+func (ml *mockLedger) GetBlockchainInfo() (*cb.BlockchainInfo, error) {
+	return &cb.BlockchainInfo{Height: uint64(len(ml.blocks))}, nil
+}
+
+func (ml *mockLedger) GetBlocksIterator(ctx context.Context, startBlockNumber uint64) (commonledger.ResultsIterator, error) {
+	return &blocksItr{blocks: ml.blocks[startBlockNumber:]}, nil
+}
+
+// commit appends a block holding env, with a conflict on key if it is not empty
+func (ml *mockLedger) commit(env *cb.Envelope, key string) {
+	block := cb.NewBlock(uint64(len(ml.blocks)), nil)
+	block.Data.Data = [][]byte{putils.MarshalOrPanic(env)}
+	txsFilter := util.NewFilterBitArray(1)
+	if key != "" {
+		txsFilter.Set(0)
+		block.Metadata.Metadata[cb.BlockMetadataIndex_MVCC_CONFLICTS] = putils.MarshalOrPanic(&pb.MVCCConflicts{
+			Conflicts: []*pb.MVCCConflict{{TxIndex: 0, Namespace: "mycc", Key: key}},
+		})
+	}
+	block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter.ToBytes()
+	ml.blocks = append(ml.blocks, block)
+}
+
+type mockEndorser struct {
+	status int32
+}
+
+func (me *mockEndorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ProposalResponse, error) {
+	payload, err := putils.GetBytesProposalResponsePayload(nil, &pb.Response{Status: me.status}, []byte("results"), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ProposalResponse{Response: &pb.Response{Status: me.status}, Payload: payload, Endorsement: &pb.Endorsement{}}, nil
+}
+
+// mockStream plays the client: it answers SIGN with the signed transaction
+// and RETRY with a new proposal
+type mockStream struct {
+	grpc.ServerStream
+	t        *testing.T
+	attempts int
+	pending  []*pb.SubmitRequest
+	sent     []*pb.SubmitResponse
+}
+
+func (ms *mockStream) propose() {
+	ms.attempts++
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{Name: "mycc"},
+		Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte("invoke")}},
+	}}
+	prop, err := putils.CreateChaincodeProposal(fmt.Sprintf("tx%d", ms.attempts), cb.HeaderType_ENDORSER_TRANSACTION, "mychain", cis, []byte("creator"))
+	assert.NoError(ms.t, err)
+	ms.pending = append(ms.pending, &pb.SubmitRequest{Proposal: &pb.SignedProposal{ProposalBytes: putils.MarshalOrPanic(prop)}})
+}
+
+func (ms *mockStream) Context() context.Context {
+	return context.Background()
+}
+
+func (ms *mockStream) Send(resp *pb.SubmitResponse) error {
+	ms.sent = append(ms.sent, resp)
+	switch resp.Status {
+	case pb.SubmitResponse_SIGN:
+		ms.pending = append(ms.pending, &pb.SubmitRequest{Transaction: &cb.Envelope{Payload: resp.Payload, Signature: []byte("signature")}})
+	case pb.SubmitResponse_RETRY:
+		ms.propose()
+	}
+	return nil
+}
+
+func (ms *mockStream) Recv() (*pb.SubmitRequest, error) {
+	if len(ms.pending) == 0 {
+		return nil, fmt.Errorf("No request")
+	}
+	req := ms.pending[0]
+	ms.pending = ms.pending[1:]
+	return req, nil
+}
+
+// newTestServer returns a server over a ledger in which the first
+// conflicts transactions are invalidated by an MVCC conflict
+func newTestServer(lgr *mockLedger, conflicts int, maxRetries int) pb.GatewayServer {
+	lgr.blocks = []*cb.Block{cb.NewBlock(0, nil)}
+	return NewGatewayServer(Config{
+		Endorsers: []Endorser{&mockEndorser{status: 200}, &mockEndorser{status: 200}},
+		Broadcast: func(env *cb.Envelope) error {
+			key := ""
+			if conflicts > 0 {
+				conflicts--
+				key = "a"
+			}
+			lgr.commit(env, key)
+			return nil
+		},
+		GetLedger: func(chainID string) ledger.PeerLedger {
+			if chainID == "mychain" {
+				return lgr
+			}
+			return nil
+		},
+		MaxRetries: maxRetries,
+	})
+}
+
+func TestSubmitRetry(t *testing.T) {
+	lgr := &mockLedger{}
+	srv := newTestServer(lgr, 1, 3)
+
+	stream := &mockStream{t: t}
+	stream.propose()
+	assert.NoError(t, srv.Submit(stream))
+
+	statuses := make([]pb.SubmitResponse_Status, len(stream.sent))
+	for i, resp := range stream.sent {
+		statuses[i] = resp.Status
+	}
+	assert.Equal(t, []pb.SubmitResponse_Status{pb.SubmitResponse_SIGN, pb.SubmitResponse_RETRY, pb.SubmitResponse_SIGN, pb.SubmitResponse_VALID}, statuses)
+	assert.Equal(t, "tx1", stream.sent[1].TxId)
+	assert.Equal(t, "a", stream.sent[1].Conflict.Key)
+	assert.Equal(t, "tx2", stream.sent[3].TxId)
+	assert.Equal(t, uint32(2), stream.sent[3].Attempt)
+	assert.Equal(t, uint64(2), stream.sent[3].BlockNumber)
+	assert.Len(t, lgr.blocks, 3)
+}
+
+func TestSubmitMaxRetries(t *testing.T) {
+	srv := newTestServer(&mockLedger{}, 3, 1)
+
+	stream := &mockStream{t: t}
+	stream.propose()
+	assert.NoError(t, srv.Submit(stream))
+
+	last := stream.sent[len(stream.sent)-1]
+	assert.Equal(t, pb.SubmitResponse_INVALID, last.Status, "The transaction should be reported invalid after the last retry")
+	assert.Equal(t, uint32(2), last.Attempt)
+	assert.NotNil(t, last.Conflict)
+}
+
+func TestSubmitErrors(t *testing.T) {
+	lgr := &mockLedger{}
+	srv := newTestServer(lgr, 0, 0)
+
+	stream := &mockStream{t: t}
+	stream.pending = []*pb.SubmitRequest{{Transaction: &cb.Envelope{}}}
+	assert.Error(t, srv.Submit(stream), "The first request should be a proposal")
+
+	failing := NewGatewayServer(Config{
+		Endorsers: []Endorser{&mockEndorser{status: 200}, &mockEndorser{status: 500}},
+		GetLedger: func(chainID string) ledger.PeerLedger { return lgr },
+	})
+	stream = &mockStream{t: t}
+	stream.propose()
+	assert.Error(t, failing.Submit(stream), "A failed endorsement should fail the submission")
+	assert.Empty(t, stream.sent)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/protobuf/proto"
+	commonutil "github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	putils "github.com/hyperledger/fabric/protos/utils"
+)
+
+// addCommitHash records in the metadata of the validated block the hash of the
+// commit hash of the previous block and of the writes of the valid transactions
+// of the block. The peers committing the same blocks record the same hashes, as
+// long as they agree on the validity of the transactions. The chain of hashes is
+// anchored at the first block of the ledger, whose previous commit hash is empty,
+// however late it is enabled
+func (l *kvLedger) addCommitHash(block *common.Block) error {
+	if !l.commitHashLoaded {
+		if err := l.loadCommitHash(); err != nil {
+			return err
+		}
+		l.commitHashLoaded = true
+	}
+
+	commitHash, err := nextCommitHash(l.commitHash, block)
+	if err != nil {
+		return err
+	}
+	for len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_COMMIT_HASH) {
+		block.Metadata.Metadata = append(block.Metadata.Metadata, []byte{})
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_COMMIT_HASH] = commitHash
+	l.commitHash = commitHash
+	logger.Debugf("Channel [%s]: commit hash of block [%d] is [%x]", l.ledgerID, block.Header.Number, commitHash)
+	return nil
+}
+
+// loadCommitHash loads the commit hash of the last block committed. The blocks
+// committed while the commit hash was disabled have none recorded, so their
+// hashes are computed again from the last block which has one, or from the
+// first block of the ledger
+func (l *kvLedger) loadCommitHash() error {
+	info, err := l.blockStore.GetBlockchainInfo()
+	if err != nil || info.Height == 0 {
+		return err
+	}
+	lastBlock, err := l.blockStore.RetrieveBlockByNumber(math.MaxUint64)
+	if err != nil {
+		return err
+	}
+	last := lastBlock.Header.Number
+	first := last + 1 - info.Height
+
+	// next is the first block after the last one which has a commit hash
+	var commitHash []byte
+	next := first
+	for n := last + 1; n > first; n-- {
+		block := lastBlock
+		if n-1 != last {
+			if block, err = l.blockStore.RetrieveBlockByNumber(n - 1); err != nil {
+				return err
+			}
+		}
+		if commitHash = putils.GetCommitHashFromBlock(block); commitHash != nil {
+			next = n
+			break
+		}
+	}
+	if next <= last {
+		logger.Infof("Channel [%s]: computing the commit hashes of blocks [%d] to [%d], committed without", l.ledgerID, next, last)
+	}
+	for ; next <= last; next++ {
+		block, err := l.blockStore.RetrieveBlockByNumber(next)
+		if err != nil {
+			return err
+		}
+		if commitHash, err = nextCommitHash(commitHash, block); err != nil {
+			return err
+		}
+	}
+	l.commitHash = commitHash
+	return nil
+}
+
+// nextCommitHash returns the commit hash of the block whose previous block has
+// the commit hash prevCommitHash
+func nextCommitHash(prevCommitHash []byte, block *common.Block) ([]byte, error) {
+	writes, err := encodeValidWrites(block)
+	if err != nil {
+		return nil, err
+	}
+	return commonutil.ComputeCryptoHash(append(append([]byte{}, prevCommitHash...), writes...)), nil
+}
+
+// encodeValidWrites encodes the writes of the valid endorser transactions of the
+// block, in the order of the transactions and of their namespaces
+func encodeValidWrites(block *common.Block) ([]byte, error) {
+	buf := proto.NewBuffer(nil)
+	txsFilter, err := util.GetTxsFilter(block)
+	if err != nil {
+		return nil, err
+	}
+	for txIndex, envBytes := range block.Data.Data {
+		if txsFilter.IsSet(uint(txIndex)) {
+			continue
+		}
+
+		env, err := putils.GetEnvelopeFromBlock(envBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Error extracting transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		payload, err := putils.GetPayload(env)
+		if err != nil {
+			return nil, fmt.Errorf("Error extracting payload of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		if common.HeaderType(payload.Header.ChannelHeader.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue
+		}
+
+		respPayload, err := putils.GetActionFromEnvelope(envBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Error extracting action of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		txRWSet := &rwset.TxReadWriteSet{}
+		if err = txRWSet.Unmarshal(respPayload.Results); err != nil {
+			return nil, fmt.Errorf("Error unmarshaling read-write set of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+
+		for _, nsRWSet := range txRWSet.NsRWs {
+			for _, kvWrite := range nsRWSet.Writes {
+				buf.EncodeStringBytes(nsRWSet.NameSpace)
+				buf.EncodeStringBytes(kvWrite.Key)
+				buf.EncodeVarint(boolToUint64(kvWrite.IsDelete))
+				buf.EncodeRawBytes(kvWrite.Value)
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func boolToUint64(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -148,7 +148,8 @@ func tryDoubleSpend() {
 func printBlocksInfo(block *common.Block) {
 	logger.Debug("Entering printBlocksInfo()")
 	// Read invalid transactions filter
-	txsFltr := util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	txsFltr, err := util.GetTxsFilter(block)
+	handleError(err, true)
 	numOfInvalid := 0
 	// Count how many transaction indeed invalid
 	for i := 0; i < len(block.Data.Data); i++ {
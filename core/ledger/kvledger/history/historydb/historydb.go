@@ -35,4 +35,6 @@ type HistoryDB interface {
 	NewHistoryQueryExecutor(blockStore blkstorage.BlockStore) (ledger.HistoryQueryExecutor, error)
 	Commit(block *common.Block) error
 	GetBlockNumFromSavepoint() (uint64, error)
+	// Redact marks the values written to the keys of a namespace by the blocks below height as purged
+	Redact(namespace string, keys []string, height uint64) error
 }
@@ -18,6 +18,7 @@ package historyleveldb
 
 import (
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
@@ -33,6 +34,7 @@ var logger = logging.MustGetLogger("historyleveldb")
 
 var compositeKeySep = []byte{0x00}
 var savePointKey = []byte{0x00}
+var redactionKeyPrefix = []byte{0x00, 0x01}
 var emptyValue = []byte{}
 
 // HistoryDBProvider implements interface HistoryDBProvider
@@ -170,3 +172,35 @@ func (historyDB *historyDB) GetBlockNumFromSavepoint() (uint64, error) {
 	height, _ := version.NewHeightFromBytes(versionBytes)
 	return height.BlockNum, nil
 }
+
+// Redact implements method in HistoryDB interface. The redaction marker of a key holds the height
+// below which its values are redacted, and is only ever raised
+func (historyDB *historyDB) Redact(namespace string, keys []string, height uint64) error {
+	dbBatch := leveldbhelper.NewUpdateBatch()
+	for _, key := range keys {
+		redactedHeight, err := historyDB.getRedactedHeight(namespace, key)
+		if err != nil {
+			return err
+		}
+		if height > redactedHeight {
+			dbBatch.Put(constructRedactionKey(namespace, key), util.EncodeOrderPreservingVarUint64(height))
+		}
+	}
+	return historyDB.db.WriteBatch(dbBatch, true)
+}
+
+// getRedactedHeight returns the height below which the values of the key are redacted, 0 if none is
+func (historyDB *historyDB) getRedactedHeight(namespace string, key string) (uint64, error) {
+	heightBytes, err := historyDB.db.Get(constructRedactionKey(namespace, key))
+	if err != nil || heightBytes == nil {
+		return 0, err
+	}
+	height, _ := util.DecodeOrderPreservingVarUint64(heightBytes)
+	return height, nil
+}
+
+// constructRedactionKey builds the key of the redaction marker of namespace~key~, which can not
+// collide with the history keys as namespaces are not empty
+func constructRedactionKey(namespace string, key string) []byte {
+	return append(append([]byte{}, redactionKeyPrefix...), historydb.ConstructPartialCompositeHistoryKey(namespace, key, false)...)
+}
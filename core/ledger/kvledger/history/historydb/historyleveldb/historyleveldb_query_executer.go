@@ -18,6 +18,7 @@ package historyleveldb
 
 import (
 	"errors"
+	"fmt"
 
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
@@ -26,6 +27,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	ledgerutil "github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
 	putils "github.com/hyperledger/fabric/protos/utils"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
@@ -49,9 +51,108 @@ func (q *LevelHistoryDBQueryExecutor) GetHistoryForKey(namespace string, key str
 	compositeStartKey = historydb.ConstructPartialCompositeHistoryKey(namespace, key, false)
 	compositeEndKey = historydb.ConstructPartialCompositeHistoryKey(namespace, key, true)
 
+	redactedHeight, err := q.historyDB.getRedactedHeight(namespace, key)
+	if err != nil {
+		return nil, err
+	}
+
 	// range scan to find any history records starting with namespace~key
 	dbItr := q.historyDB.db.GetIterator(compositeStartKey, compositeEndKey)
-	return newHistoryScanner(compositeStartKey, namespace, key, dbItr, q.blockStore), nil
+	return newHistoryScanner(compositeStartKey, namespace, key, dbItr, q.blockStore, redactedHeight), nil
+}
+
+// GetStateAtHeight implements method in interface `ledger.HistoryQueryExecutor`
+func (q *LevelHistoryDBQueryExecutor) GetStateAtHeight(namespace string, key string, height uint64) ([]byte, error) {
+	values, err := q.GetStateMultipleKeysAtHeight(namespace, []string{key}, height)
+	if err != nil {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// GetStateMultipleKeysAtHeight implements method in interface `ledger.HistoryQueryExecutor`
+func (q *LevelHistoryDBQueryExecutor) GetStateMultipleKeysAtHeight(namespace string, keys []string, height uint64) ([][]byte, error) {
+
+	if ledgerconfig.IsHistoryDBEnabled() == false {
+		return nil, errors.New("History tracking not enabled - historyDatabase is false")
+	}
+
+	bcInfo, err := q.blockStore.GetBlockchainInfo()
+	if err != nil {
+		return nil, err
+	}
+	if height > bcInfo.Height {
+		return nil, fmt.Errorf("Height %d is above the height %d of the ledger", height, bcInfo.Height)
+	}
+
+	// the transaction filters of the blocks visited, shared by the keys
+	txsFilters := make(map[uint64]ledgerutil.FilterBitArray)
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		if values[i], err = q.getStateAtHeight(namespace, key, height, txsFilters); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// getStateAtHeight looks for the last valid write of the key below height
+func (q *LevelHistoryDBQueryExecutor) getStateAtHeight(namespace string, key string, height uint64,
+	txsFilters map[uint64]ledgerutil.FilterBitArray) ([]byte, error) {
+
+	// history records below height range from namespace~key~ to namespace~key~height
+	compositePartialKey := historydb.ConstructPartialCompositeHistoryKey(namespace, key, false)
+	compositeEndKey := append(append([]byte{}, compositePartialKey...), util.EncodeOrderPreservingVarUint64(height)...)
+	dbItr := q.historyDB.db.GetIterator(compositePartialKey, compositeEndKey)
+	defer dbItr.Release()
+
+	for ok := dbItr.Last(); ok; ok = dbItr.Prev() {
+		_, blockNumTranNumBytes := historydb.SplitCompositeHistoryKey(dbItr.Key(), compositePartialKey)
+		blockNum, bytesConsumed := util.DecodeOrderPreservingVarUint64(blockNumTranNumBytes[0:])
+		tranNum, _ := util.DecodeOrderPreservingVarUint64(blockNumTranNumBytes[bytesConsumed:])
+
+		// history records are kept for the invalid transactions too
+		txsFilter, ok := txsFilters[blockNum]
+		if !ok {
+			block, err := q.blockStore.RetrieveBlockByNumber(blockNum)
+			if err != nil {
+				return nil, err
+			}
+			if txsFilter, err = ledgerutil.GetTxsFilter(block); err != nil {
+				return nil, err
+			}
+			txsFilters[blockNum] = txsFilter
+		}
+		if txsFilter.IsSet(uint(tranNum - 1)) {
+			logger.Debugf("Skipping history record for namespace:%s key:%s of invalid transaction %v:%v",
+				namespace, key, blockNum, tranNum)
+			continue
+		}
+
+		tranEnvelope, err := q.blockStore.RetrieveTxByBlockNumTranNum(blockNum, tranNum)
+		if err != nil {
+			return nil, err
+		}
+		// the value of a delete is nil
+		_, keyValue, err := getTxIDandKeyWriteValueFromTran(tranEnvelope, namespace, key)
+		if err != nil {
+			return nil, err
+		}
+		if keyValue != nil {
+			redactedHeight, err := q.historyDB.getRedactedHeight(namespace, key)
+			if err != nil {
+				return nil, err
+			}
+			if blockNum < redactedHeight {
+				return nil, &ledger.PurgedKeyError{Namespace: namespace, Key: key}
+			}
+		}
+		return keyValue, nil
+	}
+	if err := dbItr.Error(); err != nil {
+		return nil, err
+	}
+	return nil, nil
 }
 
 //historyScanner implements ResultsIterator for iterating through history results
@@ -61,11 +162,12 @@ type historyScanner struct {
 	key                 string
 	dbItr               iterator.Iterator
 	blockStore          blkstorage.BlockStore
+	redactedHeight      uint64 //values written below redactedHeight have been purged
 }
 
 func newHistoryScanner(compositePartialKey []byte, namespace string, key string,
-	dbItr iterator.Iterator, blockStore blkstorage.BlockStore) *historyScanner {
-	return &historyScanner{compositePartialKey, namespace, key, dbItr, blockStore}
+	dbItr iterator.Iterator, blockStore blkstorage.BlockStore, redactedHeight uint64) *historyScanner {
+	return &historyScanner{compositePartialKey, namespace, key, dbItr, blockStore, redactedHeight}
 }
 
 func (scanner *historyScanner) Next() (commonledger.QueryResult, error) {
@@ -94,6 +196,9 @@ func (scanner *historyScanner) Next() (commonledger.QueryResult, error) {
 	}
 	logger.Debugf("Found historic key value for namespace:%s key:%s from transaction %s\n",
 		scanner.namespace, scanner.key, txID)
+	if keyValue != nil && blockNum < scanner.redactedHeight {
+		return &ledger.KeyModification{TxID: txID, Redacted: true}, nil
+	}
 	return &ledger.KeyModification{TxID: txID, Value: keyValue}, nil
 }
 
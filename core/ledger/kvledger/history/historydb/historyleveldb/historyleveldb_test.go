@@ -24,6 +24,8 @@ import (
 	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger"
+	ledgerutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
 	"github.com/spf13/viper"
 )
 
@@ -124,6 +126,81 @@ func TestHistory(t *testing.T) {
 	testutil.AssertEquals(t, count, 3)
 }
 
+func TestStateAtHeight(t *testing.T) {
+
+	env := NewTestHistoryEnv(t)
+	defer env.cleanup()
+	provider := env.testBlockStorageEnv.provider
+	store1, err := provider.OpenBlockStore("ledger1")
+	testutil.AssertNoError(t, err, "Error upon provider.OpenBlockStore()")
+	defer store1.Shutdown()
+
+	simulate := func(kvs map[string][]byte) []byte {
+		simulator, _ := env.txmgr.NewTxSimulator()
+		for key, value := range kvs {
+			if value == nil {
+				simulator.DeleteState("ns1", key)
+			} else {
+				simulator.SetState("ns1", key, value)
+			}
+		}
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		return simRes
+	}
+	commit := func(block *common.Block) {
+		err := store1.AddBlock(block)
+		testutil.AssertNoError(t, err, "")
+		err = env.testHistoryDB.Commit(block)
+		testutil.AssertNoError(t, err, "")
+	}
+	bg := testutil.NewBlockGenerator(t)
+
+	//block0
+	genesisBlock, err := configtxtest.MakeGenesisBlock("ledger1")
+	testutil.AssertNoError(t, err, "")
+	commit(genesisBlock)
+
+	//block1
+	commit(bg.NextBlock([][]byte{simulate(map[string][]byte{"key7": []byte("value1"), "key8": []byte("value1")})}, false))
+
+	//block2 tran1 writes key7, tran2 is invalid, tran3 deletes key8
+	block2 := bg.NextBlock([][]byte{
+		simulate(map[string][]byte{"key7": []byte("value2")}),
+		simulate(map[string][]byte{"key7": []byte("invalid")}),
+		simulate(map[string][]byte{"key8": nil}),
+	}, false)
+	txsFilter := ledgerutil.NewFilterBitArray(3)
+	txsFilter.Set(1)
+	block2.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter.ToBytes()
+	commit(block2)
+
+	//block3
+	commit(bg.NextBlock([][]byte{simulate(map[string][]byte{"key7": []byte("value3")})}, false))
+
+	qhistory, err := env.testHistoryDB.NewHistoryQueryExecutor(store1)
+	testutil.AssertNoError(t, err, "Error upon NewHistoryQueryExecutor")
+
+	value, err := qhistory.GetStateAtHeight("ns1", "key7", 1)
+	testutil.AssertNoError(t, err, "Error upon GetStateAtHeight()")
+	testutil.AssertNil(t, value)
+
+	values, err := qhistory.GetStateMultipleKeysAtHeight("ns1", []string{"key7", "key8", "key9"}, 2)
+	testutil.AssertNoError(t, err, "Error upon GetStateMultipleKeysAtHeight()")
+	testutil.AssertEquals(t, values, [][]byte{[]byte("value1"), []byte("value1"), nil})
+
+	values, err = qhistory.GetStateMultipleKeysAtHeight("ns1", []string{"key7", "key8"}, 3)
+	testutil.AssertNoError(t, err, "Error upon GetStateMultipleKeysAtHeight()")
+	testutil.AssertEquals(t, values, [][]byte{[]byte("value2"), nil})
+
+	value, err = qhistory.GetStateAtHeight("ns1", "key7", 4)
+	testutil.AssertNoError(t, err, "Error upon GetStateAtHeight()")
+	testutil.AssertEquals(t, value, []byte("value3"))
+
+	_, err = qhistory.GetStateAtHeight("ns1", "key7", 5)
+	testutil.AssertError(t, err, "Error should have been returned for a height above the height of the ledger")
+}
+
 //TestSavepoint tests that save points get written after each block and get returned via GetBlockNumfromSavepoint
 func TestHistoryDisabled(t *testing.T) {
 
@@ -138,6 +215,9 @@ func TestHistoryDisabled(t *testing.T) {
 
 	_, err2 := qhistory.GetHistoryForKey("ns1", "key7")
 	testutil.AssertError(t, err2, "Error should have been returned for GetHistoryForKey() when history disabled")
+
+	_, err2 = qhistory.GetStateAtHeight("ns1", "key7", 1)
+	testutil.AssertError(t, err2, "Error should have been returned for GetStateAtHeight() when history disabled")
 }
 
 //TestGenesisBlockNoError tests that Genesis blocks are ignored by history processing
@@ -19,6 +19,7 @@ package kvledger
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
@@ -31,6 +32,7 @@ import (
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/peer"
 	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
 )
 
 var logger = logging.MustGetLogger("kvledger")
@@ -42,6 +44,16 @@ type kvLedger struct {
 	blockStore blkstorage.BlockStore
 	txtmgmt    txmgr.TxMgr
 	historyDB  historydb.HistoryDB
+
+	// commitHash is the commit hash of the last block committed, loaded from
+	// the block storage on the first block committed
+	commitHash       []byte
+	commitHashLoaded bool
+
+	// commitLock serializes Commit and Close, so that closing the ledger
+	// waits for the block being committed instead of interrupting it
+	commitLock sync.Mutex
+	closed     bool
 }
 
 // NewKVLedger constructs new `KVLedger`
@@ -56,7 +68,7 @@ func newKVLedger(ledgerID string, blockStore blkstorage.BlockStore,
 
 	// Create a kvLedger for this chain/ledger, which encasulates the underlying
 	// id store, blockstore, txmgr (state database), history database
-	l := &kvLedger{ledgerID, blockStore, txmgmt, historyDB}
+	l := &kvLedger{ledgerID: ledgerID, blockStore: blockStore, txtmgmt: txmgmt, historyDB: historyDB}
 
 	//Recover both state DB and history DB if they are out of sync with block storage
 	if err := recoverDB(l); err != nil {
@@ -232,10 +244,11 @@ func (l *kvLedger) GetBlockByNumber(blockNumber uint64) (*common.Block, error) {
 }
 
 // GetBlocksIterator returns an iterator that starts from `startBlockNumber`(inclusive).
-// The iterator is a blocking iterator i.e., it blocks till the next block gets available in the ledger
+// The iterator is a blocking iterator i.e., it blocks till the next block gets available in the ledger,
+// or till ctx is done
 // ResultsIterator contains type BlockHolder
-func (l *kvLedger) GetBlocksIterator(startBlockNumber uint64) (commonledger.ResultsIterator, error) {
-	return l.blockStore.RetrieveBlocks(startBlockNumber)
+func (l *kvLedger) GetBlocksIterator(ctx context.Context, startBlockNumber uint64) (commonledger.ResultsIterator, error) {
+	return l.blockStore.RetrieveBlocks(ctx, startBlockNumber)
 
 }
 
@@ -249,11 +262,28 @@ func (l *kvLedger) GetBlockByTxID(txID string) (*common.Block, error) {
 	return l.blockStore.RetrieveBlockByTxID(txID)
 }
 
+// GetConfigBlocks returns the sequence and block numbers of the config blocks of the ledger
+func (l *kvLedger) GetConfigBlocks() ([]*common.ConfigBlockInfo, error) {
+	return l.blockStore.RetrieveConfigBlocks()
+}
+
 //Prune prunes the blocks/transactions that satisfy the given policy
 func (l *kvLedger) Prune(policy commonledger.PrunePolicy) error {
 	return errors.New("Not yet implemented")
 }
 
+// PurgeState implements method in interface `ledger.PeerLedger`
+func (l *kvLedger) PurgeState(namespace string, keys []string) error {
+	logger.Infof("Channel [%s]: Purging %d keys of namespace [%s]", l.ledgerID, len(keys), namespace)
+	height, err := l.txtmgmt.Purge(namespace, keys)
+	if err != nil {
+		return err
+	}
+	// the redaction markers are kept even when history is disabled, as the history database
+	// is rebuilt from the blocks when it is enabled
+	return l.historyDB.Redact(namespace, keys, height)
+}
+
 // NewTxSimulator returns new `ledger.TxSimulator`
 func (l *kvLedger) NewTxSimulator() (ledger.TxSimulator, error) {
 	return l.txtmgmt.NewTxSimulator()
@@ -279,14 +309,31 @@ func (l *kvLedger) Commit(block *common.Block) error {
 	var err error
 	blockNo := block.Header.Number
 
+	l.commitLock.Lock()
+	defer l.commitLock.Unlock()
+	if l.closed {
+		return fmt.Errorf("Ledger [%s] is closed, block [%d] not committed", l.ledgerID, blockNo)
+	}
+
 	logger.Debugf("Validating block [%d]", blockNo)
 	err = l.txtmgmt.ValidateAndPrepare(block, true)
 	if err != nil {
 		return err
 	}
+	if err = l.resolveMVCCConflicts(block); err != nil {
+		return err
+	}
+
+	if ledgerconfig.IsCommitHashEnabled() {
+		if err = l.addCommitHash(block); err != nil {
+			return err
+		}
+	}
 
 	logger.Debugf("Committing block [%d] to storage", blockNo)
 	if err = l.blockStore.AddBlock(block); err != nil {
+		// the commit hash of the block not added is forgotten
+		l.commitHashLoaded = false
 		return err
 	}
 
@@ -308,6 +355,12 @@ func (l *kvLedger) Commit(block *common.Block) error {
 
 // Close closes `KVLedger`
 func (l *kvLedger) Close() {
+	l.commitLock.Lock()
+	defer l.commitLock.Unlock()
+	if l.closed {
+		return
+	}
+	l.closed = true
 	l.blockStore.Shutdown()
 	l.txtmgmt.Shutdown()
 }
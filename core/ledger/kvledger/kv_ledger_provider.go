@@ -18,9 +18,11 @@ package kvledger
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage/leveldbblkstorage"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
@@ -46,6 +48,7 @@ type Provider struct {
 	blockStoreProvider blkstorage.BlockStoreProvider
 	vdbProvider        statedb.VersionedDBProvider
 	historydbProvider  historydb.HistoryDBProvider
+	ledgers            *ledgerCache
 }
 
 // NewProvider instantiates a new Provider.
@@ -64,11 +67,14 @@ func NewProvider() (ledger.PeerLedgerProvider, error) {
 		blkstorage.IndexableAttrTxID,
 		blkstorage.IndexableAttrBlockNumTranNum,
 		blkstorage.IndexableAttrBlockTxID,
+		blkstorage.IndexableAttrConfigSeq,
 	}
 	indexConfig := &blkstorage.IndexConfig{AttrsToIndex: attrsToIndex}
-	blockStoreProvider := fsblkstorage.NewProvider(
-		fsblkstorage.NewConf(ledgerconfig.GetBlockStorePath(), ledgerconfig.GetMaxBlockfileSize()),
-		indexConfig)
+	blockStoreProvider, err := newBlockStoreProvider(indexConfig)
+	if err != nil {
+		idStore.close()
+		return nil, err
+	}
 
 	// Initialize the versioned database (state database)
 	var vdbProvider statedb.VersionedDBProvider
@@ -77,7 +83,6 @@ func NewProvider() (ledger.PeerLedgerProvider, error) {
 		vdbProvider = stateleveldb.NewVersionedDBProvider()
 	} else {
 		logger.Debug("Constructing CouchDB VersionedDBProvider")
-		var err error
 		vdbProvider, err = statecouchdb.NewVersionedDBProvider()
 		if err != nil {
 			return nil, err
@@ -88,8 +93,46 @@ func NewProvider() (ledger.PeerLedgerProvider, error) {
 	var historydbProvider historydb.HistoryDBProvider
 	historydbProvider = historyleveldb.NewHistoryDBProvider()
 
+	provider := &Provider{idStore: idStore, blockStoreProvider: blockStoreProvider,
+		vdbProvider: vdbProvider, historydbProvider: historydbProvider}
+	provider.ledgers = newLedgerCache(ledgerconfig.GetMaxOpenLedgers(), provider.openKVLedger)
 	logger.Info("ledger provider Initialized")
-	return &Provider{idStore, blockStoreProvider, vdbProvider, historydbProvider}, nil
+	return provider, nil
+}
+
+// newBlockStoreProvider constructs the provider of the block storage backend
+// selected by ledger.blockchain.storage
+func newBlockStoreProvider(indexConfig *blkstorage.IndexConfig) (blkstorage.BlockStoreProvider, error) {
+	switch storage := ledgerconfig.GetBlockStorage(); storage {
+	case ledgerconfig.FileBlockStorage:
+		logger.Debug("Constructing file BlockStoreProvider")
+		return fsblkstorage.NewProvider(blockStoreConf(), indexConfig), nil
+	case ledgerconfig.LevelDBBlockStorage:
+		logger.Debug("Constructing leveldb BlockStoreProvider")
+		return leveldbblkstorage.NewProvider(ledgerconfig.GetBlockStoreLevelDBPath(), indexConfig), nil
+	default:
+		return nil, fmt.Errorf("Invalid block storage %s, must be one of %s, %s", storage,
+			ledgerconfig.FileBlockStorage, ledgerconfig.LevelDBBlockStorage)
+	}
+}
+
+// blockStoreConf returns the configuration of the block storage, the block
+// files of the channels mapped to paths being stored under these paths
+func blockStoreConf() *fsblkstorage.Conf {
+	return fsblkstorage.NewConfWithLedgerPaths(ledgerconfig.GetBlockStorePath(),
+		ledgerconfig.GetMaxBlockfileSize(), ledgerconfig.GetBlockStorePaths())
+}
+
+// MoveBlockStore moves the block files of a ledger from where they are
+// stored to the folder of the ledger under toPath, or to the block store path
+// if toPath is empty, before the channel gets mapped to toPath in
+// ledger.blockchain.paths. The peer must be stopped. MoveBlockStore returns
+// the folder the block files were moved to
+func MoveBlockStore(ledgerID string, toPath string) (string, error) {
+	if storage := ledgerconfig.GetBlockStorage(); storage != ledgerconfig.FileBlockStorage {
+		return "", fmt.Errorf("The blocks of the %s block storage are not stored in block files", storage)
+	}
+	return fsblkstorage.MoveLedgerBlocks(blockStoreConf(), ledgerID, toPath)
 }
 
 // Create implements the corresponding method from interface ledger.PeerLedgerProvider
@@ -105,7 +148,10 @@ func (provider *Provider) Create(ledgerID string) (ledger.PeerLedger, error) {
 	return provider.Open(ledgerID)
 }
 
-// Open implements the corresponding method from interface ledger.PeerLedgerProvider
+// Open implements the corresponding method from interface ledger.PeerLedgerProvider.
+// The databases of the ledger are only opened on its first access, and may be
+// closed when it is not in use while more than ledger.maxOpenLedgers ledgers
+// are open, to be opened again on its next access
 func (provider *Provider) Open(ledgerID string) (ledger.PeerLedger, error) {
 
 	logger.Debugf("Open() opening kvledger: %s", ledgerID)
@@ -118,7 +164,11 @@ func (provider *Provider) Open(ledgerID string) (ledger.PeerLedger, error) {
 	if !exists {
 		return nil, ErrNonExistingLedgerID
 	}
+	return newLazyLedger(ledgerID, provider.ledgers), nil
+}
 
+// openKVLedger opens the databases of a ledger
+func (provider *Provider) openKVLedger(ledgerID string) (*kvLedger, error) {
 	// Get the block store for a chain/ledger
 	blockStore, err := provider.blockStoreProvider.OpenBlockStore(ledgerID)
 	if err != nil {
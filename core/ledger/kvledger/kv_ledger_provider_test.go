@@ -18,10 +18,15 @@ package kvledger
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
 )
 
 func TestLedgerProvider(t *testing.T) {
@@ -102,6 +107,185 @@ func TestMultipleLedgerBasicRW(t *testing.T) {
 	}
 }
 
+func TestLazyLedgers(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	viper.Set("ledger.maxOpenLedgers", 2)
+	defer viper.Set("ledger.maxOpenLedgers", 0)
+	numLedgers := 4
+	provider, _ := NewProvider()
+	defer provider.Close()
+	cache := provider.(*Provider).ledgers
+
+	ledgers := make([]ledger.PeerLedger, numLedgers)
+	for i := 0; i < numLedgers; i++ {
+		l, err := provider.Create(constructTestLedgerID(i))
+		testutil.AssertNoError(t, err, "")
+		ledgers[i] = l
+	}
+	testutil.AssertEquals(t, cache.openLedgers(), 0)
+
+	for i, l := range ledgers {
+		s, _ := l.NewTxSimulator()
+		s.SetState("ns", "testKey", []byte(fmt.Sprintf("testValue_%d", i)))
+		s.Done()
+		res, _ := s.GetTxSimulationResults()
+		testutil.AssertNoError(t, l.Commit(testutil.ConstructBlock(t, [][]byte{res}, false)), "")
+		testutil.AssertEquals(t, cache.openLedgers() <= 2, true)
+	}
+
+	// A ledger in use is not closed, even when it is the least recently used
+	itr, err := ledgers[0].GetBlocksIterator(context.Background(), 0)
+	testutil.AssertNoError(t, err, "")
+	for i, l := range ledgers {
+		q, _ := l.NewQueryExecutor()
+		val, err := q.GetState("ns", "testKey")
+		q.Done()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, val, []byte(fmt.Sprintf("testValue_%d", i)))
+	}
+	testutil.AssertEquals(t, ledgers[0].(*lazyLedger).l != nil, true)
+	testutil.AssertEquals(t, cache.openLedgers(), 2)
+	itr.Close()
+	_, err = ledgers[1].GetBlockchainInfo()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, ledgers[0].(*lazyLedger).l == nil, true)
+
+	ledgers[3].Close()
+	_, err = ledgers[3].GetBlockchainInfo()
+	testutil.AssertEquals(t, err, ErrLedgerClosed)
+	testutil.AssertEquals(t, cache.openLedgers(), 1)
+}
+
+func TestLazyLedgerConcurrentOpen(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+	slow, _ := provider.Create(constructTestLedgerID(0))
+	fast, _ := provider.Create(constructTestLedgerID(1))
+
+	cache := provider.(*Provider).ledgers
+	var opens int32
+	opening, unblock := make(chan struct{}, 3), make(chan struct{})
+	cache.open = func(ledgerID string) (*kvLedger, error) {
+		if ledgerID == constructTestLedgerID(0) {
+			atomic.AddInt32(&opens, 1)
+			opening <- struct{}{}
+			<-unblock
+		}
+		return provider.(*Provider).openKVLedger(ledgerID)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := slow.GetBlockchainInfo()
+			testutil.AssertNoError(t, err, "")
+		}()
+	}
+	// The other ledgers are accessed while a ledger is being opened
+	<-opening
+	_, err := fast.GetBlockchainInfo()
+	testutil.AssertNoError(t, err, "")
+	close(unblock)
+	wg.Wait()
+	testutil.AssertEquals(t, atomic.LoadInt32(&opens), int32(1))
+}
+
+func TestLazyLedgersThrashing(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	viper.Set("ledger.maxOpenLedgers", 1)
+	defer viper.Set("ledger.maxOpenLedgers", 0)
+	numLedgers := 4
+	provider, _ := NewProvider()
+	defer provider.Close()
+	cache := provider.(*Provider).ledgers
+
+	ledgers := make([]ledger.PeerLedger, numLedgers)
+	for i := 0; i < numLedgers; i++ {
+		ledgers[i], _ = provider.Create(constructTestLedgerID(i))
+		s, _ := ledgers[i].NewTxSimulator()
+		s.SetState("ns", "testKey", []byte(fmt.Sprintf("testValue_%d", i)))
+		s.Done()
+		res, _ := s.GetTxSimulationResults()
+		testutil.AssertNoError(t, ledgers[i].Commit(testutil.ConstructBlock(t, [][]byte{res}, false)), "")
+	}
+
+	// Every access closes the ledger accessed before, while the ledgers in
+	// use are kept open
+	var wg sync.WaitGroup
+	for g := 0; g < 2*numLedgers; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				i := (g + j) % numLedgers
+				q, err := ledgers[i].NewQueryExecutor()
+				testutil.AssertNoError(t, err, "")
+				val, err := q.GetState("ns", "testKey")
+				q.Done()
+				testutil.AssertNoError(t, err, "")
+				testutil.AssertEquals(t, val, []byte(fmt.Sprintf("testValue_%d", i)))
+				info, err := ledgers[i].GetBlockchainInfo()
+				testutil.AssertNoError(t, err, "")
+				testutil.AssertEquals(t, info.Height, uint64(1))
+			}
+		}(g)
+	}
+	wg.Wait()
+	testutil.AssertEquals(t, cache.openLedgers(), 1)
+}
+
 func constructTestLedgerID(i int) string {
 	return fmt.Sprintf("ledger_%06d", i)
 }
+
+func TestLedgerProviderLevelDBBlockStorage(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	viper.Set("ledger.blockchain.storage", ledgerconfig.LevelDBBlockStorage)
+	defer viper.Set("ledger.blockchain.storage", ledgerconfig.FileBlockStorage)
+
+	provider, err := NewProvider()
+	testutil.AssertNoError(t, err, "")
+	l, err := provider.Create(constructTestLedgerID(0))
+	testutil.AssertNoError(t, err, "")
+	s, _ := l.NewTxSimulator()
+	testutil.AssertNoError(t, s.SetState("ns", "testKey", []byte("testValue")), "")
+	s.Done()
+	res, err := s.GetTxSimulationResults()
+	testutil.AssertNoError(t, err, "")
+	block := testutil.ConstructBlock(t, [][]byte{res}, false)
+	testutil.AssertNoError(t, l.Commit(block), "")
+	l.Close()
+	provider.Close()
+
+	provider, err = NewProvider()
+	testutil.AssertNoError(t, err, "")
+	defer provider.Close()
+	l, err = provider.Open(constructTestLedgerID(0))
+	testutil.AssertNoError(t, err, "")
+	defer l.Close()
+	bcInfo, _ := l.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(1))
+	b, err := l.GetBlockByNumber(1)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, b.Header.Hash(), block.Header.Hash())
+
+	_, err = MoveBlockStore(constructTestLedgerID(0), "")
+	testutil.AssertError(t, err, "Expected error moving the blocks of the goleveldb block storage")
+}
+
+func TestLedgerProviderInvalidBlockStorage(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	viper.Set("ledger.blockchain.storage", "s3")
+	defer viper.Set("ledger.blockchain.storage", ledgerconfig.FileBlockStorage)
+
+	_, err := NewProvider()
+	testutil.AssertError(t, err, "Expected error for an invalid block storage")
+}
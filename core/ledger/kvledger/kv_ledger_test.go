@@ -25,8 +25,11 @@ import (
 	ledgerpackage "github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
 	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -98,6 +101,244 @@ func TestKVLedgerBlockStorage(t *testing.T) {
 
 }
 
+func TestKVLedgerPurgeState(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer viper.Set("ledger.state.historyDatabase", ledgerconfig.IsHistoryDBEnabled())
+	viper.Set("ledger.state.historyDatabase", true)
+	provider, _ := NewProvider()
+	defer provider.Close()
+	ledger, _ := provider.Create("testLedger")
+	defer ledger.Close()
+
+	bg := testutil.NewBlockGenerator(t)
+	simulator, _ := ledger.NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.SetState("ns1", "key2", []byte("value2"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	block1 := bg.NextBlock([][]byte{simRes}, false)
+	testutil.AssertNoError(t, ledger.Commit(block1), "")
+
+	// a transaction simulated before the purge reads key1
+	simulator, _ = ledger.NewTxSimulator()
+	value, err := simulator.GetState("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, value, []byte("value1"))
+	simulator.SetState("ns1", "key3", []byte("value3"))
+	simulator.Done()
+	simResReadingKey1, _ := simulator.GetTxSimulationResults()
+
+	testutil.AssertNoError(t, ledger.PurgeState("ns1", []string{"key1", "key4"}), "")
+
+	qe, _ := ledger.NewQueryExecutor()
+	_, err = qe.GetState("ns1", "key1")
+	testutil.AssertEquals(t, err, &ledgerpackage.PurgedKeyError{Namespace: "ns1", Key: "key1"})
+	_, err = qe.GetStateMultipleKeys("ns1", []string{"key2", "key1"})
+	testutil.AssertError(t, err, "Reading a purged key should have failed")
+	value, err = qe.GetState("ns1", "key2")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, value, []byte("value2"))
+	value, err = qe.GetState("ns1", "key4")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, value)
+	itr, _ := qe.GetStateRangeScanIterator("ns1", "", "")
+	_, err = itr.Next()
+	testutil.AssertError(t, err, "Scanning a purged key should have failed")
+	itr.Close()
+	qe.Done()
+
+	// the purged value is redacted from the history but kept in the block
+	hqe, _ := ledger.NewHistoryQueryExecutor()
+	historyItr, _ := hqe.GetHistoryForKey("ns1", "key1")
+	kmod, err := historyItr.Next()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, kmod.(*ledgerpackage.KeyModification).Redacted, true)
+	testutil.AssertNil(t, kmod.(*ledgerpackage.KeyModification).Value)
+	historyItr.Close()
+	_, err = hqe.GetStateAtHeight("ns1", "key1", block1.Header.Number+1)
+	testutil.AssertError(t, err, "Reading a purged value of the history should have failed")
+	b1, _ := ledger.GetBlockByNumber(block1.Header.Number)
+	testutil.AssertEquals(t, b1, block1)
+
+	// the tombstone keeps the version read by the transaction, which stays valid
+	simulator, _ = ledger.NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value5"))
+	simulator.Done()
+	simRes, _ = simulator.GetTxSimulationResults()
+	block2 := bg.NextBlock([][]byte{simResReadingKey1, simRes}, false)
+	testutil.AssertNoError(t, ledger.Commit(block2), "")
+	b2, _ := ledger.GetBlockByNumber(block2.Header.Number)
+	txsFilter, err := util.GetTxsFilter(b2)
+	assert.NoError(t, err)
+	testutil.AssertEquals(t, txsFilter.IsSet(0), false)
+
+	// writing a purged key again makes it readable
+	qe, _ = ledger.NewQueryExecutor()
+	value, err = qe.GetState("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, value, []byte("value5"))
+	qe.Done()
+	historyItr, _ = hqe.GetHistoryForKey("ns1", "key1")
+	historyItr.Next()
+	kmod, _ = historyItr.Next()
+	testutil.AssertEquals(t, kmod.(*ledgerpackage.KeyModification).Value, []byte("value5"))
+	historyItr.Close()
+}
+
+func TestKVLedgerCommitHash(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer viper.Set("ledger.state.commitHash", ledgerconfig.IsCommitHashEnabled())
+	viper.Set("ledger.state.commitHash", true)
+	provider, _ := NewProvider()
+	ledger1, _ := provider.Create("ledger1")
+	ledger2, _ := provider.Create("ledger2")
+
+	bg := testutil.NewBlockGenerator(t)
+	var blocks []*common.Block
+	for i := 0; i < 3; i++ {
+		simulator, _ := ledger1.NewTxSimulator()
+		simulator.SetState("ns1", "key1", []byte(fmt.Sprintf("value%d", i)))
+		simulator.DeleteState("ns1", "key2")
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		blocks = append(blocks, bg.NextBlock([][]byte{simRes}, false))
+	}
+	commitHashes := func(ledger ledgerpackage.PeerLedger) [][]byte {
+		var hashes [][]byte
+		for _, block := range blocks {
+			b, err := ledger.GetBlockByNumber(block.Header.Number)
+			testutil.AssertNoError(t, err, "")
+			hashes = append(hashes, putils.GetCommitHashFromBlock(b))
+		}
+		return hashes
+	}
+
+	for _, block := range blocks {
+		testutil.AssertNoError(t, ledger2.Commit(block), "")
+	}
+	expectedHashes := commitHashes(ledger2)
+	// the commit hash of the last block survives the restart of the ledger
+	for _, block := range blocks[:2] {
+		testutil.AssertNoError(t, ledger1.Commit(block), "")
+	}
+	provider.Close()
+	provider, _ = NewProvider()
+	defer provider.Close()
+	ledger1, _ = provider.Open("ledger1")
+	testutil.AssertNoError(t, ledger1.Commit(blocks[2]), "")
+
+	hashes := commitHashes(ledger1)
+	testutil.AssertEquals(t, hashes, expectedHashes)
+	for i, hash := range hashes {
+		testutil.AssertEquals(t, len(hash), 64)
+		if i > 0 {
+			testutil.AssertNotEquals(t, hash, hashes[i-1])
+		}
+	}
+
+	// no commit hash is recorded when disabled
+	viper.Set("ledger.state.commitHash", false)
+	ledger3, _ := provider.Create("ledger3")
+	simulator, _ := ledger3.NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	block := testutil.NewBlockGenerator(t).NextBlock([][]byte{simRes}, false)
+	testutil.AssertNoError(t, ledger3.Commit(block), "")
+	b, _ := ledger3.GetBlockByNumber(block.Header.Number)
+	testutil.AssertNil(t, putils.GetCommitHashFromBlock(b))
+
+	// the chain is anchored at the first block, however late it is enabled
+	ledger4, _ := provider.Create("ledger4")
+	blocks[0].Metadata.Metadata[common.BlockMetadataIndex_COMMIT_HASH] = nil
+	testutil.AssertNoError(t, ledger4.Commit(blocks[0]), "")
+	viper.Set("ledger.state.commitHash", true)
+	for _, block := range blocks[1:] {
+		testutil.AssertNoError(t, ledger4.Commit(block), "")
+	}
+	hashes = commitHashes(ledger4)
+	testutil.AssertNil(t, hashes[0])
+	testutil.AssertEquals(t, hashes[1:], expectedHashes[1:])
+}
+
+func TestKVLedgerMVCCConflicts(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+	ledger, _ := provider.Create("testLedger")
+	bg := testutil.NewBlockGenerator(t)
+
+	readWrite := func(value string) []byte {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.GetState("ns1", "key1")
+		simulator.SetState("ns1", "key1", []byte(value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		return simRes
+	}
+	txID := func(block *common.Block, txIndex int) string {
+		env, err := putils.GetEnvelopeFromBlock(block.Data.Data[txIndex])
+		testutil.AssertNoError(t, err, "")
+		payload, err := putils.GetPayload(env)
+		testutil.AssertNoError(t, err, "")
+		return payload.Header.ChannelHeader.TxId
+	}
+	conflicts := func(block *common.Block) []*peer.MVCCConflict {
+		b, err := ledger.GetBlockByNumber(block.Header.Number)
+		testutil.AssertNoError(t, err, "")
+		conflicts, err := putils.GetMVCCConflictsFromBlock(b)
+		testutil.AssertNoError(t, err, "")
+		if conflicts == nil {
+			return nil
+		}
+		return conflicts.Conflicts
+	}
+
+	block1 := bg.NextBlock([][]byte{readWrite("value1")}, false)
+	testutil.AssertNoError(t, ledger.Commit(block1), "")
+	testutil.AssertNil(t, conflicts(block1))
+
+	// the second transaction conflicts with the first one of the same block
+	// and the third transaction, simulated before, with the committed one
+	stale := readWrite("value3")
+	block2 := bg.NextBlock([][]byte{readWrite("value2"), readWrite("value2")}, false)
+	testutil.AssertNoError(t, ledger.Commit(block2), "")
+	block3 := bg.NextBlock([][]byte{stale}, false)
+	testutil.AssertNoError(t, ledger.Commit(block3), "")
+
+	testutil.AssertEquals(t, conflicts(block2), []*peer.MVCCConflict{{
+		TxIndex: 1, TxId: txID(block2, 1), Namespace: "ns1", Key: "key1",
+		ConflictingBlockNum: block2.Header.Number, ConflictingTxNum: 1, ConflictingTxId: txID(block2, 0),
+	}})
+	testutil.AssertEquals(t, conflicts(block3), []*peer.MVCCConflict{{
+		TxIndex: 0, TxId: txID(block3, 0), Namespace: "ns1", Key: "key1",
+		ConflictingBlockNum: block2.Header.Number, ConflictingTxNum: 1, ConflictingTxId: txID(block2, 0),
+	}})
+}
+
+func TestKVLedgerCommitAfterClose(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+	ledger, _ := provider.Create("testLedger")
+
+	simulator, _ := ledger.NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	bg := testutil.NewBlockGenerator(t)
+	testutil.AssertNoError(t, ledger.Commit(bg.NextBlock([][]byte{simRes}, false)), "")
+
+	ledger.Close()
+	// a closed ledger refuses the blocks instead of half committing them, and closing it again is harmless
+	testutil.AssertError(t, ledger.Commit(bg.NextBlock([][]byte{simRes}, false)), "Expected an error committing to a closed ledger")
+	ledger.Close()
+}
+
 func TestKVLedgerDBRecovery(t *testing.T) {
 	ledgertestutil.SetupCoreYAMLConfig("./../../../peer")
 	env := newTestEnv(t)
@@ -144,10 +385,10 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	block2 := bg.NextBlock([][]byte{simRes}, false)
 
 	//performing validation of read and write set to find valid transactions
-	ledger.(*kvLedger).txtmgmt.ValidateAndPrepare(block2, true)
+	kvLedgerOf(ledger).txtmgmt.ValidateAndPrepare(block2, true)
 	//writing the validated block to block storage but not committing the transaction
 	//to state DB and history DB (if exist)
-	err = ledger.(*kvLedger).blockStore.AddBlock(block2)
+	err = kvLedgerOf(ledger).blockStore.AddBlock(block2)
 
 	//assume that peer fails here before committing the transaction
 	assert.NoError(t, err)
@@ -168,7 +409,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	//value for 'key3' should be 'value3' as the last commit failed
 	testutil.AssertEquals(t, value, []byte("value3.1"))
 	//savepoint in state DB should 1 as the last commit failed
-	stateDBSavepoint, _ := ledger.(*kvLedger).txtmgmt.GetBlockNumFromSavepoint()
+	stateDBSavepoint, _ := kvLedgerOf(ledger).txtmgmt.GetBlockNumFromSavepoint()
 	testutil.AssertEquals(t, stateDBSavepoint, uint64(1))
 
 	if ledgerconfig.IsHistoryDBEnabled() == true {
@@ -189,7 +430,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 		testutil.AssertEquals(t, count, 1)
 
 		//savepoint in history DB should 1 as the last commit failed
-		historyDBSavepoint, _ := ledger.(*kvLedger).historyDB.GetBlockNumFromSavepoint()
+		historyDBSavepoint, _ := kvLedgerOf(ledger).historyDB.GetBlockNumFromSavepoint()
 		testutil.AssertEquals(t, historyDBSavepoint, uint64(1))
 	}
 
@@ -213,7 +454,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	//value for 'key3' should be 'value6' after recovery
 	testutil.AssertEquals(t, value, []byte("value3.2"))
 	//savepoint in state DB should 2 after recovery
-	stateDBSavepoint, _ = ledger.(*kvLedger).txtmgmt.GetBlockNumFromSavepoint()
+	stateDBSavepoint, _ = kvLedgerOf(ledger).txtmgmt.GetBlockNumFromSavepoint()
 	testutil.AssertEquals(t, stateDBSavepoint, uint64(2))
 
 	if ledgerconfig.IsHistoryDBEnabled() == true {
@@ -234,7 +475,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 		testutil.AssertEquals(t, count, 2)
 
 		//savepoint in history DB should 2 after recovery
-		historyDBSavepoint, _ := ledger.(*kvLedger).historyDB.GetBlockNumFromSavepoint()
+		historyDBSavepoint, _ := kvLedgerOf(ledger).historyDB.GetBlockNumFromSavepoint()
 		testutil.AssertEquals(t, historyDBSavepoint, uint64(2))
 	}
 
@@ -255,11 +496,11 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	//generating a block based on the simulation result
 	block3 := bg.NextBlock([][]byte{simRes}, false)
 	//performing validation of read and write set to find valid transactions
-	ledger.(*kvLedger).txtmgmt.ValidateAndPrepare(block3, true)
+	kvLedgerOf(ledger).txtmgmt.ValidateAndPrepare(block3, true)
 	//writing the validated block to block storage
-	err = ledger.(*kvLedger).blockStore.AddBlock(block3)
+	err = kvLedgerOf(ledger).blockStore.AddBlock(block3)
 	//committing the transaction to state DB
-	err = ledger.(*kvLedger).txtmgmt.Commit()
+	err = kvLedgerOf(ledger).txtmgmt.Commit()
 	//assume that peer fails here after committing the transaction to state DB but before
 	//history DB
 	assert.NoError(t, err)
@@ -280,7 +521,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	//value for 'key3' should be 'value9'
 	testutil.AssertEquals(t, value, []byte("value3.3"))
 	//savepoint in state DB should 3
-	stateDBSavepoint, _ = ledger.(*kvLedger).txtmgmt.GetBlockNumFromSavepoint()
+	stateDBSavepoint, _ = kvLedgerOf(ledger).txtmgmt.GetBlockNumFromSavepoint()
 	testutil.AssertEquals(t, stateDBSavepoint, uint64(3))
 
 	if ledgerconfig.IsHistoryDBEnabled() == true {
@@ -301,7 +542,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 		testutil.AssertEquals(t, count, 2)
 
 		//savepoint in history DB should 2 as the last commit failed
-		historyDBSavepoint, _ := ledger.(*kvLedger).historyDB.GetBlockNumFromSavepoint()
+		historyDBSavepoint, _ := kvLedgerOf(ledger).historyDB.GetBlockNumFromSavepoint()
 		testutil.AssertEquals(t, historyDBSavepoint, uint64(2))
 	}
 	simulator.Done()
@@ -313,7 +554,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	provider, _ = NewProvider()
 	ledger, _ = provider.Open("testLedger")
 	simulator, _ = ledger.NewTxSimulator()
-	stateDBSavepoint, _ = ledger.(*kvLedger).txtmgmt.GetBlockNumFromSavepoint()
+	stateDBSavepoint, _ = kvLedgerOf(ledger).txtmgmt.GetBlockNumFromSavepoint()
 	testutil.AssertEquals(t, stateDBSavepoint, uint64(3))
 
 	if ledgerconfig.IsHistoryDBEnabled() == true {
@@ -334,7 +575,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 		testutil.AssertEquals(t, count, 3)
 
 		//savepoint in history DB should 3 after recovery
-		historyDBSavepoint, _ := ledger.(*kvLedger).historyDB.GetBlockNumFromSavepoint()
+		historyDBSavepoint, _ := kvLedgerOf(ledger).historyDB.GetBlockNumFromSavepoint()
 		testutil.AssertEquals(t, historyDBSavepoint, uint64(3))
 	}
 	simulator.Done()
@@ -355,12 +596,12 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	//generating a block based on the simulation result
 	block4 := bg.NextBlock([][]byte{simRes}, false)
 	//performing validation of read and write set to find valid transactions
-	ledger.(*kvLedger).txtmgmt.ValidateAndPrepare(block4, true)
+	kvLedgerOf(ledger).txtmgmt.ValidateAndPrepare(block4, true)
 	//writing the validated block to block storage but fails to commit to state DB but
 	//successfully commits to history DB (if exists)
-	err = ledger.(*kvLedger).blockStore.AddBlock(block4)
+	err = kvLedgerOf(ledger).blockStore.AddBlock(block4)
 	if ledgerconfig.IsHistoryDBEnabled() == true {
-		err = ledger.(*kvLedger).historyDB.Commit(block4)
+		err = kvLedgerOf(ledger).historyDB.Commit(block4)
 	}
 	assert.NoError(t, err)
 
@@ -380,7 +621,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	//value for 'key3' should be 'value9' as the last commit to State DB failed
 	testutil.AssertEquals(t, value, []byte("value3.3"))
 	//savepoint in state DB should 3 as the last commit failed
-	stateDBSavepoint, _ = ledger.(*kvLedger).txtmgmt.GetBlockNumFromSavepoint()
+	stateDBSavepoint, _ = kvLedgerOf(ledger).txtmgmt.GetBlockNumFromSavepoint()
 	testutil.AssertEquals(t, stateDBSavepoint, uint64(3))
 
 	if ledgerconfig.IsHistoryDBEnabled() == true {
@@ -400,7 +641,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 		}
 		testutil.AssertEquals(t, count, 4)
 		//savepoint in history DB should 4
-		historyDBSavepoint, _ := ledger.(*kvLedger).historyDB.GetBlockNumFromSavepoint()
+		historyDBSavepoint, _ := kvLedgerOf(ledger).historyDB.GetBlockNumFromSavepoint()
 		testutil.AssertEquals(t, historyDBSavepoint, uint64(4))
 	}
 	simulator.Done()
@@ -422,7 +663,7 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	//value for 'key3' should be 'value12' after state DB recovery
 	testutil.AssertEquals(t, value, []byte("value3.4"))
 	//savepoint in state DB should 4 after the recovery
-	stateDBSavepoint, _ = ledger.(*kvLedger).txtmgmt.GetBlockNumFromSavepoint()
+	stateDBSavepoint, _ = kvLedgerOf(ledger).txtmgmt.GetBlockNumFromSavepoint()
 	testutil.AssertEquals(t, stateDBSavepoint, uint64(4))
 	simulator.Done()
 }
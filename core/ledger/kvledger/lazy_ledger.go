@@ -0,0 +1,435 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+	"golang.org/x/net/context"
+)
+
+// ErrLedgerClosed is returned by the calls made to a ledger after it has been closed
+var ErrLedgerClosed = errors.New("Ledger is closed")
+
+// ledgerCache keeps track of the ledgers of a Provider that are open. A ledger
+// is only opened on its first access, and once more than max ledgers are open
+// the least recently used ones, which are not in use, are closed until they
+// are accessed again. A ledger is in use while a call is made to it, and
+// while a query executor, tx simulator or blocks iterator it returned is not
+// done or closed. A max <= 0 means no limit.
+//
+// The state and history databases of the ledgers are the shared LevelDBs of
+// the Provider (or its CouchDB), which closing a kvLedger leaves open. Closing
+// a ledger thus only frees the handles of its block files, and opening it
+// again syncs the index of its blocks and checks the savepoints of its
+// databases, so a max below the number of ledgers accessed in turn makes
+// every access pay for an open
+type ledgerCache struct {
+	lock sync.Mutex
+	max  int
+	// lru holds the open ledgers, the most recently used first
+	lru  *list.List
+	open func(ledgerID string) (*kvLedger, error)
+}
+
+func newLedgerCache(max int, open func(ledgerID string) (*kvLedger, error)) *ledgerCache {
+	return &ledgerCache{max: max, lru: list.New(), open: open}
+}
+
+// evict closes the least recently used ledgers not in use while more than max
+// ledgers are open. It must be called with the lock held
+func (c *ledgerCache) evict() {
+	if c.max <= 0 {
+		return
+	}
+	for e := c.lru.Back(); e != nil && c.lru.Len() > c.max; {
+		ll := e.Value.(*lazyLedger)
+		e = e.Prev()
+		if ll.users > 0 {
+			continue
+		}
+		logger.Debugf("Closing ledger [%s], the least recently used of %d open ledgers", ll.id, c.lru.Len())
+		ll.closeWithoutLock()
+	}
+}
+
+// openLedgers returns the number of ledgers open
+func (c *ledgerCache) openLedgers() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
+
+// lazyLedger implements interface ledger.PeerLedger over a kvLedger which is
+// opened on first access and may be closed by its ledgerCache when not in use
+type lazyLedger struct {
+	id     string
+	cache  *ledgerCache
+	l      *kvLedger
+	elem   *list.Element
+	users  int
+	closed bool
+	// opening is the opening of the kvLedger in progress, if any
+	opening *ledgerOpening
+}
+
+// ledgerOpening is the opening of a kvLedger, which the other accesses to the
+// ledger wait for rather than opening it again
+type ledgerOpening struct {
+	done chan struct{}
+	err  error
+}
+
+func newLazyLedger(ledgerID string, cache *ledgerCache) *lazyLedger {
+	return &lazyLedger{id: ledgerID, cache: cache}
+}
+
+// acquire opens the kvLedger if it is not open and marks it in use, until
+// release is called. The kvLedger is opened without the lock of the cache
+// held, so that the other ledgers are accessed meanwhile, and the concurrent
+// accesses to the ledger wait for it to be opened
+func (ll *lazyLedger) acquire() (*kvLedger, error) {
+	c := ll.cache
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for ll.l == nil {
+		if ll.closed {
+			return nil, ErrLedgerClosed
+		}
+		if opening := ll.opening; opening != nil {
+			c.lock.Unlock()
+			<-opening.done
+			c.lock.Lock()
+			if opening.err != nil {
+				return nil, opening.err
+			}
+			// the ledger may have been closed again in the meantime
+			continue
+		}
+		if err := ll.openWithoutLock(); err != nil {
+			return nil, err
+		}
+	}
+	c.lru.MoveToFront(ll.elem)
+	ll.users++
+	c.evict()
+	return ll.l, nil
+}
+
+// openWithoutLock opens the kvLedger, releasing the lock of the cache, which
+// must be held, while it is opened
+func (ll *lazyLedger) openWithoutLock() error {
+	c := ll.cache
+	opening := &ledgerOpening{done: make(chan struct{})}
+	ll.opening = opening
+	c.lock.Unlock()
+	logger.Debugf("Opening ledger [%s] on first access", ll.id)
+	l, err := c.open(ll.id)
+	c.lock.Lock()
+	ll.opening = nil
+	defer close(opening.done)
+
+	if err != nil {
+		opening.err = err
+		return err
+	}
+	if ll.closed {
+		l.Close()
+		opening.err = ErrLedgerClosed
+		return ErrLedgerClosed
+	}
+	ll.l = l
+	ll.elem = c.lru.PushFront(ll)
+	return nil
+}
+
+func (ll *lazyLedger) release() {
+	c := ll.cache
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ll.users--
+	c.evict()
+}
+
+// releaseOnce returns a function calling release the first time it is called
+func (ll *lazyLedger) releaseOnce() func() {
+	var once sync.Once
+	return func() {
+		once.Do(ll.release)
+	}
+}
+
+// closeWithoutLock closes the kvLedger if it is open. It must be called with
+// the lock of the cache held
+func (ll *lazyLedger) closeWithoutLock() {
+	if ll.l == nil {
+		return
+	}
+	ll.l.Close()
+	ll.cache.lru.Remove(ll.elem)
+	ll.l = nil
+	ll.elem = nil
+}
+
+// GetBlockchainInfo implements method in interface ledger.PeerLedger
+func (ll *lazyLedger) GetBlockchainInfo() (*common.BlockchainInfo, error) {
+	l, err := ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer ll.release()
+	return l.GetBlockchainInfo()
+}
+
+// GetBlockByNumber implements method in interface ledger.PeerLedger
+func (ll *lazyLedger) GetBlockByNumber(blockNumber uint64) (*common.Block, error) {
+	l, err := ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer ll.release()
+	return l.GetBlockByNumber(blockNumber)
+}
+
+// GetBlocksIterator implements method in interface ledger.PeerLedger. The
+// ledger is in use until the iterator is closed
+func (ll *lazyLedger) GetBlocksIterator(ctx context.Context, startBlockNumber uint64) (commonledger.ResultsIterator, error) {
+	l, err := ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	itr, err := l.GetBlocksIterator(ctx, startBlockNumber)
+	if err != nil {
+		ll.release()
+		return nil, err
+	}
+	return &releasingIterator{itr, ll.releaseOnce()}, nil
+}
+
+// GetTransactionByID implements method in interface ledger.PeerLedger
+func (ll *lazyLedger) GetTransactionByID(txID string) (*peer.ProcessedTransaction, error) {
+	l, err := ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer ll.release()
+	return l.GetTransactionByID(txID)
+}
+
+// GetBlockByHash implements method in interface ledger.PeerLedger
+func (ll *lazyLedger) GetBlockByHash(blockHash []byte) (*common.Block, error) {
+	l, err := ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer ll.release()
+	return l.GetBlockByHash(blockHash)
+}
+
+// GetBlockByTxID implements method in interface ledger.PeerLedger
+func (ll *lazyLedger) GetBlockByTxID(txID string) (*common.Block, error) {
+	l, err := ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer ll.release()
+	return l.GetBlockByTxID(txID)
+}
+
+// GetConfigBlocks implements method in interface ledger.PeerLedger
+func (ll *lazyLedger) GetConfigBlocks() ([]*common.ConfigBlockInfo, error) {
+	l, err := ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer ll.release()
+	return l.GetConfigBlocks()
+}
+
+// Prune implements method in interface ledger.PeerLedger
+func (ll *lazyLedger) Prune(policy commonledger.PrunePolicy) error {
+	l, err := ll.acquire()
+	if err != nil {
+		return err
+	}
+	defer ll.release()
+	return l.Prune(policy)
+}
+
+// PurgeState implements method in interface ledger.PeerLedger
+func (ll *lazyLedger) PurgeState(namespace string, keys []string) error {
+	l, err := ll.acquire()
+	if err != nil {
+		return err
+	}
+	defer ll.release()
+	return l.PurgeState(namespace, keys)
+}
+
+// NewTxSimulator implements method in interface ledger.PeerLedger. The ledger
+// is in use until the simulator is done
+func (ll *lazyLedger) NewTxSimulator() (ledger.TxSimulator, error) {
+	l, err := ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	s, err := l.NewTxSimulator()
+	if err != nil {
+		ll.release()
+		return nil, err
+	}
+	return &releasingTxSimulator{s, ll.releaseOnce()}, nil
+}
+
+// NewQueryExecutor implements method in interface ledger.PeerLedger. The
+// ledger is in use until the query executor is done
+func (ll *lazyLedger) NewQueryExecutor() (ledger.QueryExecutor, error) {
+	l, err := ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	qe, err := l.NewQueryExecutor()
+	if err != nil {
+		ll.release()
+		return nil, err
+	}
+	return &releasingQueryExecutor{qe, ll.releaseOnce()}, nil
+}
+
+// NewHistoryQueryExecutor implements method in interface ledger.PeerLedger.
+// As a history query executor is never done, the ledger is only in use
+// during its queries
+func (ll *lazyLedger) NewHistoryQueryExecutor() (ledger.HistoryQueryExecutor, error) {
+	l, err := ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer ll.release()
+	if _, err = l.NewHistoryQueryExecutor(); err != nil {
+		return nil, err
+	}
+	return &lazyHistoryQueryExecutor{ll}, nil
+}
+
+// Commit implements method in interface ledger.PeerLedger
+func (ll *lazyLedger) Commit(block *common.Block) error {
+	l, err := ll.acquire()
+	if err != nil {
+		return err
+	}
+	defer ll.release()
+	return l.Commit(block)
+}
+
+// Close implements method in interface ledger.PeerLedger
+func (ll *lazyLedger) Close() {
+	c := ll.cache
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ll.closed = true
+	ll.closeWithoutLock()
+}
+
+type releasingIterator struct {
+	commonledger.ResultsIterator
+	release func()
+}
+
+func (itr *releasingIterator) Close() {
+	itr.ResultsIterator.Close()
+	itr.release()
+}
+
+type releasingQueryExecutor struct {
+	ledger.QueryExecutor
+	release func()
+}
+
+func (qe *releasingQueryExecutor) Done() {
+	qe.QueryExecutor.Done()
+	qe.release()
+}
+
+type releasingTxSimulator struct {
+	ledger.TxSimulator
+	release func()
+}
+
+func (s *releasingTxSimulator) Done() {
+	s.TxSimulator.Done()
+	s.release()
+}
+
+// lazyHistoryQueryExecutor makes each query with a history query executor of
+// the kvLedger open at the time of the query
+type lazyHistoryQueryExecutor struct {
+	ll *lazyLedger
+}
+
+// GetHistoryForKey implements method in interface ledger.HistoryQueryExecutor.
+// The ledger is in use until the iterator is closed
+func (hqe *lazyHistoryQueryExecutor) GetHistoryForKey(namespace string, key string) (commonledger.ResultsIterator, error) {
+	l, err := hqe.ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	qe, err := l.NewHistoryQueryExecutor()
+	if err != nil {
+		hqe.ll.release()
+		return nil, err
+	}
+	itr, err := qe.GetHistoryForKey(namespace, key)
+	if err != nil {
+		hqe.ll.release()
+		return nil, err
+	}
+	return &releasingIterator{itr, hqe.ll.releaseOnce()}, nil
+}
+
+// GetStateAtHeight implements method in interface ledger.HistoryQueryExecutor
+func (hqe *lazyHistoryQueryExecutor) GetStateAtHeight(namespace string, key string, height uint64) ([]byte, error) {
+	l, err := hqe.ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer hqe.ll.release()
+	qe, err := l.NewHistoryQueryExecutor()
+	if err != nil {
+		return nil, err
+	}
+	return qe.GetStateAtHeight(namespace, key, height)
+}
+
+// GetStateMultipleKeysAtHeight implements method in interface ledger.HistoryQueryExecutor
+func (hqe *lazyHistoryQueryExecutor) GetStateMultipleKeysAtHeight(namespace string, keys []string, height uint64) ([][]byte, error) {
+	l, err := hqe.ll.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer hqe.ll.release()
+	qe, err := l.NewHistoryQueryExecutor()
+	if err != nil {
+		return nil, err
+	}
+	return qe.GetStateMultipleKeysAtHeight(namespace, keys, height)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	putils "github.com/hyperledger/fabric/protos/utils"
+)
+
+// resolveMVCCConflicts completes the MVCC conflicts recorded in the metadata of
+// the validated block with the ids of the committed transactions of the previous
+// blocks they conflict with. The validator only knows the ids of the transactions
+// of the block. A transaction which cannot be retrieved, for instance because the
+// block storage does not index the transactions by number, is left unresolved
+func (l *kvLedger) resolveMVCCConflicts(block *common.Block) error {
+	conflicts, err := putils.GetMVCCConflictsFromBlock(block)
+	if err != nil || conflicts == nil {
+		return err
+	}
+
+	resolved := false
+	for _, conflict := range conflicts.Conflicts {
+		if conflict.ConflictingTxId != "" || conflict.ConflictingTxNum == 0 || conflict.ConflictingBlockNum >= block.Header.Number {
+			continue
+		}
+		env, err := l.blockStore.RetrieveTxByBlockNumTranNum(conflict.ConflictingBlockNum, conflict.ConflictingTxNum)
+		if err != nil {
+			logger.Warningf("Channel [%s]: cannot retrieve transaction [%d:%d] conflicting with transaction [%s]: %s",
+				l.ledgerID, conflict.ConflictingBlockNum, conflict.ConflictingTxNum, conflict.TxId, err)
+			continue
+		}
+		payload, err := putils.GetPayload(env)
+		if err != nil {
+			logger.Warningf("Channel [%s]: cannot extract payload of transaction [%d:%d]: %s",
+				l.ledgerID, conflict.ConflictingBlockNum, conflict.ConflictingTxNum, err)
+			continue
+		}
+		conflict.ConflictingTxId = payload.Header.ChannelHeader.TxId
+		resolved = true
+	}
+	if !resolved {
+		return nil
+	}
+
+	conflictsBytes, err := proto.Marshal(conflicts)
+	if err != nil {
+		return fmt.Errorf("Error marshaling MVCC conflicts of block %d: %s", block.Header.Number, err)
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_MVCC_CONFLICTS] = conflictsBytes
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/spf13/viper"
 )
@@ -39,3 +40,14 @@ func (env *testEnv) cleanup() {
 	path := ledgerconfig.GetRootPath()
 	os.RemoveAll(path)
 }
+
+// kvLedgerOf returns the kvLedger a ledger returned by a Provider is open on
+func kvLedgerOf(l ledger.PeerLedger) *kvLedger {
+	ll := l.(*lazyLedger)
+	kvl, err := ll.acquire()
+	if err != nil {
+		panic(err)
+	}
+	ll.release()
+	return kvl
+}
@@ -19,6 +19,7 @@ package rwset
 import (
 	"bytes"
 	"fmt"
+	"io"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
@@ -54,6 +55,19 @@ func (w *KVWrite) SetValue(value []byte) {
 	w.IsDelete = value == nil
 }
 
+// KVMetadataEntry - a named entry of the metadata of a key
+type KVMetadataEntry struct {
+	Name  string
+	Value []byte
+}
+
+// KVMetadataWrite - the metadata that a transaction wants to set for a key during simulation.
+// The entries, sorted by name, replace all the entries the key had. No entries removes the metadata of the key
+type KVMetadataWrite struct {
+	Key     string
+	Entries []*KVMetadataEntry
+}
+
 // RangeQueryInfo captures a range query executed by a transaction
 // and the tuples <key,version> that are read by the transaction
 // This it to be used to perform a phantom-read validation during commit
@@ -86,6 +100,7 @@ type NsReadWriteSet struct {
 	Reads            []*KVRead
 	Writes           []*KVWrite
 	RangeQueriesInfo []*RangeQueryInfo
+	MetadataWrites   []*KVMetadataWrite
 }
 
 // TxReadWriteSet - a collection of all the reads and writes collected as a result of a transaction simulation
@@ -318,6 +333,49 @@ func (w *KVWrite) Unmarshal(buf *proto.Buffer) error {
 	return nil
 }
 
+// Marshal serializes a `KVMetadataWrite`
+func (w *KVMetadataWrite) Marshal(buf *proto.Buffer) error {
+	var err error
+	if err = buf.EncodeStringBytes(w.Key); err != nil {
+		return err
+	}
+	if err = buf.EncodeVarint(uint64(len(w.Entries))); err != nil {
+		return err
+	}
+	for _, entry := range w.Entries {
+		if err = buf.EncodeStringBytes(entry.Name); err != nil {
+			return err
+		}
+		if err = buf.EncodeRawBytes(entry.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unmarshal deserializes a `KVMetadataWrite`
+func (w *KVMetadataWrite) Unmarshal(buf *proto.Buffer) error {
+	var err error
+	if w.Key, err = buf.DecodeStringBytes(); err != nil {
+		return err
+	}
+	var numEntries uint64
+	if numEntries, err = buf.DecodeVarint(); err != nil {
+		return err
+	}
+	for i := 0; i < int(numEntries); i++ {
+		entry := &KVMetadataEntry{}
+		if entry.Name, err = buf.DecodeStringBytes(); err != nil {
+			return err
+		}
+		if entry.Value, err = buf.DecodeRawBytes(false); err != nil {
+			return err
+		}
+		w.Entries = append(w.Entries, entry)
+	}
+	return nil
+}
+
 // Marshal serializes a `NsReadWriteSet`
 func (nsRW *NsReadWriteSet) Marshal(buf *proto.Buffer) error {
 	var err error
@@ -395,17 +453,35 @@ func (nsRW *NsReadWriteSet) Unmarshal(buf *proto.Buffer) error {
 	return nil
 }
 
-// Marshal serializes a `TxReadWriteSet`
+// Marshal serializes a `TxReadWriteSet`.
+// The metadata writes of the namespaces follow all the namespaces, and are left out
+// if there is none, so that the read-write sets without them keep their former encoding
 func (txRW *TxReadWriteSet) Marshal() ([]byte, error) {
 	buf := proto.NewBuffer(nil)
 	var err error
 	if err = buf.EncodeVarint(uint64(len(txRW.NsRWs))); err != nil {
 		return nil, err
 	}
+	hasMetadataWrites := false
 	for i := 0; i < len(txRW.NsRWs); i++ {
 		if err = txRW.NsRWs[i].Marshal(buf); err != nil {
 			return nil, err
 		}
+		hasMetadataWrites = hasMetadataWrites || len(txRW.NsRWs[i].MetadataWrites) > 0
+	}
+	if !hasMetadataWrites {
+		return buf.Bytes(), nil
+	}
+	for i := 0; i < len(txRW.NsRWs); i++ {
+		metadataWrites := txRW.NsRWs[i].MetadataWrites
+		if err = buf.EncodeVarint(uint64(len(metadataWrites))); err != nil {
+			return nil, err
+		}
+		for j := 0; j < len(metadataWrites); j++ {
+			if err = metadataWrites[j].Marshal(buf); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return buf.Bytes(), nil
 }
@@ -425,6 +501,23 @@ func (txRW *TxReadWriteSet) Unmarshal(b []byte) error {
 		}
 		txRW.NsRWs = append(txRW.NsRWs, nsRW)
 	}
+	for i := 0; i < int(numEntries); i++ {
+		var numMetadataWrites uint64
+		if numMetadataWrites, err = buf.DecodeVarint(); err != nil {
+			if i == 0 && err == io.ErrUnexpectedEOF {
+				// the read-write set has no metadata writes
+				return nil
+			}
+			return err
+		}
+		for j := 0; j < int(numMetadataWrites); j++ {
+			w := &KVMetadataWrite{}
+			if err = w.Unmarshal(buf); err != nil {
+				return err
+			}
+			txRW.NsRWs[i].MetadataWrites = append(txRW.NsRWs[i].MetadataWrites, w)
+		}
+	}
 	return nil
 }
 
@@ -438,6 +531,21 @@ func (w *KVWrite) String() string {
 	return fmt.Sprintf("%s=[%#v]", w.Key, w.Value)
 }
 
+// String prints a `KVMetadataWrite`
+func (w *KVMetadataWrite) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(w.Key)
+	buffer.WriteString("={")
+	for i, entry := range w.Entries {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteString(fmt.Sprintf("%s:[%#v]", entry.Name, entry.Value))
+	}
+	buffer.WriteString("}")
+	return buffer.String()
+}
+
 // String prints a range query info
 func (rqi *RangeQueryInfo) String() string {
 	return fmt.Sprintf("StartKey=%s, EndKey=%s, ItrExhausted=%t, Results=%#v, Hash=%#v",
@@ -465,6 +573,14 @@ func (nsRW *NsReadWriteSet) String() string {
 		buffer.WriteString(rqi.String())
 		buffer.WriteString("\n")
 	}
+	if len(nsRW.MetadataWrites) > 0 {
+		buffer.WriteString("MetadataWriteSet=\n")
+		for _, w := range nsRW.MetadataWrites {
+			buffer.WriteString("\t")
+			buffer.WriteString(w.String())
+			buffer.WriteString("\n")
+		}
+	}
 	return buffer.String()
 }
 
@@ -29,10 +29,12 @@ type nsRWs struct {
 	writeMap         map[string]*KVWrite
 	rangeQueriesMap  map[rangeQueryKey]*RangeQueryInfo //for phantom read validation
 	rangeQueriesKeys []rangeQueryKey
+	metadataWriteMap map[string]*KVMetadataWrite
 }
 
 func newNsRWs() *nsRWs {
-	return &nsRWs{make(map[string]*KVRead), make(map[string]*KVWrite), make(map[rangeQueryKey]*RangeQueryInfo), nil}
+	return &nsRWs{make(map[string]*KVRead), make(map[string]*KVWrite), make(map[rangeQueryKey]*RangeQueryInfo), nil,
+		make(map[string]*KVMetadataWrite)}
 }
 
 type rangeQueryKey struct {
@@ -63,6 +65,16 @@ func (rws *RWSet) AddToWriteSet(ns string, key string, value []byte) {
 	nsRWs.writeMap[key] = NewKVWrite(key, value)
 }
 
+// AddToMetadataWriteSet adds the metadata entries that replace the metadata of a key to the metadata write-set
+func (rws *RWSet) AddToMetadataWriteSet(ns string, key string, metadata map[string][]byte) {
+	nsRWs := rws.getOrCreateNsRW(ns)
+	kvMetadataWrite := &KVMetadataWrite{Key: key}
+	for _, name := range util.GetSortedKeys(metadata) {
+		kvMetadataWrite.Entries = append(kvMetadataWrite.Entries, &KVMetadataEntry{name, metadata[name]})
+	}
+	nsRWs.metadataWriteMap[key] = kvMetadataWrite
+}
+
 // AddToRangeQuerySet adds a range query info for performing phantom read validation
 func (rws *RWSet) AddToRangeQuerySet(ns string, rqi *RangeQueryInfo) {
 	nsRWs := rws.getOrCreateNsRW(ns)
@@ -116,7 +128,14 @@ func (rws *RWSet) GetTxReadWriteSet() *TxReadWriteSet {
 		for _, key := range nsReadWriteMap.rangeQueriesKeys {
 			rangeQueriesInfo = append(rangeQueriesInfo, rangeQueriesMap[key])
 		}
-		nsRWs := &NsReadWriteSet{NameSpace: ns, Reads: reads, Writes: writes, RangeQueriesInfo: rangeQueriesInfo}
+		//add metadata write set, left nil when empty
+		var metadataWrites []*KVMetadataWrite
+		sortedMetadataWriteKeys := util.GetSortedKeys(nsReadWriteMap.metadataWriteMap)
+		for _, key := range sortedMetadataWriteKeys {
+			metadataWrites = append(metadataWrites, nsReadWriteMap.metadataWriteMap[key])
+		}
+		nsRWs := &NsReadWriteSet{NameSpace: ns, Reads: reads, Writes: writes, RangeQueriesInfo: rangeQueriesInfo,
+			MetadataWrites: metadataWrites}
 		txRWSet.NsRWs = append(txRWSet.NsRWs, nsRWs)
 	}
 	return txRWSet
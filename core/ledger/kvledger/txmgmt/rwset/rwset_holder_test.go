@@ -49,18 +49,21 @@ func TestRWSetHolder(t *testing.T) {
 
 	rwSet.AddToReadSet("ns2", "key2", version.NewHeight(1, 2))
 	rwSet.AddToWriteSet("ns2", "key3", []byte("value3"))
+	rwSet.AddToMetadataWriteSet("ns2", "key3", map[string][]byte{"b": nil, "a": []byte("1")})
 
 	txRWSet := rwSet.GetTxReadWriteSet()
 
 	ns1RWSet := &NsReadWriteSet{"ns1",
 		[]*KVRead{&KVRead{"key1", version.NewHeight(1, 1)}, &KVRead{"key2", version.NewHeight(1, 2)}},
 		[]*KVWrite{&KVWrite{"key2", false, []byte("value2")}},
-		[]*RangeQueryInfo{rqi1, rqi3}}
+		[]*RangeQueryInfo{rqi1, rqi3},
+		nil}
 
 	ns2RWSet := &NsReadWriteSet{"ns2",
 		[]*KVRead{&KVRead{"key2", version.NewHeight(1, 2)}},
 		[]*KVWrite{&KVWrite{"key3", false, []byte("value3")}},
-		[]*RangeQueryInfo{}}
+		[]*RangeQueryInfo{},
+		[]*KVMetadataWrite{&KVMetadataWrite{"key3", []*KVMetadataEntry{&KVMetadataEntry{"a", []byte("1")}, &KVMetadataEntry{"b", nil}}}}}
 
 	expectedTxRWSet := &TxReadWriteSet{[]*NsReadWriteSet{ns1RWSet, ns2RWSet}}
 	t.Logf("Actual=%s\n Expected=%s", txRWSet, expectedTxRWSet)
@@ -7,6 +7,7 @@ import (
 )
 
 var dataWrapper = "data"
+var metadataWrapper = "metadata"
 var jsonQueryFields = "fields"
 
 var validOperators = []string{"$and", "$or", "$not", "$nor", "$all", "$elemMatch",
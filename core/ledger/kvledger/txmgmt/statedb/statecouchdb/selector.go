@@ -0,0 +1,517 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statecouchdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
+)
+
+// defaultQueryLimit is the number of documents CouchDB returns for the
+// queries which do not set a limit
+const defaultQueryLimit = 25
+
+// couchQuery is a CouchDB query, whose selector, sort, skip, limit and fields
+// are evaluated on the documents a snapshot kept, which CouchDB no longer has
+type couchQuery struct {
+	query    map[string]interface{}
+	selector map[string]interface{}
+	fields   []string
+	sort     []sortField
+	skip     int
+	limit    int
+}
+
+// sortField is a field a query sorts the documents by
+type sortField struct {
+	field      string
+	descending bool
+}
+
+// queryDoc is a document matching a query
+type queryDoc struct {
+	id       string
+	doc      map[string]interface{}
+	docBytes []byte
+}
+
+// parseQuery parses a query wrapped by ApplyQueryWrapper
+func parseQuery(queryString string) (*couchQuery, error) {
+	q := &couchQuery{query: make(map[string]interface{}), limit: defaultQueryLimit}
+	if err := json.Unmarshal([]byte(queryString), &q.query); err != nil {
+		return nil, fmt.Errorf("Error parsing query %s: %s", queryString, err)
+	}
+	if selector, ok := q.query["selector"].(map[string]interface{}); ok {
+		q.selector = selector
+	} else {
+		return nil, fmt.Errorf("Query %s has no selector", queryString)
+	}
+	if fields, ok := q.query[jsonQueryFields].([]interface{}); ok {
+		for _, field := range fields {
+			name, ok := field.(string)
+			if !ok {
+				return nil, fmt.Errorf("Invalid field %v in query %s", field, queryString)
+			}
+			q.fields = append(q.fields, name)
+		}
+	}
+	if sortFields, ok := q.query["sort"].([]interface{}); ok {
+		for _, spec := range sortFields {
+			switch s := spec.(type) {
+			case string:
+				q.sort = append(q.sort, sortField{field: s})
+			case map[string]interface{}:
+				for field, direction := range s {
+					q.sort = append(q.sort, sortField{field: field, descending: direction == "desc"})
+				}
+			default:
+				return nil, fmt.Errorf("Invalid sort %v in query %s", spec, queryString)
+			}
+		}
+	}
+	if skip, ok := q.query["skip"].(float64); ok {
+		q.skip = int(skip)
+	}
+	if limit, ok := q.query["limit"].(float64); ok {
+		q.limit = int(limit)
+	}
+	return q, nil
+}
+
+// fullDocsQuery returns the query reading the whole documents matching the
+// selector, extra more than the query skips and limits
+func (q *couchQuery) fullDocsQuery(extra int) (string, error) {
+	query := make(map[string]interface{}, len(q.query))
+	for k, v := range q.query {
+		query[k] = v
+	}
+	delete(query, jsonQueryFields)
+	query["skip"] = 0
+	query["limit"] = q.skip + q.limit + extra
+	queryBytes, err := json.Marshal(query)
+	if err != nil {
+		return "", fmt.Errorf("Error encoding query: %s", err)
+	}
+	return string(queryBytes), nil
+}
+
+// replaceChangedDocs returns the documents CouchDB returned which did not
+// change, and the changed documents as they were which match the selector
+func (q *couchQuery) replaceChangedDocs(results []couchdb.QueryResult, changedDocs map[string][]byte) ([]queryDoc, error) {
+	var matches []queryDoc
+	for _, result := range results {
+		if _, changed := changedDocs[result.ID]; changed {
+			continue
+		}
+		doc := make(map[string]interface{})
+		if err := json.Unmarshal(result.Value, &doc); err != nil {
+			return nil, fmt.Errorf("Error decoding document %s: %s", result.ID, err)
+		}
+		matches = append(matches, queryDoc{result.ID, doc, result.Value})
+	}
+	for id, docBytes := range changedDocs {
+		if docBytes == nil {
+			continue
+		}
+		// the binary values are attachments, the document has no data
+		doc := map[string]interface{}{"_id": id}
+		if couchdb.IsJSON(string(docBytes)) {
+			if err := json.Unmarshal(docBytes, &doc); err != nil {
+				return nil, fmt.Errorf("Error decoding document %s: %s", id, err)
+			}
+		}
+		match, err := matchSelector(q.selector, doc)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			matches = append(matches, queryDoc{id, doc, docBytes})
+		}
+	}
+	return matches, nil
+}
+
+// apply sorts, skips, limits and projects the documents matching the query,
+// the documents are sorted by id if the query does not sort them
+func (q *couchQuery) apply(matches []queryDoc) []couchdb.QueryResult {
+	sort.Stable(&sortedDocs{matches, q.sort})
+	if q.skip >= len(matches) {
+		return nil
+	}
+	matches = matches[q.skip:]
+	if q.limit < len(matches) {
+		matches = matches[:q.limit]
+	}
+
+	results := make([]couchdb.QueryResult, len(matches))
+	for i, match := range matches {
+		results[i] = couchdb.QueryResult{ID: match.id, Value: match.docBytes}
+		if len(q.fields) > 0 {
+			projection := make(map[string]interface{})
+			for _, field := range q.fields {
+				if value, exists := lookupField(match.doc, field); exists {
+					setField(projection, field, value)
+				}
+			}
+			results[i].Value, _ = json.Marshal(projection)
+		}
+	}
+	return results
+}
+
+// sortedDocs sorts documents by fields, or by id if there are none
+type sortedDocs struct {
+	docs   []queryDoc
+	fields []sortField
+}
+
+func (s *sortedDocs) Len() int      { return len(s.docs) }
+func (s *sortedDocs) Swap(i, j int) { s.docs[i], s.docs[j] = s.docs[j], s.docs[i] }
+func (s *sortedDocs) Less(i, j int) bool {
+	if len(s.fields) == 0 {
+		return s.docs[i].id < s.docs[j].id
+	}
+	for _, f := range s.fields {
+		a, _ := lookupField(s.docs[i].doc, f.field)
+		b, _ := lookupField(s.docs[j].doc, f.field)
+		if c := compareJSON(a, b); c != 0 {
+			return (c < 0) != f.descending
+		}
+	}
+	return false
+}
+
+// matchSelector returns whether the document matches the CouchDB selector. It
+// supports the combination and condition operators of CouchDB, and compares
+// the strings by their bytes where CouchDB uses the Unicode collation
+func matchSelector(selector map[string]interface{}, doc map[string]interface{}) (bool, error) {
+	for key, cond := range selector {
+		var match bool
+		var err error
+		switch key {
+		case "$and", "$or", "$nor":
+			match, err = matchCombination(key, cond, func(sub interface{}) (bool, error) {
+				subSelector, ok := sub.(map[string]interface{})
+				if !ok {
+					return false, fmt.Errorf("Invalid selector %v in %s", sub, key)
+				}
+				return matchSelector(subSelector, doc)
+			})
+		case "$not":
+			subSelector, ok := cond.(map[string]interface{})
+			if !ok {
+				return false, fmt.Errorf("Invalid selector %v in $not", cond)
+			}
+			match, err = matchSelector(subSelector, doc)
+			match = !match
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("Unsupported selector operator %s", key)
+			}
+			value, exists := lookupField(doc, key)
+			match, err = matchCondition(value, exists, cond)
+		}
+		if err != nil || !match {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchCombination evaluates the $and, $or or $nor operator op over the list
+// of conditions conds
+func matchCombination(op string, conds interface{}, match func(interface{}) (bool, error)) (bool, error) {
+	list, ok := conds.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("Operator %s requires a list", op)
+	}
+	for _, cond := range list {
+		m, err := match(cond)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case op == "$and" && !m:
+			return false, nil
+		case op == "$or" && m:
+			return true, nil
+		case op == "$nor" && m:
+			return false, nil
+		}
+	}
+	return op != "$or", nil
+}
+
+// matchCondition returns whether the value of a field, which exists or not,
+// matches the condition. A condition which is not an object of operators is
+// an equality, or a selector on the subfields of the field
+func matchCondition(value interface{}, exists bool, cond interface{}) (bool, error) {
+	condMap, ok := cond.(map[string]interface{})
+	if !ok {
+		return exists && compareJSON(value, cond) == 0, nil
+	}
+	for op, arg := range condMap {
+		if !strings.HasPrefix(op, "$") {
+			subDoc, ok := value.(map[string]interface{})
+			if !ok {
+				return false, nil
+			}
+			return matchSelector(condMap, subDoc)
+		}
+		match, err := matchOperator(op, arg, value, exists)
+		if err != nil || !match {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchOperator evaluates a condition operator on the value of a field. The
+// fields which do not exist only match $exists false
+func matchOperator(op string, arg interface{}, value interface{}, exists bool) (bool, error) {
+	if op == "$exists" {
+		want, ok := arg.(bool)
+		if !ok {
+			return false, fmt.Errorf("Operator $exists requires a boolean")
+		}
+		return exists == want, nil
+	}
+	if !exists {
+		return false, nil
+	}
+	switch op {
+	case "$eq":
+		return compareJSON(value, arg) == 0, nil
+	case "$ne":
+		return compareJSON(value, arg) != 0, nil
+	case "$lt":
+		return compareJSON(value, arg) < 0, nil
+	case "$lte":
+		return compareJSON(value, arg) <= 0, nil
+	case "$gt":
+		return compareJSON(value, arg) > 0, nil
+	case "$gte":
+		return compareJSON(value, arg) >= 0, nil
+	case "$type":
+		return jsonType(value) == arg, nil
+	case "$in", "$nin":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("Operator %s requires a list", op)
+		}
+		in := false
+		for _, item := range list {
+			if compareJSON(value, item) == 0 {
+				in = true
+				break
+			}
+			if values, isArray := value.([]interface{}); isArray && containsJSON(values, item) {
+				in = true
+				break
+			}
+		}
+		return in == (op == "$in"), nil
+	case "$size":
+		values, isArray := value.([]interface{})
+		size, ok := arg.(float64)
+		return isArray && ok && float64(len(values)) == size, nil
+	case "$mod":
+		args, ok := arg.([]interface{})
+		if !ok || len(args) != 2 {
+			return false, fmt.Errorf("Operator $mod requires a divisor and a remainder")
+		}
+		divisor, ok1 := args[0].(float64)
+		remainder, ok2 := args[1].(float64)
+		n, isNumber := value.(float64)
+		if !ok1 || !ok2 || divisor == 0 {
+			return false, fmt.Errorf("Operator $mod requires a divisor and a remainder")
+		}
+		return isNumber && n == math.Trunc(n) && math.Mod(n, divisor) == remainder, nil
+	case "$regex":
+		pattern, ok := arg.(string)
+		if !ok {
+			return false, fmt.Errorf("Operator $regex requires a string")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("Invalid $regex %s: %s", pattern, err)
+		}
+		s, isString := value.(string)
+		return isString && re.MatchString(s), nil
+	case "$all":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("Operator $all requires a list")
+		}
+		values, isArray := value.([]interface{})
+		if !isArray {
+			return false, nil
+		}
+		for _, item := range list {
+			if !containsJSON(values, item) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$elemMatch", "$allMatch":
+		values, isArray := value.([]interface{})
+		if !isArray || (op == "$allMatch" && len(values) == 0) {
+			return false, nil
+		}
+		for _, item := range values {
+			match, err := matchCondition(item, true, arg)
+			if err != nil {
+				return false, err
+			}
+			if match == (op == "$elemMatch") {
+				return match, nil
+			}
+		}
+		return op == "$allMatch", nil
+	case "$and", "$or", "$nor":
+		return matchCombination(op, arg, func(cond interface{}) (bool, error) {
+			return matchCondition(value, exists, cond)
+		})
+	case "$not":
+		match, err := matchCondition(value, exists, arg)
+		return !match, err
+	default:
+		return false, fmt.Errorf("Unsupported selector operator %s", op)
+	}
+}
+
+// lookupField returns the value of the field of the document, whose subfields
+// are separated by dots, and whether it exists
+func lookupField(doc map[string]interface{}, field string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, name := range strings.Split(field, ".") {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = fields[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// setField sets the field of the document, whose subfields are separated by dots
+func setField(doc map[string]interface{}, field string, value interface{}) {
+	names := strings.Split(field, ".")
+	for _, name := range names[:len(names)-1] {
+		sub, ok := doc[name].(map[string]interface{})
+		if !ok {
+			sub = make(map[string]interface{})
+			doc[name] = sub
+		}
+		doc = sub
+	}
+	doc[names[len(names)-1]] = value
+}
+
+// jsonType returns the CouchDB type of a JSON value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// compareJSON compares two JSON values in the CouchDB order: null, false,
+// true, numbers, strings, arrays, then objects
+func compareJSON(a, b interface{}) int {
+	rankA, rankB := jsonRank(a), jsonRank(b)
+	if rankA != rankB {
+		return rankA - rankB
+	}
+	switch x := a.(type) {
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(x, b.(string))
+	case []interface{}:
+		y := b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compareJSON(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	case map[string]interface{}:
+		if reflect.DeepEqual(a, b) {
+			return 0
+		}
+		xBytes, _ := json.Marshal(x)
+		yBytes, _ := json.Marshal(b)
+		return strings.Compare(string(xBytes), string(yBytes))
+	}
+	return 0
+}
+
+func jsonRank(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 2
+		}
+		return 1
+	case float64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	default:
+		return 6
+	}
+}
+
+func containsJSON(values []interface{}, item interface{}) bool {
+	for _, value := range values {
+		if compareJSON(value, item) == 0 {
+			return true
+		}
+	}
+	return false
+}
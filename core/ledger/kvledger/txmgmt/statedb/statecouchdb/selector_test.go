@@ -0,0 +1,167 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statecouchdb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
+)
+
+func testDoc(t *testing.T, doc string) map[string]interface{} {
+	m := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(doc), &m); err != nil {
+		t.Fatalf("Error decoding %s: %s", doc, err)
+	}
+	return m
+}
+
+func TestMatchSelector(t *testing.T) {
+	doc := testDoc(t, `{"_id":"ns1\u0000key1","version":"1:0","data":{"owner":"tom","size":10,"colors":["red","blue"],"asset":{"name":"marble"}}}`)
+	for selector, expected := range map[string]bool{
+		`{"data.owner":"tom"}`:                                              true,
+		`{"data.owner":{"$eq":"jerry"}}`:                                    false,
+		`{"data.size":{"$gt":5,"$lte":10}}`:                                 true,
+		`{"data.size":{"$lt":10}}`:                                          false,
+		`{"data.owner":{"$gt":5}}`:                                          true,
+		`{"data.missing":{"$exists":false}}`:                                true,
+		`{"data.missing":{"$ne":"tom"}}`:                                    false,
+		`{"data.owner":{"$in":["jerry","tom"]}}`:                            true,
+		`{"data.owner":{"$nin":["jerry","tom"]}}`:                           false,
+		`{"data.colors":{"$all":["blue","red"]}}`:                           true,
+		`{"data.colors":{"$size":3}}`:                                       false,
+		`{"data.colors":{"$elemMatch":{"$eq":"red"}}}`:                      true,
+		`{"data.size":{"$mod":[3,1]}}`:                                      true,
+		`{"data.owner":{"$regex":"^t"}}`:                                    true,
+		`{"data.size":{"$type":"number"}}`:                                  true,
+		`{"data.asset":{"name":"marble"}}`:                                  true,
+		`{"data.asset.name":"marble"}`:                                      true,
+		`{"$or":[{"data.owner":"jerry"},{"data.size":10}]}`:                 true,
+		`{"$and":[{"data.owner":"tom"},{"data.size":{"$gt":10}}]}`:          false,
+		`{"$nor":[{"data.owner":"jerry"}]}`:                                 true,
+		`{"$not":{"data.owner":"tom"}}`:                                     false,
+		`{"data.owner":"tom","data.asset.name":{"$exists":true}}`:           true,
+		`{"data.size":{"$or":[{"$lt":5},{"$gt":8}]}}`:                       true,
+		`{"data.colors":{"$elemMatch":{"$in":["green","yellow"]}}}`:         false,
+		`{"$or":[{"data.owner":"jerry"},{"data.colors":{"$in":["blue"]}}]}`: true,
+	} {
+		match, err := matchSelector(testDoc(t, selector), doc)
+		testutil.AssertNoError(t, err, selector)
+		testutil.AssertEquals(t, match, expected)
+	}
+
+	_, err := matchSelector(testDoc(t, `{"data.owner":{"$near":1}}`), doc)
+	testutil.AssertError(t, err, "An unsupported operator should have failed the match")
+}
+
+func TestCompareJSON(t *testing.T) {
+	ordered := []interface{}{nil, false, true, float64(-1), float64(2), "a", "b",
+		[]interface{}{"a"}, []interface{}{"a", "b"}, map[string]interface{}{"a": "b"}}
+	for i := range ordered {
+		for j := range ordered {
+			c := compareJSON(ordered[i], ordered[j])
+			switch {
+			case i < j:
+				testutil.AssertEquals(t, c < 0, true)
+			case i > j:
+				testutil.AssertEquals(t, c > 0, true)
+			default:
+				testutil.AssertEquals(t, c, 0)
+			}
+		}
+	}
+}
+
+func TestQueryApply(t *testing.T) {
+	q, err := parseQuery(ApplyQueryWrapper("ns1", `{"selector":{"owner":"tom"},"fields":["owner","size"],"sort":[{"size":"desc"}],"skip":1,"limit":2}`))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, q.skip, 1)
+	testutil.AssertEquals(t, q.limit, 2)
+
+	fullQuery, err := q.fullDocsQuery(3)
+	testutil.AssertNoError(t, err, "")
+	full := testDoc(t, fullQuery)
+	testutil.AssertEquals(t, full["limit"], float64(6))
+	testutil.AssertEquals(t, full["skip"], float64(0))
+	_, hasFields := full["fields"]
+	testutil.AssertEquals(t, hasFields, false)
+
+	docs := map[string]string{
+		"ns1\x00key1": `{"_id":"ns1\u0000key1","version":"1:0","data":{"owner":"tom","size":1}}`,
+		"ns1\x00key2": `{"_id":"ns1\u0000key2","version":"1:1","data":{"owner":"tom","size":2}}`,
+		"ns1\x00key3": `{"_id":"ns1\u0000key3","version":"1:2","data":{"owner":"tom","size":3}}`,
+		"ns1\x00key4": `{"_id":"ns1\u0000key4","version":"1:3","data":{"owner":"tom","size":4}}`,
+	}
+	// CouchDB has key1 and key2 as they were, key3 was changed to another
+	// owner, key4 was deleted and key5 added since the snapshot was taken
+	results := []couchdb.QueryResult{
+		{ID: "ns1\x00key2", Value: []byte(docs["ns1\x00key2"])},
+		{ID: "ns1\x00key1", Value: []byte(docs["ns1\x00key1"])},
+		{ID: "ns1\x00key5", Value: []byte(`{"_id":"ns1\u0000key5","version":"2:0","data":{"owner":"tom","size":5}}`)},
+	}
+	changed := map[string][]byte{
+		"ns1\x00key3": []byte(docs["ns1\x00key3"]),
+		"ns1\x00key4": []byte(docs["ns1\x00key4"]),
+		"ns1\x00key5": nil,
+	}
+	matches, err := q.replaceChangedDocs(results, changed)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(matches), 4)
+
+	applied := q.apply(matches)
+	testutil.AssertEquals(t, len(applied), 2)
+	testutil.AssertEquals(t, applied[0].ID, "ns1\x00key3")
+	testutil.AssertEquals(t, applied[1].ID, "ns1\x00key2")
+	value, _, version := removeDataWrapper(applied[0].Value)
+	testutil.AssertEquals(t, testDoc(t, string(value)), map[string]interface{}{"owner": "tom", "size": float64(3)})
+	testutil.AssertEquals(t, version.BlockNum, uint64(1))
+	testutil.AssertEquals(t, version.TxNum, uint64(2))
+}
+
+func TestSnapshotChangedDocs(t *testing.T) {
+	vdb := &VersionedDB{snapshots: make(map[*snapshot]struct{})}
+	s1, _ := vdb.GetSnapshot()
+	vdb.recordPreviousDoc("ns1\x00key1", []byte("v1"))
+	// a snapshot taken while an update is in progress reads the state before it
+	s2, _ := vdb.GetSnapshot()
+	vdb.recordPreviousDoc("ns1\x00key2", nil)
+	vdb.endUpdate()
+	s3, _ := vdb.GetSnapshot()
+	vdb.recordPreviousDoc("ns1\x00key1", []byte("v2"))
+	vdb.endUpdate()
+
+	docBytes, changed := s1.(*snapshot).changedDoc("ns1\x00key1")
+	testutil.AssertEquals(t, changed, true)
+	testutil.AssertEquals(t, docBytes, []byte("v1"))
+	docBytes, changed = s2.(*snapshot).changedDoc("ns1\x00key1")
+	testutil.AssertEquals(t, changed, true)
+	testutil.AssertEquals(t, docBytes, []byte("v1"))
+	testutil.AssertEquals(t, s2.(*snapshot).changedCount(), 2)
+	docBytes, changed = s3.(*snapshot).changedDoc("ns1\x00key1")
+	testutil.AssertEquals(t, changed, true)
+	testutil.AssertEquals(t, docBytes, []byte("v2"))
+	_, changed = s3.(*snapshot).changedDoc("ns1\x00key2")
+	testutil.AssertEquals(t, changed, false)
+	testutil.AssertEquals(t, len(s1.(*snapshot).changedDocs("ns1\x00key2", "ns1\x00key3")), 1)
+
+	s1.Release()
+	s2.Release()
+	s3.Release()
+	testutil.AssertEquals(t, len(vdb.snapshots), 0)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statecouchdb
+
+import (
+	"sort"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
+)
+
+// GetSnapshot implements method in SnapshotProvider interface. CouchDB has no
+// snapshots, so the updates applied while a snapshot is open keep the documents
+// they change as they were before in the snapshot. The snapshot reads CouchDB,
+// then replaces what it read by the documents it kept, which the update keeps
+// before changing them. A snapshot taken while an update is in progress keeps
+// the documents the update already changed as well, so it reads the state
+// before the update
+func (vdb *VersionedDB) GetSnapshot() (statedb.Snapshot, error) {
+	vdb.snapshotsLock.Lock()
+	defer vdb.snapshotsLock.Unlock()
+	s := &snapshot{vdb: vdb, docs: make(map[string][]byte, len(vdb.pending))}
+	for id, docBytes := range vdb.pending {
+		s.docs[id] = docBytes
+	}
+	vdb.snapshots[s] = struct{}{}
+	return s, nil
+}
+
+// recordPreviousDoc keeps the document of id as it is before the update in
+// progress changes it, nil if there is none
+func (vdb *VersionedDB) recordPreviousDoc(id string, docBytes []byte) {
+	vdb.snapshotsLock.Lock()
+	defer vdb.snapshotsLock.Unlock()
+	if vdb.pending == nil {
+		vdb.pending = make(map[string][]byte)
+	}
+	if _, exists := vdb.pending[id]; !exists {
+		vdb.pending[id] = docBytes
+	}
+	for s := range vdb.snapshots {
+		if _, exists := s.docs[id]; !exists {
+			s.docs[id] = docBytes
+		}
+	}
+}
+
+// endUpdate drops the documents kept for the update which ended
+func (vdb *VersionedDB) endUpdate() {
+	vdb.snapshotsLock.Lock()
+	vdb.pending = nil
+	vdb.snapshotsLock.Unlock()
+}
+
+// snapshot implements Snapshot interface
+type snapshot struct {
+	vdb *VersionedDB
+	// docs are the documents changed since the snapshot was taken, as they
+	// were then, guarded by the snapshotsLock of vdb
+	docs map[string][]byte
+}
+
+// changedDocs returns the documents changed since the snapshot was taken whose
+// id is in [startID, endID), all of them if endID is empty
+func (s *snapshot) changedDocs(startID, endID string) map[string][]byte {
+	s.vdb.snapshotsLock.Lock()
+	defer s.vdb.snapshotsLock.Unlock()
+	docs := make(map[string][]byte)
+	for id, docBytes := range s.docs {
+		if endID == "" || (id >= startID && id < endID) {
+			docs[id] = docBytes
+		}
+	}
+	return docs
+}
+
+// changedDoc returns the document of id as it was when the snapshot was taken,
+// and whether it changed since
+func (s *snapshot) changedDoc(id string) ([]byte, bool) {
+	s.vdb.snapshotsLock.Lock()
+	defer s.vdb.snapshotsLock.Unlock()
+	docBytes, changed := s.docs[id]
+	return docBytes, changed
+}
+
+// changedCount returns the number of documents changed since the snapshot was taken
+func (s *snapshot) changedCount() int {
+	s.vdb.snapshotsLock.Lock()
+	defer s.vdb.snapshotsLock.Unlock()
+	return len(s.docs)
+}
+
+// GetState implements method in Snapshot interface
+func (s *snapshot) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
+	vv, err := s.vdb.GetState(namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	if docBytes, changed := s.changedDoc(string(constructCompositeKey(namespace, key))); changed {
+		return newVersionedValue(docBytes), nil
+	}
+	return vv, nil
+}
+
+// GetStateMultipleKeys implements method in Snapshot interface
+func (s *snapshot) GetStateMultipleKeys(namespace string, keys []string) ([]*statedb.VersionedValue, error) {
+	vals := make([]*statedb.VersionedValue, len(keys))
+	for i, key := range keys {
+		val, err := s.GetState(namespace, key)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+	return vals, nil
+}
+
+// GetStateRangeScanIterator implements method in Snapshot interface
+func (s *snapshot) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (statedb.ResultsIterator, error) {
+	compositeStartKey := constructCompositeKey(namespace, startKey)
+	compositeEndKey := constructCompositeKey(namespace, endKey)
+	if endKey == "" {
+		compositeEndKey[len(compositeEndKey)-1] = lastKeyIndicator
+	}
+	queryResult, err := s.vdb.db.ReadDocRange(string(compositeStartKey), string(compositeEndKey), 1000, 0)
+	if err != nil {
+		logger.Debugf("Error calling ReadDocRange(): %s\n", err.Error())
+		return nil, err
+	}
+	docs := s.changedDocs(string(compositeStartKey), string(compositeEndKey))
+	if len(docs) == 0 {
+		return newKVScanner(namespace, *queryResult), nil
+	}
+
+	var results []couchdb.QueryResult
+	for _, result := range *queryResult {
+		if _, changed := docs[result.ID]; !changed {
+			results = append(results, result)
+		}
+	}
+	for id, docBytes := range docs {
+		if docBytes != nil {
+			results = append(results, couchdb.QueryResult{ID: id, Value: docBytes})
+		}
+	}
+	sort.Sort(resultsByID(results))
+	return newKVScanner(namespace, results), nil
+}
+
+// ExecuteQuery implements method in Snapshot interface. When documents were
+// changed since the snapshot was taken, CouchDB returns the whole documents
+// matching the selector of the query, and the changed ones are replaced by
+// the ones kept which match it before the documents are sorted, skipped,
+// limited and projected as the query says
+func (s *snapshot) ExecuteQuery(namespace, query string) (statedb.ResultsIterator, error) {
+	queryString := ApplyQueryWrapper(namespace, query)
+	if s.changedCount() == 0 {
+		queryResult, err := s.vdb.db.QueryDocuments(queryString, 1000, 0)
+		if err != nil {
+			logger.Debugf("Error calling QueryDocuments(): %s\n", err.Error())
+			return nil, err
+		}
+		if s.changedCount() == 0 {
+			return newQueryScanner(*queryResult), nil
+		}
+	}
+
+	q, err := parseQuery(queryString)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		// the query reads enough documents to replace the ones changed since
+		// the snapshot was taken, the changes made during the read included
+		changedCount := s.changedCount()
+		fullQuery, err := q.fullDocsQuery(changedCount)
+		if err != nil {
+			return nil, err
+		}
+		queryResult, err := s.vdb.db.QueryDocuments(fullQuery, q.skip+q.limit+changedCount, 0)
+		if err != nil {
+			logger.Debugf("Error calling QueryDocuments(): %s\n", err.Error())
+			return nil, err
+		}
+		docs := s.changedDocs("", "")
+		if len(docs) > changedCount {
+			continue
+		}
+		matches, err := q.replaceChangedDocs(*queryResult, docs)
+		if err != nil {
+			return nil, err
+		}
+		return newQueryScanner(q.apply(matches)), nil
+	}
+}
+
+// Release implements method in Snapshot interface
+func (s *snapshot) Release() {
+	s.vdb.snapshotsLock.Lock()
+	delete(s.vdb.snapshots, s)
+	s.vdb.snapshotsLock.Unlock()
+}
+
+type resultsByID []couchdb.QueryResult
+
+func (r resultsByID) Len() int           { return len(r) }
+func (r resultsByID) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r resultsByID) Less(i, j int) bool { return r[i].ID < r[j].ID }
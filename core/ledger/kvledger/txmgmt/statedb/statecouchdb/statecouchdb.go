@@ -18,6 +18,7 @@ package statecouchdb
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -86,10 +87,16 @@ func (provider *VersionedDBProvider) Close() {
 	// No close needed on Couch
 }
 
-// VersionedDB implements VersionedDB interface
+// VersionedDB implements VersionedDB and SnapshotProvider interfaces
 type VersionedDB struct {
 	db     *couchdb.CouchDatabase
 	dbName string
+
+	// snapshotsLock guards the open snapshots and pending, the documents
+	// changed by the update in progress as they were before it
+	snapshotsLock sync.Mutex
+	snapshots     map[*snapshot]struct{}
+	pending       map[string][]byte
 }
 
 // newVersionedDB constructs an instance of VersionedDB
@@ -99,7 +106,7 @@ func newVersionedDB(couchInstance *couchdb.CouchInstance, dbName string) (*Versi
 	if err != nil {
 		return nil, err
 	}
-	return &VersionedDB{db, dbName}, nil
+	return &VersionedDB{db: db, dbName: dbName, snapshots: make(map[*snapshot]struct{})}, nil
 }
 
 // Open implements method in VersionedDB interface
@@ -123,8 +130,13 @@ func (vdb *VersionedDB) GetState(namespace string, key string) (*statedb.Version
 	if err != nil {
 		return nil, err
 	}
+	return newVersionedValue(docBytes), nil
+}
+
+// newVersionedValue returns the value of the document read, nil if there is none
+func newVersionedValue(docBytes []byte) *statedb.VersionedValue {
 	if docBytes == nil {
-		return nil, nil
+		return nil
 	}
 
 	// trace the first 200 bytes of value only, in case it is huge
@@ -136,17 +148,20 @@ func (vdb *VersionedDB) GetState(namespace string, key string) (*statedb.Version
 		}
 	}
 
-	//remove the data wrapper and return the value and version
-	returnValue, returnVersion := removeDataWrapper(docBytes)
+	//remove the data wrapper and return the value, metadata and version
+	returnValue, returnMetadata, returnVersion := removeDataWrapper(docBytes)
 
-	return &statedb.VersionedValue{Value: returnValue, Version: &returnVersion}, nil
+	return &statedb.VersionedValue{Value: returnValue, Version: &returnVersion, Metadata: returnMetadata}
 }
 
-func removeDataWrapper(wrappedValue []byte) ([]byte, version.Height) {
+func removeDataWrapper(wrappedValue []byte) ([]byte, []byte, version.Height) {
 
 	//initialize the return value
 	returnValue := []byte{}
 
+	//initialize the return metadata, nil if the key has none
+	var returnMetadata []byte
+
 	//initialize a default return version
 	returnVersion := version.NewHeight(0, 0)
 
@@ -177,14 +192,19 @@ func removeDataWrapper(wrappedValue []byte) ([]byte, version.Height) {
 		//create the version based on the blockNum and txNum
 		returnVersion = version.NewHeight(blockNum, txNum)
 
+		//the metadata is base64 encoded by the json marshaling
+		if metadata, ok := jsonResult[metadataWrapper].(string); ok {
+			returnMetadata, _ = base64.StdEncoding.DecodeString(metadata)
+		}
+
 	} else {
 
-		//this is a binary, so decode the value and version from the binary
-		returnValue, returnVersion = statedb.DecodeValue(wrappedValue)
+		//this is a binary, so decode the value, metadata and version from the binary
+		returnValue, returnMetadata, returnVersion = statedb.DecodeValueAndMetadata(wrappedValue)
 
 	}
 
-	return returnValue, *returnVersion
+	return returnValue, returnMetadata, *returnVersion
 
 }
 
@@ -240,8 +260,10 @@ func (vdb *VersionedDB) ExecuteQuery(namespace, query string) (statedb.ResultsIt
 	return newQueryScanner(*queryResult), nil
 }
 
-// ApplyUpdates implements method in VersionedDB interface
+// ApplyUpdates implements method in VersionedDB interface. The documents are read before they
+// are changed, which gives their revision, and the open snapshots keep them as they were
 func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version.Height) error {
+	defer vdb.endUpdate()
 
 	namespaces := batch.GetUpdatedNamespaces()
 	for _, ns := range namespaces {
@@ -258,10 +280,19 @@ func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version
 				logger.Debugf("Applying key=%#v, versionedValue=%s", compositeKey, versionedValueDump)
 			}
 
+			docBytes, rev, err := vdb.db.ReadDoc(string(compositeKey))
+			if err != nil {
+				logger.Errorf("Error during Commit(): %s\n", err.Error())
+				return err
+			}
+			vdb.recordPreviousDoc(string(compositeKey), docBytes)
+
 			//convert nils to deletes
 			if vv.Value == nil {
 
-				vdb.db.DeleteDoc(string(compositeKey), "")
+				if docBytes != nil {
+					vdb.db.DeleteDoc(string(compositeKey), rev)
+				}
 
 			} else {
 
@@ -270,20 +301,20 @@ func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version
 				if couchdb.IsJSON(string(vv.Value)) {
 
 					// SaveDoc using couchdb client and use JSON format
-					rev, err := vdb.db.SaveDoc(string(compositeKey), "", addVersionAndChainCodeID(vv.Value, ns, vv.Version), nil)
+					newRev, err := vdb.db.SaveDoc(string(compositeKey), rev, addVersionAndChainCodeID(vv.Value, ns, vv.Version, vv.Metadata), nil)
 					if err != nil {
 						logger.Errorf("Error during Commit(): %s\n", err.Error())
 						return err
 					}
-					if rev != "" {
-						logger.Debugf("Saved document revision number: %s\n", rev)
+					if newRev != "" {
+						logger.Debugf("Saved document revision number: %s\n", newRev)
 					}
 
 				} else { // if the data is not JSON, save as binary attachment in Couch
 
 					//Create an attachment structure and load the bytes
 					attachment := &couchdb.Attachment{}
-					attachment.AttachmentBytes = statedb.EncodeValue(vv.Value, vv.Version)
+					attachment.AttachmentBytes = statedb.EncodeValueAndMetadata(vv.Value, vv.Metadata, vv.Version)
 					attachment.ContentType = "application/octet-stream"
 					attachment.Name = "valueBytes"
 
@@ -291,13 +322,13 @@ func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version
 					attachments = append(attachments, *attachment)
 
 					// SaveDoc using couchdb client and use attachment to persist the binary data
-					rev, err := vdb.db.SaveDoc(string(compositeKey), "", addVersionAndChainCodeID(nil, ns, vv.Version), attachments)
+					newRev, err := vdb.db.SaveDoc(string(compositeKey), rev, addVersionAndChainCodeID(nil, ns, vv.Version, nil), attachments)
 					if err != nil {
 						logger.Errorf("Error during Commit(): %s\n", err.Error())
 						return err
 					}
-					if rev != "" {
-						logger.Debugf("Saved document revision number: %s\n", rev)
+					if newRev != "" {
+						logger.Debugf("Saved document revision number: %s\n", newRev)
 					}
 				}
 			}
@@ -314,8 +345,8 @@ func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version
 	return nil
 }
 
-//addVersionAndChainCodeID adds keys for version and chaincodeID to the JSON value
-func addVersionAndChainCodeID(value []byte, chaincodeID string, version *version.Height) []byte {
+//addVersionAndChainCodeID adds keys for version, chaincodeID and metadata to the JSON value
+func addVersionAndChainCodeID(value []byte, chaincodeID string, version *version.Height, metadata []byte) []byte {
 
 	//create a version mapping
 	jsonMap := map[string]interface{}{"version": fmt.Sprintf("%v:%v", version.BlockNum, version.TxNum)}
@@ -323,6 +354,11 @@ func addVersionAndChainCodeID(value []byte, chaincodeID string, version *version
 	//add the chaincodeID
 	jsonMap["chaincodeid"] = chaincodeID
 
+	//add the metadata if the key has some
+	if metadata != nil {
+		jsonMap[metadataWrapper] = metadata
+	}
+
 	//Add the wrapped data if the value is not null
 	if value != nil {
 
@@ -457,12 +493,12 @@ func (scanner *kvScanner) Next() (statedb.QueryResult, error) {
 
 	_, key := splitCompositeKey([]byte(selectedKV.ID))
 
-	//remove the data wrapper and return the value and version
-	returnValue, returnVersion := removeDataWrapper(selectedKV.Value)
+	//remove the data wrapper and return the value, metadata and version
+	returnValue, returnMetadata, returnVersion := removeDataWrapper(selectedKV.Value)
 
 	return &statedb.VersionedKV{
 		CompositeKey:   statedb.CompositeKey{Namespace: scanner.namespace, Key: key},
-		VersionedValue: statedb.VersionedValue{Value: returnValue, Version: &returnVersion}}, nil
+		VersionedValue: statedb.VersionedValue{Value: returnValue, Version: &returnVersion, Metadata: returnMetadata}}, nil
 }
 
 func (scanner *kvScanner) Close() {
@@ -491,7 +527,7 @@ func (scanner *queryScanner) Next() (statedb.QueryResult, error) {
 	namespace, key := splitCompositeKey([]byte(selectedResultRecord.ID))
 
 	//remove the data wrapper and return the value and version
-	returnValue, returnVersion := removeDataWrapper(selectedResultRecord.Value)
+	returnValue, _, returnVersion := removeDataWrapper(selectedResultRecord.Value)
 
 	return &statedb.VersionedQueryRecord{
 		Namespace: namespace,
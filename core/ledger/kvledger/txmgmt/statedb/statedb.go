@@ -57,16 +57,39 @@ type VersionedDB interface {
 	Close()
 }
 
+// Snapshot is a read-only view of a VersionedDB at the time it was taken, which the updates applied
+// afterwards do not affect. The snapshot should be released after the use
+type Snapshot interface {
+	// GetState gets the value for given namespace and key as of the snapshot
+	GetState(namespace string, key string) (*VersionedValue, error)
+	// GetStateMultipleKeys gets the values for multiple keys in a single call
+	GetStateMultipleKeys(namespace string, keys []string) ([]*VersionedValue, error)
+	// GetStateRangeScanIterator returns an iterator over the snapshot, see VersionedDB.GetStateRangeScanIterator
+	GetStateRangeScanIterator(namespace string, startKey string, endKey string) (ResultsIterator, error)
+	// ExecuteQuery executes the given query over the snapshot, see VersionedDB.ExecuteQuery
+	ExecuteQuery(namespace, query string) (ResultsIterator, error)
+	// Release releases the resources held by the snapshot
+	Release()
+}
+
+// SnapshotProvider is implemented by the VersionedDBs which can take snapshots
+type SnapshotProvider interface {
+	// GetSnapshot takes a snapshot of the db
+	GetSnapshot() (Snapshot, error)
+}
+
 // CompositeKey encloses Namespace and Key components
 type CompositeKey struct {
 	Namespace string
 	Key       string
 }
 
-// VersionedValue encloses value and corresponding version
+// VersionedValue encloses value and corresponding version.
+// Metadata holds the metadata entries of the key encoded by EncodeMetadata, nil if there is none
 type VersionedValue struct {
-	Value   []byte
-	Version *version.Height
+	Value    []byte
+	Version  *version.Height
+	Metadata []byte
 }
 
 // VersionedKV encloses key and corresponding VersionedValue
@@ -125,17 +148,25 @@ func (batch *UpdateBatch) Get(ns string, key string) *VersionedValue {
 
 // Put adds a VersionedKV
 func (batch *UpdateBatch) Put(ns string, key string, value []byte, version *version.Height) {
+	if value == nil {
+		panic("Nil value not allowed")
+	}
+	batch.PutValAndMetadata(ns, key, value, nil, version)
+}
+
+// PutValAndMetadata adds a VersionedKV along with the encoded metadata of the key
+func (batch *UpdateBatch) PutValAndMetadata(ns string, key string, value []byte, metadata []byte, version *version.Height) {
 	if value == nil {
 		panic("Nil value not allowed")
 	}
 	nsUpdates := batch.getOrCreateNsUpdates(ns)
-	nsUpdates.m[key] = &VersionedValue{value, version}
+	nsUpdates.m[key] = &VersionedValue{value, version, metadata}
 }
 
 // Delete deletes a Key and associated value
 func (batch *UpdateBatch) Delete(ns string, key string, version *version.Height) {
 	nsUpdates := batch.getOrCreateNsUpdates(ns)
-	nsUpdates.m[key] = &VersionedValue{nil, version}
+	nsUpdates.m[key] = &VersionedValue{nil, version, nil}
 }
 
 // Exists checks whether the given key exists in the batch
@@ -225,7 +256,7 @@ func (itr *nsIterator) Next() (QueryResult, error) {
 	key := itr.sortedKeys[itr.nextIndex]
 	vv := itr.nsUpdates.m[key]
 	itr.nextIndex++
-	return &VersionedKV{CompositeKey{itr.ns, key}, VersionedValue{vv.Value, vv.Version}}, nil
+	return &VersionedKV{CompositeKey{itr.ns, key}, VersionedValue{vv.Value, vv.Version, vv.Metadata}}, nil
 }
 
 // Close implements the method from QueryResult interface
@@ -80,45 +80,36 @@ func (vdb *versionedDB) Close() {
 	// do nothing because shared db is used
 }
 
+// dbReader reads the db or one of its snapshots
+type dbReader interface {
+	Get(key []byte) ([]byte, error)
+	GetIterator(startKey []byte, endKey []byte) *leveldbhelper.Iterator
+}
+
 // GetState implements method in VersionedDB interface
 func (vdb *versionedDB) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
-	logger.Debugf("GetState(). ns=%s, key=%s", namespace, key)
-	compositeKey := constructCompositeKey(namespace, key)
-	dbVal, err := vdb.db.Get(compositeKey)
-	if err != nil {
-		return nil, err
-	}
-	if dbVal == nil {
-		return nil, nil
-	}
-	val, ver := statedb.DecodeValue(dbVal)
-	return &statedb.VersionedValue{Value: val, Version: ver}, nil
+	return getState(vdb.db, namespace, key)
 }
 
 // GetStateMultipleKeys implements method in VersionedDB interface
 func (vdb *versionedDB) GetStateMultipleKeys(namespace string, keys []string) ([]*statedb.VersionedValue, error) {
-	vals := make([]*statedb.VersionedValue, len(keys))
-	for i, key := range keys {
-		val, err := vdb.GetState(namespace, key)
-		if err != nil {
-			return nil, err
-		}
-		vals[i] = val
-	}
-	return vals, nil
+	return getStateMultipleKeys(vdb.db, namespace, keys)
 }
 
 // GetStateRangeScanIterator implements method in VersionedDB interface
 // startKey is inclusive
 // endKey is exclusive
 func (vdb *versionedDB) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (statedb.ResultsIterator, error) {
-	compositeStartKey := constructCompositeKey(namespace, startKey)
-	compositeEndKey := constructCompositeKey(namespace, endKey)
-	if endKey == "" {
-		compositeEndKey[len(compositeEndKey)-1] = lastKeyIndicator
+	return getStateRangeScanIterator(vdb.db, namespace, startKey, endKey), nil
+}
+
+// GetSnapshot implements method in SnapshotProvider interface
+func (vdb *versionedDB) GetSnapshot() (statedb.Snapshot, error) {
+	s, err := vdb.db.GetSnapshot()
+	if err != nil {
+		return nil, err
 	}
-	dbItr := vdb.db.GetIterator(compositeStartKey, compositeEndKey)
-	return newKVScanner(namespace, dbItr), nil
+	return &snapshot{s}, nil
 }
 
 // ExecuteQuery implements method in VersionedDB interface
@@ -145,7 +136,7 @@ func (vdb *versionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version
 			if vv.Value == nil {
 				dbBatch.Delete(compositeKey)
 			} else {
-				dbBatch.Put(compositeKey, statedb.EncodeValue(vv.Value, vv.Version))
+				dbBatch.Put(compositeKey, statedb.EncodeValueAndMetadata(vv.Value, vv.Metadata, vv.Version))
 			}
 		}
 	}
@@ -169,6 +160,72 @@ func (vdb *versionedDB) GetLatestSavePoint() (*version.Height, error) {
 	return version, nil
 }
 
+// snapshot implements Snapshot interface
+type snapshot struct {
+	db *leveldbhelper.SnapshotHandle
+}
+
+// GetState implements method in Snapshot interface
+func (s *snapshot) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
+	return getState(s.db, namespace, key)
+}
+
+// GetStateMultipleKeys implements method in Snapshot interface
+func (s *snapshot) GetStateMultipleKeys(namespace string, keys []string) ([]*statedb.VersionedValue, error) {
+	return getStateMultipleKeys(s.db, namespace, keys)
+}
+
+// GetStateRangeScanIterator implements method in Snapshot interface
+func (s *snapshot) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (statedb.ResultsIterator, error) {
+	return getStateRangeScanIterator(s.db, namespace, startKey, endKey), nil
+}
+
+// ExecuteQuery implements method in Snapshot interface
+func (s *snapshot) ExecuteQuery(namespace, query string) (statedb.ResultsIterator, error) {
+	return nil, errors.New("ExecuteQuery not supported for leveldb")
+}
+
+// Release implements method in Snapshot interface
+func (s *snapshot) Release() {
+	s.db.Release()
+}
+
+func getState(db dbReader, namespace string, key string) (*statedb.VersionedValue, error) {
+	logger.Debugf("GetState(). ns=%s, key=%s", namespace, key)
+	compositeKey := constructCompositeKey(namespace, key)
+	dbVal, err := db.Get(compositeKey)
+	if err != nil {
+		return nil, err
+	}
+	if dbVal == nil {
+		return nil, nil
+	}
+	val, metadata, ver := statedb.DecodeValueAndMetadata(dbVal)
+	return &statedb.VersionedValue{Value: val, Version: ver, Metadata: metadata}, nil
+}
+
+func getStateMultipleKeys(db dbReader, namespace string, keys []string) ([]*statedb.VersionedValue, error) {
+	vals := make([]*statedb.VersionedValue, len(keys))
+	for i, key := range keys {
+		val, err := getState(db, namespace, key)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+	return vals, nil
+}
+
+func getStateRangeScanIterator(db dbReader, namespace string, startKey string, endKey string) *kvScanner {
+	compositeStartKey := constructCompositeKey(namespace, startKey)
+	compositeEndKey := constructCompositeKey(namespace, endKey)
+	if endKey == "" {
+		compositeEndKey[len(compositeEndKey)-1] = lastKeyIndicator
+	}
+	dbItr := db.GetIterator(compositeStartKey, compositeEndKey)
+	return newKVScanner(namespace, dbItr)
+}
+
 func constructCompositeKey(ns string, key string) []byte {
 	return append(append([]byte(ns), compositeKeySep...), []byte(key)...)
 }
@@ -196,10 +253,10 @@ func (scanner *kvScanner) Next() (statedb.QueryResult, error) {
 	dbValCopy := make([]byte, len(dbVal))
 	copy(dbValCopy, dbVal)
 	_, key := splitCompositeKey(dbKey)
-	value, version := statedb.DecodeValue(dbValCopy)
+	value, metadata, version := statedb.DecodeValueAndMetadata(dbValCopy)
 	return &statedb.VersionedKV{
 		CompositeKey:   statedb.CompositeKey{Namespace: scanner.namespace, Key: key},
-		VersionedValue: statedb.VersionedValue{Value: value, Version: version}}, nil
+		VersionedValue: statedb.VersionedValue{Value: value, Version: version, Metadata: metadata}}, nil
 }
 
 func (scanner *kvScanner) Close() {
@@ -16,7 +16,24 @@ limitations under the License.
 
 package statedb
 
-import "github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+)
+
+//metadataMarker starts the encoded values which carry metadata. The encoded versions
+//of the values without metadata start with the size of the block number, at most 8
+const metadataMarker = 0xff
+
+//tombstonePrefix starts the values left in the state db in place of purged values
+var tombstonePrefix = []byte("\x00fabric:purged\x00")
 
 //EncodeValue appends the value to the version, allows storage of version and value in binary form
 func EncodeValue(value []byte, version *version.Height) []byte {
@@ -29,7 +46,121 @@ func EncodeValue(value []byte, version *version.Height) []byte {
 
 //DecodeValue separates the version and value from a binary value
 func DecodeValue(encodedValue []byte) ([]byte, *version.Height) {
-	version, n := version.NewHeightFromBytes(encodedValue)
-	value := encodedValue[n:]
+	value, _, version := DecodeValueAndMetadata(encodedValue)
 	return value, version
 }
+
+//EncodeValueAndMetadata appends the metadata and the value to the version. The values
+//without metadata are encoded as by EncodeValue
+func EncodeValueAndMetadata(value []byte, metadata []byte, version *version.Height) []byte {
+	if metadata == nil {
+		return EncodeValue(value, version)
+	}
+	encodedValue := append([]byte{metadataMarker}, version.ToBytes()...)
+	encodedValue = append(encodedValue, proto.EncodeVarint(uint64(len(metadata)))...)
+	encodedValue = append(encodedValue, metadata...)
+	return append(encodedValue, value...)
+}
+
+//DecodeValueAndMetadata separates the version, metadata and value from a binary value
+func DecodeValueAndMetadata(encodedValue []byte) ([]byte, []byte, *version.Height) {
+	if len(encodedValue) == 0 || encodedValue[0] != metadataMarker {
+		version, n := version.NewHeightFromBytes(encodedValue)
+		return encodedValue[n:], nil, version
+	}
+	version, n := version.NewHeightFromBytes(encodedValue[1:])
+	n++
+	metadataLen, m := proto.DecodeVarint(encodedValue[n:])
+	n += m
+	metadata := encodedValue[n : n+int(metadataLen)]
+	return encodedValue[n+int(metadataLen):], metadata, version
+}
+
+//EncodeMetadata serializes the metadata entries of a key sorted by name, nil if there is none
+func EncodeMetadata(metadata map[string][]byte) []byte {
+	if len(metadata) == 0 {
+		return nil
+	}
+	var names []string
+	for name := range metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf := proto.NewBuffer(nil)
+	buf.EncodeVarint(uint64(len(names)))
+	for _, name := range names {
+		buf.EncodeStringBytes(name)
+		buf.EncodeRawBytes(metadata[name])
+	}
+	return buf.Bytes()
+}
+
+//DecodeMetadata deserializes the metadata entries encoded by EncodeMetadata
+func DecodeMetadata(encodedMetadata []byte) (map[string][]byte, error) {
+	if encodedMetadata == nil {
+		return nil, nil
+	}
+	buf := proto.NewBuffer(encodedMetadata)
+	numEntries, err := buf.DecodeVarint()
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(map[string][]byte, int(numEntries))
+	for i := 0; i < int(numEntries); i++ {
+		name, err := buf.DecodeStringBytes()
+		if err != nil {
+			return nil, err
+		}
+		if metadata[name], err = buf.DecodeRawBytes(true); err != nil {
+			return nil, err
+		}
+	}
+	return metadata, nil
+}
+
+//NewTombstone returns the value left in place of value when it is purged.
+//The tombstone holds the hash of value, so the erased value can still be
+//matched against the writes kept in the blocks
+func NewTombstone(value []byte) []byte {
+	hash := sha256.Sum256(value)
+	return append(append([]byte{}, tombstonePrefix...), hash[:]...)
+}
+
+//IsTombstone returns whether value was left in place of a purged value
+func IsTombstone(value []byte) bool {
+	return len(value) == len(tombstonePrefix)+sha256.Size && bytes.HasPrefix(value, tombstonePrefix)
+}
+
+//TombstoneHash returns the hash of the purged value held by tombstone
+func TombstoneHash(tombstone []byte) []byte {
+	return tombstone[len(tombstonePrefix):]
+}
+
+//compositeKeyDelimiter separates the object type and attributes of the composite keys
+//built by the chaincodes, and ends them
+const compositeKeyDelimiter = "\x00"
+
+//ValidateKey returns an error if key cannot be written to the state db: it must be a
+//non empty UTF-8 string of at most maxLength bytes, maxLength <= 0 meaning unlimited,
+//containing none of the illegalChars. The null character may only appear as the
+//delimiter of a composite key, which ends with it, so the keys cannot collide with
+//the composite keys or break the indexes and CouchDB document IDs built upon them
+func ValidateKey(key string, maxLength int, illegalChars string) error {
+	if key == "" {
+		return fmt.Errorf("Empty key")
+	}
+	if !utf8.ValidString(key) {
+		return fmt.Errorf("Key [%q] is not a valid UTF-8 string", key)
+	}
+	if maxLength > 0 && len(key) > maxLength {
+		return fmt.Errorf("Key [%q] is %d bytes long, exceeding the maximum of %d", key, len(key), maxLength)
+	}
+	if i := strings.IndexAny(key, illegalChars); i >= 0 {
+		r, _ := utf8.DecodeRuneInString(key[i:])
+		return fmt.Errorf("Key [%q] contains the illegal character %U", key, r)
+	}
+	if strings.Contains(key, compositeKeyDelimiter) && !strings.HasSuffix(key, compositeKeyDelimiter) {
+		return fmt.Errorf("Key [%q] contains a null character but is not a composite key", key)
+	}
+	return nil
+}
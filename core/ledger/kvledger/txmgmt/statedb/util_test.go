@@ -17,6 +17,7 @@ limitations under the License.
 package statedb
 
 import (
+	"crypto/sha256"
 	"testing"
 
 	"github.com/hyperledger/fabric/common/ledger/testutil"
@@ -52,3 +53,55 @@ func TestEncodeDecodeJSON(t *testing.T) {
 	testutil.AssertEquals(t, decodedVersion, version2)
 
 }
+
+// TestTombstone tests that a tombstone holds the hash of the purged value
+func TestTombstone(t *testing.T) {
+
+	value := []byte("value1")
+	tombstone := NewTombstone(value)
+	hash := sha256.Sum256(value)
+
+	testutil.AssertEquals(t, IsTombstone(tombstone), true)
+	testutil.AssertEquals(t, TombstoneHash(tombstone), hash[:])
+	testutil.AssertEquals(t, IsTombstone(NewTombstone(nil)), true)
+
+	testutil.AssertEquals(t, IsTombstone(value), false)
+	testutil.AssertEquals(t, IsTombstone(nil), false)
+	testutil.AssertEquals(t, IsTombstone(tombstone[:len(tombstone)-1]), false)
+}
+
+// TestEncodeDecodeValueAndMetadata tests encoding and decoding a value with its metadata
+func TestEncodeDecodeValueAndMetadata(t *testing.T) {
+
+	value := []byte("value1")
+	version1 := version.NewHeight(1, 2)
+	metadata := EncodeMetadata(map[string][]byte{"owner": []byte("org1"), "label": []byte("gold")})
+
+	decodedValue, decodedMetadata, decodedVersion := DecodeValueAndMetadata(EncodeValueAndMetadata(value, metadata, version1))
+	testutil.AssertEquals(t, decodedValue, value)
+	testutil.AssertEquals(t, decodedMetadata, metadata)
+	testutil.AssertEquals(t, decodedVersion, version1)
+
+	// values without metadata keep the encoding of EncodeValue
+	testutil.AssertEquals(t, EncodeValueAndMetadata(value, nil, version1), EncodeValue(value, version1))
+	decodedValue, decodedMetadata, _ = DecodeValueAndMetadata(EncodeValue(value, version1))
+	testutil.AssertEquals(t, decodedValue, value)
+	testutil.AssertNil(t, decodedMetadata)
+
+	entries, err := DecodeMetadata(metadata)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, entries, map[string][]byte{"owner": []byte("org1"), "label": []byte("gold")})
+	testutil.AssertNil(t, EncodeMetadata(map[string][]byte{}))
+}
+
+func TestValidateKey(t *testing.T) {
+	testutil.AssertNoError(t, ValidateKey("key1", 10, "\U0010FFFF"), "")
+	testutil.AssertNoError(t, ValidateKey("type\x00attr1\x00", 0, "\U0010FFFF"), "A composite key should be valid")
+	testutil.AssertNoError(t, ValidateKey("key1key1key1", 0, ""), "No limit should apply with maxLength 0")
+
+	testutil.AssertError(t, ValidateKey("", 10, ""), "An empty key should be invalid")
+	testutil.AssertError(t, ValidateKey("key\xff", 10, ""), "A key which is not UTF-8 should be invalid")
+	testutil.AssertError(t, ValidateKey("key1key1key1", 10, ""), "A key over the maximum length should be invalid")
+	testutil.AssertError(t, ValidateKey("type\x00\U0010FFFF", 0, "\U0010FFFF"), "A key with an illegal character should be invalid")
+	testutil.AssertError(t, ValidateKey("ke\x00y", 0, ""), "A key with a null character which is not a composite key should be invalid")
+}
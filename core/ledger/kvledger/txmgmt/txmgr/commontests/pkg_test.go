@@ -28,7 +28,6 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger/util"
-	"github.com/hyperledger/fabric/protos/common"
 	"github.com/spf13/viper"
 )
 
@@ -153,7 +152,8 @@ func (h *txMgrTestHelper) validateAndCommitRWSet(txRWSet []byte) {
 	block := h.bg.NextBlock([][]byte{txRWSet}, false)
 	err := h.txMgr.ValidateAndPrepare(block, true)
 	testutil.AssertNoError(h.t, err, "")
-	txsFltr := util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	txsFltr, err := util.GetTxsFilter(block)
+	testutil.AssertNoError(h.t, err, "")
 	invalidTxNum := 0
 	for i := 0; i < len(block.Data.Data); i++ {
 		if txsFltr.IsSet(uint(i)) {
@@ -169,7 +169,8 @@ func (h *txMgrTestHelper) checkRWsetInvalid(txRWSet []byte) {
 	block := h.bg.NextBlock([][]byte{txRWSet}, false)
 	err := h.txMgr.ValidateAndPrepare(block, true)
 	testutil.AssertNoError(h.t, err, "")
-	txsFltr := util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	txsFltr, err := util.GetTxsFilter(block)
+	testutil.AssertNoError(h.t, err, "")
 	invalidTxNum := 0
 	for i := 0; i < len(block.Data.Data); i++ {
 		if txsFltr.IsSet(uint(i)) {
@@ -20,11 +20,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/spf13/viper"
 )
 
 func TestTxSimulatorWithNoExistingData(t *testing.T) {
@@ -489,6 +492,85 @@ func testGetSetMultipeKeys(t *testing.T, env testEnv) {
 	}
 }
 
+func TestStateMetadata(t *testing.T) {
+	for _, testEnv := range testEnvs {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testEnv.init(t)
+		testStateMetadata(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testStateMetadata(t *testing.T, env testEnv) {
+	cID := "cID"
+	txMgr := env.getTxMgr()
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+	metadata := map[string][]byte{"owner": []byte("org1")}
+
+	// simulate tx1 setting a key with its metadata
+	s1, _ := txMgr.NewTxSimulator()
+	s1.SetState(cID, "key1", []byte("value1"))
+	s1.SetStateMetadata(cID, "key1", metadata)
+	txRWSet1, _ := s1.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet1)
+
+	// simulate tx2 updating the value, which keeps the metadata
+	s2, _ := txMgr.NewTxSimulator()
+	s2.SetState(cID, "key1", []byte("value1_new"))
+	txRWSet2, _ := s2.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet2)
+
+	qe, _ := txMgr.NewQueryExecutor()
+	value, _ := qe.GetState(cID, "key1")
+	testutil.AssertEquals(t, value, []byte("value1_new"))
+	md, err := qe.GetStateMetadata(cID, "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, md, metadata)
+	md, err = qe.GetStateMetadata(cID, "key2")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, md)
+	qe.Done()
+
+	// simulate tx3 deleting the key, which drops the metadata
+	s3, _ := txMgr.NewTxSimulator()
+	s3.DeleteState(cID, "key1")
+	txRWSet3, _ := s3.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet3)
+
+	qe, _ = txMgr.NewQueryExecutor()
+	defer qe.Done()
+	md, err = qe.GetStateMetadata(cID, "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, md)
+}
+
+func TestKeyValidation(t *testing.T) {
+	for _, testEnv := range testEnvs {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testEnv.init(t)
+		testKeyValidation(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testKeyValidation(t *testing.T, env testEnv) {
+	cID := "cID"
+	txMgr := env.getTxMgr()
+	defer viper.Set("ledger.state.keys.maxLength", viper.GetInt("ledger.state.keys.maxLength"))
+	viper.Set("ledger.state.keys.maxLength", 8)
+
+	s, _ := txMgr.NewTxSimulator()
+	defer s.Done()
+	testutil.AssertNoError(t, s.SetState(cID, "key1", []byte("value1")), "")
+	testutil.AssertNoError(t, s.SetState(cID, "t\x00a\x00", []byte("value1")), "A composite key should be valid")
+	testutil.AssertError(t, s.SetState(cID, "", []byte("value1")), "An empty key should be rejected")
+	testutil.AssertError(t, s.SetState(cID, "key1key1key1", []byte("value1")), "A key over the maximum length should be rejected")
+	testutil.AssertError(t, s.SetState(cID, "ke\x00y1", []byte("value1")), "A key with a null character should be rejected")
+	testutil.AssertError(t, s.SetState(cID, "t\x00\U0010FFFF", []byte("value1")), "A key with an illegal character should be rejected")
+	testutil.AssertError(t, s.SetStateMetadata(cID, "key1key1key1", map[string][]byte{"owner": []byte("org1")}),
+		"The metadata of a key over the maximum length should be rejected")
+}
+
 func createTestKey(i int) string {
 	if i == 0 {
 		return ""
@@ -500,7 +582,70 @@ func createTestValue(i int) []byte {
 	return []byte(fmt.Sprintf("value_%03d", i))
 }
 
-//TestExecuteQueryQuery is only tested on the CouchDB testEnv
+// TestExecuteQueryQuery is only tested on the CouchDB testEnv
+func TestQueryDuringCommit(t *testing.T) {
+	for _, testEnv := range testEnvs {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testEnv.init(t)
+		// the state dbs without snapshots keep blocking the commits
+		if _, ok := testEnv.getVDB().(statedb.SnapshotProvider); ok {
+			testQueryDuringCommit(t, testEnv)
+		}
+		testEnv.cleanup()
+	}
+}
+
+func testQueryDuringCommit(t *testing.T, env testEnv) {
+	txMgr := env.getTxMgr()
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+	s1, _ := txMgr.NewTxSimulator()
+	s1.SetState("ns1", "key1", []byte("value1"))
+	s1.SetState("ns1", "key2", []byte("value2"))
+	s1.Done()
+	txRWSet1, _ := s1.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet1)
+
+	// a query executor which is not done does not hold back the commits
+	qe, _ := txMgr.NewQueryExecutor()
+	defer qe.Done()
+	s2, _ := txMgr.NewTxSimulator()
+	s2.SetState("ns1", "key1", []byte("value1_1"))
+	s2.SetState("ns1", "key3", []byte("value3"))
+	s2.Done()
+	txRWSet2, _ := s2.GetTxSimulationResults()
+	committed := make(chan struct{})
+	go func() {
+		txMgrHelper.validateAndCommitRWSet(txRWSet2)
+		close(committed)
+	}()
+	select {
+	case <-committed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The commit should not have been blocked by the query executor")
+	}
+
+	// the query executor keeps reading the state it started with
+	value, _ := qe.GetState("ns1", "key1")
+	testutil.AssertEquals(t, value, []byte("value1"))
+	itr, _ := qe.GetStateRangeScanIterator("ns1", "", "")
+	var keys []string
+	for {
+		kv, err := itr.Next()
+		testutil.AssertNoError(t, err, "")
+		if kv == nil {
+			break
+		}
+		keys = append(keys, kv.(*ledger.KV).Key)
+	}
+	itr.Close()
+	testutil.AssertEquals(t, keys, []string{"key1", "key2"})
+
+	qe2, _ := txMgr.NewQueryExecutor()
+	value, _ = qe2.GetState("ns1", "key1")
+	testutil.AssertEquals(t, value, []byte("value1_1"))
+	qe2.Done()
+}
+
 func TestExecuteQuery(t *testing.T) {
 
 	// Query is only tested on the CouchDB testEnv
@@ -25,8 +25,18 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 )
 
+// stateReader is the part of the state db read by the query executors and the simulators,
+// either the db itself or one of its snapshots
+type stateReader interface {
+	GetState(namespace string, key string) (*statedb.VersionedValue, error)
+	GetStateMultipleKeys(namespace string, keys []string) ([]*statedb.VersionedValue, error)
+	GetStateRangeScanIterator(namespace string, startKey string, endKey string) (statedb.ResultsIterator, error)
+	ExecuteQuery(namespace, query string) (statedb.ResultsIterator, error)
+}
+
 type queryHelper struct {
-	txmgr       *LockBasedTxMgr
+	db          stateReader
+	release     func()
 	rwset       *rwset.RWSet
 	itrs        []*resultsItr
 	err         error
@@ -35,11 +45,14 @@ type queryHelper struct {
 
 func (h *queryHelper) getState(ns string, key string) ([]byte, error) {
 	h.checkDone()
-	versionedValue, err := h.txmgr.db.GetState(ns, key)
+	versionedValue, err := h.db.GetState(ns, key)
 	if err != nil {
 		return nil, err
 	}
 	val, ver := decomposeVersionedValue(versionedValue)
+	if statedb.IsTombstone(val) {
+		return nil, &ledger.PurgedKeyError{Namespace: ns, Key: key}
+	}
 	if h.rwset != nil {
 		h.rwset.AddToReadSet(ns, key, ver)
 	}
@@ -48,13 +61,16 @@ func (h *queryHelper) getState(ns string, key string) ([]byte, error) {
 
 func (h *queryHelper) getStateMultipleKeys(namespace string, keys []string) ([][]byte, error) {
 	h.checkDone()
-	versionedValues, err := h.txmgr.db.GetStateMultipleKeys(namespace, keys)
+	versionedValues, err := h.db.GetStateMultipleKeys(namespace, keys)
 	if err != nil {
 		return nil, nil
 	}
 	values := make([][]byte, len(versionedValues))
 	for i, versionedValue := range versionedValues {
 		val, ver := decomposeVersionedValue(versionedValue)
+		if statedb.IsTombstone(val) {
+			return nil, &ledger.PurgedKeyError{Namespace: namespace, Key: keys[i]}
+		}
 		if h.rwset != nil {
 			h.rwset.AddToReadSet(namespace, keys[i], ver)
 		}
@@ -63,9 +79,28 @@ func (h *queryHelper) getStateMultipleKeys(namespace string, keys []string) ([][
 	return values, nil
 }
 
+func (h *queryHelper) getStateMetadata(ns string, key string) (map[string][]byte, error) {
+	h.checkDone()
+	versionedValue, err := h.db.GetState(ns, key)
+	if err != nil {
+		return nil, err
+	}
+	val, ver := decomposeVersionedValue(versionedValue)
+	if statedb.IsTombstone(val) {
+		return nil, &ledger.PurgedKeyError{Namespace: ns, Key: key}
+	}
+	if h.rwset != nil {
+		h.rwset.AddToReadSet(ns, key, ver)
+	}
+	if versionedValue == nil {
+		return nil, nil
+	}
+	return statedb.DecodeMetadata(versionedValue.Metadata)
+}
+
 func (h *queryHelper) getStateRangeScanIterator(namespace string, startKey string, endKey string) (commonledger.ResultsIterator, error) {
 	h.checkDone()
-	itr, err := newResultsItr(namespace, startKey, endKey, h.txmgr.db, h.rwset,
+	itr, err := newResultsItr(namespace, startKey, endKey, h.db, h.rwset,
 		ledgerconfig.IsQueryReadsHashingEnabled(), ledgerconfig.GetMaxDegreeQueryReadsHashing())
 	if err != nil {
 		return nil, err
@@ -75,7 +110,7 @@ func (h *queryHelper) getStateRangeScanIterator(namespace string, startKey strin
 }
 
 func (h *queryHelper) executeQuery(namespace, query string) (commonledger.ResultsIterator, error) {
-	dbItr, err := h.txmgr.db.ExecuteQuery(namespace, query)
+	dbItr, err := h.db.ExecuteQuery(namespace, query)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +121,7 @@ func (h *queryHelper) done() {
 	if h.doneInvoked {
 		return
 	}
-	defer h.txmgr.commitRWLock.RUnlock()
+	defer h.release()
 	h.doneInvoked = true
 	for _, itr := range h.itrs {
 		itr.Close()
@@ -127,7 +162,7 @@ type resultsItr struct {
 }
 
 func newResultsItr(ns string, startKey string, endKey string,
-	db statedb.VersionedDB, rwSet *rwset.RWSet, enableHashing bool, maxDegree int) (*resultsItr, error) {
+	db stateReader, rwSet *rwset.RWSet, enableHashing bool, maxDegree int) (*resultsItr, error) {
 	dbItr, err := db.GetStateRangeScanIterator(ns, startKey, endKey)
 	if err != nil {
 		return nil, err
@@ -165,6 +200,9 @@ func (itr *resultsItr) Next() (commonledger.QueryResult, error) {
 		return nil, nil
 	}
 	versionedKV := queryResult.(*statedb.VersionedKV)
+	if statedb.IsTombstone(versionedKV.Value) {
+		return nil, &ledger.PurgedKeyError{Namespace: itr.ns, Key: versionedKV.Key}
+	}
 	return &ledger.KV{Key: versionedKV.Key, Value: versionedKV.Value}, nil
 }
 
@@ -214,6 +252,9 @@ func (itr *queryResultsItr) Next() (commonledger.QueryResult, error) {
 	}
 	versionedQueryRecord := queryResult.(*statedb.VersionedQueryRecord)
 	logger.Debugf("queryResultsItr.Next() returned a record:%s", string(versionedQueryRecord.Record))
+	if statedb.IsTombstone(versionedQueryRecord.Record) {
+		return nil, &ledger.PurgedKeyError{Namespace: versionedQueryRecord.Namespace, Key: versionedQueryRecord.Key}
+	}
 
 	if itr.RWSet != nil {
 		itr.RWSet.AddToReadSet(versionedQueryRecord.Namespace, versionedQueryRecord.Key, versionedQueryRecord.Version)
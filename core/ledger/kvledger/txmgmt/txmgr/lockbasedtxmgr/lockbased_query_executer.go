@@ -27,8 +27,8 @@ type lockBasedQueryExecutor struct {
 	id     string
 }
 
-func newQueryExecutor(txmgr *LockBasedTxMgr) *lockBasedQueryExecutor {
-	helper := &queryHelper{txmgr: txmgr, rwset: nil}
+func newQueryExecutor(db stateReader, release func()) *lockBasedQueryExecutor {
+	helper := &queryHelper{db: db, release: release, rwset: nil}
 	id := util.GenerateUUID()
 	logger.Debugf("constructing new query executor [%s]", id)
 	return &lockBasedQueryExecutor{helper, id}
@@ -44,6 +44,11 @@ func (q *lockBasedQueryExecutor) GetStateMultipleKeys(namespace string, keys []s
 	return q.helper.getStateMultipleKeys(namespace, keys)
 }
 
+// GetStateMetadata implements method in interface `ledger.QueryExecutor`
+func (q *lockBasedQueryExecutor) GetStateMetadata(namespace string, key string) (map[string][]byte, error) {
+	return q.helper.getStateMetadata(namespace, key)
+}
+
 // GetStateRangeScanIterator implements method in interface `ledger.QueryExecutor`
 // startKey is included in the results and endKey is excluded. An empty startKey refers to the first available key
 // and an empty endKey refers to the last available key. For scanning all the keys, both the startKey and the endKey
@@ -21,6 +21,8 @@ import (
 
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 )
 
 // LockBasedTxSimulator is a transaction simulator used in `LockBasedTxMgr`
@@ -29,9 +31,9 @@ type lockBasedTxSimulator struct {
 	rwset *rwset.RWSet
 }
 
-func newLockBasedTxSimulator(txmgr *LockBasedTxMgr) *lockBasedTxSimulator {
+func newLockBasedTxSimulator(db stateReader, release func()) *lockBasedTxSimulator {
 	rwset := rwset.NewRWSet()
-	helper := &queryHelper{txmgr: txmgr, rwset: rwset}
+	helper := &queryHelper{db: db, release: release, rwset: rwset}
 	id := util.GenerateUUID()
 	logger.Debugf("constructing new tx simulator [%s]", id)
 	return &lockBasedTxSimulator{lockBasedQueryExecutor{helper, id}, rwset}
@@ -45,6 +47,9 @@ func (s *lockBasedTxSimulator) GetState(ns string, key string) ([]byte, error) {
 // SetState implements method in interface `ledger.TxSimulator`
 func (s *lockBasedTxSimulator) SetState(ns string, key string, value []byte) error {
 	s.helper.checkDone()
+	if err := validateKey(key); err != nil {
+		return err
+	}
 	s.rwset.AddToWriteSet(ns, key, value)
 	return nil
 }
@@ -64,6 +69,21 @@ func (s *lockBasedTxSimulator) SetStateMultipleKeys(namespace string, kvs map[st
 	return nil
 }
 
+// SetStateMetadata implements method in interface `ledger.TxSimulator`
+func (s *lockBasedTxSimulator) SetStateMetadata(namespace string, key string, metadata map[string][]byte) error {
+	s.helper.checkDone()
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	s.rwset.AddToMetadataWriteSet(namespace, key, metadata)
+	return nil
+}
+
+// validateKey checks a key written by the simulation against the limits of the ledger config
+func validateKey(key string) error {
+	return statedb.ValidateKey(key, ledgerconfig.GetMaxKeyLength(), ledgerconfig.GetIllegalKeyChars())
+}
+
 // GetTxSimulationResults implements method in interface `ledger.TxSimulator`
 func (s *lockBasedTxSimulator) GetTxSimulationResults() ([]byte, error) {
 	logger.Debugf("Simulation completed, getting simulation results")
@@ -31,7 +31,10 @@ import (
 var logger = logging.MustGetLogger("lockbasedtxmgr")
 
 // LockBasedTxMgr a simple implementation of interface `txmgmt.TxMgr`.
-// This implementation uses a read-write lock to prevent conflicts between transaction simulation and committing
+// The query executors and the transaction simulators read a snapshot of the state db when the db supports
+// snapshots, as both LevelDB and CouchDB do, so that they neither block nor are blocked by the commits.
+// Otherwise this implementation uses a read-write lock to prevent conflicts between transaction simulation
+// and committing
 type LockBasedTxMgr struct {
 	db           statedb.VersionedDB
 	validator    validator.Validator
@@ -58,17 +61,36 @@ func (txmgr *LockBasedTxMgr) GetBlockNumFromSavepoint() (uint64, error) {
 
 // NewQueryExecutor implements method in interface `txmgmt.TxMgr`
 func (txmgr *LockBasedTxMgr) NewQueryExecutor() (ledger.QueryExecutor, error) {
-	qe := newQueryExecutor(txmgr)
-	txmgr.commitRWLock.RLock()
-	return qe, nil
+	db, release, err := txmgr.newStateReader()
+	if err != nil {
+		return nil, err
+	}
+	return newQueryExecutor(db, release), nil
 }
 
 // NewTxSimulator implements method in interface `txmgmt.TxMgr`
 func (txmgr *LockBasedTxMgr) NewTxSimulator() (ledger.TxSimulator, error) {
 	logger.Debugf("constructing new tx simulator")
-	s := newLockBasedTxSimulator(txmgr)
+	db, release, err := txmgr.newStateReader()
+	if err != nil {
+		return nil, err
+	}
+	return newLockBasedTxSimulator(db, release), nil
+}
+
+// newStateReader returns the state read by a query executor or a simulator and the function releasing it
+// when the executor is done. The state is a snapshot of the db if the db supports snapshots, otherwise it
+// is the db itself read under the commit lock
+func (txmgr *LockBasedTxMgr) newStateReader() (stateReader, func(), error) {
+	if sp, ok := txmgr.db.(statedb.SnapshotProvider); ok {
+		snapshot, err := sp.GetSnapshot()
+		if err != nil {
+			return nil, nil, err
+		}
+		return snapshot, snapshot.Release, nil
+	}
 	txmgr.commitRWLock.RLock()
-	return s, nil
+	return txmgr.db, txmgr.commitRWLock.RUnlock, nil
 }
 
 // ValidateAndPrepare implements method in interface `txmgmt.TxMgr`
@@ -106,6 +128,35 @@ func (txmgr *LockBasedTxMgr) Commit() error {
 	return nil
 }
 
+// Purge implements method in interface `txmgmt.TxMgr`. It replaces the values of the existing keys by
+// tombstones which keep their versions, so that the transactions which read the keys before the purge
+// are still validated. It returns the height of the state the keys were purged at, the purged values
+// were written by the blocks below it
+func (txmgr *LockBasedTxMgr) Purge(namespace string, keys []string) (uint64, error) {
+	txmgr.commitRWLock.Lock()
+	defer txmgr.commitRWLock.Unlock()
+	savepoint, err := txmgr.db.GetLatestSavePoint()
+	if err != nil || savepoint == nil {
+		return 0, err
+	}
+	versionedValues, err := txmgr.db.GetStateMultipleKeys(namespace, keys)
+	if err != nil {
+		return 0, err
+	}
+	batch := statedb.NewUpdateBatch()
+	for i, versionedValue := range versionedValues {
+		if versionedValue == nil || statedb.IsTombstone(versionedValue.Value) {
+			continue
+		}
+		logger.Debugf("Purging key [%s] of namespace [%s] at version [%#v]", keys[i], namespace, versionedValue.Version)
+		batch.PutValAndMetadata(namespace, keys[i], statedb.NewTombstone(versionedValue.Value), versionedValue.Metadata, versionedValue.Version)
+	}
+	if err = txmgr.db.ApplyUpdates(batch, savepoint); err != nil {
+		return 0, err
+	}
+	return savepoint.BlockNum + 1, nil
+}
+
 // Rollback implements method in interface `txmgmt.TxMgr`
 func (txmgr *LockBasedTxMgr) Rollback() {
 	txmgr.batch = nil
@@ -28,6 +28,7 @@ type TxMgr interface {
 	ValidateAndPrepare(block *common.Block, doMVCCValidation bool) error
 	GetBlockNumFromSavepoint() (uint64, error)
 	Commit() error
+	Purge(namespace string, keys []string) (uint64, error)
 	Rollback()
 	Shutdown()
 }
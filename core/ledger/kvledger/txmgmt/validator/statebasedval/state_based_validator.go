@@ -17,11 +17,15 @@ limitations under the License.
 package statebasedval
 
 import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	putils "github.com/hyperledger/fabric/protos/utils"
 	logging "github.com/op/go-logging"
 )
@@ -39,12 +43,12 @@ func NewValidator(db statedb.VersionedDB) *Validator {
 	return &Validator{db}
 }
 
-//validate endorser transaction
-func (v *Validator) validateEndorserTX(envBytes []byte, doMVCCValidation bool, updates *statedb.UpdateBatch) (*rwset.TxReadWriteSet, error) {
+//validate endorser transaction, returning the MVCC conflict which invalidated it if any
+func (v *Validator) validateEndorserTX(envBytes []byte, doMVCCValidation bool, updates *statedb.UpdateBatch) (*rwset.TxReadWriteSet, *pb.MVCCConflict, error) {
 	// extract actions from the envelope message
 	respPayload, err := putils.GetActionFromEnvelope(envBytes)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	//preparation for extracting RWSet from transaction
@@ -53,7 +57,7 @@ func (v *Validator) validateEndorserTX(envBytes []byte, doMVCCValidation bool, u
 	// Get the Result from the Action
 	// and then Unmarshal it into a TxReadWriteSet using custom unmarshalling
 	if err = txRWSet.Unmarshal(respPayload.Results); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// trace the first 1000 characters of RWSet only, in case it is huge
@@ -67,15 +71,17 @@ func (v *Validator) validateEndorserTX(envBytes []byte, doMVCCValidation bool, u
 	}
 
 	//mvccvalidation, may invalidate transaction
+	var conflict *pb.MVCCConflict
 	if doMVCCValidation {
-		if valid, err := v.validateTx(txRWSet, updates); err != nil {
-			return nil, err
+		var valid bool
+		if valid, conflict, err = v.validateTx(txRWSet, updates); err != nil {
+			return nil, nil, err
 		} else if !valid {
 			txRWSet = nil
 		}
 	}
 
-	return txRWSet, err
+	return txRWSet, conflict, err
 }
 
 // TODO validate configuration transaction
@@ -88,7 +94,14 @@ func (v *Validator) ValidateAndPrepareBatch(block *common.Block, doMVCCValidatio
 	logger.Debugf("New block arrived for validation:%#v, doMVCCValidation=%t", block, doMVCCValidation)
 	updates := statedb.NewUpdateBatch()
 	logger.Debugf("Validating a block with [%d] transactions", len(block.Data.Data))
-	txsFilter := util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	txsFilter, err := util.GetTxsFilter(block)
+	if err != nil {
+		return nil, err
+	}
+	// the committer chose the encoding of the filter for the channel
+	runLength := util.IsRunLengthTxsFilter(block)
+	txIDs := make([]string, len(block.Data.Data))
+	conflicts := &pb.MVCCConflicts{}
 	for txIndex, envBytes := range block.Data.Data {
 		if txsFilter.IsSet(uint(txIndex)) {
 			// Skiping invalid transaction
@@ -105,17 +118,28 @@ func (v *Validator) ValidateAndPrepareBatch(block *common.Block, doMVCCValidatio
 		if err != nil {
 			return nil, err
 		}
+		txIDs[txIndex] = payload.Header.ChannelHeader.TxId
 
 		valid := false
 		if common.HeaderType(payload.Header.ChannelHeader.Type) == common.HeaderType_ENDORSER_TRANSACTION {
-			txRWSet, err := v.validateEndorserTX(envBytes, doMVCCValidation, updates)
+			txRWSet, conflict, err := v.validateEndorserTX(envBytes, doMVCCValidation, updates)
 			if err != nil {
 				return nil, err
 			}
+			if conflict != nil {
+				conflict.TxIndex = uint64(txIndex)
+				conflict.TxId = txIDs[txIndex]
+				if conflict.ConflictingBlockNum == block.Header.Number && conflict.ConflictingTxNum > 0 {
+					conflict.ConflictingTxId = txIDs[conflict.ConflictingTxNum-1]
+				}
+				conflicts.Conflicts = append(conflicts.Conflicts, conflict)
+			}
 			//txRWSet != nil => t is valid
 			if txRWSet != nil {
 				committingTxHeight := version.NewHeight(block.Header.Number, uint64(txIndex+1))
-				addWriteSetToBatch(txRWSet, committingTxHeight, updates)
+				if err = v.addWriteSetToBatch(txRWSet, committingTxHeight, updates); err != nil {
+					return nil, err
+				}
 				valid = true
 			}
 		} else if common.HeaderType(payload.Header.ChannelHeader.Type) == common.HeaderType_CONFIG {
@@ -133,56 +157,112 @@ func (v *Validator) ValidateAndPrepareBatch(block *common.Block, doMVCCValidatio
 			txsFilter.Set(uint(txIndex))
 		}
 	}
-	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter.ToBytes()
+	util.SetTxsFilter(block, txsFilter, runLength)
+	if len(conflicts.Conflicts) > 0 {
+		if err := setMVCCConflicts(block, conflicts); err != nil {
+			return nil, err
+		}
+	}
 	return updates, nil
 }
 
-func addWriteSetToBatch(txRWSet *rwset.TxReadWriteSet, txHeight *version.Height, batch *statedb.UpdateBatch) {
+// setMVCCConflicts records in the metadata of the block the MVCC conflicts
+// which invalidated its transactions
+func setMVCCConflicts(block *common.Block, conflicts *pb.MVCCConflicts) error {
+	conflictsBytes, err := proto.Marshal(conflicts)
+	if err != nil {
+		return fmt.Errorf("Error marshaling MVCC conflicts of block %d: %s", block.Header.Number, err)
+	}
+	for len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_MVCC_CONFLICTS) {
+		block.Metadata.Metadata = append(block.Metadata.Metadata, []byte{})
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_MVCC_CONFLICTS] = conflictsBytes
+	return nil
+}
+
+// addWriteSetToBatch adds the writes of a valid transaction to the batch. The writes of values keep the
+// metadata of the keys, the deletes drop it and the metadata writes replace it on the latest value of the keys
+func (v *Validator) addWriteSetToBatch(txRWSet *rwset.TxReadWriteSet, txHeight *version.Height, batch *statedb.UpdateBatch) error {
 	for _, nsRWSet := range txRWSet.NsRWs {
 		ns := nsRWSet.NameSpace
 		for _, kvWrite := range nsRWSet.Writes {
 			if kvWrite.IsDelete {
 				batch.Delete(ns, kvWrite.Key, txHeight)
-			} else {
-				batch.Put(ns, kvWrite.Key, kvWrite.Value, txHeight)
+				continue
+			}
+			latest, err := v.getLatestValue(ns, kvWrite.Key, batch)
+			if err != nil {
+				return err
 			}
+			var metadata []byte
+			if latest != nil {
+				metadata = latest.Metadata
+			}
+			batch.PutValAndMetadata(ns, kvWrite.Key, kvWrite.Value, metadata, txHeight)
 		}
+		for _, metadataWrite := range nsRWSet.MetadataWrites {
+			latest, err := v.getLatestValue(ns, metadataWrite.Key, batch)
+			if err != nil {
+				return err
+			}
+			if latest == nil || latest.Value == nil {
+				logger.Debugf("Ignoring the metadata write of the key [%s:%s] which does not exist", ns, metadataWrite.Key)
+				continue
+			}
+			entries := make(map[string][]byte, len(metadataWrite.Entries))
+			for _, entry := range metadataWrite.Entries {
+				entries[entry.Name] = entry.Value
+			}
+			batch.PutValAndMetadata(ns, metadataWrite.Key, latest.Value, statedb.EncodeMetadata(entries), txHeight)
+		}
+	}
+	return nil
+}
+
+// getLatestValue returns the value of a key as updated by the batch or else as committed in the statedb
+func (v *Validator) getLatestValue(ns string, key string, batch *statedb.UpdateBatch) (*statedb.VersionedValue, error) {
+	if batch.Exists(ns, key) {
+		return batch.Get(ns, key), nil
 	}
+	return v.db.GetState(ns, key)
 }
 
-func (v *Validator) validateTx(txRWSet *rwset.TxReadWriteSet, updates *statedb.UpdateBatch) (bool, error) {
+// validateTx validates the read-write set of a transaction, returning the MVCC
+// conflict which invalidated it, if it was invalidated by one
+func (v *Validator) validateTx(txRWSet *rwset.TxReadWriteSet, updates *statedb.UpdateBatch) (bool, *pb.MVCCConflict, error) {
 	for _, nsRWSet := range txRWSet.NsRWs {
 		ns := nsRWSet.NameSpace
 		//TODO introduce different Error codes for different causes of validation failure
-		if valid, err := v.validateReadSet(ns, nsRWSet.Reads, updates); !valid || err != nil {
-			return valid, err
+		if conflict, err := v.validateReadSet(ns, nsRWSet.Reads, updates); conflict != nil || err != nil {
+			return false, conflict, err
 		}
-		if valid, err := v.validateRangeQueries(ns, nsRWSet.RangeQueriesInfo, updates); !valid || err != nil {
-			return valid, err
+		if conflict, err := v.validateRangeQueries(ns, nsRWSet.RangeQueriesInfo, updates); conflict != nil || err != nil {
+			return false, conflict, err
 		}
 	}
-	return true, nil
+	return true, nil, nil
 }
 
-func (v *Validator) validateReadSet(ns string, kvReads []*rwset.KVRead, updates *statedb.UpdateBatch) (bool, error) {
+func (v *Validator) validateReadSet(ns string, kvReads []*rwset.KVRead, updates *statedb.UpdateBatch) (*pb.MVCCConflict, error) {
 	for _, kvRead := range kvReads {
-		if valid, err := v.validateKVRead(ns, kvRead, updates); !valid || err != nil {
-			return valid, err
+		if conflict, err := v.validateKVRead(ns, kvRead, updates); conflict != nil || err != nil {
+			return conflict, err
 		}
 	}
-	return true, nil
+	return nil, nil
 }
 
 // validateKVRead performs mvcc check for a key read during transaction simulation.
 // i.e., it checks whether a key/version combination is already updated in the statedb (by an already committed block)
-// or in the updates (by a preceding valid transaction in the current block)
-func (v *Validator) validateKVRead(ns string, kvRead *rwset.KVRead, updates *statedb.UpdateBatch) (bool, error) {
+// or in the updates (by a preceding valid transaction in the current block). The conflict returned for an updated
+// key holds the height of the update, which is unknown when the key has since been deleted from the statedb
+func (v *Validator) validateKVRead(ns string, kvRead *rwset.KVRead, updates *statedb.UpdateBatch) (*pb.MVCCConflict, error) {
 	if updates.Exists(ns, kvRead.Key) {
-		return false, nil
+		return newMVCCConflict(ns, kvRead.Key, updates.Get(ns, kvRead.Key).Version), nil
 	}
 	versionedValue, err := v.db.GetState(ns, kvRead.Key)
 	if err != nil {
-		return false, nil
+		return newMVCCConflict(ns, kvRead.Key, nil), nil
 	}
 	var committedVersion *version.Height
 	if versionedValue != nil {
@@ -191,18 +271,31 @@ func (v *Validator) validateKVRead(ns string, kvRead *rwset.KVRead, updates *sta
 	if !version.AreSame(committedVersion, kvRead.Version) {
 		logger.Debugf("Version mismatch for key [%s:%s]. Committed version = [%s], Version in readSet [%s]",
 			ns, kvRead.Key, committedVersion, kvRead.Version)
-		return false, nil
+		return newMVCCConflict(ns, kvRead.Key, committedVersion), nil
 	}
-	return true, nil
+	return nil, nil
 }
 
-func (v *Validator) validateRangeQueries(ns string, rangeQueriesInfo []*rwset.RangeQueryInfo, updates *statedb.UpdateBatch) (bool, error) {
+func newMVCCConflict(ns string, key string, committingHeight *version.Height) *pb.MVCCConflict {
+	conflict := &pb.MVCCConflict{Namespace: ns, Key: key}
+	if committingHeight != nil {
+		conflict.ConflictingBlockNum = committingHeight.BlockNum
+		conflict.ConflictingTxNum = committingHeight.TxNum
+	}
+	return conflict
+}
+
+// validateRangeQueries returns a conflict for the first range query whose results changed, keyed by its
+// start key. The transaction which changed them is not tracked
+func (v *Validator) validateRangeQueries(ns string, rangeQueriesInfo []*rwset.RangeQueryInfo, updates *statedb.UpdateBatch) (*pb.MVCCConflict, error) {
 	for _, rqi := range rangeQueriesInfo {
-		if valid, err := v.validateRangeQuery(ns, rqi, updates); !valid || err != nil {
-			return valid, err
+		if valid, err := v.validateRangeQuery(ns, rqi, updates); err != nil {
+			return nil, err
+		} else if !valid {
+			return &pb.MVCCConflict{Namespace: ns, Key: rqi.StartKey, RangeQuery: true}, nil
 		}
 	}
-	return true, nil
+	return nil, nil
 }
 
 // validateRangeQuery performs a phatom read check i.e., it
@@ -26,7 +26,8 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/core/ledger/util"
-	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
 )
 
@@ -199,6 +200,103 @@ func TestPhantomHashBasedValidation(t *testing.T) {
 	checkValidation(t, validator, []*rwset.RWSet{rwset2}, []int{1})
 }
 
+func TestMetadataWrites(t *testing.T) {
+	testDBEnv := stateleveldb.NewTestVDBEnv(t)
+	defer testDBEnv.Cleanup()
+
+	db, err := testDBEnv.DBProvider.GetDBHandle("TestDB")
+	testutil.AssertNoError(t, err, "")
+
+	metadata := statedb.EncodeMetadata(map[string][]byte{"owner": []byte("org1")})
+	batch := statedb.NewUpdateBatch()
+	batch.PutValAndMetadata("ns1", "key1", []byte("value1"), metadata, version.NewHeight(1, 1))
+	batch.PutValAndMetadata("ns1", "key2", []byte("value2"), metadata, version.NewHeight(1, 2))
+	db.ApplyUpdates(batch, version.NewHeight(1, 2))
+	validator := NewValidator(db)
+
+	//the metadata is kept by value writes, dropped by deletes and replaced by metadata writes
+	rwset1 := rwset.NewRWSet()
+	rwset1.AddToWriteSet("ns1", "key1", []byte("value1_new"))
+	rwset1.AddToWriteSet("ns1", "key2", nil)
+	rwset2 := rwset.NewRWSet()
+	rwset2.AddToWriteSet("ns1", "key3", []byte("value3"))
+	rwset2.AddToMetadataWriteSet("ns1", "key3", map[string][]byte{"owner": []byte("org2")})
+	rwset2.AddToMetadataWriteSet("ns1", "key4", map[string][]byte{"owner": []byte("org2")})
+	var simulationResults [][]byte
+	for _, rws := range []*rwset.RWSet{rwset1, rwset2} {
+		sr, err := rws.GetTxReadWriteSet().Marshal()
+		testutil.AssertNoError(t, err, "")
+		simulationResults = append(simulationResults, sr)
+	}
+	updates, err := validator.ValidateAndPrepareBatch(testutil.ConstructBlock(t, simulationResults, false), true)
+	testutil.AssertNoError(t, err, "")
+
+	testutil.AssertEquals(t, updates.Get("ns1", "key1"), &statedb.VersionedValue{Value: []byte("value1_new"), Metadata: metadata, Version: version.NewHeight(1, 1)})
+	testutil.AssertEquals(t, updates.Get("ns1", "key2"), &statedb.VersionedValue{Version: version.NewHeight(1, 1)})
+	testutil.AssertEquals(t, updates.Get("ns1", "key3"), &statedb.VersionedValue{Value: []byte("value3"),
+		Metadata: statedb.EncodeMetadata(map[string][]byte{"owner": []byte("org2")}), Version: version.NewHeight(1, 2)})
+	testutil.AssertNil(t, updates.Get("ns1", "key4"))
+}
+
+func TestMVCCConflicts(t *testing.T) {
+	testDBEnv := stateleveldb.NewTestVDBEnv(t)
+	defer testDBEnv.Cleanup()
+
+	db, err := testDBEnv.DBProvider.GetDBHandle("TestDB")
+	testutil.AssertNoError(t, err, "")
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns1", "key1", []byte("value1"), version.NewHeight(0, 1))
+	batch.Put("ns1", "key2", []byte("value2"), version.NewHeight(0, 2))
+	db.ApplyUpdates(batch, version.NewHeight(0, 2))
+	validator := NewValidator(db)
+
+	//rwset2 conflicts with rwset1, rwset3 with the committed key2 and rwset4 with the range query
+	rwset1 := rwset.NewRWSet()
+	rwset1.AddToReadSet("ns1", "key1", version.NewHeight(0, 1))
+	rwset1.AddToWriteSet("ns1", "key1", []byte("value1_new"))
+	rwset2 := rwset.NewRWSet()
+	rwset2.AddToReadSet("ns1", "key1", version.NewHeight(0, 1))
+	rwset3 := rwset.NewRWSet()
+	rwset3.AddToReadSet("ns1", "key2", version.NewHeight(0, 1))
+	rwset4 := rwset.NewRWSet()
+	rqi4 := &rwset.RangeQueryInfo{StartKey: "key1", EndKey: "key3", ItrExhausted: true}
+	rqi4.Results = []*rwset.KVRead{rwset.NewKVRead("key2", version.NewHeight(0, 2))}
+	rwset4.AddToRangeQuerySet("ns1", rqi4)
+
+	var simulationResults [][]byte
+	for _, rwset := range []*rwset.RWSet{rwset1, rwset2, rwset3, rwset4} {
+		sr, err := rwset.GetTxReadWriteSet().Marshal()
+		testutil.AssertNoError(t, err, "")
+		simulationResults = append(simulationResults, sr)
+	}
+	block := testutil.ConstructBlock(t, simulationResults, false)
+	_, err = validator.ValidateAndPrepareBatch(block, true)
+	testutil.AssertNoError(t, err, "")
+
+	conflicts, err := putils.GetMVCCConflictsFromBlock(block)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(conflicts.Conflicts), 3)
+	txID := func(txIndex int) string {
+		env, _ := putils.GetEnvelopeFromBlock(block.Data.Data[txIndex])
+		payload, _ := putils.GetPayload(env)
+		return payload.Header.ChannelHeader.TxId
+	}
+	testutil.AssertEquals(t, conflicts.Conflicts[0], &pb.MVCCConflict{TxIndex: 1, TxId: txID(1), Namespace: "ns1", Key: "key1",
+		ConflictingBlockNum: block.Header.Number, ConflictingTxNum: 1, ConflictingTxId: txID(0)})
+	testutil.AssertEquals(t, conflicts.Conflicts[1], &pb.MVCCConflict{TxIndex: 2, TxId: txID(2), Namespace: "ns1", Key: "key2",
+		ConflictingBlockNum: 0, ConflictingTxNum: 2})
+	testutil.AssertEquals(t, conflicts.Conflicts[2], &pb.MVCCConflict{TxIndex: 3, TxId: txID(3), Namespace: "ns1", Key: "key1",
+		RangeQuery: true})
+
+	//no conflicts are recorded for a block without any
+	block = testutil.ConstructBlock(t, simulationResults[:1], false)
+	_, err = validator.ValidateAndPrepareBatch(block, true)
+	testutil.AssertNoError(t, err, "")
+	conflicts, err = putils.GetMVCCConflictsFromBlock(block)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, conflicts)
+}
+
 func checkValidation(t *testing.T, validator *Validator, rwsets []*rwset.RWSet, invalidTxIndexes []int) {
 	simulationResults := [][]byte{}
 	for _, rwset := range rwsets {
@@ -208,7 +306,8 @@ func checkValidation(t *testing.T, validator *Validator, rwsets []*rwset.RWSet,
 	}
 	block := testutil.ConstructBlock(t, simulationResults, false)
 	_, err := validator.ValidateAndPrepareBatch(block, true)
-	txsFltr := util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	testutil.AssertNoError(t, err, "")
+	txsFltr, err := util.GetTxsFilter(block)
 	invalidTxNum := 0
 	for i := 0; i < len(block.Data.Data); i++ {
 		if txsFltr.IsSet(uint(i)) {
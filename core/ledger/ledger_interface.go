@@ -17,6 +17,8 @@ limitations under the License.
 package ledger
 
 import (
+	"fmt"
+
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/peer"
@@ -46,6 +48,9 @@ type PeerLedger interface {
 	GetBlockByHash(blockHash []byte) (*common.Block, error)
 	// GetBlockByTxID returns a block which contains a transaction
 	GetBlockByTxID(txID string) (*common.Block, error)
+	// GetConfigBlocks returns the sequence and block numbers of the config blocks of the
+	// ledger, in the order of their sequence numbers
+	GetConfigBlocks() ([]*common.ConfigBlockInfo, error)
 	// NewTxSimulator gives handle to a transaction simulator.
 	// A client can obtain more than one 'TxSimulator's for parallel execution.
 	// Any snapshoting/synchronization should be performed at the implementation level if required
@@ -60,6 +65,11 @@ type PeerLedger interface {
 	NewHistoryQueryExecutor() (HistoryQueryExecutor, error)
 	//Prune prunes the blocks/transactions that satisfy the given policy
 	Prune(policy commonledger.PrunePolicy) error
+	// PurgeState erases from the local storage of the peer the values the keys of a namespace have
+	// had so far. The current values are replaced in the state by tombstones which keep their versions,
+	// and the values are redacted from the history of the keys. The blocks are left untouched so that
+	// the hash chain stays intact. Reading a purged key returns a *PurgedKeyError until it is written again.
+	PurgeState(namespace string, keys []string) error
 }
 
 // ValidatedLedger represents the 'final ledger' after filtering out invalid transactions from PeerLedger.
@@ -78,6 +88,8 @@ type QueryExecutor interface {
 	GetState(namespace string, key string) ([]byte, error)
 	// GetStateMultipleKeys gets the values for multiple keys in a single call
 	GetStateMultipleKeys(namespace string, keys []string) ([][]byte, error)
+	// GetStateMetadata gets the metadata entries of the given namespace and key, nil if the key has none
+	GetStateMetadata(namespace string, key string) (map[string][]byte, error)
 	// GetStateRangeScanIterator returns an iterator that contains all the key-values between given key ranges.
 	// startKey is included in the results and endKey is excluded. An empty startKey refers to the first available key
 	// and an empty endKey refers to the last available key. For scanning all the keys, both the startKey and the endKey
@@ -96,6 +108,11 @@ type QueryExecutor interface {
 type HistoryQueryExecutor interface {
 	// GetHistoryForKey retrieves the history of values for a key.
 	GetHistoryForKey(namespace string, key string) (commonledger.ResultsIterator, error)
+	// GetStateAtHeight retrieves the value a key had once the blocks below height were committed.
+	// A nil value is returned if the key did not exist or was deleted at that height.
+	GetStateAtHeight(namespace string, key string, height uint64) ([]byte, error)
+	// GetStateMultipleKeysAtHeight retrieves the values multiple keys had at the same height.
+	GetStateMultipleKeysAtHeight(namespace string, keys []string, height uint64) ([][]byte, error)
 }
 
 // TxSimulator simulates a transaction on a consistent snapshot of the 'as recent state as possible'
@@ -108,6 +125,9 @@ type TxSimulator interface {
 	DeleteState(namespace string, key string) error
 	// SetMultipleKeys sets the values for multiple keys in a single call
 	SetStateMultipleKeys(namespace string, kvs map[string][]byte) error
+	// SetStateMetadata replaces the metadata entries of the given namespace and key, an empty map removes them.
+	// The value of the key is not changed and the metadata is dropped at commit if the key does not exist
+	SetStateMetadata(namespace string, key string, metadata map[string][]byte) error
 	// ExecuteUpdate for supporting rich data model (see comments on QueryExecutor above)
 	ExecuteUpdate(query string) error
 	// GetTxSimulationResults encapsulates the results of the transaction simulation.
@@ -127,10 +147,21 @@ type KV struct {
 	Value []byte
 }
 
-// KeyModification - QueryResult for History.
+// KeyModification - QueryResult for History. Redacted is set when the value has been purged.
 type KeyModification struct {
-	TxID  string
-	Value []byte
+	TxID     string
+	Value    []byte
+	Redacted bool
+}
+
+// PurgedKeyError is returned when reading a key whose value has been purged from the local storage
+type PurgedKeyError struct {
+	Namespace string
+	Key       string
+}
+
+func (e *PurgedKeyError) Error() string {
+	return fmt.Sprintf("The value of key %s in namespace %s has been purged", e.Key, e.Namespace)
 }
 
 // QueryRecord - Result structure for query records. Holds a namespace, key and record.
@@ -30,6 +30,12 @@ var historyDatabase = true
 
 var maxBlockFileSize = 0
 
+// The block storage backends, see GetBlockStorage
+const (
+	FileBlockStorage    = "file"
+	LevelDBBlockStorage = "goleveldb"
+)
+
 // CouchDBDef contains parameters
 type CouchDBDef struct {
 	URL      string
@@ -73,6 +79,44 @@ func GetBlockStorePath() string {
 	return filepath.Join(GetRootPath(), "blocks")
 }
 
+// GetBlockStorage returns the backend storing the blocks, read from
+// ledger.blockchain.storage: FileBlockStorage, the default, appending the
+// blocks to block files indexed in a goleveldb, or LevelDBBlockStorage keeping
+// both the blocks and their index in a goleveldb
+func GetBlockStorage() string {
+	if storage := viper.GetString("ledger.blockchain.storage"); storage != "" {
+		return storage
+	}
+	return FileBlockStorage
+}
+
+// GetBlockStoreLevelDBPath returns the filesystem path of the goleveldb of
+// the LevelDBBlockStorage backend
+func GetBlockStoreLevelDBPath() string {
+	return filepath.Join(GetRootPath(), "blocksLeveldb")
+}
+
+// GetBlockStorePaths returns the filesystem paths the block files of some
+// channels are stored under instead of the block store path, read from
+// ledger.blockchain.paths which maps channel IDs to paths
+func GetBlockStorePaths() map[string]string {
+	return viper.GetStringMapString("ledger.blockchain.paths")
+}
+
+// GetDeactivatedChainsPath returns the filesystem path that is used to record
+// the chains deactivated on the peer, whose ledgers are kept but not served
+func GetDeactivatedChainsPath() string {
+	return filepath.Join(GetRootPath(), "deactivatedChains")
+}
+
+// GetMaxOpenLedgers returns the maximum number of ledgers whose databases are
+// kept open, read from ledger.maxOpenLedgers. The ledgers are opened on their
+// first access, and the least recently used ones are closed while more are
+// open. A value <= 0 means no limit
+func GetMaxOpenLedgers() int {
+	return viper.GetInt("ledger.maxOpenLedgers")
+}
+
 // GetMaxBlockfileSize returns maximum size of the block file
 func GetMaxBlockfileSize() int {
 	return 64 * 1024 * 1024
@@ -93,6 +137,24 @@ func IsHistoryDBEnabled() bool {
 	return viper.GetBool("ledger.state.historyDatabase")
 }
 
+// IsCommitHashEnabled returns true if the commit hash of the state written by
+// the valid transactions is recorded in the metadata of the blocks committed
+func IsCommitHashEnabled() bool {
+	return viper.GetBool("ledger.state.commitHash")
+}
+
+// GetMaxKeyLength returns the maximum length in bytes of the keys written to
+// the world state, a value <= 0 meaning unlimited
+func GetMaxKeyLength() int {
+	return viper.GetInt("ledger.state.keys.maxLength")
+}
+
+// GetIllegalKeyChars returns the characters the keys written to the world
+// state may not contain
+func GetIllegalKeyChars() string {
+	return viper.GetString("ledger.state.keys.illegalChars")
+}
+
 // IsQueryReadsHashingEnabled enables or disables computing of hash
 // of range query results for phantom item validation
 func IsQueryReadsHashingEnabled() bool {
@@ -82,3 +82,13 @@ func setUpCoreYAMLConfig() {
 	//call a helper method to load the core.yaml
 	ledgertestutil.SetupCoreYAMLConfig("./../../../peer")
 }
+
+func TestGetBlockStorage(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	testutil.AssertEquals(t, GetBlockStorage(), FileBlockStorage)
+	viper.Set("ledger.blockchain.storage", "")
+	testutil.AssertEquals(t, GetBlockStorage(), FileBlockStorage)
+	viper.Set("ledger.blockchain.storage", LevelDBBlockStorage)
+	testutil.AssertEquals(t, GetBlockStorage(), LevelDBBlockStorage)
+}
@@ -85,6 +85,8 @@ func ResetConfigToDefaultValues() {
 	//reset to defaults
 	viper.Set("ledger.state.stateDatabase", "goleveldb")
 	viper.Set("ledger.state.historyDatabase", false)
+	viper.Set("ledger.blockchain.storage", "file")
+	viper.Set("ledger.state.commitHash", false)
 }
 
 // SetLogLevel sets up log level
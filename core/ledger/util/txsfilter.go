@@ -0,0 +1,146 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// The transactions filter of a block, its TRANSACTIONS_FILTER metadata, marks
+// the invalid transactions of the block. It is stored as a FilterBitArray, one
+// bit per transaction, unless the TxsFilterRunLength capability is enabled on
+// the channel, in which case it is run-length encoded when that is shorter.
+// The run-length encoding is a version byte followed by the uvarint lengths of
+// the alternating runs of valid and invalid transactions, starting with a run
+// of valid transactions, the trailing run of valid transactions being left
+// out. As it is only used when it is shorter than the bit array of the block,
+// the length of the filter tells the two apart, so GetTxsFilter reads both
+
+// txsFilterRunLengthV1 is the version of the run-length encoding
+const txsFilterRunLengthV1 byte = 1
+
+// EncodeTxsFilter encodes the filter of a block of txCount transactions
+func EncodeTxsFilter(filter FilterBitArray, txCount uint) []byte {
+	bitArrayLen := bitArrayLength(txCount)
+	encoded := []byte{txsFilterRunLengthV1}
+	buf := make([]byte, binary.MaxVarintLen64)
+	invalid := false
+	run := uint64(0)
+	for i := uint(0); i < txCount && uint(len(encoded)) < bitArrayLen; i++ {
+		if filter.IsSet(i) != invalid {
+			encoded = append(encoded, buf[:binary.PutUvarint(buf, run)]...)
+			invalid = !invalid
+			run = 0
+		}
+		run++
+	}
+	if invalid {
+		encoded = append(encoded, buf[:binary.PutUvarint(buf, run)]...)
+	}
+	if uint(len(encoded)) < bitArrayLen {
+		return encoded
+	}
+
+	bitArray := make([]byte, bitArrayLen)
+	copy(bitArray, filter)
+	return bitArray
+}
+
+// DecodeTxsFilter decodes the filter of a block of txCount transactions
+func DecodeTxsFilter(bytes []byte, txCount uint) (FilterBitArray, error) {
+	bitArrayLen := bitArrayLength(txCount)
+	if len(bytes) == 0 || uint(len(bytes)) >= bitArrayLen {
+		return NewFilterBitArrayFromBytes(bytes), nil
+	}
+	if bytes[0] != txsFilterRunLengthV1 {
+		return nil, fmt.Errorf("Unknown transactions filter encoding version %d", bytes[0])
+	}
+
+	filter := make(FilterBitArray, bitArrayLen)
+	invalid := false
+	begin := uint64(0)
+	for bytes = bytes[1:]; len(bytes) > 0; invalid = !invalid {
+		run, n := binary.Uvarint(bytes)
+		if n <= 0 {
+			return nil, fmt.Errorf("Error decoding transactions filter: malformed run length")
+		}
+		bytes = bytes[n:]
+		if run > uint64(txCount)-begin {
+			return nil, fmt.Errorf("Error decoding transactions filter: runs exceed the %d transactions of the block", txCount)
+		}
+		if invalid && run > 0 {
+			filter.SetRange(uint(begin), uint(begin+run-1))
+		}
+		begin += run
+	}
+	return filter, nil
+}
+
+// GetTxsFilter returns the transactions filter of block, an empty one if the
+// block has none
+func GetTxsFilter(block *common.Block) (FilterBitArray, error) {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		return FilterBitArray{}, nil
+	}
+	txCount := 0
+	if block.Data != nil {
+		txCount = len(block.Data.Data)
+	}
+	return DecodeTxsFilter(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER], uint(txCount))
+}
+
+// IsRunLengthTxsFilter returns whether the transactions filter of block is
+// run-length encoded
+func IsRunLengthTxsFilter(block *common.Block) bool {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		return false
+	}
+	txCount := 0
+	if block.Data != nil {
+		txCount = len(block.Data.Data)
+	}
+	filter := block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	return len(filter) > 0 && uint(len(filter)) < bitArrayLength(uint(txCount))
+}
+
+// SetTxsFilter sets filter as the transactions filter of block, run-length
+// encoded if runLength and that is shorter, and as a FilterBitArray otherwise
+func SetTxsFilter(block *common.Block, filter FilterBitArray, runLength bool) {
+	if block.Metadata == nil {
+		block.Metadata = &common.BlockMetadata{}
+	}
+	for len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		block.Metadata.Metadata = append(block.Metadata.Metadata, []byte{})
+	}
+	txCount := 0
+	if block.Data != nil {
+		txCount = len(block.Data.Data)
+	}
+	if !runLength {
+		block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = filter.ToBytes()
+		return
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = EncodeTxsFilter(filter, uint(txCount))
+}
+
+// bitArrayLength returns the length of the FilterBitArray of txCount transactions
+func bitArrayLength(txCount uint) uint {
+	return (txCount + byteSize - 1) / byteSize
+}
@@ -0,0 +1,116 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func assertSameTxsFilter(t *testing.T, expected FilterBitArray, actual FilterBitArray, txCount uint) {
+	for i := uint(0); i < txCount; i++ {
+		assert.Equal(t, expected.IsSet(i), actual.IsSet(i), "Transaction %d", i)
+	}
+}
+
+func TestTxsFilterRunLength(t *testing.T) {
+	txCount := uint(1000)
+	filter := NewFilterBitArray(txCount)
+	filter.Set(10)
+	filter.SetRange(500, 599)
+	filter.Set(999)
+
+	encoded := EncodeTxsFilter(filter, txCount)
+	assert.Equal(t, []byte{txsFilterRunLengthV1, 10, 1, 0xe9, 0x03, 100, 0x8f, 0x03, 1}, encoded)
+	decoded, err := DecodeTxsFilter(encoded, txCount)
+	assert.NoError(t, err)
+	assertSameTxsFilter(t, filter, decoded, txCount)
+
+	encoded = EncodeTxsFilter(NewFilterBitArray(txCount), txCount)
+	assert.Equal(t, []byte{txsFilterRunLengthV1}, encoded, "The trailing valid transactions should be left out")
+	decoded, err = DecodeTxsFilter(encoded, txCount)
+	assert.NoError(t, err)
+	assertSameTxsFilter(t, NewFilterBitArray(txCount), decoded, txCount)
+}
+
+func TestTxsFilterBitArray(t *testing.T) {
+	// Alternating transactions are shorter as a bit array
+	txCount := uint(100)
+	filter := NewFilterBitArray(txCount)
+	for i := uint(0); i < txCount; i += 2 {
+		filter.Set(i)
+	}
+	encoded := EncodeTxsFilter(filter, txCount)
+	assert.Equal(t, []byte(filter), encoded)
+	decoded, err := DecodeTxsFilter(encoded, txCount)
+	assert.NoError(t, err)
+	assertSameTxsFilter(t, filter, decoded, txCount)
+
+	// Small blocks are never run-length encoded
+	filter = NewFilterBitArray(3)
+	filter.Set(1)
+	assert.Equal(t, []byte{2}, EncodeTxsFilter(filter, 3))
+	assert.Equal(t, []byte{}, EncodeTxsFilter(FilterBitArray{}, 0))
+}
+
+func TestTxsFilterLegacy(t *testing.T) {
+	// The filters written before the run-length encoding are read as they are
+	decoded, err := DecodeTxsFilter([]byte{0xff, 0x01}, 16)
+	assert.NoError(t, err)
+	assertSameTxsFilter(t, FilterBitArray{0xff, 0x01}, decoded, 16)
+
+	decoded, err = DecodeTxsFilter(nil, 16)
+	assert.NoError(t, err)
+	assert.False(t, decoded.IsSet(0))
+}
+
+func TestTxsFilterMalformed(t *testing.T) {
+	_, err := DecodeTxsFilter([]byte{2, 10}, 100)
+	assert.Error(t, err, "An unknown version should be rejected")
+	_, err = DecodeTxsFilter([]byte{txsFilterRunLengthV1, 0x80}, 100)
+	assert.Error(t, err, "A truncated run length should be rejected")
+	_, err = DecodeTxsFilter([]byte{txsFilterRunLengthV1, 90, 20}, 100)
+	assert.Error(t, err, "Runs beyond the transactions of the block should be rejected")
+}
+
+func TestBlockTxsFilter(t *testing.T) {
+	block := &common.Block{Data: &common.BlockData{Data: make([][]byte, 200)}}
+	filter, err := GetTxsFilter(block)
+	assert.NoError(t, err)
+	assert.False(t, filter.IsSet(0), "A block without metadata should have no invalid transactions")
+
+	filter.Set(150)
+	SetTxsFilter(block, filter, true)
+	assert.Len(t, block.Metadata.Metadata, int(common.BlockMetadataIndex_TRANSACTIONS_FILTER)+1)
+	assert.Equal(t, []byte{txsFilterRunLengthV1, 0x96, 0x01, 1}, block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.True(t, IsRunLengthTxsFilter(block))
+	filter, err = GetTxsFilter(block)
+	assert.NoError(t, err)
+	assert.True(t, filter.IsSet(150))
+	assert.False(t, filter.IsSet(151))
+
+	// Without the TxsFilterRunLength capability, the filter is the bit array
+	SetTxsFilter(block, filter, false)
+	assert.Equal(t, filter.ToBytes(), block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.False(t, IsRunLengthTxsFilter(block))
+	filter, err = GetTxsFilter(block)
+	assert.NoError(t, err)
+	assert.True(t, filter.IsSet(150))
+	assert.False(t, filter.IsSet(151))
+}
@@ -0,0 +1,99 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package limits provides the per channel resource limits of the peer, which
+// keep the activity on one channel from starving the other channels hosted
+// by the same peer
+package limits
+
+import (
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Limits bounds the resources a single channel may use on the peer. A value
+// <= 0 means unlimited
+type Limits struct {
+	// Endorsements is the maximum number of proposals simulated concurrently
+	Endorsements int
+
+	// PendingBlocks is the maximum number of blocks received ahead of the
+	// ledger height and held in memory until they are validated and committed
+	PendingBlocks int
+
+	// Iterators is the maximum number of query iterators open at once
+	Iterators int
+}
+
+// ForChannel returns the limits of chainID. The defaults are read from
+// peer.limits and may be overridden per channel under
+// peer.limits.channels.<chainID>
+func ForChannel(chainID string) Limits {
+	get := func(key string) int {
+		if override := "peer.limits.channels." + chainID + "." + key; chainID != "" && viper.IsSet(override) {
+			return viper.GetInt(override)
+		}
+		return viper.GetInt("peer.limits." + key)
+	}
+	return Limits{
+		Endorsements:  get("endorsements"),
+		PendingBlocks: get("pendingBlocks"),
+		Iterators:     get("iterators"),
+	}
+}
+
+// Counter tracks the number of units of a resource in use on each channel
+type Counter struct {
+	sync.Mutex
+	inUse map[string]int
+}
+
+// NewCounter creates an empty Counter
+func NewCounter() *Counter {
+	return &Counter{inUse: make(map[string]int)}
+}
+
+// TryAcquire takes a unit of the resource on chainID unless limit units are
+// already in use. A limit <= 0 means unlimited. It returns false if the unit
+// could not be taken
+func (c *Counter) TryAcquire(chainID string, limit int) bool {
+	c.Lock()
+	defer c.Unlock()
+	if limit > 0 && c.inUse[chainID] >= limit {
+		return false
+	}
+	c.inUse[chainID]++
+	return true
+}
+
+// Release gives back a unit taken by TryAcquire
+func (c *Counter) Release(chainID string) {
+	c.Lock()
+	defer c.Unlock()
+	if c.inUse[chainID] <= 1 {
+		delete(c.inUse, chainID)
+		return
+	}
+	c.inUse[chainID]--
+}
+
+// InUse returns the number of units in use on chainID
+func (c *Counter) InUse(chainID string) int {
+	c.Lock()
+	defer c.Unlock()
+	return c.inUse[chainID]
+}
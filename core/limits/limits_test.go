@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limits
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestForChannel(t *testing.T) {
+	viper.Set("peer.limits.endorsements", 10)
+	viper.Set("peer.limits.iterators", 5)
+	viper.Set("peer.limits.channels.busychannel.endorsements", 2)
+	defer viper.Reset()
+
+	l := ForChannel("otherchannel")
+	if l.Endorsements != 10 || l.Iterators != 5 || l.PendingBlocks != 0 {
+		t.Fatalf("Expected default limits, got %+v", l)
+	}
+
+	l = ForChannel("busychannel")
+	if l.Endorsements != 2 || l.Iterators != 5 {
+		t.Fatalf("Expected channel override of endorsements only, got %+v", l)
+	}
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter()
+	if !c.TryAcquire("a", 2) || !c.TryAcquire("a", 2) {
+		t.Fatalf("Expected to acquire up to the limit")
+	}
+	if c.TryAcquire("a", 2) {
+		t.Fatalf("Expected acquiring beyond the limit to fail")
+	}
+	if !c.TryAcquire("b", 2) {
+		t.Fatalf("Expected the limit of one channel not to affect another")
+	}
+
+	c.Release("a")
+	if c.InUse("a") != 1 {
+		t.Fatalf("Expected 1 unit in use, got %d", c.InUse("a"))
+	}
+	if !c.TryAcquire("a", 2) {
+		t.Fatalf("Expected to acquire a released unit")
+	}
+
+	for i := 0; i < 100; i++ {
+		if !c.TryAcquire("c", 0) {
+			t.Fatalf("Expected no limit to apply")
+		}
+	}
+}
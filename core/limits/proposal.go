@@ -0,0 +1,179 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limits
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+)
+
+// Proposal bounds the size of the proposals the peer accepts, whatever their
+// channel. A value <= 0 means unlimited
+type Proposal struct {
+	// MaxBytes is the maximum size of a marshaled proposal
+	MaxBytes int
+
+	// MaxArgs is the maximum number of arguments of the chaincode input
+	MaxArgs int
+
+	// MaxArgBytes is the maximum size of a single argument of the chaincode input
+	MaxArgBytes int
+
+	// MaxDetachedPayloads is the maximum number of detached payloads of the
+	// chaincode input
+	MaxDetachedPayloads int
+
+	// MaxDetachedRefBytes is the maximum size of a single marshaled detached
+	// payload reference of the chaincode input
+	MaxDetachedRefBytes int
+
+	// MaxDetachedBytes is the maximum total size of the contents fetched for
+	// the detached payloads of a proposal
+	MaxDetachedBytes int
+}
+
+// ForProposals returns the proposal limits, read from peer.limits.proposal
+func ForProposals() Proposal {
+	return Proposal{
+		MaxBytes:    viper.GetInt("peer.limits.proposal.maxBytes"),
+		MaxArgs:     viper.GetInt("peer.limits.proposal.maxArgs"),
+		MaxArgBytes: viper.GetInt("peer.limits.proposal.maxArgBytes"),
+
+		MaxDetachedPayloads: viper.GetInt("peer.limits.proposal.maxDetachedPayloads"),
+		MaxDetachedRefBytes: viper.GetInt("peer.limits.proposal.maxDetachedRefBytes"),
+		MaxDetachedBytes:    viper.GetInt("peer.limits.proposal.maxDetachedBytes"),
+	}
+}
+
+// ExceededError is returned for a proposal which exceeds one of the limits
+type ExceededError struct {
+	// Limit is the name of the limit exceeded, as in the peer configuration
+	Limit string
+	Max   int
+	// Actual is the value of the proposal, which is only known up to the
+	// first value exceeding Max when counting
+	Actual int
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("Proposal exceeds the %s limit of %d: %d", e.Limit, e.Max, e.Actual)
+}
+
+var errMalformedInput = errors.New("Malformed chaincode input")
+
+// CheckSize checks the size of a marshaled proposal
+func (l Proposal) CheckSize(size int) error {
+	if l.MaxBytes > 0 && size > l.MaxBytes {
+		return &ExceededError{Limit: "maxBytes", Max: l.MaxBytes, Actual: size}
+	}
+	return nil
+}
+
+// CheckInput checks the arguments and the detached payload references of the
+// chaincode input of a marshaled ChaincodeProposalPayload. They are only
+// scanned in the wire format, they are not unmarshaled
+func (l Proposal) CheckInput(chaincodeProposalPayload []byte) error {
+	if l.MaxArgs <= 0 && l.MaxArgBytes <= 0 && l.MaxDetachedPayloads <= 0 && l.MaxDetachedRefBytes <= 0 {
+		return nil
+	}
+
+	args := 0
+	checkArg := func(arg []byte) error {
+		args++
+		if l.MaxArgs > 0 && args > l.MaxArgs {
+			return &ExceededError{Limit: "maxArgs", Max: l.MaxArgs, Actual: args}
+		}
+		if l.MaxArgBytes > 0 && len(arg) > l.MaxArgBytes {
+			return &ExceededError{Limit: "maxArgBytes", Max: l.MaxArgBytes, Actual: len(arg)}
+		}
+		return nil
+	}
+
+	refs := 0
+	checkRef := func(ref []byte) error {
+		refs++
+		if l.MaxDetachedPayloads > 0 && refs > l.MaxDetachedPayloads {
+			return &ExceededError{Limit: "maxDetachedPayloads", Max: l.MaxDetachedPayloads, Actual: refs}
+		}
+		if l.MaxDetachedRefBytes > 0 && len(ref) > l.MaxDetachedRefBytes {
+			return &ExceededError{Limit: "maxDetachedRefBytes", Max: l.MaxDetachedRefBytes, Actual: len(ref)}
+		}
+		return nil
+	}
+
+	// ChaincodeProposalPayload.input (1) holds a ChaincodeInvocationSpec, whose
+	// chaincode_spec (1) has the ChaincodeInput input (3) made of the args (1)
+	// and the detached payloads (2)
+	return forEachField(chaincodeProposalPayload, 1, func(invocationSpec []byte) error {
+		return forEachField(invocationSpec, 1, func(spec []byte) error {
+			return forEachField(spec, 3, func(input []byte) error {
+				if err := forEachField(input, 1, checkArg); err != nil {
+					return err
+				}
+				return forEachField(input, 2, checkRef)
+			})
+		})
+	})
+}
+
+// forEachField calls fn with the value of every occurrence of the length
+// delimited field of a marshaled message, the other fields are skipped
+func forEachField(buf []byte, field uint64, fn func([]byte) error) error {
+	for len(buf) > 0 {
+		key, n := proto.DecodeVarint(buf)
+		if n == 0 {
+			return errMalformedInput
+		}
+		buf = buf[n:]
+
+		switch key & 7 {
+		case proto.WireVarint:
+			if _, n = proto.DecodeVarint(buf); n == 0 {
+				return errMalformedInput
+			}
+			buf = buf[n:]
+		case proto.WireFixed64:
+			if len(buf) < 8 {
+				return errMalformedInput
+			}
+			buf = buf[8:]
+		case proto.WireFixed32:
+			if len(buf) < 4 {
+				return errMalformedInput
+			}
+			buf = buf[4:]
+		case proto.WireBytes:
+			length, n := proto.DecodeVarint(buf)
+			if n == 0 || length > uint64(len(buf)-n) {
+				return errMalformedInput
+			}
+			value := buf[n : n+int(length)]
+			buf = buf[n+int(length):]
+			if key>>3 == field {
+				if err := fn(value); err != nil {
+					return err
+				}
+			}
+		default:
+			return errMalformedInput
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limits
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeChaincodeProposalPayload(t *testing.T, args ...string) []byte {
+	return makeChaincodeProposalPayloadWithInput(t, makeInput(args...))
+}
+
+func makeInput(args ...string) *pb.ChaincodeInput {
+	input := &pb.ChaincodeInput{}
+	for _, arg := range args {
+		input.Args = append(input.Args, []byte(arg))
+	}
+	return input
+}
+
+func makeChaincodeProposalPayloadWithInput(t *testing.T, input *pb.ChaincodeInput) []byte {
+	cis, err := proto.Marshal(&pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_GOLANG,
+		ChaincodeId: &pb.ChaincodeID{Name: "mycc"},
+		Input:       input,
+	}})
+	assert.NoError(t, err)
+	payload, err := proto.Marshal(&pb.ChaincodeProposalPayload{Input: cis, Transient: []byte("transient")})
+	assert.NoError(t, err)
+	return payload
+}
+
+func TestCheckSize(t *testing.T) {
+	assert.NoError(t, Proposal{}.CheckSize(1<<30))
+	assert.NoError(t, Proposal{MaxBytes: 10}.CheckSize(10))
+
+	err := Proposal{MaxBytes: 10}.CheckSize(11)
+	assert.Equal(t, &ExceededError{Limit: "maxBytes", Max: 10, Actual: 11}, err)
+}
+
+func TestCheckInput(t *testing.T) {
+	payload := makeChaincodeProposalPayload(t, "invoke", "a", "b", "10")
+
+	assert.NoError(t, Proposal{}.CheckInput(payload))
+	assert.NoError(t, Proposal{MaxArgs: 4, MaxArgBytes: 6}.CheckInput(payload))
+
+	err := Proposal{MaxArgs: 3}.CheckInput(payload)
+	assert.Equal(t, &ExceededError{Limit: "maxArgs", Max: 3, Actual: 4}, err)
+
+	err = Proposal{MaxArgBytes: 5}.CheckInput(payload)
+	assert.Equal(t, &ExceededError{Limit: "maxArgBytes", Max: 5, Actual: 6}, err)
+
+	// the input is scanned, a truncated one is reported rather than accepted
+	err = Proposal{MaxArgs: 4}.CheckInput(payload[:len(payload)-12])
+	assert.Equal(t, errMalformedInput, err)
+}
+
+func TestCheckInputDetached(t *testing.T) {
+	input := makeInput("invoke")
+	for i := 0; i < 3; i++ {
+		input.Detached = append(input.Detached, &pb.DetachedPayload{Uri: "https://example.com/doc", Hash: make([]byte, 32)})
+	}
+	payload := makeChaincodeProposalPayloadWithInput(t, input)
+	refBytes := proto.Size(input.Detached[0])
+
+	assert.NoError(t, Proposal{MaxDetachedPayloads: 3, MaxDetachedRefBytes: refBytes}.CheckInput(payload))
+
+	err := Proposal{MaxDetachedPayloads: 2}.CheckInput(payload)
+	assert.Equal(t, &ExceededError{Limit: "maxDetachedPayloads", Max: 2, Actual: 3}, err)
+
+	err = Proposal{MaxDetachedRefBytes: refBytes - 1}.CheckInput(payload)
+	assert.Equal(t, &ExceededError{Limit: "maxDetachedRefBytes", Max: refBytes - 1, Actual: refBytes}, err)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limits
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// Rate is the rate of a token bucket, which holds up to Burst tokens and
+// gains PerSecond tokens every second. A PerSecond <= 0 means unlimited, and
+// a Burst <= 0 a burst of one second of tokens
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+func (r Rate) unlimited() bool {
+	return r.PerSecond <= 0
+}
+
+func (r Rate) capacity() float64 {
+	if r.Burst <= 0 {
+		if r.PerSecond < 1 {
+			return 1
+		}
+		return r.PerSecond
+	}
+	return float64(r.Burst)
+}
+
+// ProposalRates are the rates at which the peer accepts proposals from a
+// single client identity and from the identities of a single organization,
+// whatever their channel, so that an application cannot monopolize the
+// endorsement capacity the peer shares between organizations
+type ProposalRates struct {
+	Identity Rate
+
+	// Org is the default rate of an organization, overridden by Orgs for the
+	// organizations of its MSP IDs, in lower case as the configuration keys
+	Org  Rate
+	Orgs map[string]Rate
+}
+
+// ForProposalRates returns the proposal rates, read from
+// peer.limits.rate.identity and peer.limits.rate.org. The rate of an
+// organization is overridden under peer.limits.rate.orgs.<mspID>
+func ForProposalRates() ProposalRates {
+	get := func(key string) Rate {
+		return Rate{PerSecond: viper.GetFloat64(key + ".perSecond"), Burst: viper.GetInt(key + ".burst")}
+	}
+	rates := ProposalRates{
+		Identity: get("peer.limits.rate.identity"),
+		Org:      get("peer.limits.rate.org"),
+		Orgs:     make(map[string]Rate),
+	}
+	for mspID, override := range viper.GetStringMap("peer.limits.rate.orgs") {
+		rate := Rate{}
+		for key, value := range cast.ToStringMap(override) {
+			switch strings.ToLower(key) {
+			case "persecond":
+				rate.PerSecond = cast.ToFloat64(value)
+			case "burst":
+				rate.Burst = cast.ToInt(value)
+			}
+		}
+		rates.Orgs[strings.ToLower(mspID)] = rate
+	}
+	return rates
+}
+
+// Unlimited returns whether no proposal rate is limited
+func (r ProposalRates) Unlimited() bool {
+	for _, rate := range r.Orgs {
+		if !rate.unlimited() {
+			return false
+		}
+	}
+	return r.Identity.unlimited() && r.Org.unlimited()
+}
+
+// ForOrg returns the rate of the organization of mspID
+func (r ProposalRates) ForOrg(mspID string) Rate {
+	if rate, ok := r.Orgs[strings.ToLower(mspID)]; ok {
+		return rate
+	}
+	return r.Org
+}
+
+// RateExceededError is returned for a proposal beyond the rate of its
+// identity or organization
+type RateExceededError struct {
+	// Limit is the name of the rate exceeded, as in the peer configuration
+	Limit string
+	Rate  Rate
+}
+
+func (e *RateExceededError) Error() string {
+	return fmt.Sprintf("Proposal exceeds the %s rate of %g per second", e.Limit, e.Rate.PerSecond)
+}
+
+// maxIdleBuckets is the number of buckets above which the full buckets,
+// which are the same as no bucket, are dropped
+const maxIdleBuckets = 4096
+
+type bucket struct {
+	rate   Rate
+	tokens float64
+	last   time.Time
+}
+
+// full returns whether the bucket is refilled to its capacity by now
+func (bk *bucket) full(now time.Time) bool {
+	return bk.tokens+now.Sub(bk.last).Seconds()*bk.rate.PerSecond >= bk.rate.capacity()
+}
+
+// Take is a token to take from the bucket of Key, which fills at Rate
+type Take struct {
+	Key  string
+	Rate Rate
+}
+
+// Buckets are token buckets by key, a key without bucket having a full one
+type Buckets struct {
+	sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewBuckets creates Buckets, all full
+func NewBuckets() *Buckets {
+	return &Buckets{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// refill returns the bucket of take refilled by now, the buckets being locked
+func (b *Buckets) refill(take Take, now time.Time) *bucket {
+	bk, ok := b.buckets[take.Key]
+	if !ok {
+		bk = &bucket{rate: take.Rate, tokens: take.Rate.capacity(), last: now}
+		b.buckets[take.Key] = bk
+		return bk
+	}
+	bk.tokens += now.Sub(bk.last).Seconds() * bk.rate.PerSecond
+	bk.rate = take.Rate
+	if capacity := bk.rate.capacity(); bk.tokens > capacity {
+		bk.tokens = capacity
+	}
+	bk.last = now
+	return bk
+}
+
+// TakeAll takes the tokens of takes if all their buckets have one. Otherwise
+// it takes none and returns false with the index of the first take whose
+// bucket is empty. The takes of an unlimited rate always succeed
+func (b *Buckets) TakeAll(takes ...Take) (int, bool) {
+	b.Lock()
+	defer b.Unlock()
+	now := b.now()
+	taken := make([]*bucket, 0, len(takes))
+	for i, take := range takes {
+		if take.Rate.unlimited() {
+			continue
+		}
+		bk := b.refill(take, now)
+		if bk.tokens < 1 {
+			return i, false
+		}
+		taken = append(taken, bk)
+	}
+	for _, bk := range taken {
+		bk.tokens--
+	}
+
+	if len(b.buckets) > maxIdleBuckets {
+		for key, bk := range b.buckets {
+			if bk.full(now) {
+				delete(b.buckets, key)
+			}
+		}
+	}
+	return -1, true
+}
+
+// Len returns the number of buckets held
+func (b *Buckets) Len() int {
+	b.Lock()
+	defer b.Unlock()
+	return len(b.buckets)
+}
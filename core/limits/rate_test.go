@@ -0,0 +1,100 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limits
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestForProposalRates(t *testing.T) {
+	if !ForProposalRates().Unlimited() {
+		t.Fatalf("Expected no rate by default")
+	}
+
+	viper.Set("peer.limits.rate.identity.perSecond", 5)
+	viper.Set("peer.limits.rate.org.perSecond", 50)
+	viper.Set("peer.limits.rate.org.burst", 100)
+	viper.Set("peer.limits.rate.orgs", map[string]interface{}{"Org1MSP": map[string]interface{}{"perSecond": 200}})
+	defer viper.Reset()
+
+	r := ForProposalRates()
+	if r.Unlimited() || r.Identity != (Rate{PerSecond: 5}) {
+		t.Fatalf("Expected an identity rate, got %+v", r)
+	}
+	if rate := r.ForOrg("Org2MSP"); rate != (Rate{PerSecond: 50, Burst: 100}) {
+		t.Fatalf("Expected the default org rate, got %+v", rate)
+	}
+	if rate := r.ForOrg("Org1MSP"); rate != (Rate{PerSecond: 200}) {
+		t.Fatalf("Expected the org override, got %+v", rate)
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	b := NewBuckets()
+	now := time.Unix(0, 0)
+	b.now = func() time.Time { return now }
+	identity := Take{Key: "identity", Rate: Rate{PerSecond: 2, Burst: 3}}
+	org := Take{Key: "org", Rate: Rate{PerSecond: 1}}
+
+	for i := 0; i < 3; i++ {
+		if _, ok := b.TakeAll(identity); !ok {
+			t.Fatalf("Expected the burst to be allowed")
+		}
+	}
+	if i, ok := b.TakeAll(identity); ok || i != 0 {
+		t.Fatalf("Expected the bucket to be empty after the burst")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if _, ok := b.TakeAll(identity, org); !ok {
+		t.Fatalf("Expected a token to be gained back in half a second")
+	}
+	// the org bucket, with a burst of one second of tokens, is still empty
+	// and no token is taken from the identity bucket
+	now = now.Add(500 * time.Millisecond)
+	if i, ok := b.TakeAll(identity, org); ok || i != 1 {
+		t.Fatalf("Expected the org bucket to be empty, got %d %t", i, ok)
+	}
+	if _, ok := b.TakeAll(identity); !ok {
+		t.Fatalf("Expected the identity token to be left")
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, ok := b.TakeAll(Take{Key: "unlimited"}); !ok {
+			t.Fatalf("Expected no rate to apply")
+		}
+	}
+}
+
+func TestBucketsDropFull(t *testing.T) {
+	b := NewBuckets()
+	now := time.Unix(0, 0)
+	b.now = func() time.Time { return now }
+	rate := Rate{PerSecond: 1}
+	for i := 0; i < maxIdleBuckets; i++ {
+		b.TakeAll(Take{Key: fmt.Sprint(i), Rate: rate})
+	}
+	now = now.Add(time.Second)
+	b.TakeAll(Take{Key: "last", Rate: rate})
+	if b.Len() != 1 {
+		t.Fatalf("Expected the refilled buckets to be dropped, %d left", b.Len())
+	}
+}
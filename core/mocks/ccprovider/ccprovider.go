@@ -46,6 +46,11 @@ func (c *mockCcProviderImpl) GetContext(ledger ledger.PeerLedger) (context.Conte
 	return nil, nil
 }
 
+// GetContextForSimulator does nothing
+func (c *mockCcProviderImpl) GetContextForSimulator(txsim ledger.TxSimulator) context.Context {
+	return nil
+}
+
 // GetCCContext does nothing
 func (c *mockCcProviderImpl) GetCCContext(cid, name, version, txid string, syscc bool, prop *peer.Proposal) interface{} {
 	return &mockCcProviderContextImpl{}
@@ -56,6 +61,11 @@ func (c *mockCcProviderImpl) GetCCValidationInfoFromLCCC(ctxt context.Context, t
 	return "vscc", nil, nil
 }
 
+// GetChaincodeDataFromLCCC does nothing
+func (c *mockCcProviderImpl) GetChaincodeDataFromLCCC(ctxt context.Context, txid string, prop *peer.Proposal, chainID string, chaincodeID string) (*ccprovider.ChaincodeData, error) {
+	return &ccprovider.ChaincodeData{Name: chaincodeID, Vscc: "vscc"}, nil
+}
+
 // ExecuteChaincode does nothing
 func (c *mockCcProviderImpl) ExecuteChaincode(ctxt context.Context, cccid interface{}, args [][]byte) (*peer.Response, *peer.ChaincodeEvent, error) {
 	return nil, nil, nil
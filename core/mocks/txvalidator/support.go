@@ -17,6 +17,8 @@ limitations under the License.
 package support
 
 import (
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	mockchannel "github.com/hyperledger/fabric/common/mocks/configtx/handlers/channel"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/protos/common"
@@ -26,6 +28,9 @@ type Support struct {
 	LedgerVal     ledger.PeerLedger
 	MSPManagerVal msp.MSPManager
 	ApplyVal      error
+	// ChannelConfigVal is returned by ChannelConfig if set, a channel config
+	// without capabilities otherwise
+	ChannelConfigVal configtxapi.ChannelConfig
 }
 
 // Ledger returns LedgerVal
@@ -42,3 +47,11 @@ func (ms *Support) MSPManager() msp.MSPManager {
 func (ms *Support) Apply(configtx *common.Envelope) error {
 	return ms.ApplyVal
 }
+
+// ChannelConfig returns ChannelConfigVal
+func (ms *Support) ChannelConfig() configtxapi.ChannelConfig {
+	if ms.ChannelConfigVal == nil {
+		return &mockchannel.SharedConfig{}
+	}
+	return ms.ChannelConfigVal
+}
@@ -0,0 +1,176 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/gossip/service"
+)
+
+// deactivatedChains is a local map of chainID->ledger of the chains
+// deactivated since the peer started, their ledger being kept open to
+// reactivate them. A chain is recorded as deactivated by a file named after
+// it under the deactivated chains path, so that it stays deactivated when
+// the peer restarts, in which case its ledger is nil until it is reactivated
+var deactivatedChains = struct {
+	sync.Mutex
+	list map[string]ledger.PeerLedger
+}{list: make(map[string]ledger.PeerLedger)}
+
+// DeactivateChain stops serving the chain cid: the peer stops gossiping and
+// pulling its blocks, and neither endorses nor delivers on it, but its ledger
+// is kept so that the chain is served again without a resync once it is
+// reactivated with ReactivateChain
+func DeactivateChain(cid string) error {
+	deactivatedChains.Lock()
+	defer deactivatedChains.Unlock()
+	if isDeactivated(cid) {
+		return fmt.Errorf("Chain %s is already deactivated", cid)
+	}
+
+	if dc := getDeferredChain(cid); dc != nil {
+		dc.Lock()
+		if !dc.activated {
+			// A deferred chain isn't served yet, it only has to be kept from
+			// being activated on first use
+			defer dc.Unlock()
+			if err := writeDeactivatedMarker(cid); err != nil {
+				return err
+			}
+			dc.activated = true
+			deferredChains.Lock()
+			delete(deferredChains.list, cid)
+			deferredChains.Unlock()
+			deactivatedChains.list[cid] = dc.ledger
+			peerLogger.Infof("Deactivated deferred chain %s", cid)
+			return nil
+		}
+		dc.Unlock()
+	}
+
+	chains.RLock()
+	c, ok := chains.list[cid]
+	chains.RUnlock()
+	if !ok {
+		return fmt.Errorf("Chain %s doesn't exist on the peer", cid)
+	}
+	if err := writeDeactivatedMarker(cid); err != nil {
+		return err
+	}
+
+	service.GetGossipService().StopChannel(cid)
+	chains.Lock()
+	delete(chains.list, cid)
+	chains.Unlock()
+	deactivatedChains.list[cid] = c.cs.ledger
+	peerLogger.Infof("Deactivated chain %s, its ledger is kept", cid)
+	return nil
+}
+
+// ReactivateChain serves again the chain cid deactivated with DeactivateChain,
+// from the config block and the blocks its ledger holds. The blocks committed
+// on the chain meanwhile are then pulled
+func ReactivateChain(cid string) error {
+	deactivatedChains.Lock()
+	defer deactivatedChains.Unlock()
+	if !isDeactivated(cid) {
+		return fmt.Errorf("Chain %s is not deactivated", cid)
+	}
+
+	var err error
+	l := deactivatedChains.list[cid]
+	if l == nil {
+		if l, err = ledgermgmt.OpenLedger(cid); err != nil {
+			return fmt.Errorf("Failed to load ledger %s: %s", cid, err)
+		}
+		deactivatedChains.list[cid] = l
+	}
+	cb, err := getCurrConfigBlockFromLedger(l)
+	if err != nil {
+		return fmt.Errorf("Failed to find config block on ledger %s: %s", cid, err)
+	}
+	if err = createChain(cid, l, cb); err != nil {
+		return fmt.Errorf("Failed to load chain %s: %s", cid, err)
+	}
+	if err = os.Remove(deactivatedMarkerPath(cid)); err != nil && !os.IsNotExist(err) {
+		peerLogger.Warningf("Failed removing the deactivation record of chain %s, it is deactivated again at restart: %s", cid, err)
+	}
+	delete(deactivatedChains.list, cid)
+	peerLogger.Infof("Reactivated chain %s", cid)
+
+	InitChain(cid)
+	return nil
+}
+
+// IsDeactivated returns whether the chain cid has been deactivated
+func IsDeactivated(cid string) bool {
+	deactivatedChains.Lock()
+	defer deactivatedChains.Unlock()
+	return isDeactivated(cid)
+}
+
+func isDeactivated(cid string) bool {
+	if _, ok := deactivatedChains.list[cid]; ok {
+		return true
+	}
+	_, err := os.Stat(deactivatedMarkerPath(cid))
+	return err == nil
+}
+
+// GetDeactivatedChainIDs returns the IDs of the deactivated chains
+func GetDeactivatedChainIDs() []string {
+	deactivatedChains.Lock()
+	defer deactivatedChains.Unlock()
+	ids := make(map[string]bool)
+	for cid := range deactivatedChains.list {
+		ids[cid] = true
+	}
+	if files, err := ioutil.ReadDir(ledgerconfig.GetDeactivatedChainsPath()); err == nil {
+		for _, f := range files {
+			ids[f.Name()] = true
+		}
+	}
+	cids := make([]string, 0, len(ids))
+	for cid := range ids {
+		cids = append(cids, cid)
+	}
+	sort.Strings(cids)
+	return cids
+}
+
+func deactivatedMarkerPath(cid string) string {
+	return filepath.Join(ledgerconfig.GetDeactivatedChainsPath(), cid)
+}
+
+func writeDeactivatedMarker(cid string) error {
+	if err := os.MkdirAll(ledgerconfig.GetDeactivatedChainsPath(), 0755); err != nil {
+		return fmt.Errorf("Error creating the deactivated chains directory: %s", err)
+	}
+	if err := ioutil.WriteFile(deactivatedMarkerPath(cid), nil, 0644); err != nil {
+		return fmt.Errorf("Error recording the deactivation of chain %s: %s", cid, err)
+	}
+	return nil
+}
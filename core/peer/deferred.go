@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/spf13/viper"
+)
+
+// deferredChain is a joined chain which was not activated at startup. Its
+// ledger, gossip, deliver and validation are started on first use
+type deferredChain struct {
+	sync.Mutex
+	// ledger is opened without activating the chain to read its state
+	ledger    ledger.PeerLedger
+	activated bool
+}
+
+// deferredChains is a local map of chainID->deferredChain
+var deferredChains = struct {
+	sync.Mutex
+	list map[string]*deferredChain
+}{list: make(map[string]*deferredChain)}
+
+// getStartupChains returns the set of chains activated at startup, nil when
+// all the joined chains are
+func getStartupChains() map[string]bool {
+	cids := viper.GetStringSlice("peer.startupChannels")
+	if len(cids) == 0 {
+		return nil
+	}
+	startup := make(map[string]bool)
+	for _, cid := range cids {
+		startup[cid] = true
+	}
+	return startup
+}
+
+// deferChain records a joined chain which is activated on first use
+func deferChain(cid string) {
+	deferredChains.Lock()
+	defer deferredChains.Unlock()
+	deferredChains.list[cid] = &deferredChain{}
+}
+
+// GetDeferredChainIDs returns the IDs of the joined chains which have not
+// been activated yet
+func GetDeferredChainIDs() []string {
+	deferredChains.Lock()
+	defer deferredChains.Unlock()
+	cids := make([]string, 0, len(deferredChains.list))
+	for cid := range deferredChains.list {
+		cids = append(cids, cid)
+	}
+	sort.Strings(cids)
+	return cids
+}
+
+func getDeferredChain(cid string) *deferredChain {
+	deferredChains.Lock()
+	defer deferredChains.Unlock()
+	return deferredChains.list[cid]
+}
+
+// ActivateChain starts the chain cid if it was deferred at startup. It does
+// nothing for the chains already active
+func ActivateChain(cid string) error {
+	dc := getDeferredChain(cid)
+	if dc == nil {
+		return nil
+	}
+	dc.Lock()
+	defer dc.Unlock()
+	if dc.activated {
+		return nil
+	}
+	peerLogger.Infof("Activating deferred chain %s", cid)
+	var err error
+	if dc.ledger == nil {
+		if dc.ledger, err = ledgermgmt.OpenLedger(cid); err != nil {
+			return fmt.Errorf("Failed to load ledger %s: %s", cid, err)
+		}
+	}
+	cb, err := getCurrConfigBlockFromLedger(dc.ledger)
+	if err != nil {
+		return fmt.Errorf("Failed to find config block on ledger %s: %s", cid, err)
+	}
+	if err = createChain(cid, dc.ledger, cb); err != nil {
+		return fmt.Errorf("Failed to load chain %s: %s", cid, err)
+	}
+	dc.activated = true
+	deferredChains.Lock()
+	delete(deferredChains.list, cid)
+	deferredChains.Unlock()
+
+	InitChain(cid)
+	return nil
+}
+
+// getLedgerWithoutActivation returns the ledger of the chain cid, opening
+// the ledger of a deferred chain without activating the chain
+func getLedgerWithoutActivation(cid string) (ledger.PeerLedger, error) {
+	dc := getDeferredChain(cid)
+	if dc == nil {
+		return GetLedger(cid), nil
+	}
+	dc.Lock()
+	defer dc.Unlock()
+	if dc.ledger == nil {
+		l, err := ledgermgmt.OpenLedger(cid)
+		if err != nil {
+			return nil, err
+		}
+		dc.ledger = l
+	}
+	return dc.ledger, nil
+}
+
+// getChain returns the chain cid, activating it if it was deferred. It
+// returns nil if the chain has not been created or fails to activate
+func getChain(cid string) *chain {
+	chains.RLock()
+	c, ok := chains.list[cid]
+	chains.RUnlock()
+	if ok {
+		return c
+	}
+	if err := ActivateChain(cid); err != nil {
+		peerLogger.Errorf("Failed activating chain %s on demand: %s", cid, err)
+		return nil
+	}
+	chains.RLock()
+	defer chains.RUnlock()
+	return chains.list[cid]
+}
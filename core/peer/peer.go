@@ -20,19 +20,25 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"sort"
 	"sync"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/configtx"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	configtxapplication "github.com/hyperledger/fabric/common/configtx/handlers/application"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/committer"
 	"github.com/hyperledger/fabric/core/committer/txvalidator"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
 	"github.com/hyperledger/fabric/gossip/service"
 	"github.com/hyperledger/fabric/msp"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 	"github.com/spf13/viper"
@@ -41,6 +47,10 @@ import (
 
 var peerLogger = logging.MustGetLogger("peer")
 
+func init() {
+	ccprovider.RegisterNamespacePrefixSource(namespacePrefixSource{})
+}
+
 type chainSupport struct {
 	configtxapi.Manager
 	configtxapi.ApplicationConfig
@@ -64,11 +74,13 @@ var chains = struct {
 	list map[string]*chain
 }{list: make(map[string]*chain)}
 
-//MockInitialize resets chains for test env
+// MockInitialize resets chains for test env
 func MockInitialize() {
 	ledgermgmt.InitializeTestEnv()
 	chains.list = nil
 	chains.list = make(map[string]*chain)
+	deferredChains.list = make(map[string]*deferredChain)
+	deactivatedChains.list = make(map[string]ledger.PeerLedger)
 	chainInitializer = func(string) { return }
 }
 
@@ -76,7 +88,9 @@ var chainInitializer func(string)
 
 // Initialize sets up any chains that the peer has from the persistence. This
 // function should be called at the start up when the ledger and gossip
-// ready
+// ready. When peer.startupChannels is set, the other chains are deferred
+// and activated on first use. The deactivated chains are left until they
+// are reactivated
 func Initialize(init func(string)) {
 	chainInitializer = init
 
@@ -87,7 +101,17 @@ func Initialize(init func(string)) {
 	if err != nil {
 		panic(fmt.Errorf("Error in initializing ledgermgmt: %s", err))
 	}
+	startup := getStartupChains()
 	for _, cid := range ledgerIds {
+		if IsDeactivated(cid) {
+			peerLogger.Infof("Chain %s is deactivated, it is not loaded", cid)
+			continue
+		}
+		if startup != nil && !startup[cid] {
+			peerLogger.Infof("Deferring chain %s until it is used", cid)
+			deferChain(cid)
+			continue
+		}
 		peerLogger.Infof("Loading chain %s", cid)
 		if ledger, err = ledgermgmt.OpenLedger(cid); err != nil {
 			peerLogger.Warningf("Failed to load ledger %s(%s)", cid, err)
@@ -170,10 +194,17 @@ func createChain(cid string, ledger ledger.PeerLedger, cb *common.Block) error {
 		})
 	}
 
+	configDigestCallback := func(cm configtxapi.Manager) {
+		service.GetGossipService().UpdateConfigDigest(cm.ChainID(), cm.ConfigDigest())
+	}
+
 	configtxManager, err := configtx.NewManagerImpl(
 		configEnvelope,
 		configtxInitializer,
-		[]func(cm configtxapi.Manager){gossipCallbackWrapper},
+		[]configtxapi.ConfigListener{
+			configtxapi.PostApplyFunc(gossipCallbackWrapper),
+			configtxapi.PostApplyFunc(configDigestCallback),
+		},
 	)
 	if err != nil {
 		return err
@@ -201,12 +232,23 @@ func createChain(cid string, ledger ledger.PeerLedger, cb *common.Block) error {
 	return nil
 }
 
-// CreateChainFromBlock creates a new chain from config block
+// CreateChainFromBlock creates a new chain from its genesis block. A later
+// config block is refused: the world state of a ledger starting from it would
+// lack the writes of the blocks preceding it, and its validation results
+// would fork from those of the other peers
 func CreateChainFromBlock(cb *common.Block) error {
 	cid, err := utils.GetChainIDFromBlock(cb)
 	if err != nil {
 		return err
 	}
+	if IsDeactivated(cid) {
+		return fmt.Errorf("Chain %s is deactivated on the peer, it must be reactivated rather than joined", cid)
+	}
+
+	if cb.Header.Number != 0 {
+		return fmt.Errorf("Block %d is not the genesis block of chain %s, a chain can only be joined from its genesis block", cb.Header.Number, cid)
+	}
+
 	var ledger ledger.PeerLedger
 	if ledger, err = createLedger(cid); err != nil {
 		return err
@@ -238,9 +280,7 @@ func MockCreateChain(cid string) error {
 // GetLedger returns the ledger of the chain with chain ID. Note that this
 // call returns nil if chain cid has not been created.
 func GetLedger(cid string) ledger.PeerLedger {
-	chains.RLock()
-	defer chains.RUnlock()
-	if c, ok := chains.list[cid]; ok {
+	if c := getChain(cid); c != nil {
 		return c.cs.ledger
 	}
 	return nil
@@ -249,20 +289,74 @@ func GetLedger(cid string) ledger.PeerLedger {
 // GetMSPMgr returns the MSP manager of the chain with chain ID.
 // Note that this call returns nil if chain cid has not been created.
 func GetMSPMgr(cid string) msp.MSPManager {
-	chains.RLock()
-	defer chains.RUnlock()
-	if c, ok := chains.list[cid]; ok {
+	if c := getChain(cid); c != nil {
 		return c.cs.MSPManager()
 	}
 	return nil
 }
 
+// GetPolicyManager returns the policy manager of the chain with chain ID.
+// Note that this call returns nil if chain cid has not been created.
+func GetPolicyManager(cid string) policies.Manager {
+	if c := getChain(cid); c != nil && c.cs.Manager != nil {
+		return c.cs.PolicyManager()
+	}
+	return nil
+}
+
+// GetApplicationConfig returns the application config of the chain with chain
+// ID. Note that this call returns nil if chain cid has not been created.
+func GetApplicationConfig(cid string) configtxapi.ApplicationConfig {
+	if c := getChain(cid); c != nil {
+		return c.cs.ApplicationConfig
+	}
+	return nil
+}
+
+// namespacePrefixSource implements ccprovider.NamespacePrefixSource with the
+// application config of the chains
+type namespacePrefixSource struct{}
+
+func (namespacePrefixSource) NamespacePrefix(cid string) string {
+	if ac := GetApplicationConfig(cid); ac != nil {
+		return ac.ChaincodeNamespacePrefix()
+	}
+	return ""
+}
+
+// GetOrdererConfig returns the orderer config of the chain with chain ID.
+// Note that this call returns nil if chain cid has not been created.
+func GetOrdererConfig(cid string) configtxapi.OrdererConfig {
+	if c := getChain(cid); c != nil && c.cs.Manager != nil {
+		return c.cs.OrdererConfig()
+	}
+	return nil
+}
+
+// GetConfigDigest returns the digest of the current config of the chain with
+// chain ID, which is the same on the peers and orderers with the same config.
+// Note that this call returns nil if chain cid has not been created.
+func GetConfigDigest(cid string) []byte {
+	if c := getChain(cid); c != nil {
+		return c.cs.ConfigDigest()
+	}
+	return nil
+}
+
+// GetConfigSnapshot returns the current config of the chain with chain ID
+// along with its sequence number. Note that this call returns nil if chain
+// cid has not been created.
+func GetConfigSnapshot(cid string) *common.ConfigSnapshot {
+	if c := getChain(cid); c != nil && c.cs.Manager != nil {
+		return &common.ConfigSnapshot{Config: c.cs.ConfigEnvelope().Config, Sequence: c.cs.Sequence()}
+	}
+	return nil
+}
+
 // GetCommitter returns the committer of the chain with chain ID. Note that this
 // call returns nil if chain cid has not been created.
 func GetCommitter(cid string) committer.Committer {
-	chains.RLock()
-	defer chains.RUnlock()
-	if c, ok := chains.list[cid]; ok {
+	if c := getChain(cid); c != nil {
 		return c.committer
 	}
 	return nil
@@ -271,14 +365,124 @@ func GetCommitter(cid string) committer.Committer {
 // GetCurrConfigBlock returns the cached config block of the specified chain.
 // Note that this call returns nil if chain cid has not been created.
 func GetCurrConfigBlock(cid string) *common.Block {
-	chains.RLock()
-	defer chains.RUnlock()
-	if c, ok := chains.list[cid]; ok {
+	if c := getChain(cid); c != nil {
 		return c.cb
 	}
 	return nil
 }
 
+// GetChainIDs returns the IDs of the active chains, see GetDeferredChainIDs
+// for the joined chains not activated yet
+func GetChainIDs() []string {
+	chains.RLock()
+	defer chains.RUnlock()
+	cids := make([]string, 0, len(chains.list))
+	for cid := range chains.list {
+		cids = append(cids, cid)
+	}
+	sort.Strings(cids)
+	return cids
+}
+
+// DefinedChaincode is a chaincode version instantiated on a chain, with the
+// chaincode namespace prefix of the chain
+type DefinedChaincode struct {
+	*ccprovider.ChaincodeData
+	NamespacePrefix string
+}
+
+// GetDefinedChaincodes returns the chaincode versions instantiated on any of
+// the chains the peer has joined, as recorded by the lifecycle chaincode.
+// The deferred chains are read without being activated
+func GetDefinedChaincodes() ([]*DefinedChaincode, error) {
+	var defined []*DefinedChaincode
+	// the deferred chains are listed first so that a chain activated
+	// meanwhile is found among the active ones
+	cids := GetDeferredChainIDs()
+	for _, cid := range append(cids, GetChainIDs()...) {
+		l, err := getLedgerWithoutActivation(cid)
+		if err != nil {
+			return nil, fmt.Errorf("Failed opening the ledger of chain %s: %s", cid, err)
+		}
+		if l == nil {
+			continue
+		}
+		cds, err := getChaincodesFromLedger(l)
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing the chaincodes of chain %s: %s", cid, err)
+		}
+		if len(cds) == 0 {
+			continue
+		}
+		prefix, err := getNamespacePrefixFromLedger(l)
+		if err != nil {
+			return nil, fmt.Errorf("Failed reading the chaincode namespace prefix of chain %s: %s", cid, err)
+		}
+		for _, cd := range cds {
+			defined = append(defined, &DefinedChaincode{ChaincodeData: cd, NamespacePrefix: prefix})
+		}
+	}
+	return defined, nil
+}
+
+// getNamespacePrefixFromLedger returns the chaincode namespace prefix of the
+// chain of the ledger. As it cannot change, it is read from the genesis block
+func getNamespacePrefixFromLedger(l ledger.PeerLedger) (string, error) {
+	block, err := l.GetBlockByNumber(0)
+	if err != nil {
+		return "", err
+	}
+	configEnvelope, err := configtx.ConfigEnvelopeFromBlock(block)
+	if err != nil {
+		return "", err
+	}
+	if configEnvelope.Config == nil || configEnvelope.Config.Channel == nil {
+		return "", fmt.Errorf("Genesis block has no channel config")
+	}
+	application, ok := configEnvelope.Config.Channel.Groups[configtxapplication.GroupKey]
+	if !ok {
+		return "", nil
+	}
+	value, ok := application.Values[configtxapplication.ChaincodeNamespacePrefixKey]
+	if !ok {
+		return "", nil
+	}
+	namespacePrefix := &pb.ChaincodeNamespacePrefix{}
+	if err := proto.Unmarshal(value.Value, namespacePrefix); err != nil {
+		return "", err
+	}
+	return namespacePrefix.Prefix, nil
+}
+
+func getChaincodesFromLedger(l ledger.PeerLedger) ([]*ccprovider.ChaincodeData, error) {
+	qe, err := l.NewQueryExecutor()
+	if err != nil {
+		return nil, err
+	}
+	defer qe.Done()
+	itr, err := qe.GetStateRangeScanIterator("lccc", "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Close()
+	var cds []*ccprovider.ChaincodeData
+	for {
+		res, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			return cds, nil
+		}
+		cd := &ccprovider.ChaincodeData{}
+		if err := proto.Unmarshal(res.(*ledger.KV).Value, cd); err != nil {
+			peerLogger.Warningf("Skipping lifecycle entry %s which is not a chaincode definition: %s", res.(*ledger.KV).Key, err)
+			continue
+		}
+		cds = append(cds, cd)
+	}
+}
+
 // SetCurrConfigBlock sets the current config block of the specified chain
 func SetCurrConfigBlock(block *common.Block, cid string) error {
 	chains.Lock()
@@ -303,6 +507,13 @@ func createLedger(cid string) (ledger.PeerLedger, error) {
 	return ledgermgmt.CreateLedger(cid)
 }
 
+// IsReadOnly returns whether the peer runs as a read-only replica, which joins
+// channels, commits blocks and serves queries and events, but does not
+// endorse proposals
+func IsReadOnly() bool {
+	return viper.GetBool("peer.readOnly")
+}
+
 // NewPeerClientConnection Returns a new grpc.ClientConn to the configured local PEER.
 func NewPeerClientConnection() (*grpc.ClientConn, error) {
 	return NewPeerClientConnectionWithAddress(viper.GetString("peer.address"))
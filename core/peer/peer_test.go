@@ -26,6 +26,8 @@ import (
 	ccp "github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/deliverservice"
 	"github.com/hyperledger/fabric/core/deliverservice/blocksprovider"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
 	"github.com/hyperledger/fabric/core/mocks/ccprovider"
 	"github.com/hyperledger/fabric/gossip/service"
 	"github.com/hyperledger/fabric/msp/mgmt"
@@ -45,6 +47,12 @@ func (*mockDeliveryClient) JoinChain(chainID string, ledgerInfo blocksprovider.L
 	return nil
 }
 
+// LeaveChain stops pulling the blocks of the given chain from the
+// ordering service
+func (*mockDeliveryClient) LeaveChain(chainID string) error {
+	return nil
+}
+
 // Stop terminates delivery service and closes the connection
 func (*mockDeliveryClient) Stop() {
 
@@ -133,3 +141,89 @@ func TestGetLocalIP(t *testing.T) {
 	ip := GetLocalIP()
 	t.Log(ip)
 }
+
+func TestDeferredChains(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/var/hyperledger/test/")
+	MockInitialize()
+	defer MockInitialize()
+
+	viper.Set("peer.startupChannels", []string{"active"})
+	defer viper.Set("peer.startupChannels", nil)
+	assert.Equal(t, map[string]bool{"active": true}, getStartupChains())
+
+	assert.NoError(t, MockCreateChain("active"))
+	l, err := ledgermgmt.CreateLedger("deferred")
+	assert.NoError(t, err)
+	l.Close()
+	deferChain("deferred")
+	assert.Equal(t, []string{"active"}, GetChainIDs())
+	assert.Equal(t, []string{"deferred"}, GetDeferredChainIDs())
+
+	// reading the chaincodes of a deferred chain does not activate it
+	cds, err := GetDefinedChaincodes()
+	assert.NoError(t, err)
+	assert.Empty(t, cds)
+	assert.Equal(t, []string{"deferred"}, GetDeferredChainIDs())
+
+	// the ledger has no config block, so the activation on demand fails and
+	// the chain stays deferred
+	assert.Nil(t, GetLedger("deferred"))
+	assert.Error(t, ActivateChain("deferred"))
+	assert.Equal(t, []string{"deferred"}, GetDeferredChainIDs())
+
+	assert.NoError(t, ActivateChain("active"), "Activating an active chain should do nothing")
+	assert.Nil(t, GetLedger("unknown"))
+}
+
+func TestDeactivateChain(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/var/hyperledger/test/")
+	MockInitialize()
+	defer MockInitialize()
+	testChainID := "deactivatedchain"
+	block, err := configtxtest.MakeGenesisBlock(testChainID)
+	assert.NoError(t, err)
+
+	// Initialize gossip service, unless it already is
+	grpcServer := grpc.NewServer()
+	socket, err := net.Listen("tcp", fmt.Sprintf("%s:%d", "", 13612))
+	assert.NoError(t, err)
+	go grpcServer.Serve(socket)
+	defer grpcServer.Stop()
+
+	msptesttools.LoadMSPSetupForTesting("../../msp/sampleconfig")
+
+	identity, _ := mgmt.GetLocalSigningIdentityOrPanic().Serialize()
+	service.InitGossipServiceCustomDeliveryFactory(identity, "localhost:13612", grpcServer, &mockDeliveryClientFactory{})
+
+	assert.NoError(t, CreateChainFromBlock(block))
+	assert.Error(t, ReactivateChain(testChainID), "Reactivating an active chain should fail")
+	assert.Error(t, DeactivateChain("BogusChain"))
+
+	assert.NoError(t, DeactivateChain(testChainID))
+	assert.True(t, IsDeactivated(testChainID))
+	assert.Nil(t, GetLedger(testChainID), "A deactivated chain should not be served")
+	assert.Nil(t, GetCommitter(testChainID))
+	assert.NotContains(t, GetChainIDs(), testChainID)
+	assert.Equal(t, []string{testChainID}, GetDeactivatedChainIDs())
+	assert.Error(t, DeactivateChain(testChainID), "Deactivating a deactivated chain should fail")
+	assert.Error(t, CreateChainFromBlock(block), "Joining a deactivated chain should fail")
+
+	assert.NoError(t, ReactivateChain(testChainID))
+	assert.False(t, IsDeactivated(testChainID))
+	assert.Empty(t, GetDeactivatedChainIDs())
+	l := GetLedger(testChainID)
+	assert.NotNil(t, l)
+	info, err := l.GetBlockchainInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), info.Height, "The ledger should have been kept")
+
+	// The deactivation is recorded for the chain to stay deactivated at restart,
+	// its ledger being opened then when it is reactivated
+	assert.NoError(t, DeactivateChain(testChainID))
+	l.Close()
+	deactivatedChains.list = make(map[string]ledger.PeerLedger)
+	assert.True(t, IsDeactivated(testChainID))
+	assert.Equal(t, []string{testChainID}, GetDeactivatedChainIDs())
+	assert.NoError(t, ReactivateChain(testChainID))
+	assert.NotNil(t, GetLedger(testChainID))
+}
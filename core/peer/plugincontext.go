@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/common/plugincontext"
+	"github.com/hyperledger/fabric/core/ledger"
+)
+
+func init() {
+	plugincontext.Register(&pluginContext{})
+}
+
+// pluginContext implements plugincontext.Context over the chains of the peer
+type pluginContext struct {
+}
+
+// ChannelConfig implements method in interface plugincontext.Context
+func (*pluginContext) ChannelConfig(chainID string) (plugincontext.ChannelConfig, error) {
+	chains.RLock()
+	defer chains.RUnlock()
+	c, ok := chains.list[chainID]
+	if !ok {
+		return nil, fmt.Errorf("Chain %s doesn't exist on the peer", chainID)
+	}
+	if c.cs.Manager == nil {
+		return nil, fmt.Errorf("Chain %s has no config", chainID)
+	}
+	return &channelConfig{c.cs}, nil
+}
+
+// StateFetcher implements method in interface plugincontext.Context
+func (*pluginContext) StateFetcher(chainID string) (plugincontext.StateFetcher, error) {
+	l := GetLedger(chainID)
+	if l == nil {
+		return nil, fmt.Errorf("Chain %s doesn't exist on the peer", chainID)
+	}
+	return &stateFetcher{l}, nil
+}
+
+// channelConfig implements plugincontext.ChannelConfig, reading the config
+// from the config manager of the chain which is updated in place
+type channelConfig struct {
+	cs *chainSupport
+}
+
+func (cc *channelConfig) ChainID() string {
+	return cc.cs.ChainID()
+}
+
+func (cc *channelConfig) Sequence() uint64 {
+	return cc.cs.Sequence()
+}
+
+func (cc *channelConfig) MSPIDs() ([]string, error) {
+	msps, err := cc.cs.MSPManager().GetMSPs()
+	if err != nil {
+		return nil, err
+	}
+	var mspIDs []string
+	for mspID := range msps {
+		mspIDs = append(mspIDs, mspID)
+	}
+	sort.Strings(mspIDs)
+	return mspIDs, nil
+}
+
+func (cc *channelConfig) GetPolicy(name string) (policies.Policy, bool) {
+	return cc.cs.PolicyManager().GetPolicy(name)
+}
+
+func (cc *channelConfig) ShimCapabilityDisabled(msgType string) bool {
+	return cc.cs.ApplicationConfig.ShimCapabilityDisabled(msgType)
+}
+
+// stateFetcher implements plugincontext.StateFetcher with query executors of
+// the ledger of the chain
+type stateFetcher struct {
+	ledger ledger.PeerLedger
+}
+
+func (sf *stateFetcher) FetchState() (plugincontext.State, error) {
+	return sf.ledger.NewQueryExecutor()
+}
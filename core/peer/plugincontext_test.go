@@ -0,0 +1,103 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"os"
+	"testing"
+
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	mockconfigtx "github.com/hyperledger/fabric/common/mocks/configtx"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/core/common/plugincontext"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockApplicationConfig disables the shim capabilities it lists
+type mockApplicationConfig struct {
+	configtxapi.ApplicationConfig
+	disabled map[string]bool
+}
+
+func (ac *mockApplicationConfig) ShimCapabilityDisabled(msgType string) bool {
+	return ac.disabled[msgType]
+}
+
+func TestPluginContext(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/var/hyperledger/plugincontexttest/")
+	defer os.RemoveAll("/var/hyperledger/plugincontexttest/")
+	MockInitialize()
+	assert.NoError(t, MockCreateChain("pluginchain"))
+
+	ctx := plugincontext.Get()
+	_, err := ctx.ChannelConfig("BogusChain")
+	assert.Error(t, err)
+	_, err = ctx.StateFetcher("BogusChain")
+	assert.Error(t, err)
+
+	// mock chains have a ledger but no config
+	_, err = ctx.ChannelConfig("pluginchain")
+	assert.Error(t, err)
+
+	fetcher, err := ctx.StateFetcher("pluginchain")
+	assert.NoError(t, err)
+	state, err := fetcher.FetchState()
+	assert.NoError(t, err)
+	value, err := state.GetState("mycc", "key1")
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+	state.Done()
+}
+
+func TestPluginChannelConfig(t *testing.T) {
+	writers := &mockpolicies.Policy{}
+	chains.Lock()
+	chains.list["configchain"] = &chain{cs: &chainSupport{
+		Manager: &mockconfigtx.Manager{
+			ChainIDVal:  "configchain",
+			SequenceVal: 3,
+			Initializer: mockconfigtx.Initializer{Resources: mockconfigtx.Resources{
+				PolicyManagerVal: &mockpolicies.Manager{PolicyMap: map[string]*mockpolicies.Policy{"Writers": writers}},
+				MSPManagerVal:    msp.NewMSPManager(),
+			}},
+		},
+		ApplicationConfig: &mockApplicationConfig{disabled: map[string]bool{"GET_HISTORY_FOR_KEY": true}},
+	}}
+	chains.Unlock()
+	defer func() {
+		chains.Lock()
+		delete(chains.list, "configchain")
+		chains.Unlock()
+	}()
+
+	config, err := plugincontext.Get().ChannelConfig("configchain")
+	assert.NoError(t, err)
+	assert.Equal(t, "configchain", config.ChainID())
+	assert.Equal(t, uint64(3), config.Sequence())
+	policy, ok := config.GetPolicy("Writers")
+	assert.True(t, ok)
+	assert.Equal(t, writers, policy)
+	_, ok = config.GetPolicy("Readers")
+	assert.False(t, ok)
+	assert.True(t, config.ShimCapabilityDisabled("GET_HISTORY_FOR_KEY"))
+	assert.False(t, config.ShimCapabilityDisabled("GET_STATE"))
+	mspIDs, err := config.MSPIDs()
+	assert.NoError(t, err)
+	assert.Empty(t, mspIDs)
+}
@@ -0,0 +1,349 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replay executes a committed transaction again against the state
+// versions it read, and reports whether the chaincode reproduces the writes,
+// response and event recorded in the transaction. It serves auditing the
+// correctness of the endorsements after the fact. The values of the versions
+// read are taken from the blocks, so that the state of the peer and its
+// history database are not needed
+package replay
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/common/sysccprovider"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	logging "github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("replay")
+
+// Ledger is the part of the ledger of a chain read by the replay
+type Ledger interface {
+	GetTransactionByID(txID string) (*pb.ProcessedTransaction, error)
+	GetBlockByNumber(blockNumber uint64) (*common.Block, error)
+}
+
+// Executor executes the chaincode invocation cis of transaction txID of the
+// chain chainID with the tx simulator txsim, as the endorser did with the
+// proposal prop
+type Executor func(chainID string, txID string, prop *pb.Proposal, cis *pb.ChaincodeInvocationSpec, txsim ledger.TxSimulator) (*pb.Response, *pb.ChaincodeEvent, error)
+
+// Report is the outcome of the replay of a transaction
+type Report struct {
+	ChainID   string `json:"chain_id"`
+	TxID      string `json:"tx_id"`
+	Chaincode string `json:"chaincode"`
+	// Reproducible is set when the replay wrote, responded and emitted what
+	// the transaction recorded
+	Reproducible bool `json:"reproducible"`
+	// Differences describe how the replay diverged from the transaction
+	Differences []string `json:"differences,omitempty"`
+}
+
+func (r *Report) differ(format string, args ...interface{}) {
+	r.Differences = append(r.Differences, fmt.Sprintf(format, args...))
+}
+
+// Replay executes the transaction txID of the chain chainID again with
+// execute, against the versions of the keys the transaction read, and reports
+// whether the execution reproduces it. An error is returned when the
+// transaction cannot be replayed at all
+func Replay(l Ledger, chainID string, txID string, execute Executor) (*Report, error) {
+	ptx, err := l.GetTransactionByID(txID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get transaction %s, error %s", txID, err)
+	}
+	payload, err := utils.GetPayload(ptx.TransactionEnvelope)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the payload of transaction %s, error %s", txID, err)
+	}
+	if payload.Header == nil || payload.Header.ChannelHeader == nil ||
+		common.HeaderType(payload.Header.ChannelHeader.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+		return nil, fmt.Errorf("Transaction %s is not an endorser transaction", txID)
+	}
+	tx, err := utils.GetTransaction(payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get transaction %s, error %s", txID, err)
+	}
+	if len(tx.Actions) != 1 {
+		return nil, fmt.Errorf("Transaction %s has %d actions, only transactions of one action can be replayed", txID, len(tx.Actions))
+	}
+	cap, action, err := utils.GetPayloads(tx.Actions[0])
+	if err != nil || action == nil {
+		return nil, fmt.Errorf("Failed to get the chaincode action of transaction %s, error %v", txID, err)
+	}
+	cpp, err := utils.GetChaincodeProposalPayload(cap.ChaincodeProposalPayload)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the proposal payload of transaction %s, error %s", txID, err)
+	}
+	cis := &pb.ChaincodeInvocationSpec{}
+	if err = proto.Unmarshal(cpp.Input, cis); err != nil {
+		return nil, fmt.Errorf("Failed to get the chaincode invocation of transaction %s, error %s", txID, err)
+	}
+	if cis.ChaincodeSpec == nil || cis.ChaincodeSpec.ChaincodeId == nil || cis.ChaincodeSpec.Input == nil {
+		return nil, fmt.Errorf("Transaction %s has an incomplete chaincode invocation", txID)
+	}
+	recorded := &rwset.TxReadWriteSet{}
+	if err = recorded.Unmarshal(action.Results); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal the read write set of transaction %s, error %s", txID, err)
+	}
+
+	// the proposal as the chaincode saw it, the transient data and the
+	// signature of the proposal not being recorded in the transaction
+	hdrBytes, err := utils.GetBytesHeader(payload.Header)
+	if err != nil {
+		return nil, err
+	}
+	prop := &pb.Proposal{Header: hdrBytes, Payload: cap.ChaincodeProposalPayload}
+
+	report := &Report{
+		ChainID:   chainID,
+		TxID:      txID,
+		Chaincode: cis.ChaincodeSpec.ChaincodeId.Name,
+	}
+	logger.Debugf("Replaying transaction %s of chaincode %s on chain %s", txID, report.Chaincode, chainID)
+
+	sim := newSimulator(newHistoricalState(l, recorded))
+	res, event, err := execute(chainID, txID, prop, cis, sim)
+	results, rerr := sim.GetTxSimulationResults()
+	for _, divergence := range sim.divergences {
+		report.differ("%s", divergence)
+	}
+	if err != nil {
+		report.differ("Chaincode execution failed: %s", err)
+		return report, nil
+	}
+	if rerr != nil {
+		return nil, rerr
+	}
+	replayed := &rwset.TxReadWriteSet{}
+	if err = replayed.Unmarshal(results); err != nil {
+		return nil, err
+	}
+
+	compareRWSets(report, recorded, replayed)
+	compareResponses(report, action.Response, res)
+	if err = compareEvents(report, action.Events, event); err != nil {
+		return nil, err
+	}
+	report.Reproducible = len(report.Differences) == 0
+	return report, nil
+}
+
+// ChaincodeExecutor is the Executor running the chaincodes of the peer, at
+// the version LCCC listed when the transaction was endorsed
+func ChaincodeExecutor(chainID string, txID string, prop *pb.Proposal, cis *pb.ChaincodeInvocationSpec, txsim ledger.TxSimulator) (*pb.Response, *pb.ChaincodeEvent, error) {
+	name := cis.ChaincodeSpec.ChaincodeId.Name
+	if sysccprovider.GetSystemChaincodeProvider().IsSysCC(name) {
+		return nil, nil, fmt.Errorf("Transactions of system chaincode %s cannot be replayed", name)
+	}
+
+	ccprov := ccprovider.GetChaincodeProvider()
+	ctxt := ccprov.GetContextForSimulator(txsim)
+	cd, err := ccprov.GetChaincodeDataFromLCCC(ctxt, txID, prop, chainID, name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to obtain the chaincode data of %s, error %s", name, err)
+	}
+	cccid := ccprov.GetCCContext(chainID, name, cd.Version, txID, false, prop)
+	return ccprov.ExecuteChaincode(ctxt, cccid, cis.ChaincodeSpec.Input.Args)
+}
+
+func nsRWSets(txRWSet *rwset.TxReadWriteSet) map[string]*rwset.NsReadWriteSet {
+	nsRWSets := make(map[string]*rwset.NsReadWriteSet)
+	for _, nsRWSet := range txRWSet.NsRWs {
+		nsRWSets[nsRWSet.NameSpace] = nsRWSet
+	}
+	return nsRWSets
+}
+
+func versionString(ver *version.Height) string {
+	if ver == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d:%d", ver.BlockNum, ver.TxNum)
+}
+
+// compareRWSets reports how the replayed read write set differs from the
+// recorded one, namespace by namespace
+func compareRWSets(report *Report, recorded, replayed *rwset.TxReadWriteSet) {
+	recordedNs, replayedNs := nsRWSets(recorded), nsRWSets(replayed)
+	for _, nsRWSet := range recorded.NsRWs {
+		if _, ok := replayedNs[nsRWSet.NameSpace]; !ok {
+			replayed.NsRWs = append(replayed.NsRWs, &rwset.NsReadWriteSet{NameSpace: nsRWSet.NameSpace})
+		}
+	}
+	for _, replayedRWSet := range replayed.NsRWs {
+		ns := replayedRWSet.NameSpace
+		recordedRWSet, ok := recordedNs[ns]
+		if !ok {
+			recordedRWSet = &rwset.NsReadWriteSet{NameSpace: ns}
+		}
+		compareReads(report, ns, recordedRWSet.Reads, replayedRWSet.Reads)
+		compareWrites(report, ns, recordedRWSet.Writes, replayedRWSet.Writes)
+		compareRangeQueries(report, ns, recordedRWSet.RangeQueriesInfo, replayedRWSet.RangeQueriesInfo)
+		compareMetadataWrites(report, ns, recordedRWSet.MetadataWrites, replayedRWSet.MetadataWrites)
+	}
+}
+
+func compareReads(report *Report, ns string, recorded, replayed []*rwset.KVRead) {
+	replayedReads := make(map[string]*rwset.KVRead)
+	for _, kvRead := range replayed {
+		replayedReads[kvRead.Key] = kvRead
+	}
+	for _, kvRead := range recorded {
+		if _, ok := replayedReads[kvRead.Key]; !ok {
+			report.differ("Key %s of namespace %s was read by the transaction but not by the replay", kvRead.Key, ns)
+		}
+		delete(replayedReads, kvRead.Key)
+	}
+	for _, kvRead := range replayed {
+		if _, ok := replayedReads[kvRead.Key]; ok {
+			report.differ("Key %s of namespace %s was read by the replay but not by the transaction", kvRead.Key, ns)
+		}
+	}
+}
+
+func writeString(kvWrite *rwset.KVWrite) string {
+	if kvWrite == nil {
+		return "no write"
+	}
+	if kvWrite.IsDelete {
+		return "a delete"
+	}
+	return fmt.Sprintf("value %q", kvWrite.Value)
+}
+
+func compareWrites(report *Report, ns string, recorded, replayed []*rwset.KVWrite) {
+	recordedWrites, replayedWrites := make(map[string]*rwset.KVWrite), make(map[string]*rwset.KVWrite)
+	var keys []string
+	for _, kvWrite := range recorded {
+		recordedWrites[kvWrite.Key] = kvWrite
+		keys = append(keys, kvWrite.Key)
+	}
+	for _, kvWrite := range replayed {
+		replayedWrites[kvWrite.Key] = kvWrite
+		if _, ok := recordedWrites[kvWrite.Key]; !ok {
+			keys = append(keys, kvWrite.Key)
+		}
+	}
+	for _, key := range keys {
+		w1, w2 := recordedWrites[key], replayedWrites[key]
+		if w1 != nil && w2 != nil && w1.IsDelete == w2.IsDelete && bytes.Equal(w1.Value, w2.Value) {
+			continue
+		}
+		report.differ("Key %s of namespace %s: the transaction recorded %s, the replay %s", key, ns, writeString(w1), writeString(w2))
+	}
+}
+
+func rangeQueryString(rqi *rwset.RangeQueryInfo) string {
+	s := fmt.Sprintf("[%s, %s) exhausted %t with %d results", rqi.StartKey, rqi.EndKey, rqi.ItrExhausted, len(rqi.Results))
+	for _, kvRead := range rqi.Results {
+		s += fmt.Sprintf(" %s@%s", kvRead.Key, versionString(kvRead.Version))
+	}
+	return s
+}
+
+func compareRangeQueries(report *Report, ns string, recorded, replayed []*rwset.RangeQueryInfo) {
+	if len(recorded) != len(replayed) {
+		report.differ("Namespace %s: the transaction recorded %d range queries, the replay %d", ns, len(recorded), len(replayed))
+		return
+	}
+	for i := range recorded {
+		if s1, s2 := rangeQueryString(recorded[i]), rangeQueryString(replayed[i]); s1 != s2 {
+			report.differ("Range query %d of namespace %s: the transaction recorded %s, the replay %s", i, ns, s1, s2)
+		}
+	}
+}
+
+func compareMetadataWrites(report *Report, ns string, recorded, replayed []*rwset.KVMetadataWrite) {
+	recordedWrites := make(map[string]*rwset.KVMetadataWrite)
+	for _, w := range recorded {
+		recordedWrites[w.Key] = w
+	}
+	for _, w := range replayed {
+		recordedWrite, ok := recordedWrites[w.Key]
+		delete(recordedWrites, w.Key)
+		if !ok {
+			report.differ("The metadata of key %s of namespace %s was written by the replay but not by the transaction", w.Key, ns)
+			continue
+		}
+		if !metadataEqual(recordedWrite.Entries, w.Entries) {
+			report.differ("The metadata of key %s of namespace %s written by the replay differs from the transaction", w.Key, ns)
+		}
+	}
+	for _, w := range recorded {
+		if _, ok := recordedWrites[w.Key]; ok {
+			report.differ("The metadata of key %s of namespace %s was written by the transaction but not by the replay", w.Key, ns)
+		}
+	}
+}
+
+func metadataEqual(e1, e2 []*rwset.KVMetadataEntry) bool {
+	if len(e1) != len(e2) {
+		return false
+	}
+	for i := range e1 {
+		if e1[i].Name != e2[i].Name || !bytes.Equal(e1[i].Value, e2[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func compareResponses(report *Report, recorded, replayed *pb.Response) {
+	if recorded == nil {
+		recorded = &pb.Response{}
+	}
+	if replayed == nil {
+		replayed = &pb.Response{}
+	}
+	if recorded.Status != replayed.Status || recorded.Message != replayed.Message {
+		report.differ("The transaction responded status %d %q, the replay status %d %q",
+			recorded.Status, recorded.Message, replayed.Status, replayed.Message)
+	}
+	if !bytes.Equal(recorded.Payload, replayed.Payload) {
+		report.differ("The transaction responded payload %q, the replay payload %q", recorded.Payload, replayed.Payload)
+	}
+}
+
+func compareEvents(report *Report, recorded []byte, replayed *pb.ChaincodeEvent) error {
+	recordedEvent := &pb.ChaincodeEvent{}
+	if len(recorded) > 0 {
+		var err error
+		if recordedEvent, err = utils.GetChaincodeEvents(recorded); err != nil {
+			return fmt.Errorf("Failed to unmarshal the event of transaction %s, error %s", report.TxID, err)
+		}
+	}
+	if replayed == nil {
+		replayed = &pb.ChaincodeEvent{}
+	}
+	// the endorser sets the transaction ID of the event
+	if recordedEvent.EventName != replayed.EventName || !bytes.Equal(recordedEvent.Payload, replayed.Payload) {
+		report.differ("The transaction emitted event %q with payload %q, the replay event %q with payload %q",
+			recordedEvent.EventName, recordedEvent.Payload, replayed.EventName, replayed.Payload)
+	}
+	return nil
+}
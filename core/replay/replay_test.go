@@ -0,0 +1,207 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockLedger is a chain of blocks of one transaction each
+type mockLedger struct {
+	blocks []*common.Block
+	txs    map[string]*common.Envelope
+}
+
+func (l *mockLedger) GetTransactionByID(txID string) (*pb.ProcessedTransaction, error) {
+	env, ok := l.txs[txID]
+	if !ok {
+		return nil, fmt.Errorf("Entry not found in index")
+	}
+	return &pb.ProcessedTransaction{TransactionEnvelope: env, Valid: true}, nil
+}
+
+func (l *mockLedger) GetBlockByNumber(blockNumber uint64) (*common.Block, error) {
+	if blockNumber >= uint64(len(l.blocks)) {
+		return nil, fmt.Errorf("Block %d not found", blockNumber)
+	}
+	return l.blocks[blockNumber], nil
+}
+
+// addTx appends a block with the transaction txID of chaincode "cc",
+// invoked with args, which recorded txRWSet, response and event
+func (l *mockLedger) addTx(t *testing.T, txID string, args []string, txRWSet *rwset.TxReadWriteSet, response *pb.Response, event *pb.ChaincodeEvent) {
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{Name: "cc"},
+		Input:       &pb.ChaincodeInput{Args: make([][]byte, len(args))},
+	}}
+	for i, arg := range args {
+		cis.ChaincodeSpec.Input.Args[i] = []byte(arg)
+	}
+	results, err := txRWSet.Marshal()
+	assert.NoError(t, err)
+	var events []byte
+	if event != nil {
+		events = utils.MarshalOrPanic(event)
+	}
+	action := &pb.ChaincodeAction{Results: results, Events: events, Response: response}
+	prp := &pb.ProposalResponsePayload{Extension: utils.MarshalOrPanic(action)}
+	cap := &pb.ChaincodeActionPayload{
+		ChaincodeProposalPayload: utils.MarshalOrPanic(&pb.ChaincodeProposalPayload{Input: utils.MarshalOrPanic(cis)}),
+		Action:                   &pb.ChaincodeEndorsedAction{ProposalResponsePayload: utils.MarshalOrPanic(prp)},
+	}
+	tx := &pb.Transaction{Actions: []*pb.TransactionAction{{Payload: utils.MarshalOrPanic(cap)}}}
+	payload := &common.Payload{
+		Header: &common.Header{
+			ChannelHeader:   &common.ChannelHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION), ChannelId: "testchain", TxId: txID},
+			SignatureHeader: &common.SignatureHeader{Creator: []byte("creator")},
+		},
+		Data: utils.MarshalOrPanic(tx),
+	}
+	env := &common.Envelope{Payload: utils.MarshalOrPanic(payload)}
+	block := common.NewBlock(uint64(len(l.blocks)), nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(env)}
+	l.blocks = append(l.blocks, block)
+	l.txs[txID] = env
+}
+
+// newLedger returns a ledger where tx1 wrote a and b, and tx2 read them,
+// directly and through a range query, to write their concatenation in c
+func newLedger(t *testing.T) *mockLedger {
+	l := &mockLedger{txs: make(map[string]*common.Envelope)}
+	l.addTx(t, "tx0", []string{"init"}, &rwset.TxReadWriteSet{}, &pb.Response{Status: 200}, nil)
+
+	tx1 := rwset.NewRWSet()
+	tx1.AddToWriteSet("cc", "a", []byte("1"))
+	tx1.AddToWriteSet("cc", "b", []byte("2"))
+	l.addTx(t, "tx1", []string{"put"}, tx1.GetTxReadWriteSet(), &pb.Response{Status: 200}, nil)
+
+	v := version.NewHeight(1, 1)
+	tx2 := rwset.NewRWSet()
+	tx2.AddToReadSet("cc", "a", v)
+	tx2.AddToReadSet("cc", "missing", nil)
+	tx2.AddToRangeQuerySet("cc", &rwset.RangeQueryInfo{StartKey: "a", EndKey: "c", ItrExhausted: true,
+		Results: []*rwset.KVRead{rwset.NewKVRead("a", v), rwset.NewKVRead("b", v)}})
+	tx2.AddToWriteSet("cc", "c", []byte("12"))
+	l.addTx(t, "tx2", []string{"concat"}, tx2.GetTxReadWriteSet(), &pb.Response{Status: 200, Payload: []byte("12")},
+		&pb.ChaincodeEvent{ChaincodeId: "cc", TxId: "tx2", EventName: "concat", Payload: []byte("12")})
+	return l
+}
+
+// concat is an Executor reading a and missing directly, and all the keys
+// from a to c, to write their values concatenated with suffix in c
+func concat(suffix string) Executor {
+	return func(chainID string, txID string, prop *pb.Proposal, cis *pb.ChaincodeInvocationSpec, txsim ledger.TxSimulator) (*pb.Response, *pb.ChaincodeEvent, error) {
+		if _, err := txsim.GetState("cc", "a"); err != nil {
+			return nil, nil, err
+		}
+		if value, err := txsim.GetState("cc", "missing"); err != nil || value != nil {
+			return nil, nil, fmt.Errorf("Expected missing to be absent, got %q %v", value, err)
+		}
+		itr, err := txsim.GetStateRangeScanIterator("cc", "a", "c")
+		if err != nil {
+			return nil, nil, err
+		}
+		var value []byte
+		for {
+			result, err := itr.Next()
+			if err != nil {
+				return nil, nil, err
+			}
+			if result == nil {
+				break
+			}
+			value = append(value, result.(*ledger.KV).Value...)
+		}
+		itr.Close()
+		value = append(value, suffix...)
+		if err = txsim.SetState("cc", "c", value); err != nil {
+			return nil, nil, err
+		}
+		return &pb.Response{Status: 200, Payload: value}, &pb.ChaincodeEvent{EventName: "concat", Payload: value}, nil
+	}
+}
+
+func TestReplayReproducible(t *testing.T) {
+	report, err := Replay(newLedger(t), "testchain", "tx2", concat(""))
+	assert.NoError(t, err)
+	assert.Empty(t, report.Differences)
+	assert.True(t, report.Reproducible)
+	assert.Equal(t, "cc", report.Chaincode)
+}
+
+func TestReplayNotReproducible(t *testing.T) {
+	report, err := Replay(newLedger(t), "testchain", "tx2", concat("3"))
+	assert.NoError(t, err)
+	assert.False(t, report.Reproducible)
+	// the write, the response and the event differ
+	assert.Len(t, report.Differences, 3)
+	assert.Contains(t, report.Differences[0], `the transaction recorded value "12", the replay value "123"`)
+
+	// a read the transaction did not perform diverges
+	report, err = Replay(newLedger(t), "testchain", "tx2",
+		func(chainID string, txID string, prop *pb.Proposal, cis *pb.ChaincodeInvocationSpec, txsim ledger.TxSimulator) (*pb.Response, *pb.ChaincodeEvent, error) {
+			_, err := txsim.GetState("cc", "b")
+			if err != nil {
+				return nil, nil, err
+			}
+			_, err = txsim.GetState("cc", "z")
+			return &pb.Response{Status: 500, Message: err.Error()}, nil, nil
+		})
+	assert.NoError(t, err)
+	assert.False(t, report.Reproducible)
+	assert.Contains(t, report.Differences[0], "Key z of namespace cc was not read by the transaction")
+	assert.Contains(t, report.Differences, "Key b of namespace cc was read by the replay but not by the transaction")
+
+	// iterating further than the transaction diverges
+	report, err = Replay(newLedger(t), "testchain", "tx2",
+		func(chainID string, txID string, prop *pb.Proposal, cis *pb.ChaincodeInvocationSpec, txsim ledger.TxSimulator) (*pb.Response, *pb.ChaincodeEvent, error) {
+			_, err := txsim.GetStateRangeScanIterator("cc", "b", "")
+			return nil, nil, err
+		})
+	assert.NoError(t, err)
+	assert.False(t, report.Reproducible)
+	assert.Contains(t, report.Differences[0], "No range query from key b of namespace cc")
+	assert.Contains(t, report.Differences[1], "Chaincode execution failed")
+}
+
+func TestReplayErrors(t *testing.T) {
+	l := newLedger(t)
+	_, err := Replay(l, "testchain", "unknown", concat(""))
+	assert.Error(t, err)
+
+	// the value of a version must be written by the transaction of the version
+	tx3 := rwset.NewRWSet()
+	tx3.AddToReadSet("cc", "a", version.NewHeight(0, 1))
+	l.addTx(t, "tx3", []string{"get"}, tx3.GetTxReadWriteSet(), &pb.Response{Status: 200}, nil)
+	report, err := Replay(l, "testchain", "tx3",
+		func(chainID string, txID string, prop *pb.Proposal, cis *pb.ChaincodeInvocationSpec, txsim ledger.TxSimulator) (*pb.Response, *pb.ChaincodeEvent, error) {
+			_, err := txsim.GetState("cc", "a")
+			return nil, nil, err
+		})
+	assert.NoError(t, err)
+	assert.False(t, report.Reproducible)
+	assert.Contains(t, report.Differences[0], "at version 0:1 was not written by the transaction of that version")
+}
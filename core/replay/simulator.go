@@ -0,0 +1,319 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"errors"
+	"fmt"
+
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// historicalState serves the values of the keys read by a transaction, at the
+// versions it read them. The value of a key at a version is the value written
+// by the transaction of that version, found in its block
+type historicalState struct {
+	ledger Ledger
+
+	// reads are the versions of the keys read by the transaction, including
+	// the results of its range queries, by namespace
+	reads map[string]map[string]*version.Height
+	// rangeQueries are the range queries of the transaction by namespace,
+	// consumed in order by the range queries replayed
+	rangeQueries map[string][]*rwset.RangeQueryInfo
+
+	// rwsets are the read write sets of the transactions which wrote the
+	// versions read, by version
+	rwsets map[version.Height]*rwset.TxReadWriteSet
+}
+
+func newHistoricalState(l Ledger, recorded *rwset.TxReadWriteSet) *historicalState {
+	s := &historicalState{
+		ledger:       l,
+		reads:        make(map[string]map[string]*version.Height),
+		rangeQueries: make(map[string][]*rwset.RangeQueryInfo),
+		rwsets:       make(map[version.Height]*rwset.TxReadWriteSet),
+	}
+	for _, nsRWSet := range recorded.NsRWs {
+		reads := make(map[string]*version.Height)
+		for _, kvRead := range nsRWSet.Reads {
+			reads[kvRead.Key] = kvRead.Version
+		}
+		for _, rqi := range nsRWSet.RangeQueriesInfo {
+			for _, kvRead := range rqi.Results {
+				reads[kvRead.Key] = kvRead.Version
+			}
+		}
+		s.reads[nsRWSet.NameSpace] = reads
+		s.rangeQueries[nsRWSet.NameSpace] = nsRWSet.RangeQueriesInfo
+	}
+	return s
+}
+
+// readVersion returns the version at which the transaction read a key
+func (s *historicalState) readVersion(ns string, key string) (*version.Height, error) {
+	ver, ok := s.reads[ns][key]
+	if !ok {
+		return nil, fmt.Errorf("Key %s of namespace %s was not read by the transaction", key, ns)
+	}
+	return ver, nil
+}
+
+// valueAt returns the value of a key at a version, nil if the version is nil
+// as the key did not exist
+func (s *historicalState) valueAt(ns string, key string, ver *version.Height) ([]byte, error) {
+	if ver == nil {
+		return nil, nil
+	}
+	txRWSet, err := s.rwsetAt(ver)
+	if err != nil {
+		return nil, err
+	}
+	for _, nsRWSet := range txRWSet.NsRWs {
+		if nsRWSet.NameSpace != ns {
+			continue
+		}
+		for _, kvWrite := range nsRWSet.Writes {
+			if kvWrite.Key == key {
+				if kvWrite.IsDelete {
+					return nil, nil
+				}
+				return kvWrite.Value, nil
+			}
+		}
+	}
+	// a metadata write keeps the value, written by an earlier transaction
+	return nil, fmt.Errorf("The value of key %s of namespace %s at version %d:%d was not written by the transaction of that version",
+		key, ns, ver.BlockNum, ver.TxNum)
+}
+
+// rwsetAt returns the read write set of the transaction of a version, the
+// transaction numbers of the versions starting at 1
+func (s *historicalState) rwsetAt(ver *version.Height) (*rwset.TxReadWriteSet, error) {
+	if txRWSet, ok := s.rwsets[*ver]; ok {
+		return txRWSet, nil
+	}
+	block, err := s.ledger.GetBlockByNumber(ver.BlockNum)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get block %d, error %s", ver.BlockNum, err)
+	}
+	if block.Data == nil || ver.TxNum < 1 || ver.TxNum > uint64(len(block.Data.Data)) {
+		return nil, fmt.Errorf("Block %d has no transaction %d", ver.BlockNum, ver.TxNum)
+	}
+	action, err := utils.GetActionFromEnvelope(block.Data.Data[ver.TxNum-1])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the chaincode action of transaction %d:%d, error %s", ver.BlockNum, ver.TxNum, err)
+	}
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err = txRWSet.Unmarshal(action.Results); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal the read write set of transaction %d:%d, error %s", ver.BlockNum, ver.TxNum, err)
+	}
+	s.rwsets[*ver] = txRWSet
+	return txRWSet, nil
+}
+
+// nextRangeQuery returns the next range query of the transaction starting at
+// startKey in a namespace
+func (s *historicalState) nextRangeQuery(ns string, startKey string) (*rwset.RangeQueryInfo, error) {
+	rangeQueries := s.rangeQueries[ns]
+	for i, rqi := range rangeQueries {
+		if rqi.StartKey != startKey {
+			continue
+		}
+		if rqi.ResultHash != nil {
+			return nil, fmt.Errorf("The results of the range query from key %s of namespace %s were recorded as a hash and cannot be replayed", startKey, ns)
+		}
+		s.rangeQueries[ns] = append(append([]*rwset.RangeQueryInfo{}, rangeQueries[:i]...), rangeQueries[i+1:]...)
+		return rqi, nil
+	}
+	return nil, fmt.Errorf("No range query from key %s of namespace %s was executed by the transaction", startKey, ns)
+}
+
+// simulator is a ledger.TxSimulator executing a transaction again on the
+// historical state it read. The reads which the transaction did not perform
+// fail, and are recorded as divergences of the replay
+type simulator struct {
+	state       *historicalState
+	rwset       *rwset.RWSet
+	itrs        []*rangeIterator
+	divergences []string
+	done        bool
+}
+
+func newSimulator(state *historicalState) *simulator {
+	return &simulator{state: state, rwset: rwset.NewRWSet()}
+}
+
+func (s *simulator) diverge(err error) error {
+	s.divergences = append(s.divergences, err.Error())
+	return err
+}
+
+// GetState implements method in interface `ledger.TxSimulator`
+func (s *simulator) GetState(ns string, key string) ([]byte, error) {
+	ver, err := s.state.readVersion(ns, key)
+	if err != nil {
+		return nil, s.diverge(err)
+	}
+	value, err := s.state.valueAt(ns, key, ver)
+	if err != nil {
+		return nil, s.diverge(err)
+	}
+	s.rwset.AddToReadSet(ns, key, ver)
+	return value, nil
+}
+
+// GetStateMultipleKeys implements method in interface `ledger.TxSimulator`
+func (s *simulator) GetStateMultipleKeys(ns string, keys []string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		value, err := s.GetState(ns, key)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// GetStateMetadata implements method in interface `ledger.TxSimulator`. The
+// metadata of a key is kept across the transactions writing its value, so
+// that the version read does not tell its metadata
+func (s *simulator) GetStateMetadata(ns string, key string) (map[string][]byte, error) {
+	return nil, s.diverge(fmt.Errorf("The metadata of key %s of namespace %s cannot be replayed", key, ns))
+}
+
+// GetStateRangeScanIterator implements method in interface `ledger.TxSimulator`.
+// The iterator returns the results of the range query of the transaction
+func (s *simulator) GetStateRangeScanIterator(ns string, startKey string, endKey string) (commonledger.ResultsIterator, error) {
+	recorded, err := s.state.nextRangeQuery(ns, startKey)
+	if err != nil {
+		return nil, s.diverge(err)
+	}
+	helper, err := rwset.NewRangeQueryResultsHelper(false, 0)
+	if err != nil {
+		return nil, err
+	}
+	itr := &rangeIterator{
+		sim:      s,
+		ns:       ns,
+		endKey:   endKey,
+		recorded: recorded,
+		info:     &rwset.RangeQueryInfo{StartKey: startKey},
+		helper:   helper,
+	}
+	s.itrs = append(s.itrs, itr)
+	return itr, nil
+}
+
+// ExecuteQuery implements method in interface `ledger.TxSimulator`. The
+// results of rich queries are not recorded by the transactions
+func (s *simulator) ExecuteQuery(ns, query string) (commonledger.ResultsIterator, error) {
+	return nil, s.diverge(fmt.Errorf("Rich queries of namespace %s cannot be replayed", ns))
+}
+
+// SetState implements method in interface `ledger.TxSimulator`
+func (s *simulator) SetState(ns string, key string, value []byte) error {
+	s.rwset.AddToWriteSet(ns, key, value)
+	return nil
+}
+
+// DeleteState implements method in interface `ledger.TxSimulator`
+func (s *simulator) DeleteState(ns string, key string) error {
+	return s.SetState(ns, key, nil)
+}
+
+// SetStateMultipleKeys implements method in interface `ledger.TxSimulator`
+func (s *simulator) SetStateMultipleKeys(ns string, kvs map[string][]byte) error {
+	for k, v := range kvs {
+		if err := s.SetState(ns, k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetStateMetadata implements method in interface `ledger.TxSimulator`
+func (s *simulator) SetStateMetadata(ns string, key string, metadata map[string][]byte) error {
+	s.rwset.AddToMetadataWriteSet(ns, key, metadata)
+	return nil
+}
+
+// ExecuteUpdate implements method in interface `ledger.TxSimulator`
+func (s *simulator) ExecuteUpdate(query string) error {
+	return errors.New("Not supported")
+}
+
+// Done implements method in interface `ledger.TxSimulator`
+func (s *simulator) Done() {
+	if s.done {
+		return
+	}
+	s.done = true
+	for _, itr := range s.itrs {
+		itr.info.Results, _, _ = itr.helper.Done()
+		s.rwset.AddToRangeQuerySet(itr.ns, itr.info)
+	}
+}
+
+// GetTxSimulationResults implements method in interface `ledger.TxSimulator`
+func (s *simulator) GetTxSimulationResults() ([]byte, error) {
+	s.Done()
+	return s.rwset.GetTxReadWriteSet().Marshal()
+}
+
+// rangeIterator returns the results of a range query of the transaction,
+// recording the range query replayed like the simulators of the ledger do
+type rangeIterator struct {
+	sim      *simulator
+	ns       string
+	endKey   string
+	recorded *rwset.RangeQueryInfo
+	next     int
+	info     *rwset.RangeQueryInfo
+	helper   *rwset.RangeQueryResultsHelper
+}
+
+// Next implements method in interface ledger.ResultsIterator
+func (itr *rangeIterator) Next() (commonledger.QueryResult, error) {
+	if itr.next == len(itr.recorded.Results) {
+		if !itr.recorded.ItrExhausted {
+			return nil, itr.sim.diverge(fmt.Errorf("The range query from key %s of namespace %s was iterated past key %s",
+				itr.recorded.StartKey, itr.ns, itr.recorded.EndKey))
+		}
+		itr.info.ItrExhausted = true
+		itr.info.EndKey = itr.endKey
+		return nil, nil
+	}
+	kvRead := itr.recorded.Results[itr.next]
+	itr.next++
+	value, err := itr.sim.state.valueAt(itr.ns, kvRead.Key, kvRead.Version)
+	if err != nil {
+		return nil, itr.sim.diverge(err)
+	}
+	itr.helper.AddResult(rwset.NewKVRead(kvRead.Key, kvRead.Version))
+	itr.info.EndKey = kvRead.Key
+	return &ledger.KV{Key: kvRead.Key, Value: value}, nil
+}
+
+// Close implements method in interface ledger.ResultsIterator
+func (itr *rangeIterator) Close() {
+}
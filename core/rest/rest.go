@@ -0,0 +1,288 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rest maps the read only services of the peer to REST endpoints
+// returning JSON, for the monitoring systems and the scripts which have no
+// protobuf tooling. As a gRPC gateway would, every request is translated to a
+// call of the gRPC service, the queries of the ledger being proposals to QSCC
+// which are signed by the identity of the gateway and processed by the
+// endorser as any other proposal. As a client of the gateway queries the
+// ledger as the peer, the peer only serves it over TLS to the clients
+// authenticated by a certificate issued by one of its client root CAs, and
+// only answers the queries of the ledger of the clients whose certificate is
+// the one of an admin of its local MSP. The endpoints are
+//
+//	GET /health                                      the status of the peer
+//	GET /channels                                    the channels of the peer
+//	GET /channels/{channel}                          the height of the channel
+//	GET /channels/{channel}/peers                    the peers of the channel alive
+//	GET /channels/{channel}/blocks/{number}          a block by number
+//	GET /channels/{channel}/blocks?hash={hex}        a block by hash
+//	GET /channels/{channel}/transactions/{txid}      a transaction
+//	GET /channels/{channel}/transactions/{txid}/block the block of a transaction
+//	GET /channels/{channel}/state/{namespace}?height={height}&key={key}...
+//	                                                 keys at a height
+//
+// The messages are rendered in the JSON mapping of protobuf, the bytes fields
+// being base64 encoded, and the errors as {"error": message}
+package rest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/scc/qscc"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+var logger = logging.MustGetLogger("rest")
+
+// Support is what the gateway needs from the peer besides its gRPC services
+type Support interface {
+	// ChannelIDs returns the channels the peer has joined
+	ChannelIDs() []string
+
+	// PeersOfChannel returns the peers of a channel known to be alive
+	PeersOfChannel(chainID string) []Peer
+}
+
+// StatusServer is the part of the admin service reporting the status of the peer
+type StatusServer interface {
+	GetStatus(context.Context, *empty.Empty) (*pb.ServerStatus, error)
+}
+
+// Peer is a peer of a channel, as discovered by gossip
+type Peer struct {
+	Endpoint string `json:"endpoint"`
+	PKIID    string `json:"pkiId"`
+}
+
+// Gateway serves the REST endpoints
+type Gateway struct {
+	support  Support
+	admin    StatusServer
+	endorser pb.EndorserServer
+	signer   msp.SigningIdentity
+	localMSP msp.MSP
+}
+
+// NewGateway returns a Gateway translating the requests to calls of admin and
+// endorser, the proposals being signed by signer. The ledger is only queried
+// for the clients authenticated by the certificate of an admin of localMSP
+func NewGateway(support Support, admin StatusServer, endorser pb.EndorserServer, signer msp.SigningIdentity, localMSP msp.MSP) *Gateway {
+	return &Gateway{support: support, admin: admin, endorser: endorser, signer: signer, localMSP: localMSP}
+}
+
+// httpError is an error with the HTTP status of the response reporting it
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+func errorf(status int, format string, args ...interface{}) error {
+	return &httpError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// ServeHTTP serves a request
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		g.reply(w, errorf(http.StatusMethodNotAllowed, "Method %s not allowed", req.Method), nil)
+		return
+	}
+	result, err := g.route(req)
+	g.reply(w, err, result)
+}
+
+func (g *Gateway) route(req *http.Request) (interface{}, error) {
+	path := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 1 && path[0] == "health":
+		return g.admin.GetStatus(req.Context(), &empty.Empty{})
+	case len(path) == 1 && path[0] == "channels":
+		return &struct {
+			Channels []string `json:"channels"`
+		}{g.support.ChannelIDs()}, nil
+	case len(path) < 2 || path[0] != "channels" || !g.hasChannel(path[1]):
+		return nil, errorf(http.StatusNotFound, "Not found: %s", req.URL.Path)
+	}
+
+	chainID := path[1]
+	switch resource := path[2:]; {
+	case len(resource) == 0:
+		return g.query(req, chainID, &cb.BlockchainInfo{}, qscc.GetChainInfo)
+	case len(resource) == 1 && resource[0] == "peers":
+		return &struct {
+			Peers []Peer `json:"peers"`
+		}{g.support.PeersOfChannel(chainID)}, nil
+	case len(resource) == 1 && resource[0] == "blocks":
+		hash, err := hex.DecodeString(req.URL.Query().Get("hash"))
+		if err != nil || len(hash) == 0 {
+			return nil, errorf(http.StatusBadRequest, "A hex encoded block hash must be provided")
+		}
+		return g.query(req, chainID, &cb.Block{}, qscc.GetBlockByHash, string(hash))
+	case len(resource) == 2 && resource[0] == "blocks":
+		if _, err := strconv.ParseUint(resource[1], 10, 64); err != nil {
+			return nil, errorf(http.StatusBadRequest, "Invalid block number %s", resource[1])
+		}
+		return g.query(req, chainID, &cb.Block{}, qscc.GetBlockByNumber, resource[1])
+	case len(resource) == 2 && resource[0] == "transactions":
+		return g.query(req, chainID, &pb.ProcessedTransaction{}, qscc.GetTransactionByID, resource[1])
+	case len(resource) == 3 && resource[0] == "transactions" && resource[2] == "block":
+		return g.query(req, chainID, &cb.Block{}, qscc.GetBlockByTxID, resource[1])
+	case len(resource) == 2 && resource[0] == "state":
+		height := req.URL.Query().Get("height")
+		if _, err := strconv.ParseUint(height, 10, 64); err != nil {
+			return nil, errorf(http.StatusBadRequest, "Invalid height %s", height)
+		}
+		keys := req.URL.Query()["key"]
+		if len(keys) == 0 {
+			return nil, errorf(http.StatusBadRequest, "At least one key must be provided")
+		}
+		return g.query(req, chainID, &pb.QueryStateResponse{}, qscc.GetStateAtHeight, append([]string{height, resource[1]}, keys...)...)
+	}
+	return nil, errorf(http.StatusNotFound, "Not found: %s", req.URL.Path)
+}
+
+func (g *Gateway) hasChannel(chainID string) bool {
+	for _, id := range g.support.ChannelIDs() {
+		if id == chainID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLocalAdmin returns nil if the client of req is authenticated by the TLS
+// certificate of an admin of the local MSP, as the proposals of the gateway
+// are signed by the peer
+func (g *Gateway) checkLocalAdmin(req *http.Request) error {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return errorf(http.StatusUnauthorized, "A TLS client certificate is required")
+	}
+	mspID, err := g.localMSP.GetIdentifier()
+	if err != nil {
+		return fmt.Errorf("Error getting the local MSP ID: %s", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: req.TLS.PeerCertificates[0].Raw})
+	serialized, err := msp.NewSerializedIdentity(mspID, certPEM)
+	if err != nil {
+		return fmt.Errorf("Error serializing the identity of the client: %s", err)
+	}
+	id, err := g.localMSP.DeserializeIdentity(serialized)
+	if err != nil {
+		return errorf(http.StatusForbidden, "The client is not an identity of the local MSP %s", mspID)
+	}
+	principal := &cb.MSPPrincipal{
+		PrincipalClassification: cb.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&cb.MSPRole{MspIdentifier: mspID, Role: cb.MSPRole_ADMIN}),
+	}
+	if err = g.localMSP.SatisfiesPrincipal(id, principal); err != nil {
+		return errorf(http.StatusForbidden, "The client is not an admin of the local MSP %s", mspID)
+	}
+	return nil
+}
+
+// query sends a proposal invoking fname of QSCC on chainID, and unmarshals the
+// payload of the response into result. Only the admins of the local MSP query
+// the ledger
+func (g *Gateway) query(req *http.Request, chainID string, result proto.Message, fname string, args ...string) (proto.Message, error) {
+	if err := g.checkLocalAdmin(req); err != nil {
+		return nil, err
+	}
+	ctx := req.Context()
+	input := &pb.ChaincodeInput{Args: [][]byte{[]byte(fname), []byte(chainID)}}
+	for _, arg := range args {
+		input.Args = append(input.Args, []byte(arg))
+	}
+	invocation := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_GOLANG,
+		ChaincodeId: &pb.ChaincodeID{Name: "qscc"},
+		Input:       input,
+	}}
+
+	creator, err := g.signer.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Error serializing the identity of the gateway: %s", err)
+	}
+	prop, err := utils.CreateProposalFromCIS(util.GenerateUUID(), cb.HeaderType_ENDORSER_TRANSACTION, chainID, invocation, creator)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating proposal for %s: %s", fname, err)
+	}
+	signedProp, err := utils.GetSignedProposal(prop, g.signer)
+	if err != nil {
+		return nil, fmt.Errorf("Error signing proposal for %s: %s", fname, err)
+	}
+
+	proposalResp, err := g.endorser.ProcessProposal(ctx, signedProp)
+	switch {
+	case proposalResp != nil && proposalResp.Response != nil && proposalResp.Response.Status >= 400 && proposalResp.Response.Status < 600:
+		return nil, errorf(int(proposalResp.Response.Status), "%s", proposalResp.Response.Message)
+	case err != nil:
+		return nil, fmt.Errorf("Error processing proposal for %s: %s", fname, err)
+	case proposalResp == nil || proposalResp.Response == nil:
+		return nil, fmt.Errorf("Nil proposal response for %s", fname)
+	}
+	if err = proto.Unmarshal(proposalResp.Response.Payload, result); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling the result of %s: %s", fname, err)
+	}
+	return result, nil
+}
+
+// reply writes the JSON rendering of result, or of err if not nil
+func (g *Gateway) reply(w http.ResponseWriter, err error, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		status := http.StatusInternalServerError
+		if httpErr, ok := err.(*httpError); ok {
+			status = httpErr.status
+		} else {
+			logger.Warningf("Failed serving a request: %s", err)
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(&struct {
+			Error string `json:"error"`
+		}{err.Error()})
+		return
+	}
+
+	if msg, ok := result.(proto.Message); ok {
+		if err = (&jsonpb.Marshaler{OrigName: true}).Marshal(w, msg); err != nil {
+			logger.Errorf("Failed encoding a response: %s", err)
+		}
+		return
+	}
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		logger.Errorf("Failed encoding a response: %s", err)
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/core/scc/qscc"
+	mspfixtures "github.com/hyperledger/fabric/msp/fixtures"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+type mockSupport struct{}
+
+func (mockSupport) ChannelIDs() []string {
+	return []string{"mychannel"}
+}
+
+func (mockSupport) PeersOfChannel(chainID string) []Peer {
+	return []Peer{{Endpoint: "peer0:7051", PKIID: "0102"}}
+}
+
+type mockAdmin struct{}
+
+func (mockAdmin) GetStatus(context.Context, *empty.Empty) (*pb.ServerStatus, error) {
+	return &pb.ServerStatus{Status: pb.ServerStatus_STARTED}, nil
+}
+
+// mockEndorser answers the proposals to QSCC, recording their arguments
+type mockEndorser struct {
+	args [][]byte
+}
+
+func (e *mockEndorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ProposalResponse, error) {
+	prop, err := utils.GetProposal(signedProp.ProposalBytes)
+	if err != nil {
+		return nil, err
+	}
+	cis, err := utils.GetChaincodeInvocationSpec(prop)
+	if err != nil {
+		return nil, err
+	}
+	if cis.ChaincodeSpec.ChaincodeId.Name != "qscc" {
+		return nil, fmt.Errorf("Unexpected chaincode %s", cis.ChaincodeSpec.ChaincodeId.Name)
+	}
+	e.args = cis.ChaincodeSpec.Input.Args
+
+	var result proto.Message
+	switch string(e.args[0]) {
+	case qscc.GetChainInfo:
+		result = &cb.BlockchainInfo{Height: 5}
+	case qscc.GetBlockByNumber, qscc.GetBlockByHash, qscc.GetBlockByTxID:
+		result = &cb.Block{Header: &cb.BlockHeader{Number: 3}}
+	case qscc.GetTransactionByID:
+		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: "Failed to get transaction"}}, fmt.Errorf("Failed to get transaction")
+	case qscc.GetStateAtHeight:
+		result = &pb.QueryStateResponse{KeysAndValues: []*pb.QueryStateKeyValue{{Key: "a", Value: []byte("1")}}}
+	}
+	payload, err := proto.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ProposalResponse{Response: &pb.Response{Status: 200, Payload: payload}}, nil
+}
+
+func newTestGateway(t *testing.T) (*mockEndorser, *httptest.Server) {
+	org, err := mspfixtures.NewOrg("Org1MSP")
+	assert.NoError(t, err)
+	return newTestGatewayWithClient(t, org, org.Admin.Cert)
+}
+
+// newTestGatewayWithClient serves a gateway of the peer of org to the client
+// authenticated by the TLS certificate cert, if not nil
+func newTestGatewayWithClient(t *testing.T, org *mspfixtures.Org, cert *x509.Certificate) (*mockEndorser, *httptest.Server) {
+	signer, err := org.SigningIdentity(org.Member)
+	assert.NoError(t, err)
+	localMSP, err := org.MSP(org.Member)
+	assert.NoError(t, err)
+	endorser := &mockEndorser{}
+	gateway := NewGateway(mockSupport{}, mockAdmin{}, endorser, signer, localMSP)
+	return endorser, httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if cert != nil {
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+		gateway.ServeHTTP(w, req)
+	}))
+}
+
+func get(t *testing.T, server *httptest.Server, path string, result proto.Message) int {
+	resp, err := http.Get(server.URL + path)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	if resp.StatusCode == http.StatusOK {
+		assert.NoError(t, jsonpb.Unmarshal(resp.Body, result))
+	}
+	return resp.StatusCode
+}
+
+func TestHealthAndChannels(t *testing.T) {
+	_, server := newTestGateway(t)
+	defer server.Close()
+
+	status := &pb.ServerStatus{}
+	assert.Equal(t, http.StatusOK, get(t, server, "/health", status))
+	assert.Equal(t, pb.ServerStatus_STARTED, status.Status)
+
+	resp, err := http.Get(server.URL + "/channels")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	channels := &struct {
+		Channels []string `json:"channels"`
+	}{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(channels))
+	assert.Equal(t, []string{"mychannel"}, channels.Channels)
+
+	resp, err = http.Get(server.URL + "/channels/mychannel/peers")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	peers := &struct {
+		Peers []Peer `json:"peers"`
+	}{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(peers))
+	assert.Equal(t, "peer0:7051", peers.Peers[0].Endpoint)
+}
+
+func TestQueries(t *testing.T) {
+	endorser, server := newTestGateway(t)
+	defer server.Close()
+
+	info := &cb.BlockchainInfo{}
+	assert.Equal(t, http.StatusOK, get(t, server, "/channels/mychannel", info))
+	assert.Equal(t, uint64(5), info.Height)
+	assert.Equal(t, [][]byte{[]byte(qscc.GetChainInfo), []byte("mychannel")}, endorser.args)
+
+	block := &cb.Block{}
+	assert.Equal(t, http.StatusOK, get(t, server, "/channels/mychannel/blocks/3", block))
+	assert.Equal(t, uint64(3), block.Header.Number)
+	assert.Equal(t, []byte("3"), endorser.args[2])
+
+	assert.Equal(t, http.StatusOK, get(t, server, "/channels/mychannel/blocks?hash=0aff", block))
+	assert.Equal(t, [][]byte{[]byte(qscc.GetBlockByHash), []byte("mychannel"), {0x0a, 0xff}}, endorser.args)
+
+	assert.Equal(t, http.StatusOK, get(t, server, "/channels/mychannel/transactions/tx1/block", block))
+	assert.Equal(t, [][]byte{[]byte(qscc.GetBlockByTxID), []byte("mychannel"), []byte("tx1")}, endorser.args)
+
+	state := &pb.QueryStateResponse{}
+	assert.Equal(t, http.StatusOK, get(t, server, "/channels/mychannel/state/mycc?height=4&key=a&key=b", state))
+	assert.Equal(t, "a", state.KeysAndValues[0].Key)
+	assert.Equal(t, [][]byte{[]byte(qscc.GetStateAtHeight), []byte("mychannel"), []byte("4"), []byte("mycc"), []byte("a"), []byte("b")}, endorser.args)
+}
+
+func TestErrors(t *testing.T) {
+	_, server := newTestGateway(t)
+	defer server.Close()
+
+	// the status of the response of the endorser is kept
+	assert.Equal(t, http.StatusInternalServerError, get(t, server, "/channels/mychannel/transactions/tx1", nil))
+	assert.Equal(t, http.StatusNotFound, get(t, server, "/channels/otherchannel", nil))
+	assert.Equal(t, http.StatusNotFound, get(t, server, "/channels/mychannel/unknown", nil))
+	assert.Equal(t, http.StatusBadRequest, get(t, server, "/channels/mychannel/blocks/first", nil))
+	assert.Equal(t, http.StatusBadRequest, get(t, server, "/channels/mychannel/blocks?hash=zz", nil))
+	assert.Equal(t, http.StatusBadRequest, get(t, server, "/channels/mychannel/state/mycc?height=4", nil))
+
+	resp, err := http.Post(server.URL+"/health", "application/json", nil)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestQueriesOnlyForLocalAdmins(t *testing.T) {
+	org, err := mspfixtures.NewOrg("Org1MSP")
+	assert.NoError(t, err)
+	other, err := mspfixtures.NewOrg("Org2MSP")
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		name   string
+		cert   *x509.Certificate
+		status int
+	}{
+		{"NoCertificate", nil, http.StatusUnauthorized},
+		{"Member", org.Member.Cert, http.StatusForbidden},
+		{"OtherOrgAdmin", other.Admin.Cert, http.StatusForbidden},
+		{"Admin", org.Admin.Cert, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, server := newTestGatewayWithClient(t, org, tc.cert)
+			defer server.Close()
+			assert.Equal(t, tc.status, get(t, server, "/channels/mychannel", &cb.BlockchainInfo{}))
+			// the endpoints which do not query the ledger are served to any client
+			assert.Equal(t, http.StatusOK, get(t, server, "/health", &pb.ServerStatus{}))
+		})
+	}
+}
@@ -23,9 +23,17 @@ package cscc
 
 import (
 	"fmt"
+	"sort"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/gossip/service"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
@@ -44,6 +52,12 @@ const (
 	JoinChain         string = "JoinChain"
 	UpdateConfigBlock string = "UpdateConfigBlock"
 	GetConfigBlock    string = "GetConfigBlock"
+	GetConfigDigest   string = "GetConfigDigest"
+	GetChannelConfig  string = "GetChannelConfig"
+	GetEndorsers      string = "GetEndorsers"
+	SimulatePolicy    string = "SimulatePolicy"
+	DeactivateChain   string = "DeactivateChain"
+	ReactivateChain   string = "ReactivateChain"
 )
 
 // Init is called once per chain when the chain is created.
@@ -57,12 +71,21 @@ func (e *PeerConfiger) Init(stub shim.ChaincodeStubInterface) pb.Response {
 // Invoke is called for the following:
 // # to process joining a chain (called by app as a transaction proposal)
 // # to get the current configuration block (called by app)
+// # to get the current configuration and its sequence number (called by app)
+// # to get the peers to collect the endorsements of a chaincode from (called by app)
+// # to simulate whether identities would satisfy a channel policy (called by app)
+// # to deactivate or reactivate a chain, keeping its ledger (called by an
+// admin of the local MSP)
 // # to update the configuration block (called by commmitter)
 // Peer calls this function with 2 arguments:
-// # args[0] is the function name, which must be JoinChain, GetConfigBlock or
-// UpdateConfigBlock
+// # args[0] is the function name, which must be JoinChain, GetConfigBlock,
+// GetConfigDigest, GetChannelConfig, GetEndorsers, SimulatePolicy,
+// DeactivateChain, ReactivateChain or UpdateConfigBlock
 // # args[1] is a configuration Block if args[0] is JoinChain or
 // UpdateConfigBlock; otherwise it is the chain id
+// # args[2] is the chaincode name if args[0] is GetEndorsers, and the policy
+// name if args[0] is SimulatePolicy, in which case args[3:] are the serialized
+// identities to simulate the policy with
 // TODO: Improve the scc interface to avoid marshal/unmarshal args
 func (e *PeerConfiger) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	args := stub.GetArgs()
@@ -80,6 +103,30 @@ func (e *PeerConfiger) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		return joinChain(args[1])
 	} else if fname == GetConfigBlock {
 		return getConfigBlock(args[1])
+	} else if fname == GetConfigDigest {
+		return getConfigDigest(args[1])
+	} else if fname == GetChannelConfig {
+		return getChannelConfig(args[1])
+	} else if fname == GetEndorsers {
+		if len(args) < 3 {
+			return shim.Error(fmt.Sprintf("Incorrect number of arguments, %d", len(args)))
+		}
+		return getEndorsers(args[1], args[2])
+	} else if fname == SimulatePolicy {
+		if len(args) < 3 {
+			return shim.Error(fmt.Sprintf("Incorrect number of arguments, %d", len(args)))
+		}
+		return simulatePolicy(args[1], args[2], args[3:])
+	} else if fname == DeactivateChain {
+		if err := checkLocalAdmin(stub); err != nil {
+			return shim.Error(fmt.Sprintf("Authorization for %s failed, %s", fname, err))
+		}
+		return deactivateChain(args[1])
+	} else if fname == ReactivateChain {
+		if err := checkLocalAdmin(stub); err != nil {
+			return shim.Error(fmt.Sprintf("Authorization for %s failed, %s", fname, err))
+		}
+		return reactivateChain(args[1])
 	} else if fname == UpdateConfigBlock {
 		return updateConfigBlock(args[1])
 	}
@@ -114,6 +161,78 @@ func joinChain(blockBytes []byte) pb.Response {
 	return shim.Success(nil)
 }
 
+// checkLocalAdmin returns nil if the proposal being executed is validly signed
+// by an admin of the local MSP, as only the operators of the peer may stop or
+// resume serving one of its chains
+func checkLocalAdmin(stub shim.ChaincodeStubInterface) error {
+	signedProp, err := stub.GetSignedProposal()
+	if err != nil {
+		return err
+	}
+	if signedProp == nil {
+		return fmt.Errorf("no signed proposal")
+	}
+	prop, err := utils.GetProposal(signedProp.ProposalBytes)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the proposal, %s", err)
+	}
+	hdr, err := utils.GetHeader(prop.Header)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the header of the proposal, %s", err)
+	}
+	if hdr.SignatureHeader == nil {
+		return fmt.Errorf("the proposal has no signature header")
+	}
+
+	localMSP := mspmgmt.GetLocalMSP()
+	mspID, err := localMSP.GetIdentifier()
+	if err != nil {
+		return fmt.Errorf("failed to get the local MSP ID, %s", err)
+	}
+	id, err := localMSP.DeserializeIdentity(hdr.SignatureHeader.Creator)
+	if err != nil {
+		return fmt.Errorf("the creator is not an identity of the local MSP %s, %s", mspID, err)
+	}
+	principal := &common.MSPPrincipal{
+		PrincipalClassification: common.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&common.MSPRole{MspIdentifier: mspID, Role: common.MSPRole_ADMIN}),
+	}
+	if err = localMSP.SatisfiesPrincipal(id, principal); err != nil {
+		return fmt.Errorf("the creator is not an admin of the local MSP %s, %s", mspID, err)
+	}
+	if err = id.Verify(signedProp.ProposalBytes, signedProp.Signature); err != nil {
+		return fmt.Errorf("invalid signature of the proposal, %s", err)
+	}
+	return nil
+}
+
+// deactivateChain stops serving the specified chain, the peer neither gossips,
+// pulls, endorses nor delivers on it, but keeps its ledger until the chain is
+// reactivated
+func deactivateChain(chainID []byte) pb.Response {
+	if chainID == nil {
+		return shim.Error("ChainID must not be nil.")
+	}
+	if err := peer.DeactivateChain(string(chainID)); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+// reactivateChain serves again the specified chain from its ledger, the
+// blocks committed meanwhile are then pulled rather than the whole chain
+func reactivateChain(chainID []byte) pb.Response {
+	if chainID == nil {
+		return shim.Error("ChainID must not be nil.")
+	}
+	if err := peer.ReactivateChain(string(chainID)); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
 func updateConfigBlock(blockBytes []byte) pb.Response {
 	if blockBytes == nil {
 		return shim.Error("Configuration block must not be nil.")
@@ -151,3 +270,153 @@ func getConfigBlock(chainID []byte) pb.Response {
 
 	return shim.Success(blockBytes)
 }
+
+// Return the digest of the current configuration of the specified chainID,
+// to compare with that of the other peers and orderers of the chain. If the
+// peer doesn't belong to the chain, return error
+func getConfigDigest(chainID []byte) pb.Response {
+	if chainID == nil {
+		return shim.Error("ChainID must not be nil.")
+	}
+	digest := peer.GetConfigDigest(string(chainID))
+	if digest == nil {
+		return shim.Error(fmt.Sprintf("Unknown chain ID, %s", string(chainID)))
+	}
+
+	return shim.Success(digest)
+}
+
+// Return the current configuration of the specified chainID as a marshaled
+// ConfigSnapshot, holding the Config and its sequence number, which clients
+// build config updates from. If the peer doesn't belong to the chain, return
+// error
+func getChannelConfig(chainID []byte) pb.Response {
+	if chainID == nil {
+		return shim.Error("ChainID must not be nil.")
+	}
+	snapshot := peer.GetConfigSnapshot(string(chainID))
+	if snapshot == nil {
+		return shim.Error(fmt.Sprintf("Unknown chain ID, %s", string(chainID)))
+	}
+	snapshotBytes, err := utils.Marshal(snapshot)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(snapshotBytes)
+}
+
+// Return a marshaled EndorsementLayout listing alive peers of the specified
+// chainID whose endorsements satisfy the endorsement policy of the chaincode,
+// this peer first if it endorses. The organizations of the other peers are
+// known from their identities gossiped. If the peer doesn't belong to the
+// chain or the chaincode isn't instantiated on it, return error
+func getEndorsers(chainID []byte, ccName []byte) pb.Response {
+	if chainID == nil {
+		return shim.Error("ChainID must not be nil.")
+	}
+	lgr := peer.GetLedger(string(chainID))
+	if lgr == nil {
+		return shim.Error(fmt.Sprintf("Unknown chain ID, %s", string(chainID)))
+	}
+
+	qe, err := lgr.NewQueryExecutor()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get the query executor of chain %s, %s", string(chainID), err))
+	}
+	cdBytes, err := qe.GetState("lccc", string(ccName))
+	qe.Done()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get the chaincode data of %s, %s", string(ccName), err))
+	}
+	if cdBytes == nil {
+		return shim.Error(fmt.Sprintf("Chaincode %s is not instantiated on chain %s", string(ccName), string(chainID)))
+	}
+	cd := &ccprovider.ChaincodeData{}
+	if err = proto.Unmarshal(cdBytes, cd); err != nil {
+		return shim.Error(fmt.Sprintf("Failed to unmarshal the chaincode data of %s, %s", string(ccName), err))
+	}
+	policy := &common.SignaturePolicyEnvelope{}
+	if err = proto.Unmarshal(cd.Policy, policy); err != nil {
+		return shim.Error(fmt.Sprintf("Failed to unmarshal the endorsement policy of %s, %s", string(ccName), err))
+	}
+
+	endorsers := service.GetGossipService().EndorsersOfChannel(string(chainID))
+	if !peer.IsReadOnly() {
+		mspID, err := mspmgmt.GetLocalMSP().GetIdentifier()
+		if err != nil {
+			return shim.Error(fmt.Sprintf("Failed to get the local MSP ID, %s", err))
+		}
+		endpoint, err := peer.GetPeerEndpoint()
+		if err != nil {
+			return shim.Error(fmt.Sprintf("Failed to get the peer endpoint, %s", err))
+		}
+		endorsers[mspID] = append([]string{endpoint.Address}, endorsers[mspID]...)
+	}
+
+	available := make(map[string]int)
+	for mspID, endpoints := range endorsers {
+		available[mspID] = len(endpoints)
+	}
+	counts, err := cauthdsl.SatisfyingLayout(policy, available)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to find endorsers for %s, %s", string(ccName), err))
+	}
+
+	mspIDs := make([]string, 0, len(counts))
+	for mspID := range counts {
+		mspIDs = append(mspIDs, mspID)
+	}
+	sort.Strings(mspIDs)
+	layout := &pb.EndorsementLayout{}
+	for _, mspID := range mspIDs {
+		for _, endpoint := range endorsers[mspID][:counts[mspID]] {
+			layout.Endorsers = append(layout.Endorsers, &pb.EndorsingPeer{Endpoint: endpoint, MspId: mspID})
+		}
+	}
+	layoutBytes, err := utils.Marshal(layout)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(layoutBytes)
+}
+
+// Return a marshaled PolicySimulation reporting whether the signatures of the
+// given serialized identities would satisfy the signature policy of the
+// specified chainID with the given name, and which branches of the policy
+// they satisfy. The policy name is either a path such as
+// Application/Org1/Admins or a key found once in the configuration. If the
+// peer doesn't belong to the chain or the policy isn't found, return error
+func simulatePolicy(chainID []byte, policyName []byte, identities [][]byte) pb.Response {
+	if chainID == nil {
+		return shim.Error("ChainID must not be nil.")
+	}
+	snapshot := peer.GetConfigSnapshot(string(chainID))
+	if snapshot == nil {
+		return shim.Error(fmt.Sprintf("Unknown chain ID, %s", string(chainID)))
+	}
+
+	policy, err := configtx.FindPolicy(snapshot.Config, string(policyName))
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to find policy %s, %s", string(policyName), err))
+	}
+	if policy.Type != int32(common.Policy_SIGNATURE) {
+		return shim.Error(fmt.Sprintf("Policy %s is not a signature policy and cannot be simulated", string(policyName)))
+	}
+	sigPolicy := &common.SignaturePolicyEnvelope{}
+	if err = proto.Unmarshal(policy.Policy, sigPolicy); err != nil {
+		return shim.Error(fmt.Sprintf("Failed to unmarshal policy %s, %s", string(policyName), err))
+	}
+
+	simulation, err := cauthdsl.Simulate(sigPolicy, identities, mspmgmt.GetManagerForChain(string(chainID)))
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to simulate policy %s, %s", string(policyName), err))
+	}
+	simulationBytes, err := utils.Marshal(simulation)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(simulationBytes)
+}
@@ -51,6 +51,12 @@ func (*mockDeliveryClient) JoinChain(chainID string, ledgerInfo blocksprovider.L
 	return nil
 }
 
+// LeaveChain stops pulling the blocks of the given chain from the
+// ordering service
+func (*mockDeliveryClient) LeaveChain(chainID string) error {
+	return nil
+}
+
 // Stop terminates delivery service and closes the connection
 func (*mockDeliveryClient) Stop() {
 
@@ -211,6 +217,117 @@ func TestConfigerInvokeUpdateConfigBlock(t *testing.T) {
 
 }
 
+func TestConfigerInvokeGetConfigDigest(t *testing.T) {
+	e := new(PeerConfiger)
+	stub := shim.NewMockStub("PeerConfiger", e)
+
+	// Failed path: Not enough parameters
+	args := [][]byte{[]byte("GetConfigDigest")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke GetConfigDigest should have failed with invalid number of args: %v", args)
+	}
+
+	// Failed path: the peer did not join the chain
+	args = [][]byte{[]byte("GetConfigDigest"), []byte("unknownchain")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke GetConfigDigest should have failed for an unknown chain")
+	}
+}
+
+func TestConfigerInvokeGetChannelConfig(t *testing.T) {
+	e := new(PeerConfiger)
+	stub := shim.NewMockStub("PeerConfiger", e)
+
+	// Failed path: Not enough parameters
+	args := [][]byte{[]byte("GetChannelConfig")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke GetChannelConfig should have failed with invalid number of args: %v", args)
+	}
+
+	// Failed path: the peer did not join the chain
+	args = [][]byte{[]byte("GetChannelConfig"), []byte("unknownchain")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke GetChannelConfig should have failed for an unknown chain")
+	}
+}
+
+func TestConfigerInvokeGetEndorsers(t *testing.T) {
+	e := new(PeerConfiger)
+	stub := shim.NewMockStub("PeerConfiger", e)
+
+	// Failed path: Not enough parameters
+	args := [][]byte{[]byte("GetEndorsers"), []byte("unknownchain")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke GetEndorsers should have failed with invalid number of args: %v", args)
+	}
+
+	// Failed path: the peer did not join the chain
+	args = [][]byte{[]byte("GetEndorsers"), []byte("unknownchain"), []byte("mycc")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke GetEndorsers should have failed for an unknown chain")
+	}
+}
+
+func TestConfigerInvokeSimulatePolicy(t *testing.T) {
+	e := new(PeerConfiger)
+	stub := shim.NewMockStub("PeerConfiger", e)
+
+	// Failed path: Not enough parameters
+	args := [][]byte{[]byte("SimulatePolicy"), []byte("unknownchain")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke SimulatePolicy should have failed with invalid number of args: %v", args)
+	}
+
+	// Failed path: the peer did not join the chain
+	args = [][]byte{[]byte("SimulatePolicy"), []byte("unknownchain"), []byte("Admins"), []byte("identity")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke SimulatePolicy should have failed for an unknown chain")
+	}
+}
+
+func TestConfigerInvokeDeactivateChain(t *testing.T) {
+	e := new(PeerConfiger)
+	stub := shim.NewMockStub("PeerConfiger", e)
+
+	for _, fname := range []string{"DeactivateChain", "ReactivateChain"} {
+		// Failed path: Not enough parameters
+		args := [][]byte{[]byte(fname)}
+		if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+			t.Fatalf("cscc invoke %s should have failed with invalid number of args: %v", fname, args)
+		}
+
+		// Failed path: the proposal is not signed by an admin of the local MSP
+		args = [][]byte{[]byte(fname), []byte("unknownchain")}
+		if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+			t.Fatalf("cscc invoke %s should have failed without a signed proposal", fname)
+		}
+	}
+}
+
+func TestCheckLocalAdmin(t *testing.T) {
+	msptesttools.LoadMSPSetupForTesting("../../../msp/sampleconfig")
+	stub := shim.NewMockStub("PeerConfiger", new(PeerConfiger))
+
+	assert.Error(t, checkLocalAdmin(stub), "A proposal without signature should have been refused")
+
+	// The local signing identity of the sample config is not one of its admins
+	signer := mgmt.GetLocalSigningIdentityOrPanic()
+	creator, err := signer.Serialize()
+	assert.NoError(t, err)
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{Name: "cscc"},
+		Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte(DeactivateChain), []byte("mytestchainid")}},
+	}}
+	prop, err := utils.CreateProposalFromCIS("txid", common.HeaderType_ENDORSER_TRANSACTION, "", cis, creator)
+	assert.NoError(t, err)
+	stub.SignedProposal, err = utils.GetSignedProposal(prop, signer)
+	assert.NoError(t, err)
+	err = checkLocalAdmin(stub)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not an admin")
+	}
+}
+
 func mockConfigBlock() []byte {
 	var blockBytes []byte
 	block, err := configtxtest.MakeGenesisBlock("mytestchainid")
@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
+	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
@@ -185,19 +186,33 @@ func (f EmptyVersionErr) Error() string {
 	return fmt.Sprintf("version not provided for chaincode %s", string(f))
 }
 
+//InvalidInvocationPolicyErr invalid invocation policy error
+type InvalidInvocationPolicyErr string
+
+func (f InvalidInvocationPolicyErr) Error() string {
+	return fmt.Sprintf("invalid invocation policy(%s)", string(f))
+}
+
+//InvalidInitArgsErr invalid instantiation arguments error
+type InvalidInitArgsErr string
+
+func (f InvalidInitArgsErr) Error() string {
+	return fmt.Sprintf("invalid init arguments(%s)", string(f))
+}
+
 //-------------- helper functions ------------------
 //create the chaincode on the given chain
-func (lccc *LifeCycleSysCC) createChaincode(stub shim.ChaincodeStubInterface, chainname string, ccname string, version string, cccode []byte, policy []byte, escc []byte, vscc []byte) (*ccprovider.ChaincodeData, error) {
-	return lccc.putChaincodeData(stub, chainname, ccname, version, cccode, policy, escc, vscc)
+func (lccc *LifeCycleSysCC) createChaincode(stub shim.ChaincodeStubInterface, chainname string, ccname string, version string, cccode []byte, policy []byte, escc []byte, vscc []byte, invocationPolicy []byte) (*ccprovider.ChaincodeData, error) {
+	return lccc.putChaincodeData(stub, chainname, ccname, version, cccode, policy, escc, vscc, invocationPolicy)
 }
 
 //upgrade the chaincode on the given chain
-func (lccc *LifeCycleSysCC) upgradeChaincode(stub shim.ChaincodeStubInterface, chainname string, ccname string, version string, cccode []byte, policy []byte, escc []byte, vscc []byte) (*ccprovider.ChaincodeData, error) {
-	return lccc.putChaincodeData(stub, chainname, ccname, version, cccode, policy, escc, vscc)
+func (lccc *LifeCycleSysCC) upgradeChaincode(stub shim.ChaincodeStubInterface, chainname string, ccname string, version string, cccode []byte, policy []byte, escc []byte, vscc []byte, invocationPolicy []byte) (*ccprovider.ChaincodeData, error) {
+	return lccc.putChaincodeData(stub, chainname, ccname, version, cccode, policy, escc, vscc, invocationPolicy)
 }
 
 //create the chaincode on the given chain
-func (lccc *LifeCycleSysCC) putChaincodeData(stub shim.ChaincodeStubInterface, chainname string, ccname string, version string, cccode []byte, policy []byte, escc []byte, vscc []byte) (*ccprovider.ChaincodeData, error) {
+func (lccc *LifeCycleSysCC) putChaincodeData(stub shim.ChaincodeStubInterface, chainname string, ccname string, version string, cccode []byte, policy []byte, escc []byte, vscc []byte, invocationPolicy []byte) (*ccprovider.ChaincodeData, error) {
 	// check that escc and vscc are real system chaincodes
 	if !lccc.sccprovider.IsSysCC(string(escc)) {
 		return nil, fmt.Errorf("%s is not a valid endorsement system chaincode", string(escc))
@@ -206,7 +221,16 @@ func (lccc *LifeCycleSysCC) putChaincodeData(stub shim.ChaincodeStubInterface, c
 		return nil, fmt.Errorf("%s is not a valid validation system chaincode", string(vscc))
 	}
 
-	cd := &ccprovider.ChaincodeData{Name: ccname, Version: version, DepSpec: cccode, Policy: policy, Escc: string(escc), Vscc: string(vscc)}
+	// the endorsers refuse the invocations of a chaincode whose invocation
+	// policy does not parse, so that it is checked here
+	if invocationPolicy != nil {
+		if err := proto.Unmarshal(invocationPolicy, &common.SignaturePolicyEnvelope{}); err != nil {
+			return nil, InvalidInvocationPolicyErr(err.Error())
+		}
+	}
+
+	cd := &ccprovider.ChaincodeData{Name: ccname, Version: version, DepSpec: cccode, Policy: policy, Escc: string(escc), Vscc: string(vscc),
+		InvocationPolicy: invocationPolicy}
 	cdbytes, err := proto.Marshal(cd)
 	if err != nil {
 		return nil, err
@@ -289,6 +313,27 @@ func (lccc *LifeCycleSysCC) isValidChaincodeName(chaincodename string) bool {
 	return true
 }
 
+//validates the Init arguments of the deployment spec against the schema declared
+//in the package of the given version of the chaincode installed on this peer, so
+//that malformed arguments are rejected before the chaincode is initialized
+func (lccc *LifeCycleSysCC) validateInitArgs(cds *pb.ChaincodeDeploymentSpec, version string) error {
+	_, installedCDS, err := ccprovider.GetChaincodeFromFS(cds.ChaincodeSpec.ChaincodeId.Name, version)
+	if err != nil {
+		// the chaincode cannot be launched on this peer anyway
+		logger.Debugf("Init arguments of chaincode %s:%s not validated, package not installed(%s)", cds.ChaincodeSpec.ChaincodeId.Name, version, err)
+		return nil
+	}
+
+	var args [][]byte
+	if cds.ChaincodeSpec.Input != nil {
+		args = cds.ChaincodeSpec.Input.Args
+	}
+	if err = ccprovider.ValidateInitArgs(installedCDS.InitArgsSchema, args); err != nil {
+		return InvalidInitArgsErr(err.Error())
+	}
+	return nil
+}
+
 //this implements "install" Invoke transaction
 func (lccc *LifeCycleSysCC) executeInstall(stub shim.ChaincodeStubInterface, depSpec []byte) error {
 	cds, err := lccc.getChaincodeDeploymentSpec(depSpec)
@@ -305,6 +350,10 @@ func (lccc *LifeCycleSysCC) executeInstall(stub shim.ChaincodeStubInterface, dep
 		return EmptyVersionErr(cds.ChaincodeSpec.ChaincodeId.Name)
 	}
 
+	if err = ccprovider.CheckInitArgsSchema(cds.InitArgsSchema); err != nil {
+		return InvalidDeploymentSpecErr(err.Error())
+	}
+
 	if err = ccprovider.PutChaincodeIntoFS(cds); err != nil {
 		return fmt.Errorf("Error installing chaincode code %s:%s(%s)", cds.ChaincodeSpec.ChaincodeId.Name, cds.ChaincodeSpec.ChaincodeId.Version, err)
 	}
@@ -313,7 +362,7 @@ func (lccc *LifeCycleSysCC) executeInstall(stub shim.ChaincodeStubInterface, dep
 }
 
 //this implements "deploy" Invoke transaction
-func (lccc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chainname string, depSpec []byte, policy []byte, escc []byte, vscc []byte) error {
+func (lccc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chainname string, depSpec []byte, policy []byte, escc []byte, vscc []byte, invocationPolicy []byte) error {
 	cds, err := lccc.getChaincodeDeploymentSpec(depSpec)
 
 	if err != nil {
@@ -337,7 +386,11 @@ func (lccc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chai
 		return EmptyVersionErr(cds.ChaincodeSpec.ChaincodeId.Name)
 	}
 
-	_, err = lccc.createChaincode(stub, chainname, cds.ChaincodeSpec.ChaincodeId.Name, cds.ChaincodeSpec.ChaincodeId.Version, depSpec, policy, escc, vscc)
+	if err = lccc.validateInitArgs(cds, cds.ChaincodeSpec.ChaincodeId.Version); err != nil {
+		return err
+	}
+
+	_, err = lccc.createChaincode(stub, chainname, cds.ChaincodeSpec.ChaincodeId.Name, cds.ChaincodeSpec.ChaincodeId.Version, depSpec, policy, escc, vscc, invocationPolicy)
 
 	return err
 }
@@ -367,7 +420,7 @@ func (lccc *LifeCycleSysCC) getUpgradeVersion(cd *ccprovider.ChaincodeData, cds
 }
 
 //this implements "upgrade" Invoke transaction
-func (lccc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, chainName string, depSpec []byte, policy []byte, escc []byte, vscc []byte) ([]byte, error) {
+func (lccc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, chainName string, depSpec []byte, policy []byte, escc []byte, vscc []byte, invocationPolicy []byte) ([]byte, error) {
 	cds, err := lccc.getChaincodeDeploymentSpec(depSpec)
 	if err != nil {
 		return nil, err
@@ -393,7 +446,11 @@ func (lccc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, cha
 		return nil, err
 	}
 
-	newCD, err := lccc.upgradeChaincode(stub, chainName, chaincodeName, ver, depSpec, policy, escc, vscc)
+	if err = lccc.validateInitArgs(cds, ver); err != nil {
+		return nil, err
+	}
+
+	newCD, err := lccc.upgradeChaincode(stub, chainName, chaincodeName, ver, depSpec, policy, escc, vscc, invocationPolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -436,7 +493,7 @@ func (lccc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		}
 		return shim.Success([]byte("OK"))
 	case DEPLOY:
-		if len(args) < 3 || len(args) > 6 {
+		if len(args) < 3 || len(args) > 7 {
 			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
@@ -454,6 +511,7 @@ func (lccc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		// args[3] is a marshalled SignaturePolicyEnvelope representing the endorsement policy
 		// args[4] is the name of escc
 		// args[5] is the name of vscc
+		// args[6] is a marshalled SignaturePolicyEnvelope the creators of the invocations must satisfy
 		var policy []byte
 		if len(args) > 3 && args[3] != nil {
 			policy = args[3]
@@ -476,13 +534,18 @@ func (lccc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 			vscc = []byte("vscc")
 		}
 
-		err := lccc.executeDeploy(stub, chainname, depSpec, policy, escc, vscc)
+		var invocationPolicy []byte
+		if len(args) > 6 {
+			invocationPolicy = args[6]
+		}
+
+		err := lccc.executeDeploy(stub, chainname, depSpec, policy, escc, vscc, invocationPolicy)
 		if err != nil {
 			return shim.Error(err.Error())
 		}
 		return shim.Success(nil)
 	case UPGRADE:
-		if len(args) < 3 || len(args) > 6 {
+		if len(args) < 3 || len(args) > 7 {
 			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
@@ -497,6 +560,7 @@ func (lccc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		// args[3] is a marshalled SignaturePolicyEnvelope representing the endorsement policy
 		// args[4] is the name of escc
 		// args[5] is the name of vscc
+		// args[6] is a marshalled SignaturePolicyEnvelope the creators of the invocations must satisfy
 		var policy []byte
 		if len(args) > 3 && args[3] != nil {
 			policy = args[3]
@@ -519,7 +583,14 @@ func (lccc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 			vscc = []byte("vscc")
 		}
 
-		verBytes, err := lccc.executeUpgrade(stub, chainname, depSpec, policy, escc, vscc)
+		// the invocation policy is replaced as the endorsement policy is, an
+		// upgrade without invocation policy letting anyone invoke the chaincode
+		var invocationPolicy []byte
+		if len(args) > 6 {
+			invocationPolicy = args[6]
+		}
+
+		verBytes, err := lccc.executeUpgrade(stub, chainname, depSpec, policy, escc, vscc, invocationPolicy)
 		if err != nil {
 			return shim.Error(err.Error())
 		}
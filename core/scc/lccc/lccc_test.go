@@ -16,12 +16,14 @@ limitations under the License.
 package lccc
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 
 	"os"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
@@ -93,6 +95,126 @@ func TestDeploy(t *testing.T) {
 	}
 }
 
+//TestDeployWithInvocationPolicy tests that the invocation policy is stored
+//along with the chaincode and that an invalid one is rejected
+func TestDeployWithInvocationPolicy(t *testing.T) {
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lccc", scc)
+
+	if res := stub.MockInit("1", nil); res.Status != shim.OK {
+		fmt.Println("Init failed", string(res.Message))
+		t.FailNow()
+	}
+
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
+	defer os.Remove(lccctestpath + "/example02.0")
+	var b []byte
+	if b, err = proto.Marshal(cds); err != nil || b == nil {
+		t.FailNow()
+	}
+
+	args := [][]byte{[]byte(DEPLOY), []byte("test"), b, nil, nil, nil, []byte("not a policy")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("Deploy with an invalid invocation policy should have failed")
+	}
+
+	invocationPolicy, err := proto.Marshal(cauthdsl.SignedByMspMember("Org1MSP"))
+	if err != nil {
+		t.Fatalf("Marshal invocation policy failed: %s", err)
+	}
+	args = [][]byte{[]byte(DEPLOY), []byte("test"), b, nil, nil, nil, invocationPolicy}
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("Deploy chaincode error: %s", res.Message)
+	}
+
+	args = [][]byte{[]byte(GETCCDATA), []byte("test"), []byte(cds.ChaincodeSpec.ChaincodeId.Name)}
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		t.Fatalf("GETCCDATA error: %s", res.Message)
+	}
+	cd := &ccprovider.ChaincodeData{}
+	if err = proto.Unmarshal(res.Payload, cd); err != nil {
+		t.Fatalf("Unmarshal ChaincodeData failed: %s", err)
+	}
+	if !bytes.Equal(cd.InvocationPolicy, invocationPolicy) {
+		t.Fatalf("Invocation policy not stored")
+	}
+}
+
+//TestDeployWithInitArgsSchema tests that the init arguments are validated
+//against the schema of the installed package
+func TestDeployWithInitArgsSchema(t *testing.T) {
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lccc", scc)
+
+	if res := stub.MockInit("1", nil); res.Status != shim.OK {
+		fmt.Println("Init failed", string(res.Message))
+		t.FailNow()
+	}
+
+	schema := &pb.ChaincodeInitArgsSchema{Args: []*pb.ChaincodeArgSchema{
+		{Name: "a", Pattern: "[a-z]+"},
+		{Name: "aval", Type: pb.ChaincodeArgSchema_INTEGER},
+		{Name: "b", Optional: true},
+		{Name: "bval", Type: pb.ChaincodeArgSchema_INTEGER, Optional: true},
+	}}
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", nil, false)
+	cds.InitArgsSchema = schema
+	if err = ccprovider.PutChaincodeIntoFS(cds); err != nil {
+		t.Fatalf("Install failed: %s", err)
+	}
+	defer os.Remove(lccctestpath + "/example02.0")
+
+	deploy := func(initArgs ...string) pb.Response {
+		spec := &pb.ChaincodeSpec{Type: 1, ChaincodeId: cds.ChaincodeSpec.ChaincodeId, Input: &pb.ChaincodeInput{Args: [][]byte{[]byte("init")}}}
+		for _, arg := range initArgs {
+			spec.Input.Args = append(spec.Input.Args, []byte(arg))
+		}
+		b, err := proto.Marshal(&pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec})
+		if err != nil {
+			t.Fatalf("Marshal DeploymentSpec failed")
+		}
+		return stub.MockInvoke("1", [][]byte{[]byte(DEPLOY), []byte("test"), b})
+	}
+
+	for _, initArgs := range [][]string{{"a"}, {"A", "100"}, {"a", "one"}, {"a", "100", "b", "two"}, {"a", "100", "b", "200", "c"}} {
+		if res := deploy(initArgs...); res.Status == shim.OK {
+			t.Fatalf("Deploy with init arguments %v should have failed", initArgs)
+		}
+	}
+	if res := deploy("a", "100", "b"); res.Status != shim.OK {
+		t.Fatalf("Deploy chaincode error: %s", res.Message)
+	}
+}
+
+//TestInstallWithInvalidInitArgsSchema tests that a package declaring an invalid
+//schema of its init arguments is not installed
+func TestInstallWithInvalidInitArgsSchema(t *testing.T) {
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lccc", scc)
+
+	if res := stub.MockInit("1", nil); res.Status != shim.OK {
+		fmt.Println("Init failed", string(res.Message))
+		t.FailNow()
+	}
+
+	for _, schema := range []*pb.ChaincodeInitArgsSchema{
+		{Args: []*pb.ChaincodeArgSchema{{Name: "a", Optional: true}, {Name: "aval"}}},
+		{Args: []*pb.ChaincodeArgSchema{{Name: "a", Pattern: "[a-z"}}},
+	} {
+		cds, _ := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", nil, false)
+		cds.InitArgsSchema = schema
+		b, err := proto.Marshal(cds)
+		if err != nil {
+			t.Fatalf("Marshal DeploymentSpec failed")
+		}
+		if res := stub.MockInvoke("1", [][]byte{[]byte(INSTALL), b}); res.Status == shim.OK {
+			os.Remove(lccctestpath + "/example02.0")
+			t.Fatalf("Install with the invalid init arguments schema %v should have failed", schema)
+		}
+	}
+}
+
 //TestInstall tests the install function
 func TestInstall(t *testing.T) {
 	scc := new(LifeCycleSysCC)
@@ -17,6 +17,7 @@ limitations under the License.
 package qscc
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 
@@ -25,6 +26,8 @@ import (
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/core/replay"
+	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -34,6 +37,13 @@ import (
 // - GetBlockByNumber returns a block
 // - GetBlockByHash returns a block
 // - GetTransactionByID returns a transaction
+// - GetStateAtHeight returns the values keys had at a block height
+// - PurgeState erases the values of keys from the local storage of the peer
+// - ReplayTransaction executes a transaction again and reports whether it is reproducible
+// - GetCommitHash returns the commit hash the peer recorded in a block
+// - GetConfigBlocks returns the sequence and block numbers of the config blocks
+// - GetMVCCConflicts returns the MVCC conflicts which invalidated transactions of a block
+// - GetTxMVCCConflict returns the MVCC conflict which invalidated a transaction
 type LedgerQuerier struct {
 }
 
@@ -46,6 +56,13 @@ const (
 	GetBlockByHash     string = "GetBlockByHash"
 	GetTransactionByID string = "GetTransactionByID"
 	GetBlockByTxID     string = "GetBlockByTxID"
+	GetStateAtHeight   string = "GetStateAtHeight"
+	PurgeState         string = "PurgeState"
+	ReplayTransaction  string = "ReplayTransaction"
+	GetCommitHash      string = "GetCommitHash"
+	GetConfigBlocks    string = "GetConfigBlocks"
+	GetMVCCConflicts   string = "GetMVCCConflicts"
+	GetTxMVCCConflict  string = "GetTxMVCCConflict"
 )
 
 // Init is called once per chain when the chain is created.
@@ -63,7 +80,31 @@ func (e *LedgerQuerier) Init(stub shim.ChaincodeStubInterface) pb.Response {
 // # GetChainInfo: Return a BlockchainInfo object marshalled in bytes
 // # GetBlockByNumber: Return the block specified by block number in args[2]
 // # GetBlockByHash: Return the block specified by block hash in args[2]
-// # GetTransactionByID: Return the transaction specified by ID in args[2]
+// # GetBlockByTxID: Return the block containing the transaction of ID args[2]
+//   The blocks are redacted, with the message RedactedView, unless the creator
+//   satisfies the FullReaders policy of the channel, if it has one
+// # GetTransactionByID: Return the transaction specified by ID in args[2], as a
+//   RedactedTransaction with the message RedactedView unless the creator
+//   satisfies the FullReaders policy of the channel, if it has one
+// # GetStateAtHeight: Return a QueryStateResponse with the values the keys in
+//   args[4:] of the namespace in args[3] had at the block height in args[2],
+//   if the creator may read transactions in full
+// # PurgeState: Erase from the local storage of the peer the values the keys in
+//   args[3:] of the namespace in args[2] have had so far, if the creator is one
+//   of the purgers set in peer.qscc.purgers
+// # ReplayTransaction: Return the replay.Report, in JSON, of the execution of the
+//   transaction of ID args[2] against the state versions it read, if the creator
+//   may read transactions in full
+// # GetCommitHash: Return the commit hash recorded by the peer, if it computes
+//   them, in the metadata of the block specified by block number in args[2]
+// # GetConfigBlocks: Return a ConfigBlocksInfo object marshalled in bytes listing
+//   the config blocks of the chain by sequence number
+// # GetMVCCConflicts: Return an MVCCConflicts object marshalled in bytes listing
+//   the key and the committing transaction each transaction of the block
+//   specified by block number in args[2] invalidated by an MVCC conflict read
+// # GetTxMVCCConflict: Return the MVCCConflict object marshalled in bytes which
+//   invalidated the transaction specified by ID in args[2], so that clients can
+//   retry it once the conflicting transaction is known
 func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	args := stub.GetArgs()
 
@@ -73,7 +114,7 @@ func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	fname := string(args[0])
 	cid := string(args[1])
 
-	if fname != GetChainInfo && len(args) < 3 {
+	if fname != GetChainInfo && fname != GetConfigBlocks && len(args) < 3 {
 		return shim.Error(fmt.Sprintf("missing 3rd argument for %s", fname))
 	}
 
@@ -89,30 +130,63 @@ func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 
 	switch fname {
 	case GetTransactionByID:
-		return getTransactionByID(targetLedger, args[2])
+		return getTransactionByID(stub, cid, targetLedger, args[2])
 	case GetBlockByNumber:
-		return getBlockByNumber(targetLedger, args[2])
+		return getBlockByNumber(stub, cid, targetLedger, args[2])
 	case GetBlockByHash:
-		return getBlockByHash(targetLedger, args[2])
+		return getBlockByHash(stub, cid, targetLedger, args[2])
 	case GetChainInfo:
 		return getChainInfo(targetLedger)
 	case GetBlockByTxID:
-		return getBlockByTxID(targetLedger, args[2])
+		return getBlockByTxID(stub, cid, targetLedger, args[2])
+	case GetStateAtHeight:
+		return getStateAtHeight(stub, cid, targetLedger, args[2:])
+	case PurgeState:
+		return purgeState(stub, cid, targetLedger, args[2:])
+	case ReplayTransaction:
+		return replayTransaction(stub, cid, targetLedger, args[2])
+	case GetCommitHash:
+		return getCommitHash(targetLedger, args[2])
+	case GetConfigBlocks:
+		return getConfigBlocks(targetLedger)
+	case GetMVCCConflicts:
+		return getMVCCConflicts(targetLedger, args[2])
+	case GetTxMVCCConflict:
+		return getTxMVCCConflict(targetLedger, args[2])
 	}
 
 	return shim.Error(fmt.Sprintf("Requested function %s not found.", fname))
 }
 
-func getTransactionByID(vledger ledger.PeerLedger, tid []byte) pb.Response {
+// getTransactionByID returns the full transaction to the identities allowed to
+// read it in full, and a redacted view of it to the other members of the channel
+func getTransactionByID(stub shim.ChaincodeStubInterface, cid string, vledger ledger.PeerLedger, tid []byte) pb.Response {
 	if tid == nil {
 		return shim.Error("Transaction ID must not be nil.")
 	}
 
+	full, err := fullReader(stub, cid)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Access denied to transaction %s, error %s", string(tid), err))
+	}
+
 	processedTran, err := vledger.GetTransactionByID(string(tid))
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get transaction with id %s, error %s", string(tid), err))
 	}
 
+	if !full {
+		redacted, err := redactTransaction(processedTran)
+		if err != nil {
+			return shim.Error(fmt.Sprintf("Failed to redact transaction with id %s, error %s", string(tid), err))
+		}
+		bytes, err := utils.Marshal(redacted)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return pb.Response{Status: shim.OK, Message: RedactedView, Payload: bytes}
+	}
+
 	bytes, err := utils.Marshal(processedTran)
 	if err != nil {
 		return shim.Error(err.Error())
@@ -121,7 +195,108 @@ func getTransactionByID(vledger ledger.PeerLedger, tid []byte) pb.Response {
 	return shim.Success(bytes)
 }
 
-func getBlockByNumber(vledger ledger.PeerLedger, number []byte) pb.Response {
+// getStateAtHeight returns the values of keys at a height to the identities
+// allowed to read transactions in full, as the values are not redacted
+func getStateAtHeight(stub shim.ChaincodeStubInterface, cid string, vledger ledger.PeerLedger, args [][]byte) pb.Response {
+	if len(args) < 3 {
+		return shim.Error("Height, namespace and at least one key must be provided.")
+	}
+	height, err := strconv.ParseUint(string(args[0]), 10, 64)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to parse height with error %s", err))
+	}
+	namespace := string(args[1])
+	var keys []string
+	for _, key := range args[2:] {
+		keys = append(keys, string(key))
+	}
+
+	if full, err := fullReader(stub, cid); err != nil || !full {
+		return shim.Error(fmt.Sprintf("Access denied to the state of namespace %s, error %v", namespace, err))
+	}
+
+	qe, err := vledger.NewHistoryQueryExecutor()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get history query executor, error %s", err))
+	}
+	values, err := qe.GetStateMultipleKeysAtHeight(namespace, keys, height)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get the state of namespace %s at height %d, error %s", namespace, height, err))
+	}
+
+	resp := &pb.QueryStateResponse{}
+	for i, key := range keys {
+		resp.KeysAndValues = append(resp.KeysAndValues, &pb.QueryStateKeyValue{Key: key, Value: values[i]})
+	}
+	bytes, err := utils.Marshal(resp)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
+
+// replayTransaction executes a transaction again against the state versions it
+// read, which discloses them, hence the restriction to the full readers
+func replayTransaction(stub shim.ChaincodeStubInterface, cid string, vledger ledger.PeerLedger, tid []byte) pb.Response {
+	if len(tid) == 0 {
+		return shim.Error("Transaction ID must not be empty.")
+	}
+
+	if full, err := fullReader(stub, cid); err != nil || !full {
+		return shim.Error(fmt.Sprintf("Access denied to the replay of transaction %s, error %v", tid, err))
+	}
+
+	report, err := replay.Replay(vledger, cid, string(tid), replay.ChaincodeExecutor)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to replay transaction %s, error %s", tid, err))
+	}
+	bytes, err := json.Marshal(report)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
+
+// purgeState erases the values of keys from the local storage of the peer.
+// Purging is disabled unless peer.qscc.purgers is set, and only the identities
+// it lists may purge
+func purgeState(stub shim.ChaincodeStubInterface, cid string, vledger ledger.PeerLedger, args [][]byte) pb.Response {
+	if len(args) < 2 {
+		return shim.Error("Namespace and at least one key must be provided.")
+	}
+	namespace := string(args[0])
+	var keys []string
+	for _, key := range args[1:] {
+		keys = append(keys, string(key))
+	}
+
+	principals, err := configuredPrincipals("peer.qscc.purgers")
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if len(principals) == 0 {
+		return shim.Error("Purging is disabled, peer.qscc.purgers is not set")
+	}
+	creator, err := stub.GetCreator()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get the creator of the purge, error %s", err))
+	}
+	allowed, err := satisfiesPrincipals(peer.GetMSPMgr(cid), creator, principals)
+	if err != nil || !allowed {
+		return shim.Error(fmt.Sprintf("Access denied to purging the state of namespace %s, error %v", namespace, err))
+	}
+
+	if err = vledger.PurgeState(namespace, keys); err != nil {
+		return shim.Error(fmt.Sprintf("Failed to purge the state of namespace %s, error %s", namespace, err))
+	}
+	qscclogger.Infof("Purged %d keys of namespace %s on chain %s", len(keys), namespace, cid)
+
+	return shim.Success(nil)
+}
+
+func getBlockByNumber(stub shim.ChaincodeStubInterface, cid string, vledger ledger.PeerLedger, number []byte) pb.Response {
 	if number == nil {
 		return shim.Error("Block number must not be nil.")
 	}
@@ -133,10 +308,31 @@ func getBlockByNumber(vledger ledger.PeerLedger, number []byte) pb.Response {
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get block number %d, error %s", bnum, err))
 	}
-	// TODO: consider trim block content before returning
-	//  Specifically, trim transaction 'data' out of the transaction array Payloads
-	//  This will preserve the transaction Payload header,
-	//  and client can do GetTransactionByID() if they want the full transaction details
+
+	return blockResponse(stub, cid, block)
+}
+
+// blockResponse returns block to the identities allowed to read transactions
+// in full, and block stripped of the data of its transactions to the other
+// members of the channel, which can get the transactions they may read with
+// GetTransactionByID
+func blockResponse(stub shim.ChaincodeStubInterface, cid string, block *common.Block) pb.Response {
+	full, err := fullReader(stub, cid)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Access denied to block number %d, error %s", block.Header.Number, err))
+	}
+
+	if !full {
+		redacted, err := redactBlock(block)
+		if err != nil {
+			return shim.Error(fmt.Sprintf("Failed to redact block number %d, error %s", block.Header.Number, err))
+		}
+		bytes, err := utils.Marshal(redacted)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return pb.Response{Status: shim.OK, Message: RedactedView, Payload: bytes}
+	}
 
 	bytes, err := utils.Marshal(block)
 	if err != nil {
@@ -146,20 +342,50 @@ func getBlockByNumber(vledger ledger.PeerLedger, number []byte) pb.Response {
 	return shim.Success(bytes)
 }
 
-func getBlockByHash(vledger ledger.PeerLedger, hash []byte) pb.Response {
-	if hash == nil {
-		return shim.Error("Block hash must not be nil.")
+// getCommitHash returns the commit hash of a block, which the peers of the
+// channel committing the same state record identically
+func getCommitHash(vledger ledger.PeerLedger, number []byte) pb.Response {
+	if number == nil {
+		return shim.Error("Block number must not be nil.")
 	}
-	block, err := vledger.GetBlockByHash(hash)
+	bnum, err := strconv.ParseUint(string(number), 10, 64)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get block hash %s, error %s", string(hash), err))
+		return shim.Error(fmt.Sprintf("Failed to parse block number with error %s", err))
+	}
+	block, err := vledger.GetBlockByNumber(bnum)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get block number %d, error %s", bnum, err))
+	}
+	hash := utils.GetCommitHashFromBlock(block)
+	if hash == nil {
+		return shim.Error(fmt.Sprintf("No commit hash recorded in block number %d, ledger.state.commitHash is not set", bnum))
 	}
-	// TODO: consider trim block content before returning
-	//  Specifically, trim transaction 'data' out of the transaction array Payloads
-	//  This will preserve the transaction Payload header,
-	//  and client can do GetTransactionByID() if they want the full transaction details
 
-	bytes, err := utils.Marshal(block)
+	return shim.Success(hash)
+}
+
+// getMVCCConflicts returns the MVCC conflicts recorded in a block, none if
+// no transaction of the block was invalidated by one
+func getMVCCConflicts(vledger ledger.PeerLedger, number []byte) pb.Response {
+	if number == nil {
+		return shim.Error("Block number must not be nil.")
+	}
+	bnum, err := strconv.ParseUint(string(number), 10, 64)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to parse block number with error %s", err))
+	}
+	block, err := vledger.GetBlockByNumber(bnum)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get block number %d, error %s", bnum, err))
+	}
+	conflicts, err := utils.GetMVCCConflictsFromBlock(block)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get the MVCC conflicts of block number %d, error %s", bnum, err))
+	}
+	if conflicts == nil {
+		conflicts = &pb.MVCCConflicts{}
+	}
+	bytes, err := utils.Marshal(conflicts)
 	if err != nil {
 		return shim.Error(err.Error())
 	}
@@ -167,6 +393,45 @@ func getBlockByHash(vledger ledger.PeerLedger, hash []byte) pb.Response {
 	return shim.Success(bytes)
 }
 
+// getTxMVCCConflict returns the MVCC conflict which invalidated a transaction
+func getTxMVCCConflict(vledger ledger.PeerLedger, rawTxID []byte) pb.Response {
+	txID := string(rawTxID)
+	block, err := vledger.GetBlockByTxID(txID)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get block for txID %s, error %s", txID, err))
+	}
+	conflicts, err := utils.GetMVCCConflictsFromBlock(block)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get the MVCC conflicts of block number %d, error %s", block.Header.Number, err))
+	}
+	if conflicts != nil {
+		for _, conflict := range conflicts.Conflicts {
+			if conflict.TxId != txID {
+				continue
+			}
+			bytes, err := utils.Marshal(conflict)
+			if err != nil {
+				return shim.Error(err.Error())
+			}
+			return shim.Success(bytes)
+		}
+	}
+
+	return shim.Error(fmt.Sprintf("Transaction %s was not invalidated by an MVCC conflict", txID))
+}
+
+func getBlockByHash(stub shim.ChaincodeStubInterface, cid string, vledger ledger.PeerLedger, hash []byte) pb.Response {
+	if hash == nil {
+		return shim.Error("Block hash must not be nil.")
+	}
+	block, err := vledger.GetBlockByHash(hash)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get block hash %s, error %s", string(hash), err))
+	}
+
+	return blockResponse(stub, cid, block)
+}
+
 func getChainInfo(vledger ledger.PeerLedger) pb.Response {
 	binfo, err := vledger.GetBlockchainInfo()
 	if err != nil {
@@ -180,19 +445,26 @@ func getChainInfo(vledger ledger.PeerLedger) pb.Response {
 	return shim.Success(bytes)
 }
 
-func getBlockByTxID(vledger ledger.PeerLedger, rawTxID []byte) pb.Response {
-	txID := string(rawTxID)
-	block, err := vledger.GetBlockByTxID(txID)
-
+func getConfigBlocks(vledger ledger.PeerLedger) pb.Response {
+	configBlocks, err := vledger.GetConfigBlocks()
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get block for txID %s, error %s", txID, err))
+		return shim.Error(fmt.Sprintf("Failed to get config blocks with error %s", err))
 	}
-
-	bytes, err := utils.Marshal(block)
-
+	bytes, err := utils.Marshal(&common.ConfigBlocksInfo{ConfigBlocks: configBlocks})
 	if err != nil {
 		return shim.Error(err.Error())
 	}
 
 	return shim.Success(bytes)
 }
+
+func getBlockByTxID(stub shim.ChaincodeStubInterface, cid string, vledger ledger.PeerLedger, rawTxID []byte) pb.Response {
+	txID := string(rawTxID)
+	block, err := vledger.GetBlockByTxID(txID)
+
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get block for txID %s, error %s", txID, err))
+	}
+
+	return blockResponse(stub, cid, block)
+}
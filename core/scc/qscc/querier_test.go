@@ -20,10 +20,16 @@ import (
 	"os"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/spf13/viper"
 
+	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
 )
 
 func TestInit(t *testing.T) {
@@ -134,3 +140,317 @@ func TestQueryGetBlockByTxID(t *testing.T) {
 		t.Fatalf("qscc GetBlockByTxID should have failed with invalid txID: %s", txID)
 	}
 }
+
+func TestQueryGetStateAtHeight(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/var/hyperledger/test9/")
+	defer os.RemoveAll("/var/hyperledger/test9/")
+	peer.MockInitialize()
+	peer.MockCreateChain("mytestchainid9")
+
+	e := new(LedgerQuerier)
+	stub := shim.NewMockStub("LedgerQuerier", e)
+
+	args := [][]byte{[]byte(GetStateAtHeight), []byte("mytestchainid9"), []byte("1"), []byte("mycc")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("qscc GetStateAtHeight should have failed without keys")
+	}
+
+	args = [][]byte{[]byte(GetStateAtHeight), []byte("mytestchainid9"), []byte("one"), []byte("mycc"), []byte("key1")}
+	if res := stub.MockInvoke("2", args); res.Status == shim.OK {
+		t.Fatalf("qscc GetStateAtHeight should have failed with invalid height: one")
+	}
+
+	args = [][]byte{[]byte(GetStateAtHeight), []byte("mytestchainid9"), []byte("10"), []byte("mycc"), []byte("key1")}
+	if res := stub.MockInvoke("3", args); res.Status == shim.OK {
+		t.Fatalf("qscc GetStateAtHeight should have failed with a height above the height of the ledger")
+	}
+}
+
+func TestQueryPurgeState(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/var/hyperledger/test10/")
+	defer os.RemoveAll("/var/hyperledger/test10/")
+	peer.MockInitialize()
+	peer.MockCreateChain("mytestchainid10")
+	defer viper.Set("peer.qscc.purgers", nil)
+
+	e := new(LedgerQuerier)
+	stub := shim.NewMockStub("LedgerQuerier", e)
+
+	args := [][]byte{[]byte(PurgeState), []byte("mytestchainid10"), []byte("mycc")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("qscc PurgeState should have failed without keys")
+	}
+
+	args = [][]byte{[]byte(PurgeState), []byte("mytestchainid10"), []byte("mycc"), []byte("key1")}
+	if res := stub.MockInvoke("2", args); res.Status == shim.OK {
+		t.Fatalf("qscc PurgeState should have failed as no purger is set")
+	}
+
+	viper.Set("peer.qscc.purgers", []string{"Org1MSP.auditor"})
+	if res := stub.MockInvoke("3", args); res.Status == shim.OK {
+		t.Fatalf("qscc PurgeState should have failed with an invalid purger")
+	}
+}
+
+func TestQueryGetCommitHash(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/var/hyperledger/test11/")
+	defer os.RemoveAll("/var/hyperledger/test11/")
+	peer.MockInitialize()
+	peer.MockCreateChain("mytestchainid11")
+	defer viper.Set("ledger.state.commitHash", false)
+
+	e := new(LedgerQuerier)
+	stub := shim.NewMockStub("LedgerQuerier", e)
+
+	args := [][]byte{[]byte(GetCommitHash), []byte("mytestchainid11"), []byte("one")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("qscc GetCommitHash should have failed with invalid number: one")
+	}
+
+	ledger := peer.GetLedger("mytestchainid11")
+	bg := testutil.NewBlockGenerator(t)
+	simulator, _ := ledger.NewTxSimulator()
+	simulator.SetState("mycc", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	if err := ledger.Commit(bg.NextBlock([][]byte{simRes}, false)); err != nil {
+		t.Fatalf("Failed to commit block: %s", err)
+	}
+	args = [][]byte{[]byte(GetCommitHash), []byte("mytestchainid11"), []byte("1")}
+	if res := stub.MockInvoke("2", args); res.Status == shim.OK {
+		t.Fatalf("qscc GetCommitHash should have failed as no commit hash is recorded")
+	}
+
+	viper.Set("ledger.state.commitHash", true)
+	simulator, _ = ledger.NewTxSimulator()
+	simulator.SetState("mycc", "key1", []byte("value2"))
+	simulator.Done()
+	simRes, _ = simulator.GetTxSimulationResults()
+	if err := ledger.Commit(bg.NextBlock([][]byte{simRes}, false)); err != nil {
+		t.Fatalf("Failed to commit block: %s", err)
+	}
+	args = [][]byte{[]byte(GetCommitHash), []byte("mytestchainid11"), []byte("2")}
+	res := stub.MockInvoke("3", args)
+	if res.Status != shim.OK {
+		t.Fatalf("qscc GetCommitHash failed with err: %s", res.Message)
+	}
+	if len(res.Payload) == 0 {
+		t.Fatalf("qscc GetCommitHash should have returned the commit hash of block 2")
+	}
+}
+
+func TestConfiguredPrincipals(t *testing.T) {
+	defer viper.Set("peer.qscc.purgers", nil)
+
+	viper.Set("peer.qscc.purgers", []string{"Org1MSP.admin", "Org.2MSP.member"})
+	principals, err := configuredPrincipals("peer.qscc.purgers")
+	if err != nil {
+		t.Fatalf("Error parsing purgers: %s", err)
+	}
+	if len(principals) != 2 {
+		t.Fatalf("Expected 2 principals, got %d", len(principals))
+	}
+	role := &common.MSPRole{}
+	if err = proto.Unmarshal(principals[1].Principal, role); err != nil {
+		t.Fatalf("Error unmarshaling MSP role: %s", err)
+	}
+	if role.MspIdentifier != "Org.2MSP" || role.Role != common.MSPRole_MEMBER {
+		t.Errorf("Unexpected principal %v", role)
+	}
+
+	for _, invalid := range []string{"Org1MSP", "Org1MSP.auditor", ".admin"} {
+		viper.Set("peer.qscc.purgers", []string{invalid})
+		if _, err = configuredPrincipals("peer.qscc.purgers"); err == nil {
+			t.Errorf("Purger %s should have been rejected", invalid)
+		}
+	}
+}
+
+func TestRedactTransaction(t *testing.T) {
+	endorsement := func(mspID string) *pb.Endorsement {
+		return &pb.Endorsement{
+			Endorser:  utils.MarshalOrPanic(&msp.SerializedIdentity{Mspid: mspID, IdBytes: []byte("cert")}),
+			Signature: []byte("signature"),
+		}
+	}
+	ccActionPayload := &pb.ChaincodeActionPayload{
+		ChaincodeProposalPayload: []byte("secret proposal"),
+		Action: &pb.ChaincodeEndorsedAction{
+			ProposalResponsePayload: []byte("secret results"),
+			Endorsements:            []*pb.Endorsement{endorsement("Org1MSP"), endorsement("Org2MSP"), endorsement("Org1MSP")},
+		},
+	}
+	header := &common.Header{
+		ChannelHeader: &common.ChannelHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION), ChannelId: "mychannel", TxId: "txid"},
+	}
+	processedTran := &pb.ProcessedTransaction{
+		TransactionEnvelope: &common.Envelope{
+			Payload: utils.MarshalOrPanic(&common.Payload{
+				Header: header,
+				Data: utils.MarshalOrPanic(&pb.Transaction{
+					Actions: []*pb.TransactionAction{{Payload: utils.MarshalOrPanic(ccActionPayload)}},
+				}),
+			}),
+		},
+		Valid: true,
+	}
+
+	redacted, err := redactTransaction(processedTran)
+	if err != nil {
+		t.Fatalf("Error redacting transaction: %s", err)
+	}
+	if !proto.Equal(redacted.Header, header) || !redacted.Valid {
+		t.Errorf("Redacted transaction should keep the header and validity, got %v", redacted)
+	}
+	if len(redacted.EndorsingMsps) != 2 || redacted.EndorsingMsps[0] != "Org1MSP" || redacted.EndorsingMsps[1] != "Org2MSP" {
+		t.Errorf("Expected endorsing MSPs [Org1MSP Org2MSP], got %v", redacted.EndorsingMsps)
+	}
+}
+
+func TestRedactBlock(t *testing.T) {
+	tx := &common.Envelope{
+		Payload: utils.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{ChannelHeader: &common.ChannelHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION), TxId: "txid"}},
+			Data:   []byte("secret transaction"),
+		}),
+	}
+	config := &common.Envelope{
+		Payload: utils.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{ChannelHeader: &common.ChannelHeader{Type: int32(common.HeaderType_CONFIG)}},
+			Data:   []byte("config"),
+		}),
+	}
+	block := common.NewBlock(1, []byte("previous hash"))
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(tx), utils.MarshalOrPanic(config)}
+
+	redacted, err := redactBlock(block)
+	if err != nil {
+		t.Fatalf("Error redacting block: %s", err)
+	}
+	if !proto.Equal(redacted.Header, block.Header) {
+		t.Errorf("Redacted block should keep the header, got %v", redacted.Header)
+	}
+	payload, err := utils.GetPayload(utils.UnmarshalEnvelopeOrPanic(redacted.Data.Data[0]))
+	if err != nil {
+		t.Fatalf("Error getting the payload of the redacted transaction: %s", err)
+	}
+	if payload.Data != nil || payload.Header.ChannelHeader.TxId != "txid" {
+		t.Errorf("Redacted transaction should keep its header only, got %v", payload)
+	}
+	if string(redacted.Data.Data[1]) != string(block.Data.Data[1]) {
+		t.Errorf("Config transactions should not be redacted")
+	}
+	if payload, _ = utils.GetPayload(utils.UnmarshalEnvelopeOrPanic(block.Data.Data[0])); string(payload.Data) != "secret transaction" {
+		t.Errorf("The original block should be left untouched")
+	}
+}
+
+func TestQueryGetConfigBlocks(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/var/hyperledger/test12/")
+	defer os.RemoveAll("/var/hyperledger/test12/")
+	peer.MockInitialize()
+	peer.MockCreateChain("mytestchainid12")
+
+	ledger := peer.GetLedger("mytestchainid12")
+	bg := testutil.NewBlockGenerator(t)
+	if err := ledger.Commit(bg.NextConfigBlock(0)); err != nil {
+		t.Fatalf("Failed to commit config block: %s", err)
+	}
+	simulator, _ := ledger.NewTxSimulator()
+	simulator.SetState("mycc", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	if err := ledger.Commit(bg.NextBlock([][]byte{simRes}, false)); err != nil {
+		t.Fatalf("Failed to commit block: %s", err)
+	}
+	if err := ledger.Commit(bg.NextConfigBlock(1)); err != nil {
+		t.Fatalf("Failed to commit config block: %s", err)
+	}
+
+	e := new(LedgerQuerier)
+	stub := shim.NewMockStub("LedgerQuerier", e)
+
+	args := [][]byte{[]byte(GetConfigBlocks), []byte("mytestchainid12")}
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		t.Fatalf("qscc GetConfigBlocks failed with err: %s", res.Message)
+	}
+	configBlocks := &common.ConfigBlocksInfo{}
+	if err := proto.Unmarshal(res.Payload, configBlocks); err != nil {
+		t.Fatalf("Failed to unmarshal config blocks: %s", err)
+	}
+	expected := []*common.ConfigBlockInfo{{Sequence: 0, BlockNumber: 1}, {Sequence: 1, BlockNumber: 3}}
+	if !proto.Equal(configBlocks, &common.ConfigBlocksInfo{ConfigBlocks: expected}) {
+		t.Fatalf("qscc GetConfigBlocks returned %s, expected %s", configBlocks, expected)
+	}
+}
+
+func TestQueryGetMVCCConflicts(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/var/hyperledger/test13/")
+	defer os.RemoveAll("/var/hyperledger/test13/")
+	peer.MockInitialize()
+	peer.MockCreateChain("mytestchainid13")
+
+	ledger := peer.GetLedger("mytestchainid13")
+	bg := testutil.NewBlockGenerator(t)
+	var simResults [][]byte
+	for i := 0; i < 2; i++ {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.GetState("mycc", "key1")
+		simulator.SetState("mycc", "key1", []byte(fmt.Sprintf("value%d", i)))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		simResults = append(simResults, simRes)
+	}
+	block := bg.NextBlock(simResults, false)
+	if err := ledger.Commit(block); err != nil {
+		t.Fatalf("Failed to commit block: %s", err)
+	}
+	txIDs := make([]string, 2)
+	for i := range txIDs {
+		env, _ := utils.GetEnvelopeFromBlock(block.Data.Data[i])
+		payload, _ := utils.GetPayload(env)
+		txIDs[i] = payload.Header.ChannelHeader.TxId
+	}
+
+	e := new(LedgerQuerier)
+	stub := shim.NewMockStub("LedgerQuerier", e)
+
+	args := [][]byte{[]byte(GetMVCCConflicts), []byte("mytestchainid13"), []byte("one")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("qscc GetMVCCConflicts should have failed with invalid number: one")
+	}
+
+	args = [][]byte{[]byte(GetMVCCConflicts), []byte("mytestchainid13"), []byte(fmt.Sprint(block.Header.Number))}
+	res := stub.MockInvoke("2", args)
+	if res.Status != shim.OK {
+		t.Fatalf("qscc GetMVCCConflicts failed with err: %s", res.Message)
+	}
+	conflicts := &pb.MVCCConflicts{}
+	if err := proto.Unmarshal(res.Payload, conflicts); err != nil {
+		t.Fatalf("Failed to unmarshal MVCC conflicts: %s", err)
+	}
+	expected := &pb.MVCCConflict{TxIndex: 1, TxId: txIDs[1], Namespace: "mycc", Key: "key1",
+		ConflictingBlockNum: block.Header.Number, ConflictingTxNum: 1, ConflictingTxId: txIDs[0]}
+	if !proto.Equal(conflicts, &pb.MVCCConflicts{Conflicts: []*pb.MVCCConflict{expected}}) {
+		t.Fatalf("qscc GetMVCCConflicts returned %s, expected %s", conflicts, expected)
+	}
+
+	args = [][]byte{[]byte(GetTxMVCCConflict), []byte("mytestchainid13"), []byte(txIDs[1])}
+	res = stub.MockInvoke("3", args)
+	if res.Status != shim.OK {
+		t.Fatalf("qscc GetTxMVCCConflict failed with err: %s", res.Message)
+	}
+	conflict := &pb.MVCCConflict{}
+	if err := proto.Unmarshal(res.Payload, conflict); err != nil {
+		t.Fatalf("Failed to unmarshal MVCC conflict: %s", err)
+	}
+	if !proto.Equal(conflict, expected) {
+		t.Fatalf("qscc GetTxMVCCConflict returned %s, expected %s", conflict, expected)
+	}
+
+	args = [][]byte{[]byte(GetTxMVCCConflict), []byte("mytestchainid13"), []byte(txIDs[0])}
+	if res := stub.MockInvoke("4", args); res.Status == shim.OK {
+		t.Fatalf("qscc GetTxMVCCConflict should have failed for a valid transaction")
+	}
+}
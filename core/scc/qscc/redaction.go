@@ -0,0 +1,199 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package qscc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// RedactedView is set as the message of the responses to GetTransactionByID
+// which carry a RedactedTransaction rather than a ProcessedTransaction, and of
+// the responses to the block queries which carry a block redacted by redactBlock
+const RedactedView = "redacted"
+
+// fullReader returns whether the creator of the query may read the content of
+// the transactions of chain cid in full, that is whether the signed proposal
+// of the query satisfies the FullReaders policy of the chain. If the chain
+// has no such policy, every member of the channel may
+func fullReader(stub shim.ChaincodeStubInterface, cid string) (bool, error) {
+	pm := peer.GetPolicyManager(cid)
+	if pm == nil {
+		return true, nil
+	}
+	policy, ok := pm.GetPolicy(policies.ChannelFullReaders)
+	if !ok {
+		return true, nil
+	}
+
+	signedProp, err := stub.GetSignedProposal()
+	if err != nil {
+		return false, fmt.Errorf("Failed to get the signed proposal of the query, error %s", err)
+	}
+	creator, err := stub.GetCreator()
+	if err != nil {
+		return false, fmt.Errorf("Failed to get the creator of the query, error %s", err)
+	}
+	signedData := &common.SignedData{Data: signedProp.ProposalBytes, Identity: creator, Signature: signedProp.Signature}
+	if err = policy.Evaluate([]*common.SignedData{signedData}); err != nil {
+		qscclogger.Debugf("Creator of the query is not a full reader of chain %s: %s", cid, err)
+		return false, nil
+	}
+	return true, nil
+}
+
+// configuredPrincipals returns the principals of the <MSP ID>.<role> entries
+// of the configuration key
+func configuredPrincipals(key string) ([]*common.MSPPrincipal, error) {
+	var principals []*common.MSPPrincipal
+	for _, entry := range viper.GetStringSlice(key) {
+		i := strings.LastIndex(entry, ".")
+		if i <= 0 {
+			return nil, fmt.Errorf("Invalid entry %s of %s, expected <MSP ID>.<role>", entry, key)
+		}
+
+		var role common.MSPRole_MSPRoleType
+		switch entry[i+1:] {
+		case "member":
+			role = common.MSPRole_MEMBER
+		case "admin":
+			role = common.MSPRole_ADMIN
+		default:
+			return nil, fmt.Errorf("Invalid role in entry %s of %s, expected member or admin", entry, key)
+		}
+
+		principals = append(principals, &common.MSPPrincipal{
+			PrincipalClassification: common.MSPPrincipal_ROLE,
+			Principal:               utils.MarshalOrPanic(&common.MSPRole{MspIdentifier: entry[:i], Role: role}),
+		})
+	}
+	return principals, nil
+}
+
+// satisfiesPrincipals returns whether creator satisfies one of principals, and an error
+// if creator is not a valid member of the channel whose MSPs are in mspMgr
+func satisfiesPrincipals(mspMgr msp.MSPManager, creator []byte, principals []*common.MSPPrincipal) (bool, error) {
+	id, err := mspMgr.DeserializeIdentity(creator)
+	if err != nil {
+		return false, fmt.Errorf("Failed to deserialize creator identity, error %s", err)
+	}
+	if err = id.Validate(); err != nil {
+		return false, fmt.Errorf("Creator is not a member of the channel, error %s", err)
+	}
+
+	msps, err := mspMgr.GetMSPs()
+	if err != nil {
+		return false, fmt.Errorf("Failed to get the MSPs of the channel, error %s", err)
+	}
+	idMSP, ok := msps[id.GetMSPIdentifier()]
+	if !ok {
+		return false, fmt.Errorf("Creator MSP %s is not an MSP of the channel", id.GetMSPIdentifier())
+	}
+
+	for _, principal := range principals {
+		if idMSP.SatisfiesPrincipal(id, principal) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// redactTransaction strips processedTran down to its header, its validity and
+// the MSPs which endorsed it
+func redactTransaction(processedTran *pb.ProcessedTransaction) (*pb.RedactedTransaction, error) {
+	payload, err := utils.GetPayload(processedTran.TransactionEnvelope)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get transaction payload, error %s", err)
+	}
+
+	redacted := &pb.RedactedTransaction{
+		Header: payload.Header,
+		Valid:  processedTran.Valid,
+	}
+
+	if payload.Header == nil || payload.Header.ChannelHeader == nil ||
+		common.HeaderType(payload.Header.ChannelHeader.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+		return redacted, nil
+	}
+
+	tx, err := utils.GetTransaction(payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get transaction, error %s", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, action := range tx.Actions {
+		ccActionPayload, err := utils.GetChaincodeActionPayload(action.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get chaincode action payload, error %s", err)
+		}
+		if ccActionPayload.Action == nil {
+			continue
+		}
+		for _, endorsement := range ccActionPayload.Action.Endorsements {
+			endorser := &msp.SerializedIdentity{}
+			if err := proto.Unmarshal(endorsement.Endorser, endorser); err != nil {
+				return nil, fmt.Errorf("Failed to unmarshal endorser identity, error %s", err)
+			}
+			if !seen[endorser.Mspid] {
+				seen[endorser.Mspid] = true
+				redacted.EndorsingMsps = append(redacted.EndorsingMsps, endorser.Mspid)
+			}
+		}
+	}
+
+	return redacted, nil
+}
+
+// redactBlock returns a copy of block whose endorser transactions are stripped
+// of their data, keeping their headers only. The data hash of the header of the
+// block does not match the redacted transactions
+func redactBlock(block *common.Block) (*common.Block, error) {
+	redacted := proto.Clone(block).(*common.Block)
+	if redacted.Data == nil {
+		return redacted, nil
+	}
+	for i, envBytes := range redacted.Data.Data {
+		env, err := utils.GetEnvelopeFromBlock(envBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get transaction %d, error %s", i, err)
+		}
+		payload, err := utils.GetPayload(env)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get payload of transaction %d, error %s", i, err)
+		}
+		if payload.Header == nil || payload.Header.ChannelHeader == nil ||
+			common.HeaderType(payload.Header.ChannelHeader.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue
+		}
+		payload.Data = nil
+		env.Payload = utils.MarshalOrPanic(payload)
+		redacted.Data.Data[i] = utils.MarshalOrPanic(env)
+	}
+	return redacted, nil
+}
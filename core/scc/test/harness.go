@@ -0,0 +1,219 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/core/scc"
+	"github.com/hyperledger/fabric/msp"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	msptesttools "github.com/hyperledger/fabric/msp/mgmt/testtools"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+var logger = logging.MustGetLogger("scc/test")
+
+// systemChaincodes lists the system chaincodes the harness enables
+var systemChaincodes = []string{"cscc", "lccc", "escc", "vscc", "qscc"}
+
+// Harness runs the chaincode support of a peer together with its system
+// chaincodes and a ledger per channel, all within the calling process.
+// System chaincodes execute through the in-process container controller so
+// neither Docker nor a gRPC listener is required
+type Harness struct {
+	fileSystemPath string
+	chainIDs       []string
+	signer         msp.SigningIdentity
+
+	// commitLock serializes commits as the committer would
+	commitLock sync.Mutex
+}
+
+// NewHarness creates the ledgers for the given channels, starts the chaincode
+// support and deploys the system chaincodes on every channel. The local MSP is
+// loaded from mspConfigDir, falling back to the sample configuration in the
+// GOPATH. Close must be called to release the ledgers and remove their files
+func NewHarness(mspConfigDir string, chainIDs ...string) (*Harness, error) {
+	fileSystemPath, err := ioutil.TempDir("", "scctest")
+	if err != nil {
+		return nil, fmt.Errorf("Error creating ledger directory: %s", err)
+	}
+	viper.Set("peer.fileSystemPath", fileSystemPath)
+
+	whitelist := make(map[string]string)
+	for _, name := range systemChaincodes {
+		whitelist[name] = "true"
+	}
+	viper.Set("chaincode.system", whitelist)
+
+	if err = msptesttools.LoadMSPSetupForTesting(mspConfigDir); err != nil {
+		os.RemoveAll(fileSystemPath)
+		return nil, fmt.Errorf("Error loading MSP setup: %s", err)
+	}
+	signer, err := mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	if err != nil {
+		os.RemoveAll(fileSystemPath)
+		return nil, fmt.Errorf("Error getting signing identity: %s", err)
+	}
+
+	peer.MockInitialize()
+
+	getPeerEndpoint := func() (*pb.PeerEndpoint, error) {
+		return &pb.PeerEndpoint{Id: &pb.PeerID{Name: "scctestpeer"}, Address: "0.0.0.0:0"}, nil
+	}
+	chaincode.NewChaincodeSupport(getPeerEndpoint, false, 5*time.Second)
+
+	scc.RegisterSysCCs()
+
+	h := &Harness{fileSystemPath: fileSystemPath, signer: signer}
+	for _, chainID := range chainIDs {
+		if err = peer.MockCreateChain(chainID); err != nil {
+			h.Close()
+			return nil, fmt.Errorf("Error creating chain %s: %s", chainID, err)
+		}
+		scc.DeploySysCCs(chainID)
+		h.chainIDs = append(h.chainIDs, chainID)
+	}
+
+	return h, nil
+}
+
+// Ledger returns the ledger of the given channel
+func (h *Harness) Ledger(chainID string) ledger.PeerLedger {
+	return peer.GetLedger(chainID)
+}
+
+// Signer returns the identity the harness signs proposals with
+func (h *Harness) Signer() msp.SigningIdentity {
+	return h.signer
+}
+
+// Invoke executes ccName on chainID with a proposal signed by the harness'
+// signer. The simulation results of a successful invocation are committed to
+// the ledger so that subsequent invocations observe them
+func (h *Harness) Invoke(chainID string, ccName string, args ...[]byte) (*pb.Response, error) {
+	return h.execute(chainID, ccName, true, args)
+}
+
+// Query executes ccName on chainID like Invoke but discards the simulation
+// results
+func (h *Harness) Query(chainID string, ccName string, args ...[]byte) (*pb.Response, error) {
+	return h.execute(chainID, ccName, false, args)
+}
+
+func (h *Harness) execute(chainID string, ccName string, commit bool, args [][]byte) (*pb.Response, error) {
+	lgr := peer.GetLedger(chainID)
+	if lgr == nil {
+		return nil, fmt.Errorf("Unknown chain %s", chainID)
+	}
+
+	creator, err := h.signer.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	version := util.GetSysCCVersion()
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{Type: pb.ChaincodeSpec_GOLANG, ChaincodeId: &pb.ChaincodeID{Name: ccName, Version: version}, Input: &pb.ChaincodeInput{Args: args}}}
+	txid := util.GenerateUUID()
+	prop, err := putils.CreateProposalFromCIS(txid, common.HeaderType_ENDORSER_TRANSACTION, chainID, cis, creator)
+	if err != nil {
+		return nil, err
+	}
+
+	txsim, err := lgr.NewTxSimulator()
+	if err != nil {
+		return nil, err
+	}
+	defer txsim.Done()
+
+	ctxt := context.WithValue(context.Background(), chaincode.TXSimulatorKey, txsim)
+	cccid := ccprovider.NewCCContext(chainID, ccName, version, txid, scc.IsSysCC(ccName), prop)
+	res, _, err := chaincode.Execute(ctxt, cccid, cis)
+	if err != nil {
+		return nil, err
+	}
+
+	if !commit || res.Status >= 400 {
+		return res, nil
+	}
+
+	results, err := txsim.GetTxSimulationResults()
+	if err != nil {
+		return nil, err
+	}
+	if err = h.commit(lgr, prop, res, results); err != nil {
+		return nil, fmt.Errorf("Error committing results of %s: %s", txid, err)
+	}
+	return res, nil
+}
+
+// commit wraps the simulation results in a transaction and commits it to
+// lgr in a block of its own
+func (h *Harness) commit(lgr ledger.PeerLedger, prop *pb.Proposal, res *pb.Response, results []byte) error {
+	presp, err := putils.CreateProposalResponse(prop.Header, prop.Payload, res, results, nil, nil, h.signer)
+	if err != nil {
+		return err
+	}
+	env, err := putils.CreateSignedTx(prop, h.signer, presp)
+	if err != nil {
+		return err
+	}
+	envBytes, err := putils.GetBytesEnvelope(env)
+	if err != nil {
+		return err
+	}
+
+	h.commitLock.Lock()
+	defer h.commitLock.Unlock()
+
+	info, err := lgr.GetBlockchainInfo()
+	if err != nil {
+		return err
+	}
+	block := common.NewBlock(info.Height, info.CurrentBlockHash)
+	block.Data.Data = [][]byte{envBytes}
+	block.Header.DataHash = block.Data.Hash()
+	return lgr.Commit(block)
+}
+
+// Close stops the system chaincodes, closes the ledgers and removes their
+// files
+func (h *Harness) Close() {
+	for _, chainID := range h.chainIDs {
+		scc.DeDeploySysCCs(chainID)
+	}
+	ledgermgmt.CleanupTestEnv()
+	if err := os.RemoveAll(h.fileSystemPath); err != nil {
+		logger.Warningf("Error removing %s: %s", h.fileSystemPath, err)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func getHeight(t *testing.T, h *Harness, chainID string) uint64 {
+	res, err := h.Query(chainID, "qscc", []byte("GetChainInfo"), []byte(chainID))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(shim.OK), res.Status)
+
+	info := &common.BlockchainInfo{}
+	assert.NoError(t, proto.Unmarshal(res.Payload, info))
+	return info.Height
+}
+
+func TestHarness(t *testing.T) {
+	h, err := NewHarness("../../../msp/sampleconfig/", "foo", "bar")
+	if err != nil {
+		t.Fatalf("Error creating harness: %s", err)
+	}
+	defer h.Close()
+
+	assert.Equal(t, uint64(0), getHeight(t, h, "foo"))
+
+	res, err := h.Invoke("foo", "qscc", []byte("GetChainInfo"), []byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(shim.OK), res.Status)
+
+	assert.Equal(t, uint64(1), getHeight(t, h, "foo"), "Invoke should commit a block")
+	assert.Equal(t, uint64(0), getHeight(t, h, "bar"), "Channels should be independent")
+
+	res, err = h.Query("foo", "lccc", []byte("getid"), []byte("foo"), []byte("nosuchcc"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, int32(shim.OK), res.Status, "Expected lccc to report unknown chaincode")
+
+	_, err = h.Query("nosuchchain", "qscc", []byte("GetChainInfo"))
+	assert.Error(t, err)
+}
@@ -49,13 +49,15 @@ func (vscc *ValidatorOneValidSignature) Init(stub shim.ChaincodeStubInterface) p
 // policy specification to be coded as a transaction of the chaincode and the client
 // selecting which policy to use for validation using parameter function
 // @return serialized Block of valid and invalid transactions indentified
-// Note that Peer calls this function with 3 arguments, where args[0] is the
-// function name, args[1] is the Envelope and args[2] is the validation policy
+// Note that Peer calls this function with at least 3 arguments, where args[0] is the
+// function name, args[1] is the Envelope, args[2] is the validation policy and
+// args[3:] are the namespaces of the transaction validated by this policy
 func (vscc *ValidatorOneValidSignature) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	// TODO: document the argument in some white paper or design document
 	// args[0] - function name (not used now)
 	// args[1] - serialized Envelope
 	// args[2] - serialized policy
+	// args[3:] - the namespaces validated (not used now)
 	args := stub.GetArgs()
 	if len(args) < 3 {
 		return shim.Error("Incorrect number of arguments")
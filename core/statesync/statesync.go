@@ -0,0 +1,188 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package statesync streams the world state updates committed to the
+// ledgers of the peer, so that external stores can mirror the world state
+package statesync
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("statesync")
+
+// LedgerGetter returns the ledger of a chain, or nil if the peer has not joined it
+type LedgerGetter func(chainID string) ledger.PeerLedger
+
+// PolicyManagerGetter returns the policy manager of a chain, or nil if the
+// peer has not joined it
+type PolicyManagerGetter func(chainID string) policies.Manager
+
+type server struct {
+	getLedger        LedgerGetter
+	getPolicyManager PolicyManagerGetter
+}
+
+// NewStateSyncServer creates a StateSync server streaming the state updates
+// of the ledgers returned by getLedger to the readers of the chains, as
+// defined by the policy managers returned by getPolicyManager
+func NewStateSyncServer(getLedger LedgerGetter, getPolicyManager PolicyManagerGetter) pb.StateSyncServer {
+	return &server{getLedger: getLedger, getPolicyManager: getPolicyManager}
+}
+
+// Sync streams the state updates of the requested chain block by block,
+// starting at the requested block and then following the chain as new
+// blocks are committed, until the client goes away. The request must be
+// signed by an identity satisfying the Readers policy of the chain
+func (s *server) Sync(env *common.Envelope, stream pb.StateSync_SyncServer) error {
+	req, err := s.authorize(env)
+	if err != nil {
+		logger.Warningf("Refusing state sync request: %s", err)
+		return err
+	}
+
+	lgr := s.getLedger(req.ChainId)
+	if lgr == nil {
+		return fmt.Errorf("Chain %s does not exist on this peer", req.ChainId)
+	}
+
+	// The iterator blocks waiting for new blocks until the client goes away
+	itr, err := lgr.GetBlocksIterator(stream.Context(), req.StartBlock)
+	if err != nil {
+		return fmt.Errorf("Error iterating the blocks of chain %s from block %d: %s", req.ChainId, req.StartBlock, err)
+	}
+	defer itr.Close()
+
+	for {
+		result, err := itr.Next()
+		if err != nil {
+			if err == stream.Context().Err() {
+				logger.Debugf("State sync of chain %s ended, the client went away", req.ChainId)
+				return err
+			}
+			return fmt.Errorf("Error reading blocks of chain %s: %s", req.ChainId, err)
+		}
+		if result == nil {
+			logger.Debugf("State sync of chain %s ended", req.ChainId)
+			return nil
+		}
+
+		updates, err := BlockUpdates(result.(commonledger.BlockHolder).GetBlock())
+		if err != nil {
+			return err
+		}
+		if err = stream.Send(updates); err != nil {
+			return err
+		}
+	}
+}
+
+// authorize returns the StateSyncRequest carried by env if env is signed by
+// a reader of the requested chain
+func (s *server) authorize(env *common.Envelope) (*pb.StateSyncRequest, error) {
+	payload, err := putils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling the payload of the request: %s", err)
+	}
+	if payload.Header == nil || payload.Header.ChannelHeader == nil || payload.Header.SignatureHeader == nil {
+		return nil, fmt.Errorf("The request has no header")
+	}
+	req := &pb.StateSyncRequest{}
+	if err = proto.Unmarshal(payload.Data, req); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling the request: %s", err)
+	}
+	if req.ChainId != payload.Header.ChannelHeader.ChannelId {
+		return nil, fmt.Errorf("The request for chain %s is signed for chain %s", req.ChainId, payload.Header.ChannelHeader.ChannelId)
+	}
+
+	pm := s.getPolicyManager(req.ChainId)
+	if pm == nil {
+		return nil, fmt.Errorf("Chain %s does not exist on this peer", req.ChainId)
+	}
+	signedData, err := env.AsSignedData()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the signature of the request: %s", err)
+	}
+	policy, _ := pm.GetPolicy(policies.ChannelReaders)
+	if err = policy.Evaluate(signedData); err != nil {
+		return nil, fmt.Errorf("The creator of the request does not satisfy the %s policy of chain %s: %s", policies.ChannelReaders, req.ChainId, err)
+	}
+	return req, nil
+}
+
+// BlockUpdates extracts the writes of the valid transactions of a committed
+// block, with the versions they give to the keys in the state database
+func BlockUpdates(block *common.Block) (*pb.BlockStateUpdates, error) {
+	updates := &pb.BlockStateUpdates{BlockNumber: block.Header.Number}
+	txsFilter, err := util.GetTxsFilter(block)
+	if err != nil {
+		return nil, err
+	}
+
+	for txIndex, envBytes := range block.Data.Data {
+		if txsFilter.IsSet(uint(txIndex)) {
+			continue
+		}
+
+		env, err := putils.GetEnvelopeFromBlock(envBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Error extracting transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		payload, err := putils.GetPayload(env)
+		if err != nil {
+			return nil, fmt.Errorf("Error extracting payload of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		if common.HeaderType(payload.Header.ChannelHeader.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue
+		}
+
+		respPayload, err := putils.GetActionFromEnvelope(envBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Error extracting action of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		txRWSet := &rwset.TxReadWriteSet{}
+		if err = txRWSet.Unmarshal(respPayload.Results); err != nil {
+			return nil, fmt.Errorf("Error unmarshaling read-write set of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+
+		// The state database versions keys with the 1-based position of the transaction
+		for _, nsRWSet := range txRWSet.NsRWs {
+			for _, kvWrite := range nsRWSet.Writes {
+				updates.Writes = append(updates.Writes, &pb.StateWrite{
+					Namespace: nsRWSet.NameSpace,
+					Key:       kvWrite.Key,
+					Value:     kvWrite.Value,
+					IsDelete:  kvWrite.IsDelete,
+					BlockNum:  block.Header.Number,
+					TxNum:     uint64(txIndex + 1),
+				})
+			}
+		}
+	}
+
+	return updates, nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesync
+
+import (
+	"fmt"
+	"testing"
+
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type blockHolder struct {
+	block *common.Block
+}
+
+func (bh *blockHolder) GetBlock() *common.Block {
+	return bh.block
+}
+
+func (bh *blockHolder) GetBlockBytes() []byte {
+	return nil
+}
+
+// blocksItr returns the blocks it holds, then ends as if the iterator was closed
+type blocksItr struct {
+	blocks []*common.Block
+}
+
+func (itr *blocksItr) Next() (commonledger.QueryResult, error) {
+	if len(itr.blocks) == 0 {
+		return nil, nil
+	}
+	block := itr.blocks[0]
+	itr.blocks = itr.blocks[1:]
+	return &blockHolder{block}, nil
+}
+
+func (itr *blocksItr) Close() {}
+
+type mockLedger struct {
+	ledger.PeerLedger
+	blocks []*common.Block
+}
+
+func (ml *mockLedger) GetBlocksIterator(ctx context.Context, startBlockNumber uint64) (commonledger.ResultsIterator, error) {
+	return &blocksItr{blocks: ml.blocks[startBlockNumber:]}, nil
+}
+
+type mockStream struct {
+	grpc.ServerStream
+	sent []*pb.BlockStateUpdates
+}
+
+func (ms *mockStream) Context() context.Context {
+	return context.Background()
+}
+
+func (ms *mockStream) Send(updates *pb.BlockStateUpdates) error {
+	ms.sent = append(ms.sent, updates)
+	return nil
+}
+
+func makeResults(t *testing.T, ns string, writes ...*rwset.KVWrite) []byte {
+	txRWSet := &rwset.TxReadWriteSet{NsRWs: []*rwset.NsReadWriteSet{{NameSpace: ns, Writes: writes}}}
+	results, err := txRWSet.Marshal()
+	assert.NoError(t, err)
+	return results
+}
+
+func makeBlocks(t *testing.T) []*common.Block {
+	bg := testutil.NewBlockGenerator(t)
+	block1 := bg.NextBlock([][]byte{
+		makeResults(t, "mycc", rwset.NewKVWrite("a", []byte("1")), rwset.NewKVWrite("b", []byte("2"))),
+		makeResults(t, "mycc", rwset.NewKVWrite("c", []byte("3"))),
+	}, false)
+	block2 := bg.NextBlock([][]byte{
+		makeResults(t, "mycc", rwset.NewKVWrite("a", nil)),
+	}, false)
+
+	// Mark the second transaction of the first block as invalid
+	txsFilter := util.NewFilterBitArray(2)
+	txsFilter.Set(1)
+	block1.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter.ToBytes()
+
+	return []*common.Block{testutil.ConstructBlock(t, [][]byte{}, false), block1, block2}
+}
+
+func TestBlockUpdates(t *testing.T) {
+	blocks := makeBlocks(t)
+
+	updates, err := BlockUpdates(blocks[1])
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[1].Header.Number, updates.BlockNumber)
+	assert.Len(t, updates.Writes, 2, "Writes of invalid transactions should be skipped")
+	assert.Equal(t, &pb.StateWrite{Namespace: "mycc", Key: "a", Value: []byte("1"), BlockNum: blocks[1].Header.Number, TxNum: 1}, updates.Writes[0])
+	assert.Equal(t, "b", updates.Writes[1].Key)
+
+	updates, err = BlockUpdates(blocks[2])
+	assert.NoError(t, err)
+	assert.Len(t, updates.Writes, 1)
+	assert.True(t, updates.Writes[0].IsDelete)
+}
+
+func makeRequest(signedChainID string, req *pb.StateSyncRequest) *common.Envelope {
+	return &common.Envelope{
+		Payload: putils.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{
+				ChannelHeader:   &common.ChannelHeader{Type: int32(common.HeaderType_MESSAGE), ChannelId: signedChainID},
+				SignatureHeader: &common.SignatureHeader{Creator: []byte("creator")},
+			},
+			Data: putils.MarshalOrPanic(req),
+		}),
+		Signature: []byte("signature"),
+	}
+}
+
+func TestSync(t *testing.T) {
+	lgr := &mockLedger{blocks: makeBlocks(t)}
+	readers := &mockpolicies.Policy{}
+	srv := NewStateSyncServer(func(chainID string) ledger.PeerLedger {
+		if chainID == "mychain" {
+			return lgr
+		}
+		return nil
+	}, func(chainID string) policies.Manager {
+		if chainID == "mychain" {
+			return &mockpolicies.Manager{PolicyMap: map[string]*mockpolicies.Policy{policies.ChannelReaders: readers}}
+		}
+		return nil
+	})
+
+	stream := &mockStream{}
+	assert.NoError(t, srv.Sync(makeRequest("mychain", &pb.StateSyncRequest{ChainId: "mychain", StartBlock: 1}), stream))
+	assert.Len(t, stream.sent, 2, "Sync should resume from the requested block")
+	assert.Equal(t, lgr.blocks[1].Header.Number, stream.sent[0].BlockNumber)
+
+	assert.Error(t, srv.Sync(makeRequest("otherchain", &pb.StateSyncRequest{ChainId: "otherchain"}), &mockStream{}))
+}
+
+func TestSyncRefusesUnauthorizedRequests(t *testing.T) {
+	readers := &mockpolicies.Policy{}
+	srv := NewStateSyncServer(func(chainID string) ledger.PeerLedger {
+		return &mockLedger{blocks: makeBlocks(t)}
+	}, func(chainID string) policies.Manager {
+		return &mockpolicies.Manager{PolicyMap: map[string]*mockpolicies.Policy{policies.ChannelReaders: readers}}
+	})
+
+	stream := &mockStream{}
+	assert.Error(t, srv.Sync(&common.Envelope{Payload: []byte("garbage")}, stream), "A malformed request should be refused")
+	assert.Error(t, srv.Sync(makeRequest("otherchain", &pb.StateSyncRequest{ChainId: "mychain"}), stream),
+		"A request signed for another chain should be refused")
+
+	readers.Err = fmt.Errorf("Not a reader")
+	assert.Error(t, srv.Sync(makeRequest("mychain", &pb.StateSyncRequest{ChainId: "mychain"}), stream),
+		"A request not satisfying the Readers policy should be refused")
+	assert.Empty(t, stream.sent)
+}
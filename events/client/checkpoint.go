@@ -0,0 +1,107 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Checkpointer records the number of the last block a consumer has processed,
+// so that the delivery resumes after it
+type Checkpointer interface {
+	// Checkpoint returns the number of the last block processed, or false if
+	// no block has been processed yet
+	Checkpoint() (uint64, bool, error)
+
+	// SetCheckpoint records that the block with the given number was processed
+	SetCheckpoint(blockNumber uint64) error
+}
+
+// memoryCheckpointer keeps the checkpoint in memory, for the lifetime of the client
+type memoryCheckpointer struct {
+	lock        sync.Mutex
+	blockNumber uint64
+	set         bool
+}
+
+func (mc *memoryCheckpointer) Checkpoint() (uint64, bool, error) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	return mc.blockNumber, mc.set, nil
+}
+
+func (mc *memoryCheckpointer) SetCheckpoint(blockNumber uint64) error {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	mc.blockNumber = blockNumber
+	mc.set = true
+	return nil
+}
+
+// fileCheckpointer keeps the checkpoint in a file, replaced atomically on update
+type fileCheckpointer struct {
+	path string
+}
+
+// NewFileCheckpointer returns a Checkpointer storing the checkpoint in the file
+// with the given path. The file is created on the first checkpoint
+func NewFileCheckpointer(path string) Checkpointer {
+	return &fileCheckpointer{path: path}
+}
+
+func (fc *fileCheckpointer) Checkpoint() (uint64, bool, error) {
+	data, err := ioutil.ReadFile(fc.path)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("Error reading checkpoint file %s: %s", fc.path, err)
+	}
+	blockNumber, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("Invalid checkpoint in file %s: %s", fc.path, err)
+	}
+	return blockNumber, true, nil
+}
+
+func (fc *fileCheckpointer) SetCheckpoint(blockNumber uint64) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(fc.path), filepath.Base(fc.path))
+	if err != nil {
+		return fmt.Errorf("Error creating checkpoint file: %s", err)
+	}
+	_, err = tmp.WriteString(strconv.FormatUint(blockNumber, 10) + "\n")
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), fc.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("Error writing checkpoint file %s: %s", fc.path, err)
+	}
+	return nil
+}
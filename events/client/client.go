@@ -0,0 +1,492 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package client delivers the blocks of a channel to a consumer callback. It is
+// notified of the blocks committed by a peer through the events service of the
+// peer, and reads them in full from the ledger of the peer through qscc, with
+// the validation flags of their transactions. The signatures of the ordering
+// service on the blocks are verified before they are delivered. The client
+// reconnects when the stream breaks and resumes after the last block the
+// consumer processed, so that every block is delivered at least once.
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/comm"
+	ledgerutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/core/scc/qscc"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var logger = logging.MustGetLogger("eventsclient")
+
+const (
+	defaultMinRetryInterval = 500 * time.Millisecond
+	defaultMaxRetryInterval = 30 * time.Second
+)
+
+// Consumer processes a block of the channel. The blocks are passed in order; a
+// block whose processing fails is passed again after reconnecting, as is a
+// block delivered again before its checkpoint could be recorded
+type Consumer func(block *cb.Block) error
+
+// BlockVerifier verifies the signatures of the ordering service on a block
+type BlockVerifier interface {
+	VerifyBlock(block *cb.Block) error
+}
+
+// Config holds the settings of a Client
+type Config struct {
+	// Address is the address of the peer the blocks are read from with qscc
+	Address string
+
+	// EventsAddress is the address of the events service of the peer
+	EventsAddress string
+
+	// ChainID is the channel whose blocks are delivered
+	ChainID string
+
+	// TLSRootCertFiles are the PEM files of the root certificates the TLS
+	// certificate of the peer is verified with. TLS is used only if some are given
+	TLSRootCertFiles []string
+
+	// ServerNameOverride overrides the host name the TLS certificate of the
+	// peer is verified against
+	ServerNameOverride string
+
+	// Signer signs the queries of the blocks, and must satisfy the policies
+	// under which qscc serves them
+	Signer msp.SigningIdentity
+
+	// Verifier verifies the signatures of the ordering service on the blocks
+	Verifier BlockVerifier
+
+	// Checkpointer records the last block processed. The checkpoint is kept
+	// in memory if nil
+	Checkpointer Checkpointer
+
+	// StartBlock is the number of the first block delivered without checkpoint
+	StartBlock uint64
+
+	// MinRetryInterval and MaxRetryInterval bound the interval between
+	// reconnections, doubled after each consecutive failure
+	MinRetryInterval time.Duration
+	MaxRetryInterval time.Duration
+}
+
+// peerConn is the connection to the peer the client uses
+type peerConn interface {
+	// Height returns the number of blocks of the channel committed by the peer
+	Height() (uint64, error)
+
+	// Block returns the block of the channel with the given number
+	Block(number uint64) (*cb.Block, error)
+
+	// Next waits for the peer to commit a block of the channel, and returns
+	// its number
+	Next() (uint64, error)
+
+	Close() error
+}
+
+// connector opens a connection to the peer, closed when it is or the context
+// is done. The blocks committed after it is opened are notified by Next
+type connector func(ctx context.Context) (peerConn, error)
+
+// Client delivers the blocks of a channel to a Consumer
+type Client struct {
+	config   Config
+	consumer Consumer
+	connect  connector
+
+	// last is the header of the last block delivered, which the next one chains to
+	last *cb.BlockHeader
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+	done   chan struct{}
+}
+
+// NewClient returns a new Client delivering the blocks of the configured
+// channel to the consumer once started
+func NewClient(config Config, consumer Consumer) (*Client, error) {
+	if config.Address == "" {
+		return nil, errors.New("Address of the peer not set")
+	}
+	if config.EventsAddress == "" {
+		return nil, errors.New("Address of the events service of the peer not set")
+	}
+	if config.ChainID == "" {
+		return nil, errors.New("Channel not set")
+	}
+	if config.Signer == nil {
+		return nil, errors.New("Signer not set")
+	}
+	if config.Verifier == nil {
+		return nil, errors.New("Verifier not set")
+	}
+	if consumer == nil {
+		return nil, errors.New("Consumer not set")
+	}
+
+	var creds credentials.TransportCredentials
+	if len(config.TLSRootCertFiles) > 0 {
+		roots := x509.NewCertPool()
+		for _, file := range config.TLSRootCertFiles {
+			pem, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("Error reading TLS root certificate file %s: %s", file, err)
+			}
+			if !roots.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("Error decoding TLS root certificate file %s", file)
+			}
+		}
+		creds = credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: config.ServerNameOverride})
+	}
+
+	return newClient(config, consumer, func(ctx context.Context) (peerConn, error) {
+		return dialPeer(ctx, &config, creds)
+	}), nil
+}
+
+func newClient(config Config, consumer Consumer, connect connector) *Client {
+	if config.Checkpointer == nil {
+		config.Checkpointer = &memoryCheckpointer{}
+	}
+	if config.MinRetryInterval <= 0 {
+		config.MinRetryInterval = defaultMinRetryInterval
+	}
+	if config.MaxRetryInterval < config.MinRetryInterval {
+		config.MaxRetryInterval = defaultMaxRetryInterval
+		if config.MaxRetryInterval < config.MinRetryInterval {
+			config.MaxRetryInterval = config.MinRetryInterval
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Client{
+		config:   config,
+		consumer: consumer,
+		connect:  connect,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start starts delivering the blocks to the consumer, until Stop is called
+func (c *Client) Start() {
+	go c.run()
+}
+
+// Stop stops the delivery, waiting for the block being processed if any
+func (c *Client) Stop() {
+	c.once.Do(func() {
+		c.cancel()
+		<-c.done
+	})
+}
+
+func (c *Client) run() {
+	defer close(c.done)
+	interval := c.config.MinRetryInterval
+	for {
+		delivered, err := c.deliver()
+		if c.ctx.Err() != nil {
+			return
+		}
+		if delivered {
+			interval = c.config.MinRetryInterval
+		}
+		logger.Warningf("Error delivering the blocks of channel %s from %s, reconnecting in %s: %s", c.config.ChainID, c.config.Address, interval, err)
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > c.config.MaxRetryInterval {
+			interval = c.config.MaxRetryInterval
+		}
+	}
+}
+
+// deliver delivers the blocks following the checkpoint, the ones committed
+// before connecting and then the ones notified, until it fails, returning
+// whether any block was processed
+func (c *Client) deliver() (bool, error) {
+	next := c.config.StartBlock
+	checkpoint, ok, err := c.config.Checkpointer.Checkpoint()
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		next = checkpoint + 1
+	}
+
+	conn, err := c.connect(c.ctx)
+	if err != nil {
+		return false, fmt.Errorf("Error connecting: %s", err)
+	}
+	defer conn.Close()
+
+	height, err := conn.Height()
+	if err != nil {
+		return false, fmt.Errorf("Error reading the height of the channel: %s", err)
+	}
+	logger.Debugf("Delivering the blocks of channel %s from block %d", c.config.ChainID, next)
+
+	delivered := false
+	for {
+		for ; next < height; next++ {
+			if c.ctx.Err() != nil {
+				return delivered, c.ctx.Err()
+			}
+			block, err := conn.Block(next)
+			if err != nil {
+				return delivered, fmt.Errorf("Error reading block %d: %s", next, err)
+			}
+			if err = c.verify(block, next); err != nil {
+				return delivered, err
+			}
+			if err = c.consumer(block); err != nil {
+				return delivered, fmt.Errorf("Error consuming block %d: %s", next, err)
+			}
+			if err = c.config.Checkpointer.SetCheckpoint(next); err != nil {
+				return delivered, fmt.Errorf("Error recording the checkpoint of block %d: %s", next, err)
+			}
+			c.last = block.Header
+			delivered = true
+		}
+
+		committed, err := conn.Next()
+		if err != nil {
+			return delivered, err
+		}
+		if committed >= height {
+			height = committed + 1
+		}
+	}
+}
+
+// verify checks that block is the one with the given number, that it carries
+// the validation flags of its transactions, matches its header and chains to
+// the last block delivered, and that its header is signed by the ordering service
+func (c *Client) verify(block *cb.Block, number uint64) error {
+	if block.Header == nil || block.Header.Number != number {
+		return fmt.Errorf("Expected block %d, received %v", number, block.Header)
+	}
+	if block.Data == nil || !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return fmt.Errorf("Data of block %d does not match its header", number)
+	}
+	if c.last != nil && c.last.Number+1 == number && !bytes.Equal(block.Header.PreviousHash, c.last.Hash()) {
+		return fmt.Errorf("Block %d does not chain to block %d", number, c.last.Number)
+	}
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_TRANSACTIONS_FILTER) ||
+		len(block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER]) == 0 {
+		return fmt.Errorf("Block %d has no validation flags", number)
+	}
+	if _, err := ledgerutil.GetTxsFilter(block); err != nil {
+		return fmt.Errorf("Error decoding the validation flags of block %d: %s", number, err)
+	}
+	if err := c.config.Verifier.VerifyBlock(block); err != nil {
+		return fmt.Errorf("Error verifying the signatures of block %d: %s", number, err)
+	}
+	return nil
+}
+
+// policyVerifier verifies that the signatures on the blocks satisfy a policy
+type policyVerifier struct {
+	policy policies.Policy
+}
+
+// NewPolicyVerifier returns a BlockVerifier checking that the signatures on the
+// header of a block satisfy policy, one the orderers of the channel satisfy
+func NewPolicyVerifier(policy policies.Policy) BlockVerifier {
+	return &policyVerifier{policy: policy}
+}
+
+// VerifyBlock implements the BlockVerifier interface
+func (pv *policyVerifier) VerifyBlock(block *cb.Block) error {
+	metadata, err := utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		return fmt.Errorf("Error unmarshaling the signatures: %s", err)
+	}
+	signatureSet := make([]*cb.SignedData, len(metadata.Signatures))
+	for i, signature := range metadata.Signatures {
+		signatureHeader := &cb.SignatureHeader{}
+		if err = proto.Unmarshal(signature.SignatureHeader, signatureHeader); err != nil {
+			return fmt.Errorf("Error unmarshaling the header of signature %d: %s", i, err)
+		}
+		signatureSet[i] = &cb.SignedData{
+			Data:      util.ConcatenateBytes(metadata.Value, signature.SignatureHeader, block.Header.Bytes()),
+			Identity:  signatureHeader.Creator,
+			Signature: signature.Signature,
+		}
+	}
+	return pv.policy.Evaluate(signatureSet)
+}
+
+// grpcPeerConn reads the blocks from qscc, and is notified of the blocks
+// committed by the events service
+type grpcPeerConn struct {
+	ctx        context.Context
+	config     *Config
+	conn       *grpc.ClientConn
+	eventsConn *grpc.ClientConn
+	events     pb.Events_ChatClient
+}
+
+// dialPeer registers for the block events with the events service of the peer,
+// and connects to the peer
+func dialPeer(ctx context.Context, config *Config, creds credentials.TransportCredentials) (*grpcPeerConn, error) {
+	pc := &grpcPeerConn{ctx: ctx, config: config}
+	var err error
+	if pc.eventsConn, err = comm.NewClientConnectionWithAddress(config.EventsAddress, true, creds != nil, creds); err != nil {
+		return nil, err
+	}
+	if pc.conn, err = comm.NewClientConnectionWithAddress(config.Address, true, creds != nil, creds); err != nil {
+		pc.eventsConn.Close()
+		return nil, err
+	}
+	if err = pc.register(); err != nil {
+		pc.Close()
+		return nil, err
+	}
+	return pc, nil
+}
+
+func (pc *grpcPeerConn) register() error {
+	var err error
+	if pc.events, err = pb.NewEventsClient(pc.eventsConn).Chat(pc.ctx); err != nil {
+		return err
+	}
+	interest := &pb.Interest{EventType: pb.EventType_BLOCK, ChainID: pc.config.ChainID}
+	if err = pc.events.Send(&pb.Event{Event: &pb.Event_Register{Register: &pb.Register{Events: []*pb.Interest{interest}}}}); err != nil {
+		return fmt.Errorf("Error registering for the block events: %s", err)
+	}
+	// the events service echoes the registration once done
+	for {
+		event, err := pc.events.Recv()
+		if err != nil {
+			return fmt.Errorf("Error registering for the block events: %s", err)
+		}
+		if _, ok := event.Event.(*pb.Event_Register); ok {
+			return nil
+		}
+	}
+}
+
+func (pc *grpcPeerConn) Height() (uint64, error) {
+	payload, err := pc.query(qscc.GetChainInfo)
+	if err != nil {
+		return 0, err
+	}
+	info := &cb.BlockchainInfo{}
+	if err = proto.Unmarshal(payload, info); err != nil {
+		return 0, fmt.Errorf("Error unmarshaling chain info: %s", err)
+	}
+	return info.Height, nil
+}
+
+func (pc *grpcPeerConn) Block(number uint64) (*cb.Block, error) {
+	payload, err := pc.query(qscc.GetBlockByNumber, strconv.FormatUint(number, 10))
+	if err != nil {
+		return nil, err
+	}
+	return utils.GetBlockFromBlockBytes(payload)
+}
+
+func (pc *grpcPeerConn) Next() (uint64, error) {
+	for {
+		event, err := pc.events.Recv()
+		if err != nil {
+			return 0, err
+		}
+		block := event.GetBlock()
+		if block == nil || block.Header == nil {
+			continue
+		}
+		if chainID, err := utils.GetChainIDFromBlock(block); err != nil || chainID != pc.config.ChainID {
+			continue
+		}
+		return block.Header.Number, nil
+	}
+}
+
+func (pc *grpcPeerConn) Close() error {
+	pc.eventsConn.Close()
+	return pc.conn.Close()
+}
+
+// query invokes fname of qscc on the channel
+func (pc *grpcPeerConn) query(fname string, args ...string) ([]byte, error) {
+	input := &pb.ChaincodeInput{Args: [][]byte{[]byte(fname), []byte(pc.config.ChainID)}}
+	for _, arg := range args {
+		input.Args = append(input.Args, []byte(arg))
+	}
+	invocation := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_GOLANG,
+		ChaincodeId: &pb.ChaincodeID{Name: "qscc"},
+		Input:       input,
+	}}
+
+	creator, err := pc.config.Signer.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Error serializing identity: %s", err)
+	}
+	prop, err := utils.CreateProposalFromCIS(util.GenerateUUID(), cb.HeaderType_ENDORSER_TRANSACTION, pc.config.ChainID, invocation, creator)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating proposal for %s: %s", fname, err)
+	}
+	signedProp, err := utils.GetSignedProposal(prop, pc.config.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating signed proposal: %s", err)
+	}
+
+	resp, err := pb.NewEndorserClient(pc.conn).ProcessProposal(pc.ctx, signedProp)
+	if err != nil {
+		return nil, fmt.Errorf("Error querying %s: %s", fname, err)
+	}
+	if resp == nil || resp.Response == nil {
+		return nil, fmt.Errorf("Nil proposal response for %s", fname)
+	}
+	if resp.Response.Status != 0 && resp.Response.Status != 200 {
+		return nil, fmt.Errorf("Bad proposal response for %s: %d %s", fname, resp.Response.Status, resp.Response.Message)
+	}
+	if resp.Response.Message == qscc.RedactedView {
+		return nil, fmt.Errorf("Peer returned a redacted view for %s, the signer does not satisfy the FullReaders policy of channel %s", fname, pc.config.ChainID)
+	}
+	return resp.Response.Payload, nil
+}
@@ -0,0 +1,377 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/util"
+	ledgerutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+// mockPeer serves the blocks of a channel, breaking each connection after
+// breakAfter blocks read if set
+type mockPeer struct {
+	lock       sync.Mutex
+	blocks     []*cb.Block
+	breakAfter int
+	heights    int
+	committed  chan uint64
+}
+
+type mockConn struct {
+	ctx    context.Context
+	peer   *mockPeer
+	read   int
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newMockPeer(blocks []*cb.Block) *mockPeer {
+	return &mockPeer{blocks: blocks, committed: make(chan uint64, 100)}
+}
+
+// commit adds the blocks to the ledger of the peer and notifies them
+func (mp *mockPeer) commit(blocks []*cb.Block) {
+	mp.lock.Lock()
+	mp.blocks = append(mp.blocks, blocks...)
+	mp.lock.Unlock()
+	for _, block := range blocks {
+		mp.committed <- block.Header.Number
+	}
+}
+
+func (mp *mockPeer) connect(ctx context.Context) (peerConn, error) {
+	return &mockConn{ctx: ctx, peer: mp, closed: make(chan struct{})}, nil
+}
+
+func (mc *mockConn) Height() (uint64, error) {
+	mc.peer.lock.Lock()
+	defer mc.peer.lock.Unlock()
+	mc.peer.heights++
+	return uint64(len(mc.peer.blocks)), nil
+}
+
+func (mc *mockConn) Block(number uint64) (*cb.Block, error) {
+	mc.peer.lock.Lock()
+	defer mc.peer.lock.Unlock()
+	if mc.peer.breakAfter > 0 && mc.read == mc.peer.breakAfter {
+		return nil, errors.New("connection broken")
+	}
+	if number >= uint64(len(mc.peer.blocks)) {
+		return nil, fmt.Errorf("no block %d", number)
+	}
+	mc.read++
+	return mc.peer.blocks[number], nil
+}
+
+func (mc *mockConn) Next() (uint64, error) {
+	select {
+	case number := <-mc.peer.committed:
+		return number, nil
+	case <-mc.ctx.Done():
+		return 0, mc.ctx.Err()
+	case <-mc.closed:
+		return 0, errors.New("connection closed")
+	}
+}
+
+func (mc *mockConn) Close() error {
+	mc.once.Do(func() { close(mc.closed) })
+	return nil
+}
+
+// mockVerifier accepts the blocks but the ones listed in rejected
+type mockVerifier struct {
+	rejected map[uint64]bool
+}
+
+func (mv *mockVerifier) VerifyBlock(block *cb.Block) error {
+	if mv.rejected[block.Header.Number] {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+type mockSigningIdentity struct {
+	msp.SigningIdentity
+}
+
+// mockPolicy records the signature sets it evaluates
+type mockPolicy struct {
+	signatureSet []*cb.SignedData
+}
+
+func (mp *mockPolicy) Evaluate(signatureSet []*cb.SignedData) error {
+	mp.signatureSet = signatureSet
+	if len(signatureSet) == 0 {
+		return errors.New("no signature")
+	}
+	return nil
+}
+
+// makeBlocks makes the blocks from first on, chained to prev, each with a
+// transaction and its validation flags
+func makeBlocks(prev *cb.Block, n int) []*cb.Block {
+	var first uint64
+	var prevHash []byte
+	if prev != nil {
+		first = prev.Header.Number + 1
+		prevHash = prev.Header.Hash()
+	}
+	blocks := make([]*cb.Block, n)
+	for i := range blocks {
+		block := cb.NewBlock(first+uint64(i), prevHash)
+		block.Data.Data = [][]byte{[]byte(fmt.Sprintf("tx%d", block.Header.Number))}
+		block.Header.DataHash = block.Data.Hash()
+		ledgerutil.SetTxsFilter(block, ledgerutil.NewFilterBitArray(1), false)
+		blocks[i] = block
+		prevHash = block.Header.Hash()
+	}
+	return blocks
+}
+
+// collector consumes the blocks, failing on the blocks listed in failures once each
+type collector struct {
+	lock     sync.Mutex
+	numbers  []uint64
+	failures map[uint64]bool
+	received chan struct{}
+}
+
+func newCollector(failures ...uint64) *collector {
+	c := &collector{failures: map[uint64]bool{}, received: make(chan struct{}, 100)}
+	for _, number := range failures {
+		c.failures[number] = true
+	}
+	return c
+}
+
+func (c *collector) consume(block *cb.Block) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.failures[block.Header.Number] {
+		delete(c.failures, block.Header.Number)
+		return errors.New("consumer failure")
+	}
+	c.numbers = append(c.numbers, block.Header.Number)
+	c.received <- struct{}{}
+	return nil
+}
+
+func (c *collector) waitFor(t *testing.T, n int) []uint64 {
+	for i := 0; i < n; i++ {
+		select {
+		case <-c.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for block %d", i)
+		}
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]uint64{}, c.numbers...)
+}
+
+func (c *collector) assertNone(t *testing.T) {
+	select {
+	case <-c.received:
+		t.Fatalf("Unexpected block delivered")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func testConfig() Config {
+	return Config{
+		Address:          "peer:7051",
+		EventsAddress:    "peer:7053",
+		ChainID:          "testchain",
+		Verifier:         &mockVerifier{},
+		MinRetryInterval: time.Millisecond,
+		MaxRetryInterval: 10 * time.Millisecond,
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	consumer := func(*cb.Block) error { return nil }
+	signer := &mockSigningIdentity{}
+	config := testConfig()
+	config.Signer = signer
+
+	for _, change := range []func(*Config){
+		func(c *Config) { c.Address = "" },
+		func(c *Config) { c.EventsAddress = "" },
+		func(c *Config) { c.ChainID = "" },
+		func(c *Config) { c.Signer = nil },
+		func(c *Config) { c.Verifier = nil },
+		func(c *Config) { c.TLSRootCertFiles = []string{"/nonexistent/root.pem"} },
+	} {
+		invalid := config
+		change(&invalid)
+		_, err := NewClient(invalid, consumer)
+		assert.Error(t, err)
+	}
+	_, err := NewClient(config, nil)
+	assert.Error(t, err, "Expected error without consumer")
+
+	client, err := NewClient(config, consumer)
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestDeliverReconnects(t *testing.T) {
+	peer := newMockPeer(makeBlocks(nil, 10))
+	peer.breakAfter = 3
+	consumer := newCollector()
+	client := newClient(testConfig(), consumer.consume, peer.connect)
+	client.Start()
+	defer client.Stop()
+
+	assert.Equal(t, []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, consumer.waitFor(t, 10))
+	client.Stop()
+
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+	assert.True(t, peer.heights >= 4, "Expected a connection per 3 blocks")
+}
+
+func TestDeliverCommittedBlocks(t *testing.T) {
+	blocks := makeBlocks(nil, 2)
+	peer := newMockPeer(blocks)
+	consumer := newCollector()
+	client := newClient(testConfig(), consumer.consume, peer.connect)
+	client.Start()
+	defer client.Stop()
+
+	assert.Equal(t, []uint64{0, 1}, consumer.waitFor(t, 2))
+	peer.commit(makeBlocks(blocks[1], 3))
+	assert.Equal(t, []uint64{0, 1, 2, 3, 4}, consumer.waitFor(t, 3))
+}
+
+func TestDeliverRedeliversFailedBlock(t *testing.T) {
+	peer := newMockPeer(makeBlocks(nil, 5))
+	consumer := newCollector(2)
+	client := newClient(testConfig(), consumer.consume, peer.connect)
+	client.Start()
+	defer client.Stop()
+
+	assert.Equal(t, []uint64{0, 1, 2, 3, 4}, consumer.waitFor(t, 5))
+}
+
+func TestDeliverVerifiesBlocks(t *testing.T) {
+	for name, corrupt := range map[string]func(blocks []*cb.Block){
+		"signature": func(blocks []*cb.Block) {},
+		"data":      func(blocks []*cb.Block) { blocks[1].Data.Data[0] = []byte("forged") },
+		"chain":     func(blocks []*cb.Block) { blocks[1].Header.PreviousHash = []byte("forged") },
+		"flags": func(blocks []*cb.Block) {
+			blocks[1].Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER] = nil
+		},
+	} {
+		blocks := makeBlocks(nil, 3)
+		corrupt(blocks)
+		config := testConfig()
+		if name == "signature" {
+			config.Verifier = &mockVerifier{rejected: map[uint64]bool{1: true}}
+		}
+		consumer := newCollector()
+		client := newClient(config, consumer.consume, newMockPeer(blocks).connect)
+		client.Start()
+
+		assert.Equal(t, []uint64{0}, consumer.waitFor(t, 1), name)
+		consumer.assertNone(t)
+		client.Stop()
+	}
+}
+
+func TestPolicyVerifier(t *testing.T) {
+	block := makeBlocks(nil, 1)[0]
+	signatureHeader := utils.MarshalOrPanic(&cb.SignatureHeader{Creator: []byte("orderer")})
+	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&cb.Metadata{
+		Signatures: []*cb.MetadataSignature{{SignatureHeader: signatureHeader, Signature: []byte("signature")}},
+	})
+
+	policy := &mockPolicy{}
+	assert.NoError(t, NewPolicyVerifier(policy).VerifyBlock(block))
+	assert.Equal(t, []*cb.SignedData{{
+		Data:      util.ConcatenateBytes(nil, signatureHeader, block.Header.Bytes()),
+		Identity:  []byte("orderer"),
+		Signature: []byte("signature"),
+	}}, policy.signatureSet)
+
+	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = nil
+	assert.Error(t, NewPolicyVerifier(policy).VerifyBlock(block), "Expected error without signature")
+}
+
+func TestDeliverResumesFromCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventsclient")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config := testConfig()
+	config.Checkpointer = NewFileCheckpointer(filepath.Join(dir, "checkpoint"))
+	blocks := makeBlocks(nil, 3)
+	peer := newMockPeer(blocks)
+
+	consumer := newCollector()
+	client := newClient(config, consumer.consume, peer.connect)
+	client.Start()
+	assert.Equal(t, []uint64{0, 1, 2}, consumer.waitFor(t, 3))
+	client.Stop()
+
+	peer.lock.Lock()
+	peer.blocks = append(peer.blocks, makeBlocks(blocks[2], 3)...)
+	peer.lock.Unlock()
+
+	consumer = newCollector()
+	client = newClient(config, consumer.consume, peer.connect)
+	client.Start()
+	assert.Equal(t, []uint64{3, 4, 5}, consumer.waitFor(t, 3))
+	client.Stop()
+}
+
+func TestFileCheckpointer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventsclient")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint")
+	checkpointer := NewFileCheckpointer(path)
+	_, ok, err := checkpointer.Checkpoint()
+	assert.NoError(t, err)
+	assert.False(t, ok, "Expected no checkpoint before the first one is set")
+
+	assert.NoError(t, checkpointer.SetCheckpoint(42))
+	blockNumber, ok, err := NewFileCheckpointer(path).Checkpoint()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(42), blockNumber)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("garbage"), 0644))
+	_, _, err = checkpointer.Checkpoint()
+	assert.Error(t, err, "Expected error with an invalid checkpoint")
+}
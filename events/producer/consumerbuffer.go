@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package producer
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+//SlowConsumerPolicy is what the producer does with an event for a consumer
+//whose buffer is full
+type SlowConsumerPolicy string
+
+const (
+	//DropOldest drops the oldest buffered event of the consumer to make room
+	DropOldest SlowConsumerPolicy = "drop-oldest"
+	//Disconnect drops the event and disconnects the consumer
+	Disconnect SlowConsumerPolicy = "disconnect"
+	//BlockWithTimeout blocks the producer until the consumer makes room or
+	//the timeout expires, in which case the event is dropped
+	BlockWithTimeout SlowConsumerPolicy = "block"
+)
+
+//ConsumerBufferConfig bounds the events buffered for every consumer
+type ConsumerBufferConfig struct {
+	//BufferSize is the number of events buffered for a consumer
+	BufferSize int
+
+	//Policy applies when the buffer of a consumer is full
+	Policy SlowConsumerPolicy
+
+	//Timeout is how long BlockWithTimeout blocks, 0 blocks until the event
+	//is buffered
+	Timeout time.Duration
+}
+
+//defaultConsumerBufferConfig applies backpressure to the producer as the
+//events used to be sent synchronously to the consumers
+var defaultConsumerBufferConfig = ConsumerBufferConfig{BufferSize: 100, Policy: BlockWithTimeout}
+
+//ConsumerMetrics counts the events which could not be delivered to slow consumers
+type ConsumerMetrics struct {
+	//DroppedEvents is the number of events dropped for all the consumers
+	DroppedEvents uint64
+
+	//Disconnections is the number of consumers disconnected for falling behind
+	Disconnections uint64
+}
+
+var consumerMetrics ConsumerMetrics
+
+//SetConsumerBufferConfig sets the buffering of the consumers which connect
+//from now on
+func SetConsumerBufferConfig(config ConsumerBufferConfig) error {
+	if config.BufferSize <= 0 {
+		return fmt.Errorf("consumer buffer size must be positive, got %d", config.BufferSize)
+	}
+	switch config.Policy {
+	case DropOldest, Disconnect, BlockWithTimeout:
+	default:
+		return fmt.Errorf("unknown slow consumer policy %s", config.Policy)
+	}
+	if config.Timeout < 0 {
+		return fmt.Errorf("slow consumer timeout must not be negative, got %s", config.Timeout)
+	}
+	gEventProcessor.Lock()
+	gEventProcessor.consumerConfig = config
+	gEventProcessor.Unlock()
+	return nil
+}
+
+//GetConsumerMetrics returns the counts of the events which could not be
+//delivered to slow consumers since the producer started
+func GetConsumerMetrics() ConsumerMetrics {
+	return ConsumerMetrics{
+		DroppedEvents:  atomic.LoadUint64(&consumerMetrics.DroppedEvents),
+		Disconnections: atomic.LoadUint64(&consumerMetrics.Disconnections),
+	}
+}
@@ -21,7 +21,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hyperledger/fabric/common/crypto"
 	pb "github.com/hyperledger/fabric/protos/peer"
+
+	"github.com/golang/protobuf/proto"
 )
 
 //---- event hub framework ----
@@ -203,6 +206,12 @@ type eventProcessor struct {
 	//if 0, if buffer full, will block and guarantee the event will be sent out
 	//if > 0, if buffer full, blocks till timeout
 	timeout int
+
+	//signer of the integrity proofs attached to events, nil if they are not attached
+	signer crypto.LocalSigner
+
+	//buffering of the events of every consumer
+	consumerConfig ConsumerBufferConfig
 }
 
 //global eventProcessor singleton created by initializeEvents. Openchain producers
@@ -228,7 +237,7 @@ func (ep *eventProcessor) start() {
 
 		hl.foreach(e, func(h *handler) {
 			if e.Event != nil {
-				h.SendMessage(e)
+				h.queue(e)
 			}
 		})
 
@@ -241,7 +250,7 @@ func initializeEvents(bufferSize uint, tout int) {
 		panic("should not be called twice")
 	}
 
-	gEventProcessor = &eventProcessor{eventConsumers: make(map[pb.EventType]handlerList), eventChannel: make(chan *pb.Event, bufferSize), timeout: tout}
+	gEventProcessor = &eventProcessor{eventConsumers: make(map[pb.EventType]handlerList), eventChannel: make(chan *pb.Event, bufferSize), timeout: tout, consumerConfig: defaultConsumerBufferConfig}
 
 	addInternalEventTypes()
 
@@ -312,6 +321,13 @@ func Send(e *pb.Event) error {
 		return nil
 	}
 
+	if gEventProcessor.signer != nil {
+		if err := addIntegrityProof(e, gEventProcessor.signer); err != nil {
+			producerLogger.Errorf("Error attaching integrity proof to event: %s", err)
+			return err
+		}
+	}
+
 	if gEventProcessor.timeout < 0 {
 		select {
 		case gEventProcessor.eventChannel <- e:
@@ -330,3 +346,21 @@ func Send(e *pb.Event) error {
 
 	return nil
 }
+
+//EnableIntegrityProofs makes the producer attach to every event an integrity
+//proof signed by signer, so that consumers can verify the events end to end
+func EnableIntegrityProofs(signer crypto.LocalSigner) {
+	gEventProcessor.signer = signer
+}
+
+//addIntegrityProof sets the integrity proof of e, computed over the marshaled
+//event without any integrity proof
+func addIntegrityProof(e *pb.Event, signer crypto.LocalSigner) error {
+	e.Integrity = nil
+	content, err := proto.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("error marshaling event: %s", err)
+	}
+	e.Integrity, err = crypto.NewIntegrityProof(signer, content)
+	return err
+}
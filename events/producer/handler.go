@@ -19,27 +19,66 @@ package producer
 import (
 	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
 type handler struct {
+	sync.Mutex
 	ChatStream       pb.Events_ChatServer
 	interestedEvents map[string]*pb.Interest
+
+	//sendLock serializes the sends to ChatStream
+	sendLock sync.Mutex
+
+	//events waiting to be sent to the consumer
+	sendQueue    chan *pb.Event
+	bufferConfig ConsumerBufferConfig
+	dropped      uint64
+
+	//stopChan is closed when the handler stops
+	stopChan chan struct{}
+
+	//disconnectChan is closed when the consumer must be disconnected
+	disconnectChan chan struct{}
+	disconnectOnce sync.Once
+	disconnectErr  error
 }
 
 func newEventHandler(stream pb.Events_ChatServer) (*handler, error) {
+	config := defaultConsumerBufferConfig
+	if gEventProcessor != nil {
+		gEventProcessor.RLock()
+		config = gEventProcessor.consumerConfig
+		gEventProcessor.RUnlock()
+	}
 	d := &handler{
-		ChatStream: stream,
+		ChatStream:     stream,
+		sendQueue:      make(chan *pb.Event, config.BufferSize),
+		bufferConfig:   config,
+		stopChan:       make(chan struct{}),
+		disconnectChan: make(chan struct{}),
 	}
 	d.interestedEvents = make(map[string]*pb.Interest)
+	go d.sendQueued()
 	return d, nil
 }
 
 // Stop stops this handler
 func (d *handler) Stop() error {
+	//unblock the producer before deregistering, as it holds the lock of the
+	//handler lists while it waits on the buffer
+	close(d.stopChan)
 	d.deregisterAll()
+	d.Lock()
 	d.interestedEvents = nil
+	d.Unlock()
+	if dropped := atomic.LoadUint64(&d.dropped); dropped > 0 {
+		producerLogger.Warningf("%d events were dropped for a slow consumer", dropped)
+	}
 	return nil
 }
 
@@ -66,7 +105,9 @@ func (d *handler) register(iMsg []*pb.Interest) error {
 			producerLogger.Errorf("could not register %s: %s", v, err)
 			continue
 		}
+		d.Lock()
 		d.interestedEvents[getInterestKey(*v)] = v
+		d.Unlock()
 	}
 
 	return nil
@@ -78,12 +119,16 @@ func (d *handler) deregister(iMsg []*pb.Interest) error {
 			producerLogger.Errorf("could not deregister %s", v)
 			continue
 		}
+		d.Lock()
 		delete(d.interestedEvents, getInterestKey(*v))
+		d.Unlock()
 	}
 	return nil
 }
 
 func (d *handler) deregisterAll() {
+	d.Lock()
+	defer d.Unlock()
 	for k, v := range d.interestedEvents {
 		if err := deRegisterHandler(v, d); err != nil {
 			producerLogger.Errorf("could not deregister %s", v)
@@ -121,9 +166,91 @@ func (d *handler) HandleMessage(msg *pb.Event) error {
 
 // SendMessage sends a message to the remote PEER through the stream
 func (d *handler) SendMessage(msg *pb.Event) error {
+	d.sendLock.Lock()
 	err := d.ChatStream.Send(msg)
+	d.sendLock.Unlock()
 	if err != nil {
 		return fmt.Errorf("Error Sending message through ChatStream: %s", err)
 	}
 	return nil
 }
+
+//queue buffers an event for the consumer, applying the slow consumer
+//policy if the buffer is full
+func (d *handler) queue(msg *pb.Event) {
+	select {
+	case <-d.stopChan:
+		return
+	case <-d.disconnectChan:
+		return
+	case d.sendQueue <- msg:
+		return
+	default:
+	}
+
+	switch d.bufferConfig.Policy {
+	case DropOldest:
+		for {
+			select {
+			case <-d.sendQueue:
+				d.drop()
+			default:
+			}
+			select {
+			case d.sendQueue <- msg:
+				return
+			default:
+			}
+		}
+	case Disconnect:
+		d.drop()
+		if d.disconnect(fmt.Errorf("consumer fell behind by more than %d events", d.bufferConfig.BufferSize)) {
+			atomic.AddUint64(&consumerMetrics.Disconnections, 1)
+		}
+	default:
+		var timeout <-chan time.Time
+		if d.bufferConfig.Timeout > 0 {
+			timeout = time.After(d.bufferConfig.Timeout)
+		}
+		select {
+		case d.sendQueue <- msg:
+		case <-d.stopChan:
+		case <-d.disconnectChan:
+		case <-timeout:
+			d.drop()
+		}
+	}
+}
+
+func (d *handler) drop() {
+	atomic.AddUint64(&d.dropped, 1)
+	atomic.AddUint64(&consumerMetrics.DroppedEvents, 1)
+}
+
+//disconnect makes Chat end the stream of the consumer with err, it returns
+//false if the consumer was already being disconnected
+func (d *handler) disconnect(err error) bool {
+	disconnected := false
+	d.disconnectOnce.Do(func() {
+		producerLogger.Warningf("Disconnecting consumer: %s", err)
+		d.disconnectErr = err
+		close(d.disconnectChan)
+		disconnected = true
+	})
+	return disconnected
+}
+
+//sendQueued sends the buffered events until the handler stops
+func (d *handler) sendQueued() {
+	for {
+		select {
+		case msg := <-d.sendQueue:
+			if err := d.SendMessage(msg); err != nil {
+				d.disconnect(err)
+				return
+			}
+		case <-d.stopChan:
+			return
+		}
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package producer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+//slowStream is a consumer stream which sends only the events it is released for
+type slowStream struct {
+	grpc.ServerStream
+	release chan struct{}
+	sent    chan *pb.Event
+}
+
+func newSlowStream() *slowStream {
+	return &slowStream{release: make(chan struct{}, 100), sent: make(chan *pb.Event, 100)}
+}
+
+func (s *slowStream) Send(e *pb.Event) error {
+	<-s.release
+	s.sent <- e
+	return nil
+}
+
+func (s *slowStream) Recv() (*pb.Event, error) {
+	select {}
+}
+
+func blockEvent(number uint64) *pb.Event {
+	return &pb.Event{Event: &pb.Event_Block{Block: &common.Block{Header: &common.BlockHeader{Number: number}}}}
+}
+
+func newTestHandler(t *testing.T, config ConsumerBufferConfig) (*handler, *slowStream) {
+	gEventProcessor = &eventProcessor{consumerConfig: config}
+	defer func() { gEventProcessor = nil }()
+	stream := newSlowStream()
+	h, err := newEventHandler(stream)
+	assert.NoError(t, err)
+	return h, stream
+}
+
+//waitSending waits for the sender of h to take the buffered event
+func waitSending(t *testing.T, h *handler) {
+	for start := time.Now(); len(h.sendQueue) > 0; time.Sleep(5 * time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("The buffered event was not taken by the sender")
+		}
+	}
+}
+
+func TestSetConsumerBufferConfig(t *testing.T) {
+	gEventProcessor = &eventProcessor{consumerConfig: defaultConsumerBufferConfig}
+	defer func() { gEventProcessor = nil }()
+
+	assert.Error(t, SetConsumerBufferConfig(ConsumerBufferConfig{BufferSize: 0, Policy: DropOldest}))
+	assert.Error(t, SetConsumerBufferConfig(ConsumerBufferConfig{BufferSize: 10, Policy: "drop-newest"}))
+	assert.Error(t, SetConsumerBufferConfig(ConsumerBufferConfig{BufferSize: 10, Policy: BlockWithTimeout, Timeout: -time.Second}))
+	config := ConsumerBufferConfig{BufferSize: 10, Policy: Disconnect}
+	assert.NoError(t, SetConsumerBufferConfig(config))
+	assert.Equal(t, config, gEventProcessor.consumerConfig)
+}
+
+func TestDropOldest(t *testing.T) {
+	h, stream := newTestHandler(t, ConsumerBufferConfig{BufferSize: 2, Policy: DropOldest})
+	defer h.Stop()
+	before := GetConsumerMetrics()
+
+	// the first event is taken by the sender, which waits on the stream
+	h.queue(blockEvent(0))
+	waitSending(t, h)
+	for i := uint64(1); i <= 4; i++ {
+		h.queue(blockEvent(i))
+	}
+	assert.Equal(t, uint64(2), GetConsumerMetrics().DroppedEvents-before.DroppedEvents)
+
+	for i := 0; i < 3; i++ {
+		stream.release <- struct{}{}
+	}
+	for _, expected := range []uint64{0, 3, 4} {
+		e := <-stream.sent
+		assert.Equal(t, expected, e.GetBlock().Header.Number)
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	h, _ := newTestHandler(t, ConsumerBufferConfig{BufferSize: 1, Policy: Disconnect})
+	defer h.Stop()
+	before := GetConsumerMetrics()
+
+	h.queue(blockEvent(0))
+	waitSending(t, h)
+	h.queue(blockEvent(1))
+	h.queue(blockEvent(2))
+	h.queue(blockEvent(3))
+
+	select {
+	case <-h.disconnectChan:
+	case <-time.After(time.Second):
+		t.Fatal("The slow consumer should have been disconnected")
+	}
+	assert.Error(t, h.disconnectErr)
+	after := GetConsumerMetrics()
+	assert.Equal(t, uint64(1), after.Disconnections-before.Disconnections)
+	assert.Equal(t, uint64(1), after.DroppedEvents-before.DroppedEvents)
+}
+
+func TestBlockWithTimeout(t *testing.T) {
+	h, stream := newTestHandler(t, ConsumerBufferConfig{BufferSize: 1, Policy: BlockWithTimeout, Timeout: 50 * time.Millisecond})
+	defer h.Stop()
+	before := GetConsumerMetrics()
+
+	h.queue(blockEvent(0))
+	waitSending(t, h)
+	h.queue(blockEvent(1))
+	start := time.Now()
+	h.queue(blockEvent(2))
+	assert.True(t, time.Since(start) >= 50*time.Millisecond, "The producer should have been blocked until the timeout")
+	assert.Equal(t, uint64(1), GetConsumerMetrics().DroppedEvents-before.DroppedEvents)
+
+	// a consumer making room in time receives the event
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		stream.release <- struct{}{}
+	}()
+	h.queue(blockEvent(3))
+	assert.Equal(t, uint64(1), GetConsumerMetrics().DroppedEvents-before.DroppedEvents)
+	assert.Equal(t, uint64(0), (<-stream.sent).GetBlock().Header.Number)
+}
+
+func TestStopUnblocksProducer(t *testing.T) {
+	h, _ := newTestHandler(t, ConsumerBufferConfig{BufferSize: 1, Policy: BlockWithTimeout})
+
+	h.queue(blockEvent(0))
+	waitSending(t, h)
+	h.queue(blockEvent(1))
+	done := make(chan struct{})
+	go func() {
+		h.queue(blockEvent(2))
+		close(done)
+	}()
+	h.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stopping the handler should have unblocked the producer")
+	}
+}
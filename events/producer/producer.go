@@ -54,6 +54,23 @@ func (p *EventsServer) Chat(stream pb.Events_ChatServer) error {
 		return fmt.Errorf("Error creating handler during handleChat initiation: %s", err)
 	}
 	defer handler.Stop()
+
+	//receive in the background so that a consumer disconnected for falling
+	//behind is not waited for
+	recvErr := make(chan error, 1)
+	go func() {
+		recvErr <- handleMessages(stream, handler)
+	}()
+
+	select {
+	case err = <-recvErr:
+		return err
+	case <-handler.disconnectChan:
+		return fmt.Errorf("Disconnected consumer: %s", handler.disconnectErr)
+	}
+}
+
+func handleMessages(stream pb.Events_ChatServer, handler *handler) error {
 	for {
 		in, err := stream.Recv()
 		if err == io.EOF {
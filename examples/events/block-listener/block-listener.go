@@ -148,7 +148,11 @@ func main() {
 			fmt.Printf("\n")
 			fmt.Printf("Received block\n")
 			fmt.Printf("--------------\n")
-			txsFltr := util.NewFilterBitArrayFromBytes(b.Block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+			txsFltr, err := util.GetTxsFilter(b.Block)
+			if err != nil {
+				fmt.Printf("Error reading transactions filter: %s\n", err)
+				continue
+			}
 
 			for i, r := range b.Block.Data.Data {
 				if txsFltr.IsSet(uint(i)) {
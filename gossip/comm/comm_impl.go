@@ -19,6 +19,7 @@ package comm
 import (
 	"bytes"
 	"crypto/tls"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -28,6 +29,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	peerComm "github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/identity"
@@ -101,6 +103,8 @@ func NewCommInstanceWithServer(port int, idMapper identity.Mapper, peerIdentity
 		exitChan:          make(chan struct{}, 1),
 		subscriptions:     make([]chan proto.ReceivedMessage, 0),
 		blackListedPKIIDs: make([]common.PKIidType, 0),
+		pool:              peerComm.GetConnectionPool(),
+		pooledConns:       make(map[string]*grpc.ClientConn),
 	}
 	commInst.connStore = newConnStore(commInst, commInst.logger)
 	commInst.idMapper.Put(idMapper.GetPKIidOfCert(peerIdentity), peerIdentity)
@@ -157,6 +161,8 @@ type commImpl struct {
 	stopWG            sync.WaitGroup
 	subscriptions     []chan proto.ReceivedMessage
 	blackListedPKIIDs []common.PKIidType
+	pool              peerComm.ConnectionPool
+	pooledConns       map[string]*grpc.ClientConn
 }
 
 func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidType) (*connection, error) {
@@ -171,7 +177,8 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 	if c.isStopping() {
 		return nil, errors.New("Stopping")
 	}
-	cc, err = grpc.Dial(endpoint, append(c.opts, grpc.WithBlock())...)
+	key := c.poolKey(endpoint)
+	cc, err = c.getPooledConn(key, endpoint)
 	if err != nil {
 		return nil, err
 	}
@@ -179,21 +186,30 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 	cl := proto.NewGossipClient(cc)
 
 	if _, err = cl.Ping(context.Background(), &proto.Empty{}); err != nil {
-		cc.Close()
+		c.pool.Invalidate(key, cc)
 		return nil, err
 	}
 
-	if stream, err = cl.GossipStream(context.Background()); err == nil {
+	// The stream is cancelled when the connection is closed, since the pooled
+	// gRPC connection outlives it
+	ctx, cancel := context.WithCancel(context.Background())
+	if stream, err = cl.GossipStream(ctx); err == nil {
 		pkiID, err = c.authenticateRemotePeer(stream)
 		if err == nil {
 			if expectedPKIID != nil && !bytes.Equal(pkiID, expectedPKIID) {
 				// PKIID is nil when we don't know the remote PKI id's
 				c.logger.Warning("Remote endpoint claims to be a different peer, expected", expectedPKIID, "but got", pkiID)
+				cancel()
+				c.pool.Release(key, cc)
 				return nil, errors.New("Authentication failure")
 			}
 			conn := newConnection(cl, cc, stream, nil)
 			conn.pkiID = pkiID
 			conn.logger = c.logger
+			conn.release = func() {
+				cancel()
+				c.pool.Release(key, cc)
+			}
 
 			h := func(m *proto.GossipMessage) {
 				c.logger.Debug("Got message:", m)
@@ -207,10 +223,44 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 			return conn, nil
 		}
 	}
-	cc.Close()
+	cancel()
+	c.pool.Release(key, cc)
 	return nil, err
 }
 
+// poolKey returns the key of the pooled connection to endpoint. Connections are
+// authenticated with the TLS certificate of the instance, so they are not shared
+// with other instances
+func (c *commImpl) poolKey(endpoint string) string {
+	return fmt.Sprintf("gossip/%s/%s", hex.EncodeToString(c.selfCertHash), endpoint)
+}
+
+func (c *commImpl) getPooledConn(key, endpoint string) (*grpc.ClientConn, error) {
+	cc, err := c.pool.Get(key, func() (*grpc.ClientConn, error) {
+		opts := append([]grpc.DialOption{grpc.WithBlock()}, c.opts...)
+		// Follow the changes of the DNS records of the peers, e.g. of the bootstrap peers
+		opts = append(opts, peerComm.DNSDialOptions()...)
+		return grpc.Dial(endpoint, opts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	c.pooledConns[key] = cc
+	c.lock.Unlock()
+	return cc, nil
+}
+
+// closePooledConns closes the pooled connections of the instance
+func (c *commImpl) closePooledConns() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for key, cc := range c.pooledConns {
+		c.pool.Invalidate(key, cc)
+		delete(c.pooledConns, key)
+	}
+}
+
 func (c *commImpl) Send(msg *proto.GossipMessage, peers ...*RemotePeer) {
 	if c.isStopping() {
 		return
@@ -279,14 +329,19 @@ func (c *commImpl) Probe(remotePeer *RemotePeer) error {
 		return errors.New("Stopping")
 	}
 	c.logger.Debug("Entering, endpoint:", endpoint, "PKIID:", pkiID)
-	cc, err := grpc.Dial(remotePeer.Endpoint, append(c.opts, grpc.WithBlock())...)
+	key := c.poolKey(endpoint)
+	cc, err := c.getPooledConn(key, endpoint)
 	if err != nil {
 		c.logger.Debug("Returning", err)
 		return err
 	}
-	defer cc.Close()
 	cl := proto.NewGossipClient(cc)
 	_, err = cl.Ping(context.Background(), &proto.Empty{})
+	if err != nil {
+		c.pool.Invalidate(key, cc)
+	} else {
+		c.pool.Release(key, cc)
+	}
 	c.logger.Debug("Returning", err)
 	return err
 }
@@ -360,6 +415,7 @@ func (c *commImpl) Stop() {
 	}
 	c.connStore.shutdown()
 	c.logger.Debug("Shut down connection store, connection count:", c.connStore.connNum())
+	c.closePooledConns()
 	c.exitChan <- struct{}{}
 	c.msgPublisher.Close()
 	c.logger.Debug("Shut down publisher")
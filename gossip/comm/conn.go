@@ -202,6 +202,7 @@ type connection struct {
 	pkiID        common.PKIidType                // pkiID of the remote endpoint
 	handler      handler                         // function to invoke upon a message reception
 	conn         *grpc.ClientConn                // gRPC connection to remote endpoint
+	release      func()                          // releases conn if it is pooled, instead of closing it
 	cl           proto.GossipClient              // gRPC stub of remote endpoint
 	clientStream proto.Gossip_GossipStreamClient // client-side stream to remote endpoint
 	serverStream proto.Gossip_GossipStreamServer // server-side stream to remote endpoint
@@ -227,7 +228,9 @@ func (conn *connection) close() {
 	if conn.clientStream != nil {
 		conn.clientStream.CloseSend()
 	}
-	if conn.conn != nil {
+	if conn.release != nil {
+		conn.release()
+	} else if conn.conn != nil {
 		conn.conn.Close()
 	}
 
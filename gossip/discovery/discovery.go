@@ -89,6 +89,11 @@ type Discovery interface {
 	// Stops this instance
 	Stop()
 
+	// Leave announces to the alive members that this instance leaves the
+	// membership, such that they consider it dead right away. It is to be
+	// called before Stop
+	Leave()
+
 	// GetMembership returns the alive members in the view
 	GetMembership() []NetworkMember
 
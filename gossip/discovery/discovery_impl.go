@@ -84,7 +84,9 @@ type gossipDiscoveryImpl struct {
 
 	toDieChan chan struct{}
 	toDieFlag int32
-	logger    *logging.Logger
+	// leftFlag is set once this instance announced it leaves, and stops sending alive messages
+	leftFlag int32
+	logger   *logging.Logger
 }
 
 // NewDiscoveryService returns a new discovery service with the comm module passed and the crypto service passed
@@ -391,6 +393,13 @@ func (d *gossipDiscoveryImpl) handleAliveMessage(m *proto.GossipMessage) {
 	_, known := d.id2Member[string(pkiID)]
 	d.lock.RUnlock()
 
+	if m.GetAliveMsg().Leaving {
+		if known {
+			d.handleLeavingMember(pkiID, ts)
+		}
+		return
+	}
+
 	if !known {
 		d.learnNewMembers([]*proto.GossipMessage{m}, []*proto.GossipMessage{})
 		return
@@ -436,6 +445,23 @@ func (d *gossipDiscoveryImpl) handleAliveMessage(m *proto.GossipMessage) {
 	// else, ignore the message because it is too old
 }
 
+// handleLeavingMember expires a member announcing it leaves, unless the announcement
+// is older than the last alive message of the member
+func (d *gossipDiscoveryImpl) handleLeavingMember(pkiID common.PKIidType, ts *proto.PeerTime) {
+	d.lock.Lock()
+	lastAliveTS, isAlive := d.aliveLastTS[string(pkiID)]
+	if !isAlive || !before(lastAliveTS, ts) {
+		d.lock.Unlock()
+		return
+	}
+	// Alive messages sent before leaving must not resurrect the member
+	lastAliveTS.seqNum = ts.SeqNum
+	d.lock.Unlock()
+
+	d.logger.Info("Member", d.id2Member[string(pkiID)], "left the membership")
+	d.expireDeadMembers([]common.PKIidType{pkiID})
+}
+
 func (d *gossipDiscoveryImpl) resurrectMember(am *proto.GossipMessage, t proto.PeerTime) {
 	d.logger.Info("Entering, AliveMessage:", am, "t:", t)
 	defer d.logger.Info("Exiting")
@@ -595,11 +621,30 @@ func (d *gossipDiscoveryImpl) periodicalSendAlive() {
 	for !d.toDie() {
 		d.logger.Debug("Sleeping", aliveTimeInterval)
 		time.Sleep(aliveTimeInterval)
+		if atomic.LoadInt32(&d.leftFlag) == int32(1) {
+			continue
+		}
 		d.comm.Gossip(d.createAliveMessage())
 	}
 }
 
+// Leave sends to the alive members an alive message announcing that this instance leaves
+func (d *gossipDiscoveryImpl) Leave() {
+	if d.toDie() || !atomic.CompareAndSwapInt32(&d.leftFlag, int32(0), int32(1)) {
+		return
+	}
+	msg := d.signedAliveMessage(true)
+	for _, member := range d.GetMembership() {
+		member := member
+		d.comm.SendToPeer(&member, msg)
+	}
+}
+
 func (d *gossipDiscoveryImpl) createAliveMessage() *proto.GossipMessage {
+	return d.signedAliveMessage(false)
+}
+
+func (d *gossipDiscoveryImpl) signedAliveMessage(leaving bool) *proto.GossipMessage {
 	d.lock.Lock()
 	d.seqNum++
 	seqNum := d.seqNum
@@ -625,6 +670,7 @@ func (d *gossipDiscoveryImpl) createAliveMessage() *proto.GossipMessage {
 					IncNumber: uint64(d.incTime),
 					SeqNum:    seqNum,
 				},
+				Leaving: leaving,
 			},
 		},
 	}
@@ -399,6 +399,31 @@ func TestExpiration(t *testing.T) {
 	waitUntilOrFailBlocking(t, stopAction.Wait)
 }
 
+func TestLeave(t *testing.T) {
+	t.Parallel()
+	bootPeers := []string{bootPeer(2711)}
+	instances := []*gossipInstance{}
+	for i := 1; i <= 3; i++ {
+		instances = append(instances, createDiscoveryInstance(2710+i, fmt.Sprintf("d%d", i), bootPeers))
+	}
+
+	assertMembership(t, instances, 2)
+
+	instances[2].Leave()
+
+	// The members learn of the departure before the alive expiration
+	start := time.Now()
+	left := func() bool {
+		return len(instances[0].GetMembership()) == 1 && len(instances[1].GetMembership()) == 1
+	}
+	for !left() && time.Since(start) < aliveExpirationTimeout/2 {
+		time.Sleep(aliveTimeInterval / 10)
+	}
+	assert.True(t, left(), "Members should have learnt that d3 left")
+
+	stopInstances(t, instances)
+}
+
 func TestGetFullMembership(t *testing.T) {
 	t.Parallel()
 	nodeNum := 15
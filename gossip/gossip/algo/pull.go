@@ -47,6 +47,13 @@ func init() {
 	rand.Seed(42)
 }
 
+// pullPhase offsets the pull rounds of the engines of this process from the
+// multiples of their interval. The engines of a process initiating a round
+// every same interval, e.g. those of the channels of a peer, initiate their
+// rounds together, so that their messages to the same peers can be coalesced,
+// while the rounds of different processes are spread
+var pullPhase = time.Now().UnixNano()
+
 var digestWaitTime = time.Duration(1) * time.Second
 var requestWaitTime = time.Duration(1) * time.Second
 var responseWaitTime = time.Duration(2) * time.Second
@@ -126,7 +133,7 @@ func NewPullEngine(participant PullAdapter, sleepTime time.Duration) *PullEngine
 
 	go func() {
 		for !engine.toDie() {
-			time.Sleep(sleepTime)
+			time.Sleep(untilNextRound(sleepTime))
 			if engine.toDie() {
 				return
 			}
@@ -137,6 +144,19 @@ func NewPullEngine(participant PullAdapter, sleepTime time.Duration) *PullEngine
 	return engine
 }
 
+// untilNextRound returns the time until the next pull round of the engines
+// of this process initiating a round every interval
+func untilNextRound(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return interval
+	}
+	elapsed := (time.Now().UnixNano() - pullPhase) % int64(interval)
+	if elapsed < 0 {
+		elapsed += int64(interval)
+	}
+	return interval - time.Duration(elapsed)
+}
+
 func (engine *PullEngine) toDie() bool {
 	return (atomic.LoadInt32(&(engine.stopFlag)) == int32(1))
 }
@@ -210,6 +210,19 @@ func TestPullEngine_Stop(t *testing.T) {
 	assert.Equal(t, len1, len2, "PullEngine was still active after Stop() was invoked!")
 }
 
+func TestUntilNextRound(t *testing.T) {
+	t.Parallel()
+	interval := time.Duration(100) * time.Millisecond
+	wait := untilNextRound(interval)
+	assert.True(t, wait > 0 && wait <= interval, "Waiting %s for a round every %s", wait, interval)
+
+	// engines started at different times initiate their rounds together
+	time.Sleep(wait + interval/3)
+	next := untilNextRound(interval)
+	assert.True(t, next < interval*3/4, "Waiting %s for a round every %s", next, interval)
+	assert.Equal(t, time.Duration(0), untilNextRound(0))
+}
+
 func TestPullEngineAll2AllWithIncrementalSpawning(t *testing.T) {
 	t.Parallel()
 	// Scenario: spawn 10 nodes, each 50 ms after the other
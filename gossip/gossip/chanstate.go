@@ -77,6 +77,18 @@ func (cs *channelState) joinChannel(joinMsg api.JoinChannelMessage, chainID comm
 	}
 }
 
+func (cs *channelState) leaveChannel(chainID common.ChainID) {
+	if cs.isStopping() {
+		return
+	}
+	cs.Lock()
+	defer cs.Unlock()
+	if gc, exists := cs.channels[string(chainID)]; exists {
+		gc.Stop()
+		delete(cs.channels, string(chainID))
+	}
+}
+
 type gossipAdapterImpl struct {
 	*gossipServiceImpl
 	discovery.Discovery
@@ -93,6 +105,12 @@ func (ga *gossipAdapterImpl) GetConf() channel.Config {
 	}
 }
 
+// Send sends a message to remote peers, the pull messages being coalesced
+// with those of the other channels to the same peers
+func (ga *gossipAdapterImpl) Send(msg *proto.GossipMessage, peers ...*comm.RemotePeer) {
+	ga.gossipServiceImpl.pullCoalescer.Send(msg, peers...)
+}
+
 // Gossip gossips a message
 func (ga *gossipAdapterImpl) Gossip(msg *proto.GossipMessage) {
 	ga.gossipServiceImpl.emitter.Add(msg)
@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hyperledger/fabric/gossip/api"
+)
+
+// cryptoPoolVars publishes the statistics of the crypto pools of the gossip
+// instances, by ID, on /debug/vars of the profiling service of the peer
+var cryptoPoolVars = expvar.NewMap("gossipCryptoPool")
+
+// cryptoPool runs the signing and the verification of the gossip messages on a
+// bounded number of workers, rather than inline on the goroutines receiving and
+// emitting the messages. Every worker takes the jobs queued in batches, up to
+// batchSize at once
+type cryptoPool struct {
+	jobs      chan func()
+	batchSize int
+	lock      sync.RWMutex
+	stopped   bool
+	workers   sync.WaitGroup
+
+	processed uint64
+	batches   uint64
+}
+
+func newCryptoPool(workers, queueSize, batchSize int) *cryptoPool {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	p := &cryptoPool{
+		jobs:      make(chan func(), queueSize),
+		batchSize: batchSize,
+	}
+	p.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+func (p *cryptoPool) work() {
+	defer p.workers.Done()
+	batch := make([]func(), 0, p.batchSize)
+	for job := range p.jobs {
+		batch = p.takeBatch(append(batch[:0], job))
+		for _, job := range batch {
+			job()
+		}
+		atomic.AddUint64(&p.processed, uint64(len(batch)))
+		atomic.AddUint64(&p.batches, 1)
+	}
+}
+
+// takeBatch appends to batch the jobs queued, up to the size of a batch
+func (p *cryptoPool) takeBatch(batch []func()) []func() {
+	for len(batch) < p.batchSize {
+		select {
+		case job, ok := <-p.jobs:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, job)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// submit queues job, waiting for room in the queue if it is full. It returns
+// false if the pool is stopped, in which case job is not run
+func (p *cryptoPool) submit(job func()) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	if p.stopped {
+		return false
+	}
+	p.jobs <- job
+	return true
+}
+
+// do runs job on the pool and waits for it to complete, it runs job inline if
+// the pool is stopped
+func (p *cryptoPool) do(job func()) {
+	done := make(chan struct{})
+	if !p.submit(func() {
+		job()
+		close(done)
+	}) {
+		job()
+		return
+	}
+	<-done
+}
+
+// stop runs the jobs queued and stops the workers
+func (p *cryptoPool) stop() {
+	p.lock.Lock()
+	if p.stopped {
+		p.lock.Unlock()
+		return
+	}
+	p.stopped = true
+	close(p.jobs)
+	p.lock.Unlock()
+	p.workers.Wait()
+}
+
+// stats returns the depth of the queue and the number of jobs and batches processed
+func (p *cryptoPool) stats() interface{} {
+	return map[string]uint64{
+		"queueDepth": uint64(len(p.jobs)),
+		"processed":  atomic.LoadUint64(&p.processed),
+		"batches":    atomic.LoadUint64(&p.batches),
+	}
+}
+
+// pooledSigner is a MessageCryptoService signing the messages on a cryptoPool
+type pooledSigner struct {
+	api.MessageCryptoService
+	pool *cryptoPool
+}
+
+// Sign signs msg on the pool
+func (s *pooledSigner) Sign(msg []byte) (signature []byte, err error) {
+	s.pool.do(func() {
+		signature, err = s.MessageCryptoService.Sign(msg)
+	})
+	return signature, err
+}
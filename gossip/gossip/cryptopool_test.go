@@ -0,0 +1,148 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/identity"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCryptoPool(t *testing.T) {
+	p := newCryptoPool(4, 100, 10)
+
+	var count int32
+	var wg sync.WaitGroup
+	wg.Add(1000)
+	for i := 0; i < 1000; i++ {
+		assert.True(t, p.submit(func() {
+			atomic.AddInt32(&count, 1)
+			wg.Done()
+		}))
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1000), atomic.LoadInt32(&count))
+
+	ran := false
+	p.do(func() { ran = true })
+	assert.True(t, ran)
+
+	p.stop()
+	stats := p.stats().(map[string]uint64)
+	assert.Equal(t, uint64(1001), stats["processed"])
+	assert.True(t, stats["batches"] <= stats["processed"])
+	assert.Equal(t, uint64(0), stats["queueDepth"])
+
+	// once stopped, the jobs are rejected or run inline
+	assert.False(t, p.submit(func() {}))
+	ran = false
+	p.do(func() { ran = true })
+	assert.True(t, ran)
+}
+
+func TestCryptoPoolBatches(t *testing.T) {
+	p := newCryptoPool(1, 100, 10)
+
+	// the jobs queued while the worker is busy are taken in batches
+	started, block := make(chan struct{}), make(chan struct{})
+	p.submit(func() {
+		close(started)
+		<-block
+	})
+	<-started
+	for i := 0; i < 20; i++ {
+		p.submit(func() {})
+	}
+	assert.Equal(t, uint64(20), p.stats().(map[string]uint64)["queueDepth"])
+	close(block)
+	p.stop()
+	assert.Equal(t, uint64(3), atomic.LoadUint64(&p.batches))
+}
+
+func newGossipInstanceWithCryptoPool(portPrefix int, id int, boot ...int) Gossip {
+	port := id + portPrefix
+	conf := &Config{
+		BindPort:                   port,
+		BootstrapPeers:             bootPeers(portPrefix, boot...),
+		ID:                         fmt.Sprintf("p%d", id),
+		MaxBlockCountToStore:       100,
+		MaxPropagationBurstLatency: time.Duration(500) * time.Millisecond,
+		MaxPropagationBurstSize:    20,
+		PropagateIterations:        1,
+		PropagatePeerNum:           3,
+		PullInterval:               time.Duration(2) * time.Second,
+		PullPeerNum:                5,
+		InternalEndpoint:           fmt.Sprintf("localhost:%d", port),
+		ExternalEndpoint:           fmt.Sprintf("1.2.3.4:%d", port),
+		PublishCertPeriod:          time.Duration(4) * time.Second,
+		PublishStateInfoInterval:   time.Duration(1) * time.Second,
+		RequestStateInfoInterval:   time.Duration(1) * time.Second,
+		CryptoWorkers:              2,
+		CryptoQueueSize:            10,
+		CryptoBatchSize:            3,
+	}
+	cryptoService := &naiveCryptoService{}
+	idMapper := identity.NewIdentityMapper(cryptoService)
+	return NewGossipServiceWithServer(conf, &orgCryptoService{}, cryptoService, idMapper, api.PeerIdentityType(conf.InternalEndpoint))
+}
+
+func TestDisseminationWithCryptoPool(t *testing.T) {
+	// Scenario: the messages of peers verifying them on their crypto pools
+	// are disseminated as they are by peers verifying them inline
+	portPrefix := 7610
+	n, msgsCount2Send := 3, 5
+	boot := newGossipInstanceWithCryptoPool(portPrefix, 0)
+	boot.JoinChan(&joinChanMsg{}, common.ChainID("A"))
+	boot.UpdateChannelMetadata([]byte{}, common.ChainID("A"))
+
+	peers := make([]Gossip, n)
+	wg := sync.WaitGroup{}
+	wg.Add(n)
+	for i := 1; i <= n; i++ {
+		pI := newGossipInstanceWithCryptoPool(portPrefix, i, 0)
+		peers[i-1] = pI
+		pI.JoinChan(&joinChanMsg{}, common.ChainID("A"))
+		pI.UpdateChannelMetadata([]byte{}, common.ChainID("A"))
+		acceptChan, _ := pI.Accept(acceptData, false)
+		go func(ch <-chan *proto.GossipMessage) {
+			defer wg.Done()
+			for j := 0; j < msgsCount2Send; j++ {
+				<-ch
+			}
+		}(acceptChan)
+	}
+	waitUntilOrFail(t, checkPeersMembership(t, peers, n))
+
+	for i := 1; i <= msgsCount2Send; i++ {
+		boot.Gossip(createDataMsg(uint64(i), []byte{}, "", common.ChainID("A")))
+	}
+	waitUntilOrFailBlocking(t, wg.Wait)
+
+	stats := boot.(*gossipServiceImpl).cryptoPool.stats().(map[string]uint64)
+	assert.True(t, stats["processed"] > 0)
+	waitUntilOrFailBlocking(t, func() {
+		stopPeers(append(peers, boot))
+	})
+}
@@ -41,6 +41,10 @@ type Gossip interface {
 	// and also subscribed to the channel given
 	PeersOfChannel(common.ChainID) []discovery.NetworkMember
 
+	// OrgOfPeer returns the organization of the peer with the given PKI-ID,
+	// or nil if its identity isn't known
+	OrgOfPeer(PKIID common.PKIidType) api.OrgIdentityType
+
 	// UpdateMetadata updates the self metadata of the discovery layer
 	// the peer publishes to other peers
 	UpdateMetadata(metadata []byte)
@@ -61,6 +65,17 @@ type Gossip interface {
 	// JoinChan makes the Gossip instance join a channel
 	JoinChan(joinMsg api.JoinChannelMessage, chainID common.ChainID)
 
+	// LeaveChan makes the Gossip instance leave a channel, it stops
+	// disseminating and pulling the blocks of the channel
+	LeaveChan(chainID common.ChainID)
+
+	// MisbehaviorAlerts returns a channel of the alerts raised from now on about
+	// the conflicting blocks received and the peers caught sending forged alive
+	// messages.
+	// Alerts are dropped while the channel is full, and it is closed when the
+	// gossip component stops
+	MisbehaviorAlerts() <-chan *MisbehaviorAlert
+
 	// Stop stops the gossip component
 	Stop()
 }
@@ -79,8 +94,9 @@ type Config struct {
 	MaxPropagationBurstSize    int           // Max number of messages stored until it triggers a push to remote peers
 	MaxPropagationBurstLatency time.Duration // Max time between consecutive message pushes
 
-	PullInterval time.Duration // Determines frequency of pull phases
-	PullPeerNum  int           // Number of peers to pull from
+	PullInterval    time.Duration // Determines frequency of pull phases
+	PullPeerNum     int           // Number of peers to pull from
+	PullBatchWindow time.Duration // Time the pull messages to a peer are held to be sent along with those of the other channels, 0 disables it
 
 	SkipBlockVerification bool // Should we skip verifying block messages or not
 
@@ -88,6 +104,15 @@ type Config struct {
 	PublishStateInfoInterval time.Duration    // Determines frequency of pushing state info messages to peers
 	RequestStateInfoInterval time.Duration    // Determines frequency of pulling state info messages from peers
 	TLSServerCert            *tls.Certificate // TLS certificate of the peer
+	RequireOrgAttestation    bool             // Whether alive messages must carry the certificate and organization of the peer
+
+	LeaveGracePeriod time.Duration // Time given to the announcement that the peer leaves to reach the members when stopping
+
+	CryptoWorkers   int // Number of goroutines signing and verifying the messages, 0 has them signed and verified inline
+	CryptoQueueSize int // Number of messages queued for the crypto workers before the receiving goroutine blocks
+	CryptoBatchSize int // Max number of queued messages a crypto worker takes at once
+
+	QuarantineDuration time.Duration // Time the messages of a peer caught sending forged alive messages are discarded, 0 disables the quarantine
 
 	InternalEndpoint string // Endpoint we publish to peers in our organization
 	ExternalEndpoint string // Peer publishes this endpoint instead of SelfEndpoint to foreign organizations
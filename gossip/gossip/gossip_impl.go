@@ -19,6 +19,7 @@ package gossip
 import (
 	"bytes"
 	"crypto/tls"
+	"expvar"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -71,6 +72,9 @@ type gossipServiceImpl struct {
 	mcs               api.MessageCryptoService
 	aliveMsgStore     msgstore.MessageStore
 	stateInfoMsgStore msgstore.MessageStore
+	pullCoalescer     *pull.Coalescer
+	cryptoPool        *cryptoPool
+	misbehavior       *misbehaviorDetector
 }
 
 // NewGossipService creates a gossip instance attached to a gRPC server
@@ -109,7 +113,17 @@ func NewGossipService(conf *Config, s *grpc.Server, secAdvisor api.SecurityAdvis
 		includeIdentityPeriod: time.Now().Add(conf.PublishCertPeriod),
 	}
 
+	if conf.CryptoWorkers > 0 {
+		g.cryptoPool = newCryptoPool(conf.CryptoWorkers, conf.CryptoQueueSize, conf.CryptoBatchSize)
+		g.mcs = &pooledSigner{MessageCryptoService: mcs, pool: g.cryptoPool}
+		cryptoPoolVars.Set(conf.ID, expvar.Func(g.cryptoPool.stats))
+	}
+
+	g.misbehavior = newMisbehaviorDetector(c.GetPKIid(), conf.MaxBlockCountToStore, conf.QuarantineDuration, lgr)
+	misbehaviorVars.Set(conf.ID, expvar.Func(g.misbehavior.stats))
+
 	g.aliveMsgStore = msgstore.NewMessageStore(proto.NewGossipMessageComparator(0), func(m interface{}) {})
+	g.pullCoalescer = pull.NewCoalescer(conf.PullBatchWindow, c)
 
 	g.chanState = newChannelState(g)
 	g.emitter = newBatchingEmitter(conf.PropagateIterations,
@@ -121,7 +135,7 @@ func NewGossipService(conf *Config, s *grpc.Server, secAdvisor api.SecurityAdvis
 
 	g.disc = discovery.NewDiscoveryService(conf.BootstrapPeers, g.selfNetworkMember(), g.discAdapter, g.disSecAdap)
 
-	g.certStore = newCertStore(g.createCertStorePuller(), idMapper, selfIdentity, mcs)
+	g.certStore = newCertStore(g.createCertStorePuller(), idMapper, selfIdentity, g.mcs)
 
 	if g.conf.ExternalEndpoint == "" {
 		g.logger.Warning("External endpoint is empty, peer will not be accessible outside of its organization")
@@ -180,6 +194,13 @@ func (g *gossipServiceImpl) toDie() bool {
 	return atomic.LoadInt32(&g.stopFlag) == int32(1)
 }
 
+// LeaveChan makes the Gossip instance leave a channel, the messages of the
+// channel received afterwards are discarded
+func (g *gossipServiceImpl) LeaveChan(chainID common.ChainID) {
+	g.chanState.leaveChannel(chainID)
+	g.logger.Info("Left channel", string(chainID))
+}
+
 func (g *gossipServiceImpl) JoinChan(joinMsg api.JoinChannelMessage, chainID common.ChainID) {
 	// joinMsg is supposed to have been already verified
 	g.chanState.joinChannel(joinMsg, chainID)
@@ -258,6 +279,10 @@ func (g *gossipServiceImpl) acceptMessages(incMsgs <-chan proto.ReceivedMessage)
 	defer g.logger.Debug("Exiting")
 	g.stopSignal.Add(1)
 	defer g.stopSignal.Done()
+	if g.cryptoPool != nil {
+		g.acceptMessagesWithPool(incMsgs)
+		return
+	}
 	for {
 		select {
 		case s := <-g.toDieChan:
@@ -270,6 +295,53 @@ func (g *gossipServiceImpl) acceptMessages(incMsgs <-chan proto.ReceivedMessage)
 	}
 }
 
+// verifiedMessage is a message received, being verified by the crypto pool
+type verifiedMessage struct {
+	msg   proto.ReceivedMessage
+	valid bool
+	done  chan struct{}
+}
+
+// acceptMessagesWithPool verifies the messages received on the crypto pool, and
+// handles the valid ones in the order they were received
+func (g *gossipServiceImpl) acceptMessagesWithPool(incMsgs <-chan proto.ReceivedMessage) {
+	verified := make(chan *verifiedMessage, cap(g.cryptoPool.jobs))
+	defer close(verified)
+	g.stopSignal.Add(1)
+	go func() {
+		defer g.stopSignal.Done()
+		for v := range verified {
+			<-v.done
+			if v.valid {
+				g.handleValidMessage(v.msg)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case s := <-g.toDieChan:
+			g.toDieChan <- s
+			return
+		case msg := <-incMsgs:
+			if g.toDie() || msg == nil || msg.GetGossipMessage() == nil {
+				break
+			}
+			v := &verifiedMessage{msg: msg, done: make(chan struct{})}
+			if !g.cryptoPool.submit(func() {
+				v.valid = g.validateMsg(msg)
+				if !v.valid {
+					g.logger.Warning("Message", msg.GetGossipMessage(), "isn't valid")
+				}
+				close(v.done)
+			}) {
+				return
+			}
+			verified <- v
+		}
+	}
+}
+
 func (g *gossipServiceImpl) handleMessage(m proto.ReceivedMessage) {
 	if g.toDie() {
 		return
@@ -284,10 +356,31 @@ func (g *gossipServiceImpl) handleMessage(m proto.ReceivedMessage) {
 	g.logger.Debug("Entering,", m.GetPKIID(), "sent us", msg)
 	defer g.logger.Debug("Exiting")
 
+	if g.misbehavior.isQuarantined(m.GetPKIID()) {
+		g.logger.Debug("Discarding message", msg, "of quarantined peer", m.GetPKIID())
+		return
+	}
+
 	if !g.validateMsg(m) {
 		g.logger.Warning("Message", msg, "isn't valid")
 		return
 	}
+	g.handleValidMessage(m)
+}
+
+// handleValidMessage handles a message received once validated
+func (g *gossipServiceImpl) handleValidMessage(m proto.ReceivedMessage) {
+	if g.toDie() {
+		return
+	}
+	msg := m.GetGossipMessage()
+
+	if msg.IsPullBatch() {
+		for _, pullMsg := range g.pullCoalescer.Unbatch(m) {
+			g.handleMessage(pullMsg)
+		}
+		return
+	}
 
 	if msg.IsAliveMsg() {
 		am := msg.GetAliveMsg()
@@ -352,7 +445,10 @@ func (g *gossipServiceImpl) validateMsg(msg proto.ReceivedMessage) bool {
 	}
 
 	if msg.GetGossipMessage().IsAliveMsg() {
-		if !g.disSecAdap.ValidateAliveMsg(msg.GetGossipMessage()) {
+		if valid, forged := g.disSecAdap.validateAliveMsg(msg.GetGossipMessage()); !valid {
+			if forged {
+				g.misbehavior.reportForgedAliveMsg(msg.GetGossipMessage(), msg.GetPKIID())
+			}
 			return false
 		}
 	}
@@ -373,6 +469,9 @@ func (g *gossipServiceImpl) validateMsg(msg proto.ReceivedMessage) bool {
 			g.logger.Warning("Could not verify block", blockMsg.Payload.SeqNum, ":", err)
 			return false
 		}
+
+		// A conflict is only reported, the sender may merely have relayed the block
+		g.misbehavior.checkBlock(msg.GetGossipMessage(), msg.GetPKIID())
 	}
 
 	if msg.GetGossipMessage().IsStateInfoMsg() {
@@ -521,6 +620,7 @@ func (g *gossipServiceImpl) Gossip(msg *proto.GossipMessage) {
 		}
 		if msg.IsDataMsg() {
 			gc.AddToMsgStore(msg)
+			g.misbehavior.checkBlock(msg, g.comm.GetPKIid())
 		}
 	}
 
@@ -557,13 +657,31 @@ func (g *gossipServiceImpl) PeersOfChannel(channel common.ChainID) []discovery.N
 	return gc.GetPeers()
 }
 
+// OrgOfPeer returns the organization of the peer with the given PKI-ID,
+// or nil if its identity isn't known
+func (g *gossipServiceImpl) OrgOfPeer(PKIID common.PKIidType) api.OrgIdentityType {
+	return g.getOrgOfPeer(PKIID)
+}
+
+// MisbehaviorAlerts returns a channel of the alerts raised from now on about
+// the peers caught sending conflicting blocks or forged alive messages
+func (g *gossipServiceImpl) MisbehaviorAlerts() <-chan *MisbehaviorAlert {
+	return g.misbehavior.subscribe()
+}
+
 // Stop stops the gossip component
 func (g *gossipServiceImpl) Stop() {
 	if g.toDie() {
 		return
 	}
+	// Let the members know we leave instead of waiting for our alive messages to expire
+	g.disc.Leave()
+	if g.conf.LeaveGracePeriod > 0 {
+		time.Sleep(g.conf.LeaveGracePeriod)
+	}
 	atomic.StoreInt32((&g.stopFlag), int32(1))
 	g.logger.Info("Stopping gossip")
+	g.pullCoalescer.Stop()
 	comWG := sync.WaitGroup{}
 	comWG.Add(1)
 	go func() {
@@ -578,6 +696,10 @@ func (g *gossipServiceImpl) Stop() {
 	g.emitter.Stop()
 	g.ChannelDeMultiplexer.Close()
 	g.stopSignal.Wait()
+	g.misbehavior.stop()
+	if g.cryptoPool != nil {
+		g.cryptoPool.stop()
+	}
 	comWG.Wait()
 }
 
@@ -647,6 +769,7 @@ func (g *gossipServiceImpl) newDiscoveryAdapter() *discoveryAdapter {
 	return &discoveryAdapter{
 		identity:              g.selfIdentity,
 		includeIdentityPeriod: g.includeIdentityPeriod,
+		alwaysIncludeIdentity: g.conf.RequireOrgAttestation,
 		c:        g.comm,
 		stopping: int32(0),
 		gossipFunc: func(msg *proto.GossipMessage) {
@@ -661,6 +784,7 @@ func (g *gossipServiceImpl) newDiscoveryAdapter() *discoveryAdapter {
 // that the comm interface in the discovery module declares
 type discoveryAdapter struct {
 	includeIdentityPeriod time.Time
+	alwaysIncludeIdentity bool
 	identity              api.PeerIdentityType
 	stopping              int32
 	c                     comm.Comm
@@ -682,7 +806,7 @@ func (da *discoveryAdapter) Gossip(msg *proto.GossipMessage) {
 	if da.toDie() {
 		return
 	}
-	if msg.IsAliveMsg() && time.Now().Before(da.includeIdentityPeriod) {
+	if msg.IsAliveMsg() && (da.alwaysIncludeIdentity || time.Now().Before(da.includeIdentityPeriod)) {
 		msg.GetAliveMsg().Identity = da.identity
 	}
 	da.gossipFunc(msg)
@@ -712,29 +836,41 @@ func (da *discoveryAdapter) CloseConn(peer *discovery.NetworkMember) {
 }
 
 type discoverySecurityAdapter struct {
-	idMapper identity.Mapper
-	sa       api.SecurityAdvisor
-	mcs      api.MessageCryptoService
-	c        comm.Comm
-	logger   *logging.Logger
+	idMapper              identity.Mapper
+	sa                    api.SecurityAdvisor
+	mcs                   api.MessageCryptoService
+	c                     comm.Comm
+	logger                *logging.Logger
+	selfOrg               api.OrgIdentityType
+	requireOrgAttestation bool
 }
 
 func (g *gossipServiceImpl) newDiscoverySecurityAdapter() *discoverySecurityAdapter {
 	return &discoverySecurityAdapter{
-		sa:       g.secAdvisor,
-		idMapper: g.idMapper,
-		mcs:      g.mcs,
-		c:        g.comm,
-		logger:   g.logger,
+		sa:                    g.secAdvisor,
+		idMapper:              g.idMapper,
+		mcs:                   g.mcs,
+		c:                     g.comm,
+		logger:                g.logger,
+		selfOrg:               g.selfOrg,
+		requireOrgAttestation: g.conf.RequireOrgAttestation,
 	}
 }
 
 // validateAliveMsg validates that an Alive message is authentic
 func (sa *discoverySecurityAdapter) ValidateAliveMsg(m *proto.GossipMessage) bool {
+	valid, _ := sa.validateAliveMsg(m)
+	return valid
+}
+
+// validateAliveMsg validates that an Alive message is authentic, and returns
+// whether it is forged, that is, whether its signature or the PKI-ID it claims
+// don't match the identity of the peer
+func (sa *discoverySecurityAdapter) validateAliveMsg(m *proto.GossipMessage) (valid bool, forged bool) {
 	am := m.GetAliveMsg()
 	if am == nil || am.Membership == nil || am.Membership.PkiID == nil || m.Signature == nil {
 		sa.logger.Warning("Invalid alive message:", am)
-		return false
+		return false, false
 	}
 
 	var identity api.PeerIdentityType
@@ -746,12 +882,12 @@ func (sa *discoverySecurityAdapter) ValidateAliveMsg(m *proto.GossipMessage) boo
 		claimedPKIID := am.Membership.PkiID
 		if !bytes.Equal(calculatedPKIID, claimedPKIID) {
 			sa.logger.Warning("Calculated pkiID doesn't match identity:", calculatedPKIID, claimedPKIID)
-			return false
+			return false, true
 		}
 		err := sa.mcs.ValidateIdentity(api.PeerIdentityType(identity))
 		if err != nil {
 			sa.logger.Warning("Failed validating identity of", am, "reason:", err)
-			return false
+			return false, false
 		}
 	} else {
 		identity, _ = sa.idMapper.Get(am.Membership.PkiID)
@@ -762,10 +898,37 @@ func (sa *discoverySecurityAdapter) ValidateAliveMsg(m *proto.GossipMessage) boo
 
 	if identity == nil {
 		sa.logger.Warning("Don't have certificate for", am)
-		return false
+		return false, false
+	}
+
+	if !sa.validateOrgAttestation(am, identity) {
+		return false, false
+	}
+
+	if !sa.validateAliveMsgSignature(m, identity) {
+		return false, true
 	}
+	return true, false
+}
 
-	return sa.validateAliveMsgSignature(m, identity)
+// validateOrgAttestation validates that the organization an AliveMessage claims
+// is the organization of the identity it is verified with.
+// When org attestation is required, the AliveMessage must also carry that identity
+// and claim an organization
+func (sa *discoverySecurityAdapter) validateOrgAttestation(am *proto.AliveMessage, identity api.PeerIdentityType) bool {
+	if sa.requireOrgAttestation && (am.Identity == nil || len(am.Membership.Org) == 0) {
+		sa.logger.Warning("Alive message of", am.Membership, "doesn't carry the certificate and organization of the peer")
+		return false
+	}
+	if len(am.Membership.Org) == 0 {
+		return true
+	}
+	org := sa.sa.OrgByPeerIdentity(identity)
+	if !bytes.Equal(org, am.Membership.Org) {
+		sa.logger.Warning("Alive message of", am.Membership, "claims organization", string(am.Membership.Org), "but its identity belongs to", string(org))
+		return false
+	}
+	return true
 }
 
 // SignMessage signs an AliveMessage and updates its signature field
@@ -786,6 +949,10 @@ func (sa *discoverySecurityAdapter) SignMessage(m *proto.GossipMessage) *proto.G
 		am.Membership.InternalEndpoint = endpoint
 	}()
 
+	// The organization we claim is covered by the signature,
+	// so that it can't be altered by the peers that forward the message
+	am.Membership.Org = sa.selfOrg
+
 	err = m.Sign(signer)
 	if err != nil {
 		sa.logger.Error("Failed signing", am, ":", err)
@@ -866,7 +1033,7 @@ func (g *gossipServiceImpl) createCertStorePuller() pull.Mediator {
 		g.logger.Info("Learned of a new certificate:", idMsg.Cert)
 
 	}
-	return pull.NewPullMediator(conf, g.comm, g.disc, pkiIDFromMsg, certConsumer)
+	return pull.NewPullMediator(conf, g.pullCoalescer, g.disc, pkiIDFromMsg, certConsumer)
 }
 
 func (g *gossipServiceImpl) createStateInfoMsg(metadata []byte, chainID common.ChainID) (*proto.GossipMessage, error) {
@@ -1058,3 +1058,79 @@ func checkPeersMembership(t *testing.T, peers []Gossip, n int) func() bool {
 		return true
 	}
 }
+
+type orgByIdentitySecAdvisor map[string]api.OrgIdentityType
+
+// OrgByPeerIdentity returns the OrgIdentityType
+// of a given peer identity
+func (advisor orgByIdentitySecAdvisor) OrgByPeerIdentity(identity api.PeerIdentityType) api.OrgIdentityType {
+	return advisor[string(identity)]
+}
+
+func TestOrgAttestation(t *testing.T) {
+	t.Parallel()
+	mcs := &naiveCryptoService{}
+	advisor := orgByIdentitySecAdvisor{
+		"peer0.org1": api.OrgIdentityType("ORG1"),
+		"peer0.org2": api.OrgIdentityType("ORG2"),
+	}
+	newAdapter := func(self string, requireOrgAttestation bool) *discoverySecurityAdapter {
+		return &discoverySecurityAdapter{
+			idMapper:              identity.NewIdentityMapper(mcs),
+			sa:                    advisor,
+			mcs:                   mcs,
+			logger:                util.GetLogger(util.LoggingGossipModule, self),
+			selfOrg:               advisor.OrgByPeerIdentity(api.PeerIdentityType(self)),
+			requireOrgAttestation: requireOrgAttestation,
+		}
+	}
+	createAliveMsg := func(sa *discoverySecurityAdapter, self string, includeIdentity bool) *proto.GossipMessage {
+		msg := sa.SignMessage(&proto.GossipMessage{
+			Tag: proto.GossipMessage_EMPTY,
+			Content: &proto.GossipMessage_AliveMsg{
+				AliveMsg: &proto.AliveMessage{
+					Membership: &proto.Member{
+						Endpoint: self + ":7051",
+						PkiID:    []byte(self),
+					},
+					Timestamp: &proto.PeerTime{IncNumber: 1, SeqNum: 1},
+				},
+			},
+		})
+		if includeIdentity {
+			msg.GetAliveMsg().Identity = []byte(self)
+		}
+		return msg
+	}
+
+	p1 := newAdapter("peer0.org1", true)
+	p2 := newAdapter("peer0.org2", true)
+
+	// The signed alive message carries the organization of its signer
+	msg := createAliveMsg(p1, "peer0.org1", true)
+	assert.Equal(t, []byte("ORG1"), msg.GetAliveMsg().Membership.Org)
+	assert.True(t, p2.ValidateAliveMsg(msg))
+
+	// An alive message without the certificate of the peer is rejected
+	assert.False(t, p2.ValidateAliveMsg(createAliveMsg(p1, "peer0.org1", false)))
+	// unless org attestation isn't required, and the certificate is known
+	lenient := newAdapter("peer0.org2", false)
+	assert.NoError(t, lenient.idMapper.Put(common.PKIidType("peer0.org1"), api.PeerIdentityType("peer0.org1")))
+	assert.True(t, lenient.ValidateAliveMsg(createAliveMsg(p1, "peer0.org1", false)))
+
+	// A peer can't claim another organization than the one of its identity
+	msg = createAliveMsg(p1, "peer0.org1", true)
+	msg.GetAliveMsg().Membership.Org = []byte("ORG2")
+	assert.NoError(t, msg.Sign(func(msg []byte) ([]byte, error) {
+		return mcs.Sign(msg)
+	}))
+	msg.GetAliveMsg().Identity = []byte("peer0.org1")
+	assert.False(t, p2.ValidateAliveMsg(msg))
+	assert.False(t, lenient.ValidateAliveMsg(msg))
+
+	// A peer that forwards the alive message can't alter the organization it claims
+	msg = createAliveMsg(p1, "peer0.org1", true)
+	msg.GetAliveMsg().Membership.Org = []byte("ORG2")
+	advisor["peer0.org1"] = api.OrgIdentityType("ORG2")
+	assert.False(t, p2.ValidateAliveMsg(msg))
+}
@@ -0,0 +1,296 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/op/go-logging"
+)
+
+const misbehaviorAlertBufferSize = 100
+
+// misbehaviorVars publishes the statistics of the misbehavior detectors of the
+// gossip instances, by ID, on /debug/vars of the profiling service of the peer
+var misbehaviorVars = expvar.NewMap("gossipMisbehavior")
+
+// MisbehaviorKind is the kind of misbehavior a peer is caught at
+type MisbehaviorKind int
+
+const (
+	// ConflictingBlock is raised when a peer relays a block whose content differs
+	// from the one of the block with the same sequence number received before.
+	// As the blocks are relayed, the alert doesn't blame the peers they were
+	// received from, which are not quarantined
+	ConflictingBlock MisbehaviorKind = iota
+	// ForgedAliveMessage is raised when a peer sends an alive message whose
+	// signature or PKI-ID don't match the identity of the peer it claims to be from
+	ForgedAliveMessage
+)
+
+func (k MisbehaviorKind) String() string {
+	switch k {
+	case ConflictingBlock:
+		return "ConflictingBlock"
+	case ForgedAliveMessage:
+		return "ForgedAliveMessage"
+	default:
+		return fmt.Sprintf("MisbehaviorKind(%d)", int(k))
+	}
+}
+
+// MisbehaviorAlert reports conflicting blocks, or a peer caught sending a forged
+// alive message
+type MisbehaviorAlert struct {
+	Kind    MisbehaviorKind
+	PKIID   common.PKIidType // PKI-ID of the peer the data was received from, not necessarily its producer
+	Channel common.ChainID   // Channel of the block, nil for alive messages
+	SeqNum  uint64           // Sequence number of the block
+	// ConflictsWith is the PKI-ID of the peer the block conflicting with the one
+	// received was received from, or our own if we gossiped it
+	ConflictsWith common.PKIidType
+	Detail        string
+	Time          time.Time
+}
+
+func (a *MisbehaviorAlert) String() string {
+	if a.Kind == ConflictingBlock {
+		return fmt.Sprintf("%s on channel %s, block %d relayed by %v conflicts with the one relayed by %v: %s", a.Kind, string(a.Channel), a.SeqNum, a.PKIID, a.ConflictsWith, a.Detail)
+	}
+	return fmt.Sprintf("%s from %v: %s", a.Kind, a.PKIID, a.Detail)
+}
+
+// blockDigest is the hash of the content of a block, and the peer it was received from
+type blockDigest struct {
+	hash   []byte
+	sender common.PKIidType
+}
+
+// channelBlocks holds the digests of the last blocks of a channel
+type channelBlocks struct {
+	highest uint64
+	digests map[uint64]*blockDigest
+}
+
+// misbehaviorDetector compares the blocks the peers send with the ones received
+// before with the same sequence number, and is reported the alive messages
+// found forged. It raises an alert about both, and quarantines the peers caught
+// sending forged alive messages: their messages are discarded until the
+// quarantine expires. The blocks being relayed, and their signatures not being
+// verified yet, the peers a conflicting block is received from aren't blamed
+type misbehaviorDetector struct {
+	self               common.PKIidType
+	window             int
+	quarantineDuration time.Duration
+	logger             *logging.Logger
+
+	lock        sync.Mutex
+	blocks      map[string]*channelBlocks
+	quarantined map[string]time.Time
+	subscribers []chan *MisbehaviorAlert
+	stopped     bool
+
+	conflictingBlocks   uint64
+	forgedAliveMessages uint64
+	discardedMessages   uint64
+	droppedAlerts       uint64
+}
+
+// newMisbehaviorDetector returns a misbehaviorDetector remembering the digests
+// of the last window blocks of every channel, and quarantining the peers caught
+// for quarantineDuration, or not at all if it is 0
+func newMisbehaviorDetector(self common.PKIidType, window int, quarantineDuration time.Duration, logger *logging.Logger) *misbehaviorDetector {
+	if window < 1 {
+		window = 1
+	}
+	return &misbehaviorDetector{
+		self:               self,
+		window:             window,
+		quarantineDuration: quarantineDuration,
+		logger:             logger,
+		blocks:             make(map[string]*channelBlocks),
+		quarantined:        make(map[string]time.Time),
+	}
+}
+
+// checkBlock records the digest of a block received from sender, and raises an
+// alert if a block with the same sequence number but a different content was
+// received before. The block is not rejected nor its sender quarantined, as
+// sender only relayed it. A conflict with a block we gossiped ourselves is
+// reported as received from the peer that sent the other
+func (d *misbehaviorDetector) checkBlock(msg *proto.GossipMessage, sender common.PKIidType) {
+	payload := msg.GetDataMsg().GetPayload()
+	if payload == nil {
+		return
+	}
+	hash := sha256.Sum256(payload.Data)
+
+	d.lock.Lock()
+	chBlocks, exists := d.blocks[string(msg.Channel)]
+	if !exists {
+		chBlocks = &channelBlocks{digests: make(map[uint64]*blockDigest)}
+		d.blocks[string(msg.Channel)] = chBlocks
+	}
+	if payload.SeqNum+uint64(d.window) <= chBlocks.highest {
+		// Too old to be compared with
+		d.lock.Unlock()
+		return
+	}
+	previous, exists := chBlocks.digests[payload.SeqNum]
+	if !exists {
+		chBlocks.digests[payload.SeqNum] = &blockDigest{hash: hash[:], sender: sender}
+		if payload.SeqNum > chBlocks.highest {
+			chBlocks.highest = payload.SeqNum
+			for seqNum := range chBlocks.digests {
+				if seqNum+uint64(d.window) <= chBlocks.highest {
+					delete(chBlocks.digests, seqNum)
+				}
+			}
+		}
+		d.lock.Unlock()
+		return
+	}
+	d.lock.Unlock()
+
+	if bytes.Equal(previous.hash, hash[:]) {
+		return
+	}
+
+	relayer, conflictsWith := sender, previous.sender
+	if bytes.Equal(sender, d.self) {
+		relayer, conflictsWith = previous.sender, sender
+	}
+	atomic.AddUint64(&d.conflictingBlocks, 1)
+	d.report(&MisbehaviorAlert{
+		Kind:          ConflictingBlock,
+		PKIID:         relayer,
+		Channel:       common.ChainID(msg.Channel),
+		SeqNum:        payload.SeqNum,
+		ConflictsWith: conflictsWith,
+		Detail:        fmt.Sprintf("block hashes differ, %x and %x", previous.hash, hash[:]),
+	}, false)
+}
+
+// reportForgedAliveMsg raises an alert about sender, as it sent a forged alive message
+func (d *misbehaviorDetector) reportForgedAliveMsg(msg *proto.GossipMessage, sender common.PKIidType) {
+	atomic.AddUint64(&d.forgedAliveMessages, 1)
+	d.report(&MisbehaviorAlert{
+		Kind:   ForgedAliveMessage,
+		PKIID:  sender,
+		Detail: fmt.Sprintf("alive message claiming to be from %v doesn't match its identity", msg.GetAliveMsg().GetMembership()),
+	}, true)
+}
+
+// isQuarantined returns whether the messages of the given peer are to be
+// discarded, and counts them as discarded if so
+func (d *misbehaviorDetector) isQuarantined(pkiID common.PKIidType) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	expiration, exists := d.quarantined[string(pkiID)]
+	if !exists {
+		return false
+	}
+	if time.Now().After(expiration) {
+		delete(d.quarantined, string(pkiID))
+		d.logger.Info("Quarantine of", pkiID, "expired")
+		return false
+	}
+	atomic.AddUint64(&d.discardedMessages, 1)
+	return true
+}
+
+// report logs the alert, quarantines the peer it is about if quarantine is set
+// and passes the alert to the subscribers, dropping it for those whose buffer
+// is full
+func (d *misbehaviorDetector) report(alert *MisbehaviorAlert, quarantine bool) {
+	alert.Time = time.Now()
+	d.logger.Warning("Misbehavior detected:", alert)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if quarantine && d.quarantineDuration > 0 && !bytes.Equal(alert.PKIID, d.self) {
+		d.quarantined[string(alert.PKIID)] = alert.Time.Add(d.quarantineDuration)
+		d.logger.Warning("Discarding the messages of", alert.PKIID, "for", d.quarantineDuration)
+	}
+	if d.stopped {
+		return
+	}
+	for _, subscriber := range d.subscribers {
+		select {
+		case subscriber <- alert:
+		default:
+			atomic.AddUint64(&d.droppedAlerts, 1)
+		}
+	}
+}
+
+// subscribe returns a channel the alerts raised from now on are passed to,
+// closed when the detector stops
+func (d *misbehaviorDetector) subscribe() <-chan *MisbehaviorAlert {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	subscriber := make(chan *MisbehaviorAlert, misbehaviorAlertBufferSize)
+	if d.stopped {
+		close(subscriber)
+		return subscriber
+	}
+	d.subscribers = append(d.subscribers, subscriber)
+	return subscriber
+}
+
+// stop closes the channels of the subscribers
+func (d *misbehaviorDetector) stop() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.stopped {
+		return
+	}
+	d.stopped = true
+	for _, subscriber := range d.subscribers {
+		close(subscriber)
+	}
+	d.subscribers = nil
+}
+
+// stats returns the number of misbehaviors detected, of peers in quarantine,
+// and of messages and alerts discarded
+func (d *misbehaviorDetector) stats() interface{} {
+	now := time.Now()
+	quarantined := uint64(0)
+	d.lock.Lock()
+	for _, expiration := range d.quarantined {
+		if now.Before(expiration) {
+			quarantined++
+		}
+	}
+	d.lock.Unlock()
+	return map[string]uint64{
+		"conflictingBlocks":   atomic.LoadUint64(&d.conflictingBlocks),
+		"forgedAliveMessages": atomic.LoadUint64(&d.forgedAliveMessages),
+		"quarantinedPeers":    quarantined,
+		"discardedMessages":   atomic.LoadUint64(&d.discardedMessages),
+		"droppedAlerts":       atomic.LoadUint64(&d.droppedAlerts),
+	}
+}
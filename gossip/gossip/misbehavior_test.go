@@ -0,0 +1,172 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/identity"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMisbehaviorDetector(window int, quarantineDuration time.Duration) *misbehaviorDetector {
+	return newMisbehaviorDetector(common.PKIidType("self"), window, quarantineDuration, util.GetLogger(util.LoggingGossipModule, "self"))
+}
+
+func TestMisbehaviorDetectorConflictingBlock(t *testing.T) {
+	t.Parallel()
+	d := newTestMisbehaviorDetector(10, time.Minute)
+	alerts := d.subscribe()
+
+	// The same block sent by several peers isn't a conflict
+	d.checkBlock(createDataMsg(1, []byte("block1"), "", common.ChainID("A")), common.PKIidType("p1"))
+	d.checkBlock(createDataMsg(1, []byte("block1"), "", common.ChainID("A")), common.PKIidType("p2"))
+	// nor are blocks with the same sequence number on different channels
+	d.checkBlock(createDataMsg(1, []byte("other"), "", common.ChainID("B")), common.PKIidType("p2"))
+	assert.Equal(t, uint64(0), d.stats().(map[string]uint64)["conflictingBlocks"])
+
+	d.checkBlock(createDataMsg(1, []byte("forged"), "", common.ChainID("A")), common.PKIidType("p3"))
+	select {
+	case alert := <-alerts:
+		assert.Equal(t, ConflictingBlock, alert.Kind)
+		assert.Equal(t, common.PKIidType("p3"), alert.PKIID)
+		assert.Equal(t, common.PKIidType("p1"), alert.ConflictsWith)
+		assert.Equal(t, common.ChainID("A"), alert.Channel)
+		assert.Equal(t, uint64(1), alert.SeqNum)
+	case <-time.After(time.Second):
+		t.Fatal("Didn't get an alert about the conflicting block")
+	}
+	// The peers relaying conflicting blocks aren't quarantined
+	assert.False(t, d.isQuarantined(common.PKIidType("p3")))
+	assert.False(t, d.isQuarantined(common.PKIidType("p1")))
+
+	// A conflict with a block we gossiped is reported as received from the peer that sent the other
+	d.checkBlock(createDataMsg(2, []byte("forged"), "", common.ChainID("A")), common.PKIidType("p4"))
+	d.checkBlock(createDataMsg(2, []byte("block2"), "", common.ChainID("A")), common.PKIidType("self"))
+	alert := <-alerts
+	assert.Equal(t, common.PKIidType("p4"), alert.PKIID)
+	assert.Equal(t, common.PKIidType("self"), alert.ConflictsWith)
+	assert.False(t, d.isQuarantined(common.PKIidType("p4")))
+
+	stats := d.stats().(map[string]uint64)
+	assert.Equal(t, uint64(2), stats["conflictingBlocks"])
+	assert.Equal(t, uint64(0), stats["quarantinedPeers"])
+	assert.Equal(t, uint64(0), stats["discardedMessages"])
+}
+
+func TestMisbehaviorDetectorWindow(t *testing.T) {
+	t.Parallel()
+	d := newTestMisbehaviorDetector(3, time.Minute)
+
+	for i := 1; i <= 5; i++ {
+		d.checkBlock(createDataMsg(uint64(i), []byte{byte(i)}, "", common.ChainID("A")), common.PKIidType("p1"))
+	}
+	assert.Len(t, d.blocks["A"].digests, 3)
+	// Blocks older than the window aren't compared with
+	d.checkBlock(createDataMsg(2, []byte("forged"), "", common.ChainID("A")), common.PKIidType("p2"))
+	assert.Equal(t, uint64(0), d.stats().(map[string]uint64)["conflictingBlocks"])
+	d.checkBlock(createDataMsg(3, []byte("forged"), "", common.ChainID("A")), common.PKIidType("p2"))
+	assert.Equal(t, uint64(1), d.stats().(map[string]uint64)["conflictingBlocks"])
+}
+
+func TestMisbehaviorDetectorQuarantineExpires(t *testing.T) {
+	t.Parallel()
+	d := newTestMisbehaviorDetector(10, 100*time.Millisecond)
+	d.reportForgedAliveMsg(&proto.GossipMessage{}, common.PKIidType("p1"))
+	assert.True(t, d.isQuarantined(common.PKIidType("p1")))
+	time.Sleep(200 * time.Millisecond)
+	assert.False(t, d.isQuarantined(common.PKIidType("p1")))
+	assert.Equal(t, uint64(0), d.stats().(map[string]uint64)["quarantinedPeers"])
+
+	// Without quarantine duration, the peers are only reported
+	d = newTestMisbehaviorDetector(10, 0)
+	d.reportForgedAliveMsg(&proto.GossipMessage{}, common.PKIidType("p1"))
+	assert.False(t, d.isQuarantined(common.PKIidType("p1")))
+	assert.Equal(t, uint64(1), d.stats().(map[string]uint64)["forgedAliveMessages"])
+}
+
+func TestMisbehaviorDetectorSubscribers(t *testing.T) {
+	t.Parallel()
+	d := newTestMisbehaviorDetector(10, 0)
+	alerts := d.subscribe()
+
+	// Alerts are dropped while the channel of a subscriber is full
+	for i := 0; i < misbehaviorAlertBufferSize+1; i++ {
+		d.reportForgedAliveMsg(&proto.GossipMessage{}, common.PKIidType("p1"))
+	}
+	assert.Equal(t, uint64(1), d.stats().(map[string]uint64)["droppedAlerts"])
+
+	d.stop()
+	count := 0
+	for range alerts {
+		count++
+	}
+	assert.Equal(t, misbehaviorAlertBufferSize, count)
+	_, open := <-d.subscribe()
+	assert.False(t, open, "Subscribing to a stopped detector yields a closed channel")
+}
+
+func TestValidateAliveMsgForged(t *testing.T) {
+	t.Parallel()
+	mcs := &naiveCryptoService{}
+	sa := &discoverySecurityAdapter{
+		idMapper: identity.NewIdentityMapper(mcs),
+		sa:       orgByIdentitySecAdvisor{},
+		mcs:      mcs,
+		logger:   util.GetLogger(util.LoggingGossipModule, "p0"),
+	}
+	createAliveMsg := func(pkiID string) *proto.GossipMessage {
+		return sa.SignMessage(&proto.GossipMessage{
+			Tag: proto.GossipMessage_EMPTY,
+			Content: &proto.GossipMessage_AliveMsg{
+				AliveMsg: &proto.AliveMessage{
+					Membership: &proto.Member{Endpoint: pkiID + ":7051", PkiID: []byte(pkiID)},
+					Timestamp:  &proto.PeerTime{IncNumber: 1, SeqNum: 1},
+				},
+			},
+		})
+	}
+
+	// An alive message of a peer whose identity is unknown isn't forged
+	valid, forged := sa.validateAliveMsg(createAliveMsg("p1"))
+	assert.False(t, valid)
+	assert.False(t, forged)
+
+	assert.NoError(t, sa.idMapper.Put(common.PKIidType("p1"), api.PeerIdentityType("p1")))
+	msg := createAliveMsg("p1")
+	valid, forged = sa.validateAliveMsg(msg)
+	assert.True(t, valid)
+	assert.False(t, forged)
+
+	// An alive message altered after it was signed is
+	msg.GetAliveMsg().Membership.Endpoint = "attacker:7051"
+	valid, forged = sa.validateAliveMsg(msg)
+	assert.False(t, valid)
+	assert.True(t, forged)
+
+	// as is one carrying an identity other than the one of the PKI-ID it claims
+	msg = createAliveMsg("p1")
+	msg.GetAliveMsg().Identity = []byte("p2")
+	valid, forged = sa.validateAliveMsg(msg)
+	assert.False(t, valid)
+	assert.True(t, forged)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/comm"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+)
+
+// maxBatchedMessages is the number of pull messages to a peer which triggers
+// sending them without waiting for the end of the window
+const maxBatchedMessages = 64
+
+// Coalescer holds the pull messages sent to a remote peer for a short window
+// and sends the messages of all the mediators, i.e. of all the channels, to
+// that peer within the window as a single PullBatch message. The pull rounds
+// of the mediators of a peer being aligned, the hellos, digests, requests and
+// responses of every round to the same peer are coalesced. A window <= 0
+// disables the coalescing, as do peers sending their pull messages one by one
+type Coalescer struct {
+	sndr   Sender
+	window time.Duration
+
+	sync.Mutex
+	pending map[string]*pendingBatch
+	stopped bool
+}
+
+// pendingBatch is the pull messages held for a remote peer
+type pendingBatch struct {
+	send     func(*proto.GossipMessage)
+	messages []*proto.GossipMessage
+}
+
+// NewCoalescer returns a Coalescer sending the pull messages through sndr
+func NewCoalescer(window time.Duration, sndr Sender) *Coalescer {
+	return &Coalescer{sndr: sndr, window: window, pending: make(map[string]*pendingBatch)}
+}
+
+// Send sends a message to remote peers, a pull message being held to be sent
+// along with the other pull messages to the same peers
+func (c *Coalescer) Send(msg *proto.GossipMessage, peers ...*comm.RemotePeer) {
+	if c.window <= 0 || !msg.IsPullMsg() {
+		c.sndr.Send(msg, peers...)
+		return
+	}
+	for _, peer := range peers {
+		peer := peer
+		c.add(string(peer.PKIID), msg, func(m *proto.GossipMessage) {
+			c.sndr.Send(m, peer)
+		})
+	}
+}
+
+// Respond responds to a received message, a pull message being held to be
+// sent along with the other pull messages to the same peer
+func (c *Coalescer) Respond(msg *proto.GossipMessage, to proto.ReceivedMessage) {
+	if c.window <= 0 || !msg.IsPullMsg() {
+		to.Respond(msg)
+		return
+	}
+	c.add(string(to.GetPKIID()), msg, to.Respond)
+}
+
+func (c *Coalescer) add(pkiID string, msg *proto.GossipMessage, send func(*proto.GossipMessage)) {
+	c.Lock()
+	if c.stopped {
+		c.Unlock()
+		send(msg)
+		return
+	}
+	batch, exists := c.pending[pkiID]
+	if !exists {
+		batch = &pendingBatch{send: send}
+		c.pending[pkiID] = batch
+		time.AfterFunc(c.window, func() {
+			c.flush(pkiID, batch)
+		})
+	}
+	batch.messages = append(batch.messages, msg)
+	full := len(batch.messages) >= maxBatchedMessages
+	c.Unlock()
+
+	if full {
+		c.flush(pkiID, batch)
+	}
+}
+
+// flush sends the messages of batch unless it has already been sent
+func (c *Coalescer) flush(pkiID string, batch *pendingBatch) {
+	c.Lock()
+	if c.pending[pkiID] != batch {
+		c.Unlock()
+		return
+	}
+	delete(c.pending, pkiID)
+	c.Unlock()
+
+	batch.send(batchMessage(batch.messages))
+}
+
+// Stop sends the messages held and stops holding messages
+func (c *Coalescer) Stop() {
+	c.Lock()
+	pending := c.pending
+	c.pending = make(map[string]*pendingBatch)
+	c.stopped = true
+	c.Unlock()
+
+	for _, batch := range pending {
+		batch.send(batchMessage(batch.messages))
+	}
+}
+
+// batchMessage returns the PullBatch of messages, or the message itself if
+// there is only one
+func batchMessage(messages []*proto.GossipMessage) *proto.GossipMessage {
+	if len(messages) == 1 {
+		return messages[0]
+	}
+	return &proto.GossipMessage{
+		Tag: proto.GossipMessage_EMPTY,
+		Content: &proto.GossipMessage_PullBatch{
+			PullBatch: &proto.PullBatch{Messages: messages},
+		},
+	}
+}
+
+// Unbatch returns the pull messages of a PullBatch received, each to be
+// handled as if it was received alone, their responses being coalesced
+func (c *Coalescer) Unbatch(m proto.ReceivedMessage) []proto.ReceivedMessage {
+	batch := m.GetGossipMessage().GetPullBatch()
+	if batch == nil {
+		return nil
+	}
+	messages := make([]proto.ReceivedMessage, 0, len(batch.Messages))
+	for _, msg := range batch.Messages {
+		// batches only carry pull messages
+		if msg == nil || !msg.IsPullMsg() {
+			continue
+		}
+		messages = append(messages, &batchedMessage{ReceivedMessage: m, msg: msg, coalescer: c})
+	}
+	return messages
+}
+
+// batchedMessage is a pull message received in a PullBatch
+type batchedMessage struct {
+	proto.ReceivedMessage
+	msg       *proto.GossipMessage
+	coalescer *Coalescer
+}
+
+// GetGossipMessage returns the pull message
+func (m *batchedMessage) GetGossipMessage() *proto.GossipMessage {
+	return m.msg
+}
+
+// Respond responds to the pull message through the coalescer
+func (m *batchedMessage) Respond(msg *proto.GossipMessage) {
+	m.coalescer.Respond(msg, m.ReceivedMessage)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/comm"
+	"github.com/hyperledger/fabric/gossip/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+type sentMsg struct {
+	msg  *proto.GossipMessage
+	peer string
+}
+
+type recordingSender struct {
+	sync.Mutex
+	sent []sentMsg
+}
+
+func (s *recordingSender) Send(msg *proto.GossipMessage, peers ...*comm.RemotePeer) {
+	s.Lock()
+	defer s.Unlock()
+	for _, peer := range peers {
+		s.sent = append(s.sent, sentMsg{msg: msg, peer: string(peer.PKIID)})
+	}
+}
+
+// receivedFrom returns a message received from pkiID, whose responses are
+// recorded as sent to pkiID
+func (s *recordingSender) receivedFrom(pkiID string, msg *proto.GossipMessage) proto.ReceivedMessage {
+	return &receivedMsg{sender: s, pkiID: pkiID, msg: msg}
+}
+
+func (s *recordingSender) messages() []sentMsg {
+	s.Lock()
+	defer s.Unlock()
+	return append([]sentMsg(nil), s.sent...)
+}
+
+type receivedMsg struct {
+	sender *recordingSender
+	pkiID  string
+	msg    *proto.GossipMessage
+}
+
+func (m *receivedMsg) Respond(msg *proto.GossipMessage) {
+	m.sender.Send(msg, &comm.RemotePeer{PKIID: common.PKIidType(m.pkiID)})
+}
+
+func (m *receivedMsg) GetGossipMessage() *proto.GossipMessage {
+	return m.msg
+}
+
+func (m *receivedMsg) GetSourceMessage() *proto.SignedGossipMessage {
+	return nil
+}
+
+func (m *receivedMsg) GetPKIID() common.PKIidType {
+	return common.PKIidType(m.pkiID)
+}
+
+func channelHello(channel string, nonce uint64) *proto.GossipMessage {
+	return &proto.GossipMessage{
+		Channel: []byte(channel),
+		Tag:     proto.GossipMessage_CHAN_AND_ORG,
+		Content: &proto.GossipMessage_Hello{
+			Hello: &proto.GossipHello{Nonce: nonce, MsgType: proto.PullMsgType_BlockMessage},
+		},
+	}
+}
+
+func TestCoalescer(t *testing.T) {
+	sndr := &recordingSender{}
+	c := NewCoalescer(50*time.Millisecond, sndr)
+	p1 := &comm.RemotePeer{Endpoint: "p1", PKIID: common.PKIidType("p1")}
+	p2 := &comm.RemotePeer{Endpoint: "p2", PKIID: common.PKIidType("p2")}
+
+	c.Send(channelHello("A", 1), p1, p2)
+	c.Send(channelHello("B", 2), p1)
+	// messages other than pull messages are not held
+	c.Send(&proto.GossipMessage{Tag: proto.GossipMessage_EMPTY, Content: &proto.GossipMessage_Empty{Empty: &proto.Empty{}}}, p1)
+	assert.Len(t, sndr.messages(), 1)
+
+	time.Sleep(200 * time.Millisecond)
+	sent := sndr.messages()
+	assert.Len(t, sent, 3)
+	for _, m := range sent[1:] {
+		switch m.peer {
+		case "p1":
+			batch := m.msg.GetPullBatch()
+			assert.NotNil(t, batch)
+			assert.Len(t, batch.Messages, 2)
+			assert.Equal(t, []byte("A"), batch.Messages[0].Channel)
+			assert.Equal(t, []byte("B"), batch.Messages[1].Channel)
+			assert.NoError(t, m.msg.IsTagLegal())
+		case "p2":
+			// a single message is sent as is
+			assert.Equal(t, uint64(1), m.msg.GetHello().Nonce)
+		default:
+			t.Fatalf("Unexpected peer %s", m.peer)
+		}
+	}
+
+	// a full batch is sent without waiting for the end of the window
+	for i := 0; i < maxBatchedMessages; i++ {
+		c.Send(channelHello("A", uint64(i)), p1)
+	}
+	sent = sndr.messages()
+	assert.Len(t, sent, 4)
+	assert.Len(t, sent[3].msg.GetPullBatch().Messages, maxBatchedMessages)
+}
+
+func TestCoalescerUnbatch(t *testing.T) {
+	sndr := &recordingSender{}
+	c := NewCoalescer(50*time.Millisecond, sndr)
+
+	batch := batchMessage([]*proto.GossipMessage{channelHello("A", 1), channelHello("B", 2), {
+		Tag:     proto.GossipMessage_EMPTY,
+		Content: &proto.GossipMessage_Empty{Empty: &proto.Empty{}},
+	}})
+	received := c.Unbatch(sndr.receivedFrom("p1", batch))
+	// only the pull messages are unbatched
+	assert.Len(t, received, 2)
+	assert.Equal(t, []byte("B"), received[1].GetGossipMessage().Channel)
+	assert.Equal(t, common.PKIidType("p1"), received[1].GetPKIID())
+	assert.Nil(t, c.Unbatch(sndr.receivedFrom("p1", channelHello("A", 1))))
+
+	// the responses to the messages of a batch are coalesced as well
+	for _, m := range received {
+		m.Respond(&proto.GossipMessage{
+			Channel: m.GetGossipMessage().Channel,
+			Tag:     proto.GossipMessage_CHAN_AND_ORG,
+			Content: &proto.GossipMessage_DataDig{
+				DataDig: &proto.DataDigest{Nonce: m.GetGossipMessage().GetHello().Nonce, MsgType: proto.PullMsgType_BlockMessage},
+			},
+		})
+	}
+	assert.Len(t, sndr.messages(), 0)
+	c.Stop()
+	sent := sndr.messages()
+	assert.Len(t, sent, 1)
+	assert.Equal(t, "p1", sent[0].peer)
+	assert.Len(t, sent[0].msg.GetPullBatch().Messages, 2)
+
+	// once stopped, nothing is held
+	c.Send(channelHello("A", 3), &comm.RemotePeer{PKIID: common.PKIidType("p1")})
+	assert.Len(t, sndr.messages(), 2)
+}
+
+func TestCoalescerDisabled(t *testing.T) {
+	sndr := &recordingSender{}
+	c := NewCoalescer(0, sndr)
+	p1 := &comm.RemotePeer{Endpoint: "p1", PKIID: common.PKIidType("p1")}
+	c.Send(channelHello("A", 1), p1)
+	c.Send(channelHello("B", 2), p1)
+	c.Respond(channelHello("C", 3), sndr.receivedFrom("p1", channelHello("C", 3)))
+	assert.Len(t, sndr.messages(), 3)
+}
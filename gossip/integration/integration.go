@@ -18,6 +18,7 @@ package integration
 
 import (
 	"crypto/tls"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -57,12 +58,19 @@ func newConfig(selfEndpoint string, externalEndpoint string, bootPeers ...string
 		PropagatePeerNum:           util.GetIntOrDefault("peer.gossip.propagatePeerNum", 3),
 		PullInterval:               util.GetDurationOrDefault("peer.gossip.pullInterval", 4*time.Second),
 		PullPeerNum:                util.GetIntOrDefault("peer.gossip.pullPeerNum", 3),
+		PullBatchWindow:            util.GetDurationOrDefault("peer.gossip.pullBatchWindow", 0),
 		InternalEndpoint:           selfEndpoint,
 		ExternalEndpoint:           externalEndpoint,
 		PublishCertPeriod:          util.GetDurationOrDefault("peer.gossip.publishCertPeriod", 10*time.Second),
+		LeaveGracePeriod:           util.GetDurationOrDefault("peer.gossip.leaveGracePeriod", 500*time.Millisecond),
 		RequestStateInfoInterval:   util.GetDurationOrDefault("peer.gossip.requestStateInfoInterval", 4*time.Second),
 		PublishStateInfoInterval:   util.GetDurationOrDefault("peer.gossip.publishStateInfoInterval", 4*time.Second),
 		SkipBlockVerification:      viper.GetBool("peer.gossip.skipBlockVerification"),
+		RequireOrgAttestation:      viper.GetBool("peer.gossip.requireOrgAttestation"),
+		CryptoWorkers:              util.GetIntOrDefault("peer.gossip.crypto.workers", runtime.NumCPU()),
+		CryptoQueueSize:            util.GetIntOrDefault("peer.gossip.crypto.queueSize", 1000),
+		CryptoBatchSize:            util.GetIntOrDefault("peer.gossip.crypto.batchSize", 10),
+		QuarantineDuration:         viper.GetDuration("peer.gossip.quarantineDuration"),
 		TLSServerCert:              cert,
 	}
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package service
 
 import (
+	"fmt"
 	"sync"
 
 	peerComm "github.com/hyperledger/fabric/core/comm"
@@ -52,10 +53,19 @@ type GossipService interface {
 	NewConfigEventer() ConfigProcessor
 	// InitializeChannel allocates the state provider and should be invoked once per channel per execution
 	InitializeChannel(chainID string, committer committer.Committer)
+	// StopChannel stops the state provider and the delivery of the blocks of the channel, and leaves
+	// the channel, so that it can be initialized again later
+	StopChannel(chainID string)
 	// GetBlock returns block for given chain
 	GetBlock(chainID string, index uint64) *common.Block
 	// AddPayload appends message payload to for given chain
 	AddPayload(chainID string, payload *proto.Payload) error
+	// UpdateConfigDigest advertises to the peers of the chain the digest of its current config
+	UpdateConfigDigest(chainID string, digest []byte)
+	// ConfigDigests returns the config digests the alive peers of the chain advertise, by endpoint
+	ConfigDigests(chainID string) map[string][]byte
+	// EndorsersOfChannel returns the endpoints of the alive peers of the chain which endorse proposals, by organization
+	EndorsersOfChannel(chainID string) map[string][]string
 }
 
 // DeliveryServiceFactory factory to create and initialize delivery service instance
@@ -78,6 +88,7 @@ type gossipServiceImpl struct {
 	deliveryService deliverclient.DeliverService
 	deliveryFactory DeliveryServiceFactory
 	lock            sync.RWMutex
+	configDigests   map[string][]byte
 	msgCrypto       identity.Mapper
 	peerIdentity    []byte
 }
@@ -132,9 +143,17 @@ func InitGossipServiceCustomDeliveryFactory(peerIdentity []byte, endpoint string
 		idMapper := identity.NewIdentityMapper(cryptSvc)
 
 		gossip := integration.NewGossipComponent(peerIdentity, endpoint, s, secAdv, cryptSvc, idMapper, dialOpts, bootPeers...)
+		if viper.GetBool("peer.readOnly") {
+			metadata, err := (&PeerMetadata{ReadOnly: true}).Bytes()
+			if err != nil {
+				logger.Panic("Failed encoding peer metadata", err)
+			}
+			gossip.UpdateMetadata(metadata)
+		}
 		gossipServiceInstance = &gossipServiceImpl{
 			gossipSvc:       gossip,
 			chains:          make(map[string]state.GossipStateProvider),
+			configDigests:   make(map[string][]byte),
 			deliveryFactory: factory,
 			msgCrypto:       idMapper,
 			peerIdentity:    peerIdentity,
@@ -159,6 +178,9 @@ func (g *gossipServiceImpl) InitializeChannel(chainID string, committer committe
 	// Initialize new state provider for given committer
 	logger.Debug("Creating state provider for chainID", chainID)
 	g.chains[chainID] = state.NewGossipStateProvider(chainID, g, committer)
+	if digest, ok := g.configDigests[chainID]; ok && g.chains[chainID] != nil {
+		g.chains[chainID].UpdateConfigDigest(digest)
+	}
 	if g.deliveryService == nil {
 		var err error
 		g.deliveryService, err = g.deliveryFactory.Service(gossipServiceInstance)
@@ -176,6 +198,25 @@ func (g *gossipServiceImpl) InitializeChannel(chainID string, committer committe
 	}
 }
 
+// StopChannel stops the state provider and the delivery of the blocks of the
+// channel, and the gossip instance leaves the channel. The config digest of
+// the channel is kept, it is advertised again if the channel is initialized
+func (g *gossipServiceImpl) StopChannel(chainID string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	logger.Info("Stopping chain", chainID)
+	if g.deliveryService != nil {
+		if err := g.deliveryService.LeaveChain(chainID); err != nil {
+			logger.Warning("Delivery service is not able to leave the chain, due to", err)
+		}
+	}
+	if ch, exists := g.chains[chainID]; exists {
+		ch.Stop()
+		delete(g.chains, chainID)
+	}
+	g.LeaveChan(gossipCommon.ChainID(chainID))
+}
+
 // configUpdated constructs a joinChannelMessage and sends it to the gossipSvc
 func (g *gossipServiceImpl) configUpdated(config Config) {
 	jcm := &joinChannelMessage{seqNum: config.Sequence(), anchorPeers: []api.AnchorPeer{}}
@@ -199,14 +240,73 @@ func (g *gossipServiceImpl) configUpdated(config Config) {
 func (g *gossipServiceImpl) GetBlock(chainID string, index uint64) *common.Block {
 	g.lock.RLock()
 	defer g.lock.RUnlock()
-	return g.chains[chainID].GetBlock(index)
+	ch, exists := g.chains[chainID]
+	if !exists {
+		return nil
+	}
+	return ch.GetBlock(index)
 }
 
 // AddPayload appends message payload to for given chain
 func (g *gossipServiceImpl) AddPayload(chainID string, payload *proto.Payload) error {
+	// the lock is not held while adding, which may wait for the ledger to
+	// catch up, as stopping the chain meanwhile takes the lock
 	g.lock.RLock()
-	defer g.lock.RUnlock()
-	return g.chains[chainID].AddPayload(payload)
+	ch, exists := g.chains[chainID]
+	g.lock.RUnlock()
+	if !exists {
+		return fmt.Errorf("Chain %s isn't initialized", chainID)
+	}
+	return ch.AddPayload(payload)
+}
+
+// UpdateConfigDigest advertises to the peers of the chain the digest of its
+// current config. The config of a chain is known before its state provider is
+// initialized, which advertises it then
+func (g *gossipServiceImpl) UpdateConfigDigest(chainID string, digest []byte) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.configDigests[chainID] = digest
+	if ch := g.chains[chainID]; ch != nil {
+		ch.UpdateConfigDigest(digest)
+	}
+}
+
+// ConfigDigests returns the config digests the alive peers of the chain
+// advertise, by endpoint. The peers which advertise none are left out
+func (g *gossipServiceImpl) ConfigDigests(chainID string) map[string][]byte {
+	digests := make(map[string][]byte)
+	for _, member := range g.PeersOfChannel(gossipCommon.ChainID(chainID)) {
+		if nodeMetadata, err := state.FromBytes(member.Metadata); err == nil && len(nodeMetadata.ConfigDigest) > 0 {
+			digests[member.Endpoint] = nodeMetadata.ConfigDigest
+		}
+	}
+	return digests
+}
+
+// EndorsersOfChannel returns the endpoints of the alive peers of the chain,
+// by the organization they belong to. The read-only peers are left out, as
+// well as the peers whose organization isn't known
+func (g *gossipServiceImpl) EndorsersOfChannel(chainID string) map[string][]string {
+	readOnly := make(map[string]bool)
+	for _, member := range g.Peers() {
+		if IsReadOnly(member) {
+			readOnly[string(member.PKIid)] = true
+		}
+	}
+
+	endorsers := make(map[string][]string)
+	for _, member := range g.PeersOfChannel(gossipCommon.ChainID(chainID)) {
+		if readOnly[string(member.PKIid)] {
+			continue
+		}
+		org := g.OrgOfPeer(member.PKIid)
+		if len(org) == 0 {
+			continue
+		}
+		endorsers[string(org)] = append(endorsers[string(org)], member.Endpoint)
+	}
+	return endorsers
 }
 
 // Stop stops the gossip component
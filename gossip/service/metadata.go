@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/hyperledger/fabric/gossip/discovery"
+)
+
+// PeerMetadata is the information a peer advertises about itself to the
+// other members of the network through its alive messages
+type PeerMetadata struct {
+	// ReadOnly is set by peers which commit blocks and serve queries and
+	// events, but do not endorse proposals
+	ReadOnly bool
+}
+
+// Bytes encodes the peer metadata for dissemination
+func (pm *PeerMetadata) Bytes() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	err := binary.Write(buffer, binary.BigEndian, *pm)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// PeerMetadataFromBytes decodes peer metadata, peers which do not advertise any
+// have the zero value
+func PeerMetadataFromBytes(buf []byte) (*PeerMetadata, error) {
+	pm := PeerMetadata{}
+	if len(buf) == 0 {
+		return &pm, nil
+	}
+	err := binary.Read(bytes.NewReader(buf), binary.BigEndian, &pm)
+	if err != nil {
+		return nil, err
+	}
+	return &pm, nil
+}
+
+// IsReadOnly returns whether member advertised itself as a read-only peer,
+// which should not be sent proposals to endorse
+func IsReadOnly(member discovery.NetworkMember) bool {
+	pm, err := PeerMetadataFromBytes(member.Metadata)
+	if err != nil {
+		logger.Warning("Peer", member.Endpoint, "advertised malformed metadata:", err)
+		return false
+	}
+	return pm.ReadOnly
+}
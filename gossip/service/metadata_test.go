@@ -0,0 +1,35 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerMetadata(t *testing.T) {
+	metadata, err := (&PeerMetadata{ReadOnly: true}).Bytes()
+	assert.NoError(t, err)
+
+	assert.True(t, IsReadOnly(discovery.NetworkMember{Endpoint: "p0", Metadata: metadata}))
+	assert.False(t, IsReadOnly(discovery.NetworkMember{Endpoint: "p1", Metadata: []byte{}}))
+
+	_, err = PeerMetadataFromBytes([]byte{})
+	assert.NoError(t, err, "Peers advertising no metadata should decode to the defaults")
+}
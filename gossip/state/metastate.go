@@ -27,11 +27,15 @@ type NodeMetastate struct {
 
 	// Actual ledger height
 	LedgerHeight uint64
+
+	// ConfigDigest is the digest of the current config of the channel, it
+	// follows the height so that peers which only read the height ignore it
+	ConfigDigest []byte
 }
 
 // NewNodeMetastate creates new meta data with given ledger height148.69
 func NewNodeMetastate(height uint64) *NodeMetastate {
-	return &NodeMetastate{height}
+	return &NodeMetastate{LedgerHeight: height}
 }
 
 // Bytes decodes meta state into byte array for serialization
@@ -40,10 +44,11 @@ func (n *NodeMetastate) Bytes() ([]byte, error) {
 	// Explicitly specify byte order for write into the buffer
 	// to provide cross platform support, note the it consistent
 	// with FromBytes function
-	err := binary.Write(buffer, binary.BigEndian, *n)
+	err := binary.Write(buffer, binary.BigEndian, n.LedgerHeight)
 	if err != nil {
 		return nil, err
 	}
+	buffer.Write(n.ConfigDigest)
 	return buffer.Bytes(), nil
 }
 
@@ -64,9 +69,12 @@ func FromBytes(buf []byte) (*NodeMetastate, error) {
 	// As bytes are written in the big endian to keep supporting
 	// cross platforming and for consistency reasons read also
 	// done using same order
-	err := binary.Read(reader, binary.BigEndian, &state)
+	err := binary.Read(reader, binary.BigEndian, &state.LedgerHeight)
 	if err != nil {
 		return nil, err
 	}
+	if reader.Len() > 0 {
+		state.ConfigDigest = buf[len(buf)-reader.Len():]
+	}
 	return &state, nil
 }
@@ -73,3 +73,22 @@ func TestNodeMetastate_FromBytes(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Equal(t, updatedState.Height(), uint64(17))
 }
+
+// Check the config digest follows the height and is optional
+func TestNodeMetastate_ConfigDigest(t *testing.T) {
+	metastate := NewNodeMetastate(17)
+	metastate.ConfigDigest = []byte{1, 2, 3}
+	bytes, err := metastate.Bytes()
+	assert.NilError(t, err)
+
+	state, err := FromBytes(bytes)
+	assert.NilError(t, err)
+	assert.Equal(t, state.Height(), uint64(17))
+	assert.Equal(t, string(state.ConfigDigest), string([]byte{1, 2, 3}))
+
+	// peers which only advertise the height have no digest
+	state, err = FromBytes(bytes[:8])
+	assert.NilError(t, err)
+	assert.Equal(t, state.Height(), uint64(17))
+	assert.Equal(t, len(state.ConfigDigest), 0)
+}
@@ -35,6 +35,10 @@ type PayloadsBuffer interface {
 	// Adds new block into the buffer
 	Push(payload *proto.Payload) error
 
+	// Adds new block into the buffer, waiting for room in the buffer
+	// until done is closed
+	PushWait(payload *proto.Payload, done <-chan struct{}) error
+
 	// Returns next expected sequence number
 	Next() uint64
 
@@ -58,6 +62,12 @@ type PayloadsBufferImpl struct {
 
 	next uint64
 
+	// maxPending bounds how far ahead of next a payload may be, <= 0 means unbounded
+	maxPending int
+
+	// popped is closed and replaced whenever a payload is popped
+	popped chan struct{}
+
 	readyChan chan struct{}
 
 	mutex sync.RWMutex
@@ -70,11 +80,23 @@ func NewPayloadsBuffer(next uint64) PayloadsBuffer {
 	return &PayloadsBufferImpl{
 		buf:       make(map[uint64]*proto.Payload),
 		readyChan: make(chan struct{}, 0),
+		popped:    make(chan struct{}),
 		next:      next,
 		logger:    util.GetLogger(util.LoggingStateModule, ""),
 	}
 }
 
+// NewBoundedPayloadsBuffer creates a payloads buffer which holds no payload
+// maxPending or more sequence numbers ahead of the next expected one, bounding
+// the memory held by blocks waiting to be committed. Push refuses such a
+// payload, to be fetched again once the ledger has caught up, while PushWait
+// waits for the ledger to catch up
+func NewBoundedPayloadsBuffer(next uint64, maxPending int) PayloadsBuffer {
+	b := NewPayloadsBuffer(next).(*PayloadsBufferImpl)
+	b.maxPending = maxPending
+	return b
+}
+
 // Ready function returns the channel which indicates whenever expected
 // next block has arrived and one could safely pop out
 // next sequence of blocks
@@ -89,6 +111,42 @@ func (b *PayloadsBufferImpl) Push(payload *proto.Payload) error {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
+	if b.tooFarAhead(payload.SeqNum) {
+		return fmt.Errorf("Payload with sequence number = %s is too far ahead of the next expected = %s",
+			strconv.FormatUint(payload.SeqNum, 10), strconv.FormatUint(b.next, 10))
+	}
+	return b.push(payload)
+}
+
+// PushWait pushes new payload into the buffer structure like Push, except
+// that a payload too far ahead of the next expected block number waits for
+// the payloads before it to be popped, applying backpressure to the caller.
+// An error is returned if done is closed while waiting
+func (b *PayloadsBufferImpl) PushWait(payload *proto.Payload, done <-chan struct{}) error {
+	b.mutex.Lock()
+	for b.tooFarAhead(payload.SeqNum) {
+		popped := b.popped
+		b.mutex.Unlock()
+		select {
+		case <-popped:
+		case <-done:
+			return fmt.Errorf("Stopped waiting to push payload with sequence number = %s",
+				strconv.FormatUint(payload.SeqNum, 10))
+		}
+		b.mutex.Lock()
+	}
+	defer b.mutex.Unlock()
+	return b.push(payload)
+}
+
+// tooFarAhead returns whether the buffer cannot hold the payload with seqNum
+// until more payloads are popped. It must be called with the mutex held
+func (b *PayloadsBufferImpl) tooFarAhead(seqNum uint64) bool {
+	return b.maxPending > 0 && seqNum >= b.next+uint64(b.maxPending)
+}
+
+// push adds payload to the buffer. It must be called with the mutex held
+func (b *PayloadsBufferImpl) push(payload *proto.Payload) error {
 	seqNum := payload.SeqNum
 
 	if seqNum < b.next || b.buf[seqNum] != nil {
@@ -127,6 +185,9 @@ func (b *PayloadsBufferImpl) Pop() *proto.Payload {
 		delete(b.buf, b.Next())
 		// Increment next expect block index
 		atomic.AddUint64(&b.next, 1)
+		// Wake up the payloads waiting for room
+		close(b.popped)
+		b.popped = make(chan struct{})
 	}
 	return result
 }
@@ -93,6 +93,74 @@ func TestPayloadsBufferImpl_Push(t *testing.T) {
 	assert.Equal(t, buffer.Size(), 1)
 }
 
+func TestPayloadsBufferImpl_BoundedPush(t *testing.T) {
+	buffer := NewBoundedPayloadsBuffer(5, 3)
+
+	for seqNum := uint64(5); seqNum < 8; seqNum++ {
+		payload, err := randomPayloadWithSeqNum(seqNum)
+		if err != nil {
+			t.Fatal("Wasn't able to generate random payload for test")
+		}
+		assert.NoError(t, buffer.Push(payload))
+	}
+
+	// Payloads too far ahead of the next expected one should be refused
+	payload, err := randomPayloadWithSeqNum(8)
+	if err != nil {
+		t.Fatal("Wasn't able to generate random payload for test")
+	}
+	assert.Error(t, buffer.Push(payload))
+	assert.Equal(t, buffer.Size(), 3)
+
+	// Once the next payload is popped there is room for one more
+	assert.NotNil(t, buffer.Pop())
+	assert.NoError(t, buffer.Push(payload))
+	assert.Equal(t, buffer.Size(), 3)
+}
+
+func TestPayloadsBufferImpl_PushWait(t *testing.T) {
+	buffer := NewBoundedPayloadsBuffer(5, 1)
+
+	payload, err := randomPayloadWithSeqNum(5)
+	if err != nil {
+		t.Fatal("Wasn't able to generate random payload for test")
+	}
+	assert.NoError(t, buffer.PushWait(payload, nil))
+
+	// A payload too far ahead waits for the next payload to be popped
+	payload, err = randomPayloadWithSeqNum(6)
+	if err != nil {
+		t.Fatal("Wasn't able to generate random payload for test")
+	}
+	pushed := make(chan error, 1)
+	go func() {
+		pushed <- buffer.PushWait(payload, nil)
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("Payload too far ahead should wait for room in the buffer")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.NotNil(t, buffer.Pop())
+	select {
+	case err = <-pushed:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Payload should have been pushed once there was room in the buffer")
+	}
+	assert.Equal(t, buffer.Size(), 1)
+
+	// Waiting stops once done is closed
+	payload, err = randomPayloadWithSeqNum(7)
+	if err != nil {
+		t.Fatal("Wasn't able to generate random payload for test")
+	}
+	done := make(chan struct{})
+	close(done)
+	assert.Error(t, buffer.PushWait(payload, done))
+	assert.Equal(t, buffer.Size(), 1)
+}
+
 func TestPayloadsBufferImpl_Ready(t *testing.T) {
 	fin := make(chan struct{})
 	buffer := NewPayloadsBuffer(1)
@@ -25,6 +25,7 @@ import (
 
 	pb "github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/committer"
+	"github.com/hyperledger/fabric/core/limits"
 	"github.com/hyperledger/fabric/gossip/comm"
 	common2 "github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/gossip"
@@ -43,6 +44,9 @@ type GossipStateProvider interface {
 
 	AddPayload(payload *proto.Payload) error
 
+	// UpdateConfigDigest advertises the digest of the new config of the channel
+	UpdateConfigDigest(digest []byte)
+
 	// Stop terminates state transfer object
 	Stop()
 }
@@ -74,6 +78,9 @@ type GossipStateProviderImpl struct {
 	// Flag which signals for termination
 	stopFlag int32
 
+	// stopCh is closed on termination
+	stopCh chan struct{}
+
 	mutex sync.RWMutex
 
 	// Queue of payloads which wasn't acquired yet
@@ -81,6 +88,10 @@ type GossipStateProviderImpl struct {
 
 	committer committer.Committer
 
+	// metastate is the last state advertised to the other peers
+	metastateLock sync.Mutex
+	metastate     NodeMetastate
+
 	logger *logging.Logger
 
 	done sync.WaitGroup
@@ -121,24 +132,17 @@ func NewGossipStateProvider(chainID string, g gossip.Gossip, committer committer
 		commChan: commChan,
 
 		stopFlag: 0,
+		stopCh:   make(chan struct{}),
 		// Create a queue for payload received
-		payloads: NewPayloadsBuffer(height),
+		payloads: NewBoundedPayloadsBuffer(height, limits.ForChannel(chainID).PendingBlocks),
 
 		committer: committer,
 
 		logger: logger,
 	}
 
-	state := NewNodeMetastate(height - 1)
-
-	s.logger.Infof("Updating node metadata information, current ledger sequence is at = %d, next expected block is = %d", state.LedgerHeight, s.payloads.Next())
-	bytes, err := state.Bytes()
-	if err == nil {
-		s.logger.Debug("Updating gossip metadate state", state)
-		g.UpdateChannelMetadata(bytes, common2.ChainID(s.chainID))
-	} else {
-		s.logger.Errorf("Unable to serialize node meta state, error = %s", err)
-	}
+	s.logger.Infof("Updating node metadata information, current ledger sequence is at = %d, next expected block is = %d", height-1, s.payloads.Next())
+	s.publishMetastate(func(state *NodeMetastate) { state.Update(height - 1) })
 
 	s.done.Add(3)
 
@@ -253,6 +257,7 @@ func (s *GossipStateProviderImpl) isDone() bool {
 // Stop function send halting signal to all go routines
 func (s *GossipStateProviderImpl) Stop() {
 	atomic.StoreInt32(&s.stopFlag, 1)
+	close(s.stopCh)
 	s.done.Wait()
 	s.committer.Close()
 }
@@ -387,10 +392,12 @@ func (s *GossipStateProviderImpl) GetBlock(index uint64) *common.Block {
 	return nil
 }
 
-// AddPayload add new payload into state
+// AddPayload add new payload into state. While the payloads buffer is full
+// the call blocks until the ledger catches up, so that the caller delivering
+// the blocks slows down rather than having its blocks dropped
 func (s *GossipStateProviderImpl) AddPayload(payload *proto.Payload) error {
 	s.logger.Debug("Adding new payload into the buffer, seqNum = ", payload.SeqNum)
-	return s.payloads.Push(payload)
+	return s.payloads.PushWait(payload, s.stopCh)
 }
 
 func (s *GossipStateProviderImpl) commitBlock(block *common.Block, seqNum uint64) error {
@@ -400,15 +407,30 @@ func (s *GossipStateProviderImpl) commitBlock(block *common.Block, seqNum uint64
 	}
 
 	// Update ledger level within node metadata
-	state := NewNodeMetastate(seqNum)
-	// Decode state to byte array
-	bytes, err := state.Bytes()
-	if err == nil {
-		s.gossip.UpdateChannelMetadata(bytes, common2.ChainID(s.chainID))
-	} else {
-		s.logger.Errorf("Unable to serialize node meta state, error = %s", err)
-	}
+	s.publishMetastate(func(state *NodeMetastate) { state.Update(seqNum) })
 
 	s.logger.Debug("Commit success, created a block!")
 	return nil
 }
+
+// UpdateConfigDigest advertises the digest of the new config of the channel
+// with the current ledger height
+func (s *GossipStateProviderImpl) UpdateConfigDigest(digest []byte) {
+	s.publishMetastate(func(state *NodeMetastate) { state.ConfigDigest = digest })
+}
+
+// publishMetastate applies update to the state advertised to the other peers
+// of the channel and advertises it
+func (s *GossipStateProviderImpl) publishMetastate(update func(state *NodeMetastate)) {
+	s.metastateLock.Lock()
+	defer s.metastateLock.Unlock()
+	update(&s.metastate)
+	// Decode state to byte array
+	bytes, err := s.metastate.Bytes()
+	if err != nil {
+		s.logger.Errorf("Unable to serialize node meta state, error = %s", err)
+		return
+	}
+	s.logger.Debug("Updating gossip metadate state", s.metastate)
+	s.gossip.UpdateChannelMetadata(bytes, common2.ChainID(s.chainID))
+}
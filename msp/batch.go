@@ -0,0 +1,155 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+// SignedMessage is a message signed by an identity
+type SignedMessage struct {
+	Identity  Identity
+	Msg       []byte
+	Signature []byte
+}
+
+// VerifiedSignatures are the signatures found valid by a VerifyBatch, by the
+// hash of the key, digest and signature. Until they are released, Verify
+// accepts them without verifying them again. A valid signature of a digest by
+// a key stays valid, whatever the identity the key belongs to, so that they
+// only save work: the identities are still validated by their callers
+type VerifiedSignatures struct {
+	signatures map[[sha256.Size]byte]struct{}
+}
+
+// retainedSignatures are the VerifiedSignatures not released yet, those of
+// the blocks being validated
+var retainedSignatures = struct {
+	sync.RWMutex
+	sets map[*VerifiedSignatures]struct{}
+}{sets: map[*VerifiedSignatures]struct{}{}}
+
+func verifiedSignatureID(ski, digest, signature []byte) [sha256.Size]byte {
+	h := sha256.New()
+	for _, b := range [][]byte{ski, digest, signature} {
+		h.Write([]byte{byte(len(b) >> 8), byte(len(b))})
+		h.Write(b)
+	}
+	var id [sha256.Size]byte
+	copy(id[:], h.Sum(nil))
+	return id
+}
+
+// Release stops Verify from accepting the signatures without verifying them.
+// It does nothing on nil
+func (v *VerifiedSignatures) Release() {
+	if v == nil {
+		return
+	}
+	retainedSignatures.Lock()
+	delete(retainedSignatures.sets, v)
+	retainedSignatures.Unlock()
+}
+
+// isVerified returns whether a retained VerifiedSignatures has the signature
+func isVerified(ski, digest, signature []byte) bool {
+	retainedSignatures.RLock()
+	defer retainedSignatures.RUnlock()
+	if len(retainedSignatures.sets) == 0 {
+		return false
+	}
+	id := verifiedSignatureID(ski, digest, signature)
+	for v := range retainedSignatures.sets {
+		if _, ok := v.signatures[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+type verifyBatch struct {
+	indexes []int
+	items   []bccsp.VerifyItem
+}
+
+// VerifyBatch verifies the signatures of messages, errs[i] being what
+// messages[i].Identity.Verify returns. The signatures of the identities of
+// this package whose BCCSP is a bccsp.BatchVerifier are verified in batches,
+// and the valid ones are returned, retained so that verifying them again, as
+// the validation of a block does, is cheap until the caller releases them.
+// The other signatures are verified one at a time
+func VerifyBatch(messages []SignedMessage) (errs []error, verified *VerifiedSignatures) {
+	errs = make([]error, len(messages))
+	verified = &VerifiedSignatures{signatures: map[[sha256.Size]byte]struct{}{}}
+	batches := map[bccsp.BatchVerifier]*verifyBatch{}
+	for i, m := range messages {
+		var id *identity
+		switch mid := m.Identity.(type) {
+		case *identity:
+			id = mid
+		case *signingidentity:
+			id = &mid.identity
+		}
+		var batchVerifier bccsp.BatchVerifier
+		ok := id != nil
+		if ok {
+			batchVerifier, ok = id.msp.bccsp.(bccsp.BatchVerifier)
+		}
+		if !ok {
+			errs[i] = m.Identity.Verify(m.Msg, m.Signature)
+			continue
+		}
+
+		digest, err := id.digest(m.Msg)
+		if err != nil {
+			errs[i] = fmt.Errorf("Failed computing digest [%s]", err)
+			continue
+		}
+		batch, ok := batches[batchVerifier]
+		if !ok {
+			batch = &verifyBatch{}
+			batches[batchVerifier] = batch
+		}
+		batch.indexes = append(batch.indexes, i)
+		batch.items = append(batch.items, bccsp.VerifyItem{Key: id.pk, Signature: m.Signature, Digest: digest})
+	}
+
+	for batchVerifier, batch := range batches {
+		valid, verrs := batchVerifier.VerifyBatch(batch.items)
+		for j, i := range batch.indexes {
+			switch {
+			case verrs[j] != nil:
+				errs[i] = fmt.Errorf("Could not determine the validity of the signature, err %s", verrs[j])
+			case !valid[j]:
+				errs[i] = errors.New("The signature is invalid")
+			default:
+				item := batch.items[j]
+				verified.signatures[verifiedSignatureID(item.Key.SKI(), item.Digest, item.Signature)] = struct{}{}
+			}
+		}
+	}
+
+	retainedSignatures.Lock()
+	retainedSignatures.sets[verified] = struct{}{}
+	retainedSignatures.Unlock()
+	return errs, verified
+}
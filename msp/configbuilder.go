@@ -139,3 +139,67 @@ func GetLocalMspConfig(dir string, ID string) (*msp.MSPConfig, error) {
 
 	return mspconf, nil
 }
+
+// GetMspConfigFromFabricCA builds the config of the MSP ID from the material served by a
+// fabric-ca server: its CA chain, the certificates it issued to the admins adminIDs and
+// its CRL. Only the signing identity is read from the signcerts and keystore directories
+// of dir. It may be called again to refresh the material, e.g. after a revocation
+func GetMspConfigFromFabricCA(dir string, ID string, client FabricCAClient, adminIDs []string) (*msp.MSPConfig, error) {
+	signcertDir := filepath.Join(dir, signcerts)
+	keystoreDir := filepath.Join(dir, keystore)
+
+	signcert, err := getPemMaterialFromDir(signcertDir)
+	if err != nil || len(signcert) == 0 {
+		return nil, fmt.Errorf("Could not load a valid signer certificate from directory %s, err %s", signcertDir, err)
+	}
+
+	keys, err := getPemMaterialFromDir(keystoreDir)
+	if err != nil || len(keys) == 0 {
+		return nil, fmt.Errorf("Could not load a valid signing key from directory %s, err %s", keystoreDir, err)
+	}
+
+	cacerts, intermediatecert, err := client.CAChain()
+	if err != nil {
+		return nil, fmt.Errorf("Could not fetch the CA chain, err %s", err)
+	}
+
+	admincert := make([][]byte, 0)
+	for _, adminID := range adminIDs {
+		certs, err := client.Certificates(adminID)
+		if err != nil {
+			return nil, fmt.Errorf("Could not fetch the certificates of admin %s, err %s", adminID, err)
+		}
+		admincert = append(admincert, certs...)
+	}
+	if len(admincert) == 0 {
+		return nil, fmt.Errorf("Could not fetch a valid admin certificate for admins %v", adminIDs)
+	}
+
+	crl, err := client.CRL()
+	if err != nil {
+		return nil, fmt.Errorf("Could not fetch the CRL, err %s", err)
+	}
+	var revocationList [][]byte
+	if len(crl) > 0 {
+		revocationList = [][]byte{crl}
+	}
+
+	// The same assumptions as for GetLocalMspConfig hold for the signing identity
+	keyinfo := &msp.KeyInfo{KeyIdentifier: "PEER", KeyMaterial: keys[0]}
+
+	sigid := &msp.SigningIdentityInfo{PublicSigner: signcert[0], PrivateSigner: keyinfo}
+
+	fmspconf := &msp.FabricMSPConfig{
+		Admins:            admincert,
+		RootCerts:         cacerts,
+		IntermediateCerts: intermediatecert,
+		RevocationList:    revocationList,
+		SigningIdentity:   sigid,
+		Name:              ID}
+
+	fmpsjs, _ := proto.Marshal(fmspconf)
+
+	mspconf := &msp.MSPConfig{Config: fmpsjs, Type: int32(FABRIC)}
+
+	return mspconf, nil
+}
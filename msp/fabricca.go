@@ -0,0 +1,238 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hyperledger/fabric/bccsp/utils"
+)
+
+// FabricCAClient retrieves the material of an MSP from a fabric-ca server
+type FabricCAClient interface {
+	// CAChain returns the PEM certificates of the root and of the intermediate CAs
+	// of the server
+	CAChain() (rootCerts [][]byte, intermediateCerts [][]byte, err error)
+
+	// Certificates returns the PEM certificates the server issued to enrollmentID
+	Certificates(enrollmentID string) ([][]byte, error)
+
+	// CRL returns the PEM certificate revocation list of the server
+	CRL() ([]byte, error)
+}
+
+type fabricCAClient struct {
+	url        string
+	caName     string
+	httpClient *http.Client
+
+	// cert and key are the enrollment authenticating the requests which require it
+	cert []byte
+	key  *ecdsa.PrivateKey
+}
+
+// caResponse is the envelope of all the responses of the fabric-ca API
+type caResponse struct {
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// NewFabricCAClient creates a FabricCAClient for the CA caName (the default CA if empty)
+// of the fabric-ca server at serverURL. The server is only reached over https, its TLS
+// certificate being verified against the PEM root certificates tlsRootCerts alone, as
+// the material it serves becomes the trust roots of the MSP. The requests which require
+// authentication are signed with the enrollment made of the PEM certificate cert and
+// PEM ECDSA key key
+func NewFabricCAClient(serverURL, caName string, tlsRootCerts []byte, cert, key []byte) (FabricCAClient, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("The fabric-ca server URL %s must be an https URL", serverURL)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(tlsRootCerts) {
+		return nil, fmt.Errorf("No valid TLS root certificate of the fabric-ca server %s", serverURL)
+	}
+
+	privateKey, err := utils.PEMtoPrivateKey(key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Could not load the enrollment key, err %s", err)
+	}
+	ecdsaKey, ok := privateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("The enrollment key must be an ECDSA key")
+	}
+	return &fabricCAClient{
+		url:        strings.TrimSuffix(serverURL, "/"),
+		caName:     caName,
+		httpClient: &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}},
+		cert:       cert,
+		key:        ecdsaKey,
+	}, nil
+}
+
+func (c *fabricCAClient) CAChain() ([][]byte, [][]byte, error) {
+	result := &struct {
+		CAChain []byte
+	}{}
+	if err := c.call("POST", "cainfo", c.caNameBody(), false, result); err != nil {
+		return nil, nil, err
+	}
+
+	var rootCerts, intermediateCerts [][]byte
+	for rest := result.CAChain; len(rest) > 0; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Could not parse the CA chain of %s, err %s", c.url, err)
+		}
+		certPEM := pem.EncodeToMemory(block)
+		if bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil {
+			rootCerts = append(rootCerts, certPEM)
+		} else {
+			intermediateCerts = append(intermediateCerts, certPEM)
+		}
+	}
+
+	if len(rootCerts) == 0 {
+		return nil, nil, fmt.Errorf("The CA chain of %s has no root certificate", c.url)
+	}
+	return rootCerts, intermediateCerts, nil
+}
+
+func (c *fabricCAClient) Certificates(enrollmentID string) ([][]byte, error) {
+	query := url.Values{"id": {enrollmentID}}
+	if c.caName != "" {
+		query.Set("ca", c.caName)
+	}
+	result := &struct {
+		Certs []struct {
+			PEM string
+		}
+	}{}
+	if err := c.call("GET", "certificates?"+query.Encode(), nil, true, result); err != nil {
+		return nil, err
+	}
+
+	certs := make([][]byte, 0, len(result.Certs))
+	for _, cert := range result.Certs {
+		certs = append(certs, []byte(cert.PEM))
+	}
+	return certs, nil
+}
+
+func (c *fabricCAClient) CRL() ([]byte, error) {
+	result := &struct {
+		CRL []byte
+	}{}
+	if err := c.call("POST", "gencrl", c.caNameBody(), true, result); err != nil {
+		return nil, err
+	}
+	return result.CRL, nil
+}
+
+func (c *fabricCAClient) caNameBody() []byte {
+	body, _ := json.Marshal(map[string]string{"caname": c.caName})
+	return body
+}
+
+// call sends a request to the endpoint of the fabric-ca API and unmarshals the result of
+// the response into result
+func (c *fabricCAClient) call(method, endpoint string, body []byte, authenticate bool, result interface{}) error {
+	req, err := http.NewRequest(method, c.url+"/api/v1/"+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Could not create request to %s, err %s", c.url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if authenticate {
+		token, err := c.token(body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Request to %s failed, err %s", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Could not read response of %s, err %s", c.url, err)
+	}
+	caResp := &caResponse{}
+	if err = json.Unmarshal(respBody, caResp); err != nil {
+		return fmt.Errorf("Could not parse response of %s (status %d), err %s", c.url, resp.StatusCode, err)
+	}
+	if !caResp.Success {
+		if len(caResp.Errors) > 0 {
+			return fmt.Errorf("Request to %s failed with code %d: %s", c.url, caResp.Errors[0].Code, caResp.Errors[0].Message)
+		}
+		return fmt.Errorf("Request to %s failed with status %d", c.url, resp.StatusCode)
+	}
+
+	if err = json.Unmarshal(caResp.Result, result); err != nil {
+		return fmt.Errorf("Could not parse result of %s, err %s", c.url, err)
+	}
+	return nil
+}
+
+// token creates the authorization token of a request with the given body, it is made
+// of the enrollment certificate and of a signature over the body and the certificate
+func (c *fabricCAClient) token(body []byte) (string, error) {
+	b64Cert := base64.StdEncoding.EncodeToString(c.cert)
+	digest := sha256.Sum256([]byte(base64.StdEncoding.EncodeToString(body) + "." + b64Cert))
+
+	r, s, err := ecdsa.Sign(rand.Reader, c.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("Could not sign request, err %s", err)
+	}
+	// Signatures are only accepted in their low-S form
+	if halfOrder := new(big.Int).Rsh(c.key.Params().N, 1); s.Cmp(halfOrder) > 0 {
+		s.Sub(c.key.Params().N, s)
+	}
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		return "", fmt.Errorf("Could not marshal signature, err %s", err)
+	}
+
+	return b64Cert + "." + base64.StdEncoding.EncodeToString(sig), nil
+}
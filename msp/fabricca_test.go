@@ -0,0 +1,207 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleConfigDir = "sampleconfig"
+
+// mockFabricCA serves the endpoints of the fabric-ca API used by the FabricCAClient
+type mockFabricCA struct {
+	caChain  []byte
+	admin    []byte
+	crl      []byte
+	identity []byte
+}
+
+func (ca *mockFabricCA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	var result interface{}
+	switch {
+	case r.URL.Path == "/api/v1/cainfo":
+		result = map[string]interface{}{"CAName": "", "CAChain": ca.caChain}
+	case r.URL.Path == "/api/v1/certificates" && r.URL.Query().Get("id") == "admin":
+		if !ca.authorized(r, body) {
+			ca.fail(w, "Unauthorized")
+			return
+		}
+		result = map[string]interface{}{"certs": []map[string]string{{"PEM": string(ca.admin)}}}
+	case r.URL.Path == "/api/v1/gencrl":
+		if !ca.authorized(r, body) {
+			ca.fail(w, "Unauthorized")
+			return
+		}
+		result = map[string]interface{}{"CRL": ca.crl}
+	default:
+		ca.fail(w, "Not found")
+		return
+	}
+
+	resultBytes, _ := json.Marshal(result)
+	json.NewEncoder(w).Encode(&caResponse{Success: true, Result: resultBytes})
+}
+
+func (ca *mockFabricCA) fail(w http.ResponseWriter, message string) {
+	resp := &caResponse{Result: json.RawMessage("null")}
+	resp.Errors = append(resp.Errors, struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{Code: 20, Message: message})
+	json.NewEncoder(w).Encode(resp)
+}
+
+// authorized verifies the token of the request was signed with the key of the expected identity
+func (ca *mockFabricCA) authorized(r *http.Request, body []byte) bool {
+	parts := strings.Split(r.Header.Get("Authorization"), ".")
+	if len(parts) != 2 {
+		return false
+	}
+	certPEM, err := base64.StdEncoding.DecodeString(parts[0])
+	if err != nil || !bytes.Equal(certPEM, ca.identity) {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	ecdsaSig := &struct{ R, S *big.Int }{}
+	if _, err = asn1.Unmarshal(sig, ecdsaSig); err != nil {
+		return false
+	}
+
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	digest := sha256.Sum256([]byte(base64.StdEncoding.EncodeToString(body) + "." + parts[0]))
+	return ecdsa.Verify(cert.PublicKey.(*ecdsa.PublicKey), digest[:], ecdsaSig.R, ecdsaSig.S)
+}
+
+func newMockFabricCA(t *testing.T) *mockFabricCA {
+	caChain, err := readFile(sampleConfigDir + "/cacerts/cacert.pem")
+	assert.NoError(t, err)
+	admin, err := readFile(sampleConfigDir + "/admincerts/admincert.pem")
+	assert.NoError(t, err)
+	identity, err := readFile(sampleConfigDir + "/signcerts/peer.pem")
+	assert.NoError(t, err)
+	return &mockFabricCA{
+		caChain:  caChain,
+		admin:    admin,
+		crl:      pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: []byte("crl")}),
+		identity: identity,
+	}
+}
+
+// tlsRootCert returns the PEM certificate the test server serves over TLS
+func tlsRootCert(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+func newTestFabricCAClient(t *testing.T, server *httptest.Server) FabricCAClient {
+	cert, err := readFile(sampleConfigDir + "/signcerts/peer.pem")
+	assert.NoError(t, err)
+	key, err := readFile(sampleConfigDir + "/keystore/key.pem")
+	assert.NoError(t, err)
+	client, err := NewFabricCAClient(server.URL, "", tlsRootCert(server), cert, key)
+	assert.NoError(t, err)
+	return client
+}
+
+func TestGetMspConfigFromFabricCA(t *testing.T) {
+	ca := newMockFabricCA(t)
+	server := httptest.NewTLSServer(ca)
+	defer server.Close()
+
+	conf, err := GetMspConfigFromFabricCA(sampleConfigDir, "DEFAULT", newTestFabricCAClient(t, server), []string{"admin"})
+	assert.NoError(t, err)
+
+	fabricConf := &msp.FabricMSPConfig{}
+	assert.NoError(t, proto.Unmarshal(conf.Config, fabricConf))
+	assert.Equal(t, "DEFAULT", fabricConf.Name)
+	assert.Equal(t, [][]byte{ca.caChain}, fabricConf.RootCerts)
+	assert.Empty(t, fabricConf.IntermediateCerts)
+	assert.Equal(t, [][]byte{ca.admin}, fabricConf.Admins)
+	assert.Equal(t, [][]byte{ca.crl}, fabricConf.RevocationList)
+	assert.Equal(t, ca.identity, fabricConf.SigningIdentity.PublicSigner)
+
+	// The signing identity is still read from the local material
+	local, err := GetLocalMspConfig(sampleConfigDir, "DEFAULT")
+	assert.NoError(t, err)
+	localConf := &msp.FabricMSPConfig{}
+	assert.NoError(t, proto.Unmarshal(local.Config, localConf))
+	assert.Equal(t, localConf.SigningIdentity, fabricConf.SigningIdentity)
+}
+
+func TestGetMspConfigFromFabricCAFailures(t *testing.T) {
+	ca := newMockFabricCA(t)
+	server := httptest.NewTLSServer(ca)
+	defer server.Close()
+
+	_, err := GetMspConfigFromFabricCA(sampleConfigDir, "DEFAULT", newTestFabricCAClient(t, server), []string{"unknown"})
+	assert.Error(t, err, "Fetching the certificates of an unknown admin should have failed")
+
+	_, err = GetMspConfigFromFabricCA(sampleConfigDir, "DEFAULT", newTestFabricCAClient(t, server), nil)
+	assert.Error(t, err, "An MSP without admins should have been rejected")
+
+	// Requests signed by another identity are not authorized
+	ca.identity = ca.admin
+	_, err = GetMspConfigFromFabricCA(sampleConfigDir, "DEFAULT", newTestFabricCAClient(t, server), []string{"admin"})
+	assert.Error(t, err, "Unauthorized requests should have failed")
+}
+
+func TestNewFabricCAClientRequiresPinnedTLS(t *testing.T) {
+	ca := newMockFabricCA(t)
+	server := httptest.NewTLSServer(ca)
+	defer server.Close()
+	cert, err := readFile(sampleConfigDir + "/signcerts/peer.pem")
+	assert.NoError(t, err)
+	key, err := readFile(sampleConfigDir + "/keystore/key.pem")
+	assert.NoError(t, err)
+
+	_, err = NewFabricCAClient(strings.Replace(server.URL, "https://", "http://", 1), "", tlsRootCert(server), cert, key)
+	assert.Error(t, err, "A plain http URL should have been rejected")
+
+	_, err = NewFabricCAClient(server.URL, "", nil, cert, key)
+	assert.Error(t, err, "A client without TLS root certificate should have been rejected")
+
+	// A server whose certificate is not issued by the pinned root is not trusted
+	client, err := NewFabricCAClient(server.URL, "", ca.caChain, cert, key)
+	assert.NoError(t, err)
+	_, _, err = client.CAChain()
+	assert.Error(t, err, "A server certificate not issued by the pinned root should have been rejected")
+}
@@ -0,0 +1,309 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mspfixtures generates throwaway certificate authorities, MSP
+// configurations and signing identities in memory, for the tests of fabric
+// and of the applications built on it. The material is valid from the time it
+// is generated, so that tests do not depend on static certificates which
+// expire
+package mspfixtures
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/msp"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+)
+
+// Validity is how long the certificates generated are valid for. They are
+// valid from an hour before they are generated, to allow for clock skew
+var Validity = 7 * 24 * time.Hour
+
+// CA is a certificate authority, root or intermediate
+type CA struct {
+	Name    string
+	Cert    *x509.Certificate
+	CertPEM []byte
+
+	key    *ecdsa.PrivateKey
+	parent *CA
+}
+
+// Identity is a certificate issued by a CA along with its private key
+type Identity struct {
+	Name    string
+	Cert    *x509.Certificate
+	CertPEM []byte
+	KeyPEM  []byte
+
+	// CA is the issuer of the certificate
+	CA *CA
+}
+
+func newKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+func newTemplate(name string, ous []string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name, OrganizationalUnit: ous},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(Validity),
+	}, nil
+}
+
+// issue signs the template for the public key of key with the key of the
+// issuer, the template being self-signed if the issuer is nil
+func issue(template *x509.Certificate, key *ecdsa.PrivateKey, issuer *CA) (*x509.Certificate, []byte, error) {
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	ski := sha256.Sum256(pub)
+	template.SubjectKeyId = ski[:]
+
+	parent, signer := template, key
+	if issuer != nil {
+		parent, signer = issuer.Cert, issuer.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Could not create certificate for %s: %s", template.Subject.CommonName, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+func newCA(name string, parent *CA) (*CA, error) {
+	key, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	template, err := newTemplate(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	cert, certPEM, err := issue(template, key, parent)
+	if err != nil {
+		return nil, err
+	}
+	return &CA{Name: name, Cert: cert, CertPEM: certPEM, key: key, parent: parent}, nil
+}
+
+// NewCA generates a self-signed root CA
+func NewCA(name string) (*CA, error) {
+	return newCA(name, nil)
+}
+
+// NewIntermediateCA generates an intermediate CA certified by ca
+func (ca *CA) NewIntermediateCA(name string) (*CA, error) {
+	return newCA(name, ca)
+}
+
+// NewIdentity generates an identity certified by ca, with the organizational
+// units ous
+func (ca *CA) NewIdentity(name string, ous ...string) (*Identity, error) {
+	key, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	template, err := newTemplate(name, ous)
+	if err != nil {
+		return nil, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	cert, certPEM, err := issue(template, key, ca)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	return &Identity{Name: name, Cert: cert, CertPEM: certPEM, KeyPEM: keyPEM, CA: ca}, nil
+}
+
+// Root returns the root CA of the chain of ca
+func (ca *CA) Root() *CA {
+	for ca.parent != nil {
+		ca = ca.parent
+	}
+	return ca
+}
+
+// Org is the MSP of an organization: its CAs, an admin and a member
+type Org struct {
+	MSPID string
+
+	// RootCA certifies IssuingCA, which certifies the identities of the
+	// organization. They are the same CA unless the org was generated with
+	// an intermediate CA
+	RootCA    *CA
+	IssuingCA *CA
+
+	Admin  *Identity
+	Member *Identity
+}
+
+// NewOrg generates an organization of MSP ID mspID whose root CA issues
+// the identities
+func NewOrg(mspID string) (*Org, error) {
+	return newOrg(mspID, false)
+}
+
+// NewOrgWithIntermediateCA generates an organization of MSP ID mspID whose
+// identities are issued by an intermediate CA
+func NewOrgWithIntermediateCA(mspID string) (*Org, error) {
+	return newOrg(mspID, true)
+}
+
+func newOrg(mspID string, intermediate bool) (*Org, error) {
+	root, err := NewCA("ca." + mspID)
+	if err != nil {
+		return nil, err
+	}
+	org := &Org{MSPID: mspID, RootCA: root, IssuingCA: root}
+	if intermediate {
+		if org.IssuingCA, err = root.NewIntermediateCA("ica." + mspID); err != nil {
+			return nil, err
+		}
+	}
+	if org.Admin, err = org.IssuingCA.NewIdentity("admin." + mspID); err != nil {
+		return nil, err
+	}
+	if org.Member, err = org.IssuingCA.NewIdentity("member." + mspID); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// NewIdentity generates an identity of the organization
+func (org *Org) NewIdentity(name string, ous ...string) (*Identity, error) {
+	return org.IssuingCA.NewIdentity(name, ous...)
+}
+
+func (org *Org) intermediateCerts() [][]byte {
+	var certs [][]byte
+	for ca := org.IssuingCA; ca.parent != nil; ca = ca.parent {
+		certs = append(certs, ca.CertPEM)
+	}
+	return certs
+}
+
+// MSPConfig returns the config of the MSP of the organization, signing with
+// signer, or verifying only if signer is nil
+func (org *Org) MSPConfig(signer *Identity) (*mspprotos.MSPConfig, error) {
+	conf := &mspprotos.FabricMSPConfig{
+		Name:              org.MSPID,
+		RootCerts:         [][]byte{org.RootCA.CertPEM},
+		IntermediateCerts: org.intermediateCerts(),
+		Admins:            [][]byte{org.Admin.CertPEM},
+	}
+	if signer != nil {
+		conf.SigningIdentity = &mspprotos.SigningIdentityInfo{
+			PublicSigner:  signer.CertPEM,
+			PrivateSigner: &mspprotos.KeyInfo{KeyIdentifier: signer.Name, KeyMaterial: signer.KeyPEM},
+		}
+	}
+	confBytes, err := proto.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &mspprotos.MSPConfig{Config: confBytes, Type: int32(msp.FABRIC)}, nil
+}
+
+// MSP returns the MSP of the organization, set up to sign with signer, or
+// to verify only if signer is nil
+func (org *Org) MSP(signer *Identity) (msp.MSP, error) {
+	conf, err := org.MSPConfig(signer)
+	if err != nil {
+		return nil, err
+	}
+	m, err := msp.NewBccspMsp()
+	if err != nil {
+		return nil, err
+	}
+	if err = m.Setup(conf); err != nil {
+		return nil, fmt.Errorf("Could not set up MSP %s: %s", org.MSPID, err)
+	}
+	return m, nil
+}
+
+// SigningIdentity returns the identity id of the organization as a signing
+// identity of its MSP
+func (org *Org) SigningIdentity(id *Identity) (msp.SigningIdentity, error) {
+	m, err := org.MSP(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.GetDefaultSigningIdentity()
+}
+
+// Serialize returns the identity id of the organization serialized as by
+// its MSP
+func (org *Org) Serialize(id *Identity) ([]byte, error) {
+	return msp.NewSerializedIdentity(org.MSPID, id.CertPEM)
+}
+
+// WriteDir writes the MSP of the organization in dir, in the layout read by
+// msp.GetLocalMspConfig, signing with signer
+func (org *Org) WriteDir(dir string, signer *Identity) error {
+	files := map[string][]byte{
+		filepath.Join("cacerts", "cacert.pem"):   org.RootCA.CertPEM,
+		filepath.Join("admincerts", "admin.pem"): org.Admin.CertPEM,
+		filepath.Join("signcerts", "cert.pem"):   signer.CertPEM,
+		filepath.Join("keystore", "key.pem"):     signer.KeyPEM,
+	}
+	for i, cert := range org.intermediateCerts() {
+		files[filepath.Join("intermediatecerts", fmt.Sprintf("intermediatecert%d.pem", i))] = cert
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, content, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mspfixtures
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgMSP(t *testing.T) {
+	org, err := NewOrg("Org1MSP")
+	assert.NoError(t, err)
+
+	signer, err := org.SigningIdentity(org.Member)
+	assert.NoError(t, err)
+	assert.Equal(t, "Org1MSP", signer.GetMSPIdentifier())
+	sig, err := signer.Sign([]byte("msg"))
+	assert.NoError(t, err)
+
+	// the identity is verified by a verifying only MSP of the org
+	m, err := org.MSP(nil)
+	assert.NoError(t, err)
+	serialized, err := org.Serialize(org.Member)
+	assert.NoError(t, err)
+	id, err := m.DeserializeIdentity(serialized)
+	assert.NoError(t, err)
+	assert.NoError(t, id.Validate())
+	assert.NoError(t, id.Verify([]byte("msg"), sig))
+	assert.Error(t, id.Verify([]byte("other msg"), sig))
+
+	adminSerialized, err := org.Serialize(org.Admin)
+	assert.NoError(t, err)
+	admin, err := m.DeserializeIdentity(adminSerialized)
+	assert.NoError(t, err)
+	role, err := proto.Marshal(&common.MSPRole{MspIdentifier: "Org1MSP", Role: common.MSPRole_ADMIN})
+	assert.NoError(t, err)
+	adminPrincipal := &common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ROLE, Principal: role}
+	assert.NoError(t, admin.SatisfiesPrincipal(adminPrincipal))
+	assert.Error(t, id.SatisfiesPrincipal(adminPrincipal))
+
+	// an identity of another org is not valid
+	other, err := NewOrg("Org1MSP")
+	assert.NoError(t, err)
+	otherSerialized, err := other.Serialize(other.Member)
+	assert.NoError(t, err)
+	otherID, err := m.DeserializeIdentity(otherSerialized)
+	if err == nil {
+		assert.Error(t, otherID.Validate())
+	}
+}
+
+func TestOrgWithIntermediateCA(t *testing.T) {
+	org, err := NewOrgWithIntermediateCA("Org2MSP")
+	assert.NoError(t, err)
+	assert.True(t, org.IssuingCA != org.RootCA)
+	assert.True(t, org.IssuingCA.Root() == org.RootCA)
+
+	id, err := org.NewIdentity("peer0.org2", "peers")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"peers"}, id.Cert.Subject.OrganizationalUnit)
+
+	signer, err := org.SigningIdentity(id)
+	assert.NoError(t, err)
+	assert.NoError(t, signer.Validate())
+	assert.Equal(t, []string{"peers"}, signer.GetOrganizationalUnits())
+}
+
+func TestWriteDir(t *testing.T) {
+	org, err := NewOrgWithIntermediateCA("Org3MSP")
+	assert.NoError(t, err)
+	dir, err := ioutil.TempDir("", "mspfixtures")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, org.WriteDir(dir, org.Member))
+
+	conf, err := msp.GetLocalMspConfig(dir, "Org3MSP")
+	assert.NoError(t, err)
+	m, err := msp.NewBccspMsp()
+	assert.NoError(t, err)
+	assert.NoError(t, m.Setup(conf))
+	signer, err := m.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	assert.NoError(t, signer.Validate())
+	sig, err := signer.Sign([]byte("msg"))
+	assert.NoError(t, err)
+	assert.NoError(t, signer.Verify([]byte("msg"), sig))
+}
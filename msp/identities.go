@@ -26,6 +26,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/gm"
 	"github.com/hyperledger/fabric/bccsp/signer"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/op/go-logging"
@@ -101,11 +102,16 @@ func (id *identity) Verify(msg []byte, sig []byte) error {
 	// mspLogger.Infof("Verifying signature")
 
 	// Compute Hash
-	digest, err := id.msp.bccsp.Hash(msg, &bccsp.SHAOpts{})
+	digest, err := id.digest(msg)
 	if err != nil {
 		return fmt.Errorf("Failed computing digest [%s]", err)
 	}
 
+	// signatures already verified by VerifyBatch
+	if isVerified(id.pk.SKI(), digest, sig) {
+		return nil
+	}
+
 	// TODO: Are these ok to log ?
 	if mspLogger.IsEnabledFor(logging.DEBUG) {
 		mspLogger.Debugf("Verify: digest = %s", hex.Dump(digest))
@@ -122,6 +128,15 @@ func (id *identity) Verify(msg []byte, sig []byte) error {
 	return nil
 }
 
+// digest returns the digest of msg which the key of this identity signs,
+// SM3 over the identifier of the signer and msg for SM2 keys
+func (id *identity) digest(msg []byte) ([]byte, error) {
+	if pub, ok := id.cert.PublicKey.(*gm.PublicKey); ok {
+		return gm.Digest(pub, gm.DefaultSM2ID, msg)
+	}
+	return id.msp.bccsp.Hash(msg, &bccsp.SHAOpts{})
+}
+
 func (id *identity) VerifyOpts(msg []byte, sig []byte, opts SignatureOpts) error {
 	// TODO
 	return nil
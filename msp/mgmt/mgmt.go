@@ -39,6 +39,45 @@ func LoadLocalMsp(dir string, mspID string) error {
 	return GetLocalMSP().Setup(conf)
 }
 
+// LoadLocalMspFromFabricCA loads the local MSP from the material served by a fabric-ca
+// server through client, only its signing identity is read from the specified directory
+func LoadLocalMspFromFabricCA(dir string, mspID string, client msp.FabricCAClient, adminIDs []string) error {
+	if mspID == "" {
+		return errors.New("The local MSP must have an ID")
+	}
+
+	conf, err := msp.GetMspConfigFromFabricCA(dir, mspID, client, adminIDs)
+	if err != nil {
+		return err
+	}
+
+	return GetLocalMSP().Setup(conf)
+}
+
+// RefreshLocalMspFromFabricCA sets up a new local MSP from the material currently
+// served by a fabric-ca server through client, and replaces the local MSP with it.
+// The local MSP is left unchanged if the new one cannot be set up. The components
+// which kept a reference to the previous local MSP keep using it
+func RefreshLocalMspFromFabricCA(dir string, mspID string, client msp.FabricCAClient, adminIDs []string) error {
+	conf, err := msp.GetMspConfigFromFabricCA(dir, mspID, client, adminIDs)
+	if err != nil {
+		return err
+	}
+
+	refreshed, err := msp.NewBccspMsp()
+	if err != nil {
+		return err
+	}
+	if err = refreshed.Setup(conf); err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	localMsp = refreshed
+	return nil
+}
+
 // FIXME: AS SOON AS THE CHAIN MANAGEMENT CODE IS COMPLETE,
 // THESE MAPS AND HELPSER FUNCTIONS SHOULD DISAPPEAR BECAUSE
 // OWNERSHIP OF PER-CHAIN MSP MANAGERS WILL BE HANDLED BY IT;
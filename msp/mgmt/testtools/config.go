@@ -22,6 +22,7 @@ import (
 
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/fixtures"
 	"github.com/hyperledger/fabric/msp/mgmt"
 	mspprotos "github.com/hyperledger/fabric/protos/msp"
 )
@@ -42,6 +43,17 @@ func getConfigPath(dir string) (string, error) {
 	return dir, nil
 }
 
+func setupMSPsForTesting(conf *mspprotos.MSPConfig) error {
+	err := mgmt.GetLocalMSP().Setup(conf)
+	if err != nil {
+		return err
+	}
+
+	fakeConfig := []*mspprotos.MSPConfig{conf}
+
+	return mgmt.GetManagerForChain(util.GetTestChainID()).Setup(fakeConfig)
+}
+
 // LoadTestMSPSetup sets up the local MSP
 // and a chain MSP for the default chain
 func LoadMSPSetupForTesting(dir string) error {
@@ -54,17 +66,24 @@ func LoadMSPSetupForTesting(dir string) error {
 		return err
 	}
 
-	err = mgmt.GetLocalMSP().Setup(conf)
+	return setupMSPsForTesting(conf)
+}
+
+// LoadGeneratedMSPSetupForTesting sets up the local MSP and a chain MSP for
+// the default chain like LoadMSPSetupForTesting, from an organization
+// generated in memory rather than from static certificates. The local MSP
+// signs as the member of the organization returned
+func LoadGeneratedMSPSetupForTesting() (*mspfixtures.Org, error) {
+	org, err := mspfixtures.NewOrg("DEFAULT")
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	fakeConfig := []*mspprotos.MSPConfig{conf}
-
-	err = mgmt.GetManagerForChain(util.GetTestChainID()).Setup(fakeConfig)
+	conf, err := org.MSPConfig(org.Member)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	return nil
+	if err = setupMSPsForTesting(conf); err != nil {
+		return nil, err
+	}
+	return org, nil
 }
@@ -24,6 +24,7 @@ import (
 	"fmt"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/msp"
 	"github.com/stretchr/testify/assert"
@@ -277,6 +278,36 @@ func TestOUPolicyPrincipal(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestIdentityTypePrincipal(t *testing.T) {
+	fabricConf := &msp.FabricMSPConfig{}
+	assert.NoError(t, proto.Unmarshal(conf.Config, fabricConf))
+	fabricConf.PeerOrganizationalUnits = []string{"COP"}
+	fabricConf.ClientOrganizationalUnits = []string{"client"}
+	confBytes, err := proto.Marshal(fabricConf)
+	assert.NoError(t, err)
+
+	typedMsp, err := NewBccspMsp()
+	assert.NoError(t, err)
+	assert.NoError(t, typedMsp.Setup(&msp.MSPConfig{Type: conf.Type, Config: confBytes}))
+	id, err := typedMsp.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+
+	principal := func(role common.MSPRole_MSPRoleType) *common.MSPPrincipal {
+		bytes, err := proto.Marshal(&common.MSPRole{MspIdentifier: "DEFAULT", Role: role})
+		assert.NoError(t, err)
+		return &common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ROLE, Principal: bytes}
+	}
+
+	assert.NoError(t, id.SatisfiesPrincipal(principal(common.MSPRole_PEER)))
+	assert.NoError(t, id.SatisfiesPrincipal(principal(common.MSPRole_MEMBER)))
+	assert.Error(t, id.SatisfiesPrincipal(principal(common.MSPRole_CLIENT)), "The identity is not in a client OU")
+	assert.Error(t, id.SatisfiesPrincipal(principal(common.MSPRole_ORDERER)), "The MSP does not classify orderers")
+
+	localID, err := localMsp.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	assert.Error(t, localID.SatisfiesPrincipal(principal(common.MSPRole_PEER)), "An MSP without identity types should not classify peers")
+}
+
 var conf *msp.MSPConfig
 var localMsp MSP
 var mspMgr MSPManager
@@ -311,3 +342,55 @@ func TestMain(m *testing.M) {
 	retVal := m.Run()
 	os.Exit(retVal)
 }
+
+func TestVerifyBatch(t *testing.T) {
+	id, err := localMsp.GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("GetSigningIdentity should have succeeded")
+	}
+	serializedID, err := id.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize should have succeeded")
+	}
+	idBack, err := localMsp.DeserializeIdentity(serializedID)
+	if err != nil {
+		t.Fatalf("DeserializeIdentity should have succeeded")
+	}
+
+	var messages []SignedMessage
+	for i := 0; i < 8; i++ {
+		msg := []byte(fmt.Sprintf("foo %d", i))
+		sig, err := id.Sign(msg)
+		if err != nil {
+			t.Fatalf("Sign should have succeeded")
+		}
+		messages = append(messages, SignedMessage{Identity: idBack, Msg: msg, Signature: sig})
+	}
+	messages = append(messages, SignedMessage{Identity: idBack, Msg: []byte("bar"), Signature: messages[0].Signature})
+
+	errs, verified := VerifyBatch(messages)
+	for i := 0; i < len(messages)-1; i++ {
+		if errs[i] != nil {
+			t.Fatalf("The signature %d should be valid, got %s", i, errs[i])
+		}
+		digest, _ := idBack.(*identity).msp.bccsp.Hash(messages[i].Msg, &bccsp.SHAOpts{})
+		if !isVerified(idBack.(*identity).pk.SKI(), digest, messages[i].Signature) {
+			t.Fatalf("The signature %d should be retained", i)
+		}
+		if err = idBack.Verify(messages[i].Msg, messages[i].Signature); err != nil {
+			t.Fatalf("The signature %d should still be valid, got %s", i, err)
+		}
+	}
+	if errs[len(errs)-1] == nil {
+		t.Fatalf("The signature of another message should be invalid")
+	}
+	if err = idBack.Verify([]byte("bar"), messages[0].Signature); err == nil {
+		t.Fatalf("The signature of another message should still be invalid")
+	}
+
+	verified.Release()
+	digest, _ := idBack.(*identity).msp.bccsp.Hash(messages[0].Msg, &bccsp.SHAOpts{})
+	if isVerified(idBack.(*identity).pk.SKI(), digest, messages[0].Signature) {
+		t.Fatalf("The signatures should not be retained once released")
+	}
+}
@@ -26,8 +26,11 @@ import (
 
 	"errors"
 
+	"time"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/gm"
 	"github.com/hyperledger/fabric/bccsp/signer"
 	"github.com/hyperledger/fabric/bccsp/sw"
 	"github.com/hyperledger/fabric/protos/common"
@@ -57,18 +60,31 @@ type bccspmsp struct {
 
 	// verification options for MSP members
 	opts *x509.VerifyOptions
+
+	// whether a root or intermediate cert is an SM2 one, such that the
+	// chains of the members are verified by gm.VerifyChain
+	sm2Chains bool
+
+	// organizational units classifying the members by identity type
+	identityTypeOUs map[common.MSPRole_MSPRoleType][]string
 }
 
 // NewBccspMsp returns an MSP instance backed up by a BCCSP
 // crypto provider. It handles x.509 certificates and can
 // generate identities and signing identities backed by
-// certificates and keypairs
+// certificates and keypairs. The certificates may carry
+// SM2 keys and signatures, which only verify: the signing
+// identities remain ECDSA
 func NewBccspMsp() (MSP, error) {
 	mspLogger.Debugf("Creating BCCSP-based MSP instance")
 
 	// TODO: security level, hash family and keystore should
 	// be probably set in the appropriate way.
-	bccsp, err := sw.NewDefaultSecurityLevelWithKeystore(&sw.DummyKeyStore{})
+	swBCCSP, err := sw.NewDefaultSecurityLevelWithKeystore(&sw.DummyKeyStore{})
+	if err != nil {
+		return nil, fmt.Errorf("Failed initiliazing BCCSP [%s]", err)
+	}
+	bccsp, err := gm.New(swBCCSP)
 	if err != nil {
 		return nil, fmt.Errorf("Failed initiliazing BCCSP [%s]", err)
 	}
@@ -92,7 +108,7 @@ func (msp *bccspmsp) getIdentityFromConf(idBytes []byte) (Identity, error) {
 
 	// get a cert
 	var cert *x509.Certificate
-	cert, err := x509.ParseCertificate(pemCert.Bytes)
+	cert, err := gm.ParseCertificate(pemCert.Bytes)
 	if err != nil {
 		return nil, fmt.Errorf("getIdentityFromBytes error: failed to parse x509 cert, err %s", err)
 	}
@@ -211,16 +227,26 @@ func (msp *bccspmsp) Setup(conf1 *m.MSPConfig) error {
 		msp.signer = sid
 	}
 
+	// record the organizational units of the identity types
+	msp.identityTypeOUs = map[common.MSPRole_MSPRoleType][]string{
+		common.MSPRole_PEER:    conf.PeerOrganizationalUnits,
+		common.MSPRole_CLIENT:  conf.ClientOrganizationalUnits,
+		common.MSPRole_ORDERER: conf.OrdererOrganizationalUnits,
+	}
+
 	// pre-create the verify options with roots and intermediates
 	msp.opts = &x509.VerifyOptions{
 		Roots:         x509.NewCertPool(),
 		Intermediates: x509.NewCertPool(),
 	}
+	msp.sm2Chains = false
 	for _, v := range msp.rootCerts {
 		msp.opts.Roots.AddCert(v.(*identity).cert)
+		msp.sm2Chains = msp.sm2Chains || gm.IsSM2Certificate(v.(*identity).cert)
 	}
 	for _, v := range msp.intermediateCerts {
 		msp.opts.Intermediates.AddCert(v.(*identity).cert)
+		msp.sm2Chains = msp.sm2Chains || gm.IsSM2Certificate(v.(*identity).cert)
 	}
 
 	return nil
@@ -289,6 +315,15 @@ func (msp *bccspmsp) Validate(id Identity) error {
 		//    of paths (e.g. it can be signed by CA -> iCA1 -> iCA2 and it can be
 		//    signed by CA but not by CA -> iCA1)
 
+		// the SM2 chains, which golang cannot verify, are verified by gm
+		if msp.sm2Chains || gm.IsSM2Certificate(id.(*identity).cert) {
+			_, err := gm.VerifyChain(id.(*identity).cert, certs(msp.rootCerts), certs(msp.intermediateCerts), time.Now())
+			if err != nil {
+				return fmt.Errorf("The supplied identity is not valid, VerifyChain() returned %s", err)
+			}
+			return nil
+		}
+
 		// ask golang to validate the cert for us based on the options that we've built at setup time
 		_, err := id.(*identity).cert.Verify(*(msp.opts))
 		if err != nil {
@@ -327,7 +362,7 @@ func (msp *bccspmsp) deserializeIdentityInternal(serializedIdentity []byte) (Ide
 	if bl == nil {
 		return nil, fmt.Errorf("Could not decode the PEM structure")
 	}
-	cert, err := x509.ParseCertificate(bl.Bytes)
+	cert, err := gm.ParseCertificate(bl.Bytes)
 	if err != nil {
 		return nil, fmt.Errorf("ParseCertificate failed %s", err)
 	}
@@ -375,8 +410,39 @@ func (msp *bccspmsp) SatisfiesPrincipal(id Identity, principal *common.MSPPrinci
 		// whether this identity is valid for the MSP
 		case common.MSPRole_MEMBER:
 			return msp.Validate(id)
+		// in the case of admin, we check that the
+		// identity is exactly one of our admins
 		case common.MSPRole_ADMIN:
-			panic("Not yet implemented")
+			idCert, ok := id.(*identity)
+			if !ok {
+				return fmt.Errorf("Identity type not recognized")
+			}
+			for _, admin := range msp.admins {
+				if bytes.Equal(idCert.cert.Raw, admin.(*identity).cert.Raw) {
+					return msp.Validate(id)
+				}
+			}
+			return errors.New("This identity is not an admin")
+		// in the case of an identity type, we check that the
+		// identity is valid and has one of the organizational
+		// units of the type
+		case common.MSPRole_PEER, common.MSPRole_CLIENT, common.MSPRole_ORDERER:
+			typeOUs := msp.identityTypeOUs[mspRole.Role]
+			if len(typeOUs) == 0 {
+				return fmt.Errorf("MSP %s does not classify %s identities", msp.name, mspRole.Role)
+			}
+			err = msp.Validate(id)
+			if err != nil {
+				return err
+			}
+			for _, ou := range id.GetOrganizationalUnits() {
+				for _, typeOU := range typeOUs {
+					if ou == typeOU {
+						return nil
+					}
+				}
+			}
+			return fmt.Errorf("This identity is not of type %s", mspRole.Role)
 		default:
 			return fmt.Errorf("Invalid MSP role type %d", int32(mspRole.Role))
 		}
@@ -428,3 +494,12 @@ func (msp *bccspmsp) SatisfiesPrincipal(id Identity, principal *common.MSPPrinci
 		return fmt.Errorf("Invalid principal type %d", int32(principal.PrincipalClassification))
 	}
 }
+
+// certs returns the certificates of ids
+func certs(ids []Identity) []*x509.Certificate {
+	certs := make([]*x509.Certificate, len(ids))
+	for i, id := range ids {
+		certs[i] = id.(*identity).cert
+	}
+	return certs
+}
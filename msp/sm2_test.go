@@ -0,0 +1,100 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package msp
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp/gm"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSM2Cert(t *testing.T, name string, isCA bool, parent *x509.Certificate, parentKey *gm.PrivateKey) ([]byte, *x509.Certificate, *gm.PrivateKey) {
+	priv, err := gm.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent, parentKey = template, priv
+	}
+	der, err := gm.CreateCertificate(template, parent, &priv.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := gm.ParseCertificate(der)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), cert, priv
+}
+
+func TestMSPWithSM2Identities(t *testing.T) {
+	caPEM, ca, caKey := newSM2Cert(t, "ca", true, nil, nil)
+	memberPEM, _, memberKey := newSM2Cert(t, "member", false, ca, caKey)
+	otherCAPEM, otherCA, otherCAKey := newSM2Cert(t, "ca", true, nil, nil)
+	outsiderPEM, _, _ := newSM2Cert(t, "member", false, otherCA, otherCAKey)
+
+	fmspconf := &msp.FabricMSPConfig{RootCerts: [][]byte{caPEM}, Name: "SM2MSP"}
+	fmpsjs, _ := proto.Marshal(fmspconf)
+	thisMSP, err := NewBccspMsp()
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.Setup(&msp.MSPConfig{Config: fmpsjs, Type: int32(FABRIC)}))
+
+	serialized, _ := proto.Marshal(&SerializedIdentity{Mspid: "SM2MSP", IdBytes: memberPEM})
+	id, err := thisMSP.DeserializeIdentity(serialized)
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.Validate(id), "An SM2 identity issued by the SM2 CA should be valid")
+
+	msg := []byte("message")
+	digest, err := gm.Digest(&memberKey.PublicKey, gm.DefaultSM2ID, msg)
+	assert.NoError(t, err)
+	sig, err := gm.Sign(rand.Reader, memberKey, digest)
+	assert.NoError(t, err)
+	assert.NoError(t, id.Verify(msg, sig))
+	assert.Error(t, id.Verify([]byte("another message"), sig))
+	errs, verified := VerifyBatch([]SignedMessage{{Identity: id, Msg: msg, Signature: sig}, {Identity: id, Msg: []byte("another message"), Signature: sig}})
+	verified.Release()
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+
+	serialized, _ = proto.Marshal(&SerializedIdentity{Mspid: "SM2MSP", IdBytes: outsiderPEM})
+	outsider, err := thisMSP.DeserializeIdentity(serialized)
+	assert.NoError(t, err)
+	assert.Error(t, thisMSP.Validate(outsider), "An SM2 identity issued by another CA of the same name should not be valid")
+
+	serialized, _ = proto.Marshal(&SerializedIdentity{Mspid: "SM2MSP", IdBytes: otherCAPEM})
+	caID, err := thisMSP.DeserializeIdentity(serialized)
+	assert.NoError(t, err)
+	assert.Error(t, thisMSP.Validate(caID), "A CA certificate should not be valid as an identity")
+
+	// the SM2 identities are not valid with an ECDSA MSP
+	serialized, _ = proto.Marshal(&SerializedIdentity{Mspid: "DEFAULT", IdBytes: memberPEM})
+	id, err = localMsp.DeserializeIdentity(serialized)
+	assert.NoError(t, err)
+	assert.Error(t, localMsp.Validate(id))
+}
@@ -0,0 +1,227 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admin
+
+import (
+	"fmt"
+
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+var logger = logging.MustGetLogger("orderer/common/admin")
+
+// SupportManager provides a way for the admin server to look up, join and remove chains
+type SupportManager interface {
+	// ChainIDs returns the IDs of the chains served, sorted
+	ChainIDs() []string
+
+	// SystemChainID returns the ID of the ordering system chain
+	SystemChainID() string
+
+	// GetChain retrieves the Support of a chain (and whether it exists)
+	GetChain(chainID string) (Support, bool)
+
+	// JoinChain starts serving the chain created by a genesis block
+	JoinChain(genesisBlock *cb.Block) error
+
+	// RemoveChain halts a chain and removes its ledger
+	RemoveChain(chainID string) error
+
+	// BroadcastRejections returns the broadcast messages rejected for a chain, or for any
+	// chain if chainID is empty, along with the counts of the rejections by reason
+	BroadcastRejections(chainID string) *ab.BroadcastRejectionsResponse
+}
+
+// Support provides the backing resources needed to report on a chain
+type Support interface {
+	// Reader returns the chain Reader for the chain
+	Reader() ordererledger.Reader
+
+	// SharedConfig returns the shared config manager for this chain
+	SharedConfig() configtxapi.OrdererConfig
+}
+
+type adminServer struct {
+	sm SupportManager
+}
+
+// NewServer creates an ab.AdminServer operating on the chains of sm.
+// The server must be served over TLS requiring client certificates, as only
+// the clients which authenticated with a certificate are authorized
+func NewServer(sm SupportManager) ab.AdminServer {
+	return &adminServer{sm: sm}
+}
+
+// ListChannels returns the channels served by the orderer
+func (as *adminServer) ListChannels(ctx context.Context, req *ab.ListChannelsRequest) (*ab.ListChannelsResponse, error) {
+	if err := authorize(ctx); err != nil {
+		return nil, err
+	}
+	resp := &ab.ListChannelsResponse{}
+	for _, chainID := range as.sm.ChainIDs() {
+		info, err := as.channelInfo(chainID)
+		if err != nil {
+			// the chain was removed since it was listed
+			continue
+		}
+		resp.Channels = append(resp.Channels, info)
+	}
+	return resp, nil
+}
+
+// ChannelStatus returns the status of a channel and of its consenter
+func (as *adminServer) ChannelStatus(ctx context.Context, req *ab.ChannelRequest) (*ab.ChannelInfo, error) {
+	if err := authorize(ctx); err != nil {
+		return nil, err
+	}
+	return as.channelInfo(req.ChainId)
+}
+
+// ConfigBlock returns the latest config block of a channel
+func (as *adminServer) ConfigBlock(ctx context.Context, req *ab.ChannelRequest) (*cb.Block, error) {
+	if err := authorize(ctx); err != nil {
+		return nil, err
+	}
+	info, err := as.channelInfo(req.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	support, ok := as.sm.GetChain(req.ChainId)
+	if !ok {
+		return nil, fmt.Errorf("Channel %s does not exist", req.ChainId)
+	}
+	block := ordererledger.GetBlock(support.Reader(), info.LastConfig)
+	if block == nil {
+		return nil, fmt.Errorf("Config block %d of channel %s is not retrievable", info.LastConfig, req.ChainId)
+	}
+	return block, nil
+}
+
+// GenesisBlock returns the genesis block of a channel, or of the system channel if
+// the request designates none, so that other orderers can bootstrap from it
+func (as *adminServer) GenesisBlock(ctx context.Context, req *ab.ChannelRequest) (*cb.Block, error) {
+	if err := authorize(ctx); err != nil {
+		return nil, err
+	}
+	chainID := req.ChainId
+	if chainID == "" {
+		chainID = as.sm.SystemChainID()
+	}
+	support, ok := as.sm.GetChain(chainID)
+	if !ok {
+		return nil, fmt.Errorf("Channel %s does not exist", chainID)
+	}
+	block := ordererledger.GetBlock(support.Reader(), 0)
+	if block == nil {
+		return nil, fmt.Errorf("Genesis block of channel %s is not retrievable", chainID)
+	}
+	return block, nil
+}
+
+// JoinChannel makes the orderer serve a channel from its genesis block
+func (as *adminServer) JoinChannel(ctx context.Context, req *ab.JoinChannelRequest) (*ab.ChannelInfo, error) {
+	if err := authorize(ctx); err != nil {
+		return nil, err
+	}
+	if err := as.sm.JoinChain(req.GenesisBlock); err != nil {
+		logger.Warningf("Failed joining channel: %s", err)
+		return nil, err
+	}
+	chainID, err := utils.GetChainIDFromBlock(req.GenesisBlock)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Joined channel %s", chainID)
+	return as.channelInfo(chainID)
+}
+
+// RemoveChannel stops serving a channel and removes its ledger
+func (as *adminServer) RemoveChannel(ctx context.Context, req *ab.ChannelRequest) (*ab.ChannelInfo, error) {
+	if err := authorize(ctx); err != nil {
+		return nil, err
+	}
+	info, err := as.channelInfo(req.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	if err = as.sm.RemoveChain(req.ChainId); err != nil {
+		logger.Warningf("Failed removing channel %s: %s", req.ChainId, err)
+		return nil, err
+	}
+	logger.Infof("Removed channel %s", req.ChainId)
+	return info, nil
+}
+
+// BroadcastRejections returns the latest broadcast messages rejected for a channel, or for any
+// channel if the request designates none, along with the counts of the rejections by reason
+func (as *adminServer) BroadcastRejections(ctx context.Context, req *ab.ChannelRequest) (*ab.BroadcastRejectionsResponse, error) {
+	if err := authorize(ctx); err != nil {
+		return nil, err
+	}
+	return as.sm.BroadcastRejections(req.ChainId), nil
+}
+
+func (as *adminServer) channelInfo(chainID string) (*ab.ChannelInfo, error) {
+	support, ok := as.sm.GetChain(chainID)
+	if !ok {
+		return nil, fmt.Errorf("Channel %s does not exist", chainID)
+	}
+	height := support.Reader().Height()
+	info := &ab.ChannelInfo{
+		ChainId:       chainID,
+		Height:        height,
+		ConsensusType: support.SharedConfig().ConsensusType(),
+		SystemChannel: chainID == as.sm.SystemChainID(),
+	}
+	// the genesis block is the only config block of a chain without last config metadata
+	if height > 1 {
+		lastBlock := ordererledger.GetBlock(support.Reader(), height-1)
+		if lastBlock == nil {
+			return nil, fmt.Errorf("Block %d of channel %s is not retrievable", height-1, chainID)
+		}
+		lastConfig, err := utils.GetLastConfigIndexFromBlock(lastBlock)
+		if err != nil {
+			return nil, fmt.Errorf("Error retrieving the last config of channel %s: %s", chainID, err)
+		}
+		info.LastConfig = lastConfig
+	}
+	return info, nil
+}
+
+// authorize only lets through the requests of clients which authenticated with
+// a TLS certificate, the server verifies it was issued by one of the admin CAs
+func authorize(ctx context.Context) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("Admin request is not authorized, the client is unknown")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		logger.Warningf("Rejected admin request of %s which did not authenticate with a TLS client certificate", p.Addr)
+		return fmt.Errorf("Admin request is not authorized, a TLS client certificate is required")
+	}
+	logger.Debugf("Admin request of %s authenticated as %s", p.Addr, tlsInfo.State.PeerCertificates[0].Subject.CommonName)
+	return nil
+}
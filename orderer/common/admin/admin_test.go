@@ -0,0 +1,227 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"sort"
+	"testing"
+
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	mockconfigtxorderer "github.com/hyperledger/fabric/common/mocks/configtx/handlers/orderer"
+	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+var genesisBlock = provisional.New(genesisconfig.Load()).GenesisBlock()
+
+const systemChainID = "systemChain"
+
+type mockSupportManager struct {
+	chains     map[string]*mockSupport
+	rejections []*ab.BroadcastRejection
+}
+
+func (mm *mockSupportManager) ChainIDs() []string {
+	var chainIDs []string
+	for chainID := range mm.chains {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+	return chainIDs
+}
+
+func (mm *mockSupportManager) SystemChainID() string {
+	return systemChainID
+}
+
+func (mm *mockSupportManager) GetChain(chainID string) (Support, bool) {
+	cs, ok := mm.chains[chainID]
+	return cs, ok
+}
+
+func (mm *mockSupportManager) JoinChain(genesisBlock *cb.Block) error {
+	chainID, err := utils.GetChainIDFromBlock(genesisBlock)
+	if err != nil {
+		return err
+	}
+	if _, ok := mm.chains[chainID]; ok {
+		return fmt.Errorf("Chain %s already exists", chainID)
+	}
+	mm.chains[chainID] = newMockSupport(genesisBlock)
+	return nil
+}
+
+func (mm *mockSupportManager) RemoveChain(chainID string) error {
+	if chainID == systemChainID {
+		return fmt.Errorf("Chain %s is the ordering system chain", chainID)
+	}
+	delete(mm.chains, chainID)
+	return nil
+}
+
+func (mm *mockSupportManager) BroadcastRejections(chainID string) *ab.BroadcastRejectionsResponse {
+	resp := &ab.BroadcastRejectionsResponse{}
+	for _, rejection := range mm.rejections {
+		if chainID == "" || rejection.ChainId == chainID {
+			resp.Rejections = append(resp.Rejections, rejection)
+		}
+	}
+	return resp
+}
+
+type mockSupport struct {
+	ledger       ordererledger.ReadWriter
+	sharedConfig *mockconfigtxorderer.SharedConfig
+}
+
+func newMockSupport(genesisBlock *cb.Block) *mockSupport {
+	rl, _ := ramledger.New(10).GetOrCreate("")
+	rl.Append(genesisBlock)
+	return &mockSupport{
+		ledger:       rl,
+		sharedConfig: &mockconfigtxorderer.SharedConfig{ConsensusTypeVal: "solo"},
+	}
+}
+
+func (mcs *mockSupport) Reader() ordererledger.Reader {
+	return mcs.ledger
+}
+
+func (mcs *mockSupport) SharedConfig() configtxapi.OrdererConfig {
+	return mcs.sharedConfig
+}
+
+// appendBlock appends a block to the ledger pointing to the lastConfig block
+func (mcs *mockSupport) appendBlock(lastConfig uint64) {
+	block := ordererledger.CreateNextBlock(mcs.ledger, []*cb.Envelope{&cb.Envelope{Payload: []byte("My Data")}})
+	block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&cb.Metadata{
+		Value: utils.MarshalOrPanic(&cb.LastConfig{Index: lastConfig}),
+	})
+	mcs.ledger.Append(block)
+}
+
+func authenticatedContext() context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{&x509.Certificate{}}}},
+	})
+}
+
+func TestAuthorization(t *testing.T) {
+	as := NewServer(&mockSupportManager{chains: map[string]*mockSupport{systemChainID: newMockSupport(genesisBlock)}})
+
+	_, err := as.ListChannels(context.Background(), &ab.ListChannelsRequest{})
+	assert.Error(t, err, "Requests of unknown clients should have been rejected")
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234},
+		AuthInfo: credentials.TLSInfo{},
+	})
+	_, err = as.ListChannels(ctx, &ab.ListChannelsRequest{})
+	assert.Error(t, err, "Requests of clients without a certificate should have been rejected")
+	_, err = as.RemoveChannel(ctx, &ab.ChannelRequest{ChainId: systemChainID})
+	assert.Error(t, err, "Requests of clients without a certificate should have been rejected")
+
+	_, err = as.ListChannels(authenticatedContext(), &ab.ListChannelsRequest{})
+	assert.NoError(t, err)
+}
+
+func TestChannelOperations(t *testing.T) {
+	system := newMockSupport(genesisBlock)
+	system.appendBlock(0)
+	system.appendBlock(1)
+	system.appendBlock(1)
+	mm := &mockSupportManager{chains: map[string]*mockSupport{systemChainID: system}}
+	as := NewServer(mm)
+	ctx := authenticatedContext()
+
+	info, err := as.ChannelStatus(ctx, &ab.ChannelRequest{ChainId: systemChainID})
+	assert.NoError(t, err)
+	assert.Equal(t, &ab.ChannelInfo{ChainId: systemChainID, Height: 4, ConsensusType: "solo", SystemChannel: true, LastConfig: 1}, info)
+
+	block, err := as.ConfigBlock(ctx, &ab.ChannelRequest{ChainId: systemChainID})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), block.Header.Number)
+
+	block, err = as.GenesisBlock(ctx, &ab.ChannelRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, genesisBlock, block, "The genesis block of the system channel should be returned when none is requested")
+
+	_, err = as.ChannelStatus(ctx, &ab.ChannelRequest{ChainId: "Fake"})
+	assert.Error(t, err)
+	_, err = as.ConfigBlock(ctx, &ab.ChannelRequest{ChainId: "Fake"})
+	assert.Error(t, err)
+	_, err = as.GenesisBlock(ctx, &ab.ChannelRequest{ChainId: "Fake"})
+	assert.Error(t, err)
+
+	info, err = as.JoinChannel(ctx, &ab.JoinChannelRequest{GenesisBlock: genesisBlock})
+	assert.NoError(t, err)
+	assert.Equal(t, &ab.ChannelInfo{ChainId: provisional.TestChainID, Height: 1, ConsensusType: "solo"}, info)
+	_, err = as.JoinChannel(ctx, &ab.JoinChannelRequest{GenesisBlock: genesisBlock})
+	assert.Error(t, err, "Joining a channel twice should have failed")
+
+	resp, err := as.ListChannels(ctx, &ab.ListChannelsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Channels, 2)
+
+	info, err = as.RemoveChannel(ctx, &ab.ChannelRequest{ChainId: provisional.TestChainID})
+	assert.NoError(t, err)
+	assert.Equal(t, provisional.TestChainID, info.ChainId)
+	_, err = as.RemoveChannel(ctx, &ab.ChannelRequest{ChainId: provisional.TestChainID})
+	assert.Error(t, err, "Removing a channel which does not exist should have failed")
+	_, err = as.RemoveChannel(ctx, &ab.ChannelRequest{ChainId: systemChainID})
+	assert.Error(t, err, "Removing the system channel should have failed")
+
+	resp, err = as.ListChannels(ctx, &ab.ListChannelsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Channels, 1)
+}
+
+func TestBroadcastRejections(t *testing.T) {
+	mm := &mockSupportManager{
+		chains: map[string]*mockSupport{systemChainID: newMockSupport(genesisBlock)},
+		rejections: []*ab.BroadcastRejection{
+			&ab.BroadcastRejection{ChainId: systemChainID, Reason: "MESSAGE_REJECTED"},
+			&ab.BroadcastRejection{ChainId: "Fake", Reason: "CHANNEL_NOT_FOUND"},
+		},
+	}
+	as := NewServer(mm)
+
+	_, err := as.BroadcastRejections(context.Background(), &ab.ChannelRequest{})
+	assert.Error(t, err, "Requests of unknown clients should have been rejected")
+
+	resp, err := as.BroadcastRejections(authenticatedContext(), &ab.ChannelRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Rejections, 2, "The rejections of every channel should be returned when none is requested")
+
+	resp, err = as.BroadcastRejections(authenticatedContext(), &ab.ChannelRequest{ChainId: "Fake"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*ab.BroadcastRejection{mm.rejections[1]}, resp.Rejections)
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package blockcutter
 
 import (
+	"github.com/golang/protobuf/proto"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
 
 	"github.com/op/go-logging"
 )
@@ -57,6 +59,8 @@ type receiver struct {
 	pendingBatch          []*cb.Envelope
 	pendingBatchSizeBytes uint32
 	pendingCommitters     []filter.Committer
+	// batchSize is the batch size the last message was ordered with
+	batchSize *ab.BatchSize
 }
 
 // NewReceiverImpl creates a Receiver implementation based on the given configtxorderer manager and filters
@@ -93,13 +97,14 @@ func (r *receiver) Ordered(msg *cb.Envelope) ([][]*cb.Envelope, [][]filter.Commi
 	}
 
 	messageSizeBytes := messageSizeBytes(msg)
+	batchSize := r.currentBatchSize()
 
-	if committer.Isolated() || messageSizeBytes > r.sharedConfigManager.BatchSize().PreferredMaxBytes {
+	if committer.Isolated() || messageSizeBytes > batchSize.PreferredMaxBytes {
 
 		if committer.Isolated() {
 			logger.Debugf("Found message which requested to be isolated, cutting into its own batch")
 		} else {
-			logger.Debugf("The current message, with %v bytes, is larger than the preferred batch size of %v bytes and will be isolated.", messageSizeBytes, r.sharedConfigManager.BatchSize().PreferredMaxBytes)
+			logger.Debugf("The current message, with %v bytes, is larger than the preferred batch size of %v bytes and will be isolated.", messageSizeBytes, batchSize.PreferredMaxBytes)
 		}
 
 		messageBatches := [][]*cb.Envelope{}
@@ -122,7 +127,7 @@ func (r *receiver) Ordered(msg *cb.Envelope) ([][]*cb.Envelope, [][]filter.Commi
 	messageBatches := [][]*cb.Envelope{}
 	committerBatches := [][]filter.Committer{}
 
-	messageWillOverflowBatchSizeBytes := r.pendingBatchSizeBytes+messageSizeBytes > r.sharedConfigManager.BatchSize().PreferredMaxBytes
+	messageWillOverflowBatchSizeBytes := r.pendingBatchSizeBytes+messageSizeBytes > batchSize.PreferredMaxBytes
 
 	if messageWillOverflowBatchSizeBytes {
 		logger.Debugf("The current message, with %v bytes, will overflow the pending batch of %v bytes.", messageSizeBytes, r.pendingBatchSizeBytes)
@@ -137,7 +142,7 @@ func (r *receiver) Ordered(msg *cb.Envelope) ([][]*cb.Envelope, [][]filter.Commi
 	r.pendingBatchSizeBytes += messageSizeBytes
 	r.pendingCommitters = append(r.pendingCommitters, committer)
 
-	if uint32(len(r.pendingBatch)) >= r.sharedConfigManager.BatchSize().MaxMessageCount {
+	if uint32(len(r.pendingBatch)) >= batchSize.MaxMessageCount {
 		logger.Debugf("Batch size met, cutting batch")
 		messageBatch, committerBatch := r.Cut()
 		messageBatches = append(messageBatches, messageBatch)
@@ -153,6 +158,18 @@ func (r *receiver) Ordered(msg *cb.Envelope) ([][]*cb.Envelope, [][]filter.Commi
 
 }
 
+// currentBatchSize returns the batch size of the current config, which config
+// updates may change between two messages, logging it whenever it changes
+func (r *receiver) currentBatchSize() *ab.BatchSize {
+	batchSize := r.sharedConfigManager.BatchSize()
+	if !proto.Equal(batchSize, r.batchSize) {
+		logger.Infof("Cutting batches of at most %d messages, preferably of at most %d bytes",
+			batchSize.MaxMessageCount, batchSize.PreferredMaxBytes)
+		r.batchSize = proto.Clone(batchSize).(*ab.BatchSize)
+	}
+	return batchSize
+}
+
 // Cut returns the current batch and starts a new one
 func (r *receiver) Cut() ([]*cb.Envelope, []filter.Committer) {
 	batch := r.pendingBatch
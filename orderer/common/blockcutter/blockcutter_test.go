@@ -308,6 +308,28 @@ func TestBatchSizePreferredMaxBytesOverflow(t *testing.T) {
 
 }
 
+func TestBatchSizeUpdate(t *testing.T) {
+	filters := getFilters()
+	sharedConfig := &mockconfigtxorderer.SharedConfig{BatchSizeVal: &ab.BatchSize{MaxMessageCount: 5, AbsoluteMaxBytes: 100, PreferredMaxBytes: 100}}
+	r := NewReceiverImpl(sharedConfig, filters)
+
+	for i := 0; i < 2; i++ {
+		if batches, _, ok := r.Ordered(goodTx); batches != nil || !ok {
+			t.Fatalf("Should have enqueued message into batch")
+		}
+	}
+
+	// a config update lowering the message count applies to the pending batch
+	sharedConfig.BatchSizeVal = &ab.BatchSize{MaxMessageCount: 3, AbsoluteMaxBytes: 100, PreferredMaxBytes: 100}
+	batches, _, ok := r.Ordered(goodTx)
+	if !ok {
+		t.Fatalf("Should have enqueued the third message into batch")
+	}
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("Should have created a batch of the three messages with the updated batch size, got %v", batches)
+	}
+}
+
 func TestBatchSizePreferredMaxBytesOverflowNoPending(t *testing.T) {
 	filters := getFilters()
 
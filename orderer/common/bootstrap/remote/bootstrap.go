@@ -0,0 +1,162 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/bootstrap"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var logger = logging.MustGetLogger("orderer/common/bootstrap/remote")
+
+// maxBlockSize bounds the size of a genesis block served over HTTP
+const maxBlockSize = 100 * 1024 * 1024
+
+// Config sets how a remote bootstrapper fetches the genesis block
+type Config struct {
+	// Hash is the pinned hash of the header of the genesis block, only the
+	// genesis block matching it is accepted. It is required, as neither the
+	// remote source nor the connection to it are trusted
+	Hash []byte
+
+	// TLS secures the connection to the remote source, nil connects insecurely
+	TLS *tls.Config
+
+	// Timeout bounds every attempt at fetching the genesis block
+	Timeout time.Duration
+
+	// RetryPeriod is the time between failed attempts
+	RetryPeriod time.Duration
+
+	// RetryStop is the time after which the bootstrapper gives up
+	RetryStop time.Duration
+}
+
+type remoteBootstrapper struct {
+	source string
+	conf   Config
+	fetch  func() (*cb.Block, error)
+}
+
+// NewURL returns a bootstrap helper fetching the genesis block, marshaled, from an HTTP(S) URL
+func NewURL(url string, conf Config) bootstrap.Helper {
+	rb := &remoteBootstrapper{source: url, conf: conf}
+	rb.fetch = func() (*cb.Block, error) {
+		client := &http.Client{Timeout: conf.Timeout, Transport: &http.Transport{TLSClientConfig: conf.TLS}}
+		resp, err := client.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Unexpected HTTP status %s", resp.Status)
+		}
+		raw, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBlockSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) > maxBlockSize {
+			return nil, fmt.Errorf("Genesis block exceeds %d bytes", maxBlockSize)
+		}
+		block := &cb.Block{}
+		if err = proto.Unmarshal(raw, block); err != nil {
+			return nil, fmt.Errorf("Error unmarshalling genesis block: %s", err)
+		}
+		return block, nil
+	}
+	return rb
+}
+
+// NewAdmin returns a bootstrap helper fetching the genesis block of chainID from the
+// admin service of another orderer at address, chainID may be empty for its system chain.
+// As the admin service requires clients to authenticate, conf.TLS should carry a client
+// certificate issued by one of its admin CAs
+func NewAdmin(address string, chainID string, conf Config) bootstrap.Helper {
+	rb := &remoteBootstrapper{source: address, conf: conf}
+	rb.fetch = func() (*cb.Block, error) {
+		dialOpt := grpc.WithInsecure()
+		if conf.TLS != nil {
+			dialOpt = grpc.WithTransportCredentials(credentials.NewTLS(conf.TLS))
+		}
+		conn, err := grpc.Dial(address, dialOpt, grpc.WithBlock(), grpc.WithTimeout(conf.Timeout))
+		if err != nil {
+			return nil, fmt.Errorf("Error connecting: %s", err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), conf.Timeout)
+		defer cancel()
+		return ab.NewAdminClient(conn).GenesisBlock(ctx, &ab.ChannelRequest{ChainId: chainID})
+	}
+	return rb
+}
+
+// verify checks that block is a genesis block and matches the pinned hash
+func (rb *remoteBootstrapper) verify(block *cb.Block) error {
+	if block.Header == nil || block.Data == nil {
+		return fmt.Errorf("Genesis block is missing its header or data")
+	}
+	if block.Header.Number != 0 {
+		return fmt.Errorf("Block %d is not a genesis block", block.Header.Number)
+	}
+	if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return fmt.Errorf("Genesis block data does not match the data hash of its header")
+	}
+	if !bytes.Equal(block.Header.Hash(), rb.conf.Hash) {
+		return fmt.Errorf("Genesis block hash %x does not match the pinned hash %x", block.Header.Hash(), rb.conf.Hash)
+	}
+	return nil
+}
+
+// GenesisBlock returns the genesis block fetched from the remote source, retrying until
+// RetryStop elapses. It panics if no hash is pinned, or if the source serves a block
+// which fails verification, as retrying would not make an unexpected block trustworthy
+func (rb *remoteBootstrapper) GenesisBlock() *cb.Block {
+	if len(rb.conf.Hash) == 0 {
+		panic(fmt.Errorf("Unable to bootstrap orderer. No genesis block hash is pinned for %s", rb.source))
+	}
+	start := time.Now()
+	for {
+		block, err := rb.fetch()
+		if err == nil {
+			if err = rb.verify(block); err != nil {
+				panic(fmt.Errorf("Unable to bootstrap orderer. Genesis block fetched from %s is rejected: %s", rb.source, err))
+			}
+			logger.Infof("Fetched genesis block %x from %s", block.Header.Hash(), rb.source)
+			return block
+		}
+		if time.Since(start) >= rb.conf.RetryStop {
+			panic(fmt.Errorf("Unable to bootstrap orderer. Error fetching genesis block from %s: %s", rb.source, err))
+		}
+		logger.Warningf("Error fetching genesis block from %s, retrying in %s: %s", rb.source, rb.conf.RetryPeriod, err)
+		time.Sleep(rb.conf.RetryPeriod)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/genesis"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var testConfig = Config{Timeout: time.Second, RetryPeriod: 10 * time.Millisecond, RetryStop: time.Second}
+
+func genesisBlock(t *testing.T) *cb.Block {
+	block, err := genesis.NewFactoryImpl(configtx.NewSimpleTemplate()).Block("foo")
+	assert.NoError(t, err)
+	return block
+}
+
+func TestURL(t *testing.T) {
+	block := genesisBlock(t)
+	failures := 2
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(utils.MarshalOrPanic(block))
+	}))
+	defer srv.Close()
+
+	conf := testConfig
+	conf.Hash = block.Header.Hash()
+	fetched := NewURL(srv.URL, conf).GenesisBlock()
+	assert.Equal(t, block, fetched, "The genesis block should have been fetched once the service recovered")
+
+	conf.Hash = []byte("not the hash")
+	assert.Panics(t, func() { NewURL(srv.URL, conf).GenesisBlock() }, "A genesis block not matching the pinned hash should be rejected")
+}
+
+func TestURLUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	conf := testConfig
+	conf.Hash = genesisBlock(t).Header.Hash()
+	conf.RetryStop = 50 * time.Millisecond
+	assert.Panics(t, func() { NewURL(srv.URL, conf).GenesisBlock() })
+}
+
+func TestNoPinnedHash(t *testing.T) {
+	block := genesisBlock(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(utils.MarshalOrPanic(block))
+	}))
+	defer srv.Close()
+
+	assert.Panics(t, func() { NewURL(srv.URL, testConfig).GenesisBlock() }, "A genesis block should not be fetched without a pinned hash")
+}
+
+func TestVerify(t *testing.T) {
+	block := genesisBlock(t)
+	rb := &remoteBootstrapper{conf: Config{Hash: block.Header.Hash()}}
+	assert.NoError(t, rb.verify(block))
+
+	block.Data.Data = append(block.Data.Data, []byte("forged"))
+	assert.Error(t, rb.verify(block), "A block whose data does not match its header should be rejected")
+	assert.Error(t, rb.verify(cb.NewBlock(1, nil)), "A block other than block 0 should be rejected")
+	assert.Error(t, rb.verify(&cb.Block{}))
+}
+
+type mockAdminServer struct {
+	ab.AdminServer
+	block     *cb.Block
+	requested chan string
+}
+
+func (mas *mockAdminServer) GenesisBlock(ctx context.Context, req *ab.ChannelRequest) (*cb.Block, error) {
+	mas.requested <- req.ChainId
+	return mas.block, nil
+}
+
+func TestAdmin(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := grpc.NewServer()
+	mas := &mockAdminServer{block: genesisBlock(t), requested: make(chan string, 1)}
+	ab.RegisterAdminServer(srv, mas)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conf := testConfig
+	conf.Hash = mas.block.Header.Hash()
+	fetched := NewAdmin(lis.Addr().String(), "", conf).GenesisBlock()
+	assert.Equal(t, mas.block, fetched)
+	assert.Equal(t, "", <-mas.requested, "The genesis block of the system chain should have been requested")
+}
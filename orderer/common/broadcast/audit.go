@@ -0,0 +1,212 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcast
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/op/go-logging"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+)
+
+// auditLogger logs the rejected broadcast envelopes, its level may be set apart
+// from the one of the broadcast handler
+var auditLogger = logging.MustGetLogger("orderer/common/broadcast/audit")
+
+// auditLogRate is the number of rejections logged per second at most, the
+// others being only counted and retained
+const auditLogRate = 10
+
+// rejectionKey identifies the rejections counted together
+type rejectionKey struct {
+	chainID string
+	reason  string
+}
+
+// Audit records the broadcast envelopes rejected by the orderer, with the
+// reason, channel, claimed client identity and size of each, so that the
+// operators can tell attack traffic from misconfigured clients. Every rejection
+// is counted by channel and reason, the rejections for the channels which don't
+// exist being counted together, the first auditLogRate rejections of every
+// second are logged, and the latest ones are retained to be queried through the
+// admin service
+type Audit struct {
+	lock   sync.Mutex
+	counts map[rejectionKey]uint64
+
+	// logWindow is the second the logged rejections are counted for, and
+	// suppressed the number of rejections not logged since the last one was
+	logWindow  time.Time
+	logged     int
+	suppressed uint64
+
+	// rejections is a ring buffer of the latest rejections, next is the index
+	// the next one is written at and full whether it wrapped around
+	rejections []*ab.BroadcastRejection
+	next       int
+	full       bool
+}
+
+// NewAudit creates an Audit retaining the latest size rejections, none if
+// size is 0
+func NewAudit(size int) *Audit {
+	return &Audit{
+		counts:     make(map[rejectionKey]uint64),
+		rejections: make([]*ab.BroadcastRejection, size),
+	}
+}
+
+// Record logs, counts and retains rejection. As its channel is read from the
+// envelope, it is only counted apart if channelExists, and with the rejections
+// whose channel is unknown otherwise, so that the counts stay bounded
+func (a *Audit) Record(rejection *ab.BroadcastRejection, channelExists bool) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.log(rejection)
+	key := rejectionKey{reason: rejection.Reason}
+	if channelExists {
+		key.chainID = rejection.ChainId
+	}
+	a.counts[key]++
+	if len(a.rejections) == 0 {
+		return
+	}
+	a.rejections[a.next] = rejection
+	a.next = (a.next + 1) % len(a.rejections)
+	if a.next == 0 {
+		a.full = true
+	}
+}
+
+// log logs rejection unless auditLogRate rejections were already logged in the
+// current second. It must be called with the lock held
+func (a *Audit) log(rejection *ab.BroadcastRejection) {
+	if now := time.Now(); now.Sub(a.logWindow) >= time.Second {
+		a.logWindow, a.logged = now, 0
+	}
+	if a.logged >= auditLogRate {
+		a.suppressed++
+		return
+	}
+	a.logged++
+	if a.suppressed > 0 {
+		auditLogger.Infof("%d rejected broadcast envelopes were not logged", a.suppressed)
+		a.suppressed = 0
+	}
+	auditLogger.Infof("Rejected broadcast envelope: channel=%q status=%s reason=%s claimed_msp=%q claimed_subject=%q address=%s size=%d message=%q",
+		rejection.ChainId, rejection.Status, rejection.Reason, rejection.ClaimedMspId, rejection.ClaimedSubject, rejection.Address, rejection.Size, rejection.Message)
+}
+
+// Rejections returns the rejections retained and the counts of the rejections
+// by reason, of chainID or of every channel if chainID is empty. The counts of
+// the rejections for the channels which don't exist are only returned for
+// every channel
+func (a *Audit) Rejections(chainID string) *ab.BroadcastRejectionsResponse {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	resp := &ab.BroadcastRejectionsResponse{}
+	retained := a.rejections[:a.next]
+	if a.full {
+		retained = append(append([]*ab.BroadcastRejection{}, a.rejections[a.next:]...), retained...)
+	}
+	for _, rejection := range retained {
+		if chainID == "" || rejection.ChainId == chainID {
+			resp.Rejections = append(resp.Rejections, rejection)
+		}
+	}
+
+	counts := make(map[string]uint64)
+	for key, count := range a.counts {
+		if chainID == "" || key.chainID == chainID {
+			counts[key.reason] += count
+		}
+	}
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		resp.Counts = append(resp.Counts, &ab.BroadcastRejectionCount{Reason: reason, Count: counts[reason]})
+	}
+	return resp
+}
+
+// newRejection returns the record of the rejection of msg by resp, msg being
+// sent by the client of ctx. If msg is nil or has no readable header, the
+// channel and size are those of manifest, when the envelope was sent in chunks.
+// The creator is the one the envelope claims, its signature not being verified
+func newRejection(ctx context.Context, msg *cb.Envelope, manifest *ab.ChunkManifest, resp *ab.BroadcastResponse) *ab.BroadcastRejection {
+	rejection := &ab.BroadcastRejection{Timestamp: util.CreateUtcTimestamp(), Status: resp.Status}
+	if resp.ErrorInfo != nil {
+		rejection.Reason = resp.ErrorInfo.Code
+		rejection.Message = resp.ErrorInfo.Message
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		rejection.Address = p.Addr.String()
+	}
+	if manifest != nil {
+		rejection.ChainId = manifest.ChannelId
+		rejection.Size = manifest.Size
+	}
+	if msg == nil {
+		return rejection
+	}
+
+	rejection.Size = uint64(len(msg.Payload) + len(msg.Signature))
+	payload := &cb.Payload{}
+	if err := proto.Unmarshal(msg.Payload, payload); err != nil || payload.Header == nil {
+		return rejection
+	}
+	if payload.Header.ChannelHeader != nil && payload.Header.ChannelHeader.ChannelId != "" {
+		rejection.ChainId = payload.Header.ChannelHeader.ChannelId
+	}
+	if payload.Header.SignatureHeader != nil {
+		rejection.ClaimedMspId, rejection.ClaimedSubject = creatorOf(payload.Header.SignatureHeader.Creator)
+	}
+	return rejection
+}
+
+// creatorOf returns the MSP ID and the common name of the certificate of the
+// serialized identity creator, as far as they can be read
+func creatorOf(creator []byte) (string, string) {
+	identity := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(creator, identity); err != nil {
+		return "", ""
+	}
+	block, _ := pem.Decode(identity.IdBytes)
+	if block == nil {
+		return identity.Mspid, ""
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return identity.Mspid, ""
+	}
+	return identity.Mspid, cert.Subject.CommonName
+}
@@ -0,0 +1,224 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcast
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	cerrors "github.com/hyperledger/fabric/common/errors"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+)
+
+func TestAuditRetention(t *testing.T) {
+	a := NewAudit(3)
+	for i, chainID := range []string{"foo", "bar", "foo", "foo", "bar"} {
+		a.Record(&ab.BroadcastRejection{ChainId: chainID, Reason: cerrors.MessageRejected, Size: uint64(i)}, true)
+	}
+	a.Record(&ab.BroadcastRejection{ChainId: "foo", Reason: cerrors.RequestTooLarge, Size: 5}, true)
+
+	resp := a.Rejections("")
+	var sizes []uint64
+	for _, rejection := range resp.Rejections {
+		sizes = append(sizes, rejection.Size)
+	}
+	assert.Equal(t, []uint64{3, 4, 5}, sizes, "The latest rejections should have been retained, oldest first")
+	assert.Equal(t, []*ab.BroadcastRejectionCount{
+		&ab.BroadcastRejectionCount{Reason: cerrors.MessageRejected, Count: 5},
+		&ab.BroadcastRejectionCount{Reason: cerrors.RequestTooLarge, Count: 1},
+	}, resp.Counts)
+
+	resp = a.Rejections("bar")
+	assert.Len(t, resp.Rejections, 1)
+	assert.Equal(t, []*ab.BroadcastRejectionCount{&ab.BroadcastRejectionCount{Reason: cerrors.MessageRejected, Count: 2}}, resp.Counts)
+}
+
+func TestAuditWithoutRetention(t *testing.T) {
+	a := NewAudit(0)
+	a.Record(&ab.BroadcastRejection{ChainId: "foo", Reason: cerrors.MessageRejected}, true)
+	resp := a.Rejections("")
+	assert.Empty(t, resp.Rejections)
+	assert.Equal(t, []*ab.BroadcastRejectionCount{&ab.BroadcastRejectionCount{Reason: cerrors.MessageRejected, Count: 1}}, resp.Counts, "The rejections should still be counted")
+}
+
+func TestAuditUnknownChannels(t *testing.T) {
+	a := NewAudit(10)
+	a.Record(&ab.BroadcastRejection{ChainId: "foo", Reason: cerrors.MessageRejected}, true)
+	for _, chainID := range []string{"fake1", "fake2", "fake3"} {
+		a.Record(&ab.BroadcastRejection{ChainId: chainID, Reason: cerrors.ChannelNotFound}, false)
+	}
+
+	assert.Len(t, a.counts, 2, "The rejections for the channels which don't exist should be counted together")
+	assert.Equal(t, []*ab.BroadcastRejectionCount{
+		&ab.BroadcastRejectionCount{Reason: cerrors.ChannelNotFound, Count: 3},
+		&ab.BroadcastRejectionCount{Reason: cerrors.MessageRejected, Count: 1},
+	}, a.Rejections("").Counts)
+	resp := a.Rejections("fake1")
+	assert.Len(t, resp.Rejections, 1, "The rejection should still be retained with its channel")
+	assert.Empty(t, resp.Counts)
+}
+
+func TestAuditLogRate(t *testing.T) {
+	a := NewAudit(0)
+	for i := 0; i < auditLogRate+5; i++ {
+		a.Record(&ab.BroadcastRejection{ChainId: "foo", Reason: cerrors.MessageRejected}, true)
+	}
+	assert.Equal(t, auditLogRate, a.logged)
+	assert.Equal(t, uint64(5), a.suppressed)
+	assert.Equal(t, uint64(auditLogRate+5), a.Rejections("").Counts[0].Count, "Every rejection should still be counted")
+
+	// The next second, the rejections are logged again
+	a.logWindow = a.logWindow.Add(-time.Second)
+	a.Record(&ab.BroadcastRejection{ChainId: "foo", Reason: cerrors.MessageRejected}, true)
+	assert.Equal(t, 1, a.logged)
+	assert.Equal(t, uint64(0), a.suppressed)
+}
+
+func makeCreator(t *testing.T, mspID, commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Error creating certificate: %s", err)
+	}
+	return utils.MarshalOrPanic(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+}
+
+func TestNewRejection(t *testing.T) {
+	msg := &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader:   &cb.ChannelHeader{ChannelId: systemChain},
+				SignatureHeader: &cb.SignatureHeader{Creator: makeCreator(t, "SampleOrg", "client1")},
+			},
+		}),
+		Signature: []byte("signature"),
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234}})
+	resp := response(cb.Status_BAD_REQUEST, cerrors.Validation(cerrors.MessageRejected, "Rejected"))
+
+	rejection := newRejection(ctx, msg, nil, resp)
+	assert.NotNil(t, rejection.Timestamp)
+	assert.Equal(t, systemChain, rejection.ChainId)
+	assert.Equal(t, cb.Status_BAD_REQUEST, rejection.Status)
+	assert.Equal(t, cerrors.MessageRejected, rejection.Reason)
+	assert.Equal(t, "Rejected", rejection.Message)
+	assert.Equal(t, "SampleOrg", rejection.ClaimedMspId)
+	assert.Equal(t, "client1", rejection.ClaimedSubject)
+	assert.Equal(t, "127.0.0.1:1234", rejection.Address)
+	assert.Equal(t, uint64(len(msg.Payload)+len(msg.Signature)), rejection.Size)
+
+	// An envelope rejected before it was reassembled is described by its manifest
+	rejection = newRejection(context.Background(), nil, &ab.ChunkManifest{ChannelId: "foo", Size: 100}, resp)
+	assert.Equal(t, "foo", rejection.ChainId)
+	assert.Equal(t, uint64(100), rejection.Size)
+	assert.Empty(t, rejection.ClaimedMspId)
+	assert.Empty(t, rejection.Address)
+
+	rejection = newRejection(context.Background(), &cb.Envelope{Payload: []byte("garbage")}, nil, resp)
+	assert.Empty(t, rejection.ChainId, "A malformed envelope has no channel")
+	assert.Equal(t, uint64(7), rejection.Size)
+}
+
+func TestHandlerRecordsRejections(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	mm.audit = NewAudit(10)
+	bh := NewHandlerImpl(mm)
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+	assert.Empty(t, mm.audit.Rejections("").Rejections, "An accepted message should not have been recorded")
+
+	m.recvChan <- makeMessage("Fake", []byte("Some bytes"))
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_NOT_FOUND, reply.Status)
+	resp := mm.audit.Rejections("Fake")
+	if assert.Len(t, resp.Rejections, 1) {
+		assert.Equal(t, cerrors.ChannelNotFound, resp.Rejections[0].Reason)
+		assert.Equal(t, cb.Status_NOT_FOUND, resp.Rejections[0].Status)
+	}
+}
+
+func TestBatchHandlerRecordsRejections(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	mm.audit = NewAudit(10)
+	bh := NewHandlerImpl(mm)
+	m := newMockBB()
+	defer close(m.recvChan)
+	go bh.HandleBatch(m)
+
+	m.recvChan <- &ab.BroadcastBatch{Envelopes: []*cb.Envelope{
+		makeMessage(systemChain, []byte("Some bytes")),
+		makeMessage("Fake", []byte("Some bytes")),
+		&cb.Envelope{Payload: []byte("garbage")},
+	}}
+	<-m.sendChan
+	resp := mm.audit.Rejections("")
+	assert.Len(t, resp.Rejections, 2)
+	assert.Equal(t, []*ab.BroadcastRejectionCount{
+		&ab.BroadcastRejectionCount{Reason: cerrors.ChannelNotFound, Count: 1},
+		&ab.BroadcastRejectionCount{Reason: cerrors.MalformedEnvelope, Count: 1},
+	}, resp.Counts)
+}
+
+func TestChunkedHandlerRecordsRejections(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	mm.audit = NewAudit(10)
+	mm.chunkLimits = ChunkLimits{MaxBytes: 10}
+	bh := NewHandlerImpl(mm)
+	m := newMockC()
+	defer close(m.recvChan)
+	go bh.HandleChunked(m)
+
+	m.recvChan <- chunksOf(t, makeMessage(systemChain, []byte("Too many bytes")), systemChain, 100)[0]
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_REQUEST_ENTITY_TOO_LARGE, reply.Status)
+	resp := mm.audit.Rejections(systemChain)
+	if assert.Len(t, resp.Rejections, 1) {
+		assert.Equal(t, cerrors.RequestTooLarge, resp.Rejections[0].Reason)
+		assert.True(t, resp.Rejections[0].Size > 10, "The size should be the one of the manifest")
+	}
+}
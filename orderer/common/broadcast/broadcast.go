@@ -17,14 +17,19 @@ limitations under the License.
 package broadcast
 
 import (
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	cerrors "github.com/hyperledger/fabric/common/errors"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/op/go-logging"
 
 	"io"
+	"sync"
+	"time"
 
 	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
 )
 
 var logger = logging.MustGetLogger("orderer/common/broadcast")
@@ -33,6 +38,16 @@ var logger = logging.MustGetLogger("orderer/common/broadcast")
 type Handler interface {
 	// Handle starts a service thread for a given gRPC connection and services the broadcast connection
 	Handle(srv ab.AtomicBroadcast_BroadcastServer) error
+
+	// HandleBatch starts a service thread for a given gRPC connection and services the batch broadcast connection
+	HandleBatch(srv ab.AtomicBroadcast_BroadcastBatchServer) error
+
+	// HandleChunked starts a service thread for a given gRPC connection and services the chunked broadcast connection
+	HandleChunked(srv ab.AtomicBroadcast_BroadcastChunkedServer) error
+
+	// Drain rejects the messages received from now on with SERVICE_UNAVAILABLE and waits up to
+	// timeout for the messages being processed to be enqueued, it returns whether they all were
+	Drain(timeout time.Duration) bool
 }
 
 // SupportManager provides a way for the Handler to look up the Support for a chain
@@ -44,6 +59,13 @@ type SupportManager interface {
 	// The status returned is whether the proposal is accepted for consideration, only after consensus
 	// occurs will the proposal be committed or rejected
 	ProposeChain(env *cb.Envelope) cb.Status
+
+	// MaxReassembledBytes returns the size limit of the envelopes for a given ChannelId which
+	// are reassembled from their chunks
+	MaxReassembledBytes(chainID string) uint64
+
+	// Audit returns the Audit recording the rejected envelopes, nil not to record them
+	Audit() *Audit
 }
 
 // Support provides the backing resources needed to support broadcast on a chain
@@ -53,10 +75,45 @@ type Support interface {
 
 	// Filters returns the set of broadcast filters for this chain
 	Filters() *filter.RuleSet
+
+	// SharedConfig returns the shared config manager for this chain
+	SharedConfig() configtxapi.OrdererConfig
+
+	// StorageExceeded returns true when the ledger of this chain exceeds its storage quota
+	// and new messages must be rejected
+	StorageExceeded() bool
 }
 
 type handlerImpl struct {
 	sm SupportManager
+
+	// validations feeds the validator goroutines, it is nil if messages are validated
+	// synchronously by the goroutine servicing their connection
+	validations chan *validation
+	queueSize   int
+
+	// lock protects draining, which is set once the handler is draining, and inflight
+	// counts the messages (or batches) being processed
+	lock     sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+}
+
+// validation tracks a message through its validation
+type validation struct {
+	msg     *cb.Envelope
+	chainID string
+
+	// support is the support of the chain of msg, nil if msg proposes a new chain
+	support Support
+
+	// status is the status of the ordering checks of msg, and err the error rejecting msg if they
+	// failed
+	status cb.Status
+	err    error
+
+	// result receives the result of the filtering of msg by the validators
+	result chan error
 }
 
 // NewHandlerImpl constructs a new implementation of the Handler interface
@@ -66,8 +123,29 @@ func NewHandlerImpl(sm SupportManager) Handler {
 	}
 }
 
+// NewHandlerImplWithValidators constructs a new implementation of the Handler interface which
+// offloads the signature and policy validation of the messages to a pool of validator goroutines
+// shared by all connections. The ordering checks (size, channel existence) are still performed
+// upfront and fail fast. Up to queueSize messages of a connection may be pending validation,
+// the replies and the enqueuing of the messages keep the order in which they were received
+func NewHandlerImplWithValidators(sm SupportManager, validators, queueSize int) Handler {
+	bh := &handlerImpl{
+		sm:          sm,
+		validations: make(chan *validation, validators),
+		queueSize:   queueSize,
+	}
+	for i := 0; i < validators; i++ {
+		go bh.validate()
+	}
+	return bh
+}
+
 // Handle starts a service thread for a given gRPC connection and services the broadcast connection
 func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
+	if bh.validations != nil {
+		return bh.handleOffloaded(srv)
+	}
+
 	for {
 		msg, err := srv.Recv()
 		if err == io.EOF {
@@ -77,53 +155,308 @@ func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
 			return err
 		}
 
-		payload := &cb.Payload{}
-		err = proto.Unmarshal(msg.Payload, payload)
-		if payload.Header == nil || payload.Header.ChannelHeader == nil || payload.Header.ChannelHeader.ChannelId == "" {
-			logger.Debugf("Received malformed message, dropping connection")
-			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST})
+		if !bh.enter() {
+			return srv.Send(bh.record(srv.Context(), msg, nil, drainingResponse()))
+		}
+		resp, keepOpen := bh.complete(bh.submit(msg, nil))
+		err = srv.Send(bh.record(srv.Context(), msg, nil, resp))
+		bh.inflight.Done()
+		if err != nil || !keepOpen {
+			return err
 		}
+	}
+}
 
-		support, ok := bh.sm.GetChain(payload.Header.ChannelHeader.ChannelId)
-		if !ok {
-			// Chain not found, maybe create one?
-			if payload.Header.ChannelHeader.Type != int32(cb.HeaderType_CONFIG) {
-				return srv.Send(&ab.BroadcastResponse{Status: cb.Status_NOT_FOUND})
+// handleOffloaded services the broadcast connection while the validators validate its messages,
+// the messages keep being received while the earlier ones are pending validation
+func (bh *handlerImpl) handleOffloaded(srv ab.AtomicBroadcast_BroadcastServer) error {
+	pending := make(chan *validation, bh.queueSize)
+	recvErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	// the messages left pending when the connection is dropped are no longer in flight,
+	// unlike the nil validation telling that the handler is draining
+	defer func() {
+		go func() {
+			for v := range pending {
+				if v != nil {
+					bh.inflight.Done()
+				}
 			}
+		}()
+	}()
 
-			logger.Debugf("Proposing new chain")
-			err = srv.Send(&ab.BroadcastResponse{Status: bh.sm.ProposeChain(msg)})
+	go func() {
+		defer close(pending)
+		for {
+			msg, err := srv.Recv()
 			if err != nil {
-				return err
+				recvErr <- err
+				return
+			}
+			if !bh.enter() {
+				// a nil validation tells the servicing loop that the handler is draining
+				select {
+				case pending <- nil:
+				case <-done:
+				}
+				return
+			}
+			v := bh.submit(msg, done)
+			if v == nil {
+				bh.inflight.Done()
+				return
+			}
+			select {
+			case pending <- v:
+			case <-done:
+				bh.inflight.Done()
+				return
 			}
-			continue
 		}
+	}()
 
-		if logger.IsEnabledFor(logging.DEBUG) {
-			logger.Debugf("Broadcast is filtering message for chain %s", payload.Header.ChannelHeader.ChannelId)
+	for v := range pending {
+		if v == nil {
+			return srv.Send(bh.record(srv.Context(), nil, nil, drainingResponse()))
 		}
+		resp, keepOpen := bh.complete(v)
+		err := srv.Send(bh.record(srv.Context(), v.msg, nil, resp))
+		bh.inflight.Done()
+		if err != nil || !keepOpen {
+			return err
+		}
+	}
 
-		// Normal transaction for existing chain
-		_, filterErr := support.Filters().Apply(msg)
+	if err := <-recvErr; err != io.EOF {
+		return err
+	}
+	return nil
+}
 
-		if filterErr != nil {
-			logger.Debugf("Rejecting broadcast message")
-			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST})
+// HandleBatch starts a service thread for a given gRPC connection and services the batch broadcast
+// connection. Unlike Handle, a rejected envelope does not drop the connection, its status is
+// reported along with the status of the other envelopes of its batch. The error infos of the
+// accepted envelopes are empty
+func (bh *handlerImpl) HandleBatch(srv ab.AtomicBroadcast_BroadcastBatchServer) error {
+	for {
+		batch, err := srv.Recv()
+		if err == io.EOF {
+			return nil
 		}
+		if err != nil {
+			return err
+		}
+
+		logger.Debugf("Received a batch of %d messages", len(batch.Envelopes))
 
-		if !support.Enqueue(msg) {
-			logger.Debugf("Consenter instructed us to shut down")
-			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE})
+		if !bh.enter() {
+			statuses := make([]cb.Status, len(batch.Envelopes))
+			errorInfos := make([]*cb.ErrorInfo, len(batch.Envelopes))
+			for i, msg := range batch.Envelopes {
+				resp := bh.record(srv.Context(), msg, nil, drainingResponse())
+				statuses[i], errorInfos[i] = resp.Status, resp.ErrorInfo
+			}
+			return srv.Send(&ab.BroadcastBatchResponse{Statuses: statuses, ErrorInfos: errorInfos})
 		}
 
-		if logger.IsEnabledFor(logging.DEBUG) {
-			logger.Debugf("Broadcast is successfully enqueued message for chain %s", payload.Header.ChannelHeader.ChannelId)
+		// With validators, the whole batch is validated concurrently
+		validations := make([]*validation, len(batch.Envelopes))
+		for i, msg := range batch.Envelopes {
+			validations[i] = bh.submit(msg, nil)
 		}
 
-		err = srv.Send(&ab.BroadcastResponse{Status: cb.Status_SUCCESS})
+		statuses := make([]cb.Status, len(batch.Envelopes))
+		errorInfos := make([]*cb.ErrorInfo, len(batch.Envelopes))
+		for i, v := range validations {
+			resp, _ := bh.complete(v)
+			bh.record(srv.Context(), v.msg, nil, resp)
+			statuses[i], errorInfos[i] = resp.Status, resp.ErrorInfo
+			if errorInfos[i] == nil {
+				errorInfos[i] = &cb.ErrorInfo{}
+			}
+		}
 
+		err = srv.Send(&ab.BroadcastBatchResponse{Statuses: statuses, ErrorInfos: errorInfos})
+		bh.inflight.Done()
 		if err != nil {
 			return err
 		}
 	}
 }
+
+// enter registers a message (or batch) being processed, it returns false if the handler is draining
+func (bh *handlerImpl) enter() bool {
+	bh.lock.Lock()
+	defer bh.lock.Unlock()
+	if bh.draining {
+		return false
+	}
+	bh.inflight.Add(1)
+	return true
+}
+
+// Drain rejects the messages received from now on and waits up to timeout for the ones being
+// processed to be enqueued
+func (bh *handlerImpl) Drain(timeout time.Duration) bool {
+	bh.lock.Lock()
+	bh.draining = true
+	bh.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		bh.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// validate filters the messages handed to the validators
+func (bh *handlerImpl) validate() {
+	for v := range bh.validations {
+		_, err := v.support.Filters().Apply(v.msg)
+		v.result <- err
+	}
+}
+
+// submit performs the ordering checks of msg and, if it passes them and is for an existing
+// chain, hands it to the validators. It returns nil if done is closed while waiting for them
+func (bh *handlerImpl) submit(msg *cb.Envelope, done <-chan struct{}) *validation {
+	v := &validation{msg: msg}
+	bh.check(v)
+	return bh.dispatch(v, done)
+}
+
+// dispatch hands v to the validators if its message passed the ordering checks and is for an
+// existing chain. It returns nil if done is closed while waiting for them
+func (bh *handlerImpl) dispatch(v *validation, done <-chan struct{}) *validation {
+	if v.status != cb.Status_SUCCESS || v.support == nil || bh.validations == nil {
+		return v
+	}
+
+	v.result = make(chan error, 1)
+	select {
+	case bh.validations <- v:
+		return v
+	case <-done:
+		return nil
+	}
+}
+
+// record records with the Audit of the handler the rejection of msg by resp, if resp rejects
+// it, and returns resp. msg is nil if the envelope was rejected before it was received whole,
+// manifest is the manifest of an envelope sent in chunks
+func (bh *handlerImpl) record(ctx context.Context, msg *cb.Envelope, manifest *ab.ChunkManifest, resp *ab.BroadcastResponse) *ab.BroadcastResponse {
+	if audit := bh.sm.Audit(); audit != nil && resp.Status != cb.Status_SUCCESS {
+		rejection := newRejection(ctx, msg, manifest, resp)
+		_, channelExists := bh.sm.GetChain(rejection.ChainId)
+		audit.Record(rejection, channelExists)
+	}
+	return resp
+}
+
+// reject sets the status of the failed ordering checks of v and the error rejecting its message
+func (v *validation) reject(status cb.Status, err error) {
+	v.status = status
+	v.err = err
+}
+
+// response returns the response rejecting a message with the given status and error
+func response(status cb.Status, err error) *ab.BroadcastResponse {
+	return &ab.BroadcastResponse{Status: status, ErrorInfo: cerrors.Info(err)}
+}
+
+// drainingResponse returns the response rejecting the messages received while draining
+func drainingResponse() *ab.BroadcastResponse {
+	return response(cb.Status_SERVICE_UNAVAILABLE, cerrors.Unavailable(cerrors.ServiceUnavailable, "Orderer is draining"))
+}
+
+// check performs the ordering checks of the message of v, it sets the status of the checks and
+// the support of the chain the message is for, which is left nil if it proposes a new chain
+func (bh *handlerImpl) check(v *validation) {
+	payload := &cb.Payload{}
+	err := proto.Unmarshal(v.msg.Payload, payload)
+	if err != nil || payload.Header == nil || payload.Header.ChannelHeader == nil || payload.Header.ChannelHeader.ChannelId == "" {
+		logger.Debugf("Received malformed message")
+		v.reject(cb.Status_BAD_REQUEST, cerrors.Validation(cerrors.MalformedEnvelope, "Malformed message"))
+		return
+	}
+	v.chainID = payload.Header.ChannelHeader.ChannelId
+
+	support, ok := bh.sm.GetChain(v.chainID)
+	if !ok {
+		// Chain not found, maybe create one?
+		if payload.Header.ChannelHeader.Type != int32(cb.HeaderType_CONFIG) {
+			v.reject(cb.Status_NOT_FOUND, cerrors.Validation(cerrors.ChannelNotFound, "Chain %s does not exist", v.chainID))
+			return
+		}
+		v.status = cb.Status_SUCCESS
+		return
+	}
+
+	size := uint32(len(v.msg.Payload) + len(v.msg.Signature))
+	if batchSize := support.SharedConfig().BatchSize(); batchSize != nil && size > batchSize.AbsoluteMaxBytes {
+		logger.Debugf("Rejecting %d byte message for chain %s", size, v.chainID)
+		v.reject(cb.Status_BAD_REQUEST, cerrors.Validation(cerrors.RequestTooLarge, "Message of %d bytes exceeds the limit of %d bytes", size, batchSize.AbsoluteMaxBytes))
+		return
+	}
+
+	if support.StorageExceeded() {
+		logger.Warningf("Rejecting message for chain %s which exceeds its storage quota", v.chainID)
+		v.reject(cb.Status_INSUFFICIENT_STORAGE, cerrors.Unavailable(cerrors.StorageExceeded, "Chain %s exceeds its storage quota", v.chainID))
+		return
+	}
+
+	v.support = support
+	v.status = cb.Status_SUCCESS
+}
+
+// complete finishes the processing of v, once validated if needed, by enqueuing its message for
+// ordering. It returns the response to the message and whether a Broadcast connection may keep
+// being serviced after it
+func (bh *handlerImpl) complete(v *validation) (*ab.BroadcastResponse, bool) {
+	if v.status != cb.Status_SUCCESS {
+		return response(v.status, v.err), false
+	}
+
+	if v.support == nil {
+		logger.Debugf("Proposing new chain")
+		status := bh.sm.ProposeChain(v.msg)
+		if status != cb.Status_SUCCESS {
+			return response(status, cerrors.New(cerrors.CategoryOfStatus(status), cerrors.ChannelCreationRejected, "Chain creation rejected with status %s", status)), true
+		}
+		return &ab.BroadcastResponse{Status: status}, true
+	}
+
+	var filterErr error
+	if v.result != nil {
+		filterErr = <-v.result
+	} else {
+		if logger.IsEnabledFor(logging.DEBUG) {
+			logger.Debugf("Broadcast is filtering message for chain %s", v.chainID)
+		}
+		// Normal transaction for existing chain
+		_, filterErr = v.support.Filters().Apply(v.msg)
+	}
+
+	if filterErr != nil {
+		logger.Debugf("Rejecting broadcast message")
+		return response(cb.Status_BAD_REQUEST, filterErr), false
+	}
+
+	if !v.support.Enqueue(v.msg) {
+		logger.Debugf("Consenter instructed us to shut down")
+		return response(cb.Status_SERVICE_UNAVAILABLE, cerrors.Unavailable(cerrors.ServiceUnavailable, "Chain %s is shutting down", v.chainID)), false
+	}
+
+	if logger.IsEnabledFor(logging.DEBUG) {
+		logger.Debugf("Broadcast is successfully enqueued message for chain %s", v.chainID)
+	}
+
+	return &ab.BroadcastResponse{Status: cb.Status_SUCCESS}, true
+}
@@ -21,12 +21,16 @@ import (
 	"testing"
 	"time"
 
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	cerrors "github.com/hyperledger/fabric/common/errors"
+	mockconfigtxorderer "github.com/hyperledger/fabric/common/mocks/configtx/handlers/orderer"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
 
 	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
 
@@ -54,6 +58,10 @@ func (m *mockB) Send(br *ab.BroadcastResponse) error {
 	return nil
 }
 
+func (m *mockB) Context() context.Context {
+	return context.Background()
+}
+
 func (m *mockB) Recv() (*cb.Envelope, error) {
 	msg, ok := <-m.recvChan
 	if !ok {
@@ -62,8 +70,40 @@ func (m *mockB) Recv() (*cb.Envelope, error) {
 	return msg, nil
 }
 
+type mockBB struct {
+	grpc.ServerStream
+	recvChan chan *ab.BroadcastBatch
+	sendChan chan *ab.BroadcastBatchResponse
+}
+
+func newMockBB() *mockBB {
+	return &mockBB{
+		recvChan: make(chan *ab.BroadcastBatch),
+		sendChan: make(chan *ab.BroadcastBatchResponse),
+	}
+}
+
+func (m *mockBB) Send(br *ab.BroadcastBatchResponse) error {
+	m.sendChan <- br
+	return nil
+}
+
+func (m *mockBB) Context() context.Context {
+	return context.Background()
+}
+
+func (m *mockBB) Recv() (*ab.BroadcastBatch, error) {
+	msg, ok := <-m.recvChan
+	if !ok {
+		return msg, fmt.Errorf("Channel closed")
+	}
+	return msg, nil
+}
+
 type mockSupportManager struct {
-	chains map[string]*mockSupport
+	chains      map[string]*mockSupport
+	chunkLimits ChunkLimits
+	audit       *Audit
 }
 
 func (mm *mockSupportManager) GetChain(chainID string) (Support, bool) {
@@ -71,6 +111,14 @@ func (mm *mockSupportManager) GetChain(chainID string) (Support, bool) {
 	return chain, ok
 }
 
+func (mm *mockSupportManager) MaxReassembledBytes(chainID string) uint64 {
+	return mm.chunkLimits.MaxReassembledBytes(chainID)
+}
+
+func (mm *mockSupportManager) Audit() *Audit {
+	return mm.audit
+}
+
 func (mm *mockSupportManager) ProposeChain(configTx *cb.Envelope) cb.Status {
 	payload := utils.ExtractPayloadOrPanic(configTx)
 
@@ -84,16 +132,33 @@ func (mm *mockSupportManager) ProposeChain(configTx *cb.Envelope) cb.Status {
 }
 
 type mockSupport struct {
-	filters       *filter.RuleSet
-	rejectEnqueue bool
+	filters         *filter.RuleSet
+	rejectEnqueue   bool
+	storageExceeded bool
+	sharedConfig    *mockconfigtxorderer.SharedConfig
+	enqueued        chan struct{}
 }
 
 func (ms *mockSupport) Filters() *filter.RuleSet {
 	return ms.filters
 }
 
+func (ms *mockSupport) SharedConfig() configtxapi.OrdererConfig {
+	if ms.sharedConfig == nil {
+		return &mockconfigtxorderer.SharedConfig{}
+	}
+	return ms.sharedConfig
+}
+
+func (ms *mockSupport) StorageExceeded() bool {
+	return ms.storageExceeded
+}
+
 // Enqueue sends a message for ordering
 func (ms *mockSupport) Enqueue(env *cb.Envelope) bool {
+	if ms.enqueued != nil {
+		ms.enqueued <- struct{}{}
+	}
 	return !ms.rejectEnqueue
 }
 
@@ -214,6 +279,9 @@ func TestBadChannelId(t *testing.T) {
 	if reply.Status != cb.Status_NOT_FOUND {
 		t.Fatalf("Should have rejected message to a chain which does not exist")
 	}
+	if reply.ErrorInfo == nil || reply.ErrorInfo.Code != cerrors.ChannelNotFound || reply.ErrorInfo.Category != cb.ErrorCategory_VALIDATION {
+		t.Fatalf("Should have reported the chain as not found, got %v", reply.ErrorInfo)
+	}
 
 	select {
 	case <-done:
@@ -246,3 +314,289 @@ func TestNewChannelId(t *testing.T) {
 		t.Fatalf("Should have successfully sent message to new chain, got %v", reply)
 	}
 }
+
+func TestBatch(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	bh := NewHandlerImpl(mm)
+	m := newMockBB()
+	defer close(m.recvChan)
+	go bh.HandleBatch(m)
+
+	m.recvChan <- &ab.BroadcastBatch{Envelopes: []*cb.Envelope{
+		makeMessage(systemChain, []byte("Some bytes")),
+		&cb.Envelope{},
+		makeMessage("Wrong chain", []byte("Some bytes")),
+		makeMessage(systemChain, []byte("Other bytes")),
+	}}
+	reply := <-m.sendChan
+	expected := []cb.Status{cb.Status_SUCCESS, cb.Status_BAD_REQUEST, cb.Status_NOT_FOUND, cb.Status_SUCCESS}
+	if len(reply.Statuses) != len(expected) {
+		t.Fatalf("Expected %d statuses, got %d", len(expected), len(reply.Statuses))
+	}
+	for i := range expected {
+		if reply.Statuses[i] != expected[i] {
+			t.Errorf("Expected status %v for message %d, got %v", expected[i], i, reply.Statuses[i])
+		}
+	}
+	expectedCodes := []string{"", cerrors.MalformedEnvelope, cerrors.ChannelNotFound, ""}
+	if len(reply.ErrorInfos) != len(expectedCodes) {
+		t.Fatalf("Expected %d error infos, got %d", len(expectedCodes), len(reply.ErrorInfos))
+	}
+	for i := range expectedCodes {
+		if reply.ErrorInfos[i].Code != expectedCodes[i] {
+			t.Errorf("Expected error code %q for message %d, got %v", expectedCodes[i], i, reply.ErrorInfos[i])
+		}
+	}
+
+	// Rejected messages do not terminate the stream
+	m.recvChan <- &ab.BroadcastBatch{Envelopes: []*cb.Envelope{makeMessage(systemChain, []byte("Some bytes"))}}
+	reply = <-m.sendChan
+	if len(reply.Statuses) != 1 || reply.Statuses[0] != cb.Status_SUCCESS {
+		t.Fatalf("Should have successfully queued the message of the second batch, got %v", reply)
+	}
+}
+
+func TestOversizedMessage(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	mSysChain.sharedConfig = &mockconfigtxorderer.SharedConfig{BatchSizeVal: &ab.BatchSize{AbsoluteMaxBytes: 100}}
+	bh := NewHandlerImpl(mm)
+	m := newMockB()
+	defer close(m.recvChan)
+	done := make(chan struct{})
+	go func() {
+		bh.Handle(m)
+		close(done)
+	}()
+
+	m.recvChan <- makeMessage(systemChain, make([]byte, 200))
+	reply := <-m.sendChan
+	if reply.Status != cb.Status_BAD_REQUEST {
+		t.Fatalf("Should have rejected the oversized message, got %v", reply.Status)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Should have terminated the stream")
+	}
+}
+
+func TestStorageExceeded(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	mSysChain.storageExceeded = true
+	bh := NewHandlerImpl(mm)
+	m := newMockB()
+	defer close(m.recvChan)
+	done := make(chan struct{})
+	go func() {
+		bh.Handle(m)
+		close(done)
+	}()
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply := <-m.sendChan
+	if reply.Status != cb.Status_INSUFFICIENT_STORAGE {
+		t.Fatalf("Should have rejected the message for a chain exceeding its storage quota, got %v", reply.Status)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Should have terminated the stream")
+	}
+}
+
+func TestValidators(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	bh := NewHandlerImplWithValidators(mm, 4, 10)
+	m := newMockB()
+	defer close(m.recvChan)
+	done := make(chan struct{})
+	go func() {
+		bh.Handle(m)
+		close(done)
+	}()
+
+	go func() {
+		for i := 0; i < 50; i++ {
+			m.recvChan <- makeMessage(systemChain, []byte(fmt.Sprintf("%d", i)))
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		reply := <-m.sendChan
+		if reply.Status != cb.Status_SUCCESS {
+			t.Fatalf("Should have successfully queued message %d, got %v", i, reply.Status)
+		}
+	}
+
+	mSysChain.rejectEnqueue = true
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply := <-m.sendChan
+	if reply.Status != cb.Status_SERVICE_UNAVAILABLE {
+		t.Fatalf("Should not have successfully queued the message, got %v", reply.Status)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Should have terminated the stream")
+	}
+}
+
+type rejectRule struct{}
+
+func (r rejectRule) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
+	return filter.Reject, nil
+}
+
+func TestValidatorsRejection(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	bh := NewHandlerImplWithValidators(mm, 2, 10)
+	m := newMockB()
+	defer close(m.recvChan)
+	done := make(chan struct{})
+	go func() {
+		bh.Handle(m)
+		close(done)
+	}()
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	if reply := <-m.sendChan; reply.Status != cb.Status_SUCCESS {
+		t.Fatalf("Should have successfully queued the message, got %v", reply.Status)
+	}
+
+	mSysChain.filters = filter.NewRuleSet([]filter.Rule{rejectRule{}})
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply := <-m.sendChan
+	if reply.Status != cb.Status_BAD_REQUEST {
+		t.Fatalf("Should have rejected the message, got %v", reply.Status)
+	}
+	if reply.ErrorInfo == nil || reply.ErrorInfo.Code != cerrors.MessageRejected || reply.ErrorInfo.Category != cb.ErrorCategory_VALIDATION {
+		t.Fatalf("Should have reported the message as rejected by the filters, got %v", reply.ErrorInfo)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Should have terminated the stream")
+	}
+}
+
+func TestValidatorsBatch(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	bh := NewHandlerImplWithValidators(mm, 2, 10)
+	m := newMockBB()
+	defer close(m.recvChan)
+	go bh.HandleBatch(m)
+
+	batch := &ab.BroadcastBatch{}
+	for i := 0; i < 20; i++ {
+		batch.Envelopes = append(batch.Envelopes, makeMessage(systemChain, []byte(fmt.Sprintf("%d", i))))
+	}
+	batch.Envelopes = append(batch.Envelopes, makeMessage("Wrong chain", []byte("Some bytes")))
+
+	m.recvChan <- batch
+	reply := <-m.sendChan
+	if len(reply.Statuses) != 21 {
+		t.Fatalf("Expected 21 statuses, got %d", len(reply.Statuses))
+	}
+	for i := 0; i < 20; i++ {
+		if reply.Statuses[i] != cb.Status_SUCCESS {
+			t.Errorf("Should have successfully queued message %d, got %v", i, reply.Statuses[i])
+		}
+	}
+	if reply.Statuses[20] != cb.Status_NOT_FOUND {
+		t.Errorf("Should have rejected the message for a chain which does not exist, got %v", reply.Statuses[20])
+	}
+}
+
+func TestDrain(t *testing.T) {
+	for _, withValidators := range []bool{false, true} {
+		mm, mSysChain := getMockSupportManager()
+		mSysChain.enqueued = make(chan struct{})
+		bh := NewHandlerImpl(mm)
+		if withValidators {
+			bh = NewHandlerImplWithValidators(mm, 4, 10)
+		}
+		m := newMockB()
+		done := make(chan struct{})
+		go func() {
+			bh.Handle(m)
+			close(done)
+		}()
+
+		// The reply is not read yet, so the message is still in flight
+		m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+		<-mSysChain.enqueued
+		if bh.Drain(100 * time.Millisecond) {
+			t.Fatalf("Should not have drained while a message is in flight")
+		}
+
+		drained := make(chan bool)
+		go func() {
+			drained <- bh.Drain(time.Second)
+		}()
+		if reply := <-m.sendChan; reply.Status != cb.Status_SUCCESS {
+			t.Fatalf("Should have successfully queued the in flight message, got %v", reply.Status)
+		}
+		if !<-drained {
+			t.Fatalf("Should have drained once the in flight message was enqueued")
+		}
+
+		m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+		reply := <-m.sendChan
+		if reply.Status != cb.Status_SERVICE_UNAVAILABLE {
+			t.Fatalf("Should have rejected the message received while draining, got %v", reply.Status)
+		}
+		if reply.ErrorInfo == nil || !cerrors.Retryable(reply.ErrorInfo.Category) {
+			t.Fatalf("Should have reported the message received while draining as retryable, got %v", reply.ErrorInfo)
+		}
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("Should have terminated the stream")
+		}
+	}
+}
+
+func TestDrainWhileClosing(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	bh := NewHandlerImplWithValidators(mm, 4, 10)
+	m := newMockB()
+	done := make(chan struct{})
+	go func() {
+		bh.Handle(m)
+		close(done)
+	}()
+
+	// The rejection of the message closes the stream once its reply is read
+	m.recvChan <- makeMessage("Fake", []byte("Some bytes"))
+	drained := make(chan bool)
+	go func() {
+		drained <- bh.Drain(time.Second)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// The message received while draining leaves a draining notice pending
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	time.Sleep(10 * time.Millisecond)
+	if reply := <-m.sendChan; reply.Status != cb.Status_NOT_FOUND {
+		t.Fatalf("Should have rejected the message for a chain which does not exist, got %v", reply.Status)
+	}
+	if !<-drained {
+		t.Fatalf("Should have drained once the rejected message was replied to")
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Should have terminated the stream")
+	}
+	// The draining notice left pending must not be counted as in flight
+	time.Sleep(10 * time.Millisecond)
+	if !bh.Drain(100 * time.Millisecond) {
+		t.Fatalf("Should still be drained")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcast
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	cerrors "github.com/hyperledger/fabric/common/errors"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// ChunkLimits bounds the size of the envelopes reassembled from their chunks by BroadcastChunked
+type ChunkLimits struct {
+	// MaxBytes is the limit of the chains not listed in Channels, 0 to reject chunked envelopes
+	MaxBytes uint64
+
+	// Channels are the limits of specific chains
+	Channels map[string]uint64
+}
+
+// MaxReassembledBytes returns the size limit of the envelopes reassembled for chainID
+func (cl ChunkLimits) MaxReassembledBytes(chainID string) uint64 {
+	if max, ok := cl.Channels[chainID]; ok {
+		return max
+	}
+	return cl.MaxBytes
+}
+
+// ChunkEnvelope splits the marshaled env into chunks of at most chunkSize bytes to be sent to
+// BroadcastChunked, the first chunk carrying the manifest of env for chainID
+func ChunkEnvelope(env *cb.Envelope, chainID string, chunkSize int) ([]*ab.EnvelopeChunk, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("Invalid chunk size %d", chunkSize)
+	}
+	data, err := proto.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling envelope: %s", err)
+	}
+	hash := sha256.Sum256(data)
+	manifest := &ab.ChunkManifest{ChannelId: chainID, Size: uint64(len(data)), Hash: hash[:]}
+
+	var chunks []*ab.EnvelopeChunk
+	for len(data) > 0 {
+		n := chunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+		chunks = append(chunks, &ab.EnvelopeChunk{Data: data[:n]})
+		data = data[n:]
+	}
+	chunks[0].Manifest = manifest
+	return chunks, nil
+}
+
+// reassembly is an envelope being reassembled from its chunks
+type reassembly struct {
+	manifest *ab.ChunkManifest
+	data     bytes.Buffer
+}
+
+// HandleChunked starts a service thread for a given gRPC connection and services the chunked
+// broadcast connection. The envelopes are reassembled from their chunks, up to the limit of
+// their chain, and then handled as if sent to Broadcast. A malformed or oversized envelope
+// drops the connection, as does a rejected one
+func (bh *handlerImpl) HandleChunked(srv ab.AtomicBroadcast_BroadcastChunkedServer) error {
+	var r *reassembly
+	for {
+		chunk, err := srv.Recv()
+		if err == io.EOF {
+			if r != nil {
+				logger.Debugf("Client closed the stream while sending an envelope in chunks")
+				return srv.Send(bh.record(srv.Context(), nil, r.manifest, malformedChunkResponse("Stream closed while sending an envelope in chunks")))
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if chunk.Manifest != nil {
+			if r != nil {
+				logger.Debugf("Received a manifest before the end of the previous envelope")
+				return srv.Send(bh.record(srv.Context(), nil, chunk.Manifest, malformedChunkResponse("Manifest received before the end of the previous envelope")))
+			}
+			if resp := bh.checkManifest(chunk.Manifest); resp != nil {
+				return srv.Send(bh.record(srv.Context(), nil, chunk.Manifest, resp))
+			}
+			r = &reassembly{manifest: chunk.Manifest}
+		} else if r == nil {
+			logger.Debugf("Received a chunk without a manifest")
+			return srv.Send(bh.record(srv.Context(), nil, nil, malformedChunkResponse("Chunk received without a manifest")))
+		}
+
+		if uint64(r.data.Len()+len(chunk.Data)) > r.manifest.Size {
+			logger.Debugf("Received more data than the %d bytes of the manifest", r.manifest.Size)
+			return srv.Send(bh.record(srv.Context(), nil, r.manifest, malformedChunkResponse(fmt.Sprintf("Received more data than the %d bytes of the manifest", r.manifest.Size))))
+		}
+		r.data.Write(chunk.Data)
+		if uint64(r.data.Len()) < r.manifest.Size {
+			continue
+		}
+
+		msg, err := r.envelope()
+		if err != nil {
+			logger.Debugf("Rejecting reassembled envelope: %s", err)
+			return srv.Send(bh.record(srv.Context(), nil, r.manifest, malformedChunkResponse(fmt.Sprintf("Invalid reassembled envelope: %s", err))))
+		}
+		manifest := r.manifest
+		chainID := manifest.ChannelId
+		r = nil
+
+		if !bh.enter() {
+			return srv.Send(bh.record(srv.Context(), msg, manifest, drainingResponse()))
+		}
+		v := &validation{msg: msg}
+		bh.check(v)
+		if v.chainID != "" && v.chainID != chainID {
+			logger.Debugf("Rejecting envelope for chain %s sent with a manifest for chain %s", v.chainID, chainID)
+			v.reject(cb.Status_BAD_REQUEST, cerrors.Validation(cerrors.MalformedEnvelope, "Envelope for chain %s sent with a manifest for chain %s", v.chainID, chainID))
+		}
+		resp, keepOpen := bh.complete(bh.dispatch(v, nil))
+		err = srv.Send(bh.record(srv.Context(), msg, manifest, resp))
+		bh.inflight.Done()
+		if err != nil || !keepOpen {
+			return err
+		}
+	}
+}
+
+// checkManifest checks the manifest of an envelope before its chunks are reassembled, it returns
+// the response rejecting the envelope, or nil if the manifest passes the checks
+func (bh *handlerImpl) checkManifest(manifest *ab.ChunkManifest) *ab.BroadcastResponse {
+	if manifest.ChannelId == "" || manifest.Size == 0 || len(manifest.Hash) != sha256.Size {
+		logger.Debugf("Received malformed manifest")
+		return malformedChunkResponse("Malformed manifest")
+	}
+	if max := bh.sm.MaxReassembledBytes(manifest.ChannelId); manifest.Size > max {
+		logger.Debugf("Rejecting %d byte chunked envelope for chain %s, the limit is %d bytes", manifest.Size, manifest.ChannelId, max)
+		return response(cb.Status_REQUEST_ENTITY_TOO_LARGE, cerrors.Validation(cerrors.RequestTooLarge, "Chunked envelope of %d bytes exceeds the limit of %d bytes", manifest.Size, max))
+	}
+	return nil
+}
+
+// malformedChunkResponse returns the response dropping a connection sending malformed chunks
+func malformedChunkResponse(msg string) *ab.BroadcastResponse {
+	return response(cb.Status_BAD_REQUEST, cerrors.Validation(cerrors.MalformedEnvelope, "%s", msg))
+}
+
+// envelope returns the envelope reassembled, once its hash is verified
+func (r *reassembly) envelope() (*cb.Envelope, error) {
+	hash := sha256.Sum256(r.data.Bytes())
+	if !bytes.Equal(hash[:], r.manifest.Hash) {
+		return nil, fmt.Errorf("hash mismatch")
+	}
+	msg := &cb.Envelope{}
+	if err := proto.Unmarshal(r.data.Bytes(), msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
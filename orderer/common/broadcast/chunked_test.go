@@ -0,0 +1,172 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcast
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type mockC struct {
+	grpc.ServerStream
+	recvChan chan *ab.EnvelopeChunk
+	sendChan chan *ab.BroadcastResponse
+}
+
+func newMockC() *mockC {
+	return &mockC{
+		recvChan: make(chan *ab.EnvelopeChunk),
+		sendChan: make(chan *ab.BroadcastResponse),
+	}
+}
+
+func (m *mockC) Send(br *ab.BroadcastResponse) error {
+	m.sendChan <- br
+	return nil
+}
+
+func (m *mockC) Context() context.Context {
+	return context.Background()
+}
+
+func (m *mockC) Recv() (*ab.EnvelopeChunk, error) {
+	msg, ok := <-m.recvChan
+	if !ok {
+		return msg, fmt.Errorf("Channel closed")
+	}
+	return msg, nil
+}
+
+func chunksOf(t *testing.T, env *cb.Envelope, chainID string, chunkSize int) []*ab.EnvelopeChunk {
+	chunks, err := ChunkEnvelope(env, chainID, chunkSize)
+	if err != nil {
+		t.Fatalf("Error chunking envelope: %s", err)
+	}
+	return chunks
+}
+
+func TestChunkLimits(t *testing.T) {
+	cl := ChunkLimits{MaxBytes: 10, Channels: map[string]uint64{"large": 100, "none": 0}}
+	for chainID, expected := range map[string]uint64{"other": 10, "large": 100, "none": 0} {
+		if max := cl.MaxReassembledBytes(chainID); max != expected {
+			t.Errorf("Expected limit %d for chain %s, got %d", expected, chainID, max)
+		}
+	}
+}
+
+func TestChunked(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	mm.chunkLimits = ChunkLimits{MaxBytes: 1024 * 1024}
+	mSysChain.enqueued = make(chan struct{}, 2)
+	bh := NewHandlerImpl(mm)
+	m := newMockC()
+	defer close(m.recvChan)
+	go bh.HandleChunked(m)
+
+	for i := 0; i < 2; i++ {
+		chunks := chunksOf(t, makeMessage(systemChain, bytes.Repeat([]byte{byte(i)}, 64*1024)), systemChain, 10000)
+		if len(chunks) < 7 {
+			t.Fatalf("Expected the envelope to be split in 7 chunks at least, got %d", len(chunks))
+		}
+		for _, chunk := range chunks {
+			m.recvChan <- chunk
+		}
+		reply := <-m.sendChan
+		if reply.Status != cb.Status_SUCCESS {
+			t.Fatalf("Should have successfully queued envelope %d, got %v", i, reply.Status)
+		}
+	}
+	if len(mSysChain.enqueued) != 2 {
+		t.Fatalf("Expected both envelopes to be enqueued")
+	}
+}
+
+func TestChunkedTooLarge(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	mm.chunkLimits = ChunkLimits{MaxBytes: 1024 * 1024, Channels: map[string]uint64{systemChain: 1000}}
+	bh := NewHandlerImpl(mm)
+	m := newMockC()
+	defer close(m.recvChan)
+	go bh.HandleChunked(m)
+
+	// the envelope is rejected on its manifest, before its chunks are received
+	m.recvChan <- chunksOf(t, makeMessage(systemChain, make([]byte, 2000)), systemChain, 100)[0]
+	reply := <-m.sendChan
+	if reply.Status != cb.Status_REQUEST_ENTITY_TOO_LARGE {
+		t.Fatalf("Should have rejected the oversized envelope, got %v", reply.Status)
+	}
+}
+
+func TestChunkedMalformed(t *testing.T) {
+	env := makeMessage(systemChain, []byte("Some bytes"))
+	tamper := func(chunks []*ab.EnvelopeChunk) []*ab.EnvelopeChunk {
+		chunks[1].Data = bytes.Repeat([]byte{0}, len(chunks[1].Data))
+		return chunks
+	}
+	missingManifest := func(chunks []*ab.EnvelopeChunk) []*ab.EnvelopeChunk {
+		return chunks[1:]
+	}
+	wrongChain := func(chunks []*ab.EnvelopeChunk) []*ab.EnvelopeChunk {
+		return chunksOf(t, makeMessage("otherChain", []byte("Some bytes")), systemChain, 10)
+	}
+	tooMuchData := func(chunks []*ab.EnvelopeChunk) []*ab.EnvelopeChunk {
+		chunks[len(chunks)-1].Data = append(chunks[len(chunks)-1].Data, 0)
+		return chunks
+	}
+
+	for name, mangle := range map[string]func([]*ab.EnvelopeChunk) []*ab.EnvelopeChunk{
+		"tampered":         tamper,
+		"missing manifest": missingManifest,
+		"wrong chain":      wrongChain,
+		"too much data":    tooMuchData,
+	} {
+		mm, _ := getMockSupportManager()
+		mm.chunkLimits = ChunkLimits{MaxBytes: 1024}
+		bh := NewHandlerImpl(mm)
+		m := newMockC()
+		done := make(chan error)
+		go func() {
+			done <- bh.HandleChunked(m)
+		}()
+
+		chunks := mangle(chunksOf(t, env, systemChain, 10))
+		var reply *ab.BroadcastResponse
+		for _, chunk := range chunks {
+			select {
+			case m.recvChan <- chunk:
+				continue
+			case reply = <-m.sendChan:
+			}
+			break
+		}
+		if reply == nil {
+			reply = <-m.sendChan
+		}
+		if reply.Status != cb.Status_BAD_REQUEST {
+			t.Errorf("Expected the %s envelope to be rejected, got %v", name, reply.Status)
+		}
+		<-done
+		close(m.recvChan)
+	}
+}
@@ -18,14 +18,17 @@ package deliver
 
 import (
 	"fmt"
+	"time"
 
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	"github.com/hyperledger/fabric/orderer/common/sigfilter"
 	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 
 	"github.com/golang/protobuf/proto"
@@ -56,13 +59,30 @@ type Support interface {
 }
 
 type deliverServer struct {
-	sm SupportManager
+	sm     SupportManager
+	signer crypto.LocalSigner
+
+	// revalidationInterval is the time after which the authorization of a
+	// client is evaluated again, along with the next block delivered to it
+	revalidationInterval time.Duration
+}
+
+// NewHandlerImpl creates an implementation of the Handler interface which
+// evaluates again the authorization of a client every revalidationInterval
+func NewHandlerImpl(sm SupportManager, revalidationInterval time.Duration) Handler {
+	return &deliverServer{
+		sm:                   sm,
+		revalidationInterval: revalidationInterval,
+	}
 }
 
-// NewHandlerImpl creates an implementation of the Handler interface
-func NewHandlerImpl(sm SupportManager) Handler {
+// NewHandlerImplWithIntegrity creates an implementation of the Handler interface
+// which attaches to every response an integrity proof signed by signer
+func NewHandlerImplWithIntegrity(sm SupportManager, revalidationInterval time.Duration, signer crypto.LocalSigner) Handler {
 	return &deliverServer{
-		sm: sm,
+		sm:                   sm,
+		signer:               signer,
+		revalidationInterval: revalidationInterval,
 	}
 }
 
@@ -89,13 +109,13 @@ func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
 
 		chain, ok := ds.sm.GetChain(payload.Header.ChannelHeader.ChannelId)
 		if !ok {
-			return sendStatusReply(srv, cb.Status_NOT_FOUND)
+			return ds.sendStatusReply(srv, cb.Status_NOT_FOUND)
 		}
 
 		sf := sigfilter.New(chain.SharedConfig().EgressPolicyNames, chain.PolicyManager())
 		result, _ := sf.Apply(envelope)
 		if result != filter.Forward {
-			return sendStatusReply(srv, cb.Status_FORBIDDEN)
+			return ds.sendStatusReply(srv, cb.Status_FORBIDDEN)
 		}
 
 		seekInfo := &ab.SeekInfo{}
@@ -108,6 +128,12 @@ func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
 			logger.Debugf("Received seekInfo %v for chain %s", seekInfo, payload.Header.ChannelHeader.ChannelId)
 		}
 
+		if _, ok := ab.Compression_name[int32(seekInfo.Compression)]; !ok {
+			logger.Errorf("Received a deliver request with unknown compression %d", seekInfo.Compression)
+			return ds.sendStatusReply(srv, cb.Status_BAD_REQUEST)
+		}
+
+		authorizedAt := time.Now()
 		cursor, number := chain.Reader().Iterator(seekInfo.Start)
 		var stopNum uint64
 		switch stop := seekInfo.Stop.Type.(type) {
@@ -121,23 +147,39 @@ func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
 
 		for {
 			if seekInfo.Behavior == ab.SeekInfo_BLOCK_UNTIL_READY {
-				<-cursor.ReadyChan()
+				select {
+				case <-cursor.ReadyChan():
+				case <-srv.Context().Done():
+					logger.Debugf("Client went away while waiting for the next block")
+					return srv.Context().Err()
+				}
 			} else {
 				select {
 				case <-cursor.ReadyChan():
 				default:
-					return sendStatusReply(srv, cb.Status_NOT_FOUND)
+					return ds.sendStatusReply(srv, cb.Status_NOT_FOUND)
 				}
 			}
 
 			block, status := cursor.Next()
 			if status != cb.Status_SUCCESS {
 				logger.Errorf("Error reading from channel, cause was: %v", status)
-				return sendStatusReply(srv, status)
+				return ds.sendStatusReply(srv, status)
+			}
+
+			// the config of a block being in effect once the block is written,
+			// the client must be authorized by the config of a config block
+			// to receive it, as its authorization may have been revoked
+			if isConfigBlock(block) || time.Since(authorizedAt) >= ds.revalidationInterval {
+				if result, _ := sf.Apply(envelope); result != filter.Forward {
+					logger.Warningf("Client of chain %s is no longer authorized, terminating the deliver stream at block %d", payload.Header.ChannelHeader.ChannelId, block.Header.Number)
+					return ds.sendStatusReply(srv, cb.Status_FORBIDDEN)
+				}
+				authorizedAt = time.Now()
 			}
 
 			logger.Debugf("Delivering block")
-			if err := sendBlockReply(srv, block); err != nil {
+			if err := ds.sendBlockReply(srv, block, seekInfo.Compression); err != nil {
 				return err
 			}
 
@@ -146,22 +188,69 @@ func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
 			}
 		}
 
-		if err := sendStatusReply(srv, cb.Status_SUCCESS); err != nil {
+		if err := ds.sendStatusReply(srv, cb.Status_SUCCESS); err != nil {
 			return err
 		}
 		logger.Debugf("Done delivering, waiting for new SeekInfo")
 	}
 }
 
-func sendStatusReply(srv ab.AtomicBroadcast_DeliverServer, status cb.Status) error {
-	return srv.Send(&ab.DeliverResponse{
+// isConfigBlock returns whether block contains a config transaction
+func isConfigBlock(block *cb.Block) bool {
+	if block.Data == nil || len(block.Data.Data) != 1 {
+		return false
+	}
+	env, err := utils.UnmarshalEnvelope(block.Data.Data[0])
+	if err != nil {
+		return false
+	}
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil || payload.Header == nil || payload.Header.ChannelHeader == nil {
+		return false
+	}
+	return payload.Header.ChannelHeader.Type == int32(cb.HeaderType_CONFIG)
+}
+
+func (ds *deliverServer) sendStatusReply(srv ab.AtomicBroadcast_DeliverServer, status cb.Status) error {
+	return ds.send(srv, &ab.DeliverResponse{
 		Type: &ab.DeliverResponse_Status{Status: status},
 	})
 
 }
 
-func sendBlockReply(srv ab.AtomicBroadcast_DeliverServer, block *cb.Block) error {
-	return srv.Send(&ab.DeliverResponse{
-		Type: &ab.DeliverResponse_Block{Block: block},
+// sendBlockReply sends the block compressed with the compression requested by the
+// client, if any. Clients requesting compression must still accept plain blocks, as
+// orderers which do not support it ignore the request
+func (ds *deliverServer) sendBlockReply(srv ab.AtomicBroadcast_DeliverServer, block *cb.Block, compression ab.Compression) error {
+	if compression == ab.Compression_NONE {
+		return ds.send(srv, &ab.DeliverResponse{
+			Type: &ab.DeliverResponse_Block{Block: block},
+		})
+	}
+
+	compressed, err := utils.CompressBlock(block, compression)
+	if err != nil {
+		logger.Errorf("Error compressing block: %s", err)
+		return err
+	}
+	return ds.send(srv, &ab.DeliverResponse{
+		Type: &ab.DeliverResponse_CompressedBlock{CompressedBlock: compressed},
 	})
 }
+
+// send attaches an integrity proof to the response when the handler has a signer,
+// the proof is computed over the marshaled response without any integrity proof
+func (ds *deliverServer) send(srv ab.AtomicBroadcast_DeliverServer, response *ab.DeliverResponse) error {
+	if ds.signer != nil {
+		content, err := proto.Marshal(response)
+		if err != nil {
+			logger.Errorf("Error marshaling deliver response: %s", err)
+			return err
+		}
+		if response.Integrity, err = crypto.NewIntegrityProof(ds.signer, content); err != nil {
+			logger.Errorf("Error creating integrity proof of deliver response: %s", err)
+			return err
+		}
+	}
+	return srv.Send(response)
+}
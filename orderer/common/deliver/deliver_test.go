@@ -21,18 +21,22 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
 	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
 	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	"github.com/hyperledger/fabric/common/crypto"
 	mockconfigtxorderer "github.com/hyperledger/fabric/common/mocks/configtx/handlers/orderer"
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
 	"github.com/hyperledger/fabric/common/policies"
 	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
 	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	mockmultichain "github.com/hyperledger/fabric/orderer/mocks/multichain"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
 	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
 
@@ -42,6 +46,8 @@ var systemChainID = "systemChain"
 
 const ledgerSize = 10
 
+const testRevalidationInterval = 15 * time.Minute
+
 func init() {
 	logging.SetLevel(logging.DEBUG, "")
 	genesisBlock = provisional.New(genesisconfig.Load()).GenesisBlock()
@@ -51,15 +57,24 @@ type mockD struct {
 	grpc.ServerStream
 	recvChan chan *cb.Envelope
 	sendChan chan *ab.DeliverResponse
+	ctx      context.Context
+	cancel   context.CancelFunc
 }
 
 func newMockD() *mockD {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &mockD{
 		recvChan: make(chan *cb.Envelope),
 		sendChan: make(chan *ab.DeliverResponse),
+		ctx:      ctx,
+		cancel:   cancel,
 	}
 }
 
+func (m *mockD) Context() context.Context {
+	return m.ctx
+}
+
 func (m *mockD) Send(br *ab.DeliverResponse) error {
 	m.sendChan <- br
 	return nil
@@ -150,7 +165,7 @@ func TestOldestSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
 
 	go ds.Handle(m)
 
@@ -189,7 +204,7 @@ func TestNewestSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
 
 	go ds.Handle(m)
 
@@ -221,7 +236,7 @@ func TestSpecificSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
 	specifiedStart := uint64(3)
 	specifiedStop := uint64(7)
 
@@ -260,7 +275,7 @@ func TestUnauthorizedSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
 
 	go ds.Handle(m)
 
@@ -285,7 +300,7 @@ func TestBadSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
 
 	go ds.Handle(m)
 
@@ -310,7 +325,7 @@ func TestFailFastSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
 
 	go ds.Handle(m)
 
@@ -344,7 +359,7 @@ func TestBlockingSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
 
 	go ds.Handle(m)
 
@@ -386,3 +401,206 @@ func TestBlockingSeek(t *testing.T) {
 		t.Fatalf("Timed out waiting to get all blocks")
 	}
 }
+
+func TestBlockingSeekDisconnect(t *testing.T) {
+	mm := newMockMultichainManager()
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
+
+	done := make(chan error)
+	go func() {
+		done <- ds.Handle(m)
+	}()
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(1), Stop: seekSpecified(1), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+
+	select {
+	case <-m.sendChan:
+		t.Fatalf("Should not have delivered anything before block 1 is written")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	m.cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("Expected the handler to return the error of the cancelled stream, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Should have stopped waiting for block 1 once the client went away")
+	}
+}
+
+func TestIntegritySeek(t *testing.T) {
+	mm := newMockMultichainManager()
+
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImplWithIntegrity(mm, testRevalidationInterval, &mockmultichain.ConsenterSupport{})
+
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekOldest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case deliverReply := <-m.sendChan:
+			proof := deliverReply.Integrity
+			deliverReply.Integrity = nil
+			if _, err := crypto.IntegritySignedData(proof, utils.MarshalOrPanic(deliverReply)); err != nil {
+				t.Fatalf("Deliver response should have carried a valid integrity proof: %s", err)
+			}
+
+			tampered := proto.Clone(deliverReply).(*ab.DeliverResponse)
+			tampered.Type = &ab.DeliverResponse_Status{Status: cb.Status_FORBIDDEN}
+			if _, err := crypto.IntegritySignedData(proof, utils.MarshalOrPanic(tampered)); err == nil {
+				t.Fatalf("Integrity proof should not have matched a tampered response")
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for the deliver responses")
+		}
+	}
+}
+
+func TestCompressedSeek(t *testing.T) {
+	mm := newMockMultichainManager()
+	for i := 1; i < ledgerSize; i++ {
+		ledger := mm.chains[systemChainID].ledger
+		ledger.Append(ordererledger.CreateNextBlock(ledger, []*cb.Envelope{&cb.Envelope{Payload: []byte(fmt.Sprintf("%d", i))}}))
+	}
+
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
+
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, Compression: ab.Compression_SNAPPY})
+
+	count := uint64(0)
+	for {
+		select {
+		case deliverReply := <-m.sendChan:
+			if deliverReply.GetBlock() != nil {
+				t.Fatalf("Should have received compressed blocks")
+			}
+			if deliverReply.GetCompressedBlock() == nil {
+				if deliverReply.GetStatus() != cb.Status_SUCCESS {
+					t.Fatalf("Received an error on the reply channel")
+				}
+				if count != ledgerSize {
+					t.Fatalf("Expected %d blocks but got %d", ledgerSize, count)
+				}
+				return
+			}
+			block, err := utils.DecompressBlock(deliverReply.GetCompressedBlock())
+			if err != nil {
+				t.Fatalf("Error decompressing block: %s", err)
+			}
+			if block.Header.Number != count {
+				t.Fatalf("Expected block %d but got block %d", count, block.Header.Number)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting to get all blocks")
+		}
+		count++
+	}
+}
+
+func TestBadCompressionSeek(t *testing.T) {
+	mm := newMockMultichainManager()
+
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
+
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekOldest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, Compression: ab.Compression(42)})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		if deliverReply.GetStatus() != cb.Status_BAD_REQUEST {
+			t.Fatalf("Received wrong error on the reply channel")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get all blocks")
+	}
+}
+
+func TestRevalidateOnConfigBlock(t *testing.T) {
+	mm := newMockMultichainManager()
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImpl(mm, testRevalidationInterval)
+
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(1), Stop: seekSpecified(2), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+
+	select {
+	case <-m.sendChan:
+		t.Fatalf("Should not have delivered anything before block 1 is written")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// the client is no longer authorized, which is only noticed on the next config block
+	mm.chains[systemChainID].policyManager.Policy.Err = fmt.Errorf("Fail to evaluate policy")
+	ledger := mm.chains[systemChainID].ledger
+	ledger.Append(ordererledger.CreateNextBlock(ledger, []*cb.Envelope{&cb.Envelope{Payload: []byte("1")}}))
+
+	select {
+	case deliverReply := <-m.sendChan:
+		if deliverReply.GetBlock() == nil {
+			t.Fatalf("Expected to receive block 1")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get block 1")
+	}
+
+	configEnv, err := utils.ExtractEnvelope(genesisBlock, 0)
+	if err != nil {
+		t.Fatalf("Error extracting the config envelope of the genesis block: %s", err)
+	}
+	ledger.Append(ordererledger.CreateNextBlock(ledger, []*cb.Envelope{configEnv}))
+
+	select {
+	case deliverReply := <-m.sendChan:
+		if deliverReply.GetStatus() != cb.Status_FORBIDDEN {
+			t.Fatalf("Expected the stream to be terminated on the config block")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the stream to be terminated")
+	}
+}
+
+func TestRevalidatePeriodically(t *testing.T) {
+	mm := newMockMultichainManager()
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImpl(mm, 50*time.Millisecond)
+
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(1), Stop: seekSpecified(1), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+
+	select {
+	case <-m.sendChan:
+		t.Fatalf("Should not have delivered anything before block 1 is written")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	mm.chains[systemChainID].policyManager.Policy.Err = fmt.Errorf("Fail to evaluate policy")
+	ledger := mm.chains[systemChainID].ledger
+	ledger.Append(ordererledger.CreateNextBlock(ledger, []*cb.Envelope{&cb.Envelope{Payload: []byte("1")}}))
+
+	select {
+	case deliverReply := <-m.sendChan:
+		if deliverReply.GetStatus() != cb.Status_FORBIDDEN {
+			t.Fatalf("Expected the stream to be terminated once the authorization expired")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the stream to be terminated")
+	}
+}
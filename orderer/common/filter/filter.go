@@ -17,8 +17,7 @@ limitations under the License.
 package filter
 
 import (
-	"fmt"
-
+	cerrors "github.com/hyperledger/fabric/common/errors"
 	ab "github.com/hyperledger/fabric/protos/common"
 )
 
@@ -41,6 +40,13 @@ type Rule interface {
 	Apply(message *ab.Envelope) (Action, Committer)
 }
 
+// Classifier may be implemented by the rules to report the code and category of the errors
+// rejecting messages, the rejections of the other rules are MessageRejected validation errors
+type Classifier interface {
+	// RejectionCode returns the code and category of the errors of the messages the rule rejects
+	RejectionCode() (string, ab.ErrorCategory)
+}
+
 // Committer is returned by postfiltering and should be invoked once the message has been written to the blockchain
 type Committer interface {
 	// Commit performs whatever action should be performed upon commiting of a message
@@ -92,6 +98,7 @@ func NewRuleSet(rules []Rule) *RuleSet {
 }
 
 // Apply applies the rules given for this set in order, returning the committer, nil on valid, or nil, err on invalid
+// The error is a *cerrors.Error, classified by the rule rejecting the message
 func (rs *RuleSet) Apply(message *ab.Envelope) (Committer, error) {
 	for _, rule := range rs.rules {
 		action, committer := rule.Apply(message)
@@ -99,9 +106,13 @@ func (rs *RuleSet) Apply(message *ab.Envelope) (Committer, error) {
 		case Accept:
 			return committer, nil
 		case Reject:
-			return nil, fmt.Errorf("Rejected by rule: %T", rule)
+			code, category := cerrors.MessageRejected, ab.ErrorCategory_VALIDATION
+			if classifier, ok := rule.(Classifier); ok {
+				code, category = classifier.RejectionCode()
+			}
+			return nil, cerrors.New(category, code, "Rejected by rule: %T", rule)
 		default:
 		}
 	}
-	return nil, fmt.Errorf("No matching filter found")
+	return nil, cerrors.Validation(cerrors.MessageRejected, "No matching filter found")
 }
@@ -19,6 +19,7 @@ package filter
 import (
 	"testing"
 
+	cerrors "github.com/hyperledger/fabric/common/errors"
 	cb "github.com/hyperledger/fabric/protos/common"
 )
 
@@ -30,6 +31,18 @@ func (r rejectRule) Apply(message *cb.Envelope) (Action, Committer) {
 	return Reject, nil
 }
 
+var DenyRule = Rule(denyRule{})
+
+type denyRule struct{}
+
+func (r denyRule) Apply(message *cb.Envelope) (Action, Committer) {
+	return Reject, nil
+}
+
+func (r denyRule) RejectionCode() (string, cb.ErrorCategory) {
+	return cerrors.AccessDenied, cb.ErrorCategory_AUTHORIZATION
+}
+
 var ForwardRule = Rule(forwardRule{})
 
 type forwardRule struct{}
@@ -88,3 +101,15 @@ func TestNoRule(t *testing.T) {
 		t.Fatalf("Should have rejected")
 	}
 }
+
+func TestRejectionCode(t *testing.T) {
+	_, err := NewRuleSet([]Rule{RejectRule}).Apply(&cb.Envelope{})
+	if e, ok := err.(*cerrors.Error); !ok || e.Code != cerrors.MessageRejected || e.Category != cb.ErrorCategory_VALIDATION {
+		t.Fatalf("Should have been rejected as a MessageRejected validation error, got %#v", err)
+	}
+
+	_, err = NewRuleSet([]Rule{DenyRule}).Apply(&cb.Envelope{})
+	if e, ok := err.(*cerrors.Error); !ok || e.Code != cerrors.AccessDenied || e.Category != cb.ErrorCategory_AUTHORIZATION {
+		t.Fatalf("Should have been rejected with the code of the rule, got %#v", err)
+	}
+}
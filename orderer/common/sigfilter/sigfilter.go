@@ -17,6 +17,7 @@ limitations under the License.
 package sigfilter
 
 import (
+	cerrors "github.com/hyperledger/fabric/common/errors"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -43,6 +44,11 @@ func New(policySource func() []string, policyManager policies.Manager) filter.Ru
 	}
 }
 
+// RejectionCode reports the messages the filter rejects as denied access
+func (sf *sigFilter) RejectionCode() (string, cb.ErrorCategory) {
+	return cerrors.AccessDenied, cb.ErrorCategory_AUTHORIZATION
+}
+
 // Apply applies the policy given, resulting in Reject or Forward, never Accept and always with nil Committer
 func (sf *sigFilter) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
 	signedData, err := message.AsSignedData()
@@ -17,6 +17,7 @@ limitations under the License.
 package sizefilter
 
 import (
+	cerrors "github.com/hyperledger/fabric/common/errors"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	ab "github.com/hyperledger/fabric/protos/common"
 	logging "github.com/op/go-logging"
@@ -41,6 +42,11 @@ func (r *maxBytesRule) Apply(message *ab.Envelope) (filter.Action, filter.Commit
 	return filter.Forward, nil
 }
 
+// RejectionCode reports the messages the rule rejects as too large
+func (r *maxBytesRule) RejectionCode() (string, ab.ErrorCategory) {
+	return cerrors.RequestTooLarge, ab.ErrorCategory_VALIDATION
+}
+
 func messageByteSize(message *ab.Envelope) uint32 {
 	return uint32(len(message.Payload) + len(message.Signature))
 }
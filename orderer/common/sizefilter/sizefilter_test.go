@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/golang/protobuf/proto"
+	cerrors "github.com/hyperledger/fabric/common/errors"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	cb "github.com/hyperledger/fabric/protos/common"
 )
@@ -46,6 +47,9 @@ func TestMaxBytesRule(t *testing.T) {
 		if err == nil {
 			t.Fatalf("Should have rejected")
 		}
+		if e, ok := err.(*cerrors.Error); !ok || e.Code != cerrors.RequestTooLarge {
+			t.Fatalf("Should have been rejected as too large, got %#v", err)
+		}
 	})
 }
 
@@ -142,7 +142,7 @@ type chainImpl struct {
 	support   multichain.ConsenterSupport
 
 	partition           ChainPartition
-	batchTimeout        time.Duration
+	batchTimeout        time.Duration // of the last batch, config updates changing it apply to the next batch
 	lastOffsetPersisted int64
 	lastCutBlock        uint64
 
@@ -273,7 +273,7 @@ func (ch *chainImpl) loop() {
 				batches, committers, ok := ch.support.BlockCutter().Ordered(env)
 				logger.Debugf("Ordering results: batches: %v, ok: %v", batches, ok)
 				if ok && len(batches) == 0 && timer == nil {
-					timer = time.After(ch.batchTimeout)
+					timer = time.After(ch.nextBatchTimeout())
 					logger.Debugf("Just began %s batch timer", ch.batchTimeout.String())
 					continue
 				}
@@ -303,6 +303,16 @@ func (ch *chainImpl) loop() {
 	}
 }
 
+// nextBatchTimeout returns the batch timeout of the current config of the chain,
+// logging it when a config update has changed it since the last batch
+func (ch *chainImpl) nextBatchTimeout() time.Duration {
+	if batchTimeout := ch.support.SharedConfig().BatchTimeout(); batchTimeout != ch.batchTimeout {
+		logger.Infof("Batch timeout of chain %s changed from %s to %s", ch.support.ChainID(), ch.batchTimeout, batchTimeout)
+		ch.batchTimeout = batchTimeout
+	}
+	return ch.batchTimeout
+}
+
 // Closeable allows the shut down of the calling resource.
 type Closeable interface {
 	Close() error
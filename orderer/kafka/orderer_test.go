@@ -245,9 +245,9 @@ func TestKafkaConsenterTimerHaltOnFilledBatch(t *testing.T) {
 		t.Fatal("Expected block to be cut because batch timer expired")
 	}
 
-	// Change the batch timeout to be near instant.
+	// Change the batch timeout to be near instant, as a config update would.
 	// If the timer was not reset, it will still be waiting an hour.
-	ch.batchTimeout = time.Millisecond
+	cs.SharedConfigVal.BatchTimeoutVal = time.Millisecond
 
 	cs.BlockCutterVal.CutNext = false
 
@@ -254,3 +254,46 @@ func testMultichain(lf ledgerTestFactory, t *testing.T) {
 		t.Fatalf("Did not properly store block 1 on chain 1")
 	}
 }
+
+func TestRemove(t *testing.T) {
+	allTest(t, testRemove)
+}
+
+func testRemove(lf ledgerTestFactory, t *testing.T) {
+	f, _ := lf.New()
+	chainID := "removed"
+
+	if err := f.Remove(chainID); err == nil {
+		t.Fatalf("Removing a chain which does not exist should have failed")
+	}
+
+	c, err := f.GetOrCreate(chainID)
+	if err != nil {
+		t.Fatalf("Error creating chain: %s", err)
+	}
+	c.Append(CreateNextBlock(c, []*cb.Envelope{&cb.Envelope{Payload: []byte("My Data")}}))
+
+	if err = f.Remove(chainID); err != nil {
+		t.Fatalf("Error removing chain: %s", err)
+	}
+	for _, id := range f.ChainIDs() {
+		if id == chainID {
+			t.Fatalf("Removed chain should not be listed")
+		}
+	}
+
+	c, err = f.GetOrCreate(chainID)
+	if err != nil {
+		t.Fatalf("Error creating chain again: %s", err)
+	}
+	if c.Height() != 0 {
+		t.Fatalf("Chain created again should be empty, but has height %d", c.Height())
+	}
+
+	if lf.Persistent() {
+		f, _ = lf.New()
+		if c, _ = f.GetOrCreate(chainID); c.Height() != 0 {
+			t.Fatalf("Removed blocks should not have been restored")
+		}
+	}
+}
@@ -21,7 +21,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -42,6 +45,8 @@ func init() {
 const (
 	blockFileFormatString      = "block_%020d.json"
 	chainDirectoryFormatString = "chain_%s"
+	// tmpBlockFilePrefix prefixes a block file until it is completely written
+	tmpBlockFilePrefix = "tmp_"
 )
 
 type cursor struct {
@@ -56,6 +61,8 @@ type fileLedger struct {
 	signal         chan struct{}
 	lastHash       []byte
 	marshaler      *jsonpb.Marshaler
+	// size is the number of bytes of the block files, read atomically
+	size uint64
 }
 
 type fileLedgerFactory struct {
@@ -140,6 +147,26 @@ func (flf *fileLedgerFactory) GetOrCreate(chainID string) (ordererledger.ReadWri
 	return ch, nil
 }
 
+func (flf *fileLedgerFactory) Remove(chainID string) error {
+	flf.mutex.Lock()
+	defer flf.mutex.Unlock()
+
+	if _, ok := flf.ledgers[chainID]; !ok {
+		return fmt.Errorf("Chain %s does not exist", chainID)
+	}
+
+	directory := fmt.Sprintf("%s/"+chainDirectoryFormatString, flf.directory, chainID)
+
+	logger.Debugf("Removing chain at '%s'", directory)
+
+	if err := os.RemoveAll(directory); err != nil {
+		return err
+	}
+
+	delete(flf.ledgers, chainID)
+	return nil
+}
+
 // newChain creates a new chain backed by a file ledger
 func newChain(directory string) ordererledger.ReadWriter {
 	fl := &fileLedger{
@@ -164,6 +191,14 @@ func (fl *fileLedger) initializeBlockHeight() {
 		if info.IsDir() {
 			continue
 		}
+		if strings.HasPrefix(info.Name(), tmpBlockFilePrefix) {
+			// the orderer stopped while writing this block, which was therefore never appended
+			logger.Warningf("Removing the partially written block file %s", info.Name())
+			if err := os.Remove(filepath.Join(fl.directory, info.Name())); err != nil {
+				panic(err)
+			}
+			continue
+		}
 		var number uint64
 		_, err := fmt.Sscanf(info.Name(), blockFileFormatString, &number)
 		if err != nil {
@@ -172,6 +207,7 @@ func (fl *fileLedger) initializeBlockHeight() {
 		if number != nextNumber {
 			panic(fmt.Errorf("Missing block %d in the chain", nextNumber))
 		}
+		fl.size += uint64(info.Size())
 		nextNumber++
 	}
 	fl.height = nextNumber
@@ -193,19 +229,32 @@ func (fl *fileLedger) blockFilename(number uint64) string {
 	return fmt.Sprintf(fl.fqFormatString, number)
 }
 
-// writeBlock commits a block to disk
-func (fl *fileLedger) writeBlock(block *cb.Block) {
-	file, err := os.Create(fl.blockFilename(block.Header.Number))
+// writeBlock commits a block to disk, it returns the size of the block file. The block is written
+// to a temporary file which is synced and then renamed, so that a block file is never partially written
+func (fl *fileLedger) writeBlock(block *cb.Block) uint64 {
+	filename := fl.blockFilename(block.Header.Number)
+	tmpFilename := filepath.Join(fl.directory, tmpBlockFilePrefix+filepath.Base(filename))
+	file, err := os.Create(tmpFilename)
 	if err != nil {
 		panic(err)
 	}
 	defer file.Close()
 	err = fl.marshaler.Marshal(file, block)
-	logger.Debugf("Wrote block %d", block.Header.Number)
 	if err != nil {
 		panic(err)
 	}
-
+	if err = file.Sync(); err != nil {
+		panic(err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		panic(err)
+	}
+	if err = os.Rename(tmpFilename, filename); err != nil {
+		panic(err)
+	}
+	logger.Debugf("Wrote block %d", block.Header.Number)
+	return uint64(info.Size())
 }
 
 // readBlock returns the block or nil, and whether the block was found or not, (nil,true) generally indicates an irrecoverable problem
@@ -239,7 +288,7 @@ func (fl *fileLedger) Append(block *cb.Block) error {
 		return fmt.Errorf("Block should have had previous hash of %x but was %x", fl.lastHash, block.Header.PreviousHash)
 	}
 
-	fl.writeBlock(block)
+	atomic.AddUint64(&fl.size, fl.writeBlock(block))
 	fl.lastHash = block.Header.Hash()
 	fl.height++
 	close(fl.signal)
@@ -247,6 +296,11 @@ func (fl *fileLedger) Append(block *cb.Block) error {
 	return nil
 }
 
+// Size implements the ordererledger.SizeReader definition
+func (fl *fileLedger) Size() uint64 {
+	return atomic.LoadUint64(&fl.size)
+}
+
 // Iterator implements the ordererledger.Reader definition
 func (fl *fileLedger) Iterator(startPosition *ab.SeekPosition) (ordererledger.Iterator, uint64) {
 	switch start := startPosition.Type.(type) {
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
@@ -106,6 +107,30 @@ func TestReinitialization(t *testing.T) {
 	if !bytes.Equal(block.Header.Hash(), fl.lastHash) {
 		t.Fatalf("Block hashes did no match")
 	}
+	if fl.Size() == 0 || fl.Size() != ofl.Size() {
+		t.Fatalf("Size should have been recovered as %d but was %d", ofl.Size(), fl.Size())
+	}
+}
+
+func TestReinitializationWithPartialBlock(t *testing.T) {
+	tev, ofl := initialize(t)
+	defer tev.tearDown()
+	// a block file left partially written when the orderer stopped
+	partial := filepath.Join(ofl.directory, tmpBlockFilePrefix+filepath.Base(ofl.blockFilename(1)))
+	if err := ioutil.WriteFile(partial, []byte("{\"header\":"), 0644); err != nil {
+		t.Fatalf("Error writing the partial block file: %s", err)
+	}
+
+	fl := newChain(ofl.directory).(*fileLedger)
+	if fl.height != 1 {
+		t.Fatalf("Block height should be 1 but was %d", fl.height)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Fatalf("Should have removed the partial block file")
+	}
+	if err := fl.Append(ordererledger.CreateNextBlock(fl, []*cb.Envelope{&cb.Envelope{Payload: []byte("My Data")}})); err != nil {
+		t.Fatalf("Should have appended block 1: %s", err)
+	}
 }
 
 func TestMultiReinitialization(t *testing.T) {
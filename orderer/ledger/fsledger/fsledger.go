@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 
 	"github.com/hyperledger/fabric/protos/common"
+	"golang.org/x/net/context"
 )
 
 const (
@@ -45,10 +46,11 @@ func (l *fsLedger) GetBlockByNumber(blockNumber uint64) (*common.Block, error) {
 }
 
 // GetBlocksIterator returns an iterator that starts from `startBlockNumber`(inclusive).
-// The iterator is a blocking iterator i.e., it blocks till the next block gets available in the ledger
+// The iterator is a blocking iterator i.e., it blocks till the next block gets available in the ledger,
+// or till ctx is done
 // ResultsIterator contains type BlockHolder
-func (l *fsLedger) GetBlocksIterator(startBlockNumber uint64) (ledger.ResultsIterator, error) {
-	return l.blockStore.RetrieveBlocks(startBlockNumber)
+func (l *fsLedger) GetBlocksIterator(ctx context.Context, startBlockNumber uint64) (ledger.ResultsIterator, error) {
+	return l.blockStore.RetrieveBlocks(ctx, startBlockNumber)
 }
 
 //Prune prunes the blocks/transactions that satisfy the given policy
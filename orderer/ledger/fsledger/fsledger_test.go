@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"golang.org/x/net/context"
 )
 
 const (
@@ -57,7 +58,7 @@ func TestOrdererLedger(t *testing.T) {
 	testutil.AssertEquals(t, block, blocks[1])
 
 	// get blocks iterator for block number starting from 3
-	itr, err := ordererLedger.GetBlocksIterator(3)
+	itr, err := ordererLedger.GetBlocksIterator(context.Background(), 3)
 	testutil.AssertNoError(t, err, "Error in getting iterator")
 	blockHolder, err := itr.Next()
 	testutil.AssertNoError(t, err, "")
@@ -30,6 +30,9 @@ type Factory interface {
 
 	// ChainIDs returns the chain IDs the Factory is aware of
 	ChainIDs() []string
+
+	// Remove removes the ledger of a chain, along with all of its blocks
+	Remove(chainID string) error
 }
 
 // Iterator is useful for a chain Reader to stream blocks as they are created
@@ -60,6 +63,12 @@ type ReadWriter interface {
 	Writer
 }
 
+// SizeReader is implemented by the ledgers which store their blocks on disk
+type SizeReader interface {
+	// Size returns the number of bytes the blocks of the ledger occupy on disk
+	Size() uint64
+}
+
 // CreateNextBlock provides a utility way to construct the next block from contents and metadata for a given ledger
 // XXX this will need to be modified to accept marshaled envelopes to accomodate non-deterministic marshaling
 func CreateNextBlock(rl Reader, messages []*cb.Envelope) *cb.Block {
@@ -0,0 +1,223 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"sync"
+	"sync/atomic"
+
+	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/ledger/quota")
+
+// Config bounds the disk space used by the blocks of every chain
+type Config struct {
+	// MaxBytes is the quota of the chains not listed in Channels, 0 for no quota
+	MaxBytes uint64
+
+	// AlertThreshold is the percentage of its quota above which a chain raises an alert
+	AlertThreshold uint32
+
+	// HardStop rejects the broadcasts for the chains exceeding their quota
+	HardStop bool
+
+	// Channels are the quotas of specific chains, 0 for no quota
+	Channels map[string]uint64
+}
+
+// maxBytes returns the quota of chainID
+func (conf *Config) maxBytes(chainID string) uint64 {
+	if max, ok := conf.Channels[chainID]; ok {
+		return max
+	}
+	return conf.MaxBytes
+}
+
+// Usage shows the disk space used by the blocks of a chain
+type Usage struct {
+	// Bytes is the number of bytes the blocks of the chain occupy
+	Bytes uint64
+
+	// MaxBytes is the quota of the chain
+	MaxBytes uint64
+
+	// Alerts is the number of alerts raised for the chain since the orderer started
+	Alerts uint64
+
+	// Exceeded is set when the broadcasts for the chain are rejected
+	Exceeded bool
+}
+
+// Ledger is a ledger bounded by a quota
+type Ledger interface {
+	ordererledger.ReadWriter
+
+	// Exceeded returns whether the ledger exceeds its quota with a hard stop, in which case
+	// the broadcasts for its chain should be rejected
+	Exceeded() bool
+
+	// Usage returns the disk space used by the ledger
+	Usage() Usage
+}
+
+// usage levels of a ledger, raising an alert when they go up
+const (
+	belowThreshold int32 = iota
+	aboveThreshold
+	aboveQuota
+)
+
+type quotaLedger struct {
+	ordererledger.ReadWriter
+	sizer      ordererledger.SizeReader
+	chainID    string
+	maxBytes   uint64
+	alertBytes uint64
+	hardStop   bool
+
+	level  int32
+	alerts uint64
+}
+
+// Append appends the block even if the ledger exceeds its quota, as the block was ordered,
+// and raises an alert if the ledger reaches a higher usage level
+func (ql *quotaLedger) Append(block *cb.Block) error {
+	if err := ql.ReadWriter.Append(block); err != nil {
+		return err
+	}
+	ql.checkUsage()
+	return nil
+}
+
+func (ql *quotaLedger) checkUsage() {
+	size := ql.sizer.Size()
+	level := belowThreshold
+	switch {
+	case size > ql.maxBytes:
+		level = aboveQuota
+	case size >= ql.alertBytes:
+		level = aboveThreshold
+	}
+	prev := atomic.LoadInt32(&ql.level)
+	if level <= prev || !atomic.CompareAndSwapInt32(&ql.level, prev, level) {
+		return
+	}
+	atomic.AddUint64(&ql.alerts, 1)
+	if level == aboveThreshold {
+		logger.Warningf("Chain %s uses %d bytes, above the alert threshold of its quota of %d bytes", ql.chainID, size, ql.maxBytes)
+		return
+	}
+	if ql.hardStop {
+		logger.Errorf("Chain %s uses %d bytes and exceeds its quota of %d bytes, rejecting its broadcasts", ql.chainID, size, ql.maxBytes)
+	} else {
+		logger.Errorf("Chain %s uses %d bytes and exceeds its quota of %d bytes", ql.chainID, size, ql.maxBytes)
+	}
+}
+
+// Exceeded implements the Ledger definition
+func (ql *quotaLedger) Exceeded() bool {
+	return ql.hardStop && atomic.LoadInt32(&ql.level) == aboveQuota
+}
+
+// Usage implements the Ledger definition
+func (ql *quotaLedger) Usage() Usage {
+	return Usage{
+		Bytes:    ql.sizer.Size(),
+		MaxBytes: ql.maxBytes,
+		Alerts:   atomic.LoadUint64(&ql.alerts),
+		Exceeded: ql.Exceeded(),
+	}
+}
+
+// Factory is an ordererledger.Factory bounding the ledgers of another factory with quotas
+type Factory struct {
+	ordererledger.Factory
+	conf    Config
+	mutex   sync.Mutex
+	ledgers map[string]Ledger
+}
+
+// NewFactory bounds the ledgers of lf which report their size with the quotas of conf
+func NewFactory(lf ordererledger.Factory, conf Config) *Factory {
+	return &Factory{
+		Factory: lf,
+		conf:    conf,
+		ledgers: make(map[string]Ledger),
+	}
+}
+
+// GetOrCreate implements the ordererledger.Factory definition
+func (f *Factory) GetOrCreate(chainID string) (ordererledger.ReadWriter, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if ql, ok := f.ledgers[chainID]; ok {
+		return ql, nil
+	}
+	rw, err := f.Factory.GetOrCreate(chainID)
+	if err != nil {
+		return nil, err
+	}
+	maxBytes := f.conf.maxBytes(chainID)
+	if maxBytes == 0 {
+		return rw, nil
+	}
+	sizer, ok := rw.(ordererledger.SizeReader)
+	if !ok {
+		logger.Warningf("The ledger of chain %s does not report its size, its quota is ignored", chainID)
+		return rw, nil
+	}
+	threshold := uint64(f.conf.AlertThreshold)
+	if threshold == 0 || threshold > 100 {
+		threshold = 100
+	}
+	ql := &quotaLedger{
+		ReadWriter: rw,
+		sizer:      sizer,
+		chainID:    chainID,
+		maxBytes:   maxBytes,
+		alertBytes: maxBytes/100*threshold + maxBytes%100*threshold/100,
+		hardStop:   f.conf.HardStop,
+	}
+	ql.checkUsage()
+	f.ledgers[chainID] = ql
+	return ql, nil
+}
+
+// Remove implements the ordererledger.Factory definition
+func (f *Factory) Remove(chainID string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.Factory.Remove(chainID); err != nil {
+		return err
+	}
+	delete(f.ledgers, chainID)
+	return nil
+}
+
+// Usages returns the usage of the chains with a quota
+func (f *Factory) Usages() map[string]Usage {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	usages := make(map[string]Usage, len(f.ledgers))
+	for chainID, ql := range f.ledgers {
+		usages[chainID] = ql.Usage()
+	}
+	return usages
+}
@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+
+	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// sizedLedger grows by blockSize bytes for every appended block
+type sizedLedger struct {
+	ordererledger.ReadWriter
+	size uint64
+}
+
+const blockSize = 10
+
+func (sl *sizedLedger) Append(block *cb.Block) error {
+	sl.size += blockSize
+	return sl.ReadWriter.Append(block)
+}
+
+func (sl *sizedLedger) Size() uint64 {
+	return sl.size
+}
+
+type sizedFactory struct {
+	ordererledger.Factory
+}
+
+func (sf *sizedFactory) GetOrCreate(chainID string) (ordererledger.ReadWriter, error) {
+	rw, err := sf.Factory.GetOrCreate(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return &sizedLedger{ReadWriter: rw}, nil
+}
+
+func appendBlocks(t *testing.T, rw ordererledger.ReadWriter, count int) {
+	for i := 0; i < count; i++ {
+		block := ordererledger.CreateNextBlock(rw, []*cb.Envelope{{Payload: []byte("foo")}})
+		assert.NoError(t, rw.Append(block))
+	}
+}
+
+func TestQuota(t *testing.T) {
+	f := NewFactory(&sizedFactory{ramledger.New(10)}, Config{MaxBytes: 50, AlertThreshold: 60, HardStop: true})
+	rw, err := f.GetOrCreate("foo")
+	assert.NoError(t, err)
+	ql, ok := rw.(Ledger)
+	assert.True(t, ok, "The ledger should be bounded by the quota")
+	same, _ := f.GetOrCreate("foo")
+	assert.Equal(t, rw, same)
+
+	appendBlocks(t, rw, 5)
+	assert.Equal(t, Usage{Bytes: 50, MaxBytes: 50, Alerts: 1}, ql.Usage(), "Crossing the threshold should raise one alert")
+	assert.False(t, ql.Exceeded())
+
+	// the blocks already ordered are written past the quota
+	appendBlocks(t, rw, 2)
+	assert.Equal(t, Usage{Bytes: 70, MaxBytes: 50, Alerts: 2, Exceeded: true}, ql.Usage())
+	assert.True(t, ql.Exceeded())
+	assert.Equal(t, map[string]Usage{"foo": ql.Usage()}, f.Usages())
+}
+
+func TestQuotaWithoutHardStop(t *testing.T) {
+	f := NewFactory(&sizedFactory{ramledger.New(10)}, Config{MaxBytes: 10, AlertThreshold: 80})
+	rw, _ := f.GetOrCreate("foo")
+	appendBlocks(t, rw, 2)
+	assert.Equal(t, Usage{Bytes: 20, MaxBytes: 10, Alerts: 2}, rw.(Ledger).Usage())
+	assert.False(t, rw.(Ledger).Exceeded(), "The broadcasts should only be rejected with a hard stop")
+}
+
+func TestChannelQuotas(t *testing.T) {
+	f := NewFactory(&sizedFactory{ramledger.New(10)}, Config{MaxBytes: 10, Channels: map[string]uint64{"bar": 0, "baz": 100}})
+	bar, _ := f.GetOrCreate("bar")
+	_, ok := bar.(Ledger)
+	assert.False(t, ok, "A chain with a zero quota should not be bounded")
+	baz, _ := f.GetOrCreate("baz")
+	assert.Equal(t, uint64(100), baz.(Ledger).Usage().MaxBytes)
+
+	unsized := NewFactory(ramledger.New(10), Config{MaxBytes: 10})
+	rw, _ := unsized.GetOrCreate("foo")
+	_, ok = rw.(Ledger)
+	assert.False(t, ok, "A ledger which does not report its size cannot be bounded")
+}
@@ -93,6 +93,18 @@ func (rlf *ramLedgerFactory) ChainIDs() []string {
 	return ids
 }
 
+func (rlf *ramLedgerFactory) Remove(chainID string) error {
+	rlf.mutex.Lock()
+	defer rlf.mutex.Unlock()
+
+	if _, ok := rlf.ledgers[chainID]; !ok {
+		return fmt.Errorf("Chain %s does not exist", chainID)
+	}
+
+	delete(rlf.ledgers, chainID)
+	return nil
+}
+
 // newChain creates a new instance of the ram ledger for a chain
 func newChain(maxSize int) ordererledger.ReadWriter {
 	preGenesis := &cb.Block{
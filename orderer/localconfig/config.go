@@ -49,10 +49,106 @@ type General struct {
 	TLS           TLS
 	GenesisMethod string
 	GenesisFile   string
+	GenesisRemote GenesisRemote
 	Profile       Profile
 	LogLevel      string
 	LocalMSPDir   string
 	LocalMSPID    string
+	// BCCSP is the crypto provider of the orderer, SW or GM
+	BCCSP string
+	// DeliverIntegrity attaches a signed integrity proof to every deliver response
+	DeliverIntegrity bool
+	// DeliverRevalidationInterval is the time after which the authorization of a
+	// deliver client is evaluated again, along with the next block sent to it
+	DeliverRevalidationInterval time.Duration
+	// IngressValidators is the number of goroutines validating broadcast messages,
+	// if 0 the messages are validated by the goroutine servicing their connection
+	IngressValidators int
+	// ShutdownTimeout bounds the time the orderer waits, once signaled to stop, for the
+	// broadcast messages and the calls in flight to complete
+	ShutdownTimeout time.Duration
+	// ChunkedBroadcast bounds the size of the envelopes broadcast in chunks
+	ChunkedBroadcast ChunkedBroadcast
+	// BroadcastFilters are the ordered admission filters of the broadcast messages
+	BroadcastFilters BroadcastFilters
+	// BroadcastAudit records the rejected broadcast messages
+	BroadcastAudit BroadcastAudit
+	Admin          Admin
+	WebSocket      WebSocket
+	Cluster        Cluster
+}
+
+// ChunkedBroadcast contains config for the envelopes broadcast in chunks, which may exceed
+// the maximum size of a gRPC message
+type ChunkedBroadcast struct {
+	MaxBytes uint64
+	Channels map[string]uint64
+}
+
+// BroadcastFilters contains the names of the filters applied, in order, to the broadcast
+// messages of the chains. Each pipeline must include the built in EmptyReject, MaxBytes
+// and Signature filters, along with the filters registered by the orderer plugins
+type BroadcastFilters struct {
+	Default  []string
+	Channels map[string][]string
+}
+
+// BroadcastAudit contains config for the recording of the rejected broadcast messages, which
+// are all logged and counted, Size being the number of the latest ones retained to be queried
+// through the admin service
+type BroadcastAudit struct {
+	Size int
+}
+
+// Admin contains config for the admin service of the orderer, which is served
+// on its own listener to the clients authenticating with a TLS certificate
+type Admin struct {
+	Enabled       bool
+	ListenAddress string
+	ListenPort    uint16
+	TLS           TLS
+}
+
+// WebSocket contains config for the WebSocket gateway to the Broadcast and Deliver
+// services of the orderer, which is served on its own listener and relays the
+// signed messages of the clients to the gRPC services as they are
+type WebSocket struct {
+	Enabled        bool
+	ListenAddress  string
+	ListenPort     uint16
+	AllowedOrigins []string
+	TLS            TLS
+	// ServerName is the name the TLS certificate of the orderer is verified
+	// against when the gateway connects to it, the host it connects to if empty
+	ServerName string
+	// ClientCertificate and ClientPrivateKey are the key pair the gateway
+	// presents to the orderer when it requires TLS client certificates
+	ClientCertificate string
+	ClientPrivateKey  string
+}
+
+// Cluster contains config for the listener of the intra-cluster traffic, the
+// SBFT consensus traffic between the orderers, which is served apart from the
+// clients with its own address and TLS settings. TLS is always used on it, its
+// certificate being the one the other orderers pin in the SbftShared peers.
+// Broadcast and Deliver are only served to the clients
+type Cluster struct {
+	Enabled       bool
+	ListenAddress string
+	ListenPort    uint16
+	TLS           TLS
+}
+
+// GenesisRemote contains config for fetching the genesis block from a remote
+// bootstrap service, used when the GenesisMethod is "url" or "admin"
+type GenesisRemote struct {
+	URL          string
+	AdminAddress string
+	ChainID      string
+	Hash         string
+	TLS          TLS
+	Timeout      time.Duration
+	Retry        Retry
 }
 
 //TLS contains config used to configure TLS
@@ -90,6 +186,15 @@ type RAMLedger struct {
 type FileLedger struct {
 	Location string
 	Prefix   string
+	Quota    Quota
+}
+
+// Quota contains config for the storage quotas of the chains
+type Quota struct {
+	MaxBytes       uint64
+	AlertThreshold uint32
+	HardStop       bool
+	Channels       map[string]uint64
 }
 
 // Kafka contains config for the Kafka orderer
@@ -150,13 +255,46 @@ var defaults = TopLevel{
 		ListenPort:    7050,
 		GenesisMethod: "provisional",
 		GenesisFile:   "./genesisblock",
+		GenesisRemote: GenesisRemote{
+			Timeout: 10 * time.Second,
+			Retry: Retry{
+				Period: 5 * time.Second,
+				Stop:   5 * time.Minute,
+			},
+		},
 		Profile: Profile{
 			Enabled: false,
 			Address: "0.0.0.0:6060",
 		},
-		LogLevel:    "INFO",
-		LocalMSPDir: "../msp/sampleconfig/",
-		LocalMSPID:  "DEFAULT",
+		LogLevel:                    "INFO",
+		LocalMSPDir:                 "../msp/sampleconfig/",
+		LocalMSPID:                  "DEFAULT",
+		BCCSP:                       "SW",
+		DeliverIntegrity:            false,
+		DeliverRevalidationInterval: 15 * time.Minute,
+		IngressValidators:           0,
+		ShutdownTimeout:             30 * time.Second,
+		ChunkedBroadcast: ChunkedBroadcast{
+			MaxBytes: 100 * 1024 * 1024,
+		},
+		BroadcastAudit: BroadcastAudit{
+			Size: 1000,
+		},
+		Admin: Admin{
+			Enabled:       false,
+			ListenAddress: "127.0.0.1",
+			ListenPort:    9443,
+		},
+		WebSocket: WebSocket{
+			Enabled:       false,
+			ListenAddress: "127.0.0.1",
+			ListenPort:    7055,
+		},
+		Cluster: Cluster{
+			Enabled:       false,
+			ListenAddress: "127.0.0.1",
+			ListenPort:    7060,
+		},
 	},
 	RAMLedger: RAMLedger{
 		HistorySize: 10000,
@@ -164,6 +302,11 @@ var defaults = TopLevel{
 	FileLedger: FileLedger{
 		Location: "",
 		Prefix:   "hyperledger-fabric-ordererledger",
+		Quota: Quota{
+			MaxBytes:       0,
+			AlertThreshold: 80,
+			HardStop:       false,
+		},
 	},
 	Kafka: Kafka{
 		Retry: Retry{
@@ -215,16 +358,72 @@ func (c *TopLevel) completeInitialization() {
 		case c.General.LogLevel == "":
 			logger.Infof("General.LogLevel unset, setting to %s", defaults.General.LogLevel)
 			c.General.LogLevel = defaults.General.LogLevel
+		case c.General.BCCSP == "":
+			logger.Infof("General.BCCSP unset, setting to %s", defaults.General.BCCSP)
+			c.General.BCCSP = defaults.General.BCCSP
+		case c.General.DeliverRevalidationInterval <= 0:
+			logger.Infof("General.DeliverRevalidationInterval unset, setting to %s", defaults.General.DeliverRevalidationInterval)
+			c.General.DeliverRevalidationInterval = defaults.General.DeliverRevalidationInterval
+		case c.General.ShutdownTimeout == 0:
+			logger.Infof("General.ShutdownTimeout unset, setting to %s", defaults.General.ShutdownTimeout)
+			c.General.ShutdownTimeout = defaults.General.ShutdownTimeout
 		case c.General.GenesisMethod == "":
 			c.General.GenesisMethod = defaults.General.GenesisMethod
 		case c.General.GenesisFile == "":
 			c.General.GenesisFile = defaults.General.GenesisFile
+		case c.General.GenesisMethod == "url" && c.General.GenesisRemote.URL == "":
+			logger.Panicf("General.GenesisRemote.URL must be set if General.GenesisMethod is url.")
+		case c.General.GenesisMethod == "admin" && c.General.GenesisRemote.AdminAddress == "":
+			logger.Panicf("General.GenesisRemote.AdminAddress must be set if General.GenesisMethod is admin.")
+		case (c.General.GenesisMethod == "url" || c.General.GenesisMethod == "admin") && c.General.GenesisRemote.Hash == "":
+			logger.Panicf("General.GenesisRemote.Hash must be set if General.GenesisMethod is %s.", c.General.GenesisMethod)
+		case c.General.GenesisRemote.Timeout == 0:
+			logger.Infof("General.GenesisRemote.Timeout unset, setting to %v", defaults.General.GenesisRemote.Timeout)
+			c.General.GenesisRemote.Timeout = defaults.General.GenesisRemote.Timeout
+		case c.General.GenesisRemote.Retry.Period == 0:
+			logger.Infof("General.GenesisRemote.Retry.Period unset, setting to %v", defaults.General.GenesisRemote.Retry.Period)
+			c.General.GenesisRemote.Retry.Period = defaults.General.GenesisRemote.Retry.Period
+		case c.General.GenesisRemote.Retry.Stop == 0:
+			logger.Infof("General.GenesisRemote.Retry.Stop unset, setting to %v", defaults.General.GenesisRemote.Retry.Stop)
+			c.General.GenesisRemote.Retry.Stop = defaults.General.GenesisRemote.Retry.Stop
 		case c.Kafka.TLS.Enabled && c.Kafka.TLS.Certificate == "":
 			logger.Panicf("General.Kafka.TLS.Certificate must be set if General.Kafka.TLS.Enabled is set to true.")
 		case c.Kafka.TLS.Enabled && c.Kafka.TLS.PrivateKey == "":
 			logger.Panicf("General.Kafka.TLS.PrivateKey must be set if General.Kafka.TLS.Enabled is set to true.")
 		case c.Kafka.TLS.Enabled && c.Kafka.TLS.RootCAs == nil:
 			logger.Panicf("General.Kafka.TLS.CertificatePool must be set if General.Kafka.TLS.Enabled is set to true.")
+		case c.General.Admin.Enabled && c.General.Admin.ListenAddress == "":
+			logger.Infof("General.Admin.ListenAddress unset, setting to %s", defaults.General.Admin.ListenAddress)
+			c.General.Admin.ListenAddress = defaults.General.Admin.ListenAddress
+		case c.General.Admin.Enabled && c.General.Admin.ListenPort == 0:
+			logger.Infof("General.Admin.ListenPort unset, setting to %d", defaults.General.Admin.ListenPort)
+			c.General.Admin.ListenPort = defaults.General.Admin.ListenPort
+		case c.General.Admin.Enabled && (c.General.Admin.TLS.Certificate == "" || c.General.Admin.TLS.PrivateKey == ""):
+			logger.Panicf("General.Admin.TLS.Certificate and General.Admin.TLS.PrivateKey must be set if General.Admin.Enabled is set to true.")
+		case c.General.Admin.Enabled && len(c.General.Admin.TLS.ClientRootCAs) == 0:
+			logger.Panicf("General.Admin.TLS.ClientRootCAs must be set if General.Admin.Enabled is set to true.")
+		case c.General.WebSocket.Enabled && c.General.WebSocket.ListenAddress == "":
+			logger.Infof("General.WebSocket.ListenAddress unset, setting to %s", defaults.General.WebSocket.ListenAddress)
+			c.General.WebSocket.ListenAddress = defaults.General.WebSocket.ListenAddress
+		case c.General.WebSocket.Enabled && c.General.WebSocket.ListenPort == 0:
+			logger.Infof("General.WebSocket.ListenPort unset, setting to %d", defaults.General.WebSocket.ListenPort)
+			c.General.WebSocket.ListenPort = defaults.General.WebSocket.ListenPort
+		case c.General.WebSocket.TLS.Enabled && (c.General.WebSocket.TLS.Certificate == "" || c.General.WebSocket.TLS.PrivateKey == ""):
+			logger.Panicf("General.WebSocket.TLS.Certificate and General.WebSocket.TLS.PrivateKey must be set if General.WebSocket.TLS.Enabled is set to true.")
+		case c.General.WebSocket.Enabled && c.General.TLS.ClientAuthEnabled && (c.General.WebSocket.ClientCertificate == "" || c.General.WebSocket.ClientPrivateKey == ""):
+			logger.Panicf("General.WebSocket.ClientCertificate and General.WebSocket.ClientPrivateKey must be set if General.WebSocket.Enabled and General.TLS.ClientAuthEnabled are set to true.")
+		case c.General.Cluster.Enabled && c.General.Cluster.ListenAddress == "":
+			logger.Infof("General.Cluster.ListenAddress unset, setting to %s", defaults.General.Cluster.ListenAddress)
+			c.General.Cluster.ListenAddress = defaults.General.Cluster.ListenAddress
+		case c.General.Cluster.Enabled && c.General.Cluster.ListenPort == 0:
+			logger.Infof("General.Cluster.ListenPort unset, setting to %d", defaults.General.Cluster.ListenPort)
+			c.General.Cluster.ListenPort = defaults.General.Cluster.ListenPort
+		case c.General.Cluster.Enabled && c.General.Cluster.ListenAddress == c.General.ListenAddress && c.General.Cluster.ListenPort == c.General.ListenPort:
+			logger.Panicf("General.Cluster must listen on another address than General.ListenAddress and General.ListenPort.")
+		case c.General.Cluster.Enabled && (c.General.Cluster.TLS.Certificate == "" || c.General.Cluster.TLS.PrivateKey == ""):
+			logger.Panicf("General.Cluster.TLS.Certificate and General.Cluster.TLS.PrivateKey must be set if General.Cluster.Enabled is set to true.")
+		case c.General.Cluster.TLS.ClientAuthEnabled && len(c.General.Cluster.TLS.ClientRootCAs) == 0:
+			logger.Panicf("General.Cluster.TLS.ClientRootCAs must be set if General.Cluster.TLS.ClientAuthEnabled is set to true.")
 		case c.General.Profile.Enabled && (c.General.Profile.Address == ""):
 			logger.Infof("Profiling enabled and General.Profile.Address unset, setting to %s", defaults.General.Profile.Address)
 			c.General.Profile.Address = defaults.General.Profile.Address
@@ -240,6 +439,11 @@ func (c *TopLevel) completeInitialization() {
 		case c.FileLedger.Prefix == "":
 			logger.Infof("FileLedger.Prefix unset, setting to %s", defaults.FileLedger.Prefix)
 			c.FileLedger.Prefix = defaults.FileLedger.Prefix
+		case c.FileLedger.Quota.AlertThreshold == 0:
+			logger.Infof("FileLedger.Quota.AlertThreshold unset, setting to %d", defaults.FileLedger.Quota.AlertThreshold)
+			c.FileLedger.Quota.AlertThreshold = defaults.FileLedger.Quota.AlertThreshold
+		case c.FileLedger.Quota.AlertThreshold > 100:
+			logger.Panicf("FileLedger.Quota.AlertThreshold must be a percentage, not %d", c.FileLedger.Quota.AlertThreshold)
 		case c.Kafka.Retry.Period == 0*time.Second:
 			logger.Infof("Kafka.Retry.Period unset, setting to %v", defaults.Kafka.Retry.Period)
 			c.Kafka.Retry.Period = defaults.Kafka.Retry.Period
@@ -257,6 +461,15 @@ func (c *TopLevel) completeInitialization() {
 
 // Load parses the orderer.yaml file and environment, producing a struct suitable for config use
 func Load() *TopLevel {
+	conf, err := LoadConfig()
+	if err != nil {
+		panic(err)
+	}
+	return conf
+}
+
+// LoadConfig is Load returning the error of an invalid config rather than panicking
+func LoadConfig() (conf *TopLevel, err error) {
 	config := viper.New()
 
 	config.SetConfigName("orderer")
@@ -274,7 +487,7 @@ func Load() *TopLevel {
 			cfgPath = ordererPath
 		}
 		if cfgPath == "" {
-			logger.Fatalf("Could not find orderer.yaml, try setting ORDERER_CFG_PATH or GOPATH correctly")
+			return nil, fmt.Errorf("Could not find orderer.yaml, try setting ORDERER_CFG_PATH or GOPATH correctly")
 		}
 		logger.Infof("Setting ORDERER_CFG_PATH to: %s", cfgPath)
 		os.Setenv("ORDERER_CFG_PATH", cfgPath)
@@ -287,19 +500,30 @@ func Load() *TopLevel {
 	replacer := strings.NewReplacer(".", "_")
 	config.SetEnvKeyReplacer(replacer)
 
-	err := config.ReadInConfig()
+	err = config.ReadInConfig()
 	if err != nil {
-		panic(fmt.Errorf("Error reading %s plugin config: %s", Prefix, err))
+		return nil, fmt.Errorf("Error reading %s plugin config: %s", Prefix, err)
+	}
+
+	// Replace the secret references and the _FILE overrides by the secrets
+	if _, err = viperutil.ResolveSecrets(config, Prefix); err != nil {
+		return nil, fmt.Errorf("Error resolving the secrets of %s config: %s", Prefix, err)
 	}
 
 	var uconf TopLevel
 
 	err = viperutil.EnhancedExactUnmarshal(config, &uconf)
 	if err != nil {
-		panic(fmt.Errorf("Error unmarshaling into structure: %s", err))
+		return nil, fmt.Errorf("Error unmarshaling into structure: %s", err)
 	}
 
+	// completeInitialization panics on the settings which cannot be defaulted
+	defer func() {
+		if r := recover(); r != nil {
+			conf, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
 	uconf.completeInitialization()
 
-	return &uconf
+	return &uconf, nil
 }
@@ -104,3 +104,50 @@ func TestKafkaTLSConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestWebSocketClientCertificate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		webSocket   WebSocket
+		shouldPanic bool
+	}{
+		{"Disabled", WebSocket{}, false},
+		{"NoClientKeyPair", WebSocket{Enabled: true}, true},
+		{"NoClientPrivateKey", WebSocket{Enabled: true, ClientCertificate: "public.key"}, true},
+		{"ClientKeyPair", WebSocket{Enabled: true, ClientCertificate: "public.key", ClientPrivateKey: "private.key"}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uconf := &TopLevel{General: General{TLS: TLS{ClientAuthEnabled: true}, WebSocket: tc.webSocket}}
+			if tc.shouldPanic {
+				assert.Panics(t, func() { uconf.completeInitialization() }, "should panic")
+			} else {
+				assert.NotPanics(t, func() { uconf.completeInitialization() }, "should not panic")
+			}
+		})
+	}
+}
+
+func TestIsSetting(t *testing.T) {
+	assert.True(t, IsSetting("GENERAL_LISTENPORT"))
+	assert.True(t, IsSetting("KAFKA_TLS_ROOTCAS"))
+	assert.True(t, IsSetting("KAFKA_VERSION"))
+	assert.True(t, IsSetting("FILELEDGER_QUOTA_CHANNELS_MYCHANNEL"))
+	assert.False(t, IsSetting("GENERAL_LISTENPROT"))
+	assert.False(t, IsSetting("GENERAL"))
+}
+
+func TestValidate(t *testing.T) {
+	conf := &TopLevel{}
+	*conf = defaults
+	conf.General.LedgerType = "disk"
+	conf.General.GenesisMethod = "url"
+	conf.General.GenesisRemote.Hash = "zz"
+	conf.General.LocalMSPDir = "missing"
+	errors := conf.Validate()
+	assert.Len(t, errors, 3)
+
+	conf.General.TLS = TLS{Enabled: true, Certificate: "cert", PrivateKey: "key"}
+	assert.Equal(t, "REDACTED", conf.Redacted().General.TLS.PrivateKey)
+	assert.Equal(t, "key", conf.General.TLS.PrivateKey)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/common/configcheck"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// Validate checks the settings of the config and the files they reference, the
+// local MSP and the genesis block, and returns the errors found
+func (c *TopLevel) Validate() []string {
+	checker := &configcheck.Checker{}
+
+	checker.Address("General.ListenAddress", net.JoinHostPort(c.General.ListenAddress, strconv.Itoa(int(c.General.ListenPort))))
+	checker.OneOf("General.LedgerType", c.General.LedgerType, "file", "ram")
+	if c.General.LedgerType == "file" && c.FileLedger.Location != "" {
+		checker.Directory("FileLedger.Location", c.FileLedger.Location, true)
+	}
+	checker.OneOf("General.BCCSP", c.General.BCCSP, factory.SoftwareBasedFactoryName)
+	checker.MSPDir("General.LocalMSPDir", c.General.LocalMSPDir, c.General.LocalMSPID)
+	checkTLS(checker, "General.TLS", &c.General.TLS)
+
+	switch c.General.GenesisMethod {
+	case "provisional":
+	case "file":
+		if blockBytes := checker.File("General.GenesisFile", c.General.GenesisFile); blockBytes != nil {
+			block, err := utils.GetBlockFromBlockBytes(blockBytes)
+			if err == nil {
+				_, err = utils.GetChainIDFromBlock(block)
+			}
+			if err != nil {
+				checker.Errorf("General.GenesisFile", "invalid genesis block: %s", err)
+			}
+		}
+	case "url", "admin":
+		if hash, err := hex.DecodeString(c.General.GenesisRemote.Hash); err != nil {
+			checker.Errorf("General.GenesisRemote.Hash", "must be hex encoded: %s", err)
+		} else if len(hash) == 0 {
+			checker.Errorf("General.GenesisRemote.Hash", "must be set to the hash of the expected genesis block")
+		}
+		checkTLS(checker, "General.GenesisRemote.TLS", &c.General.GenesisRemote.TLS)
+	default:
+		checker.OneOf("General.GenesisMethod", c.General.GenesisMethod, "provisional", "file", "url", "admin")
+	}
+
+	if c.General.Admin.Enabled {
+		checker.Address("General.Admin.ListenAddress", net.JoinHostPort(c.General.Admin.ListenAddress, strconv.Itoa(int(c.General.Admin.ListenPort))))
+		checkKeyPair(checker, "General.Admin.TLS", &c.General.Admin.TLS)
+		checkCertificates(checker, "General.Admin.TLS.ClientRootCAs", c.General.Admin.TLS.ClientRootCAs)
+	}
+	if c.General.WebSocket.Enabled {
+		checker.Address("General.WebSocket.ListenAddress", net.JoinHostPort(c.General.WebSocket.ListenAddress, strconv.Itoa(int(c.General.WebSocket.ListenPort))))
+		checkTLS(checker, "General.WebSocket.TLS", &c.General.WebSocket.TLS)
+	}
+	if c.General.Cluster.Enabled {
+		checker.Address("General.Cluster.ListenAddress", net.JoinHostPort(c.General.Cluster.ListenAddress, strconv.Itoa(int(c.General.Cluster.ListenPort))))
+		checkKeyPair(checker, "General.Cluster.TLS", &c.General.Cluster.TLS)
+		checkCertificates(checker, "General.Cluster.TLS.ClientRootCAs", c.General.Cluster.TLS.ClientRootCAs)
+	}
+	if c.General.Profile.Enabled {
+		checker.Address("General.Profile.Address", c.General.Profile.Address)
+	}
+	checkTLS(checker, "Kafka.TLS", &c.Kafka.TLS)
+
+	return checker.Errors
+}
+
+// checkTLS checks the key pair and the CAs of an enabled TLS config
+func checkTLS(checker *configcheck.Checker, key string, tls *TLS) {
+	if !tls.Enabled {
+		return
+	}
+	if tls.Certificate != "" || tls.PrivateKey != "" {
+		checkKeyPair(checker, key, tls)
+	}
+	checkCertificates(checker, key+".RootCAs", tls.RootCAs)
+	checkCertificates(checker, key+".ClientRootCAs", tls.ClientRootCAs)
+}
+
+func checkKeyPair(checker *configcheck.Checker, key string, tls *TLS) {
+	checker.NotEmpty(key+".Certificate", tls.Certificate)
+	checker.NotEmpty(key+".PrivateKey", tls.PrivateKey)
+	checker.KeyPair(key, []byte(tls.Certificate), []byte(tls.PrivateKey))
+}
+
+func checkCertificates(checker *configcheck.Checker, key string, certificates []string) {
+	for i, certificate := range certificates {
+		checker.Certificates(fmt.Sprintf("%s[%d]", key, i), []byte(certificate))
+	}
+}
+
+// IsSetting returns whether name, e.g. GENERAL_LISTENPORT, is the
+// environment variable overriding a setting, without the prefix ORDERER
+func IsSetting(name string) bool {
+	return isSetting(reflect.TypeOf(TopLevel{}), "", name)
+}
+
+func isSetting(t reflect.Type, prefix string, name string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		fieldName := prefix + strings.ToUpper(field.Name)
+		switch {
+		case field.Type.Kind() == reflect.Map:
+			// the keys of the maps are not known in advance
+			if strings.HasPrefix(name, fieldName+"_") || name == fieldName {
+				return true
+			}
+		case field.Type.Kind() == reflect.Struct && hasExportedFields(field.Type):
+			if strings.HasPrefix(name, fieldName+"_") && isSetting(field.Type, fieldName+"_", name) {
+				return true
+			}
+		case name == fieldName:
+			return true
+		}
+	}
+	return false
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Redacted returns a copy of the config without its private keys, to be printed
+func (c *TopLevel) Redacted() *TopLevel {
+	redacted := *c
+	for _, tls := range []*TLS{&redacted.General.TLS, &redacted.General.GenesisRemote.TLS, &redacted.General.Admin.TLS,
+		&redacted.General.WebSocket.TLS, &redacted.General.Cluster.TLS, &redacted.Kafka.TLS} {
+		if tls.PrivateKey != "" {
+			tls.PrivateKey = "REDACTED"
+		}
+	}
+	return &redacted
+}
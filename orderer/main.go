@@ -17,6 +17,9 @@ limitations under the License.
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -24,15 +27,25 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/hyperledger/fabric/bccsp/factory"
 	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
 	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/wsgateway"
 	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/admin"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap/file"
+	"github.com/hyperledger/fabric/orderer/common/bootstrap/remote"
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
 	"github.com/hyperledger/fabric/orderer/kafka"
 	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
 	fileledger "github.com/hyperledger/fabric/orderer/ledger/file"
+	"github.com/hyperledger/fabric/orderer/ledger/quota"
 	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
 	"github.com/hyperledger/fabric/orderer/localconfig"
 	"github.com/hyperledger/fabric/orderer/multichain"
@@ -49,16 +62,27 @@ import (
 	"github.com/hyperledger/fabric/common/localmsp"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	logging "github.com/op/go-logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 var logger = logging.MustGetLogger("orderer/main")
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == validateConfigCommand {
+		os.Exit(validateConfig())
+	}
+
 	// Temporarilly set logging level until config is read
 	logging.SetLevel(logging.INFO, "")
 	conf := config.Load()
 	flogging.InitFromSpec(conf.General.LogLevel)
 
+	// Select the crypto provider before anything uses the default one
+	if err := factory.InitFactories(conf.General.BCCSP); err != nil {
+		panic(fmt.Errorf("Failed initializing the BCCSP [%s]", err))
+	}
+
 	// Start the profiling service if enabled.
 	// The ListenAndServe() call does not return unless an error occurs.
 	if conf.General.Profile.Enabled {
@@ -75,10 +99,7 @@ func main() {
 	}
 
 	//Create GRPC server - return if an error occurs
-	secureConfig := comm.SecureServerConfig{
-		UseTLS: conf.General.TLS.Enabled,
-	}
-	grpcServer, err := comm.NewGRPCServerFromListener(lis, secureConfig)
+	grpcServer, err := comm.NewGRPCServerFromListener(lis, secureServerConfig(&conf.General.TLS))
 	if err != nil {
 		fmt.Println("Failed to return new GRPC server: ", err)
 		return
@@ -102,6 +123,14 @@ func main() {
 			}
 		}
 		lf = fileledger.New(location)
+		if conf.FileLedger.Quota.MaxBytes > 0 || len(conf.FileLedger.Quota.Channels) > 0 {
+			lf = quota.NewFactory(lf, quota.Config{
+				MaxBytes:       conf.FileLedger.Quota.MaxBytes,
+				AlertThreshold: conf.FileLedger.Quota.AlertThreshold,
+				HardStop:       conf.FileLedger.Quota.HardStop,
+				Channels:       conf.FileLedger.Quota.Channels,
+			})
+		}
 	case "ram":
 		fallthrough
 	default:
@@ -118,6 +147,11 @@ func main() {
 			genesisBlock = provisional.New(genesisconfig.Load()).GenesisBlock()
 		case "file":
 			genesisBlock = file.New(conf.General.GenesisFile).GenesisBlock()
+		case "url":
+			genesisBlock = remote.NewURL(conf.General.GenesisRemote.URL, remoteBootstrapConfig(&conf.General.GenesisRemote)).GenesisBlock()
+		case "admin":
+			genesisBlock = remote.NewAdmin(conf.General.GenesisRemote.AdminAddress, conf.General.GenesisRemote.ChainID,
+				remoteBootstrapConfig(&conf.General.GenesisRemote)).GenesisBlock()
 		default:
 			panic(fmt.Errorf("Unknown genesis method %s", conf.General.GenesisMethod))
 		}
@@ -151,17 +185,204 @@ func main() {
 	consenters["kafka"] = kafka.New(conf.Kafka.Version, conf.Kafka.Retry, conf.Kafka.TLS)
 	consenters["sbft"] = sbft.New(makeSbftConsensusConfig(conf), makeSbftStackConfig(conf))
 
-	manager := multichain.NewManagerImpl(lf, consenters, localmsp.NewSigner())
+	manager := multichain.NewManagerImplWithFilters(lf, consenters, localmsp.NewSigner(), multichain.FilterPipelines{
+		Default:  conf.General.BroadcastFilters.Default,
+		Channels: conf.General.BroadcastFilters.Channels,
+	})
 
+	var integritySigner crypto.LocalSigner
+	if conf.General.DeliverIntegrity {
+		integritySigner = localmsp.NewSigner()
+	}
+
+	audit := broadcast.NewAudit(conf.General.BroadcastAudit.Size)
 	server := NewServer(
 		manager,
 		int(conf.General.QueueSize),
 		int(conf.General.MaxWindowSize),
+		conf.General.DeliverRevalidationInterval,
+		integritySigner,
+		conf.General.IngressValidators,
+		broadcast.ChunkLimits{
+			MaxBytes: conf.General.ChunkedBroadcast.MaxBytes,
+			Channels: conf.General.ChunkedBroadcast.Channels,
+		},
+		audit,
 	)
 
+	var adminServer comm.GRPCServer
+	if conf.General.Admin.Enabled {
+		adminServer = startAdminServer(&conf.General.Admin, manager, audit)
+	}
+
 	ab.RegisterAtomicBroadcastServer(grpcServer.Server(), server)
 	logger.Infof("Beginning to serve requests")
-	grpcServer.Start()
+	serve := make(chan error, 1)
+	go func() {
+		serve <- grpcServer.Start()
+	}()
+
+	if conf.General.WebSocket.Enabled {
+		startWebSocketGateway(&conf.General.WebSocket, &conf.General.TLS, grpcServer.Address())
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-serve:
+		if err != nil {
+			logger.Errorf("Failed serving requests: %s", err)
+		}
+		return
+	case sig := <-signals:
+		logger.Infof("Received %s, shutting down", sig)
+	}
+
+	shutdown(conf.General.ShutdownTimeout, server.(drainer), grpcServer, adminServer, manager)
+}
+
+// shutdown stops the orderer within timeout: the broadcast messages received from now on are
+// rejected, the ones in flight are enqueued and the servers are stopped before the chains are halted
+func shutdown(timeout time.Duration, d drainer, grpcServer comm.GRPCServer, adminServer comm.GRPCServer, manager multichain.Manager) {
+	deadline := time.Now().Add(timeout)
+
+	if !d.Drain(timeout) {
+		logger.Warningf("Shutdown timeout of %s exceeded, dropping the broadcast messages in flight", timeout)
+	}
+	if !grpcServer.GracefulStop(deadline.Sub(time.Now())) {
+		logger.Warningf("Shutdown timeout of %s exceeded, dropping the requests in flight", timeout)
+	}
+	if adminServer != nil {
+		adminServer.GracefulStop(deadline.Sub(time.Now()))
+	}
+
+	manager.Halt()
+	logger.Infof("Orderer stopped")
+}
+
+// remoteBootstrapConfig translates the config of the remote bootstrap service
+func remoteBootstrapConfig(conf *config.GenesisRemote) remote.Config {
+	hash, err := hex.DecodeString(conf.Hash)
+	if err != nil {
+		logger.Panicf("General.GenesisRemote.Hash must be hex encoded: %s", err)
+	}
+	remoteConf := remote.Config{
+		Hash:        hash,
+		Timeout:     conf.Timeout,
+		RetryPeriod: conf.Retry.Period,
+		RetryStop:   conf.Retry.Stop,
+	}
+	if !conf.TLS.Enabled {
+		return remoteConf
+	}
+	remoteConf.TLS = &tls.Config{}
+	if conf.TLS.Certificate != "" || conf.TLS.PrivateKey != "" {
+		keyPair, err := tls.X509KeyPair([]byte(conf.TLS.Certificate), []byte(conf.TLS.PrivateKey))
+		if err != nil {
+			logger.Panicf("Unable to decode the TLS client key pair of the remote bootstrap service: %s", err)
+		}
+		remoteConf.TLS.Certificates = []tls.Certificate{keyPair}
+	}
+	if len(conf.TLS.RootCAs) > 0 {
+		remoteConf.TLS.RootCAs = x509.NewCertPool()
+		for _, certificate := range conf.TLS.RootCAs {
+			if !remoteConf.TLS.RootCAs.AppendCertsFromPEM([]byte(certificate)) {
+				logger.Panicf("Unable to decode the root CAs of the remote bootstrap service")
+			}
+		}
+	}
+	return remoteConf
+}
+
+// startAdminServer serves the admin service on its own listener, to the clients
+// authenticating with a TLS certificate issued by one of the admin CAs, reporting the broadcast
+// messages rejected recorded with audit
+func startAdminServer(conf *config.Admin, manager multichain.Manager, audit *broadcast.Audit) comm.GRPCServer {
+	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", conf.ListenAddress, conf.ListenPort))
+	if err != nil {
+		logger.Panicf("Failed to listen for admin requests: %s", err)
+	}
+
+	// the admins always authenticate with a TLS certificate
+	secureConfig := secureServerConfig(&conf.TLS)
+	secureConfig.UseTLS = true
+	secureConfig.RequireClientCert = true
+	adminServer, err := comm.NewGRPCServerFromListener(lis, secureConfig)
+	if err != nil {
+		logger.Panicf("Failed to create the admin server: %s", err)
+	}
+
+	ab.RegisterAdminServer(adminServer.Server(), admin.NewServer(adminSupport{Manager: manager, audit: audit}))
+	logger.Infof("Beginning to serve admin requests on %s", adminServer.Address())
+	go adminServer.Start()
+	return adminServer
+}
+
+// secureServerConfig returns the config of a gRPC server with the TLS settings conf
+func secureServerConfig(conf *config.TLS) comm.SecureServerConfig {
+	secureConfig := comm.SecureServerConfig{
+		UseTLS:            conf.Enabled,
+		RequireClientCert: conf.ClientAuthEnabled,
+	}
+	if conf.Certificate != "" && conf.PrivateKey != "" {
+		secureConfig.ServerCertificate = []byte(conf.Certificate)
+		secureConfig.ServerKey = []byte(conf.PrivateKey)
+	}
+	for _, rootCA := range conf.ClientRootCAs {
+		secureConfig.ClientRootCAs = append(secureConfig.ClientRootCAs, []byte(rootCA))
+	}
+	return secureConfig
+}
+
+// startWebSocketGateway serves the WebSocket gateway on its own listener, relaying
+// the connections to the Broadcast and Deliver services served on address. The
+// connections relayed end when the gRPC server stops
+func startWebSocketGateway(conf *config.WebSocket, ordererTLS *config.TLS, address string) {
+	// the orderer listening on all addresses, the gateway connects to it on
+	// the loopback address
+	if host, port, err := net.SplitHostPort(address); err == nil && net.ParseIP(host) != nil && net.ParseIP(host).IsUnspecified() {
+		address = net.JoinHostPort("127.0.0.1", port)
+	}
+	dialOpts := []grpc.DialOption{grpc.WithInsecure()}
+	if ordererTLS.Enabled {
+		rootCAs := x509.NewCertPool()
+		for _, certificate := range ordererTLS.RootCAs {
+			if !rootCAs.AppendCertsFromPEM([]byte(certificate)) {
+				logger.Panicf("Unable to decode the root CAs of the orderer")
+			}
+		}
+		tlsConfig := &tls.Config{RootCAs: rootCAs, ServerName: conf.ServerName}
+		if conf.ClientCertificate != "" || conf.ClientPrivateKey != "" {
+			keyPair, err := tls.X509KeyPair([]byte(conf.ClientCertificate), []byte(conf.ClientPrivateKey))
+			if err != nil {
+				logger.Panicf("Unable to decode the TLS client key pair of the WebSocket gateway: %s", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{keyPair}
+		}
+		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+	}
+	conn, err := grpc.Dial(address, dialOpts...)
+	if err != nil {
+		logger.Panicf("Failed to connect the WebSocket gateway to the orderer: %s", err)
+	}
+
+	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", conf.ListenAddress, conf.ListenPort))
+	if err != nil {
+		logger.Panicf("Failed to listen for WebSocket connections: %s", err)
+	}
+	if conf.TLS.Enabled {
+		keyPair, err := tls.X509KeyPair([]byte(conf.TLS.Certificate), []byte(conf.TLS.PrivateKey))
+		if err != nil {
+			logger.Panicf("Unable to decode the TLS key pair of the WebSocket gateway: %s", err)
+		}
+		lis = tls.NewListener(lis, &tls.Config{Certificates: []tls.Certificate{keyPair}})
+	}
+
+	gateway := wsgateway.New(wsgateway.Config{AllowedOrigins: conf.AllowedOrigins}, ab.NewAtomicBroadcastClient(conn), nil)
+	logger.Infof("Beginning to serve WebSocket connections on %s", lis.Addr())
+	go func() {
+		logger.Errorf("WebSocket gateway stopped: %s", http.Serve(lis, gateway))
+	}()
 }
 
 func makeSbftConsensusConfig(conf *config.TopLevel) *sbft.ConsensusConfig {
@@ -177,8 +398,32 @@ func makeSbftConsensusConfig(conf *config.TopLevel) *sbft.ConsensusConfig {
 }
 
 func makeSbftStackConfig(conf *config.TopLevel) *backend.StackConfig {
-	return &backend.StackConfig{ListenAddr: conf.SbftLocal.PeerCommAddr,
+	sc := &backend.StackConfig{ListenAddr: conf.SbftLocal.PeerCommAddr,
 		CertFile: conf.SbftLocal.CertFile,
 		KeyFile:  conf.SbftLocal.KeyFile,
 		DataDir:  conf.SbftLocal.DataDir}
+	if conf.General.Cluster.Enabled {
+		listenCluster(&conf.General.Cluster, sc)
+	}
+	return sc
+}
+
+// listenCluster has the SBFT consensus traffic, the traffic between the orderers,
+// served on the cluster listener with its TLS settings rather than on the
+// PeerCommAddr of SbftLocal. The clients are only served on General.ListenAddress
+func listenCluster(conf *config.Cluster, sc *backend.StackConfig) {
+	cert, err := tls.X509KeyPair([]byte(conf.TLS.Certificate), []byte(conf.TLS.PrivateKey))
+	if err != nil {
+		logger.Panicf("Failed to load the key pair of the cluster listener: %s", err)
+	}
+	sc.Certificate = &cert
+	if conf.TLS.ClientAuthEnabled {
+		sc.ClientCAs = x509.NewCertPool()
+		for _, rootCA := range conf.TLS.ClientRootCAs {
+			if !sc.ClientCAs.AppendCertsFromPEM([]byte(rootCA)) {
+				logger.Panicf("Failed to parse the ClientRootCAs of the cluster listener")
+			}
+		}
+	}
+	sc.ListenAddr = fmt.Sprintf("%s:%d", conf.ListenAddress, conf.ListenPort)
 }
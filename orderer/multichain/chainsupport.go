@@ -28,6 +28,7 @@ import (
 	"github.com/hyperledger/fabric/orderer/common/sigfilter"
 	"github.com/hyperledger/fabric/orderer/common/sizefilter"
 	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
+	"github.com/hyperledger/fabric/orderer/ledger/quota"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -98,6 +99,7 @@ type chainSupport struct {
 }
 
 func newChainSupport(
+	broadcastFilters *filter.RuleSet,
 	filters *filter.RuleSet,
 	ledgerResources *ledgerResources,
 	consenters map[string]Consenter,
@@ -114,7 +116,7 @@ func newChainSupport(
 	cs := &chainSupport{
 		ledgerResources: ledgerResources,
 		cutter:          cutter,
-		filters:         filters,
+		filters:         broadcastFilters,
 		signer:          signer,
 	}
 
@@ -137,7 +139,9 @@ func newChainSupport(
 	return cs
 }
 
-// createStandardFilters creates the set of filters for a normal (non-system) chain
+// createStandardFilters creates the set of filters for a normal (non-system) chain. The
+// block cutter applies them to the ordered messages, so they do not depend on the filter
+// pipelines, which may differ between the orderers
 func createStandardFilters(ledgerResources *ledgerResources) *filter.RuleSet {
 	return filter.NewRuleSet([]filter.Rule{
 		filter.EmptyRejectRule,
@@ -161,10 +165,33 @@ func createSystemChainFilters(ml *multiLedger, ledgerResources *ledgerResources)
 	})
 }
 
+// createStandardBroadcastFilters creates the set of filters of the messages broadcast to a
+// normal (non-system) chain, the filters of its pipeline first
+func createStandardBroadcastFilters(pipelines FilterPipelines, ledgerResources *ledgerResources) *filter.RuleSet {
+	return filter.NewRuleSet(append(pipelines.rules(ledgerResources),
+		configtxfilter.NewFilter(ledgerResources),
+		filter.AcceptRule,
+	))
+}
+
+// createSystemChainBroadcastFilters creates the set of filters of the messages broadcast to
+// the ordering system chain, the filters of its pipeline first
+func createSystemChainBroadcastFilters(ml *multiLedger, ledgerResources *ledgerResources) *filter.RuleSet {
+	return filter.NewRuleSet(append(ml.filterPipelines.rules(ledgerResources),
+		newSystemChainFilter(ml),
+		configtxfilter.NewFilter(ledgerResources),
+		filter.AcceptRule,
+	))
+}
+
 func (cs *chainSupport) start() {
 	cs.chain.Start()
 }
 
+func (cs *chainSupport) halt() {
+	cs.chain.Halt()
+}
+
 func (cs *chainSupport) NewSignatureHeader() (*cb.SignatureHeader, error) {
 	return cs.signer.NewSignatureHeader()
 }
@@ -173,10 +200,17 @@ func (cs *chainSupport) Sign(message []byte) ([]byte, error) {
 	return cs.signer.Sign(message)
 }
 
+// Filters returns the filters of the messages broadcast to the chain, made of the built in
+// filters and the filter plugins of its pipeline
 func (cs *chainSupport) Filters() *filter.RuleSet {
 	return cs.filters
 }
 
+func (cs *chainSupport) StorageExceeded() bool {
+	ql, ok := cs.ledger.(quota.Ledger)
+	return ok && ql.Exceeded()
+}
+
 func (cs *chainSupport) BlockCutter() blockcutter.Receiver {
 	return cs.cutter
 }
@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multichain
+
+import (
+	"fmt"
+	"sync"
+
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/sigfilter"
+	"github.com/hyperledger/fabric/orderer/common/sizefilter"
+)
+
+// The names of the built in broadcast filters, which every pipeline must include
+const (
+	// EmptyRejectFilter rejects the messages without payload
+	EmptyRejectFilter = "EmptyReject"
+
+	// MaxBytesFilter rejects the messages larger than the AbsoluteMaxBytes of the chain
+	MaxBytesFilter = "MaxBytes"
+
+	// SignatureFilter rejects the messages not satisfying the ingress policies of the chain
+	SignatureFilter = "Signature"
+)
+
+// defaultFilterPipeline is the pipeline of the chains for which none is configured
+var defaultFilterPipeline = []string{EmptyRejectFilter, MaxBytesFilter, SignatureFilter}
+
+// FilterResources are the resources of a chain available to the filters created for it
+type FilterResources interface {
+	// ChainID returns the ID of the chain
+	ChainID() string
+
+	// PolicyManager returns the policy manager of the chain
+	PolicyManager() policies.Manager
+
+	// SharedConfig returns the orderer config of the chain
+	SharedConfig() configtxapi.OrdererConfig
+}
+
+// FilterFactory creates a broadcast filter for a chain. The filter may Reject or Forward
+// messages, it should not Accept them: the messages forwarded by the pipeline are still
+// handled by the config transaction filters of the chain
+type FilterFactory func(resources FilterResources) filter.Rule
+
+var filterFactories = struct {
+	sync.RWMutex
+	byName map[string]FilterFactory
+}{byName: map[string]FilterFactory{
+	EmptyRejectFilter: func(FilterResources) filter.Rule {
+		return filter.EmptyRejectRule
+	},
+	MaxBytesFilter: func(resources FilterResources) filter.Rule {
+		return sizefilter.MaxBytesRule(resources.SharedConfig().BatchSize().AbsoluteMaxBytes)
+	},
+	SignatureFilter: func(resources FilterResources) filter.Rule {
+		return sigfilter.New(resources.SharedConfig().IngressPolicyNames, resources.PolicyManager())
+	},
+}}
+
+// RegisterFilter registers the factory of the broadcast filter name, for the pipelines to
+// include it. Filters are registered before the Manager is created, usually from the init
+// function of the package implementing them
+func RegisterFilter(name string, factory FilterFactory) error {
+	filterFactories.Lock()
+	defer filterFactories.Unlock()
+	if _, ok := filterFactories.byName[name]; ok {
+		return fmt.Errorf("Broadcast filter %s already registered", name)
+	}
+	filterFactories.byName[name] = factory
+	return nil
+}
+
+func getFilterFactory(name string) (FilterFactory, bool) {
+	filterFactories.RLock()
+	defer filterFactories.RUnlock()
+	factory, ok := filterFactories.byName[name]
+	return factory, ok
+}
+
+// FilterPipelines are the ordered names of the broadcast filters applied to the messages of
+// the chains, before their config transaction filters
+type FilterPipelines struct {
+	// Default is the pipeline of the chains not listed in Channels, the built in filters
+	// only if empty
+	Default []string
+
+	// Channels are the pipelines of specific chains
+	Channels map[string][]string
+}
+
+// pipeline returns the names of the filters of chainID
+func (fp FilterPipelines) pipeline(chainID string) []string {
+	if pipeline, ok := fp.Channels[chainID]; ok {
+		return pipeline
+	}
+	if len(fp.Default) > 0 {
+		return fp.Default
+	}
+	return defaultFilterPipeline
+}
+
+// validate returns an error if a pipeline includes a filter which is not registered, or
+// includes a filter twice, or misses one of the built in filters
+func (fp FilterPipelines) validate() error {
+	pipelines := map[string][]string{"default": fp.pipeline("")}
+	for chainID, pipeline := range fp.Channels {
+		pipelines["chain "+chainID] = pipeline
+	}
+	for name, pipeline := range pipelines {
+		seen := map[string]bool{}
+		for _, filterName := range pipeline {
+			if _, ok := getFilterFactory(filterName); !ok {
+				return fmt.Errorf("Unknown broadcast filter %s in the %s pipeline", filterName, name)
+			}
+			if seen[filterName] {
+				return fmt.Errorf("Broadcast filter %s included twice in the %s pipeline", filterName, name)
+			}
+			seen[filterName] = true
+		}
+		for _, filterName := range defaultFilterPipeline {
+			if !seen[filterName] {
+				return fmt.Errorf("Built in broadcast filter %s missing from the %s pipeline", filterName, name)
+			}
+		}
+	}
+	return nil
+}
+
+// rules creates the filters of the pipeline of the chain of resources
+func (fp FilterPipelines) rules(resources FilterResources) []filter.Rule {
+	var rules []filter.Rule
+	for _, name := range fp.pipeline(resources.ChainID()) {
+		factory, _ := getFilterFactory(name)
+		rules = append(rules, factory(resources))
+	}
+	return rules
+}
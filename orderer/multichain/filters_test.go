@@ -0,0 +1,108 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multichain
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// rejectOddRule rejects the normal transactions of makeNormalTx with an odd index
+type rejectOddRule struct{}
+
+func (r rejectOddRule) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
+	payload, err := utils.UnmarshalPayload(message.Payload)
+	if err == nil && len(payload.Data) > 0 && (payload.Data[len(payload.Data)-1]-'0')%2 == 1 {
+		return filter.Reject, nil
+	}
+	return filter.Forward, nil
+}
+
+func init() {
+	if err := RegisterFilter("RejectOdd", func(FilterResources) filter.Rule { return rejectOddRule{} }); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterFilter(t *testing.T) {
+	assert.Error(t, RegisterFilter(SignatureFilter, func(FilterResources) filter.Rule { return filter.AcceptRule }))
+	assert.Error(t, RegisterFilter("RejectOdd", func(FilterResources) filter.Rule { return rejectOddRule{} }))
+}
+
+func TestFilterPipelinesValidate(t *testing.T) {
+	assert.NoError(t, FilterPipelines{}.validate())
+	assert.NoError(t, FilterPipelines{
+		Default:  []string{"RejectOdd", EmptyRejectFilter, MaxBytesFilter, SignatureFilter},
+		Channels: map[string][]string{"foo": {EmptyRejectFilter, SignatureFilter, MaxBytesFilter}},
+	}.validate())
+
+	assert.Error(t, FilterPipelines{Default: []string{EmptyRejectFilter, MaxBytesFilter, SignatureFilter, "Unknown"}}.validate())
+	assert.Error(t, FilterPipelines{Default: []string{EmptyRejectFilter, MaxBytesFilter, SignatureFilter, SignatureFilter}}.validate())
+	assert.Error(t, FilterPipelines{Channels: map[string][]string{"foo": {EmptyRejectFilter, MaxBytesFilter}}}.validate())
+}
+
+func TestFilterPipelinesPipeline(t *testing.T) {
+	fp := FilterPipelines{Channels: map[string][]string{"foo": {"RejectOdd", EmptyRejectFilter, MaxBytesFilter, SignatureFilter}}}
+	assert.Equal(t, defaultFilterPipeline, fp.pipeline("bar"))
+	assert.Equal(t, "RejectOdd", fp.pipeline("foo")[0])
+
+	fp.Default = []string{SignatureFilter, MaxBytesFilter, EmptyRejectFilter}
+	assert.Equal(t, fp.Default, fp.pipeline("bar"))
+}
+
+func TestInvalidFilterPipelines(t *testing.T) {
+	lf, _ := NewRAMLedgerAndFactory(10)
+	consenters := map[string]Consenter{conf.Orderer.OrdererType: &mockConsenter{}}
+	assert.Panics(t, func() {
+		NewManagerImplWithFilters(lf, consenters, &mockCryptoHelper{}, FilterPipelines{Default: []string{"Unknown"}})
+	})
+}
+
+// This test makes sure that the filters of the pipeline of a chain are applied to its messages
+func TestCustomFilter(t *testing.T) {
+	lf, _ := NewRAMLedgerAndFactory(10)
+	consenters := map[string]Consenter{conf.Orderer.OrdererType: &mockConsenter{}}
+	manager := NewManagerImplWithFilters(lf, consenters, &mockCryptoHelper{}, FilterPipelines{
+		Channels: map[string][]string{
+			provisional.TestChainID: {EmptyRejectFilter, "RejectOdd", MaxBytesFilter, SignatureFilter},
+		},
+	})
+
+	cs, ok := manager.GetChain(provisional.TestChainID)
+	if !ok {
+		t.Fatalf("Should have gotten chain which was initialized by ramledger")
+	}
+
+	_, err := cs.Filters().Apply(makeNormalTx(provisional.TestChainID, 2))
+	assert.NoError(t, err, "Even transaction should have been accepted")
+	_, err = cs.Filters().Apply(makeNormalTx(provisional.TestChainID, 3))
+	assert.Error(t, err, "Odd transaction should have been rejected by the custom filter")
+	_, err = cs.Filters().Apply(&cb.Envelope{})
+	assert.Error(t, err, "Empty transaction should have been rejected")
+
+	// the ordered messages are only filtered by the built in filters, the
+	// plugins of the pipeline of an orderer do not change the blocks it cuts
+	_, _, ok = cs.BlockCutter().Ordered(makeNormalTx(provisional.TestChainID, 3))
+	assert.True(t, ok, "Odd transaction should have been accepted by the block cutter")
+	_, _, ok = cs.BlockCutter().Ordered(&cb.Envelope{})
+	assert.False(t, ok, "Empty transaction should have been rejected by the block cutter")
+}
@@ -18,11 +18,15 @@ package multichain
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/hyperledger/fabric/common/configtx"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	configtxorderer "github.com/hyperledger/fabric/common/configtx/handlers/orderer"
 	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 
@@ -41,6 +45,23 @@ type Manager interface {
 	// The status returned is whether the proposal is accepted for consideration, only after consensus
 	// occurs will the proposal be committed or rejected
 	ProposeChain(env *cb.Envelope) cb.Status
+
+	// ChainIDs returns the IDs of the chains served, sorted
+	ChainIDs() []string
+
+	// SystemChainID returns the ID of the ordering system chain
+	SystemChainID() string
+
+	// JoinChain starts serving the chain created by a genesis block, it may not
+	// be used for a chain which already exists nor for an ordering system chain
+	JoinChain(genesisBlock *cb.Block) error
+
+	// RemoveChain halts a chain and removes its ledger, the ordering system chain
+	// may not be removed
+	RemoveChain(chainID string) error
+
+	// Halt halts all the chains, for the orderer to shut down
+	Halt()
 }
 
 type configResources struct {
@@ -57,11 +78,17 @@ type ledgerResources struct {
 }
 
 type multiLedger struct {
+	// lock serializes the updates of chains, which is replaced rather than
+	// modified so that it may be read concurrently
+	lock          sync.Mutex
 	chains        map[string]*chainSupport
 	consenters    map[string]Consenter
 	ledgerFactory ordererledger.Factory
 	sysChain      *systemChain
 	signer        crypto.LocalSigner
+
+	// filterPipelines are the broadcast filters of the chains
+	filterPipelines FilterPipelines
 }
 
 func getConfigTx(reader ordererledger.Reader) *cb.Envelope {
@@ -80,11 +107,23 @@ func getConfigTx(reader ordererledger.Reader) *cb.Envelope {
 
 // NewManagerImpl produces an instance of a Manager
 func NewManagerImpl(ledgerFactory ordererledger.Factory, consenters map[string]Consenter, signer crypto.LocalSigner) Manager {
+	return NewManagerImplWithFilters(ledgerFactory, consenters, signer, FilterPipelines{})
+}
+
+// NewManagerImplWithFilters produces an instance of a Manager whose chains apply the filters
+// of filterPipelines to the messages broadcast to them. The block cutters of the chains only
+// apply the built in filters to the ordered messages, which every orderer must handle alike
+func NewManagerImplWithFilters(ledgerFactory ordererledger.Factory, consenters map[string]Consenter, signer crypto.LocalSigner, filterPipelines FilterPipelines) Manager {
+	if err := filterPipelines.validate(); err != nil {
+		logger.Panicf("Invalid broadcast filter pipelines: %s", err)
+	}
+
 	ml := &multiLedger{
-		chains:        make(map[string]*chainSupport),
-		ledgerFactory: ledgerFactory,
-		consenters:    consenters,
-		signer:        signer,
+		chains:          make(map[string]*chainSupport),
+		ledgerFactory:   ledgerFactory,
+		consenters:      consenters,
+		signer:          signer,
+		filterPipelines: filterPipelines,
 	}
 
 	existingChains := ledgerFactory.ChainIDs()
@@ -105,7 +144,8 @@ func NewManagerImpl(ledgerFactory ordererledger.Factory, consenters map[string]C
 				logger.Fatalf("There appear to be two system chains %s and %s", ml.sysChain.support.ChainID(), chainID)
 			}
 			logger.Debugf("Starting with system chain: %x", chainID)
-			chain := newChainSupport(createSystemChainFilters(ml, ledgerResources),
+			chain := newChainSupport(createSystemChainBroadcastFilters(ml, ledgerResources),
+				createSystemChainFilters(ml, ledgerResources),
 				ledgerResources,
 				consenters,
 				signer)
@@ -115,7 +155,8 @@ func NewManagerImpl(ledgerFactory ordererledger.Factory, consenters map[string]C
 			defer chain.start()
 		} else {
 			logger.Debugf("Starting chain: %x", chainID)
-			chain := newChainSupport(createStandardFilters(ledgerResources),
+			chain := newChainSupport(createStandardBroadcastFilters(ml.filterPipelines, ledgerResources),
+				createStandardFilters(ledgerResources),
 				ledgerResources,
 				consenters,
 				signer)
@@ -145,33 +186,177 @@ func (ml *multiLedger) GetChain(chainID string) (ChainSupport, bool) {
 	return cs, ok
 }
 
-func newConfigResources(configEnvelope *cb.ConfigEnvelope) (*configResources, error) {
-	initializer := configtx.NewInitializer()
-	configManager, err := configtx.NewManagerImpl(configEnvelope, initializer, nil)
+// ChainIDs returns the IDs of the chains served, sorted
+func (ml *multiLedger) ChainIDs() []string {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+	chainIDs := make([]string, 0, len(ml.chains))
+	for chainID := range ml.chains {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+	return chainIDs
+}
+
+// SystemChainID returns the ID of the ordering system chain
+func (ml *multiLedger) SystemChainID() string {
+	return ml.sysChain.support.ChainID()
+}
+
+// JoinChain starts serving the chain created by a genesis block
+func (ml *multiLedger) JoinChain(genesisBlock *cb.Block) error {
+	if genesisBlock == nil || genesisBlock.Header == nil || genesisBlock.Header.Number != 0 {
+		return fmt.Errorf("Block is not the genesis block of a chain")
+	}
+	configTx, err := utils.ExtractEnvelope(genesisBlock, 0)
 	if err != nil {
-		return nil, fmt.Errorf("Error unpacking config transaction: %s", err)
+		return fmt.Errorf("Error extracting the config transaction of the genesis block: %s", err)
+	}
+	configResources, err := newConfigResourcesFromTx(configTx)
+	if err != nil {
+		return err
+	}
+	chainID := configResources.ChainID()
+	if configResources.SharedConfig().ChainCreationPolicyNames() != nil {
+		return fmt.Errorf("Chain %s is an ordering system chain, which cannot be joined", chainID)
+	}
+	if consensusType := configResources.SharedConfig().ConsensusType(); ml.consenters[consensusType] == nil {
+		return fmt.Errorf("Chain %s is ordered by an unsupported consenter type %s", chainID, consensusType)
 	}
 
-	return &configResources{
-		Manager: configManager,
-	}, nil
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+
+	if _, ok := ml.chains[chainID]; ok {
+		return fmt.Errorf("Chain %s already exists", chainID)
+	}
+
+	ledger, err := ml.ledgerFactory.GetOrCreate(chainID)
+	if err != nil {
+		return fmt.Errorf("Error creating ledger for %s: %s", chainID, err)
+	}
+	if ledger.Height() != 0 {
+		return fmt.Errorf("Ledger of %s already has %d blocks", chainID, ledger.Height())
+	}
+	if err = ledger.Append(genesisBlock); err != nil {
+		ml.ledgerFactory.Remove(chainID)
+		return fmt.Errorf("Error appending the genesis block of %s: %s", chainID, err)
+	}
+
+	ml.startChain(&ledgerResources{configResources: configResources, ledger: ledger})
+	return nil
 }
 
-func (ml *multiLedger) newLedgerResources(configTx *cb.Envelope) *ledgerResources {
+// RemoveChain halts a chain and removes its ledger
+func (ml *multiLedger) RemoveChain(chainID string) error {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+
+	cs, ok := ml.chains[chainID]
+	if !ok {
+		return fmt.Errorf("Chain %s does not exist", chainID)
+	}
+	if chainID == ml.SystemChainID() {
+		return fmt.Errorf("Chain %s is the ordering system chain, which cannot be removed", chainID)
+	}
+
+	newChains := make(map[string]*chainSupport)
+	for key, value := range ml.chains {
+		if key != chainID {
+			newChains[key] = value
+		}
+	}
+	ml.chains = newChains
+
+	logger.Debugf("Halting and removing chain %s", chainID)
+	cs.halt()
+	return ml.ledgerFactory.Remove(chainID)
+}
+
+// Halt halts all the chains
+func (ml *multiLedger) Halt() {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+	for chainID, cs := range ml.chains {
+		logger.Debugf("Halting chain %s", chainID)
+		cs.halt()
+	}
+}
+
+func newConfigResourcesFromTx(configTx *cb.Envelope) (*configResources, error) {
 	payload := &cb.Payload{}
 	err := proto.Unmarshal(configTx.Payload, payload)
 	if err != nil {
-		logger.Fatalf("Error unmarshaling a config transaction payload: %s", err)
+		return nil, fmt.Errorf("Error unmarshaling a config transaction payload: %s", err)
 	}
 
 	configEnvelope := &cb.ConfigEnvelope{}
 	err = proto.Unmarshal(payload.Data, configEnvelope)
 	if err != nil {
-		logger.Fatalf("Error unmarshaling a config transaction to config envelope: %s", err)
+		return nil, fmt.Errorf("Error unmarshaling a config transaction to config envelope: %s", err)
+	}
+
+	return newConfigResources(configEnvelope)
+}
+
+// chainRoleListener rejects the config updates which would turn the system chain into a standard
+// chain, or a standard chain into a system chain, as the orderer could not start again without a
+// system chain or with two of them
+type chainRoleListener struct {
+	system bool
+}
+
+// OnValidate rejects configEnv if it does not name the chain creation policies of a system chain
+// while the chain is the system chain, or the other way around
+func (crl *chainRoleListener) OnValidate(configEnv *cb.ConfigEnvelope) error {
+	if isSystemChainConfig(configEnv) == crl.system {
+		return nil
+	}
+	if crl.system {
+		return fmt.Errorf("The config of the system chain must name the chain creation policies")
+	}
+	return fmt.Errorf("The config of a standard chain cannot name chain creation policies")
+}
+
+// OnPostApply does nothing
+func (crl *chainRoleListener) OnPostApply(manager configtxapi.Manager) {}
+
+// isSystemChainConfig returns whether configEnv names the policies allowed to create chains, as
+// only the config of the system chain does
+func isSystemChainConfig(configEnv *cb.ConfigEnvelope) bool {
+	if configEnv.Config == nil || configEnv.Config.Channel == nil {
+		return false
+	}
+	ordererGroup, ok := configEnv.Config.Channel.Groups[configtxorderer.GroupKey]
+	if !ok {
+		return false
+	}
+	value, ok := ordererGroup.Values[configtxorderer.ChainCreationPolicyNamesKey]
+	if !ok {
+		return false
+	}
+	chainCreationPolicyNames := &ab.ChainCreationPolicyNames{}
+	if err := proto.Unmarshal(value.Value, chainCreationPolicyNames); err != nil {
+		return false
+	}
+	return len(chainCreationPolicyNames.Names) > 0
+}
+
+func newConfigResources(configEnvelope *cb.ConfigEnvelope) (*configResources, error) {
+	initializer := configtx.NewInitializer()
+	listeners := []configtxapi.ConfigListener{&chainRoleListener{system: isSystemChainConfig(configEnvelope)}}
+	configManager, err := configtx.NewManagerImpl(configEnvelope, initializer, listeners)
+	if err != nil {
+		return nil, fmt.Errorf("Error unpacking config transaction: %s", err)
 	}
 
-	configResources, err := newConfigResources(configEnvelope)
+	return &configResources{
+		Manager: configManager,
+	}, nil
+}
 
+func (ml *multiLedger) newLedgerResources(configTx *cb.Envelope) *ledgerResources {
+	configResources, err := newConfigResourcesFromTx(configTx)
 	if err != nil {
 		logger.Fatalf("Error creating configtx manager and handlers: %s", err)
 	}
@@ -194,16 +379,24 @@ func (ml *multiLedger) systemChain() *systemChain {
 }
 
 func (ml *multiLedger) newChain(configtx *cb.Envelope) {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+
 	ledgerResources := ml.newLedgerResources(configtx)
 	ledgerResources.ledger.Append(ordererledger.CreateNextBlock(ledgerResources.ledger, []*cb.Envelope{configtx}))
+	ml.startChain(ledgerResources)
+}
 
+// startChain starts a standard chain with ledgerResources and adds it to the
+// chains, ml.lock must be held
+func (ml *multiLedger) startChain(ledgerResources *ledgerResources) {
 	// Copy the map to allow concurrent reads from broadcast/deliver while the new chainSupport is
 	newChains := make(map[string]*chainSupport)
 	for key, value := range ml.chains {
 		newChains[key] = value
 	}
 
-	cs := newChainSupport(createStandardFilters(ledgerResources), ledgerResources, ml.consenters, ml.signer)
+	cs := newChainSupport(createStandardBroadcastFilters(ml.filterPipelines, ledgerResources), createStandardFilters(ledgerResources), ledgerResources, ml.consenters, ml.signer)
 	chainID := ledgerResources.ChainID()
 
 	logger.Debugf("Created and starting new chain %s", chainID)
@@ -21,7 +21,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/configtx"
+	configtxorderer "github.com/hyperledger/fabric/common/configtx/handlers/orderer"
 	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
 	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
 	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
@@ -116,6 +118,25 @@ func TestGetConfigTxFailure(t *testing.T) {
 
 }
 
+// TestChainRoleListener tests that the configs which would turn the system chain into a standard
+// chain, or the other way around, are rejected
+func TestChainRoleListener(t *testing.T) {
+	systemConfig, err := configtx.ConfigEnvelopeFromBlock(genesisBlock)
+	if err != nil {
+		t.Fatalf("Error extracting the config of the genesis block: %s", err)
+	}
+	standardConfig := proto.Clone(systemConfig).(*cb.ConfigEnvelope)
+	delete(standardConfig.Config.Channel.Groups[configtxorderer.GroupKey].Values, configtxorderer.ChainCreationPolicyNamesKey)
+	assert.True(t, isSystemChainConfig(systemConfig))
+	assert.False(t, isSystemChainConfig(standardConfig))
+
+	system, standard := &chainRoleListener{system: true}, &chainRoleListener{}
+	assert.NoError(t, system.OnValidate(systemConfig))
+	assert.Error(t, system.OnValidate(standardConfig), "The system chain should not become a standard chain")
+	assert.NoError(t, standard.OnValidate(standardConfig))
+	assert.Error(t, standard.OnValidate(systemConfig), "A standard chain should not become a system chain")
+}
+
 // This test essentially brings the entire system up and is ultimately what main.go will replicate
 func TestNoSystemChain(t *testing.T) {
 	defer func() {
@@ -309,3 +330,59 @@ func TestNewChain(t *testing.T) {
 		t.Fatalf("Block 1 not produced after timeout on new chain")
 	}
 }
+
+func TestJoinAndRemoveChain(t *testing.T) {
+	conf := genesisconfig.Load()
+	consenters := make(map[string]Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+
+	// The genesis block of the chain to join is taken from another orderer
+	lf, _ := NewRAMLedgerAndFactory(10)
+	creator := NewManagerImpl(lf, consenters, &mockCryptoHelper{})
+	signer, err := msp.NewNoopMsp().GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	newChainID := "TestJoinChain"
+	newChainMessage, err := configtx.MakeChainCreationTransaction(provisional.AcceptAllPolicyKey, newChainID, signer, provisional.New(conf).ChannelTemplate())
+	assert.NoError(t, err)
+	assert.Equal(t, cb.Status_SUCCESS, creator.ProposeChain(newChainMessage))
+	var newChainGenesis *cb.Block
+	for i := 0; i < 100 && newChainGenesis == nil; i++ {
+		if cs, ok := creator.GetChain(newChainID); ok {
+			newChainGenesis = ordererledger.GetBlock(cs.Reader(), 0)
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if newChainGenesis == nil {
+		t.Fatalf("Chain %s was not created", newChainID)
+	}
+
+	lf, _ = NewRAMLedgerAndFactory(10)
+	manager := NewManagerImpl(lf, consenters, &mockCryptoHelper{})
+	assert.Equal(t, []string{provisional.TestChainID}, manager.ChainIDs())
+	assert.Equal(t, provisional.TestChainID, manager.SystemChainID())
+
+	assert.Error(t, manager.JoinChain(genesisBlock), "Joining the system chain should have failed")
+	notGenesis := ordererledger.CreateNextBlock(NewRAMLedger(10), []*cb.Envelope{newChainMessage})
+	assert.Error(t, manager.JoinChain(notGenesis), "Joining from a block other than a genesis block should have failed")
+
+	assert.NoError(t, manager.JoinChain(newChainGenesis))
+	assert.Equal(t, []string{newChainID, provisional.TestChainID}, manager.ChainIDs())
+	assert.Error(t, manager.JoinChain(newChainGenesis), "Joining a chain twice should have failed")
+
+	chainSupport, ok := manager.GetChain(newChainID)
+	if !ok {
+		t.Fatalf("Should have gotten the chain which was joined")
+	}
+	chainSupport.Enqueue(makeNormalTx(newChainID, 0))
+
+	assert.Error(t, manager.RemoveChain(provisional.TestChainID), "Removing the system chain should have failed")
+	assert.Error(t, manager.RemoveChain("Fake"))
+	assert.NoError(t, manager.RemoveChain(newChainID))
+	_, ok = manager.GetChain(newChainID)
+	assert.False(t, ok, "Removed chain should not be served")
+	assert.Equal(t, []string{provisional.TestChainID}, lf.ChainIDs())
+
+	// A removed chain may be joined again
+	assert.NoError(t, manager.JoinChain(newChainGenesis))
+}
@@ -0,0 +1,283 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// osnadmin operates the channels of an orderer through its admin service
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/configtx"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// adminClientConfig holds the flags used to connect to the admin service
+type adminClientConfig struct {
+	address    string
+	caFile     string
+	clientCert string
+	clientKey  string
+	timeout    time.Duration
+}
+
+func main() {
+	if err := newRootCmd(os.Stdout).Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCmd(out io.Writer) *cobra.Command {
+	conf := &adminClientConfig{}
+	rootCmd := &cobra.Command{
+		Use:   "osnadmin",
+		Short: "Operate the channels of an orderer.",
+		Long:  `Operate the channels of an orderer through its admin service, authenticating with a TLS client certificate.`,
+	}
+	flags := rootCmd.PersistentFlags()
+	flags.StringVarP(&conf.address, "orderer", "o", "127.0.0.1:9443", "Address of the admin service of the orderer")
+	flags.StringVar(&conf.caFile, "ca-file", "", "PEM encoded certificate of the CA of the TLS certificate of the admin service")
+	flags.StringVar(&conf.clientCert, "client-cert", "", "PEM encoded TLS client certificate to authenticate with")
+	flags.StringVar(&conf.clientKey, "client-key", "", "PEM encoded private key of the TLS client certificate")
+	flags.DurationVar(&conf.timeout, "timeout", 10*time.Second, "Timeout of the admin requests")
+
+	channelCmd := &cobra.Command{
+		Use:   "channel",
+		Short: "Operate the channels of the orderer: list|status|join|remove|fetch-config|rejections|lint.",
+	}
+	channelCmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "Lists the channels served by the orderer.",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withAdminClient(conf, func(ctx context.Context, client ab.AdminClient) error {
+					resp, err := client.ListChannels(ctx, &ab.ListChannelsRequest{})
+					if err != nil {
+						return err
+					}
+					return printMessage(out, resp)
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "status <channel>",
+			Short: "Returns the status of a channel and of its consenter.",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if len(args) != 1 {
+					return fmt.Errorf("Must supply the channel")
+				}
+				return withAdminClient(conf, func(ctx context.Context, client ab.AdminClient) error {
+					info, err := client.ChannelStatus(ctx, &ab.ChannelRequest{ChainId: args[0]})
+					if err != nil {
+						return err
+					}
+					return printMessage(out, info)
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "join <genesis block file>",
+			Short: "Makes the orderer serve the channel created by a genesis block.",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if len(args) != 1 {
+					return fmt.Errorf("Must supply the file of the genesis block")
+				}
+				raw, err := ioutil.ReadFile(args[0])
+				if err != nil {
+					return fmt.Errorf("Error reading the genesis block: %s", err)
+				}
+				block := &cb.Block{}
+				if err = proto.Unmarshal(raw, block); err != nil {
+					return fmt.Errorf("Error unmarshaling the genesis block: %s", err)
+				}
+				return withAdminClient(conf, func(ctx context.Context, client ab.AdminClient) error {
+					info, err := client.JoinChannel(ctx, &ab.JoinChannelRequest{GenesisBlock: block})
+					if err != nil {
+						return err
+					}
+					return printMessage(out, info)
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "remove <channel>",
+			Short: "Stops serving a channel and removes its ledger.",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if len(args) != 1 {
+					return fmt.Errorf("Must supply the channel")
+				}
+				return withAdminClient(conf, func(ctx context.Context, client ab.AdminClient) error {
+					info, err := client.RemoveChannel(ctx, &ab.ChannelRequest{ChainId: args[0]})
+					if err != nil {
+						return err
+					}
+					return printMessage(out, info)
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "fetch-config <channel> <output file>",
+			Short: "Writes the latest config block of a channel to a file.",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if len(args) != 2 {
+					return fmt.Errorf("Must supply the channel and the output file")
+				}
+				return withAdminClient(conf, func(ctx context.Context, client ab.AdminClient) error {
+					block, err := client.ConfigBlock(ctx, &ab.ChannelRequest{ChainId: args[0]})
+					if err != nil {
+						return err
+					}
+					raw, err := proto.Marshal(block)
+					if err != nil {
+						return err
+					}
+					if err = ioutil.WriteFile(args[1], raw, 0644); err != nil {
+						return fmt.Errorf("Error writing the config block: %s", err)
+					}
+					fmt.Fprintf(out, "Wrote config block %d of channel %s to %s\n", block.Header.Number, args[0], args[1])
+					return nil
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "rejections [channel]",
+			Short: "Returns the latest broadcast messages rejected for a channel, or for any channel, and their counts by reason.",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if len(args) > 1 {
+					return fmt.Errorf("Must supply at most one channel")
+				}
+				req := &ab.ChannelRequest{}
+				if len(args) == 1 {
+					req.ChainId = args[0]
+				}
+				return withAdminClient(conf, func(ctx context.Context, client ab.AdminClient) error {
+					resp, err := client.BroadcastRejections(ctx, req)
+					if err != nil {
+						return err
+					}
+					return printMessage(out, resp)
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "lint <config block file>",
+			Short: "Reports the unsatisfiable or dangerous policies of the config of a block, without contacting the orderer.",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if len(args) != 1 {
+					return fmt.Errorf("Must supply the file of the config block")
+				}
+				return lintConfigBlock(out, args[0])
+			},
+		},
+	)
+	rootCmd.AddCommand(channelCmd)
+	return rootCmd
+}
+
+// lintConfigBlock prints the issues LintConfig finds in the config of the block stored in
+// file, it fails if any of them is an error
+func lintConfigBlock(out io.Writer, file string) error {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("Error reading the config block: %s", err)
+	}
+	block := &cb.Block{}
+	if err = proto.Unmarshal(raw, block); err != nil {
+		return fmt.Errorf("Error unmarshaling the config block: %s", err)
+	}
+	configEnv, err := configtx.ConfigEnvelopeFromBlock(block)
+	if err != nil {
+		return fmt.Errorf("Error extracting the config: %s", err)
+	}
+	issues, err := configtx.LintConfig(configEnv.Config)
+	if err != nil {
+		return err
+	}
+	errors := 0
+	for _, issue := range issues {
+		if issue.Severity == configtx.LintError {
+			errors++
+		}
+		fmt.Fprintln(out, issue)
+	}
+	if errors > 0 {
+		return fmt.Errorf("Found %d errors in the config", errors)
+	}
+	fmt.Fprintf(out, "Found no errors in the config, %d warnings\n", len(issues))
+	return nil
+}
+
+// withAdminClient connects to the admin service and calls f with a client of it
+func withAdminClient(conf *adminClientConfig, f func(ctx context.Context, client ab.AdminClient) error) error {
+	tlsConfig, err := conf.tlsConfig()
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.Dial(conf.address, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		grpc.WithBlock(), grpc.WithTimeout(conf.timeout))
+	if err != nil {
+		return fmt.Errorf("Error connecting to %s: %s", conf.address, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), conf.timeout)
+	defer cancel()
+	return f(ctx, ab.NewAdminClient(conn))
+}
+
+// tlsConfig returns the TLS config authenticating the client with its certificate
+func (conf *adminClientConfig) tlsConfig() (*tls.Config, error) {
+	if conf.caFile == "" || conf.clientCert == "" || conf.clientKey == "" {
+		return nil, fmt.Errorf("Must supply --ca-file, --client-cert and --client-key")
+	}
+	cert, err := tls.LoadX509KeyPair(conf.clientCert, conf.clientKey)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading the TLS client certificate: %s", err)
+	}
+	caPEM, err := ioutil.ReadFile(conf.caFile)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the CA certificate: %s", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("No certificate found in %s", conf.caFile)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      roots,
+	}, nil
+}
+
+func printMessage(out io.Writer, msg proto.Message) error {
+	m := &jsonpb.Marshaler{OrigName: true, EmitDefaults: true, Indent: "  "}
+	if err := m.Marshal(out, msg); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(out)
+	return err
+}
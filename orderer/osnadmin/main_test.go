@@ -0,0 +1,284 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/core/comm"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+// testCert is a certificate and its key, PEM encoded
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+		BasicConstraintsValid: true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	issuer, issuerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+	} else {
+		issuer, issuerKey = parent.cert, parent.key
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(raw)
+	assert.NoError(t, err)
+	rawKey, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: rawKey}),
+	}
+}
+
+type mockAdminServer struct {
+	chains map[string]*ab.ChannelInfo
+}
+
+func (mas *mockAdminServer) ListChannels(ctx context.Context, req *ab.ListChannelsRequest) (*ab.ListChannelsResponse, error) {
+	resp := &ab.ListChannelsResponse{}
+	for _, info := range mas.chains {
+		resp.Channels = append(resp.Channels, info)
+	}
+	return resp, nil
+}
+
+func (mas *mockAdminServer) ChannelStatus(ctx context.Context, req *ab.ChannelRequest) (*ab.ChannelInfo, error) {
+	info, ok := mas.chains[req.ChainId]
+	if !ok {
+		return nil, fmt.Errorf("Channel %s does not exist", req.ChainId)
+	}
+	return info, nil
+}
+
+func (mas *mockAdminServer) ConfigBlock(ctx context.Context, req *ab.ChannelRequest) (*cb.Block, error) {
+	info, err := mas.ChannelStatus(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &cb.Block{Header: &cb.BlockHeader{Number: info.LastConfig}}, nil
+}
+
+func (mas *mockAdminServer) GenesisBlock(ctx context.Context, req *ab.ChannelRequest) (*cb.Block, error) {
+	if _, err := mas.ChannelStatus(ctx, req); err != nil {
+		return nil, err
+	}
+	return &cb.Block{Header: &cb.BlockHeader{Number: 0}}, nil
+}
+
+func (mas *mockAdminServer) JoinChannel(ctx context.Context, req *ab.JoinChannelRequest) (*ab.ChannelInfo, error) {
+	info := &ab.ChannelInfo{ChainId: "joined", Height: 1, ConsensusType: "solo"}
+	mas.chains[info.ChainId] = info
+	return info, nil
+}
+
+func (mas *mockAdminServer) RemoveChannel(ctx context.Context, req *ab.ChannelRequest) (*ab.ChannelInfo, error) {
+	info, err := mas.ChannelStatus(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	delete(mas.chains, req.ChainId)
+	return info, nil
+}
+
+func (mas *mockAdminServer) BroadcastRejections(ctx context.Context, req *ab.ChannelRequest) (*ab.BroadcastRejectionsResponse, error) {
+	return &ab.BroadcastRejectionsResponse{
+		Rejections: []*ab.BroadcastRejection{&ab.BroadcastRejection{ChainId: req.ChainId, Reason: "MESSAGE_REJECTED", ClaimedMspId: "SampleOrg"}},
+		Counts:     []*ab.BroadcastRejectionCount{&ab.BroadcastRejectionCount{Reason: "MESSAGE_REJECTED", Count: 1}},
+	}, nil
+}
+
+func TestChannelCommands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osnadmin")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", nil, x509.ExtKeyUsageAny)
+	server := newTestCert(t, "orderer", ca, x509.ExtKeyUsageServerAuth)
+	client := newTestCert(t, "admin", ca, x509.ExtKeyUsageClientAuth)
+	otherCA := newTestCert(t, "other-ca", nil, x509.ExtKeyUsageAny)
+	stranger := newTestCert(t, "stranger", otherCA, x509.ExtKeyUsageClientAuth)
+	genesis, err := proto.Marshal(cb.NewBlock(0, nil))
+	assert.NoError(t, err)
+	files := map[string][]byte{
+		"ca.pem":          ca.certPEM,
+		"admin.pem":       client.certPEM,
+		"admin.key":       client.keyPEM,
+		"stranger.pem":    stranger.certPEM,
+		"stranger.key":    stranger.keyPEM,
+		"genesisblock.pb": genesis,
+		"notablock.pb":    []byte("not a block"),
+	}
+	for name, content := range files {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), content, 0600))
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv, err := comm.NewGRPCServerFromListener(lis, comm.SecureServerConfig{
+		UseTLS:            true,
+		ServerCertificate: server.certPEM,
+		ServerKey:         server.keyPEM,
+		RequireClientCert: true,
+		ClientRootCAs:     [][]byte{ca.certPEM},
+	})
+	assert.NoError(t, err)
+	mas := &mockAdminServer{chains: map[string]*ab.ChannelInfo{
+		"system": &ab.ChannelInfo{ChainId: "system", Height: 5, ConsensusType: "solo", SystemChannel: true, LastConfig: 3},
+	}}
+	ab.RegisterAdminServer(srv.Server(), mas)
+	go srv.Start()
+	defer srv.Stop()
+
+	run := func(identity string, args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		cmd := newRootCmd(out)
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs(append([]string{
+			"--orderer", srv.Address(),
+			"--ca-file", filepath.Join(dir, "ca.pem"),
+			"--client-cert", filepath.Join(dir, identity+".pem"),
+			"--client-key", filepath.Join(dir, identity+".key"),
+			"--timeout", "2s",
+		}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("admin", "channel", "status", "system")
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"chain_id": "system"`)
+	assert.Contains(t, out, `"system_channel": true`)
+	assert.Contains(t, out, `"last_config": "3"`)
+
+	_, err = run("admin", "channel", "status", "Fake")
+	assert.Error(t, err)
+
+	out, err = run("admin", "channel", "join", filepath.Join(dir, "genesisblock.pb"))
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"chain_id": "joined"`)
+	_, err = run("admin", "channel", "join", filepath.Join(dir, "notablock.pb"))
+	assert.Error(t, err)
+
+	out, err = run("admin", "channel", "list")
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"joined"`)
+	assert.Contains(t, out, `"system"`)
+
+	configFile := filepath.Join(dir, "config.pb")
+	_, err = run("admin", "channel", "fetch-config", "system", configFile)
+	assert.NoError(t, err)
+	raw, err := ioutil.ReadFile(configFile)
+	assert.NoError(t, err)
+	block := &cb.Block{}
+	assert.NoError(t, proto.Unmarshal(raw, block))
+	assert.Equal(t, uint64(3), block.Header.Number)
+
+	out, err = run("admin", "channel", "rejections", "system")
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"claimed_msp_id": "SampleOrg"`)
+	assert.Contains(t, out, `"count": "1"`)
+
+	_, err = run("admin", "channel", "remove", "joined")
+	assert.NoError(t, err)
+	assert.NotContains(t, mas.chains, "joined")
+
+	// Clients with a certificate from another CA are rejected by the handshake
+	_, err = run("stranger", "channel", "list")
+	assert.Error(t, err)
+}
+
+func TestLintCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osnadmin")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeConfigBlock := func(name string, channel *cb.ConfigGroup) string {
+		payload := &cb.Payload{Data: utils.MarshalOrPanic(&cb.ConfigEnvelope{Config: &cb.Config{Channel: channel}})}
+		block := cb.NewBlock(0, nil)
+		block.Data.Data = [][]byte{utils.MarshalOrPanic(&cb.Envelope{Payload: utils.MarshalOrPanic(payload)})}
+		file := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(file, utils.MarshalOrPanic(block), 0600))
+		return file
+	}
+	run := func(args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		cmd := newRootCmd(out)
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	channel := cb.NewConfigGroup()
+	channel.ModPolicy = "Admins"
+	channel.Policies["Admins"] = &cb.ConfigPolicy{
+		ModPolicy: "Admins",
+		Policy:    &cb.Policy{Type: int32(cb.Policy_SIGNATURE), Policy: cauthdsl.MarshaledAcceptAllPolicy},
+	}
+	out, err := run("channel", "lint", writeConfigBlock("acceptall.pb", channel))
+	assert.NoError(t, err)
+	assert.Contains(t, out, "WARNING [Policy] /Channel/Admins: Policy is satisfied without any signature")
+	assert.Contains(t, out, "Found no errors in the config, 1 warnings")
+
+	channel.ModPolicy = "Missing"
+	out, err = run("channel", "lint", writeConfigBlock("missing.pb", channel))
+	assert.Error(t, err, "Lint errors should fail the command")
+	assert.Contains(t, out, "ERROR [Groups] /Channel: Mod_policy Missing is not defined")
+
+	_, err = run("channel", "lint", filepath.Join(dir, "nonexistent.pb"))
+	assert.Error(t, err)
+}
@@ -36,6 +36,8 @@ import (
 	"crypto"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/asn1"
 	"encoding/gob"
 
@@ -80,6 +82,12 @@ type StackConfig struct {
 	CertFile   string
 	KeyFile    string
 	DataDir    string
+
+	// Certificate, when set, is presented instead of the one of CertFile
+	// and KeyFile, and the certificates of the peers are verified against
+	// ClientCAs unless it is nil
+	Certificate *tls.Certificate
+	ClientCAs   *x509.CertPool
 }
 
 type PeerInfo struct {
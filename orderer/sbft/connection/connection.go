@@ -44,12 +44,29 @@ type Manager struct {
 }
 
 func New(addr string, certFile string, keyFile string) (_ *Manager, err error) {
-	c := &Manager{}
-
 	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
 	if err != nil {
 		return nil, err
 	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewFromListener(lis, cert, nil)
+	if err != nil {
+		lis.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewFromListener serves the connections of the peers on lis, presenting cert,
+// which the peers pin. The certificates of the peers are requested, and verified
+// against clientCAs unless it is nil
+func NewFromListener(lis net.Listener, cert tls.Certificate, clientCAs *x509.CertPool) (_ *Manager, err error) {
+	c := &Manager{}
+
 	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
 	if err != nil {
 		return nil, err
@@ -63,14 +80,15 @@ func New(addr string, certFile string, keyFile string) (_ *Manager, err error) {
 		ClientAuth:         tls.RequestClientCert,
 		InsecureSkipVerify: true,
 	}
-
-	c.Listener, err = net.Listen("tcp", addr)
-	if err != nil {
-		return nil, err
+	if clientCAs != nil {
+		c.tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		c.tlsConfig.ClientCAs = clientCAs
 	}
 
+	c.Listener = lis
+
 	serverTls := c.tlsConfig
-	serverTls.ServerName = addr
+	serverTls.ServerName = lis.Addr().String()
 	c.Server = grpc.NewServer(grpc.Creds(credentials.NewTLS(serverTls)))
 	go c.Server.Serve(c.Listener)
 	return c, nil
@@ -17,10 +17,21 @@ limitations under the License.
 package connection
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
+	"math/big"
+	"net"
 	"testing"
+	"time"
+
+	"google.golang.org/grpc"
 )
 
 const cert = `
@@ -73,3 +84,61 @@ func TestPeerInfoWithOkCert(t *testing.T) {
 		t.Fatalf("Certificate field is empty.")
 	}
 }
+
+func TestNewFromListener(t *testing.T) {
+	certPEM, keyPEM := generateKeyPair(t)
+	kp, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("Failed to load the key pair: %s", err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+
+	c, err := NewFromListener(lis, kp, nil)
+	if err != nil {
+		t.Fatalf("Failed to serve the listener: %s", err)
+	}
+	defer c.Server.Stop()
+	if c.Listener != lis {
+		t.Fatalf("The listener given is not the one served.")
+	}
+	if !c.Self.Cert().Equal(c.Cert.Leaf) {
+		t.Fatalf("The certificate presented is not the one of the peer.")
+	}
+
+	// the peers dialing the listener pin the certificate it presents
+	self, err := NewPeerInfo(lis.Addr().String(), kp.Certificate[0])
+	if err != nil {
+		t.Fatalf("Peer creation failed: %s", err)
+	}
+	conn, err := c.DialPeer(self, grpc.WithBlock(), grpc.WithTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("Failed to dial the listener: %s", err)
+	}
+	conn.Close()
+}
+
+func generateKeyPair(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate a key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "orderer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create a certificate: %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal the key: %s", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
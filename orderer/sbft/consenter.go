@@ -17,6 +17,8 @@ limitations under the License.
 package sbft
 
 import (
+	"net"
+
 	"github.com/hyperledger/fabric/orderer/multichain"
 	"github.com/hyperledger/fabric/orderer/sbft/backend"
 	"github.com/hyperledger/fabric/orderer/sbft/connection"
@@ -73,8 +75,19 @@ func newChain(sbft *consenter, support multichain.ConsenterSupport) *chain {
 }
 
 func createConsensusStack(sbft *consenter) *consensusStack {
-	logger.Infof("%v    %v      %v", sbft.sbftStackConfig.ListenAddr, sbft.sbftStackConfig.CertFile, sbft.sbftStackConfig.KeyFile)
-	conn, err := connection.New(sbft.sbftStackConfig.ListenAddr, sbft.sbftStackConfig.CertFile, sbft.sbftStackConfig.KeyFile)
+	sc := sbft.sbftStackConfig
+	var conn *connection.Manager
+	var err error
+	if sc.Certificate != nil {
+		logger.Infof("%v", sc.ListenAddr)
+		var lis net.Listener
+		if lis, err = net.Listen("tcp", sc.ListenAddr); err == nil {
+			conn, err = connection.NewFromListener(lis, *sc.Certificate, sc.ClientCAs)
+		}
+	} else {
+		logger.Infof("%v    %v      %v", sc.ListenAddr, sc.CertFile, sc.KeyFile)
+		conn, err = connection.New(sc.ListenAddr, sc.CertFile, sc.KeyFile)
+	}
 	if err != nil {
 		logger.Errorf("Error when trying to connect: %s", err)
 		panic(err)
@@ -32,6 +32,7 @@ import (
 	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
 	"github.com/hyperledger/fabric/common/localmsp"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
 	"github.com/hyperledger/fabric/orderer/ledger"
 	"github.com/hyperledger/fabric/orderer/ledger/ram"
 	"github.com/hyperledger/fabric/orderer/localconfig"
@@ -92,7 +93,7 @@ func TestSbftPeer(t *testing.T) {
 	keyFile := "sbft/testdata/key.pem"
 	cons := &simplebft.Config{N: 1, F: 0, BatchDurationNsec: 1000, BatchSizeBytes: 1000000000, RequestTimeoutNsec: 1000000000}
 	c := &sbft.ConsensusConfig{Consensus: cons, Peers: peers}
-	sc := &backend.StackConfig{listenAddr, certFile, keyFile, dataTmpDir}
+	sc := &backend.StackConfig{ListenAddr: listenAddr, CertFile: certFile, KeyFile: keyFile, DataDir: dataTmpDir}
 	sbftConsenter := sbft.New(c, sc)
 	<-time.After(5 * time.Second)
 	// End SBFT
@@ -115,7 +116,7 @@ func TestSbftPeer(t *testing.T) {
 	signer := localmsp.NewSigner()
 	manager := multichain.NewManagerImpl(lf, consenters, signer)
 
-	server := NewServer(manager, int(conf.General.QueueSize), int(conf.General.MaxWindowSize))
+	server := NewServer(manager, int(conf.General.QueueSize), int(conf.General.MaxWindowSize), conf.General.DeliverRevalidationInterval, nil, 0, broadcast.ChunkLimits{}, broadcast.NewAudit(0))
 	grpcServer := grpc.NewServer()
 	grpcAddr := fmt.Sprintf("%s:%d", conf.General.ListenAddress, conf.General.ListenPort)
 	lis, err := net.Listen("tcp", grpcAddr)
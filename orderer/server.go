@@ -17,6 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/orderer/common/admin"
 	"github.com/hyperledger/fabric/orderer/common/broadcast"
 	"github.com/hyperledger/fabric/orderer/common/deliver"
 	"github.com/hyperledger/fabric/orderer/multichain"
@@ -25,6 +29,16 @@ import (
 
 type broadcastSupport struct {
 	multichain.Manager
+	chunkLimits broadcast.ChunkLimits
+	audit       *broadcast.Audit
+}
+
+func (bs broadcastSupport) MaxReassembledBytes(chainID string) uint64 {
+	return bs.chunkLimits.MaxReassembledBytes(chainID)
+}
+
+func (bs broadcastSupport) Audit() *broadcast.Audit {
+	return bs.audit
 }
 
 func (bs broadcastSupport) GetChain(chainID string) (broadcast.Support, bool) {
@@ -39,18 +53,50 @@ func (bs deliverSupport) GetChain(chainID string) (deliver.Support, bool) {
 	return bs.Manager.GetChain(chainID)
 }
 
+type adminSupport struct {
+	multichain.Manager
+	audit *broadcast.Audit
+}
+
+func (as adminSupport) BroadcastRejections(chainID string) *ab.BroadcastRejectionsResponse {
+	return as.audit.Rejections(chainID)
+}
+
+func (as adminSupport) GetChain(chainID string) (admin.Support, bool) {
+	return as.Manager.GetChain(chainID)
+}
+
+// drainer is implemented by the server returned by NewServer, for the orderer to shut down
+type drainer interface {
+	// Drain rejects the broadcast messages received from now on and waits up to timeout
+	// for the ones in flight to be enqueued, it returns whether they all were
+	Drain(timeout time.Duration) bool
+}
+
 type server struct {
 	bh broadcast.Handler
 	dh deliver.Handler
 }
 
-// NewServer creates a ab.AtomicBroadcastServer based on the broadcast target and ledger Reader
-func NewServer(ml multichain.Manager, queueSize, maxWindowSize int) ab.AtomicBroadcastServer {
+// NewServer creates a ab.AtomicBroadcastServer based on the broadcast target and ledger Reader,
+// the authorization of the deliver clients is evaluated again every deliverRevalidationInterval,
+// if integritySigner is not nil it signs the integrity proofs attached to the deliver responses,
+// if ingressValidators is not 0 that many goroutines validate the broadcast messages, and chunkLimits
+// bounds the size of the envelopes broadcast in chunks. The rejected broadcast messages are recorded
+// with audit
+func NewServer(ml multichain.Manager, queueSize, maxWindowSize int, deliverRevalidationInterval time.Duration, integritySigner crypto.LocalSigner, ingressValidators int, chunkLimits broadcast.ChunkLimits, audit *broadcast.Audit) ab.AtomicBroadcastServer {
 	logger.Infof("Starting orderer")
 
+	bs := broadcastSupport{Manager: ml, chunkLimits: chunkLimits, audit: audit}
 	s := &server{
-		dh: deliver.NewHandlerImpl(deliverSupport{ml}),
-		bh: broadcast.NewHandlerImpl(broadcastSupport{ml}),
+		dh: deliver.NewHandlerImpl(deliverSupport{ml}, deliverRevalidationInterval),
+		bh: broadcast.NewHandlerImpl(bs),
+	}
+	if ingressValidators > 0 {
+		s.bh = broadcast.NewHandlerImplWithValidators(bs, ingressValidators, queueSize)
+	}
+	if integritySigner != nil {
+		s.dh = deliver.NewHandlerImplWithIntegrity(deliverSupport{ml}, deliverRevalidationInterval, integritySigner)
 	}
 	return s
 }
@@ -61,8 +107,26 @@ func (s *server) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
 	return s.bh.Handle(srv)
 }
 
+// BroadcastBatch receives a stream of batches of messages from a client for ordering
+func (s *server) BroadcastBatch(srv ab.AtomicBroadcast_BroadcastBatchServer) error {
+	logger.Debugf("Starting new BroadcastBatch handler")
+	return s.bh.HandleBatch(srv)
+}
+
+// BroadcastChunked receives a stream of messages sent in chunks from a client for ordering
+func (s *server) BroadcastChunked(srv ab.AtomicBroadcast_BroadcastChunkedServer) error {
+	logger.Debugf("Starting new BroadcastChunked handler")
+	return s.bh.HandleChunked(srv)
+}
+
 // Deliver sends a stream of blocks to a client after ordering
 func (s *server) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
 	logger.Debugf("Starting new Deliver handler")
 	return s.dh.Handle(srv)
 }
+
+// Drain rejects the broadcast messages received from now on and waits up to timeout
+// for the ones in flight to be enqueued
+func (s *server) Drain(timeout time.Duration) bool {
+	return s.bh.Drain(timeout)
+}
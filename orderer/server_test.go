@@ -0,0 +1,41 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/localconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecureServerConfig(t *testing.T) {
+	secureConfig := secureServerConfig(&config.TLS{
+		Enabled:           true,
+		Certificate:       "cert",
+		PrivateKey:        "key",
+		ClientAuthEnabled: true,
+		ClientRootCAs:     []string{"ca1", "ca2"},
+	})
+	assert.True(t, secureConfig.UseTLS)
+	assert.True(t, secureConfig.RequireClientCert)
+	assert.Equal(t, []byte("cert"), secureConfig.ServerCertificate)
+	assert.Equal(t, []byte("key"), secureConfig.ServerKey)
+	assert.Equal(t, [][]byte{[]byte("ca1"), []byte("ca2")}, secureConfig.ClientRootCAs)
+
+	assert.False(t, secureServerConfig(&config.TLS{}).UseTLS)
+}
@@ -29,7 +29,9 @@ var logger = logging.MustGetLogger("orderer/solo")
 type consenter struct{}
 
 type chain struct {
-	support      multichain.ConsenterSupport
+	support multichain.ConsenterSupport
+	// batchTimeout is the batch timeout of the last batch, config updates
+	// changing it apply to the next batch
 	batchTimeout time.Duration
 	sendChan     chan *cb.Envelope
 	exitChan     chan struct{}
@@ -79,6 +81,16 @@ func (ch *chain) Enqueue(env *cb.Envelope) bool {
 	}
 }
 
+// nextBatchTimeout returns the batch timeout of the current config of the chain,
+// logging it when a config update has changed it since the last batch
+func (ch *chain) nextBatchTimeout() time.Duration {
+	if batchTimeout := ch.support.SharedConfig().BatchTimeout(); batchTimeout != ch.batchTimeout {
+		logger.Infof("Batch timeout of chain %s changed from %s to %s", ch.support.ChainID(), ch.batchTimeout, batchTimeout)
+		ch.batchTimeout = batchTimeout
+	}
+	return ch.batchTimeout
+}
+
 func (ch *chain) main() {
 	var timer <-chan time.Time
 
@@ -87,7 +99,7 @@ func (ch *chain) main() {
 		case msg := <-ch.sendChan:
 			batches, committers, ok := ch.support.BlockCutter().Ordered(msg)
 			if ok && len(batches) == 0 && timer == nil {
-				timer = time.After(ch.batchTimeout)
+				timer = time.After(ch.nextBatchTimeout())
 				continue
 			}
 			for i, batch := range batches {
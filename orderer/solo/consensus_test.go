@@ -133,8 +133,8 @@ func TestBatchTimerHaltOnFilledBatch(t *testing.T) {
 		t.Fatalf("Expected a block to be cut because the batch was filled, but did not")
 	}
 
-	// Change the batch timeout to be near instant, if the timer was not reset, it will still be waiting an hour
-	bs.batchTimeout = time.Millisecond
+	// Change the batch timeout to be near instant as a config update would, if the timer was not reset, it will still be waiting an hour
+	support.SharedConfigVal.BatchTimeoutVal = time.Millisecond
 
 	support.BlockCutterVal.CutNext = false
 	syncQueueMessage(testMessage, bs, support.BlockCutterVal)
@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/common/configcheck"
+	"github.com/hyperledger/fabric/orderer/localconfig"
+)
+
+const validateConfigCommand = "validate-config"
+
+// validateConfig loads orderer.yaml and the environment variables overriding
+// it, validates the result and the files it references, and prints the
+// effective configuration along with the errors found. It returns the exit
+// code of the orderer
+func validateConfig() int {
+	var errors []string
+	conf, err := config.LoadConfig()
+	if err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	overrides, unknown := configcheck.EnvOverrides(config.Prefix, func(name string) bool {
+		return name == "CFG_PATH" || config.IsSetting(name)
+	})
+
+	fmt.Printf("Configuration path: %s\n", os.Getenv("ORDERER_CFG_PATH"))
+	if conf != nil {
+		// the MSP is set up with the crypto provider of the configuration
+		factory.InitFactories(conf.General.BCCSP)
+		errors = append(errors, conf.Validate()...)
+
+		settings, err := json.MarshalIndent(conf.Redacted(), "", "  ")
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Error rendering the configuration: %s", err))
+		} else {
+			fmt.Printf("Effective configuration:\n%s\n", settings)
+		}
+	}
+	if len(overrides) > 0 {
+		fmt.Println("Environment variables overriding settings:")
+		for _, name := range overrides {
+			fmt.Println("  " + name)
+		}
+	}
+	if len(unknown) > 0 {
+		fmt.Println("Environment variables not overriding any setting:")
+		for _, name := range unknown {
+			fmt.Println("  " + name)
+		}
+	}
+
+	if len(errors) == 0 {
+		fmt.Println("The configuration is valid")
+		return 0
+	}
+	fmt.Println("Errors:")
+	for _, err := range errors {
+		fmt.Println("  " + err)
+	}
+	return 1
+}
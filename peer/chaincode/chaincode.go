@@ -52,6 +52,10 @@ func AddFlags(cmd *cobra.Command) {
 		fmt.Sprint("The chain on which this command should be executed"))
 	flags.StringVarP(&policy, "policy", "P", common.UndefinedParamValue,
 		fmt.Sprint("The endorsement policy associated to this chaincode"))
+	flags.StringVarP(&invocationPolicy, "invocation-policy", "I", common.UndefinedParamValue,
+		fmt.Sprint("The policy the clients invoking this chaincode must satisfy, e.g. OR('Org1MSP.member'), anyone may invoke it if not set"))
+	flags.StringVarP(&initArgsSchema, "init-args-schema", "S", common.UndefinedParamValue,
+		fmt.Sprint("The schema, in JSON, of the arguments following the function name the instantiation and the upgrades must provide, declared in the package by install/package"))
 	flags.StringVarP(&escc, "escc", "E", common.UndefinedParamValue,
 		fmt.Sprint("The name of the endorsement system chaincode to be used for this chaincode"))
 	flags.StringVarP(&vscc, "vscc", "V", common.UndefinedParamValue,
@@ -88,6 +92,13 @@ var (
 	escc              string
 	vscc              string
 	policyMarhsalled  []byte
+	invocationPolicy  string
+	// invocationPolicyMarshalled is nil if no invocation policy is given
+	invocationPolicyMarshalled []byte
+	initArgsSchema             string
+	peerAddresses              []string
+	tlsRootCertFiles           []string
+	discover                   bool
 )
 
 var chaincodeCmd = &cobra.Command{
@@ -17,16 +17,21 @@ limitations under the License.
 package chaincode
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	cutil "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/chaincode/platforms"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/container"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/peer/common"
@@ -68,6 +73,16 @@ func getChaincodeBytes(spec *pb.ChaincodeSpec, crtPkg bool) (*pb.ChaincodeDeploy
 		}
 	}
 	chaincodeDeploymentSpec := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec, CodePackage: codePackageBytes}
+	if crtPkg && initArgsSchema != common.UndefinedParamValue {
+		schema := &pb.ChaincodeInitArgsSchema{}
+		if err := jsonpb.UnmarshalString(initArgsSchema, schema); err != nil {
+			return nil, fmt.Errorf("Invalid init arguments schema %s: %s", initArgsSchema, err)
+		}
+		if err := ccprovider.CheckInitArgsSchema(schema); err != nil {
+			return nil, fmt.Errorf("Invalid init arguments schema %s: %s", initArgsSchema, err)
+		}
+		chaincodeDeploymentSpec.InitArgsSchema = schema
+	}
 	return chaincodeDeploymentSpec, nil
 }
 
@@ -98,18 +113,32 @@ func chaincodeInvokeOrQuery(cmd *cobra.Command, args []string, invoke bool, cf *
 		return err
 	}
 
-	proposalResp, err := ChaincodeInvokeOrQuery(spec, chainID, invoke, cf.Signer, cf.EndorserClient, cf.BroadcastClient)
+	endorserClients := cf.EndorserClients
+	if len(endorserClients) == 0 {
+		endorserClients = []pb.EndorserClient{cf.EndorserClient}
+	}
+	proposalResp, err := ChaincodeInvokeOrQueryWithEndorsers(spec, chainID, invoke, cf.Signer, endorserClients, cf.BroadcastClient)
 	if err != nil {
 		return err
 	}
 
 	if invoke {
+		if common.IsJSONOutput() {
+			return common.PrintResult(common.NewProposalResult(proposalResp, false), "")
+		}
 		logger.Infof("Invoke result: %v", proposalResp)
 	} else {
 		if proposalResp == nil {
 			return fmt.Errorf("Error query %s by endorsing: %s\n", chainFuncName, err)
 		}
 
+		if common.IsJSONOutput() {
+			if chaincodeQueryRaw {
+				return errors.New("Option --raw (-r) is not compatible with the json output\n")
+			}
+			return common.PrintResult(common.NewProposalResult(proposalResp, chaincodeQueryHex), "")
+		}
+
 		if chaincodeQueryRaw {
 			if chaincodeQueryHex {
 				err = errors.New("Options --raw (-r) and --hex (-x) are not compatible\n")
@@ -128,6 +157,37 @@ func chaincodeInvokeOrQuery(cmd *cobra.Command, args []string, invoke bool, cf *
 	return err
 }
 
+// deployResult is the result of an instantiate or upgrade rendered by the json
+// output
+type deployResult struct {
+	ChainID string `json:"chain_id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	TxID    string `json:"tx_id"`
+}
+
+// printDeployResult renders the result of the instantiate or upgrade
+// transaction env sent to the orderer. The text output prints nothing
+func printDeployResult(env *pcommon.Envelope) error {
+	if !common.IsJSONOutput() {
+		return nil
+	}
+	payload, err := putils.GetPayload(env)
+	if err != nil {
+		return err
+	}
+	if payload.Header == nil || payload.Header.ChannelHeader == nil {
+		return fmt.Errorf("Transaction has no channel header")
+	}
+	result := &deployResult{
+		ChainID: chainID,
+		Name:    chaincodeName,
+		Version: chaincodeVersion,
+		TxID:    payload.Header.ChannelHeader.TxId,
+	}
+	return common.PrintResult(result, "")
+}
+
 func checkChaincodeCmdParams(cmd *cobra.Command) error {
 	//we need chaincode name for everything, including deploy
 	if chaincodeName == common.UndefinedParamValue {
@@ -153,6 +213,10 @@ func checkChaincodeCmdParams(cmd *cobra.Command) error {
 		if policy != common.UndefinedParamValue {
 			return fmt.Errorf("policy should be supplied only to chaincode deploy requests")
 		}
+
+		if invocationPolicy != common.UndefinedParamValue {
+			return fmt.Errorf("invocation policy should be supplied only to chaincode deploy requests")
+		}
 	} else {
 		if escc != common.UndefinedParamValue {
 			logger.Infof("Using escc %s", escc)
@@ -179,6 +243,20 @@ func checkChaincodeCmdParams(cmd *cobra.Command) error {
 			p := cauthdsl.SignedByMspMember("DEFAULT")
 			policyMarhsalled = putils.MarshalOrPanic(p)
 		}
+
+		invocationPolicyMarshalled = nil
+		if invocationPolicy != common.UndefinedParamValue {
+			p, err := cauthdsl.FromString(invocationPolicy)
+			if err != nil {
+				return fmt.Errorf("Invalid invocation policy %s\n", invocationPolicy)
+			}
+			invocationPolicyMarshalled = putils.MarshalOrPanic(p)
+		}
+	}
+
+	// the schema of the init arguments is declared in the package
+	if initArgsSchema != common.UndefinedParamValue && cmd.Name() != install_cmdname && cmd.Name() != package_cmdname {
+		return fmt.Errorf("init arguments schema should be supplied only to chaincode install and package requests")
 	}
 
 	// Check that non-empty chaincode parameters contain only Args as a key.
@@ -213,21 +291,35 @@ func checkChaincodeCmdParams(cmd *cobra.Command) error {
 
 // ChaincodeCmdFactory holds the clients used by ChaincodeCmd
 type ChaincodeCmdFactory struct {
-	EndorserClient  pb.EndorserClient
+	EndorserClient pb.EndorserClient
+	// EndorserClients are the endorsers the invocations collect the
+	// endorsements from, the EndorserClient alone if empty
+	EndorserClients []pb.EndorserClient
 	Signer          msp.SigningIdentity
 	BroadcastClient common.BroadcastClient
 }
 
-// InitCmdFactory init the ChaincodeCmdFactory with default clients
+// InitCmdFactory init the ChaincodeCmdFactory with default clients, the
+// endorser clients connecting to the peers given with --peerAddresses or
+// discovered with --discover if any
 func InitCmdFactory() (*ChaincodeCmdFactory, error) {
-	endorserClient, err := common.GetEndorserClient()
+	signer, err := common.GetDefaultSigner()
 	if err != nil {
-		return nil, fmt.Errorf("Error getting endorser client %s: %s", chainFuncName, err)
+		return nil, fmt.Errorf("Error getting default signer: %s", err)
 	}
 
-	signer, err := common.GetDefaultSigner()
+	endorserClients, err := getEndorserClients(signer)
 	if err != nil {
-		return nil, fmt.Errorf("Error getting default signer: %s", err)
+		return nil, err
+	}
+	var endorserClient pb.EndorserClient
+	if len(endorserClients) > 0 {
+		endorserClient = endorserClients[0]
+	} else {
+		endorserClient, err = common.GetEndorserClient()
+		if err != nil {
+			return nil, fmt.Errorf("Error getting endorser client %s: %s", chainFuncName, err)
+		}
 	}
 
 	broadcastClient, err := common.GetBroadcastClient()
@@ -237,11 +329,95 @@ func InitCmdFactory() (*ChaincodeCmdFactory, error) {
 
 	return &ChaincodeCmdFactory{
 		EndorserClient:  endorserClient,
+		EndorserClients: endorserClients,
 		Signer:          signer,
 		BroadcastClient: broadcastClient,
 	}, nil
 }
 
+// getEndorserClients connects to the peers given with --peerAddresses, their
+// TLS certificates being verified with the matching --tlsRootCertFiles, or to
+// the peers the configured peer discovers with --discover, whose certificates
+// are verified with any of the --tlsRootCertFiles
+func getEndorserClients(signer msp.SigningIdentity) ([]pb.EndorserClient, error) {
+	if discover {
+		if len(peerAddresses) > 0 {
+			return nil, errors.New("Peer addresses should not be supplied along with --discover")
+		}
+		endorserClient, err := common.GetEndorserClient()
+		if err != nil {
+			return nil, fmt.Errorf("Error getting endorser client %s: %s", chainFuncName, err)
+		}
+		endpoints, err := discoverEndorsers(endorserClient, signer)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("Collecting the endorsements of %s from the peers discovered %v", chaincodeName, endpoints)
+
+		endorserClients := make([]pb.EndorserClient, len(endpoints))
+		for i, endpoint := range endpoints {
+			if endorserClients[i], err = common.GetEndorserClientForPeer(endpoint, tlsRootCertFiles...); err != nil {
+				return nil, fmt.Errorf("Error getting endorser client for peer %s: %s", endpoint, err)
+			}
+		}
+		return endorserClients, nil
+	}
+
+	if len(peerAddresses) == 0 {
+		if len(tlsRootCertFiles) > 0 {
+			return nil, errors.New("TLS root certificate files should be supplied only along with peer addresses")
+		}
+		return nil, nil
+	}
+	if len(tlsRootCertFiles) > 0 && len(tlsRootCertFiles) != len(peerAddresses) {
+		return nil, fmt.Errorf("Expected one TLS root certificate file per peer address, got %d for %d peers", len(tlsRootCertFiles), len(peerAddresses))
+	}
+
+	endorserClients := make([]pb.EndorserClient, len(peerAddresses))
+	for i, peerAddress := range peerAddresses {
+		var peerTLSRootCertFiles []string
+		if len(tlsRootCertFiles) > 0 {
+			peerTLSRootCertFiles = tlsRootCertFiles[i : i+1]
+		}
+		endorserClient, err := common.GetEndorserClientForPeer(peerAddress, peerTLSRootCertFiles...)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting endorser client for peer %s: %s", peerAddress, err)
+		}
+		endorserClients[i] = endorserClient
+	}
+	return endorserClients, nil
+}
+
+// discoverEndorsers asks the peer of endorserClient for the endpoints of peers
+// whose endorsements satisfy the endorsement policy of the chaincode on the chain
+func discoverEndorsers(endorserClient pb.EndorserClient, signer msp.SigningIdentity) ([]string, error) {
+	spec := &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_GOLANG,
+		ChaincodeId: &pb.ChaincodeID{Name: "cscc"},
+		Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte("GetEndorsers"), []byte(chainID), []byte(chaincodeName)}},
+	}
+	proposalResp, err := ChaincodeInvokeOrQuery(spec, "", false, signer, endorserClient, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error discovering the endorsers of %s: %s", chaincodeName, err)
+	}
+	if proposalResp.Response.Status != shim.OK {
+		return nil, fmt.Errorf("Error discovering the endorsers of %s: %s", chaincodeName, proposalResp.Response.Message)
+	}
+
+	layout := &pb.EndorsementLayout{}
+	if err = proto.Unmarshal(proposalResp.Response.Payload, layout); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling the endorsers of %s: %s", chaincodeName, err)
+	}
+	endpoints := make([]string, len(layout.Endorsers))
+	for i, endorser := range layout.Endorsers {
+		endpoints[i] = endorser.Endpoint
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("No endorsers discovered for %s", chaincodeName)
+	}
+	return endpoints, nil
+}
+
 // ChaincodeInvokeOrQuery invokes or queries the chaincode. If successful, the
 // INVOKE form prints the ProposalResponse to STDOUT, and the QUERY form prints
 // the query result on STDOUT. A command-line flag (-r, --raw) determines
@@ -252,6 +428,18 @@ func InitCmdFactory() (*ChaincodeCmdFactory, error) {
 // NOTE - Query will likely go away as all interactions with the endorser are
 // Proposal and ProposalResponses
 func ChaincodeInvokeOrQuery(spec *pb.ChaincodeSpec, cID string, invoke bool, signer msp.SigningIdentity, endorserClient pb.EndorserClient, bc common.BroadcastClient) (*pb.ProposalResponse, error) {
+	return ChaincodeInvokeOrQueryWithEndorsers(spec, cID, invoke, signer, []pb.EndorserClient{endorserClient}, bc)
+}
+
+// ChaincodeInvokeOrQueryWithEndorsers invokes or queries the chaincode as
+// ChaincodeInvokeOrQuery does, sending the proposal to each of the endorsers.
+// The responses of the endorsers must match, the transaction of an INVOKE
+// carrying all their endorsements. The response of the first endorser is returned
+func ChaincodeInvokeOrQueryWithEndorsers(spec *pb.ChaincodeSpec, cID string, invoke bool, signer msp.SigningIdentity, endorserClients []pb.EndorserClient, bc common.BroadcastClient) (*pb.ProposalResponse, error) {
+	if len(endorserClients) == 0 {
+		return nil, errors.New("At least one endorser is necessary")
+	}
+
 	// Build the ChaincodeInvocationSpec message
 	invocation := &pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}
 	if customIDGenAlg != common.UndefinedParamValue {
@@ -282,16 +470,30 @@ func ChaincodeInvokeOrQuery(spec *pb.ChaincodeSpec, cID string, invoke bool, sig
 		return nil, fmt.Errorf("Error creating signed proposal  %s: %s", funcName, err)
 	}
 
-	var proposalResp *pb.ProposalResponse
-	proposalResp, err = endorserClient.ProcessProposal(context.Background(), signedProp)
-	if err != nil {
-		return nil, fmt.Errorf("Error endorsing %s: %s", funcName, err)
+	proposalResps := make([]*pb.ProposalResponse, len(endorserClients))
+	for i, endorserClient := range endorserClients {
+		proposalResp, err := endorserClient.ProcessProposal(context.Background(), signedProp)
+		if err != nil {
+			return nil, fmt.Errorf("Error endorsing %s%s: %s", funcName, endorserSuffix(i, len(endorserClients)), err)
+		}
+		if err = common.CheckProposalResponse(proposalResp); err != nil {
+			return proposalResp, fmt.Errorf("Error endorsing %s%s: %s", funcName, endorserSuffix(i, len(endorserClients)), err)
+		}
+		proposalResps[i] = proposalResp
+	}
+	proposalResp := proposalResps[0]
+
+	// the endorsements of different results can't be assembled in one transaction
+	for i := 1; i < len(proposalResps); i++ {
+		if !bytes.Equal(proposalResps[i].Payload, proposalResp.Payload) {
+			return proposalResp, fmt.Errorf("Error endorsing %s: the response of endorser %d does not match the response of endorser 1", funcName, i+1)
+		}
 	}
 
 	if invoke {
 		if proposalResp != nil {
 			// assemble a signed transaction (it's an Envelope message)
-			env, err := putils.CreateSignedTx(prop, signer, proposalResp)
+			env, err := putils.CreateSignedTx(prop, signer, proposalResps...)
 			if err != nil {
 				return proposalResp, fmt.Errorf("Could not assemble transaction, err %s", err)
 			}
@@ -305,3 +507,11 @@ func ChaincodeInvokeOrQuery(spec *pb.ChaincodeSpec, cID string, invoke bool, sig
 
 	return proposalResp, nil
 }
+
+// endorserSuffix names the endorser in the errors, when there are several
+func endorserSuffix(i, n int) string {
+	if n == 1 {
+		return ""
+	}
+	return fmt.Sprintf(" with endorser %d", i+1)
+}
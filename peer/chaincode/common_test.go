@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/hyperledger/fabric/peer/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
@@ -75,6 +76,18 @@ func TestCheckChaincodeCmdParamsEmptyCtor(t *testing.T) {
 	require.Error(result)
 }
 
+func TestCheckChaincodeCmdParamsWithInitArgsSchema(t *testing.T) {
+	chaincodeCtorJSON = `{ "Args":["func", "param"] }`
+	chaincodePath = "some/path"
+	chaincodeName = "somename"
+	initArgsSchema = `{"args":[{"name":"a","type":"INTEGER"}]}`
+	defer func() { initArgsSchema = common.UndefinedParamValue }()
+	require := require.New(t)
+
+	require.Error(checkChaincodeCmdParams(&cobra.Command{Use: "invoke"}))
+	require.Nil(checkChaincodeCmdParams(&cobra.Command{Use: package_cmdname}))
+}
+
 func TestCheckValidJSON(t *testing.T) {
 	validJSON := `{"Args":["a","b","c"]}`
 	input := &pb.ChaincodeInput{}
@@ -73,9 +73,12 @@ func install(chaincodeName string, chaincodeVersion string, cds *pb.ChaincodeDep
 	if err != nil {
 		return fmt.Errorf("Error endorsing %s: %s\n", chainFuncName, err)
 	}
+	if err = common.CheckProposalResponse(proposalResponse); err != nil {
+		return fmt.Errorf("Error endorsing %s: %s\n", chainFuncName, err)
+	}
 
 	if proposalResponse != nil {
-		fmt.Printf("Installed remotely %v\n", proposalResponse)
+		return common.PrintResult(common.NewProposalResult(proposalResponse, false), "Installed remotely %v\n", proposalResponse)
 	}
 
 	return nil
@@ -66,7 +66,7 @@ func instantiate(cmd *cobra.Command, cf *ChaincodeCmdFactory) (*protcommon.Envel
 
 	uuid := util.GenerateUUID()
 
-	prop, err := utils.CreateDeployProposalFromCDS(uuid, chainID, cds, creator, policyMarhsalled, []byte(escc), []byte(vscc))
+	prop, err := utils.CreateDeployProposalFromCDSWithInvocationPolicy(uuid, chainID, cds, creator, policyMarhsalled, []byte(escc), []byte(vscc), invocationPolicyMarshalled)
 	if err != nil {
 		return nil, fmt.Errorf("Error creating proposal  %s: %s\n", chainFuncName, err)
 	}
@@ -113,8 +113,11 @@ func chaincodeDeploy(cmd *cobra.Command, args []string, cf *ChaincodeCmdFactory)
 	}
 
 	if env != nil {
-		err = cf.BroadcastClient.Send(env)
+		if err = cf.BroadcastClient.Send(env); err != nil {
+			return err
+		}
+		return printDeployResult(env)
 	}
 
-	return err
+	return nil
 }
@@ -29,12 +29,19 @@ func invokeCmd(cf *ChaincodeCmdFactory) *cobra.Command {
 	chaincodeInvokeCmd = &cobra.Command{
 		Use:       "invoke",
 		Short:     fmt.Sprintf("Invoke the specified %s.", chainFuncName),
-		Long:      fmt.Sprintf(`Invoke the specified %s. It will try to commit the endorsed transaction to the network.`, chainFuncName),
+		Long:      fmt.Sprintf(`Invoke the specified %s. It will try to commit the endorsed transaction to the network, with the endorsements of all the peers given with --peerAddresses or discovered with --discover.`, chainFuncName),
 		ValidArgs: []string{"1"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return chaincodeInvoke(cmd, args, cf)
 		},
 	}
+	flags := chaincodeInvokeCmd.Flags()
+	flags.StringSliceVar(&peerAddresses, "peerAddresses", nil,
+		fmt.Sprint("The addresses of the peers to collect the endorsements from, the configured peer if not set"))
+	flags.StringSliceVar(&tlsRootCertFiles, "tlsRootCertFiles", nil,
+		fmt.Sprint("The files of the TLS root certificates of the peers given with --peerAddresses, in the same order, or of any of the peers discovered, if TLS is enabled"))
+	flags.BoolVar(&discover, "discover", false,
+		fmt.Sprint("Collect the endorsements from peers satisfying the endorsement policy of the chaincode, which the configured peer discovers"))
 
 	return chaincodeInvokeCmd
 }
@@ -0,0 +1,125 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvokeCmdMultipleEndorsers(t *testing.T) {
+	InitMSP()
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	newResponse := func(payload string) *pb.ProposalResponse {
+		return &pb.ProposalResponse{
+			Response:    &pb.Response{Status: 200},
+			Payload:     []byte(payload),
+			Endorsement: &pb.Endorsement{},
+		}
+	}
+	invoke := func(responses ...*pb.ProposalResponse) error {
+		var endorserClients []pb.EndorserClient
+		for _, response := range responses {
+			endorserClients = append(endorserClients, common.GetMockEndorserClient(response, nil))
+		}
+		mockCF := &ChaincodeCmdFactory{
+			EndorserClient:  endorserClients[0],
+			EndorserClients: endorserClients,
+			Signer:          signer,
+			BroadcastClient: common.GetMockBroadcastClient(nil),
+		}
+
+		cmd := invokeCmd(mockCF)
+		AddFlags(cmd)
+		cmd.SetArgs([]string{"-n", "example02", "-c", "{\"Args\": [\"invoke\",\"a\",\"b\",\"10\"]}"})
+		return cmd.Execute()
+	}
+
+	assert.NoError(t, invoke(newResponse("result"), newResponse("result")))
+
+	err = invoke(newResponse("result"), newResponse("result"), newResponse("other result"))
+	assert.EqualError(t, err, "Error endorsing invoke: the response of endorser 3 does not match the response of endorser 1")
+}
+
+func TestGetEndorserClientsParams(t *testing.T) {
+	defer func() {
+		peerAddresses = nil
+		tlsRootCertFiles = nil
+	}()
+
+	peerAddresses = nil
+	tlsRootCertFiles = []string{"ca.pem"}
+	_, err := getEndorserClients(nil)
+	assert.Error(t, err, "TLS root certificate files without peer addresses should be rejected")
+
+	peerAddresses = []string{"peer0:7051", "peer1:7051"}
+	_, err = getEndorserClients(nil)
+	assert.Error(t, err, "A TLS root certificate file missing for a peer should be rejected")
+
+	peerAddresses = nil
+	tlsRootCertFiles = nil
+	endorserClients, err := getEndorserClients(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, endorserClients)
+
+	discover = true
+	defer func() { discover = false }()
+	peerAddresses = []string{"peer0:7051"}
+	_, err = getEndorserClients(nil)
+	assert.Error(t, err, "Peer addresses along with --discover should be rejected")
+}
+
+func TestDiscoverEndorsers(t *testing.T) {
+	InitMSP()
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	layout := &pb.EndorsementLayout{Endorsers: []*pb.EndorsingPeer{
+		{Endpoint: "peer0.org1:7051", MspId: "Org1MSP"},
+		{Endpoint: "peer0.org2:7051", MspId: "Org2MSP"},
+	}}
+	mockResponse := &pb.ProposalResponse{
+		Response:    &pb.Response{Status: 200, Payload: utils.MarshalOrPanic(layout)},
+		Endorsement: &pb.Endorsement{},
+	}
+	endpoints, err := discoverEndorsers(common.GetMockEndorserClient(mockResponse, nil), signer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"peer0.org1:7051", "peer0.org2:7051"}, endpoints)
+
+	mockResponse = &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: "no layout satisfies the policy"}}
+	_, err = discoverEndorsers(common.GetMockEndorserClient(mockResponse, nil), signer)
+	assert.Error(t, err)
+
+	mockResponse = &pb.ProposalResponse{
+		Response:    &pb.Response{Status: 200, Payload: utils.MarshalOrPanic(&pb.EndorsementLayout{})},
+		Endorsement: &pb.Endorsement{},
+	}
+	_, err = discoverEndorsers(common.GetMockEndorserClient(mockResponse, nil), signer)
+	assert.Error(t, err, "An empty layout should be rejected")
+}
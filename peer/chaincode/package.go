@@ -25,10 +25,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const package_cmdname = "package"
+
 // deployCmd returns the cobra command for Chaincode Deploy
 func packageCmd(cf *ChaincodeCmdFactory) *cobra.Command {
 	chaincodeInstantiateCmd = &cobra.Command{
-		Use:       "package",
+		Use:       package_cmdname,
 		Short:     fmt.Sprintf("Package the specified chaincode into a deployment spec."),
 		Long:      fmt.Sprintf(`Package the specified chaincode into a deployment spec.`),
 		ValidArgs: []string{"1"},
@@ -66,7 +66,7 @@ func upgrade(cmd *cobra.Command, cf *ChaincodeCmdFactory) (*protcommon.Envelope,
 
 	uuid := util.GenerateUUID()
 
-	prop, err := utils.CreateUpgradeProposalFromCDS(uuid, chainID, cds, creator, policyMarhsalled, []byte(escc), []byte(vscc))
+	prop, err := utils.CreateUpgradeProposalFromCDSWithInvocationPolicy(uuid, chainID, cds, creator, policyMarhsalled, []byte(escc), []byte(vscc), invocationPolicyMarshalled)
 	if err != nil {
 		return nil, fmt.Errorf("Error creating proposal %s: %s\n", chainFuncName, err)
 	}
@@ -116,8 +116,10 @@ func chaincodeUpgrade(cmd *cobra.Command, args []string, cf *ChaincodeCmdFactory
 
 	if env != nil {
 		logger.Debug("Send signed envelope to orderer")
-		err = cf.BroadcastClient.Send(env)
-		return err
+		if err = cf.BroadcastClient.Send(env); err != nil {
+			return err
+		}
+		return printDeployResult(env)
 	}
 
 	return nil
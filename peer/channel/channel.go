@@ -27,7 +27,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/net/context"
-	"google.golang.org/grpc"
 )
 
 const channelFuncName = "channel"
@@ -55,6 +54,9 @@ func Cmd(cf *ChannelCmdFactory) *cobra.Command {
 	channelCmd.AddCommand(joinCmd(cf))
 	channelCmd.AddCommand(createCmd(cf))
 	channelCmd.AddCommand(fetchCmd(cf))
+	channelCmd.AddCommand(deactivateCmd(cf))
+	channelCmd.AddCommand(reactivateCmd(cf))
+	channelCmd.AddCommand(inspectCmd())
 
 	return channelCmd
 }
@@ -103,28 +105,43 @@ func InitCmdFactory(isOrdererRequired bool) (*ChannelCmdFactory, error) {
 	if isOrdererRequired {
 		cmdFact.EndorserClient, err = common.GetEndorserClient()
 		if err != nil {
+			cmdFact.Close()
 			return nil, fmt.Errorf("Error getting endorser client %s: %s", channelFuncName, err)
 		}
 	} else {
 		orderer := viper.GetString("peer.committer.ledger.orderer")
-		conn, err := grpc.Dial(orderer, grpc.WithInsecure())
+		conn, err := common.GetOrdererConnection(orderer)
 		if err != nil {
+			cmdFact.Close()
 			return nil, err
 		}
 
 		client, err := ab.NewAtomicBroadcastClient(conn).Deliver(context.TODO())
 		if err != nil {
 			fmt.Println("Error connecting:", err)
+			common.InvalidateOrdererConnection(orderer, conn)
+			cmdFact.Close()
 			return nil, err
 		}
 
-		cmdFact.DeliverClient = newDeliverClient(client, chainID)
+		cmdFact.DeliverClient = newDeliverClient(client, chainID, orderer, conn)
 		cmdFact.AnchorPeerParser = common.GetAnchorPeersParser(anchorPeerList)
 	}
 
 	return cmdFact, nil
 }
 
+// Close closes the orderer clients of the factory, releasing their pooled
+// connections
+func (cf *ChannelCmdFactory) Close() {
+	if cf.BroadcastClient != nil {
+		cf.BroadcastClient.Close()
+	}
+	if cf.DeliverClient != nil {
+		cf.DeliverClient.Close()
+	}
+}
+
 const anchorPeerUsage = `In case of a newChain command, the list of anchor peer files, separated by commas.
 	The files should be in the following format:
 	anchorPeerHost
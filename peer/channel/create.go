@@ -76,7 +76,7 @@ func sendCreateChainTransaction(cf *ChannelCmdFactory) error {
 }
 
 func executeCreate(cf *ChannelCmdFactory) error {
-	defer cf.BroadcastClient.Close()
+	defer cf.Close()
 
 	var err error
 
@@ -101,7 +101,7 @@ func executeCreate(cf *ChannelCmdFactory) error {
 		return err
 	}
 
-	return nil
+	return printBlockResult(block, file)
 }
 
 func create(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
@@ -41,6 +41,10 @@ func (m *mockDeliverClient) readBlock() (*cb.Block, error) {
 	return &cb.Block{}, nil
 }
 
+func (m *mockDeliverClient) Close() error {
+	return nil
+}
+
 func (m *mockDeliverClient) getBlock() (*cb.Block, error) {
 	b, err := m.readBlock()
 	if err != nil {
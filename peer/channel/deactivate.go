@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"fmt"
+
+	cutil "github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/peer/common"
+	pcommon "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func deactivateCmd(cf *ChannelCmdFactory) *cobra.Command {
+	channelDeactivateCmd := &cobra.Command{
+		Use:   "deactivate",
+		Short: "Stops serving a chain on the peer, keeping its ledger.",
+		Long:  `Stops serving a chain on the peer: the peer neither gossips, pulls, endorses nor delivers on the chain, but keeps its ledger so that it doesn't resync the chain when it is reactivated. Requires the identity of an admin of the local MSP of the peer.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return changeChainActivation(cf, "DeactivateChain", "Deactivate")
+		},
+	}
+	return channelDeactivateCmd
+}
+
+func reactivateCmd(cf *ChannelCmdFactory) *cobra.Command {
+	channelReactivateCmd := &cobra.Command{
+		Use:   "reactivate",
+		Short: "Serves again a chain deactivated on the peer.",
+		Long:  `Serves again a chain deactivated on the peer, from its ledger. Only the blocks committed on the chain since it was deactivated are pulled. Requires the identity of an admin of the local MSP of the peer.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return changeChainActivation(cf, "ReactivateChain", "Reactivate")
+		},
+	}
+	return channelReactivateCmd
+}
+
+// changeChainActivation invokes the given CSCC function on the chain given by
+// the chain flag
+func changeChainActivation(cf *ChannelCmdFactory, fname string, operation string) error {
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(true)
+		if err != nil {
+			return err
+		}
+	}
+	defer cf.Close()
+	if chainID == common.UndefinedParamValue || chainID == "" {
+		return fmt.Errorf("Must supply the chain ID.\n")
+	}
+
+	spec := &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value["GOLANG"]),
+		ChaincodeId: &pb.ChaincodeID{Name: "cscc"},
+		Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte(fname), []byte(chainID)}},
+	}
+	invocation := &pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}
+
+	creator, err := cf.Signer.Serialize()
+	if err != nil {
+		return fmt.Errorf("Error serializing identity for %s: %s\n", cf.Signer.GetIdentifier(), err)
+	}
+
+	prop, err := putils.CreateProposalFromCIS(cutil.GenerateUUID(), pcommon.HeaderType_CONFIG, "", invocation, creator)
+	if err != nil {
+		return fmt.Errorf("Error creating proposal for %s %s\n", fname, err)
+	}
+
+	signedProp, err := putils.GetSignedProposal(prop, cf.Signer)
+	if err != nil {
+		return fmt.Errorf("Error creating signed proposal  %s\n", err)
+	}
+
+	proposalResp, err := cf.EndorserClient.ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		return ProposalFailedErr(err.Error())
+	}
+
+	if proposalResp == nil {
+		return ProposalFailedErr("nil proposal response")
+	}
+
+	if proposalResp.Response.Status != 0 && proposalResp.Response.Status != 200 {
+		return ProposalFailedErr(fmt.Sprintf("bad proposal response %d: %s", proposalResp.Response.Status, proposalResp.Response.Message))
+	}
+
+	return common.PrintResult(common.NewProposalResult(proposalResp, false), operation+" Result: %s\n", chainID)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/cobra"
+)
+
+func mockActivationCmdFactory(t *testing.T, status int32) *ChannelCmdFactory {
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	mockResponse := &pb.ProposalResponse{
+		Response:    &pb.Response{Status: status},
+		Endorsement: &pb.Endorsement{},
+	}
+
+	return &ChannelCmdFactory{
+		EndorserClient:  common.GetMockEndorserClient(mockResponse, nil),
+		BroadcastClient: common.GetMockBroadcastClient(nil),
+		Signer:          signer,
+	}
+}
+
+func TestDeactivateReactivate(t *testing.T) {
+	InitMSP()
+
+	for _, newCmd := range []func(*ChannelCmdFactory) *cobra.Command{deactivateCmd, reactivateCmd} {
+		cmd := newCmd(mockActivationCmdFactory(t, 200))
+		AddFlags(cmd)
+		cmd.SetArgs([]string{"-c", "mychain"})
+		if err := cmd.Execute(); err != nil {
+			t.Errorf("expected %s command to succeed, got %s", cmd.Name(), err)
+		}
+
+		cmd = newCmd(mockActivationCmdFactory(t, 200))
+		AddFlags(cmd)
+		cmd.SetArgs([]string{"-c", ""})
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("expected %s command to fail without chain ID", cmd.Name())
+		}
+
+		cmd = newCmd(mockActivationCmdFactory(t, 500))
+		AddFlags(cmd)
+		cmd.SetArgs([]string{"-c", "mychain"})
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("expected %s command to fail", cmd.Name())
+		} else if _, ok := err.(ProposalFailedErr); !ok {
+			t.Errorf("expected proposal failure error, got %s", err)
+		}
+	}
+}
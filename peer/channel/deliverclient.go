@@ -20,22 +20,29 @@ import (
 	"fmt"
 	"math"
 
+	peercommon "github.com/hyperledger/fabric/peer/common"
 	"github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
+	"google.golang.org/grpc"
 )
 
 type deliverClientIntf interface {
 	getBlock() (*common.Block, error)
+	Close() error
 }
 
 type deliverClient struct {
 	client  ab.AtomicBroadcast_DeliverClient
 	chainID string
+
+	// orderer and conn are the pooled connection the client streams over
+	orderer string
+	conn    *grpc.ClientConn
 }
 
-func newDeliverClient(client ab.AtomicBroadcast_DeliverClient, chainID string) *deliverClient {
-	return &deliverClient{client: client, chainID: chainID}
+func newDeliverClient(client ab.AtomicBroadcast_DeliverClient, chainID string, orderer string, conn *grpc.ClientConn) *deliverClient {
+	return &deliverClient{client: client, chainID: chainID, orderer: orderer, conn: conn}
 }
 
 func seekHelper(chainID string, start *ab.SeekPosition) *common.Envelope {
@@ -102,3 +109,10 @@ func (r *deliverClient) getBlock() (*common.Block, error) {
 
 	return b, nil
 }
+
+// Close closes the stream and releases its connection
+func (r *deliverClient) Close() error {
+	err := r.client.CloseSend()
+	peercommon.ReleaseOrdererConnection(r.orderer, r.conn)
+	return err
+}
@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/peer/common"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/spf13/cobra"
 )
@@ -46,7 +47,7 @@ func fetch(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
 		}
 	}
 
-	defer cf.BroadcastClient.Close()
+	defer cf.Close()
 
 	var block *cb.Block
 	if block, err = cf.DeliverClient.getBlock(); err != nil {
@@ -63,5 +64,26 @@ func fetch(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
 		return err
 	}
 
-	return nil
+	return printBlockResult(block, file)
+}
+
+// blockResult is the result of a channel create or fetch rendered by the json
+// output
+type blockResult struct {
+	ChainID     string `json:"chain_id"`
+	BlockNumber uint64 `json:"block_number"`
+	File        string `json:"file"`
+}
+
+// printBlockResult renders the block written to file by a channel create or
+// fetch. The text output prints nothing
+func printBlockResult(block *cb.Block, file string) error {
+	if !common.IsJSONOutput() {
+		return nil
+	}
+	result := &blockResult{ChainID: chainID, File: file}
+	if block.Header != nil {
+		result.BlockNumber = block.Header.Number
+	}
+	return common.PrintResult(result, "")
 }
@@ -0,0 +1,71 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/spf13/cobra"
+)
+
+// inspect related variables
+var printFormat string
+
+func inspectCmd() *cobra.Command {
+	channelInspectCmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Prints the config of a config block.",
+		Long:  `Prints the config held by a config block, such as a genesis block or a block fetched from the orderer, with its MSPs, policies and orderer settings decoded.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inspect(os.Stdout)
+		},
+	}
+	channelInspectCmd.Flags().StringVarP(&printFormat, "output", "o", "yaml", "Output format, json or yaml")
+	return channelInspectCmd
+}
+
+// inspect prints the config of the block read from the blockpath flag to w
+func inspect(w io.Writer) error {
+	if genesisBlockPath == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply the config block file.\n")
+	}
+	format, err := configtx.ParsePrintFormat(printFormat)
+	if err != nil {
+		return err
+	}
+
+	blockBytes, err := ioutil.ReadFile(genesisBlockPath)
+	if err != nil {
+		return fmt.Errorf("Error reading config block: %s", err)
+	}
+	block := &cb.Block{}
+	if err = proto.Unmarshal(blockBytes, block); err != nil {
+		return fmt.Errorf("Error unmarshaling config block: %s", err)
+	}
+	configEnvelope, err := configtx.ConfigEnvelopeFromBlock(block)
+	if err != nil {
+		return fmt.Errorf("Error getting config from block: %s", err)
+	}
+	return configtx.NewPrinter(format).PrintConfigEnvelope(w, configEnvelope)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+func TestInspect(t *testing.T) {
+	block, err := configtxtest.MakeGenesisBlock("mockchain")
+	if err != nil {
+		t.Fatalf("Error creating genesis block: %s", err)
+	}
+	file, err := ioutil.TempFile("", "inspect")
+	if err != nil {
+		t.Fatalf("Error creating block file: %s", err)
+	}
+	defer os.Remove(file.Name())
+	file.Write(utils.MarshalOrPanic(block))
+	file.Close()
+
+	defer func() { genesisBlockPath, printFormat = common.UndefinedParamValue, "yaml" }()
+	genesisBlockPath, printFormat = file.Name(), "json"
+	buf := &bytes.Buffer{}
+	if err = inspect(buf); err != nil {
+		t.Fatalf("Expected inspect to succeed: %s", err)
+	}
+	config := make(map[string]interface{})
+	if err = json.Unmarshal(buf.Bytes(), &config); err != nil {
+		t.Fatalf("Expected JSON output, got %s", buf.String())
+	}
+	if _, ok := config["config"]; !ok {
+		t.Errorf("Expected the config to be printed, got %s", buf.String())
+	}
+
+	genesisBlockPath = common.UndefinedParamValue
+	if err = inspect(buf); err == nil {
+		t.Error("Expected inspect to fail without a block file")
+	}
+}
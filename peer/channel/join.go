@@ -125,9 +125,7 @@ func executeJoin(cf *ChannelCmdFactory) (err error) {
 		return ProposalFailedErr(fmt.Sprintf("bad proposal response %d", proposalResp.Response.Status))
 	}
 
-	fmt.Printf("Join Result: %s\n", string(proposalResp.Response.Payload))
-
-	return nil
+	return common.PrintResult(common.NewProposalResult(proposalResp, false), "Join Result: %s\n", string(proposalResp.Response.Payload))
 }
 
 func join(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
@@ -138,5 +136,6 @@ func join(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
 			return err
 		}
 	}
+	defer cf.Close()
 	return executeJoin(cf)
 }
@@ -17,17 +17,25 @@ limitations under the License.
 package common
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/viperutil"
+	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/errors"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/msp"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // UndefinedParamValue defines what undefined parameters in the command line will initialise to
@@ -56,11 +64,33 @@ func InitConfig(cmdRoot string) error {
 		return fmt.Errorf("Fatal error when reading %s config file: %s\n", cmdRoot, err)
 	}
 
+	// Replace the secret references and the _FILE overrides by the secrets
+	resolved, err := viperutil.ResolveSecrets(viperutil.GlobalViper, cmdRoot)
+	for _, key := range resolved {
+		secretSettings[key] = true
+	}
+	if err != nil {
+		return fmt.Errorf("Fatal error when resolving the secrets of the %s config: %s\n", cmdRoot, err)
+	}
+
 	return nil
 }
 
+// secretSettings are the keys of the settings set to a secret by InitConfig
+var secretSettings = make(map[string]bool)
+
+// IsSecretSetting returns whether the setting of key was set to a secret, and
+// must not be displayed
+func IsSecretSetting(key string) bool {
+	return secretSettings[strings.ToLower(key)]
+}
+
 //InitCrypto initializes crypto for this peer
 func InitCrypto(mspMgrConfigDir string, localMSPID string) error {
+	if viper.GetString("peer.mspFabricCA.url") != "" {
+		return initCryptoFromFabricCA(mspMgrConfigDir, localMSPID)
+	}
+
 	err := mspmgmt.LoadLocalMsp(mspMgrConfigDir, localMSPID)
 	if err != nil {
 		return fmt.Errorf("Fatal error when setting up MSP from directory %s: err %s\n", mspMgrConfigDir, err)
@@ -69,6 +99,63 @@ func InitCrypto(mspMgrConfigDir string, localMSPID string) error {
 	return nil
 }
 
+// initCryptoFromFabricCA initializes crypto for this peer with the MSP material served
+// by the fabric-ca server configured under peer.mspFabricCA
+func initCryptoFromFabricCA(mspMgrConfigDir string, localMSPID string) error {
+	client, err := newFabricCAClient()
+	if err != nil {
+		return err
+	}
+
+	err = mspmgmt.LoadLocalMspFromFabricCA(mspMgrConfigDir, localMSPID, client, viper.GetStringSlice("peer.mspFabricCA.admins"))
+	if err != nil {
+		return fmt.Errorf("Fatal error when setting up MSP from fabric-ca server %s: err %s\n", viper.GetString("peer.mspFabricCA.url"), err)
+	}
+
+	fabricCARefresh = func() error {
+		return mspmgmt.RefreshLocalMspFromFabricCA(mspMgrConfigDir, localMSPID, client, viper.GetStringSlice("peer.mspFabricCA.admins"))
+	}
+	return nil
+}
+
+// fabricCARefresh sets up the local MSP again from the material served by the
+// fabric-ca server, it is nil unless the local MSP was set up from the server
+var fabricCARefresh func() error
+
+// RefreshCryptoFromFabricCA sets up the local MSP again from the material currently
+// served by the fabric-ca server it was set up from by InitCrypto, taking into account
+// the admin certificates and the CRL issued since. It returns false if the local MSP
+// was not set up from a fabric-ca server
+func RefreshCryptoFromFabricCA() (bool, error) {
+	if fabricCARefresh == nil {
+		return false, nil
+	}
+	return true, fabricCARefresh()
+}
+
+// newFabricCAClient creates the client of the fabric-ca server configured under
+// peer.mspFabricCA, which must be reached over https with a pinned TLS root
+func newFabricCAClient() (msp.FabricCAClient, error) {
+	cert, err := ioutil.ReadFile(viper.GetString("peer.mspFabricCA.enrollment.cert.file"))
+	if err != nil {
+		return nil, fmt.Errorf("Fatal error when reading the fabric-ca enrollment certificate: err %s\n", err)
+	}
+	key, err := ioutil.ReadFile(viper.GetString("peer.mspFabricCA.enrollment.key.file"))
+	if err != nil {
+		return nil, fmt.Errorf("Fatal error when reading the fabric-ca enrollment key: err %s\n", err)
+	}
+	rootCert, err := ioutil.ReadFile(viper.GetString("peer.mspFabricCA.tls.rootcert.file"))
+	if err != nil {
+		return nil, fmt.Errorf("Fatal error when reading the fabric-ca TLS root certificate: err %s\n", err)
+	}
+
+	client, err := msp.NewFabricCAClient(viper.GetString("peer.mspFabricCA.url"), viper.GetString("peer.mspFabricCA.caName"), rootCert, cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("Fatal error when creating the fabric-ca client: err %s\n", err)
+	}
+	return client, nil
+}
+
 // GetEndorserClient returns a new endorser client connection for this peer
 func GetEndorserClient() (pb.EndorserClient, error) {
 	clientConn, err := peer.NewPeerClientConnection()
@@ -80,6 +167,35 @@ func GetEndorserClient() (pb.EndorserClient, error) {
 	return endorserClient, nil
 }
 
+// GetEndorserClientForPeer returns a new endorser client connected to the given
+// peer. If TLS is enabled, the certificate of the peer is verified with the
+// given root certificate files, or the configured peer.tls.cert.file if none
+func GetEndorserClientForPeer(peerAddress string, tlsRootCertFiles ...string) (pb.EndorserClient, error) {
+	var clientConn *grpc.ClientConn
+	var err error
+	if comm.TLSEnabled() && len(tlsRootCertFiles) > 0 {
+		roots := x509.NewCertPool()
+		for _, file := range tlsRootCertFiles {
+			pem, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("Error reading TLS root certificate file %s: %s", file, err)
+			}
+			if !roots.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("Error decoding TLS root certificate file %s", file)
+			}
+		}
+		creds := credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: viper.GetString("peer.tls.serverhostoverride")})
+		clientConn, err = comm.NewClientConnectionWithAddress(peerAddress, true, true, creds)
+	} else {
+		clientConn, err = peer.NewPeerClientConnectionWithAddress(peerAddress)
+	}
+	if err != nil {
+		err = errors.ErrorWithCallstack("Peer", "ConnectionError", "Error trying to connect to peer %s: %s", peerAddress, err.Error())
+		return nil, err
+	}
+	return pb.NewEndorserClient(clientConn), nil
+}
+
 func GetAnchorPeersParser(anchorPeerParam string) *AnchorPeerParser {
 	if len(anchorPeerParam) == 0 {
 		return GetDefaultAnchorPeerParser()
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hyperledger/fabric/core/comm"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/spf13/viper"
@@ -34,8 +35,40 @@ type BroadcastClient interface {
 }
 
 type broadcastClient struct {
-	conn   *grpc.ClientConn
-	client ab.AtomicBroadcast_BroadcastClient
+	orderer string
+	conn    *grpc.ClientConn
+	client  ab.AtomicBroadcast_BroadcastClient
+}
+
+// GetOrdererConnection returns the connection to orderer from the connection pool,
+// it must be released with ReleaseOrdererConnection
+func GetOrdererConnection(orderer string) (*grpc.ClientConn, error) {
+	conn, err := comm.GetConnectionPool().Get(ordererConnKey(orderer), func() (*grpc.ClientConn, error) {
+		var opts []grpc.DialOption
+		opts = append(opts, grpc.WithInsecure())
+		opts = append(opts, grpc.WithTimeout(3*time.Second))
+		opts = append(opts, grpc.WithBlock())
+		return grpc.Dial(orderer, opts...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting to %s due to %s", orderer, err)
+	}
+	return conn, nil
+}
+
+// ReleaseOrdererConnection releases a connection returned by GetOrdererConnection
+func ReleaseOrdererConnection(orderer string, conn *grpc.ClientConn) {
+	comm.GetConnectionPool().Release(ordererConnKey(orderer), conn)
+}
+
+// InvalidateOrdererConnection releases a connection returned by GetOrdererConnection
+// which failed, for the next GetOrdererConnection to dial a new one
+func InvalidateOrdererConnection(orderer string, conn *grpc.ClientConn) {
+	comm.GetConnectionPool().Invalidate(ordererConnKey(orderer), conn)
+}
+
+func ordererConnKey(orderer string) string {
+	return "orderer/" + orderer
 }
 
 // GetBroadcastClient creates a simple instance of the BroadcastClient interface
@@ -49,22 +82,17 @@ func GetBroadcastClient() (BroadcastClient, error) {
 		return nil, fmt.Errorf("Can't get orderer address")
 	}
 
-	var opts []grpc.DialOption
-	opts = append(opts, grpc.WithInsecure())
-	opts = append(opts, grpc.WithTimeout(3*time.Second))
-	opts = append(opts, grpc.WithBlock())
-
-	conn, err := grpc.Dial(orderer, opts...)
+	conn, err := GetOrdererConnection(orderer)
 	if err != nil {
-		return nil, fmt.Errorf("Error connecting to %s due to %s", orderer, err)
+		return nil, err
 	}
 	client, err := ab.NewAtomicBroadcastClient(conn).Broadcast(context.TODO())
 	if err != nil {
-		conn.Close()
+		InvalidateOrdererConnection(orderer, conn)
 		return nil, fmt.Errorf("Error connecting to %s due to %s", orderer, err)
 	}
 
-	return &broadcastClient{conn: conn, client: client}, nil
+	return &broadcastClient{orderer: orderer, conn: conn, client: client}, nil
 }
 
 func (s *broadcastClient) getAck() error {
@@ -90,5 +118,7 @@ func (s *broadcastClient) Send(env *cb.Envelope) error {
 }
 
 func (s *broadcastClient) Close() error {
-	return s.conn.Close()
+	err := s.client.CloseSend()
+	ReleaseOrdererConnection(s.orderer, s.conn)
+	return err
 }
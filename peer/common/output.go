@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	cerrors "github.com/hyperledger/fabric/common/errors"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// TextOutput renders the results of the commands as text
+	TextOutput = "text"
+	// JSONOutput renders the results of the commands as JSON
+	JSONOutput = "json"
+)
+
+// OutputFormat is the format the results of the commands are rendered in,
+// it is set by the global --output flag
+var OutputFormat = TextOutput
+
+// output is where the results of the commands are written to
+var output io.Writer = os.Stdout
+
+// CheckOutputFormat returns an error if the output format isn't supported
+func CheckOutputFormat() error {
+	if OutputFormat != TextOutput && OutputFormat != JSONOutput {
+		return fmt.Errorf("Unsupported output format %s, must be %s or %s", OutputFormat, TextOutput, JSONOutput)
+	}
+	return nil
+}
+
+// IsJSONOutput returns whether the results of the commands are rendered as JSON
+func IsJSONOutput() bool {
+	return OutputFormat == JSONOutput
+}
+
+// PrintResult writes the result of a command. With the JSON output format
+// result is written as a JSON document on a single line, otherwise the text
+// formatted with format and args is written
+func PrintResult(result interface{}, format string, args ...interface{}) error {
+	if !IsJSONOutput() {
+		_, err := fmt.Fprintf(output, format, args...)
+		return err
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("Error rendering the result as JSON: %s", err)
+	}
+	_, err = fmt.Fprintf(output, "%s\n", raw)
+	return err
+}
+
+// Encodings of the payloads of the proposal results
+const (
+	UTF8Encoding = "utf8"
+	HexEncoding  = "hex"
+)
+
+// ProposalResult is the result of a proposal processed by a peer, such as a
+// chaincode invocation, query or installation, or a channel join
+type ProposalResult struct {
+	Status   int32  `json:"status"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+	Payload  string `json:"payload"`
+	Encoding string `json:"encoding"`
+}
+
+// NewProposalResult returns the result of a proposal from its response. The
+// payload is hex encoded when asHex is set or when it isn't valid UTF-8
+func NewProposalResult(resp *pb.ProposalResponse, asHex bool) *ProposalResult {
+	result := &ProposalResult{Encoding: UTF8Encoding}
+	if resp == nil || resp.Response == nil {
+		return result
+	}
+	result.Status = resp.Response.Status
+	if e := cerrors.FromResponse(resp.Response); e != nil {
+		result.Code = e.Code
+	} else if resp.Response.Code != 0 {
+		result.Code = codes.Code(resp.Response.Code).String()
+	}
+	result.Message = resp.Response.Message
+	if asHex || !utf8.Valid(resp.Response.Payload) {
+		result.Payload = hex.EncodeToString(resp.Response.Payload)
+		result.Encoding = HexEncoding
+	} else {
+		result.Payload = string(resp.Response.Payload)
+	}
+	return result
+}
+
+// CheckProposalResponse returns an error if the response of the peer is an
+// error response, with a status of 400 or more such as the access denials of
+// the peer, carrying the error code of the peer or the status code the
+// chaincode returned if any
+func CheckProposalResponse(resp *pb.ProposalResponse) error {
+	if resp == nil || resp.Response == nil {
+		return fmt.Errorf("Nil proposal response")
+	}
+	if resp.Response.Status < 400 {
+		return nil
+	}
+	if e := cerrors.FromResponse(resp.Response); e != nil {
+		return fmt.Errorf("Bad proposal response %d (%s): %s", resp.Response.Status, e.Code, e.Message)
+	}
+	if resp.Response.Code != 0 {
+		return fmt.Errorf("Bad proposal response %d (%s): %s", resp.Response.Status, codes.Code(resp.Response.Code), resp.Response.Message)
+	}
+	return fmt.Errorf("Bad proposal response %d: %s", resp.Response.Status, resp.Response.Message)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	cerrors "github.com/hyperledger/fabric/common/errors"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestPrintResult(t *testing.T) {
+	buf := &bytes.Buffer{}
+	output = buf
+	defer func() {
+		output = os.Stdout
+		OutputFormat = TextOutput
+	}()
+	resp := &pb.ProposalResponse{Response: &pb.Response{Status: 200, Payload: []byte("100")}}
+
+	assert.NoError(t, PrintResult(NewProposalResult(resp, false), "Query Result: %s\n", "100"))
+	assert.Equal(t, "Query Result: 100\n", buf.String())
+
+	OutputFormat = "yaml"
+	assert.Error(t, CheckOutputFormat())
+	OutputFormat = JSONOutput
+	assert.NoError(t, CheckOutputFormat())
+
+	buf.Reset()
+	assert.NoError(t, PrintResult(NewProposalResult(resp, false), "Query Result: %s\n", "100"))
+	assert.Equal(t, `{"status":200,"message":"","payload":"100","encoding":"utf8"}`+"\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, PrintResult(NewProposalResult(resp, true), ""))
+	assert.Equal(t, `{"status":200,"message":"","payload":"313030","encoding":"hex"}`+"\n", buf.String())
+}
+
+func TestNewProposalResult(t *testing.T) {
+	result := NewProposalResult(&pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: "failed", Payload: []byte{0xff, 0x01}}}, false)
+	assert.Equal(t, &ProposalResult{Status: 500, Message: "failed", Payload: "ff01", Encoding: HexEncoding}, result)
+
+	assert.Equal(t, &ProposalResult{Encoding: UTF8Encoding}, NewProposalResult(nil, false))
+}
+
+func TestCheckProposalResponse(t *testing.T) {
+	assert.NoError(t, CheckProposalResponse(&pb.ProposalResponse{Response: &pb.Response{Status: 200}}))
+	assert.Error(t, CheckProposalResponse(nil))
+	assert.Error(t, CheckProposalResponse(&pb.ProposalResponse{}))
+
+	err := CheckProposalResponse(&pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: "failed"}})
+	assert.EqualError(t, err, "Bad proposal response 500: failed")
+	resp := &pb.ProposalResponse{Response: &pb.Response{Status: 500, Code: int32(codes.NotFound), Message: "no such asset"}}
+	assert.EqualError(t, CheckProposalResponse(resp), "Bad proposal response 500 (NotFound): no such asset")
+	assert.Equal(t, "NotFound", NewProposalResult(resp, false).Code)
+
+	resp = &pb.ProposalResponse{Response: cerrors.Response(403, cerrors.Authorization(cerrors.AccessDenied, "Access denied to chaincode mycc"))}
+	assert.EqualError(t, CheckProposalResponse(resp), "Bad proposal response 403 (ACCESS_DENIED): Access denied to chaincode mycc")
+	assert.Equal(t, cerrors.AccessDenied, NewProposalResult(resp, false).Code)
+}
@@ -0,0 +1,200 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/archive"
+	cutil "github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/scc/qscc"
+	"github.com/hyperledger/fabric/peer/common"
+	pcommon "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func exportCmd(cf *LedgerCmdFactory) *cobra.Command {
+	ledgerExportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Exports a range of blocks of a chain to an archive.",
+		Long: `Exports blocks --from through --to of a chain, together with the config block in effect for
+the first of them, to a portable archive with a manifest of integrity hashes.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return export(cmd, args, cf)
+		},
+	}
+
+	flags := ledgerExportCmd.Flags()
+	flags.StringVarP(&chainID, "chain", "c", "", "The chain to export blocks from.")
+	flags.Uint64Var(&fromBlock, "from", 0, "Number of the first block to export.")
+	flags.Uint64Var(&toBlock, "to", 0, "Number of the last block to export.")
+	flags.StringVarP(&archivePath, "output", "o", "", "Path of the archive to write, defaults to <chain>_<from>_<to>.tar")
+
+	return ledgerExportCmd
+}
+
+// queryLedger invokes fname of QSCC on the chain being exported
+func queryLedger(cf *LedgerCmdFactory, fname string, args ...string) ([]byte, error) {
+	input := &pb.ChaincodeInput{Args: [][]byte{[]byte(fname), []byte(chainID)}}
+	for _, arg := range args {
+		input.Args = append(input.Args, []byte(arg))
+	}
+	invocation := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value["GOLANG"]),
+		ChaincodeId: &pb.ChaincodeID{Name: "qscc"},
+		Input:       input,
+	}}
+
+	creator, err := cf.Signer.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Error serializing identity for %s: %s", cf.Signer.GetIdentifier(), err)
+	}
+
+	prop, err := putils.CreateProposalFromCIS(cutil.GenerateUUID(), pcommon.HeaderType_ENDORSER_TRANSACTION, chainID, invocation, creator)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating proposal for %s: %s", fname, err)
+	}
+	signedProp, err := putils.GetSignedProposal(prop, cf.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating signed proposal: %s", err)
+	}
+
+	proposalResp, err := cf.EndorserClient.ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		return nil, fmt.Errorf("Error endorsing %s: %s", fname, err)
+	}
+	if proposalResp == nil || proposalResp.Response == nil {
+		return nil, fmt.Errorf("Nil proposal response for %s", fname)
+	}
+	if proposalResp.Response.Status != 0 && proposalResp.Response.Status != 200 {
+		return nil, fmt.Errorf("Bad proposal response for %s: %d %s", fname, proposalResp.Response.Status, proposalResp.Response.Message)
+	}
+	if proposalResp.Response.Message == qscc.RedactedView {
+		return nil, fmt.Errorf("Peer returned a redacted view for %s, %s does not satisfy the FullReaders policy of chain %s", fname, cf.Signer.GetIdentifier(), chainID)
+	}
+
+	return proposalResp.Response.Payload, nil
+}
+
+func getBlock(cf *LedgerCmdFactory, number uint64) (*pcommon.Block, error) {
+	payload, err := queryLedger(cf, qscc.GetBlockByNumber, strconv.FormatUint(number, 10))
+	if err != nil {
+		return nil, err
+	}
+	block, err := putils.GetBlockFromBlockBytes(payload)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling block %d: %s", number, err)
+	}
+	if block.Header == nil || block.Header.Number != number {
+		return nil, fmt.Errorf("Peer returned wrong block for block %d", number)
+	}
+	return block, nil
+}
+
+func executeExport(cf *LedgerCmdFactory) (err error) {
+	if chainID == "" {
+		return fmt.Errorf("Must supply the chain to export")
+	}
+	if toBlock < fromBlock {
+		return fmt.Errorf("Invalid range, --to (%d) precedes --from (%d)", toBlock, fromBlock)
+	}
+
+	payload, err := queryLedger(cf, qscc.GetChainInfo)
+	if err != nil {
+		return err
+	}
+	info := &pcommon.BlockchainInfo{}
+	if err = proto.Unmarshal(payload, info); err != nil {
+		return fmt.Errorf("Error unmarshaling chain info: %s", err)
+	}
+	if toBlock >= info.Height {
+		return fmt.Errorf("Chain %s has %d blocks, cannot export up to block %d", chainID, info.Height, toBlock)
+	}
+
+	path := archivePath
+	if path == "" {
+		path = fmt.Sprintf("%s_%d_%d.tar", chainID, fromBlock, toBlock)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Error creating archive %s: %s", path, err)
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	w := archive.NewWriter(file, chainID)
+	for number := fromBlock; number <= toBlock; number++ {
+		block, err := getBlock(cf, number)
+		if err != nil {
+			return err
+		}
+
+		if number == fromBlock {
+			if lastConfig, ok := archive.LastConfigIndex(block); ok && lastConfig < fromBlock {
+				configBlock, err := getBlock(cf, lastConfig)
+				if err != nil {
+					return err
+				}
+				if err = w.AddConfigBlock(configBlock); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err = w.AddBlock(block); err != nil {
+			return err
+		}
+		logger.Debugf("Exported block %d of chain %s", number, chainID)
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	result := &exportResult{ChainID: chainID, FromBlock: fromBlock, ToBlock: toBlock, Path: path}
+	return common.PrintResult(result, "Exported blocks %d to %d of chain %s to %s\n", fromBlock, toBlock, chainID, path)
+}
+
+// exportResult is the result of the export rendered by the json output
+type exportResult struct {
+	ChainID   string `json:"chain_id"`
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+	Path      string `json:"path"`
+}
+
+func export(cmd *cobra.Command, args []string, cf *LedgerCmdFactory) error {
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory()
+		if err != nil {
+			return err
+		}
+	}
+	return executeExport(cf)
+}
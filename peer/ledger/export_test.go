@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/scc/qscc"
+	msptesttools "github.com/hyperledger/fabric/msp/mgmt/testtools"
+	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// qsccEndorserClient answers QSCC queries from an in memory chain
+type qsccEndorserClient struct {
+	blocks []*cb.Block
+}
+
+func (q *qsccEndorserClient) ProcessProposal(ctx context.Context, in *pb.SignedProposal, opts ...grpc.CallOption) (*pb.ProposalResponse, error) {
+	prop, err := utils.GetProposal(in.ProposalBytes)
+	if err != nil {
+		return nil, err
+	}
+	cis, err := utils.GetChaincodeInvocationSpec(prop)
+	if err != nil {
+		return nil, err
+	}
+	args := cis.ChaincodeSpec.Input.Args
+
+	var payload []byte
+	switch string(args[0]) {
+	case qscc.GetChainInfo:
+		last := q.blocks[len(q.blocks)-1]
+		payload = utils.MarshalOrPanic(&cb.BlockchainInfo{Height: uint64(len(q.blocks)), CurrentBlockHash: last.Header.Hash()})
+	case qscc.GetBlockByNumber:
+		number, _ := strconv.Atoi(string(args[2]))
+		payload = utils.MarshalOrPanic(q.blocks[number])
+	default:
+		return &pb.ProposalResponse{Response: &pb.Response{Status: 500}}, nil
+	}
+	return &pb.ProposalResponse{Response: &pb.Response{Status: 200, Payload: payload}}, nil
+}
+
+func makeChain(length int) []*cb.Block {
+	configEnv := &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: &cb.ChannelHeader{Type: int32(cb.HeaderType_CONFIG), ChannelId: "testchain"}},
+		Data:   utils.MarshalOrPanic(&cb.ConfigEnvelope{}),
+	})}
+
+	var blocks []*cb.Block
+	var previousHash []byte
+	for i := 0; i < length; i++ {
+		block := cb.NewBlock(uint64(i), previousHash)
+		if i == 0 {
+			block.Data.Data = [][]byte{utils.MarshalOrPanic(configEnv)}
+		} else {
+			block.Data.Data = [][]byte{[]byte("tx" + strconv.Itoa(i))}
+		}
+		block.Header.DataHash = block.Data.Hash()
+		block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&cb.Metadata{
+			Value:      utils.MarshalOrPanic(&cb.LastConfig{Index: 0}),
+			Signatures: []*cb.MetadataSignature{{Signature: []byte("signature")}},
+		})
+		previousHash = block.Header.Hash()
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func TestExportAndVerify(t *testing.T) {
+	if err := msptesttools.LoadMSPSetupForTesting("../../msp/sampleconfig"); err != nil {
+		t.Fatalf("Error loading MSP setup: %s", err)
+	}
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "ledgerexport")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "export.tar")
+
+	cf := &LedgerCmdFactory{EndorserClient: &qsccEndorserClient{blocks: makeChain(6)}, Signer: signer}
+
+	cmd := exportCmd(cf)
+	cmd.SetArgs([]string{"-c", "testchain", "--from", "2", "--to", "4", "-o", path})
+	if err = cmd.Execute(); err != nil {
+		t.Fatalf("Expected export to succeed, got %s", err)
+	}
+
+	cmd = verifyCmd()
+	cmd.SetArgs([]string{path})
+	if err = cmd.Execute(); err != nil {
+		t.Fatalf("Expected exported archive to verify, got %s", err)
+	}
+
+	cmd = exportCmd(cf)
+	cmd.SetArgs([]string{"-c", "testchain", "--from", "2", "--to", "6", "-o", path})
+	if err = cmd.Execute(); err == nil {
+		t.Fatalf("Expected export beyond the chain height to fail")
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/op/go-logging"
+	"github.com/spf13/cobra"
+)
+
+const ledgerFuncName = "ledger"
+
+var logger = logging.MustGetLogger("ledgerCmd")
+
+var (
+	chainID     string
+	fromBlock   uint64
+	toBlock     uint64
+	archivePath string
+)
+
+// Cmd returns the cobra command for Ledger
+func Cmd(cf *LedgerCmdFactory) *cobra.Command {
+	ledgerCmd.AddCommand(exportCmd(cf))
+	ledgerCmd.AddCommand(verifyCmd())
+	ledgerCmd.AddCommand(moveCmd())
+	ledgerCmd.AddCommand(replayCmd(cf))
+
+	return ledgerCmd
+}
+
+var ledgerCmd = &cobra.Command{
+	Use:   ledgerFuncName,
+	Short: fmt.Sprintf("%s specific commands.", ledgerFuncName),
+	Long:  fmt.Sprintf("%s specific commands.", ledgerFuncName),
+}
+
+// LedgerCmdFactory holds the clients used by LedgerCmd
+type LedgerCmdFactory struct {
+	EndorserClient pb.EndorserClient
+	Signer         msp.SigningIdentity
+}
+
+// InitCmdFactory init the LedgerCmdFactory with default clients
+func InitCmdFactory() (*LedgerCmdFactory, error) {
+	endorserClient, err := common.GetEndorserClient()
+	if err != nil {
+		return nil, fmt.Errorf("Error getting endorser client %s: %s", ledgerFuncName, err)
+	}
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		return nil, fmt.Errorf("Error getting default signer: %s", err)
+	}
+
+	return &LedgerCmdFactory{EndorserClient: endorserClient, Signer: signer}, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/spf13/cobra"
+)
+
+var toPath string
+
+func moveCmd() *cobra.Command {
+	ledgerMoveCmd := &cobra.Command{
+		Use:   "move",
+		Short: "Moves the block files of a chain to another path.",
+		Long: `Moves the block files of a chain from where ledger.blockchain.paths currently stores them to
+the path given by --to, or to peer.fileSystemPath if --to is not given. The peer must be stopped, and
+the chain mapped to its new path in ledger.blockchain.paths before the peer is started again.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return move(cmd, args)
+		},
+	}
+
+	flags := ledgerMoveCmd.Flags()
+	flags.StringVarP(&chainID, "chain", "c", "", "The chain to move the block files of.")
+	flags.StringVar(&toPath, "to", "", "The path to move the block files under, defaults to peer.fileSystemPath")
+
+	return ledgerMoveCmd
+}
+
+func move(cmd *cobra.Command, args []string) error {
+	if chainID == "" {
+		return fmt.Errorf("Must supply the chain to move")
+	}
+
+	dir, err := kvledger.MoveBlockStore(chainID, toPath)
+	if err != nil {
+		return err
+	}
+
+	result := &moveResult{ChainID: chainID, Path: toPath, Dir: dir}
+	if toPath == "" {
+		return common.PrintResult(result, "Moved the block files of chain %s to %s, remove it from ledger.blockchain.paths before starting the peer\n", chainID, dir)
+	}
+	return common.PrintResult(result, "Moved the block files of chain %s to %s, map it to %s in ledger.blockchain.paths before starting the peer\n", chainID, dir, toPath)
+}
+
+// moveResult is the result of the move rendered by the json output
+type moveResult struct {
+	ChainID string `json:"chain_id"`
+	Path    string `json:"path"`
+	Dir     string `json:"dir"`
+}
@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/replay"
+	"github.com/hyperledger/fabric/core/scc/qscc"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/spf13/cobra"
+)
+
+func replayCmd(cf *LedgerCmdFactory) *cobra.Command {
+	ledgerReplayCmd := &cobra.Command{
+		Use:   "replay <txid>",
+		Short: "Replays a committed transaction and reports whether it is reproducible.",
+		Long: `Has the peer execute the chaincode of a committed transaction again against the versions of the keys
+the transaction read, and reports whether the execution reproduces the writes, response and event recorded
+in the transaction. The peer restricts replays to the identities satisfying the FullReaders policy of the channel, if it has one.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return replayTransaction(cmd, args, cf)
+		},
+	}
+
+	flags := ledgerReplayCmd.Flags()
+	flags.StringVarP(&chainID, "chain", "c", "", "The chain of the transaction to replay.")
+
+	return ledgerReplayCmd
+}
+
+func executeReplay(cf *LedgerCmdFactory, txID string) error {
+	if chainID == "" {
+		return fmt.Errorf("Must supply the chain of the transaction to replay")
+	}
+
+	payload, err := queryLedger(cf, qscc.ReplayTransaction, txID)
+	if err != nil {
+		return err
+	}
+	report := &replay.Report{}
+	if err = json.Unmarshal(payload, report); err != nil {
+		return fmt.Errorf("Error unmarshaling the replay report: %s", err)
+	}
+
+	if report.Reproducible {
+		return common.PrintResult(report, "Transaction %s of chaincode %s on chain %s is reproducible\n", txID, report.Chaincode, chainID)
+	}
+	return common.PrintResult(report, "Transaction %s of chaincode %s on chain %s is not reproducible:\n  %s\n",
+		txID, report.Chaincode, chainID, strings.Join(report.Differences, "\n  "))
+}
+
+func replayTransaction(cmd *cobra.Command, args []string, cf *LedgerCmdFactory) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Must supply the ID of the transaction to replay")
+	}
+
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory()
+		if err != nil {
+			return err
+		}
+	}
+	return executeReplay(cf, args[0])
+}
@@ -0,0 +1,78 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric/common/ledger/archive"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/spf13/cobra"
+)
+
+func verifyCmd() *cobra.Command {
+	ledgerVerifyCmd := &cobra.Command{
+		Use:   "verify <archive>",
+		Short: "Verifies the integrity of an exported archive.",
+		Long:  `Verifies the integrity of an archive written by the export command. No peer is required.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verify(cmd, args)
+		},
+	}
+	return ledgerVerifyCmd
+}
+
+func verify(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Must supply the path of the archive to verify")
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("Error opening archive %s: %s", args[0], err)
+	}
+	defer file.Close()
+
+	a, err := archive.Open(file)
+	if err != nil {
+		return err
+	}
+	if err = a.Verify(); err != nil {
+		return fmt.Errorf("Archive %s failed verification: %s", args[0], err)
+	}
+
+	m := a.Manifest()
+	result := &verifyResult{
+		Archive:      args[0],
+		ChainID:      m.ChainID,
+		FromBlock:    m.From,
+		ToBlock:      m.To,
+		ConfigBlocks: len(m.ConfigBlocks),
+	}
+	return common.PrintResult(result, "Archive %s verified: chain %s, blocks %d to %d, %d config block(s) preceding the range\n",
+		args[0], m.ChainID, m.From, m.To, len(m.ConfigBlocks))
+}
+
+// verifyResult is the result of the verification rendered by the json output
+type verifyResult struct {
+	Archive      string `json:"archive"`
+	ChainID      string `json:"chain_id"`
+	FromBlock    uint64 `json:"from_block"`
+	ToBlock      uint64 `json:"to_block"`
+	ConfigBlocks int    `json:"config_blocks"`
+}
@@ -28,12 +28,14 @@ import (
 
 	_ "net/http/pprof"
 
+	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core"
 	"github.com/hyperledger/fabric/peer/chaincode"
 	"github.com/hyperledger/fabric/peer/channel"
 	"github.com/hyperledger/fabric/peer/clilogging"
 	"github.com/hyperledger/fabric/peer/common"
+	"github.com/hyperledger/fabric/peer/ledger"
 	"github.com/hyperledger/fabric/peer/node"
 	"github.com/hyperledger/fabric/peer/version"
 )
@@ -52,6 +54,10 @@ var mainCmd = &cobra.Command{
 		peerCommand := getPeerCommandFromCobraCommand(cmd)
 		flogging.InitFromViper(peerCommand)
 
+		if err := common.CheckOutputFormat(); err != nil {
+			return err
+		}
+
 		return core.CacheConfiguration()
 	},
 	Run: func(cmd *cobra.Command, args []string) {
@@ -80,19 +86,31 @@ func main() {
 
 	mainFlags.String("logging-level", "", "Default logging level and overrides, see core.yaml for full syntax")
 	viper.BindPFlag("logging_level", mainFlags.Lookup("logging-level"))
+	mainFlags.StringVar(&common.OutputFormat, "output", common.TextOutput, "Format of the command results, text or json")
 	testCoverProfile := ""
 	mainFlags.StringVarP(&testCoverProfile, "test.coverprofile", "", "coverage.cov", "Done")
 
+	// validate-config reports the errors of the configuration itself
+	validatingConfig := node.IsValidateConfig(os.Args[1:])
+
 	err := common.InitConfig(cmdRoot)
-	if err != nil { // Handle errors reading the config file
+	if err != nil && !validatingConfig { // Handle errors reading the config file
 		panic(fmt.Errorf("Fatal error when initializing %s config : %s\n", cmdRoot, err))
 	}
 
+	// Select the crypto provider before anything uses the default one
+	if provider := viper.GetString("peer.BCCSP.Default"); provider != "" {
+		if err = factory.InitFactories(provider); err != nil && !validatingConfig {
+			panic(fmt.Errorf("Fatal error when initializing the BCCSP : %s\n", err))
+		}
+	}
+
 	mainCmd.AddCommand(version.Cmd())
 	mainCmd.AddCommand(node.Cmd())
 	mainCmd.AddCommand(chaincode.Cmd(nil))
 	mainCmd.AddCommand(clilogging.Cmd())
 	mainCmd.AddCommand(channel.Cmd(nil))
+	mainCmd.AddCommand(ledger.Cmd(nil))
 
 	runtime.GOMAXPROCS(viper.GetInt("peer.gomaxprocs"))
 
@@ -109,7 +127,7 @@ func main() {
 	} // FIXME: remove this line as soon as GOSSIP GETS the MSP ID from the genesis block
 
 	err = common.InitCrypto(mspMgrConfigDir, mspID)
-	if err != nil { // Handle errors reading the config file
+	if err != nil && !validatingConfig { // Handle errors reading the config file
 		panic(err.Error())
 	}
 	// On failure Cobra prints the usage message and error string, so we only
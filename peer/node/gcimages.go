@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var gcDryRun bool
+
+func gcImagesCmd() *cobra.Command {
+	nodeGCImagesCmd.Flags().BoolVarP(&gcDryRun, "dryrun", "", false,
+		"List the images and containers which would be removed without removing them")
+	return nodeGCImagesCmd
+}
+
+var nodeGCImagesCmd = &cobra.Command{
+	Use:   "gcimages",
+	Short: "Removes the images of undefined chaincode versions.",
+	Long:  `Removes the images and containers of the chaincode versions no longer defined on any channel of the running node.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return gcImages(gcDryRun)
+	},
+}
+
+// collectedImages is the result of a collection rendered by the json output
+type collectedImages struct {
+	DryRun     bool     `json:"dry_run"`
+	Images     []string `json:"images"`
+	Containers []string `json:"containers"`
+	Retained   []string `json:"retained"`
+}
+
+func gcImages(dryRun bool) error {
+	adminClient, err := common.GetAdminClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := adminClient.CollectChaincodeImages(context.Background(), &pb.CollectImagesRequest{DryRun: dryRun})
+	if err != nil {
+		return fmt.Errorf("Error collecting chaincode images on the local peer: %s", err)
+	}
+	result := &collectedImages{
+		DryRun:     dryRun,
+		Images:     resp.Images,
+		Containers: resp.Containers,
+		Retained:   resp.Retained,
+	}
+	return common.PrintResult(result, "Images: %v\nContainers: %v\nRetained: %v\n", resp.Images, resp.Containers, resp.Retained)
+}
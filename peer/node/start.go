@@ -17,30 +17,46 @@ limitations under the License.
 package node
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"expvar"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/hyperledger/fabric/common/configtx/test"
 	"github.com/hyperledger/fabric/common/genesis"
+	"github.com/hyperledger/fabric/common/localmsp"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/common/wsgateway"
 	"github.com/hyperledger/fabric/core"
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/core/committer/txvalidator"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	"github.com/hyperledger/fabric/core/container/dockercontroller"
 	"github.com/hyperledger/fabric/core/endorser"
+	"github.com/hyperledger/fabric/core/gateway"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
 	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/core/rest"
 	"github.com/hyperledger/fabric/core/scc"
+	"github.com/hyperledger/fabric/core/statesync"
 	"github.com/hyperledger/fabric/events/producer"
+	gossipcommon "github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/service"
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -90,6 +106,10 @@ func serve(args []string) error {
 
 	}
 
+	if peer.IsReadOnly() {
+		logger.Info("Running as a read-only peer, proposals will not be endorsed")
+	}
+
 	if err := peer.CacheConfiguration(); err != nil {
 		return err
 	}
@@ -135,12 +155,28 @@ func serve(args []string) error {
 	logger.Debugf("Running peer")
 
 	// Register the Admin server
-	pb.RegisterAdminServer(grpcServer.Server(), core.NewAdminServer())
+	adminServer := core.NewAdminServer()
+	adminServer.SetImageGC(newImageGC())
+	pb.RegisterAdminServer(grpcServer.Server(), adminServer)
 
 	// Register the Endorser server
 	serverEndorser := endorser.NewEndorserServer()
 	pb.RegisterEndorserServer(grpcServer.Server(), serverEndorser)
 
+	// Register the StateSync server
+	if viper.GetBool("peer.stateSync.enabled") {
+		pb.RegisterStateSyncServer(grpcServer.Server(), statesync.NewStateSyncServer(peer.GetLedger, peer.GetPolicyManager))
+	}
+
+	// Register the Gateway server
+	if viper.GetBool("peer.gateway.enabled") {
+		gatewayServer, err := newGatewayServer(serverEndorser)
+		if err != nil {
+			logger.Fatalf("Failed to create the gateway server: %s", err)
+		}
+		pb.RegisterGatewayServer(grpcServer.Server(), gatewayServer)
+	}
+
 	// Initialize gossip component
 	bootstrap := viper.GetStringSlice("peer.gossip.bootstrap")
 
@@ -150,7 +186,8 @@ func serve(args []string) error {
 	}
 
 	service.InitGossipService(serializedIdentity, peerEndpoint.Address, grpcServer.Server(), bootstrap...)
-	defer service.GetGossipService().Stop()
+	var stopGossip sync.Once
+	defer stopGossip.Do(service.GetGossipService().Stop)
 
 	//initialize system chaincodes
 	initSysCCs()
@@ -187,16 +224,10 @@ func serve(args []string) error {
 
 	// Start the grpc server. Done in a goroutine so we can deploy the
 	// genesis block if needed.
-	serve := make(chan error)
+	serve := make(chan error, 1)
 
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		sig := <-sigs
-		fmt.Println()
-		fmt.Println(sig)
-		serve <- nil
-	}()
 
 	go func() {
 		var grpcErr error
@@ -215,10 +246,27 @@ func serve(args []string) error {
 	// Start the event hub server
 	if ehubGrpcServer != nil {
 		go ehubGrpcServer.Start()
+
+		if viper.GetBool("peer.events.websocket.enabled") {
+			if err := startEventsWebSocketGateway(ehubGrpcServer.Address()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Start the REST gateway to the read only services if enabled
+	if viper.GetBool("peer.rest.enabled") {
+		if err := startRESTGateway(adminServer, serverEndorser); err != nil {
+			return err
+		}
 	}
 
-	// Start profiling http endpoint if enabled
+	// Start profiling http endpoint if enabled, it serves the validation
+	// metrics under /debug/vars as well
 	if viper.GetBool("peer.profile.enabled") {
+		expvar.Publish("validation", expvar.Func(func() interface{} {
+			return txvalidator.GetValidationMetrics()
+		}))
 		go func() {
 			profileListenAddress := viper.GetString("peer.profile.listenAddress")
 			logger.Infof("Starting profiling server with listenAddress = %s", profileListenAddress)
@@ -228,14 +276,68 @@ func serve(args []string) error {
 		}()
 	}
 
+	// Refresh the local MSP set up from a fabric-ca server if enabled
+	if interval := viper.GetDuration("peer.mspFabricCA.refreshInterval"); interval > 0 {
+		go refreshMSPFromFabricCA(interval)
+	}
+
 	// sets the logging level for the 'error' and 'msp' modules to the
 	// values from core.yaml. they can also be updated dynamically using
 	// "peer logging setlevel <module-name> <log-level>"
 	common.SetLogLevelFromViper("error")
 	common.SetLogLevelFromViper("msp")
 
-	// Block until grpc server exits
-	return <-serve
+	// Block until grpc server exits or the peer is signaled to stop
+	select {
+	case err := <-serve:
+		return err
+	case sig := <-sigs:
+		fmt.Println()
+		fmt.Println(sig)
+		shutdown(viper.GetDuration("peer.shutdownTimeout"), grpcServer, ehubGrpcServer, &stopGossip)
+		return nil
+	}
+}
+
+// refreshMSPFromFabricCA sets up the local MSP again every interval from the
+// material of the fabric-ca server it was set up from, if it was
+func refreshMSPFromFabricCA(interval time.Duration) {
+	for range time.Tick(interval) {
+		refreshed, err := common.RefreshCryptoFromFabricCA()
+		if !refreshed && err == nil {
+			return
+		}
+		if err != nil {
+			logger.Warningf("Failed refreshing the local MSP from the fabric-ca server, keeping the current one: %s", err)
+			continue
+		}
+		logger.Info("Refreshed the local MSP from the fabric-ca server")
+	}
+}
+
+// shutdown stops the peer within timeout: the proposals received from now on are rejected, the
+// endorsements in flight complete, gossip leaves the network and the ledgers are closed once the
+// blocks being committed are
+func shutdown(timeout time.Duration, grpcServer comm.GRPCServer, ehubGrpcServer comm.GRPCServer, stopGossip *sync.Once) {
+	done := make(chan struct{})
+	go func() {
+		if !grpcServer.GracefulStop(timeout) {
+			logger.Warningf("Shutdown timeout of %s exceeded, dropping the requests in flight", timeout)
+		}
+		if ehubGrpcServer != nil {
+			ehubGrpcServer.Stop()
+		}
+		stopGossip.Do(service.GetGossipService().Stop)
+		ledgermgmt.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Infof("Peer stopped")
+	case <-time.After(timeout):
+		logger.Warningf("Shutdown timeout of %s exceeded, exiting before the ledgers are closed", timeout)
+	}
 }
 
 //NOTE - when we implment JOIN we will no longer pass the chainID as param
@@ -278,10 +380,142 @@ func createEventHubServer(secureConfig comm.SecureServerConfig) (comm.GRPCServer
 		uint(viper.GetInt("peer.events.buffersize")),
 		viper.GetInt("peer.events.timeout"))
 
+	consumerConfig := producer.ConsumerBufferConfig{
+		BufferSize: viper.GetInt("peer.events.consumer.buffersize"),
+		Policy:     producer.SlowConsumerPolicy(viper.GetString("peer.events.consumer.policy")),
+		Timeout:    viper.GetDuration("peer.events.consumer.timeout"),
+	}
+	if err = producer.SetConsumerBufferConfig(consumerConfig); err != nil {
+		return nil, fmt.Errorf("invalid event consumer buffering: %s", err)
+	}
+
+	if viper.GetBool("peer.events.integrity") {
+		logger.Info("Attaching integrity proofs to events")
+		producer.EnableIntegrityProofs(localmsp.NewSigner())
+	}
+
 	pb.RegisterEventsServer(grpcServer.Server(), ehServer)
 	return grpcServer, nil
 }
 
+// startEventsWebSocketGateway serves the events over WebSocket on its own listener,
+// relaying the connections to the event hub server on address
+func startEventsWebSocketGateway(address string) error {
+	conn, err := comm.NewClientConnectionWithAddress(address, false, comm.TLSEnabled(), comm.InitTLSForPeer())
+	if err != nil {
+		return fmt.Errorf("failed to connect the WebSocket gateway to the event hub: %s", err)
+	}
+
+	lis, err := net.Listen("tcp", viper.GetString("peer.events.websocket.address"))
+	if err != nil {
+		return fmt.Errorf("failed to listen for WebSocket connections: %s", err)
+	}
+	if viper.GetBool("peer.events.websocket.tls.enabled") {
+		keyPair, err := tls.LoadX509KeyPair(viper.GetString("peer.events.websocket.tls.cert.file"), viper.GetString("peer.events.websocket.tls.key.file"))
+		if err != nil {
+			return fmt.Errorf("failed to load the TLS key pair of the WebSocket gateway: %s", err)
+		}
+		lis = tls.NewListener(lis, &tls.Config{Certificates: []tls.Certificate{keyPair}})
+	}
+
+	gateway := wsgateway.New(wsgateway.Config{AllowedOrigins: viper.GetStringSlice("peer.events.websocket.allowedOrigins")}, nil, pb.NewEventsClient(conn))
+	logger.Infof("Serving the events over WebSocket on %s", lis.Addr())
+	go func() {
+		logger.Errorf("WebSocket gateway stopped: %s", http.Serve(lis, gateway))
+	}()
+	return nil
+}
+
+// restSupport provides the REST gateway with the channels of the peer and
+// their members discovered by gossip
+type restSupport struct{}
+
+func (restSupport) ChannelIDs() []string {
+	return peer.GetChainIDs()
+}
+
+func (restSupport) PeersOfChannel(chainID string) []rest.Peer {
+	var peers []rest.Peer
+	for _, member := range service.GetGossipService().PeersOfChannel(gossipcommon.ChainID(chainID)) {
+		peers = append(peers, rest.Peer{Endpoint: member.Endpoint, PKIID: hex.EncodeToString(member.PKIid)})
+	}
+	return peers
+}
+
+// newGatewayServer creates the Gateway server submitting transactions endorsed
+// by this peer and the configured peers to the orderer of the committer
+func newGatewayServer(serverEndorser pb.EndorserServer) (pb.GatewayServer, error) {
+	endorsers := []gateway.Endorser{serverEndorser}
+	for _, address := range viper.GetStringSlice("peer.gateway.endorsers") {
+		client, err := common.GetEndorserClientForPeer(address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to endorser %s: %s", address, err)
+		}
+		endorsers = append(endorsers, gateway.EndorserClient(client))
+	}
+
+	broadcast := func(env *cb.Envelope) error {
+		client, err := common.GetBroadcastClient()
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		return client.Send(env)
+	}
+
+	return gateway.NewGatewayServer(gateway.Config{
+		Endorsers:     endorsers,
+		Broadcast:     broadcast,
+		GetLedger:     peer.GetLedger,
+		MaxRetries:    viper.GetInt("peer.gateway.maxRetries"),
+		CommitTimeout: viper.GetDuration("peer.gateway.commitTimeout"),
+	}), nil
+}
+
+// startRESTGateway serves the REST endpoints of the read only services on
+// their own listener. As the queries are signed by the peer, the gateway only
+// starts if its clients are authenticated by a TLS certificate issued by one of
+// the client root CAs, and only queries the ledger for the admins of the local MSP
+func startRESTGateway(adminServer pb.AdminServer, serverEndorser pb.EndorserServer) error {
+	if !viper.GetBool("peer.rest.tls.enabled") {
+		return fmt.Errorf("the REST gateway requires peer.rest.tls.enabled, as its clients query the ledger as the peer")
+	}
+	files := viper.GetStringSlice("peer.rest.tls.clientRootCAs.files")
+	if len(files) == 0 {
+		return fmt.Errorf("the REST gateway requires peer.rest.tls.clientRootCAs.files, as its clients query the ledger as the peer")
+	}
+	keyPair, err := tls.LoadX509KeyPair(viper.GetString("peer.rest.tls.cert.file"), viper.GetString("peer.rest.tls.key.file"))
+	if err != nil {
+		return fmt.Errorf("failed to load the TLS key pair of the REST gateway: %s", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{keyPair},
+		ClientCAs:    x509.NewCertPool(),
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	for _, file := range files {
+		rootCA, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read the client root CA %s of the REST gateway: %s", file, err)
+		}
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(rootCA) {
+			return fmt.Errorf("failed to decode the client root CA %s of the REST gateway", file)
+		}
+	}
+	lis, err := net.Listen("tcp", viper.GetString("peer.rest.address"))
+	if err != nil {
+		return fmt.Errorf("failed to listen for REST requests: %s", err)
+	}
+	lis = tls.NewListener(lis, tlsConfig)
+
+	gateway := rest.NewGateway(restSupport{}, adminServer, serverEndorser, mgmt.GetLocalSigningIdentityOrPanic(), mgmt.GetLocalMSP())
+	logger.Infof("Serving REST requests on %s", lis.Addr())
+	go func() {
+		logger.Errorf("REST gateway stopped: %s", http.Serve(lis, gateway))
+	}()
+	return nil
+}
+
 func writePid(fileName string, pid int) error {
 	err := os.MkdirAll(filepath.Dir(fileName), 0755)
 	if err != nil {
@@ -318,3 +552,32 @@ func writePid(fileName string, pid int) error {
 	}
 	return nil
 }
+
+// newImageGC creates the collector of the images of the chaincode versions no
+// longer defined on any chain, and starts it if enabled
+func newImageGC() *dockercontroller.ImageGC {
+	gc := dockercontroller.NewImageGC(viper.GetString("peer.networkId"), viper.GetString("peer.id"),
+		viper.GetDuration("vm.docker.imageGC.gracePeriod"), definedChaincodes)
+	if viper.GetBool("vm.docker.imageGC.enabled") {
+		interval := viper.GetDuration("vm.docker.imageGC.interval")
+		if interval <= 0 {
+			interval = time.Hour
+		}
+		logger.Infof("Collecting the images of undefined chaincode versions every %s", interval)
+		gc.Start(interval)
+	}
+	return gc
+}
+
+// definedChaincodes returns the chaincode versions defined on the chains of the peer
+func definedChaincodes() ([]ccintf.CCID, error) {
+	cds, err := peer.GetDefinedChaincodes()
+	if err != nil {
+		return nil, err
+	}
+	ccids := make([]ccintf.CCID, len(cds))
+	for i, cd := range cds {
+		ccids[i] = ccintf.CCID{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: cd.Name}}, Version: cd.Version, NamespacePrefix: cd.NamespacePrefix}
+	}
+	return ccids, nil
+}
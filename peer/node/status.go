@@ -44,7 +44,7 @@ func status() (err error) {
 	adminClient, err := common.GetAdminClient()
 	if err != nil {
 		logger.Warningf("%s", err)
-		fmt.Println(&pb.ServerStatus{Status: pb.ServerStatus_UNKNOWN})
+		printStatus(&pb.ServerStatus{Status: pb.ServerStatus_UNKNOWN})
 		return err
 	}
 
@@ -52,9 +52,17 @@ func status() (err error) {
 	if err != nil {
 		logger.Infof("Error trying to get status from local peer: %s", err)
 		err = fmt.Errorf("Error trying to connect to local peer: %s", err)
-		fmt.Println(&pb.ServerStatus{Status: pb.ServerStatus_UNKNOWN})
+		printStatus(&pb.ServerStatus{Status: pb.ServerStatus_UNKNOWN})
 		return err
 	}
-	fmt.Println(status)
-	return nil
+	return printStatus(status)
+}
+
+// nodeStatus is the status of the node rendered by the json output
+type nodeStatus struct {
+	Status string `json:"status"`
+}
+
+func printStatus(status *pb.ServerStatus) error {
+	return common.PrintResult(&nodeStatus{Status: status.Status.String()}, "%v\n", status)
 }
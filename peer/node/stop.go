@@ -73,12 +73,12 @@ func stop() (err error) {
 
 	status, err := serverClient.StopServer(context.Background(), &empty.Empty{})
 	if err != nil {
-		fmt.Println(&pb.ServerStatus{Status: pb.ServerStatus_STOPPED})
+		printStatus(&pb.ServerStatus{Status: pb.ServerStatus_STOPPED})
 		return nil
 	}
 
 	err = fmt.Errorf("Connection remain opened, peer process doesn't exit")
-	fmt.Println(status)
+	printStatus(status)
 	return err
 }
 
@@ -0,0 +1,271 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/common/configcheck"
+	"github.com/hyperledger/fabric/common/viperutil"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/events/producer"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const validateConfigFuncName = "validate-config"
+
+// cmdRoot is the name of the configuration file of the peer, and the prefix of
+// the environment variables overriding its settings
+const cmdRoot = "core"
+
+// unlistedSettings are the settings read by the peer which core.yaml may not list
+var unlistedSettings = []string{"logging_level"}
+
+func validateConfigCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   validateConfigFuncName,
+		Short: "Validates the configuration of the peer.",
+		Long: "Validates core.yaml, the files it references and the environment variables overriding it, " +
+			"and prints the effective configuration along with the errors found.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return validateConfig()
+		},
+	}
+}
+
+// IsValidateConfig returns whether args run validate-config, for which the
+// errors of the configuration are reported by the command rather than fatal
+// when the peer command initializes
+func IsValidateConfig(args []string) bool {
+	var commands []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			commands = append(commands, arg)
+		}
+	}
+	for i := 0; i+1 < len(commands); i++ {
+		if commands[i] == nodeFuncName && commands[i+1] == validateConfigFuncName {
+			return true
+		}
+	}
+	return false
+}
+
+// configValidation is the result of validate-config
+type configValidation struct {
+	ConfigFile string                 `json:"configFile"`
+	Settings   map[string]interface{} `json:"settings"`
+	// Overrides are the environment variables overriding settings
+	Overrides []string `json:"overrides"`
+	// UnknownOverrides are the environment variables with the prefix of the
+	// overrides which do not override any setting, likely misspelled
+	UnknownOverrides []string `json:"unknownOverrides"`
+	Errors           []string `json:"errors"`
+}
+
+func validateConfig() error {
+	result := &configValidation{ConfigFile: viper.ConfigFileUsed(), Settings: make(map[string]interface{})}
+	checker := &configcheck.Checker{}
+	if err := common.InitConfig(cmdRoot); err != nil {
+		checker.Errorf("config", "%s", strings.TrimSpace(err.Error()))
+	}
+
+	keys := append(settingKeys("", viper.AllSettings()), unlistedSettings...)
+	envNames := make(map[string]bool)
+	for _, key := range keys {
+		if common.IsSecretSetting(key) {
+			result.Settings[key] = "REDACTED"
+		} else {
+			result.Settings[key] = viper.Get(key)
+		}
+		envNames[strings.ToUpper(strings.Replace(key, ".", "_", -1))] = true
+	}
+	result.Overrides, result.UnknownOverrides = configcheck.EnvOverrides(cmdRoot, func(name string) bool {
+		return envNames[name]
+	})
+
+	checkPeerConfig(checker)
+	result.Errors = checker.Errors
+
+	if err := common.PrintResult(result, "%s", renderConfigValidation(result)); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("The configuration has %d errors", len(result.Errors))
+	}
+	return nil
+}
+
+// settingKeys returns the dotted keys of the leaves of settings
+func settingKeys(prefix string, settings map[string]interface{}) []string {
+	var keys []string
+	for key, value := range settings {
+		if prefix == "" && strings.Contains(key, ".") {
+			// an override of a nested setting, e.g. a resolved secret
+			continue
+		}
+		key = prefix + key
+		switch value := value.(type) {
+		case map[string]interface{}:
+			keys = append(keys, settingKeys(key+".", value)...)
+		case map[interface{}]interface{}:
+			m := make(map[string]interface{}, len(value))
+			for k, v := range value {
+				m[fmt.Sprint(k)] = v
+			}
+			keys = append(keys, settingKeys(key+".", m)...)
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func renderConfigValidation(result *configValidation) string {
+	var buf []string
+	buf = append(buf, fmt.Sprintf("Configuration file: %s", result.ConfigFile), "Effective configuration:")
+	overridden := make(map[string]bool)
+	for _, name := range result.Overrides {
+		overridden[name] = true
+	}
+	keys := make([]string, 0, len(result.Settings))
+	for key := range result.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := fmt.Sprint(result.Settings[key])
+		if strings.Contains(value, "\n") {
+			value = fmt.Sprintf("%q", value)
+		}
+		line := fmt.Sprintf("  %s = %s", key, value)
+		env := strings.ToUpper(cmdRoot + "_" + strings.Replace(key, ".", "_", -1))
+		for _, name := range []string{env, env + viperutil.FileSuffix} {
+			if overridden[name] {
+				line += fmt.Sprintf(" (%s)", name)
+			}
+		}
+		buf = append(buf, line)
+	}
+	if len(result.UnknownOverrides) > 0 {
+		buf = append(buf, "Environment variables not overriding any setting:")
+		for _, name := range result.UnknownOverrides {
+			buf = append(buf, "  "+name)
+		}
+	}
+	if len(result.Errors) == 0 {
+		buf = append(buf, "The configuration is valid")
+	} else {
+		buf = append(buf, "Errors:")
+		for _, err := range result.Errors {
+			buf = append(buf, "  "+err)
+		}
+	}
+	return strings.Join(buf, "\n") + "\n"
+}
+
+// checkPeerConfig checks the settings of the peer and the files they reference
+func checkPeerConfig(c *configcheck.Checker) {
+	c.Address("peer.address", viper.GetString("peer.address"))
+	if address := viper.GetString("peer.listenAddress"); address != "" {
+		c.Address("peer.listenAddress", address)
+	}
+	c.Address("peer.events.address", viper.GetString("peer.events.address"))
+	for i, address := range viper.GetStringSlice("peer.gossip.bootstrap") {
+		c.Address(fmt.Sprintf("peer.gossip.bootstrap[%d]", i), address)
+	}
+	for _, key := range []string{"peer.gossip.endpoint", "peer.gossip.externalEndpoint"} {
+		if address := viper.GetString(key); address != "" {
+			c.Address(key, address)
+		}
+	}
+	c.Directory("peer.fileSystemPath", viper.GetString("peer.fileSystemPath"), true)
+
+	if c.NotEmpty("peer.localMspId", viper.GetString("peer.localMspId")) {
+		mspDir := viper.GetString("peer.mspConfigPath")
+		if viper.GetString("peer.mspFabricCA.url") == "" {
+			c.MSPDir("peer.mspConfigPath", mspDir, viper.GetString("peer.localMspId"))
+		} else {
+			// the CA chain and admins are fetched from the server at startup
+			c.Directory("peer.mspConfigPath", mspDir, false)
+			checkKeyPair(c, "peer.mspFabricCA.enrollment")
+			checkOptionalCertificates(c, "peer.mspFabricCA.tls.rootcert.file")
+		}
+	}
+	if provider := viper.GetString("peer.BCCSP.Default"); provider != "" {
+		c.OneOf("peer.BCCSP.Default", provider, factory.SoftwareBasedFactoryName)
+	}
+
+	if viper.GetBool("peer.tls.enabled") {
+		checkKeyPair(c, "peer.tls")
+		checkOptionalCertificates(c, "peer.tls.rootcert.file")
+	}
+	c.OneOf("peer.events.consumer.policy", viper.GetString("peer.events.consumer.policy"),
+		string(producer.DropOldest), string(producer.Disconnect), string(producer.BlockWithTimeout))
+	if viper.GetBool("peer.events.websocket.enabled") {
+		c.Address("peer.events.websocket.address", viper.GetString("peer.events.websocket.address"))
+		if viper.GetBool("peer.events.websocket.tls.enabled") {
+			checkKeyPair(c, "peer.events.websocket.tls")
+		}
+	}
+	if viper.GetBool("peer.rest.enabled") {
+		c.Address("peer.rest.address", viper.GetString("peer.rest.address"))
+		if !viper.GetBool("peer.rest.tls.enabled") {
+			c.Errorf("peer.rest.tls.enabled", "must be true when the REST gateway is enabled")
+		} else {
+			checkKeyPair(c, "peer.rest.tls")
+			files := viper.GetStringSlice("peer.rest.tls.clientRootCAs.files")
+			if len(files) == 0 {
+				c.Errorf("peer.rest.tls.clientRootCAs.files", "must not be empty when the REST gateway is enabled")
+			}
+			for _, file := range files {
+				c.Certificates("peer.rest.tls.clientRootCAs.files", c.File("peer.rest.tls.clientRootCAs.files", file))
+			}
+		}
+	}
+	if viper.GetBool("peer.profile.enabled") {
+		c.Address("peer.profile.listenAddress", viper.GetString("peer.profile.listenAddress"))
+	}
+
+	if viper.GetBool("vm.docker.tls.enabled") {
+		checkKeyPair(c, "vm.docker.tls")
+		c.Certificates("vm.docker.tls.ca.file", c.File("vm.docker.tls.ca.file", viper.GetString("vm.docker.tls.ca.file")))
+	}
+	c.OneOf("ledger.blockchain.storage", ledgerconfig.GetBlockStorage(),
+		ledgerconfig.FileBlockStorage, ledgerconfig.LevelDBBlockStorage)
+	c.OneOf("chaincode.mode", viper.GetString("chaincode.mode"), "dev", "net")
+}
+
+// checkKeyPair checks the certificate and private key files under prefix
+func checkKeyPair(c *configcheck.Checker, prefix string) {
+	certKey, keyKey := prefix+".cert.file", prefix+".key.file"
+	cert := c.File(certKey, viper.GetString(certKey))
+	c.Certificates(certKey, cert)
+	c.KeyPair(prefix, cert, c.File(keyKey, viper.GetString(keyKey)))
+}
+
+// checkOptionalCertificates checks the certificates of the file of key, if set
+func checkOptionalCertificates(c *configcheck.Checker, key string) {
+	if file := viper.GetString(key); file != "" {
+		c.Certificates(key, c.File(key, file))
+	}
+}
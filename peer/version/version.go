@@ -17,9 +17,8 @@ limitations under the License.
 package version
 
 import (
-	"fmt"
-
 	"github.com/hyperledger/fabric/common/metadata"
+	"github.com/hyperledger/fabric/peer/common"
 	"github.com/spf13/cobra"
 )
 
@@ -37,7 +36,12 @@ var cobraCommand = &cobra.Command{
 	},
 }
 
+// versionInfo is the version rendered by the json output
+type versionInfo struct {
+	Version string `json:"version"`
+}
+
 // Print outputs the current executable version to stdout
 func Print() {
-	fmt.Printf("Fabric peer server version %s\n", metadata.Version)
+	common.PrintResult(&versionInfo{Version: metadata.Version}, "Fabric peer server version %s\n", metadata.Version)
 }
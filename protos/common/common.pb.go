@@ -26,6 +26,8 @@ It has these top-level messages:
 	BlockHeader
 	BlockData
 	BlockMetadata
+	IntegrityProof
+	ErrorInfo
 	ConfigEnvelope
 	ConfigGroupSchema
 	ConfigValueSchema
@@ -37,16 +39,22 @@ It has these top-level messages:
 	ConfigValue
 	ConfigPolicy
 	ConfigSignature
+	ConfigSnapshot
 	HashingAlgorithm
 	BlockDataHashingStructure
 	OrdererAddresses
+	Capabilities
 	BlockchainInfo
+	ConfigBlockInfo
+	ConfigBlocksInfo
 	MSPPrincipal
 	OrganizationUnit
 	MSPRole
 	Policy
 	SignaturePolicyEnvelope
 	SignaturePolicy
+	PolicySimulation
+	PolicyBranch
 */
 package common
 
@@ -78,6 +86,7 @@ const (
 	Status_REQUEST_ENTITY_TOO_LARGE Status = 413
 	Status_INTERNAL_SERVER_ERROR    Status = 500
 	Status_SERVICE_UNAVAILABLE      Status = 503
+	Status_INSUFFICIENT_STORAGE     Status = 507
 )
 
 var Status_name = map[int32]string{
@@ -89,6 +98,7 @@ var Status_name = map[int32]string{
 	413: "REQUEST_ENTITY_TOO_LARGE",
 	500: "INTERNAL_SERVER_ERROR",
 	503: "SERVICE_UNAVAILABLE",
+	507: "INSUFFICIENT_STORAGE",
 }
 var Status_value = map[string]int32{
 	"UNKNOWN":                  0,
@@ -99,6 +109,7 @@ var Status_value = map[string]int32{
 	"REQUEST_ENTITY_TOO_LARGE": 413,
 	"INTERNAL_SERVER_ERROR":    500,
 	"SERVICE_UNAVAILABLE":      503,
+	"INSUFFICIENT_STORAGE":     507,
 }
 
 func (x Status) String() string {
@@ -147,6 +158,8 @@ const (
 	BlockMetadataIndex_LAST_CONFIG         BlockMetadataIndex = 1
 	BlockMetadataIndex_TRANSACTIONS_FILTER BlockMetadataIndex = 2
 	BlockMetadataIndex_ORDERER             BlockMetadataIndex = 3
+	BlockMetadataIndex_COMMIT_HASH         BlockMetadataIndex = 4
+	BlockMetadataIndex_MVCC_CONFLICTS      BlockMetadataIndex = 5
 )
 
 var BlockMetadataIndex_name = map[int32]string{
@@ -154,12 +167,16 @@ var BlockMetadataIndex_name = map[int32]string{
 	1: "LAST_CONFIG",
 	2: "TRANSACTIONS_FILTER",
 	3: "ORDERER",
+	4: "COMMIT_HASH",
+	5: "MVCC_CONFLICTS",
 }
 var BlockMetadataIndex_value = map[string]int32{
 	"SIGNATURES":          0,
 	"LAST_CONFIG":         1,
 	"TRANSACTIONS_FILTER": 2,
 	"ORDERER":             3,
+	"COMMIT_HASH":         4,
+	"MVCC_CONFLICTS":      5,
 }
 
 func (x BlockMetadataIndex) String() string {
@@ -167,6 +184,41 @@ func (x BlockMetadataIndex) String() string {
 }
 func (BlockMetadataIndex) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
+// ErrorCategory tells the clients of the peer and orderer APIs how to react to
+// an error, whatever its code
+type ErrorCategory int32
+
+const (
+	ErrorCategory_UNCATEGORIZED ErrorCategory = 0
+	ErrorCategory_AUTHORIZATION ErrorCategory = 1
+	ErrorCategory_VALIDATION    ErrorCategory = 2
+	ErrorCategory_CONFLICT      ErrorCategory = 3
+	ErrorCategory_UNAVAILABLE   ErrorCategory = 4
+	ErrorCategory_INTERNAL      ErrorCategory = 5
+)
+
+var ErrorCategory_name = map[int32]string{
+	0: "UNCATEGORIZED",
+	1: "AUTHORIZATION",
+	2: "VALIDATION",
+	3: "CONFLICT",
+	4: "UNAVAILABLE",
+	5: "INTERNAL",
+}
+var ErrorCategory_value = map[string]int32{
+	"UNCATEGORIZED": 0,
+	"AUTHORIZATION": 1,
+	"VALIDATION":    2,
+	"CONFLICT":      3,
+	"UNAVAILABLE":   4,
+	"INTERNAL":      5,
+}
+
+func (x ErrorCategory) String() string {
+	return proto.EnumName(ErrorCategory_name, int32(x))
+}
+func (ErrorCategory) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
 // LastConfig is the encoded value for the Metadata message which is encoded in the LAST_CONFIGURATION block metadata index
 type LastConfig struct {
 	Index uint64 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
@@ -383,6 +435,34 @@ func (m *BlockMetadata) String() string            { return proto.CompactTextStr
 func (*BlockMetadata) ProtoMessage()               {}
 func (*BlockMetadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
+// IntegrityProof lets the receiver of a message verify its content end to end,
+// even when the transport security is terminated before reaching it. The
+// signature is by the creator in the signature header, over the signature
+// header concatenated with the content hash
+type IntegrityProof struct {
+	ContentHash     []byte `protobuf:"bytes,1,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
+	SignatureHeader []byte `protobuf:"bytes,2,opt,name=signature_header,json=signatureHeader,proto3" json:"signature_header,omitempty"`
+	Signature       []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *IntegrityProof) Reset()                    { *m = IntegrityProof{} }
+func (m *IntegrityProof) String() string            { return proto.CompactTextString(m) }
+func (*IntegrityProof) ProtoMessage()               {}
+func (*IntegrityProof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+// ErrorInfo describes an error returned in a proposal response or a broadcast
+// response, by a code which is stable across releases and its category
+type ErrorInfo struct {
+	Code     string        `protobuf:"bytes,1,opt,name=code" json:"code,omitempty"`
+	Category ErrorCategory `protobuf:"varint,2,opt,name=category,enum=common.ErrorCategory" json:"category,omitempty"`
+	Message  string        `protobuf:"bytes,3,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *ErrorInfo) Reset()                    { *m = ErrorInfo{} }
+func (m *ErrorInfo) String() string            { return proto.CompactTextString(m) }
+func (*ErrorInfo) ProtoMessage()               {}
+func (*ErrorInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
 func init() {
 	proto.RegisterType((*LastConfig)(nil), "common.LastConfig")
 	proto.RegisterType((*Metadata)(nil), "common.Metadata")
@@ -396,69 +476,84 @@ func init() {
 	proto.RegisterType((*BlockHeader)(nil), "common.BlockHeader")
 	proto.RegisterType((*BlockData)(nil), "common.BlockData")
 	proto.RegisterType((*BlockMetadata)(nil), "common.BlockMetadata")
+	proto.RegisterType((*IntegrityProof)(nil), "common.IntegrityProof")
+	proto.RegisterType((*ErrorInfo)(nil), "common.ErrorInfo")
 	proto.RegisterEnum("common.Status", Status_name, Status_value)
 	proto.RegisterEnum("common.HeaderType", HeaderType_name, HeaderType_value)
 	proto.RegisterEnum("common.BlockMetadataIndex", BlockMetadataIndex_name, BlockMetadataIndex_value)
+	proto.RegisterEnum("common.ErrorCategory", ErrorCategory_name, ErrorCategory_value)
 }
 
 func init() { proto.RegisterFile("common/common.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 886 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x55, 0xdf, 0x6e, 0xe3, 0xc4,
-	0x1b, 0xad, 0xe3, 0xfc, 0x69, 0xbe, 0x34, 0xed, 0x74, 0xb2, 0xfd, 0xd5, 0xbf, 0xc2, 0x6a, 0x2b,
-	0x23, 0x50, 0x69, 0x45, 0x22, 0xca, 0x0d, 0x48, 0xdc, 0x38, 0xf1, 0xa4, 0x6b, 0x6d, 0xd6, 0x5e,
-	0xc6, 0xce, 0x22, 0x58, 0x24, 0x6b, 0x92, 0x4c, 0x13, 0x8b, 0xc4, 0x8e, 0x6c, 0xa7, 0x6a, 0x6f,
-	0xb9, 0xe4, 0x02, 0x21, 0xc1, 0x2d, 0x2f, 0xc0, 0x93, 0xf0, 0x16, 0xbc, 0x04, 0x12, 0xb7, 0x68,
-	0x3c, 0xb6, 0x37, 0xe9, 0x22, 0x71, 0x15, 0x9f, 0xf3, 0x9d, 0x99, 0xef, 0xcc, 0xf9, 0xc6, 0x31,
-	0x74, 0xa6, 0xd1, 0x6a, 0x15, 0x85, 0x3d, 0xf9, 0xd3, 0x5d, 0xc7, 0x51, 0x1a, 0xe1, 0xba, 0x44,
-	0x67, 0xcf, 0xe6, 0x51, 0x34, 0x5f, 0xf2, 0x5e, 0xc6, 0x4e, 0x36, 0xb7, 0xbd, 0x34, 0x58, 0xf1,
-	0x24, 0x65, 0xab, 0xb5, 0x14, 0xea, 0x3a, 0xc0, 0x88, 0x25, 0xe9, 0x20, 0x0a, 0x6f, 0x83, 0x39,
-	0x7e, 0x02, 0xb5, 0x20, 0x9c, 0xf1, 0x7b, 0x4d, 0x39, 0x57, 0x2e, 0xaa, 0x54, 0x02, 0xfd, 0x0d,
-	0xec, 0xbf, 0xe4, 0x29, 0x9b, 0xb1, 0x94, 0x09, 0xc5, 0x1d, 0x5b, 0x6e, 0x78, 0xa6, 0x38, 0xa0,
-	0x12, 0xe0, 0x2f, 0x00, 0x92, 0x60, 0x1e, 0xb2, 0x74, 0x13, 0xf3, 0x44, 0xab, 0x9c, 0xab, 0x17,
-	0xad, 0xeb, 0xff, 0x77, 0x73, 0x47, 0xc5, 0x5a, 0xb7, 0x50, 0xd0, 0x2d, 0xb1, 0xfe, 0x1d, 0x1c,
-	0xbf, 0x23, 0xc0, 0x1f, 0x03, 0x2a, 0x25, 0xfe, 0x82, 0xb3, 0x19, 0x8f, 0xf3, 0x86, 0x47, 0x25,
-	0xff, 0x3c, 0xa3, 0xf1, 0xfb, 0xd0, 0x2c, 0x29, 0xad, 0x92, 0x69, 0xde, 0x12, 0xfa, 0x8f, 0x0a,
-	0xd4, 0x73, 0xe1, 0x97, 0x70, 0x38, 0x5d, 0xb0, 0x30, 0xe4, 0xcb, 0xed, 0x1d, 0x5b, 0xd7, 0x27,
-	0x85, 0xcf, 0x81, 0xac, 0x4a, 0x39, 0x6d, 0x4f, 0xb7, 0x21, 0xee, 0xff, 0x8b, 0xa3, 0x4a, 0xb6,
-	0xfe, 0xb4, 0x58, 0xef, 0xee, 0x3a, 0x7b, 0xc7, 0xaa, 0xfe, 0xa7, 0x02, 0xed, 0x9d, 0x26, 0x18,
-	0x43, 0x35, 0x7d, 0x58, 0xcb, 0x30, 0x6b, 0x34, 0x7b, 0xc6, 0x1a, 0x34, 0xee, 0x78, 0x9c, 0x04,
-	0x51, 0x98, 0x35, 0xa8, 0xd1, 0x02, 0xe2, 0xcf, 0xa1, 0x59, 0x8e, 0x4f, 0x53, 0xb3, 0xe6, 0x67,
-	0x5d, 0x39, 0xe0, 0x6e, 0x31, 0xe0, 0xae, 0x57, 0x28, 0xe8, 0x5b, 0x31, 0x7e, 0x0a, 0x50, 0x9c,
-	0x3d, 0x98, 0x69, 0xd5, 0x73, 0xe5, 0xa2, 0x49, 0x9b, 0x39, 0x63, 0xcd, 0x70, 0x07, 0x6a, 0xe9,
-	0xbd, 0xa8, 0xd4, 0xb2, 0x4a, 0x35, 0xbd, 0xb7, 0x66, 0x62, 0xd2, 0x7c, 0x1d, 0x4d, 0x17, 0x5a,
-	0x5d, 0xde, 0x85, 0x0c, 0x88, 0xb8, 0xf9, 0x7d, 0xca, 0xc3, 0xcc, 0x5f, 0x43, 0xc6, 0x5d, 0x12,
-	0xba, 0x01, 0x47, 0x8f, 0x52, 0x10, 0xc7, 0x99, 0xc6, 0x9c, 0xa5, 0x51, 0x31, 0xc1, 0x02, 0x8a,
-	0x06, 0x61, 0x14, 0x4e, 0x8b, 0xa9, 0x49, 0xa0, 0x13, 0x68, 0xbc, 0x62, 0x0f, 0xcb, 0x88, 0xcd,
-	0xf0, 0x47, 0x50, 0xdf, 0x99, 0xd4, 0x61, 0x91, 0x74, 0x1e, 0x70, 0x5e, 0x15, 0x29, 0x8a, 0xeb,
-	0x93, 0xef, 0x93, 0x3d, 0xeb, 0x7d, 0xd8, 0x27, 0xe1, 0x1d, 0x5f, 0x46, 0x32, 0xd1, 0xb5, 0xdc,
-	0xb2, 0xb0, 0x90, 0xc3, 0xff, 0xb8, 0x3c, 0x3f, 0x29, 0x50, 0xeb, 0x2f, 0xa3, 0xe9, 0xf7, 0xf8,
-	0xea, 0x91, 0x93, 0x4e, 0xe1, 0x24, 0x2b, 0x3f, 0xb2, 0xf3, 0xe1, 0x96, 0x9d, 0xd6, 0xf5, 0xf1,
-	0x8e, 0xd4, 0x64, 0x29, 0x93, 0x0e, 0xf1, 0xa7, 0xb0, 0xbf, 0xca, 0x2f, 0x7e, 0x3e, 0xcc, 0x93,
-	0x1d, 0x69, 0xf1, 0x56, 0xd0, 0x52, 0xa6, 0xcf, 0xa1, 0xb5, 0xd5, 0x10, 0xff, 0x0f, 0xea, 0xe1,
-	0x66, 0x35, 0xc9, 0x5d, 0x55, 0x69, 0x8e, 0xf0, 0x07, 0xd0, 0x5e, 0xc7, 0xfc, 0x2e, 0x88, 0x36,
-	0x89, 0xbf, 0x60, 0xc9, 0x22, 0x3f, 0xd9, 0x41, 0x41, 0x3e, 0x67, 0xc9, 0x02, 0xbf, 0x07, 0x4d,
-	0xb1, 0xa7, 0x14, 0xa8, 0x99, 0x60, 0x5f, 0x10, 0xa2, 0xa8, 0x3f, 0x83, 0x66, 0x69, 0xb7, 0x8c,
-	0x57, 0x39, 0x57, 0xcb, 0x78, 0xaf, 0xa0, 0xbd, 0x63, 0x12, 0x9f, 0x6d, 0x9d, 0x46, 0x0a, 0x4b,
-	0x7c, 0xf9, 0xbb, 0x02, 0x75, 0x37, 0x65, 0xe9, 0x26, 0xc1, 0x2d, 0x68, 0x8c, 0xed, 0x17, 0xb6,
-	0xf3, 0xb5, 0x8d, 0xf6, 0xf0, 0x01, 0x34, 0xdc, 0xf1, 0x60, 0x40, 0x5c, 0x17, 0xfd, 0xa1, 0x60,
-	0x04, 0xad, 0xbe, 0x61, 0xfa, 0x94, 0x7c, 0x35, 0x26, 0xae, 0x87, 0x7e, 0x56, 0xf1, 0x21, 0x34,
-	0x87, 0x0e, 0xed, 0x5b, 0xa6, 0x49, 0x6c, 0xf4, 0x4b, 0x86, 0x6d, 0xc7, 0xf3, 0x87, 0xce, 0xd8,
-	0x36, 0xd1, 0xaf, 0x2a, 0x7e, 0x0a, 0x5a, 0xae, 0xf6, 0x89, 0xed, 0x59, 0xde, 0x37, 0xbe, 0xe7,
-	0x38, 0xfe, 0xc8, 0xa0, 0x37, 0x04, 0xfd, 0xa6, 0xe2, 0x33, 0x38, 0xb1, 0x6c, 0x8f, 0x50, 0xdb,
-	0x18, 0xf9, 0x2e, 0xa1, 0xaf, 0x09, 0xf5, 0x09, 0xa5, 0x0e, 0x45, 0x7f, 0xa9, 0x58, 0x83, 0x8e,
-	0xa0, 0xac, 0x01, 0xf1, 0xc7, 0xb6, 0xf1, 0xda, 0xb0, 0x46, 0x46, 0x7f, 0x44, 0xd0, 0xdf, 0xea,
-	0xe5, 0x0f, 0x0a, 0x80, 0xcc, 0xd7, 0x13, 0x6f, 0x63, 0x0b, 0x1a, 0x2f, 0x89, 0xeb, 0x1a, 0x37,
-	0x04, 0xed, 0x61, 0x80, 0xfa, 0xc0, 0xb1, 0x87, 0xd6, 0x0d, 0x52, 0xf0, 0x31, 0xb4, 0xe5, 0xb3,
-	0x3f, 0x7e, 0x65, 0x1a, 0x1e, 0x41, 0x15, 0xac, 0xc1, 0x13, 0x62, 0x9b, 0x0e, 0x75, 0x09, 0xf5,
-	0x3d, 0x6a, 0xd8, 0xae, 0x31, 0xf0, 0x2c, 0xc7, 0x46, 0x2a, 0x3e, 0x85, 0x8e, 0x43, 0x4d, 0x42,
-	0x1f, 0x15, 0xaa, 0xf8, 0x04, 0x8e, 0x4d, 0x32, 0xb2, 0x84, 0x37, 0x97, 0x90, 0x17, 0xbe, 0x65,
-	0x0f, 0x1d, 0x54, 0xbb, 0x7c, 0x03, 0x78, 0x27, 0x5e, 0x4b, 0xfc, 0x0f, 0xe3, 0x43, 0x00, 0xd7,
-	0xba, 0xb1, 0x0d, 0x6f, 0x4c, 0x89, 0x8b, 0xf6, 0xf0, 0x11, 0xb4, 0x46, 0x86, 0xeb, 0xf9, 0xa5,
-	0xa7, 0x53, 0xe8, 0x6c, 0x6d, 0xef, 0xfa, 0x43, 0x6b, 0xe4, 0x11, 0x8a, 0x2a, 0xe2, 0x14, 0x79,
-	0x7f, 0xa4, 0xf6, 0x3f, 0xf9, 0xf6, 0x6a, 0x1e, 0xa4, 0x8b, 0xcd, 0x44, 0x5c, 0xb7, 0xde, 0xe2,
-	0x61, 0xcd, 0xe3, 0x25, 0x9f, 0xcd, 0x79, 0xdc, 0xbb, 0x65, 0x93, 0x38, 0x98, 0xca, 0x8f, 0x45,
-	0x92, 0x7f, 0x50, 0x26, 0xf5, 0x0c, 0x7e, 0xf6, 0x4f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x7e, 0xa9,
-	0x91, 0x13, 0x68, 0x06, 0x00, 0x00,
+	// 1078 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0x5d, 0x6f, 0xe3, 0x44,
+	0x17, 0xae, 0xe3, 0x7c, 0x34, 0x27, 0x4d, 0xd6, 0x9d, 0x6c, 0xdf, 0x66, 0xfb, 0xb2, 0xda, 0x12,
+	0x04, 0xea, 0xb6, 0xa2, 0x15, 0xe5, 0x06, 0x24, 0x6e, 0x1c, 0x67, 0xd2, 0x8e, 0x36, 0xb5, 0xcb,
+	0xd8, 0x29, 0x62, 0x41, 0xb2, 0xdc, 0x64, 0x9a, 0x44, 0x24, 0x9e, 0xc8, 0x76, 0xaa, 0x56, 0xe2,
+	0x02, 0x71, 0xc9, 0x05, 0x42, 0x82, 0x5b, 0x7e, 0x0e, 0x12, 0xff, 0x82, 0x3f, 0x81, 0xc4, 0x05,
+	0x37, 0x68, 0x66, 0x6c, 0x37, 0xe9, 0x82, 0xf6, 0x2a, 0xf3, 0x9c, 0xf3, 0xcc, 0x39, 0xcf, 0xf9,
+	0x70, 0x06, 0x9a, 0x43, 0x3e, 0x9f, 0xf3, 0xf0, 0x44, 0xfd, 0x1c, 0x2f, 0x22, 0x9e, 0x70, 0x54,
+	0x56, 0x68, 0xef, 0xc5, 0x98, 0xf3, 0xf1, 0x8c, 0x9d, 0x48, 0xeb, 0xf5, 0xf2, 0xe6, 0x24, 0x99,
+	0xce, 0x59, 0x9c, 0x04, 0xf3, 0x85, 0x22, 0xb6, 0xdb, 0x00, 0xfd, 0x20, 0x4e, 0x2c, 0x1e, 0xde,
+	0x4c, 0xc7, 0xe8, 0x29, 0x94, 0xa6, 0xe1, 0x88, 0xdd, 0xb5, 0xb4, 0x7d, 0xed, 0xa0, 0x48, 0x15,
+	0x68, 0x7f, 0x05, 0x9b, 0x17, 0x2c, 0x09, 0x46, 0x41, 0x12, 0x08, 0xc6, 0x6d, 0x30, 0x5b, 0x32,
+	0xc9, 0xd8, 0xa2, 0x0a, 0xa0, 0x4f, 0x01, 0xe2, 0xe9, 0x38, 0x0c, 0x92, 0x65, 0xc4, 0xe2, 0x56,
+	0x61, 0x5f, 0x3f, 0xa8, 0x9d, 0x3e, 0x3b, 0x4e, 0x15, 0x65, 0x77, 0xdd, 0x8c, 0x41, 0x57, 0xc8,
+	0xed, 0xaf, 0x61, 0xfb, 0x0d, 0x02, 0x7a, 0x09, 0x46, 0x4e, 0xf1, 0x27, 0x2c, 0x18, 0xb1, 0x28,
+	0x4d, 0xf8, 0x24, 0xb7, 0x9f, 0x4b, 0x33, 0x7a, 0x07, 0xaa, 0xb9, 0xa9, 0x55, 0x90, 0x9c, 0x07,
+	0x43, 0xfb, 0x07, 0x0d, 0xca, 0x29, 0xf1, 0x33, 0x68, 0x0c, 0x27, 0x41, 0x18, 0xb2, 0xd9, 0x6a,
+	0xc4, 0xda, 0xe9, 0x4e, 0xa6, 0xd3, 0x52, 0x5e, 0x45, 0xa7, 0xf5, 0xe1, 0x2a, 0x44, 0x9d, 0x7f,
+	0x51, 0x54, 0x90, 0xf7, 0x77, 0xb3, 0xfb, 0xee, 0xba, 0xb2, 0x37, 0xa4, 0xb6, 0xff, 0xd0, 0xa0,
+	0xbe, 0x96, 0x04, 0x21, 0x28, 0x26, 0xf7, 0x0b, 0xd5, 0xcc, 0x12, 0x95, 0x67, 0xd4, 0x82, 0xca,
+	0x2d, 0x8b, 0xe2, 0x29, 0x0f, 0x65, 0x82, 0x12, 0xcd, 0x20, 0xfa, 0x04, 0xaa, 0xf9, 0xf8, 0x5a,
+	0xba, 0x4c, 0xbe, 0x77, 0xac, 0x06, 0x7c, 0x9c, 0x0d, 0xf8, 0xd8, 0xcb, 0x18, 0xf4, 0x81, 0x8c,
+	0x9e, 0x03, 0x64, 0xb5, 0x4f, 0x47, 0xad, 0xe2, 0xbe, 0x76, 0x50, 0xa5, 0xd5, 0xd4, 0x42, 0x46,
+	0xa8, 0x09, 0xa5, 0xe4, 0x4e, 0x78, 0x4a, 0xd2, 0x53, 0x4c, 0xee, 0xc8, 0x48, 0x4c, 0x9a, 0x2d,
+	0xf8, 0x70, 0xd2, 0x2a, 0xab, 0x5d, 0x90, 0x40, 0xb4, 0x9b, 0xdd, 0x25, 0x2c, 0x94, 0xfa, 0x2a,
+	0xaa, 0xdd, 0xb9, 0xa1, 0x6d, 0xc2, 0x93, 0x47, 0x5d, 0x10, 0xe5, 0x0c, 0x23, 0x16, 0x24, 0x3c,
+	0x9b, 0x60, 0x06, 0x45, 0x82, 0x90, 0x87, 0xc3, 0x6c, 0x6a, 0x0a, 0xb4, 0x31, 0x54, 0x2e, 0x83,
+	0xfb, 0x19, 0x0f, 0x46, 0xe8, 0x03, 0x28, 0xaf, 0x4d, 0xaa, 0x91, 0x75, 0x3a, 0x6d, 0x70, 0x79,
+	0x92, 0x77, 0x51, 0xac, 0x4f, 0x1a, 0x47, 0x9e, 0xdb, 0x1d, 0xd8, 0xc4, 0xe1, 0x2d, 0x9b, 0x71,
+	0xd5, 0xd1, 0x85, 0x0a, 0x99, 0x49, 0x48, 0xe1, 0x5b, 0x96, 0xe7, 0x47, 0x0d, 0x4a, 0x9d, 0x19,
+	0x1f, 0x7e, 0x83, 0x8e, 0x1e, 0x29, 0x69, 0x66, 0x4a, 0xa4, 0xfb, 0x91, 0x9c, 0xf7, 0x57, 0xe4,
+	0xd4, 0x4e, 0xb7, 0xd7, 0xa8, 0xdd, 0x20, 0x09, 0x94, 0x42, 0xf4, 0x11, 0x6c, 0xce, 0xd3, 0xc5,
+	0x6f, 0xe9, 0xeb, 0x9b, 0x28, 0xa9, 0xd9, 0x57, 0x41, 0x73, 0x5a, 0x7b, 0x0c, 0xb5, 0x95, 0x84,
+	0xe8, 0x7f, 0x50, 0x0e, 0x97, 0xf3, 0xeb, 0x54, 0x55, 0x91, 0xa6, 0x08, 0xbd, 0x07, 0xf5, 0x45,
+	0xc4, 0x6e, 0xa7, 0x7c, 0x19, 0xfb, 0x93, 0x20, 0x9e, 0xa4, 0x95, 0x6d, 0x65, 0xc6, 0xf3, 0x20,
+	0x9e, 0xa0, 0xff, 0x43, 0x55, 0xc4, 0x54, 0x04, 0x5d, 0x12, 0x36, 0x85, 0x41, 0x38, 0xdb, 0x2f,
+	0xa0, 0x9a, 0xcb, 0xcd, 0xdb, 0xab, 0xed, 0xeb, 0x79, 0x7b, 0x8f, 0xa0, 0xbe, 0x26, 0x12, 0xed,
+	0xad, 0x54, 0xa3, 0x88, 0x0f, 0xb2, 0xbf, 0x85, 0x06, 0x09, 0x13, 0x36, 0x8e, 0xa6, 0xc9, 0xfd,
+	0x65, 0xc4, 0xf9, 0x0d, 0x7a, 0x17, 0xb6, 0x86, 0x3c, 0x4c, 0x58, 0x98, 0xa8, 0xfc, 0x6a, 0x2c,
+	0xb5, 0xd4, 0x26, 0xf5, 0xbd, 0xfc, 0x8f, 0x0f, 0xee, 0x6d, 0x7f, 0x01, 0xfa, 0xe3, 0x29, 0xce,
+	0xa0, 0x8a, 0xa3, 0x88, 0x47, 0x24, 0xbc, 0xe1, 0xa2, 0x96, 0x21, 0x1f, 0xa9, 0x0f, 0xae, 0x4a,
+	0xe5, 0x59, 0x0c, 0x62, 0x18, 0x24, 0x6c, 0xcc, 0xa3, 0x7b, 0x99, 0xa1, 0xf1, 0x30, 0x08, 0x79,
+	0xd1, 0x4a, 0x9d, 0x34, 0xa7, 0x89, 0x8d, 0x9a, 0xb3, 0x38, 0x0e, 0xc6, 0x2a, 0x5f, 0x95, 0x66,
+	0xf0, 0xf0, 0x37, 0x0d, 0xca, 0x6e, 0x12, 0x24, 0xcb, 0x18, 0xd5, 0xa0, 0x32, 0xb0, 0x5f, 0xd9,
+	0xce, 0x17, 0xb6, 0xb1, 0x81, 0xb6, 0xa0, 0xe2, 0x0e, 0x2c, 0x0b, 0xbb, 0xae, 0xf1, 0xbb, 0x86,
+	0x0c, 0xa8, 0x75, 0xcc, 0xae, 0x4f, 0xf1, 0xe7, 0x03, 0xec, 0x7a, 0xc6, 0x4f, 0x3a, 0x6a, 0x40,
+	0xb5, 0xe7, 0xd0, 0x0e, 0xe9, 0x76, 0xb1, 0x6d, 0xfc, 0x2c, 0xb1, 0xed, 0x78, 0x7e, 0xcf, 0x19,
+	0xd8, 0x5d, 0xe3, 0x17, 0x1d, 0x3d, 0x87, 0x56, 0xca, 0xf6, 0xb1, 0xed, 0x11, 0xef, 0x4b, 0xdf,
+	0x73, 0x1c, 0xbf, 0x6f, 0xd2, 0x33, 0x6c, 0xfc, 0xaa, 0xa3, 0x3d, 0xd8, 0x21, 0xb6, 0x87, 0xa9,
+	0x6d, 0xf6, 0x7d, 0x17, 0xd3, 0x2b, 0x4c, 0x7d, 0x4c, 0xa9, 0x43, 0x8d, 0x3f, 0x75, 0xd4, 0x82,
+	0xa6, 0x30, 0x11, 0x0b, 0xfb, 0x03, 0xdb, 0xbc, 0x32, 0x49, 0xdf, 0xec, 0xf4, 0xb1, 0xf1, 0x97,
+	0x8e, 0x9e, 0xc1, 0x53, 0x62, 0xbb, 0x83, 0x5e, 0x8f, 0x58, 0x04, 0xdb, 0x9e, 0xef, 0x7a, 0x0e,
+	0x35, 0xcf, 0xb0, 0xf1, 0xb7, 0x7e, 0xf8, 0xbd, 0x06, 0xa0, 0xda, 0xeb, 0x89, 0x3f, 0xa5, 0x1a,
+	0x54, 0x2e, 0xb0, 0xeb, 0x0a, 0xe7, 0x06, 0x02, 0x28, 0x5b, 0x8e, 0xdd, 0x23, 0x67, 0x86, 0x86,
+	0xb6, 0xa1, 0xae, 0xce, 0xfe, 0xe0, 0xb2, 0x6b, 0x7a, 0xd8, 0x28, 0xa0, 0x16, 0x3c, 0xc5, 0x76,
+	0xd7, 0xa1, 0x2e, 0xa6, 0xbe, 0x47, 0x4d, 0xdb, 0x35, 0x2d, 0x8f, 0x38, 0xb6, 0xa1, 0xa3, 0x5d,
+	0x68, 0x3a, 0xb4, 0x8b, 0xe9, 0x23, 0x47, 0x11, 0xed, 0xc0, 0x76, 0x17, 0xf7, 0x89, 0x90, 0xed,
+	0x62, 0xfc, 0xca, 0x27, 0x76, 0xcf, 0x31, 0x4a, 0x87, 0xdf, 0x69, 0x80, 0xd6, 0xd6, 0x8c, 0x88,
+	0xf7, 0x08, 0x35, 0x00, 0x5c, 0x72, 0x66, 0x9b, 0xde, 0x80, 0x62, 0xd7, 0xd8, 0x40, 0x4f, 0xa0,
+	0xd6, 0x37, 0x5d, 0xcf, 0xcf, 0x45, 0xed, 0x42, 0x73, 0x25, 0xbe, 0xeb, 0xf7, 0x48, 0xdf, 0xc3,
+	0xd4, 0x28, 0x88, 0x32, 0x52, 0x01, 0x86, 0x2e, 0xae, 0x59, 0xce, 0xc5, 0x05, 0xf1, 0xfc, 0x73,
+	0xd3, 0x3d, 0x37, 0x8a, 0x08, 0x41, 0xe3, 0xe2, 0xca, 0xb2, 0x64, 0x9c, 0x3e, 0xb1, 0x3c, 0xd7,
+	0x28, 0x1d, 0x46, 0x50, 0x5f, 0x5b, 0x02, 0x51, 0xf0, 0xc0, 0xb6, 0x4c, 0x0f, 0x9f, 0x39, 0x94,
+	0xbc, 0xc6, 0x5d, 0x63, 0x43, 0x98, 0xcc, 0x81, 0x77, 0x2e, 0xb0, 0x29, 0x0b, 0xd2, 0x84, 0xc4,
+	0x2b, 0xb3, 0x4f, 0xba, 0x0a, 0x17, 0xd0, 0x16, 0x6c, 0x66, 0x51, 0x55, 0xe6, 0xd5, 0x49, 0x14,
+	0x85, 0x3b, 0x1b, 0x9f, 0x51, 0xea, 0x7c, 0xf8, 0xfa, 0x68, 0x3c, 0x4d, 0x26, 0xcb, 0x6b, 0xb1,
+	0x86, 0x27, 0x93, 0xfb, 0x05, 0x8b, 0x66, 0x6c, 0x34, 0x66, 0xd1, 0xc9, 0x4d, 0x70, 0x1d, 0x4d,
+	0x87, 0xea, 0x35, 0x8f, 0xd3, 0x17, 0xff, 0xba, 0x2c, 0xe1, 0xc7, 0xff, 0x0c, 0x00, 0xf5, 0x08,
+	0xac, 0xcd, 0x09, 0x08, 0x00, 0x00,
 }
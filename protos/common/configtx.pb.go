@@ -157,10 +157,14 @@ func (m *ConfigUpdateEnvelope) GetSignatures() []*ConfigSignature {
 // 3. The corresponding mod_policy for every remaining element in the write_set is collected.
 // 4. Each policy is checked against the signatures from the ConfigUpdateEnvelope, any failing to verify are rejected
 // 5. The write_set is applied to the Config and the ConfigGroupSchema verifies that the updates were legal
+// 6. The elements in the delete_set are removed from the Config, provided their mod_policy is satisfied
+//    and they are absent from the write_set. An element missing from the write_set which is not in the
+//    delete_set is an implicit deletion, and the config update is rejected.
 type ConfigUpdate struct {
-	Header   *ChannelHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
-	ReadSet  *ConfigGroup   `protobuf:"bytes,2,opt,name=read_set,json=readSet" json:"read_set,omitempty"`
-	WriteSet *ConfigGroup   `protobuf:"bytes,3,opt,name=write_set,json=writeSet" json:"write_set,omitempty"`
+	Header    *ChannelHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	ReadSet   *ConfigGroup   `protobuf:"bytes,2,opt,name=read_set,json=readSet" json:"read_set,omitempty"`
+	WriteSet  *ConfigGroup   `protobuf:"bytes,3,opt,name=write_set,json=writeSet" json:"write_set,omitempty"`
+	DeleteSet *ConfigGroup   `protobuf:"bytes,4,opt,name=delete_set,json=deleteSet" json:"delete_set,omitempty"`
 }
 
 func (m *ConfigUpdate) Reset()                    { *m = ConfigUpdate{} }
@@ -189,6 +193,13 @@ func (m *ConfigUpdate) GetWriteSet() *ConfigGroup {
 	return nil
 }
 
+func (m *ConfigUpdate) GetDeleteSet() *ConfigGroup {
+	if m != nil {
+		return m.DeleteSet
+	}
+	return nil
+}
+
 // ConfigGroup is the hierarchical data structure for holding config
 type ConfigGroup struct {
 	Version   uint64                   `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
@@ -264,6 +275,25 @@ func (m *ConfigSignature) String() string            { return proto.CompactTextS
 func (*ConfigSignature) ProtoMessage()               {}
 func (*ConfigSignature) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{10} }
 
+// ConfigSnapshot is the current config of a channel along with its sequence
+// number, as returned to the clients building config updates
+type ConfigSnapshot struct {
+	Config   *Config `protobuf:"bytes,1,opt,name=config" json:"config,omitempty"`
+	Sequence uint64  `protobuf:"varint,2,opt,name=sequence" json:"sequence,omitempty"`
+}
+
+func (m *ConfigSnapshot) Reset()                    { *m = ConfigSnapshot{} }
+func (m *ConfigSnapshot) String() string            { return proto.CompactTextString(m) }
+func (*ConfigSnapshot) ProtoMessage()               {}
+func (*ConfigSnapshot) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{11} }
+
+func (m *ConfigSnapshot) GetConfig() *Config {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ConfigEnvelope)(nil), "common.ConfigEnvelope")
 	proto.RegisterType((*ConfigGroupSchema)(nil), "common.ConfigGroupSchema")
@@ -276,51 +306,54 @@ func init() {
 	proto.RegisterType((*ConfigValue)(nil), "common.ConfigValue")
 	proto.RegisterType((*ConfigPolicy)(nil), "common.ConfigPolicy")
 	proto.RegisterType((*ConfigSignature)(nil), "common.ConfigSignature")
+	proto.RegisterType((*ConfigSnapshot)(nil), "common.ConfigSnapshot")
 }
 
 func init() { proto.RegisterFile("common/configtx.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 652 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x55, 0xdd, 0x6e, 0xda, 0x4c,
-	0x10, 0x15, 0x38, 0x71, 0xc2, 0x40, 0x7e, 0xbe, 0x85, 0xe8, 0x73, 0x51, 0xab, 0xa6, 0xae, 0x9a,
-	0x26, 0xad, 0x80, 0x34, 0xbd, 0xa0, 0xaa, 0x94, 0x9b, 0x46, 0x51, 0x7b, 0x15, 0xb5, 0xa6, 0x3f,
-	0x52, 0x54, 0x09, 0x19, 0x7b, 0x01, 0x2b, 0xc6, 0x6b, 0xad, 0x17, 0x5a, 0x9e, 0xa6, 0x0f, 0xd6,
-	0x37, 0xe8, 0x53, 0x54, 0xde, 0x1f, 0x77, 0x0d, 0x06, 0x94, 0x2b, 0xd8, 0x99, 0x73, 0xce, 0xcc,
-	0x8e, 0xe7, 0x68, 0xe1, 0xc8, 0x23, 0x93, 0x09, 0x89, 0x3a, 0x1e, 0x89, 0x86, 0xc1, 0x88, 0xfd,
-	0x6c, 0xc7, 0x94, 0x30, 0x82, 0x4c, 0x11, 0x6e, 0xd6, 0xb3, 0x74, 0xfa, 0x23, 0x92, 0x4d, 0xc5,
-	0x89, 0x49, 0x18, 0x78, 0x01, 0x4e, 0x44, 0xd8, 0xbe, 0x83, 0xfd, 0x2b, 0xae, 0x72, 0x1d, 0xcd,
-	0x70, 0x48, 0x62, 0x8c, 0x4e, 0xc0, 0x14, 0xba, 0x56, 0xe9, 0xb8, 0x74, 0x5a, 0xbd, 0xd8, 0x6f,
-	0x4b, 0x1d, 0x81, 0x73, 0x64, 0x16, 0xbd, 0x82, 0x6a, 0xe8, 0x26, 0xac, 0x3f, 0x8d, 0x7d, 0x97,
-	0x61, 0xab, 0xcc, 0xc1, 0x87, 0x0a, 0xac, 0xe4, 0x1c, 0x48, 0x41, 0x5f, 0x38, 0xc6, 0xfe, 0x6d,
-	0xc0, 0x7f, 0x42, 0xe5, 0x3d, 0x25, 0xd3, 0xb8, 0xe7, 0x8d, 0xf1, 0xc4, 0x45, 0x97, 0x60, 0x8e,
-	0xd2, 0x63, 0x62, 0x95, 0x8e, 0x8d, 0xd3, 0xea, 0xc5, 0xb3, 0x7c, 0x41, 0x0d, 0xda, 0xe6, 0xff,
-	0x93, 0xeb, 0x88, 0xd1, 0xb9, 0x23, 0x49, 0x29, 0x7d, 0xe6, 0x86, 0x53, 0x9c, 0x58, 0xe5, 0x4d,
-	0xf4, 0xaf, 0x1c, 0x27, 0xe9, 0x82, 0x84, 0xae, 0x60, 0x57, 0x8d, 0xc4, 0x32, 0xb8, 0xc0, 0xf3,
-	0xd5, 0x02, 0x1f, 0x25, 0x52, 0x48, 0x64, 0xc4, 0xe6, 0x67, 0xa8, 0x6a, 0xad, 0xa1, 0x43, 0x30,
-	0xee, 0xf0, 0x9c, 0xcf, 0xaf, 0xe2, 0xa4, 0x7f, 0x51, 0x07, 0xb6, 0x79, 0x3d, 0x39, 0xa6, 0x07,
-	0x2b, 0x4b, 0x38, 0x02, 0xf7, 0xb6, 0xfc, 0xa6, 0x94, 0xaa, 0x6a, 0x1d, 0xdf, 0x5b, 0x95, 0x73,
-	0x97, 0x55, 0xbf, 0xc1, 0x5e, 0xee, 0x1a, 0x05, 0xba, 0xe7, 0x79, 0xdd, 0x66, 0x5e, 0x97, 0xb3,
-	0xe7, 0x4b, 0xc2, 0x76, 0x5d, 0x7d, 0x5c, 0xad, 0xb0, 0xdd, 0x00, 0xb4, 0xcc, 0xb2, 0x87, 0x60,
-	0x8a, 0x28, 0x6a, 0x81, 0x39, 0xc6, 0xae, 0x8f, 0xa9, 0xdc, 0xb6, 0xa3, 0xac, 0xd6, 0xd8, 0x8d,
-	0x22, 0x1c, 0x7e, 0xe0, 0x49, 0x47, 0x82, 0x50, 0x0b, 0x76, 0x3c, 0x91, 0x90, 0xbd, 0xd5, 0x0b,
-	0x26, 0xe9, 0x28, 0x8c, 0xcd, 0xa0, 0x21, 0xe2, 0x62, 0x01, 0xb3, 0x1d, 0x7f, 0x0a, 0x7b, 0x62,
-	0x8b, 0xd5, 0xf6, 0xa6, 0xc5, 0x6b, 0x4e, 0xcd, 0xd3, 0xc0, 0xa8, 0x0b, 0x90, 0x04, 0xa3, 0xc8,
-	0x65, 0x53, 0x9a, 0x2d, 0xd7, 0xff, 0xf9, 0x72, 0x3d, 0x95, 0x77, 0x34, 0xa8, 0xfd, 0xab, 0x04,
-	0x35, 0xbd, 0xec, 0x7d, 0x2f, 0xd9, 0x86, 0x5d, 0x8a, 0x5d, 0xbf, 0x9f, 0x60, 0xb6, 0xf6, 0x96,
-	0x29, 0xa8, 0x87, 0x19, 0x3a, 0x87, 0xca, 0x0f, 0x1a, 0x30, 0xcc, 0x09, 0xc6, 0x6a, 0xc2, 0x2e,
-	0x47, 0xf5, 0x30, 0xb3, 0xff, 0x18, 0x50, 0xd5, 0x32, 0xc8, 0x82, 0x9d, 0x19, 0xa6, 0x49, 0x40,
-	0x22, 0xde, 0xe1, 0x96, 0xa3, 0x8e, 0xa8, 0x9b, 0x99, 0x53, 0x0c, 0xe0, 0x71, 0x81, 0x70, 0xa1,
-	0x2d, 0xbb, 0x99, 0x2d, 0x8d, 0xd5, 0xc4, 0x22, 0x43, 0x5e, 0x6a, 0x86, 0xdc, 0xe2, 0xd4, 0x27,
-	0x45, 0xd4, 0x15, 0x56, 0x44, 0x8f, 0x00, 0x26, 0xc4, 0xef, 0xf3, 0xf3, 0xdc, 0xda, 0xe6, 0x4b,
-	0x5d, 0x99, 0x10, 0x5f, 0xec, 0x5f, 0xf3, 0x66, 0x93, 0x53, 0xcf, 0xf2, 0xbb, 0x5f, 0x38, 0x48,
-	0xcd, 0x4d, 0x37, 0x9b, 0x3c, 0xba, 0x5e, 0x8f, 0x73, 0x75, 0xbd, 0x4f, 0x9b, 0xdd, 0xf9, 0x22,
-	0xaf, 0xd8, 0x28, 0x72, 0xa7, 0xee, 0xcb, 0xef, 0xea, 0x5b, 0xf3, 0x62, 0x6b, 0xbe, 0x75, 0x43,
-	0x17, 0xae, 0x49, 0x89, 0x85, 0x81, 0x1a, 0x0b, 0x03, 0xb5, 0x89, 0xda, 0x75, 0x71, 0x5e, 0x23,
-	0x7f, 0x02, 0xa6, 0x14, 0x29, 0xe7, 0x1f, 0x16, 0xd9, 0xb2, 0xcc, 0x6e, 0x2a, 0x78, 0x0b, 0x07,
-	0x0b, 0xe6, 0x43, 0x67, 0x70, 0x98, 0xd9, 0xaf, 0xaf, 0x39, 0xad, 0xe6, 0x1c, 0x64, 0x71, 0xe1,
-	0x31, 0xf4, 0x10, 0x2a, 0x59, 0x48, 0xde, 0xf3, 0x5f, 0xe0, 0x5d, 0xeb, 0xf6, 0xe5, 0x28, 0x60,
-	0xe3, 0xe9, 0x20, 0x6d, 0xad, 0x33, 0x9e, 0xc7, 0x98, 0x86, 0xd8, 0x1f, 0x61, 0xda, 0x19, 0xba,
-	0x03, 0x1a, 0x78, 0x1d, 0xfe, 0x68, 0x26, 0xf2, 0x65, 0x1d, 0x98, 0xfc, 0xf8, 0xfa, 0x6f, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x72, 0x14, 0xff, 0x97, 0x90, 0x07, 0x00, 0x00,
+	// 685 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xdd, 0x6e, 0xd3, 0x4c,
+	0x10, 0x55, 0xe2, 0xd4, 0x4d, 0x26, 0xe9, 0xdf, 0x36, 0xd5, 0xe7, 0x2f, 0x02, 0x51, 0x8c, 0x28,
+	0x2d, 0xa8, 0x49, 0x29, 0x17, 0x45, 0x48, 0xbd, 0xa1, 0xaa, 0xe0, 0xaa, 0x82, 0x4d, 0x01, 0xa9,
+	0x42, 0x8a, 0x5c, 0x7b, 0x1b, 0x5b, 0x75, 0xbc, 0xc6, 0x5e, 0x17, 0xf2, 0x9c, 0x5c, 0xf2, 0x06,
+	0x3c, 0x05, 0xf2, 0xee, 0xda, 0xac, 0x13, 0x3b, 0xa1, 0x57, 0xf1, 0xcc, 0x9c, 0x73, 0x66, 0x77,
+	0x32, 0x47, 0x0b, 0x3b, 0x36, 0x9d, 0x4c, 0x68, 0x30, 0xb0, 0x69, 0x70, 0xe3, 0x8d, 0xd9, 0x8f,
+	0x7e, 0x18, 0x51, 0x46, 0x91, 0x2e, 0xd2, 0xbd, 0xed, 0xbc, 0x9c, 0xfe, 0x88, 0x62, 0x2f, 0xe3,
+	0x84, 0xd4, 0xf7, 0x6c, 0x8f, 0xc4, 0x22, 0x6d, 0xde, 0xc2, 0xfa, 0x19, 0x57, 0x39, 0x0f, 0xee,
+	0x88, 0x4f, 0x43, 0x82, 0xf6, 0x40, 0x17, 0xba, 0x46, 0x6d, 0xb7, 0xb6, 0xdf, 0x3e, 0x5e, 0xef,
+	0x4b, 0x1d, 0x81, 0xc3, 0xb2, 0x8a, 0x5e, 0x42, 0xdb, 0xb7, 0x62, 0x36, 0x4a, 0x42, 0xc7, 0x62,
+	0xc4, 0xa8, 0x73, 0xf0, 0x66, 0x06, 0xce, 0xe4, 0x30, 0xa4, 0xa0, 0x4f, 0x1c, 0x63, 0xfe, 0xd2,
+	0x60, 0x4b, 0xa8, 0xbc, 0x8b, 0x68, 0x12, 0x0e, 0x6d, 0x97, 0x4c, 0x2c, 0x74, 0x0a, 0xfa, 0x38,
+	0x0d, 0x63, 0xa3, 0xb6, 0xab, 0xed, 0xb7, 0x8f, 0x9f, 0x16, 0x1b, 0x2a, 0xd0, 0x3e, 0xff, 0x8e,
+	0xcf, 0x03, 0x16, 0x4d, 0xb1, 0x24, 0xa5, 0xf4, 0x3b, 0xcb, 0x4f, 0x48, 0x6c, 0xd4, 0x97, 0xd1,
+	0x3f, 0x73, 0x9c, 0xa4, 0x0b, 0x12, 0x3a, 0x83, 0x66, 0x36, 0x12, 0x43, 0xe3, 0x02, 0xcf, 0xaa,
+	0x05, 0x3e, 0x48, 0xa4, 0x90, 0xc8, 0x89, 0xbd, 0x4b, 0x68, 0x2b, 0x47, 0x43, 0x9b, 0xa0, 0xdd,
+	0x92, 0x29, 0x9f, 0x5f, 0x0b, 0xa7, 0x9f, 0x68, 0x00, 0x2b, 0xbc, 0x9f, 0x1c, 0xd3, 0xff, 0x95,
+	0x2d, 0xb0, 0xc0, 0xbd, 0xa9, 0xbf, 0xae, 0xa5, 0xaa, 0xca, 0x89, 0xef, 0xad, 0xca, 0xb9, 0xf3,
+	0xaa, 0x5f, 0x60, 0xad, 0x70, 0x8d, 0x12, 0xdd, 0xa3, 0xa2, 0x6e, 0xaf, 0xa8, 0xcb, 0xd9, 0xd3,
+	0x39, 0x61, 0x73, 0x1b, 0xb6, 0xe6, 0x1a, 0x9b, 0x5d, 0x40, 0xf3, 0x2c, 0xf3, 0x06, 0x74, 0x91,
+	0x45, 0x87, 0xa0, 0xbb, 0xc4, 0x72, 0x48, 0x24, 0xb7, 0x6d, 0x27, 0xef, 0xe5, 0x5a, 0x41, 0x40,
+	0xfc, 0xf7, 0xbc, 0x88, 0x25, 0x08, 0x1d, 0xc2, 0xaa, 0x2d, 0x0a, 0xf2, 0x6c, 0xdb, 0x25, 0x93,
+	0xc4, 0x19, 0xc6, 0x64, 0xd0, 0x15, 0x79, 0xb1, 0x80, 0xf9, 0x8e, 0x3f, 0x81, 0x35, 0xb1, 0xc5,
+	0xd9, 0xf6, 0xa6, 0xcd, 0x3b, 0xb8, 0x63, 0x2b, 0x60, 0x74, 0x02, 0x10, 0x7b, 0xe3, 0xc0, 0x62,
+	0x49, 0x94, 0x2f, 0xd7, 0x7f, 0xc5, 0x76, 0xc3, 0xac, 0x8e, 0x15, 0xa8, 0xf9, 0xb3, 0x06, 0x1d,
+	0xb5, 0xed, 0x7d, 0x2f, 0xd9, 0x87, 0x66, 0x44, 0x2c, 0x67, 0x14, 0x13, 0xb6, 0xf0, 0x96, 0x29,
+	0x68, 0x48, 0x18, 0x3a, 0x82, 0xd6, 0xf7, 0xc8, 0x63, 0x84, 0x13, 0xb4, 0x6a, 0x42, 0x93, 0xa3,
+	0x52, 0xc6, 0x31, 0x80, 0x43, 0x7c, 0x22, 0x29, 0x8d, 0x6a, 0x4a, 0x4b, 0xc0, 0x86, 0x84, 0x99,
+	0xbf, 0x35, 0x68, 0x2b, 0x25, 0x64, 0xc0, 0xea, 0x1d, 0x89, 0x62, 0x8f, 0x06, 0xfc, 0x56, 0x0d,
+	0x9c, 0x85, 0xe8, 0x24, 0x37, 0xb4, 0x18, 0xda, 0xa3, 0x12, 0xe5, 0x52, 0x2b, 0x9f, 0xe4, 0x56,
+	0xd6, 0xaa, 0x89, 0x65, 0x26, 0x3e, 0x55, 0x4c, 0xdc, 0xe0, 0xd4, 0xc7, 0x65, 0xd4, 0x0a, 0xfb,
+	0xa2, 0x87, 0x00, 0x13, 0xea, 0x8c, 0x78, 0x3c, 0x35, 0x56, 0xb8, 0x11, 0x5a, 0x13, 0xea, 0x88,
+	0x9d, 0xed, 0x5d, 0x2c, 0x73, 0xf7, 0x41, 0xd1, 0x2f, 0xa5, 0x93, 0x54, 0x1c, 0x78, 0xb1, 0xcc,
+	0xd7, 0x8b, 0xf5, 0x38, 0x57, 0xd5, 0xfb, 0xb8, 0xdc, 0xd1, 0xcf, 0x8b, 0x8a, 0xdd, 0x32, 0x47,
+	0xab, 0x5e, 0xfe, 0x0a, 0x6d, 0xa5, 0xd9, 0x82, 0xff, 0xba, 0xab, 0x0a, 0x77, 0xa4, 0xc4, 0xcc,
+	0x40, 0xb5, 0x99, 0x81, 0x9a, 0x34, 0xf3, 0x87, 0x88, 0x17, 0xc8, 0xef, 0x81, 0x2e, 0x45, 0xea,
+	0xc5, 0xc7, 0x48, 0x1e, 0x59, 0x56, 0x97, 0x35, 0xbc, 0x82, 0x8d, 0x19, 0xc3, 0xa2, 0x03, 0xd8,
+	0xcc, 0x2d, 0x3b, 0x52, 0xdc, 0xd9, 0xc1, 0x1b, 0x79, 0x5e, 0xf8, 0x12, 0x3d, 0x80, 0x56, 0x9e,
+	0x92, 0xf7, 0xfc, 0x9b, 0x30, 0x2f, 0xb3, 0x17, 0x74, 0x18, 0x58, 0x61, 0xec, 0x52, 0xf6, 0xcf,
+	0x2f, 0x68, 0x0f, 0x9a, 0x31, 0xf9, 0x96, 0x90, 0xc0, 0x16, 0xb2, 0x0d, 0x9c, 0xc7, 0x6f, 0x0f,
+	0xaf, 0x5e, 0x8c, 0x3d, 0xe6, 0x26, 0xd7, 0x29, 0x77, 0xe0, 0x4e, 0x43, 0x12, 0xf9, 0xc4, 0x19,
+	0x93, 0x68, 0x70, 0x63, 0x5d, 0x47, 0x9e, 0x3d, 0xe0, 0xcf, 0x77, 0x2c, 0xdf, 0xf8, 0x6b, 0x9d,
+	0x87, 0xaf, 0xfe, 0x0c, 0x00, 0x2d, 0x01, 0x74, 0x2e, 0x1a, 0x08, 0x00, 0x00,
 }
@@ -49,27 +49,41 @@ func (m *OrdererAddresses) String() string            { return proto.CompactText
 func (*OrdererAddresses) ProtoMessage()               {}
 func (*OrdererAddresses) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
 
+// Capabilities is encoded into the configuration transaction as a configuration item of type Chain
+// with a Key of "Capabilities" and a Value of Capabilities as marshaled protobuf bytes. It lists the
+// capabilities enabled on the channel, which every peer and orderer of the channel must support
+type Capabilities struct {
+	Capabilities []string `protobuf:"bytes,1,rep,name=capabilities" json:"capabilities,omitempty"`
+}
+
+func (m *Capabilities) Reset()                    { *m = Capabilities{} }
+func (m *Capabilities) String() string            { return proto.CompactTextString(m) }
+func (*Capabilities) ProtoMessage()               {}
+func (*Capabilities) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
+
 func init() {
 	proto.RegisterType((*HashingAlgorithm)(nil), "common.HashingAlgorithm")
 	proto.RegisterType((*BlockDataHashingStructure)(nil), "common.BlockDataHashingStructure")
 	proto.RegisterType((*OrdererAddresses)(nil), "common.OrdererAddresses")
+	proto.RegisterType((*Capabilities)(nil), "common.Capabilities")
 }
 
 func init() { proto.RegisterFile("common/configuration.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 205 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x44, 0x8e, 0x41, 0x4b, 0xc4, 0x30,
-	0x10, 0x46, 0x29, 0xea, 0x42, 0x03, 0xc2, 0x12, 0x3c, 0xac, 0xe2, 0x61, 0xe9, 0x41, 0x16, 0xc4,
-	0x8d, 0xe2, 0x2f, 0xd8, 0xe2, 0xc1, 0x9b, 0x50, 0x6f, 0xde, 0xd2, 0x64, 0x9a, 0x04, 0x9b, 0x4c,
-	0x99, 0x4c, 0x10, 0xff, 0xbd, 0x90, 0x2a, 0xde, 0xe6, 0xcd, 0xc7, 0x83, 0x27, 0x6e, 0x0c, 0xc6,
-	0x88, 0x49, 0x19, 0x4c, 0x53, 0x70, 0x85, 0x34, 0x07, 0x4c, 0xc7, 0x85, 0x90, 0x51, 0x6e, 0xd6,
-	0xad, 0xbb, 0x13, 0xdb, 0x57, 0x9d, 0x7d, 0x48, 0xee, 0x34, 0x3b, 0xa4, 0xc0, 0x3e, 0x4a, 0x29,
-	0xce, 0x93, 0x8e, 0xb0, 0x6b, 0xf6, 0xcd, 0xa1, 0x1d, 0xea, 0xdd, 0x3d, 0x89, 0xeb, 0x7e, 0x46,
-	0xf3, 0xf9, 0xa2, 0x59, 0xff, 0x0a, 0xef, 0x4c, 0xc5, 0x70, 0x21, 0x90, 0x57, 0xe2, 0xe2, 0x2b,
-	0x58, 0xf6, 0xd5, 0xb8, 0x1c, 0x56, 0xe8, 0x1e, 0xc5, 0xf6, 0x8d, 0x2c, 0x10, 0xd0, 0xc9, 0x5a,
-	0x82, 0x9c, 0x21, 0xcb, 0x5b, 0xd1, 0xea, 0x3f, 0xd8, 0x35, 0xfb, 0xb3, 0x43, 0x3b, 0xfc, 0x3f,
-	0xfa, 0x87, 0x8f, 0x7b, 0x17, 0xd8, 0x97, 0xf1, 0x68, 0x30, 0x2a, 0xff, 0xbd, 0x00, 0xcd, 0x60,
-	0x1d, 0x90, 0x9a, 0xf4, 0x48, 0xc1, 0xa8, 0xda, 0x9e, 0xd5, 0xda, 0x3e, 0x6e, 0x2a, 0x3e, 0xff,
-	0x04, 0x00, 0x00, 0xff, 0xff, 0xc8, 0x65, 0x4f, 0x72, 0xe8, 0x00, 0x00, 0x00,
+	// 224 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x8f, 0x4f, 0x4b, 0x03, 0x31,
+	0x10, 0x47, 0x59, 0xd4, 0xc2, 0x86, 0x0a, 0x25, 0x78, 0xa8, 0xe2, 0xa1, 0xe4, 0x20, 0x05, 0xb1,
+	0xf1, 0xcf, 0x27, 0x68, 0xf5, 0xe0, 0x4d, 0x58, 0x6f, 0xde, 0xb2, 0xc9, 0x34, 0x19, 0xdc, 0x24,
+	0xcb, 0x64, 0x16, 0xf1, 0xdb, 0x0b, 0xbb, 0x15, 0xed, 0x6d, 0xde, 0x6f, 0x78, 0x87, 0x27, 0xae,
+	0x6c, 0x8e, 0x31, 0x27, 0x6d, 0x73, 0xda, 0xa3, 0x1f, 0xc8, 0x30, 0xe6, 0xb4, 0xe9, 0x29, 0x73,
+	0x96, 0xb3, 0xe9, 0xa7, 0x6e, 0xc4, 0xe2, 0xd5, 0x94, 0x80, 0xc9, 0x6f, 0x3b, 0x9f, 0x09, 0x39,
+	0x44, 0x29, 0xc5, 0x69, 0x32, 0x11, 0x96, 0xd5, 0xaa, 0x5a, 0xd7, 0xcd, 0x78, 0xab, 0x07, 0x71,
+	0xb9, 0xeb, 0xb2, 0xfd, 0x7c, 0x31, 0x6c, 0x0e, 0xc2, 0x3b, 0xd3, 0x60, 0x79, 0x20, 0x90, 0x17,
+	0xe2, 0xec, 0x0b, 0x1d, 0x87, 0xd1, 0x38, 0x6f, 0x26, 0x50, 0xf7, 0x62, 0xf1, 0x46, 0x0e, 0x08,
+	0x68, 0xeb, 0x1c, 0x41, 0x29, 0x50, 0xe4, 0xb5, 0xa8, 0xcd, 0x2f, 0x2c, 0xab, 0xd5, 0xc9, 0xba,
+	0x6e, 0xfe, 0x06, 0xf5, 0x28, 0xe6, 0xcf, 0xa6, 0x37, 0x2d, 0x76, 0xc8, 0x08, 0x45, 0x2a, 0x31,
+	0xb7, 0xff, 0xf8, 0x20, 0x1c, 0x6d, 0xbb, 0xbb, 0x8f, 0x5b, 0x8f, 0x1c, 0x86, 0x76, 0x63, 0x73,
+	0xd4, 0xe1, 0xbb, 0x07, 0xea, 0xc0, 0x79, 0x20, 0xbd, 0x37, 0x2d, 0xa1, 0xd5, 0x63, 0x6f, 0xd1,
+	0x53, 0x6f, 0x3b, 0x1b, 0xf1, 0xe9, 0x67, 0x00, 0x0d, 0xa3, 0x43, 0x72, 0x1c, 0x01, 0x00, 0x00,
 }
@@ -26,23 +26,59 @@ func (m *BlockchainInfo) String() string            { return proto.CompactTextSt
 func (*BlockchainInfo) ProtoMessage()               {}
 func (*BlockchainInfo) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
 
+// Identifies a config block of a channel by the sequence number of the config
+// it carries
+type ConfigBlockInfo struct {
+	Sequence    uint64 `protobuf:"varint,1,opt,name=sequence" json:"sequence,omitempty"`
+	BlockNumber uint64 `protobuf:"varint,2,opt,name=blockNumber" json:"blockNumber,omitempty"`
+}
+
+func (m *ConfigBlockInfo) Reset()                    { *m = ConfigBlockInfo{} }
+func (m *ConfigBlockInfo) String() string            { return proto.CompactTextString(m) }
+func (*ConfigBlockInfo) ProtoMessage()               {}
+func (*ConfigBlockInfo) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{1} }
+
+// Lists the config blocks of a channel in the order of their sequence numbers
+type ConfigBlocksInfo struct {
+	ConfigBlocks []*ConfigBlockInfo `protobuf:"bytes,1,rep,name=configBlocks" json:"configBlocks,omitempty"`
+}
+
+func (m *ConfigBlocksInfo) Reset()                    { *m = ConfigBlocksInfo{} }
+func (m *ConfigBlocksInfo) String() string            { return proto.CompactTextString(m) }
+func (*ConfigBlocksInfo) ProtoMessage()               {}
+func (*ConfigBlocksInfo) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{2} }
+
+func (m *ConfigBlocksInfo) GetConfigBlocks() []*ConfigBlockInfo {
+	if m != nil {
+		return m.ConfigBlocks
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*BlockchainInfo)(nil), "common.BlockchainInfo")
+	proto.RegisterType((*ConfigBlockInfo)(nil), "common.ConfigBlockInfo")
+	proto.RegisterType((*ConfigBlocksInfo)(nil), "common.ConfigBlocksInfo")
 }
 
 func init() { proto.RegisterFile("common/ledger.proto", fileDescriptor3) }
 
 var fileDescriptor3 = []byte{
-	// 173 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x12, 0x4e, 0xce, 0xcf, 0xcd,
-	0xcd, 0xcf, 0xd3, 0xcf, 0x49, 0x4d, 0x49, 0x4f, 0x2d, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17,
-	0x62, 0x83, 0x08, 0x2a, 0x35, 0x31, 0x72, 0xf1, 0x39, 0xe5, 0xe4, 0x27, 0x67, 0x27, 0x67, 0x24,
-	0x66, 0xe6, 0x79, 0xe6, 0xa5, 0xe5, 0x0b, 0x89, 0x71, 0xb1, 0x65, 0xa4, 0x66, 0xa6, 0x67, 0x94,
-	0x48, 0x30, 0x2a, 0x30, 0x6a, 0xb0, 0x04, 0x41, 0x79, 0x42, 0x5a, 0x5c, 0x02, 0xc9, 0xa5, 0x45,
-	0x45, 0xa9, 0x79, 0x25, 0x60, 0x0d, 0x1e, 0x89, 0xc5, 0x19, 0x12, 0x4c, 0x0a, 0x8c, 0x1a, 0x3c,
-	0x41, 0x18, 0xe2, 0x42, 0x3a, 0x5c, 0x82, 0x05, 0x45, 0xa9, 0x65, 0x99, 0xf9, 0xa5, 0xc5, 0x08,
-	0xc5, 0xcc, 0x60, 0xc5, 0x98, 0x12, 0x4e, 0xba, 0x51, 0xda, 0xe9, 0x99, 0x25, 0x19, 0xa5, 0x49,
-	0x7a, 0xc9, 0xf9, 0xb9, 0xfa, 0x19, 0x95, 0x05, 0xa9, 0x45, 0x10, 0xc7, 0xea, 0xa7, 0x25, 0x26,
-	0x15, 0x65, 0x26, 0xeb, 0x83, 0xdd, 0x5c, 0xac, 0x0f, 0x71, 0x73, 0x12, 0x1b, 0x98, 0x6b, 0x0c,
-	0x08, 0x00, 0x00, 0xff, 0xff, 0xb5, 0x93, 0x7a, 0x44, 0xd9, 0x00, 0x00, 0x00,
+	// 244 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x90, 0xbf, 0x4b, 0xc4, 0x30,
+	0x18, 0x86, 0x89, 0x77, 0x14, 0xf9, 0xee, 0xd0, 0x33, 0x82, 0x16, 0xa7, 0xd2, 0xa9, 0xf8, 0xa3,
+	0x01, 0x1d, 0xdd, 0xce, 0x45, 0x17, 0x0f, 0x3a, 0xba, 0x35, 0xf1, 0x6b, 0x13, 0xbc, 0x26, 0x35,
+	0x69, 0x04, 0x57, 0xff, 0x72, 0xb9, 0xe4, 0xd0, 0x6a, 0xc7, 0xef, 0xc9, 0x93, 0x97, 0x97, 0x17,
+	0x4e, 0x85, 0xe9, 0x3a, 0xa3, 0xd9, 0x16, 0x5f, 0x5b, 0xb4, 0x65, 0x6f, 0xcd, 0x60, 0x68, 0x12,
+	0x61, 0xfe, 0x45, 0xe0, 0x68, 0xbd, 0x35, 0xe2, 0x4d, 0xc8, 0x5a, 0xe9, 0x27, 0xdd, 0x18, 0x7a,
+	0x06, 0x89, 0x44, 0xd5, 0xca, 0x21, 0x25, 0x19, 0x29, 0xe6, 0xd5, 0xfe, 0xa2, 0x97, 0xb0, 0x12,
+	0xde, 0x5a, 0xd4, 0x43, 0xf8, 0xf0, 0x58, 0x3b, 0x99, 0x1e, 0x64, 0xa4, 0x58, 0x56, 0x13, 0x4e,
+	0xaf, 0xe1, 0xa4, 0xb7, 0xf8, 0xa1, 0x8c, 0x77, 0xbf, 0xf2, 0x2c, 0xc8, 0xd3, 0x87, 0x7c, 0x03,
+	0xc7, 0x0f, 0x46, 0x37, 0xaa, 0x0d, 0x28, 0x94, 0xb8, 0x80, 0x43, 0x87, 0xef, 0x1e, 0xb5, 0xc0,
+	0x7d, 0x8d, 0x9f, 0x9b, 0x66, 0xb0, 0xe0, 0x3b, 0xf1, 0xd9, 0x77, 0x1c, 0x6d, 0xe8, 0x30, 0xaf,
+	0xc6, 0x28, 0xdf, 0xc0, 0x6a, 0x14, 0xe8, 0x42, 0xe2, 0x3d, 0x2c, 0xc5, 0x88, 0xa5, 0x24, 0x9b,
+	0x15, 0x8b, 0xdb, 0xf3, 0x32, 0x0e, 0x51, 0xfe, 0x2b, 0x50, 0xfd, 0x91, 0xd7, 0x37, 0x2f, 0x57,
+	0xad, 0x1a, 0xa4, 0xe7, 0x3b, 0x9d, 0xc9, 0xcf, 0x1e, 0x6d, 0x9c, 0x93, 0x35, 0x35, 0xb7, 0x4a,
+	0xb0, 0xb0, 0xaa, 0x63, 0x31, 0x8c, 0x27, 0xe1, 0xbc, 0xfb, 0x1e, 0x00, 0xdd, 0xb2, 0x4a, 0xc4,
+	0x7b, 0x01, 0x00, 0x00,
 }
@@ -47,17 +47,26 @@ func (MSPPrincipal_Classification) EnumDescriptor() ([]byte, []int) {
 type MSPRole_MSPRoleType int32
 
 const (
-	MSPRole_MEMBER MSPRole_MSPRoleType = 0
-	MSPRole_ADMIN  MSPRole_MSPRoleType = 1
+	MSPRole_MEMBER  MSPRole_MSPRoleType = 0
+	MSPRole_ADMIN   MSPRole_MSPRoleType = 1
+	MSPRole_PEER    MSPRole_MSPRoleType = 2
+	MSPRole_CLIENT  MSPRole_MSPRoleType = 3
+	MSPRole_ORDERER MSPRole_MSPRoleType = 4
 )
 
 var MSPRole_MSPRoleType_name = map[int32]string{
 	0: "MEMBER",
 	1: "ADMIN",
+	2: "PEER",
+	3: "CLIENT",
+	4: "ORDERER",
 }
 var MSPRole_MSPRoleType_value = map[string]int32{
-	"MEMBER": 0,
-	"ADMIN":  1,
+	"MEMBER":  0,
+	"ADMIN":   1,
+	"PEER":    2,
+	"CLIENT":  3,
+	"ORDERER": 4,
 }
 
 func (x MSPRole_MSPRoleType) String() string {
@@ -124,7 +133,9 @@ func (*OrganizationUnit) Descriptor() ([]byte, []int) { return fileDescriptor4,
 
 // MSPRole governs the organization of the Principal
 // field of an MSPPrincipal when it aims to define one of the
-// two dedicated roles within an MSP: Admin and Members.
+// dedicated roles within an MSP: Admin and Members, or one of
+// the identity types the MSP derives from the organizational
+// units of its members: Peer, Client and Orderer.
 type MSPRole struct {
 	// MSPIdentifier represents the identifier of the MSP this principal
 	// refers to
@@ -150,27 +161,29 @@ func init() {
 func init() { proto.RegisterFile("common/msp_principal.proto", fileDescriptor4) }
 
 var fileDescriptor4 = []byte{
-	// 352 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x8c, 0x92, 0xc1, 0x6a, 0xf2, 0x40,
-	0x14, 0x85, 0x8d, 0xf8, 0xfb, 0xeb, 0xad, 0x0d, 0xe9, 0x40, 0xa9, 0xb4, 0x52, 0x24, 0x6d, 0x41,
-	0x28, 0x4d, 0xc0, 0x3e, 0x40, 0xd1, 0x1a, 0x4a, 0xa0, 0x49, 0x64, 0x8c, 0x8b, 0xba, 0x68, 0x88,
-	0x31, 0xea, 0x40, 0x92, 0x19, 0x26, 0x71, 0x61, 0x37, 0x5d, 0xf6, 0xe9, 0xfa, 0x4e, 0x25, 0x23,
-	0xc6, 0xd8, 0x55, 0x57, 0xc3, 0x9c, 0x73, 0xbe, 0x73, 0x99, 0xcb, 0xc0, 0x65, 0x40, 0xe3, 0x98,
-	0x26, 0x7a, 0x9c, 0x32, 0x8f, 0x71, 0x92, 0x04, 0x84, 0xf9, 0x91, 0xc6, 0x38, 0xcd, 0x28, 0xaa,
-	0xef, 0x3c, 0xf5, 0x5b, 0x82, 0x96, 0x35, 0x19, 0x8f, 0xf7, 0x36, 0x7a, 0x87, 0x76, 0x91, 0xf5,
-	0x82, 0xc8, 0x4f, 0x53, 0xb2, 0x24, 0x81, 0x9f, 0x11, 0x9a, 0xb4, 0xa5, 0xae, 0xd4, 0x93, 0xfb,
-	0x37, 0xda, 0x8e, 0xd5, 0xca, 0x9c, 0xf6, 0x7c, 0x14, 0xc5, 0x17, 0x45, 0xc9, 0xb1, 0x81, 0x3a,
-	0xd0, 0x2c, 0xac, 0x76, 0xb5, 0x2b, 0xf5, 0x5a, 0xf8, 0x20, 0xa8, 0x4f, 0x20, 0xff, 0xca, 0x37,
-	0xa0, 0x86, 0x9d, 0x57, 0x43, 0xa9, 0xa0, 0x73, 0x38, 0x73, 0xf0, 0xcb, 0xc0, 0x36, 0x67, 0x03,
-	0xd7, 0x74, 0x6c, 0x6f, 0x6a, 0x9b, 0xae, 0x22, 0xa1, 0x16, 0x34, 0xcc, 0x91, 0x61, 0xbb, 0xa6,
-	0xfb, 0xa6, 0x54, 0xd5, 0x4f, 0x50, 0x1c, 0xbe, 0xf2, 0x13, 0xf2, 0x21, 0xf0, 0x69, 0x42, 0x32,
-	0x74, 0x07, 0x72, 0xbe, 0x02, 0xb2, 0x08, 0x93, 0x8c, 0x2c, 0x49, 0xc8, 0xc5, 0x43, 0x9a, 0xf8,
-	0x34, 0x4e, 0x99, 0x59, 0x88, 0x68, 0x04, 0xd7, 0xb4, 0x84, 0xfa, 0x91, 0xb7, 0x49, 0x48, 0x56,
-	0xc6, 0xaa, 0x02, 0xeb, 0x1c, 0xa7, 0xf2, 0x11, 0x87, 0x16, 0xf5, 0x4b, 0x82, 0xff, 0xd6, 0x64,
-	0x8c, 0x69, 0x14, 0xfe, 0x75, 0xb0, 0x0e, 0xb5, 0x3c, 0x2e, 0xea, 0xe5, 0xfe, 0x55, 0x69, 0xbd,
-	0xb9, 0xbc, 0x3f, 0xdd, 0x2d, 0x0b, 0xb1, 0x08, 0xaa, 0xb7, 0x70, 0x52, 0x12, 0x11, 0x40, 0xdd,
-	0x32, 0xac, 0xa1, 0x81, 0x95, 0x0a, 0x6a, 0xc2, 0xbf, 0xc1, 0xc8, 0x32, 0x6d, 0x45, 0x1a, 0x3e,
-	0xcc, 0xee, 0x57, 0x24, 0x5b, 0x6f, 0xe6, 0x79, 0xa1, 0xbe, 0xde, 0xb2, 0x90, 0x47, 0xe1, 0x62,
-	0x15, 0x72, 0x7d, 0xe9, 0xcf, 0x39, 0x09, 0x74, 0xf1, 0x13, 0x52, 0x7d, 0x37, 0x6e, 0x5e, 0x17,
-	0xd7, 0xc7, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x03, 0xd5, 0x3f, 0x0e, 0x36, 0x02, 0x00, 0x00,
+	// 375 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xdd, 0x8a, 0xda, 0x40,
+	0x1c, 0xc5, 0x4d, 0x6a, 0xfd, 0xf8, 0x6b, 0xc3, 0x74, 0xa0, 0x54, 0x5a, 0x29, 0x92, 0x52, 0x10,
+	0x4a, 0x13, 0xb0, 0x0f, 0x50, 0xd4, 0x0c, 0x32, 0x60, 0x3e, 0x18, 0xe3, 0x45, 0xbd, 0xd8, 0x10,
+	0x63, 0xd4, 0x81, 0x7c, 0x91, 0xc4, 0x0b, 0xf7, 0x66, 0xdf, 0x67, 0xdf, 0x65, 0xdf, 0x69, 0x49,
+	0xc4, 0x18, 0xf7, 0x6a, 0xaf, 0x42, 0xce, 0xf9, 0x9d, 0xff, 0x7f, 0xe6, 0x30, 0xf0, 0xcd, 0x8b,
+	0xc3, 0x30, 0x8e, 0xd4, 0x30, 0x4b, 0x9c, 0x24, 0xe5, 0x91, 0xc7, 0x13, 0x37, 0x50, 0x92, 0x34,
+	0xce, 0x63, 0xdc, 0xba, 0x78, 0xf2, 0x8b, 0x00, 0x7d, 0x7d, 0x65, 0x59, 0x57, 0x1b, 0x3f, 0xc0,
+	0xa0, 0x62, 0x1d, 0x2f, 0x70, 0xb3, 0x8c, 0xef, 0xb9, 0xe7, 0xe6, 0x3c, 0x8e, 0x06, 0xc2, 0x48,
+	0x18, 0x4b, 0x93, 0x9f, 0xca, 0x25, 0xab, 0xd4, 0x73, 0xca, 0xfc, 0x0e, 0x65, 0x5f, 0xab, 0x21,
+	0xf7, 0x06, 0x1e, 0x42, 0xb7, 0xb2, 0x06, 0xe2, 0x48, 0x18, 0xf7, 0xd9, 0x4d, 0x90, 0xff, 0x81,
+	0xf4, 0x86, 0xef, 0x40, 0x93, 0x99, 0x4b, 0x82, 0x1a, 0xf8, 0x0b, 0x7c, 0x36, 0xd9, 0x62, 0x6a,
+	0xd0, 0xcd, 0xd4, 0xa6, 0xa6, 0xe1, 0xac, 0x0d, 0x6a, 0x23, 0x01, 0xf7, 0xa1, 0x43, 0x35, 0x62,
+	0xd8, 0xd4, 0xfe, 0x8f, 0x44, 0xf9, 0x09, 0x90, 0x99, 0x1e, 0xdc, 0x88, 0x3f, 0x96, 0xf1, 0x75,
+	0xc4, 0x73, 0xfc, 0x0b, 0xa4, 0xa2, 0x02, 0xbe, 0xf3, 0xa3, 0x9c, 0xef, 0xb9, 0x9f, 0x96, 0x17,
+	0xe9, 0xb2, 0x4f, 0x61, 0x96, 0xd0, 0x4a, 0xc4, 0x1a, 0xfc, 0x88, 0x6b, 0x51, 0x37, 0x70, 0x4e,
+	0x11, 0xcf, 0xeb, 0x31, 0xb1, 0x8c, 0x0d, 0xef, 0xa9, 0x62, 0xc5, 0x6d, 0x8a, 0xfc, 0x2c, 0x40,
+	0x5b, 0x5f, 0x59, 0x2c, 0x0e, 0xfc, 0xf7, 0x2e, 0x56, 0xa1, 0x59, 0xe0, 0xe5, 0x78, 0x69, 0xf2,
+	0xbd, 0x56, 0x6f, 0x21, 0x5f, 0xbf, 0xf6, 0x39, 0xf1, 0x59, 0x09, 0xca, 0x0b, 0xe8, 0xd5, 0x44,
+	0x0c, 0xd0, 0xd2, 0x89, 0x3e, 0x23, 0x0c, 0x35, 0x70, 0x17, 0x3e, 0x4e, 0x35, 0x9d, 0x1a, 0x48,
+	0x28, 0x9a, 0xb3, 0x08, 0x61, 0x48, 0x2c, 0x80, 0xf9, 0x92, 0x12, 0xc3, 0x46, 0x1f, 0x70, 0x0f,
+	0xda, 0x26, 0xd3, 0x08, 0x23, 0x0c, 0x35, 0x67, 0x7f, 0x36, 0xbf, 0x0f, 0x3c, 0x3f, 0x9e, 0xb6,
+	0xc5, 0x4e, 0xf5, 0x78, 0x4e, 0xfc, 0x34, 0xf0, 0x77, 0x07, 0x3f, 0x55, 0xf7, 0xee, 0x36, 0xe5,
+	0x9e, 0x5a, 0x3e, 0x96, 0x4c, 0xbd, 0x9c, 0x68, 0xdb, 0x2a, 0x7f, 0xff, 0xbe, 0x0e, 0x00, 0x7a,
+	0x76, 0x1d, 0x10, 0x59, 0x02, 0x00, 0x00,
 }
@@ -214,39 +214,82 @@ func (m *SignaturePolicy_NOutOf) GetPolicies() []*SignaturePolicy {
 	return nil
 }
 
+// PolicySimulation reports whether a set of identities would satisfy a
+// signature policy, were they all to sign, and how each branch of the policy
+// evaluated, so that the signatures a policy needs can be checked before they
+// are collected
+type PolicySimulation struct {
+	Satisfied bool            `protobuf:"varint,1,opt,name=satisfied" json:"satisfied,omitempty"`
+	Branches  []*PolicyBranch `protobuf:"bytes,2,rep,name=branches" json:"branches,omitempty"`
+}
+
+func (m *PolicySimulation) Reset()                    { *m = PolicySimulation{} }
+func (m *PolicySimulation) String() string            { return proto.CompactTextString(m) }
+func (*PolicySimulation) ProtoMessage()               {}
+func (*PolicySimulation) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{3} }
+
+func (m *PolicySimulation) GetBranches() []*PolicyBranch {
+	if m != nil {
+		return m.Branches
+	}
+	return nil
+}
+
+// PolicyBranch is the evaluation of a branch of a signature policy. Its path
+// lists the indices of the branch and its ancestors within the NOutOf rules
+// they belong to, separated by '/', the path of the root being empty
+type PolicyBranch struct {
+	Path      string `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	Rule      string `protobuf:"bytes,2,opt,name=rule" json:"rule,omitempty"`
+	Satisfied bool   `protobuf:"varint,3,opt,name=satisfied" json:"satisfied,omitempty"`
+	Identity  int32  `protobuf:"varint,4,opt,name=identity" json:"identity,omitempty"`
+}
+
+func (m *PolicyBranch) Reset()                    { *m = PolicyBranch{} }
+func (m *PolicyBranch) String() string            { return proto.CompactTextString(m) }
+func (*PolicyBranch) ProtoMessage()               {}
+func (*PolicyBranch) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{4} }
+
 func init() {
 	proto.RegisterType((*Policy)(nil), "common.Policy")
 	proto.RegisterType((*SignaturePolicyEnvelope)(nil), "common.SignaturePolicyEnvelope")
 	proto.RegisterType((*SignaturePolicy)(nil), "common.SignaturePolicy")
 	proto.RegisterType((*SignaturePolicy_NOutOf)(nil), "common.SignaturePolicy.NOutOf")
+	proto.RegisterType((*PolicySimulation)(nil), "common.PolicySimulation")
+	proto.RegisterType((*PolicyBranch)(nil), "common.PolicyBranch")
 	proto.RegisterEnum("common.Policy_PolicyType", Policy_PolicyType_name, Policy_PolicyType_value)
 }
 
 func init() { proto.RegisterFile("common/policies.proto", fileDescriptor5) }
 
 var fileDescriptor5 = []byte{
-	// 359 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x51, 0xd1, 0x6a, 0xea, 0x40,
-	0x14, 0x74, 0xd5, 0x1b, 0xf5, 0xe8, 0xa5, 0xb2, 0xb4, 0x35, 0x08, 0x2d, 0x92, 0x27, 0xa1, 0x34,
-	0xa1, 0xda, 0x1f, 0xa8, 0x20, 0xb5, 0x88, 0x31, 0x24, 0x4a, 0xa1, 0x2f, 0x62, 0xe2, 0x1a, 0x17,
-	0x92, 0xec, 0xb2, 0x89, 0x42, 0x7e, 0xa5, 0xbf, 0xd2, 0x9f, 0x2b, 0xc9, 0x26, 0xa5, 0x15, 0x7c,
-	0xcb, 0x99, 0xcc, 0xcc, 0x99, 0xd9, 0x03, 0x37, 0x1e, 0x0b, 0x43, 0x16, 0x19, 0x9c, 0x05, 0xd4,
-	0xa3, 0x24, 0xd6, 0xb9, 0x60, 0x09, 0xc3, 0x8a, 0x84, 0xfb, 0xfd, 0xe2, 0x77, 0x18, 0xf3, 0x0d,
-	0x17, 0x34, 0xf2, 0x28, 0xdf, 0x06, 0x92, 0xa3, 0xf9, 0xa0, 0x58, 0x99, 0x2a, 0xc5, 0x18, 0xea,
-	0x49, 0xca, 0x89, 0x8a, 0x06, 0x68, 0xf8, 0xcf, 0xce, 0xbf, 0xf1, 0x2d, 0x28, 0xb9, 0x67, 0xaa,
-	0x56, 0x07, 0x68, 0xd8, 0xb1, 0x8b, 0x49, 0x7b, 0x02, 0x90, 0xaa, 0x55, 0xc6, 0x6a, 0x43, 0x63,
-	0x6d, 0xce, 0xcd, 0xe5, 0xbb, 0xd9, 0xad, 0xe0, 0xff, 0xd0, 0x72, 0xde, 0x5e, 0xcd, 0x97, 0xd5,
-	0xda, 0x9e, 0x76, 0x11, 0x6e, 0x40, 0x6d, 0xe1, 0x58, 0xdd, 0xaa, 0xf6, 0x89, 0xa0, 0xe7, 0x50,
-	0x3f, 0xda, 0x26, 0x47, 0x41, 0xa4, 0x78, 0x1a, 0x9d, 0x48, 0xc0, 0x38, 0xc1, 0x2a, 0x34, 0x4e,
-	0x44, 0xc4, 0x94, 0x45, 0xc5, 0xf6, 0x72, 0xc4, 0xc6, 0x9f, 0x00, 0xed, 0x51, 0x4f, 0x97, 0x5d,
-	0xf4, 0x33, 0xab, 0x32, 0x19, 0x7e, 0x06, 0xa0, 0x3b, 0x12, 0x25, 0x34, 0xa1, 0x24, 0x56, 0x6b,
-	0x83, 0xda, 0xb0, 0x3d, 0xba, 0x2e, 0x45, 0x0b, 0xc7, 0xb2, 0xca, 0xfe, 0xf6, 0x2f, 0x9e, 0xf6,
-	0x85, 0xe0, 0xea, 0xcc, 0x11, 0xdf, 0x41, 0x2b, 0xa6, 0x7e, 0x44, 0x76, 0x1b, 0x37, 0x95, 0xb1,
-	0x66, 0x15, 0xbb, 0x29, 0xa1, 0x49, 0xb6, 0xa8, 0xbe, 0x17, 0x2c, 0x2c, 0x72, 0xdd, 0x5f, 0xc8,
-	0xa5, 0x9b, 0xcb, 0x63, 0xb2, 0xdc, 0xcf, 0x2a, 0x76, 0xce, 0xee, 0xcf, 0x41, 0x91, 0x08, 0xee,
-	0x00, 0x32, 0x8b, 0xb6, 0xc8, 0xc4, 0x63, 0x68, 0x96, 0xc7, 0x53, 0xab, 0x79, 0xe8, 0x8b, 0x4d,
-	0x7f, 0x88, 0x13, 0x05, 0xea, 0xd9, 0xfb, 0x4f, 0x1e, 0x3f, 0x1e, 0x7c, 0x9a, 0x1c, 0x8e, 0x6e,
-	0x26, 0x31, 0x0e, 0x29, 0x27, 0x22, 0x20, 0x3b, 0x9f, 0x08, 0x63, 0xbf, 0x75, 0x05, 0xf5, 0x8c,
-	0xfc, 0xd4, 0xb1, 0x21, 0x0d, 0x5d, 0x25, 0x1f, 0xc7, 0xdf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x3b,
-	0xed, 0x93, 0x2e, 0x36, 0x02, 0x00, 0x00,
+	// 445 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x52, 0xed, 0x6a, 0xdb, 0x30,
+	0x14, 0x8d, 0xe3, 0xcc, 0xb1, 0x6f, 0x32, 0x16, 0xc4, 0xb6, 0x9a, 0xb0, 0x8d, 0xe0, 0x5f, 0x81,
+	0xb1, 0x78, 0x6b, 0xf7, 0x02, 0x0b, 0x94, 0x75, 0x94, 0x3a, 0x41, 0x6e, 0x19, 0xec, 0x4f, 0xb1,
+	0x1d, 0xc5, 0x16, 0xf8, 0x43, 0xc8, 0x72, 0xc1, 0xaf, 0xb2, 0x57, 0xd9, 0xcb, 0x0d, 0x49, 0xb6,
+	0xd7, 0x06, 0xd6, 0x7f, 0xf7, 0xe3, 0xdc, 0x73, 0xcf, 0x3d, 0x12, 0xbc, 0x49, 0xaa, 0xa2, 0xa8,
+	0x4a, 0x9f, 0x55, 0x39, 0x4d, 0x28, 0xa9, 0x37, 0x8c, 0x57, 0xa2, 0x42, 0x96, 0x2e, 0x2f, 0x97,
+	0x5d, 0xbb, 0xa8, 0xd9, 0x3d, 0xe3, 0xb4, 0x4c, 0x28, 0x8b, 0x72, 0x8d, 0xf1, 0x52, 0xb0, 0xf6,
+	0x72, 0xaa, 0x45, 0x08, 0x26, 0xa2, 0x65, 0xc4, 0x35, 0x56, 0xc6, 0xfa, 0x05, 0x56, 0x31, 0x7a,
+	0x0b, 0x96, 0xe2, 0x6c, 0xdd, 0xf1, 0xca, 0x58, 0xcf, 0x71, 0x97, 0x79, 0x5f, 0x00, 0xf4, 0xd4,
+	0xad, 0x44, 0xcd, 0x60, 0x7a, 0x17, 0x5c, 0x07, 0xbb, 0x9f, 0xc1, 0x62, 0x84, 0x5e, 0x82, 0x13,
+	0xfe, 0xf8, 0x1e, 0x7c, 0xbb, 0xbd, 0xc3, 0x97, 0x0b, 0x03, 0x4d, 0xc1, 0xbc, 0x09, 0xf7, 0x8b,
+	0xb1, 0xf7, 0xdb, 0x80, 0xb3, 0x90, 0xa6, 0x65, 0x24, 0x1a, 0x4e, 0xf4, 0xf0, 0x65, 0xf9, 0x40,
+	0xf2, 0x8a, 0x11, 0xe4, 0xc2, 0xf4, 0x81, 0xf0, 0x9a, 0x56, 0x65, 0xb7, 0xbd, 0x4f, 0x91, 0xff,
+	0x44, 0xc0, 0xec, 0xfc, 0x6c, 0xa3, 0x6f, 0xd9, 0x9c, 0x50, 0xf5, 0xca, 0xd0, 0x57, 0x00, 0x7a,
+	0x20, 0xa5, 0xa0, 0x82, 0x92, 0xda, 0x35, 0x57, 0xe6, 0x7a, 0x76, 0xfe, 0xba, 0x1f, 0xba, 0x09,
+	0xf7, 0xfb, 0xfe, 0x7e, 0xfc, 0x08, 0xe7, 0xfd, 0x31, 0xe0, 0xd5, 0x09, 0x23, 0x7a, 0x0f, 0x4e,
+	0x4d, 0xd3, 0x92, 0x1c, 0xee, 0xe3, 0x56, 0xcb, 0xba, 0x1a, 0x61, 0x5b, 0x97, 0xb6, 0x72, 0xd1,
+	0xe4, 0xc8, 0xab, 0xa2, 0xd3, 0xf5, 0xe1, 0x3f, 0xba, 0x36, 0xc1, 0xae, 0x11, 0xbb, 0xe3, 0xd5,
+	0x08, 0x2b, 0xf4, 0xf2, 0x1a, 0x2c, 0x5d, 0x41, 0x73, 0x30, 0x82, 0xee, 0x5a, 0x23, 0x40, 0x17,
+	0x60, 0xf7, 0x8f, 0xe7, 0x8e, 0x57, 0xe6, 0x73, 0x97, 0x0e, 0xc0, 0xad, 0x05, 0x13, 0xe9, 0xbf,
+	0x17, 0xc3, 0x42, 0xf7, 0x42, 0x5a, 0x34, 0x79, 0x24, 0xa4, 0x71, 0xef, 0xc0, 0xa9, 0x23, 0x41,
+	0xeb, 0x23, 0x25, 0x07, 0xb5, 0xc6, 0xc6, 0xff, 0x0a, 0xe8, 0x33, 0xd8, 0x31, 0x8f, 0xca, 0x24,
+	0x1b, 0xd6, 0x0d, 0x1e, 0x69, 0xa6, 0xad, 0xea, 0xe2, 0x01, 0xe5, 0x31, 0x98, 0x3f, 0xee, 0xc8,
+	0xdf, 0xc2, 0x22, 0x91, 0x29, 0x6a, 0x07, 0xab, 0x58, 0xd6, 0x78, 0x93, 0x13, 0x65, 0x89, 0x83,
+	0x55, 0xfc, 0x54, 0x87, 0x79, 0xaa, 0x63, 0x09, 0x76, 0xf7, 0x0a, 0xad, 0x3b, 0x51, 0x5e, 0x0c,
+	0xf9, 0xf6, 0xd3, 0xaf, 0x8f, 0x29, 0x15, 0x59, 0x13, 0x4b, 0x65, 0x7e, 0xd6, 0x32, 0xc2, 0x73,
+	0x72, 0x48, 0x09, 0xf7, 0x8f, 0x51, 0xcc, 0x69, 0xe2, 0xab, 0x0f, 0x5c, 0xfb, 0x5a, 0x77, 0x6c,
+	0xa9, 0xf4, 0xe2, 0xef, 0x00, 0x18, 0x5a, 0x7b, 0x09, 0x0c, 0x03, 0x00, 0x00,
 }
@@ -163,6 +163,11 @@ func (m *GossipMessage) IsPullMsg() bool {
 		m.GetHello() != nil || m.GetDataDig() != nil
 }
 
+// IsPullBatch returns whether this GossipMessage is a batch of pull messages
+func (m *GossipMessage) IsPullBatch() bool {
+	return m.GetPullBatch() != nil
+}
+
 // IsRemoteStateMessage returns whether this GossipMessage is related to state synchronization
 func (m *GossipMessage) IsRemoteStateMessage() bool {
 	return m.GetStateRequest() != nil || m.GetStateResponse() != nil
@@ -259,6 +264,13 @@ func (m *GossipMessage) IsTagLegal() error {
 		return nil
 	}
 
+	if m.IsPullBatch() {
+		if m.Tag != GossipMessage_EMPTY {
+			return fmt.Errorf("Tag should be %s", GossipMessage_Tag_name[int32(GossipMessage_EMPTY)])
+		}
+		return nil
+	}
+
 	if m.IsIdentityMsg() {
 		if m.Tag != GossipMessage_ORG_ONLY {
 			return fmt.Errorf("Tag should be %s", GossipMessage_Tag_name[int32(GossipMessage_ORG_ONLY)])
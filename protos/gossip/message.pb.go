@@ -32,6 +32,7 @@ It has these top-level messages:
 	Empty
 	RemoteStateRequest
 	RemoteStateResponse
+	PullBatch
 */
 package gossip
 
@@ -155,6 +156,7 @@ type GossipMessage struct {
 	//	*GossipMessage_StateResponse
 	//	*GossipMessage_LeadershipMsg
 	//	*GossipMessage_PeerIdentity
+	//	*GossipMessage_PullBatch
 	Content isGossipMessage_Content `protobuf_oneof:"content"`
 }
 
@@ -218,6 +220,9 @@ type GossipMessage_LeadershipMsg struct {
 type GossipMessage_PeerIdentity struct {
 	PeerIdentity *PeerIdentity `protobuf:"bytes,21,opt,name=peerIdentity,oneof"`
 }
+type GossipMessage_PullBatch struct {
+	PullBatch *PullBatch `protobuf:"bytes,22,opt,name=pullBatch,oneof"`
+}
 
 func (*GossipMessage_AliveMsg) isGossipMessage_Content()         {}
 func (*GossipMessage_MemReq) isGossipMessage_Content()           {}
@@ -236,6 +241,7 @@ func (*GossipMessage_StateRequest) isGossipMessage_Content()     {}
 func (*GossipMessage_StateResponse) isGossipMessage_Content()    {}
 func (*GossipMessage_LeadershipMsg) isGossipMessage_Content()    {}
 func (*GossipMessage_PeerIdentity) isGossipMessage_Content()     {}
+func (*GossipMessage_PullBatch) isGossipMessage_Content()        {}
 
 func (m *GossipMessage) GetContent() isGossipMessage_Content {
 	if m != nil {
@@ -363,6 +369,13 @@ func (m *GossipMessage) GetPeerIdentity() *PeerIdentity {
 	return nil
 }
 
+func (m *GossipMessage) GetPullBatch() *PullBatch {
+	if x, ok := m.GetContent().(*GossipMessage_PullBatch); ok {
+		return x.PullBatch
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*GossipMessage) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _GossipMessage_OneofMarshaler, _GossipMessage_OneofUnmarshaler, _GossipMessage_OneofSizer, []interface{}{
@@ -383,6 +396,7 @@ func (*GossipMessage) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer)
 		(*GossipMessage_StateResponse)(nil),
 		(*GossipMessage_LeadershipMsg)(nil),
 		(*GossipMessage_PeerIdentity)(nil),
+		(*GossipMessage_PullBatch)(nil),
 	}
 }
 
@@ -475,6 +489,11 @@ func _GossipMessage_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.PeerIdentity); err != nil {
 			return err
 		}
+	case *GossipMessage_PullBatch:
+		b.EncodeVarint(22<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PullBatch); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("GossipMessage.Content has unexpected type %T", x)
@@ -621,6 +640,14 @@ func _GossipMessage_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.
 		err := b.DecodeMessage(msg)
 		m.Content = &GossipMessage_PeerIdentity{msg}
 		return true, err
+	case 22: // content.pullBatch
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PullBatch)
+		err := b.DecodeMessage(msg)
+		m.Content = &GossipMessage_PullBatch{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -715,6 +742,11 @@ func _GossipMessage_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(21<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *GossipMessage_PullBatch:
+		s := proto.Size(x.PullBatch)
+		n += proto.SizeVarint(22<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -891,6 +923,7 @@ type AliveMessage struct {
 	Membership *Member   `protobuf:"bytes,1,opt,name=membership" json:"membership,omitempty"`
 	Timestamp  *PeerTime `protobuf:"bytes,2,opt,name=timestamp" json:"timestamp,omitempty"`
 	Identity   []byte    `protobuf:"bytes,4,opt,name=identity,proto3" json:"identity,omitempty"`
+	Leaving    bool      `protobuf:"varint,5,opt,name=leaving" json:"leaving,omitempty"`
 }
 
 func (m *AliveMessage) Reset()                    { *m = AliveMessage{} }
@@ -996,6 +1029,9 @@ type Member struct {
 	// internalEndpoint is used to connect to the peer
 	// if its in your own organization
 	InternalEndpoint *SignedEndpoint `protobuf:"bytes,4,opt,name=internalEndpoint" json:"internalEndpoint,omitempty"`
+	// org is the organization the peer claims to belong to,
+	// which its identity is verified against
+	Org []byte `protobuf:"bytes,5,opt,name=org,proto3" json:"org,omitempty"`
 }
 
 func (m *Member) Reset()                    { *m = Member{} }
@@ -1063,6 +1099,24 @@ func (m *RemoteStateResponse) GetPayloads() []*Payload {
 	return nil
 }
 
+// PullBatch is a set of pull messages of several channels,
+// sent to the same remote peer in a single message
+type PullBatch struct {
+	Messages []*GossipMessage `protobuf:"bytes,1,rep,name=messages" json:"messages,omitempty"`
+}
+
+func (m *PullBatch) Reset()                    { *m = PullBatch{} }
+func (m *PullBatch) String() string            { return proto.CompactTextString(m) }
+func (*PullBatch) ProtoMessage()               {}
+func (*PullBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *PullBatch) GetMessages() []*GossipMessage {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*SignedGossipMessage)(nil), "gossip.SignedGossipMessage")
 	proto.RegisterType((*GossipMessage)(nil), "gossip.GossipMessage")
@@ -1087,6 +1141,7 @@ func init() {
 	proto.RegisterType((*Empty)(nil), "gossip.Empty")
 	proto.RegisterType((*RemoteStateRequest)(nil), "gossip.RemoteStateRequest")
 	proto.RegisterType((*RemoteStateResponse)(nil), "gossip.RemoteStateResponse")
+	proto.RegisterType((*PullBatch)(nil), "gossip.PullBatch")
 	proto.RegisterEnum("gossip.PullMsgType", PullMsgType_name, PullMsgType_value)
 	proto.RegisterEnum("gossip.GossipMessage_Tag", GossipMessage_Tag_name, GossipMessage_Tag_value)
 }
@@ -1236,84 +1291,87 @@ var _Gossip_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("gossip/message.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1250 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xb4, 0x57, 0x5f, 0x4f, 0xdc, 0x46,
-	0x10, 0x3f, 0x73, 0x7f, 0x3d, 0x77, 0x07, 0x66, 0x21, 0x91, 0x4b, 0x53, 0x09, 0x59, 0x6d, 0x45,
-	0x8a, 0x72, 0x34, 0x24, 0x0f, 0x55, 0x5f, 0x52, 0xc8, 0x91, 0x1c, 0x51, 0xee, 0x82, 0x16, 0xf2,
-	0x90, 0xbe, 0xa0, 0xe5, 0xbc, 0xf8, 0x5c, 0xec, 0xb5, 0xe3, 0x5d, 0x5a, 0x21, 0x55, 0xea, 0x7b,
-	0x3f, 0x42, 0x3f, 0x40, 0x3f, 0x67, 0xb5, 0xbb, 0x5e, 0x9f, 0xcd, 0x1d, 0x54, 0x54, 0xea, 0x9b,
-	0x67, 0xf6, 0x37, 0x7f, 0x76, 0x66, 0x76, 0x66, 0x0c, 0x9b, 0x41, 0xc2, 0x79, 0x98, 0xee, 0xc5,
-	0x94, 0x73, 0x12, 0xd0, 0x41, 0x9a, 0x25, 0x22, 0x41, 0x2d, 0xcd, 0xf5, 0xc6, 0xb0, 0x71, 0x1a,
-	0x06, 0x8c, 0xfa, 0x6f, 0x15, 0x3d, 0xd6, 0x20, 0xe4, 0x42, 0x3b, 0x25, 0x37, 0x51, 0x42, 0x7c,
-	0xd7, 0xda, 0xb6, 0x76, 0x7a, 0xd8, 0x90, 0xe8, 0x09, 0xd8, 0x3c, 0x0c, 0x18, 0x11, 0xd7, 0x19,
-	0x75, 0x57, 0xd4, 0xd9, 0x9c, 0xe1, 0xfd, 0x6d, 0x43, 0xbf, 0xaa, 0x69, 0x13, 0x9a, 0x2c, 0x61,
-	0x53, 0xaa, 0xf4, 0x34, 0xb0, 0x26, 0xa4, 0xfe, 0xe9, 0x8c, 0x30, 0x46, 0xa3, 0x5c, 0x87, 0x21,
-	0xd1, 0x2e, 0xd4, 0x05, 0x09, 0xdc, 0xfa, 0xb6, 0xb5, 0xb3, 0xba, 0xff, 0xc5, 0x40, 0xbb, 0x39,
-	0xa8, 0xe8, 0x1c, 0x9c, 0x91, 0x00, 0x4b, 0x54, 0xd5, 0x99, 0xc6, 0x2d, 0x67, 0xd0, 0x3e, 0x74,
-	0x48, 0x14, 0xfe, 0x4a, 0xc7, 0x3c, 0x70, 0x9b, 0xdb, 0xd6, 0x4e, 0x77, 0x7f, 0xd3, 0xe8, 0x3b,
-	0x50, 0x7c, 0xad, 0x6e, 0x54, 0xc3, 0x05, 0x0e, 0xbd, 0x80, 0x56, 0x4c, 0x63, 0x4c, 0x3f, 0xbb,
-	0x2d, 0x25, 0x51, 0x78, 0x30, 0xa6, 0xf1, 0x05, 0xcd, 0xf8, 0x2c, 0x4c, 0x31, 0xfd, 0x7c, 0x4d,
-	0xb9, 0x18, 0xd5, 0x70, 0x0e, 0x45, 0x2f, 0x73, 0x21, 0xee, 0xb6, 0x95, 0xd0, 0xd6, 0x32, 0x21,
-	0x9e, 0x26, 0x8c, 0xd3, 0x42, 0x8a, 0xa3, 0x3d, 0x68, 0xfb, 0x44, 0x10, 0xe9, 0x5d, 0x47, 0x89,
-	0x6d, 0x18, 0xb1, 0xa1, 0x64, 0x17, 0xce, 0x19, 0x14, 0xda, 0x85, 0xe6, 0x8c, 0x46, 0x51, 0xe2,
-	0xda, 0x55, 0xb8, 0x0e, 0xce, 0x48, 0x1e, 0x8d, 0x6a, 0x58, 0x63, 0xd0, 0x40, 0x6b, 0x1f, 0x86,
-	0x81, 0x0b, 0x0a, 0x8e, 0xca, 0xda, 0x87, 0x61, 0xa0, 0xaf, 0x60, 0x40, 0xc6, 0x1b, 0x79, 0xf3,
-	0xee, 0xa2, 0x37, 0xf3, 0x3b, 0x1b, 0x14, 0x7a, 0x09, 0x20, 0x3f, 0x3f, 0xa6, 0x3e, 0x11, 0xd4,
-	0xed, 0x2d, 0xda, 0xd0, 0x27, 0xa3, 0x1a, 0x2e, 0xe1, 0xd0, 0x37, 0xd0, 0xa4, 0x71, 0x2a, 0x6e,
-	0xdc, 0xbe, 0x12, 0xe8, 0x1b, 0x81, 0x23, 0xc9, 0x94, 0xde, 0xab, 0x53, 0xb4, 0x0b, 0x8d, 0x69,
-	0xc2, 0x98, 0xbb, 0xaa, 0x50, 0x8f, 0x0c, 0xea, 0x75, 0xc2, 0xd8, 0x11, 0x17, 0xe4, 0x22, 0x0a,
-	0xf9, 0x6c, 0x54, 0xc3, 0x0a, 0x84, 0x9e, 0x83, 0xcd, 0x05, 0x11, 0xf4, 0x98, 0x5d, 0x26, 0xee,
-	0x9a, 0x92, 0x58, 0x37, 0x12, 0xa7, 0xe6, 0x60, 0x54, 0xc3, 0x73, 0x14, 0x3a, 0x80, 0xbe, 0x22,
-	0x4e, 0x19, 0x49, 0xf9, 0x2c, 0x11, 0xae, 0x53, 0xcd, 0x76, 0x21, 0x66, 0x00, 0xa3, 0x1a, 0xae,
-	0x4a, 0xa0, 0x77, 0xe0, 0x14, 0xfa, 0x4e, 0xae, 0xa3, 0x48, 0x46, 0x6e, 0x5d, 0x69, 0x79, 0xb2,
-	0xa0, 0x25, 0x3f, 0xcf, 0x43, 0xb8, 0x20, 0x87, 0x7e, 0x82, 0x9e, 0xe2, 0xe5, 0x18, 0x17, 0x55,
-	0xcb, 0x08, 0xd3, 0x38, 0x11, 0xf4, 0xb4, 0x84, 0x18, 0xd5, 0x70, 0x45, 0x02, 0xbd, 0xce, 0x2f,
-	0x64, 0xea, 0xcc, 0xdd, 0x50, 0x2a, 0xbe, 0x5c, 0xaa, 0xa2, 0x28, 0xc5, 0xaa, 0x8c, 0x8c, 0x4a,
-	0x44, 0x89, 0xaf, 0x2b, 0x56, 0xd6, 0xe5, 0x66, 0x35, 0x2a, 0xef, 0xe7, 0x87, 0x45, 0x75, 0x56,
-	0x25, 0xd0, 0x8f, 0xd0, 0x4b, 0x29, 0xcd, 0x8e, 0x7d, 0xca, 0x44, 0x28, 0x6e, 0xdc, 0x47, 0xd5,
-	0x77, 0x77, 0x52, 0x3a, 0x93, 0x77, 0x28, 0x63, 0xbd, 0x73, 0xa8, 0x9f, 0x91, 0x00, 0xf5, 0xc1,
-	0xfe, 0x38, 0x19, 0x1e, 0xbd, 0x39, 0x9e, 0x1c, 0x0d, 0x9d, 0x1a, 0xb2, 0xa1, 0x79, 0x34, 0x3e,
-	0x39, 0xfb, 0xe4, 0x58, 0xa8, 0x07, 0x9d, 0x0f, 0xf8, 0xed, 0xf9, 0x87, 0xc9, 0xfb, 0x4f, 0xce,
-	0x8a, 0xc4, 0xbd, 0x1e, 0x1d, 0x4c, 0x34, 0x59, 0x47, 0x0e, 0xf4, 0x14, 0x79, 0x30, 0x19, 0x9e,
-	0x7f, 0xc0, 0x6f, 0x9d, 0x06, 0x5a, 0x83, 0xae, 0x06, 0x60, 0xc5, 0x68, 0x1e, 0xda, 0xd0, 0x9e,
-	0x26, 0x4c, 0x50, 0x26, 0xbc, 0x18, 0xec, 0x22, 0x3b, 0x68, 0x0b, 0x3a, 0x31, 0x15, 0x44, 0x96,
-	0x69, 0xde, 0xee, 0x0a, 0x1a, 0x0d, 0xc0, 0x16, 0x61, 0x4c, 0xb9, 0x20, 0x71, 0xaa, 0x7a, 0x55,
-	0x77, 0xdf, 0x29, 0xdf, 0xe6, 0x2c, 0x8c, 0x29, 0x9e, 0x43, 0x64, 0xbf, 0x4b, 0xaf, 0xc2, 0xe3,
-	0xa1, 0xea, 0x60, 0x3d, 0xac, 0x09, 0xef, 0x0d, 0xac, 0x2f, 0x94, 0x14, 0x7a, 0x0e, 0x1d, 0x1a,
-	0xd1, 0x98, 0x32, 0xc1, 0x5d, 0x6b, 0xbb, 0x5e, 0x2e, 0xf4, 0x4a, 0xbf, 0xc3, 0x05, 0xcc, 0x7b,
-	0x0c, 0x9b, 0xcb, 0x8a, 0xca, 0x1b, 0x43, 0xbf, 0xf2, 0x36, 0xe6, 0x6e, 0x58, 0x25, 0x37, 0x10,
-	0x82, 0xc6, 0x94, 0x66, 0x22, 0xef, 0xb9, 0xea, 0x5b, 0xf2, 0x66, 0x84, 0xcf, 0x72, 0x7f, 0xd5,
-	0xb7, 0x77, 0x06, 0xbd, 0x72, 0xa6, 0x1e, 0xa0, 0xad, 0x1c, 0xca, 0x7a, 0x35, 0x94, 0x5e, 0x04,
-	0xdd, 0x52, 0x2f, 0xb9, 0x7b, 0x32, 0xf8, 0xaa, 0x39, 0x71, 0x77, 0x65, 0xbb, 0xbe, 0x63, 0x63,
-	0x43, 0xa2, 0x67, 0xd0, 0x8e, 0x79, 0x70, 0x76, 0x93, 0xd2, 0x7c, 0x3a, 0x14, 0x1d, 0x4a, 0x46,
-	0x62, 0xac, 0x8f, 0xb0, 0xc1, 0x78, 0x0c, 0xba, 0xa5, 0xc6, 0x78, 0x87, 0xb5, 0xb2, 0xbb, 0x2b,
-	0xb7, 0x32, 0xff, 0x40, 0x7b, 0xbf, 0x03, 0xcc, 0xbb, 0xde, 0x1d, 0xe6, 0x9e, 0x42, 0x23, 0x37,
-	0x75, 0x4f, 0xb6, 0x1b, 0xff, 0xc5, 0xfa, 0x95, 0xb6, 0xae, 0xfb, 0xfa, 0xff, 0x1d, 0xda, 0x1f,
-	0x74, 0x22, 0xcd, 0x88, 0x7f, 0x5a, 0x5d, 0x16, 0xba, 0xfb, 0x6b, 0x85, 0xb4, 0x66, 0x17, 0xdb,
-	0x83, 0x77, 0x0c, 0xed, 0x9c, 0x87, 0x1e, 0x43, 0x8b, 0xd3, 0xcf, 0x93, 0xeb, 0x38, 0x77, 0x32,
-	0xa7, 0x8a, 0x7a, 0x94, 0xe9, 0xb0, 0x75, 0x3d, 0x4a, 0x5e, 0xa9, 0xa2, 0xd4, 0xb7, 0xf7, 0xa7,
-	0x05, 0xbd, 0xf2, 0x18, 0x47, 0x03, 0x80, 0xb8, 0x98, 0xb7, 0xb9, 0x27, 0xab, 0xd5, 0x49, 0x8c,
-	0x4b, 0x88, 0x07, 0xbf, 0xec, 0x2d, 0xe8, 0x84, 0xa6, 0xad, 0xe9, 0x5d, 0xa3, 0xa0, 0xbd, 0x3f,
-	0x60, 0x7d, 0xa1, 0x39, 0xde, 0xf1, 0x6a, 0x1e, 0x6a, 0xf6, 0x6b, 0xe8, 0x87, 0x7c, 0x48, 0xa7,
-	0x11, 0xc9, 0x88, 0x08, 0x13, 0xa6, 0x82, 0xd0, 0xc1, 0x55, 0xa6, 0x77, 0x00, 0x1d, 0x23, 0x8c,
-	0xbe, 0x02, 0x08, 0xd9, 0xf4, 0x9c, 0x5d, 0xcb, 0xab, 0xe6, 0xd1, 0xb5, 0x43, 0x36, 0x9d, 0x28,
-	0x46, 0x29, 0xf0, 0x2b, 0xe5, 0xc0, 0x7b, 0xbf, 0xc0, 0xfa, 0xc2, 0x92, 0x83, 0x5e, 0xc1, 0x1a,
-	0xa7, 0xd1, 0xa5, 0xec, 0x37, 0x59, 0xac, 0xed, 0x5b, 0xd5, 0x99, 0x5c, 0x2d, 0xde, 0xdb, 0x68,
-	0x19, 0x84, 0x2b, 0x96, 0xfc, 0xc6, 0x54, 0xc9, 0xf5, 0xb0, 0x26, 0xbc, 0x08, 0xd0, 0xe2, 0x6e,
-	0x24, 0x17, 0x1c, 0xb5, 0x88, 0xdd, 0xdf, 0x0d, 0x35, 0x46, 0xbd, 0x25, 0x4a, 0xfc, 0x7f, 0x7b,
-	0x4b, 0x94, 0xf8, 0xde, 0x5f, 0x16, 0xb4, 0xb4, 0x39, 0x99, 0x44, 0xca, 0xfc, 0x34, 0x09, 0x99,
-	0x50, 0x17, 0xb1, 0x71, 0x41, 0xdf, 0xdb, 0x0c, 0x96, 0xb6, 0x75, 0x74, 0x08, 0x4e, 0xc8, 0x04,
-	0xcd, 0x18, 0x89, 0x8e, 0x8c, 0xd6, 0x86, 0x0a, 0xcf, 0xe3, 0x62, 0x07, 0x50, 0xdb, 0xb5, 0x39,
-	0xc5, 0x0b, 0x78, 0xef, 0x1d, 0xac, 0x56, 0x31, 0xf7, 0xfa, 0x78, 0xff, 0xfa, 0xdd, 0x86, 0xa6,
-	0x5a, 0xa4, 0xbc, 0x01, 0xa0, 0xc5, 0xa5, 0x41, 0x36, 0x00, 0x9d, 0x6b, 0x3d, 0x6f, 0x1a, 0xd8,
-	0x90, 0xde, 0x21, 0x6c, 0x2c, 0xd9, 0x10, 0xd0, 0x2e, 0x74, 0xf2, 0x97, 0x6b, 0x26, 0xd4, 0xc2,
-	0xd3, 0x2e, 0x00, 0xdf, 0xbd, 0x82, 0x6e, 0xa9, 0x5b, 0xa8, 0x31, 0xce, 0x7c, 0x7a, 0x19, 0x32,
-	0xea, 0x3b, 0x35, 0x39, 0x9e, 0x0f, 0xa3, 0x64, 0x7a, 0x95, 0x67, 0xc6, 0xb1, 0xe4, 0x78, 0x36,
-	0x03, 0x66, 0xcc, 0x03, 0x67, 0x65, 0x5f, 0x40, 0x4b, 0x67, 0x0f, 0x1d, 0x42, 0x4f, 0x7f, 0x9d,
-	0x8a, 0x8c, 0x92, 0x18, 0x2d, 0xcf, 0xee, 0xd6, 0x72, 0xb6, 0x57, 0xdb, 0xb1, 0xbe, 0xb7, 0xd0,
-	0xb7, 0xd0, 0x38, 0x09, 0x59, 0x80, 0xaa, 0x2b, 0xe6, 0x56, 0x95, 0xf4, 0x6a, 0x87, 0xcf, 0x7e,
-	0xde, 0x0d, 0x42, 0x31, 0xbb, 0xbe, 0x18, 0x4c, 0x93, 0x78, 0x6f, 0x76, 0x93, 0xd2, 0x2c, 0xa2,
-	0x7e, 0x40, 0xb3, 0xbd, 0x4b, 0x72, 0x91, 0x85, 0xd3, 0x3d, 0xf5, 0xc3, 0xc4, 0xf7, 0xb4, 0xd8,
-	0x45, 0x4b, 0x91, 0x2f, 0xfe, 0x09, 0x00, 0x00, 0xff, 0xff, 0xaf, 0xae, 0x6f, 0x88, 0x57, 0x0d,
-	0x00, 0x00,
+	// 1305 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x57, 0x5f, 0x6f, 0xdb, 0xb6,
+	0x16, 0xb7, 0xe2, 0xbf, 0x3a, 0xb6, 0x13, 0x87, 0x49, 0x03, 0xdd, 0xdc, 0x5e, 0x20, 0x10, 0xee,
+	0xbd, 0x48, 0x17, 0xd4, 0x59, 0xd3, 0x3e, 0x0c, 0x7b, 0x58, 0x17, 0xd7, 0x69, 0x9d, 0xa2, 0x76,
+	0x03, 0x26, 0x7d, 0xe8, 0x5e, 0x02, 0xc6, 0x62, 0x64, 0x2d, 0x12, 0xa5, 0x8a, 0x74, 0x87, 0x00,
+	0x03, 0xf6, 0x65, 0xf6, 0xb8, 0x2f, 0xb4, 0x6f, 0x33, 0x90, 0x14, 0x65, 0xa9, 0x76, 0x52, 0x64,
+	0xc0, 0xde, 0x74, 0x0e, 0x7f, 0xbf, 0xc3, 0xc3, 0x73, 0x0e, 0x0f, 0x8f, 0x60, 0xdb, 0x8f, 0x39,
+	0x0f, 0x92, 0xc3, 0x88, 0x72, 0x4e, 0x7c, 0xda, 0x4f, 0xd2, 0x58, 0xc4, 0xa8, 0xa1, 0xb5, 0xee,
+	0x18, 0xb6, 0xce, 0x03, 0x9f, 0x51, 0xef, 0x8d, 0x92, 0xc7, 0x1a, 0x84, 0x1c, 0x68, 0x26, 0xe4,
+	0x36, 0x8c, 0x89, 0xe7, 0x58, 0x7b, 0xd6, 0x7e, 0x07, 0x1b, 0x11, 0x3d, 0x06, 0x9b, 0x07, 0x3e,
+	0x23, 0x62, 0x9e, 0x52, 0x67, 0x4d, 0xad, 0x2d, 0x14, 0xee, 0x9f, 0x36, 0x74, 0xcb, 0x96, 0xb6,
+	0xa1, 0xce, 0x62, 0x36, 0xa5, 0xca, 0x4e, 0x0d, 0x6b, 0x41, 0xda, 0x9f, 0xce, 0x08, 0x63, 0x34,
+	0xcc, 0x6c, 0x18, 0x11, 0x1d, 0x40, 0x55, 0x10, 0xdf, 0xa9, 0xee, 0x59, 0xfb, 0xeb, 0x47, 0xff,
+	0xea, 0x6b, 0x37, 0xfb, 0x25, 0x9b, 0xfd, 0x0b, 0xe2, 0x63, 0x89, 0x2a, 0x3b, 0x53, 0xfb, 0xc2,
+	0x19, 0x74, 0x04, 0x2d, 0x12, 0x06, 0x9f, 0xe9, 0x98, 0xfb, 0x4e, 0x7d, 0xcf, 0xda, 0x6f, 0x1f,
+	0x6d, 0x1b, 0x7b, 0xc7, 0x4a, 0xaf, 0xcd, 0x8d, 0x2a, 0x38, 0xc7, 0xa1, 0xe7, 0xd0, 0x88, 0x68,
+	0x84, 0xe9, 0x27, 0xa7, 0xa1, 0x18, 0xb9, 0x07, 0x63, 0x1a, 0x5d, 0xd1, 0x94, 0xcf, 0x82, 0x04,
+	0xd3, 0x4f, 0x73, 0xca, 0xc5, 0xa8, 0x82, 0x33, 0x28, 0x7a, 0x91, 0x91, 0xb8, 0xd3, 0x54, 0xa4,
+	0xdd, 0x55, 0x24, 0x9e, 0xc4, 0x8c, 0xd3, 0x9c, 0xc5, 0xd1, 0x21, 0x34, 0x3d, 0x22, 0x88, 0xf4,
+	0xae, 0xa5, 0x68, 0x5b, 0x86, 0x36, 0x94, 0xea, 0xdc, 0x39, 0x83, 0x42, 0x07, 0x50, 0x9f, 0xd1,
+	0x30, 0x8c, 0x1d, 0xbb, 0x0c, 0xd7, 0xc1, 0x19, 0xc9, 0xa5, 0x51, 0x05, 0x6b, 0x0c, 0xea, 0x6b,
+	0xeb, 0xc3, 0xc0, 0x77, 0x40, 0xc1, 0x51, 0xd1, 0xfa, 0x30, 0xf0, 0xf5, 0x11, 0x0c, 0xc8, 0x78,
+	0x23, 0x4f, 0xde, 0x5e, 0xf6, 0x66, 0x71, 0x66, 0x83, 0x42, 0x2f, 0x00, 0xe4, 0xe7, 0x87, 0xc4,
+	0x23, 0x82, 0x3a, 0x9d, 0xe5, 0x3d, 0xf4, 0xca, 0xa8, 0x82, 0x0b, 0x38, 0xf4, 0x3f, 0xa8, 0xd3,
+	0x28, 0x11, 0xb7, 0x4e, 0x57, 0x11, 0xba, 0x86, 0x70, 0x22, 0x95, 0xd2, 0x7b, 0xb5, 0x8a, 0x0e,
+	0xa0, 0x36, 0x8d, 0x19, 0x73, 0xd6, 0x15, 0xea, 0x91, 0x41, 0xbd, 0x8a, 0x19, 0x3b, 0xe1, 0x82,
+	0x5c, 0x85, 0x01, 0x9f, 0x8d, 0x2a, 0x58, 0x81, 0xd0, 0x33, 0xb0, 0xb9, 0x20, 0x82, 0x9e, 0xb2,
+	0xeb, 0xd8, 0xd9, 0x50, 0x8c, 0x4d, 0xc3, 0x38, 0x37, 0x0b, 0xa3, 0x0a, 0x5e, 0xa0, 0xd0, 0x31,
+	0x74, 0x95, 0x70, 0xce, 0x48, 0xc2, 0x67, 0xb1, 0x70, 0x7a, 0xe5, 0x6c, 0xe7, 0x34, 0x03, 0x18,
+	0x55, 0x70, 0x99, 0x81, 0xde, 0x42, 0x2f, 0xb7, 0x77, 0x36, 0x0f, 0x43, 0x19, 0xb9, 0x4d, 0x65,
+	0xe5, 0xf1, 0x92, 0x95, 0x6c, 0x3d, 0x0b, 0xe1, 0x12, 0x0f, 0xfd, 0x08, 0x1d, 0xa5, 0xcb, 0x30,
+	0x0e, 0x2a, 0x97, 0x11, 0xa6, 0x51, 0x2c, 0xe8, 0x79, 0x01, 0x31, 0xaa, 0xe0, 0x12, 0x03, 0xbd,
+	0xca, 0x0e, 0x64, 0xea, 0xcc, 0xd9, 0x52, 0x26, 0xfe, 0xbd, 0xd2, 0x44, 0x5e, 0x8a, 0x65, 0x8e,
+	0x8c, 0x4a, 0x48, 0x89, 0xa7, 0x2b, 0x56, 0xd6, 0xe5, 0x76, 0x39, 0x2a, 0xef, 0x16, 0x8b, 0x79,
+	0x75, 0x96, 0x19, 0xe8, 0x7b, 0xe8, 0x24, 0x94, 0xa6, 0xa7, 0x1e, 0x65, 0x22, 0x10, 0xb7, 0xce,
+	0xa3, 0xf2, 0xbd, 0x3b, 0x2b, 0xac, 0xc9, 0x33, 0x14, 0xb1, 0x32, 0x8f, 0xc9, 0x3c, 0x0c, 0x07,
+	0x44, 0x4c, 0x67, 0xce, 0x4e, 0x39, 0x8f, 0x67, 0x66, 0x41, 0xe6, 0x31, 0x47, 0xb9, 0x97, 0x50,
+	0xbd, 0x20, 0x3e, 0xea, 0x82, 0xfd, 0x61, 0x32, 0x3c, 0x79, 0x7d, 0x3a, 0x39, 0x19, 0xf6, 0x2a,
+	0xc8, 0x86, 0xfa, 0xc9, 0xf8, 0xec, 0xe2, 0x63, 0xcf, 0x42, 0x1d, 0x68, 0xbd, 0xc7, 0x6f, 0x2e,
+	0xdf, 0x4f, 0xde, 0x7d, 0xec, 0xad, 0x49, 0xdc, 0xab, 0xd1, 0xf1, 0x44, 0x8b, 0x55, 0xd4, 0x83,
+	0x8e, 0x12, 0x8f, 0x27, 0xc3, 0xcb, 0xf7, 0xf8, 0x4d, 0xaf, 0x86, 0x36, 0xa0, 0xad, 0x01, 0x58,
+	0x29, 0xea, 0x03, 0x1b, 0x9a, 0xd3, 0x98, 0x09, 0xca, 0x84, 0x1b, 0x81, 0x9d, 0x27, 0x14, 0xed,
+	0x42, 0x2b, 0xa2, 0x82, 0xc8, 0xca, 0xce, 0x3a, 0x64, 0x2e, 0xa3, 0x3e, 0xd8, 0x22, 0x88, 0x28,
+	0x17, 0x24, 0x4a, 0x54, 0x7b, 0x6b, 0x1f, 0xf5, 0x8a, 0x01, 0xb8, 0x08, 0x22, 0x8a, 0x17, 0x10,
+	0xd9, 0x22, 0x93, 0x9b, 0xe0, 0x74, 0xa8, 0x9a, 0x5e, 0x07, 0x6b, 0xc1, 0x7d, 0x0d, 0x9b, 0x4b,
+	0x55, 0x88, 0x9e, 0x41, 0x8b, 0x86, 0x34, 0xa2, 0x4c, 0x70, 0xc7, 0xda, 0xab, 0x16, 0xef, 0x46,
+	0xa9, 0x45, 0xe2, 0x1c, 0xe6, 0xee, 0xc0, 0xf6, 0xaa, 0x3a, 0x74, 0xc7, 0xd0, 0x2d, 0x5d, 0xa7,
+	0x85, 0x1b, 0x56, 0xc1, 0x0d, 0x84, 0xa0, 0x36, 0xa5, 0xa9, 0xc8, 0xda, 0xb4, 0xfa, 0x96, 0xba,
+	0x19, 0xe1, 0xb3, 0xcc, 0x5f, 0xf5, 0xed, 0x5e, 0x40, 0xa7, 0x98, 0xdc, 0x07, 0x58, 0x2b, 0x86,
+	0xb2, 0x5a, 0x0e, 0xa5, 0x1b, 0x42, 0xbb, 0xd0, 0x7e, 0xee, 0x7e, 0x4c, 0x3c, 0xd5, 0xcf, 0xb8,
+	0xb3, 0xb6, 0x57, 0xdd, 0xb7, 0xb1, 0x11, 0xd1, 0x53, 0x68, 0x46, 0xdc, 0xbf, 0xb8, 0x4d, 0x68,
+	0xf6, 0xa0, 0x6c, 0x15, 0xeb, 0x69, 0xac, 0x97, 0xb0, 0xc1, 0xb8, 0x0c, 0xda, 0x85, 0x5e, 0x7a,
+	0xc7, 0x6e, 0x45, 0x77, 0xd7, 0xbe, 0xc8, 0xfc, 0x03, 0xf7, 0xfb, 0x15, 0x60, 0xd1, 0x28, 0xef,
+	0xd8, 0xee, 0x09, 0xd4, 0xb2, 0xad, 0xee, 0xc9, 0x76, 0xed, 0xef, 0xec, 0x7e, 0x03, 0xb0, 0x78,
+	0x0a, 0xfe, 0xe9, 0xd0, 0x7e, 0xa7, 0x13, 0x69, 0xa6, 0x82, 0x27, 0xe5, 0xf9, 0xa2, 0x7d, 0xb4,
+	0x91, 0xb3, 0xb5, 0x3a, 0x1f, 0x38, 0xdc, 0x53, 0x68, 0x66, 0x3a, 0xb4, 0x03, 0x0d, 0x4e, 0x3f,
+	0x4d, 0xe6, 0x51, 0xe6, 0x64, 0x26, 0xe5, 0xf5, 0x28, 0xd3, 0x61, 0xeb, 0x7a, 0x94, 0xba, 0x42,
+	0x45, 0xa9, 0x6f, 0xf7, 0x77, 0x0b, 0x3a, 0xc5, 0x97, 0x1f, 0xf5, 0x01, 0xa2, 0xfc, 0x89, 0xce,
+	0x3c, 0x59, 0x2f, 0x3f, 0xde, 0xb8, 0x80, 0x78, 0xf0, 0xcd, 0xde, 0x85, 0x56, 0x60, 0x3a, 0xa1,
+	0x1e, 0x4f, 0x72, 0x59, 0x86, 0x36, 0xa4, 0xe4, 0x73, 0xc0, 0xf4, 0x70, 0xd2, 0xc2, 0x46, 0x74,
+	0x7f, 0x83, 0xcd, 0xa5, 0x4e, 0x7b, 0xc7, 0x7d, 0x7a, 0xa8, 0x43, 0xff, 0x85, 0x6e, 0xc0, 0x87,
+	0x74, 0x1a, 0x92, 0x94, 0x88, 0x20, 0x66, 0x2a, 0x3c, 0x2d, 0x5c, 0x56, 0xba, 0xc7, 0xd0, 0x32,
+	0x64, 0xf4, 0x1f, 0x80, 0x80, 0x4d, 0x2f, 0xd9, 0x5c, 0x06, 0x21, 0x8b, 0xbb, 0x1d, 0xb0, 0xe9,
+	0x44, 0x29, 0x0a, 0x29, 0x59, 0x2b, 0xa6, 0xc4, 0xfd, 0x19, 0x36, 0x97, 0x26, 0x26, 0xf4, 0x12,
+	0x36, 0x38, 0x0d, 0xaf, 0x65, 0x27, 0x4a, 0x23, 0xbd, 0xbf, 0xb5, 0x67, 0xdd, 0x5d, 0xd6, 0x5f,
+	0xa2, 0x65, 0x10, 0x6e, 0x58, 0xfc, 0x0b, 0x53, 0xc5, 0xd8, 0xc1, 0x5a, 0x70, 0x43, 0x40, 0xcb,
+	0x83, 0x96, 0x9c, 0x96, 0xd4, 0x54, 0x77, 0x7f, 0x9f, 0xd4, 0x18, 0x75, 0xcb, 0x28, 0xf1, 0xbe,
+	0x76, 0xcb, 0x28, 0xf1, 0xdc, 0x3f, 0x2c, 0x68, 0xe8, 0xed, 0x64, 0x7a, 0x29, 0xf3, 0x92, 0x38,
+	0x60, 0x42, 0x1d, 0xc4, 0xc6, 0xb9, 0x7c, 0x6f, 0x9b, 0x58, 0xd9, 0xf0, 0xd1, 0x00, 0x7a, 0x01,
+	0x13, 0x34, 0x65, 0x24, 0x3c, 0x31, 0x56, 0x6b, 0x2a, 0x3c, 0x3b, 0xf9, 0x40, 0xa1, 0x46, 0x75,
+	0xb3, 0x8a, 0x97, 0xf0, 0xa8, 0x07, 0xd5, 0x38, 0xd5, 0x05, 0xd5, 0xc1, 0xf2, 0xd3, 0x7d, 0x0b,
+	0xeb, 0x65, 0xd6, 0xbd, 0x5e, 0xdf, 0x3f, 0xdd, 0x37, 0xa1, 0xae, 0xe6, 0x34, 0xb7, 0x0f, 0x68,
+	0x79, 0x26, 0x91, 0x15, 0xad, 0xb3, 0xaf, 0xdf, 0xa6, 0x1a, 0x36, 0xa2, 0x3b, 0x80, 0xad, 0x15,
+	0x03, 0x08, 0x3a, 0x80, 0x56, 0x76, 0xcb, 0xcd, 0x6b, 0xb6, 0xd4, 0x06, 0x72, 0x80, 0xfb, 0x03,
+	0xd8, 0xf9, 0x10, 0x20, 0xdf, 0xc1, 0xec, 0x7f, 0xe6, 0x6b, 0xef, 0xa0, 0x81, 0x7d, 0xf3, 0x12,
+	0xda, 0x85, 0xce, 0xa4, 0x46, 0x06, 0xe6, 0xd1, 0xeb, 0x80, 0x51, 0xaf, 0x57, 0x91, 0xa3, 0xc0,
+	0x20, 0x8c, 0xa7, 0x37, 0x19, 0xaf, 0x67, 0xc9, 0x51, 0xc0, 0x3c, 0x66, 0x63, 0xee, 0xf7, 0xd6,
+	0x8e, 0x04, 0x34, 0xb4, 0x6d, 0x34, 0x80, 0x8e, 0xfe, 0x3a, 0x17, 0x29, 0x25, 0x11, 0x5a, 0xbd,
+	0xf7, 0xee, 0x6a, 0xb5, 0x5b, 0xd9, 0xb7, 0xbe, 0xb5, 0xd0, 0xff, 0xa1, 0x76, 0x16, 0x30, 0x1f,
+	0x95, 0x27, 0xe0, 0xdd, 0xb2, 0xe8, 0x56, 0x06, 0x4f, 0x7f, 0x3a, 0xf0, 0x03, 0x31, 0x9b, 0x5f,
+	0xf5, 0xa7, 0x71, 0x74, 0x38, 0xbb, 0x4d, 0x68, 0x1a, 0x52, 0xcf, 0xa7, 0xe9, 0xe1, 0x35, 0xb9,
+	0x4a, 0x83, 0xe9, 0xa1, 0xfa, 0x9f, 0xe3, 0x87, 0x9a, 0x76, 0xd5, 0x50, 0xe2, 0xf3, 0xbf, 0x06,
+	0x00, 0xda, 0x76, 0x3c, 0x63, 0xf6, 0x0d, 0x00, 0x00,
 }
@@ -83,6 +83,17 @@ type FabricMSPConfig struct {
 	// this peer is to use, and which is to be imported by the
 	// MSP defined before
 	SigningIdentity *SigningIdentityInfo `protobuf:"bytes,6,opt,name=signing_identity,json=signingIdentity" json:"signing_identity,omitempty"`
+	// RequirePairedAdminRotation requires a config update changing the
+	// root or admin certs of this MSP to be signed by an admin of the
+	// MSP both before and after the change
+	RequirePairedAdminRotation bool `protobuf:"varint,7,opt,name=require_paired_admin_rotation,json=requirePairedAdminRotation" json:"require_paired_admin_rotation,omitempty"`
+	// Organizational units classifying the members of this MSP as peers,
+	// clients and orderers, so that policies can require the signature of
+	// a given type of identity. A member is of a type if one of the
+	// organizational units of its certificate is listed for the type
+	PeerOrganizationalUnits    []string `protobuf:"bytes,8,rep,name=peer_organizational_units,json=peerOrganizationalUnits" json:"peer_organizational_units,omitempty"`
+	ClientOrganizationalUnits  []string `protobuf:"bytes,9,rep,name=client_organizational_units,json=clientOrganizationalUnits" json:"client_organizational_units,omitempty"`
+	OrdererOrganizationalUnits []string `protobuf:"bytes,10,rep,name=orderer_organizational_units,json=ordererOrganizationalUnits" json:"orderer_organizational_units,omitempty"`
 }
 
 func (m *FabricMSPConfig) Reset()                    { *m = FabricMSPConfig{} }
@@ -151,29 +162,35 @@ func init() {
 func init() { proto.RegisterFile("msp/mspconfig.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 373 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x6c, 0x92, 0xcb, 0x8b, 0xd4, 0x40,
-	0x10, 0x87, 0xc9, 0xcc, 0xee, 0xc8, 0xd4, 0x66, 0x66, 0xb4, 0x17, 0x96, 0x5c, 0x84, 0x18, 0x11,
-	0x83, 0x60, 0x02, 0xee, 0xc1, 0xbb, 0x03, 0xc2, 0xa0, 0x0b, 0x92, 0xdc, 0xbc, 0x84, 0x3c, 0x2a,
-	0xd9, 0x62, 0xd2, 0x0f, 0xba, 0x7b, 0x17, 0xf2, 0xa7, 0x7b, 0x93, 0x74, 0x1a, 0x75, 0xc0, 0x5b,
-	0xf5, 0x57, 0xdf, 0x8f, 0x4a, 0xba, 0x0b, 0x6e, 0xb9, 0x51, 0x39, 0x37, 0xaa, 0x95, 0xa2, 0xa7,
-	0x21, 0x53, 0x5a, 0x5a, 0xc9, 0xd6, 0xdc, 0xa8, 0xe4, 0x33, 0x6c, 0x1f, 0xca, 0x1f, 0x47, 0xc7,
-	0x19, 0x83, 0x2b, 0x3b, 0x29, 0x8c, 0x82, 0x38, 0x48, 0xaf, 0x0b, 0x57, 0xb3, 0x3b, 0xd8, 0x2c,
-	0xa9, 0x68, 0x15, 0x07, 0x69, 0x58, 0xf8, 0x53, 0xf2, 0x2b, 0x80, 0xc3, 0xd7, 0xba, 0xd1, 0xd4,
-	0x5e, 0xe4, 0x45, 0xcd, 0x97, 0xfc, 0xb6, 0x70, 0x35, 0x7b, 0x0d, 0xa0, 0xa5, 0xb4, 0x55, 0x8b,
-	0xda, 0x9a, 0x68, 0x15, 0xaf, 0xd3, 0xb0, 0xd8, 0xce, 0xe4, 0x38, 0x03, 0xf6, 0x11, 0x18, 0x09,
-	0x8b, 0x9a, 0x63, 0x47, 0xb5, 0x45, 0xaf, 0xad, 0x9d, 0xf6, 0xea, 0xdf, 0xce, 0xa2, 0xdf, 0xc1,
-	0xa6, 0xee, 0x38, 0x09, 0x13, 0x5d, 0x39, 0xc5, 0x9f, 0xd8, 0x7b, 0x38, 0x68, 0x7c, 0x96, 0x6d,
-	0x6d, 0x49, 0x8a, 0x6a, 0x24, 0x63, 0xa3, 0x6b, 0x27, 0xec, 0xff, 0xe2, 0xef, 0x64, 0x2c, 0x3b,
-	0xc2, 0x4b, 0x43, 0x83, 0x20, 0x31, 0x54, 0xd4, 0xa1, 0xb0, 0x64, 0xa7, 0x68, 0x13, 0x07, 0xe9,
-	0xcd, 0xa7, 0x28, 0xe3, 0x46, 0x65, 0xe5, 0xd2, 0x3c, 0xf9, 0xde, 0x49, 0xf4, 0xb2, 0x38, 0x98,
-	0x4b, 0x98, 0x48, 0xb8, 0xfd, 0x8f, 0xc7, 0xde, 0xc2, 0x4e, 0x3d, 0x35, 0x23, 0xb5, 0xd5, 0x1c,
-	0x40, 0xed, 0xee, 0x21, 0x2c, 0xc2, 0x05, 0x96, 0x8e, 0xb1, 0x7b, 0xd8, 0x2b, 0x4d, 0xcf, 0xf3,
-	0xbf, 0x7a, 0x6b, 0xe5, 0xc6, 0x87, 0x6e, 0xfc, 0x37, 0x5c, 0x46, 0xee, 0xbc, 0xb3, 0x84, 0x92,
-	0x12, 0x5e, 0xf8, 0x0e, 0x7b, 0x07, 0xfb, 0x33, 0x4e, 0xfe, 0xe3, 0x7b, 0xf2, 0x53, 0xb6, 0xc5,
-	0xee, 0x8c, 0xd3, 0xe9, 0x0f, 0x64, 0x6f, 0x20, 0x9c, 0x35, 0x5e, 0x5b, 0xd4, 0x54, 0x8f, 0xfe,
-	0xf1, 0x6e, 0xce, 0x38, 0x3d, 0x78, 0xf4, 0xe5, 0xc3, 0xcf, 0x74, 0x20, 0xfb, 0xf8, 0xd4, 0x64,
-	0xad, 0xe4, 0xf9, 0xe3, 0xa4, 0x50, 0x8f, 0xd8, 0x0d, 0xa8, 0xf3, 0xde, 0xbd, 0x6b, 0xee, 0xd6,
-	0xc4, 0xcc, 0x7b, 0xd3, 0x6c, 0x5c, 0x7d, 0xff, 0x3b, 0x00, 0x00, 0xff, 0xff, 0x1f, 0xd3, 0x3a,
-	0x98, 0x49, 0x02, 0x00, 0x00,
+	// 480 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x93, 0xd1, 0x8b, 0xd3, 0x40,
+	0x10, 0xc6, 0x69, 0x73, 0xd7, 0xbb, 0xcc, 0xa5, 0xad, 0xee, 0xc1, 0x99, 0x3b, 0x3d, 0x88, 0x15,
+	0x31, 0x08, 0xb6, 0xe0, 0x3d, 0x08, 0x3e, 0x88, 0x67, 0x41, 0x28, 0x7a, 0x78, 0xa4, 0xf8, 0xe2,
+	0x4b, 0x48, 0x93, 0x69, 0x6e, 0x68, 0xb2, 0xbb, 0xee, 0x6e, 0x0f, 0xe2, 0x9f, 0xee, 0x93, 0x64,
+	0xb3, 0xa8, 0x85, 0xde, 0xdb, 0xe4, 0xfb, 0x7e, 0xdf, 0x4e, 0x98, 0x9d, 0x85, 0xd3, 0x5a, 0xcb,
+	0x59, 0xad, 0x65, 0x2e, 0xf8, 0x9a, 0xca, 0xa9, 0x54, 0xc2, 0x08, 0xe6, 0xd5, 0x5a, 0x4e, 0xde,
+	0x81, 0x7f, 0xb3, 0xbc, 0x9d, 0x5b, 0x9d, 0x31, 0x38, 0x30, 0x8d, 0xc4, 0xb0, 0x17, 0xf5, 0xe2,
+	0xc3, 0xc4, 0xd6, 0xec, 0x0c, 0x06, 0x5d, 0x2a, 0xec, 0x47, 0xbd, 0x38, 0x48, 0xdc, 0xd7, 0xe4,
+	0xb7, 0x07, 0xe3, 0xcf, 0xd9, 0x4a, 0x51, 0xbe, 0x93, 0xe7, 0x59, 0xdd, 0xe5, 0xfd, 0xc4, 0xd6,
+	0xec, 0x12, 0x40, 0x09, 0x61, 0xd2, 0x1c, 0x95, 0xd1, 0x61, 0x3f, 0xf2, 0xe2, 0x20, 0xf1, 0x5b,
+	0x65, 0xde, 0x0a, 0xec, 0x0d, 0x30, 0xe2, 0x06, 0x55, 0x8d, 0x05, 0x65, 0x06, 0x1d, 0xe6, 0x59,
+	0xec, 0xf1, 0xff, 0x4e, 0x87, 0x9f, 0xc1, 0x20, 0x2b, 0x6a, 0xe2, 0x3a, 0x3c, 0xb0, 0x88, 0xfb,
+	0x62, 0xaf, 0x60, 0xac, 0xf0, 0x5e, 0xe4, 0x99, 0x21, 0xc1, 0xd3, 0x8a, 0xb4, 0x09, 0x0f, 0x2d,
+	0x30, 0xfa, 0x27, 0x7f, 0x25, 0x6d, 0xd8, 0x1c, 0x1e, 0x69, 0x2a, 0x39, 0xf1, 0x32, 0xa5, 0x02,
+	0xb9, 0x21, 0xd3, 0x84, 0x83, 0xa8, 0x17, 0x9f, 0xbc, 0x0d, 0xa7, 0xb5, 0x96, 0xd3, 0x65, 0x67,
+	0x2e, 0x9c, 0xb7, 0xe0, 0x6b, 0x91, 0x8c, 0xf5, 0xae, 0xc8, 0xae, 0xe1, 0x52, 0xe1, 0xcf, 0x2d,
+	0x29, 0x4c, 0x65, 0x46, 0x0a, 0x8b, 0xd4, 0xfe, 0x46, 0xaa, 0x84, 0xb1, 0x9d, 0xc2, 0xa3, 0xa8,
+	0x17, 0x1f, 0x27, 0x17, 0x0e, 0xba, 0xb5, 0xcc, 0x75, 0x8b, 0x24, 0x8e, 0x60, 0xef, 0xe1, 0x5c,
+	0x22, 0xaa, 0x54, 0xa8, 0x32, 0xe3, 0xf4, 0xcb, 0x8a, 0x59, 0x95, 0x6e, 0x39, 0x19, 0x1d, 0x1e,
+	0x47, 0x5e, 0xec, 0x27, 0x4f, 0x5a, 0xe0, 0xdb, 0x8e, 0xff, 0xbd, 0xb5, 0xd9, 0x07, 0x78, 0x9a,
+	0x57, 0x84, 0xdc, 0xec, 0x4f, 0xfb, 0x36, 0x7d, 0xde, 0x21, 0xfb, 0xf2, 0x1f, 0xe1, 0x99, 0x50,
+	0x05, 0xaa, 0x87, 0xda, 0x83, 0x3d, 0xe0, 0xc2, 0x31, 0x7b, 0x4e, 0x98, 0x08, 0x38, 0xdd, 0x33,
+	0x28, 0xf6, 0x02, 0x86, 0x72, 0xbb, 0xaa, 0x28, 0x4f, 0xdb, 0x89, 0xa1, 0xb2, 0x8b, 0x10, 0x24,
+	0x41, 0x27, 0x2e, 0xad, 0xc6, 0xae, 0x60, 0x24, 0x15, 0xdd, 0xb7, 0x97, 0xed, 0xa8, 0xbe, 0x9d,
+	0x7f, 0x60, 0xe7, 0xff, 0x05, 0xbb, 0x99, 0x0f, 0x1d, 0xd3, 0x85, 0x26, 0x4b, 0x38, 0x72, 0x0e,
+	0x7b, 0x09, 0xa3, 0x0d, 0x36, 0xee, 0xf6, 0xd6, 0xe4, 0xba, 0xf8, 0xc9, 0x70, 0x83, 0xcd, 0xe2,
+	0xaf, 0xc8, 0x9e, 0x43, 0xd0, 0x62, 0x75, 0x66, 0x50, 0x51, 0x56, 0xb9, 0xed, 0x3d, 0xd9, 0x60,
+	0x73, 0xe3, 0xa4, 0x4f, 0xaf, 0x7f, 0xc4, 0x25, 0x99, 0xbb, 0xed, 0x6a, 0x9a, 0x8b, 0x7a, 0x76,
+	0xd7, 0x48, 0x54, 0x15, 0x16, 0x25, 0xaa, 0xd9, 0xda, 0x2e, 0xf6, 0xcc, 0xbe, 0x13, 0xdd, 0x3e,
+	0x9c, 0xd5, 0xc0, 0xd6, 0x57, 0x7f, 0x06, 0x00, 0x62, 0xca, 0x8c, 0xd3, 0x4a, 0x03, 0x00, 0x00,
 }
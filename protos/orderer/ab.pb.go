@@ -7,6 +7,7 @@ Package orderer is a generated protocol buffer package.
 
 It is generated from these files:
 	orderer/ab.proto
+	orderer/admin.proto
 	orderer/configuration.proto
 	orderer/kafka.proto
 
@@ -18,6 +19,16 @@ It has these top-level messages:
 	SeekPosition
 	SeekInfo
 	DeliverResponse
+	BroadcastBatch
+	BroadcastBatchResponse
+	CompressedBlock
+	ChunkManifest
+	EnvelopeChunk
+	ChannelInfo
+	ListChannelsRequest
+	ListChannelsResponse
+	ChannelRequest
+	JoinChannelRequest
 	ConsensusType
 	BatchSize
 	BatchTimeout
@@ -55,6 +66,31 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+// Compression is the algorithm used to compress the blocks of a Deliver stream
+type Compression int32
+
+const (
+	Compression_NONE   Compression = 0
+	Compression_GZIP   Compression = 1
+	Compression_SNAPPY Compression = 2
+)
+
+var Compression_name = map[int32]string{
+	0: "NONE",
+	1: "GZIP",
+	2: "SNAPPY",
+}
+var Compression_value = map[string]int32{
+	"NONE":   0,
+	"GZIP":   1,
+	"SNAPPY": 2,
+}
+
+func (x Compression) String() string {
+	return proto.EnumName(Compression_name, int32(x))
+}
+func (Compression) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
 type SeekInfo_SeekBehavior int32
 
 const (
@@ -77,7 +113,8 @@ func (x SeekInfo_SeekBehavior) String() string {
 func (SeekInfo_SeekBehavior) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{5, 0} }
 
 type BroadcastResponse struct {
-	Status common.Status `protobuf:"varint,1,opt,name=status,enum=common.Status" json:"status,omitempty"`
+	Status    common.Status     `protobuf:"varint,1,opt,name=status,enum=common.Status" json:"status,omitempty"`
+	ErrorInfo *common.ErrorInfo `protobuf:"bytes,2,opt,name=error_info,json=errorInfo" json:"error_info,omitempty"`
 }
 
 func (m *BroadcastResponse) Reset()                    { *m = BroadcastResponse{} }
@@ -85,6 +122,13 @@ func (m *BroadcastResponse) String() string            { return proto.CompactTex
 func (*BroadcastResponse) ProtoMessage()               {}
 func (*BroadcastResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
+func (m *BroadcastResponse) GetErrorInfo() *common.ErrorInfo {
+	if m != nil {
+		return m.ErrorInfo
+	}
+	return nil
+}
+
 type SeekNewest struct {
 }
 
@@ -271,9 +315,10 @@ func _SeekPosition_OneofSizer(msg proto.Message) (n int) {
 // as they are created, behavior should be set to BLOCK_UNTIL_READY and the stop should be set to
 // specified with a number of MAX_UINT64
 type SeekInfo struct {
-	Start    *SeekPosition         `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
-	Stop     *SeekPosition         `protobuf:"bytes,2,opt,name=stop" json:"stop,omitempty"`
-	Behavior SeekInfo_SeekBehavior `protobuf:"varint,3,opt,name=behavior,enum=orderer.SeekInfo_SeekBehavior" json:"behavior,omitempty"`
+	Start       *SeekPosition         `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
+	Stop        *SeekPosition         `protobuf:"bytes,2,opt,name=stop" json:"stop,omitempty"`
+	Behavior    SeekInfo_SeekBehavior `protobuf:"varint,3,opt,name=behavior,enum=orderer.SeekInfo_SeekBehavior" json:"behavior,omitempty"`
+	Compression Compression           `protobuf:"varint,4,opt,name=compression,enum=orderer.Compression" json:"compression,omitempty"`
 }
 
 func (m *SeekInfo) Reset()                    { *m = SeekInfo{} }
@@ -299,7 +344,10 @@ type DeliverResponse struct {
 	// Types that are valid to be assigned to Type:
 	//	*DeliverResponse_Status
 	//	*DeliverResponse_Block
+	//	*DeliverResponse_CompressedBlock
 	Type isDeliverResponse_Type `protobuf_oneof:"Type"`
+	// Integrity proof of the response, set by orderers configured to attach one
+	Integrity *common.IntegrityProof `protobuf:"bytes,3,opt,name=integrity" json:"integrity,omitempty"`
 }
 
 func (m *DeliverResponse) Reset()                    { *m = DeliverResponse{} }
@@ -317,9 +365,13 @@ type DeliverResponse_Status struct {
 type DeliverResponse_Block struct {
 	Block *common.Block `protobuf:"bytes,2,opt,name=block,oneof"`
 }
+type DeliverResponse_CompressedBlock struct {
+	CompressedBlock *CompressedBlock `protobuf:"bytes,4,opt,name=compressed_block,json=compressedBlock,oneof"`
+}
 
-func (*DeliverResponse_Status) isDeliverResponse_Type() {}
-func (*DeliverResponse_Block) isDeliverResponse_Type()  {}
+func (*DeliverResponse_Status) isDeliverResponse_Type()          {}
+func (*DeliverResponse_Block) isDeliverResponse_Type()           {}
+func (*DeliverResponse_CompressedBlock) isDeliverResponse_Type() {}
 
 func (m *DeliverResponse) GetType() isDeliverResponse_Type {
 	if m != nil {
@@ -342,11 +394,26 @@ func (m *DeliverResponse) GetBlock() *common.Block {
 	return nil
 }
 
+func (m *DeliverResponse) GetCompressedBlock() *CompressedBlock {
+	if x, ok := m.GetType().(*DeliverResponse_CompressedBlock); ok {
+		return x.CompressedBlock
+	}
+	return nil
+}
+
+func (m *DeliverResponse) GetIntegrity() *common.IntegrityProof {
+	if m != nil {
+		return m.Integrity
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*DeliverResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _DeliverResponse_OneofMarshaler, _DeliverResponse_OneofUnmarshaler, _DeliverResponse_OneofSizer, []interface{}{
 		(*DeliverResponse_Status)(nil),
 		(*DeliverResponse_Block)(nil),
+		(*DeliverResponse_CompressedBlock)(nil),
 	}
 }
 
@@ -362,6 +429,11 @@ func _DeliverResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Block); err != nil {
 			return err
 		}
+	case *DeliverResponse_CompressedBlock:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.CompressedBlock); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("DeliverResponse.Type has unexpected type %T", x)
@@ -387,6 +459,14 @@ func _DeliverResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *prot
 		err := b.DecodeMessage(msg)
 		m.Type = &DeliverResponse_Block{msg}
 		return true, err
+	case 4: // Type.compressed_block
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(CompressedBlock)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_CompressedBlock{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -404,6 +484,11 @@ func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(2<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *DeliverResponse_CompressedBlock:
+		s := proto.Size(x.CompressedBlock)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -411,6 +496,88 @@ func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+// BroadcastBatch is a set of envelopes submitted for ordering together
+type BroadcastBatch struct {
+	// The envelopes to order, each handled as if sent to Broadcast
+	Envelopes []*common.Envelope `protobuf:"bytes,1,rep,name=envelopes" json:"envelopes,omitempty"`
+}
+
+func (m *BroadcastBatch) Reset()                    { *m = BroadcastBatch{} }
+func (m *BroadcastBatch) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastBatch) ProtoMessage()               {}
+func (*BroadcastBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *BroadcastBatch) GetEnvelopes() []*common.Envelope {
+	if m != nil {
+		return m.Envelopes
+	}
+	return nil
+}
+
+// BroadcastBatchResponse carries the status of each envelope of a BroadcastBatch, in order
+type BroadcastBatchResponse struct {
+	// The status of each envelope of the batch, in the order of the batch
+	Statuses []common.Status `protobuf:"varint,1,rep,packed,name=statuses,enum=common.Status" json:"statuses,omitempty"`
+	// The code and category of the error of each envelope, in the order of the batch, empty for
+	// the envelopes accepted
+	ErrorInfos []*common.ErrorInfo `protobuf:"bytes,2,rep,name=error_infos,json=errorInfos" json:"error_infos,omitempty"`
+}
+
+func (m *BroadcastBatchResponse) Reset()                    { *m = BroadcastBatchResponse{} }
+func (m *BroadcastBatchResponse) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastBatchResponse) ProtoMessage()               {}
+func (*BroadcastBatchResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *BroadcastBatchResponse) GetErrorInfos() []*common.ErrorInfo {
+	if m != nil {
+		return m.ErrorInfos
+	}
+	return nil
+}
+
+// CompressedBlock is a marshaled common.Block compressed with the algorithm requested
+// in the SeekInfo, sent in place of the block when compression is requested
+type CompressedBlock struct {
+	Compression Compression `protobuf:"varint,1,opt,name=compression,enum=orderer.Compression" json:"compression,omitempty"`
+	Data        []byte      `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *CompressedBlock) Reset()                    { *m = CompressedBlock{} }
+func (m *CompressedBlock) String() string            { return proto.CompactTextString(m) }
+func (*CompressedBlock) ProtoMessage()               {}
+func (*CompressedBlock) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+// ChunkManifest describes an envelope sent in chunks to BroadcastChunked
+type ChunkManifest struct {
+	ChannelId string `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	Size      uint64 `protobuf:"varint,2,opt,name=size" json:"size,omitempty"`
+	Hash      []byte `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+}
+
+func (m *ChunkManifest) Reset()                    { *m = ChunkManifest{} }
+func (m *ChunkManifest) String() string            { return proto.CompactTextString(m) }
+func (*ChunkManifest) ProtoMessage()               {}
+func (*ChunkManifest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+// EnvelopeChunk is a chunk of the marshaled envelope described by the last manifest received,
+// the first chunk of every envelope carries its manifest
+type EnvelopeChunk struct {
+	Manifest *ChunkManifest `protobuf:"bytes,1,opt,name=manifest" json:"manifest,omitempty"`
+	Data     []byte         `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *EnvelopeChunk) Reset()                    { *m = EnvelopeChunk{} }
+func (m *EnvelopeChunk) String() string            { return proto.CompactTextString(m) }
+func (*EnvelopeChunk) ProtoMessage()               {}
+func (*EnvelopeChunk) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *EnvelopeChunk) GetManifest() *ChunkManifest {
+	if m != nil {
+		return m.Manifest
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*BroadcastResponse)(nil), "orderer.BroadcastResponse")
 	proto.RegisterType((*SeekNewest)(nil), "orderer.SeekNewest")
@@ -419,6 +586,12 @@ func init() {
 	proto.RegisterType((*SeekPosition)(nil), "orderer.SeekPosition")
 	proto.RegisterType((*SeekInfo)(nil), "orderer.SeekInfo")
 	proto.RegisterType((*DeliverResponse)(nil), "orderer.DeliverResponse")
+	proto.RegisterType((*BroadcastBatch)(nil), "orderer.BroadcastBatch")
+	proto.RegisterType((*BroadcastBatchResponse)(nil), "orderer.BroadcastBatchResponse")
+	proto.RegisterType((*CompressedBlock)(nil), "orderer.CompressedBlock")
+	proto.RegisterType((*ChunkManifest)(nil), "orderer.ChunkManifest")
+	proto.RegisterType((*EnvelopeChunk)(nil), "orderer.EnvelopeChunk")
+	proto.RegisterEnum("orderer.Compression", Compression_name, Compression_value)
 	proto.RegisterEnum("orderer.SeekInfo_SeekBehavior", SeekInfo_SeekBehavior_name, SeekInfo_SeekBehavior_value)
 }
 
@@ -437,6 +610,10 @@ type AtomicBroadcastClient interface {
 	Broadcast(ctx context.Context, opts ...grpc.CallOption) (AtomicBroadcast_BroadcastClient, error)
 	// deliver first requires an Envelope of type DELIVER_SEEK_INFO with Payload data as a mashaled SeekInfo message, then a stream of block replies is received.
 	Deliver(ctx context.Context, opts ...grpc.CallOption) (AtomicBroadcast_DeliverClient, error)
+	// broadcastBatch receives a reply for each BroadcastBatch in order, carrying the status of each of its envelopes
+	BroadcastBatch(ctx context.Context, opts ...grpc.CallOption) (AtomicBroadcast_BroadcastBatchClient, error)
+	// broadcastChunked receives a reply for each envelope reassembled from its chunks, in order
+	BroadcastChunked(ctx context.Context, opts ...grpc.CallOption) (AtomicBroadcast_BroadcastChunkedClient, error)
 }
 
 type atomicBroadcastClient struct {
@@ -509,6 +686,68 @@ func (x *atomicBroadcastDeliverClient) Recv() (*DeliverResponse, error) {
 	return m, nil
 }
 
+func (c *atomicBroadcastClient) BroadcastBatch(ctx context.Context, opts ...grpc.CallOption) (AtomicBroadcast_BroadcastBatchClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_AtomicBroadcast_serviceDesc.Streams[2], c.cc, "/orderer.AtomicBroadcast/BroadcastBatch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &atomicBroadcastBroadcastBatchClient{stream}
+	return x, nil
+}
+
+type AtomicBroadcast_BroadcastBatchClient interface {
+	Send(*BroadcastBatch) error
+	Recv() (*BroadcastBatchResponse, error)
+	grpc.ClientStream
+}
+
+type atomicBroadcastBroadcastBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *atomicBroadcastBroadcastBatchClient) Send(m *BroadcastBatch) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *atomicBroadcastBroadcastBatchClient) Recv() (*BroadcastBatchResponse, error) {
+	m := new(BroadcastBatchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *atomicBroadcastClient) BroadcastChunked(ctx context.Context, opts ...grpc.CallOption) (AtomicBroadcast_BroadcastChunkedClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_AtomicBroadcast_serviceDesc.Streams[3], c.cc, "/orderer.AtomicBroadcast/BroadcastChunked", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &atomicBroadcastBroadcastChunkedClient{stream}
+	return x, nil
+}
+
+type AtomicBroadcast_BroadcastChunkedClient interface {
+	Send(*EnvelopeChunk) error
+	Recv() (*BroadcastResponse, error)
+	grpc.ClientStream
+}
+
+type atomicBroadcastBroadcastChunkedClient struct {
+	grpc.ClientStream
+}
+
+func (x *atomicBroadcastBroadcastChunkedClient) Send(m *EnvelopeChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *atomicBroadcastBroadcastChunkedClient) Recv() (*BroadcastResponse, error) {
+	m := new(BroadcastResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for AtomicBroadcast service
 
 type AtomicBroadcastServer interface {
@@ -516,6 +755,10 @@ type AtomicBroadcastServer interface {
 	Broadcast(AtomicBroadcast_BroadcastServer) error
 	// deliver first requires an Envelope of type DELIVER_SEEK_INFO with Payload data as a mashaled SeekInfo message, then a stream of block replies is received.
 	Deliver(AtomicBroadcast_DeliverServer) error
+	// broadcastBatch receives a reply for each BroadcastBatch in order, carrying the status of each of its envelopes
+	BroadcastBatch(AtomicBroadcast_BroadcastBatchServer) error
+	// broadcastChunked receives a reply for each envelope reassembled from its chunks, in order
+	BroadcastChunked(AtomicBroadcast_BroadcastChunkedServer) error
 }
 
 func RegisterAtomicBroadcastServer(s *grpc.Server, srv AtomicBroadcastServer) {
@@ -574,6 +817,58 @@ func (x *atomicBroadcastDeliverServer) Recv() (*common.Envelope, error) {
 	return m, nil
 }
 
+func _AtomicBroadcast_BroadcastBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AtomicBroadcastServer).BroadcastBatch(&atomicBroadcastBroadcastBatchServer{stream})
+}
+
+type AtomicBroadcast_BroadcastBatchServer interface {
+	Send(*BroadcastBatchResponse) error
+	Recv() (*BroadcastBatch, error)
+	grpc.ServerStream
+}
+
+type atomicBroadcastBroadcastBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *atomicBroadcastBroadcastBatchServer) Send(m *BroadcastBatchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *atomicBroadcastBroadcastBatchServer) Recv() (*BroadcastBatch, error) {
+	m := new(BroadcastBatch)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _AtomicBroadcast_BroadcastChunked_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AtomicBroadcastServer).BroadcastChunked(&atomicBroadcastBroadcastChunkedServer{stream})
+}
+
+type AtomicBroadcast_BroadcastChunkedServer interface {
+	Send(*BroadcastResponse) error
+	Recv() (*EnvelopeChunk, error)
+	grpc.ServerStream
+}
+
+type atomicBroadcastBroadcastChunkedServer struct {
+	grpc.ServerStream
+}
+
+func (x *atomicBroadcastBroadcastChunkedServer) Send(m *BroadcastResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *atomicBroadcastBroadcastChunkedServer) Recv() (*EnvelopeChunk, error) {
+	m := new(EnvelopeChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _AtomicBroadcast_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "orderer.AtomicBroadcast",
 	HandlerType: (*AtomicBroadcastServer)(nil),
@@ -591,6 +886,18 @@ var _AtomicBroadcast_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "BroadcastBatch",
+			Handler:       _AtomicBroadcast_BroadcastBatch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "BroadcastChunked",
+			Handler:       _AtomicBroadcast_BroadcastChunked_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: fileDescriptor0,
 }
@@ -598,35 +905,55 @@ var _AtomicBroadcast_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("orderer/ab.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 480 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x93, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xc7, 0x63, 0x48, 0xdd, 0x76, 0x68, 0xd3, 0x74, 0xab, 0x56, 0x51, 0x0e, 0x08, 0x59, 0x02,
-	0x82, 0x00, 0x1b, 0x05, 0x89, 0x03, 0x45, 0x42, 0x31, 0x6d, 0x95, 0x88, 0x28, 0x41, 0x4e, 0x38,
-	0xc0, 0x25, 0xf2, 0xc7, 0xa4, 0x31, 0x75, 0xbc, 0xd6, 0xee, 0x26, 0xa8, 0x4f, 0xc1, 0x8b, 0xf0,
-	0x48, 0x3c, 0x0c, 0xda, 0x0f, 0x3b, 0x0a, 0x14, 0x4e, 0xc9, 0xcc, 0xfc, 0xfe, 0xb3, 0xff, 0x19,
-	0x8d, 0xa1, 0x49, 0x59, 0x82, 0x0c, 0x99, 0x17, 0x46, 0x6e, 0xc1, 0xa8, 0xa0, 0x64, 0xd7, 0x64,
-	0xda, 0x27, 0x31, 0x5d, 0x2e, 0x69, 0xee, 0xe9, 0x1f, 0x5d, 0x75, 0xce, 0xe1, 0xd8, 0x67, 0x34,
-	0x4c, 0xe2, 0x90, 0x8b, 0x00, 0x79, 0x41, 0x73, 0x8e, 0xe4, 0x09, 0xd8, 0x5c, 0x84, 0x62, 0xc5,
-	0x5b, 0xd6, 0x23, 0xab, 0xd3, 0xe8, 0x36, 0x5c, 0xa3, 0x99, 0xa8, 0x6c, 0x60, 0xaa, 0xce, 0x01,
-	0xc0, 0x04, 0xf1, 0x66, 0x84, 0xdf, 0x91, 0x8b, 0x32, 0x1a, 0x67, 0x89, 0x8c, 0x9e, 0xc2, 0xa1,
-	0x8c, 0x26, 0x05, 0xc6, 0xe9, 0x3c, 0xc5, 0x84, 0x9c, 0x81, 0x9d, 0xaf, 0x96, 0x11, 0x32, 0xd5,
-	0xb4, 0x1e, 0x98, 0xc8, 0xf9, 0x69, 0xc1, 0x81, 0x24, 0x3f, 0x51, 0x9e, 0x8a, 0x94, 0xe6, 0xe4,
-	0x25, 0xd8, 0xb9, 0xea, 0xa8, 0xc0, 0x07, 0xdd, 0x13, 0xd7, 0x4c, 0xe0, 0x6e, 0x1e, 0xeb, 0xd7,
-	0x02, 0x03, 0x49, 0x9c, 0xaa, 0x27, 0x5b, 0xf7, 0xee, 0xc0, 0xb5, 0x1b, 0x89, 0x6b, 0x88, 0xbc,
-	0x81, 0x7d, 0x5e, 0x7a, 0x6a, 0xdd, 0x57, 0x8a, 0xb3, 0x2d, 0x45, 0xe5, 0xb8, 0x5f, 0x0b, 0x36,
-	0xa8, 0x6f, 0x43, 0x7d, 0x7a, 0x5b, 0xa0, 0xf3, 0xcb, 0x82, 0x3d, 0x89, 0x0d, 0xf2, 0x39, 0x25,
-	0xcf, 0x61, 0x87, 0x8b, 0x90, 0x95, 0x4e, 0x4f, 0xb7, 0x1a, 0x95, 0x03, 0x05, 0x9a, 0x21, 0xcf,
-	0xa0, 0xce, 0x05, 0x2d, 0x8c, 0xcd, 0x7f, 0xb0, 0x0a, 0x21, 0x6f, 0x61, 0x2f, 0xc2, 0x45, 0xb8,
-	0x4e, 0x29, 0x53, 0x1e, 0x1b, 0xdd, 0x87, 0x5b, 0xb8, 0x7c, 0x5c, 0xfd, 0xf1, 0x0d, 0x15, 0x54,
-	0xbc, 0xf3, 0x4e, 0xaf, 0xb3, 0xac, 0x90, 0x53, 0x38, 0xf6, 0x87, 0xe3, 0x0f, 0x1f, 0x67, 0x9f,
-	0x47, 0xd3, 0xc1, 0x70, 0x16, 0x5c, 0xf6, 0x2e, 0xbe, 0x34, 0x6b, 0x32, 0x7d, 0xd5, 0x1b, 0x0c,
-	0x67, 0x83, 0xab, 0xd9, 0x68, 0x3c, 0x35, 0x69, 0xcb, 0xf9, 0x06, 0x47, 0x17, 0x98, 0xa5, 0x6b,
-	0x64, 0xd5, 0x35, 0x74, 0xfe, 0x7f, 0x0d, 0x72, 0xb7, 0xba, 0x4e, 0x1e, 0xc3, 0x4e, 0x94, 0xd1,
-	0xf8, 0xc6, 0x8c, 0x78, 0x58, 0x82, 0xbe, 0x4c, 0xf6, 0x6b, 0x81, 0xae, 0x96, 0xab, 0xec, 0xfe,
-	0xb0, 0xe0, 0xa8, 0x27, 0xe8, 0x32, 0x8d, 0xab, 0x13, 0x24, 0xef, 0x61, 0x7f, 0x13, 0x34, 0xcb,
-	0x06, 0x97, 0xf9, 0x1a, 0x33, 0x5a, 0x60, 0xbb, 0x5d, 0xad, 0xe1, 0xaf, 0xab, 0x75, 0x6a, 0x1d,
-	0xeb, 0x95, 0x45, 0xce, 0x61, 0xd7, 0x0c, 0x70, 0x87, 0xbc, 0x55, 0xc9, 0xff, 0x18, 0x52, 0x8b,
-	0x7d, 0xf7, 0xeb, 0x8b, 0xeb, 0x54, 0x2c, 0x56, 0x91, 0x54, 0x7a, 0x8b, 0xdb, 0x02, 0x59, 0x86,
-	0xc9, 0x35, 0x32, 0x6f, 0x1e, 0x46, 0x2c, 0x8d, 0x3d, 0xf5, 0xd1, 0x70, 0xcf, 0x74, 0x89, 0x6c,
-	0x15, 0xbf, 0xfe, 0x1d, 0x00, 0x00, 0xff, 0xff, 0xe2, 0x79, 0xcc, 0xb7, 0x76, 0x03, 0x00, 0x00,
+	// 797 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xef, 0x6e, 0xe3, 0x44,
+	0x10, 0x8f, 0x53, 0x5f, 0x2e, 0x99, 0xa4, 0xad, 0xbb, 0xc7, 0x15, 0xab, 0x12, 0x50, 0x59, 0x02,
+	0xc2, 0xc1, 0x25, 0x27, 0x83, 0xee, 0x03, 0x20, 0x41, 0xd3, 0xeb, 0xd1, 0x88, 0x90, 0x44, 0x9b,
+	0x02, 0xba, 0x93, 0x50, 0xe4, 0xd8, 0x9b, 0x7a, 0xd5, 0xc4, 0x6b, 0xed, 0x3a, 0x85, 0xf2, 0x1c,
+	0x3c, 0x06, 0x3c, 0x17, 0xaf, 0x81, 0x76, 0xbd, 0x5e, 0x37, 0x7f, 0x28, 0x7c, 0xca, 0xce, 0xcc,
+	0x6f, 0x7e, 0xf3, 0xdf, 0x01, 0x87, 0xf1, 0x88, 0x70, 0xc2, 0xbb, 0xc1, 0xac, 0x93, 0x72, 0x96,
+	0x31, 0xf4, 0x58, 0x6b, 0x4e, 0x9e, 0x84, 0x6c, 0xb9, 0x64, 0x49, 0x37, 0xff, 0xc9, 0xad, 0xde,
+	0x12, 0x8e, 0x7a, 0x9c, 0x05, 0x51, 0x18, 0x88, 0x0c, 0x13, 0x91, 0xb2, 0x44, 0x10, 0xf4, 0x11,
+	0xd4, 0x44, 0x16, 0x64, 0x2b, 0xe1, 0x5a, 0xa7, 0x56, 0xfb, 0xc0, 0x3f, 0xe8, 0x68, 0x9f, 0x89,
+	0xd2, 0x62, 0x6d, 0x45, 0x2f, 0x00, 0x08, 0xe7, 0x8c, 0x4f, 0x69, 0x32, 0x67, 0x6e, 0xf5, 0xd4,
+	0x6a, 0x37, 0xfd, 0xa3, 0x02, 0x7b, 0x21, 0x2d, 0xfd, 0x64, 0xce, 0x70, 0x83, 0x14, 0x4f, 0xaf,
+	0x05, 0x30, 0x21, 0xe4, 0x66, 0x48, 0x7e, 0x25, 0x22, 0x2b, 0xa4, 0xd1, 0x22, 0x92, 0xd2, 0xc7,
+	0xb0, 0x2f, 0xa5, 0x49, 0x4a, 0x42, 0x3a, 0xa7, 0x24, 0x42, 0xc7, 0x50, 0x4b, 0x56, 0xcb, 0x19,
+	0xe1, 0x2a, 0x0d, 0x1b, 0x6b, 0xc9, 0xfb, 0xd3, 0x82, 0x96, 0x44, 0x8e, 0x99, 0xa0, 0x19, 0x65,
+	0x09, 0x7a, 0x0e, 0xb5, 0x44, 0x31, 0x2a, 0x60, 0xd3, 0x7f, 0xd2, 0xd1, 0x35, 0x77, 0xca, 0x60,
+	0x97, 0x15, 0xac, 0x41, 0x12, 0xce, 0x54, 0x48, 0xb7, 0xba, 0x03, 0x9e, 0x67, 0x23, 0xe1, 0x39,
+	0x08, 0xbd, 0x84, 0x86, 0x28, 0x72, 0x72, 0xf7, 0x94, 0xc7, 0xf1, 0x9a, 0x87, 0xc9, 0xf8, 0xb2,
+	0x82, 0x4b, 0x68, 0xaf, 0x06, 0xf6, 0xd5, 0x5d, 0x4a, 0xbc, 0x3f, 0xaa, 0x50, 0x97, 0x30, 0xd9,
+	0x00, 0xf4, 0x29, 0x3c, 0x12, 0x59, 0xc0, 0x8b, 0x4c, 0x9f, 0xae, 0x11, 0x15, 0x05, 0xe1, 0x1c,
+	0x83, 0x3e, 0x01, 0x5b, 0x64, 0x2c, 0x75, 0xab, 0x0f, 0x61, 0x15, 0x04, 0x7d, 0x09, 0xf5, 0x19,
+	0x89, 0x83, 0x5b, 0xca, 0xb8, 0xca, 0xf1, 0xc0, 0x7f, 0x7f, 0x0d, 0x2e, 0x83, 0xab, 0x47, 0x4f,
+	0xa3, 0xb0, 0xc1, 0xa3, 0x97, 0xd0, 0x0c, 0xd9, 0x32, 0xe5, 0x44, 0x08, 0xca, 0x12, 0xd7, 0x56,
+	0xee, 0xef, 0x18, 0xf7, 0xf3, 0xd2, 0x86, 0xef, 0x03, 0xbd, 0xaf, 0xa1, 0x75, 0x9f, 0x11, 0x3d,
+	0x85, 0xa3, 0xde, 0x60, 0x74, 0xfe, 0xfd, 0xf4, 0xc7, 0xe1, 0x55, 0x7f, 0x30, 0xc5, 0x17, 0x67,
+	0xaf, 0xde, 0x38, 0x15, 0xa9, 0x7e, 0x7d, 0xd6, 0x1f, 0x4c, 0xfb, 0xaf, 0xa7, 0xc3, 0xd1, 0x95,
+	0x56, 0x5b, 0xde, 0xdf, 0x16, 0x1c, 0xbe, 0x22, 0x0b, 0x7a, 0x4b, 0xb8, 0x59, 0xbc, 0xf6, 0xc3,
+	0x8b, 0x27, 0x87, 0xa2, 0x57, 0xef, 0x43, 0x78, 0x34, 0x5b, 0xb0, 0xf0, 0x46, 0xf7, 0x66, 0xbf,
+	0x00, 0xf6, 0xa4, 0xf2, 0xb2, 0x82, 0x73, 0x2b, 0xfa, 0x02, 0x1a, 0x34, 0xc9, 0xc8, 0x35, 0xa7,
+	0xd9, 0x9d, 0x99, 0x9d, 0x86, 0xf6, 0x0b, 0xc3, 0x98, 0x33, 0x36, 0xc7, 0x25, 0x10, 0x5d, 0x80,
+	0x53, 0xd4, 0x49, 0xa2, 0x69, 0x1e, 0xc7, 0x56, 0xce, 0xee, 0x56, 0x57, 0x48, 0x54, 0x84, 0x3c,
+	0x0c, 0xd7, 0x55, 0x66, 0x01, 0xbe, 0x85, 0x03, 0x73, 0x63, 0xbd, 0x20, 0x0b, 0x63, 0xd4, 0x81,
+	0x06, 0x49, 0x6e, 0xc9, 0x82, 0xa5, 0x44, 0x96, 0xba, 0xd7, 0x6e, 0xfa, 0x8e, 0xb9, 0x1b, 0x6d,
+	0xc0, 0x25, 0xc4, 0xfb, 0x0d, 0x8e, 0xd7, 0x19, 0x4c, 0xc7, 0x9e, 0x41, 0x3d, 0xef, 0x88, 0x26,
+	0xda, 0x3e, 0x56, 0x63, 0x47, 0x3e, 0x34, 0xcb, 0x73, 0x15, 0x6e, 0xf5, 0x74, 0x6f, 0xf7, 0xbd,
+	0x82, 0xb9, 0x57, 0xe1, 0xfd, 0x02, 0x87, 0x1b, 0x95, 0x6e, 0xae, 0x8b, 0xf5, 0x3f, 0xd7, 0x05,
+	0x21, 0xb0, 0xa3, 0x20, 0x0b, 0xd4, 0xc4, 0x5a, 0x58, 0xbd, 0xbd, 0x9f, 0x60, 0xff, 0x3c, 0x5e,
+	0x25, 0x37, 0x3f, 0x04, 0x09, 0x9d, 0xcb, 0x63, 0x7b, 0x0f, 0x20, 0x8c, 0x83, 0x24, 0x21, 0x8b,
+	0x29, 0x8d, 0x14, 0x77, 0x03, 0x37, 0xb4, 0xa6, 0x1f, 0x49, 0x0e, 0x41, 0x7f, 0x27, 0x8a, 0xc3,
+	0xc6, 0xea, 0x2d, 0x75, 0x71, 0x20, 0x62, 0x35, 0xde, 0x16, 0x56, 0x6f, 0xef, 0x67, 0xd8, 0x2f,
+	0xfa, 0xa8, 0xf8, 0x91, 0x0f, 0xf5, 0xa5, 0x8e, 0xe1, 0x5a, 0x1b, 0x37, 0xbc, 0x96, 0x01, 0x36,
+	0xb8, 0x5d, 0x09, 0x3f, 0x7b, 0x0e, 0xcd, 0x7b, 0x05, 0xa2, 0x3a, 0xd8, 0xc3, 0xd1, 0xf0, 0xc2,
+	0xa9, 0xc8, 0xd7, 0x77, 0x6f, 0xfb, 0x63, 0xc7, 0x42, 0x00, 0xb5, 0xc9, 0xf0, 0x6c, 0x3c, 0x7e,
+	0xe3, 0x54, 0xfd, 0xbf, 0xaa, 0x70, 0x78, 0x96, 0xb1, 0x25, 0x0d, 0xcd, 0xfc, 0xd0, 0x37, 0xd0,
+	0x28, 0x85, 0xad, 0xb1, 0x9f, 0x9c, 0x98, 0xbc, 0xb6, 0x3e, 0xcc, 0x5e, 0xa5, 0x6d, 0xbd, 0xb0,
+	0xd0, 0x57, 0xf0, 0x58, 0x1f, 0xce, 0x0e, 0xf7, 0x72, 0x43, 0x37, 0x8e, 0x4b, 0x3b, 0x0f, 0xb7,
+	0x96, 0xf1, 0xdd, 0xed, 0x80, 0xca, 0x70, 0xf2, 0xc1, 0xbf, 0x18, 0x0a, 0x46, 0xc5, 0x37, 0x00,
+	0xc7, 0x58, 0x55, 0x23, 0xe5, 0x87, 0xdb, 0x38, 0xae, 0x0d, 0xe1, 0xbf, 0x4b, 0xeb, 0x75, 0xde,
+	0x7e, 0x76, 0x4d, 0xb3, 0x78, 0x35, 0x93, 0x75, 0x75, 0xe3, 0xbb, 0x94, 0xf0, 0x05, 0x89, 0xae,
+	0x09, 0xef, 0xce, 0x83, 0x19, 0xa7, 0x61, 0x57, 0xfd, 0x6b, 0x89, 0xae, 0xe6, 0x99, 0xd5, 0x94,
+	0xfc, 0xf9, 0x3f, 0x03, 0x00, 0x92, 0x35, 0xe4, 0xbd, 0xf7, 0x06, 0x00, 0x00,
 }
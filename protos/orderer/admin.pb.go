@@ -0,0 +1,487 @@
+// Code generated by protoc-gen-go.
+// source: orderer/admin.proto
+// DO NOT EDIT!
+
+package orderer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// ChannelInfo describes a channel served by the orderer
+type ChannelInfo struct {
+	ChainId string `protobuf:"bytes,1,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
+	Height  uint64 `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
+	// consensus_type is the type of the consenter ordering the channel
+	ConsensusType string `protobuf:"bytes,3,opt,name=consensus_type,json=consensusType" json:"consensus_type,omitempty"`
+	SystemChannel bool   `protobuf:"varint,4,opt,name=system_channel,json=systemChannel" json:"system_channel,omitempty"`
+	// last_config is the number of the latest config block of the channel
+	LastConfig uint64 `protobuf:"varint,5,opt,name=last_config,json=lastConfig" json:"last_config,omitempty"`
+}
+
+func (m *ChannelInfo) Reset()                    { *m = ChannelInfo{} }
+func (m *ChannelInfo) String() string            { return proto.CompactTextString(m) }
+func (*ChannelInfo) ProtoMessage()               {}
+func (*ChannelInfo) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+
+// ListChannelsRequest asks for the channels served by the orderer
+type ListChannelsRequest struct {
+}
+
+func (m *ListChannelsRequest) Reset()                    { *m = ListChannelsRequest{} }
+func (m *ListChannelsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListChannelsRequest) ProtoMessage()               {}
+func (*ListChannelsRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+
+type ListChannelsResponse struct {
+	Channels []*ChannelInfo `protobuf:"bytes,1,rep,name=channels" json:"channels,omitempty"`
+}
+
+func (m *ListChannelsResponse) Reset()                    { *m = ListChannelsResponse{} }
+func (m *ListChannelsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListChannelsResponse) ProtoMessage()               {}
+func (*ListChannelsResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+
+func (m *ListChannelsResponse) GetChannels() []*ChannelInfo {
+	if m != nil {
+		return m.Channels
+	}
+	return nil
+}
+
+// ChannelRequest designates the channel an admin operation applies to
+type ChannelRequest struct {
+	ChainId string `protobuf:"bytes,1,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
+}
+
+func (m *ChannelRequest) Reset()                    { *m = ChannelRequest{} }
+func (m *ChannelRequest) String() string            { return proto.CompactTextString(m) }
+func (*ChannelRequest) ProtoMessage()               {}
+func (*ChannelRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+
+// JoinChannelRequest asks the orderer to serve the channel created by genesis_block
+type JoinChannelRequest struct {
+	GenesisBlock *common.Block `protobuf:"bytes,1,opt,name=genesis_block,json=genesisBlock" json:"genesis_block,omitempty"`
+}
+
+func (m *JoinChannelRequest) Reset()                    { *m = JoinChannelRequest{} }
+func (m *JoinChannelRequest) String() string            { return proto.CompactTextString(m) }
+func (*JoinChannelRequest) ProtoMessage()               {}
+func (*JoinChannelRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+
+func (m *JoinChannelRequest) GetGenesisBlock() *common.Block {
+	if m != nil {
+		return m.GenesisBlock
+	}
+	return nil
+}
+
+// BroadcastRejection records a broadcast envelope the orderer rejected
+type BroadcastRejection struct {
+	Timestamp *google_protobuf.Timestamp `protobuf:"bytes,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	// chain_id is empty if the envelope was rejected before its channel was known
+	ChainId string        `protobuf:"bytes,2,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
+	Status  common.Status `protobuf:"varint,3,opt,name=status,enum=common.Status" json:"status,omitempty"`
+	// reason is the code of the error rejecting the envelope
+	Reason  string `protobuf:"bytes,4,opt,name=reason" json:"reason,omitempty"`
+	Message string `protobuf:"bytes,5,opt,name=message" json:"message,omitempty"`
+	// claimed_msp_id and claimed_subject identify the creator the envelope
+	// claims, when it could be read. They are unverified, as the signature of
+	// a rejected envelope may not have been checked. address is the one of the
+	// client which sent it
+	ClaimedMspId   string `protobuf:"bytes,6,opt,name=claimed_msp_id,json=claimedMspId" json:"claimed_msp_id,omitempty"`
+	ClaimedSubject string `protobuf:"bytes,7,opt,name=claimed_subject,json=claimedSubject" json:"claimed_subject,omitempty"`
+	Address        string `protobuf:"bytes,8,opt,name=address" json:"address,omitempty"`
+	Size           uint64 `protobuf:"varint,9,opt,name=size" json:"size,omitempty"`
+}
+
+func (m *BroadcastRejection) Reset()                    { *m = BroadcastRejection{} }
+func (m *BroadcastRejection) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastRejection) ProtoMessage()               {}
+func (*BroadcastRejection) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+
+func (m *BroadcastRejection) GetTimestamp() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+// BroadcastRejectionCount counts the broadcast envelopes rejected for a reason
+type BroadcastRejectionCount struct {
+	Reason string `protobuf:"bytes,1,opt,name=reason" json:"reason,omitempty"`
+	Count  uint64 `protobuf:"varint,2,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *BroadcastRejectionCount) Reset()                    { *m = BroadcastRejectionCount{} }
+func (m *BroadcastRejectionCount) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastRejectionCount) ProtoMessage()               {}
+func (*BroadcastRejectionCount) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
+
+type BroadcastRejectionsResponse struct {
+	// rejections are the latest rejections retained by the orderer, oldest first
+	Rejections []*BroadcastRejection `protobuf:"bytes,1,rep,name=rejections" json:"rejections,omitempty"`
+	// counts are the rejections counted since the orderer started, by reason
+	Counts []*BroadcastRejectionCount `protobuf:"bytes,2,rep,name=counts" json:"counts,omitempty"`
+}
+
+func (m *BroadcastRejectionsResponse) Reset()                    { *m = BroadcastRejectionsResponse{} }
+func (m *BroadcastRejectionsResponse) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastRejectionsResponse) ProtoMessage()               {}
+func (*BroadcastRejectionsResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{7} }
+
+func (m *BroadcastRejectionsResponse) GetRejections() []*BroadcastRejection {
+	if m != nil {
+		return m.Rejections
+	}
+	return nil
+}
+
+func (m *BroadcastRejectionsResponse) GetCounts() []*BroadcastRejectionCount {
+	if m != nil {
+		return m.Counts
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ChannelInfo)(nil), "orderer.ChannelInfo")
+	proto.RegisterType((*ListChannelsRequest)(nil), "orderer.ListChannelsRequest")
+	proto.RegisterType((*ListChannelsResponse)(nil), "orderer.ListChannelsResponse")
+	proto.RegisterType((*ChannelRequest)(nil), "orderer.ChannelRequest")
+	proto.RegisterType((*JoinChannelRequest)(nil), "orderer.JoinChannelRequest")
+	proto.RegisterType((*BroadcastRejection)(nil), "orderer.BroadcastRejection")
+	proto.RegisterType((*BroadcastRejectionCount)(nil), "orderer.BroadcastRejectionCount")
+	proto.RegisterType((*BroadcastRejectionsResponse)(nil), "orderer.BroadcastRejectionsResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion3
+
+// Client API for Admin service
+
+type AdminClient interface {
+	ListChannels(ctx context.Context, in *ListChannelsRequest, opts ...grpc.CallOption) (*ListChannelsResponse, error)
+	ChannelStatus(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*ChannelInfo, error)
+	ConfigBlock(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*common.Block, error)
+	GenesisBlock(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*common.Block, error)
+	JoinChannel(ctx context.Context, in *JoinChannelRequest, opts ...grpc.CallOption) (*ChannelInfo, error)
+	RemoveChannel(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*ChannelInfo, error)
+	BroadcastRejections(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*BroadcastRejectionsResponse, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) ListChannels(ctx context.Context, in *ListChannelsRequest, opts ...grpc.CallOption) (*ListChannelsResponse, error) {
+	out := new(ListChannelsResponse)
+	err := grpc.Invoke(ctx, "/orderer.Admin/ListChannels", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ChannelStatus(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*ChannelInfo, error) {
+	out := new(ChannelInfo)
+	err := grpc.Invoke(ctx, "/orderer.Admin/ChannelStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ConfigBlock(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*common.Block, error) {
+	out := new(common.Block)
+	err := grpc.Invoke(ctx, "/orderer.Admin/ConfigBlock", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GenesisBlock(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*common.Block, error) {
+	out := new(common.Block)
+	err := grpc.Invoke(ctx, "/orderer.Admin/GenesisBlock", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) JoinChannel(ctx context.Context, in *JoinChannelRequest, opts ...grpc.CallOption) (*ChannelInfo, error) {
+	out := new(ChannelInfo)
+	err := grpc.Invoke(ctx, "/orderer.Admin/JoinChannel", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RemoveChannel(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*ChannelInfo, error) {
+	out := new(ChannelInfo)
+	err := grpc.Invoke(ctx, "/orderer.Admin/RemoveChannel", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) BroadcastRejections(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*BroadcastRejectionsResponse, error) {
+	out := new(BroadcastRejectionsResponse)
+	err := grpc.Invoke(ctx, "/orderer.Admin/BroadcastRejections", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Admin service
+
+type AdminServer interface {
+	ListChannels(context.Context, *ListChannelsRequest) (*ListChannelsResponse, error)
+	ChannelStatus(context.Context, *ChannelRequest) (*ChannelInfo, error)
+	ConfigBlock(context.Context, *ChannelRequest) (*common.Block, error)
+	GenesisBlock(context.Context, *ChannelRequest) (*common.Block, error)
+	JoinChannel(context.Context, *JoinChannelRequest) (*ChannelInfo, error)
+	RemoveChannel(context.Context, *ChannelRequest) (*ChannelInfo, error)
+	BroadcastRejections(context.Context, *ChannelRequest) (*BroadcastRejectionsResponse, error)
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_ListChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/ListChannels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListChannels(ctx, req.(*ListChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ChannelStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ChannelStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/ChannelStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ChannelStatus(ctx, req.(*ChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ConfigBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ConfigBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/ConfigBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ConfigBlock(ctx, req.(*ChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GenesisBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GenesisBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/GenesisBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GenesisBlock(ctx, req.(*ChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_JoinChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).JoinChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/JoinChannel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).JoinChannel(ctx, req.(*JoinChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RemoveChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RemoveChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/RemoveChannel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RemoveChannel(ctx, req.(*ChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_BroadcastRejections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).BroadcastRejections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/BroadcastRejections",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).BroadcastRejections(ctx, req.(*ChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "orderer.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListChannels",
+			Handler:    _Admin_ListChannels_Handler,
+		},
+		{
+			MethodName: "ChannelStatus",
+			Handler:    _Admin_ChannelStatus_Handler,
+		},
+		{
+			MethodName: "ConfigBlock",
+			Handler:    _Admin_ConfigBlock_Handler,
+		},
+		{
+			MethodName: "GenesisBlock",
+			Handler:    _Admin_GenesisBlock_Handler,
+		},
+		{
+			MethodName: "JoinChannel",
+			Handler:    _Admin_JoinChannel_Handler,
+		},
+		{
+			MethodName: "RemoveChannel",
+			Handler:    _Admin_RemoveChannel_Handler,
+		},
+		{
+			MethodName: "BroadcastRejections",
+			Handler:    _Admin_BroadcastRejections_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: fileDescriptor1,
+}
+
+func init() { proto.RegisterFile("orderer/admin.proto", fileDescriptor1) }
+
+var fileDescriptor1 = []byte{
+	// 675 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x6e, 0xd3, 0x4a,
+	0x14, 0xae, 0xd3, 0x36, 0x69, 0x4e, 0x7e, 0xae, 0x34, 0xe9, 0xbd, 0xf5, 0x4d, 0x41, 0x8d, 0xac,
+	0x02, 0x91, 0x40, 0x0e, 0x0a, 0x0b, 0x2a, 0xb1, 0x29, 0xe9, 0xa2, 0x2d, 0xa2, 0x9b, 0x69, 0xc5,
+	0x82, 0x4d, 0xe4, 0xd8, 0x13, 0x67, 0x20, 0x9e, 0x31, 0x3e, 0x63, 0xa4, 0xf0, 0x1c, 0xbc, 0x07,
+	0xaf, 0xc0, 0xeb, 0xf0, 0x16, 0xc8, 0xe3, 0x71, 0xea, 0x34, 0x4d, 0xa5, 0xae, 0x92, 0xf3, 0xcd,
+	0x77, 0x7e, 0xbf, 0x73, 0x0c, 0x1d, 0x99, 0x04, 0x2c, 0x61, 0xc9, 0xc0, 0x0b, 0x22, 0x2e, 0xdc,
+	0x38, 0x91, 0x4a, 0x92, 0x9a, 0x01, 0xbb, 0x1d, 0x5f, 0x46, 0x91, 0x14, 0x83, 0xfc, 0x27, 0x7f,
+	0xed, 0x1e, 0x85, 0x52, 0x86, 0x73, 0x36, 0xd0, 0xd6, 0x24, 0x9d, 0x0e, 0x14, 0x8f, 0x18, 0x2a,
+	0x2f, 0x8a, 0x73, 0x82, 0xf3, 0xcb, 0x82, 0xc6, 0xd9, 0xcc, 0x13, 0x82, 0xcd, 0x2f, 0xc5, 0x54,
+	0x92, 0xff, 0x61, 0xcf, 0x9f, 0x79, 0x5c, 0x8c, 0x79, 0x60, 0x5b, 0x3d, 0xab, 0x5f, 0xa7, 0x35,
+	0x6d, 0x5f, 0x06, 0xe4, 0x3f, 0xa8, 0xce, 0x18, 0x0f, 0x67, 0xca, 0xae, 0xf4, 0xac, 0xfe, 0x0e,
+	0x35, 0x16, 0x79, 0x06, 0x6d, 0x5f, 0x0a, 0x64, 0x02, 0x53, 0x1c, 0xab, 0x45, 0xcc, 0xec, 0x6d,
+	0xed, 0xd8, 0x5a, 0xa2, 0x37, 0x8b, 0x98, 0x65, 0x34, 0x5c, 0xa0, 0x62, 0xd1, 0xd8, 0xcf, 0xf3,
+	0xd9, 0x3b, 0x3d, 0xab, 0xbf, 0x47, 0x5b, 0x39, 0x6a, 0x8a, 0x20, 0x47, 0xd0, 0x98, 0x7b, 0xa8,
+	0xc6, 0xbe, 0x14, 0x53, 0x1e, 0xda, 0xbb, 0x3a, 0x15, 0x64, 0xd0, 0x99, 0x46, 0x9c, 0x7f, 0xa1,
+	0xf3, 0x91, 0xa3, 0x32, 0x7c, 0xa4, 0xec, 0x5b, 0xca, 0x50, 0x39, 0x17, 0xb0, 0xbf, 0x0a, 0x63,
+	0x9c, 0xa5, 0x27, 0xaf, 0x75, 0x43, 0x1a, 0xb3, 0xad, 0xde, 0x76, 0xbf, 0x31, 0xdc, 0x77, 0xcd,
+	0xc8, 0xdc, 0x52, 0xe3, 0x74, 0xc9, 0x72, 0x5e, 0x42, 0xdb, 0x3c, 0x98, 0xd8, 0x0f, 0x0c, 0xc5,
+	0xb9, 0x00, 0xf2, 0x41, 0x72, 0x71, 0xc7, 0x61, 0x08, 0xad, 0x90, 0x09, 0x86, 0x1c, 0xc7, 0x93,
+	0xb9, 0xf4, 0xbf, 0x6a, 0xaf, 0xc6, 0xb0, 0xe5, 0x1a, 0x71, 0x46, 0x19, 0x48, 0x9b, 0x86, 0xa3,
+	0x2d, 0xe7, 0x77, 0x05, 0xc8, 0x28, 0x91, 0x5e, 0xe0, 0x7b, 0xa8, 0x28, 0xfb, 0xc2, 0x7c, 0xc5,
+	0xa5, 0x20, 0x27, 0x50, 0x5f, 0x6a, 0x66, 0xc2, 0x74, 0xdd, 0x5c, 0x55, 0xb7, 0x50, 0xd5, 0xbd,
+	0x29, 0x18, 0xf4, 0x96, 0xbc, 0x52, 0x75, 0x65, 0x55, 0xca, 0xe7, 0x50, 0x45, 0xe5, 0xa9, 0x14,
+	0xb5, 0x54, 0xed, 0x61, 0xbb, 0x28, 0xec, 0x5a, 0xa3, 0xd4, 0xbc, 0x66, 0x92, 0x27, 0xcc, 0x43,
+	0x29, 0xb4, 0x56, 0x75, 0x6a, 0x2c, 0x62, 0x43, 0x2d, 0x62, 0x88, 0x5e, 0xc8, 0xb4, 0x40, 0x75,
+	0x5a, 0x98, 0xe4, 0x18, 0xda, 0xfe, 0xdc, 0xe3, 0x11, 0x0b, 0xc6, 0x11, 0xc6, 0x59, 0xea, 0xaa,
+	0x26, 0x34, 0x0d, 0x7a, 0x85, 0xf1, 0x65, 0x40, 0x5e, 0xc0, 0x3f, 0x05, 0x0b, 0xd3, 0x49, 0xd6,
+	0xaa, 0x5d, 0xd3, 0xb4, 0xc2, 0xf9, 0x3a, 0x47, 0xb3, 0x44, 0x5e, 0x10, 0x24, 0x0c, 0xd1, 0xde,
+	0xcb, 0x13, 0x19, 0x93, 0x10, 0xd8, 0x41, 0xfe, 0x83, 0xd9, 0x75, 0xbd, 0x20, 0xfa, 0xbf, 0x73,
+	0x0e, 0x07, 0xeb, 0x13, 0x3c, 0x93, 0xa9, 0x50, 0xa5, 0x4e, 0xac, 0x95, 0x4e, 0xf6, 0x61, 0xd7,
+	0xcf, 0x08, 0x66, 0xa7, 0x73, 0xc3, 0xf9, 0x69, 0xc1, 0xe1, 0x7a, 0xa4, 0xdb, 0xa5, 0x7a, 0x07,
+	0x90, 0x2c, 0x51, 0xb3, 0x56, 0x87, 0xcb, 0xb5, 0x5a, 0xf7, 0xa4, 0x25, 0x3a, 0x39, 0x81, 0xaa,
+	0xce, 0x82, 0x76, 0x45, 0x3b, 0xf6, 0x1e, 0x70, 0xd4, 0xc5, 0x53, 0xc3, 0x1f, 0xfe, 0xd9, 0x86,
+	0xdd, 0xf7, 0xd9, 0xed, 0x93, 0x2b, 0x68, 0x96, 0xb7, 0x9d, 0x3c, 0x59, 0xc6, 0xb8, 0xe7, 0x36,
+	0xba, 0x4f, 0x37, 0xbc, 0xe6, 0xdd, 0x38, 0x5b, 0xe4, 0x14, 0x5a, 0x06, 0xcd, 0x17, 0x80, 0x1c,
+	0xdc, 0xbd, 0x91, 0x22, 0xd4, 0xbd, 0xc7, 0xe3, 0x6c, 0x91, 0xb7, 0xd0, 0xc8, 0xef, 0x53, 0x2f,
+	0xf3, 0x66, 0xff, 0xd5, 0x13, 0x70, 0xb6, 0xc8, 0x08, 0x1a, 0xa5, 0x03, 0x22, 0xb7, 0x53, 0x5c,
+	0x3f, 0xab, 0x8d, 0xc9, 0x4f, 0xa1, 0x45, 0x59, 0x24, 0xbf, 0xb3, 0x22, 0xca, 0xa3, 0xcb, 0x3f,
+	0x81, 0xe6, 0x79, 0xe9, 0x18, 0x1f, 0x51, 0xff, 0x27, 0xe8, 0xdc, 0xb3, 0x29, 0x9b, 0x03, 0x1c,
+	0x3f, 0xa0, 0x76, 0x49, 0x92, 0x91, 0xfb, 0xf9, 0x55, 0xc8, 0xd5, 0x2c, 0x9d, 0x64, 0x09, 0x07,
+	0xb3, 0x45, 0xcc, 0x92, 0x39, 0x0b, 0x42, 0x96, 0x0c, 0xa6, 0xde, 0x24, 0xe1, 0x7e, 0xfe, 0x49,
+	0xc7, 0x81, 0x89, 0x36, 0xa9, 0x6a, 0xfb, 0xcd, 0xdf, 0x01, 0x00, 0x0f, 0x87, 0xb2, 0x0a, 0x25,
+	0x06, 0x00, 0x00,
+}
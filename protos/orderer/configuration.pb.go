@@ -20,7 +20,7 @@ type ConsensusType struct {
 func (m *ConsensusType) Reset()                    { *m = ConsensusType{} }
 func (m *ConsensusType) String() string            { return proto.CompactTextString(m) }
 func (*ConsensusType) ProtoMessage()               {}
-func (*ConsensusType) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+func (*ConsensusType) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
 
 type BatchSize struct {
 	// Simply specified as number of messages for now, in the future
@@ -37,7 +37,7 @@ type BatchSize struct {
 func (m *BatchSize) Reset()                    { *m = BatchSize{} }
 func (m *BatchSize) String() string            { return proto.CompactTextString(m) }
 func (*BatchSize) ProtoMessage()               {}
-func (*BatchSize) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+func (*BatchSize) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
 
 type BatchTimeout struct {
 	// Any duration string parseable by ParseDuration():
@@ -48,7 +48,7 @@ type BatchTimeout struct {
 func (m *BatchTimeout) Reset()                    { *m = BatchTimeout{} }
 func (m *BatchTimeout) String() string            { return proto.CompactTextString(m) }
 func (*BatchTimeout) ProtoMessage()               {}
-func (*BatchTimeout) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+func (*BatchTimeout) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
 
 // When submitting a new chain configuration transaction to create a new chain,
 // the first configuration item must be of type Orderer with Key CreationPolicy
@@ -66,7 +66,7 @@ type CreationPolicy struct {
 func (m *CreationPolicy) Reset()                    { *m = CreationPolicy{} }
 func (m *CreationPolicy) String() string            { return proto.CompactTextString(m) }
 func (*CreationPolicy) ProtoMessage()               {}
-func (*CreationPolicy) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+func (*CreationPolicy) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
 
 // IngressPolicyNames is the set of policy names which incoming Broadcast signatures are filtered against
 type IngressPolicyNames struct {
@@ -78,7 +78,7 @@ type IngressPolicyNames struct {
 func (m *IngressPolicyNames) Reset()                    { *m = IngressPolicyNames{} }
 func (m *IngressPolicyNames) String() string            { return proto.CompactTextString(m) }
 func (*IngressPolicyNames) ProtoMessage()               {}
-func (*IngressPolicyNames) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+func (*IngressPolicyNames) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{4} }
 
 // EgressPolicyNames is the set of policy names which incoming Deliver signatures are filtered against
 type EgressPolicyNames struct {
@@ -90,7 +90,7 @@ type EgressPolicyNames struct {
 func (m *EgressPolicyNames) Reset()                    { *m = EgressPolicyNames{} }
 func (m *EgressPolicyNames) String() string            { return proto.CompactTextString(m) }
 func (*EgressPolicyNames) ProtoMessage()               {}
-func (*EgressPolicyNames) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+func (*EgressPolicyNames) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{5} }
 
 // ChainCreationPolicyNames is the set of policies which may be invoked for chain creation
 type ChainCreationPolicyNames struct {
@@ -102,7 +102,7 @@ type ChainCreationPolicyNames struct {
 func (m *ChainCreationPolicyNames) Reset()                    { *m = ChainCreationPolicyNames{} }
 func (m *ChainCreationPolicyNames) String() string            { return proto.CompactTextString(m) }
 func (*ChainCreationPolicyNames) ProtoMessage()               {}
-func (*ChainCreationPolicyNames) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
+func (*ChainCreationPolicyNames) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{6} }
 
 // Carries a list of bootstrap brokers, i.e. this is not the exclusive set of
 // brokers an ordering service
@@ -115,7 +115,7 @@ type KafkaBrokers struct {
 func (m *KafkaBrokers) Reset()                    { *m = KafkaBrokers{} }
 func (m *KafkaBrokers) String() string            { return proto.CompactTextString(m) }
 func (*KafkaBrokers) ProtoMessage()               {}
-func (*KafkaBrokers) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{7} }
+func (*KafkaBrokers) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{7} }
 
 func init() {
 	proto.RegisterType((*ConsensusType)(nil), "orderer.ConsensusType")
@@ -128,9 +128,9 @@ func init() {
 	proto.RegisterType((*KafkaBrokers)(nil), "orderer.KafkaBrokers")
 }
 
-func init() { proto.RegisterFile("orderer/configuration.proto", fileDescriptor1) }
+func init() { proto.RegisterFile("orderer/configuration.proto", fileDescriptor2) }
 
-var fileDescriptor1 = []byte{
+var fileDescriptor2 = []byte{
 	// 318 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x8c, 0x91, 0xc1, 0x4a, 0xc3, 0x40,
 	0x10, 0x86, 0x89, 0xd5, 0x96, 0x0e, 0xad, 0xda, 0x45, 0x24, 0xe0, 0xa5, 0xc4, 0x4b, 0x2c, 0xa5,
@@ -11,15 +11,19 @@ It is generated from these files:
 	peer/chaincodeevent.proto
 	peer/configuration.proto
 	peer/events.proto
+	peer/gateway.proto
 	peer/peer.proto
 	peer/proposal.proto
 	peer/proposal_response.proto
+	peer/statesync.proto
 	peer/transaction.proto
 
 It has these top-level messages:
 	ServerStatus
 	LogLevelRequest
 	LogLevelResponse
+	CollectImagesRequest
+	CollectImagesResponse
 	ChaincodeID
 	ChaincodeInput
 	ChaincodeSpec
@@ -35,9 +39,16 @@ It has these top-level messages:
 	QueryStateClose
 	QueryStateKeyValue
 	QueryStateResponse
+	GetStateAtHeight
+	DetachedPayload
+	ChaincodeResume
+	ChaincodeInitArgsSchema
+	ChaincodeArgSchema
 	ChaincodeEvent
 	AnchorPeers
 	AnchorPeer
+	ShimCapabilities
+	ChaincodeNamespacePrefix
 	ChaincodeReg
 	Interest
 	Register
@@ -45,8 +56,12 @@ It has these top-level messages:
 	Unregister
 	SignedEvent
 	Event
+	SubmitRequest
+	SubmitResponse
 	PeerID
 	PeerEndpoint
+	EndorsementLayout
+	EndorsingPeer
 	SignedProposal
 	Proposal
 	ChaincodeHeaderExtension
@@ -56,12 +71,18 @@ It has these top-level messages:
 	Response
 	ProposalResponsePayload
 	Endorsement
+	StateSyncRequest
+	StateWrite
+	BlockStateUpdates
 	SignedTransaction
 	ProcessedTransaction
 	Transaction
 	TransactionAction
 	ChaincodeActionPayload
 	ChaincodeEndorsedAction
+	RedactedTransaction
+	MVCCConflict
+	MVCCConflicts
 */
 package peer
 
@@ -148,10 +169,32 @@ func (m *LogLevelResponse) String() string            { return proto.CompactText
 func (*LogLevelResponse) ProtoMessage()               {}
 func (*LogLevelResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
+type CollectImagesRequest struct {
+	DryRun bool `protobuf:"varint,1,opt,name=dry_run,json=dryRun" json:"dry_run,omitempty"`
+}
+
+func (m *CollectImagesRequest) Reset()                    { *m = CollectImagesRequest{} }
+func (m *CollectImagesRequest) String() string            { return proto.CompactTextString(m) }
+func (*CollectImagesRequest) ProtoMessage()               {}
+func (*CollectImagesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+type CollectImagesResponse struct {
+	Images     []string `protobuf:"bytes,1,rep,name=images" json:"images,omitempty"`
+	Containers []string `protobuf:"bytes,2,rep,name=containers" json:"containers,omitempty"`
+	Retained   []string `protobuf:"bytes,3,rep,name=retained" json:"retained,omitempty"`
+}
+
+func (m *CollectImagesResponse) Reset()                    { *m = CollectImagesResponse{} }
+func (m *CollectImagesResponse) String() string            { return proto.CompactTextString(m) }
+func (*CollectImagesResponse) ProtoMessage()               {}
+func (*CollectImagesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
 func init() {
 	proto.RegisterType((*ServerStatus)(nil), "protos.ServerStatus")
 	proto.RegisterType((*LogLevelRequest)(nil), "protos.LogLevelRequest")
 	proto.RegisterType((*LogLevelResponse)(nil), "protos.LogLevelResponse")
+	proto.RegisterType((*CollectImagesRequest)(nil), "protos.CollectImagesRequest")
+	proto.RegisterType((*CollectImagesResponse)(nil), "protos.CollectImagesResponse")
 	proto.RegisterEnum("protos.ServerStatus_StatusCode", ServerStatus_StatusCode_name, ServerStatus_StatusCode_value)
 }
 
@@ -172,6 +215,7 @@ type AdminClient interface {
 	StopServer(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
 	GetModuleLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error)
 	SetModuleLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error)
+	CollectChaincodeImages(ctx context.Context, in *CollectImagesRequest, opts ...grpc.CallOption) (*CollectImagesResponse, error)
 }
 
 type adminClient struct {
@@ -227,6 +271,15 @@ func (c *adminClient) SetModuleLogLevel(ctx context.Context, in *LogLevelRequest
 	return out, nil
 }
 
+func (c *adminClient) CollectChaincodeImages(ctx context.Context, in *CollectImagesRequest, opts ...grpc.CallOption) (*CollectImagesResponse, error) {
+	out := new(CollectImagesResponse)
+	err := grpc.Invoke(ctx, "/protos.Admin/CollectChaincodeImages", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Admin service
 
 type AdminServer interface {
@@ -236,6 +289,7 @@ type AdminServer interface {
 	StopServer(context.Context, *google_protobuf.Empty) (*ServerStatus, error)
 	GetModuleLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error)
 	SetModuleLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error)
+	CollectChaincodeImages(context.Context, *CollectImagesRequest) (*CollectImagesResponse, error)
 }
 
 func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
@@ -332,6 +386,24 @@ func _Admin_SetModuleLogLevel_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Admin_CollectChaincodeImages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CollectChaincodeImages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/CollectChaincodeImages",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CollectChaincodeImages(ctx, req.(*CollectImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Admin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Admin",
 	HandlerType: (*AdminServer)(nil),
@@ -356,6 +428,10 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SetModuleLogLevel",
 			Handler:    _Admin_SetModuleLogLevel_Handler,
 		},
+		{
+			MethodName: "CollectChaincodeImages",
+			Handler:    _Admin_CollectChaincodeImages_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
@@ -364,30 +440,36 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("peer/admin.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 388 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x92, 0xdf, 0x8e, 0xd2, 0x40,
-	0x14, 0xc6, 0x29, 0x0a, 0xda, 0x83, 0x7f, 0xc6, 0x89, 0x51, 0x02, 0x31, 0x9a, 0x5e, 0x69, 0x4c,
-	0xda, 0x04, 0x2f, 0xbc, 0x50, 0x2f, 0xd0, 0x56, 0x34, 0x42, 0x21, 0x2d, 0xc4, 0xe8, 0x0d, 0x69,
-	0xe9, 0xa1, 0x90, 0x4c, 0x99, 0xee, 0xcc, 0x94, 0x84, 0xd7, 0xd9, 0x77, 0xda, 0xf7, 0xd9, 0xb4,
-	0x03, 0x61, 0xb3, 0xbb, 0x37, 0xfb, 0xe7, 0xea, 0xf4, 0x9c, 0xf3, 0x9d, 0x2f, 0x9d, 0x5f, 0x3e,
-	0x20, 0x39, 0xa2, 0x70, 0xa2, 0x24, 0x5b, 0x6f, 0xec, 0x5c, 0x70, 0xc5, 0x69, 0xb3, 0x2a, 0xb2,
-	0xd3, 0x4d, 0x39, 0x4f, 0x19, 0x3a, 0x55, 0x1b, 0x17, 0x4b, 0x07, 0xb3, 0x5c, 0xed, 0xb4, 0xc8,
-	0x3a, 0x35, 0xe0, 0x49, 0x88, 0x62, 0x8b, 0x22, 0x54, 0x91, 0x2a, 0x24, 0xfd, 0x0c, 0x4d, 0x59,
-	0x7d, 0xb5, 0x8d, 0x77, 0xc6, 0xfb, 0x67, 0xbd, 0xb7, 0x5a, 0x28, 0xed, 0x8b, 0x2a, 0x5b, 0x97,
-	0x1f, 0x3c, 0xc1, 0x60, 0x2f, 0xb7, 0xfe, 0x01, 0x1c, 0xa7, 0xf4, 0x29, 0x98, 0x33, 0xdf, 0xf5,
-	0x7e, 0xfe, 0xf6, 0x3d, 0x97, 0xd4, 0x68, 0x0b, 0x1e, 0x85, 0xd3, 0x7e, 0x30, 0xf5, 0x5c, 0x62,
-	0xe8, 0x66, 0x3c, 0x99, 0x78, 0x2e, 0xa9, 0x53, 0x80, 0xe6, 0xa4, 0x3f, 0x0b, 0x3d, 0x97, 0x3c,
-	0xa0, 0x26, 0x34, 0xbc, 0x20, 0x18, 0x07, 0xe4, 0x61, 0xa9, 0x99, 0xf9, 0x7f, 0xfc, 0xf1, 0x5f,
-	0x9f, 0x34, 0xac, 0x11, 0x3c, 0x1f, 0xf2, 0x74, 0x88, 0x5b, 0x64, 0x01, 0x9e, 0x14, 0x28, 0x15,
-	0x7d, 0x03, 0xc0, 0x78, 0x3a, 0xcf, 0x78, 0x52, 0x30, 0xac, 0x7e, 0xd5, 0x0c, 0x4c, 0xc6, 0xd3,
-	0x51, 0x35, 0xa0, 0x5d, 0x28, 0x9b, 0x39, 0x2b, 0x4f, 0xda, 0xf5, 0x6a, 0xfb, 0x98, 0xed, 0x2d,
-	0x2c, 0x1f, 0xc8, 0xd1, 0x4e, 0xe6, 0x7c, 0x23, 0xf1, 0x2e, 0x7e, 0xbd, 0xb3, 0x3a, 0x34, 0xfa,
-	0x25, 0x78, 0xfa, 0x05, 0xcc, 0x01, 0xaa, 0x3d, 0xc9, 0x57, 0xb6, 0x06, 0x6f, 0x1f, 0xc0, 0xdb,
-	0x5e, 0x09, 0xbe, 0xf3, 0xf2, 0x3a, 0xa2, 0x56, 0x8d, 0x7e, 0x83, 0x56, 0xa8, 0x22, 0xa1, 0xf4,
-	0xf8, 0xc6, 0xe7, 0x5f, 0x4b, 0xfe, 0x3c, 0xbf, 0xe5, 0xf5, 0x2f, 0x78, 0x31, 0x40, 0xa5, 0x5f,
-	0x7b, 0x80, 0x43, 0x5f, 0x1f, 0xc4, 0x97, 0xe8, 0x77, 0xda, 0x57, 0x17, 0x9a, 0xa3, 0x76, 0x0a,
-	0xef, 0xc5, 0xe9, 0xfb, 0xc7, 0xff, 0x1f, 0xd2, 0xb5, 0x5a, 0x15, 0xb1, 0xbd, 0xe0, 0x99, 0xb3,
-	0xda, 0xe5, 0x28, 0x18, 0x26, 0x29, 0x0a, 0x67, 0x19, 0xc5, 0x62, 0xbd, 0xd0, 0x89, 0x96, 0x4e,
-	0x99, 0xfc, 0x58, 0xa7, 0xfd, 0xd3, 0x79, 0x00, 0x00, 0x00, 0xff, 0xff, 0xaa, 0x24, 0x41, 0xca,
-	0x08, 0x03, 0x00, 0x00,
+	// 489 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x53, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0x6d, 0x12, 0x92, 0xc6, 0x53, 0x3e, 0xcc, 0xaa, 0xa4, 0x56, 0x4a, 0xa1, 0xf2, 0xa9, 0x08,
+	0xc9, 0x96, 0xca, 0x81, 0x03, 0x70, 0x08, 0x89, 0x29, 0x15, 0xad, 0x13, 0xd9, 0x8d, 0x2a, 0xb8,
+	0x44, 0x4e, 0x3c, 0x75, 0x2c, 0x36, 0x5e, 0xb3, 0xbb, 0xae, 0x94, 0xbf, 0xc3, 0x0f, 0xe0, 0x37,
+	0x22, 0xef, 0xda, 0xb4, 0x2a, 0xe5, 0x00, 0xf4, 0xb4, 0x7e, 0x33, 0x6f, 0x9e, 0x46, 0x7e, 0x6f,
+	0xc0, 0xcc, 0x11, 0xb9, 0x1b, 0xc5, 0xab, 0x34, 0x73, 0x72, 0xce, 0x24, 0x23, 0x1d, 0xf5, 0x88,
+	0xfe, 0x6e, 0xc2, 0x58, 0x42, 0xd1, 0x55, 0x70, 0x5e, 0x5c, 0xb8, 0xb8, 0xca, 0xe5, 0x5a, 0x93,
+	0xec, 0xef, 0x0d, 0xb8, 0x1f, 0x22, 0xbf, 0x44, 0x1e, 0xca, 0x48, 0x16, 0x82, 0xbc, 0x86, 0x8e,
+	0x50, 0x5f, 0x56, 0x63, 0xbf, 0x71, 0xf0, 0xf0, 0xf0, 0xb9, 0x26, 0x0a, 0xe7, 0x3a, 0xcb, 0xd1,
+	0xcf, 0x90, 0xc5, 0x18, 0x54, 0x74, 0xfb, 0x33, 0xc0, 0x55, 0x95, 0x3c, 0x00, 0x63, 0xea, 0x8f,
+	0xbc, 0x0f, 0xc7, 0xbe, 0x37, 0x32, 0x37, 0xc8, 0x16, 0x6c, 0x86, 0x67, 0x83, 0xe0, 0xcc, 0x1b,
+	0x99, 0x0d, 0x0d, 0xc6, 0x93, 0x89, 0x37, 0x32, 0x9b, 0x04, 0xa0, 0x33, 0x19, 0x4c, 0x43, 0x6f,
+	0x64, 0xb6, 0x88, 0x01, 0x6d, 0x2f, 0x08, 0xc6, 0x81, 0x79, 0xaf, 0xe4, 0x4c, 0xfd, 0x4f, 0xfe,
+	0xf8, 0xdc, 0x37, 0xdb, 0xf6, 0x29, 0x3c, 0x3a, 0x61, 0xc9, 0x09, 0x5e, 0x22, 0x0d, 0xf0, 0x5b,
+	0x81, 0x42, 0x92, 0x3d, 0x00, 0xca, 0x92, 0xd9, 0x8a, 0xc5, 0x05, 0x45, 0xb5, 0xaa, 0x11, 0x18,
+	0x94, 0x25, 0xa7, 0xaa, 0x40, 0x76, 0xa1, 0x04, 0x33, 0x5a, 0x8e, 0x58, 0x4d, 0xd5, 0xed, 0xd2,
+	0x4a, 0xc2, 0xf6, 0xc1, 0xbc, 0x92, 0x13, 0x39, 0xcb, 0x04, 0xfe, 0x97, 0x9e, 0x0b, 0xdb, 0x43,
+	0x46, 0x29, 0x2e, 0xe4, 0xf1, 0x2a, 0x4a, 0x50, 0xd4, 0x3b, 0xee, 0xc0, 0x66, 0xcc, 0xd7, 0x33,
+	0x5e, 0x64, 0x4a, 0xb0, 0x1b, 0x74, 0x62, 0xbe, 0x0e, 0x8a, 0xcc, 0xfe, 0x0a, 0x4f, 0x6e, 0x0c,
+	0x54, 0x5b, 0xf4, 0xa0, 0x93, 0xaa, 0x8a, 0xd5, 0xd8, 0x6f, 0x1d, 0x18, 0x41, 0x85, 0xc8, 0x33,
+	0x80, 0x05, 0xcb, 0x64, 0x94, 0x66, 0xc8, 0x85, 0xd5, 0x54, 0xbd, 0x6b, 0x15, 0xd2, 0x87, 0x2e,
+	0x47, 0x05, 0x62, 0xab, 0xa5, 0xba, 0xbf, 0xf0, 0xe1, 0x8f, 0x16, 0xb4, 0x07, 0x65, 0x2c, 0xc8,
+	0x1b, 0x30, 0x8e, 0x50, 0x56, 0x3e, 0xf7, 0x1c, 0x1d, 0x0b, 0xa7, 0x8e, 0x85, 0xe3, 0x95, 0xb1,
+	0xe8, 0x6f, 0xdf, 0xe6, 0xb7, 0xbd, 0x41, 0xde, 0xc1, 0x56, 0x28, 0x23, 0x2e, 0x75, 0xf9, 0xaf,
+	0xc7, 0xdf, 0x96, 0xe9, 0x60, 0xf9, 0x3f, 0x4e, 0x7f, 0x84, 0xc7, 0x47, 0x28, 0xb5, 0x17, 0xb5,
+	0x75, 0x64, 0xa7, 0x26, 0xdf, 0xc8, 0x46, 0xdf, 0xfa, 0xbd, 0xa1, 0xff, 0xaf, 0x56, 0x0a, 0xef,
+	0x46, 0xe9, 0x1c, 0x7a, 0x95, 0x89, 0xc3, 0x65, 0x94, 0x66, 0x0b, 0x16, 0xa3, 0x76, 0x93, 0x3c,
+	0xad, 0xa7, 0x6e, 0x4b, 0x45, 0x7f, 0xef, 0x0f, 0xdd, 0x5a, 0xf8, 0xfd, 0xcb, 0x2f, 0x2f, 0x92,
+	0x54, 0x2e, 0x8b, 0xb9, 0xb3, 0x60, 0x2b, 0x77, 0xb9, 0xce, 0x91, 0x53, 0x8c, 0x13, 0xe4, 0xee,
+	0x45, 0x34, 0xe7, 0xe9, 0x42, 0x1f, 0xb2, 0x70, 0xcb, 0x83, 0x9f, 0xeb, 0x23, 0x7f, 0xf5, 0x73,
+	0x00, 0x62, 0xb9, 0x78, 0x4a, 0xff, 0x03, 0x00, 0x00,
 }
@@ -94,6 +94,33 @@ func (ChaincodeDeploymentSpec_ExecutionEnvironment) EnumDescriptor() ([]byte, []
 	return fileDescriptor1, []int{3, 0}
 }
 
+type ChaincodeArgSchema_Type int32
+
+const (
+	ChaincodeArgSchema_STRING  ChaincodeArgSchema_Type = 0
+	ChaincodeArgSchema_INTEGER ChaincodeArgSchema_Type = 1
+	ChaincodeArgSchema_BOOL    ChaincodeArgSchema_Type = 2
+	ChaincodeArgSchema_JSON    ChaincodeArgSchema_Type = 3
+)
+
+var ChaincodeArgSchema_Type_name = map[int32]string{
+	0: "STRING",
+	1: "INTEGER",
+	2: "BOOL",
+	3: "JSON",
+}
+var ChaincodeArgSchema_Type_value = map[string]int32{
+	"STRING":  0,
+	"INTEGER": 1,
+	"BOOL":    2,
+	"JSON":    3,
+}
+
+func (x ChaincodeArgSchema_Type) String() string {
+	return proto.EnumName(ChaincodeArgSchema_Type_name, int32(x))
+}
+func (ChaincodeArgSchema_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{19, 0} }
+
 type ChaincodeMessage_Type int32
 
 const (
@@ -116,6 +143,8 @@ const (
 	ChaincodeMessage_QUERY_STATE_CLOSE   ChaincodeMessage_Type = 17
 	ChaincodeMessage_KEEPALIVE           ChaincodeMessage_Type = 18
 	ChaincodeMessage_GET_HISTORY_FOR_KEY ChaincodeMessage_Type = 19
+	ChaincodeMessage_GET_STATE_AT_HEIGHT ChaincodeMessage_Type = 20
+	ChaincodeMessage_RESUME              ChaincodeMessage_Type = 21
 )
 
 var ChaincodeMessage_Type_name = map[int32]string{
@@ -138,6 +167,8 @@ var ChaincodeMessage_Type_name = map[int32]string{
 	17: "QUERY_STATE_CLOSE",
 	18: "KEEPALIVE",
 	19: "GET_HISTORY_FOR_KEY",
+	20: "GET_STATE_AT_HEIGHT",
+	21: "RESUME",
 }
 var ChaincodeMessage_Type_value = map[string]int32{
 	"UNDEFINED":           0,
@@ -159,6 +190,8 @@ var ChaincodeMessage_Type_value = map[string]int32{
 	"QUERY_STATE_CLOSE":   17,
 	"KEEPALIVE":           18,
 	"GET_HISTORY_FOR_KEY": 19,
+	"GET_STATE_AT_HEIGHT": 20,
+	"RESUME":              21,
 }
 
 func (x ChaincodeMessage_Type) String() string {
@@ -193,6 +226,8 @@ func (*ChaincodeID) Descriptor() ([]byte, []int) { return fileDescriptor1, []int
 // the []byte-based current ChaincodeInput structure.
 type ChaincodeInput struct {
 	Args [][]byte `protobuf:"bytes,1,rep,name=args,proto3" json:"args,omitempty"`
+	// Detached references payloads kept out of the transaction
+	Detached []*DetachedPayload `protobuf:"bytes,2,rep,name=detached" json:"detached,omitempty"`
 }
 
 func (m *ChaincodeInput) Reset()                    { *m = ChaincodeInput{} }
@@ -200,6 +235,13 @@ func (m *ChaincodeInput) String() string            { return proto.CompactTextSt
 func (*ChaincodeInput) ProtoMessage()               {}
 func (*ChaincodeInput) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
 
+func (m *ChaincodeInput) GetDetached() []*DetachedPayload {
+	if m != nil {
+		return m.Detached
+	}
+	return nil
+}
+
 // Carries the chaincode specification. This is the actual metadata required for
 // defining a chaincode.
 type ChaincodeSpec struct {
@@ -236,6 +278,9 @@ type ChaincodeDeploymentSpec struct {
 	EffectiveDate *google_protobuf1.Timestamp                  `protobuf:"bytes,2,opt,name=effective_date,json=effectiveDate" json:"effective_date,omitempty"`
 	CodePackage   []byte                                       `protobuf:"bytes,3,opt,name=code_package,json=codePackage,proto3" json:"code_package,omitempty"`
 	ExecEnv       ChaincodeDeploymentSpec_ExecutionEnvironment `protobuf:"varint,4,opt,name=exec_env,json=execEnv,enum=protos.ChaincodeDeploymentSpec_ExecutionEnvironment" json:"exec_env,omitempty"`
+	// The schema the arguments of the instantiation and of the upgrades of the
+	// chaincode are validated against, if declared in the installed package.
+	InitArgsSchema *ChaincodeInitArgsSchema `protobuf:"bytes,5,opt,name=init_args_schema,json=initArgsSchema" json:"init_args_schema,omitempty"`
 }
 
 func (m *ChaincodeDeploymentSpec) Reset()                    { *m = ChaincodeDeploymentSpec{} }
@@ -257,6 +302,13 @@ func (m *ChaincodeDeploymentSpec) GetEffectiveDate() *google_protobuf1.Timestamp
 	return nil
 }
 
+func (m *ChaincodeDeploymentSpec) GetInitArgsSchema() *ChaincodeInitArgsSchema {
+	if m != nil {
+		return m.InitArgsSchema
+	}
+	return nil
+}
+
 // Carries the chaincode function and its arguments.
 type ChaincodeInvocationSpec struct {
 	ChaincodeSpec *ChaincodeSpec `protobuf:"bytes,1,opt,name=chaincode_spec,json=chaincodeSpec" json:"chaincode_spec,omitempty"`
@@ -293,6 +345,16 @@ type ChaincodeProposalContext struct {
 	// To simply access to this data, replacing bytes with a map
 	// is the next step to be carried.
 	Transient []byte `protobuf:"bytes,2,opt,name=transient,proto3" json:"transient,omitempty"`
+	// DetachedContents are the contents of the detached payloads of the input,
+	// in order, when the peer resolved them
+	DetachedContents [][]byte `protobuf:"bytes,3,rep,name=detached_contents,json=detachedContents,proto3" json:"detached_contents,omitempty"`
+	// PreferredMaxBytes is the preferred maximum size in bytes of the blocks
+	// of the channel, 0 if the peer does not know it
+	PreferredMaxBytes uint32 `protobuf:"varint,4,opt,name=preferred_max_bytes,json=preferredMaxBytes" json:"preferred_max_bytes,omitempty"`
+	// ProposalBytes and Signature are those of the SignedProposal, for the
+	// chaincode to evaluate policies against the creator of the proposal
+	ProposalBytes []byte `protobuf:"bytes,5,opt,name=proposal_bytes,json=proposalBytes,proto3" json:"proposal_bytes,omitempty"`
+	Signature     []byte `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
 }
 
 func (m *ChaincodeProposalContext) Reset()                    { *m = ChaincodeProposalContext{} }
@@ -422,6 +484,82 @@ func (m *QueryStateResponse) GetKeysAndValues() []*QueryStateKeyValue {
 	return nil
 }
 
+type GetStateAtHeight struct {
+	Key    string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Height uint64 `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
+}
+
+func (m *GetStateAtHeight) Reset()                    { *m = GetStateAtHeight{} }
+func (m *GetStateAtHeight) String() string            { return proto.CompactTextString(m) }
+func (*GetStateAtHeight) ProtoMessage()               {}
+func (*GetStateAtHeight) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{15} }
+
+// DetachedPayload references a payload kept out of the transaction by its URI
+// and the SHA256 hash of its content
+type DetachedPayload struct {
+	Uri  string `protobuf:"bytes,1,opt,name=uri" json:"uri,omitempty"`
+	Hash []byte `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+}
+
+func (m *DetachedPayload) Reset()                    { *m = DetachedPayload{} }
+func (m *DetachedPayload) String() string            { return proto.CompactTextString(m) }
+func (*DetachedPayload) ProtoMessage()               {}
+func (*DetachedPayload) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{16} }
+
+// ChaincodeResume is sent by a chaincode in a RESUME message to resume its
+// registration on a new stream, with the transactions it is still executing
+// and the nonce the peer sent it in the REGISTERED message of its registration
+type ChaincodeResume struct {
+	ChaincodeId *ChaincodeID `protobuf:"bytes,1,opt,name=chaincode_id,json=chaincodeId" json:"chaincode_id,omitempty"`
+	Txids       []string     `protobuf:"bytes,2,rep,name=txids" json:"txids,omitempty"`
+	Nonce       []byte       `protobuf:"bytes,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+}
+
+func (m *ChaincodeResume) Reset()                    { *m = ChaincodeResume{} }
+func (m *ChaincodeResume) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeResume) ProtoMessage()               {}
+func (*ChaincodeResume) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{17} }
+
+func (m *ChaincodeResume) GetChaincodeId() *ChaincodeID {
+	if m != nil {
+		return m.ChaincodeId
+	}
+	return nil
+}
+
+// ChaincodeInitArgsSchema describes the arguments following the function name
+// the Init function of a chaincode expects, in order
+type ChaincodeInitArgsSchema struct {
+	Args []*ChaincodeArgSchema `protobuf:"bytes,1,rep,name=args" json:"args,omitempty"`
+}
+
+func (m *ChaincodeInitArgsSchema) Reset()                    { *m = ChaincodeInitArgsSchema{} }
+func (m *ChaincodeInitArgsSchema) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeInitArgsSchema) ProtoMessage()               {}
+func (*ChaincodeInitArgsSchema) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{18} }
+
+func (m *ChaincodeInitArgsSchema) GetArgs() []*ChaincodeArgSchema {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+// ChaincodeArgSchema describes an argument of the Init function of a chaincode.
+// Only the last arguments may be optional, and the pattern, if any, is a regular
+// expression the whole argument must match
+type ChaincodeArgSchema struct {
+	Name     string                  `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Type     ChaincodeArgSchema_Type `protobuf:"varint,2,opt,name=type,enum=protos.ChaincodeArgSchema_Type" json:"type,omitempty"`
+	Optional bool                    `protobuf:"varint,3,opt,name=optional" json:"optional,omitempty"`
+	Pattern  string                  `protobuf:"bytes,4,opt,name=pattern" json:"pattern,omitempty"`
+}
+
+func (m *ChaincodeArgSchema) Reset()                    { *m = ChaincodeArgSchema{} }
+func (m *ChaincodeArgSchema) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeArgSchema) ProtoMessage()               {}
+func (*ChaincodeArgSchema) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{19} }
+
 func init() {
 	proto.RegisterType((*ChaincodeID)(nil), "protos.ChaincodeID")
 	proto.RegisterType((*ChaincodeInput)(nil), "protos.ChaincodeInput")
@@ -438,10 +576,16 @@ func init() {
 	proto.RegisterType((*QueryStateClose)(nil), "protos.QueryStateClose")
 	proto.RegisterType((*QueryStateKeyValue)(nil), "protos.QueryStateKeyValue")
 	proto.RegisterType((*QueryStateResponse)(nil), "protos.QueryStateResponse")
+	proto.RegisterType((*GetStateAtHeight)(nil), "protos.GetStateAtHeight")
+	proto.RegisterType((*DetachedPayload)(nil), "protos.DetachedPayload")
+	proto.RegisterType((*ChaincodeResume)(nil), "protos.ChaincodeResume")
+	proto.RegisterType((*ChaincodeInitArgsSchema)(nil), "protos.ChaincodeInitArgsSchema")
+	proto.RegisterType((*ChaincodeArgSchema)(nil), "protos.ChaincodeArgSchema")
 	proto.RegisterEnum("protos.ConfidentialityLevel", ConfidentialityLevel_name, ConfidentialityLevel_value)
 	proto.RegisterEnum("protos.ChaincodeSpec_Type", ChaincodeSpec_Type_name, ChaincodeSpec_Type_value)
 	proto.RegisterEnum("protos.ChaincodeDeploymentSpec_ExecutionEnvironment", ChaincodeDeploymentSpec_ExecutionEnvironment_name, ChaincodeDeploymentSpec_ExecutionEnvironment_value)
 	proto.RegisterEnum("protos.ChaincodeMessage_Type", ChaincodeMessage_Type_name, ChaincodeMessage_Type_value)
+	proto.RegisterEnum("protos.ChaincodeArgSchema_Type", ChaincodeArgSchema_Type_name, ChaincodeArgSchema_Type_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -551,79 +695,101 @@ var _ChaincodeSupport_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("peer/chaincode.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 1184 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x56, 0xcf, 0x6f, 0xdb, 0xc6,
-	0x12, 0x8e, 0x6c, 0xc9, 0x96, 0x46, 0xb2, 0xb4, 0xd9, 0x38, 0x8e, 0x62, 0xbc, 0x87, 0xe7, 0x10,
-	0xc1, 0x83, 0x5f, 0xf0, 0x20, 0xb7, 0x6e, 0x10, 0xf4, 0x10, 0xb4, 0xa0, 0xc9, 0xb5, 0xc2, 0x4a,
-	0x26, 0x95, 0x15, 0x6d, 0xc4, 0xbd, 0x10, 0x34, 0x39, 0x96, 0x89, 0xc8, 0x24, 0x4b, 0xae, 0x04,
-	0xeb, 0x1c, 0xf4, 0xdf, 0xea, 0xb5, 0x7f, 0x56, 0x8b, 0x5d, 0xea, 0x87, 0x1d, 0x25, 0x40, 0x0e,
-	0x3d, 0x69, 0xbf, 0xd9, 0x6f, 0x66, 0x67, 0xbe, 0x9d, 0x59, 0x11, 0x76, 0x53, 0xc4, 0xec, 0x28,
-	0xb8, 0xf1, 0xa3, 0x38, 0x48, 0x42, 0xec, 0xa4, 0x59, 0x22, 0x12, 0xba, 0xa5, 0x7e, 0xf2, 0xfd,
-	0xe7, 0x0f, 0x77, 0x71, 0x8a, 0xb1, 0x28, 0x28, 0xfb, 0xff, 0x19, 0x25, 0xc9, 0x68, 0x8c, 0x47,
-	0x0a, 0x5d, 0x4d, 0xae, 0x8f, 0x44, 0x74, 0x8b, 0xb9, 0xf0, 0x6f, 0xd3, 0x82, 0xa0, 0x39, 0x50,
-	0x37, 0x16, 0x8e, 0x96, 0x49, 0x29, 0x94, 0x53, 0x5f, 0xdc, 0xb4, 0x4b, 0x07, 0xa5, 0xc3, 0x1a,
-	0x57, 0x6b, 0x69, 0x8b, 0xfd, 0x5b, 0x6c, 0x6f, 0x14, 0x36, 0xb9, 0xa6, 0x6d, 0xd8, 0x9e, 0x62,
-	0x96, 0x47, 0x49, 0xdc, 0xde, 0x54, 0xe6, 0x05, 0xd4, 0x5e, 0x42, 0x73, 0x15, 0x30, 0x4e, 0x27,
-	0x42, 0xfa, 0xfb, 0xd9, 0x28, 0x6f, 0x97, 0x0e, 0x36, 0x0f, 0x1b, 0x5c, 0xad, 0xb5, 0xbf, 0x4a,
-	0xb0, 0xb3, 0xa4, 0x0d, 0x53, 0x0c, 0x68, 0x07, 0xca, 0x62, 0x96, 0xa2, 0x3a, 0xb9, 0x79, 0xbc,
-	0x5f, 0xa4, 0x97, 0x77, 0x1e, 0x90, 0x3a, 0xee, 0x2c, 0x45, 0xae, 0x78, 0xf4, 0x0d, 0x34, 0x96,
-	0x15, 0x7b, 0x51, 0xa8, 0xb2, 0xab, 0x1f, 0x3f, 0x59, 0xf3, 0xb3, 0x4c, 0x5e, 0x5f, 0x12, 0xad,
-	0x90, 0xfe, 0x1f, 0x2a, 0x91, 0x4c, 0x4b, 0xe5, 0x5d, 0x3f, 0xde, 0x5b, 0x77, 0x90, 0xbb, 0xbc,
-	0x20, 0xc9, 0x3a, 0xa5, 0x62, 0xc9, 0x44, 0xb4, 0xcb, 0x07, 0xa5, 0xc3, 0x0a, 0x5f, 0x40, 0xed,
-	0x27, 0x28, 0xcb, 0x6c, 0xe8, 0x0e, 0xd4, 0xce, 0x6d, 0x93, 0x9d, 0x5a, 0x36, 0x33, 0xc9, 0x23,
-	0x0a, 0xb0, 0xd5, 0x75, 0xfa, 0xba, 0xdd, 0x25, 0x25, 0x5a, 0x85, 0xb2, 0xed, 0x98, 0x8c, 0x6c,
-	0xd0, 0x6d, 0xd8, 0x34, 0x74, 0x4e, 0x36, 0xa5, 0xe9, 0x17, 0xfd, 0x42, 0x27, 0x65, 0xed, 0x8f,
-	0x0d, 0x78, 0xb6, 0x3c, 0xd3, 0xc4, 0x74, 0x9c, 0xcc, 0x6e, 0x31, 0x16, 0x4a, 0x8b, 0xb7, 0xd0,
-	0x5c, 0xd5, 0x96, 0xa7, 0x18, 0x28, 0x55, 0xea, 0xc7, 0x4f, 0xbf, 0xa8, 0x0a, 0xdf, 0x09, 0x1e,
-	0x28, 0xa9, 0x43, 0x13, 0xaf, 0xaf, 0x31, 0x10, 0xd1, 0x14, 0xbd, 0xd0, 0x17, 0x38, 0xd7, 0x66,
-	0xbf, 0x53, 0x34, 0x43, 0x67, 0xd1, 0x0c, 0x1d, 0x77, 0xd1, 0x0c, 0x7c, 0x67, 0xe9, 0x61, 0xfa,
-	0x02, 0xe9, 0x0b, 0x68, 0xa8, 0xb3, 0x53, 0x3f, 0xf8, 0xe8, 0x8f, 0x50, 0x69, 0xd5, 0xe0, 0x75,
-	0x69, 0x1b, 0x14, 0x26, 0xea, 0x40, 0x15, 0xef, 0x30, 0xf0, 0x30, 0x9e, 0x2a, 0x69, 0x9a, 0xc7,
-	0xaf, 0xd7, 0xb2, 0x7b, 0x58, 0x56, 0x87, 0xdd, 0x61, 0x30, 0x11, 0x51, 0x12, 0xb3, 0x78, 0x1a,
-	0x65, 0x49, 0x2c, 0x37, 0xf8, 0xb6, 0x8c, 0xc2, 0xe2, 0xa9, 0xd6, 0x81, 0xdd, 0x2f, 0x11, 0xa4,
-	0xa2, 0xa6, 0x63, 0xf4, 0x18, 0x2f, 0xd4, 0x1d, 0x5e, 0x0e, 0x5d, 0x76, 0x46, 0x4a, 0xda, 0xa7,
-	0xd2, 0x3d, 0x01, 0xad, 0x78, 0x9a, 0x04, 0xbe, 0x74, 0xfd, 0x07, 0x04, 0x7c, 0x05, 0x8f, 0xa3,
-	0xd0, 0x1b, 0x61, 0x8c, 0x99, 0x0a, 0xe9, 0xf9, 0xe3, 0xd1, 0xbc, 0xfb, 0x5b, 0x51, 0xd8, 0x5d,
-	0xda, 0xf5, 0xf1, 0x48, 0xe3, 0xd0, 0x5e, 0xc6, 0x1a, 0x64, 0x49, 0x9a, 0xe4, 0xfe, 0xd8, 0x48,
-	0x62, 0x81, 0x77, 0xaa, 0x79, 0x82, 0x0c, 0x7d, 0x91, 0x64, 0xea, 0xf8, 0x06, 0x5f, 0x40, 0xfa,
-	0x2f, 0xa8, 0x89, 0xcc, 0x8f, 0xf3, 0x08, 0x63, 0xa1, 0x22, 0x37, 0xf8, 0xca, 0xa0, 0xfd, 0x5e,
-	0x01, 0xb2, 0x0c, 0x7a, 0x86, 0x79, 0x2e, 0xf5, 0xfe, 0xfe, 0xc1, 0x7c, 0xfc, 0x7b, 0xad, 0x90,
-	0x39, 0xef, 0xfe, 0x88, 0xfc, 0x08, 0xb5, 0xe5, 0xb8, 0x7f, 0x43, 0x0f, 0xac, 0xc8, 0x32, 0xf3,
-	0xd4, 0x9f, 0x8d, 0x13, 0x3f, 0x9c, 0x5f, 0xfd, 0x02, 0xca, 0x61, 0x16, 0x77, 0x51, 0xa8, 0xae,
-	0xbc, 0xc6, 0xd5, 0x9a, 0xf6, 0x80, 0xa4, 0xf3, 0xd2, 0xbd, 0xa0, 0xa8, 0xbd, 0x5d, 0x51, 0xc7,
-	0x1d, 0xac, 0xa5, 0xf9, 0x99, 0x46, 0xbc, 0x95, 0x7e, 0x26, 0xda, 0xcf, 0xd0, 0x5a, 0x5d, 0x9d,
-	0x7a, 0xca, 0xda, 0x5b, 0x5f, 0x99, 0x54, 0x26, 0x77, 0xf9, 0xea, 0xa6, 0x15, 0xd6, 0xfe, 0xdc,
-	0xf8, 0xf2, 0x64, 0x36, 0xa0, 0xca, 0x59, 0xd7, 0x1a, 0xba, 0x8c, 0x93, 0x12, 0x6d, 0x02, 0x2c,
-	0x10, 0x33, 0xc9, 0x86, 0x1c, 0x4c, 0xcb, 0xb6, 0x5c, 0xb2, 0x49, 0x6b, 0x50, 0xe1, 0x4c, 0x37,
-	0x2f, 0x49, 0x99, 0xb6, 0xa0, 0xee, 0x72, 0xdd, 0x1e, 0xea, 0x86, 0x6b, 0x39, 0x36, 0xa9, 0xc8,
-	0x90, 0x86, 0x73, 0x36, 0xe8, 0x33, 0x97, 0x99, 0x64, 0x4b, 0x52, 0x19, 0xe7, 0x0e, 0x27, 0xdb,
-	0x72, 0xa7, 0xcb, 0x5c, 0x6f, 0xe8, 0xea, 0x2e, 0x23, 0x55, 0x09, 0x07, 0xe7, 0x0b, 0x58, 0x93,
-	0xd0, 0x64, 0xfd, 0x39, 0x04, 0xba, 0x0b, 0xc4, 0xb2, 0x2f, 0x9c, 0x1e, 0xf3, 0x8c, 0x77, 0xba,
-	0x65, 0x1b, 0xf2, 0x91, 0xa8, 0x17, 0x09, 0x0e, 0x07, 0x8e, 0x3d, 0x64, 0x64, 0x87, 0xee, 0x01,
-	0x5d, 0x06, 0xf4, 0x4e, 0x2e, 0x3d, 0xae, 0xdb, 0x5d, 0x46, 0x9a, 0xd2, 0x57, 0xda, 0xdf, 0x9f,
-	0x33, 0x7e, 0xe9, 0x71, 0x36, 0x3c, 0xef, 0xbb, 0xa4, 0x25, 0xad, 0x85, 0xa5, 0xe0, 0xdb, 0xec,
-	0x83, 0x4b, 0x08, 0x7d, 0x0a, 0x8f, 0xef, 0x5b, 0x8d, 0xbe, 0x33, 0x64, 0xe4, 0xb1, 0xcc, 0xa6,
-	0xc7, 0xd8, 0x40, 0xef, 0x5b, 0x17, 0x8c, 0x50, 0xfa, 0x0c, 0x9e, 0xc8, 0x88, 0xef, 0xac, 0xa1,
-	0xeb, 0xf0, 0x4b, 0xef, 0xd4, 0xe1, 0x5e, 0x8f, 0x5d, 0x92, 0x27, 0xda, 0x1b, 0x68, 0x0c, 0x26,
-	0x62, 0x28, 0x7c, 0x81, 0x56, 0x7c, 0x9d, 0x50, 0x02, 0x9b, 0x1f, 0x71, 0x36, 0xff, 0x6f, 0x90,
-	0x4b, 0xba, 0x0b, 0x95, 0xa9, 0x3f, 0x9e, 0xe0, 0xbc, 0x87, 0x0b, 0xa0, 0x31, 0x68, 0x75, 0xb1,
-	0xf0, 0x3b, 0x99, 0x71, 0x3f, 0x1e, 0x21, 0xdd, 0x87, 0x6a, 0x2e, 0xfc, 0x4c, 0xf4, 0x96, 0xfe,
-	0x4b, 0x4c, 0xf7, 0x60, 0x0b, 0xe3, 0x50, 0xee, 0x14, 0x33, 0x36, 0x47, 0xda, 0x7f, 0xa1, 0xd9,
-	0x45, 0xf1, 0x7e, 0x82, 0xd9, 0x8c, 0x63, 0x3e, 0x19, 0x0b, 0x79, 0xdc, 0x6f, 0x12, 0xce, 0x43,
-	0x14, 0x40, 0x7b, 0x09, 0xa4, 0x8b, 0xe2, 0x5d, 0x94, 0x8b, 0x24, 0x9b, 0x9d, 0x26, 0x99, 0x8c,
-	0xb9, 0x96, 0xaa, 0x76, 0x00, 0x4d, 0x15, 0x4a, 0xa5, 0x65, 0xcb, 0x4e, 0x6b, 0xc2, 0x46, 0x14,
-	0xce, 0x29, 0x1b, 0x51, 0xa8, 0xbd, 0x80, 0xd6, 0x8a, 0x61, 0x8c, 0x93, 0x1c, 0xd7, 0x28, 0x6f,
-	0x81, 0xae, 0x28, 0x3d, 0x9c, 0x5d, 0xc8, 0x7a, 0xbf, 0x59, 0x97, 0x4f, 0xa5, 0xfb, 0xee, 0x1c,
-	0xf3, 0x34, 0x89, 0x73, 0xa4, 0x27, 0xd0, 0xfa, 0x88, 0xb3, 0xdc, 0xf3, 0xe3, 0xd0, 0x53, 0xc4,
-	0xe2, 0xaf, 0xb2, 0xbe, 0xfa, 0x13, 0x5c, 0x3f, 0x93, 0xef, 0x48, 0x17, 0x3d, 0x0e, 0x15, 0xca,
-	0xe9, 0x73, 0xa8, 0xde, 0xf8, 0xb9, 0x77, 0x9b, 0x64, 0xc5, 0x99, 0x55, 0xbe, 0x7d, 0xe3, 0xe7,
-	0x67, 0x49, 0xb6, 0xa8, 0x61, 0x73, 0x51, 0xc3, 0xab, 0xd7, 0xb0, 0x6b, 0x24, 0xf1, 0x75, 0x14,
-	0x62, 0x2c, 0x22, 0x7f, 0x1c, 0x89, 0x59, 0x1f, 0xa7, 0x38, 0x96, 0x6f, 0xeb, 0xe0, 0xfc, 0xa4,
-	0x6f, 0x19, 0xe4, 0x11, 0x25, 0xd0, 0x30, 0x1c, 0xfb, 0xd4, 0x32, 0x99, 0xed, 0x5a, 0x7a, 0x9f,
-	0x94, 0x8e, 0x3f, 0xdc, 0x7b, 0x92, 0x86, 0x93, 0x34, 0x4d, 0x32, 0x41, 0x4d, 0xa8, 0x72, 0x1c,
-	0x45, 0xb9, 0xc0, 0x8c, 0xb6, 0xbf, 0xf6, 0x20, 0xed, 0x7f, 0x75, 0x47, 0x7b, 0x74, 0x58, 0xfa,
-	0xae, 0x74, 0x62, 0xc0, 0x5e, 0x92, 0x8d, 0x3a, 0x37, 0xb3, 0x14, 0xb3, 0x31, 0x86, 0x23, 0xcc,
-	0xe6, 0x0e, 0xbf, 0xfe, 0x6f, 0x14, 0x89, 0x9b, 0xc9, 0x55, 0x27, 0x48, 0x6e, 0x8f, 0xee, 0x6d,
-	0x1f, 0x5d, 0xfb, 0x57, 0x59, 0x14, 0x14, 0xdf, 0x34, 0xf9, 0x91, 0xfc, 0xf8, 0xb9, 0x2a, 0x3e,
-	0x85, 0x7e, 0xf8, 0x3b, 0x00, 0x00, 0xff, 0xff, 0x51, 0xfa, 0x66, 0xd7, 0x29, 0x09, 0x00, 0x00,
+	// 1530 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0x4f, 0x6f, 0xe3, 0xb8,
+	0x15, 0x1f, 0xc5, 0x76, 0x62, 0x3f, 0x3b, 0x36, 0xc3, 0xc9, 0x64, 0xbc, 0xc1, 0x16, 0x93, 0x15,
+	0xda, 0x22, 0xdd, 0x16, 0x4e, 0x9b, 0x59, 0x6c, 0x7b, 0x58, 0xb4, 0x50, 0x6c, 0x8e, 0xa3, 0x8d,
+	0x23, 0x79, 0x69, 0x65, 0xb0, 0xe9, 0x45, 0x60, 0x24, 0xc6, 0x16, 0xc6, 0x96, 0x54, 0x89, 0x36,
+	0xe2, 0xf3, 0x9c, 0xfa, 0x55, 0xfa, 0x49, 0xfa, 0x61, 0x7a, 0xec, 0xbd, 0x05, 0xa9, 0x3f, 0x71,
+	0xc6, 0x19, 0x60, 0x0e, 0x7b, 0x32, 0x7f, 0xef, 0x1f, 0xdf, 0x7b, 0xa4, 0x7e, 0x7c, 0x86, 0xc3,
+	0x98, 0xf3, 0xe4, 0xcc, 0x9b, 0xb1, 0x20, 0xf4, 0x22, 0x9f, 0xf7, 0xe2, 0x24, 0x12, 0x11, 0xde,
+	0x55, 0x3f, 0xe9, 0xf1, 0x57, 0x4f, 0xb5, 0x7c, 0xc5, 0x43, 0x91, 0x99, 0x1c, 0xbf, 0x99, 0x46,
+	0xd1, 0x74, 0xce, 0xcf, 0x14, 0xba, 0x5b, 0xde, 0x9f, 0x89, 0x60, 0xc1, 0x53, 0xc1, 0x16, 0x71,
+	0x66, 0xa0, 0xdb, 0xd0, 0xec, 0x17, 0x8e, 0xe6, 0x00, 0x63, 0xa8, 0xc6, 0x4c, 0xcc, 0xba, 0xda,
+	0x89, 0x76, 0xda, 0xa0, 0x6a, 0x2d, 0x65, 0x21, 0x5b, 0xf0, 0xee, 0x4e, 0x26, 0x93, 0x6b, 0xdc,
+	0x85, 0xbd, 0x15, 0x4f, 0xd2, 0x20, 0x0a, 0xbb, 0x15, 0x25, 0x2e, 0xa0, 0x7e, 0x0b, 0xed, 0xc7,
+	0x80, 0x61, 0xbc, 0x14, 0xd2, 0x9f, 0x25, 0xd3, 0xb4, 0xab, 0x9d, 0x54, 0x4e, 0x5b, 0x54, 0xad,
+	0xf1, 0x5b, 0xa8, 0xfb, 0x5c, 0x30, 0x6f, 0xc6, 0xfd, 0xee, 0xce, 0x49, 0xe5, 0xb4, 0x79, 0xfe,
+	0x3a, 0x4b, 0x28, 0xed, 0x0d, 0x72, 0xf9, 0x98, 0xad, 0xe7, 0x11, 0xf3, 0x69, 0x69, 0xa8, 0xff,
+	0x4f, 0x83, 0xfd, 0x32, 0xf6, 0x24, 0xe6, 0x1e, 0xee, 0x41, 0x55, 0xac, 0x63, 0xae, 0xd2, 0x6d,
+	0x9f, 0x1f, 0x17, 0x21, 0x9e, 0x18, 0xf5, 0x9c, 0x75, 0xcc, 0xa9, 0xb2, 0xc3, 0xdf, 0x43, 0xab,
+	0x6c, 0x93, 0x1b, 0xf8, 0xaa, 0xa4, 0xe6, 0xf9, 0xcb, 0x2d, 0x3f, 0x73, 0x40, 0x9b, 0xa5, 0xa1,
+	0xe9, 0xe3, 0x3f, 0x40, 0x2d, 0x90, 0xb5, 0xa8, 0x62, 0x9b, 0xe7, 0x47, 0xdb, 0x0e, 0x52, 0x4b,
+	0x33, 0x23, 0xd9, 0x1c, 0xd9, 0xe6, 0x68, 0x29, 0xba, 0xd5, 0x13, 0xed, 0xb4, 0x46, 0x0b, 0xa8,
+	0xff, 0x15, 0xaa, 0x32, 0x1b, 0xbc, 0x0f, 0x8d, 0x1b, 0x6b, 0x40, 0xde, 0x99, 0x16, 0x19, 0xa0,
+	0x17, 0x18, 0x60, 0x77, 0x68, 0x8f, 0x0c, 0x6b, 0x88, 0x34, 0x5c, 0x87, 0xaa, 0x65, 0x0f, 0x08,
+	0xda, 0xc1, 0x7b, 0x50, 0xe9, 0x1b, 0x14, 0x55, 0xa4, 0xe8, 0x47, 0xe3, 0xbd, 0x81, 0xaa, 0xfa,
+	0x3f, 0x2b, 0xf0, 0xba, 0xdc, 0x73, 0xc0, 0xe3, 0x79, 0xb4, 0x5e, 0xf0, 0x50, 0xa8, 0x5e, 0xfc,
+	0x00, 0xed, 0xc7, 0xda, 0xd2, 0x98, 0x7b, 0xaa, 0x2b, 0xcd, 0xf3, 0x57, 0xcf, 0x76, 0x85, 0xee,
+	0x7b, 0x9b, 0x10, 0x1b, 0xd0, 0xe6, 0xf7, 0xf7, 0xdc, 0x13, 0xc1, 0x8a, 0xbb, 0x3e, 0x13, 0x3c,
+	0xef, 0xcd, 0x71, 0x2f, 0xbb, 0x41, 0xbd, 0xe2, 0x06, 0xf5, 0x9c, 0xe2, 0x06, 0xd1, 0xfd, 0xd2,
+	0x63, 0xc0, 0x04, 0xc7, 0xdf, 0x40, 0x4b, 0xed, 0x1d, 0x33, 0xef, 0x03, 0x9b, 0x72, 0xd5, 0xab,
+	0x16, 0x6d, 0x4a, 0xd9, 0x38, 0x13, 0x61, 0x1b, 0xea, 0xfc, 0x81, 0x7b, 0x2e, 0x0f, 0x57, 0xaa,
+	0x35, 0xed, 0xf3, 0xef, 0xb6, 0xb2, 0x7b, 0x5a, 0x56, 0x8f, 0x3c, 0x70, 0x6f, 0x29, 0x82, 0x28,
+	0x24, 0xe1, 0x2a, 0x48, 0xa2, 0x50, 0x2a, 0xe8, 0x9e, 0x8c, 0x42, 0xc2, 0x15, 0x36, 0x01, 0x05,
+	0x61, 0x20, 0x5c, 0x79, 0xa9, 0xdc, 0xd4, 0x9b, 0xf1, 0x05, 0xeb, 0xd6, 0x54, 0xe2, 0x6f, 0x9e,
+	0x39, 0xa3, 0x40, 0x18, 0xc9, 0x34, 0x9d, 0x28, 0x33, 0xda, 0x0e, 0x9e, 0x60, 0xbd, 0x07, 0x87,
+	0xcf, 0xed, 0x25, 0x0f, 0x67, 0x60, 0xf7, 0xaf, 0x08, 0xcd, 0x0e, 0x6a, 0x72, 0x3b, 0x71, 0xc8,
+	0x35, 0xd2, 0xf4, 0x8f, 0xda, 0xc6, 0x59, 0x98, 0xe1, 0x2a, 0xf2, 0x98, 0x74, 0xfd, 0x05, 0xce,
+	0xe2, 0x5b, 0x38, 0x08, 0x7c, 0x77, 0xca, 0x43, 0x9e, 0xa8, 0x90, 0x2e, 0x9b, 0x4f, 0xf3, 0xaf,
+	0xaf, 0x13, 0xf8, 0xc3, 0x52, 0x6e, 0xcc, 0xa7, 0xfa, 0x7f, 0x35, 0xe8, 0x96, 0xc1, 0xc6, 0x49,
+	0x14, 0x47, 0x29, 0x9b, 0xf7, 0xa3, 0x50, 0xf0, 0x07, 0x75, 0x11, 0xbd, 0x84, 0x33, 0x11, 0x25,
+	0x6a, 0xff, 0x16, 0x2d, 0x20, 0xfe, 0x1a, 0x1a, 0x22, 0x61, 0x61, 0x1a, 0xf0, 0x50, 0xa8, 0xd0,
+	0x2d, 0xfa, 0x28, 0xc0, 0xbf, 0x87, 0x83, 0xe2, 0xa3, 0x73, 0x3d, 0x19, 0x2b, 0x14, 0x69, 0xb7,
+	0xa2, 0x3e, 0x5f, 0x54, 0x28, 0xfa, 0xb9, 0x1c, 0xf7, 0xe0, 0x65, 0x9c, 0xf0, 0x7b, 0x9e, 0x24,
+	0xdc, 0x77, 0x17, 0xec, 0xc1, 0xbd, 0x5b, 0x0b, 0x9e, 0xaa, 0xe3, 0xdd, 0xa7, 0x07, 0xa5, 0xea,
+	0x9a, 0x3d, 0x5c, 0x48, 0x05, 0xfe, 0x0d, 0xb4, 0xe3, 0x3c, 0xcf, 0xdc, 0xb4, 0xa6, 0xf6, 0xdf,
+	0x2f, 0xa4, 0x99, 0xd9, 0xd7, 0xd0, 0x48, 0x83, 0x69, 0xc8, 0xc4, 0x32, 0xe1, 0xdd, 0xdd, 0x2c,
+	0xc3, 0x52, 0xa0, 0xff, 0xab, 0x06, 0xa8, 0x2c, 0xfb, 0x9a, 0xa7, 0xa9, 0xbc, 0x5d, 0x7f, 0x7a,
+	0xc2, 0x06, 0xbf, 0xda, 0xea, 0x75, 0x6e, 0xb7, 0x49, 0x08, 0x7f, 0x81, 0x46, 0xc9, 0x88, 0x5f,
+	0x70, 0xe3, 0x1f, 0x8d, 0x65, 0x6f, 0xe3, 0x8c, 0xa1, 0xf2, 0x8b, 0x5e, 0x40, 0xc9, 0x77, 0xe2,
+	0x21, 0xf0, 0x55, 0x07, 0x1a, 0x54, 0xad, 0xf1, 0x15, 0xa0, 0xb2, 0x68, 0x2f, 0x3b, 0x9d, 0xfc,
+	0x9e, 0x9e, 0x6c, 0xa5, 0xf9, 0xc9, 0x29, 0xd2, 0x4e, 0xfc, 0xc9, 0xb1, 0xfe, 0x0d, 0x3a, 0x8f,
+	0xb7, 0x4b, 0xb1, 0x7d, 0x77, 0xf7, 0x33, 0xbc, 0x44, 0xa4, 0x96, 0xb6, 0xbd, 0x27, 0x58, 0xff,
+	0xcf, 0xce, 0xf3, 0x3c, 0xd4, 0x82, 0x3a, 0x25, 0x43, 0x73, 0xe2, 0x10, 0x8a, 0x34, 0xdc, 0x06,
+	0x28, 0x10, 0x19, 0xa0, 0x1d, 0x49, 0x43, 0xa6, 0x65, 0x3a, 0xa8, 0x82, 0x1b, 0x50, 0xa3, 0xc4,
+	0x18, 0xdc, 0xa2, 0x2a, 0xee, 0x40, 0xd3, 0xa1, 0x86, 0x35, 0x31, 0xfa, 0x8e, 0x69, 0x5b, 0xa8,
+	0x26, 0x43, 0xf6, 0xed, 0xeb, 0xf1, 0x88, 0x38, 0x64, 0x80, 0x76, 0xa5, 0x29, 0xa1, 0xd4, 0xa6,
+	0x68, 0x4f, 0x6a, 0x86, 0xc4, 0x71, 0x27, 0x8e, 0xe1, 0x10, 0x54, 0x97, 0x70, 0x7c, 0x53, 0xc0,
+	0x86, 0x84, 0x03, 0x32, 0xca, 0x21, 0xe0, 0x43, 0x40, 0xa6, 0xf5, 0xde, 0xbe, 0x22, 0x6e, 0xff,
+	0xd2, 0x30, 0xad, 0xbe, 0xa4, 0xc4, 0x66, 0x96, 0xe0, 0x64, 0x6c, 0x5b, 0x13, 0x82, 0xf6, 0xf1,
+	0x11, 0xe0, 0x32, 0xa0, 0x7b, 0x71, 0xeb, 0x52, 0xc3, 0x1a, 0x12, 0xd4, 0x96, 0xbe, 0x52, 0xfe,
+	0xd3, 0x0d, 0xa1, 0xb7, 0x2e, 0x25, 0x93, 0x9b, 0x91, 0x83, 0x3a, 0x52, 0x9a, 0x49, 0x32, 0x7b,
+	0x8b, 0xfc, 0xec, 0x20, 0x84, 0x5f, 0xc1, 0xc1, 0xa6, 0xb4, 0x3f, 0xb2, 0x27, 0x04, 0x1d, 0xc8,
+	0x6c, 0xae, 0x08, 0x19, 0x1b, 0x23, 0xf3, 0x3d, 0x41, 0x18, 0xbf, 0x86, 0x97, 0x32, 0xe2, 0xa5,
+	0x39, 0x71, 0x6c, 0x7a, 0xeb, 0xbe, 0xb3, 0xa9, 0x7b, 0x45, 0x6e, 0xd1, 0xcb, 0x42, 0x91, 0x39,
+	0x1b, 0x8e, 0x7b, 0x49, 0xcc, 0xe1, 0xa5, 0x83, 0x0e, 0x25, 0x53, 0xc8, 0x9d, 0xaf, 0x09, 0x7a,
+	0xa5, 0x7f, 0x0f, 0xad, 0xf1, 0x52, 0x4c, 0x04, 0x13, 0xdc, 0x0c, 0xef, 0x23, 0x8c, 0xa0, 0xf2,
+	0x81, 0xaf, 0xf3, 0x37, 0x56, 0x2e, 0xf1, 0x21, 0xd4, 0x56, 0x6c, 0xbe, 0xe4, 0xf9, 0xa7, 0x98,
+	0x01, 0x9d, 0x40, 0x67, 0xc8, 0x33, 0xbf, 0x8b, 0x35, 0x65, 0xe1, 0x94, 0xe3, 0x63, 0xa8, 0xa7,
+	0x82, 0x25, 0xe2, 0xaa, 0xf4, 0x2f, 0x31, 0x3e, 0x82, 0x5d, 0x1e, 0xfa, 0x52, 0x93, 0x71, 0x45,
+	0x8e, 0xf4, 0xdf, 0x42, 0x7b, 0xc8, 0xc5, 0x4f, 0x4b, 0x9e, 0xac, 0x29, 0x4f, 0x97, 0x73, 0x21,
+	0xb7, 0xfb, 0x87, 0x84, 0x79, 0x88, 0x0c, 0xe8, 0xbf, 0x06, 0x34, 0xe4, 0xe2, 0x32, 0x48, 0x45,
+	0x94, 0xac, 0xdf, 0x45, 0x89, 0x8c, 0xb9, 0x95, 0xaa, 0x7e, 0x02, 0x6d, 0x15, 0x4a, 0xa5, 0x65,
+	0xc9, 0xeb, 0xd8, 0x86, 0x9d, 0xc0, 0xcf, 0x4d, 0x76, 0x02, 0x5f, 0xff, 0x06, 0x3a, 0x8f, 0x16,
+	0xfd, 0x79, 0x94, 0xf2, 0x2d, 0x93, 0x1f, 0x00, 0x3f, 0x9a, 0x5c, 0xf1, 0xf5, 0x7b, 0x59, 0xef,
+	0x17, 0xf7, 0xe5, 0xa3, 0xb6, 0xe9, 0x4e, 0x79, 0x1a, 0x47, 0x61, 0xca, 0xf1, 0x05, 0x74, 0x3e,
+	0xf0, 0x75, 0xea, 0xb2, 0xd0, 0x77, 0x95, 0x61, 0x36, 0x72, 0x34, 0x1f, 0xe7, 0x82, 0xed, 0x3d,
+	0xe9, 0xbe, 0x74, 0x31, 0x42, 0x5f, 0xa1, 0x14, 0x7f, 0x05, 0xf5, 0x19, 0x4b, 0xdd, 0x45, 0x94,
+	0x64, 0x7b, 0xd6, 0xe9, 0xde, 0x8c, 0xa5, 0xd7, 0x51, 0x52, 0xd4, 0x50, 0xd9, 0xa8, 0x01, 0x15,
+	0xa7, 0x63, 0x88, 0x4b, 0x1e, 0x4c, 0x67, 0xe2, 0x99, 0x0a, 0x8e, 0x60, 0x77, 0xa6, 0x74, 0x2a,
+	0x5c, 0x95, 0xe6, 0x48, 0xff, 0x33, 0x74, 0x3e, 0x19, 0x74, 0xa4, 0xf3, 0x32, 0x09, 0x0a, 0xe7,
+	0x65, 0x12, 0x48, 0x26, 0x99, 0xb1, 0x74, 0x96, 0x57, 0xaf, 0xd6, 0xfa, 0x12, 0x3a, 0xe5, 0xd7,
+	0x2d, 0x8f, 0x73, 0xb1, 0x3d, 0xd5, 0x68, 0x5f, 0x38, 0xd5, 0x1c, 0x42, 0x4d, 0x92, 0x53, 0xaa,
+	0x26, 0xb0, 0x06, 0xcd, 0x80, 0x94, 0x86, 0x51, 0xe8, 0x15, 0xef, 0x77, 0x06, 0x74, 0xf3, 0xc9,
+	0x63, 0xb7, 0xf9, 0x70, 0xca, 0x21, 0xac, 0x9c, 0xef, 0x9a, 0xcf, 0x0c, 0x61, 0x46, 0x32, 0xcd,
+	0x2c, 0xb3, 0xd9, 0x4f, 0xff, 0xb7, 0x06, 0x78, 0x5b, 0x59, 0x8e, 0x99, 0xda, 0xc6, 0x98, 0xf9,
+	0x36, 0x67, 0xf4, 0x1d, 0xc5, 0xe8, 0x6f, 0x3e, 0x1f, 0x7a, 0x93, 0xd3, 0x8f, 0xa1, 0x1e, 0xc5,
+	0xf2, 0x7d, 0x64, 0x73, 0x55, 0x43, 0x9d, 0x96, 0x38, 0x63, 0x6d, 0x21, 0x78, 0x12, 0xe6, 0xf4,
+	0x5c, 0x40, 0xfd, 0x6d, 0x4e, 0x89, 0xf2, 0x89, 0x77, 0xa8, 0x69, 0x0d, 0xd1, 0x0b, 0xdc, 0x84,
+	0x3d, 0xd3, 0x72, 0xc8, 0x50, 0xd1, 0x61, 0x1d, 0xaa, 0x17, 0xb6, 0x3d, 0xca, 0x88, 0xf0, 0xc7,
+	0x89, 0x6d, 0xa1, 0xca, 0xb7, 0xdf, 0xc1, 0x61, 0x3f, 0x0a, 0xef, 0x03, 0x9f, 0x87, 0x22, 0x60,
+	0xf3, 0x40, 0xac, 0x47, 0x7c, 0xc5, 0xe7, 0x32, 0xc8, 0xf8, 0xe6, 0x62, 0x64, 0xf6, 0xd1, 0x0b,
+	0x8c, 0xa0, 0xd5, 0xb7, 0xad, 0x77, 0xe6, 0x80, 0x58, 0x8e, 0x69, 0x8c, 0x90, 0x76, 0xfe, 0xf3,
+	0xc6, 0xdb, 0x35, 0x59, 0xc6, 0x71, 0x94, 0x08, 0x3c, 0x80, 0x3a, 0xe5, 0xd3, 0x20, 0x15, 0x3c,
+	0xc1, 0xdd, 0xcf, 0xbd, 0x5c, 0xc7, 0x9f, 0xd5, 0xe8, 0x2f, 0x4e, 0xb5, 0x3f, 0x6a, 0x17, 0x7d,
+	0x38, 0x8a, 0x92, 0x69, 0x6f, 0xb6, 0x8e, 0x79, 0x32, 0xe7, 0xfe, 0x94, 0x27, 0xb9, 0xc3, 0xdf,
+	0x7f, 0x37, 0x0d, 0xc4, 0x6c, 0x79, 0xd7, 0xf3, 0xa2, 0xc5, 0xd9, 0x86, 0xfa, 0xec, 0x9e, 0xdd,
+	0x25, 0x81, 0x97, 0xfd, 0x3f, 0x48, 0xcf, 0xe4, 0x1f, 0x89, 0xbb, 0xec, 0x6f, 0xc5, 0xdb, 0xff,
+	0x0f, 0x00, 0x31, 0x59, 0xd0, 0x34, 0x75, 0x0c, 0x00, 0x00,
 }
@@ -17,7 +17,9 @@ limitations under the License.
 package peer
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 
 	"github.com/hyperledger/fabric/common/util"
 )
@@ -25,6 +27,13 @@ import (
 type strArgs struct {
 	Function string
 	Args     []string
+	Detached []detachedArg
+}
+
+// detachedArg is a detached payload reference, its hash hex encoded
+type detachedArg struct {
+	URI  string
+	Hash string
 }
 
 // UnmarshalJSON converts the string-based REST/JSON input to
@@ -40,5 +49,13 @@ func (c *ChaincodeInput) UnmarshalJSON(b []byte) error {
 		allArgs = append([]string{sa.Function}, sa.Args...)
 	}
 	c.Args = util.ToChaincodeArgs(allArgs...)
+	c.Detached = nil
+	for _, d := range sa.Detached {
+		hash, err := hex.DecodeString(d.Hash)
+		if err != nil {
+			return fmt.Errorf("Invalid hash of detached payload %s: %s", d.URI, err)
+		}
+		c.Detached = append(c.Detached, &DetachedPayload{Uri: d.URI, Hash: hash})
+	}
 	return nil
 }
@@ -47,25 +47,56 @@ func (m *AnchorPeer) String() string            { return proto.CompactTextString
 func (*AnchorPeer) ProtoMessage()               {}
 func (*AnchorPeer) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{1} }
 
+// ShimCapabilities restricts the shim APIs available to the chaincodes of a channel
+type ShimCapabilities struct {
+	// The chaincode message types (e.g. GET_HISTORY_FOR_KEY) chaincodes
+	// may not send on the channel
+	Disabled []string `protobuf:"bytes,1,rep,name=disabled" json:"disabled,omitempty"`
+}
+
+func (m *ShimCapabilities) Reset()                    { *m = ShimCapabilities{} }
+func (m *ShimCapabilities) String() string            { return proto.CompactTextString(m) }
+func (*ShimCapabilities) ProtoMessage()               {}
+func (*ShimCapabilities) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{2} }
+
+// ChaincodeNamespacePrefix isolates the state and the containers of the
+// chaincodes of a channel from those of the chaincodes of the same name on
+// the other channels of a peer
+type ChaincodeNamespacePrefix struct {
+	// The prefix, lower case letters and digits
+	Prefix string `protobuf:"bytes,1,opt,name=prefix" json:"prefix,omitempty"`
+}
+
+func (m *ChaincodeNamespacePrefix) Reset()                    { *m = ChaincodeNamespacePrefix{} }
+func (m *ChaincodeNamespacePrefix) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeNamespacePrefix) ProtoMessage()               {}
+func (*ChaincodeNamespacePrefix) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{3} }
+
 func init() {
 	proto.RegisterType((*AnchorPeers)(nil), "protos.AnchorPeers")
 	proto.RegisterType((*AnchorPeer)(nil), "protos.AnchorPeer")
+	proto.RegisterType((*ShimCapabilities)(nil), "protos.ShimCapabilities")
+	proto.RegisterType((*ChaincodeNamespacePrefix)(nil), "protos.ChaincodeNamespacePrefix")
 }
 
 func init() { proto.RegisterFile("peer/configuration.proto", fileDescriptor3) }
 
 var fileDescriptor3 = []byte{
-	// 191 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x44, 0x8f, 0x31, 0x4f, 0xc6, 0x20,
-	0x10, 0x86, 0x83, 0x55, 0x13, 0x69, 0x27, 0x26, 0x46, 0xd2, 0x09, 0x63, 0x52, 0x12, 0x8d, 0x3f,
-	0x40, 0xe3, 0xe0, 0x68, 0x18, 0x5d, 0x0c, 0xc5, 0x6b, 0x21, 0xd1, 0x1e, 0x39, 0xe8, 0xf0, 0xfd,
-	0xfb, 0x2f, 0xd0, 0xa1, 0x13, 0x0f, 0xef, 0xdd, 0x93, 0xbc, 0xc7, 0x65, 0x02, 0x20, 0xe3, 0x71,
-	0x5b, 0xe2, 0xba, 0x93, 0x2b, 0x11, 0xb7, 0x29, 0x11, 0x16, 0x14, 0xf7, 0xed, 0xc9, 0xe3, 0x07,
-	0xef, 0xdf, 0x36, 0x1f, 0x90, 0xbe, 0x00, 0x28, 0x8b, 0x57, 0x3e, 0xb8, 0xf6, 0xfd, 0xa9, 0x66,
-	0x96, 0x4c, 0x75, 0xba, 0x7f, 0x16, 0x87, 0x94, 0xa7, 0x73, 0xd5, 0xf6, 0xee, 0xd4, 0xc6, 0x4f,
-	0xce, 0xcf, 0x91, 0x10, 0xfc, 0x36, 0x60, 0x2e, 0x92, 0x29, 0xa6, 0x1f, 0x6c, 0xe3, 0x9a, 0x25,
-	0xa4, 0x22, 0x6f, 0x14, 0xd3, 0x77, 0xb6, 0x71, 0xcd, 0x3c, 0x50, 0x91, 0x9d, 0x62, 0x7a, 0xb0,
-	0x8d, 0xdf, 0x9f, 0xbe, 0x1f, 0xd7, 0x58, 0xc2, 0x3e, 0x4f, 0x1e, 0xff, 0x4d, 0xb8, 0x24, 0xa0,
-	0x3f, 0xf8, 0x5d, 0x81, 0xcc, 0xe2, 0x66, 0x8a, 0xde, 0x1c, 0x4d, 0x4c, 0xad, 0x37, 0x1f, 0x47,
-	0xbc, 0x5c, 0x03, 0x00, 0x00, 0xff, 0xff, 0x3a, 0x2c, 0x85, 0xf6, 0xe7, 0x00, 0x00, 0x00,
+	// 251 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x44, 0x90, 0x41, 0x4b, 0xfc, 0x30,
+	0x14, 0xc4, 0xe9, 0x7f, 0xff, 0x2e, 0x6e, 0xba, 0x07, 0xc9, 0x41, 0x82, 0xa7, 0xd2, 0x53, 0x45,
+	0x68, 0x61, 0xc5, 0x0f, 0xa0, 0xeb, 0xc1, 0x93, 0x2c, 0xf5, 0xe6, 0x45, 0x5e, 0xd3, 0xd7, 0xe6,
+	0x41, 0xdb, 0x84, 0x97, 0x2c, 0xe8, 0xb7, 0x97, 0xa6, 0x8b, 0x3d, 0xe5, 0x37, 0x93, 0x0c, 0x4c,
+	0x46, 0x28, 0x87, 0xc8, 0x95, 0xb6, 0x53, 0x47, 0xfd, 0x99, 0x21, 0x90, 0x9d, 0x4a, 0xc7, 0x36,
+	0x58, 0xb9, 0x8d, 0x87, 0xcf, 0x5f, 0x45, 0xfa, 0x3c, 0x69, 0x63, 0xf9, 0x84, 0xc8, 0x5e, 0x3e,
+	0x89, 0x3d, 0x44, 0xf9, 0x35, 0x27, 0xbd, 0x4a, 0xb2, 0x4d, 0x91, 0x1e, 0xe4, 0x12, 0xf2, 0xe5,
+	0xfa, 0xb4, 0x4e, 0x61, 0x8d, 0xe5, 0x6f, 0x42, 0xac, 0x57, 0x52, 0x8a, 0xff, 0xc6, 0xfa, 0xa0,
+	0x92, 0x2c, 0x29, 0x76, 0x75, 0xe4, 0xd9, 0x73, 0x96, 0x83, 0xfa, 0x97, 0x25, 0xc5, 0x55, 0x1d,
+	0x79, 0xf6, 0x34, 0x72, 0x50, 0x9b, 0x2c, 0x29, 0xf6, 0x75, 0xe4, 0xbc, 0x14, 0x37, 0x1f, 0x86,
+	0xc6, 0x23, 0x38, 0x68, 0x68, 0xa0, 0x40, 0xe8, 0xe5, 0x9d, 0xb8, 0x6e, 0xc9, 0x43, 0x33, 0x60,
+	0x1b, 0x0b, 0xed, 0xea, 0x3f, 0x9d, 0x1f, 0x84, 0x3a, 0x1a, 0xa0, 0x49, 0xdb, 0x16, 0xdf, 0x61,
+	0x44, 0xef, 0x40, 0xe3, 0x89, 0xb1, 0xa3, 0x6f, 0x79, 0x2b, 0xb6, 0x2e, 0xd2, 0xa5, 0xc9, 0x45,
+	0xbd, 0x3c, 0x7c, 0xde, 0xf7, 0x14, 0xcc, 0xb9, 0x29, 0xb5, 0x1d, 0x2b, 0xf3, 0xe3, 0x90, 0x07,
+	0x6c, 0x7b, 0xe4, 0xaa, 0x83, 0x86, 0x49, 0x57, 0xcb, 0x6f, 0xab, 0x79, 0x82, 0x66, 0x19, 0xea,
+	0xf1, 0x77, 0x00, 0x81, 0x50, 0x24, 0xfc, 0x4b, 0x01, 0x00, 0x00,
 }
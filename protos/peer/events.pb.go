@@ -236,6 +236,8 @@ type Event struct {
 	Event isEvent_Event `protobuf_oneof:"Event"`
 	// Creator of the event, specified as a certificate chain
 	Creator []byte `protobuf:"bytes,6,opt,name=creator,proto3" json:"creator,omitempty"`
+	// Integrity proof of the event, set by producers configured to attach one
+	Integrity *common.IntegrityProof `protobuf:"bytes,7,opt,name=integrity" json:"integrity,omitempty"`
 }
 
 func (m *Event) Reset()                    { *m = Event{} }
@@ -311,6 +313,13 @@ func (m *Event) GetUnregister() *Unregister {
 	return nil
 }
 
+func (m *Event) GetIntegrity() *common.IntegrityProof {
+	if m != nil {
+		return m.Integrity
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Event) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Event_OneofMarshaler, _Event_OneofUnmarshaler, _Event_OneofSizer, []interface{}{
@@ -562,42 +571,43 @@ var _Events_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("peer/events.proto", fileDescriptor4) }
 
 var fileDescriptor4 = []byte{
-	// 587 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x54, 0x4f, 0x6f, 0xd3, 0x4e,
-	0x10, 0xb5, 0xd3, 0x26, 0x8d, 0x27, 0x69, 0x7f, 0xe9, 0xf6, 0xa7, 0xca, 0x94, 0x3f, 0x2a, 0x46,
-	0x48, 0xa1, 0x48, 0x49, 0x09, 0x15, 0xf7, 0xda, 0xb5, 0xb0, 0x29, 0x6d, 0xd1, 0x36, 0x5c, 0xb8,
-	0x44, 0x8e, 0x33, 0x75, 0x0c, 0x8d, 0x1d, 0xad, 0xb7, 0xa8, 0xf9, 0x44, 0x9c, 0xf8, 0x8e, 0xc8,
-	0x63, 0xaf, 0x9d, 0x88, 0x13, 0x27, 0x67, 0xde, 0xbc, 0xb7, 0xfb, 0xf6, 0xcd, 0x6e, 0x60, 0x7f,
-	0x89, 0x28, 0x86, 0xf8, 0x13, 0x13, 0x99, 0x0d, 0x96, 0x22, 0x95, 0x29, 0x6b, 0xd1, 0x27, 0x3b,
-	0x3a, 0x08, 0xd3, 0xc5, 0x22, 0x4d, 0x86, 0xc5, 0xa7, 0x68, 0x1e, 0x3d, 0x21, 0x7e, 0x38, 0x0f,
-	0xe2, 0x24, 0x4c, 0x67, 0x48, 0xc2, 0xb2, 0x75, 0x48, 0x2d, 0x29, 0x82, 0x24, 0x0b, 0x42, 0x19,
-	0x2b, 0x89, 0xf5, 0x05, 0xba, 0x8e, 0xe2, 0x73, 0x8c, 0xd8, 0x4b, 0xe8, 0x56, 0xfa, 0x49, 0x3c,
-	0x33, 0xf5, 0x63, 0xbd, 0x6f, 0xf0, 0x4e, 0x85, 0xf9, 0x33, 0xf6, 0x1c, 0x80, 0x56, 0x9e, 0x24,
-	0xc1, 0x02, 0xcd, 0x06, 0x11, 0x0c, 0x42, 0xae, 0x83, 0x05, 0x5a, 0xbf, 0x74, 0x68, 0xfb, 0x89,
-	0x44, 0x81, 0x99, 0x64, 0xa7, 0x8a, 0x2b, 0x57, 0x4b, 0xa4, 0xc5, 0xf6, 0x46, 0xfb, 0xc5, 0xd6,
-	0xd9, 0xc0, 0xcd, 0x3b, 0xe3, 0xd5, 0x12, 0x4b, 0x79, 0xfe, 0x93, 0x5d, 0x00, 0xab, 0x0d, 0x08,
-	0x8c, 0x26, 0x71, 0x72, 0x97, 0xd2, 0x2e, 0x9d, 0xd1, 0xff, 0x4a, 0xb9, 0x6e, 0xd9, 0xd3, 0x78,
-	0x2f, 0x5c, 0xab, 0xfd, 0xe4, 0x2e, 0x65, 0x26, 0xec, 0x10, 0xe6, 0x5f, 0x98, 0x5b, 0x64, 0x50,
-	0x95, 0xb6, 0x01, 0x3b, 0x25, 0xc9, 0x3a, 0x83, 0x36, 0xc7, 0x28, 0xce, 0x24, 0x0a, 0xd6, 0x87,
-	0x56, 0x91, 0xb3, 0xa9, 0x1f, 0x6f, 0xf5, 0x3b, 0xa3, 0x9e, 0xda, 0x4a, 0x1d, 0x85, 0x97, 0x7d,
-	0xeb, 0x0a, 0x0c, 0x8e, 0xdf, 0x91, 0x42, 0x64, 0xaf, 0xa0, 0x21, 0x1f, 0xe9, 0x5c, 0x9d, 0xd1,
-	0x81, 0x92, 0x8c, 0xeb, 0x94, 0x79, 0x43, 0x3e, 0xb2, 0xa7, 0x60, 0xa0, 0x10, 0xa9, 0x98, 0x2c,
-	0xb2, 0xa8, 0xcc, 0xab, 0x4d, 0xc0, 0x55, 0x16, 0x59, 0x1f, 0x00, 0xbe, 0x26, 0xe2, 0xdf, 0x6d,
-	0x5c, 0x42, 0xe7, 0x36, 0x8e, 0x12, 0x9c, 0x51, 0x8a, 0xec, 0x19, 0x18, 0x59, 0x1c, 0x25, 0x81,
-	0x7c, 0x10, 0x45, 0xce, 0x5d, 0x5e, 0x03, 0xec, 0x45, 0x39, 0x06, 0x7b, 0x25, 0x31, 0x23, 0x0b,
-	0x5d, 0xbe, 0x86, 0x58, 0xbf, 0x1b, 0xd0, 0x2c, 0xd6, 0x19, 0x40, 0x5b, 0x99, 0x29, 0x8f, 0x55,
-	0x59, 0x50, 0x59, 0x79, 0x1a, 0xaf, 0x38, 0xec, 0x35, 0x34, 0xa7, 0xf7, 0x69, 0xf8, 0xa3, 0x9c,
-	0xd0, 0xee, 0xa0, 0xbc, 0x90, 0x76, 0x0e, 0x7a, 0x1a, 0x2f, 0xba, 0xec, 0x1c, 0xfe, 0xab, 0xa7,
-	0x4a, 0x1b, 0xd3, 0x5c, 0x3a, 0xa3, 0xc3, 0xbf, 0x46, 0x4a, 0x3e, 0x3c, 0x8d, 0xef, 0x85, 0x1b,
-	0x08, 0x7b, 0x07, 0x86, 0x50, 0xb9, 0x9b, 0xdb, 0x24, 0xde, 0xaf, 0xad, 0x95, 0x0d, 0x4f, 0xe3,
-	0x35, 0x8b, 0x9d, 0x01, 0x3c, 0x54, 0xd9, 0x9a, 0x4d, 0xd2, 0x30, 0xa5, 0xa9, 0x53, 0xf7, 0x34,
-	0xbe, 0xc6, 0xa3, 0xbb, 0x23, 0x30, 0x90, 0xa9, 0x30, 0x5b, 0x94, 0x94, 0x2a, 0xed, 0x9d, 0x32,
-	0xa5, 0x13, 0x1b, 0x8c, 0xea, 0xf2, 0xb2, 0x2e, 0xb4, 0xb9, 0xfb, 0xd1, 0xbf, 0x1d, 0xbb, 0xbc,
-	0xa7, 0x31, 0x03, 0x9a, 0xf6, 0xe7, 0x1b, 0xe7, 0xb2, 0xa7, 0xb3, 0x5d, 0x30, 0x1c, 0xef, 0xdc,
-	0xbf, 0x76, 0x6e, 0x2e, 0xdc, 0x5e, 0x23, 0x2f, 0xb9, 0xfb, 0xc9, 0x75, 0xc6, 0xfe, 0xcd, 0x75,
-	0x6f, 0x6b, 0x74, 0x06, 0x2d, 0x5a, 0x23, 0x63, 0x27, 0xb0, 0xed, 0xcc, 0x03, 0xc9, 0x76, 0x37,
-	0x1e, 0xc6, 0xd1, 0x66, 0x69, 0x69, 0x7d, 0xfd, 0x54, 0xb7, 0xdf, 0x7e, 0x7b, 0x13, 0xc5, 0x72,
-	0xfe, 0x30, 0xcd, 0x83, 0x1e, 0xce, 0x57, 0x4b, 0x14, 0xf7, 0x38, 0x8b, 0x50, 0x0c, 0xef, 0x82,
-	0xa9, 0x88, 0xc3, 0x61, 0xa1, 0x19, 0xe6, 0xcf, 0x7d, 0x5a, 0xfc, 0x59, 0xbc, 0xff, 0x13, 0x00,
-	0x00, 0xff, 0xff, 0x82, 0xc7, 0xec, 0x4d, 0x48, 0x04, 0x00, 0x00,
+	// 606 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xdb, 0x6e, 0xda, 0x40,
+	0x10, 0xb5, 0x49, 0xb8, 0x78, 0x20, 0x29, 0xd9, 0x54, 0x91, 0x9b, 0x5e, 0x94, 0xba, 0xaa, 0x44,
+	0x53, 0x09, 0x52, 0x8a, 0xfa, 0x1e, 0x13, 0x54, 0xbb, 0x69, 0x2e, 0xda, 0xd0, 0x97, 0xbe, 0x20,
+	0x63, 0x06, 0xe3, 0x36, 0xd8, 0x68, 0xbd, 0xa9, 0xc2, 0x17, 0xf5, 0xc3, 0xfa, 0x23, 0x15, 0xe3,
+	0x5d, 0x0c, 0xea, 0x53, 0x9f, 0x96, 0x99, 0x73, 0xce, 0xee, 0xd9, 0x33, 0x8b, 0xe1, 0x60, 0x81,
+	0x28, 0x3a, 0xf8, 0x0b, 0x13, 0x99, 0xb5, 0x17, 0x22, 0x95, 0x29, 0xab, 0xd0, 0x92, 0x1d, 0x1f,
+	0x86, 0xe9, 0x7c, 0x9e, 0x26, 0x9d, 0x7c, 0xc9, 0xc1, 0xe3, 0x67, 0xc4, 0x0f, 0x67, 0x41, 0x9c,
+	0x84, 0xe9, 0x04, 0x49, 0xa8, 0xa0, 0x23, 0x82, 0xa4, 0x08, 0x92, 0x2c, 0x08, 0x65, 0xac, 0x25,
+	0xce, 0x2d, 0x34, 0xfa, 0x9a, 0xcf, 0x31, 0x62, 0xaf, 0xa1, 0xb1, 0xd6, 0x8f, 0xe2, 0x89, 0x6d,
+	0x9e, 0x98, 0x2d, 0x8b, 0xd7, 0xd7, 0x3d, 0x7f, 0xc2, 0x5e, 0x02, 0xd0, 0xce, 0xa3, 0x24, 0x98,
+	0xa3, 0x5d, 0x22, 0x82, 0x45, 0x9d, 0xeb, 0x60, 0x8e, 0xce, 0x6f, 0x13, 0x6a, 0x7e, 0x22, 0x51,
+	0x60, 0x26, 0xd9, 0x99, 0xe6, 0xca, 0xe5, 0x02, 0x69, 0xb3, 0xfd, 0xee, 0x41, 0x7e, 0x74, 0xd6,
+	0x1e, 0xac, 0x90, 0xe1, 0x72, 0x81, 0x4a, 0xbe, 0xfa, 0xc9, 0x2e, 0x80, 0x15, 0x06, 0x04, 0x46,
+	0xa3, 0x38, 0x99, 0xa6, 0x74, 0x4a, 0xbd, 0xfb, 0x54, 0x2b, 0x37, 0x2d, 0x7b, 0x06, 0x6f, 0x86,
+	0x1b, 0xb5, 0x9f, 0x4c, 0x53, 0x66, 0x43, 0x95, 0x7a, 0xfe, 0x85, 0xbd, 0x43, 0x06, 0x75, 0xe9,
+	0x5a, 0x50, 0x55, 0x24, 0xa7, 0x07, 0x35, 0x8e, 0x51, 0x9c, 0x49, 0x14, 0xac, 0x05, 0x95, 0x3c,
+	0x67, 0xdb, 0x3c, 0xd9, 0x69, 0xd5, 0xbb, 0x4d, 0x7d, 0x94, 0xbe, 0x0a, 0x57, 0xb8, 0x73, 0x05,
+	0x16, 0xc7, 0x1f, 0x48, 0x21, 0xb2, 0x37, 0x50, 0x92, 0x8f, 0x74, 0xaf, 0x7a, 0xf7, 0x50, 0x4b,
+	0x86, 0x45, 0xca, 0xbc, 0x24, 0x1f, 0xd9, 0x73, 0xb0, 0x50, 0x88, 0x54, 0x8c, 0xe6, 0x59, 0xa4,
+	0xf2, 0xaa, 0x51, 0xe3, 0x2a, 0x8b, 0x9c, 0x4f, 0x00, 0xdf, 0x12, 0xf1, 0xff, 0x36, 0x2e, 0xa1,
+	0x7e, 0x17, 0x47, 0x09, 0x4e, 0x28, 0x45, 0xf6, 0x02, 0xac, 0x2c, 0x8e, 0x92, 0x40, 0x3e, 0x88,
+	0x3c, 0xe7, 0x06, 0x2f, 0x1a, 0xec, 0x95, 0x1a, 0x83, 0xbb, 0x94, 0x98, 0x91, 0x85, 0x06, 0xdf,
+	0xe8, 0x38, 0x7f, 0x4a, 0x50, 0xce, 0xf7, 0x69, 0x43, 0x4d, 0x9b, 0x51, 0xd7, 0x5a, 0x5b, 0xd0,
+	0x59, 0x79, 0x06, 0x5f, 0x73, 0xd8, 0x5b, 0x28, 0x8f, 0xef, 0xd3, 0xf0, 0xa7, 0x9a, 0xd0, 0x5e,
+	0x5b, 0x3d, 0x48, 0x77, 0xd5, 0xf4, 0x0c, 0x9e, 0xa3, 0xec, 0x1c, 0x9e, 0x14, 0x53, 0xa5, 0x83,
+	0x69, 0x2e, 0xf5, 0xee, 0xd1, 0x3f, 0x23, 0x25, 0x1f, 0x9e, 0xc1, 0xf7, 0xc3, 0xad, 0x0e, 0xfb,
+	0x00, 0x96, 0xd0, 0xb9, 0xdb, 0xbb, 0x24, 0x3e, 0x28, 0xac, 0x29, 0xc0, 0x33, 0x78, 0xc1, 0x62,
+	0x3d, 0x80, 0x87, 0x75, 0xb6, 0x76, 0x99, 0x34, 0x4c, 0x6b, 0x8a, 0xd4, 0x3d, 0x83, 0x6f, 0xf0,
+	0xe8, 0xed, 0x08, 0x0c, 0x64, 0x2a, 0xec, 0x0a, 0x25, 0xa5, 0x4b, 0xd6, 0x03, 0x2b, 0x4e, 0x24,
+	0x46, 0x22, 0x96, 0x4b, 0xbb, 0xaa, 0xfc, 0xab, 0x0b, 0xfb, 0x1a, 0xb8, 0x15, 0x69, 0x3a, 0xe5,
+	0x05, 0xd1, 0xad, 0xaa, 0x6c, 0x4f, 0x5d, 0xb0, 0xd6, 0x4f, 0x9e, 0x35, 0xa0, 0xc6, 0x07, 0x9f,
+	0xfd, 0xbb, 0xe1, 0x80, 0x37, 0x0d, 0x66, 0x41, 0xd9, 0xfd, 0x7a, 0xd3, 0xbf, 0x6c, 0x9a, 0x6c,
+	0x0f, 0xac, 0xbe, 0x77, 0xee, 0x5f, 0xf7, 0x6f, 0x2e, 0x06, 0xcd, 0xd2, 0xaa, 0xe4, 0x83, 0x2f,
+	0x83, 0xfe, 0xd0, 0xbf, 0xb9, 0x6e, 0xee, 0x74, 0x7b, 0x50, 0xa1, 0x3d, 0x32, 0x76, 0x0a, 0xbb,
+	0xfd, 0x59, 0x20, 0xd9, 0xde, 0xd6, 0xdf, 0xe9, 0x78, 0xbb, 0x74, 0x8c, 0x96, 0x79, 0x66, 0xba,
+	0xef, 0xbf, 0xbf, 0x8b, 0x62, 0x39, 0x7b, 0x18, 0xaf, 0xdc, 0x76, 0x66, 0xcb, 0x05, 0x8a, 0x7b,
+	0x9c, 0x44, 0x28, 0x3a, 0xd3, 0x60, 0x2c, 0xe2, 0xb0, 0x93, 0x6b, 0x3a, 0x0b, 0x44, 0x31, 0xce,
+	0x3f, 0x31, 0x1f, 0xff, 0x0e, 0x00, 0x4e, 0x10, 0x20, 0xc6, 0x7e, 0x04, 0x00, 0x00,
 }
@@ -0,0 +1,239 @@
+// Code generated by protoc-gen-go.
+// source: peer/gateway.proto
+// DO NOT EDIT!
+
+package peer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type SubmitResponse_Status int32
+
+const (
+	SubmitResponse_SIGN    SubmitResponse_Status = 0
+	SubmitResponse_RETRY   SubmitResponse_Status = 1
+	SubmitResponse_VALID   SubmitResponse_Status = 2
+	SubmitResponse_INVALID SubmitResponse_Status = 3
+)
+
+var SubmitResponse_Status_name = map[int32]string{
+	0: "SIGN",
+	1: "RETRY",
+	2: "VALID",
+	3: "INVALID",
+}
+var SubmitResponse_Status_value = map[string]int32{
+	"SIGN":    0,
+	"RETRY":   1,
+	"VALID":   2,
+	"INVALID": 3,
+}
+
+func (x SubmitResponse_Status) String() string {
+	return proto.EnumName(SubmitResponse_Status_name, int32(x))
+}
+func (SubmitResponse_Status) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{1, 0} }
+
+// SubmitRequest is sent by a client submitting a transaction through the
+// gateway: a signed proposal to start each attempt, and then the signed
+// transaction the gateway asked for
+type SubmitRequest struct {
+	Proposal    *SignedProposal  `protobuf:"bytes,1,opt,name=proposal" json:"proposal,omitempty"`
+	Transaction *common.Envelope `protobuf:"bytes,2,opt,name=transaction" json:"transaction,omitempty"`
+}
+
+func (m *SubmitRequest) Reset()                    { *m = SubmitRequest{} }
+func (m *SubmitRequest) String() string            { return proto.CompactTextString(m) }
+func (*SubmitRequest) ProtoMessage()               {}
+func (*SubmitRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{0} }
+
+func (m *SubmitRequest) GetProposal() *SignedProposal {
+	if m != nil {
+		return m.Proposal
+	}
+	return nil
+}
+
+func (m *SubmitRequest) GetTransaction() *common.Envelope {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
+}
+
+// SubmitResponse tells the client what the gateway expects next, or how
+// its transaction ended
+type SubmitResponse struct {
+	Status      SubmitResponse_Status `protobuf:"varint,1,opt,name=status,enum=protos.SubmitResponse_Status" json:"status,omitempty"`
+	TxId        string                `protobuf:"bytes,2,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
+	Attempt     uint32                `protobuf:"varint,3,opt,name=attempt" json:"attempt,omitempty"`
+	Payload     []byte                `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	BlockNumber uint64                `protobuf:"varint,5,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	Conflict    *MVCCConflict         `protobuf:"bytes,6,opt,name=conflict" json:"conflict,omitempty"`
+}
+
+func (m *SubmitResponse) Reset()                    { *m = SubmitResponse{} }
+func (m *SubmitResponse) String() string            { return proto.CompactTextString(m) }
+func (*SubmitResponse) ProtoMessage()               {}
+func (*SubmitResponse) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{1} }
+
+func (m *SubmitResponse) GetConflict() *MVCCConflict {
+	if m != nil {
+		return m.Conflict
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*SubmitRequest)(nil), "protos.SubmitRequest")
+	proto.RegisterType((*SubmitResponse)(nil), "protos.SubmitResponse")
+	proto.RegisterEnum("protos.SubmitResponse_Status", SubmitResponse_Status_name, SubmitResponse_Status_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion3
+
+// Client API for Gateway service
+
+type GatewayClient interface {
+	Submit(ctx context.Context, opts ...grpc.CallOption) (Gateway_SubmitClient, error)
+}
+
+type gatewayClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewGatewayClient(cc *grpc.ClientConn) GatewayClient {
+	return &gatewayClient{cc}
+}
+
+func (c *gatewayClient) Submit(ctx context.Context, opts ...grpc.CallOption) (Gateway_SubmitClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Gateway_serviceDesc.Streams[0], c.cc, "/protos.Gateway/Submit", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gatewaySubmitClient{stream}
+	return x, nil
+}
+
+type Gateway_SubmitClient interface {
+	Send(*SubmitRequest) error
+	Recv() (*SubmitResponse, error)
+	grpc.ClientStream
+}
+
+type gatewaySubmitClient struct {
+	grpc.ClientStream
+}
+
+func (x *gatewaySubmitClient) Send(m *SubmitRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *gatewaySubmitClient) Recv() (*SubmitResponse, error) {
+	m := new(SubmitResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Gateway service
+
+type GatewayServer interface {
+	Submit(Gateway_SubmitServer) error
+}
+
+func RegisterGatewayServer(s *grpc.Server, srv GatewayServer) {
+	s.RegisterService(&_Gateway_serviceDesc, srv)
+}
+
+func _Gateway_Submit_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GatewayServer).Submit(&gatewaySubmitServer{stream})
+}
+
+type Gateway_SubmitServer interface {
+	Send(*SubmitResponse) error
+	Recv() (*SubmitRequest, error)
+	grpc.ServerStream
+}
+
+type gatewaySubmitServer struct {
+	grpc.ServerStream
+}
+
+func (x *gatewaySubmitServer) Send(m *SubmitResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *gatewaySubmitServer) Recv() (*SubmitRequest, error) {
+	m := new(SubmitRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Gateway_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.Gateway",
+	HandlerType: (*GatewayServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Submit",
+			Handler:       _Gateway_Submit_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: fileDescriptor5,
+}
+
+func init() { proto.RegisterFile("peer/gateway.proto", fileDescriptor5) }
+
+var fileDescriptor5 = []byte{
+	// 406 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0x51, 0x6f, 0xd3, 0x30,
+	0x10, 0xc7, 0xe7, 0xae, 0x4d, 0xbb, 0xeb, 0x36, 0x55, 0x2e, 0x54, 0x51, 0x25, 0xa4, 0x92, 0xa7,
+	0x20, 0xa4, 0x64, 0x0a, 0xda, 0x23, 0x0f, 0x50, 0xa6, 0x11, 0x09, 0x2a, 0xe4, 0xa0, 0x49, 0xf0,
+	0x32, 0x39, 0x89, 0x97, 0x45, 0x24, 0xb1, 0xb1, 0x1d, 0xb6, 0x7e, 0x17, 0x3e, 0x2c, 0x8a, 0x9d,
+	0x54, 0x03, 0xf1, 0xe4, 0xdc, 0xdd, 0xef, 0x9f, 0xff, 0xf9, 0xce, 0x80, 0x05, 0x63, 0x32, 0x2c,
+	0xa8, 0x66, 0x0f, 0x74, 0x1f, 0x08, 0xc9, 0x35, 0xc7, 0x8e, 0x39, 0xd4, 0x7a, 0x99, 0xf1, 0xba,
+	0xe6, 0x4d, 0x68, 0x0f, 0x5b, 0x5c, 0x2f, 0x8d, 0x40, 0x48, 0x2e, 0xb8, 0xa2, 0x55, 0x9f, 0x5c,
+	0x99, 0xa4, 0x96, 0xb4, 0x51, 0x34, 0xd3, 0xe5, 0x00, 0x7b, 0x0f, 0x70, 0x96, 0xb4, 0x69, 0x5d,
+	0x6a, 0xc2, 0x7e, 0xb6, 0x4c, 0x69, 0x1c, 0xc1, 0x6c, 0x90, 0xba, 0x68, 0x83, 0xfc, 0x79, 0xb4,
+	0xb2, 0xa8, 0x0a, 0x92, 0xb2, 0x68, 0x58, 0xfe, 0xa5, 0xaf, 0x92, 0x03, 0x87, 0x23, 0x98, 0x3f,
+	0xf9, 0xb3, 0x3b, 0x32, 0xb2, 0x45, 0xd0, 0x77, 0x75, 0xd5, 0xfc, 0x62, 0x15, 0x17, 0x8c, 0x3c,
+	0x85, 0xbc, 0xdf, 0x23, 0x38, 0x1f, 0x9c, 0x95, 0xe0, 0x8d, 0x62, 0xf8, 0x12, 0x1c, 0xa5, 0xa9,
+	0x6e, 0x95, 0x31, 0x3e, 0x8f, 0x5e, 0x1c, 0x8c, 0xff, 0xe2, 0x82, 0xc4, 0x40, 0xa4, 0x87, 0xf1,
+	0x12, 0x26, 0xfa, 0xf1, 0xb6, 0xcc, 0x8d, 0xef, 0x09, 0x19, 0xeb, 0xc7, 0x38, 0xc7, 0x2e, 0x4c,
+	0xa9, 0xd6, 0xac, 0x16, 0xda, 0x3d, 0xde, 0x20, 0xff, 0x8c, 0x0c, 0x61, 0x57, 0x11, 0x74, 0x5f,
+	0x71, 0x9a, 0xbb, 0xe3, 0x0d, 0xf2, 0x4f, 0xc9, 0x10, 0xe2, 0x97, 0x70, 0x9a, 0x56, 0x3c, 0xfb,
+	0x71, 0xdb, 0xb4, 0x75, 0xca, 0xa4, 0x3b, 0xd9, 0x20, 0x7f, 0x4c, 0xe6, 0x26, 0xb7, 0x33, 0x29,
+	0x7c, 0x01, 0xb3, 0x8c, 0x37, 0x77, 0x55, 0x99, 0x69, 0xd7, 0x31, 0xd7, 0x7c, 0x36, 0x34, 0xf9,
+	0xf9, 0x66, 0xbb, 0xdd, 0xf6, 0x35, 0x72, 0xa0, 0xbc, 0x4b, 0x70, 0x6c, 0xbf, 0x78, 0x06, 0xe3,
+	0x24, 0xbe, 0xde, 0x2d, 0x8e, 0xf0, 0x09, 0x4c, 0xc8, 0xd5, 0x57, 0xf2, 0x6d, 0x81, 0xba, 0xcf,
+	0x9b, 0x77, 0x9f, 0xe2, 0x0f, 0x8b, 0x11, 0x9e, 0xc3, 0x34, 0xde, 0xd9, 0xe0, 0x38, 0xfa, 0x08,
+	0xd3, 0x6b, 0xbb, 0x72, 0xfc, 0x16, 0x1c, 0x3b, 0x00, 0xfc, 0xfc, 0xdf, 0x81, 0x98, 0x95, 0xad,
+	0x57, 0xff, 0x9f, 0x93, 0x77, 0xe4, 0xa3, 0x0b, 0xf4, 0xfe, 0xf5, 0xf7, 0x57, 0x45, 0xa9, 0xef,
+	0xdb, 0xb4, 0xdb, 0x47, 0x78, 0xbf, 0x17, 0x4c, 0x56, 0x2c, 0x2f, 0x98, 0x0c, 0xef, 0x68, 0x2a,
+	0xcb, 0x2c, 0xb4, 0xe2, 0xb0, 0x7b, 0x20, 0xa9, 0x7d, 0x58, 0x6f, 0xfe, 0x0c, 0x00, 0x54, 0x6e,
+	0x30, 0x62, 0x75, 0x02, 0x00, 0x00,
+}
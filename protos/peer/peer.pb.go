@@ -25,7 +25,7 @@ type PeerID struct {
 func (m *PeerID) Reset()                    { *m = PeerID{} }
 func (m *PeerID) String() string            { return proto.CompactTextString(m) }
 func (*PeerID) ProtoMessage()               {}
-func (*PeerID) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{0} }
+func (*PeerID) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{0} }
 
 type PeerEndpoint struct {
 	Id      *PeerID `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
@@ -35,7 +35,7 @@ type PeerEndpoint struct {
 func (m *PeerEndpoint) Reset()                    { *m = PeerEndpoint{} }
 func (m *PeerEndpoint) String() string            { return proto.CompactTextString(m) }
 func (*PeerEndpoint) ProtoMessage()               {}
-func (*PeerEndpoint) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{1} }
+func (*PeerEndpoint) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{1} }
 
 func (m *PeerEndpoint) GetId() *PeerID {
 	if m != nil {
@@ -44,9 +44,40 @@ func (m *PeerEndpoint) GetId() *PeerID {
 	return nil
 }
 
+// EndorsementLayout lists peers whose endorsements together satisfy the
+// endorsement policy of a chaincode
+type EndorsementLayout struct {
+	Endorsers []*EndorsingPeer `protobuf:"bytes,1,rep,name=endorsers" json:"endorsers,omitempty"`
+}
+
+func (m *EndorsementLayout) Reset()                    { *m = EndorsementLayout{} }
+func (m *EndorsementLayout) String() string            { return proto.CompactTextString(m) }
+func (*EndorsementLayout) ProtoMessage()               {}
+func (*EndorsementLayout) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{2} }
+
+func (m *EndorsementLayout) GetEndorsers() []*EndorsingPeer {
+	if m != nil {
+		return m.Endorsers
+	}
+	return nil
+}
+
+// EndorsingPeer is a peer which endorses proposals, with the MSP it belongs to
+type EndorsingPeer struct {
+	Endpoint string `protobuf:"bytes,1,opt,name=endpoint" json:"endpoint,omitempty"`
+	MspId    string `protobuf:"bytes,2,opt,name=msp_id,json=mspId" json:"msp_id,omitempty"`
+}
+
+func (m *EndorsingPeer) Reset()                    { *m = EndorsingPeer{} }
+func (m *EndorsingPeer) String() string            { return proto.CompactTextString(m) }
+func (*EndorsingPeer) ProtoMessage()               {}
+func (*EndorsingPeer) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{3} }
+
 func init() {
 	proto.RegisterType((*PeerID)(nil), "protos.PeerID")
 	proto.RegisterType((*PeerEndpoint)(nil), "protos.PeerEndpoint")
+	proto.RegisterType((*EndorsementLayout)(nil), "protos.EndorsementLayout")
+	proto.RegisterType((*EndorsingPeer)(nil), "protos.EndorsingPeer")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -118,26 +149,30 @@ var _Endorser_serviceDesc = grpc.ServiceDesc{
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
-	Metadata: fileDescriptor5,
-}
-
-func init() { proto.RegisterFile("peer/peer.proto", fileDescriptor5) }
-
-var fileDescriptor5 = []byte{
-	// 234 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x54, 0x90, 0x4f, 0x4b, 0xc3, 0x40,
-	0x10, 0xc5, 0x6d, 0x90, 0xaa, 0xa3, 0x58, 0x58, 0x41, 0x42, 0x28, 0x22, 0x39, 0x29, 0x42, 0x02,
-	0xf5, 0x1b, 0x88, 0x01, 0xbd, 0xc5, 0x78, 0xf3, 0x22, 0x49, 0x66, 0x4c, 0x17, 0xda, 0x9d, 0x65,
-	0x26, 0x1e, 0xfc, 0xf6, 0xd2, 0xdd, 0x44, 0xf0, 0xb2, 0x7f, 0xde, 0x6f, 0xe7, 0xed, 0xe3, 0xc1,
-	0xca, 0x13, 0x49, 0x79, 0x58, 0x0a, 0x2f, 0x3c, 0xb2, 0x59, 0x86, 0x4d, 0xb3, 0xab, 0x08, 0x84,
-	0x3d, 0x6b, 0xbb, 0x8b, 0x30, 0x5b, 0xff, 0x13, 0x3f, 0x85, 0xd4, 0xb3, 0x53, 0x8a, 0x34, 0x5f,
-	0xc3, 0xb2, 0x26, 0x92, 0xd7, 0x67, 0x63, 0xe0, 0xd8, 0xb5, 0x7b, 0x4a, 0x17, 0xb7, 0x8b, 0xbb,
-	0xb3, 0x26, 0x9c, 0xf3, 0x17, 0xb8, 0x38, 0xd0, 0xca, 0xa1, 0x67, 0xeb, 0x46, 0x73, 0x03, 0x89,
-	0xc5, 0xf0, 0xe2, 0x7c, 0x73, 0x19, 0x1d, 0xb4, 0x88, 0xf3, 0x4d, 0x62, 0xd1, 0xa4, 0x70, 0xd2,
-	0x22, 0x0a, 0xa9, 0xa6, 0x49, 0xb0, 0x99, 0xaf, 0x9b, 0x37, 0x38, 0xad, 0x1c, 0xb2, 0x28, 0x89,
-	0xa9, 0x60, 0x55, 0x0b, 0xf7, 0xa4, 0x5a, 0x4f, 0xa9, 0xcc, 0xf5, 0x6c, 0xf6, 0x6e, 0x07, 0x47,
-	0x38, 0xeb, 0x59, 0xfa, 0xf7, 0xc9, 0xa4, 0x34, 0x53, 0xfc, 0xfc, 0xe8, 0xe9, 0xe1, 0xe3, 0x7e,
-	0xb0, 0xe3, 0xf6, 0xbb, 0x2b, 0x7a, 0xde, 0x97, 0xdb, 0x1f, 0x4f, 0xb2, 0x23, 0x1c, 0x48, 0xca,
-	0xaf, 0xb6, 0x13, 0xdb, 0x97, 0x71, 0x34, 0x14, 0xd5, 0xc5, 0x8a, 0x1e, 0x7f, 0x03, 0x00, 0x00,
-	0xff, 0xff, 0x38, 0x9b, 0xb5, 0xcd, 0x3c, 0x01, 0x00, 0x00,
+	Metadata: fileDescriptor6,
+}
+
+func init() { proto.RegisterFile("peer/peer.proto", fileDescriptor6) }
+
+var fileDescriptor6 = []byte{
+	// 297 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x51, 0xc1, 0x6a, 0xc2, 0x40,
+	0x10, 0xad, 0xb6, 0xb5, 0x3a, 0xb6, 0x95, 0x6e, 0xb1, 0x84, 0x20, 0x45, 0x72, 0xb2, 0x14, 0x0c,
+	0xe8, 0x1f, 0x48, 0x05, 0x85, 0x1e, 0x6c, 0x7a, 0xeb, 0x45, 0x62, 0x76, 0x1a, 0x17, 0xcc, 0xee,
+	0x32, 0x13, 0x0f, 0xfe, 0x7d, 0x49, 0x76, 0x63, 0xf1, 0xb2, 0xbb, 0xf3, 0xde, 0x9b, 0xc7, 0xdb,
+	0x19, 0x18, 0x58, 0x44, 0x8a, 0xab, 0x63, 0x6a, 0xc9, 0x94, 0x46, 0x74, 0xea, 0x8b, 0xc3, 0x67,
+	0x47, 0x90, 0xb1, 0x86, 0xd3, 0x83, 0x23, 0xc3, 0xd1, 0x05, 0xb8, 0x25, 0x64, 0x6b, 0x34, 0xa3,
+	0x63, 0xa3, 0x11, 0x74, 0x36, 0x88, 0xb4, 0xfe, 0x10, 0x02, 0x6e, 0x74, 0x5a, 0x60, 0xd0, 0x1a,
+	0xb7, 0x26, 0xbd, 0xa4, 0x7e, 0x47, 0x2b, 0xb8, 0xaf, 0xd8, 0xa5, 0x96, 0xd6, 0x28, 0x5d, 0x8a,
+	0x57, 0x68, 0x2b, 0x59, 0x2b, 0xfa, 0xb3, 0x47, 0xe7, 0xc0, 0x53, 0xd7, 0x9f, 0xb4, 0x95, 0x14,
+	0x01, 0xdc, 0xa5, 0x52, 0x12, 0x32, 0x07, 0xed, 0xda, 0xa6, 0x29, 0xa3, 0x15, 0x3c, 0x2d, 0xb5,
+	0x34, 0xc4, 0x58, 0xa0, 0x2e, 0x3f, 0xd3, 0x93, 0x39, 0x96, 0x62, 0x0e, 0x3d, 0x74, 0x20, 0x71,
+	0xd0, 0x1a, 0x5f, 0x4f, 0xfa, 0xb3, 0x61, 0xe3, 0xea, 0xd4, 0x4a, 0xe7, 0x95, 0x7d, 0xf2, 0xaf,
+	0x8b, 0x16, 0xf0, 0x70, 0xc1, 0x89, 0x10, 0xba, 0xe8, 0x03, 0xfa, 0xf0, 0xe7, 0x5a, 0x0c, 0xa1,
+	0x53, 0xb0, 0xdd, 0x2a, 0xe9, 0xf3, 0xdc, 0x16, 0x6c, 0xd7, 0x72, 0xf6, 0x05, 0x5d, 0x9f, 0x86,
+	0xc4, 0x12, 0x06, 0x1b, 0x32, 0x19, 0x32, 0x6f, 0xfc, 0x8c, 0xc4, 0x4b, 0x13, 0xe2, 0x5b, 0xe5,
+	0x1a, 0x65, 0x83, 0x87, 0xc1, 0xf9, 0xcb, 0x1e, 0x49, 0xfc, 0x30, 0xa3, 0xab, 0xc5, 0xfb, 0xcf,
+	0x5b, 0xae, 0xca, 0xfd, 0x71, 0x37, 0xcd, 0x4c, 0x11, 0xef, 0x4f, 0x16, 0xe9, 0x80, 0x32, 0x47,
+	0x8a, 0x7f, 0xd3, 0x1d, 0xa9, 0x2c, 0x76, 0xad, 0xf5, 0xda, 0x76, 0x6e, 0x61, 0xf3, 0xbf, 0x01,
+	0x00, 0x6b, 0xb2, 0x51, 0x64, 0xca, 0x01, 0x00, 0x00,
 }
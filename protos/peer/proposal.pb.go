@@ -49,7 +49,7 @@ type SignedProposal struct {
 func (m *SignedProposal) Reset()                    { *m = SignedProposal{} }
 func (m *SignedProposal) String() string            { return proto.CompactTextString(m) }
 func (*SignedProposal) ProtoMessage()               {}
-func (*SignedProposal) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{0} }
+func (*SignedProposal) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{0} }
 
 // A Proposal is sent to an endorser for endorsement.  The proposal contains:
 // 1. A header which should be unmarshaled to a Header message.  Note that
@@ -85,7 +85,7 @@ type Proposal struct {
 func (m *Proposal) Reset()                    { *m = Proposal{} }
 func (m *Proposal) String() string            { return proto.CompactTextString(m) }
 func (*Proposal) ProtoMessage()               {}
-func (*Proposal) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{1} }
+func (*Proposal) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{1} }
 
 // ChaincodeHeaderExtension is the Header's extentions message to be used when
 // the Header's type is CHAINCODE.  This extensions is used to specify which
@@ -110,7 +110,7 @@ type ChaincodeHeaderExtension struct {
 func (m *ChaincodeHeaderExtension) Reset()                    { *m = ChaincodeHeaderExtension{} }
 func (m *ChaincodeHeaderExtension) String() string            { return proto.CompactTextString(m) }
 func (*ChaincodeHeaderExtension) ProtoMessage()               {}
-func (*ChaincodeHeaderExtension) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{2} }
+func (*ChaincodeHeaderExtension) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{2} }
 
 func (m *ChaincodeHeaderExtension) GetChaincodeId() *ChaincodeID {
 	if m != nil {
@@ -136,7 +136,7 @@ type ChaincodeProposalPayload struct {
 func (m *ChaincodeProposalPayload) Reset()                    { *m = ChaincodeProposalPayload{} }
 func (m *ChaincodeProposalPayload) String() string            { return proto.CompactTextString(m) }
 func (*ChaincodeProposalPayload) ProtoMessage()               {}
-func (*ChaincodeProposalPayload) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{3} }
+func (*ChaincodeProposalPayload) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{3} }
 
 // ChaincodeAction contains the actions the events generated by the execution
 // of the chaincode.
@@ -154,7 +154,7 @@ type ChaincodeAction struct {
 func (m *ChaincodeAction) Reset()                    { *m = ChaincodeAction{} }
 func (m *ChaincodeAction) String() string            { return proto.CompactTextString(m) }
 func (*ChaincodeAction) ProtoMessage()               {}
-func (*ChaincodeAction) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{4} }
+func (*ChaincodeAction) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{4} }
 
 func (m *ChaincodeAction) GetResponse() *Response {
 	if m != nil {
@@ -171,9 +171,9 @@ func init() {
 	proto.RegisterType((*ChaincodeAction)(nil), "protos.ChaincodeAction")
 }
 
-func init() { proto.RegisterFile("peer/proposal.proto", fileDescriptor6) }
+func init() { proto.RegisterFile("peer/proposal.proto", fileDescriptor7) }
 
-var fileDescriptor6 = []byte{
+var fileDescriptor7 = []byte{
 	// 361 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x54, 0x52, 0xcf, 0x4b, 0xf3, 0x40,
 	0x10, 0xa5, 0xdf, 0xc7, 0xd7, 0xf6, 0xdb, 0xd6, 0xaa, 0xdb, 0x22, 0xa1, 0xf4, 0x20, 0x01, 0x41,
@@ -8,6 +8,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import google_protobuf1 "github.com/golang/protobuf/ptypes/timestamp"
+import google_protobuf2 "github.com/golang/protobuf/ptypes/any"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -41,7 +42,7 @@ type ProposalResponse struct {
 func (m *ProposalResponse) Reset()                    { *m = ProposalResponse{} }
 func (m *ProposalResponse) String() string            { return proto.CompactTextString(m) }
 func (*ProposalResponse) ProtoMessage()               {}
-func (*ProposalResponse) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{0} }
+func (*ProposalResponse) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{0} }
 
 func (m *ProposalResponse) GetTimestamp() *google_protobuf1.Timestamp {
 	if m != nil {
@@ -73,12 +74,24 @@ type Response struct {
 	Message string `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
 	// A payload that can be used to include metadata with this response.
 	Payload []byte `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	// A gRPC style status code (see google.golang.org/grpc/codes) telling
+	// business errors from system errors, 0 (OK) when not set.
+	Code int32 `protobuf:"varint,4,opt,name=code" json:"code,omitempty"`
+	// Structured details of the error, propagated verbatim to the client.
+	Details []*google_protobuf2.Any `protobuf:"bytes,5,rep,name=details" json:"details,omitempty"`
 }
 
 func (m *Response) Reset()                    { *m = Response{} }
 func (m *Response) String() string            { return proto.CompactTextString(m) }
 func (*Response) ProtoMessage()               {}
-func (*Response) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{1} }
+func (*Response) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{1} }
+
+func (m *Response) GetDetails() []*google_protobuf2.Any {
+	if m != nil {
+		return m.Details
+	}
+	return nil
+}
 
 // ProposalResponsePayload is the payload of a proposal response.  This message
 // is the "bridge" between the client's request and the endorser's action in
@@ -113,7 +126,7 @@ type ProposalResponsePayload struct {
 func (m *ProposalResponsePayload) Reset()                    { *m = ProposalResponsePayload{} }
 func (m *ProposalResponsePayload) String() string            { return proto.CompactTextString(m) }
 func (*ProposalResponsePayload) ProtoMessage()               {}
-func (*ProposalResponsePayload) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{2} }
+func (*ProposalResponsePayload) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{2} }
 
 // An endorsement is a signature of an endorser over a proposal response.  By
 // producing an endorsement message, an endorser implicitly "approves" that
@@ -135,7 +148,7 @@ type Endorsement struct {
 func (m *Endorsement) Reset()                    { *m = Endorsement{} }
 func (m *Endorsement) String() string            { return proto.CompactTextString(m) }
 func (*Endorsement) ProtoMessage()               {}
-func (*Endorsement) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{3} }
+func (*Endorsement) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{3} }
 
 func init() {
 	proto.RegisterType((*ProposalResponse)(nil), "protos.ProposalResponse")
@@ -144,30 +157,33 @@ func init() {
 	proto.RegisterType((*Endorsement)(nil), "protos.Endorsement")
 }
 
-func init() { proto.RegisterFile("peer/proposal_response.proto", fileDescriptor7) }
-
-var fileDescriptor7 = []byte{
-	// 345 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x5c, 0x52, 0x5f, 0x4b, 0xfb, 0x30,
-	0x14, 0xa5, 0xfb, 0xfd, 0x36, 0xb7, 0xbb, 0x09, 0xa3, 0x82, 0x96, 0x31, 0x70, 0xd4, 0x97, 0x89,
-	0xd2, 0x82, 0x22, 0xf8, 0x2c, 0x88, 0x3e, 0x8e, 0x20, 0x3e, 0x88, 0x20, 0xe9, 0x76, 0xd7, 0x16,
-	0xda, 0x26, 0xe4, 0xa6, 0xe2, 0x3e, 0xb0, 0xdf, 0x43, 0x9a, 0x26, 0xdd, 0xf4, 0xa9, 0x9c, 0xdb,
-	0x93, 0xf3, 0x27, 0xb9, 0x30, 0x97, 0x88, 0x2a, 0x96, 0x4a, 0x48, 0x41, 0xbc, 0xf8, 0x50, 0x48,
-	0x52, 0x54, 0x84, 0x91, 0x54, 0x42, 0x0b, 0x7f, 0x60, 0x3e, 0x34, 0x3b, 0x4f, 0x85, 0x48, 0x0b,
-	0x8c, 0x0d, 0x4c, 0xea, 0x6d, 0xac, 0xf3, 0x12, 0x49, 0xf3, 0x52, 0xb6, 0xc4, 0xf0, 0xdb, 0x83,
-	0xe9, 0xca, 0x8a, 0x30, 0xab, 0xe1, 0x07, 0x70, 0xf4, 0x89, 0x8a, 0x72, 0x51, 0x05, 0xde, 0xc2,
-	0x5b, 0xf6, 0x99, 0x83, 0xfe, 0x3d, 0x8c, 0x3a, 0x85, 0xa0, 0xb7, 0xf0, 0x96, 0xe3, 0x9b, 0x59,
-	0xd4, 0x7a, 0x44, 0xce, 0x23, 0x7a, 0x71, 0x0c, 0xb6, 0x27, 0xfb, 0xd7, 0x30, 0x74, 0x19, 0x83,
-	0xff, 0xe6, 0xe0, 0xb4, 0x3d, 0x41, 0x91, 0xf3, 0x65, 0x1d, 0xa3, 0x49, 0x20, 0xf9, 0xae, 0x10,
-	0x7c, 0x13, 0xf4, 0x17, 0xde, 0x72, 0xc2, 0x1c, 0xf4, 0xef, 0x60, 0x8c, 0xd5, 0x46, 0x28, 0xc2,
-	0x12, 0x2b, 0x1d, 0x0c, 0x8c, 0xd4, 0x89, 0x93, 0x7a, 0xdc, 0xff, 0x62, 0x87, 0xbc, 0xf0, 0x15,
-	0x86, 0x5d, 0xbd, 0x53, 0x18, 0x90, 0xe6, 0xba, 0x26, 0xdb, 0xce, 0xa2, 0xc6, 0xb4, 0x44, 0x22,
-	0x9e, 0xa2, 0xa9, 0x36, 0x62, 0x0e, 0x1e, 0xc6, 0xf9, 0xf7, 0x2b, 0x4e, 0xf8, 0x0e, 0x67, 0x7f,
-	0xaf, 0x6f, 0x65, 0x93, 0x5e, 0xc0, 0x71, 0xf7, 0x3c, 0x19, 0xa7, 0xcc, 0xb8, 0x4d, 0xd8, 0xc4,
-	0x0d, 0x9f, 0x39, 0x65, 0xfe, 0x1c, 0x46, 0xf8, 0xa5, 0xb1, 0x32, 0x97, 0xdd, 0x33, 0x84, 0xfd,
-	0x20, 0x7c, 0x82, 0xf1, 0x41, 0x23, 0x7f, 0x06, 0x43, 0xdb, 0x49, 0x59, 0xb1, 0x0e, 0x37, 0x42,
-	0x94, 0xa7, 0x15, 0xd7, 0xb5, 0x42, 0x27, 0xd4, 0x0d, 0x1e, 0xae, 0xde, 0x2e, 0xd3, 0x5c, 0x67,
-	0x75, 0x12, 0xad, 0x45, 0x19, 0x67, 0x3b, 0x89, 0xaa, 0xc0, 0x4d, 0x8a, 0x2a, 0xde, 0xf2, 0x44,
-	0xe5, 0xeb, 0x76, 0x41, 0x28, 0x6e, 0x96, 0x2a, 0x69, 0x97, 0xe7, 0xf6, 0x27, 0x00, 0x00, 0xff,
-	0xff, 0xe8, 0x57, 0x2f, 0xb2, 0x63, 0x02, 0x00, 0x00,
+func init() { proto.RegisterFile("peer/proposal_response.proto", fileDescriptor8) }
+
+var fileDescriptor8 = []byte{
+	// 387 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x92, 0xdf, 0x8a, 0xd4, 0x30,
+	0x14, 0xc6, 0xe9, 0xee, 0x76, 0xb6, 0x93, 0x8e, 0xb0, 0x44, 0xd1, 0x5a, 0x16, 0x2c, 0xf5, 0xa6,
+	0xa2, 0xb4, 0xb0, 0x22, 0x78, 0xab, 0x20, 0x7a, 0x39, 0x04, 0xaf, 0x44, 0x90, 0x74, 0x7a, 0xa6,
+	0x2d, 0xb4, 0x49, 0xc8, 0x49, 0xc5, 0xbe, 0x8a, 0xef, 0xe7, 0x7b, 0xc8, 0xa4, 0x4d, 0xa7, 0x8e,
+	0x57, 0xed, 0x77, 0xf2, 0xe5, 0x97, 0xf3, 0x8f, 0xdc, 0x2b, 0x00, 0x5d, 0x28, 0x2d, 0x95, 0x44,
+	0xde, 0xfd, 0xd0, 0x80, 0x4a, 0x0a, 0x84, 0x5c, 0x69, 0x69, 0x24, 0xdd, 0xd8, 0x0f, 0xc6, 0x2f,
+	0x6a, 0x29, 0xeb, 0x0e, 0x0a, 0x2b, 0xcb, 0xe1, 0x58, 0x98, 0xb6, 0x07, 0x34, 0xbc, 0x57, 0x93,
+	0x31, 0x7e, 0x7e, 0x69, 0xe0, 0x62, 0x9c, 0x8e, 0xd2, 0x3f, 0x1e, 0xb9, 0xdb, 0xcf, 0x7c, 0x36,
+	0xe3, 0x69, 0x44, 0x6e, 0x7f, 0x82, 0xc6, 0x56, 0x8a, 0xc8, 0x4b, 0xbc, 0xcc, 0x67, 0x4e, 0xd2,
+	0xf7, 0x64, 0xbb, 0xc0, 0xa3, 0xab, 0xc4, 0xcb, 0xc2, 0x87, 0x38, 0x9f, 0xe8, 0xb9, 0xa3, 0xe7,
+	0x5f, 0x9d, 0x83, 0x9d, 0xcd, 0xf4, 0x0d, 0x09, 0x5c, 0xfa, 0xd1, 0x8d, 0xbd, 0x78, 0x37, 0xdd,
+	0xc0, 0xdc, 0xbd, 0xcb, 0x02, 0xbd, 0xca, 0x40, 0xf1, 0xb1, 0x93, 0xbc, 0x8a, 0xfc, 0xc4, 0xcb,
+	0x76, 0xcc, 0x49, 0xfa, 0x8e, 0x84, 0x20, 0x2a, 0xa9, 0x11, 0x7a, 0x10, 0x26, 0xda, 0x58, 0xd4,
+	0x63, 0x87, 0xfa, 0x74, 0x3e, 0x62, 0x6b, 0x5f, 0xfa, 0xdb, 0x23, 0xc1, 0x52, 0xdf, 0x53, 0xb2,
+	0x41, 0xc3, 0xcd, 0x80, 0x73, 0x79, 0xb3, 0x3a, 0xbd, 0xda, 0x03, 0x22, 0xaf, 0xc1, 0xd6, 0xb6,
+	0x65, 0x4e, 0xae, 0xf3, 0xb9, 0xfe, 0x37, 0x1f, 0x4a, 0x6e, 0x0e, 0xb2, 0x9a, 0x6a, 0xf2, 0x99,
+	0xfd, 0xa7, 0x39, 0xb9, 0xad, 0xc0, 0xf0, 0xb6, 0xc3, 0xc8, 0x4f, 0xae, 0xb3, 0xf0, 0xe1, 0xc9,
+	0x7f, 0x3d, 0xfa, 0x20, 0x46, 0xe6, 0x4c, 0xe9, 0x77, 0xf2, 0xec, 0x72, 0x06, 0xfb, 0x19, 0xff,
+	0x92, 0x3c, 0x5a, 0xc6, 0xdf, 0x70, 0x6c, 0x6c, 0xc6, 0x3b, 0xb6, 0x73, 0xc1, 0x2f, 0x1c, 0x1b,
+	0x7a, 0x4f, 0xb6, 0xf0, 0xcb, 0x80, 0xb0, 0x13, 0xbb, 0xb2, 0x86, 0x73, 0x20, 0xfd, 0x4c, 0xc2,
+	0x55, 0x5b, 0x68, 0x4c, 0x82, 0xb9, 0x31, 0x7a, 0x86, 0x2d, 0xfa, 0x04, 0xc2, 0xb6, 0x16, 0xdc,
+	0x0c, 0x1a, 0x1c, 0x68, 0x09, 0x7c, 0x7c, 0xfd, 0xed, 0x55, 0xdd, 0x9a, 0x66, 0x28, 0xf3, 0x83,
+	0xec, 0x8b, 0x66, 0x54, 0xa0, 0x3b, 0xa8, 0x6a, 0xd0, 0xc5, 0x91, 0x97, 0xba, 0x3d, 0x4c, 0xfb,
+	0x85, 0xc5, 0x69, 0x69, 0xcb, 0x69, 0x39, 0xdf, 0xfe, 0x1d, 0x00, 0x21, 0x57, 0xc4, 0x8a, 0xc3,
+	0x02, 0x00, 0x00,
 }
@@ -0,0 +1,202 @@
+// Code generated by protoc-gen-go.
+// source: peer/statesync.proto
+// DO NOT EDIT!
+
+package peer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// StateSyncRequest asks for the state updates committed to a chain,
+// starting with those of block start_block
+type StateSyncRequest struct {
+	ChainId    string `protobuf:"bytes,1,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
+	StartBlock uint64 `protobuf:"varint,2,opt,name=start_block,json=startBlock" json:"start_block,omitempty"`
+}
+
+func (m *StateSyncRequest) Reset()                    { *m = StateSyncRequest{} }
+func (m *StateSyncRequest) String() string            { return proto.CompactTextString(m) }
+func (*StateSyncRequest) ProtoMessage()               {}
+func (*StateSyncRequest) Descriptor() ([]byte, []int) { return fileDescriptor9, []int{0} }
+
+// StateWrite is a write to the world state by a valid transaction, along
+// with the version it gives to the key
+type StateWrite struct {
+	Namespace string `protobuf:"bytes,1,opt,name=namespace" json:"namespace,omitempty"`
+	Key       string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	Value     []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	IsDelete  bool   `protobuf:"varint,4,opt,name=is_delete,json=isDelete" json:"is_delete,omitempty"`
+	BlockNum  uint64 `protobuf:"varint,5,opt,name=block_num,json=blockNum" json:"block_num,omitempty"`
+	TxNum     uint64 `protobuf:"varint,6,opt,name=tx_num,json=txNum" json:"tx_num,omitempty"`
+}
+
+func (m *StateWrite) Reset()                    { *m = StateWrite{} }
+func (m *StateWrite) String() string            { return proto.CompactTextString(m) }
+func (*StateWrite) ProtoMessage()               {}
+func (*StateWrite) Descriptor() ([]byte, []int) { return fileDescriptor9, []int{1} }
+
+// BlockStateUpdates carries the writes of the valid transactions of a
+// block, in commit order. A consumer which has applied the updates of a
+// block resumes from block_number + 1
+type BlockStateUpdates struct {
+	BlockNumber uint64        `protobuf:"varint,1,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	Writes      []*StateWrite `protobuf:"bytes,2,rep,name=writes" json:"writes,omitempty"`
+}
+
+func (m *BlockStateUpdates) Reset()                    { *m = BlockStateUpdates{} }
+func (m *BlockStateUpdates) String() string            { return proto.CompactTextString(m) }
+func (*BlockStateUpdates) ProtoMessage()               {}
+func (*BlockStateUpdates) Descriptor() ([]byte, []int) { return fileDescriptor9, []int{2} }
+
+func (m *BlockStateUpdates) GetWrites() []*StateWrite {
+	if m != nil {
+		return m.Writes
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*StateSyncRequest)(nil), "protos.StateSyncRequest")
+	proto.RegisterType((*StateWrite)(nil), "protos.StateWrite")
+	proto.RegisterType((*BlockStateUpdates)(nil), "protos.BlockStateUpdates")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion3
+
+// Client API for StateSync service
+
+type StateSyncClient interface {
+	Sync(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (StateSync_SyncClient, error)
+}
+
+type stateSyncClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewStateSyncClient(cc *grpc.ClientConn) StateSyncClient {
+	return &stateSyncClient{cc}
+}
+
+func (c *stateSyncClient) Sync(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (StateSync_SyncClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_StateSync_serviceDesc.Streams[0], c.cc, "/protos.StateSync/Sync", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &stateSyncSyncClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StateSync_SyncClient interface {
+	Recv() (*BlockStateUpdates, error)
+	grpc.ClientStream
+}
+
+type stateSyncSyncClient struct {
+	grpc.ClientStream
+}
+
+func (x *stateSyncSyncClient) Recv() (*BlockStateUpdates, error) {
+	m := new(BlockStateUpdates)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for StateSync service
+
+type StateSyncServer interface {
+	Sync(*common.Envelope, StateSync_SyncServer) error
+}
+
+func RegisterStateSyncServer(s *grpc.Server, srv StateSyncServer) {
+	s.RegisterService(&_StateSync_serviceDesc, srv)
+}
+
+func _StateSync_Sync_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(common.Envelope)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StateSyncServer).Sync(m, &stateSyncSyncServer{stream})
+}
+
+type StateSync_SyncServer interface {
+	Send(*BlockStateUpdates) error
+	grpc.ServerStream
+}
+
+type stateSyncSyncServer struct {
+	grpc.ServerStream
+}
+
+func (x *stateSyncSyncServer) Send(m *BlockStateUpdates) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _StateSync_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.StateSync",
+	HandlerType: (*StateSyncServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Sync",
+			Handler:       _StateSync_Sync_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: fileDescriptor9,
+}
+
+func init() { proto.RegisterFile("peer/statesync.proto", fileDescriptor9) }
+
+var fileDescriptor9 = []byte{
+	// 357 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x51, 0x3d, 0x4f, 0xeb, 0x40,
+	0x10, 0x7c, 0x4e, 0x1c, 0x3f, 0x7b, 0x93, 0x22, 0xef, 0x5e, 0x90, 0x9c, 0x80, 0x84, 0x71, 0x65,
+	0x40, 0x8a, 0x51, 0x28, 0xe8, 0xa3, 0x50, 0xd0, 0xa4, 0xb8, 0x08, 0x21, 0xd1, 0x58, 0xfe, 0x58,
+	0x12, 0x2b, 0xfe, 0xe2, 0xee, 0x1c, 0xe2, 0xdf, 0xc3, 0x1f, 0x45, 0x77, 0xce, 0x47, 0x41, 0xb5,
+	0xb7, 0x33, 0xb7, 0xa3, 0xd9, 0x59, 0x18, 0x55, 0x88, 0xcc, 0xe7, 0x22, 0x14, 0xc8, 0x9b, 0x22,
+	0x9e, 0x56, 0xac, 0x14, 0x25, 0x31, 0x54, 0xe1, 0x93, 0xff, 0x71, 0x99, 0xe7, 0x65, 0xe1, 0xb7,
+	0xa5, 0x25, 0xdd, 0x25, 0x0c, 0x57, 0xf2, 0xff, 0xaa, 0x29, 0x62, 0x8a, 0x9f, 0x35, 0x72, 0x41,
+	0xc6, 0x60, 0xc6, 0x9b, 0x30, 0x2d, 0x82, 0x34, 0xb1, 0x35, 0x47, 0xf3, 0x2c, 0xfa, 0x57, 0xf5,
+	0x2f, 0x09, 0xb9, 0x86, 0x3e, 0x17, 0x21, 0x13, 0x41, 0x94, 0x95, 0xf1, 0xd6, 0xee, 0x38, 0x9a,
+	0xa7, 0x53, 0x50, 0xd0, 0x5c, 0x22, 0xee, 0xb7, 0x06, 0xa0, 0x04, 0xdf, 0x58, 0x2a, 0x90, 0x5c,
+	0x81, 0x55, 0x84, 0x39, 0xf2, 0x2a, 0x8c, 0xf1, 0xa0, 0x75, 0x06, 0xc8, 0x10, 0xba, 0x5b, 0x6c,
+	0x94, 0x8a, 0x45, 0xe5, 0x93, 0x8c, 0xa0, 0xb7, 0x0b, 0xb3, 0x1a, 0xed, 0xae, 0xa3, 0x79, 0x03,
+	0xda, 0x36, 0xe4, 0x12, 0xac, 0x94, 0x07, 0x09, 0x66, 0x28, 0xd0, 0xd6, 0x1d, 0xcd, 0x33, 0xa9,
+	0x99, 0xf2, 0x85, 0xea, 0x25, 0xa9, 0xcc, 0x04, 0x45, 0x9d, 0xdb, 0x3d, 0x65, 0xc8, 0x54, 0xc0,
+	0xb2, 0xce, 0xc9, 0x05, 0x18, 0x62, 0xaf, 0x18, 0x43, 0x31, 0x3d, 0xb1, 0x5f, 0xd6, 0xb9, 0x1b,
+	0xc1, 0x3f, 0x65, 0x57, 0x39, 0x7d, 0xad, 0x12, 0x19, 0x18, 0xb9, 0x81, 0xc1, 0x49, 0x28, 0x42,
+	0xa6, 0xec, 0xea, 0xb4, 0x7f, 0xd4, 0x8a, 0x90, 0x91, 0x3b, 0x30, 0xbe, 0xe4, 0x5e, 0xdc, 0xee,
+	0x38, 0x5d, 0xaf, 0x3f, 0x23, 0x6d, 0x8a, 0x7c, 0x7a, 0x5e, 0x99, 0x1e, 0x7e, 0xcc, 0x16, 0x60,
+	0x9d, 0x92, 0x25, 0x4f, 0xa0, 0xab, 0x3a, 0x9c, 0x1e, 0xd2, 0x7f, 0x2e, 0x76, 0x98, 0x95, 0x15,
+	0x4e, 0xc6, 0x47, 0x89, 0x5f, 0x86, 0xdc, 0x3f, 0x0f, 0xda, 0xfc, 0xfe, 0xfd, 0x76, 0x9d, 0x8a,
+	0x4d, 0x1d, 0xc9, 0x41, 0x7f, 0xd3, 0x54, 0xc8, 0x32, 0x4c, 0xd6, 0xc8, 0xfc, 0x8f, 0x30, 0x62,
+	0x69, 0xec, 0xb7, 0xd3, 0xbe, 0xbc, 0x7c, 0xd4, 0x5e, 0xfa, 0xf1, 0x67, 0x00, 0x0e, 0x06, 0x75,
+	0x25, 0x08, 0x02, 0x00, 0x00,
+}
@@ -31,7 +31,7 @@ type SignedTransaction struct {
 func (m *SignedTransaction) Reset()                    { *m = SignedTransaction{} }
 func (m *SignedTransaction) String() string            { return proto.CompactTextString(m) }
 func (*SignedTransaction) ProtoMessage()               {}
-func (*SignedTransaction) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{0} }
+func (*SignedTransaction) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{0} }
 
 // ProcessedTransaction wraps an Envelope that includes a transaction along with an indication
 // of whether the transaction was validated or invalidated by committing peer.
@@ -49,7 +49,7 @@ type ProcessedTransaction struct {
 func (m *ProcessedTransaction) Reset()                    { *m = ProcessedTransaction{} }
 func (m *ProcessedTransaction) String() string            { return proto.CompactTextString(m) }
 func (*ProcessedTransaction) ProtoMessage()               {}
-func (*ProcessedTransaction) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{1} }
+func (*ProcessedTransaction) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{1} }
 
 func (m *ProcessedTransaction) GetTransactionEnvelope() *common.Envelope {
 	if m != nil {
@@ -84,7 +84,7 @@ type Transaction struct {
 func (m *Transaction) Reset()                    { *m = Transaction{} }
 func (m *Transaction) String() string            { return proto.CompactTextString(m) }
 func (*Transaction) ProtoMessage()               {}
-func (*Transaction) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{2} }
+func (*Transaction) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{2} }
 
 func (m *Transaction) GetTimestamp() *google_protobuf1.Timestamp {
 	if m != nil {
@@ -113,7 +113,7 @@ type TransactionAction struct {
 func (m *TransactionAction) Reset()                    { *m = TransactionAction{} }
 func (m *TransactionAction) String() string            { return proto.CompactTextString(m) }
 func (*TransactionAction) ProtoMessage()               {}
-func (*TransactionAction) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{3} }
+func (*TransactionAction) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{3} }
 
 // ChaincodeActionPayload is the message to be used for the TransactionAction's
 // payload when the Header's type is set to CHAINCODE.  It carries the
@@ -136,7 +136,7 @@ type ChaincodeActionPayload struct {
 func (m *ChaincodeActionPayload) Reset()                    { *m = ChaincodeActionPayload{} }
 func (m *ChaincodeActionPayload) String() string            { return proto.CompactTextString(m) }
 func (*ChaincodeActionPayload) ProtoMessage()               {}
-func (*ChaincodeActionPayload) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{4} }
+func (*ChaincodeActionPayload) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{4} }
 
 func (m *ChaincodeActionPayload) GetAction() *ChaincodeEndorsedAction {
 	if m != nil {
@@ -160,7 +160,7 @@ type ChaincodeEndorsedAction struct {
 func (m *ChaincodeEndorsedAction) Reset()                    { *m = ChaincodeEndorsedAction{} }
 func (m *ChaincodeEndorsedAction) String() string            { return proto.CompactTextString(m) }
 func (*ChaincodeEndorsedAction) ProtoMessage()               {}
-func (*ChaincodeEndorsedAction) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{5} }
+func (*ChaincodeEndorsedAction) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{5} }
 
 func (m *ChaincodeEndorsedAction) GetEndorsements() []*Endorsement {
 	if m != nil {
@@ -169,6 +169,77 @@ func (m *ChaincodeEndorsedAction) GetEndorsements() []*Endorsement {
 	return nil
 }
 
+// RedactedTransaction is the view of a processed transaction returned by
+// GetTransactionByID to the members of the channel who are not allowed to read
+// the content of its transactions. It only tells what the transaction is,
+// whether it was validated by the committing peer and who endorsed it.
+type RedactedTransaction struct {
+	// The header of the transaction Envelope payload
+	Header *common.Header `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	// An indication of whether the transaction was validated or invalidated by committing peer
+	Valid bool `protobuf:"varint,2,opt,name=valid" json:"valid,omitempty"`
+	// The MSP identifiers of the endorsers of the transaction actions
+	EndorsingMsps []string `protobuf:"bytes,3,rep,name=endorsing_msps,json=endorsingMsps" json:"endorsing_msps,omitempty"`
+}
+
+func (m *RedactedTransaction) Reset()                    { *m = RedactedTransaction{} }
+func (m *RedactedTransaction) String() string            { return proto.CompactTextString(m) }
+func (*RedactedTransaction) ProtoMessage()               {}
+func (*RedactedTransaction) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{6} }
+
+func (m *RedactedTransaction) GetHeader() *common.Header {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+// MVCCConflict describes the read which invalidated a transaction of a block
+// in the MVCC validation of the committing peer, so that the client can retry
+// the transaction knowing which write it conflicted with.
+type MVCCConflict struct {
+	// The index of the invalidated transaction in the block
+	TxIndex uint64 `protobuf:"varint,1,opt,name=tx_index,json=txIndex" json:"tx_index,omitempty"`
+	// The ID of the invalidated transaction
+	TxId string `protobuf:"bytes,2,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
+	// The namespace and key read by the transaction. For a range query, the
+	// key is the start key of the range whose results changed
+	Namespace  string `protobuf:"bytes,3,opt,name=namespace" json:"namespace,omitempty"`
+	Key        string `protobuf:"bytes,4,opt,name=key" json:"key,omitempty"`
+	RangeQuery bool   `protobuf:"varint,5,opt,name=range_query,json=rangeQuery" json:"range_query,omitempty"`
+	// The version of the key written since the transaction read it, that is
+	// the block and the number in the block, starting at 1, of the transaction
+	// which wrote it, and the ID of that transaction. They are left empty when
+	// the committing peer does not know the write, as for a deleted key or a
+	// range query
+	ConflictingBlockNum uint64 `protobuf:"varint,6,opt,name=conflicting_block_num,json=conflictingBlockNum" json:"conflicting_block_num,omitempty"`
+	ConflictingTxNum    uint64 `protobuf:"varint,7,opt,name=conflicting_tx_num,json=conflictingTxNum" json:"conflicting_tx_num,omitempty"`
+	ConflictingTxId     string `protobuf:"bytes,8,opt,name=conflicting_tx_id,json=conflictingTxId" json:"conflicting_tx_id,omitempty"`
+}
+
+func (m *MVCCConflict) Reset()                    { *m = MVCCConflict{} }
+func (m *MVCCConflict) String() string            { return proto.CompactTextString(m) }
+func (*MVCCConflict) ProtoMessage()               {}
+func (*MVCCConflict) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{7} }
+
+// MVCCConflicts is the encoded value stored in the MVCC_CONFLICTS block
+// metadata index, listing the conflicts in the order of the transactions
+type MVCCConflicts struct {
+	Conflicts []*MVCCConflict `protobuf:"bytes,1,rep,name=conflicts" json:"conflicts,omitempty"`
+}
+
+func (m *MVCCConflicts) Reset()                    { *m = MVCCConflicts{} }
+func (m *MVCCConflicts) String() string            { return proto.CompactTextString(m) }
+func (*MVCCConflicts) ProtoMessage()               {}
+func (*MVCCConflicts) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{8} }
+
+func (m *MVCCConflicts) GetConflicts() []*MVCCConflict {
+	if m != nil {
+		return m.Conflicts
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*SignedTransaction)(nil), "protos.SignedTransaction")
 	proto.RegisterType((*ProcessedTransaction)(nil), "protos.ProcessedTransaction")
@@ -176,39 +247,55 @@ func init() {
 	proto.RegisterType((*TransactionAction)(nil), "protos.TransactionAction")
 	proto.RegisterType((*ChaincodeActionPayload)(nil), "protos.ChaincodeActionPayload")
 	proto.RegisterType((*ChaincodeEndorsedAction)(nil), "protos.ChaincodeEndorsedAction")
+	proto.RegisterType((*RedactedTransaction)(nil), "protos.RedactedTransaction")
+	proto.RegisterType((*MVCCConflict)(nil), "protos.MVCCConflict")
+	proto.RegisterType((*MVCCConflicts)(nil), "protos.MVCCConflicts")
 }
 
-func init() { proto.RegisterFile("peer/transaction.proto", fileDescriptor8) }
-
-var fileDescriptor8 = []byte{
-	// 449 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x53, 0x4d, 0x6b, 0xdb, 0x40,
-	0x14, 0x44, 0x09, 0x71, 0x9a, 0xe7, 0x1c, 0xe2, 0x75, 0x70, 0x14, 0x13, 0x48, 0xd0, 0x29, 0x25,
-	0x20, 0x81, 0x73, 0x68, 0x29, 0xbd, 0xd4, 0xc5, 0x77, 0xa3, 0xe6, 0xd4, 0x43, 0xcd, 0x4a, 0x7a,
-	0x91, 0x17, 0xa4, 0xdd, 0x65, 0x77, 0x6d, 0xf0, 0x9f, 0x68, 0x6f, 0xfd, 0xbd, 0xc5, 0xfb, 0x61,
-	0x29, 0x75, 0x7b, 0x91, 0x78, 0x6f, 0x46, 0x33, 0xa3, 0x61, 0x17, 0x26, 0x12, 0x51, 0x65, 0x46,
-	0x51, 0xae, 0x69, 0x69, 0x98, 0xe0, 0xa9, 0x54, 0xc2, 0x08, 0x32, 0xb0, 0x2f, 0x3d, 0xbd, 0xaf,
-	0x85, 0xa8, 0x1b, 0xcc, 0xec, 0x58, 0x6c, 0x5e, 0x33, 0xc3, 0x5a, 0xd4, 0x86, 0xb6, 0xd2, 0x11,
-	0xa7, 0x77, 0x56, 0x40, 0x2a, 0x21, 0x85, 0xa6, 0xcd, 0x4a, 0xa1, 0x96, 0x82, 0x6b, 0xf4, 0xe8,
-	0xb8, 0x14, 0x6d, 0x2b, 0x78, 0xe6, 0x5e, 0x6e, 0x99, 0xfc, 0x80, 0xd1, 0x37, 0x56, 0x73, 0xac,
-	0x5e, 0x3a, 0x5b, 0xf2, 0x04, 0xa3, 0x5e, 0x8a, 0x55, 0xb1, 0x33, 0xa8, 0xe3, 0xe8, 0x21, 0x7a,
-	0xbc, 0xcc, 0xaf, 0x7a, 0xc0, 0x7c, 0xbf, 0x27, 0x77, 0x70, 0xa1, 0x59, 0xcd, 0xa9, 0xd9, 0x28,
-	0x8c, 0x4f, 0x2c, 0xa9, 0x5b, 0x24, 0x12, 0xae, 0x97, 0x4a, 0x94, 0xa8, 0xf5, 0x5b, 0x8b, 0x39,
-	0x8c, 0x7b, 0x4a, 0x0b, 0xbe, 0xc5, 0x46, 0x48, 0xb4, 0x26, 0xc3, 0xd9, 0x55, 0xea, 0x33, 0x86,
-	0x7d, 0xfe, 0x2f, 0x32, 0xb9, 0x86, 0xb3, 0x2d, 0x6d, 0x58, 0x65, 0x5d, 0xdf, 0xe5, 0x6e, 0x48,
-	0x7e, 0x47, 0x30, 0xec, 0x3b, 0xc5, 0x70, 0xbe, 0x45, 0xa5, 0x99, 0xe0, 0x56, 0xfd, 0x2c, 0x0f,
-	0x23, 0xf9, 0x08, 0x17, 0x87, 0x06, 0xad, 0xc6, 0x70, 0x36, 0x4d, 0x5d, 0xc7, 0x69, 0xe8, 0x38,
-	0x7d, 0x09, 0x8c, 0xbc, 0x23, 0x93, 0x67, 0x38, 0x77, 0xea, 0x3a, 0x3e, 0x7d, 0x38, 0x7d, 0x1c,
-	0xce, 0x6e, 0xdd, 0x07, 0x3a, 0xed, 0x39, 0x7f, 0xb1, 0xcf, 0x3c, 0x30, 0x93, 0x05, 0x8c, 0x8e,
-	0x50, 0x32, 0x81, 0xc1, 0x1a, 0x69, 0x85, 0xca, 0xf7, 0xeb, 0xa7, 0x7d, 0x6a, 0x49, 0x77, 0x8d,
-	0xa0, 0x95, 0xef, 0x34, 0x8c, 0xc9, 0xaf, 0x08, 0x26, 0x5f, 0xd7, 0x94, 0xf1, 0x52, 0x54, 0xe8,
-	0x54, 0x96, 0x0e, 0x22, 0x9f, 0x61, 0x5a, 0x06, 0x64, 0x75, 0x38, 0x06, 0x41, 0xc7, 0x19, 0xc4,
-	0x07, 0xc6, 0xd2, 0x13, 0xc2, 0xd7, 0x1f, 0x60, 0xe0, 0xa2, 0xf9, 0x2e, 0xee, 0xc3, 0x3f, 0x1d,
-	0xdc, 0x16, 0xbc, 0x12, 0x4a, 0x63, 0xe5, 0xff, 0xcc, 0xd3, 0x93, 0x9f, 0x11, 0xdc, 0xfc, 0x87,
-	0x43, 0x3e, 0xc1, 0xed, 0xd1, 0x79, 0xfc, 0x2b, 0xd1, 0x4d, 0x20, 0xe4, 0x1e, 0xef, 0x02, 0x5d,
-	0xa2, 0x53, 0x6b, 0x91, 0x1b, 0x1d, 0x9f, 0xd8, 0xaa, 0xc7, 0x21, 0xd6, 0xa2, 0xc3, 0xf2, 0x37,
-	0xc4, 0xf9, 0xd3, 0xf7, 0xf7, 0x35, 0x33, 0xeb, 0x4d, 0xb1, 0x3f, 0x47, 0xd9, 0x7a, 0x27, 0x51,
-	0x35, 0x58, 0xd5, 0xa8, 0xb2, 0x57, 0x5a, 0x28, 0x56, 0xba, 0x1b, 0xa4, 0xb3, 0xfd, 0x75, 0x29,
-	0xdc, 0xed, 0x7a, 0xfe, 0x13, 0x00, 0x00, 0xff, 0xff, 0x86, 0x51, 0xa3, 0x83, 0x7e, 0x03, 0x00,
-	0x00,
+func init() { proto.RegisterFile("peer/transaction.proto", fileDescriptor10) }
+
+var fileDescriptor10 = []byte{
+	// 672 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x54, 0xc1, 0x6e, 0xda, 0x4a,
+	0x14, 0x15, 0x21, 0x40, 0xb8, 0x24, 0x79, 0x30, 0xe4, 0x25, 0x0e, 0x8a, 0x94, 0xc8, 0xd2, 0x7b,
+	0xca, 0x7b, 0xa9, 0x40, 0x22, 0x8b, 0x56, 0x55, 0x37, 0x05, 0x21, 0x35, 0x8b, 0x54, 0xa9, 0x1b,
+	0x75, 0xd1, 0x45, 0xd1, 0x60, 0xdf, 0x18, 0x2b, 0xf6, 0x8c, 0x3b, 0x33, 0x44, 0xd0, 0x8f, 0x68,
+	0x77, 0xdd, 0xf5, 0x5f, 0x2b, 0xcf, 0x78, 0xb0, 0x49, 0xd2, 0x0d, 0xe6, 0xde, 0x73, 0xe6, 0x9e,
+	0xe3, 0x33, 0xe3, 0x81, 0xc3, 0x14, 0x51, 0x0c, 0x94, 0xa0, 0x4c, 0x52, 0x5f, 0x45, 0x9c, 0xf5,
+	0x53, 0xc1, 0x15, 0x27, 0x75, 0xfd, 0x90, 0xbd, 0xd3, 0x90, 0xf3, 0x30, 0xc6, 0x81, 0x2e, 0x67,
+	0x8b, 0xbb, 0x81, 0x8a, 0x12, 0x94, 0x8a, 0x26, 0xa9, 0x21, 0xf6, 0x4e, 0xf4, 0x80, 0x54, 0xf0,
+	0x94, 0x4b, 0x1a, 0x4f, 0x05, 0xca, 0x94, 0x33, 0x89, 0x39, 0xda, 0xf5, 0x79, 0x92, 0x70, 0x36,
+	0x30, 0x0f, 0xd3, 0x74, 0xbf, 0x40, 0xe7, 0x63, 0x14, 0x32, 0x0c, 0x6e, 0x0b, 0x59, 0x72, 0x01,
+	0x9d, 0x92, 0x8b, 0xe9, 0x6c, 0xa5, 0x50, 0x3a, 0x95, 0xb3, 0xca, 0xf9, 0xae, 0xd7, 0x2e, 0x01,
+	0xa3, 0xac, 0x4f, 0x4e, 0xa0, 0x29, 0xa3, 0x90, 0x51, 0xb5, 0x10, 0xe8, 0x6c, 0x69, 0x52, 0xd1,
+	0x70, 0x53, 0x38, 0xb8, 0x11, 0xdc, 0x47, 0x29, 0x37, 0x25, 0x46, 0xd0, 0x2d, 0x4d, 0x9a, 0xb0,
+	0x07, 0x8c, 0x79, 0x8a, 0x5a, 0xa4, 0x35, 0x6c, 0xf7, 0x73, 0x8f, 0xb6, 0xef, 0x3d, 0x47, 0x26,
+	0x07, 0x50, 0x7b, 0xa0, 0x71, 0x14, 0x68, 0xd5, 0x1d, 0xcf, 0x14, 0xee, 0xcf, 0x0a, 0xb4, 0xca,
+	0x4a, 0x0e, 0x34, 0x1e, 0x50, 0xc8, 0x88, 0x33, 0x3d, 0xbd, 0xe6, 0xd9, 0x92, 0xbc, 0x82, 0xe6,
+	0x3a, 0x41, 0x3d, 0xa3, 0x35, 0xec, 0xf5, 0x4d, 0xc6, 0x7d, 0x9b, 0x71, 0xff, 0xd6, 0x32, 0xbc,
+	0x82, 0x4c, 0x2e, 0xa1, 0x61, 0xa6, 0x4b, 0xa7, 0x7a, 0x56, 0x3d, 0x6f, 0x0d, 0x8f, 0xcd, 0x02,
+	0xd9, 0x2f, 0x29, 0xbf, 0xd5, 0xbf, 0x9e, 0x65, 0xba, 0x13, 0xe8, 0x3c, 0x41, 0xc9, 0x21, 0xd4,
+	0xe7, 0x48, 0x03, 0x14, 0x79, 0xbe, 0x79, 0x95, 0xb9, 0x4e, 0xe9, 0x2a, 0xe6, 0x34, 0xc8, 0x33,
+	0xb5, 0xa5, 0xfb, 0xa3, 0x02, 0x87, 0xe3, 0x39, 0x8d, 0x98, 0xcf, 0x03, 0x34, 0x53, 0x6e, 0x0c,
+	0x44, 0xde, 0x40, 0xcf, 0xb7, 0xc8, 0x74, 0x7d, 0x0c, 0xec, 0x1c, 0x23, 0xe0, 0xac, 0x19, 0x37,
+	0x39, 0xc1, 0xae, 0x7e, 0x09, 0x75, 0x63, 0x2d, 0xcf, 0xe2, 0xd4, 0xbe, 0xd3, 0x5a, 0x6d, 0xc2,
+	0x02, 0x2e, 0x24, 0x06, 0xf9, 0x9b, 0xe5, 0x74, 0xf7, 0x7b, 0x05, 0x8e, 0xfe, 0xc0, 0x21, 0xaf,
+	0xe1, 0xf8, 0xc9, 0x79, 0x7c, 0xe4, 0xe8, 0xc8, 0x12, 0xbc, 0x1c, 0x2f, 0x0c, 0xed, 0xa2, 0x99,
+	0x96, 0x20, 0x53, 0xd2, 0xd9, 0xd2, 0x51, 0x77, 0xad, 0xad, 0x49, 0x81, 0x79, 0x1b, 0x44, 0xf7,
+	0x1b, 0x74, 0x3d, 0x0c, 0xa8, 0xaf, 0x36, 0xcf, 0xdc, 0xbf, 0x1b, 0x59, 0xb7, 0x86, 0xfb, 0xf6,
+	0x98, 0xbd, 0xd3, 0xdd, 0x75, 0xf6, 0xcf, 0x9e, 0x2b, 0xf2, 0x0f, 0xec, 0x1b, 0x91, 0x88, 0x85,
+	0xd3, 0x44, 0xa6, 0x66, 0xeb, 0x9b, 0xde, 0xde, 0xba, 0x7b, 0x2d, 0x53, 0xe9, 0xfe, 0xda, 0x82,
+	0xdd, 0xeb, 0x4f, 0xe3, 0xf1, 0x98, 0xb3, 0xbb, 0x38, 0xf2, 0x15, 0x39, 0x86, 0x1d, 0xb5, 0x9c,
+	0x46, 0x2c, 0xc0, 0xa5, 0xd6, 0xdd, 0xf6, 0x1a, 0x6a, 0x79, 0x95, 0x95, 0xa4, 0x0b, 0xb5, 0x0c,
+	0x32, 0x42, 0x4d, 0x6f, 0x5b, 0x2d, 0xaf, 0x82, 0xec, 0x7b, 0x62, 0x34, 0x41, 0x99, 0x52, 0x1f,
+	0x9d, 0xaa, 0x06, 0x8a, 0x06, 0x69, 0x43, 0xf5, 0x1e, 0x57, 0xce, 0xb6, 0xee, 0x67, 0x7f, 0xc9,
+	0x29, 0xb4, 0x04, 0x65, 0x21, 0x4e, 0xbf, 0x2e, 0x50, 0xac, 0x9c, 0x9a, 0xf6, 0x0c, 0xba, 0xf5,
+	0x21, 0xeb, 0x90, 0x21, 0xfc, 0xed, 0xe7, 0x66, 0x32, 0xeb, 0xb3, 0x98, 0xfb, 0xf7, 0x53, 0xb6,
+	0x48, 0x9c, 0xba, 0x76, 0xd3, 0x2d, 0x81, 0xa3, 0x0c, 0x7b, 0xbf, 0x48, 0xc8, 0x0b, 0x20, 0xe5,
+	0x35, 0x6a, 0xa9, 0x17, 0x34, 0xf4, 0x82, 0x76, 0x09, 0xb9, 0x5d, 0x66, 0xec, 0xff, 0xa1, 0xf3,
+	0x88, 0x1d, 0x05, 0xce, 0x8e, 0xb6, 0xf8, 0xd7, 0x06, 0xf9, 0x2a, 0x70, 0xc7, 0xb0, 0x57, 0x8e,
+	0x47, 0x92, 0x21, 0x34, 0x2d, 0x27, 0xbb, 0x64, 0xb2, 0x2d, 0x3e, 0xb0, 0x5b, 0x5c, 0x66, 0x7a,
+	0x05, 0x6d, 0x74, 0xf1, 0xf9, 0xbf, 0x30, 0x52, 0xf3, 0xc5, 0x2c, 0xdb, 0xc1, 0xc1, 0x7c, 0x95,
+	0xa2, 0x88, 0x31, 0x08, 0x51, 0x0c, 0xee, 0xe8, 0x4c, 0x44, 0xbe, 0xb9, 0x22, 0xe5, 0x20, 0xbb,
+	0x0f, 0x67, 0xe6, 0xfa, 0xbc, 0xfc, 0x3d, 0x00, 0x26, 0x7c, 0xa0, 0x8f, 0x5f, 0x05, 0x00, 0x00,
 }
@@ -17,10 +17,16 @@ limitations under the License.
 package utils
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io/ioutil"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
 // GetChainIDFromBlock returns chain ID in the block
@@ -83,6 +89,94 @@ func GetLastConfigIndexFromBlockOrPanic(block *cb.Block) uint64 {
 	return index
 }
 
+// GetCommitHashFromBlock retrieves the commit hash recorded in the block metadata by
+// the committing peer, or nil if the peer did not compute the commit hash of the block
+func GetCommitHashFromBlock(block *cb.Block) []byte {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_COMMIT_HASH) {
+		return nil
+	}
+	if hash := block.Metadata.Metadata[cb.BlockMetadataIndex_COMMIT_HASH]; len(hash) > 0 {
+		return hash
+	}
+	return nil
+}
+
+// GetMVCCConflictsFromBlock retrieves the MVCC conflicts recorded in the block metadata
+// by the committing peer, or nil if no transaction of the block was invalidated by one
+func GetMVCCConflictsFromBlock(block *cb.Block) (*pb.MVCCConflicts, error) {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_MVCC_CONFLICTS) {
+		return nil, nil
+	}
+	conflictsBytes := block.Metadata.Metadata[cb.BlockMetadataIndex_MVCC_CONFLICTS]
+	if len(conflictsBytes) == 0 {
+		return nil, nil
+	}
+	conflicts := &pb.MVCCConflicts{}
+	if err := proto.Unmarshal(conflictsBytes, conflicts); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling MVCC conflicts: %s", err)
+	}
+	return conflicts, nil
+}
+
+// GetConfigSequenceFromBlock returns the sequence number of the config carried by
+// a config block, or false if the block is not a config block. As the ledger
+// stores the blocks whatever their transactions, a block whose envelope cannot
+// be parsed is not a config block rather than an error
+func GetConfigSequenceFromBlock(block *cb.Block) (uint64, bool) {
+	if block.Data == nil || len(block.Data.Data) != 1 {
+		return 0, false
+	}
+	envelope, err := GetEnvelopeFromBlock(block.Data.Data[0])
+	if err != nil {
+		return 0, false
+	}
+	payload, err := UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return 0, false
+	}
+	if payload.Header == nil || payload.Header.ChannelHeader == nil ||
+		cb.HeaderType(payload.Header.ChannelHeader.Type) != cb.HeaderType_CONFIG {
+		return 0, false
+	}
+	configEnvelope := &cb.ConfigEnvelope{}
+	if err = proto.Unmarshal(payload.Data, configEnvelope); err != nil {
+		return 0, false
+	}
+	return ComputeConfigSequence(configEnvelope.Config.GetChannel()), true
+}
+
+// ComputeConfigSequence returns the sequence number of a config, which is the
+// highest version of its elements. The versions of the groups and policies are
+// included so that the sequence number does not regress when the only values
+// at the highest version are deleted, the groups which held them being written
+// at that version
+func ComputeConfigSequence(configGroup *cb.ConfigGroup) uint64 {
+	if configGroup == nil {
+		return 0
+	}
+
+	max := configGroup.Version
+	for _, value := range configGroup.GetValues() {
+		if value.Version > max {
+			max = value.Version
+		}
+	}
+
+	for _, policy := range configGroup.GetPolicies() {
+		if policy.Version > max {
+			max = policy.Version
+		}
+	}
+
+	for _, group := range configGroup.GetGroups() {
+		if groupMax := ComputeConfigSequence(group); groupMax > max {
+			max = groupMax
+		}
+	}
+
+	return max
+}
+
 // GetBlockFromBlockBytes marshals the bytes into Block
 func GetBlockFromBlockBytes(blockBytes []byte) (*cb.Block, error) {
 	block := &cb.Block{}
@@ -108,3 +202,55 @@ func InitBlockMetadata(block *cb.Block) {
 		}
 	}
 }
+
+// CompressBlock marshals the block and compresses it with the given algorithm
+func CompressBlock(block *cb.Block, compression ab.Compression) (*ab.CompressedBlock, error) {
+	blockBytes, err := proto.Marshal(block)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling block: %s", err)
+	}
+
+	var data []byte
+	switch compression {
+	case ab.Compression_GZIP:
+		buffer := new(bytes.Buffer)
+		writer := gzip.NewWriter(buffer)
+		if _, err = writer.Write(blockBytes); err != nil {
+			return nil, fmt.Errorf("Error compressing block: %s", err)
+		}
+		if err = writer.Close(); err != nil {
+			return nil, fmt.Errorf("Error compressing block: %s", err)
+		}
+		data = buffer.Bytes()
+	case ab.Compression_SNAPPY:
+		data = snappy.Encode(nil, blockBytes)
+	default:
+		return nil, fmt.Errorf("Unsupported block compression %s", compression)
+	}
+
+	return &ab.CompressedBlock{Compression: compression, Data: data}, nil
+}
+
+// DecompressBlock decompresses and unmarshals the block compressed by CompressBlock
+func DecompressBlock(compressed *ab.CompressedBlock) (*cb.Block, error) {
+	var blockBytes []byte
+	var err error
+	switch compressed.Compression {
+	case ab.Compression_GZIP:
+		var reader *gzip.Reader
+		if reader, err = gzip.NewReader(bytes.NewReader(compressed.Data)); err != nil {
+			return nil, fmt.Errorf("Error decompressing block: %s", err)
+		}
+		if blockBytes, err = ioutil.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("Error decompressing block: %s", err)
+		}
+	case ab.Compression_SNAPPY:
+		if blockBytes, err = snappy.Decode(nil, compressed.Data); err != nil {
+			return nil, fmt.Errorf("Error decompressing block: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported block compression %s", compressed.Compression)
+	}
+
+	return GetBlockFromBlockBytes(blockBytes)
+}
@@ -20,9 +20,12 @@ package utils_test
 import (
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
 	"github.com/hyperledger/fabric/protos/common"
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
 
@@ -84,3 +87,90 @@ func TestGetMetadataFromNewBlock(t *testing.T) {
 	}
 
 }
+
+func TestGetCommitHashFromBlock(t *testing.T) {
+	block := &cb.Block{}
+	if hash := utils.GetCommitHashFromBlock(block); hash != nil {
+		t.Fatal("Expected no commit hash in a block without metadata, got", hash)
+	}
+	block = common.NewBlock(0, nil)
+	if hash := utils.GetCommitHashFromBlock(block); hash != nil {
+		t.Fatal("Expected no commit hash in a new block, got", hash)
+	}
+	block.Metadata.Metadata[cb.BlockMetadataIndex_COMMIT_HASH] = []byte("hash")
+	if hash := utils.GetCommitHashFromBlock(block); string(hash) != "hash" {
+		t.Fatal("Expected the commit hash recorded in the block, got", hash)
+	}
+}
+
+func TestGetMVCCConflictsFromBlock(t *testing.T) {
+	block := common.NewBlock(0, nil)
+	conflicts, err := utils.GetMVCCConflictsFromBlock(block)
+	if err != nil || conflicts != nil {
+		t.Fatal("Expected no MVCC conflicts in a new block, got", conflicts, err)
+	}
+	block.Metadata.Metadata[cb.BlockMetadataIndex_MVCC_CONFLICTS] = utils.MarshalOrPanic(&pb.MVCCConflicts{
+		Conflicts: []*pb.MVCCConflict{{TxIndex: 1, TxId: "tx1", Namespace: "ns", Key: "key", ConflictingTxId: "tx0"}},
+	})
+	conflicts, err = utils.GetMVCCConflictsFromBlock(block)
+	if err != nil || len(conflicts.Conflicts) != 1 || conflicts.Conflicts[0].ConflictingTxId != "tx0" {
+		t.Fatal("Expected the MVCC conflicts recorded in the block, got", conflicts, err)
+	}
+	block.Metadata.Metadata[cb.BlockMetadataIndex_MVCC_CONFLICTS] = []byte("garbage")
+	if _, err = utils.GetMVCCConflictsFromBlock(block); err == nil {
+		t.Fatal("Expected an error for malformed MVCC conflicts")
+	}
+}
+
+func TestGetConfigSequenceFromBlock(t *testing.T) {
+	gb, err := configtxtest.MakeGenesisBlock("myuniquetestchainid")
+	if err != nil {
+		t.Fatalf("failed to create test configuration block: %s", err)
+	}
+	sequence, ok := utils.GetConfigSequenceFromBlock(gb)
+	if !ok {
+		t.Fatalf("failed to get the config sequence of the genesis block")
+	}
+	if sequence != 0 {
+		t.Fatalf("expected config sequence 0, got %d", sequence)
+	}
+
+	if _, ok = utils.GetConfigSequenceFromBlock(cb.NewBlock(1, nil)); ok {
+		t.Fatalf("expected an empty block not to be a config block")
+	}
+
+	block := cb.NewBlock(2, nil)
+	block.Data.Data = [][]byte{[]byte("garbage")}
+	if _, ok = utils.GetConfigSequenceFromBlock(block); ok {
+		t.Fatalf("expected a block with an unparsable envelope not to be a config block")
+	}
+}
+
+func TestCompressBlock(t *testing.T) {
+	gb, err := configtxtest.MakeGenesisBlock("myuniquetestchainid")
+	if err != nil {
+		t.Fatalf("failed to create test configuration block: %s", err)
+	}
+
+	for _, compression := range []ab.Compression{ab.Compression_GZIP, ab.Compression_SNAPPY} {
+		compressed, err := utils.CompressBlock(gb, compression)
+		if err != nil {
+			t.Fatalf("failed to compress block with %s: %s", compression, err)
+		}
+		block, err := utils.DecompressBlock(compressed)
+		if err != nil {
+			t.Fatalf("failed to decompress block with %s: %s", compression, err)
+		}
+		if !proto.Equal(gb, block) {
+			t.Fatalf("block compressed with %s did not survive the round trip", compression)
+		}
+	}
+
+	if _, err = utils.CompressBlock(gb, ab.Compression_NONE); err == nil {
+		t.Fatalf("error is expected -- compressing without an algorithm is not supported")
+	}
+
+	if _, err = utils.DecompressBlock(&ab.CompressedBlock{Compression: ab.Compression_GZIP, Data: []byte("garbage")}); err == nil {
+		t.Fatalf("error is expected -- the data is not a compressed block")
+	}
+}
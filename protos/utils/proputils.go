@@ -439,21 +439,33 @@ func CreateProposalFromCIS(txid string, typ common.HeaderType, chainID string, c
 
 // CreateInstallProposalFromCDS returns a install proposal given a serialized identity and a ChaincodeDeploymentSpec
 func CreateInstallProposalFromCDS(txid string, cds *peer.ChaincodeDeploymentSpec, creator []byte) (*peer.Proposal, error) {
-	return createProposalFromCDS(txid, "", cds, creator, nil, nil, nil, "install")
+	return createProposalFromCDS(txid, "", cds, creator, nil, nil, nil, nil, "install")
 }
 
 // CreateDeployProposalFromCDS returns a deploy proposal given a serialized identity and a ChaincodeDeploymentSpec
 func CreateDeployProposalFromCDS(txid string, chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte, policy []byte, escc []byte, vscc []byte) (*peer.Proposal, error) {
-	return createProposalFromCDS(txid, chainID, cds, creator, policy, escc, vscc, "deploy")
+	return createProposalFromCDS(txid, chainID, cds, creator, policy, escc, vscc, nil, "deploy")
+}
+
+// CreateDeployProposalFromCDSWithInvocationPolicy returns a deploy proposal given a serialized identity and a ChaincodeDeploymentSpec,
+// the chaincode being invocable only by the creators satisfying the marshalled SignaturePolicyEnvelope invocationPolicy
+func CreateDeployProposalFromCDSWithInvocationPolicy(txid string, chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte, policy []byte, escc []byte, vscc []byte, invocationPolicy []byte) (*peer.Proposal, error) {
+	return createProposalFromCDS(txid, chainID, cds, creator, policy, escc, vscc, invocationPolicy, "deploy")
 }
 
 // CreateUpgradeProposalFromCDS returns a upgrade proposal given a serialized identity and a ChaincodeDeploymentSpec
 func CreateUpgradeProposalFromCDS(txid string, chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte, policy []byte, escc []byte, vscc []byte) (*peer.Proposal, error) {
-	return createProposalFromCDS(txid, chainID, cds, creator, policy, escc, vscc, "upgrade")
+	return createProposalFromCDS(txid, chainID, cds, creator, policy, escc, vscc, nil, "upgrade")
+}
+
+// CreateUpgradeProposalFromCDSWithInvocationPolicy returns a upgrade proposal given a serialized identity and a ChaincodeDeploymentSpec,
+// the chaincode being invocable only by the creators satisfying the marshalled SignaturePolicyEnvelope invocationPolicy
+func CreateUpgradeProposalFromCDSWithInvocationPolicy(txid string, chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte, policy []byte, escc []byte, vscc []byte, invocationPolicy []byte) (*peer.Proposal, error) {
+	return createProposalFromCDS(txid, chainID, cds, creator, policy, escc, vscc, invocationPolicy, "upgrade")
 }
 
 // createProposalFromCDS returns a deploy or upgrade proposal given a serialized identity and a ChaincodeDeploymentSpec
-func createProposalFromCDS(txid string, chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte, policy []byte, escc []byte, vscc []byte, propType string) (*peer.Proposal, error) {
+func createProposalFromCDS(txid string, chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte, policy []byte, escc []byte, vscc []byte, invocationPolicy []byte, propType string) (*peer.Proposal, error) {
 	//in the new mode, cds will be nil, "deploy" and "upgrade" are instantiates.
 	var ccinp *peer.ChaincodeInput
 	var b []byte
@@ -469,6 +481,9 @@ func createProposalFromCDS(txid string, chainID string, cds *peer.ChaincodeDeplo
 		fallthrough
 	case "upgrade":
 		ccinp = &peer.ChaincodeInput{Args: [][]byte{[]byte(propType), []byte(chainID), b, policy, escc, vscc}}
+		if invocationPolicy != nil {
+			ccinp.Args = append(ccinp.Args, invocationPolicy)
+		}
 	case "install":
 		ccinp = &peer.ChaincodeInput{Args: [][]byte{[]byte(propType), b}}
 	}
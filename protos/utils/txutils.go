@@ -86,12 +86,6 @@ func CreateSignedTx(proposal *peer.Proposal, signer msp.SigningIdentity, resps .
 		return nil, fmt.Errorf("Could not unmarshal the proposal header")
 	}
 
-	// the original payload
-	pPayl, err := GetChaincodeProposalPayload(proposal.Payload)
-	if err != nil {
-		return nil, fmt.Errorf("Could not unmarshal the proposal payload")
-	}
-
 	// check that the signer is the same that is referenced in the header
 	// TODO: maybe worth removing?
 	signerBytes, err := signer.Serialize()
@@ -103,6 +97,41 @@ func CreateSignedTx(proposal *peer.Proposal, signer msp.SigningIdentity, resps .
 		return nil, fmt.Errorf("The signer needs to be the same as the one referenced in the header")
 	}
 
+	paylBytes, err := CreateTxPayload(proposal, resps...)
+	if err != nil {
+		return nil, err
+	}
+
+	// sign the payload
+	sig, err := signer.Sign(paylBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// here's the envelope
+	return &common.Envelope{Payload: paylBytes, Signature: sig}, nil
+}
+
+// CreateTxPayload assembles the marshaled Payload of the transaction of a proposal
+// and its endorsements, which the creator of the proposal signs to create the Envelope
+// of the transaction. It lets a transaction be assembled by another party than its creator
+func CreateTxPayload(proposal *peer.Proposal, resps ...*peer.ProposalResponse) ([]byte, error) {
+	if len(resps) == 0 {
+		return nil, fmt.Errorf("At least one proposal response is necessary")
+	}
+
+	// the original header
+	hdr, err := GetHeader(proposal.Header)
+	if err != nil {
+		return nil, fmt.Errorf("Could not unmarshal the proposal header")
+	}
+
+	// the original payload
+	pPayl, err := GetChaincodeProposalPayload(proposal.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("Could not unmarshal the proposal payload")
+	}
+
 	// get header extensions so we have the visibility field
 	hdrExt, err := GetChaincodeHeaderExtension(hdr)
 	if err != nil {
@@ -167,19 +196,7 @@ func CreateSignedTx(proposal *peer.Proposal, signer msp.SigningIdentity, resps .
 
 	// create the payload
 	payl := &common.Payload{Header: hdr, Data: txBytes}
-	paylBytes, err := GetBytesPayload(payl)
-	if err != nil {
-		return nil, err
-	}
-
-	// sign the payload
-	sig, err := signer.Sign(paylBytes)
-	if err != nil {
-		return nil, err
-	}
-
-	// here's the envelope
-	return &common.Envelope{Payload: paylBytes, Signature: sig}, nil
+	return GetBytesPayload(payl)
 }
 
 // CreateProposalResponse creates a proposal response.
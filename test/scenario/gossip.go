@@ -0,0 +1,205 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	"github.com/hyperledger/fabric/core/committer"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/gossip"
+	"github.com/hyperledger/fabric/msp"
+)
+
+func init() {
+	// the peers of a scenario come and go within seconds, so the members of
+	// the gossip network are declared dead and reconnected to quickly
+	aliveTimeInterval := 500 * time.Millisecond
+	discovery.SetAliveTimeInternal(aliveTimeInterval)
+	discovery.SetAliveExpirationCheckInterval(aliveTimeInterval)
+	discovery.SetExpirationTimeout(aliveTimeInterval * 10)
+	discovery.SetReconnectInterval(aliveTimeInterval * 2)
+}
+
+// newGossipConfig returns the gossip config of the peer id listening on
+// endpoint, which connects to bootPeers at startup. The blocks are pulled
+// every 4s as the peer command does, since a pull round waits a second for
+// its digests and a shorter interval would overlap the rounds
+func newGossipConfig(id string, endpoint string, bootPeers []string) (*gossip.Config, error) {
+	_, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	bindPort, err := net.LookupPort("tcp", port)
+	if err != nil {
+		return nil, err
+	}
+	return &gossip.Config{
+		BindPort:                   bindPort,
+		ID:                         id,
+		BootstrapPeers:             bootPeers,
+		PropagateIterations:        1,
+		PropagatePeerNum:           3,
+		MaxBlockCountToStore:       100,
+		MaxPropagationBurstSize:    10,
+		MaxPropagationBurstLatency: 10 * time.Millisecond,
+		PullInterval:               4 * time.Second,
+		PullPeerNum:                3,
+		PublishCertPeriod:          10 * time.Second,
+		PublishStateInfoInterval:   time.Second,
+		RequestStateInfoInterval:   time.Second,
+		InternalEndpoint:           endpoint,
+		ExternalEndpoint:           endpoint,
+	}, nil
+}
+
+// newEndpoint returns an endpoint of localhost whose port is free
+func newEndpoint() (string, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", fmt.Errorf("Error finding a free port: %s", err)
+	}
+	defer l.Close()
+	return l.Addr().String(), nil
+}
+
+// peerCrypto is the MessageCryptoService and the SecurityAdvisor of the gossip
+// of a peer. Those of the peer command rely on the MSPs of the process, which
+// the peers of a scenario cannot share, so the identities are checked against
+// the MSPs of the config of the peer and the messages are signed by the peer
+type peerCrypto struct {
+	signer msp.SigningIdentity
+	config configtxapi.Manager
+}
+
+// GetPKIidOfCert returns the SHA2-256 of peerIdentity, the serialization of an
+// identity of the channel
+func (c *peerCrypto) GetPKIidOfCert(peerIdentity api.PeerIdentityType) common.PKIidType {
+	if len(peerIdentity) == 0 {
+		return nil
+	}
+	digest := sha256.Sum256(peerIdentity)
+	return digest[:]
+}
+
+// VerifyBlock accepts every block, as the peer command does
+func (c *peerCrypto) VerifyBlock(chainID common.ChainID, signedBlock api.SignedBlock) error {
+	return nil
+}
+
+// Sign signs msg with the identity of the peer
+func (c *peerCrypto) Sign(msg []byte) ([]byte, error) {
+	return c.signer.Sign(msg)
+}
+
+// Verify checks that signature is a valid signature of message by peerIdentity,
+// a valid identity of the channel
+func (c *peerCrypto) Verify(peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	identity, err := c.validatedIdentity(peerIdentity)
+	if err != nil {
+		return err
+	}
+	return identity.Verify(message, signature)
+}
+
+// VerifyByChannel checks that signature is a valid signature of message by
+// peerIdentity, a valid identity of the channel, the only one of the peer
+func (c *peerCrypto) VerifyByChannel(chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	if string(chainID) != c.config.ChainID() {
+		return fmt.Errorf("Unknown channel %s", chainID)
+	}
+	return c.Verify(peerIdentity, signature, message)
+}
+
+// ValidateIdentity checks that peerIdentity is a valid identity of the channel
+func (c *peerCrypto) ValidateIdentity(peerIdentity api.PeerIdentityType) error {
+	_, err := c.validatedIdentity(peerIdentity)
+	return err
+}
+
+// OrgByPeerIdentity returns the MSP ID of peerIdentity, nil if it is not an
+// identity of the channel
+func (c *peerCrypto) OrgByPeerIdentity(peerIdentity api.PeerIdentityType) api.OrgIdentityType {
+	identity, err := c.config.MSPManager().DeserializeIdentity(peerIdentity)
+	if err != nil {
+		logger.Warningf("Error deserializing peer identity: %s", err)
+		return nil
+	}
+	return api.OrgIdentityType(identity.GetMSPIdentifier())
+}
+
+func (c *peerCrypto) validatedIdentity(peerIdentity api.PeerIdentityType) (msp.Identity, error) {
+	if len(peerIdentity) == 0 {
+		return nil, errors.New("Empty peer identity")
+	}
+	identity, err := c.config.MSPManager().DeserializeIdentity(peerIdentity)
+	if err != nil {
+		return nil, fmt.Errorf("Error deserializing peer identity: %s", err)
+	}
+	if err = identity.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid peer identity: %s", err)
+	}
+	return identity, nil
+}
+
+// joinChannelMessage lists the anchor peers of the config of the channel,
+// which gossip connects to across the organizations
+type joinChannelMessage struct {
+	sequence    uint64
+	anchorPeers []api.AnchorPeer
+}
+
+func newJoinChannelMessage(config configtxapi.Manager) *joinChannelMessage {
+	msg := &joinChannelMessage{sequence: config.Sequence()}
+	for _, org := range config.ApplicationConfig().Organizations() {
+		for _, ap := range org.AnchorPeers() {
+			msg.anchorPeers = append(msg.anchorPeers, api.AnchorPeer{
+				Host: ap.Host,
+				Port: int(ap.Port),
+				Cert: api.PeerIdentityType(ap.Cert),
+			})
+		}
+	}
+	return msg
+}
+
+// SequenceNumber returns the sequence of the config listing the anchor peers
+func (m *joinChannelMessage) SequenceNumber() uint64 {
+	return m.sequence
+}
+
+// AnchorPeers returns the anchor peers of the channel
+func (m *joinChannelMessage) AnchorPeers() []api.AnchorPeer {
+	return m.anchorPeers
+}
+
+// stateCommitter is the committer of a peer as its state provider sees it. The
+// state provider closes its committer when it is stopped, while the ledger of
+// a peer outlives its gossip, which an isolated peer stops
+type stateCommitter struct {
+	committer.Committer
+}
+
+// Close leaves the ledger open
+func (c *stateCommitter) Close() {}
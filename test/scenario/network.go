@@ -0,0 +1,645 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package scenario runs a network of a solo orderer and several peers within
+the calling process, so that features spanning the components can be tested
+without Docker.
+
+Every organization of the network has an MSP of its own, generated when the
+network is created, and its peers sign with identities it issues. The peers
+validate blocks as the peer command does, the validation system chaincode
+checking the endorsements against the endorsement policy the chaincodes are
+deployed with, and gossip them with the other peers of their organization.
+
+The orderer is driven by the test: nothing is sent to the peers until Sync
+is called. Sync delivers the blocks of the orderer to the peers connected to
+it, which gossip them, and waits for the peers to commit them. Faults are
+injected by disconnecting peers from the orderer, by isolating peers, whose
+gossip is then stopped, or by losing the blocks the orderer sends to a peer.
+The chaincodes of the network run in-process like the system chaincodes do,
+so they need neither a container nor a build.
+*/
+package scenario
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/configtx"
+	configtxapplication "github.com/hyperledger/fabric/common/configtx/handlers/application"
+	configtxmsp "github.com/hyperledger/fabric/common/configtx/handlers/msp"
+	configtxorderer "github.com/hyperledger/fabric/common/configtx/handlers/orderer"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	"github.com/hyperledger/fabric/common/genesis"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/core/scc"
+	"github.com/hyperledger/fabric/msp"
+	mspfixtures "github.com/hyperledger/fabric/msp/fixtures"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+)
+
+var logger = logging.MustGetLogger("test/scenario")
+
+const (
+	defaultOrgs         = 1
+	defaultPeersPerOrg  = 1
+	defaultBatchSize    = 1
+	defaultBlockHistory = 1000
+	defaultSyncTimeout  = 30 * time.Second
+
+	// ordererMSPID is the MSP ID of the organization of the orderer
+	ordererMSPID = "OrdererMSP"
+
+	// ccStartupTimeout is how long the chaincode support waits for a chaincode
+	// to register
+	ccStartupTimeout = 5 * time.Second
+)
+
+// networkCount numbers the networks of the process, the chaincodes of every
+// network being registered under paths of their own
+var networkCount uint64
+
+// Config describes the network to create
+type Config struct {
+	// Orgs is the number of organizations of the peers, 1 if unset. The MSP
+	// ID of the organization of index i is OrgMSPID(i)
+	Orgs int
+
+	// PeersPerOrg is the number of peers of every organization, 1 if unset.
+	// The first one is the anchor peer of the organization
+	PeersPerOrg int
+
+	// Chaincodes are the chaincodes the peers run, by name
+	Chaincodes map[string]shim.Chaincode
+
+	// EndorsementPolicy is the policy the chaincodes are deployed with, a
+	// member of any organization if unset
+	EndorsementPolicy *cb.SignaturePolicyEnvelope
+
+	// BatchSize is the maximum number of messages of a block, 1 if unset so
+	// that every message broadcast is cut into a block of its own
+	BatchSize uint32
+
+	// BlockHistory is the number of blocks the orderer keeps, 1000 if unset
+	BlockHistory int
+
+	// SyncTimeout is how long the network waits for the peers to commit the
+	// blocks and to gossip with each other, 30s if unset
+	SyncTimeout time.Duration
+}
+
+// OrgMSPID returns the MSP ID of the organization of index i
+func OrgMSPID(i int) string {
+	return fmt.Sprintf("Org%dMSP", i)
+}
+
+// MemberPolicy returns the endorsement policy satisfied by the signatures of
+// members of n of the organizations of mspIDs
+func MemberPolicy(n int32, mspIDs ...string) *cb.SignaturePolicyEnvelope {
+	principals := make([]*cb.MSPPrincipal, len(mspIDs))
+	rules := make([]*cb.SignaturePolicy, len(mspIDs))
+	for i, mspID := range mspIDs {
+		principals[i] = &cb.MSPPrincipal{
+			PrincipalClassification: cb.MSPPrincipal_ROLE,
+			Principal:               utils.MarshalOrPanic(&cb.MSPRole{Role: cb.MSPRole_MEMBER, MspIdentifier: mspID}),
+		}
+		rules[i] = cauthdsl.SignedBy(int32(i))
+	}
+	return &cb.SignaturePolicyEnvelope{
+		Version:    0,
+		Policy:     cauthdsl.NOutOf(n, rules),
+		Identities: principals,
+	}
+}
+
+// Network is an orderer and its peers sharing a chain
+type Network struct {
+	fileSystemPath string
+	chainID        string
+	syncTimeout    time.Duration
+	orderer        *Orderer
+	peers          []*Peer
+	sysCCs         []*scc.SystemChaincode
+
+	disconnected map[*Peer]bool
+}
+
+// peerIdentity is a peer of an organization before it is created
+type peerIdentity struct {
+	name     string
+	signer   msp.SigningIdentity
+	endpoint string
+}
+
+// NewNetwork creates the orderer and the peers of conf, joins the peers to
+// the chain of the orderer and deploys the chaincodes of conf. Close must be
+// called to stop the network and remove its files
+func NewNetwork(conf Config) (*Network, error) {
+	if conf.Orgs == 0 {
+		conf.Orgs = defaultOrgs
+	}
+	if conf.PeersPerOrg == 0 {
+		conf.PeersPerOrg = defaultPeersPerOrg
+	}
+	if conf.BatchSize == 0 {
+		conf.BatchSize = defaultBatchSize
+	}
+	if conf.BlockHistory == 0 {
+		conf.BlockHistory = defaultBlockHistory
+	}
+	if conf.SyncTimeout == 0 {
+		conf.SyncTimeout = defaultSyncTimeout
+	}
+	if conf.EndorsementPolicy == nil {
+		mspIDs := make([]string, conf.Orgs)
+		for i := range mspIDs {
+			mspIDs[i] = OrgMSPID(i)
+		}
+		conf.EndorsementPolicy = MemberPolicy(1, mspIDs...)
+	}
+
+	fileSystemPath, err := ioutil.TempDir("", "scenario")
+	if err != nil {
+		return nil, fmt.Errorf("Error creating network directory: %s", err)
+	}
+	n := &Network{
+		fileSystemPath: fileSystemPath,
+		chainID:        provisional.TestChainID,
+		syncTimeout:    conf.SyncTimeout,
+		disconnected:   make(map[*Peer]bool),
+	}
+	if err = n.start(conf); err != nil {
+		n.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+// start generates the organizations of the network, starts the orderer and
+// the peers and deploys the chaincodes
+func (n *Network) start(conf Config) error {
+	// the orderer signs the blocks with the local MSP of the process
+	ordererOrg, err := mspfixtures.NewOrg(ordererMSPID)
+	if err != nil {
+		return fmt.Errorf("Error generating the orderer organization: %s", err)
+	}
+	localMSPConf, err := ordererOrg.MSPConfig(ordererOrg.Member)
+	if err != nil {
+		return err
+	}
+	if err = mspmgmt.GetLocalMSP().Setup(localMSPConf); err != nil {
+		return fmt.Errorf("Error setting up the local MSP: %s", err)
+	}
+	ordererMSPConf, err := ordererOrg.MSPConfig(nil)
+	if err != nil {
+		return err
+	}
+	groups := []*cb.ConfigGroup{
+		configtxorderer.TemplateChainCreationPolicyNames(provisional.DefaultChainCreationPolicyNames),
+		configtxmsp.TemplateGroupMSP([]string{configtxorderer.GroupKey, ordererMSPID}, ordererMSPConf),
+	}
+
+	orgs := make([]*mspfixtures.Org, conf.Orgs)
+	identities := make([][]peerIdentity, conf.Orgs)
+	for i := range orgs {
+		if orgs[i], err = mspfixtures.NewOrg(OrgMSPID(i)); err != nil {
+			return fmt.Errorf("Error generating organization %s: %s", OrgMSPID(i), err)
+		}
+		for j := 0; j < conf.PeersPerOrg; j++ {
+			name := fmt.Sprintf("peer%d", i*conf.PeersPerOrg+j)
+			id, err := orgs[i].NewIdentity(name)
+			if err != nil {
+				return fmt.Errorf("Error generating the identity of %s: %s", name, err)
+			}
+			signer, err := orgs[i].SigningIdentity(id)
+			if err != nil {
+				return err
+			}
+			endpoint, err := newEndpoint()
+			if err != nil {
+				return err
+			}
+			identities[i] = append(identities[i], peerIdentity{name: name, signer: signer, endpoint: endpoint})
+		}
+
+		orgMSPConf, err := orgs[i].MSPConfig(nil)
+		if err != nil {
+			return err
+		}
+		anchorPeer, err := newAnchorPeer(identities[i][0])
+		if err != nil {
+			return err
+		}
+		groups = append(groups,
+			configtxmsp.TemplateGroupMSP([]string{configtxapplication.GroupKey, orgs[i].MSPID}, orgMSPConf),
+			configtxapplication.TemplateAnchorPeers(orgs[i].MSPID, []*pb.AnchorPeer{anchorPeer}))
+	}
+
+	genesisConf := genesisconfig.Load()
+	genesisConf.Orderer.OrdererType = provisional.ConsensusTypeSolo
+	genesisConf.Orderer.BatchSize.MaxMessageCount = conf.BatchSize
+	genesisBlock, err := genesis.NewFactoryImpl(configtx.NewCompositeTemplate(
+		provisional.New(genesisConf).ChannelTemplate(),
+		configtx.NewSimpleTemplate(groups...),
+	)).Block(n.chainID)
+	if err != nil {
+		return fmt.Errorf("Error creating genesis block: %s", err)
+	}
+
+	if err = n.startChaincodes(conf.Chaincodes); err != nil {
+		return err
+	}
+	if n.orderer, err = newOrderer(genesisBlock, conf.BlockHistory); err != nil {
+		return err
+	}
+
+	for i, org := range orgs {
+		client, err := org.SigningIdentity(org.Member)
+		if err != nil {
+			return err
+		}
+		for j, id := range identities[i] {
+			p, err := newPeer(id.name, filepath.Join(n.fileSystemPath, id.name), genesisBlock, id.signer, client, id.endpoint)
+			if err != nil {
+				return fmt.Errorf("Error creating %s: %s", id.name, err)
+			}
+			n.peers = append(n.peers, p)
+
+			// the peers of an organization bootstrap from its anchor peer
+			var bootPeers []string
+			if j > 0 {
+				bootPeers = []string{identities[i][0].endpoint}
+			}
+			if err = p.startGossip(bootPeers); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the validation of the transactions and the validation system chaincode
+	// read the MSPs of the chain from the process, the same for every peer
+	mspmgmt.XXXSetMSPManager(n.chainID, n.peers[0].Config().MSPManager())
+
+	if err = n.waitForGossip(); err != nil {
+		return err
+	}
+	return n.deployChaincodes(conf.Chaincodes, conf.EndorsementPolicy, conf.BatchSize)
+}
+
+// newAnchorPeer returns the anchor peer of the peer id
+func newAnchorPeer(id peerIdentity) (*pb.AnchorPeer, error) {
+	host, port, err := net.SplitHostPort(id.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := id.signer.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.AnchorPeer{Host: host, Port: int32(portNum), Cert: cert}, nil
+}
+
+// startChaincodes starts the chaincode support with the system chaincodes
+// and the chaincodes of the network. The chaincode support only deploys
+// chaincodes on the chains of the peer package, so the chain is created
+// there too; the peers of the network do not use it
+func (n *Network) startChaincodes(chaincodes map[string]shim.Chaincode) error {
+	viper.Set("peer.fileSystemPath", filepath.Join(n.fileSystemPath, "chaincodes"))
+	peer.MockInitialize()
+
+	getPeerEndpoint := func() (*pb.PeerEndpoint, error) {
+		return &pb.PeerEndpoint{Id: &pb.PeerID{Name: "scenariopeer"}, Address: "0.0.0.0:0"}, nil
+	}
+	chaincode.NewChaincodeSupport(getPeerEndpoint, false, ccStartupTimeout)
+
+	n.sysCCs = scc.MockRegisterSysCCs(nil)
+	sysCCs := append([]*scc.SystemChaincode{}, n.sysCCs...)
+	id := atomic.AddUint64(&networkCount, 1)
+	for name, cc := range chaincodes {
+		sysCCs = append(sysCCs, &scc.SystemChaincode{
+			Enabled:   true,
+			Name:      name,
+			Path:      fmt.Sprintf("github.com/hyperledger/fabric/test/scenario/%d/%s", id, name),
+			InitArgs:  [][]byte{[]byte("")},
+			Chaincode: cc,
+		})
+	}
+
+	whitelist := make(map[string]string)
+	for _, syscc := range sysCCs {
+		whitelist[syscc.Name] = "true"
+	}
+	viper.Set("chaincode.system", whitelist)
+	scc.MockRegisterSysCCs(sysCCs)
+
+	if err := peer.MockCreateChain(n.chainID); err != nil {
+		return fmt.Errorf("Error creating chain %s: %s", n.chainID, err)
+	}
+	scc.DeploySysCCs(n.chainID)
+	return nil
+}
+
+// deployChaincodes deploys the chaincodes through the lifecycle system
+// chaincode with policy, so that the peers validate their transactions, and
+// delivers the blocks of the deployments to the peers
+func (n *Network) deployChaincodes(chaincodes map[string]shim.Chaincode, policy *cb.SignaturePolicyEnvelope, batchSize uint32) error {
+	if len(chaincodes) == 0 {
+		return nil
+	}
+	policyBytes, err := proto.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(chaincodes))
+	for name := range chaincodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p := n.peers[0]
+	creator, err := p.client.Serialize()
+	if err != nil {
+		return err
+	}
+	height := n.orderer.Height(n.chainID)
+	for _, name := range names {
+		cds := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+			Type:        pb.ChaincodeSpec_GOLANG,
+			ChaincodeId: &pb.ChaincodeID{Name: name, Version: util.GetSysCCVersion()},
+			Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte("")}},
+		}}
+		prop, err := utils.CreateDeployProposalFromCDS(util.GenerateUUID(), n.chainID, cds, creator, policyBytes, []byte("escc"), []byte("vscc"))
+		if err != nil {
+			return fmt.Errorf("Error creating deployment proposal of %s: %s", name, err)
+		}
+		presp, err := p.endorse(prop)
+		if err != nil {
+			return fmt.Errorf("Error deploying %s: %s", name, err)
+		}
+		env, err := utils.CreateSignedTx(prop, p.client, presp)
+		if err != nil {
+			return fmt.Errorf("Error creating deployment transaction of %s: %s", name, err)
+		}
+		if err = n.orderer.Broadcast(env); err != nil {
+			return err
+		}
+	}
+
+	blocks := (uint64(len(names)) + uint64(batchSize) - 1) / uint64(batchSize)
+	if err = n.orderer.WaitForHeight(n.chainID, height+blocks, n.syncTimeout); err != nil {
+		return err
+	}
+	return n.Sync()
+}
+
+// ChainID returns the ID of the chain of the network
+func (n *Network) ChainID() string {
+	return n.chainID
+}
+
+// Orderer returns the orderer of the network
+func (n *Network) Orderer() *Orderer {
+	return n.orderer
+}
+
+// Peers returns the peers of the network, ordered by organization
+func (n *Network) Peers() []*Peer {
+	return n.peers
+}
+
+// Peer returns the peer of the given index
+func (n *Network) Peer(i int) *Peer {
+	return n.peers[i]
+}
+
+// Invoke has the endorsers, or the first peer if none is given, endorse ccName
+// for the client of the first endorser and broadcasts the transaction to the
+// orderer. It returns the ID of the transaction
+func (n *Network) Invoke(ccName string, args [][]byte, endorsers ...*Peer) (string, error) {
+	if len(endorsers) == 0 {
+		endorsers = n.peers[:1]
+	}
+
+	prop, presp, err := endorsers[0].Endorse(ccName, args...)
+	if err != nil {
+		return "", err
+	}
+	presps := []*pb.ProposalResponse{presp}
+	for _, p := range endorsers[1:] {
+		if presp, err = p.endorse(prop); err != nil {
+			return "", err
+		}
+		presps = append(presps, presp)
+	}
+
+	env, err := utils.CreateSignedTx(prop, endorsers[0].client, presps...)
+	if err != nil {
+		return "", fmt.Errorf("Error creating transaction: %s", err)
+	}
+	if err = n.orderer.Broadcast(env); err != nil {
+		return "", err
+	}
+
+	hdr, err := utils.GetHeader(prop.Header)
+	if err != nil {
+		return "", err
+	}
+	return hdr.ChannelHeader.TxId, nil
+}
+
+// Disconnect cuts p from the orderer, it only receives blocks from the other
+// peers of its organization
+func (n *Network) Disconnect(p *Peer) {
+	n.disconnected[p] = true
+}
+
+// Reconnect connects p to the orderer again
+func (n *Network) Reconnect(p *Peer) {
+	delete(n.disconnected, p)
+}
+
+// Isolate stops the gossip of p, it neither sends blocks to the other peers
+// nor receives blocks from them. The blocks p received and did not commit yet
+// are lost
+func (n *Network) Isolate(p *Peer) {
+	p.stopGossip()
+}
+
+// Rejoin starts the gossip of p again, bootstrapping from the peers of its
+// organization which are not isolated, and waits for them to know each other
+func (n *Network) Rejoin(p *Peer) error {
+	if !p.isolated() {
+		return nil
+	}
+	var bootPeers []string
+	for _, q := range n.peers {
+		if q != p && q.mspID == p.mspID && !q.isolated() {
+			bootPeers = append(bootPeers, q.endpoint)
+		}
+	}
+	if err := p.startGossip(bootPeers); err != nil {
+		return err
+	}
+	return n.waitForGossip()
+}
+
+// DropBlocks loses the next count blocks the orderer sends to p. The peers of
+// the organization of p gossip them to p, an isolated peer receives nothing
+// more from the orderer until the next Sync
+func (n *Network) DropBlocks(p *Peer, count int) {
+	p.drops += count
+}
+
+// Sync has the orderer send its blocks to the peers connected to it and waits
+// for every peer to commit the blocks it was sent and those it can get from
+// the peers of its organization it gossips with
+func (n *Network) Sync() error {
+	height := n.orderer.Height(n.chainID)
+	for _, p := range n.peers {
+		if n.disconnected[p] {
+			continue
+		}
+		if err := n.deliver(p, height); err != nil {
+			return err
+		}
+	}
+
+	// a peer gets the blocks the peers of its organization were sent or have
+	// through gossip, an isolated peer only has those it committed
+	expected := make(map[*Peer]uint64)
+	for _, p := range n.peers {
+		expected[p] = p.Height()
+		if !n.disconnected[p] && !p.isolated() && p.delivered > expected[p] {
+			expected[p] = p.delivered
+		}
+	}
+	for _, p := range n.peers {
+		for _, q := range n.peers {
+			if p.mspID == q.mspID && !p.isolated() && !q.isolated() && expected[q] > expected[p] {
+				expected[p] = expected[q]
+			}
+		}
+	}
+
+	deadline := time.Now().Add(n.syncTimeout)
+	for _, p := range n.peers {
+		for p.Height() < expected[p] {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("Peer %s did not reach height %d within %s, its height is %d", p.name, expected[p], n.syncTimeout, p.Height())
+			}
+			time.Sleep(pollInterval)
+		}
+	}
+	return nil
+}
+
+// deliver sends p the blocks of the orderer up to height it was not sent yet.
+// The orderer sends the first block p loses again at the next Sync, and stops
+// at it if p is isolated
+func (n *Network) deliver(p *Peer, height uint64) error {
+	isolated := p.isolated()
+	start := p.delivered
+	if isolated || start < p.Height() {
+		start = p.Height()
+	}
+
+	p.delivered = height
+	for number := start; number < height; number++ {
+		block := n.orderer.Block(n.chainID, number)
+		if block == nil {
+			return fmt.Errorf("Block %d is missing", number)
+		}
+		delivered, err := p.deliver(block)
+		if err != nil {
+			return err
+		}
+		if delivered {
+			continue
+		}
+		if p.delivered == height {
+			p.delivered = number
+		}
+		if isolated {
+			break
+		}
+	}
+	return nil
+}
+
+// waitForGossip waits for every peer which is not isolated to know the other
+// peers of its organization which are not, which the blocks are gossiped to
+func (n *Network) waitForGossip() error {
+	deadline := time.Now().Add(n.syncTimeout)
+	for _, p := range n.peers {
+		for _, q := range n.peers {
+			if p == q || p.mspID != q.mspID || p.isolated() || q.isolated() {
+				continue
+			}
+			for !p.gossipsWith(q) {
+				if time.Now().After(deadline) {
+					return fmt.Errorf("Peer %s does not gossip with %s after %s", p.name, q.name, n.syncTimeout)
+				}
+				time.Sleep(pollInterval)
+			}
+		}
+	}
+	return nil
+}
+
+// Close stops the orderer, the peers and the chaincodes and removes the files
+// of the network
+func (n *Network) Close() {
+	for _, p := range n.peers {
+		p.close()
+	}
+	if n.orderer != nil {
+		n.orderer.halt()
+	}
+	if n.sysCCs != nil {
+		scc.DeDeploySysCCs(n.chainID)
+		scc.MockResetSysCCs(n.sysCCs)
+	}
+
+	viper.Set("peer.fileSystemPath", filepath.Join(n.fileSystemPath, "chaincodes"))
+	ledgermgmt.CleanupTestEnv()
+	if err := os.RemoveAll(n.fileSystemPath); err != nil {
+		logger.Warningf("Error removing %s: %s", n.fileSystemPath, err)
+	}
+}
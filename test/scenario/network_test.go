@@ -0,0 +1,149 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+// kvChaincode puts and gets a single key
+type kvChaincode struct{}
+
+func (cc *kvChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+func (cc *kvChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	args := stub.GetArgs()
+	switch string(args[0]) {
+	case "put":
+		if err := stub.PutState("key", args[1]); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
+	case "get":
+		value, err := stub.GetState("key")
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(value)
+	}
+	return shim.Error("Unknown function")
+}
+
+func get(t *testing.T, p *Peer) string {
+	res, err := p.Query("kv", []byte("get"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(shim.OK), res.Status)
+	return string(res.Payload)
+}
+
+func put(t *testing.T, n *Network, value string, height uint64, endorsers ...*Peer) {
+	_, err := n.Invoke("kv", [][]byte{[]byte("put"), []byte(value)}, endorsers...)
+	assert.NoError(t, err)
+	assert.NoError(t, n.Orderer().WaitForHeight(n.ChainID(), height, 5*time.Second))
+}
+
+func TestNetwork(t *testing.T) {
+	n, err := NewNetwork(Config{
+		Orgs:        2,
+		PeersPerOrg: 2,
+		Chaincodes:  map[string]shim.Chaincode{"kv": &kvChaincode{}},
+	})
+	if err != nil {
+		t.Fatalf("Error creating network: %s", err)
+	}
+	defer n.Close()
+	p0, p1, p2, p3 := n.Peer(0), n.Peer(1), n.Peer(2), n.Peer(3)
+	assert.Equal(t, OrgMSPID(0), p1.MSPID())
+	assert.Equal(t, OrgMSPID(1), p2.MSPID())
+
+	put(t, n, "1", 3)
+	assert.Equal(t, uint64(2), p0.Height(), "Nothing should be delivered before Sync")
+	assert.NoError(t, n.Sync())
+	for _, p := range n.Peers() {
+		assert.Equal(t, uint64(3), p.Height())
+		assert.Equal(t, "1", get(t, p))
+	}
+
+	// p1 only receives the block through the gossip of its organization
+	n.Disconnect(p1)
+	put(t, n, "2", 4)
+	assert.NoError(t, n.Sync())
+	assert.Equal(t, uint64(4), p1.Height())
+	assert.Equal(t, "2", get(t, p1))
+
+	n.Isolate(p1)
+	put(t, n, "3", 5)
+	assert.NoError(t, n.Sync())
+	assert.Equal(t, uint64(5), p0.Height())
+	assert.Equal(t, uint64(4), p1.Height(), "An isolated peer disconnected from the orderer should not receive blocks")
+
+	assert.NoError(t, n.Rejoin(p1))
+	assert.NoError(t, n.Sync())
+	assert.Equal(t, uint64(5), p1.Height())
+	assert.Equal(t, "3", get(t, p1))
+	n.Reconnect(p1)
+
+	// the block p2 loses is gossiped by p3
+	n.DropBlocks(p2, 1)
+	put(t, n, "4", 6)
+	assert.NoError(t, n.Sync())
+	assert.Equal(t, uint64(6), p2.Height())
+	assert.Equal(t, "4", get(t, p2))
+
+	n.Isolate(p3)
+	n.DropBlocks(p3, 1)
+	put(t, n, "5", 7)
+	assert.NoError(t, n.Sync())
+	assert.Equal(t, uint64(6), p3.Height(), "An isolated peer losing a block should not receive more until the next Sync")
+	assert.NoError(t, n.Sync())
+	assert.Equal(t, uint64(7), p3.Height())
+	assert.Equal(t, "5", get(t, p3))
+	assert.NoError(t, n.Rejoin(p3))
+}
+
+func TestEndorsementPolicy(t *testing.T) {
+	n, err := NewNetwork(Config{
+		Orgs:              2,
+		Chaincodes:        map[string]shim.Chaincode{"kv": &kvChaincode{}},
+		EndorsementPolicy: MemberPolicy(2, OrgMSPID(0), OrgMSPID(1)),
+	})
+	if err != nil {
+		t.Fatalf("Error creating network: %s", err)
+	}
+	defer n.Close()
+	p0, p1 := n.Peer(0), n.Peer(1)
+
+	put(t, n, "1", 3, p0)
+	assert.NoError(t, n.Sync())
+	for _, p := range n.Peers() {
+		assert.Equal(t, uint64(3), p.Height())
+		assert.Equal(t, "", get(t, p), "A transaction endorsed by one organization should be invalid")
+	}
+
+	put(t, n, "2", 4, p0, p1)
+	assert.NoError(t, n.Sync())
+	for _, p := range n.Peers() {
+		assert.Equal(t, "2", get(t, p))
+	}
+}
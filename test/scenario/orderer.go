@@ -0,0 +1,123 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/common/localmsp"
+	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	"github.com/hyperledger/fabric/orderer/multichain"
+	"github.com/hyperledger/fabric/orderer/solo"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// pollInterval is how often WaitForHeight checks the height of a chain
+const pollInterval = 10 * time.Millisecond
+
+// Orderer is a solo orderer whose chains are kept in RAM. Messages are handed
+// to the chains the way the broadcast service does, without a gRPC listener
+type Orderer struct {
+	manager multichain.Manager
+}
+
+// newOrderer bootstraps a solo orderer from the genesis block of its system
+// chain, keeping up to historySize blocks of every chain
+func newOrderer(genesisBlock *cb.Block, historySize int) (*Orderer, error) {
+	chainID, err := utils.GetChainIDFromBlock(genesisBlock)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting chain ID from genesis block: %s", err)
+	}
+
+	lf := ramledger.New(historySize)
+	rl, err := lf.GetOrCreate(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating the ledger of chain %s: %s", chainID, err)
+	}
+	if err = rl.Append(genesisBlock); err != nil {
+		return nil, fmt.Errorf("Error appending genesis block: %s", err)
+	}
+
+	consenters := map[string]multichain.Consenter{"solo": solo.New()}
+	return &Orderer{manager: multichain.NewManagerImpl(lf, consenters, localmsp.NewSigner())}, nil
+}
+
+// Broadcast filters env and enqueues it for ordering on the chain it names,
+// returning an error if it is rejected
+func (o *Orderer) Broadcast(env *cb.Envelope) error {
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return fmt.Errorf("Error unmarshaling payload: %s", err)
+	}
+	if payload.Header == nil || payload.Header.ChannelHeader == nil {
+		return fmt.Errorf("Missing channel header")
+	}
+	chainID := payload.Header.ChannelHeader.ChannelId
+
+	support, ok := o.manager.GetChain(chainID)
+	if !ok {
+		return fmt.Errorf("Unknown chain %s", chainID)
+	}
+	if _, err = support.Filters().Apply(env); err != nil {
+		return fmt.Errorf("Message rejected by the filters of chain %s: %s", chainID, err)
+	}
+	if !support.Enqueue(env) {
+		return fmt.Errorf("Chain %s is shutting down", chainID)
+	}
+	return nil
+}
+
+// Height returns the number of blocks of the chain, or 0 if it is unknown
+func (o *Orderer) Height(chainID string) uint64 {
+	support, ok := o.manager.GetChain(chainID)
+	if !ok {
+		return 0
+	}
+	return support.Reader().Height()
+}
+
+// Block returns the block of the chain with the given number, or nil if the
+// chain has not cut it yet
+func (o *Orderer) Block(chainID string, number uint64) *cb.Block {
+	support, ok := o.manager.GetChain(chainID)
+	if !ok {
+		return nil
+	}
+	return ordererledger.GetBlock(support.Reader(), number)
+}
+
+// WaitForHeight waits until the chain reaches the given height. Solo cuts
+// blocks on a goroutine of its own, so the blocks of the messages broadcast
+// are written asynchronously
+func (o *Orderer) WaitForHeight(chainID string, height uint64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for o.Height(chainID) < height {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Chain %s did not reach height %d within %s, its height is %d", chainID, height, timeout, o.Height(chainID))
+		}
+		time.Sleep(pollInterval)
+	}
+	return nil
+}
+
+// halt stops the chains of the orderer
+func (o *Orderer) halt() {
+	o.manager.Halt()
+}
@@ -0,0 +1,406 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/configtx"
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/committer"
+	"github.com/hyperledger/fabric/core/committer/txvalidator"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger"
+	gossipcommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/gossip"
+	"github.com/hyperledger/fabric/gossip/identity"
+	"github.com/hyperledger/fabric/gossip/state"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	gossipproto "github.com/hyperledger/fabric/protos/gossip"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+// Peer is a peer of the network with a ledger and a config of its own. It
+// endorses with the chaincodes of the network, validates the blocks it is
+// delivered with the validation system chaincode and gossips them with the
+// other peers
+type Peer struct {
+	name      string
+	mspID     string
+	chainID   string
+	endpoint  string
+	signer    msp.SigningIdentity
+	client    msp.SigningIdentity
+	provider  ledger.PeerLedgerProvider
+	ledger    ledger.PeerLedger
+	config    configtxapi.Manager
+	committer committer.Committer
+
+	// lock guards the gossip of the peer and its state provider, which are
+	// nil while the peer is isolated
+	lock   sync.Mutex
+	gossip gossip.Gossip
+	state  state.GossipStateProvider
+
+	// delivered is the number of the next block the orderer sends the peer,
+	// and drops is the number of blocks to be lost before the peer receives one
+	delivered uint64
+	drops     int
+}
+
+// newPeer creates the ledger of the peer under fileSystemPath and joins it to
+// the chain of genesisBlock. The peer endorses with signer and invokes the
+// chaincodes for client, a member of its organization. Its gossip is started
+// by startGossip
+func newPeer(name string, fileSystemPath string, genesisBlock *cb.Block, signer msp.SigningIdentity, client msp.SigningIdentity, endpoint string) (*Peer, error) {
+	chainID, err := utils.GetChainIDFromBlock(genesisBlock)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting chain ID from genesis block: %s", err)
+	}
+	p := &Peer{
+		name:      name,
+		mspID:     signer.GetMSPIdentifier(),
+		chainID:   chainID,
+		endpoint:  endpoint,
+		signer:    signer,
+		client:    client,
+		delivered: 1,
+	}
+
+	configEnvelope, err := configtx.ConfigEnvelopeFromBlock(genesisBlock)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting config from genesis block: %s", err)
+	}
+	p.config, err = configtx.NewManagerImpl(configEnvelope, configtx.NewInitializer(), []configtxapi.ConfigListener{
+		configtxapi.PostApplyFunc(p.configUpdated),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error creating config manager: %s", err)
+	}
+
+	// the ledger paths are read from the peer config when the provider is
+	// created, which gives every peer a storage of its own
+	viper.Set("peer.fileSystemPath", fileSystemPath)
+	if p.provider, err = kvledger.NewProvider(); err != nil {
+		return nil, fmt.Errorf("Error creating ledger provider: %s", err)
+	}
+	if p.ledger, err = p.provider.Create(chainID); err != nil {
+		p.provider.Close()
+		return nil, fmt.Errorf("Error creating ledger: %s", err)
+	}
+	if err = p.ledger.Commit(genesisBlock); err != nil {
+		p.provider.Close()
+		return nil, fmt.Errorf("Error committing genesis block: %s", err)
+	}
+	validator := txvalidator.NewTxValidator(&validatorSupport{Manager: p.config, ledger: p.ledger})
+	p.committer = committer.NewLedgerCommitter(p.ledger, &serialValidator{Validator: validator})
+	return p, nil
+}
+
+// Name returns the name of the peer
+func (p *Peer) Name() string {
+	return p.name
+}
+
+// MSPID returns the MSP ID of the organization of the peer
+func (p *Peer) MSPID() string {
+	return p.mspID
+}
+
+// Ledger returns the ledger of the peer
+func (p *Peer) Ledger() ledger.PeerLedger {
+	return p.ledger
+}
+
+// Config returns the config manager of the chain of the peer
+func (p *Peer) Config() configtxapi.Manager {
+	return p.config
+}
+
+// Height returns the height of the ledger of the peer
+func (p *Peer) Height() uint64 {
+	height, err := p.committer.LedgerHeight()
+	if err != nil {
+		logger.Warningf("Error getting the ledger height of %s: %s", p.name, err)
+		return 0
+	}
+	return height
+}
+
+// Endorse executes ccName against the world state of the peer for the client
+// of the peer and returns the proposal with the signed proposal response of
+// the peer. The simulation results are not committed
+func (p *Peer) Endorse(ccName string, args ...[]byte) (*pb.Proposal, *pb.ProposalResponse, error) {
+	creator, err := p.client.Serialize()
+	if err != nil {
+		return nil, nil, err
+	}
+	prop, err := utils.CreateProposalFromCIS(util.GenerateUUID(), cb.HeaderType_ENDORSER_TRANSACTION, p.chainID, newInvocationSpec(ccName, args), creator)
+	if err != nil {
+		return nil, nil, err
+	}
+	presp, err := p.endorse(prop)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prop, presp, nil
+}
+
+// Query executes ccName against the world state of the peer and returns its
+// response
+func (p *Peer) Query(ccName string, args ...[]byte) (*pb.Response, error) {
+	txsim, err := p.ledger.NewTxSimulator()
+	if err != nil {
+		return nil, err
+	}
+	defer txsim.Done()
+
+	res, _, err := p.execute(txsim, ccName, util.GenerateUUID(), nil, newInvocationSpec(ccName, args))
+	return res, err
+}
+
+// endorse simulates the proposal prop and signs the response
+func (p *Peer) endorse(prop *pb.Proposal) (*pb.ProposalResponse, error) {
+	hdr, err := utils.GetHeader(prop.Header)
+	if err != nil {
+		return nil, err
+	}
+	cis, err := utils.GetChaincodeInvocationSpec(prop)
+	if err != nil {
+		return nil, err
+	}
+
+	txsim, err := p.ledger.NewTxSimulator()
+	if err != nil {
+		return nil, err
+	}
+	defer txsim.Done()
+
+	res, event, err := p.execute(txsim, cis.ChaincodeSpec.ChaincodeId.Name, hdr.ChannelHeader.TxId, prop, cis)
+	if err != nil {
+		return nil, fmt.Errorf("Error executing %s on %s: %s", cis.ChaincodeSpec.ChaincodeId.Name, p.name, err)
+	}
+	if res.Status >= shim.ERROR {
+		return nil, fmt.Errorf("Chaincode %s returned status %d on %s: %s", cis.ChaincodeSpec.ChaincodeId.Name, res.Status, p.name, res.Message)
+	}
+
+	results, err := txsim.GetTxSimulationResults()
+	if err != nil {
+		return nil, err
+	}
+	var eventBytes []byte
+	if event != nil {
+		if eventBytes, err = utils.GetBytesChaincodeEvent(event); err != nil {
+			return nil, err
+		}
+	}
+	return utils.CreateProposalResponse(prop.Header, prop.Payload, res, results, eventBytes, nil, p.signer)
+}
+
+// execute runs cis with the simulator of the peer. The chaincodes of the
+// network run in-process and are shared by the peers, the simulator in the
+// context decides which world state they see
+func (p *Peer) execute(txsim ledger.TxSimulator, ccName string, txid string, prop *pb.Proposal, cis *pb.ChaincodeInvocationSpec) (*pb.Response, *pb.ChaincodeEvent, error) {
+	ctxt := context.WithValue(context.Background(), chaincode.TXSimulatorKey, txsim)
+	cccid := ccprovider.NewCCContext(p.chainID, ccName, util.GetSysCCVersion(), txid, true, prop)
+	return chaincode.Execute(ctxt, cccid, cis)
+}
+
+// startGossip starts the gossip of the peer, which connects to bootPeers and
+// to the anchor peers of the chain, and the state provider committing the
+// blocks it receives
+func (p *Peer) startGossip(bootPeers []string) error {
+	conf, err := newGossipConfig(p.name, p.endpoint, bootPeers)
+	if err != nil {
+		return fmt.Errorf("Error creating gossip config of %s: %s", p.name, err)
+	}
+	peerIdentity, err := p.signer.Serialize()
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	crypto := &peerCrypto{signer: p.signer, config: p.config}
+	p.gossip = gossip.NewGossipServiceWithServer(conf, crypto, crypto, identity.NewIdentityMapper(crypto), peerIdentity)
+	p.gossip.JoinChan(newJoinChannelMessage(p.config), gossipcommon.ChainID(p.chainID))
+	p.state = state.NewGossipStateProvider(p.chainID, p.gossip, &stateCommitter{Committer: p.committer})
+	if p.state == nil {
+		p.gossip.Stop()
+		p.gossip = nil
+		return fmt.Errorf("Error creating the state provider of %s", p.name)
+	}
+	p.state.UpdateConfigDigest(p.config.ConfigDigest())
+	return nil
+}
+
+// stopGossip stops the gossip of the peer and its state provider, dropping
+// the blocks it received but did not commit yet
+func (p *Peer) stopGossip() {
+	// the state provider waits for the block it commits, whose config may
+	// need the lock, so they are stopped without it
+	p.lock.Lock()
+	g, s := p.gossip, p.state
+	p.gossip, p.state = nil, nil
+	p.lock.Unlock()
+	if s != nil {
+		s.Stop()
+	}
+	if g != nil {
+		g.Stop()
+	}
+}
+
+// configUpdated has the gossip of the peer join the anchor peers of the config
+// committed and advertise its digest, as the peer command does
+func (p *Peer) configUpdated(cm configtxapi.Manager) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.gossip == nil {
+		return
+	}
+	p.gossip.JoinChan(newJoinChannelMessage(cm), gossipcommon.ChainID(p.chainID))
+	p.state.UpdateConfigDigest(cm.ConfigDigest())
+}
+
+// deliver sends block from the orderer to the peer unless a drop of the peer
+// loses it, as the deliver service of the peer command would. The block is
+// added to the state provider of the peer and gossiped to the peers of its
+// organization, or committed if the peer is isolated. It returns whether the
+// block was delivered
+func (p *Peer) deliver(block *cb.Block) (bool, error) {
+	if p.drops > 0 {
+		p.drops--
+		logger.Debugf("Block %d is lost on its way to %s", block.Header.Number, p.name)
+		return false, nil
+	}
+
+	p.lock.Lock()
+	g, s := p.gossip, p.state
+	p.lock.Unlock()
+	if s == nil {
+		// the peers must not share the metadata the committer sets
+		if err := p.committer.Commit(proto.Clone(block).(*cb.Block)); err != nil {
+			return false, fmt.Errorf("Error committing block %d on %s: %s", block.Header.Number, p.name, err)
+		}
+		return true, nil
+	}
+
+	blockBytes, err := proto.Marshal(block)
+	if err != nil {
+		return false, err
+	}
+	payload := &gossipproto.Payload{Data: blockBytes, SeqNum: block.Header.Number}
+	if err = s.AddPayload(payload); err != nil {
+		logger.Debugf("Error adding block %d to the state of %s: %s", block.Header.Number, p.name, err)
+	}
+	g.Gossip(&gossipproto.GossipMessage{
+		Tag:     gossipproto.GossipMessage_CHAN_AND_ORG,
+		Channel: []byte(p.chainID),
+		Content: &gossipproto.GossipMessage_DataMsg{DataMsg: &gossipproto.DataMessage{Payload: payload}},
+	})
+	return true, nil
+}
+
+// isolated returns whether the gossip of the peer is stopped
+func (p *Peer) isolated() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.gossip == nil
+}
+
+// gossipsWith returns whether the gossip of the peer knows q as a member of
+// the chain
+func (p *Peer) gossipsWith(q *Peer) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.gossip == nil {
+		return false
+	}
+	qIdentity, err := q.signer.Serialize()
+	if err != nil {
+		return false
+	}
+	pkiID := (&peerCrypto{}).GetPKIidOfCert(qIdentity)
+	for _, member := range p.gossip.PeersOfChannel(gossipcommon.ChainID(p.chainID)) {
+		if bytes.Equal(member.PKIid, pkiID) {
+			return true
+		}
+	}
+	return false
+}
+
+// close stops the gossip of the peer and closes its ledger
+func (p *Peer) close() {
+	p.stopGossip()
+	if p.committer != nil {
+		p.committer.Close()
+	}
+	if p.provider != nil {
+		p.provider.Close()
+	}
+}
+
+func newInvocationSpec(ccName string, args [][]byte) *pb.ChaincodeInvocationSpec {
+	return &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_GOLANG,
+		ChaincodeId: &pb.ChaincodeID{Name: ccName, Version: util.GetSysCCVersion()},
+		Input:       &pb.ChaincodeInput{Args: args},
+	}}
+}
+
+// validatorSupport is the support of the validator of a peer: its ledger and
+// its config
+type validatorSupport struct {
+	configtxapi.Manager
+	ledger ledger.PeerLedger
+}
+
+// Ledger returns the ledger of the peer
+func (s *validatorSupport) Ledger() ledger.PeerLedger {
+	return s.ledger
+}
+
+// validationLock serializes the validation of the blocks of the peers of the
+// process. They share the chaincode support, which refuses to run the same
+// transaction ID twice at a time, while the validation system chaincode and
+// the lifecycle system chaincode it queries run with the ID of the
+// transaction validated
+var validationLock sync.Mutex
+
+// serialValidator validates the blocks of a peer under the validationLock
+type serialValidator struct {
+	txvalidator.Validator
+}
+
+// Validate validates block once no other peer validates a block
+func (v *serialValidator) Validate(block *cb.Block) error {
+	validationLock.Lock()
+	defer validationLock.Unlock()
+	return v.Validator.Validate(block)
+}